@@ -0,0 +1,6 @@
+// Package ragcodev1 is the gRPC API of the RagCode server, generated from
+// ragcode.proto. Start the server with -grpc-listen and connect with
+// NewRagCodeClient.
+package ragcodev1
+
+//go:generate protoc -I ../.. --go_out=../.. --go_opt=paths=source_relative --go-grpc_out=../.. --go-grpc_opt=paths=source_relative ragcode/v1/ragcode.proto
@@ -0,0 +1,213 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             v5.29.3
+// source: ragcode/v1/ragcode.proto
+
+package ragcodev1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	RagCode_Search_FullMethodName       = "/ragcode.v1.RagCode/Search"
+	RagCode_LookupSymbol_FullMethodName = "/ragcode.v1.RagCode/LookupSymbol"
+	RagCode_IndexStatus_FullMethodName  = "/ragcode.v1.RagCode/IndexStatus"
+)
+
+// RagCodeClient is the client API for RagCode service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// RagCode exposes the retrieval operations of the MCP server to programmatic
+// clients. Every request names a workspace by a path inside it, like the
+// file_path parameter of the MCP tools.
+type RagCodeClient interface {
+	// Search runs a semantic code search, ranked like search_code.
+	Search(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (*SearchResponse, error)
+	// LookupSymbol finds symbols by name in the symbol table, like
+	// get_symbols_bulk.
+	LookupSymbol(ctx context.Context, in *LookupSymbolRequest, opts ...grpc.CallOption) (*LookupSymbolResponse, error)
+	// IndexStatus reports the languages of a workspace and their index state.
+	IndexStatus(ctx context.Context, in *IndexStatusRequest, opts ...grpc.CallOption) (*IndexStatusResponse, error)
+}
+
+type ragCodeClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewRagCodeClient(cc grpc.ClientConnInterface) RagCodeClient {
+	return &ragCodeClient{cc}
+}
+
+func (c *ragCodeClient) Search(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (*SearchResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SearchResponse)
+	err := c.cc.Invoke(ctx, RagCode_Search_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ragCodeClient) LookupSymbol(ctx context.Context, in *LookupSymbolRequest, opts ...grpc.CallOption) (*LookupSymbolResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(LookupSymbolResponse)
+	err := c.cc.Invoke(ctx, RagCode_LookupSymbol_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *ragCodeClient) IndexStatus(ctx context.Context, in *IndexStatusRequest, opts ...grpc.CallOption) (*IndexStatusResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(IndexStatusResponse)
+	err := c.cc.Invoke(ctx, RagCode_IndexStatus_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// RagCodeServer is the server API for RagCode service.
+// All implementations must embed UnimplementedRagCodeServer
+// for forward compatibility.
+//
+// RagCode exposes the retrieval operations of the MCP server to programmatic
+// clients. Every request names a workspace by a path inside it, like the
+// file_path parameter of the MCP tools.
+type RagCodeServer interface {
+	// Search runs a semantic code search, ranked like search_code.
+	Search(context.Context, *SearchRequest) (*SearchResponse, error)
+	// LookupSymbol finds symbols by name in the symbol table, like
+	// get_symbols_bulk.
+	LookupSymbol(context.Context, *LookupSymbolRequest) (*LookupSymbolResponse, error)
+	// IndexStatus reports the languages of a workspace and their index state.
+	IndexStatus(context.Context, *IndexStatusRequest) (*IndexStatusResponse, error)
+	mustEmbedUnimplementedRagCodeServer()
+}
+
+// UnimplementedRagCodeServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedRagCodeServer struct{}
+
+func (UnimplementedRagCodeServer) Search(context.Context, *SearchRequest) (*SearchResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Search not implemented")
+}
+func (UnimplementedRagCodeServer) LookupSymbol(context.Context, *LookupSymbolRequest) (*LookupSymbolResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method LookupSymbol not implemented")
+}
+func (UnimplementedRagCodeServer) IndexStatus(context.Context, *IndexStatusRequest) (*IndexStatusResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method IndexStatus not implemented")
+}
+func (UnimplementedRagCodeServer) mustEmbedUnimplementedRagCodeServer() {}
+func (UnimplementedRagCodeServer) testEmbeddedByValue()                 {}
+
+// UnsafeRagCodeServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to RagCodeServer will
+// result in compilation errors.
+type UnsafeRagCodeServer interface {
+	mustEmbedUnimplementedRagCodeServer()
+}
+
+func RegisterRagCodeServer(s grpc.ServiceRegistrar, srv RagCodeServer) {
+	// If the following call pancis, it indicates UnimplementedRagCodeServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&RagCode_ServiceDesc, srv)
+}
+
+func _RagCode_Search_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SearchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RagCodeServer).Search(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RagCode_Search_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RagCodeServer).Search(ctx, req.(*SearchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RagCode_LookupSymbol_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LookupSymbolRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RagCodeServer).LookupSymbol(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RagCode_LookupSymbol_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RagCodeServer).LookupSymbol(ctx, req.(*LookupSymbolRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RagCode_IndexStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(IndexStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RagCodeServer).IndexStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RagCode_IndexStatus_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RagCodeServer).IndexStatus(ctx, req.(*IndexStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// RagCode_ServiceDesc is the grpc.ServiceDesc for RagCode service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var RagCode_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "ragcode.v1.RagCode",
+	HandlerType: (*RagCodeServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Search",
+			Handler:    _RagCode_Search_Handler,
+		},
+		{
+			MethodName: "LookupSymbol",
+			Handler:    _RagCode_LookupSymbol_Handler,
+		},
+		{
+			MethodName: "IndexStatus",
+			Handler:    _RagCode_IndexStatus_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "ragcode/v1/ragcode.proto",
+}
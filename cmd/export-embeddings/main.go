@@ -0,0 +1,197 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/doITmagic/rag-code-mcp/internal/config"
+	"github.com/doITmagic/rag-code-mcp/internal/export"
+	"github.com/doITmagic/rag-code-mcp/internal/objstore"
+	"github.com/doITmagic/rag-code-mcp/internal/storage"
+	"github.com/doITmagic/rag-code-mcp/internal/workspace"
+)
+
+var errLimitReached = errors.New("limit reached")
+
+// export-embeddings dumps the chunk vectors of a workspace with their labels,
+// to plot them (Embedding Projector, UMAP) and see which code clusters together.
+func main() {
+	var (
+		workspacePath = flag.String("workspace", ".", "Path inside the workspace to export")
+		languagesCSV  = flag.String("languages", "", "Comma-separated languages to export (default: all indexed languages of the workspace)")
+		collsCSV      = flag.String("collections", "", "Comma-separated Qdrant collections to export instead of the workspace collections")
+		format        = flag.String("format", "tsv", "Output format: tsv (vectors + metadata files, Embedding Projector) or parquet")
+		out           = flag.String("out", "embeddings", "Output path without extension, or an s3:// or gs:// URL to upload to")
+		limit         = flag.Int("limit", 0, "Maximum number of chunks to export (0 = all)")
+		timeoutSec    = flag.Int("timeout", 300, "Export timeout in seconds")
+		configPath    = flag.String("config", "config.yaml", "Path to config.yaml to read settings")
+	)
+	flag.Parse()
+
+	if *format != "tsv" && *format != "parquet" {
+		log.Fatalf("unknown format %q: use tsv or parquet", *format)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(*timeoutSec)*time.Second)
+	defer cancel()
+
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		log.Fatalf("load config: %v", err)
+	}
+
+	// collection name -> programming language, for the labels
+	collections := make(map[string]string)
+	var order []string
+	if *collsCSV != "" {
+		for _, c := range splitCSV(*collsCSV) {
+			collections[c] = ""
+			order = append(order, c)
+		}
+	} else {
+		detector := workspace.NewDetector()
+		detector.SetIdentity(cfg.Workspace.Identity)
+		info, err := detector.DetectFromPath(*workspacePath)
+		if err != nil {
+			log.Fatalf("detect workspace: %v", err)
+		}
+		if cfg.Workspace.CollectionPrefix != "" {
+			info.CollectionPrefix = cfg.Workspace.CollectionPrefix
+		}
+
+		languages := splitCSV(*languagesCSV)
+		if len(languages) == 0 {
+			languages, err = workspace.NewLanguageDetector().DetectLanguages(info.Root)
+			if err != nil {
+				log.Fatalf("detect languages: %v", err)
+			}
+		}
+		for _, lang := range languages {
+			name := info.CollectionNameForLanguage(lang)
+			collections[name] = lang
+			order = append(order, name)
+		}
+		fmt.Printf("🔎 Exporting workspace '%s' (%s)\n", info.Root, strings.Join(languages, ", "))
+	}
+
+	var rows []export.Row
+	dim := 0
+	skipped := 0
+	for _, name := range order {
+		client, err := storage.Open(cfg.Storage.VectorDB, name)
+		if err != nil {
+			log.Fatalf("vector store client: %v", err)
+		}
+
+		exists, err := client.CollectionExists(ctx, name)
+		if err != nil {
+			log.Fatalf("check collection '%s': %v", name, err)
+		}
+		if !exists {
+			fmt.Printf("ℹ️ Collection '%s' does not exist, skipping (index the workspace first)\n", name)
+			client.Close()
+			continue
+		}
+
+		err = client.ScrollVectors(ctx, 256, func(p storage.VectorPoint) error {
+			// Collections embedded with another model cannot share a plot
+			if len(p.Vector) == 0 || (dim != 0 && len(p.Vector) != dim) {
+				skipped++
+				return nil
+			}
+			dim = len(p.Vector)
+			rows = append(rows, export.RowFromPoint(p, name, collections[name]))
+			if *limit > 0 && len(rows) >= *limit {
+				return errLimitReached
+			}
+			return nil
+		})
+		client.Close()
+		if err != nil && err != errLimitReached {
+			log.Fatalf("export collection '%s': %v", name, err)
+		}
+		if err == errLimitReached {
+			break
+		}
+	}
+
+	if len(rows) == 0 {
+		log.Fatalf("no chunks to export: index the workspace first")
+	}
+	if skipped > 0 {
+		log.Printf("⚠️ Skipped %d chunk(s) without a vector or with a dimension other than %d", skipped, dim)
+	}
+
+	// Object storage exports are written to a temporary directory first
+	base := *out
+	if objstore.IsURL(*out) {
+		tmp, err := os.MkdirTemp("", "ragcode-export-")
+		if err != nil {
+			log.Fatalf("create temporary directory: %v", err)
+		}
+		defer os.RemoveAll(tmp)
+		base = filepath.Join(tmp, "embeddings")
+	}
+
+	var suffixes []string
+	switch *format {
+	case "parquet":
+		suffixes = []string{".parquet"}
+		err = writeFile(base+".parquet", func(f *os.File) error { return export.WriteParquet(f, rows) })
+	default:
+		suffixes = []string{"_vectors.tsv", "_metadata.tsv"}
+		err = writeFile(base+suffixes[0], func(vf *os.File) error {
+			return writeFile(base+suffixes[1], func(mf *os.File) error { return export.WriteTSV(vf, mf, rows) })
+		})
+	}
+	if err != nil {
+		log.Fatalf("write export: %v", err)
+	}
+
+	written := make([]string, 0, len(suffixes))
+	for _, suffix := range suffixes {
+		if objstore.IsURL(*out) {
+			if err := objstore.PutFile(ctx, *out+suffix, base+suffix); err != nil {
+				log.Fatalf("upload export: %v", err)
+			}
+		}
+		written = append(written, *out+suffix)
+	}
+	fmt.Printf("✅ Exported %d chunk(s) of dimension %d to %s\n", len(rows), dim, strings.Join(written, " and "))
+}
+
+func writeFile(path string, write func(*os.File) error) error {
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := write(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func splitCSV(s string) []string {
+	parts := strings.Split(s, ",")
+	out := make([]string, 0, len(parts))
+	for _, p := range parts {
+		p = strings.TrimSpace(p)
+		if p != "" {
+			out = append(out, p)
+		}
+	}
+	return out
+}
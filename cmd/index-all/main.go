@@ -1,7 +1,6 @@
 package main
 
 import (
-	"bufio"
 	"context"
 	"flag"
 	"fmt"
@@ -17,6 +16,7 @@ import (
 	"github.com/doITmagic/rag-code-mcp/internal/config"
 	"github.com/doITmagic/rag-code-mcp/internal/llm"
 	"github.com/doITmagic/rag-code-mcp/internal/memory"
+	"github.com/doITmagic/rag-code-mcp/internal/ragcode"
 	"github.com/doITmagic/rag-code-mcp/internal/storage"
 	"github.com/doITmagic/rag-code-mcp/internal/workspace"
 )
@@ -27,11 +27,13 @@ func main() {
 		model      = flag.String("model", "", "Embedding model id (overrides config; empty = use config)")
 		codeColl   = flag.String("code-collection", "", "Qdrant collection name for code (default: rag_code.collection)")
 		docsColl   = flag.String("docs-collection", "", "Qdrant collection name for docs (default: docs.collection)")
-		dim        = flag.Int("dim", 768, "Vector dimension for collections (depends on model)")
+		dim        = flag.Int("dim", 0, "Vector dimension for collections (0 = detected from the embedding model)")
 		timeoutSec = flag.Int("timeout", 300, "Indexing timeout in seconds")
 		configPath = flag.String("config", "config.yaml", "Path to config.yaml to read settings")
 		sourceDocs = flag.String("docs-source", "docs", "Source tag for docs metadata")
 		recreate   = flag.Bool("recreate-collections", false, "If set, delete and recreate code/docs collections before indexing (DANGEROUS)")
+		deleteIdx  = flag.String("delete-index", "", "Delete the index (collections and .ragcode state) of the workspace containing this path and exit")
+		deleteLang = flag.String("delete-language", "", "With -delete-index, only delete the index of this language")
 	)
 	flag.Parse()
 
@@ -43,6 +45,11 @@ func main() {
 		log.Fatalf("load config: %v", err)
 	}
 
+	if *deleteIdx != "" {
+		deleteWorkspaceIndex(ctx, cfg, *deleteIdx, *deleteLang)
+		return
+	}
+
 	codeCollection := cfg.RagCode.Collection
 	if codeCollection == "" {
 		if cfg.Storage.VectorDB.Collection != "" {
@@ -75,36 +82,46 @@ func main() {
 	if llmCfg.OllamaModel == "" && llmCfg.Model != "" {
 		llmCfg.OllamaModel = llmCfg.Model
 	}
-	if *model != "" {
-		llmCfg.OllamaEmbed = *model
-	} else if cfg.RagCode.Model != "" {
-		llmCfg.OllamaEmbed = cfg.RagCode.Model
+	embedModel := *model
+	if embedModel == "" {
+		embedModel = cfg.RagCode.Model
 	}
-	if llmCfg.OllamaEmbed == "" {
-		llmCfg.OllamaEmbed = cfg.LLM.OllamaEmbed
+	if llmCfg.Provider == "openai" {
+		if embedModel != "" {
+			llmCfg.OpenAIEmbed = embedModel
+		}
+		embedModel = llmCfg.OpenAIEmbed
+	} else {
+		llmCfg.Provider = "ollama"
+		if embedModel != "" {
+			llmCfg.OllamaEmbed = embedModel
+		}
+		embedModel = llmCfg.OllamaEmbed
 	}
-	llmCfg.Provider = "ollama"
 
 	//fmt.Printf("ℹ️ config: %+v\n", llmCfg)
 
-	provider, err := llm.NewOllamaLLMProvider(llmCfg)
+	provider, err := llm.NewProvider(&llmCfg)
 	if err != nil {
-		log.Fatalf("ollama provider: %v", err)
+		log.Fatalf("%s provider: %v", llmCfg.Provider, err)
 	}
-
-	qcfgCode := storage.QdrantConfig{
-		URL:        cfg.Storage.VectorDB.URL,
-		APIKey:     cfg.Storage.VectorDB.APIKey,
-		Collection: codeCollection,
+	if *dim == 0 {
+		if *dim, err = llm.EmbeddingDimension(ctx, provider); err != nil {
+			log.Fatalf("embedding dimension: %v", err)
+		}
+		log.Printf("ℹ️ Embedding dimension: %d", *dim)
 	}
+
 	// Wait for Qdrant gRPC to become available (default port 6334)
-	if err := waitForQdrantGRPC(cfg.Storage.VectorDB.URL, 30*time.Second); err != nil {
-		log.Fatalf("qdrant grpc port did not become available in time: %v", err)
+	if cfg.Storage.VectorDB.Provider != "local" {
+		if err := waitForQdrantGRPC(cfg.Storage.VectorDB.URL, 30*time.Second); err != nil {
+			log.Fatalf("qdrant grpc port did not become available in time: %v", err)
+		}
 	}
 
-	qclientCode, err := storage.NewQdrantClient(qcfgCode)
+	qclientCode, err := storage.Open(cfg.Storage.VectorDB, codeCollection)
 	if err != nil {
-		log.Fatalf("qdrant code client: %v", err)
+		log.Fatalf("code collection client: %v", err)
 	}
 	defer qclientCode.Close()
 
@@ -154,15 +171,9 @@ func main() {
 	if docsCollection == "" {
 		fmt.Println("ℹ️ docs.collection is empty, skipping docs indexing")
 	} else {
-		qcfgDocs := storage.QdrantConfig{
-			URL:        cfg.Storage.VectorDB.URL,
-			APIKey:     cfg.Storage.VectorDB.APIKey,
-			Collection: docsCollection,
-		}
-
-		qclientDocs, err := storage.NewQdrantClient(qcfgDocs)
+		qclientDocs, err := storage.Open(cfg.Storage.VectorDB, docsCollection)
 		if err != nil {
-			log.Fatalf("qdrant docs client: %v", err)
+			log.Fatalf("docs collection client: %v", err)
 		}
 		defer qclientDocs.Close()
 
@@ -177,7 +188,7 @@ func main() {
 			log.Fatalf("create docs collection: %v", err)
 		}
 
-		ltmDocs = storage.NewQdrantLongTermMemory(qclientDocs)
+		ltmDocs = storage.NewVectorStoreMemory(qclientDocs)
 		var _ memory.LongTermMemory = ltmDocs
 
 		readmePath := cfg.Docs.ReadmePath
@@ -213,11 +224,11 @@ func main() {
 		if len(docFiles) == 0 {
 			fmt.Println("ℹ️ no markdown files found for docs indexing")
 		} else {
-			fmt.Printf("📚 Indexing %d docs file(s) into docs collection '%s' (model=%s, dim=%d) ...\n", len(docFiles), docsCollection, llmCfg.OllamaEmbed, *dim)
+			fmt.Printf("📚 Indexing %d docs file(s) into docs collection '%s' (model=%s, dim=%d) ...\n", len(docFiles), docsCollection, embedModel, *dim)
 
 			indexedDocs := 0
 			for _, path := range docFiles {
-				if err := indexMarkdownFile(ctx, provider, ltmDocs, path, *sourceDocs); err != nil {
+				if err := indexMarkdownFile(ctx, provider, ltmDocs, path, *sourceDocs, cfg.Docs); err != nil {
 					log.Fatalf("docs indexing failed for %s after %d file(s): %v", path, indexedDocs, err)
 				}
 				indexedDocs++
@@ -229,6 +240,32 @@ func main() {
 
 }
 
+// deleteWorkspaceIndex deletes the index of the workspace containing path,
+// or of one of its languages; it needs the vector store but no LLM
+func deleteWorkspaceIndex(ctx context.Context, cfg *config.Config, path, language string) {
+	mgr := workspace.NewManager(nil, nil, cfg)
+	info, err := mgr.DetectWorkspace(map[string]interface{}{"file_path": path})
+	if err != nil {
+		log.Fatalf("detect workspace: %v", err)
+	}
+	report, err := mgr.DeleteIndex(ctx, info, language, false)
+	if err != nil {
+		log.Fatalf("delete index: %v", err)
+	}
+	if len(report.Collections) == 0 {
+		fmt.Printf("ℹ️ No collections found for '%s'\n", report.Root)
+	}
+	for _, c := range report.Collections {
+		fmt.Printf("🗑️ Deleted collection %s\n", c)
+	}
+	for _, f := range report.StateFiles {
+		fmt.Printf("🗑️ Removed %s\n", f)
+	}
+	if report.StateEntries > 0 {
+		fmt.Printf("🗑️ Dropped %d file(s) from the workspace state\n", report.StateEntries)
+	}
+}
+
 func splitCSV(s string) []string {
 	parts := strings.Split(s, ",")
 	out := make([]string, 0, len(parts))
@@ -274,52 +311,30 @@ func waitForQdrantGRPC(baseURL string, timeout time.Duration) error {
 	return fmt.Errorf("timed out waiting for qdrant grpc at %s", grpcHost)
 }
 
-func indexMarkdownFile(ctx context.Context, provider llm.Provider, ltm memory.LongTermMemory, path string, source string) error {
-	f, err := os.Open(path)
+// indexMarkdownFile indexes a markdown file in chunks that follow its
+// headings, recording their breadcrumb under "headings"
+func indexMarkdownFile(ctx context.Context, provider llm.Provider, ltm memory.LongTermMemory, path string, source string, docs config.DocsConfig) error {
+	data, err := os.ReadFile(path)
 	if err != nil {
-		return fmt.Errorf("open %s: %w", path, err)
+		return fmt.Errorf("read %s: %w", path, err)
 	}
-	defer f.Close()
-
-	scanner := bufio.NewScanner(f)
-	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
-
-	var (
-		chunks   []string
-		current  strings.Builder
-		maxChars = 1000
-	)
-
-	flushChunk := func() {
-		text := strings.TrimSpace(current.String())
-		if text != "" {
-			chunks = append(chunks, text)
+	fm, body := ragcode.ParseFrontMatter(string(data), ragcode.FrontMatterOptions(docs.FrontMatter))
+	chunks := ragcode.ChunkMarkdown(body, docs.ChunkMaxTokens, docs.ChunkOverlap)
+	var title, fields string
+	if fm != nil {
+		for i := range chunks {
+			chunks[i].StartLine += fm.Lines
+			chunks[i].EndLine += fm.Lines
 		}
-		current.Reset()
+		title, fields = fm.Title, fm.FieldsText()
 	}
+	tags := fm.WithTags(nil)
 
-	for scanner.Scan() {
-		line := scanner.Text()
-		if strings.TrimSpace(line) == "" && current.Len() > 0 {
-			flushChunk()
-			continue
-		}
-
-		if current.Len()+len(line)+1 > maxChars {
-			flushChunk()
-		}
-		if current.Len() > 0 {
-			current.WriteString("\n")
-		}
-		current.WriteString(line)
-	}
-	if err := scanner.Err(); err != nil {
-		return fmt.Errorf("scan %s: %w", path, err)
-	}
-	flushChunk()
+	lang := ragcode.DetectDocLanguage(path, string(data))
 
-	for i, text := range chunks {
-		emb, err := provider.Embed(ctx, text)
+	for i, chunk := range chunks {
+		breadcrumb := chunk.Breadcrumb()
+		emb, err := provider.Embed(ctx, ragcode.MarkdownEmbeddingText(chunk.Text, ragcode.TitledBreadcrumb(title, breadcrumb)))
 		if err != nil {
 			return fmt.Errorf("embed failed for %s chunk %d: %w", path, i, err)
 		}
@@ -330,14 +345,31 @@ func indexMarkdownFile(ctx context.Context, provider llm.Provider, ltm memory.Lo
 
 		doc := memory.Document{
 			ID:        id,
-			Content:   text,
+			Content:   chunk.Text,
 			Embedding: emb,
 			Metadata: map[string]interface{}{
-				"file":     path,
-				"chunk_id": i,
-				"source":   source,
+				"file":       path,
+				"chunk_id":   i,
+				"source":     source,
+				"start_line": chunk.StartLine,
+				"end_line":   chunk.EndLine,
 			},
 		}
+		if breadcrumb != "" {
+			doc.Metadata["headings"] = breadcrumb
+		}
+		if title != "" {
+			doc.Metadata["title"] = title
+		}
+		if fields != "" {
+			doc.Metadata["front_matter"] = fields
+		}
+		if lang != "" {
+			doc.Metadata["lang"] = lang
+		}
+		if len(tags) > 0 {
+			doc.Metadata["tags"] = strings.Join(tags, ",")
+		}
 
 		if err := ltm.Store(ctx, doc); err != nil {
 			return fmt.Errorf("store failed for %s: %w", id, err)
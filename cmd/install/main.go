@@ -69,6 +69,7 @@ func installRuntimeBinaries() {
 	}{
 		{"rag-code-mcp", "./cmd/rag-code-mcp"},
 		{"index-all", "./cmd/index-all"},
+		{"export-embeddings", "./cmd/export-embeddings"},
 	}
 
 	// Check which binaries are missing from the install directory
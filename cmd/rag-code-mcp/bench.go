@@ -0,0 +1,119 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"text/tabwriter"
+	"time"
+
+	"github.com/doITmagic/rag-code-mcp/internal/bench"
+	"github.com/doITmagic/rag-code-mcp/internal/config"
+	"github.com/doITmagic/rag-code-mcp/internal/llm"
+	"github.com/doITmagic/rag-code-mcp/internal/storage"
+)
+
+// runBench implements `rag-code-mcp bench [--files N] [--queries N] [--offline]`:
+// it indexes a synthetic repository into a temporary collection and reports
+// chunks/sec, embeddings/sec, upserts/sec and search latency. With --offline
+// it embeds with word hashes into a local store, without Ollama or Qdrant.
+func runBench(args []string, stdout, stderr io.Writer) int {
+	set := flag.NewFlagSet("bench", flag.ContinueOnError)
+	set.SetOutput(stderr)
+	configPath := set.String("config", "config.yaml", "Path to configuration file (embedding provider and vector store)")
+	files := set.Int("files", 200, "Source files of the synthetic repository")
+	queries := set.Int("queries", 200, "Searches timed for the latency percentiles")
+	batch := set.Int("batch", 0, "Texts per embedding request (default: rag_code.embed_batch_size)")
+	offline := set.Bool("offline", false, "Embed with word hashes into a temporary local store: no Ollama, no Qdrant")
+	format := set.String("format", "table", "Output format: table or json")
+	set.Usage = func() {
+		fmt.Fprintf(stderr, "Usage: rag-code-mcp bench [--files N] [--queries N] [--offline] [--format json|table]\n\n")
+		set.PrintDefaults()
+	}
+	if err := set.Parse(args); err != nil {
+		return 2
+	}
+	if set.NArg() > 0 || *files <= 0 || *queries <= 0 {
+		set.Usage()
+		return 2
+	}
+	if *format != "table" && *format != "json" {
+		fmt.Fprintf(stderr, "Error: unknown format %q: use table or json\n", *format)
+		return 2
+	}
+
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return 1
+	}
+	if *batch == 0 {
+		*batch = cfg.RagCode.EmbedBatchSize
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	collection := fmt.Sprintf("ragcode-bench-%d", time.Now().UnixNano())
+	opts := bench.Options{Files: *files, Queries: *queries, BatchSize: *batch, Collection: collection}
+	var store storage.VectorStore
+	if *offline {
+		dir, err := os.MkdirTemp("", "ragcode-bench-store-")
+		if err != nil {
+			fmt.Fprintf(stderr, "Error: %v\n", err)
+			return 1
+		}
+		defer os.RemoveAll(dir)
+		opts.Embedder = bench.NewHashEmbedder(384)
+		store, err = storage.NewLocalStore(storage.LocalConfig{Dir: dir, Collection: collection})
+		if err != nil {
+			fmt.Fprintf(stderr, "Error: %v\n", err)
+			return 1
+		}
+	} else {
+		if opts.Embedder, err = llm.NewProvider(&cfg.LLM); err != nil {
+			fmt.Fprintf(stderr, "Error: %s provider: %v\n", cfg.LLM.Provider, err)
+			return 1
+		}
+		if store, err = storage.Open(cfg.Storage.VectorDB, collection); err != nil {
+			fmt.Fprintf(stderr, "Error: vector store: %v\n", err)
+			return 1
+		}
+	}
+	defer store.Close()
+	opts.Store = store
+
+	res, err := bench.Run(ctx, opts)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	if *format == "json" {
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(res); err != nil {
+			fmt.Fprintf(stderr, "Error: encode: %v\n", err)
+			return 1
+		}
+		return 0
+	}
+	storeName := cfg.Storage.VectorDB.Provider
+	if *offline {
+		storeName = "local (temporary)"
+	} else if storeName == "" {
+		storeName = "qdrant"
+	}
+	tw := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "Synthetic repository\t%d files, %d chunks\n", res.Files, res.Chunks)
+	fmt.Fprintf(tw, "Analysis\t%.0f chunks/s\n", res.ChunksPerSec)
+	fmt.Fprintf(tw, "Embedding (%s, %d dims)\t%.1f embeddings/s\n", res.Embedder, res.Dimension, res.EmbeddingsPerSec)
+	fmt.Fprintf(tw, "Upsert (%s)\t%.0f points/s\n", storeName, res.UpsertsPerSec)
+	fmt.Fprintf(tw, "Search (%d queries)\tmean %.2fms, p50 %.2fms, p95 %.2fms\n", res.Queries, res.SearchMeanMs, res.SearchP50Ms, res.SearchP95Ms)
+	tw.Flush()
+	return 0
+}
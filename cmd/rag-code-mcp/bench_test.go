@@ -0,0 +1,30 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"github.com/doITmagic/rag-code-mcp/internal/bench"
+)
+
+func TestRunBenchOffline(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	args := []string{"--offline", "--files", "5", "--queries", "10", "--format", "json",
+		"--config", filepath.Join(t.TempDir(), "none.yaml")}
+	if code := runBench(args, &stdout, &stderr); code != 0 {
+		t.Fatalf("exit %d: %s", code, stderr.String())
+	}
+	var res bench.Result
+	if err := json.Unmarshal(stdout.Bytes(), &res); err != nil {
+		t.Fatalf("json output: %v", err)
+	}
+	if res.Files != 5 || res.Chunks == 0 || res.Queries != 10 || res.Embedder != "hash" {
+		t.Errorf("result = %+v", res)
+	}
+
+	if code := runBench([]string{"--format", "yaml"}, &stdout, &stderr); code != 2 {
+		t.Errorf("unknown format: exit %d, want 2", code)
+	}
+}
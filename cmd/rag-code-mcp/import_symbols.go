@@ -0,0 +1,117 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/doITmagic/rag-code-mcp/internal/config"
+	"github.com/doITmagic/rag-code-mcp/internal/symbolimport"
+	"github.com/doITmagic/rag-code-mcp/internal/workspace"
+)
+
+// runImportSymbols implements `rag-code-mcp import-symbols <path> --from
+// ARTIFACT [--type ctags|lsif|scip] [--replace] [--dry-run]`: it seeds the
+// symbol table of the workspace containing path with the definitions of a
+// ctags, LSIF or SCIP artifact built in CI, so structural tools work before
+// the workspace is indexed. It needs neither the vector store nor the
+// embedding model.
+func runImportSymbols(args []string, stdout, stderr io.Writer) int {
+	set := flag.NewFlagSet("import-symbols", flag.ContinueOnError)
+	set.SetOutput(stderr)
+	configPath := set.String("config", "config.yaml", "Path to configuration file (workspace detection)")
+	from := set.String("from", "", "Artifact to import: a ctags tags or JSON file, an LSIF dump or a SCIP index")
+	kind := set.String("type", "auto", "Artifact format: auto, ctags, lsif or scip")
+	replace := set.Bool("replace", false, "Replace the symbols of files the table already holds")
+	dryRun := set.Bool("dry-run", false, "Report what would be imported without writing the symbol table")
+	format := set.String("format", "table", "Output format: table or json")
+	set.Usage = func() {
+		fmt.Fprintf(stderr, "Usage: rag-code-mcp import-symbols <path> --from ARTIFACT [--type auto|ctags|lsif|scip] [--replace] [--dry-run] [--format json|table]\n\n")
+		set.PrintDefaults()
+	}
+
+	// Flags may come before or after the path
+	var paths []string
+	for {
+		if err := set.Parse(args); err != nil {
+			return 2
+		}
+		if set.NArg() == 0 {
+			break
+		}
+		paths = append(paths, set.Arg(0))
+		args = set.Args()[1:]
+	}
+	if len(paths) != 1 || *from == "" {
+		set.Usage()
+		return 2
+	}
+	if *format != "table" && *format != "json" {
+		fmt.Fprintf(stderr, "Error: unknown format %q: use table or json\n", *format)
+		return 2
+	}
+	artifactFormat, err := symbolimport.ParseFormat(*kind)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return 2
+	}
+	path, err := filepath.Abs(paths[0])
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return 1
+	}
+	artifact, err := filepath.Abs(*from)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return 1
+	}
+	wm := workspace.NewManager(nil, nil, cfg)
+	info, err := wm.DetectWorkspace(map[string]interface{}{"file_path": path})
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return 1
+	}
+	report, err := wm.ImportSymbols(info, artifact, artifactFormat, *replace, *dryRun)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	if *format == "json" {
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(report); err != nil {
+			fmt.Fprintf(stderr, "Error: encode: %v\n", err)
+			return 1
+		}
+		return 0
+	}
+	languages := make([]string, 0, len(report.Language))
+	for lang := range report.Language {
+		languages = append(languages, lang)
+	}
+	sort.Strings(languages)
+	tw := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "LANGUAGE\tSYMBOLS")
+	for _, lang := range languages {
+		fmt.Fprintf(tw, "%s\t%d\n", lang, report.Language[lang])
+	}
+	tw.Flush()
+	verb := "imported"
+	if report.DryRun {
+		verb = "would import"
+	}
+	fmt.Fprintf(stderr, "%s %d symbol(s) of %d file(s) from %s (%s) into %s; %d file(s) kept, %d not indexed, %d definition(s) outside the workspace\n",
+		verb, report.Symbols, report.Files, report.Artifact, report.Format, report.Root, report.Kept, report.Ignored, report.Outside)
+	return 0
+}
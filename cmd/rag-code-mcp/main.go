@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"io"
 	"log"
+	"net"
 	"net/url"
 	"os"
 	"os/signal"
@@ -16,8 +17,10 @@ import (
 	"time"
 
 	"github.com/doITmagic/rag-code-mcp/internal/config"
+	"github.com/doITmagic/rag-code-mcp/internal/grpcapi"
 	"github.com/doITmagic/rag-code-mcp/internal/healthcheck"
 	"github.com/doITmagic/rag-code-mcp/internal/llm"
+	"github.com/doITmagic/rag-code-mcp/internal/notify"
 	"github.com/doITmagic/rag-code-mcp/internal/storage"
 	"github.com/doITmagic/rag-code-mcp/internal/tools"
 	"github.com/doITmagic/rag-code-mcp/internal/workspace"
@@ -78,6 +81,14 @@ func (l *simpleLogger) Warn(format string, args ...interface{}) {
 
 var logger = &simpleLogger{}
 
+// usageManager records finished tool calls in the workspace usage statistics
+// (queries.usage). It is set once the workspace manager exists.
+var usageManager *workspace.Manager
+
+// toolSelection decides which tools are registered (tools.profile,
+// tools.enabled, tools.disabled). Every tool is registered while it is nil.
+var toolSelection *tools.ToolSelection
+
 func resolveLogPath(path string) (string, error) {
 	if path == "" {
 		return "", nil
@@ -268,9 +279,22 @@ type MCPTool interface {
 
 // SearchCodeInput defines the typed input for the search_code tool.
 type SearchCodeInput struct {
-	Query    string `json:"query"`
-	Limit    int    `json:"limit,omitempty"`
-	FilePath string `json:"file_path,omitempty"`
+	Query            string   `json:"query"`
+	Limit            int      `json:"limit,omitempty"`
+	ContextWindow    int      `json:"context_window,omitempty"`
+	FilePath         string   `json:"file_path,omitempty"`
+	MaxCoverage      *float64 `json:"max_coverage,omitempty"`
+	SortBy           string   `json:"sort_by,omitempty"`
+	PreferRecent     *bool    `json:"prefer_recent,omitempty"`
+	Tags             string   `json:"tags,omitempty"`
+	GOOS             string   `json:"goos,omitempty"`
+	GOARCH           string   `json:"goarch,omitempty"`
+	BuildTags        string   `json:"build_tags,omitempty"`
+	ConversationHint string   `json:"conversation_hint,omitempty"`
+	OutputFormat     string   `json:"output_format,omitempty"`
+	GroupBy          string   `json:"group_by,omitempty"`
+	PageSize         int      `json:"page_size,omitempty"`
+	Cursor           string   `json:"cursor,omitempty"`
 }
 
 // SearchCodeOutput defines the typed output for the search_code tool.
@@ -317,6 +341,8 @@ workspace:
   enabled: true
   auto_index: true
   max_workspaces: 10
+  idle_timeout: 30m
+  tombstone_grace: 10m
   detection_markers:
     - .git
     - go.mod
@@ -330,6 +356,8 @@ workspace:
     - build.gradle
     - Gemfile
     - Package.swift
+    - "*.sln"
+    - "*.csproj"
   exclude_patterns:
     - node_modules
     - .git
@@ -341,6 +369,22 @@ workspace:
   collection_prefix: ragcode
   index_include: []
   index_exclude: []
+
+# Search result ranking (shared by search_code, hybrid_search and search_docs)
+ranking:
+  vector_weight: 0.6        # semantic similarity
+  keyword_weight: 0.4       # query terms found in the code
+  exact_name_bonus: 0.15    # query names the symbol
+  recency_weight: 0         # recently modified files (0 = disabled)
+  recency_half_life_days: 30
+  proximity_weight: 0.1     # results near the file_path of the query
+  test_penalty: 0.85        # score multipliers, 1 = no penalty
+  generated_penalty: 0.6
+  deprecated_penalty: 0.7
+
+# Documentation search
+docs:
+  languages: []             # preferred doc languages for search_docs, e.g. [en, zh]
 `
 
 	// Ensure directory exists
@@ -373,6 +417,26 @@ func main() {
 	fmt.Fprintf(f, "Args: %v\n", os.Args)
 	f.Close()
 
+	// Subcommands run without the server configuration
+	if len(os.Args) > 1 && os.Args[1] == "scan" {
+		os.Exit(runScan(os.Args[2:], os.Stdout, os.Stderr))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "bench" {
+		os.Exit(runBench(os.Args[2:], os.Stdout, os.Stderr))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "reembed" {
+		os.Exit(runReembed(os.Args[2:], os.Stdout, os.Stderr))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		os.Exit(runMigrate(os.Args[2:], os.Stdout, os.Stderr))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "selftest" {
+		os.Exit(runSelftest(os.Args[2:], os.Stdout, os.Stderr))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "import-symbols" {
+		os.Exit(runImportSymbols(os.Args[2:], os.Stdout, os.Stderr))
+	}
+
 	// Define flags
 	configPath := flag.String("config", "config.yaml", "Path to configuration file")
 	ollamaBaseURLFlag := flag.String("ollama-base-url", "", "Ollama base URL (overrides config/env)")
@@ -381,12 +445,24 @@ func main() {
 	qdrantURLFlag := flag.String("qdrant-url", "", "Qdrant URL (overrides config/env)")
 	versionFlag := flag.Bool("version", false, "Print version information and exit")
 	healthFlag := flag.Bool("health", false, "Run health check and exit")
+	usageReportFlag := flag.String("usage-report", "", "Print the usage report of the workspace containing this path and exit")
+	usageDaysFlag := flag.Int("usage-days", 7, "Days covered by -usage-report")
+	transportFlag := flag.String("transport", "", "MCP transport: stdio, http (streamable HTTP at /mcp) or sse (server-sent events at /sse); default stdio, or http with -listen")
+	listenFlag := flag.String("listen", "", "Serve MCP over HTTP on this address (e.g. :8080) instead of stdio, with the REST API at /v1")
+	tokenFlag := flag.String("token", "", "Bearer token required by the HTTP and gRPC endpoints (overrides config/env)")
+	grpcListenFlag := flag.String("grpc-listen", "", "Serve the gRPC API (api/ragcode/v1) on this address (e.g. :9090); without -listen, instead of stdio")
 
 	// Custom usage message
 	flag.Usage = printUsage
 
 	flag.Parse()
 
+	transport, err := resolveTransport(*transportFlag, *listenFlag)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(2)
+	}
+
 	// Resolve config path
 	cfgPath := *configPath
 	if cfgPath == "config.yaml" {
@@ -457,6 +533,9 @@ func main() {
 	if *qdrantURLFlag != "" {
 		cfg.Storage.VectorDB.URL = *qdrantURLFlag
 	}
+	if *tokenFlag != "" {
+		cfg.Server.APIToken = *tokenFlag
+	}
 
 	// Set defaults
 	if cfg.LLM.OllamaBaseURL == "" {
@@ -466,9 +545,23 @@ func main() {
 		cfg.Storage.VectorDB.URL = "http://localhost:6333"
 	}
 
+	// Handle usage report flag: reads .ragcode/usage.json, needs no services
+	if *usageReportFlag != "" {
+		info, err := workspace.NewDetector().DetectFromPath(*usageReportFlag)
+		if err != nil {
+			log.Fatalf("Failed to detect workspace: %v", err)
+		}
+		report, err := workspace.BuildUsageReport(info.Root, *usageDaysFlag, 10, time.Now())
+		if err != nil {
+			log.Fatalf("Failed to load usage statistics: %v", err)
+		}
+		fmt.Print(tools.FormatUsageReport(info.Root, report))
+		os.Exit(0)
+	}
+
 	// Handle health check flag
 	if *healthFlag {
-		results := healthcheck.CheckAll(cfg.LLM.OllamaBaseURL, cfg.Storage.VectorDB.URL)
+		results := checkDependencies(cfg)
 		fmt.Fprint(os.Stderr, healthcheck.FormatResults(results))
 
 		allHealthy := true
@@ -488,7 +581,7 @@ func main() {
 
 	// Run health check on startup (non-fatal)
 	logger.Info("Checking dependencies...")
-	results := healthcheck.CheckAll(cfg.LLM.OllamaBaseURL, cfg.Storage.VectorDB.URL)
+	results := checkDependencies(cfg)
 
 	hasErrors := false
 	for _, result := range results {
@@ -505,74 +598,162 @@ func main() {
 		log.Fatal("Dependency check failed. Please fix the issues above and try again.")
 	}
 
-	embeddingModel := "nomic-embed-text"
-	if cfg.LLM.OllamaEmbed != "" {
-		embeddingModel = cfg.LLM.OllamaEmbed
-	}
-
 	llmCfg := cfg.LLM
-	if llmCfg.OllamaBaseURL == "" {
-		llmCfg.OllamaBaseURL = "http://localhost:11434"
+	if llmCfg.Provider == "" {
+		llmCfg.Provider = "ollama"
 	}
-	llmCfg.OllamaEmbed = embeddingModel
-	llmCfg.Provider = "ollama"
-
-	ollamaProvider, err := llm.NewOllamaLLMProvider(llmCfg)
-	if err != nil {
-		log.Fatalf("Failed to create Ollama provider: %v", err)
+	if llmCfg.Provider == "ollama" {
+		if llmCfg.OllamaBaseURL == "" {
+			llmCfg.OllamaBaseURL = "http://localhost:11434"
+		}
+		if llmCfg.OllamaEmbed == "" {
+			llmCfg.OllamaEmbed = "nomic-embed-text"
+		}
 	}
 
-	// Create base Qdrant config (no collection - multi-workspace manages collections)
-	qcfg := storage.QdrantConfig{
-		URL:    cfg.Storage.VectorDB.URL,
-		APIKey: cfg.Storage.VectorDB.APIKey,
+	llmProvider, err := llm.NewProvider(&llmCfg)
+	if err != nil {
+		log.Fatalf("Failed to create %s provider: %v", llmCfg.Provider, err)
 	}
 
-	// Create WorkspaceManager for multi-workspace support
-	qdrantClientForWorkspace, err := storage.NewQdrantClient(qcfg)
+	// Create WorkspaceManager for multi-workspace support (no collection -
+	// multi-workspace manages collections)
+	storeForWorkspace, err := storage.Open(cfg.Storage.VectorDB, "")
 	if err != nil {
-		log.Fatalf("Failed to create Qdrant client for workspace manager: %v", err)
+		log.Fatalf("Failed to create vector store for workspace manager: %v", err)
 	}
-	defer qdrantClientForWorkspace.Close()
+	defer storeForWorkspace.Close()
 
 	workspaceManager := workspace.NewManager(
-		qdrantClientForWorkspace,
-		ollamaProvider,
+		storeForWorkspace,
+		llmProvider,
 		cfg,
 	)
+	usageManager = workspaceManager
+	// Let background indexing checkpoint its state before the clients close
+	defer func() {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+		defer cancel()
+		if err := workspaceManager.Shutdown(shutdownCtx); err != nil {
+			logger.Warn("Shutdown: %v", err)
+		}
+	}()
+
+	// A/B testing: a second embedding model indexed into parallel collections
+	if cfg.LLM.ABEmbed != "" {
+		abCfg := llmCfg
+		abCfg.EmbedProvider = "" // model B is served by the provider, not the embed command
+		abCfg.ChatProvider = ""  // and only embeds
+		if abCfg.Provider == "openai" {
+			abCfg.OpenAIEmbed = cfg.LLM.ABEmbed
+		} else {
+			abCfg.OllamaEmbed = cfg.LLM.ABEmbed
+		}
+		abProvider, err := llm.NewProvider(&abCfg)
+		if err != nil {
+			log.Fatalf("Failed to create %s provider for llm.ab_embed: %v", abCfg.Provider, err)
+		}
+		workspaceManager.SetABEmbedder(abProvider, cfg.LLM.ABEmbed)
+		logger.Info("🆎 A/B embedding model: %s (compare with ab_search)", cfg.LLM.ABEmbed)
+	}
+
+	// Notifications: indexing results and dependency outages
+	notifier := notify.New(cfg.Notifications)
+	workspaceManager.SetNotifier(notifier)
+	if notifier != nil {
+		logger.Info("🔔 Notifications enabled for indexing and health events (health checked every %v)", cfg.Notifications.HealthInterval)
+	}
 
 	server := mcp.NewServer(&mcp.Implementation{
 		Name:    "ragcode",
 		Version: "1.1.16",
 	}, nil)
+	toolSelection = tools.NewToolSelection(cfg.Tools)
+	advertiseToolProfile(server, toolSelection)
 
 	// All tools use workspace manager - no single collections
-	searchTool := tools.NewSearchLocalIndexTool(nil, ollamaProvider)
+	searchTool := tools.NewSearchLocalIndexTool(nil, llmProvider)
 	searchTool.SetWorkspaceManager(workspaceManager)
 
-	getFunctionTool := tools.NewGetFunctionDetailsTool(nil, ollamaProvider)
+	getFunctionTool := tools.NewGetFunctionDetailsTool(nil, llmProvider)
 	getFunctionTool.SetWorkspaceManager(workspaceManager)
 
-	findTypeTool := tools.NewFindTypeDefinitionTool(nil, ollamaProvider)
+	findTypeTool := tools.NewFindTypeDefinitionTool(nil, llmProvider)
 	findTypeTool.SetWorkspaceManager(workspaceManager)
 
 	getContextTool := tools.NewGetCodeContextTool()
-	// getContextTool doesn't need workspace manager (reads files directly)
+	getContextTool.SetWorkspaceManager(workspaceManager)
 
-	listExportsTool := tools.NewListPackageExportsTool(nil, ollamaProvider)
+	listExportsTool := tools.NewListPackageExportsTool(nil, llmProvider)
 	listExportsTool.SetWorkspaceManager(workspaceManager)
 
-	findImplTool := tools.NewFindImplementationsTool(nil, ollamaProvider)
+	findImplTool := tools.NewFindImplementationsTool(nil, llmProvider)
 	findImplTool.SetWorkspaceManager(workspaceManager)
 
-	hybridTool := tools.NewHybridSearchTool(nil, ollamaProvider)
+	hybridTool := tools.NewHybridSearchTool(nil, llmProvider)
 	hybridTool.SetWorkspaceManager(workspaceManager)
 
-	searchDocsTool := tools.NewSearchDocsTool(nil, ollamaProvider)
+	searchDocsTool := tools.NewSearchDocsTool(nil, llmProvider)
 	searchDocsTool.SetWorkspaceManager(workspaceManager)
 
 	indexWorkspaceTool := tools.NewIndexWorkspaceTool(workspaceManager)
 
+	localizeBuildErrorTool := tools.NewLocalizeBuildErrorTool(nil, llmProvider)
+	localizeBuildErrorTool.SetWorkspaceManager(workspaceManager)
+
+	resolveStackTraceTool := tools.NewResolveStackTraceTool(nil, llmProvider)
+	resolveStackTraceTool.SetWorkspaceManager(workspaceManager)
+
+	findErrorOriginTool := tools.NewFindErrorOriginTool(workspaceManager)
+
+	loadCoverageTool := tools.NewLoadCoverageTool(workspaceManager)
+
+	suggestTestTargetsTool := tools.NewSuggestTestTargetsTool(workspaceManager)
+
+	diffAPISurfaceTool := tools.NewDiffAPISurfaceTool(workspaceManager)
+
+	listDeprecatedUsagesTool := tools.NewListDeprecatedUsagesTool(workspaceManager)
+
+	getSymbolsBulkTool := tools.NewGetSymbolsBulkTool(workspaceManager)
+
+	getChunkTool := tools.NewGetChunkTool(workspaceManager)
+
+	abSearchTool := tools.NewABSearchTool(workspaceManager, llmProvider)
+
+	grepWorkspaceTool := tools.NewGrepWorkspaceTool(workspaceManager)
+
+	structuralSearchTool := tools.NewStructuralSearchTool(workspaceManager)
+
+	suggestRewritesTool := tools.NewSuggestRewritesTool(workspaceManager)
+
+	applyPatchTool := tools.NewApplyPatchTool(workspaceManager)
+
+	rollbackChangeTool := tools.NewRollbackChangeTool(workspaceManager)
+
+	createFileFromTemplateTool := tools.NewCreateFileFromTemplateTool(workspaceManager)
+
+	editSessionTool := tools.NewEditSessionTool(workspaceManager)
+
+	getUsageReportTool := tools.NewGetUsageReportTool(workspaceManager)
+
+	findHookCallbacksTool := tools.NewFindHookCallbacksTool(workspaceManager)
+
+	setupWorkspaceTool := tools.NewSetupWorkspaceTool(workspaceManager)
+
+	reindexFileTool := tools.NewReindexFileTool(workspaceManager)
+	analyzeBufferTool := tools.NewAnalyzeBufferTool(workspaceManager)
+
+	getLanguageCoverageTool := tools.NewGetLanguageCoverageTool(workspaceManager)
+	indexStatusTool := tools.NewIndexStatusTool(workspaceManager)
+	cleanupWorkspacesTool := tools.NewCleanupWorkspacesTool(workspaceManager)
+	analyzeRenameImpactTool := tools.NewAnalyzeRenameImpactTool(workspaceManager)
+	deleteWorkspaceIndexTool := tools.NewDeleteWorkspaceIndexTool(workspaceManager)
+	getCallGraphTool := tools.NewGetCallGraphTool(workspaceManager)
+	getPackageDependenciesTool := tools.NewGetPackageDependenciesTool(workspaceManager)
+	findSimilarCodeTool := tools.NewFindSimilarCodeTool(workspaceManager, llmProvider)
+	findTestsForSymbolTool := tools.NewFindTestsForSymbolTool(workspaceManager)
+	reembedWorkspaceTool := tools.NewReembedWorkspaceTool(workspaceManager)
+
 	// Example: use typed ToolHandlerFor for search_code
 	registerSearchCodeToolTyped(server, searchTool)
 
@@ -585,19 +766,134 @@ func main() {
 	registerAgentTool(server, searchDocsTool)
 	registerAgentTool(server, hybridTool)
 	registerAgentTool(server, indexWorkspaceTool)
+	registerAgentTool(server, localizeBuildErrorTool)
+	registerAgentTool(server, resolveStackTraceTool)
+	registerAgentTool(server, findErrorOriginTool)
+	registerAgentTool(server, loadCoverageTool)
+	registerAgentTool(server, suggestTestTargetsTool)
+	registerAgentTool(server, diffAPISurfaceTool)
+	registerAgentTool(server, listDeprecatedUsagesTool)
+	registerAgentTool(server, getSymbolsBulkTool)
+	registerAgentTool(server, getChunkTool)
+	registerAgentTool(server, abSearchTool)
+	registerAgentTool(server, grepWorkspaceTool)
+	registerAgentTool(server, structuralSearchTool)
+	registerAgentTool(server, suggestRewritesTool)
+	// File changes are opt-in (edits.enabled)
+	if cfg.Edits.Enabled {
+		registerAgentTool(server, applyPatchTool)
+		registerAgentTool(server, rollbackChangeTool)
+		registerAgentTool(server, createFileFromTemplateTool)
+		registerAgentTool(server, editSessionTool)
+		logger.Info("✏️ apply_patch, rollback_change, create_file_from_template and edit_session enabled: the server can modify workspace files")
+	} else {
+		toolSelection.Omit(applyPatchTool.Name(), rollbackChangeTool.Name(), createFileFromTemplateTool.Name(), editSessionTool.Name())
+	}
+	registerAgentTool(server, getUsageReportTool)
+	registerAgentTool(server, findHookCallbacksTool)
+	registerAgentTool(server, setupWorkspaceTool)
+	registerAgentTool(server, reindexFileTool)
+	registerAgentTool(server, analyzeBufferTool)
+	registerAgentTool(server, getLanguageCoverageTool)
+	registerAgentTool(server, indexStatusTool)
+	registerAgentTool(server, cleanupWorkspacesTool)
+	registerAgentTool(server, analyzeRenameImpactTool)
+	registerAgentTool(server, deleteWorkspaceIndexTool)
+	registerAgentTool(server, getCallGraphTool)
+	registerAgentTool(server, getPackageDependenciesTool)
+	registerAgentTool(server, findSimilarCodeTool)
+	registerAgentTool(server, findTestsForSymbolTool)
+	registerAgentTool(server, reembedWorkspaceTool)
+	summary := toolSelection.Summary()
+	logger.Info("🧰 Tool profile '%s': %d tools registered, %d left out", summary.Profile, len(summary.Tools), len(summary.Disabled))
+	if unknown := toolSelection.Unknown(); len(unknown) > 0 {
+		logger.Warn("tools.enabled / tools.disabled name unknown tools: %s", strings.Join(unknown, ", "))
+	}
 
 	if err := registerFileResources(server); err != nil {
 		log.Fatalf("Failed to register resources: %v", err)
 	}
 
-	logger.Info("MCP RagCode Server started (stdio mode) - Multi-workspace enabled")
-	logger.Info("Embedding Model: %s", embeddingModel)
+	mode := "stdio mode"
+	if transport == transportHTTP {
+		mode = "HTTP mode"
+	} else if transport == transportSSE {
+		mode = "HTTP/SSE mode"
+	} else if *grpcListenFlag != "" {
+		mode = "gRPC mode"
+	}
+	logger.Info("MCP RagCode Server started (%s) - Multi-workspace enabled", mode)
+	embeddingModel := llmCfg.OllamaEmbed
+	if llmCfg.Provider == "openai" {
+		embeddingModel = llmCfg.OpenAIEmbed
+	}
+	logger.Info("Embedding Model: %s (%s)", embeddingModel, llmCfg.Provider)
 	logger.Info("Workspaces: auto-detected, collections created per workspace+language")
 
 	// Use a context that cancels on OS signals for graceful shutdown.
 	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer cancel()
 
+	if notifier != nil {
+		monitor := notify.NewHealthMonitor(notifier, func() []healthcheck.CheckResult {
+			return checkDependencies(cfg)
+		})
+		go monitor.Run(ctx, cfg.Notifications.HealthInterval)
+	}
+
+	// Unload workspaces unused for workspace.idle_timeout
+	go workspaceManager.RunIdleReaper(ctx)
+	// Delete workspaces unused for workspace.collection_ttl
+	go workspaceManager.RunGarbageCollector(ctx)
+
+	if *grpcListenFlag != "" {
+		lis, err := net.Listen("tcp", *grpcListenFlag)
+		if err != nil {
+			log.Fatalf("Failed to listen for gRPC: %v", err)
+		}
+		grpcServer := grpcapi.NewGRPCServer(grpcapi.NewServer(searchTool, getSymbolsBulkTool, workspaceManager), cfg.Server.APIToken)
+		go func() {
+			if err := grpcServer.Serve(lis); err != nil {
+				logger.Error("gRPC server stopped: %v", err)
+			}
+		}()
+		defer grpcServer.GracefulStop()
+		if cfg.Server.APIToken == "" {
+			logger.Warn("gRPC API served without authentication: set server.api_token (or RAGCODE_API_TOKEN)")
+		}
+		logger.Info("🔌 gRPC API listening on %s", lis.Addr())
+		if transport == transportStdio {
+			<-ctx.Done()
+			return
+		}
+	}
+
+	if transport != transportStdio {
+		api := &restAPI{search: searchTool, symbols: getSymbolsBulkTool, index: indexWorkspaceTool}
+		// The REST endpoints follow the tool profile
+		if !toolSelection.Allowed(searchTool.Name()) {
+			api.search = nil
+		}
+		if !toolSelection.Allowed(getSymbolsBulkTool.Name()) {
+			api.symbols = nil
+		}
+		if !toolSelection.Allowed(indexWorkspaceTool.Name()) {
+			api.index = nil
+		}
+		var hooks *webhookHandler
+		if cfg.Server.WebhookSecret != "" {
+			hooks = &webhookHandler{
+				secret: cfg.Server.WebhookSecret,
+				repos:  cfg.Server.WebhookRepos,
+				pull:   cfg.Server.WebhookPull,
+				wm:     workspaceManager,
+			}
+		}
+		if err := serveHTTP(ctx, *listenFlag, transport, cfg.Server.APIToken, server, api, hooks); err != nil {
+			log.Fatalf("Server terminated: %v", err)
+		}
+		return
+	}
 	if err := server.Run(ctx, &mcp.StdioTransport{}); err != nil {
 		log.Fatalf("Server terminated: %v", err)
 	}
@@ -605,7 +901,10 @@ func main() {
 
 // registerSearchCodeToolTyped registers the search_code tool using the typed
 // ToolHandlerFor API from the MCP Go SDK.
-func registerSearchCodeToolTyped(server *mcp.Server, tool *tools.SearchLocalIndexTool) {
+func registerSearchCodeToolTyped(server *mcp.Server, tool MCPTool) {
+	if toolSelection != nil && !toolSelection.Use(tool.Name()) {
+		return
+	}
 	mcp.AddTool[SearchCodeInput, SearchCodeOutput](server, &mcp.Tool{
 		Name:        tool.Name(),
 		Description: tool.Description(),
@@ -616,15 +915,56 @@ func registerSearchCodeToolTyped(server *mcp.Server, tool *tools.SearchLocalInde
 		if input.Limit > 0 {
 			args["limit"] = input.Limit
 		}
+		if input.ContextWindow > 0 {
+			args["context_window"] = input.ContextWindow
+		}
 		if input.FilePath != "" {
 			args["file_path"] = input.FilePath
 		}
+		if input.MaxCoverage != nil {
+			args["max_coverage"] = *input.MaxCoverage
+		}
+		if input.SortBy != "" {
+			args["sort_by"] = input.SortBy
+		}
+		if input.PreferRecent != nil {
+			args["prefer_recent"] = *input.PreferRecent
+		}
+		if input.Tags != "" {
+			args["tags"] = input.Tags
+		}
+		if input.GOOS != "" {
+			args["goos"] = input.GOOS
+		}
+		if input.GOARCH != "" {
+			args["goarch"] = input.GOARCH
+		}
+		if input.BuildTags != "" {
+			args["build_tags"] = input.BuildTags
+		}
+		if input.ConversationHint != "" {
+			args["conversation_hint"] = input.ConversationHint
+		}
+		if input.OutputFormat != "" {
+			args["output_format"] = input.OutputFormat
+		}
+		if input.GroupBy != "" {
+			args["group_by"] = input.GroupBy
+		}
+		if input.PageSize > 0 {
+			args["page_size"] = input.PageSize
+		}
+		if input.Cursor != "" {
+			args["cursor"] = input.Cursor
+		}
 
 		start := time.Now()
 		logger.Info("🛠️ Executing tool '%s' with args: %v", tool.Name(), args)
 
-		result, err := tool.Execute(ctx, args)
+		ctx, calls := llm.WithCallUsage(ctx)
+		result, err := tool.Execute(withClientSession(ctx, req.Session), args)
 		duration := time.Since(start)
+		tools.RecordToolCall(usageManager, tool.Name(), args, result, err, duration, calls.Totals())
 
 		if err != nil {
 			logger.Error("❌ Tool '%s' failed after %v: %v", tool.Name(), duration, err)
@@ -638,6 +978,9 @@ func registerSearchCodeToolTyped(server *mcp.Server, tool *tools.SearchLocalInde
 }
 
 func registerAgentTool(server *mcp.Server, tool MCPTool) {
+	if toolSelection != nil && !toolSelection.Use(tool.Name()) {
+		return
+	}
 	schema := getToolSchema(tool.Name())
 	server.AddTool(&mcp.Tool{
 		Name:        tool.Name(),
@@ -654,8 +997,10 @@ func registerAgentTool(server *mcp.Server, tool MCPTool) {
 		start := time.Now()
 		logger.Info("🛠️ Executing tool '%s' with args: %v", tool.Name(), args)
 
-		result, err := tool.Execute(ctx, args)
+		ctx, calls := llm.WithCallUsage(ctx)
+		result, err := tool.Execute(withClientSession(ctx, req.Session), args)
 		duration := time.Since(start)
+		tools.RecordToolCall(usageManager, tool.Name(), args, result, err, duration, calls.Totals())
 
 		if err != nil {
 			logger.Error("❌ Tool '%s' failed after %v: %v", tool.Name(), duration, err)
@@ -835,13 +1180,63 @@ func getToolSchema(toolName string) map[string]interface{} {
 					"type":        "string",
 					"description": "The search query to find relevant code",
 				},
+				"conversation_hint": map[string]interface{}{
+					"type":        "string",
+					"description": "Optional: the last user message, used to resolve references in short queries (e.g. 'that parser' -> ConfigParser)",
+				},
 				"file_path": map[string]interface{}{
 					"type":        "string",
 					"description": "Optional: file path to help detect workspace context",
 				},
 				"limit": map[string]interface{}{
 					"type":        "number",
-					"description": "Maximum number of results to return (default: 5)",
+					"description": "Maximum number of results to return (default: 5, or sized to the context window)",
+				},
+				"context_window": map[string]interface{}{
+					"type":        "number",
+					"description": "Optional: context window of your model in tokens; the default limit and snippet length are sized to it",
+				},
+				"group_by": map[string]interface{}{
+					"type":        "string",
+					"enum":        []string{"file", "package", "none"},
+					"description": "Optional: group results by file or package, best match of each group first; text output is grouped by file when most results share one file",
+				},
+				"max_coverage": map[string]interface{}{
+					"type":        "number",
+					"description": "Optional: only return results with test coverage at or below this percentage (requires load_coverage)",
+				},
+				"sort_by": map[string]interface{}{
+					"type":        "string",
+					"description": "Optional: 'relevance' (default) or 'coverage' to list least tested code first",
+					"enum":        []string{"relevance", "coverage"},
+				},
+				"prefer_recent": map[string]interface{}{
+					"type":        "boolean",
+					"description": "Optional: boost recently modified code (uses git blame when rag_code.git_blame is enabled, file modification time otherwise)",
+				},
+				"tags": map[string]interface{}{
+					"type":        "string",
+					"description": "Optional: only return code carrying all of these tags from rag_code.tag_rules (e.g. 'payment'); comma-separated for several",
+				},
+				"goos": map[string]interface{}{
+					"type":        "string",
+					"description": "Optional: Go only - return code built for this GOOS (e.g. 'windows'); files excluded by build constraints or _GOOS suffixes are dropped",
+				},
+				"goarch": map[string]interface{}{
+					"type":        "string",
+					"description": "Optional: Go only - return code built for this GOARCH (e.g. 'arm64')",
+				},
+				"build_tags": map[string]interface{}{
+					"type":        "string",
+					"description": "Optional: Go only - extra build tags that are set (e.g. 'integration,cgo'); code behind other custom tags is dropped when goos, goarch or build_tags is given",
+				},
+				"page_size": map[string]interface{}{
+					"type":        "number",
+					"description": "Optional: paginate - return this many results and a next_cursor; all pages come from the index as it was at the first page, even if a re-index lands in between",
+				},
+				"cursor": map[string]interface{}{
+					"type":        "string",
+					"description": "Optional: next_cursor of a previous page, to get the next page (the other parameters are ignored; cursors expire after 10 minutes)",
 				},
 			},
 			"required": []string{"query"},
@@ -863,6 +1258,11 @@ func getToolSchema(toolName string) map[string]interface{} {
 					"type":        "string",
 					"description": "Optional: filter by package path (e.g., 'internal/agents')",
 				},
+				"output_format": map[string]interface{}{
+					"type":        "string",
+					"description": "Optional: output format: 'markdown' (default), 'json' or 'minimal' (one line per result, for small-context models)",
+					"enum":        []string{"json", "markdown", "minimal"},
+				},
 			},
 			"required": []string{"function_name"},
 		}
@@ -883,6 +1283,11 @@ func getToolSchema(toolName string) map[string]interface{} {
 					"type":        "string",
 					"description": "Optional: filter by package path (e.g., 'internal/ragcode')",
 				},
+				"output_format": map[string]interface{}{
+					"type":        "string",
+					"description": "Optional: output format: 'markdown' (default), 'json' or 'minimal' (one line per result, for small-context models)",
+					"enum":        []string{"json", "markdown", "minimal"},
+				},
 			},
 			"required": []string{"type_name"},
 		}
@@ -927,6 +1332,11 @@ func getToolSchema(toolName string) map[string]interface{} {
 					"type":        "string",
 					"description": "Optional: filter by symbol type (function, method, type, const, var)",
 				},
+				"output_format": map[string]interface{}{
+					"type":        "string",
+					"description": "Optional: output format: 'markdown' (default), 'json' or 'minimal' (one line per result, for small-context models)",
+					"enum":        []string{"json", "markdown", "minimal"},
+				},
 			},
 			"required": []string{"package"},
 		}
@@ -959,13 +1369,38 @@ func getToolSchema(toolName string) map[string]interface{} {
 					"type":        "string",
 					"description": "The search query to find relevant documentation",
 				},
+				"conversation_hint": map[string]interface{}{
+					"type":        "string",
+					"description": "Optional: the last user message, used to resolve references in short queries (e.g. 'that parser' -> ConfigParser)",
+				},
 				"file_path": map[string]interface{}{
 					"type":        "string",
 					"description": "Optional: file path to help detect workspace context",
 				},
 				"limit": map[string]interface{}{
 					"type":        "number",
-					"description": "Maximum number of results to return (default: 5)",
+					"description": "Maximum number of results to return (default: 5, or sized to the context window)",
+				},
+				"context_window": map[string]interface{}{
+					"type":        "number",
+					"description": "Optional: context window of your model in tokens; the default limit and snippet length are sized to it",
+				},
+				"lang": map[string]interface{}{
+					"type":        "string",
+					"description": "Optional: only return documentation in this language (ISO 639-1, e.g. 'en', 'zh'); comma-separated for several",
+				},
+				"tags": map[string]interface{}{
+					"type":        "string",
+					"description": "Optional: only return documentation carrying all of these tags from rag_code.tag_rules; comma-separated for several",
+				},
+				"sources": map[string]interface{}{
+					"type":        "string",
+					"description": "Optional: only return documentation from these sources: 'readme' (workspace READMEs), 'docs' (other workspace docs), 'api' (api_docs.collection) or 'external' (docs.collection outside the workspace); comma-separated for several",
+				},
+				"output_format": map[string]interface{}{
+					"type":        "string",
+					"description": "Optional: output format: 'markdown' (default) or 'minimal' (one line per result, for small-context models)",
+					"enum":        []string{"markdown", "minimal"},
 				},
 			},
 			"required": []string{"query"},
@@ -983,6 +1418,15 @@ func getToolSchema(toolName string) map[string]interface{} {
 					"type":        "string",
 					"description": "Optional: specific language to index (e.g., 'go', 'python', 'php'). If not provided, all detected languages will be indexed.",
 				},
+				"since_ref": map[string]interface{}{
+					"type":        "string",
+					"description": "Optional: git commit, branch or tag; only files git reports as changed since it are re-indexed, instead of checking every file. 'last_indexed' uses the commit of the previous indexing run",
+				},
+				"output_format": map[string]interface{}{
+					"type":        "string",
+					"description": "Optional: 'json' (default) for a summary object (languages, files queued, estimated seconds, collections, skipped paths with reasons) or 'markdown'",
+					"enum":        []string{"json", "markdown"},
+				},
 			},
 			"required": []string{"file_path"},
 		}
@@ -995,51 +1439,1047 @@ func getToolSchema(toolName string) map[string]interface{} {
 					"type":        "string",
 					"description": "The search query combining lexical and semantic matching",
 				},
+				"conversation_hint": map[string]interface{}{
+					"type":        "string",
+					"description": "Optional: the last user message, used to resolve references in short queries (e.g. 'that parser' -> ConfigParser)",
+				},
 				"file_path": map[string]interface{}{
 					"type":        "string",
 					"description": "Optional: file path to help detect workspace context",
 				},
 				"limit": map[string]interface{}{
 					"type":        "number",
-					"description": "Maximum number of results to return (default: 5)",
+					"description": "Maximum number of results to return (default: 5, or sized to the context window)",
+				},
+				"context_window": map[string]interface{}{
+					"type":        "number",
+					"description": "Optional: context window of your model in tokens; the default limit and snippet length are sized to it",
+				},
+				"group_by": map[string]interface{}{
+					"type":        "string",
+					"enum":        []string{"file", "package", "none"},
+					"description": "Optional: group results by file or package, best match of each group first; text output is grouped by file when most results share one file",
+				},
+				"max_coverage": map[string]interface{}{
+					"type":        "number",
+					"description": "Optional: only return results with test coverage at or below this percentage (requires load_coverage)",
+				},
+				"sort_by": map[string]interface{}{
+					"type":        "string",
+					"description": "Optional: 'relevance' (default) or 'coverage' to list least tested code first",
+					"enum":        []string{"relevance", "coverage"},
+				},
+				"prefer_recent": map[string]interface{}{
+					"type":        "boolean",
+					"description": "Optional: boost recently modified code (uses git blame when rag_code.git_blame is enabled, file modification time otherwise)",
+				},
+				"tags": map[string]interface{}{
+					"type":        "string",
+					"description": "Optional: only return code carrying all of these tags from rag_code.tag_rules (e.g. 'payment'); comma-separated for several",
+				},
+				"goos": map[string]interface{}{
+					"type":        "string",
+					"description": "Optional: Go only - return code built for this GOOS (e.g. 'windows'); files excluded by build constraints or _GOOS suffixes are dropped",
+				},
+				"goarch": map[string]interface{}{
+					"type":        "string",
+					"description": "Optional: Go only - return code built for this GOARCH (e.g. 'arm64')",
+				},
+				"build_tags": map[string]interface{}{
+					"type":        "string",
+					"description": "Optional: Go only - extra build tags that are set (e.g. 'integration,cgo'); code behind other custom tags is dropped when goos, goarch or build_tags is given",
+				},
+				"page_size": map[string]interface{}{
+					"type":        "number",
+					"description": "Optional: paginate - return this many results and a next_cursor; all pages come from the index as it was at the first page, even if a re-index lands in between",
+				},
+				"cursor": map[string]interface{}{
+					"type":        "string",
+					"description": "Optional: next_cursor of a previous page, to get the next page (the other parameters are ignored; cursors expire after 10 minutes)",
+				},
+				"output_format": map[string]interface{}{
+					"type":        "string",
+					"description": "Optional: output format: 'json' (default), 'markdown' or 'minimal' (one line per result, for small-context models)",
+					"enum":        []string{"json", "markdown", "minimal"},
 				},
 			},
 			"required": []string{"query"},
 		}
 
-	default:
+	case "localize_build_error":
 		return map[string]interface{}{
-			"type":       "object",
-			"properties": map[string]interface{}{},
+			"type": "object",
+			"properties": map[string]interface{}{
+				"build_output": map[string]interface{}{
+					"type":        "string",
+					"description": "Raw output of go build/go vet, php -l or mypy",
+				},
+				"file_path": map[string]interface{}{
+					"type":        "string",
+					"description": "A file path within the workspace (used to detect workspace root and resolve relative paths)",
+				},
+				"max_errors": map[string]interface{}{
+					"type":        "number",
+					"description": "Maximum number of errors to localize (default: 10)",
+				},
+				"related_limit": map[string]interface{}{
+					"type":        "number",
+					"description": "Number of nearby chunks to include per error (default: 3)",
+				},
+				"output_format": map[string]interface{}{
+					"type":        "string",
+					"description": "Output format: 'json' (default), 'markdown' or 'minimal' (one line per result, for small-context models)",
+				},
+			},
+			"required": []string{"build_output", "file_path"},
 		}
-	}
-}
-
-func printUsage() {
-	fmt.Fprintf(os.Stderr, `RagCode MCP Server - Semantic code navigation for Go codebases
-
-USAGE:
-    rag-code-mcp [OPTIONS]
-
-EXAMPLES:
-    # Start with default configuration
-    rag-code-mcp
-
-    # Use custom config file
-    rag-code-mcp -config my-config.yaml
 
-    # Override Ollama and Qdrant URLs
-    rag-code-mcp -ollama-base-url http://remote:11434 -qdrant-url http://remote:6333
+	case "resolve_stack_trace":
+		return map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"stack_trace": map[string]interface{}{
+					"type":        "string",
+					"description": "Go panic output, PHP exception/Laravel log stack trace or Python traceback",
+				},
+				"file_path": map[string]interface{}{
+					"type":        "string",
+					"description": "A file path within the workspace (used to detect workspace root and remap frame paths)",
+				},
+				"max_frames": map[string]interface{}{
+					"type":        "number",
+					"description": "Maximum number of workspace frames to return code for (default: 5)",
+				},
+				"context_lines": map[string]interface{}{
+					"type":        "number",
+					"description": "Lines of context around each frame (default: 3)",
+				},
+				"output_format": map[string]interface{}{
+					"type":        "string",
+					"description": "Output format: 'json' (default), 'markdown' or 'minimal' (one line per result, for small-context models)",
+				},
+			},
+			"required": []string{"stack_trace", "file_path"},
+		}
 
-    # Check version
-    rag-code-mcp -version
+	case "find_error_origin":
+		return map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"message": map[string]interface{}{
+					"type":        "string",
+					"description": "Runtime log line, error message or log excerpt (one message per line)",
+				},
+				"file_path": map[string]interface{}{
+					"type":        "string",
+					"description": "Path to a file in the workspace (used to detect the workspace)",
+				},
+				"limit": map[string]interface{}{
+					"type":        "number",
+					"description": "Maximum call sites per log line (default: 5)",
+				},
+				"context_lines": map[string]interface{}{
+					"type":        "number",
+					"description": "Lines of source shown around the best match (default: 2)",
+				},
+				"output_format": map[string]interface{}{
+					"type":        "string",
+					"description": "Output format: 'json' (default), 'markdown' or 'minimal' (one line per result, for small-context models)",
+					"enum":        []string{"json", "markdown", "minimal"},
+				},
+			},
+			"required": []string{"message", "file_path"},
+		}
 
-    # Run health check only
-    rag-code-mcp -health
+	case "load_coverage":
+		return map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"coverage_file": map[string]interface{}{
+					"type":        "string",
+					"description": "Path to the coverage report (absolute or relative to the workspace root): Go coverprofile, clover.xml, coverage.py JSON or Cobertura XML",
+				},
+				"file_path": map[string]interface{}{
+					"type":        "string",
+					"description": "Path to a file in the workspace (used to detect the workspace)",
+				},
+				"output_format": map[string]interface{}{
+					"type":        "string",
+					"description": "Output format: 'json' (default), 'markdown' or 'minimal' (one line per result, for small-context models)",
+					"enum":        []string{"json", "markdown", "minimal"},
+				},
+			},
+			"required": []string{"coverage_file", "file_path"},
+		}
 
-OPTIONS:
-`)
-	flag.PrintDefaults()
+	case "suggest_test_targets":
+		return map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"file_path": map[string]interface{}{
+					"type":        "string",
+					"description": "Path to a file in the workspace (used to detect the workspace)",
+				},
+				"limit": map[string]interface{}{
+					"type":        "number",
+					"description": "Maximum number of targets to return (default: 10)",
+				},
+				"language": map[string]interface{}{
+					"type":        "string",
+					"description": "Optional: only suggest symbols of this language (go, php, python)",
+				},
+				"output_format": map[string]interface{}{
+					"type":        "string",
+					"description": "Output format: 'json' (default), 'markdown' or 'minimal' (one line per result, for small-context models)",
+					"enum":        []string{"json", "markdown", "minimal"},
+				},
+			},
+			"required": []string{"file_path"},
+		}
+
+	case "diff_api_surface":
+		return map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"file_path": map[string]interface{}{
+					"type":        "string",
+					"description": "Path to a file in the workspace (used to detect the workspace)",
+				},
+				"snapshot": map[string]interface{}{
+					"type":        "string",
+					"description": "Snapshot to diff against: 'previous' (default, the public API before the last index run) or a pinned snapshot name",
+				},
+				"pin_snapshot": map[string]interface{}{
+					"type":        "string",
+					"description": "Optional: save the current public API under this name instead of diffing (e.g. 'v1.2.0')",
+				},
+				"language": map[string]interface{}{
+					"type":        "string",
+					"description": "Optional: only compare symbols of this language (go, php, python)",
+				},
+				"output_format": map[string]interface{}{
+					"type":        "string",
+					"description": "Output format: 'json' (default), 'markdown' or 'minimal' (one line per result, for small-context models)",
+					"enum":        []string{"json", "markdown", "minimal"},
+				},
+			},
+			"required": []string{"file_path"},
+		}
+
+	case "list_deprecated_usages":
+		return map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"file_path": map[string]interface{}{
+					"type":        "string",
+					"description": "Path to a file in the workspace (used to detect the workspace)",
+				},
+				"symbol": map[string]interface{}{
+					"type":        "string",
+					"description": "Optional: only report usages of the deprecated symbol with this name",
+				},
+				"limit": map[string]interface{}{
+					"type":        "number",
+					"description": "Maximum number of deprecated symbols to report (default: 20)",
+				},
+				"output_format": map[string]interface{}{
+					"type":        "string",
+					"description": "Output format: 'json' (default), 'markdown' or 'minimal' (one line per result, for small-context models)",
+					"enum":        []string{"json", "markdown", "minimal"},
+				},
+			},
+			"required": []string{"file_path"},
+		}
+
+	case "get_symbols_bulk":
+		return map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"symbols": map[string]interface{}{
+					"type":        "array",
+					"description": "Symbols to fetch (max 50). Each item is {name, kind, package}; name may be qualified with its receiver/class, e.g. 'Billing.charge'",
+					"items": map[string]interface{}{
+						"type": "object",
+						"properties": map[string]interface{}{
+							"name": map[string]interface{}{
+								"type":        "string",
+								"description": "Symbol name",
+							},
+							"kind": map[string]interface{}{
+								"type":        "string",
+								"description": "Optional kind filter: function, method, type, class, interface, ...",
+							},
+							"package": map[string]interface{}{
+								"type":        "string",
+								"description": "Optional package / namespace filter (full path or last segment)",
+							},
+						},
+						"required": []string{"name"},
+					},
+				},
+				"file_path": map[string]interface{}{
+					"type":        "string",
+					"description": "REQUIRED: Path to a file in your project. Used to detect the workspace.",
+				},
+				"max_matches": map[string]interface{}{
+					"type":        "number",
+					"description": "Maximum symbols returned per requested name (default: 3)",
+				},
+				"include_code": map[string]interface{}{
+					"type":        "boolean",
+					"description": "Include the source code of each symbol (default: false)",
+				},
+				"output_format": map[string]interface{}{
+					"type":        "string",
+					"description": "Output format: 'json' (default), 'markdown' or 'minimal' (one line per result, for small-context models)",
+					"enum":        []string{"json", "markdown", "minimal"},
+				},
+			},
+			"required": []string{"symbols", "file_path"},
+		}
+
+	case "get_chunk":
+		return map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"chunk_id": map[string]interface{}{
+					"type":        "string",
+					"description": "Chunk ID from the chunk_id field of a search result",
+				},
+				"file_path": map[string]interface{}{
+					"type":        "string",
+					"description": "REQUIRED: Path to a file in your project. Used to detect the workspace.",
+				},
+				"neighbors": map[string]interface{}{
+					"type":        "number",
+					"description": "Number of neighbouring chunks of the same file to include (default: 2, 0 to disable)",
+				},
+				"output_format": map[string]interface{}{
+					"type":        "string",
+					"description": "Output format: 'json' (default), 'markdown' or 'minimal' (one line per result, for small-context models)",
+					"enum":        []string{"json", "markdown", "minimal"},
+				},
+			},
+			"required": []string{"chunk_id", "file_path"},
+		}
+
+	case "ab_search":
+		return map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"query": map[string]interface{}{
+					"type":        "string",
+					"description": "Search query, as for search_code",
+				},
+				"file_path": map[string]interface{}{
+					"type":        "string",
+					"description": "Path to a file in the workspace (used for workspace and language detection)",
+				},
+				"limit": map[string]interface{}{
+					"type":        "number",
+					"description": "Number of results per model (default: 10)",
+				},
+				"rebuild": map[string]interface{}{
+					"type":        "boolean",
+					"description": "Optional: re-embed the code with the second model, e.g. after code changes",
+				},
+				"output_format": map[string]interface{}{
+					"type":        "string",
+					"description": "Optional: 'markdown' (default, side-by-side table) or 'json' (both lists and metrics, for evaluation scripts)",
+				},
+			},
+			"required": []string{"query", "file_path"},
+		}
+
+	case "grep_workspace":
+		return map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"pattern": map[string]interface{}{
+					"type":        "string",
+					"description": "Text to find; a regular expression when regex is true",
+				},
+				"file_path": map[string]interface{}{
+					"type":        "string",
+					"description": "Path to a file in the workspace (used for workspace detection)",
+				},
+				"regex": map[string]interface{}{
+					"type":        "boolean",
+					"description": "Optional: treat pattern as a Go regular expression (default: literal)",
+				},
+				"case_sensitive": map[string]interface{}{
+					"type":        "boolean",
+					"description": "Optional: match case (default: ignore case)",
+				},
+				"include": map[string]interface{}{
+					"type":        "string",
+					"description": "Optional: comma-separated globs on file names or workspace-relative paths, e.g. '*.go' or 'internal/**/*.php'",
+				},
+				"context": map[string]interface{}{
+					"type":        "number",
+					"description": "Optional: lines of context before and after each match (default: 0)",
+				},
+				"limit": map[string]interface{}{
+					"type":        "number",
+					"description": "Maximum number of matches (default: 50)",
+				},
+				"output_format": map[string]interface{}{
+					"type":        "string",
+					"description": "Optional: 'json' (default, search_code result format), 'markdown' (grep-style listing) or 'minimal'",
+				},
+			},
+			"required": []string{"pattern", "file_path"},
+		}
+
+	case "structural_search":
+		return map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"pattern": map[string]interface{}{
+					"type":        "string",
+					"description": "Structural pattern, e.g. 'call:db.Query(*)', 'new PDO(*)', 'except:$X: pass', 'func:(*Server).Handle*'",
+				},
+				"file_path": map[string]interface{}{
+					"type":        "string",
+					"description": "Path to a file in the workspace (used for workspace detection)",
+				},
+				"language": map[string]interface{}{
+					"type":        "string",
+					"description": "Optional: only search files of this language (go, php or python; default: all three)",
+				},
+				"limit": map[string]interface{}{
+					"type":        "number",
+					"description": "Maximum number of matches (default: 50)",
+				},
+				"output_format": map[string]interface{}{
+					"type":        "string",
+					"description": "Optional: 'json' (default, search_code result format), 'markdown' (listing with bound variables) or 'minimal'",
+				},
+			},
+			"required": []string{"pattern", "file_path"},
+		}
+
+	case "suggest_rewrites":
+		return map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"pattern": map[string]interface{}{
+					"type":        "string",
+					"description": "Structural pattern selecting the code to replace (structural_search syntax), e.g. 'call:*.Exec($Q, *)'",
+				},
+				"replacement": map[string]interface{}{
+					"type":        "string",
+					"description": "Replacement for each match; $NAME variables of the pattern are substituted, e.g. 'db.ExecContext(ctx, $Q)'",
+				},
+				"file_path": map[string]interface{}{
+					"type":        "string",
+					"description": "Path to a file in the workspace (used for workspace detection)",
+				},
+				"language": map[string]interface{}{
+					"type":        "string",
+					"description": "Optional: only rewrite files of this language (go, php or python; default: all three)",
+				},
+				"limit": map[string]interface{}{
+					"type":        "number",
+					"description": "Maximum number of matches to rewrite (default: 200)",
+				},
+				"output_format": map[string]interface{}{
+					"type":        "string",
+					"description": "Optional: 'markdown' (default, diff blocks) or 'json' (per-file diffs)",
+				},
+			},
+			"required": []string{"pattern", "replacement", "file_path"},
+		}
+
+	case "apply_patch":
+		return map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"patch": map[string]interface{}{
+					"type":        "string",
+					"description": "Unified diff to apply (git diff or diff -u format); paths relative to the workspace root",
+				},
+				"file_path": map[string]interface{}{
+					"type":        "string",
+					"description": "Path to a file in the workspace (used for workspace detection)",
+				},
+				"dry_run": map[string]interface{}{
+					"type":        "boolean",
+					"description": "Optional: only check that the patch applies, write nothing (default: false)",
+				},
+				"output_format": map[string]interface{}{
+					"type":        "string",
+					"description": "Optional: 'markdown' (default) or 'json'",
+				},
+			},
+			"required": []string{"patch", "file_path"},
+		}
+
+	case "rollback_change":
+		return map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"file_path": map[string]interface{}{
+					"type":        "string",
+					"description": "Path to a file in the workspace (used for workspace detection)",
+				},
+				"change_id": map[string]interface{}{
+					"type":        "string",
+					"description": "Optional: change to roll back, as returned by apply_patch (default: the most recent change not rolled back)",
+				},
+				"force": map[string]interface{}{
+					"type":        "boolean",
+					"description": "Optional: restore even if the files were edited after the patch (default: false)",
+				},
+				"list": map[string]interface{}{
+					"type":        "boolean",
+					"description": "Optional: list the change journal instead of rolling back",
+				},
+				"output_format": map[string]interface{}{
+					"type":        "string",
+					"description": "Optional: 'markdown' (default) or 'json'",
+				},
+			},
+			"required": []string{"file_path"},
+		}
+
+	case "create_file_from_template":
+		return map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"file_path": map[string]interface{}{
+					"type":        "string",
+					"description": "Path to a file in the workspace (used for workspace detection)",
+				},
+				"template": map[string]interface{}{
+					"type":        "string",
+					"description": "Template name, e.g. 'go-package', 'laravel-controller', 'pytest-module' or a directory in .ragcode/templates; omit to list the templates",
+				},
+				"vars": map[string]interface{}{
+					"type":        "object",
+					"description": "Template variables, e.g. {\"Name\": \"InvoiceController\"}",
+					"additionalProperties": map[string]interface{}{
+						"type": "string",
+					},
+				},
+				"dir": map[string]interface{}{
+					"type":        "string",
+					"description": "Optional: workspace directory to create the files in (default: the workspace root)",
+				},
+				"dry_run": map[string]interface{}{
+					"type":        "boolean",
+					"description": "Optional: only check that the files can be created, write nothing (default: false)",
+				},
+				"list": map[string]interface{}{
+					"type":        "boolean",
+					"description": "Optional: list the available templates and their variables (default: false)",
+				},
+				"output_format": map[string]interface{}{
+					"type":        "string",
+					"description": "Optional: 'markdown' (default) or 'json'",
+				},
+			},
+			"required": []string{"file_path"},
+		}
+
+	case "edit_session":
+		return map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"action": map[string]interface{}{
+					"type":        "string",
+					"description": "'begin', 'stage', 'preview', 'commit' or 'discard'",
+					"enum":        []string{"begin", "stage", "preview", "commit", "discard"},
+				},
+				"file_path": map[string]interface{}{
+					"type":        "string",
+					"description": "Path to a file in the workspace (required for begin, used for workspace detection)",
+				},
+				"session_id": map[string]interface{}{
+					"type":        "string",
+					"description": "Session returned by begin (required for the other actions)",
+				},
+				"patch": map[string]interface{}{
+					"type":        "string",
+					"description": "stage: unified diff applied on top of the staged edits; paths relative to the workspace root",
+				},
+				"path": map[string]interface{}{
+					"type":        "string",
+					"description": "stage: file to replace, create or delete, relative to the workspace root",
+				},
+				"content": map[string]interface{}{
+					"type":        "string",
+					"description": "stage: new content of path",
+				},
+				"delete": map[string]interface{}{
+					"type":        "boolean",
+					"description": "stage: delete path (default: false)",
+				},
+				"output_format": map[string]interface{}{
+					"type":        "string",
+					"description": "Optional: 'markdown' (default) or 'json'",
+				},
+			},
+			"required": []string{"action"},
+		}
+
+	case "get_usage_report":
+		return map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"file_path": map[string]interface{}{
+					"type":        "string",
+					"description": "Path to any file or directory in the workspace",
+				},
+				"days": map[string]interface{}{
+					"type":        "integer",
+					"description": "Number of days to report, including today (default: 7)",
+				},
+				"limit": map[string]interface{}{
+					"type":        "integer",
+					"description": "Number of top queries and not-found queries to list (default: 10)",
+				},
+				"output_format": map[string]interface{}{
+					"type":        "string",
+					"enum":        []string{"markdown", "json"},
+					"description": "Output format (default: markdown)",
+				},
+			},
+			"required": []string{"file_path"},
+		}
+
+	case "get_language_coverage":
+		return map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"file_path": map[string]interface{}{
+					"type":        "string",
+					"description": "Path to any file or directory in the workspace",
+				},
+				"output_format": map[string]interface{}{
+					"type":        "string",
+					"enum":        []string{"markdown", "json"},
+					"description": "Output format (default: markdown)",
+				},
+			},
+			"required": []string{"file_path"},
+		}
+
+	case "index_status":
+		return map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"file_path": map[string]interface{}{
+					"type":        "string",
+					"description": "Path to any file or directory in the workspace",
+				},
+				"language": map[string]interface{}{
+					"type":        "string",
+					"description": "Only report this language (e.g. 'go', 'python'). Default: every language of the workspace",
+				},
+				"output_format": map[string]interface{}{
+					"type":        "string",
+					"enum":        []string{"markdown", "json"},
+					"description": "Output format (default: markdown)",
+				},
+			},
+			"required": []string{"file_path"},
+		}
+
+	case "cleanup_workspaces":
+		return map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"older_than": map[string]interface{}{
+					"type":        "string",
+					"description": "Evict workspaces unused for longer than this duration, e.g. '720h' (default: workspace.collection_ttl; '0' disables)",
+				},
+				"max_workspaces": map[string]interface{}{
+					"type":        "integer",
+					"description": "Keep at most this many recently used workspaces (default: workspace.max_workspaces; 0 disables)",
+				},
+				"dry_run": map[string]interface{}{
+					"type":        "boolean",
+					"description": "Report the workspaces that would be evicted without deleting anything (default: false)",
+				},
+				"output_format": map[string]interface{}{
+					"type":        "string",
+					"enum":        []string{"markdown", "json"},
+					"description": "Output format (default: markdown)",
+				},
+			},
+		}
+
+	case "delete_workspace_index":
+		return map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"file_path": map[string]interface{}{
+					"type":        "string",
+					"description": "A file path inside the workspace whose index is deleted",
+				},
+				"language": map[string]interface{}{
+					"type":        "string",
+					"description": "Optional: only delete the index of this language (e.g. 'go', 'php', 'python')",
+				},
+				"dry_run": map[string]interface{}{
+					"type":        "boolean",
+					"description": "List the collections that would be deleted without deleting anything (default: false)",
+				},
+				"output_format": map[string]interface{}{
+					"type":        "string",
+					"enum":        []string{"markdown", "json"},
+					"description": "Output format (default: markdown)",
+				},
+			},
+			"required": []string{"file_path"},
+		}
+
+	case "analyze_rename_impact":
+		return map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"symbol": map[string]interface{}{
+					"type":        "string",
+					"description": "Current name of the function, method, class or variable to rename",
+				},
+				"new_name": map[string]interface{}{
+					"type":        "string",
+					"description": "Proposed new name",
+				},
+				"file_path": map[string]interface{}{
+					"type":        "string",
+					"description": "Path to a file in the workspace (used for workspace detection)",
+				},
+				"limit": map[string]interface{}{
+					"type":        "number",
+					"description": "Optional: maximum number of matching lines to report (default: 500)",
+				},
+				"output_format": map[string]interface{}{
+					"type":        "string",
+					"description": "Output format: 'json' (default), 'markdown' or 'minimal' (one line per result, for small-context models)",
+					"enum":        []string{"json", "markdown", "minimal"},
+				},
+			},
+			"required": []string{"symbol", "new_name", "file_path"},
+		}
+	case "get_call_graph":
+		return map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"symbol_name": map[string]interface{}{
+					"type":        "string",
+					"description": "Function or method name, optionally qualified: 'Save', 'Store.Save' or 'billing.Charge'",
+				},
+				"file_path": map[string]interface{}{
+					"type":        "string",
+					"description": "Path to any file in the workspace",
+				},
+				"depth": map[string]interface{}{
+					"type":        "integer",
+					"description": "Levels of calls to follow (default: 2, max: 5)",
+				},
+				"direction": map[string]interface{}{
+					"type":        "string",
+					"enum":        []string{"both", "callers", "callees"},
+					"description": "Follow callers, callees or both (default: both)",
+				},
+				"include_external": map[string]interface{}{
+					"type":        "boolean",
+					"description": "Include callees outside the workspace, e.g. standard library (default: false)",
+				},
+				"output_format": map[string]interface{}{
+					"type":        "string",
+					"enum":        []string{"json", "markdown"},
+					"description": "Output format (default: json)",
+				},
+			},
+			"required": []string{"symbol_name", "file_path"},
+		}
+
+	case "get_package_dependencies":
+		return map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"package": map[string]interface{}{
+					"type":        "string",
+					"description": "Package, module or namespace: Go import path or name, Python module ('app.models'), PHP or C# namespace, or a directory relative to the workspace root. Default: the package of file_path",
+				},
+				"file_path": map[string]interface{}{
+					"type":        "string",
+					"description": "Path to any file in the workspace",
+				},
+				"output_format": map[string]interface{}{
+					"type":        "string",
+					"enum":        []string{"json", "markdown"},
+					"description": "Output format (default: json)",
+				},
+			},
+			"required": []string{"file_path"},
+		}
+
+	case "find_similar_code":
+		return map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"code": map[string]interface{}{
+					"type":        "string",
+					"description": "Code snippet to find duplicates of (or use symbol_name)",
+				},
+				"symbol_name": map[string]interface{}{
+					"type":        "string",
+					"description": "Indexed function, method or type to find duplicates of (or use code)",
+				},
+				"min_similarity": map[string]interface{}{
+					"type":        "number",
+					"description": "Optional: lowest cosine similarity reported, between 0 and 1 (default: queries.similarity_threshold, 0.85)",
+				},
+				"limit": map[string]interface{}{
+					"type":        "number",
+					"description": "Maximum number of results to return (default: 10, max: 50)",
+				},
+				"file_path": map[string]interface{}{
+					"type":        "string",
+					"description": "Path to a file in the workspace; its language selects the index searched",
+				},
+				"output_format": map[string]interface{}{
+					"type":        "string",
+					"enum":        []string{"json", "markdown", "minimal"},
+					"description": "Output format (default: json)",
+				},
+			},
+			"required": []string{"file_path"},
+		}
+
+	case "find_tests_for_symbol":
+		return map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"symbol_name": map[string]interface{}{
+					"type":        "string",
+					"description": "Function, method or type name, optionally qualified: 'Charge', 'Store.Save' or 'billing.Charge'",
+				},
+				"file_path": map[string]interface{}{
+					"type":        "string",
+					"description": "Path to a file in the workspace",
+				},
+				"output_format": map[string]interface{}{
+					"type":        "string",
+					"enum":        []string{"json", "markdown"},
+					"description": "Output format (default: json)",
+				},
+			},
+			"required": []string{"symbol_name", "file_path"},
+		}
+
+	case "reembed_workspace":
+		return map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"file_path": map[string]interface{}{
+					"type":        "string",
+					"description": "A file path inside the workspace to re-embed",
+				},
+				"language": map[string]interface{}{
+					"type":        "string",
+					"description": "Optional: only re-embed the collection of this language (e.g. 'go', 'php', 'python')",
+				},
+				"force": map[string]interface{}{
+					"type":        "boolean",
+					"description": "Re-embed collections whose dimension already matches the configured model, e.g. after switching between models of the same dimension (default: false)",
+				},
+				"dry_run": map[string]interface{}{
+					"type":        "boolean",
+					"description": "Report the stored and configured dimensions without re-embedding anything (default: false)",
+				},
+				"output_format": map[string]interface{}{
+					"type":        "string",
+					"enum":        []string{"markdown", "json"},
+					"description": "Output format (default: markdown)",
+				},
+			},
+			"required": []string{"file_path"},
+		}
+
+	case "find_hook_callbacks":
+		return map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"hook": map[string]interface{}{
+					"type":        "string",
+					"description": "Hook name, e.g. 'init' or 'the_content'. Wildcards allowed: 'woocommerce_*'",
+				},
+				"file_path": map[string]interface{}{
+					"type":        "string",
+					"description": "Path to any file in the workspace",
+				},
+				"include_triggers": map[string]interface{}{
+					"type":        "boolean",
+					"description": "Also list the do_action/apply_filters calls that fire the hook (default: true)",
+				},
+				"output_format": map[string]interface{}{
+					"type":        "string",
+					"enum":        []string{"markdown", "json", "minimal"},
+					"description": "Output format (default: markdown)",
+				},
+			},
+			"required": []string{"hook", "file_path"},
+		}
+
+	case "setup_workspace":
+		return map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"file_path": map[string]interface{}{
+					"type":        "string",
+					"description": "A file path within the workspace to inspect (used to detect workspace root)",
+				},
+				"write": map[string]interface{}{
+					"type":        "boolean",
+					"description": "Optional: create a starter .ragcode.yaml with the recommended languages and excludes (an existing file is never overwritten)",
+				},
+				"output_format": map[string]interface{}{
+					"type":        "string",
+					"description": "Optional: 'markdown' (default) or 'json'",
+					"enum":        []string{"markdown", "json"},
+				},
+			},
+			"required": []string{"file_path"},
+		}
+
+	case "reindex_file":
+		return map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"file_path": map[string]interface{}{
+					"type":        "string",
+					"description": "The file to re-index (absolute, or relative to the workspace root); also selects the workspace",
+				},
+				"output_format": map[string]interface{}{
+					"type":        "string",
+					"description": "Optional: 'markdown' (default), 'json' or 'minimal'",
+				},
+			},
+			"required": []string{"file_path"},
+		}
+
+	case "analyze_buffer":
+		return map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"file_path": map[string]interface{}{
+					"type":        "string",
+					"description": "The file the buffer belongs to (absolute, or relative to the workspace root); also selects the workspace",
+				},
+				"content": map[string]interface{}{
+					"type":        "string",
+					"description": "Current, unsaved text of the buffer",
+				},
+				"clear": map[string]interface{}{
+					"type":        "boolean",
+					"description": "Drop the buffer of file_path instead; searches see the indexed file again",
+				},
+				"all": map[string]interface{}{
+					"type":        "boolean",
+					"description": "With clear: drop every buffer of the session in the workspace",
+				},
+				"output_format": map[string]interface{}{
+					"type":        "string",
+					"description": "Optional: 'markdown' (default), 'json' or 'minimal'",
+				},
+			},
+			"required": []string{"file_path"},
+		}
+
+	default:
+		return map[string]interface{}{
+			"type":       "object",
+			"properties": map[string]interface{}{},
+		}
+	}
+}
+
+// checkDependencies checks the configured LLM provider, the TEI server
+// embeddings come from and, unless the vector store is the embedded one,
+// Qdrant
+func checkDependencies(cfg *config.Config) []healthcheck.CheckResult {
+	var results []healthcheck.CheckResult
+	if cfg.LLM.Provider == "openai" {
+		results = append(results, healthcheck.CheckOpenAI(cfg.LLM.OpenAIBaseURL, cfg.LLM.OpenAIAPIKey))
+	} else {
+		results = append(results, healthcheck.CheckOllama(cfg.LLM.OllamaBaseURL))
+	}
+	if cfg.LLM.EmbedProvider == "tei" {
+		results = append(results, healthcheck.CheckTEI(cfg.LLM.TEIBaseURL, cfg.LLM.TEIAPIKey))
+	}
+	// The local vector store is embedded: there is no server to check
+	if cfg.Storage.VectorDB.Provider != "local" {
+		results = append(results, healthcheck.CheckQdrant(cfg.Storage.VectorDB.URL))
+	}
+	return results
+}
+
+func printUsage() {
+	fmt.Fprintf(os.Stderr, `RagCode MCP Server - Semantic code navigation for Go codebases
+
+USAGE:
+    rag-code-mcp [OPTIONS]
+    rag-code-mcp scan <path> [--lang go|php|python|html|rust] [--format json|table]
+    rag-code-mcp bench [--files N] [--queries N] [--offline] [--format json|table]
+    rag-code-mcp reembed <path> [--lang L] [--force] [--dry-run] [--format json|table]
+    rag-code-mcp migrate <path> [--lang L] [--dry-run] [--format json|table]
+    rag-code-mcp selftest [--offline] [--keep] [--format json|table]
+    rag-code-mcp import-symbols <path> --from ARTIFACT [--type ctags|lsif|scip] [--replace] [--dry-run]
+
+EXAMPLES:
+    # Start with default configuration
+    rag-code-mcp
+
+    # Use custom config file
+    rag-code-mcp -config my-config.yaml
+
+    # Override Ollama and Qdrant URLs
+    rag-code-mcp -ollama-base-url http://remote:11434 -qdrant-url http://remote:6333
+
+    # Check version
+    rag-code-mcp -version
+
+    # Run health check only
+    rag-code-mcp -health
+
+    # Chunks the analyzer extracts from a directory, without Qdrant or Ollama
+    rag-code-mcp scan ./internal/billing --format json
+
+    # Indexing and search throughput on a synthetic repository
+    rag-code-mcp bench --files 500
+
+    # Re-embed a workspace after changing the embedding model
+    OLLAMA_EMBED=mxbai-embed-large rag-code-mcp reembed /path/to/project
+
+    # Rewrite points stored by an older version in the current payload schema
+    rag-code-mcp migrate /path/to/project --dry-run
+
+    # Validate an install or upgrade: index a sample workspace and call every tool
+    rag-code-mcp selftest
+
+    # Seed the symbol table from the SCIP index CI built, before indexing
+    rag-code-mcp import-symbols /path/to/project --from index.scip
+
+    # Usage statistics of a workspace for the last 30 days
+    rag-code-mcp -usage-report /path/to/project -usage-days 30
+
+    # Serve MCP over HTTP, with the REST API for non-MCP clients
+    RAGCODE_API_TOKEN=secret rag-code-mcp -listen 127.0.0.1:8080
+
+    # Shared daemon for several IDE clients over server-sent events
+    rag-code-mcp -transport=sse -listen=:8080 -token=secret
+
+    # Serve the gRPC API for CI bots and Go services
+    RAGCODE_API_TOKEN=secret rag-code-mcp -grpc-listen 127.0.0.1:9090
+
+OPTIONS:
+`)
+	flag.PrintDefaults()
 	fmt.Fprintf(os.Stderr, `
 CONFIGURATION PRECEDENCE:
     CLI flags > Environment variables > config.yaml > defaults
@@ -1048,9 +2488,16 @@ ENVIRONMENT VARIABLES:
     OLLAMA_BASE_URL              Ollama server URL (default: http://localhost:11434)
     OLLAMA_MODEL                 Chat model name (default: phi3:medium)
     OLLAMA_EMBED                 Embedding model name (default: nomic-embed-text)
+    LLM_PROVIDER                 ollama (default) or openai (OpenAI-compatible API)
+    OPENAI_BASE_URL              OpenAI-compatible API URL (default: https://api.openai.com/v1)
+    OPENAI_API_KEY               API key (optional for local servers)
+    OPENAI_MODEL                 Chat model name
+    OPENAI_EMBED                 Embedding model name
     QDRANT_URL                   Qdrant server URL (default: http://localhost:6333)
     QDRANT_COLLECTION            Collection name for code index (legacy mode only)
     QDRANT_API_KEY               Qdrant API key (optional)
+    VECTOR_DB_PROVIDER           Vector store: qdrant (default) or local (embedded, no server)
+    VECTOR_DB_PATH               Directory of the local vector store (default: ~/.local/share/ragcode/vectors)
 
     Multi-Workspace Mode (Recommended):
     WORKSPACE_COLLECTION_PREFIX  Prefix for auto-generated collections (default: ragcode)
@@ -1061,6 +2508,15 @@ ENVIRONMENT VARIABLES:
     Documentation (Optional):
     DOCS_COLLECTION              Qdrant collection for markdown docs (default: do-ai-docs)
     API_DOCS_COLLECTION          Qdrant collection for API docs (default: do-ai-api-docs)
+    DOCS_LANGUAGES               Preferred doc languages for search_docs, comma-separated (e.g. en,zh)
+
+    HTTP and gRPC Modes (-listen, -grpc-listen):
+    RAGCODE_API_TOKEN            Bearer token for /mcp or /sse, the REST API at /v1 (disabled without it) and gRPC
+    RAGCODE_WEBHOOK_SECRET       HMAC secret enabling POST /hooks/reindex in HTTP mode
+
+    Notifications:
+    RAGCODE_NOTIFY_COMMAND       Shell command run on indexing and health events (event JSON on stdin)
+    RAGCODE_NOTIFY_WEBHOOK       URL receiving events as JSON POSTs (Slack incoming webhooks work)
 
     Logging:
     MCP_LOG_LEVEL                Log level: debug, info, warn, error (default: info)
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"text/tabwriter"
+
+	"github.com/doITmagic/rag-code-mcp/internal/config"
+	"github.com/doITmagic/rag-code-mcp/internal/storage"
+	"github.com/doITmagic/rag-code-mcp/internal/workspace"
+)
+
+// runMigrate implements `rag-code-mcp migrate <path> [--lang L] [--dry-run]`:
+// it rewrites the points of the workspace containing path stored with an
+// older payload schema in the current one. It needs no embedding model: the
+// vectors are kept.
+func runMigrate(args []string, stdout, stderr io.Writer) int {
+	set := flag.NewFlagSet("migrate", flag.ContinueOnError)
+	set.SetOutput(stderr)
+	configPath := set.String("config", "config.yaml", "Path to configuration file (vector store)")
+	lang := set.String("lang", "", "Only migrate the collection of this language (default: every language of the workspace)")
+	dryRun := set.Bool("dry-run", false, "Count the outdated points without rewriting them")
+	format := set.String("format", "table", "Output format: table or json")
+	set.Usage = func() {
+		fmt.Fprintf(stderr, "Usage: rag-code-mcp migrate <path> [--lang L] [--dry-run] [--format json|table]\n\n")
+		set.PrintDefaults()
+	}
+
+	// Flags may come before or after the path
+	var paths []string
+	for {
+		if err := set.Parse(args); err != nil {
+			return 2
+		}
+		if set.NArg() == 0 {
+			break
+		}
+		paths = append(paths, set.Arg(0))
+		args = set.Args()[1:]
+	}
+	if len(paths) != 1 {
+		set.Usage()
+		return 2
+	}
+	if *format != "table" && *format != "json" {
+		fmt.Fprintf(stderr, "Error: unknown format %q: use table or json\n", *format)
+		return 2
+	}
+	path, err := filepath.Abs(paths[0])
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return 1
+	}
+	store, err := storage.Open(cfg.Storage.VectorDB, "")
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: vector store: %v\n", err)
+		return 1
+	}
+	defer store.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	wm := workspace.NewManager(store, nil, cfg)
+	info, err := wm.DetectWorkspace(map[string]interface{}{"file_path": path})
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return 1
+	}
+	report, err := wm.Migrate(ctx, info, *lang, *dryRun)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	if *format == "json" {
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(report); err != nil {
+			fmt.Fprintf(stderr, "Error: encode: %v\n", err)
+			return 1
+		}
+		return 0
+	}
+	tw := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "COLLECTION\tLANGUAGE\tPOINTS\tOUTDATED\tSTATUS")
+	for _, c := range report.Collections {
+		status := "up to date"
+		switch {
+		case c.Outdated > 0 && report.DryRun:
+			status = "would migrate"
+		case c.Failed > 0:
+			status = fmt.Sprintf("migrated, %d failed", c.Failed)
+		case c.Outdated > 0:
+			status = "migrated"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%d\t%s\n", c.Name, c.Language, c.Points, c.Outdated, status)
+	}
+	tw.Flush()
+	fmt.Fprintf(stderr, "%d collection(s) of %s checked against payload schema %d\n", len(report.Collections), report.Root, report.SchemaVersion)
+	return 0
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"context"
+
+	"github.com/doITmagic/rag-code-mcp/internal/tools"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// toolProfileCapability is the experimental capability of the initialize
+// response describing the tool selection
+const toolProfileCapability = "ragcode/tools"
+
+// advertiseToolProfile adds the active tool profile and the tools it
+// registered and left out to the capabilities clients receive on
+// initialize, so they can tell a read-only or minimal deployment from a
+// missing tool
+func advertiseToolProfile(server *mcp.Server, selection *tools.ToolSelection) {
+	server.AddReceivingMiddleware(func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			result, err := next(ctx, method, req)
+			if err != nil || method != "initialize" {
+				return result, err
+			}
+			if init, ok := result.(*mcp.InitializeResult); ok && init.Capabilities != nil {
+				if init.Capabilities.Experimental == nil {
+					init.Capabilities.Experimental = make(map[string]any)
+				}
+				init.Capabilities.Experimental[toolProfileCapability] = selection.Summary()
+			}
+			return result, nil
+		}
+	})
+}
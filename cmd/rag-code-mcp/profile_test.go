@@ -0,0 +1,59 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/doITmagic/rag-code-mcp/internal/config"
+	"github.com/doITmagic/rag-code-mcp/internal/tools"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestToolProfile(t *testing.T) {
+	ctx := context.Background()
+	server := mcp.NewServer(&mcp.Implementation{Name: "ragcode", Version: "test"}, nil)
+	toolSelection = tools.NewToolSelection(config.ToolsConfig{Profile: "read-only", Enabled: []string{"index_workspace"}})
+	defer func() { toolSelection = nil }()
+	advertiseToolProfile(server, toolSelection)
+	for _, name := range []string{"search_code", "index_workspace", "delete_workspace_index"} {
+		registerAgentTool(server, recordingTool{name})
+	}
+
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	serverSession, err := server.Connect(ctx, serverTransport, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer serverSession.Close()
+	client := mcp.NewClient(&mcp.Implementation{Name: "client", Version: "test"}, nil)
+	session, err := client.Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer session.Close()
+
+	listed, err := session.ListTools(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, tool := range listed.Tools {
+		names = append(names, tool.Name)
+	}
+	if len(names) != 2 || names[0] != "index_workspace" || names[1] != "search_code" {
+		t.Errorf("tools = %v, want index_workspace and search_code", names)
+	}
+
+	raw, err := json.Marshal(session.InitializeResult().Capabilities.Experimental[toolProfileCapability])
+	if err != nil {
+		t.Fatal(err)
+	}
+	var summary tools.ToolProfileSummary
+	if err := json.Unmarshal(raw, &summary); err != nil {
+		t.Fatal(err)
+	}
+	if summary.Profile != "read-only" || len(summary.Tools) != 2 || len(summary.Disabled) != 1 || summary.Disabled[0] != "delete_workspace_index" {
+		t.Errorf("capability = %+v", summary)
+	}
+}
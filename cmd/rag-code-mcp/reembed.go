@@ -0,0 +1,119 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"text/tabwriter"
+
+	"github.com/doITmagic/rag-code-mcp/internal/config"
+	"github.com/doITmagic/rag-code-mcp/internal/llm"
+	"github.com/doITmagic/rag-code-mcp/internal/storage"
+	"github.com/doITmagic/rag-code-mcp/internal/workspace"
+)
+
+// runReembed implements `rag-code-mcp reembed <path> [--lang L] [--force]
+// [--dry-run]`: it re-embeds the collections of the workspace containing
+// path whose vector dimension differs from the configured embedding model's,
+// after ollama_embed changed, and swaps them in place.
+func runReembed(args []string, stdout, stderr io.Writer) int {
+	set := flag.NewFlagSet("reembed", flag.ContinueOnError)
+	set.SetOutput(stderr)
+	configPath := set.String("config", "config.yaml", "Path to configuration file (embedding model and vector store)")
+	lang := set.String("lang", "", "Only re-embed the collection of this language (default: every language of the workspace)")
+	force := set.Bool("force", false, "Re-embed collections whose dimension already matches the configured model")
+	dryRun := set.Bool("dry-run", false, "Report the stored and configured dimensions without re-embedding")
+	format := set.String("format", "table", "Output format: table or json")
+	set.Usage = func() {
+		fmt.Fprintf(stderr, "Usage: rag-code-mcp reembed <path> [--lang L] [--force] [--dry-run] [--format json|table]\n\n")
+		set.PrintDefaults()
+	}
+
+	// Flags may come before or after the path
+	var paths []string
+	for {
+		if err := set.Parse(args); err != nil {
+			return 2
+		}
+		if set.NArg() == 0 {
+			break
+		}
+		paths = append(paths, set.Arg(0))
+		args = set.Args()[1:]
+	}
+	if len(paths) != 1 {
+		set.Usage()
+		return 2
+	}
+	if *format != "table" && *format != "json" {
+		fmt.Fprintf(stderr, "Error: unknown format %q: use table or json\n", *format)
+		return 2
+	}
+	path, err := filepath.Abs(paths[0])
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return 1
+	}
+	provider, err := llm.NewProvider(&cfg.LLM)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %s provider: %v\n", cfg.LLM.Provider, err)
+		return 1
+	}
+	store, err := storage.Open(cfg.Storage.VectorDB, "")
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: vector store: %v\n", err)
+		return 1
+	}
+	defer store.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	wm := workspace.NewManager(store, provider, cfg)
+	info, err := wm.DetectWorkspace(map[string]interface{}{"file_path": path})
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return 1
+	}
+	report, err := wm.Reembed(ctx, info, *lang, *force, *dryRun)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	if *format == "json" {
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(report); err != nil {
+			fmt.Fprintf(stderr, "Error: encode: %v\n", err)
+			return 1
+		}
+		return 0
+	}
+	tw := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "COLLECTION\tLANGUAGE\tPOINTS\tDIMENSION\tSTATUS")
+	for _, c := range report.Collections {
+		status := "up to date"
+		switch {
+		case c.Reembedded && report.DryRun:
+			status = fmt.Sprintf("would re-embed (%d → %d)", c.Dimension, report.Dimension)
+		case c.Reembedded:
+			status = fmt.Sprintf("re-embedded (%d → %d)", c.Dimension, report.Dimension)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%d\t%s\n", c.Name, c.Language, c.Points, c.Dimension, status)
+	}
+	tw.Flush()
+	fmt.Fprintf(stderr, "%d collection(s) of %s checked against %s (%d dimensions)\n", len(report.Collections), report.Root, report.Model, report.Dimension)
+	return 0
+}
@@ -0,0 +1,273 @@
+package main
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/doITmagic/rag-code-mcp/internal/llm"
+	"github.com/doITmagic/rag-code-mcp/internal/tools"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// restAPI exposes a few tools as plain HTTP endpoints for clients that
+// cannot speak MCP. Each endpoint maps onto the same tool implementation
+// the MCP server uses:
+//
+//	GET|POST /v1/search        search_code (q or query)
+//	GET      /v1/symbol/{name} get_symbols_bulk (kind, package)
+//	POST     /v1/index         index_workspace
+//
+// Endpoints of nil tools, left out by the tool profile, are not served.
+type restAPI struct {
+	search  MCPTool
+	symbols MCPTool
+	index   MCPTool
+}
+
+// restResponse is the body of every /v1 response. Result holds the tool
+// output, as JSON when the tool produced JSON and as a string otherwise.
+type restResponse struct {
+	Tool   string      `json:"tool,omitempty"`
+	Result interface{} `json:"result,omitempty"`
+	Error  string      `json:"error,omitempty"`
+}
+
+func (a *restAPI) routes(mux *http.ServeMux) {
+	if a.search != nil {
+		mux.HandleFunc("GET /v1/search", a.handleSearch)
+		mux.HandleFunc("POST /v1/search", a.handleSearch)
+	}
+	if a.symbols != nil {
+		mux.HandleFunc("GET /v1/symbol/{name}", a.handleSymbol)
+	}
+	if a.index != nil {
+		mux.HandleFunc("POST /v1/index", a.handleIndex)
+	}
+}
+
+func (a *restAPI) handleSearch(w http.ResponseWriter, r *http.Request) {
+	args, err := restArgs(r)
+	if err != nil {
+		writeREST(w, http.StatusBadRequest, restResponse{Error: err.Error()})
+		return
+	}
+	if q, ok := args["q"]; ok {
+		args["query"] = q
+		delete(args, "q")
+	}
+	if _, ok := args["output_format"]; !ok {
+		args["output_format"] = "json"
+	}
+	a.run(w, r, a.search, args)
+}
+
+func (a *restAPI) handleSymbol(w http.ResponseWriter, r *http.Request) {
+	args, err := restArgs(r)
+	if err != nil {
+		writeREST(w, http.StatusBadRequest, restResponse{Error: err.Error()})
+		return
+	}
+	symbol := map[string]interface{}{"name": r.PathValue("name")}
+	for _, key := range []string{"kind", "package"} {
+		if v, ok := args[key]; ok {
+			symbol[key] = v
+			delete(args, key)
+		}
+	}
+	args["symbols"] = []interface{}{symbol}
+	if _, ok := args["output_format"]; !ok {
+		args["output_format"] = "json"
+	}
+	a.run(w, r, a.symbols, args)
+}
+
+func (a *restAPI) handleIndex(w http.ResponseWriter, r *http.Request) {
+	args, err := restArgs(r)
+	if err != nil {
+		writeREST(w, http.StatusBadRequest, restResponse{Error: err.Error()})
+		return
+	}
+	a.run(w, r, a.index, args)
+}
+
+// run executes a tool and writes its result. Tool errors are the caller's
+// (missing parameters, unknown workspace, nothing found) and answered with
+// 400; a cancelled request gets no body.
+func (a *restAPI) run(w http.ResponseWriter, r *http.Request, tool MCPTool, args map[string]interface{}) {
+	start := time.Now()
+	logger.Info("🌐 REST %s %s -> '%s' with args: %v", r.Method, r.URL.Path, tool.Name(), args)
+	ctx, calls := llm.WithCallUsage(r.Context())
+	result, err := tool.Execute(ctx, args)
+	tools.RecordToolCall(usageManager, tool.Name(), args, result, err, time.Since(start), calls.Totals())
+	if err != nil {
+		if errors.Is(err, context.Canceled) {
+			return
+		}
+		logger.Error("❌ REST tool '%s' failed after %v: %v", tool.Name(), time.Since(start), err)
+		writeREST(w, http.StatusBadRequest, restResponse{Tool: tool.Name(), Error: err.Error()})
+		return
+	}
+	logger.Info("✅ REST tool '%s' completed in %v", tool.Name(), time.Since(start))
+
+	resp := restResponse{Tool: tool.Name(), Result: result}
+	if json.Valid([]byte(result)) {
+		resp.Result = json.RawMessage(result)
+	}
+	writeREST(w, http.StatusOK, resp)
+}
+
+// restStringParams are query parameters passed to tools as strings even
+// when they look like numbers or booleans.
+var restStringParams = map[string]bool{
+	"q": true, "query": true, "file_path": true, "kind": true,
+	"package": true, "language": true, "output_format": true,
+}
+
+// restArgs collects the tool arguments of a request: query parameters, with
+// numbers and booleans converted, overridden by the fields of a JSON body.
+func restArgs(r *http.Request) (map[string]interface{}, error) {
+	args := make(map[string]interface{})
+	for key, values := range r.URL.Query() {
+		v := values[len(values)-1]
+		if restStringParams[key] {
+			args[key] = v
+		} else if n, err := strconv.ParseFloat(v, 64); err == nil {
+			args[key] = n
+		} else if b, err := strconv.ParseBool(v); err == nil {
+			args[key] = b
+		} else {
+			args[key] = v
+		}
+	}
+	if r.Body != nil && r.ContentLength != 0 {
+		var body map[string]interface{}
+		dec := json.NewDecoder(http.MaxBytesReader(nil, r.Body, 1<<20))
+		if err := dec.Decode(&body); err != nil {
+			return nil, fmt.Errorf("invalid JSON body: %w", err)
+		}
+		for k, v := range body {
+			args[k] = v
+		}
+	}
+	return args, nil
+}
+
+func writeREST(w http.ResponseWriter, status int, resp restResponse) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(resp)
+}
+
+// requireToken rejects requests without "Authorization: Bearer <token>".
+func requireToken(token string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="ragcode"`)
+			writeREST(w, http.StatusUnauthorized, restResponse{Error: "missing or invalid bearer token"})
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// MCP transports selected with -transport
+const (
+	transportStdio = "stdio"
+	transportHTTP  = "http" // streamable HTTP
+	transportSSE   = "sse"  // HTTP with server-sent events (2024-11-05 protocol)
+)
+
+// resolveTransport validates -transport against -listen. Without
+// -transport the server speaks stdio, or streamable HTTP when -listen is set.
+func resolveTransport(transport, listen string) (string, error) {
+	switch strings.ToLower(transport) {
+	case "":
+		if listen != "" {
+			return transportHTTP, nil
+		}
+		return transportStdio, nil
+	case transportStdio:
+		if listen != "" {
+			return "", fmt.Errorf("-listen requires -transport=http or -transport=sse")
+		}
+		return transportStdio, nil
+	case transportHTTP, "streamable-http":
+		if listen == "" {
+			return "", fmt.Errorf("-transport=http requires -listen (e.g. -listen=:8080)")
+		}
+		return transportHTTP, nil
+	case transportSSE:
+		if listen == "" {
+			return "", fmt.Errorf("-transport=sse requires -listen (e.g. -listen=:8080)")
+		}
+		return transportSSE, nil
+	}
+	return "", fmt.Errorf("unknown -transport %q: use stdio, http or sse", transport)
+}
+
+// withClientSession tags a tool call with the MCP session of its client, so
+// state kept between calls stays private to each connection when several
+// clients share the server, and with the context window the client
+// announced when it initialized the session
+func withClientSession(ctx context.Context, session *mcp.ServerSession) context.Context {
+	if session == nil {
+		return ctx
+	}
+	if params := session.InitializeParams(); params != nil {
+		ctx = tools.WithContextWindow(ctx, tools.ContextWindowFromMeta(params.Meta))
+	}
+	return tools.WithClientSession(ctx, session.ID())
+}
+
+// serveHTTP runs the server on addr instead of stdio: MCP at /mcp
+// (streamable HTTP) or /sse (server-sent events) depending on transport and,
+// when a token is configured, the REST API at /v1. Both require the token
+// when one is set. Every HTTP client gets its own MCP session. hooks, when
+// not nil, is served at /hooks/reindex with its own signature check. It
+// returns when ctx is cancelled.
+func serveHTTP(ctx context.Context, addr, transport, token string, server *mcp.Server, api *restAPI, hooks *webhookHandler) error {
+	mux := http.NewServeMux()
+	getServer := func(*http.Request) *mcp.Server { return server }
+	mcpPath := "/mcp"
+	var mcpHandler http.Handler = mcp.NewStreamableHTTPHandler(getServer, nil)
+	if transport == transportSSE {
+		mcpPath = "/sse"
+		mcpHandler = mcp.NewSSEHandler(getServer, nil)
+	}
+	if token != "" {
+		mcpHandler = requireToken(token, mcpHandler)
+		restMux := http.NewServeMux()
+		api.routes(restMux)
+		mux.Handle("/v1/", requireToken(token, restMux))
+		logger.Info("🌐 REST API enabled at http://%s/v1 (search, symbol/{name}, index)", addr)
+	} else {
+		logger.Warn("REST API disabled: set server.api_token (or RAGCODE_API_TOKEN) to enable /v1; %s is served without authentication", mcpPath)
+	}
+	mux.Handle(mcpPath, mcpHandler)
+	if hooks != nil {
+		mux.Handle("POST /hooks/reindex", hooks)
+		logger.Info("🪝 Re-index webhook enabled at http://%s/hooks/reindex", addr)
+	}
+
+	srv := &http.Server{Addr: addr, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	errc := make(chan error, 1)
+	go func() { errc <- srv.ListenAndServe() }()
+	logger.Info("MCP RagCode Server listening on http://%s%s", addr, mcpPath)
+
+	select {
+	case err := <-errc:
+		return err
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		return srv.Shutdown(shutdownCtx)
+	}
+}
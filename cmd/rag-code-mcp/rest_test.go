@@ -0,0 +1,119 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// recordingTool returns its arguments as JSON.
+type recordingTool struct{ name string }
+
+func (t recordingTool) Name() string        { return t.name }
+func (t recordingTool) Description() string { return "" }
+func (t recordingTool) Execute(ctx context.Context, args map[string]interface{}) (string, error) {
+	if args["fail"] == true {
+		return "", fmt.Errorf("file_path parameter is required")
+	}
+	data, _ := json.Marshal(args)
+	return string(data), nil
+}
+
+func TestRESTAPI(t *testing.T) {
+	api := &restAPI{
+		search:  recordingTool{"search_code"},
+		symbols: recordingTool{"get_symbols_bulk"},
+		index:   recordingTool{"index_workspace"},
+	}
+	mux := http.NewServeMux()
+	api.routes(mux)
+	handler := requireToken("s3cret", mux)
+
+	do := func(method, target, body, token string) (int, restResponse) {
+		t.Helper()
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		if body == "" {
+			req = httptest.NewRequest(method, target, nil)
+		}
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		var resp restResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("%s %s: invalid response %q", method, target, rec.Body.String())
+		}
+		return rec.Code, resp
+	}
+	args := func(resp restResponse) map[string]interface{} {
+		t.Helper()
+		raw, _ := json.Marshal(resp.Result)
+		var m map[string]interface{}
+		if err := json.Unmarshal(raw, &m); err != nil {
+			t.Fatalf("result is not the tool JSON: %v", resp.Result)
+		}
+		return m
+	}
+
+	if code, _ := do("GET", "/v1/search?q=x", "", ""); code != http.StatusUnauthorized {
+		t.Fatalf("no token: status %d", code)
+	}
+	if code, _ := do("GET", "/v1/search?q=x", "", "wrong"); code != http.StatusUnauthorized {
+		t.Fatalf("wrong token: status %d", code)
+	}
+
+	code, resp := do("GET", "/v1/search?q=2024&limit=5&file_path=/w/main.go", "", "s3cret")
+	if code != http.StatusOK || resp.Tool != "search_code" {
+		t.Fatalf("search: %d %+v", code, resp)
+	}
+	got := args(resp)
+	if got["query"] != "2024" || got["limit"] != float64(5) || got["file_path"] != "/w/main.go" || got["output_format"] != "json" {
+		t.Fatalf("search args = %v", got)
+	}
+
+	_, resp = do("GET", "/v1/symbol/Manager?kind=type&file_path=/w/main.go", "", "s3cret")
+	got = args(resp)
+	symbols, _ := got["symbols"].([]interface{})
+	if resp.Tool != "get_symbols_bulk" || len(symbols) != 1 || fmt.Sprint(symbols[0]) != "map[kind:type name:Manager]" {
+		t.Fatalf("symbol args = %v", got)
+	}
+
+	_, resp = do("POST", "/v1/index", `{"file_path": "/w/main.go", "language": "go"}`, "s3cret")
+	if got := args(resp); resp.Tool != "index_workspace" || got["language"] != "go" {
+		t.Fatalf("index args = %v", got)
+	}
+
+	if code, resp := do("POST", "/v1/index", `{"fail": true}`, "s3cret"); code != http.StatusBadRequest || !strings.Contains(resp.Error, "file_path") {
+		t.Fatalf("tool error: %d %+v", code, resp)
+	}
+	if code, _ := do("POST", "/v1/index", `{`, "s3cret"); code != http.StatusBadRequest {
+		t.Fatalf("bad body: status %d", code)
+	}
+}
+
+func TestResolveTransport(t *testing.T) {
+	tests := []struct {
+		transport, listen, want string
+		wantErr                 bool
+	}{
+		{"", "", transportStdio, false},
+		{"", ":8080", transportHTTP, false},
+		{"http", ":8080", transportHTTP, false},
+		{"SSE", ":8080", transportSSE, false},
+		{"http", "", "", true},
+		{"sse", "", "", true},
+		{"stdio", ":8080", "", true},
+		{"websocket", ":8080", "", true},
+	}
+	for _, tt := range tests {
+		got, err := resolveTransport(tt.transport, tt.listen)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("resolveTransport(%q, %q) = %q, %v", tt.transport, tt.listen, got, err)
+		}
+	}
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/doITmagic/rag-code-mcp/internal/codetypes"
+	"github.com/doITmagic/rag-code-mcp/internal/ragcode"
+)
+
+// scanLanguages maps the file extensions of the languages with an analyzer
+// to the --lang value of the scan subcommand
+var scanLanguages = map[string]string{
+	".go":   "go",
+	".php":  "php",
+	".py":   "python",
+	".html": "html",
+	".htm":  "html",
+	".rs":   "rust",
+}
+
+// runScan implements `rag-code-mcp scan <path> [--lang L] [--format json|table]`:
+// it runs the analyzer used by indexing on a file or directory and prints the
+// chunks, to check the analyzer output without Qdrant or Ollama.
+func runScan(args []string, stdout, stderr io.Writer) int {
+	set := flag.NewFlagSet("scan", flag.ContinueOnError)
+	set.SetOutput(stderr)
+	lang := set.String("lang", "", "Language of the analyzer: go, php, python, html or rust (default: detected from the file extensions)")
+	format := set.String("format", "table", "Output format: table or json")
+	set.Usage = func() {
+		fmt.Fprintf(stderr, "Usage: rag-code-mcp scan <path> [--lang go|php|python|html|rust] [--format json|table]\n\n")
+		set.PrintDefaults()
+	}
+
+	// Flags may come before or after the path
+	var paths []string
+	for {
+		if err := set.Parse(args); err != nil {
+			return 2
+		}
+		if set.NArg() == 0 {
+			break
+		}
+		paths = append(paths, set.Arg(0))
+		args = set.Args()[1:]
+	}
+	if len(paths) != 1 {
+		set.Usage()
+		return 2
+	}
+	if *format != "table" && *format != "json" {
+		fmt.Fprintf(stderr, "Error: unknown format %q: use table or json\n", *format)
+		return 2
+	}
+	path := paths[0]
+	st, err := os.Stat(path)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	language := *lang
+	if language == "" {
+		language = detectScanLanguage(path)
+		if language == "" {
+			fmt.Fprintf(stderr, "Error: no supported source files in %s: pass --lang\n", path)
+			return 1
+		}
+	}
+	analyzer := ragcode.NewAnalyzerManager().CodeAnalyzerForProjectType(language)
+	if analyzer == nil {
+		fmt.Fprintf(stderr, "Error: no analyzer for language %q\n", language)
+		return 2
+	}
+
+	chunks, err := analyzer.AnalyzePaths([]string{path})
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: analyze %s: %v\n", path, err)
+		return 1
+	}
+	if !st.IsDir() {
+		// Package-level analyzers (Go) return the chunks of the whole directory
+		chunks = chunksOfFile(chunks, path)
+	}
+	if chunks == nil {
+		chunks = []codetypes.CodeChunk{}
+	}
+
+	if *format == "json" {
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(chunks); err != nil {
+			fmt.Fprintf(stderr, "Error: encode: %v\n", err)
+			return 1
+		}
+		return 0
+	}
+	writeScanTable(stdout, chunks)
+	fmt.Fprintf(stderr, "%d chunk(s) from the %s analyzer\n", len(chunks), language)
+	return 0
+}
+
+// chunksOfFile keeps the chunks extracted from one file
+func chunksOfFile(chunks []codetypes.CodeChunk, path string) []codetypes.CodeChunk {
+	want, _ := filepath.Abs(path)
+	var out []codetypes.CodeChunk
+	for _, ch := range chunks {
+		if got, _ := filepath.Abs(ch.FilePath); got == want {
+			out = append(out, ch)
+		}
+	}
+	return out
+}
+
+// writeScanTable prints one line per chunk: location, type, name, package
+// and flags
+func writeScanTable(w io.Writer, chunks []codetypes.CodeChunk) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "LOCATION\tTYPE\tNAME\tPACKAGE\tFLAGS")
+	for _, ch := range chunks {
+		loc := ch.FilePath
+		if ch.StartLine > 0 {
+			loc = fmt.Sprintf("%s:%d-%d", ch.FilePath, ch.StartLine, ch.EndLine)
+		}
+		var flags []string
+		if ch.Partial() {
+			flags = append(flags, "partial")
+		}
+		if ch.Docstring == "" {
+			flags = append(flags, "no-doc")
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", loc, ch.Type, ch.Name, ch.Package, strings.Join(flags, ","))
+	}
+	tw.Flush()
+}
+
+// detectScanLanguage returns the language of a file, or the language with
+// the most files in a directory, among those with an analyzer
+func detectScanLanguage(path string) string {
+	counts := make(map[string]int)
+	filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() {
+			name := d.Name()
+			if p != path && (strings.HasPrefix(name, ".") || name == "vendor" || name == "node_modules" || name == "__pycache__" || name == "target") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if lang := scanLanguages[strings.ToLower(filepath.Ext(p))]; lang != "" {
+			counts[lang]++
+		}
+		return nil
+	})
+
+	best := ""
+	for lang, n := range counts {
+		if n > counts[best] || (n == counts[best] && lang < best) {
+			best = lang
+		}
+	}
+	return best
+}
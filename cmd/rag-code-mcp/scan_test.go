@@ -0,0 +1,65 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/doITmagic/rag-code-mcp/internal/codetypes"
+)
+
+func TestRunScan(t *testing.T) {
+	dir := t.TempDir()
+	src := "package billing\n\n// Charge bills a customer\nfunc Charge() {}\n\nfunc Refund() {}\n"
+	if err := os.WriteFile(filepath.Join(dir, "billing.go"), []byte(src), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "index.html"), []byte("<h1>Docs</h1>"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if lang := detectScanLanguage(dir); lang != "go" {
+		t.Errorf("detected %q, want the tie broken by name (go)", lang)
+	}
+
+	var stdout, stderr bytes.Buffer
+	if code := runScan([]string{dir, "--format", "json"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("exit %d: %s", code, stderr.String())
+	}
+	var chunks []codetypes.CodeChunk
+	if err := json.Unmarshal(stdout.Bytes(), &chunks); err != nil {
+		t.Fatalf("json output: %v", err)
+	}
+	if len(chunks) != 2 || chunks[0].Name != "Charge" {
+		t.Errorf("chunks = %+v, want Charge and Refund", chunks)
+	}
+
+	stdout.Reset()
+	if code := runScan([]string{"--lang", "go", dir}, &stdout, &stderr); code != 0 {
+		t.Fatalf("exit %d: %s", code, stderr.String())
+	}
+	lines := strings.Split(strings.TrimSpace(stdout.String()), "\n")
+	if len(lines) != 3 || !strings.Contains(lines[2], "Refund") || !strings.Contains(lines[2], "no-doc") {
+		t.Errorf("table = %q, want a header and 2 rows, Refund flagged no-doc", stdout.String())
+	}
+
+	stdout.Reset()
+	other := "package billing\n\nfunc Invoice() {}\n"
+	if err := os.WriteFile(filepath.Join(dir, "invoice.go"), []byte(other), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if code := runScan([]string{filepath.Join(dir, "invoice.go")}, &stdout, &stderr); code != 0 {
+		t.Fatalf("exit %d: %s", code, stderr.String())
+	}
+	if out := stdout.String(); !strings.Contains(out, "Invoice") || strings.Contains(out, "Charge") {
+		t.Errorf("scan of one file = %q, want only its chunks", out)
+	}
+
+	for _, args := range [][]string{{}, {dir, "--format", "xml"}, {dir, "--lang", "cobol"}, {dir, dir}} {
+		if code := runScan(args, &stdout, &stderr); code != 2 {
+			t.Errorf("runScan(%q) = %d, want usage error", args, code)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestSearchCodeTypedArgs(t *testing.T) {
+	ctx := context.Background()
+	server := mcp.NewServer(&mcp.Implementation{Name: "ragcode", Version: "test"}, nil)
+	registerSearchCodeToolTyped(server, recordingTool{"search_code"})
+
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	serverSession, err := server.Connect(ctx, serverTransport, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer serverSession.Close()
+	client := mcp.NewClient(&mcp.Implementation{Name: "client", Version: "test"}, nil)
+	session, err := client.Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer session.Close()
+
+	res, err := session.CallTool(ctx, &mcp.CallToolParams{
+		Name: "search_code",
+		Arguments: map[string]interface{}{
+			"query":          "parse config",
+			"file_path":      "/src/app/main.go",
+			"context_window": 200000,
+			"group_by":       "package",
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.IsError {
+		t.Fatalf("search_code failed: %+v", res.Content)
+	}
+	raw, err := json.Marshal(res.StructuredContent)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out SearchCodeOutput
+	if err := json.Unmarshal(raw, &out); err != nil {
+		t.Fatal(err)
+	}
+	var args map[string]interface{}
+	if err := json.Unmarshal([]byte(out.Results), &args); err != nil {
+		t.Fatal(err)
+	}
+	if args["context_window"] != float64(200000) {
+		t.Errorf("tool args = %v, want context_window passed through", args)
+	}
+	if args["group_by"] != "package" {
+		t.Errorf("tool args = %v, want group_by passed through", args)
+	}
+}
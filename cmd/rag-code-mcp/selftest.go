@@ -0,0 +1,546 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/doITmagic/rag-code-mcp/internal/bench"
+	"github.com/doITmagic/rag-code-mcp/internal/config"
+	"github.com/doITmagic/rag-code-mcp/internal/llm"
+	"github.com/doITmagic/rag-code-mcp/internal/storage"
+	"github.com/doITmagic/rag-code-mcp/internal/tools"
+	"github.com/doITmagic/rag-code-mcp/internal/workspace"
+)
+
+// selftestFiles is the sample workspace of the self-test: a Go module, a
+// PHP and a Python project in one root, with documentation and a coverage
+// profile
+var selftestFiles = map[string]string{
+	"go.mod": "module example.com/selftest\n\ngo 1.21\n",
+	"billing/invoice.go": `package billing
+
+import "errors"
+
+// ErrEmptyInvoice is returned for invoices without lines
+var ErrEmptyInvoice = errors.New("invoice has no lines")
+
+// Invoice is a customer invoice
+type Invoice struct {
+	Customer string
+	Lines    []Line
+}
+
+// Line is an invoice line
+type Line struct {
+	Description string
+	Amount      float64
+}
+
+// Totaler computes a total
+type Totaler interface {
+	Total() (float64, error)
+}
+
+// Total returns the sum of the invoice lines
+func (inv Invoice) Total() (float64, error) {
+	if len(inv.Lines) == 0 {
+		return 0, ErrEmptyInvoice
+	}
+	var sum float64
+	for _, l := range inv.Lines {
+		sum += l.Amount
+	}
+	return sum, nil
+}
+
+// CalculateTotal returns the total of an invoice
+func CalculateTotal(inv Invoice) (float64, error) {
+	return inv.Total()
+}
+`,
+	"billing/invoice_test.go": `package billing
+
+import "testing"
+
+func TestCalculateTotal(t *testing.T) {
+	total, err := CalculateTotal(Invoice{Lines: []Line{{Amount: 2}, {Amount: 3}}})
+	if err != nil || total != 5 {
+		t.Fatalf("CalculateTotal = %v, %v", total, err)
+	}
+}
+`,
+	"composer.json": "{\n    \"name\": \"selftest/shop\"\n}\n",
+	"src/Cart.php": `<?php
+
+namespace Shop;
+
+/**
+ * Cart holds the items of a customer order
+ */
+class Cart
+{
+    private array $items = [];
+
+    public function addItem(string $sku, float $price): void
+    {
+        $this->items[$sku] = $price;
+    }
+
+    public function total(): float
+    {
+        return array_sum($this->items);
+    }
+}
+
+add_action('init', function () {
+    $cart = new Cart();
+});
+`,
+	"pyproject.toml": "[project]\nname = \"selftest\"\n",
+	"shop/cart.py": `class Cart:
+    """Cart holds the items of a customer order."""
+
+    def __init__(self):
+        self.items = {}
+
+    def add_item(self, sku, price):
+        self.items[sku] = price
+
+    def total(self):
+        return sum(self.items.values())
+
+
+def apply_discount(cart, percent):
+    """Return the cart total with a discount."""
+    return cart.total() * (1 - percent / 100)
+`,
+	"README.md":    "# Selftest\n\nInvoices are totaled by CalculateTotal in the billing package.\n",
+	"coverage.out": "mode: set\nexample.com/selftest/billing/invoice.go:25.45,26.22 1 1\nexample.com/selftest/billing/invoice.go:38.51,40.2 1 0\n",
+}
+
+// selftestCase is a canned call of a tool. The result must contain expect.
+type selftestCase struct {
+	tool   MCPTool
+	params func() map[string]interface{}
+	expect string
+	skip   string // reason the tool is not run
+}
+
+// selftestStage is a stage of a self-test run
+type selftestStage struct {
+	Stage      string  `json:"stage"`
+	Status     string  `json:"status"` // pass, fail or skip
+	DurationMs float64 `json:"duration_ms"`
+	Detail     string  `json:"detail,omitempty"`
+}
+
+// selftestReport is the result of a self-test run
+type selftestReport struct {
+	Workspace string          `json:"workspace"`
+	Offline   bool            `json:"offline,omitempty"`
+	Passed    int             `json:"passed"`
+	Failed    int             `json:"failed"`
+	Skipped   int             `json:"skipped"`
+	Stages    []selftestStage `json:"stages"`
+}
+
+func (r *selftestReport) add(stage, status, detail string, start time.Time) {
+	r.Stages = append(r.Stages, selftestStage{
+		Stage:      stage,
+		Status:     status,
+		DurationMs: float64(time.Since(start).Microseconds()) / 1000,
+		Detail:     detail,
+	})
+	switch status {
+	case "pass":
+		r.Passed++
+	case "fail":
+		r.Failed++
+	default:
+		r.Skipped++
+	}
+}
+
+// chunkIDPattern finds a chunk ID in search results, for get_chunk
+var chunkIDPattern = regexp.MustCompile(`\[chunk_id ([^\]]+)\]`)
+
+// runSelftest implements `rag-code-mcp selftest [--offline] [--keep]`: it
+// writes a sample Go/PHP/Python workspace to a temporary directory, indexes
+// it, calls every tool the configuration registers with canned parameters
+// and reports pass/fail per stage, then deletes the workspace and its index.
+// With --offline it embeds with word hashes into a temporary local store,
+// without Ollama or Qdrant.
+func runSelftest(args []string, stdout, stderr io.Writer) int {
+	set := flag.NewFlagSet("selftest", flag.ContinueOnError)
+	set.SetOutput(stderr)
+	configPath := set.String("config", "config.yaml", "Path to configuration file (embedding provider, vector store, tool profile)")
+	offline := set.Bool("offline", false, "Embed with word hashes into a temporary local store: no Ollama, no Qdrant")
+	keep := set.Bool("keep", false, "Keep the sample workspace and its index for inspection")
+	dir := set.String("dir", "", "Directory of the sample workspace (default: ~/.local/share/ragcode/selftest; workspaces under /tmp are rejected)")
+	timeout := set.Duration("timeout", 2*time.Minute, "Time allowed to each stage")
+	format := set.String("format", "table", "Output format: table or json")
+	set.Usage = func() {
+		fmt.Fprintf(stderr, "Usage: rag-code-mcp selftest [--offline] [--keep] [--format json|table]\n\n")
+		set.PrintDefaults()
+	}
+	if err := set.Parse(args); err != nil {
+		return 2
+	}
+	if set.NArg() > 0 {
+		set.Usage()
+		return 2
+	}
+	if *format != "table" && *format != "json" {
+		fmt.Fprintf(stderr, "Error: unknown format %q: use table or json\n", *format)
+		return 2
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	report := &selftestReport{Offline: *offline, Stages: []selftestStage{}}
+	env := selftestSetup(ctx, report, *configPath, *dir, *offline, *timeout)
+	if env != nil {
+		if env.indexed {
+			selftestTools(ctx, report, env, *timeout)
+		}
+		if *keep {
+			fmt.Fprintf(stderr, "Sample workspace kept in %s\n", env.info.Root)
+		} else {
+			env.cleanup(report)
+		}
+	}
+
+	if *format == "json" {
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(report); err != nil {
+			fmt.Fprintf(stderr, "Error: encode: %v\n", err)
+			return 1
+		}
+	} else {
+		tw := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "STAGE\tSTATUS\tTIME\tDETAIL")
+		for _, s := range report.Stages {
+			fmt.Fprintf(tw, "%s\t%s\t%.0fms\t%s\n", s.Stage, s.Status, s.DurationMs, s.Detail)
+		}
+		tw.Flush()
+		fmt.Fprintf(stderr, "%d passed, %d failed, %d skipped\n", report.Passed, report.Failed, report.Skipped)
+	}
+	if report.Failed > 0 {
+		return 1
+	}
+	return 0
+}
+
+// selftestEnv is the sample workspace of a self-test run
+type selftestEnv struct {
+	cfg      *config.Config
+	provider llm.Provider
+	wm       *workspace.Manager
+	info     *workspace.Info
+	indexed  bool // at least one language
+	cleanup  func(*selftestReport)
+}
+
+// selftestSetup runs the stages before the tools: configuration,
+// dependencies, sample workspace and indexing. It returns nil when the
+// workspace could not be created.
+func selftestSetup(ctx context.Context, report *selftestReport, configPath, dir string, offline bool, timeout time.Duration) *selftestEnv {
+	start := time.Now()
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		report.add("config", "fail", err.Error(), start)
+		return nil
+	}
+	report.add("config", "pass", configPath, start)
+
+	var temps []string
+	removeTemps := func() {
+		for _, dir := range temps {
+			os.RemoveAll(dir)
+		}
+	}
+	var provider llm.Provider
+	start = time.Now()
+	if offline {
+		dir, err := os.MkdirTemp("", "ragcode-selftest-store-")
+		if err != nil {
+			report.add("dependencies", "fail", err.Error(), start)
+			return nil
+		}
+		temps = append(temps, dir)
+		cfg.Storage.VectorDB = config.VectorDBConfig{Provider: "local", Path: dir}
+		cfg.Workspace.RegistryPath = filepath.Join(dir, "registry.json")
+		provider = bench.NewHashEmbedder(384)
+		report.add("dependencies", "skip", "offline: word-hash embeddings, temporary local store", start)
+	} else {
+		var failed []string
+		for _, result := range checkDependencies(cfg) {
+			if result.Status != "ok" {
+				failed = append(failed, fmt.Sprintf("%s: %s", result.Service, result.Message))
+			}
+		}
+		if len(failed) > 0 {
+			report.add("dependencies", "fail", strings.Join(failed, "; "), start)
+			return nil
+		}
+		if provider, err = llm.NewProvider(&cfg.LLM); err != nil {
+			report.add("dependencies", "fail", fmt.Sprintf("%s provider: %v", cfg.LLM.Provider, err), start)
+			return nil
+		}
+		report.add("dependencies", "pass", "", start)
+	}
+
+	start = time.Now()
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			report.add("workspace", "fail", err.Error(), start)
+			removeTemps()
+			return nil
+		}
+		dir = filepath.Join(home, ".local", "share", "ragcode", "selftest")
+	}
+	root, err := "", os.MkdirAll(dir, 0o755)
+	if err == nil {
+		root, err = os.MkdirTemp(dir, "workspace-")
+	}
+	if err == nil {
+		temps = append(temps, root)
+		err = writeSelftestFiles(root)
+	}
+	if err != nil {
+		report.add("workspace", "fail", err.Error(), start)
+		removeTemps()
+		return nil
+	}
+	report.Workspace = root
+
+	// The default markers leave PHP to .git; the sample workspace has none
+	if !slices.Contains(cfg.Workspace.DetectionMarkers, "composer.json") {
+		cfg.Workspace.DetectionMarkers = append(cfg.Workspace.DetectionMarkers, "composer.json")
+	}
+	store, err := storage.Open(cfg.Storage.VectorDB, "")
+	if err != nil {
+		report.add("workspace", "fail", fmt.Sprintf("vector store: %v", err), start)
+		removeTemps()
+		return nil
+	}
+	wm := workspace.NewManager(store, provider, cfg)
+	info, err := wm.DetectWorkspace(map[string]interface{}{"file_path": filepath.Join(root, "billing", "invoice.go")})
+	if err != nil {
+		report.add("workspace", "fail", err.Error(), start)
+		store.Close()
+		removeTemps()
+		return nil
+	}
+	report.add("workspace", "pass", fmt.Sprintf("%d files, languages %s", len(selftestFiles), strings.Join(info.Languages, ", ")), start)
+
+	cleanup := func(report *selftestReport) {
+		start := time.Now()
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		waitIdle(ctx, wm, info)
+		_, err := wm.DeleteIndex(ctx, info, "", false)
+		if shutdownErr := wm.Shutdown(ctx); err == nil {
+			err = shutdownErr
+		}
+		store.Close()
+		removeTemps()
+		if err != nil {
+			report.add("cleanup", "fail", err.Error(), start)
+			return
+		}
+		report.add("cleanup", "pass", "", start)
+	}
+
+	env := &selftestEnv{cfg: cfg, provider: provider, wm: wm, info: info, cleanup: cleanup}
+	for _, lang := range info.Languages {
+		start := time.Now()
+		// Creating the collection may start indexing with workspace.auto_index;
+		// IndexLanguage then finds the files indexed
+		stageCtx, cancel := context.WithTimeout(ctx, timeout)
+		_, err := wm.GetMemoryForWorkspaceLanguage(stageCtx, info, lang)
+		if err == nil {
+			waitIdle(stageCtx, wm, info)
+			err = wm.IndexLanguage(stageCtx, info, lang, info.CollectionNameForLanguage(lang))
+		}
+		cancel()
+		if err != nil {
+			report.add("index "+lang, "fail", err.Error(), start)
+			continue
+		}
+		env.indexed = true
+		report.add("index "+lang, "pass", info.CollectionNameForLanguage(lang), start)
+	}
+	return env
+}
+
+// writeSelftestFiles writes the sample workspace under root
+func writeSelftestFiles(root string) error {
+	for name, content := range selftestFiles {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// waitIdle waits until background indexing of the workspace, started by a
+// tool, is done
+func waitIdle(ctx context.Context, wm *workspace.Manager, info *workspace.Info) {
+	for _, lang := range info.Languages {
+		for wm.IsIndexing(info.ID + "-" + lang) {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(50 * time.Millisecond):
+			}
+		}
+	}
+}
+
+// selftestTools calls each tool the configuration registers, in the order
+// of registration
+func selftestTools(ctx context.Context, report *selftestReport, env *selftestEnv, timeout time.Duration) {
+	cfg, provider, wm, info := env.cfg, env.provider, env.wm, env.info
+	root := info.Root
+	goFile := filepath.Join(root, "billing", "invoice.go")
+	phpFile := filepath.Join(root, "src", "Cart.php")
+	var chunkID string
+	params := func(kv ...interface{}) func() map[string]interface{} {
+		return func() map[string]interface{} {
+			p := map[string]interface{}{"file_path": goFile}
+			for i := 0; i+1 < len(kv); i += 2 {
+				p[kv[i].(string)] = kv[i+1]
+			}
+			return p
+		}
+	}
+
+	searchTool := tools.NewSearchLocalIndexTool(nil, provider)
+	searchTool.SetWorkspaceManager(wm)
+	getFunctionTool := tools.NewGetFunctionDetailsTool(nil, provider)
+	getFunctionTool.SetWorkspaceManager(wm)
+	findTypeTool := tools.NewFindTypeDefinitionTool(nil, provider)
+	findTypeTool.SetWorkspaceManager(wm)
+	getContextTool := tools.NewGetCodeContextTool()
+	getContextTool.SetWorkspaceManager(wm)
+	listExportsTool := tools.NewListPackageExportsTool(nil, provider)
+	listExportsTool.SetWorkspaceManager(wm)
+	findImplTool := tools.NewFindImplementationsTool(nil, provider)
+	findImplTool.SetWorkspaceManager(wm)
+	searchDocsTool := tools.NewSearchDocsTool(nil, provider)
+	searchDocsTool.SetWorkspaceManager(wm)
+	hybridTool := tools.NewHybridSearchTool(nil, provider)
+	hybridTool.SetWorkspaceManager(wm)
+	localizeBuildErrorTool := tools.NewLocalizeBuildErrorTool(nil, provider)
+	localizeBuildErrorTool.SetWorkspaceManager(wm)
+	resolveStackTraceTool := tools.NewResolveStackTraceTool(nil, provider)
+	resolveStackTraceTool.SetWorkspaceManager(wm)
+
+	abSkip := ""
+	if cfg.LLM.ABEmbed == "" {
+		abSkip = "llm.ab_embed is not set"
+	}
+	editSkip := ""
+	if !cfg.Edits.Enabled {
+		editSkip = "edits.enabled is false"
+	}
+	patch := "--- a/README.md\n+++ b/README.md\n@@ -1,3 +1,3 @@\n # Selftest\n \n-Invoices are totaled by CalculateTotal in the billing package.\n+Invoices are totaled by CalculateTotal.\n"
+	stackTrace := fmt.Sprintf("panic: invoice has no lines\n\ngoroutine 1 [running]:\nexample.com/selftest/billing.CalculateTotal(...)\n\t%s:39 +0x1d\n", goFile)
+
+	cases := []selftestCase{
+		{tool: searchTool, params: params("query", "calculate the total of an invoice"), expect: "CalculateTotal"},
+		{tool: getFunctionTool, params: params("function_name", "CalculateTotal"), expect: "CalculateTotal"},
+		{tool: findTypeTool, params: params("type_name", "Invoice"), expect: "Invoice"},
+		{tool: getContextTool, params: params("start_line", float64(1), "end_line", float64(5)), expect: "package billing"},
+		{tool: listExportsTool, params: params("package", "billing"), expect: "CalculateTotal"},
+		{tool: findImplTool, params: params("symbol_name", "Totaler")},
+		{tool: searchDocsTool, params: params("query", "how are invoices totaled")},
+		{tool: hybridTool, params: params("query", "CalculateTotal invoice"), expect: "CalculateTotal"},
+		{tool: tools.NewIndexWorkspaceTool(wm), params: params()},
+		{tool: localizeBuildErrorTool, params: params("build_output", "billing/invoice.go:39:9: undefined: total")},
+		{tool: resolveStackTraceTool, params: params("stack_trace", stackTrace)},
+		{tool: tools.NewFindErrorOriginTool(wm), params: params("message", "invoice has no lines")},
+		{tool: tools.NewLoadCoverageTool(wm), params: params("coverage_file", filepath.Join(root, "coverage.out"))},
+		{tool: tools.NewSuggestTestTargetsTool(wm), params: params()},
+		{tool: tools.NewDiffAPISurfaceTool(wm), params: params()},
+		{tool: tools.NewListDeprecatedUsagesTool(wm), params: params()},
+		{tool: tools.NewGetSymbolsBulkTool(wm), params: params("symbols", []interface{}{
+			map[string]interface{}{"name": "CalculateTotal"},
+			map[string]interface{}{"name": "Cart"},
+		}), expect: "CalculateTotal"},
+		{tool: tools.NewGetChunkTool(wm), params: func() map[string]interface{} {
+			return params("chunk_id", chunkID)()
+		}},
+		{tool: tools.NewABSearchTool(wm, provider), params: params("query", "invoice total"), skip: abSkip},
+		{tool: tools.NewGrepWorkspaceTool(wm), params: params("pattern", "CalculateTotal"), expect: "invoice.go"},
+		{tool: tools.NewStructuralSearchTool(wm), params: params("pattern", "call:inv.Total()"), expect: "invoice.go"},
+		{tool: tools.NewSuggestRewritesTool(wm), params: params("pattern", "call:inv.Total()", "replacement", "inv.Sum()")},
+		{tool: tools.NewApplyPatchTool(wm), params: params("patch", patch, "dry_run", true), skip: editSkip},
+		{tool: tools.NewRollbackChangeTool(wm), params: params("list", true), skip: editSkip},
+		{tool: tools.NewCreateFileFromTemplateTool(wm), params: params("list", true), skip: editSkip},
+		{tool: tools.NewEditSessionTool(wm), params: params("action", "begin"), skip: editSkip},
+		{tool: tools.NewGetUsageReportTool(wm), params: params()},
+		{tool: tools.NewFindHookCallbacksTool(wm), params: params("hook", "init", "file_path", phpFile)},
+		{tool: tools.NewSetupWorkspaceTool(wm), params: params()},
+		{tool: tools.NewReindexFileTool(wm), params: params()},
+		{tool: tools.NewAnalyzeBufferTool(wm), params: params("content", selftestFiles["billing/invoice.go"])},
+		{tool: tools.NewGetLanguageCoverageTool(wm), params: params()},
+		{tool: tools.NewIndexStatusTool(wm), params: params()},
+		{tool: tools.NewCleanupWorkspacesTool(wm), params: params("dry_run", true)},
+		{tool: tools.NewAnalyzeRenameImpactTool(wm), params: params("symbol", "CalculateTotal", "new_name", "ComputeTotal"), expect: "CalculateTotal"},
+		{tool: tools.NewDeleteWorkspaceIndexTool(wm), params: params("dry_run", true)},
+		{tool: tools.NewGetCallGraphTool(wm), params: params("symbol_name", "CalculateTotal")},
+		{tool: tools.NewGetPackageDependenciesTool(wm), params: params()},
+		{tool: tools.NewFindSimilarCodeTool(wm, provider), params: params("symbol_name", "CalculateTotal")},
+		{tool: tools.NewFindTestsForSymbolTool(wm), params: params("symbol_name", "CalculateTotal")},
+		{tool: tools.NewReembedWorkspaceTool(wm), params: params("dry_run", true)},
+	}
+
+	selection := tools.NewToolSelection(cfg.Tools)
+	for _, c := range cases {
+		stage := "tool " + c.tool.Name()
+		start := time.Now()
+		if !selection.Allowed(c.tool.Name()) {
+			report.add(stage, "skip", fmt.Sprintf("not registered by tool profile '%s'", selection.Summary().Profile), start)
+			continue
+		}
+		if c.skip != "" {
+			report.add(stage, "skip", c.skip, start)
+			continue
+		}
+		stageCtx, cancel := context.WithTimeout(ctx, timeout)
+		result, err := c.tool.Execute(stageCtx, c.params())
+		waitIdle(stageCtx, wm, info)
+		cancel()
+		switch {
+		case err != nil:
+			report.add(stage, "fail", err.Error(), start)
+		case c.expect != "" && !strings.Contains(result, c.expect):
+			report.add(stage, "fail", fmt.Sprintf("result does not mention %q", c.expect), start)
+		default:
+			report.add(stage, "pass", "", start)
+		}
+		if m := chunkIDPattern.FindStringSubmatch(result); chunkID == "" && m != nil {
+			chunkID = m[1]
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRunSelftestOffline(t *testing.T) {
+	// Workspaces under /tmp are rejected: the sample goes next to the test
+	dir, err := os.MkdirTemp(".", ".selftest-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	var stdout, stderr bytes.Buffer
+	args := []string{"--offline", "--format", "json", "--dir", dir,
+		"--config", filepath.Join(t.TempDir(), "none.yaml")}
+	if code := runSelftest(args, &stdout, &stderr); code != 0 {
+		t.Fatalf("exit %d: %s\n%s", code, stdout.String(), stderr.String())
+	}
+	var report selftestReport
+	if err := json.Unmarshal(stdout.Bytes(), &report); err != nil {
+		t.Fatalf("json output: %v", err)
+	}
+	stages := make(map[string]string)
+	for _, s := range report.Stages {
+		stages[s.Stage] = s.Status
+	}
+	for _, stage := range []string{"index go", "index php", "index python", "tool search_code", "tool get_chunk", "cleanup"} {
+		if stages[stage] != "pass" {
+			t.Errorf("stage %s = %q, want pass", stage, stages[stage])
+		}
+	}
+	if stages["tool apply_patch"] != "skip" {
+		t.Errorf("apply_patch without edits.enabled = %q, want skip", stages["tool apply_patch"])
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("sample workspace left behind: %v", entries)
+	}
+
+	if code := runSelftest([]string{"--format", "yaml"}, &stdout, &stderr); code != 2 {
+		t.Errorf("unknown format: exit %d, want 2", code)
+	}
+}
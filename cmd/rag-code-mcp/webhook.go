@@ -0,0 +1,223 @@
+package main
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/doITmagic/rag-code-mcp/internal/workspace"
+)
+
+// webhookHandler serves POST /hooks/reindex: CI jobs and GitHub push
+// webhooks call it after a push and the matching checkout is re-indexed
+// incrementally in the background. Requests are authenticated with an
+// HMAC-SHA256 of the body in X-Hub-Signature-256, the scheme GitHub uses,
+// so the server's bearer token never has to be shared with CI.
+//
+// The body is either {"path": ..., "repo": ..., "branch": ...} or a GitHub
+// push event, whose repository.full_name is looked up in repos.
+type webhookHandler struct {
+	secret string
+	repos  map[string]string // repository full name -> local checkout
+	pull   bool
+	wm     *workspace.Manager
+}
+
+// webhookPayload holds the fields read from both body formats.
+type webhookPayload struct {
+	Path       string `json:"path"`
+	Repo       string `json:"repo"`
+	Branch     string `json:"branch"`
+	Ref        string `json:"ref"`
+	Deleted    bool   `json:"deleted"`
+	Repository struct {
+		FullName string `json:"full_name"`
+	} `json:"repository"`
+}
+
+type webhookResponse struct {
+	Status    string   `json:"status"`
+	Root      string   `json:"root,omitempty"`
+	Branch    string   `json:"branch,omitempty"`
+	Languages []string `json:"languages,omitempty"`
+	Reason    string   `json:"reason,omitempty"`
+	Error     string   `json:"error,omitempty"`
+}
+
+const webhookMaxBody = 25 << 20 // GitHub caps payloads at 25 MB
+
+func (h *webhookHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, webhookMaxBody))
+	if err != nil {
+		writeWebhook(w, http.StatusRequestEntityTooLarge, webhookResponse{Status: "error", Error: err.Error()})
+		return
+	}
+	if !verifyWebhookSignature(h.secret, body, r.Header.Get("X-Hub-Signature-256")) {
+		logger.Warn("🪝 Webhook from %s rejected: invalid signature", r.RemoteAddr)
+		writeWebhook(w, http.StatusUnauthorized, webhookResponse{Status: "error", Error: "missing or invalid X-Hub-Signature-256"})
+		return
+	}
+	switch event := r.Header.Get("X-GitHub-Event"); event {
+	case "", "push":
+	case "ping":
+		writeWebhook(w, http.StatusOK, webhookResponse{Status: "pong"})
+		return
+	default:
+		writeWebhook(w, http.StatusAccepted, webhookResponse{Status: "skipped", Reason: fmt.Sprintf("event %q does not trigger re-indexing", event)})
+		return
+	}
+
+	var p webhookPayload
+	if err := json.Unmarshal(body, &p); err != nil {
+		writeWebhook(w, http.StatusBadRequest, webhookResponse{Status: "error", Error: fmt.Sprintf("invalid JSON body: %v", err)})
+		return
+	}
+	root, branch, skip, err := resolveWebhook(p, h.repos)
+	if err != nil {
+		writeWebhook(w, http.StatusBadRequest, webhookResponse{Status: "error", Error: err.Error()})
+		return
+	}
+	if skip != "" {
+		writeWebhook(w, http.StatusAccepted, webhookResponse{Status: "skipped", Root: root, Branch: branch, Reason: skip})
+		return
+	}
+	if branch != "" {
+		if current := checkedOutBranch(r.Context(), root); current != "" && current != branch {
+			writeWebhook(w, http.StatusAccepted, webhookResponse{
+				Status: "skipped", Root: root, Branch: branch,
+				Reason: fmt.Sprintf("%s has %s checked out", root, current),
+			})
+			return
+		}
+	}
+
+	info, err := h.wm.DetectWorkspace(map[string]interface{}{"file_path": root})
+	if err != nil {
+		writeWebhook(w, http.StatusBadRequest, webhookResponse{Status: "error", Root: root, Error: fmt.Sprintf("failed to detect workspace: %v", err)})
+		return
+	}
+	// Shutdown waits for the job; pushes to one checkout pull one at a time
+	h.wm.RunExclusive("webhook:"+info.Root, func(ctx context.Context) {
+		h.reindex(ctx, info)
+	})
+
+	logger.Info("🪝 Webhook accepted: re-indexing %s (branch %q)", info.Root, branch)
+	writeWebhook(w, http.StatusAccepted, webhookResponse{Status: "accepted", Root: info.Root, Branch: branch, Languages: info.Languages})
+}
+
+// reindex pulls the checkout when configured and starts incremental
+// indexing. It runs after the response is sent, so webhook senders with
+// short timeouts are not kept waiting, and stops when ctx is cancelled by
+// shutdown.
+func (h *webhookHandler) reindex(ctx context.Context, info *workspace.Info) {
+	if h.pull {
+		pullCtx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+		defer cancel()
+		out, err := exec.CommandContext(pullCtx, "git", "-C", info.Root, "pull", "--ff-only").CombinedOutput()
+		if err != nil {
+			logger.Error("❌ Webhook: git pull in %s failed: %v: %s", info.Root, err, strings.TrimSpace(string(out)))
+			return
+		}
+	}
+	if ctx.Err() != nil {
+		return
+	}
+	if started := h.wm.ReindexWorkspace(info); len(started) > 0 {
+		logger.Info("🪝 Webhook: indexing %s started for %s", strings.Join(started, ", "), info.Root)
+	}
+}
+
+// verifyWebhookSignature checks a "sha256=<hex>" HMAC-SHA256 signature of
+// body in constant time.
+func verifyWebhookSignature(secret string, body []byte, signature string) bool {
+	got, ok := strings.CutPrefix(signature, "sha256=")
+	if !ok {
+		return false
+	}
+	sig, err := hex.DecodeString(got)
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hmac.Equal(sig, mac.Sum(nil))
+}
+
+// resolveWebhook returns the checkout and branch a payload refers to. A
+// non-empty skip explains why the push needs no re-indexing (a tag or a
+// deleted branch). When repos is configured, only its checkouts can be
+// re-indexed.
+func resolveWebhook(p webhookPayload, repos map[string]string) (root, branch, skip string, err error) {
+	branch = p.Branch
+	if branch == "" && p.Ref != "" {
+		var ok bool
+		if branch, ok = strings.CutPrefix(p.Ref, "refs/heads/"); !ok {
+			return "", "", fmt.Sprintf("%s is not a branch", p.Ref), nil
+		}
+	}
+	repo := p.Repo
+	if repo == "" {
+		repo = p.Repository.FullName
+	}
+
+	root = p.Path
+	if root == "" {
+		if repo == "" {
+			return "", "", "", fmt.Errorf("path or repo is required")
+		}
+		var ok bool
+		if root, ok = repos[repo]; !ok {
+			return "", "", "", fmt.Errorf("repository %s is not configured in server.webhook_repos", repo)
+		}
+	}
+	if !filepath.IsAbs(root) {
+		return "", "", "", fmt.Errorf("path must be absolute: %s", root)
+	}
+	root = filepath.Clean(root)
+	if len(repos) > 0 && !insideWebhookRepo(root, repos) {
+		return "", "", "", fmt.Errorf("%s is not inside a repository configured in server.webhook_repos", root)
+	}
+	if p.Deleted {
+		return root, branch, fmt.Sprintf("branch %s was deleted", branch), nil
+	}
+	return root, branch, "", nil
+}
+
+func insideWebhookRepo(path string, repos map[string]string) bool {
+	for _, dir := range repos {
+		rel, err := filepath.Rel(filepath.Clean(dir), path)
+		if err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkedOutBranch returns the branch checked out in root, or "" when it
+// cannot be determined (not a git checkout, detached HEAD).
+func checkedOutBranch(ctx context.Context, root string) string {
+	out, err := exec.CommandContext(ctx, "git", "-C", root, "rev-parse", "--abbrev-ref", "HEAD").Output()
+	if err != nil {
+		return ""
+	}
+	branch := strings.TrimSpace(string(out))
+	if branch == "HEAD" {
+		return ""
+	}
+	return branch
+}
+
+func writeWebhook(w http.ResponseWriter, status int, resp webhookResponse) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(resp)
+}
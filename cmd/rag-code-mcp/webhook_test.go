@@ -0,0 +1,88 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestWebhook(t *testing.T) {
+	body := []byte(`{"ref":"refs/heads/main","repository":{"full_name":"acme/api"}}`)
+	mac := hmac.New(sha256.New, []byte("hook-secret"))
+	mac.Write(body)
+	signature := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+
+	if !verifyWebhookSignature("hook-secret", body, signature) {
+		t.Error("valid signature rejected")
+	}
+	for _, bad := range []string{"", "sha256=zz", strings.ToUpper(signature), "sha1=" + signature[7:]} {
+		if verifyWebhookSignature("hook-secret", body, bad) {
+			t.Errorf("signature %q accepted", bad)
+		}
+	}
+	if verifyWebhookSignature("other", body, signature) {
+		t.Error("signature with another secret accepted")
+	}
+
+	h := &webhookHandler{secret: "hook-secret"}
+	send := func(event, sig string) int {
+		req := httptest.NewRequest(http.MethodPost, "/hooks/reindex", strings.NewReader(string(body)))
+		req.Header.Set("X-GitHub-Event", event)
+		req.Header.Set("X-Hub-Signature-256", sig)
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec.Code
+	}
+	if code := send("push", "sha256=00"); code != http.StatusUnauthorized {
+		t.Errorf("bad signature: status %d", code)
+	}
+	if code := send("ping", signature); code != http.StatusOK {
+		t.Errorf("ping: status %d", code)
+	}
+	if code := send("issues", signature); code != http.StatusAccepted {
+		t.Errorf("other event: status %d", code)
+	}
+
+	repos := map[string]string{"acme/api": "/srv/api"}
+	tests := []struct {
+		name    string
+		payload webhookPayload
+		root    string
+		branch  string
+		skip    bool
+		wantErr bool
+	}{
+		{name: "path and branch", payload: webhookPayload{Path: "/srv/api/", Branch: "dev"}, root: "/srv/api", branch: "dev"},
+		{name: "subdirectory", payload: webhookPayload{Path: "/srv/api/services"}, root: "/srv/api/services"},
+		{name: "repo name", payload: webhookPayload{Repo: "acme/api"}, root: "/srv/api"},
+		{name: "github push", payload: func() webhookPayload {
+			p := webhookPayload{Ref: "refs/heads/main"}
+			p.Repository.FullName = "acme/api"
+			return p
+		}(), root: "/srv/api", branch: "main"},
+		{name: "tag", payload: webhookPayload{Repo: "acme/api", Ref: "refs/tags/v1.0.0"}, skip: true},
+		{name: "deleted branch", payload: webhookPayload{Repo: "acme/api", Ref: "refs/heads/old", Deleted: true}, root: "/srv/api", branch: "old", skip: true},
+		{name: "unknown repo", payload: webhookPayload{Repo: "acme/web"}, wantErr: true},
+		{name: "outside repos", payload: webhookPayload{Path: "/srv/api-old"}, wantErr: true},
+		{name: "relative path", payload: webhookPayload{Path: "srv/api"}, wantErr: true},
+		{name: "empty", payload: webhookPayload{}, wantErr: true},
+	}
+	for _, tt := range tests {
+		root, branch, skip, err := resolveWebhook(tt.payload, repos)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: err = %v, wantErr %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if root != tt.root || branch != tt.branch || (skip != "") != tt.skip {
+			t.Errorf("%s: got (%q, %q, skip %q), want (%q, %q, skip %v)", tt.name, root, branch, skip, tt.root, tt.branch, tt.skip)
+		}
+	}
+
+	if root, _, _, err := resolveWebhook(webhookPayload{Path: "/anywhere"}, nil); err != nil || root != "/anywhere" {
+		t.Errorf("any path without webhook_repos: got %q, %v", root, err)
+	}
+}
@@ -0,0 +1,177 @@
+// Package bench measures indexing and search on a synthetic repository:
+// analyzer throughput, embedding throughput, vector store upserts and
+// search latency.
+package bench
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/doITmagic/rag-code-mcp/internal/llm"
+	"github.com/doITmagic/rag-code-mcp/internal/ragcode"
+	"github.com/doITmagic/rag-code-mcp/internal/storage"
+)
+
+// Options configures a benchmark run
+type Options struct {
+	Files     int // source files of the synthetic repository
+	Queries   int // searches timed
+	BatchSize int // texts per embedding request
+
+	Embedder llm.Provider
+	// Store holds Collection, which the run creates and deletes; it must
+	// not exist before
+	Store      storage.VectorStore
+	Collection string
+}
+
+// Result holds the measures of a run
+type Result struct {
+	Files            int     `json:"files"`
+	Chunks           int     `json:"chunks"`
+	ChunksPerSec     float64 `json:"chunks_per_sec"`
+	Embedder         string  `json:"embedder"`
+	Dimension        int     `json:"dimension"`
+	EmbeddingsPerSec float64 `json:"embeddings_per_sec"`
+	UpsertsPerSec    float64 `json:"upserts_per_sec"`
+	Queries          int     `json:"queries"`
+	SearchMeanMs     float64 `json:"search_mean_ms"`
+	SearchP50Ms      float64 `json:"search_p50_ms"`
+	SearchP95Ms      float64 `json:"search_p95_ms"`
+}
+
+// Run writes the synthetic repository to a temporary directory, indexes it
+// into the collection and times each stage. The collection is deleted when
+// the run ends.
+func Run(ctx context.Context, opts Options) (Result, error) {
+	if opts.Files <= 0 {
+		opts.Files = 200
+	}
+	if opts.Queries <= 0 {
+		opts.Queries = 200
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 16
+	}
+	res := Result{Files: opts.Files, Queries: opts.Queries, Embedder: opts.Embedder.Name()}
+
+	dir, err := os.MkdirTemp("", "ragcode-bench-")
+	if err != nil {
+		return res, err
+	}
+	defer os.RemoveAll(dir)
+	if err := WriteSyntheticRepo(dir, opts.Files); err != nil {
+		return res, fmt.Errorf("failed to write the synthetic repository: %w", err)
+	}
+
+	// Analysis
+	analyzer := ragcode.NewAnalyzerManager().CodeAnalyzerForProjectType("go")
+	start := time.Now()
+	chunks, err := analyzer.AnalyzePaths([]string{dir})
+	if err != nil {
+		return res, fmt.Errorf("failed to analyze: %w", err)
+	}
+	res.Chunks = len(chunks)
+	res.ChunksPerSec = rate(len(chunks), time.Since(start))
+	if len(chunks) == 0 {
+		return res, fmt.Errorf("the synthetic repository gave no chunks")
+	}
+
+	// Embedding, in batches like indexing
+	texts := make([]string, len(chunks))
+	for i, ch := range chunks {
+		texts[i] = strings.TrimSpace(strings.Join([]string{ch.Docstring, ch.Signature, ch.Code}, "\n\n"))
+	}
+	start = time.Now()
+	vectors := make([][]float64, 0, len(texts))
+	for i := 0; i < len(texts); i += opts.BatchSize {
+		end := min(i+opts.BatchSize, len(texts))
+		batch, err := llm.EmbedBatch(ctx, opts.Embedder, texts[i:end])
+		if err != nil {
+			return res, fmt.Errorf("failed to embed: %w", err)
+		}
+		vectors = append(vectors, batch...)
+	}
+	res.EmbeddingsPerSec = rate(len(vectors), time.Since(start))
+	res.Dimension = len(vectors[0])
+
+	// Upserts, one point at a time like the indexer stores documents
+	if err := opts.Store.CreateCollection(ctx, opts.Collection, res.Dimension); err != nil {
+		return res, fmt.Errorf("failed to create collection: %w", err)
+	}
+	defer func() {
+		cleanup, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		opts.Store.DeleteCollection(cleanup, opts.Collection)
+	}()
+	start = time.Now()
+	for i, ch := range chunks {
+		payload := map[string]interface{}{
+			"content": ch.Code,
+			"name":    ch.Name,
+			"type":    ch.Type,
+			"file":    ch.FilePath,
+		}
+		if err := opts.Store.Upsert(ctx, strconv.Itoa(i+1), vectors[i], payload); err != nil {
+			return res, fmt.Errorf("failed to upsert: %w", err)
+		}
+	}
+	res.UpsertsPerSec = rate(len(chunks), time.Since(start))
+
+	// Search latency, without the embedding of the queries
+	rnd := rand.New(rand.NewSource(1))
+	queries := make([]string, opts.Queries)
+	for i := range queries {
+		queries[i] = verbs[rnd.Intn(len(verbs))] + " the " + nouns[rnd.Intn(len(nouns))] + " of a " + nouns[rnd.Intn(len(nouns))]
+	}
+	queryVectors, err := llm.EmbedBatch(ctx, opts.Embedder, queries)
+	if err != nil {
+		return res, fmt.Errorf("failed to embed queries: %w", err)
+	}
+	latencies := make([]time.Duration, 0, len(queryVectors))
+	for _, q := range queryVectors {
+		start := time.Now()
+		if _, err := opts.Store.Search(ctx, q, 10); err != nil {
+			return res, fmt.Errorf("failed to search: %w", err)
+		}
+		latencies = append(latencies, time.Since(start))
+	}
+	res.SearchMeanMs, res.SearchP50Ms, res.SearchP95Ms = latencyStats(latencies)
+	return res, nil
+}
+
+// rate returns n per second over d
+func rate(n int, d time.Duration) float64 {
+	if d <= 0 {
+		d = time.Nanosecond
+	}
+	return float64(n) / d.Seconds()
+}
+
+// latencyStats returns the mean, median and 95th percentile of latencies, in
+// milliseconds
+func latencyStats(latencies []time.Duration) (mean, p50, p95 float64) {
+	if len(latencies) == 0 {
+		return 0, 0, 0
+	}
+	sorted := append([]time.Duration(nil), latencies...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	var total time.Duration
+	for _, l := range sorted {
+		total += l
+	}
+	ms := func(d time.Duration) float64 { return float64(d) / float64(time.Millisecond) }
+	// Nearest-rank percentiles
+	rank := func(p float64) time.Duration {
+		i := int(math.Ceil(p*float64(len(sorted)))) - 1
+		return sorted[max(0, min(i, len(sorted)-1))]
+	}
+	return ms(total / time.Duration(len(sorted))), ms(rank(0.50)), ms(rank(0.95))
+}
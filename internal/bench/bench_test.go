@@ -0,0 +1,138 @@
+package bench
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/doITmagic/rag-code-mcp/internal/ragcode"
+	"github.com/doITmagic/rag-code-mcp/internal/storage"
+)
+
+func newLocalStore(tb testing.TB, collection string) storage.VectorStore {
+	tb.Helper()
+	s, err := storage.NewLocalStore(storage.LocalConfig{Dir: tb.TempDir(), Collection: collection})
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return s
+}
+
+func TestRun(t *testing.T) {
+	res, err := Run(context.Background(), Options{
+		Files:      12,
+		Queries:    20,
+		Embedder:   NewHashEmbedder(64),
+		Store:      newLocalStore(t, "bench"),
+		Collection: "bench",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A struct, an interface, 3 methods and 2 functions per file
+	if res.Chunks < 12*7 {
+		t.Errorf("chunks = %d, want at least %d", res.Chunks, 12*7)
+	}
+	if res.Dimension != 64 || res.Embedder != "hash" {
+		t.Errorf("embedder %s of dimension %d, want hash of 64", res.Embedder, res.Dimension)
+	}
+	if res.ChunksPerSec <= 0 || res.EmbeddingsPerSec <= 0 || res.UpsertsPerSec <= 0 {
+		t.Errorf("throughputs should be positive: %+v", res)
+	}
+	if res.SearchP50Ms > res.SearchP95Ms {
+		t.Errorf("p50 %.3fms above p95 %.3fms", res.SearchP50Ms, res.SearchP95Ms)
+	}
+}
+
+func TestWriteSyntheticRepoIsDeterministic(t *testing.T) {
+	a, b := t.TempDir(), t.TempDir()
+	if err := WriteSyntheticRepo(a, 15); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteSyntheticRepo(b, 15); err != nil {
+		t.Fatal(err)
+	}
+	files, _ := filepath.Glob(filepath.Join(a, "internal", "*", "*.go"))
+	if len(files) != 15 {
+		t.Fatalf("%d files, want 15", len(files))
+	}
+	for _, f := range files {
+		rel, _ := filepath.Rel(a, f)
+		want, _ := os.ReadFile(f)
+		got, err := os.ReadFile(filepath.Join(b, rel))
+		if err != nil || string(got) != string(want) {
+			t.Errorf("%s differs between two runs", rel)
+		}
+	}
+}
+
+func TestLatencyStats(t *testing.T) {
+	var latencies []time.Duration
+	for i := 100; i >= 1; i-- {
+		latencies = append(latencies, time.Duration(i)*time.Millisecond)
+	}
+	mean, p50, p95 := latencyStats(latencies)
+	if mean != 50.5 || p50 != 50 || p95 != 95 {
+		t.Errorf("latencyStats = %v, %v, %v; want 50.5, 50, 95", mean, p50, p95)
+	}
+}
+
+func BenchmarkAnalyze(b *testing.B) {
+	dir := b.TempDir()
+	if err := WriteSyntheticRepo(dir, 200); err != nil {
+		b.Fatal(err)
+	}
+	analyzer := ragcode.NewAnalyzerManager().CodeAnalyzerForProjectType("go")
+	b.ResetTimer()
+	chunks := 0
+	for i := 0; i < b.N; i++ {
+		got, err := analyzer.AnalyzePaths([]string{dir})
+		if err != nil {
+			b.Fatal(err)
+		}
+		chunks += len(got)
+	}
+	b.ReportMetric(float64(chunks)/b.Elapsed().Seconds(), "chunks/s")
+}
+
+func BenchmarkLocalUpsert(b *testing.B) {
+	ctx := context.Background()
+	store := newLocalStore(b, "bench")
+	if err := store.CreateCollection(ctx, "bench", 384); err != nil {
+		b.Fatal(err)
+	}
+	embedder := NewHashEmbedder(384)
+	vector, _ := embedder.Embed(ctx, "compute the invoice of a customer")
+	payload := map[string]interface{}{"name": "ComputeInvoice", "type": "function", "file": "invoice.go"}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := store.Upsert(ctx, strconv.Itoa(i+1), vector, payload); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkLocalSearch(b *testing.B) {
+	ctx := context.Background()
+	store := newLocalStore(b, "bench")
+	if err := store.CreateCollection(ctx, "bench", 384); err != nil {
+		b.Fatal(err)
+	}
+	embedder := NewHashEmbedder(384)
+	for i := 0; i < 10000; i++ {
+		v, _ := embedder.Embed(ctx, nouns[i%len(nouns)]+" "+verbs[i%len(verbs)]+" "+strconv.Itoa(i))
+		if err := store.Upsert(ctx, strconv.Itoa(i+1), v, nil); err != nil {
+			b.Fatal(err)
+		}
+	}
+	query, _ := embedder.Embed(ctx, "validate the order of a customer")
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := store.Search(ctx, query, 10); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package bench
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"strings"
+	"unicode"
+
+	"github.com/doITmagic/rag-code-mcp/internal/llm"
+)
+
+// HashEmbedder embeds texts without a model: every word adds to a dimension
+// picked by its hash. The vectors only capture shared words, which is
+// enough to time the rest of the pipeline without Ollama.
+type HashEmbedder struct {
+	dim int
+}
+
+// NewHashEmbedder creates an embedder of dim dimensions
+func NewHashEmbedder(dim int) *HashEmbedder {
+	return &HashEmbedder{dim: dim}
+}
+
+// Generate is not supported: the embedder has no language model
+func (h *HashEmbedder) Generate(ctx context.Context, prompt string, opts ...llm.GenerateOption) (string, error) {
+	return "", fmt.Errorf("hash embedder cannot generate text")
+}
+
+// GenerateStream is not supported: the embedder has no language model
+func (h *HashEmbedder) GenerateStream(ctx context.Context, prompt string, opts ...llm.GenerateOption) (<-chan string, <-chan error) {
+	textChan := make(chan string)
+	errChan := make(chan error, 1)
+	close(textChan)
+	errChan <- fmt.Errorf("hash embedder cannot generate text")
+	close(errChan)
+	return textChan, errChan
+}
+
+// Embed returns the normalized word-hash vector of text
+func (h *HashEmbedder) Embed(ctx context.Context, text string) ([]float64, error) {
+	v := make([]float64, h.dim)
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for _, w := range words {
+		f := fnv.New64a()
+		f.Write([]byte(w))
+		sum := f.Sum64()
+		sign := 1.0
+		if sum&1 == 1 {
+			sign = -1
+		}
+		v[(sum>>1)%uint64(h.dim)] += sign
+	}
+
+	var norm float64
+	for _, x := range v {
+		norm += x * x
+	}
+	if norm == 0 {
+		v[0] = 1
+		return v, nil
+	}
+	norm = math.Sqrt(norm)
+	for i := range v {
+		v[i] /= norm
+	}
+	return v, nil
+}
+
+// EmbedBatch embeds several texts
+func (h *HashEmbedder) EmbedBatch(ctx context.Context, texts []string) ([][]float64, error) {
+	out := make([][]float64, len(texts))
+	for i, text := range texts {
+		out[i], _ = h.Embed(ctx, text)
+	}
+	return out, nil
+}
+
+// EmbeddingDimension returns the vector size
+func (h *HashEmbedder) EmbeddingDimension(ctx context.Context) (int, error) {
+	return h.dim, nil
+}
+
+// Name returns the provider name
+func (h *HashEmbedder) Name() string {
+	return "hash"
+}
+
+var _ llm.Provider = (*HashEmbedder)(nil)
+var _ llm.BatchEmbedder = (*HashEmbedder)(nil)
+var _ llm.DimensionReporter = (*HashEmbedder)(nil)
@@ -0,0 +1,85 @@
+package bench
+
+import (
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// filesPerPackage is the number of files of each package of the synthetic
+// repository
+const filesPerPackage = 10
+
+// nouns and verbs name the symbols of the synthetic repository, so
+// searches for them have lexical matches of varying strength
+var (
+	nouns = []string{"invoice", "customer", "order", "payment", "account", "session", "token", "report",
+		"cache", "queue", "worker", "schedule", "ledger", "refund", "discount", "shipment", "address",
+		"profile", "audit", "webhook", "export", "import", "tenant", "quota", "metric", "alert"}
+	verbs = []string{"load", "save", "validate", "compute", "render", "sync", "merge", "split", "publish",
+		"resolve", "archive", "notify", "refresh", "parse", "encode", "apply", "cancel", "retry"}
+)
+
+// WriteSyntheticRepo writes a Go module of files source files to dir: a
+// struct with methods, functions and an interface per file, documented
+// like real code. The same files count always gives the same repository.
+func WriteSyntheticRepo(dir string, files int) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/synthetic\n\ngo 1.21\n"), 0644); err != nil {
+		return err
+	}
+
+	rnd := rand.New(rand.NewSource(int64(files)))
+	for i := 0; i < files; i++ {
+		pkg := fmt.Sprintf("%s%d", nouns[(i/filesPerPackage)%len(nouns)], i/filesPerPackage)
+		pkgDir := filepath.Join(dir, "internal", pkg)
+		if err := os.MkdirAll(pkgDir, 0755); err != nil {
+			return err
+		}
+		noun := nouns[rnd.Intn(len(nouns))]
+		src := syntheticFile(rnd, pkg, fmt.Sprintf("%s%d", title(noun), i), noun)
+		if err := os.WriteFile(filepath.Join(pkgDir, fmt.Sprintf("%s_%d.go", noun, i)), []byte(src), 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// syntheticFile returns the source of one file of package pkg around the
+// struct typeName
+func syntheticFile(rnd *rand.Rand, pkg, typeName, noun string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "// Package %s handles %ss.\npackage %s\n\nimport (\n\t\"errors\"\n\t\"strings\"\n)\n\n", pkg, noun, pkg)
+
+	fmt.Fprintf(&b, "// %s is a %s tracked by the billing system. It keeps the\n// identifiers and amounts needed to %s it.\n", typeName, noun, verbs[rnd.Intn(len(verbs))])
+	fmt.Fprintf(&b, "type %s struct {\n\tID     string\n\tOwner  string\n\tAmount int64\n\tTags   []string\n}\n\n", typeName)
+
+	fmt.Fprintf(&b, "// %sStore persists %ss.\ntype %sStore interface {\n", typeName, noun, typeName)
+	fmt.Fprintf(&b, "\tLoad(id string) (*%s, error)\n\tSave(v *%s) error\n}\n\n", typeName, typeName)
+
+	for m := 0; m < 3; m++ {
+		verb := verbs[rnd.Intn(len(verbs))]
+		fmt.Fprintf(&b, "// %s%d %ss the %s, returning an error when it is incomplete.\n", title(verb), m, verb, noun)
+		fmt.Fprintf(&b, "func (x *%s) %s%d(limit int) error {\n", typeName, title(verb), m)
+		fmt.Fprintf(&b, "\tif x.ID == \"\" {\n\t\treturn errors.New(\"%s: missing id\")\n\t}\n", noun)
+		fmt.Fprintf(&b, "\tfor i := 0; i < limit && i < len(x.Tags); i++ {\n\t\tx.Tags[i] = strings.ToLower(x.Tags[i])\n\t}\n")
+		fmt.Fprintf(&b, "\tx.Amount += int64(limit) * %d\n\treturn nil\n}\n\n", rnd.Intn(100)+1)
+	}
+
+	for f := 0; f < 2; f++ {
+		verb := verbs[rnd.Intn(len(verbs))]
+		other := nouns[rnd.Intn(len(nouns))]
+		fmt.Fprintf(&b, "// %s%s%d %ss the %s of a %s and reports how many changed.\n", title(verb), title(other), f, verb, other, noun)
+		fmt.Fprintf(&b, "func %s%s%d(items []*%s, owner string) int {\n\tchanged := 0\n", title(verb), title(other), f, typeName)
+		fmt.Fprintf(&b, "\tfor _, it := range items {\n\t\tif it.Owner != owner {\n\t\t\tit.Owner = owner\n\t\t\tchanged++\n\t\t}\n\t}\n\treturn changed\n}\n\n")
+	}
+	return b.String()
+}
+
+func title(s string) string {
+	return strings.ToUpper(s[:1]) + s[1:]
+}
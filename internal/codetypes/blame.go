@@ -0,0 +1,34 @@
+package codetypes
+
+import "time"
+
+// Chunk metadata keys for git blame info, set when git blame annotation is
+// enabled (rag_code.git_blame).
+const (
+	MetaLastModified = "last_modified" // RFC 3339 commit time of the most recently changed line
+	MetaLastAuthor   = "last_author"
+)
+
+// SetLastModified records when the chunk last changed and who changed it.
+func SetLastModified(ch *CodeChunk, t time.Time, author string) {
+	if ch.Metadata == nil {
+		ch.Metadata = make(map[string]any)
+	}
+	ch.Metadata[MetaLastModified] = t.UTC().Format(time.RFC3339)
+	if author != "" {
+		ch.Metadata[MetaLastAuthor] = author
+	}
+}
+
+// LastModified returns the time recorded by SetLastModified, if any.
+func (c CodeChunk) LastModified() (time.Time, bool) {
+	s, _ := c.Metadata[MetaLastModified].(string)
+	if s == "" {
+		return time.Time{}, false
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return t, true
+}
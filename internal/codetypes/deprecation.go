@@ -0,0 +1,30 @@
+package codetypes
+
+// Chunk metadata keys for normalized deprecation info. Analyzers set them via
+// MarkDeprecated so every language exposes deprecation the same way.
+const (
+	MetaDeprecated      = "deprecated"
+	MetaDeprecationNote = "deprecation_note"
+)
+
+// MarkDeprecated flags a chunk as deprecated with an optional note (the text
+// of @deprecated, "Deprecated:" or the warning message).
+func MarkDeprecated(ch *CodeChunk, note string) {
+	if ch.Metadata == nil {
+		ch.Metadata = make(map[string]any)
+	}
+	ch.Metadata[MetaDeprecated] = true
+	if note != "" {
+		ch.Metadata[MetaDeprecationNote] = note
+	}
+}
+
+// Deprecation reports whether the chunk is deprecated and the attached note.
+func (c CodeChunk) Deprecation() (bool, string) {
+	deprecated, _ := c.Metadata[MetaDeprecated].(bool)
+	if !deprecated {
+		return false, ""
+	}
+	note, _ := c.Metadata[MetaDeprecationNote].(string)
+	return true, note
+}
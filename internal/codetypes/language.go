@@ -0,0 +1,103 @@
+package codetypes
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Language is the canonical, lowercase name of a programming language, as
+// stored on chunks and in the "language" metadata of indexed documents.
+type Language string
+
+const (
+	LanguageGo         Language = "go"
+	LanguagePHP        Language = "php"
+	LanguagePython     Language = "python"
+	LanguageRust       Language = "rust"
+	LanguageHTML       Language = "html"
+	LanguageJavaScript Language = "javascript"
+	LanguageTypeScript Language = "typescript"
+	LanguageJava       Language = "java"
+	LanguageRuby       Language = "ruby"
+	LanguageC          Language = "c"
+	LanguageCPP        Language = "cpp"
+	LanguageCSharp     Language = "csharp"
+)
+
+// knownLanguages lists every canonical language
+var knownLanguages = []Language{
+	LanguageGo, LanguagePHP, LanguagePython, LanguageRust, LanguageHTML,
+	LanguageJavaScript, LanguageTypeScript, LanguageJava, LanguageRuby,
+	LanguageC, LanguageCPP, LanguageCSharp,
+}
+
+// languageAliases maps other spellings and file extensions to the canonical
+// name. Keys are lowercase.
+var languageAliases = map[string]Language{
+	"golang":  LanguageGo,
+	"py":      LanguagePython,
+	"python3": LanguagePython,
+	"rs":      LanguageRust,
+	"htm":     LanguageHTML,
+	"js":      LanguageJavaScript,
+	"jsx":     LanguageJavaScript,
+	"mjs":     LanguageJavaScript,
+	"node":    LanguageJavaScript,
+	"ts":      LanguageTypeScript,
+	"tsx":     LanguageTypeScript,
+	"rb":      LanguageRuby,
+	"c++":     LanguageCPP,
+	"cc":      LanguageCPP,
+	"cxx":     LanguageCPP,
+	"cs":      LanguageCSharp,
+	"c#":      LanguageCSharp,
+}
+
+// NormalizeLanguage maps a language name to its canonical form, ignoring
+// case, surrounding spaces and a leading dot: "Golang" and ".py" give go and
+// python. Unknown names are returned lowercased; Valid tells them apart.
+func NormalizeLanguage(name string) Language {
+	s := strings.TrimPrefix(strings.ToLower(strings.TrimSpace(name)), ".")
+	if lang, ok := languageAliases[s]; ok {
+		return lang
+	}
+	return Language(s)
+}
+
+// ParseLanguage normalizes name and fails when it is not a known language
+func ParseLanguage(name string) (Language, error) {
+	lang := NormalizeLanguage(name)
+	if !lang.Valid() {
+		return "", fmt.Errorf("unknown language %q", name)
+	}
+	return lang, nil
+}
+
+// Valid reports whether l is a canonical language name
+func (l Language) Valid() bool {
+	for _, known := range knownLanguages {
+		if l == known {
+			return true
+		}
+	}
+	return false
+}
+
+// String returns the language name
+func (l Language) String() string {
+	return string(l)
+}
+
+// Languages returns the canonical languages
+func Languages() []Language {
+	return append([]Language(nil), knownLanguages...)
+}
+
+// NormalizeChunkLanguages rewrites the language of chunks to its canonical
+// form. Analyzer results go through it before they are stored, so filters on
+// the language never miss because of casing or synonyms.
+func NormalizeChunkLanguages(chunks []CodeChunk) {
+	for i := range chunks {
+		chunks[i].Language = NormalizeLanguage(string(chunks[i].Language))
+	}
+}
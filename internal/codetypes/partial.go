@@ -0,0 +1,35 @@
+package codetypes
+
+// MetaPartial is the chunk metadata key set on symbols extracted from a file
+// with syntax errors. The analyzer recovered what it could: the symbol may
+// be incomplete, and others of the file may be missing.
+const MetaPartial = "partial"
+
+// MarkPartial flags the chunks of files with syntax errors the analyzer
+// recovered from, i.e. errors not marked Skipped.
+func MarkPartial(chunks []CodeChunk, errs []ParseError) {
+	broken := make(map[string]bool)
+	for _, e := range errs {
+		if !e.Skipped {
+			broken[e.FilePath] = true
+		}
+	}
+	if len(broken) == 0 {
+		return
+	}
+	for i := range chunks {
+		if !broken[chunks[i].FilePath] {
+			continue
+		}
+		if chunks[i].Metadata == nil {
+			chunks[i].Metadata = make(map[string]any)
+		}
+		chunks[i].Metadata[MetaPartial] = true
+	}
+}
+
+// Partial reports whether the chunk comes from a file with syntax errors.
+func (c CodeChunk) Partial() bool {
+	partial, _ := c.Metadata[MetaPartial].(bool)
+	return partial
+}
@@ -5,10 +5,10 @@ package codetypes
 // interface declaration) that is stored in vector search.
 type CodeChunk struct {
 	// Symbol metadata
-	Type     string // function | method | type | interface | file
-	Name     string // Symbol name (or file base name for Type=file)
-	Package  string // Package/module name
-	Language string // go | php | python | typescript etc
+	Type     string   // function | method | type | interface | file
+	Name     string   // Symbol name (or file base name for Type=file)
+	Package  string   // Package/module name
+	Language Language // Canonical language name, see NormalizeLanguage
 
 	// Source location
 	FilePath  string // Relative path from repository root
@@ -124,6 +124,46 @@ type PathAnalyzer interface {
 	AnalyzePaths(paths []string) ([]CodeChunk, error)
 }
 
+// CallSite is a call made from the body of a function or method. Analyzers
+// that resolve calls store them in the "calls" chunk metadata.
+type CallSite struct {
+	Name     string `json:"name"`
+	Receiver string `json:"receiver,omitempty"` // expression the method is called on, e.g. "s.store" or "self"
+	Line     int    `json:"line,omitempty"`
+}
+
+// ParseError is a syntax error an analyzer met in a file. Skipped is set
+// when the file yielded no chunks because of it.
+type ParseError struct {
+	FilePath string `json:"file_path"`
+	Line     int    `json:"line,omitempty"`
+	Message  string `json:"message"`
+	Skipped  bool   `json:"skipped,omitempty"`
+}
+
+// ParseErrorReporter is implemented by analyzers that report the syntax
+// errors of the files they analyze. ParseErrors returns those of the last
+// AnalyzePaths call.
+type ParseErrorReporter interface {
+	ParseErrors() []ParseError
+}
+
+// FileImports are the imports of one source file. Package is the package,
+// module or namespace the file belongs to, named the way other files import
+// it: Go import path, Python dotted module, PHP or C# namespace.
+type FileImports struct {
+	FilePath string   `json:"file_path"`
+	Language Language `json:"language"`
+	Package  string   `json:"package"`
+	Imports  []string `json:"imports,omitempty"`
+}
+
+// ImportReporter is implemented by analyzers that record what each file
+// imports. Imports returns those of the files of the last AnalyzePaths call.
+type ImportReporter interface {
+	Imports() []FileImports
+}
+
 // APIAnalyzer is any analyzer that can return APIChunks for given paths.
 // LEGACY: prefer PathAnalyzer + Descriptor schema instead.
 type APIAnalyzer interface {
@@ -34,21 +34,75 @@ type Config struct {
 
 	// Workspace configuration (multi-workspace support)
 	Workspace WorkspaceConfig `yaml:"workspace"`
+
+	// Ranking configuration (search result scoring)
+	Ranking RankingConfig `yaml:"ranking"`
+
+	// Queries configuration (query log and cache)
+	Queries QueriesConfig `yaml:"queries"`
+
+	// Edits configuration (tools that modify workspace files)
+	Edits EditsConfig `yaml:"edits"`
+
+	// Tools configuration (which tools the server registers)
+	Tools ToolsConfig `yaml:"tools"`
+
+	// Notifications configuration (indexing and health events)
+	Notifications NotificationsConfig `yaml:"notifications"`
+
+	// Output configuration (how tool responses render code)
+	Output OutputConfig `yaml:"output"`
 }
 
 // LLMConfig contains LLM provider settings
 type LLMConfig struct {
-	// Provider type: "ollama" (local Ollama), "llamafile" (local GGUF), "huggingface" (HF API)
+	// Provider type: "ollama" (local Ollama) or "openai" (OpenAI-compatible API:
+	// OpenAI, Azure OpenAI, vLLM, LM Studio, llama.cpp server)
 	Provider string `yaml:"provider"`
 
 	// Embedding provider: if set, use different provider for embeddings
-	// Options: "ollama", "llamafile", "huggingface", "" (empty = use same as Provider)
+	// Options: "command" (run EmbedCommand), "tei" (text-embeddings-inference
+	// server), "" (empty = use same as Provider)
 	EmbedProvider string `yaml:"embed_provider"`
+	// EmbedCommand is run through the shell for every embedding: the text on
+	// stdin, a JSON vector ([...] or {"embedding": [...]}) on stdout
+	EmbedCommand string `yaml:"embed_command"`
+
+	// Chat provider: if set, generate text with a hosted model while
+	// embeddings stay with Provider/EmbedProvider
+	// Options: "anthropic", "gemini", "" (empty = use Provider)
+	ChatProvider string `yaml:"chat_provider"`
+
+	// Anthropic settings (chat_provider: anthropic)
+	AnthropicAPIKey  string `yaml:"anthropic_api_key"`  // Or ANTHROPIC_API_KEY
+	AnthropicModel   string `yaml:"anthropic_model"`    // e.g., claude-sonnet-4-5
+	AnthropicBaseURL string `yaml:"anthropic_base_url"` // Default: https://api.anthropic.com
+
+	// Gemini settings (chat_provider: gemini)
+	GeminiAPIKey  string `yaml:"gemini_api_key"`  // Or GEMINI_API_KEY
+	GeminiModel   string `yaml:"gemini_model"`    // e.g., gemini-2.5-flash
+	GeminiBaseURL string `yaml:"gemini_base_url"` // Default: https://generativelanguage.googleapis.com/v1beta
+
+	// TEI settings (embed_provider: tei)
+	TEIBaseURL   string `yaml:"tei_base_url"`   // Default: http://localhost:8080; a URL ending in /embeddings is called OpenAI-style
+	TEIAPIKey    string `yaml:"tei_api_key"`    // Optional bearer token
+	TEIModel     string `yaml:"tei_model"`      // Optional: model sent to /embeddings endpoints
+	TEIBatchSize int    `yaml:"tei_batch_size"` // Optional: texts per request (default: the server's max_client_batch_size)
 
 	// Ollama settings
 	OllamaBaseURL string `yaml:"ollama_base_url"` // Default: http://localhost:11434
 	OllamaModel   string `yaml:"ollama_model"`    // e.g., phi3:medium, granite3.1-dense:8b
 	OllamaEmbed   string `yaml:"ollama_embed"`    // e.g., nomic-embed-text
+	ABEmbed       string `yaml:"ab_embed"`        // optional: second embedding model compared with ab_search
+
+	// OpenAI-compatible settings (provider: openai)
+	OpenAIBaseURL         string `yaml:"openai_base_url"`         // Default: https://api.openai.com/v1; e.g. http://localhost:8000/v1 for vLLM
+	OpenAIAPIKey          string `yaml:"openai_api_key"`          // Optional for local servers
+	OpenAIModel           string `yaml:"openai_model"`            // Chat model (Azure: deployment name)
+	OpenAIEmbed           string `yaml:"openai_embed"`            // e.g., text-embedding-3-small (Azure: deployment name)
+	OpenAIEmbedDimensions int    `yaml:"openai_embed_dimensions"` // Optional: shorter vectors, for models that support it
+	OpenAIAPIType         string `yaml:"openai_api_type"`         // "openai" (default) or "azure"
+	OpenAIAPIVersion      string `yaml:"openai_api_version"`      // Azure API version
 
 	// Llamafile settings (local GGUF models via llama.cpp server)
 	LlamafileBaseURL string `yaml:"llamafile_base_url"` // Default: http://localhost:8080
@@ -61,6 +115,10 @@ type LLMConfig struct {
 	HuggingFaceEmbedModel string `yaml:"huggingface_embed_model"` // e.g., sentence-transformers/all-MiniLM-L6-v2
 	HuggingFaceProvider   string `yaml:"huggingface_provider"`    // Optional: inference provider (e.g., hyperbolic)
 
+	// Budgets limit and price the requests of each provider per day, keyed
+	// by provider name: ollama, openai, anthropic, gemini, tei, command
+	Budgets map[string]ProviderBudget `yaml:"budgets"`
+
 	// Global settings (apply to all providers)
 	FallbackProvider string        `yaml:"fallback_provider"` // Fallback provider if primary fails
 	Temperature      float64       `yaml:"temperature"`
@@ -75,6 +133,14 @@ type LLMConfig struct {
 	EmbedModel string `yaml:"embed_model"` // Legacy: use provider-specific embed model
 }
 
+// ProviderBudget limits the daily requests and estimated tokens sent to a
+// provider (0 = unlimited) and prices its tokens for the usage report
+type ProviderBudget struct {
+	DailyRequests       int     `yaml:"daily_requests"`
+	DailyTokens         int     `yaml:"daily_tokens"`
+	USDPerMillionTokens float64 `yaml:"usd_per_million_tokens"`
+}
+
 // MemoryConfig contains memory engine settings
 type MemoryConfig struct {
 	ShortTermSize  int  `yaml:"short_term_size"`
@@ -90,10 +156,17 @@ type StorageConfig struct {
 
 // VectorDBConfig contains vector database settings
 type VectorDBConfig struct {
-	Provider   string `yaml:"provider"` // qdrant, chromadb
+	Provider   string `yaml:"provider"` // qdrant (server) or local (embedded, file-backed)
 	URL        string `yaml:"url"`
 	APIKey     string `yaml:"api_key"`
 	Collection string `yaml:"collection"`
+	Path       string `yaml:"path"` // Directory of the local provider (default ~/.local/share/ragcode/vectors)
+
+	// ReadURLs are Qdrant read replicas. Similarity searches rotate over
+	// them (falling back to URL when one fails); lookups, counts, scrolls,
+	// writes and searches right after a write use URL. Empty searches URL
+	// too.
+	ReadURLs []string `yaml:"read_urls"`
 }
 
 // RedisConfig contains Redis settings
@@ -114,6 +187,26 @@ type ServerConfig struct {
 	Host            string `yaml:"host"`
 	Port            int    `yaml:"port"`
 	EnableWebSocket bool   `yaml:"enable_websocket"`
+
+	// APIToken is the bearer token required by the HTTP endpoints served
+	// with --listen. The REST API (/v1) is disabled without it.
+	APIToken string `yaml:"api_token"`
+
+	// WebhookSecret enables POST /hooks/reindex in HTTP mode. Requests are
+	// verified with an HMAC-SHA256 of the body (X-Hub-Signature-256).
+	WebhookSecret string `yaml:"webhook_secret"`
+
+	// WebhookRepos maps repository names ("owner/repo", as sent by GitHub)
+	// to local checkouts. When set, webhooks may only reindex these paths.
+	WebhookRepos map[string]string `yaml:"webhook_repos"`
+
+	// WebhookPull runs "git pull --ff-only" in the checkout before
+	// re-indexing
+	WebhookPull bool `yaml:"webhook_pull"`
+
+	// ShutdownTimeout is how long SIGTERM waits for background indexing to
+	// checkpoint its state before exiting (default: 10s)
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout"`
 }
 
 // LoggingConfig contains logging settings
@@ -134,6 +227,50 @@ type RagCodeConfig struct {
 	Model          string   `yaml:"model"`            // optional: embedding model override
 	Include        []string `yaml:"include"`          // glob include patterns
 	Exclude        []string `yaml:"exclude"`          // glob exclude patterns
+	GitBlame       bool     `yaml:"git_blame"`        // record last commit time/author per chunk (used by prefer_recent)
+
+	// IndexTests indexes test files and test functions too (Go _test.go,
+	// Python test_*.py, Rust #[test] items), as chunk_type "test", so
+	// find_tests_for_symbol can link them to the code they exercise
+	IndexTests bool `yaml:"index_tests"`
+
+	// KeepBoilerplate embeds license headers, generated banners and comment
+	// lines repeated across the workspace along with the code. By default
+	// they are left out of the embedded text (the stored code keeps them).
+	KeepBoilerplate bool `yaml:"keep_boilerplate"`
+
+	// MaxChunkLines caps the code stored per chunk, per language (e.g. php:
+	// 50). Longer symbols keep their full line range and are flagged as
+	// truncated, with a get_code_context call for the rest. 0 stores the
+	// whole symbol (default: php 50, python 100)
+	MaxChunkLines map[string]int `yaml:"max_chunk_lines"`
+
+	// EmbedBatchSize is the number of chunk texts sent per embedding request
+	// and EmbedWorkers the requests in flight at once while indexing
+	// (default: 32 and 4). EmbedWorkers 1 embeds one batch at a time.
+	EmbedBatchSize int `yaml:"embed_batch_size"`
+	EmbedWorkers   int `yaml:"embed_workers"`
+
+	// PostProcessors run in order on every analyzed chunk before it is embedded
+	PostProcessors []ChunkProcessorConfig `yaml:"post_processors"`
+
+	// TagRules tag chunks at index time; tags are shown in results and usable as search filters
+	TagRules []TagRuleConfig `yaml:"tag_rules"`
+}
+
+// TagRuleConfig adds Tags to the chunks whose file matches one of Paths or
+// Regex, and whose code matches Content (conditions left empty always match)
+type TagRuleConfig struct {
+	Tags    []string `yaml:"tags"`    // e.g. payment, auth, legacy
+	Paths   []string `yaml:"paths"`   // path globs, e.g. internal/payment/**
+	Regex   string   `yaml:"regex"`   // regexp on the file path
+	Content string   `yaml:"content"` // regexp on the chunk code
+}
+
+// ChunkProcessorConfig selects one step of the chunk post-processing pipeline
+type ChunkProcessorConfig struct {
+	Type    string                 `yaml:"type"`    // redact, summarize, link, metadata or a registered custom type
+	Options map[string]interface{} `yaml:"options"` // processor-specific settings
 }
 
 // DocsConfig contains configuration for Markdown documentation indexing
@@ -144,6 +281,36 @@ type DocsConfig struct {
 	// Root-level README and docs directory paths
 	ReadmePath string   `yaml:"readme_path"`
 	DocsPaths  []string `yaml:"docs_paths"`
+
+	// Preferred documentation languages (ISO 639-1, e.g. en, zh). search_docs
+	// lists docs in these languages first; empty means no preference.
+	Languages []string `yaml:"languages"`
+
+	// ChunkMaxTokens caps the estimated tokens of a Markdown chunk and
+	// ChunkOverlap the tokens of the previous chunk repeated at the start of
+	// the next when a section is split (default: 300 and 40). Chunks follow
+	// headings and keep code fences whole.
+	ChunkMaxTokens int `yaml:"chunk_max_tokens"`
+	ChunkOverlap   int `yaml:"chunk_overlap"`
+
+	// FrontMatter maps the YAML front matter of Markdown docs to metadata
+	// instead of embedding it as text
+	FrontMatter FrontMatterConfig `yaml:"front_matter"`
+}
+
+// FrontMatterConfig selects the front matter keys read from Markdown docs.
+// Empty lists take the defaults.
+type FrontMatterConfig struct {
+	// Disabled leaves front matter in the text, embedded as it is
+	Disabled bool `yaml:"disabled"`
+	// TitleKeys are the keys titling a doc in search_docs results, the first
+	// one set wins (default: title)
+	TitleKeys []string `yaml:"title_keys"`
+	// TagKeys are the keys whose values become tags, filterable with the
+	// tags parameter of search_docs (default: tags, keywords, categories)
+	TagKeys []string `yaml:"tag_keys"`
+	// Fields are other keys shown with results (default: owners)
+	Fields []string `yaml:"fields"`
 }
 
 // APIDocsConfig contains configuration for API documentation indexing
@@ -167,8 +334,9 @@ type WorkspaceConfig struct {
 	// Set to 0 for unlimited (default: 10)
 	MaxWorkspaces int `yaml:"max_workspaces"`
 
-	// DetectionMarkers are files/directories used to identify workspace roots
-	// Default: [".git", "go.mod", "package.json", "Cargo.toml", "pyproject.toml"]
+	// DetectionMarkers are files/directories used to identify workspace roots;
+	// glob patterns ("*.sln") match any file of that pattern
+	// Default: [".git", "go.mod", "package.json", "Cargo.toml", "pyproject.toml", "pom.xml", "*.sln", "*.csproj"]
 	DetectionMarkers []string `yaml:"detection_markers"`
 
 	// ExcludePatterns are glob patterns for paths to exclude from workspace detection
@@ -180,8 +348,171 @@ type WorkspaceConfig struct {
 	// Default: "ragcode"
 	CollectionPrefix string `yaml:"collection_prefix"`
 
+	// Identity selects how workspace IDs, and so collection names, are
+	// derived: "path" hashes the absolute workspace path, so every clone is
+	// indexed on its own; "git_remote" hashes the normalized remote URL and
+	// the path of the workspace in the repository, so clones at different
+	// locations share collections and index state. Workspaces without a git
+	// remote always use the path (default: path)
+	Identity string `yaml:"identity"`
+
 	// IndexPatterns override rag_code include/exclude patterns per workspace
 	// If empty, uses global rag_code patterns
 	IndexInclude []string `yaml:"index_include"`
 	IndexExclude []string `yaml:"index_exclude"`
+
+	// IdleTimeout stops the file watcher and closes the collection clients
+	// of workspaces unused for this long; they are recreated on the next
+	// query. 0 keeps them loaded (default: 30m)
+	IdleTimeout time.Duration `yaml:"idle_timeout"`
+
+	// TombstoneGrace keeps the chunks replaced by a re-index searchable by
+	// ID for this long before they are purged, so IDs an agent got just
+	// before the swap still resolve. 0 deletes them at once (default: 10m)
+	TombstoneGrace time.Duration `yaml:"tombstone_grace"`
+
+	// CollectionTTL deletes the collections and index state of workspaces
+	// unused for this long, checked hourly and by cleanup_workspaces.
+	// 0 keeps them (default: 0)
+	CollectionTTL time.Duration `yaml:"collection_ttl"`
+
+	// RegistryPath is the file recording when each collection was last
+	// used (default: ~/.local/share/ragcode/workspaces.json)
+	RegistryPath string `yaml:"registry_path"`
+
+	// SnapshotURL shares pinned API snapshots through object storage: an
+	// s3:// or gs:// prefix they are uploaded to and looked up in, under
+	// the workspace directory name. Credentials come from the environment
+	// (see package objstore).
+	SnapshotURL string `yaml:"snapshot_url"`
+}
+
+// RankingConfig contains the weights used to score search results. A result
+// scores VectorWeight*vector + KeywordWeight*keyword + RecencyWeight*recency,
+// plus ExactNameBonus when the query names the symbol, and is then multiplied
+// by every penalty that applies to it.
+type RankingConfig struct {
+	// VectorWeight scales the semantic similarity score (0..1)
+	VectorWeight float64 `yaml:"vector_weight"`
+
+	// KeywordWeight scales the keyword match score, normalised to the best match (0..1)
+	KeywordWeight float64 `yaml:"keyword_weight"`
+
+	// ExactNameBonus is added when a query term equals the symbol name
+	ExactNameBonus float64 `yaml:"exact_name_bonus"`
+
+	// RecencyWeight scales how recently the file was modified (0..1, halved every
+	// RecencyHalfLifeDays). Default: 0 (disabled)
+	RecencyWeight       float64 `yaml:"recency_weight"`
+	RecencyHalfLifeDays float64 `yaml:"recency_half_life_days"`
+
+	// ProximityWeight scales how close a result is to the file_path of the
+	// query: same file (1) > same directory/package (0.6) > same top-level
+	// directory (0.3)
+	ProximityWeight float64 `yaml:"proximity_weight"`
+
+	// Penalties are score multipliers between 0 and 1 (1 = no penalty)
+	TestPenalty       float64 `yaml:"test_penalty"`       // test files and test functions
+	GeneratedPenalty  float64 `yaml:"generated_penalty"`  // generated code (*.pb.go, "DO NOT EDIT", ...)
+	DeprecatedPenalty float64 `yaml:"deprecated_penalty"` // deprecated symbols
+}
+
+// QueriesConfig controls the per-workspace query log and query cache. Both
+// are disabled by default because queries can contain sensitive text.
+type QueriesConfig struct {
+	// Log appends every search query to .ragcode/query_log.jsonl
+	Log bool `yaml:"log"`
+
+	// Cache keeps the embeddings and results of frequent queries in memory
+	// until the workspace is re-indexed
+	Cache bool `yaml:"cache"`
+
+	// CacheSize limits the cached queries per workspace (default: 200)
+	CacheSize int `yaml:"cache_size"`
+
+	// CacheMinHits is how often a query must be seen before its results are
+	// cached (default: 2)
+	CacheMinHits int `yaml:"cache_min_hits"`
+
+	// PrimeQueries is how many of the most recent cached queries are re-run
+	// in the background after a re-index (default: 10, 0 disables)
+	PrimeQueries int `yaml:"prime_queries"`
+
+	// Usage aggregates anonymized tool-call statistics per day in
+	// .ragcode/usage.json for get_usage_report
+	Usage bool `yaml:"usage"`
+
+	// Timeout bounds one search_code, hybrid_search or search_docs call.
+	// When it expires the results gathered so far are returned with
+	// timed_out: true instead of an error (default: 20s, 0 disables).
+	Timeout time.Duration `yaml:"timeout"`
+
+	// ToolTimeouts overrides Timeout per tool (e.g. hybrid_search: 30s)
+	ToolTimeouts map[string]time.Duration `yaml:"tool_timeouts"`
+
+	// SimilarityThreshold is the cosine similarity from which find_similar_code
+	// reports a chunk as a near-duplicate (default: 0.85)
+	SimilarityThreshold float64 `yaml:"similarity_threshold"`
+}
+
+// EditsConfig controls the tools that modify workspace files. The server is
+// read-only unless Enabled is set.
+type EditsConfig struct {
+	// Enabled registers the apply_patch tool
+	Enabled bool `yaml:"enabled"`
+
+	// MaxFiles limits the files one patch may change (default: 20)
+	MaxFiles int `yaml:"max_files"`
+
+	// ProtectedPaths are globs, relative to the workspace root, that patches
+	// may never touch. .git and .ragcode are always protected.
+	ProtectedPaths []string `yaml:"protected_paths"`
+}
+
+// ToolsConfig selects the tools the server registers, so a deployment can
+// leave out tools that change files or the index, or keep a small tool list
+type ToolsConfig struct {
+	// Profile is the base set of tools: "full" (default: every tool),
+	// "read-only" (no tool changing workspace files or the index) or
+	// "minimal" (search and navigation)
+	Profile string `yaml:"profile"`
+
+	// Enabled adds tools to the profile
+	Enabled []string `yaml:"enabled"`
+
+	// Disabled removes tools from the profile and Enabled
+	Disabled []string `yaml:"disabled"`
+}
+
+// NotificationsConfig sends indexing and health events to operators. Nothing
+// is sent unless Command or WebhookURL is set.
+type NotificationsConfig struct {
+	// Command is run through the shell for every event, with the event as
+	// JSON on stdin and in RAGCODE_EVENT_* environment variables
+	Command string `yaml:"command"`
+
+	// WebhookURL receives every event as a JSON POST. The "text" field makes
+	// the body a valid Slack incoming webhook message.
+	WebhookURL string `yaml:"webhook_url"`
+
+	// Events limits the events sent: index_completed, index_failed,
+	// health_degraded, health_recovered (default: all)
+	Events []string `yaml:"events"`
+
+	// HealthInterval is how often Ollama and Qdrant are checked (default: 1m)
+	HealthInterval time.Duration `yaml:"health_interval"`
+}
+
+// OutputConfig controls how markdown tool responses render code.
+type OutputConfig struct {
+	// CodeFences: "language" fences code tagged with the chunk language
+	// (default), "plain" fences it without a language, "none" writes code
+	// without fences for clients that render them poorly
+	CodeFences string `yaml:"code_fences"`
+
+	// ContextWindow is the context window of the client's model, in tokens.
+	// Search tools size their default results, snippets and context lines to
+	// fit it; clients may announce their own in the initialize request. 0
+	// keeps the fixed defaults (5 results).
+	ContextWindow int `yaml:"context_window"`
 }
@@ -106,11 +106,17 @@ func TestApplyEnvOverrides(t *testing.T) {
 	t.Setenv("DOCS_COLLECTION", "my-docs")
 	t.Setenv("DOCS_README_PATH", "./OTHER.md")
 	t.Setenv("DOCS_PATHS", "./docs, ./more-docs  ")
+	t.Setenv("DOCS_LANGUAGES", "EN, zh")
+	t.Setenv("DOCS_CHUNK_MAX_TOKENS", "500")
+	t.Setenv("DOCS_CHUNK_OVERLAP", "60")
+	t.Setenv("DOCS_FRONT_MATTER", "false")
 	t.Setenv("API_DOCS_COLLECTION", "api-docs")
 	t.Setenv("WORKSPACE_ENABLED", "false")
 	t.Setenv("WORKSPACE_AUTO_INDEX", "false")
 	t.Setenv("WORKSPACE_MAX_WORKSPACES", "42")
 	t.Setenv("WORKSPACE_COLLECTION_PREFIX", "myragcode")
+	t.Setenv("TOOLS_PROFILE", "read-only")
+	t.Setenv("TOOLS_DISABLED", "ab_search, ,grep_workspace")
 
 	applyEnvOverrides(cfg)
 
@@ -129,6 +135,15 @@ func TestApplyEnvOverrides(t *testing.T) {
 	if len(cfg.Docs.DocsPaths) != 2 || cfg.Docs.DocsPaths[0] != "./docs" || cfg.Docs.DocsPaths[1] != "./more-docs" {
 		t.Errorf("Docs.DocsPaths = %#v, want [./docs ./more-docs]", cfg.Docs.DocsPaths)
 	}
+	if len(cfg.Docs.Languages) != 2 || cfg.Docs.Languages[0] != "en" || cfg.Docs.Languages[1] != "zh" {
+		t.Errorf("Docs.Languages = %#v, want [en zh]", cfg.Docs.Languages)
+	}
+	if cfg.Docs.ChunkMaxTokens != 500 || cfg.Docs.ChunkOverlap != 60 {
+		t.Errorf("Docs chunk sizes = %d/%d, want 500/60", cfg.Docs.ChunkMaxTokens, cfg.Docs.ChunkOverlap)
+	}
+	if !cfg.Docs.FrontMatter.Disabled {
+		t.Errorf("Docs.FrontMatter.Disabled = false, want true")
+	}
 	if cfg.APIDocs.Collection != "api-docs" {
 		t.Errorf("APIDocs.Collection = %q, want %q", cfg.APIDocs.Collection, "api-docs")
 	}
@@ -144,6 +159,12 @@ func TestApplyEnvOverrides(t *testing.T) {
 	if cfg.Workspace.CollectionPrefix != "myragcode" {
 		t.Errorf("Workspace.CollectionPrefix = %q, want %q", cfg.Workspace.CollectionPrefix, "myragcode")
 	}
+	if cfg.Tools.Profile != "read-only" {
+		t.Errorf("Tools.Profile = %q, want %q", cfg.Tools.Profile, "read-only")
+	}
+	if len(cfg.Tools.Disabled) != 2 || cfg.Tools.Disabled[0] != "ab_search" || cfg.Tools.Disabled[1] != "grep_workspace" {
+		t.Errorf("Tools.Disabled = %#v, want [ab_search grep_workspace]", cfg.Tools.Disabled)
+	}
 }
 
 func TestValidateDefaultsProviderAndRequiresModel(t *testing.T) {
@@ -169,6 +190,55 @@ func TestValidateDefaultsProviderAndRequiresModel(t *testing.T) {
 	if err := validate(cfgBadProvider); err == nil {
 		t.Fatalf("validate(cfg with bad provider) = nil error, want non-nil")
 	}
+
+	cfgOpenAI := DefaultConfig()
+	cfgOpenAI.LLM.Provider = "openai"
+	if err := validate(cfgOpenAI); err == nil {
+		t.Fatalf("validate(openai cfg without openai_embed) = nil error, want non-nil")
+	}
+	cfgOpenAI.LLM.OpenAIEmbed = "text-embedding-3-small"
+	if err := validate(cfgOpenAI); err != nil {
+		t.Fatalf("validate(openai cfg) returned error: %v", err)
+	}
+
+	cfgCommand := DefaultConfig()
+	cfgCommand.LLM.Provider = "openai"
+	cfgCommand.LLM.EmbedProvider = "command"
+	if err := validate(cfgCommand); err == nil {
+		t.Fatalf("validate(embed_provider command without embed_command) = nil error, want non-nil")
+	}
+	cfgCommand.LLM.EmbedCommand = "python3 embed.py"
+	if err := validate(cfgCommand); err != nil {
+		t.Fatalf("validate(openai cfg embedding with a command) returned error: %v", err)
+	}
+
+	cfgTEI := DefaultConfig()
+	cfgTEI.LLM.EmbedProvider = "tei"
+	if err := validate(cfgTEI); err != nil {
+		t.Fatalf("validate(embed_provider tei) returned error: %v", err)
+	}
+	cfgTEI.LLM.TEIBatchSize = -1
+	if err := validate(cfgTEI); err == nil {
+		t.Fatalf("validate(negative tei_batch_size) = nil error, want non-nil")
+	}
+	cfgTEI.LLM.EmbedProvider = "sagemaker"
+	if err := validate(cfgTEI); err == nil {
+		t.Fatalf("validate(unknown embed_provider) = nil error, want non-nil")
+	}
+
+	cfgChat := DefaultConfig()
+	cfgChat.LLM.ChatProvider = "anthropic"
+	if err := validate(cfgChat); err == nil {
+		t.Fatalf("validate(chat_provider anthropic without anthropic_model) = nil error, want non-nil")
+	}
+	cfgChat.LLM.AnthropicModel = "claude-sonnet-4-5"
+	if err := validate(cfgChat); err != nil {
+		t.Fatalf("validate(chat_provider anthropic) returned error: %v", err)
+	}
+	cfgChat.LLM.ChatProvider = "cohere"
+	if err := validate(cfgChat); err == nil {
+		t.Fatalf("validate(unknown chat_provider) = nil error, want non-nil")
+	}
 }
 
 func TestValidateServerPort(t *testing.T) {
@@ -180,3 +250,76 @@ func TestValidateServerPort(t *testing.T) {
 		t.Fatalf("validate(cfg with high port) returned unexpected error: %v", err)
 	}
 }
+
+func TestValidateDocsChunking(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Docs.ChunkOverlap = cfg.Docs.ChunkMaxTokens
+	if err := validate(cfg); err == nil {
+		t.Error("validate accepted docs.chunk_overlap >= docs.chunk_max_tokens")
+	}
+	cfg.Docs.ChunkMaxTokens, cfg.Docs.ChunkOverlap = -1, 0
+	if err := validate(cfg); err == nil {
+		t.Error("validate accepted a negative docs.chunk_max_tokens")
+	}
+}
+
+func TestLoadRankingConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	yamlContent := []byte(`
+llm:
+  ollama_model: custom-model
+ranking:
+  keyword_weight: 0.8
+  test_penalty: 0.5
+`)
+	if err := os.WriteFile(path, yamlContent, 0o644); err != nil {
+		t.Fatalf("failed to write temp config: %v", err)
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load(%q) returned error: %v", path, err)
+	}
+	defaults := DefaultRankingConfig()
+	if cfg.Ranking.KeywordWeight != 0.8 || cfg.Ranking.TestPenalty != 0.5 {
+		t.Errorf("Ranking = %+v, want keyword_weight 0.8 and test_penalty 0.5", cfg.Ranking)
+	}
+	if cfg.Ranking.VectorWeight != defaults.VectorWeight || cfg.Ranking.DeprecatedPenalty != defaults.DeprecatedPenalty {
+		t.Errorf("Ranking = %+v, omitted weights should keep defaults", cfg.Ranking)
+	}
+
+	cfg.Ranking.GeneratedPenalty = 1.5
+	if err := validate(cfg); err == nil {
+		t.Errorf("validate(generated_penalty 1.5) = nil error, want non-nil")
+	}
+}
+
+func TestLoadPostProcessors(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	yamlContent := []byte(`
+llm:
+  ollama_model: custom-model
+rag_code:
+  post_processors:
+    - type: redact
+    - type: metadata
+      options:
+        paths: ["internal/payment/**"]
+        set: {owner: payments-team}
+`)
+	if err := os.WriteFile(path, yamlContent, 0o644); err != nil {
+		t.Fatalf("failed to write temp config: %v", err)
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load(%q) returned error: %v", path, err)
+	}
+	pp := cfg.RagCode.PostProcessors
+	if len(pp) != 2 || pp[0].Type != "redact" || pp[1].Type != "metadata" {
+		t.Fatalf("PostProcessors = %+v, want redact then metadata", pp)
+	}
+	if set, _ := pp[1].Options["set"].(map[string]interface{}); set["owner"] != "payments-team" {
+		t.Errorf("metadata options = %+v", pp[1].Options)
+	}
+}
@@ -22,8 +22,8 @@ func Load(path string) (*Config, error) {
 		return nil, fmt.Errorf("failed to read config file: %w", err)
 	}
 
-	// Parse YAML
-	var cfg Config
+	// Parse YAML; sections that are usually omitted keep their defaults
+	cfg := Config{Ranking: DefaultRankingConfig(), Queries: DefaultQueriesConfig()}
 	if err := yaml.Unmarshal(data, &cfg); err != nil {
 		return nil, fmt.Errorf("failed to parse config file: %w", err)
 	}
@@ -39,12 +39,19 @@ func Load(path string) (*Config, error) {
 	return &cfg, nil
 }
 
+// DefaultMaxChunkLines returns the per-language caps on the code stored per
+// chunk: large PHP classes and Python symbols keep their header
+func DefaultMaxChunkLines() map[string]int {
+	return map[string]int{"php": 50, "python": 100}
+}
+
 // DefaultConfig returns a default configuration
 func DefaultConfig() *Config {
 	return &Config{
 		LLM: LLMConfig{
 			Provider:         "ollama",
 			OllamaBaseURL:    "http://localhost:11434",
+			TEIBaseURL:       "http://localhost:8080",
 			OllamaModel:      "llama3",
 			OllamaEmbed:      "nomic-embed-text",
 			LlamafileBaseURL: "http://localhost:8080",
@@ -95,11 +102,16 @@ func DefaultConfig() *Config {
 			Model:          "",
 			Include:        []string{"**/*.go"},
 			Exclude:        []string{"**/*_test.go", "vendor/**", ".git/**", "testdata/**"},
+			MaxChunkLines:  DefaultMaxChunkLines(),
+			EmbedBatchSize: 32,
+			EmbedWorkers:   4,
 		},
 		Docs: DocsConfig{
-			Collection: "do-ai-docs",
-			ReadmePath: "./README.md",
-			DocsPaths:  []string{"./docs"},
+			Collection:     "do-ai-docs",
+			ReadmePath:     "./README.md",
+			DocsPaths:      []string{"./docs"},
+			ChunkMaxTokens: 300,
+			ChunkOverlap:   40,
 		},
 		APIDocs: APIDocsConfig{
 			Collection: "do-ai-api-docs",
@@ -108,12 +120,44 @@ func DefaultConfig() *Config {
 			Enabled:          true,
 			AutoIndex:        true,
 			MaxWorkspaces:    10,
-			DetectionMarkers: []string{".git", "go.mod", "package.json", "Cargo.toml", "pyproject.toml", "pom.xml"},
+			DetectionMarkers: []string{".git", "go.mod", "package.json", "Cargo.toml", "pyproject.toml", "pom.xml", "*.sln", "*.csproj"},
 			ExcludePatterns:  []string{"node_modules", ".git", "vendor", "target", "build", "dist", ".venv"},
 			CollectionPrefix: "ragcode",
 			IndexInclude:     []string{}, // Empty means use global rag_code.include
 			IndexExclude:     []string{}, // Empty means use global rag_code.exclude
+			IdleTimeout:      30 * time.Minute,
+			TombstoneGrace:   10 * time.Minute,
 		},
+		Ranking: DefaultRankingConfig(),
+		Queries: DefaultQueriesConfig(),
+	}
+}
+
+// DefaultQueriesConfig returns the default query log and cache settings
+func DefaultQueriesConfig() QueriesConfig {
+	return QueriesConfig{
+		Log:                 false,
+		Cache:               false,
+		CacheSize:           200,
+		CacheMinHits:        2,
+		PrimeQueries:        10,
+		Timeout:             20 * time.Second,
+		SimilarityThreshold: 0.85,
+	}
+}
+
+// DefaultRankingConfig returns the default search ranking weights
+func DefaultRankingConfig() RankingConfig {
+	return RankingConfig{
+		VectorWeight:        0.6,
+		KeywordWeight:       0.4,
+		ExactNameBonus:      0.15,
+		RecencyWeight:       0,
+		RecencyHalfLifeDays: 30,
+		ProximityWeight:     0.1,
+		TestPenalty:         0.85,
+		GeneratedPenalty:    0.6,
+		DeprecatedPenalty:   0.7,
 	}
 }
 
@@ -144,6 +188,30 @@ func applyEnvOverrides(cfg *Config) {
 		}
 	}
 
+	if langs := os.Getenv("DOCS_LANGUAGES"); langs != "" {
+		cfg.Docs.Languages = nil
+		for _, l := range strings.Split(langs, ",") {
+			if l = strings.ToLower(strings.TrimSpace(l)); l != "" {
+				cfg.Docs.Languages = append(cfg.Docs.Languages, l)
+			}
+		}
+	}
+	if maxTokens := os.Getenv("DOCS_CHUNK_MAX_TOKENS"); maxTokens != "" {
+		if v, err := strconv.Atoi(maxTokens); err == nil {
+			cfg.Docs.ChunkMaxTokens = v
+		}
+	}
+	if overlap := os.Getenv("DOCS_CHUNK_OVERLAP"); overlap != "" {
+		if v, err := strconv.Atoi(overlap); err == nil {
+			cfg.Docs.ChunkOverlap = v
+		}
+	}
+	if frontMatter := os.Getenv("DOCS_FRONT_MATTER"); frontMatter != "" {
+		if v, err := strconv.ParseBool(frontMatter); err == nil {
+			cfg.Docs.FrontMatter.Disabled = !v
+		}
+	}
+
 	if apiColl := os.Getenv("API_DOCS_COLLECTION"); apiColl != "" {
 		cfg.APIDocs.Collection = apiColl
 	}
@@ -161,6 +229,35 @@ func applyEnvOverrides(cfg *Config) {
 	if embed := os.Getenv("OLLAMA_EMBED"); embed != "" {
 		cfg.LLM.OllamaEmbed = embed
 	}
+	if baseURL := os.Getenv("OPENAI_BASE_URL"); baseURL != "" {
+		cfg.LLM.OpenAIBaseURL = baseURL
+	}
+	if key := os.Getenv("OPENAI_API_KEY"); key != "" {
+		cfg.LLM.OpenAIAPIKey = key
+	}
+	if model := os.Getenv("OPENAI_MODEL"); model != "" {
+		cfg.LLM.OpenAIModel = model
+	}
+	if embed := os.Getenv("OPENAI_EMBED"); embed != "" {
+		cfg.LLM.OpenAIEmbed = embed
+	}
+	if command := os.Getenv("EMBED_COMMAND"); command != "" {
+		cfg.LLM.EmbedProvider = "command"
+		cfg.LLM.EmbedCommand = command
+	}
+	if provider := os.Getenv("LLM_CHAT_PROVIDER"); provider != "" {
+		cfg.LLM.ChatProvider = provider
+	}
+	if key := os.Getenv("ANTHROPIC_API_KEY"); key != "" {
+		cfg.LLM.AnthropicAPIKey = key
+	}
+	if key := os.Getenv("GEMINI_API_KEY"); key != "" {
+		cfg.LLM.GeminiAPIKey = key
+	}
+	if baseURL := os.Getenv("TEI_BASE_URL"); baseURL != "" {
+		cfg.LLM.EmbedProvider = "tei"
+		cfg.LLM.TEIBaseURL = baseURL
+	}
 
 	// Vector DB (Qdrant) configuration overrides
 	if url := os.Getenv("QDRANT_URL"); url != "" {
@@ -169,9 +266,23 @@ func applyEnvOverrides(cfg *Config) {
 	if apiKey := os.Getenv("QDRANT_API_KEY"); apiKey != "" {
 		cfg.Storage.VectorDB.APIKey = apiKey
 	}
+	if readURLs := os.Getenv("QDRANT_READ_URLS"); readURLs != "" {
+		cfg.Storage.VectorDB.ReadURLs = nil
+		for _, u := range strings.Split(readURLs, ",") {
+			if u = strings.TrimSpace(u); u != "" {
+				cfg.Storage.VectorDB.ReadURLs = append(cfg.Storage.VectorDB.ReadURLs, u)
+			}
+		}
+	}
 	if coll := os.Getenv("QDRANT_COLLECTION"); coll != "" {
 		cfg.Storage.VectorDB.Collection = coll
 	}
+	if provider := os.Getenv("VECTOR_DB_PROVIDER"); provider != "" {
+		cfg.Storage.VectorDB.Provider = provider
+	}
+	if path := os.Getenv("VECTOR_DB_PATH"); path != "" {
+		cfg.Storage.VectorDB.Path = path
+	}
 
 	// RagCode configuration overrides
 	if codeColl := os.Getenv("CODE_RAG_COLLECTION"); codeColl != "" {
@@ -191,6 +302,121 @@ func applyEnvOverrides(cfg *Config) {
 		}
 	}
 
+	if gitBlame := os.Getenv("CODE_RAG_GIT_BLAME"); gitBlame != "" {
+		if v, err := strconv.ParseBool(gitBlame); err == nil {
+			cfg.RagCode.GitBlame = v
+		}
+	}
+	if indexTests := os.Getenv("CODE_RAG_INDEX_TESTS"); indexTests != "" {
+		if v, err := strconv.ParseBool(indexTests); err == nil {
+			cfg.RagCode.IndexTests = v
+		}
+	}
+
+	// Per-language chunk caps, e.g. CODE_RAG_MAX_CHUNK_LINES=php=80,python=0
+	if maxLines := os.Getenv("CODE_RAG_MAX_CHUNK_LINES"); maxLines != "" {
+		for _, pair := range strings.Split(maxLines, ",") {
+			lang, n, ok := strings.Cut(strings.TrimSpace(pair), "=")
+			if !ok {
+				continue
+			}
+			if v, err := strconv.Atoi(strings.TrimSpace(n)); err == nil {
+				if cfg.RagCode.MaxChunkLines == nil {
+					cfg.RagCode.MaxChunkLines = make(map[string]int)
+				}
+				cfg.RagCode.MaxChunkLines[strings.ToLower(strings.TrimSpace(lang))] = v
+			}
+		}
+	}
+
+	if batch := os.Getenv("CODE_RAG_EMBED_BATCH_SIZE"); batch != "" {
+		if v, err := strconv.Atoi(batch); err == nil {
+			cfg.RagCode.EmbedBatchSize = v
+		}
+	}
+	if workers := os.Getenv("CODE_RAG_EMBED_WORKERS"); workers != "" {
+		if v, err := strconv.Atoi(workers); err == nil {
+			cfg.RagCode.EmbedWorkers = v
+		}
+	}
+
+	// Query log and cache overrides
+	if queryLog := os.Getenv("QUERY_LOG_ENABLED"); queryLog != "" {
+		if v, err := strconv.ParseBool(queryLog); err == nil {
+			cfg.Queries.Log = v
+		}
+	}
+	if queryCache := os.Getenv("QUERY_CACHE_ENABLED"); queryCache != "" {
+		if v, err := strconv.ParseBool(queryCache); err == nil {
+			cfg.Queries.Cache = v
+		}
+	}
+	if usage := os.Getenv("USAGE_STATS_ENABLED"); usage != "" {
+		if v, err := strconv.ParseBool(usage); err == nil {
+			cfg.Queries.Usage = v
+		}
+	}
+	if timeout := os.Getenv("QUERY_TIMEOUT"); timeout != "" {
+		if v, err := time.ParseDuration(timeout); err == nil {
+			cfg.Queries.Timeout = v
+		}
+	}
+
+	if threshold := os.Getenv("SIMILARITY_THRESHOLD"); threshold != "" {
+		if v, err := strconv.ParseFloat(threshold, 64); err == nil {
+			cfg.Queries.SimilarityThreshold = v
+		}
+	}
+
+	// Server overrides
+	if timeout := os.Getenv("RAGCODE_SHUTDOWN_TIMEOUT"); timeout != "" {
+		if v, err := time.ParseDuration(timeout); err == nil {
+			cfg.Server.ShutdownTimeout = v
+		}
+	}
+	if token := os.Getenv("RAGCODE_API_TOKEN"); token != "" {
+		cfg.Server.APIToken = token
+	}
+	if secret := os.Getenv("RAGCODE_WEBHOOK_SECRET"); secret != "" {
+		cfg.Server.WebhookSecret = secret
+	}
+
+	// Notifications overrides
+	if command := os.Getenv("RAGCODE_NOTIFY_COMMAND"); command != "" {
+		cfg.Notifications.Command = command
+	}
+	if url := os.Getenv("RAGCODE_NOTIFY_WEBHOOK"); url != "" {
+		cfg.Notifications.WebhookURL = url
+	}
+
+	// Output overrides
+	if fences := os.Getenv("OUTPUT_CODE_FENCES"); fences != "" {
+		cfg.Output.CodeFences = fences
+	}
+	if window := os.Getenv("OUTPUT_CONTEXT_WINDOW"); window != "" {
+		if v, err := strconv.Atoi(window); err == nil {
+			cfg.Output.ContextWindow = v
+		}
+	}
+
+	// Edits overrides
+	if editsEnabled := os.Getenv("EDITS_ENABLED"); editsEnabled != "" {
+		if v, err := strconv.ParseBool(editsEnabled); err == nil {
+			cfg.Edits.Enabled = v
+		}
+	}
+
+	// Tools overrides
+	if profile := os.Getenv("TOOLS_PROFILE"); profile != "" {
+		cfg.Tools.Profile = profile
+	}
+	if enabled := os.Getenv("TOOLS_ENABLED"); enabled != "" {
+		cfg.Tools.Enabled = splitNames(enabled)
+	}
+	if disabled := os.Getenv("TOOLS_DISABLED"); disabled != "" {
+		cfg.Tools.Disabled = splitNames(disabled)
+	}
+
 	// Workspace configuration overrides
 	if wsEnabled := os.Getenv("WORKSPACE_ENABLED"); wsEnabled != "" {
 		if v, err := strconv.ParseBool(wsEnabled); err == nil {
@@ -207,11 +433,46 @@ func applyEnvOverrides(cfg *Config) {
 			cfg.Workspace.MaxWorkspaces = v
 		}
 	}
+	if wsIdle := os.Getenv("WORKSPACE_IDLE_TIMEOUT"); wsIdle != "" {
+		if v, err := time.ParseDuration(wsIdle); err == nil {
+			cfg.Workspace.IdleTimeout = v
+		}
+	}
+	if wsGrace := os.Getenv("WORKSPACE_TOMBSTONE_GRACE"); wsGrace != "" {
+		if v, err := time.ParseDuration(wsGrace); err == nil {
+			cfg.Workspace.TombstoneGrace = v
+		}
+	}
+	if wsTTL := os.Getenv("WORKSPACE_COLLECTION_TTL"); wsTTL != "" {
+		if v, err := time.ParseDuration(wsTTL); err == nil {
+			cfg.Workspace.CollectionTTL = v
+		}
+	}
+	if wsRegistry := os.Getenv("WORKSPACE_REGISTRY_PATH"); wsRegistry != "" {
+		cfg.Workspace.RegistryPath = wsRegistry
+	}
+	if wsSnapshots := os.Getenv("WORKSPACE_SNAPSHOT_URL"); wsSnapshots != "" {
+		cfg.Workspace.SnapshotURL = wsSnapshots
+	}
+	if wsIdentity := os.Getenv("WORKSPACE_IDENTITY"); wsIdentity != "" {
+		cfg.Workspace.Identity = wsIdentity
+	}
 	if wsPrefix := os.Getenv("WORKSPACE_COLLECTION_PREFIX"); wsPrefix != "" {
 		cfg.Workspace.CollectionPrefix = wsPrefix
 	}
 }
 
+// splitNames splits a comma-separated list, dropping empty names
+func splitNames(list string) []string {
+	var names []string
+	for _, name := range strings.Split(list, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
 // validate checks if the configuration is valid
 func validate(cfg *Config) error {
 	// Default to ollama if provider is not set
@@ -219,14 +480,187 @@ func validate(cfg *Config) error {
 		cfg.LLM.Provider = "ollama"
 	}
 
-	// Only ollama is supported in this version of the project
-	if cfg.LLM.Provider != "ollama" {
-		return fmt.Errorf("llm.provider must be 'ollama'")
+	switch cfg.LLM.Provider {
+	case "ollama":
+		if cfg.LLM.OllamaModel == "" && cfg.LLM.Model == "" {
+			return fmt.Errorf("llm.ollama_model (or legacy llm.model) is required for ollama provider")
+		}
+	case "openai":
+		if cfg.LLM.OpenAIEmbed == "" && cfg.LLM.EmbedProvider == "" {
+			return fmt.Errorf("llm.openai_embed is required for openai provider")
+		}
+		switch cfg.LLM.OpenAIAPIType {
+		case "", "openai", "azure":
+		default:
+			return fmt.Errorf("llm.openai_api_type must be 'openai' or 'azure'")
+		}
+		if cfg.LLM.OpenAIEmbedDimensions < 0 {
+			return fmt.Errorf("llm.openai_embed_dimensions must not be negative")
+		}
+	default:
+		return fmt.Errorf("llm.provider must be 'ollama' or 'openai'")
+	}
+
+	for name, budget := range cfg.LLM.Budgets {
+		if budget.DailyRequests < 0 || budget.DailyTokens < 0 || budget.USDPerMillionTokens < 0 {
+			return fmt.Errorf("llm.budgets.%s must not be negative", name)
+		}
+	}
+
+	switch cfg.LLM.ChatProvider {
+	case "":
+	case "anthropic":
+		if cfg.LLM.AnthropicModel == "" {
+			return fmt.Errorf("llm.anthropic_model is required for chat_provider 'anthropic'")
+		}
+	case "gemini":
+		if cfg.LLM.GeminiModel == "" {
+			return fmt.Errorf("llm.gemini_model is required for chat_provider 'gemini'")
+		}
+	default:
+		return fmt.Errorf("llm.chat_provider must be 'anthropic', 'gemini' or empty")
+	}
+
+	switch cfg.LLM.EmbedProvider {
+	case "":
+	case "command":
+		if strings.TrimSpace(cfg.LLM.EmbedCommand) == "" {
+			return fmt.Errorf("llm.embed_command is required for embed_provider 'command'")
+		}
+	case "tei":
+		if strings.TrimSpace(cfg.LLM.TEIBaseURL) == "" {
+			return fmt.Errorf("llm.tei_base_url is required for embed_provider 'tei'")
+		}
+		if cfg.LLM.TEIBatchSize < 0 {
+			return fmt.Errorf("llm.tei_batch_size must not be negative")
+		}
+	default:
+		return fmt.Errorf("llm.embed_provider must be 'command', 'tei' or empty")
+	}
+
+	switch cfg.Storage.VectorDB.Provider {
+	case "", "qdrant":
+	case "local":
+		if len(cfg.Storage.VectorDB.ReadURLs) > 0 {
+			return fmt.Errorf("storage.vector_db.read_urls needs the qdrant provider")
+		}
+	default:
+		return fmt.Errorf("storage.vector_db.provider must be 'qdrant' or 'local'")
+	}
+
+	// Languages without a chunk cap of their own keep the default one
+	if cfg.RagCode.MaxChunkLines == nil {
+		cfg.RagCode.MaxChunkLines = make(map[string]int)
+	}
+	for lang, n := range DefaultMaxChunkLines() {
+		if _, ok := cfg.RagCode.MaxChunkLines[lang]; !ok {
+			cfg.RagCode.MaxChunkLines[lang] = n
+		}
+	}
+	for lang, n := range cfg.RagCode.MaxChunkLines {
+		if n < 0 {
+			return fmt.Errorf("rag_code.max_chunk_lines.%s must not be negative", lang)
+		}
+	}
+
+	if cfg.RagCode.EmbedBatchSize < 0 || cfg.RagCode.EmbedWorkers < 0 {
+		return fmt.Errorf("rag_code.embed_batch_size and rag_code.embed_workers must not be negative")
+	}
+	if cfg.Docs.ChunkMaxTokens < 0 || cfg.Docs.ChunkOverlap < 0 {
+		return fmt.Errorf("docs.chunk_max_tokens and docs.chunk_overlap must not be negative")
+	}
+	if cfg.Docs.ChunkMaxTokens > 0 && cfg.Docs.ChunkOverlap >= cfg.Docs.ChunkMaxTokens {
+		return fmt.Errorf("docs.chunk_overlap (%d) must be less than docs.chunk_max_tokens (%d)", cfg.Docs.ChunkOverlap, cfg.Docs.ChunkMaxTokens)
+	}
+
+	// Validate ranking weights
+	r := cfg.Ranking
+	if r.VectorWeight < 0 || r.KeywordWeight < 0 || r.ExactNameBonus < 0 || r.RecencyWeight < 0 || r.ProximityWeight < 0 {
+		return fmt.Errorf("ranking weights must not be negative")
+	}
+	for name, p := range map[string]float64{
+		"test_penalty":       r.TestPenalty,
+		"generated_penalty":  r.GeneratedPenalty,
+		"deprecated_penalty": r.DeprecatedPenalty,
+	} {
+		if p < 0 || p > 1 {
+			return fmt.Errorf("ranking.%s must be between 0 and 1", name)
+		}
+	}
+	if cfg.Ranking.RecencyHalfLifeDays <= 0 {
+		cfg.Ranking.RecencyHalfLifeDays = 30
+	}
+
+	// Ensure query cache limits
+	if cfg.Queries.CacheSize <= 0 {
+		cfg.Queries.CacheSize = 200
+	}
+	if cfg.Queries.CacheMinHits <= 0 {
+		cfg.Queries.CacheMinHits = 2
+	}
+	if cfg.Queries.Timeout < 0 {
+		return fmt.Errorf("queries.timeout must not be negative")
+	}
+	for tool, timeout := range cfg.Queries.ToolTimeouts {
+		if timeout < 0 {
+			return fmt.Errorf("queries.tool_timeouts.%s must not be negative", tool)
+		}
+	}
+	if cfg.Queries.SimilarityThreshold == 0 {
+		cfg.Queries.SimilarityThreshold = 0.85
+	}
+	if cfg.Queries.SimilarityThreshold < 0 || cfg.Queries.SimilarityThreshold > 1 {
+		return fmt.Errorf("queries.similarity_threshold must be between 0 and 1, got %g", cfg.Queries.SimilarityThreshold)
+	}
+
+	// Validate code fence mode
+	switch cfg.Output.CodeFences {
+	case "":
+		cfg.Output.CodeFences = "language"
+	case "language", "plain", "none":
+	default:
+		return fmt.Errorf("output.code_fences must be language, plain or none")
+	}
+	if cfg.Output.ContextWindow < 0 {
+		return fmt.Errorf("output.context_window must not be negative")
+	}
+
+	if cfg.Workspace.IdleTimeout < 0 {
+		return fmt.Errorf("workspace.idle_timeout must not be negative")
+	}
+	if cfg.Workspace.TombstoneGrace < 0 {
+		return fmt.Errorf("workspace.tombstone_grace must not be negative")
+	}
+	if cfg.Workspace.CollectionTTL < 0 {
+		return fmt.Errorf("workspace.collection_ttl must not be negative")
+	}
+	switch cfg.Workspace.Identity {
+	case "":
+		cfg.Workspace.Identity = "path"
+	case "path", "git_remote":
+	default:
+		return fmt.Errorf("workspace.identity must be path or git_remote, got %q", cfg.Workspace.Identity)
+	}
+	if url := cfg.Workspace.SnapshotURL; url != "" && !strings.HasPrefix(url, "s3://") && !strings.HasPrefix(url, "gs://") {
+		return fmt.Errorf("workspace.snapshot_url must be an s3:// or gs:// URL, got %q", cfg.Workspace.SnapshotURL)
+	}
+
+	switch cfg.Tools.Profile {
+	case "":
+		cfg.Tools.Profile = "full"
+	case "full", "read-only", "minimal":
+	default:
+		return fmt.Errorf("tools.profile must be full, read-only or minimal, got %q", cfg.Tools.Profile)
+	}
+
+	// Ensure shutdown deadline
+	if cfg.Server.ShutdownTimeout <= 0 {
+		cfg.Server.ShutdownTimeout = 10 * time.Second
 	}
 
-	// Validate ollama model configuration
-	if cfg.LLM.OllamaModel == "" && cfg.LLM.Model == "" {
-		return fmt.Errorf("llm.ollama_model (or legacy llm.model) is required for ollama provider")
+	// Ensure health check interval
+	if cfg.Notifications.HealthInterval <= 0 {
+		cfg.Notifications.HealthInterval = time.Minute
 	}
 
 	// Ensure log max size
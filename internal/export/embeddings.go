@@ -0,0 +1,136 @@
+// Package export writes indexed chunk embeddings in formats read by
+// visualization tools: TSV files for the TensorFlow Embedding Projector and
+// Parquet for UMAP notebooks (pandas, polars, DuckDB).
+package export
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/doITmagic/rag-code-mcp/internal/storage"
+)
+
+// Row is one exported chunk: its vector and the labels used to color and
+// identify it in a plot.
+type Row struct {
+	ChunkID    string
+	Collection string
+	Language   string // programming language of the collection
+	Kind       string // function, method, type, markdown, ...
+	Name       string
+	Package    string
+	File       string
+	StartLine  int
+	EndLine    int
+	Lang       string // detected documentation language
+	Vector     []float32
+}
+
+// metadataColumns are the label columns, in the order they are written.
+var metadataColumns = []string{"label", "chunk_id", "collection", "language", "kind", "name", "package", "file", "start_line", "end_line", "lang"}
+
+// RowFromPoint builds a row from a point of a workspace collection.
+// Documentation chunks have no name: the first line of their text is used.
+func RowFromPoint(p storage.VectorPoint, collection, language string) Row {
+	str := func(key string) string {
+		s, _ := p.Payload[key].(string)
+		return s
+	}
+	num := func(key string) int {
+		n, _ := strconv.Atoi(str(key))
+		return n
+	}
+
+	row := Row{
+		ChunkID:    p.ID,
+		Collection: collection,
+		Language:   language,
+		Kind:       str("type"),
+		Name:       str("name"),
+		Package:    str("package"),
+		File:       str("file"),
+		StartLine:  num("start_line"),
+		EndLine:    num("end_line"),
+		Lang:       str("lang"),
+		Vector:     p.Vector,
+	}
+	if row.Kind == "" {
+		row.Kind = str("chunk_type")
+	}
+	if row.Name == "" {
+		content := strings.TrimSpace(str("content"))
+		if i := strings.IndexByte(content, '\n'); i >= 0 {
+			content = content[:i]
+		}
+		row.Name = truncate(strings.TrimSpace(strings.TrimLeft(content, "#")), 80)
+	}
+	return row
+}
+
+// Label is a short display name: "name (kind)", or the file name.
+func (r Row) Label() string {
+	switch {
+	case r.Name != "" && r.Kind != "":
+		return fmt.Sprintf("%s (%s)", r.Name, r.Kind)
+	case r.Name != "":
+		return r.Name
+	default:
+		return filepath.Base(r.File)
+	}
+}
+
+func (r Row) metadata() []string {
+	return []string{
+		r.Label(), r.ChunkID, r.Collection, r.Language, r.Kind, r.Name, r.Package, r.File,
+		strconv.Itoa(r.StartLine), strconv.Itoa(r.EndLine), r.Lang,
+	}
+}
+
+// WriteTSV writes the vectors (one row of tab-separated floats per chunk, no
+// header) and the matching metadata (with a header row), the two files the
+// Embedding Projector loads.
+func WriteTSV(vectors, metadata io.Writer, rows []Row) error {
+	vw := bufio.NewWriter(vectors)
+	mw := bufio.NewWriter(metadata)
+
+	fmt.Fprintln(mw, strings.Join(metadataColumns, "\t"))
+	for _, r := range rows {
+		for i, v := range r.Vector {
+			if i > 0 {
+				vw.WriteByte('\t')
+			}
+			vw.WriteString(strconv.FormatFloat(float64(v), 'g', -1, 32))
+		}
+		vw.WriteByte('\n')
+
+		fields := r.metadata()
+		for i, f := range fields {
+			fields[i] = tsvField(f)
+		}
+		fmt.Fprintln(mw, strings.Join(fields, "\t"))
+	}
+
+	if err := vw.Flush(); err != nil {
+		return fmt.Errorf("write vectors: %w", err)
+	}
+	if err := mw.Flush(); err != nil {
+		return fmt.Errorf("write metadata: %w", err)
+	}
+	return nil
+}
+
+// tsvField keeps a value on one cell: tabs and newlines would shift columns.
+func tsvField(s string) string {
+	return strings.NewReplacer("\t", " ", "\r", " ", "\n", " ").Replace(s)
+}
+
+func truncate(s string, max int) string {
+	if r := []rune(s); len(r) > max {
+		return string(r[:max])
+	}
+	return s
+}
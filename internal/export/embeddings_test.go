@@ -0,0 +1,49 @@
+package export
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/doITmagic/rag-code-mcp/internal/storage"
+)
+
+func TestRowFromPoint(t *testing.T) {
+	code := RowFromPoint(storage.VectorPoint{ID: "42", Vector: []float32{1}, Payload: map[string]interface{}{
+		"name": "Open", "type": "function", "package": "db", "file": "/ws/db.go", "start_line": "3", "end_line": "9",
+	}}, "ragcode-abc-go", "go")
+	if code.Label() != "Open (function)" || code.StartLine != 3 || code.EndLine != 9 || code.Language != "go" {
+		t.Errorf("code row = %+v", code)
+	}
+
+	doc := RowFromPoint(storage.VectorPoint{ID: "7", Payload: map[string]interface{}{
+		"content": "## Installation\n\nRun make.", "chunk_type": "markdown", "file": "/ws/README.md", "lang": "en",
+	}}, "ragcode-abc-go", "go")
+	if doc.Label() != "Installation (markdown)" || doc.Lang != "en" {
+		t.Errorf("doc row = %+v", doc)
+	}
+}
+
+func TestWriteTSV(t *testing.T) {
+	rows := []Row{
+		{ChunkID: "1", Kind: "function", Name: "Open", File: "/ws/db.go", StartLine: 3, EndLine: 9, Vector: []float32{0.5, -1}},
+		{ChunkID: "2", Kind: "markdown", Name: "Set\tup", File: "/ws/README.md", Vector: []float32{0.25, 2}},
+	}
+	var vectors, metadata bytes.Buffer
+	if err := WriteTSV(&vectors, &metadata, rows); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := vectors.String(); got != "0.5\t-1\n0.25\t2\n" {
+		t.Errorf("vectors = %q", got)
+	}
+	lines := strings.Split(strings.TrimSuffix(metadata.String(), "\n"), "\n")
+	if len(lines) != 3 || !strings.HasPrefix(lines[0], "label\tchunk_id\t") {
+		t.Fatalf("metadata = %q", metadata.String())
+	}
+	for _, line := range lines {
+		if n := strings.Count(line, "\t"); n != len(metadataColumns)-1 {
+			t.Errorf("line %q has %d tabs, want %d", line, n, len(metadataColumns)-1)
+		}
+	}
+}
@@ -0,0 +1,355 @@
+package export
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+)
+
+// WriteParquet writes rows as an uncompressed Parquet file with one row group:
+// the metadata columns as strings and integers, and the vector as a
+// list<float> column, so np.stack(df["vector"]) gives the matrix for UMAP.
+//
+// Only the subset of the format needed here is implemented: PLAIN values,
+// RLE levels, one data page per column.
+func WriteParquet(w io.Writer, rows []Row) error {
+	columns := parquetColumns(rows)
+
+	cw := &countingWriter{w: w}
+	cw.Write([]byte(parquetMagic))
+
+	chunks := make([]columnChunk, 0, len(columns))
+	for _, col := range columns {
+		header := col.pageHeader()
+		offset := cw.n
+		cw.Write(header)
+		cw.Write(col.page)
+		chunks = append(chunks, columnChunk{column: col, offset: offset, size: int64(len(header) + len(col.page))})
+	}
+
+	footer := fileMetaData(columns, chunks, len(rows))
+	cw.Write(footer)
+	var length [4]byte
+	binary.LittleEndian.PutUint32(length[:], uint32(len(footer)))
+	cw.Write(length[:])
+	cw.Write([]byte(parquetMagic))
+
+	if cw.err != nil {
+		return fmt.Errorf("write parquet: %w", cw.err)
+	}
+	return nil
+}
+
+const parquetMagic = "PAR1"
+
+// Parquet enum values (parquet.thrift)
+const (
+	typeInt32     = 1
+	typeFloat     = 4
+	typeByteArray = 6
+
+	repetitionRequired = 0
+	repetitionRepeated = 2
+
+	convertedUTF8 = 0
+	convertedList = 3
+
+	encodingPlain = 0
+	encodingRLE   = 3
+)
+
+// parquetColumn is a leaf column with its encoded data page.
+type parquetColumn struct {
+	name      string
+	physical  int32
+	list      bool // list<element>: path is name.list.element, with levels
+	numValues int  // values in the page, levels included
+	page      []byte
+}
+
+type columnChunk struct {
+	column parquetColumn
+	offset int64
+	size   int64
+}
+
+func parquetColumns(rows []Row) []parquetColumn {
+	meta := make([][]string, len(rows))
+	for i, r := range rows {
+		meta[i] = r.metadata()
+	}
+
+	var columns []parquetColumn
+	for i, name := range metadataColumns {
+		if name == "start_line" || name == "end_line" {
+			var page bytes.Buffer
+			for _, r := range rows {
+				n := r.StartLine
+				if name == "end_line" {
+					n = r.EndLine
+				}
+				binary.Write(&page, binary.LittleEndian, int32(n))
+			}
+			columns = append(columns, parquetColumn{name: name, physical: typeInt32, numValues: len(rows), page: page.Bytes()})
+			continue
+		}
+
+		var page bytes.Buffer
+		for _, m := range meta {
+			v := m[i]
+			binary.Write(&page, binary.LittleEndian, uint32(len(v)))
+			page.WriteString(v)
+		}
+		columns = append(columns, parquetColumn{name: name, physical: typeByteArray, numValues: len(rows), page: page.Bytes()})
+	}
+
+	// vector: required group (LIST) { repeated group list { required float element } }
+	// Max repetition and definition level are both 1; an empty vector is a
+	// single (0, 0) level pair without value.
+	var repLevels, defLevels []byte
+	var values bytes.Buffer
+	for _, r := range rows {
+		if len(r.Vector) == 0 {
+			repLevels = append(repLevels, 0)
+			defLevels = append(defLevels, 0)
+			continue
+		}
+		for i, v := range r.Vector {
+			if i == 0 {
+				repLevels = append(repLevels, 0)
+			} else {
+				repLevels = append(repLevels, 1)
+			}
+			defLevels = append(defLevels, 1)
+			binary.Write(&values, binary.LittleEndian, math.Float32bits(v))
+		}
+	}
+	var page bytes.Buffer
+	page.Write(encodeLevels(repLevels))
+	page.Write(encodeLevels(defLevels))
+	page.Write(values.Bytes())
+	columns = append(columns, parquetColumn{name: "vector", physical: typeFloat, list: true, numValues: len(repLevels), page: page.Bytes()})
+
+	return columns
+}
+
+func (c parquetColumn) path() []string {
+	if c.list {
+		return []string{c.name, "list", "element"}
+	}
+	return []string{c.name}
+}
+
+// encodeLevels encodes 0/1 levels as RLE runs (bit width 1), prefixed with
+// their byte length as data page v1 requires.
+func encodeLevels(levels []byte) []byte {
+	var runs []byte
+	for i := 0; i < len(levels); {
+		j := i
+		for j < len(levels) && levels[j] == levels[i] {
+			j++
+		}
+		runs = binary.AppendUvarint(runs, uint64(j-i)<<1)
+		runs = append(runs, levels[i])
+		i = j
+	}
+	out := binary.LittleEndian.AppendUint32(nil, uint32(len(runs)))
+	return append(out, runs...)
+}
+
+func (c parquetColumn) pageHeader() []byte {
+	t := &compactWriter{}
+	t.beginStruct()
+	t.i32(1, 0) // DATA_PAGE
+	t.i32(2, int32(len(c.page)))
+	t.i32(3, int32(len(c.page)))
+	t.fieldStruct(5)
+	t.i32(1, int32(c.numValues))
+	t.i32(2, encodingPlain)
+	t.i32(3, encodingRLE)
+	t.i32(4, encodingRLE)
+	t.endStruct()
+	t.endStruct()
+	return t.buf
+}
+
+func fileMetaData(columns []parquetColumn, chunks []columnChunk, numRows int) []byte {
+	t := &compactWriter{}
+	t.beginStruct()
+	t.i32(1, 1) // version
+
+	// Schema in depth-first order: root, then the columns
+	schemaSize := 1
+	for _, c := range columns {
+		schemaSize++
+		if c.list {
+			schemaSize += 2
+		}
+	}
+	t.listHeader(2, compactStruct, schemaSize)
+	schemaElement(t, 0, -1, "schema", len(columns), -1)
+	for _, c := range columns {
+		switch {
+		case c.list:
+			schemaElement(t, 0, repetitionRequired, c.name, 1, convertedList)
+			schemaElement(t, 0, repetitionRepeated, "list", 1, -1)
+			schemaElement(t, c.physical, repetitionRequired, "element", 0, -1)
+		case c.physical == typeByteArray:
+			schemaElement(t, c.physical, repetitionRequired, c.name, 0, convertedUTF8)
+		default:
+			schemaElement(t, c.physical, repetitionRequired, c.name, 0, -1)
+		}
+	}
+
+	t.i64(3, int64(numRows))
+
+	var total int64
+	for _, ch := range chunks {
+		total += ch.size
+	}
+	t.listHeader(4, compactStruct, 1)
+	t.beginStruct() // RowGroup
+	t.listHeader(1, compactStruct, len(chunks))
+	for _, ch := range chunks {
+		t.beginStruct() // ColumnChunk
+		t.i64(2, ch.offset)
+		t.fieldStruct(3) // ColumnMetaData
+		t.i32(1, ch.column.physical)
+		t.listHeader(2, compactI32, 2)
+		t.varint(zigzag(encodingPlain))
+		t.varint(zigzag(encodingRLE))
+		path := ch.column.path()
+		t.listHeader(3, compactBinary, len(path))
+		for _, p := range path {
+			t.varint(uint64(len(p)))
+			t.buf = append(t.buf, p...)
+		}
+		t.i32(4, 0) // UNCOMPRESSED
+		t.i64(5, int64(ch.column.numValues))
+		t.i64(6, ch.size)
+		t.i64(7, ch.size)
+		t.i64(9, ch.offset)
+		t.endStruct()
+		t.endStruct()
+	}
+	t.i64(2, total)
+	t.i64(3, int64(numRows))
+	t.endStruct()
+
+	t.binary(6, "rag-code-mcp export-embeddings")
+	t.endStruct()
+	return t.buf
+}
+
+// schemaElement writes a SchemaElement; physical 0 marks a group, and -1
+// leaves the repetition (root) or converted type unset.
+func schemaElement(t *compactWriter, physical, repetition int32, name string, children int, converted int32) {
+	t.beginStruct()
+	if physical != 0 {
+		t.i32(1, physical)
+	}
+	if repetition >= 0 {
+		t.i32(3, repetition)
+	}
+	t.binary(4, name)
+	if children > 0 {
+		t.i32(5, int32(children))
+	}
+	if converted >= 0 {
+		t.i32(6, converted)
+	}
+	t.endStruct()
+}
+
+// Thrift compact protocol types
+const (
+	compactI32    = 5
+	compactI64    = 6
+	compactBinary = 8
+	compactList   = 9
+	compactStruct = 12
+)
+
+// compactWriter encodes the Thrift compact protocol used by Parquet headers
+// and footers. Fields must be written in increasing id order.
+type compactWriter struct {
+	buf  []byte
+	last []int16 // last field id of each open struct
+}
+
+func (t *compactWriter) beginStruct() {
+	t.last = append(t.last, 0)
+}
+
+func (t *compactWriter) endStruct() {
+	t.buf = append(t.buf, 0) // stop field
+	t.last = t.last[:len(t.last)-1]
+}
+
+func (t *compactWriter) field(id int16, typ byte) {
+	top := &t.last[len(t.last)-1]
+	if delta := id - *top; delta > 0 && delta <= 15 {
+		t.buf = append(t.buf, byte(delta)<<4|typ)
+	} else {
+		t.buf = append(t.buf, typ)
+		t.varint(zigzag(int64(id)))
+	}
+	*top = id
+}
+
+func (t *compactWriter) fieldStruct(id int16) {
+	t.field(id, compactStruct)
+	t.beginStruct()
+}
+
+func (t *compactWriter) i32(id int16, v int32) {
+	t.field(id, compactI32)
+	t.varint(zigzag(int64(v)))
+}
+
+func (t *compactWriter) i64(id int16, v int64) {
+	t.field(id, compactI64)
+	t.varint(zigzag(v))
+}
+
+func (t *compactWriter) binary(id int16, s string) {
+	t.field(id, compactBinary)
+	t.varint(uint64(len(s)))
+	t.buf = append(t.buf, s...)
+}
+
+func (t *compactWriter) listHeader(id int16, elem byte, n int) {
+	t.field(id, compactList)
+	if n < 15 {
+		t.buf = append(t.buf, byte(n)<<4|elem)
+		return
+	}
+	t.buf = append(t.buf, 0xf0|elem)
+	t.varint(uint64(n))
+}
+
+func (t *compactWriter) varint(v uint64) {
+	t.buf = binary.AppendUvarint(t.buf, v)
+}
+
+func zigzag(v int64) uint64 {
+	return uint64(v<<1) ^ uint64(v>>63)
+}
+
+type countingWriter struct {
+	w   io.Writer
+	n   int64
+	err error
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	if c.err != nil {
+		return 0, c.err
+	}
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	c.err = err
+	return n, err
+}
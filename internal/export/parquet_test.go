@@ -0,0 +1,138 @@
+package export
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"testing"
+)
+
+// compactReader decodes Thrift compact structs into field id -> value maps,
+// enough to check what WriteParquet produces.
+type compactReader struct {
+	buf []byte
+	pos int
+}
+
+func (r *compactReader) uvarint() uint64 {
+	v, n := binary.Uvarint(r.buf[r.pos:])
+	r.pos += n
+	return v
+}
+
+func (r *compactReader) int() int64 {
+	u := r.uvarint()
+	return int64(u>>1) ^ -int64(u&1)
+}
+
+func (r *compactReader) value(typ byte) interface{} {
+	switch typ {
+	case compactI32, compactI64:
+		return r.int()
+	case compactBinary:
+		n := int(r.uvarint())
+		s := string(r.buf[r.pos : r.pos+n])
+		r.pos += n
+		return s
+	case compactList:
+		head := r.buf[r.pos]
+		r.pos++
+		n := int(head >> 4)
+		if n == 15 {
+			n = int(r.uvarint())
+		}
+		list := make([]interface{}, n)
+		for i := range list {
+			list[i] = r.value(head & 0x0f)
+		}
+		return list
+	case compactStruct:
+		fields := make(map[int16]interface{})
+		var last int16
+		for {
+			head := r.buf[r.pos]
+			r.pos++
+			if head == 0 {
+				return fields
+			}
+			id := last + int16(head>>4)
+			if head>>4 == 0 {
+				id = int16(r.int())
+			}
+			fields[id] = r.value(head & 0x0f)
+			last = id
+		}
+	}
+	panic("unexpected compact type")
+}
+
+func TestWriteParquet(t *testing.T) {
+	rows := []Row{
+		{ChunkID: "1", Kind: "function", Name: "Open", File: "/ws/db.go", StartLine: 3, EndLine: 9, Vector: []float32{0.5, -1, 2}},
+		{ChunkID: "2", Kind: "markdown", Name: "Setup", File: "/ws/README.md", Lang: "en", Vector: []float32{1, 1, 1}},
+	}
+	var buf bytes.Buffer
+	if err := WriteParquet(&buf, rows); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+	if string(data[:4]) != "PAR1" || string(data[len(data)-4:]) != "PAR1" {
+		t.Fatalf("missing magic bytes")
+	}
+
+	footerLen := int(binary.LittleEndian.Uint32(data[len(data)-8:]))
+	footerStart := len(data) - 8 - footerLen
+	r := &compactReader{buf: data[:len(data)-8], pos: footerStart}
+	meta := r.value(compactStruct).(map[int16]interface{})
+	if r.pos != len(data)-8 {
+		t.Fatalf("footer decoded %d bytes, length says %d", r.pos-footerStart, footerLen)
+	}
+	if meta[3] != int64(2) {
+		t.Errorf("num_rows = %v, want 2", meta[3])
+	}
+
+	schema := meta[2].([]interface{})
+	if got := len(schema); got != 1+len(metadataColumns)+3 {
+		t.Errorf("schema has %d elements", got)
+	}
+	if name := schema[1].(map[int16]interface{})[4]; name != "label" {
+		t.Errorf("first column = %v, want label", name)
+	}
+
+	columns := meta[4].([]interface{})[0].(map[int16]interface{})[1].([]interface{})
+	last := columns[len(columns)-1].(map[int16]interface{})[3].(map[int16]interface{})
+	path := last[3].([]interface{})
+	if len(path) != 3 || path[0] != "vector" || path[2] != "element" {
+		t.Errorf("vector path = %v", path)
+	}
+
+	// Read the vector page back: levels, then the floats
+	page := &compactReader{buf: data, pos: int(last[9].(int64))}
+	header := page.value(compactStruct).(map[int16]interface{})
+	if n := header[5].(map[int16]interface{})[1]; n != int64(6) {
+		t.Errorf("vector num_values = %v, want 6", n)
+	}
+	body := data[page.pos:]
+	for i := 0; i < 2; i++ {
+		body = body[4+binary.LittleEndian.Uint32(body):]
+	}
+	var got []float32
+	for i := 0; i < 6; i++ {
+		got = append(got, math.Float32frombits(binary.LittleEndian.Uint32(body[i*4:])))
+	}
+	want := []float32{0.5, -1, 2, 1, 1, 1}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("vector values = %v, want %v", got, want)
+		}
+	}
+}
+
+func TestEncodeLevels(t *testing.T) {
+	// Two rows of three values: runs 0, 1x2, 0, 1x2
+	got := encodeLevels([]byte{0, 1, 1, 0, 1, 1})
+	want := []byte{8, 0, 0, 0, 2, 0, 4, 1, 2, 0, 4, 1}
+	if !bytes.Equal(got, want) {
+		t.Errorf("encodeLevels = %v, want %v", got, want)
+	}
+}
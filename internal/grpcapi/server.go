@@ -0,0 +1,234 @@
+// Package grpcapi implements the gRPC API of api/ragcode/v1 on top of the
+// MCP tool implementations, so gRPC clients get the same results as MCP
+// clients.
+package grpcapi
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	ragcodev1 "github.com/doITmagic/rag-code-mcp/api/ragcode/v1"
+	"github.com/doITmagic/rag-code-mcp/internal/codetypes"
+	"github.com/doITmagic/rag-code-mcp/internal/tools"
+	"github.com/doITmagic/rag-code-mcp/internal/workspace"
+)
+
+// Tool is the part of an MCP tool the API calls.
+type Tool interface {
+	Execute(ctx context.Context, args map[string]interface{}) (string, error)
+}
+
+// Server implements ragcodev1.RagCodeServer. Search and LookupSymbol run the
+// search_code and get_symbols_bulk tools with JSON output; IndexStatus reads
+// the workspace manager.
+type Server struct {
+	ragcodev1.UnimplementedRagCodeServer
+
+	search           Tool
+	lookup           Tool
+	workspaceManager *workspace.Manager
+}
+
+// NewServer creates the API server over the search_code and
+// get_symbols_bulk tools.
+func NewServer(search, lookup Tool, wm *workspace.Manager) *Server {
+	return &Server{
+		search:           search,
+		lookup:           lookup,
+		workspaceManager: wm,
+	}
+}
+
+// NewGRPCServer returns a gRPC server with the API registered. When token
+// is set every call needs the metadata "authorization: Bearer <token>".
+func NewGRPCServer(api *Server, token string) *grpc.Server {
+	var opts []grpc.ServerOption
+	if token != "" {
+		opts = append(opts, grpc.UnaryInterceptor(tokenInterceptor(token)))
+	}
+	s := grpc.NewServer(opts...)
+	ragcodev1.RegisterRagCodeServer(s, api)
+	return s
+}
+
+func tokenInterceptor(token string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		for _, v := range md.Get("authorization") {
+			if got, ok := strings.CutPrefix(v, "Bearer "); ok && subtle.ConstantTimeCompare([]byte(got), []byte(token)) == 1 {
+				return handler(ctx, req)
+			}
+		}
+		return nil, status.Error(codes.Unauthenticated, "missing or invalid bearer token")
+	}
+}
+
+// Search runs search_code.
+func (s *Server) Search(ctx context.Context, req *ragcodev1.SearchRequest) (*ragcodev1.SearchResponse, error) {
+	if req.GetWorkspacePath() == "" || strings.TrimSpace(req.GetQuery()) == "" {
+		return nil, status.Error(codes.InvalidArgument, "workspace_path and query are required")
+	}
+	args := map[string]interface{}{
+		"query":         req.GetQuery(),
+		"file_path":     req.GetWorkspacePath(),
+		"output_format": "json",
+	}
+	if req.GetLimit() > 0 {
+		args["limit"] = float64(req.GetLimit())
+	}
+	if len(req.GetTags()) > 0 {
+		args["tags"] = strings.Join(req.GetTags(), ",")
+	}
+	var descriptors []codetypes.SymbolDescriptor
+	if err := runTool(ctx, s.search, args, &descriptors); err != nil {
+		return nil, err
+	}
+	resp := &ragcodev1.SearchResponse{}
+	for _, d := range descriptors {
+		resp.Results = append(resp.Results, symbolFromDescriptor(d))
+	}
+	return resp, nil
+}
+
+// LookupSymbol runs get_symbols_bulk for one symbol.
+func (s *Server) LookupSymbol(ctx context.Context, req *ragcodev1.LookupSymbolRequest) (*ragcodev1.LookupSymbolResponse, error) {
+	if req.GetWorkspacePath() == "" || req.GetName() == "" {
+		return nil, status.Error(codes.InvalidArgument, "workspace_path and name are required")
+	}
+	symbol := map[string]interface{}{"name": req.GetName()}
+	if req.GetKind() != "" {
+		symbol["kind"] = req.GetKind()
+	}
+	if req.GetPackage() != "" {
+		symbol["package"] = req.GetPackage()
+	}
+	args := map[string]interface{}{
+		"symbols":       []interface{}{symbol},
+		"file_path":     req.GetWorkspacePath(),
+		"include_code":  req.GetIncludeCode(),
+		"output_format": "json",
+	}
+	if req.GetLimit() > 0 {
+		args["max_matches"] = float64(req.GetLimit())
+	}
+	var results []tools.SymbolResult
+	if err := runTool(ctx, s.lookup, args, &results); err != nil {
+		return nil, err
+	}
+	if len(results) == 0 {
+		return nil, status.Errorf(codes.NotFound, "symbol %s not found", req.GetName())
+	}
+	if results[0].Error != "" {
+		return nil, status.Error(codes.NotFound, results[0].Error)
+	}
+	resp := &ragcodev1.LookupSymbolResponse{Truncated: results[0].Truncated}
+	for _, d := range results[0].Symbols {
+		resp.Symbols = append(resp.Symbols, symbolFromDescriptor(d))
+	}
+	return resp, nil
+}
+
+// IndexStatus reports the workspace languages and the last indexing run.
+func (s *Server) IndexStatus(ctx context.Context, req *ragcodev1.IndexStatusRequest) (*ragcodev1.IndexStatusResponse, error) {
+	if req.GetWorkspacePath() == "" {
+		return nil, status.Error(codes.InvalidArgument, "workspace_path is required")
+	}
+	if s.workspaceManager == nil {
+		return nil, status.Error(codes.Unavailable, "workspace manager not configured")
+	}
+	info, err := s.workspaceManager.DetectWorkspace(map[string]interface{}{"file_path": req.GetWorkspacePath()})
+	if err != nil {
+		return nil, status.Errorf(codes.NotFound, "failed to detect workspace: %v", err)
+	}
+	st, err := s.workspaceManager.IndexStatus(info)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	resp := &ragcodev1.IndexStatusResponse{
+		Root:         info.Root,
+		WorkspaceId:  info.ID,
+		IndexedFiles: int32(st.IndexedFiles),
+	}
+	if !st.LastIndexed.IsZero() {
+		resp.LastIndexed = timestamppb.New(st.LastIndexed)
+	}
+	for _, l := range st.Languages {
+		resp.Languages = append(resp.Languages, &ragcodev1.LanguageStatus{
+			Language:   l.Language,
+			Collection: l.Collection,
+			Indexing:   l.Indexing,
+		})
+	}
+	if table, err := s.workspaceManager.Symbols(info); err == nil && table != nil {
+		resp.Symbols = int32(len(table.All()))
+	}
+	return resp, nil
+}
+
+// runTool executes a tool and decodes its JSON output into out. Tools
+// answer conditions such as "not indexed yet" with a message instead of
+// JSON; those become FailedPrecondition errors carrying the message.
+func runTool(ctx context.Context, tool Tool, args map[string]interface{}, out interface{}) error {
+	if tool == nil {
+		return status.Error(codes.Unavailable, "tool not configured")
+	}
+	result, err := tool.Execute(ctx, args)
+	if err != nil {
+		if ctx.Err() != nil {
+			return status.FromContextError(ctx.Err()).Err()
+		}
+		return status.Error(codes.Internal, err.Error())
+	}
+	if !json.Valid([]byte(result)) {
+		return status.Error(codes.FailedPrecondition, strings.TrimSpace(result))
+	}
+	if err := json.Unmarshal([]byte(result), out); err != nil {
+		return status.Error(codes.Internal, fmt.Sprintf("unexpected tool output: %v", err))
+	}
+	return nil
+}
+
+func symbolFromDescriptor(d codetypes.SymbolDescriptor) *ragcodev1.Symbol {
+	sym := &ragcodev1.Symbol{
+		Language:    d.Language,
+		Kind:        d.Kind,
+		Name:        d.Name,
+		Namespace:   d.Namespace,
+		Package:     d.Package,
+		Signature:   d.Signature,
+		Description: d.Description,
+		Tags:        d.Tags,
+	}
+	if d.Location.FilePath != "" || d.Location.StartLine > 0 {
+		sym.Location = &ragcodev1.Location{
+			FilePath:  d.Location.FilePath,
+			StartLine: int32(d.Location.StartLine),
+			EndLine:   int32(d.Location.EndLine),
+		}
+	}
+	if score, ok := d.Metadata["score"].(float64); ok {
+		sym.Score = score
+	}
+	for _, key := range []string{"code", "snippet"} {
+		if code, ok := d.Metadata[key].(string); ok && code != "" {
+			sym.Code = code
+			break
+		}
+	}
+	switch id := d.Metadata["chunk_id"].(type) {
+	case string:
+		sym.ChunkId = id
+	case float64:
+		sym.ChunkId = fmt.Sprint(id)
+	}
+	return sym
+}
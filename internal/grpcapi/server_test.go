@@ -0,0 +1,97 @@
+package grpcapi
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	ragcodev1 "github.com/doITmagic/rag-code-mcp/api/ragcode/v1"
+)
+
+// toolFunc adapts a function to Tool.
+type toolFunc func(args map[string]interface{}) (string, error)
+
+func (f toolFunc) Execute(ctx context.Context, args map[string]interface{}) (string, error) {
+	return f(args)
+}
+
+func dialTestServer(t *testing.T, api *Server, token string) ragcodev1.RagCodeClient {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	s := NewGRPCServer(api, token)
+	go s.Serve(lis)
+	t.Cleanup(s.Stop)
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return ragcodev1.NewRagCodeClient(conn)
+}
+
+func TestServer(t *testing.T) {
+	var searchArgs map[string]interface{}
+	search := toolFunc(func(args map[string]interface{}) (string, error) {
+		searchArgs = args
+		if args["query"] == "unindexed" {
+			return "❌ Workspace '/w' is not indexed yet.", nil
+		}
+		return `[{"language":"go","kind":"function","name":"Retry","signature":"func Retry(n int) error","location":{"file_path":"/w/retry.go","start_line":10,"end_line":20},"metadata":{"score":0.87,"chunk_id":"abc","snippet":"func Retry(n int) error {}"}}]`, nil
+	})
+	lookup := toolFunc(func(args map[string]interface{}) (string, error) {
+		data, _ := json.Marshal(args["symbols"])
+		if string(data) == `[{"kind":"type","name":"Missing"}]` {
+			return `[{"request":{"name":"Missing","kind":"type"},"error":"not found"}]`, nil
+		}
+		return `[{"request":{"name":"Manager"},"symbols":[{"language":"go","kind":"type","name":"Manager","package":"workspace","location":{"file_path":"/w/manager.go","start_line":3,"end_line":9}}],"truncated":true}]`, nil
+	})
+	client := dialTestServer(t, NewServer(search, lookup, nil), "s3cret")
+
+	if _, err := client.Search(context.Background(), &ragcodev1.SearchRequest{WorkspacePath: "/w", Query: "retry"}); status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("no token: err = %v", err)
+	}
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer s3cret")
+
+	resp, err := client.Search(ctx, &ragcodev1.SearchRequest{WorkspacePath: "/w/main.go", Query: "retry", Limit: 3, Tags: []string{"api", "db"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if searchArgs["file_path"] != "/w/main.go" || searchArgs["limit"] != float64(3) || searchArgs["tags"] != "api,db" || searchArgs["output_format"] != "json" {
+		t.Fatalf("search args = %v", searchArgs)
+	}
+	if len(resp.Results) != 1 {
+		t.Fatalf("results = %v", resp.Results)
+	}
+	r := resp.Results[0]
+	if r.Name != "Retry" || r.Score != 0.87 || r.ChunkId != "abc" || r.Code == "" || r.Location.GetStartLine() != 10 {
+		t.Fatalf("result = %v", r)
+	}
+
+	if _, err := client.Search(ctx, &ragcodev1.SearchRequest{WorkspacePath: "/w", Query: "unindexed"}); status.Code(err) != codes.FailedPrecondition {
+		t.Fatalf("unindexed: err = %v", err)
+	}
+	if _, err := client.Search(ctx, &ragcodev1.SearchRequest{Query: "retry"}); status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("missing workspace: err = %v", err)
+	}
+
+	sym, err := client.LookupSymbol(ctx, &ragcodev1.LookupSymbolRequest{WorkspacePath: "/w", Name: "Manager"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sym.Symbols) != 1 || sym.Symbols[0].Package != "workspace" || !sym.Truncated {
+		t.Fatalf("lookup = %v", sym)
+	}
+	if _, err := client.LookupSymbol(ctx, &ragcodev1.LookupSymbolRequest{WorkspacePath: "/w", Name: "Missing", Kind: "type"}); status.Code(err) != codes.NotFound {
+		t.Fatalf("missing symbol: err = %v", err)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"strings"
 	"time"
 )
 
@@ -101,6 +102,118 @@ func CheckQdrant(url string) CheckResult {
 	return result
 }
 
+// CheckOpenAI verifies an OpenAI-compatible API is reachable and accepts the
+// API key. Servers without a /models endpoint (Azure) count as reachable.
+func CheckOpenAI(baseURL, apiKey string) CheckResult {
+	result := CheckResult{
+		Service: "OpenAI-compatible API",
+		Status:  "unknown",
+	}
+
+	if baseURL == "" {
+		baseURL = "https://api.openai.com/v1"
+	}
+	baseURL = strings.TrimRight(baseURL, "/")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", baseURL+"/models", nil)
+	if err != nil {
+		result.Status = "error"
+		result.Error = err
+		result.Message = fmt.Sprintf("Failed to create request: %v", err)
+		return result
+	}
+	if apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+apiKey)
+		req.Header.Set("api-key", apiKey)
+	}
+
+	client := &http.Client{Timeout: 5 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		result.Status = "error"
+		result.Error = err
+		result.Message = fmt.Sprintf("Cannot connect to %s", baseURL)
+		return result
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		result.Status = "error"
+		result.Message = fmt.Sprintf("%s rejected the API key (status %d)", baseURL, resp.StatusCode)
+	case resp.StatusCode >= 500:
+		result.Status = "error"
+		result.Message = fmt.Sprintf("%s returned status %d", baseURL, resp.StatusCode)
+	default:
+		result.Status = "ok"
+		result.Message = fmt.Sprintf("Connected to %s", baseURL)
+	}
+
+	return result
+}
+
+// CheckTEI verifies a text-embeddings-inference server is ready (GET
+// /health). For an OpenAI-style /embeddings URL it only checks that the
+// server answers and accepts the API key.
+func CheckTEI(baseURL, apiKey string) CheckResult {
+	result := CheckResult{
+		Service: "TEI",
+		Status:  "unknown",
+	}
+
+	if baseURL == "" {
+		baseURL = "http://localhost:8080"
+	}
+	baseURL = strings.TrimRight(baseURL, "/")
+	url := baseURL + "/health"
+	openAIStyle := strings.HasSuffix(baseURL, "/embeddings")
+	if openAIStyle {
+		url = baseURL
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		result.Status = "error"
+		result.Error = err
+		result.Message = fmt.Sprintf("Failed to create request: %v", err)
+		return result
+	}
+	if apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+apiKey)
+	}
+
+	client := &http.Client{Timeout: 5 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		result.Status = "error"
+		result.Error = err
+		result.Message = fmt.Sprintf("Cannot connect to TEI at %s", baseURL)
+		return result
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		result.Status = "error"
+		result.Message = fmt.Sprintf("%s rejected the API key (status %d)", baseURL, resp.StatusCode)
+	case resp.StatusCode == http.StatusOK, openAIStyle && resp.StatusCode < 500:
+		// /embeddings only accepts POST: any answer means the server is up
+		result.Status = "ok"
+		result.Message = fmt.Sprintf("Connected to TEI at %s", baseURL)
+	default:
+		result.Status = "error"
+		result.Message = fmt.Sprintf("TEI returned status %d (the model may still be loading)", resp.StatusCode)
+	}
+
+	return result
+}
+
 // CheckAll runs all health checks and returns results
 func CheckAll(ollamaURL, qdrantURL string) []CheckResult {
 	return []CheckResult{
@@ -150,6 +263,21 @@ func GetRemediation(results []CheckResult) string {
   Pull required models:
     ollama pull nomic-embed-text
     ollama pull phi3:medium
+`
+			case "OpenAI-compatible API":
+				remediation += `
+  Check llm.openai_base_url (e.g. http://localhost:8000/v1 for vLLM,
+  http://localhost:1234/v1 for LM Studio) and that the server is running.
+  For OpenAI and Azure, set llm.openai_api_key or OPENAI_API_KEY.
+`
+			case "TEI":
+				remediation += `
+  Start a text-embeddings-inference server, e.g. with Docker:
+    docker run -d -p 8080:80 \
+      ghcr.io/huggingface/text-embeddings-inference:cpu-1.5 \
+      --model-id BAAI/bge-small-en-v1.5
+
+  Then check llm.tei_base_url (or TEI_BASE_URL).
 `
 			case "Qdrant":
 				remediation += `
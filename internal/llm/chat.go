@@ -0,0 +1,263 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/doITmagic/rag-code-mcp/internal/config"
+)
+
+// Chatter generates text outside the embedding provider
+type Chatter interface {
+	Generate(ctx context.Context, prompt string, opts ...GenerateOption) (string, error)
+}
+
+// defaultChatMaxTokens caps replies when neither the call nor llm.max_tokens
+// does; the Anthropic API requires a cap
+const defaultChatMaxTokens = 1024
+
+// chatOptions merges the call's options over the configured temperature and
+// max tokens
+func chatOptions(cfg config.LLMConfig, opts []GenerateOption) GenerateOptions {
+	o := GenerateOptions{Temperature: cfg.Temperature, MaxTokens: cfg.MaxTokens}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.MaxTokens <= 0 {
+		o.MaxTokens = defaultChatMaxTokens
+	}
+	if len(o.StopSequences) == 0 {
+		o.StopSequences = o.StopWords
+	}
+	return o
+}
+
+// chatClient returns the HTTP client of a chat provider
+func chatClient(cfg config.LLMConfig) *http.Client {
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = 60 * time.Second
+	}
+	return &http.Client{Timeout: timeout}
+}
+
+// AnthropicChat generates text with the Anthropic Messages API
+type AnthropicChat struct {
+	baseURL string
+	apiKey  string
+	model   string
+	config  config.LLMConfig
+	client  *http.Client
+}
+
+// NewAnthropicChat creates a chat client for llm.anthropic_model
+func NewAnthropicChat(cfg config.LLMConfig) (*AnthropicChat, error) {
+	if cfg.AnthropicAPIKey == "" {
+		return nil, fmt.Errorf("anthropic API key is required (set anthropic_api_key or ANTHROPIC_API_KEY)")
+	}
+	if cfg.AnthropicModel == "" {
+		return nil, fmt.Errorf("anthropic model is required (set anthropic_model)")
+	}
+	baseURL := strings.TrimRight(cfg.AnthropicBaseURL, "/")
+	if baseURL == "" {
+		baseURL = "https://api.anthropic.com"
+	}
+	return &AnthropicChat{
+		baseURL: baseURL,
+		apiKey:  cfg.AnthropicAPIKey,
+		model:   cfg.AnthropicModel,
+		config:  cfg,
+		client:  chatClient(cfg),
+	}, nil
+}
+
+// Generate sends prompt as a single user message
+func (c *AnthropicChat) Generate(ctx context.Context, prompt string, opts ...GenerateOption) (string, error) {
+	o := chatOptions(c.config, opts)
+	body := map[string]interface{}{
+		"model":      c.model,
+		"max_tokens": o.MaxTokens,
+		"messages":   []map[string]string{{"role": "user", "content": prompt}},
+	}
+	if o.Temperature != 0 {
+		body["temperature"] = o.Temperature
+	}
+	if o.TopP != 0 {
+		body["top_p"] = o.TopP
+	}
+	if len(o.StopSequences) > 0 {
+		body["stop_sequences"] = o.StopSequences
+	}
+	header := http.Header{}
+	header.Set("x-api-key", c.apiKey)
+	header.Set("anthropic-version", "2023-06-01")
+
+	var resp struct {
+		Content []struct {
+			Type string `json:"type"`
+			Text string `json:"text"`
+		} `json:"content"`
+	}
+	if err := doJSON(ctx, c.client, "anthropic", http.MethodPost, c.baseURL+"/v1/messages", header, body, &resp); err != nil {
+		return "", err
+	}
+	var sb strings.Builder
+	for _, block := range resp.Content {
+		if block.Type == "text" {
+			sb.WriteString(block.Text)
+		}
+	}
+	return sb.String(), nil
+}
+
+// GeminiChat generates text with the Gemini generateContent API
+type GeminiChat struct {
+	baseURL string
+	apiKey  string
+	model   string
+	config  config.LLMConfig
+	client  *http.Client
+}
+
+// NewGeminiChat creates a chat client for llm.gemini_model
+func NewGeminiChat(cfg config.LLMConfig) (*GeminiChat, error) {
+	if cfg.GeminiAPIKey == "" {
+		return nil, fmt.Errorf("gemini API key is required (set gemini_api_key or GEMINI_API_KEY)")
+	}
+	if cfg.GeminiModel == "" {
+		return nil, fmt.Errorf("gemini model is required (set gemini_model)")
+	}
+	baseURL := strings.TrimRight(cfg.GeminiBaseURL, "/")
+	if baseURL == "" {
+		baseURL = "https://generativelanguage.googleapis.com/v1beta"
+	}
+	return &GeminiChat{
+		baseURL: baseURL,
+		apiKey:  cfg.GeminiAPIKey,
+		model:   cfg.GeminiModel,
+		config:  cfg,
+		client:  chatClient(cfg),
+	}, nil
+}
+
+// Generate sends prompt as a single user turn
+func (c *GeminiChat) Generate(ctx context.Context, prompt string, opts ...GenerateOption) (string, error) {
+	o := chatOptions(c.config, opts)
+	generation := map[string]interface{}{"maxOutputTokens": o.MaxTokens}
+	if o.Temperature != 0 {
+		generation["temperature"] = o.Temperature
+	}
+	if o.TopP != 0 {
+		generation["topP"] = o.TopP
+	}
+	if o.TopK != 0 {
+		generation["topK"] = o.TopK
+	}
+	if len(o.StopSequences) > 0 {
+		generation["stopSequences"] = o.StopSequences
+	}
+	body := map[string]interface{}{
+		"contents": []map[string]interface{}{
+			{"role": "user", "parts": []map[string]string{{"text": prompt}}},
+		},
+		"generationConfig": generation,
+	}
+	header := http.Header{}
+	header.Set("x-goog-api-key", c.apiKey)
+
+	var resp struct {
+		Candidates []struct {
+			Content struct {
+				Parts []struct {
+					Text string `json:"text"`
+				} `json:"parts"`
+			} `json:"content"`
+			FinishReason string `json:"finishReason"`
+		} `json:"candidates"`
+	}
+	endpoint := fmt.Sprintf("%s/models/%s:generateContent", c.baseURL, url.PathEscape(c.model))
+	if err := doJSON(ctx, c.client, "gemini", http.MethodPost, endpoint, header, body, &resp); err != nil {
+		return "", err
+	}
+	if len(resp.Candidates) == 0 {
+		return "", fmt.Errorf("gemini returned no candidates (the prompt may have been blocked)")
+	}
+	var sb strings.Builder
+	for _, part := range resp.Candidates[0].Content.Parts {
+		sb.WriteString(part.Text)
+	}
+	return sb.String(), nil
+}
+
+// NewChatter creates the chat client of llm.chat_provider
+func NewChatter(cfg config.LLMConfig) (Chatter, error) {
+	switch cfg.ChatProvider {
+	case "anthropic":
+		return NewAnthropicChat(cfg)
+	case "gemini":
+		return NewGeminiChat(cfg)
+	default:
+		return nil, fmt.Errorf("unknown chat provider: %s (supported: anthropic, gemini)", cfg.ChatProvider)
+	}
+}
+
+// chatProvider embeds with a provider and generates with a hosted chat
+// model (llm.chat_provider)
+type chatProvider struct {
+	Provider
+	chat Chatter
+	name string
+}
+
+// Generate generates text with the chat model
+func (p *chatProvider) Generate(ctx context.Context, prompt string, opts ...GenerateOption) (string, error) {
+	return p.chat.Generate(ctx, prompt, opts...)
+}
+
+// GenerateStream delivers the whole reply of the chat model as one chunk
+func (p *chatProvider) GenerateStream(ctx context.Context, prompt string, opts ...GenerateOption) (<-chan string, <-chan error) {
+	out := make(chan string, 1)
+	errs := make(chan error, 1)
+	go func() {
+		defer close(out)
+		defer close(errs)
+		text, err := p.chat.Generate(ctx, prompt, opts...)
+		if err != nil {
+			errs <- err
+			return
+		}
+		out <- text
+	}()
+	return out, errs
+}
+
+// Name returns the provider name
+func (p *chatProvider) Name() string {
+	return p.name + "+" + p.Provider.Name()
+}
+
+// EmbedBatch embeds texts with the wrapped provider
+func (p *chatProvider) EmbedBatch(ctx context.Context, texts []string) ([][]float64, error) {
+	return EmbedBatch(ctx, p.Provider, texts)
+}
+
+// EmbeddingDimension returns the vector size of the wrapped provider
+func (p *chatProvider) EmbeddingDimension(ctx context.Context) (int, error) {
+	return EmbeddingDimension(ctx, p.Provider)
+}
+
+// Close implements io.Closer
+func (p *chatProvider) Close() error {
+	if closer, ok := p.Provider.(interface{ Close() error }); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+var _ Provider = (*chatProvider)(nil)
+var _ BatchEmbedder = (*chatProvider)(nil)
+var _ DimensionReporter = (*chatProvider)(nil)
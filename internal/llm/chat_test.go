@@ -0,0 +1,111 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/doITmagic/rag-code-mcp/internal/config"
+)
+
+func TestAnthropicChat(t *testing.T) {
+	var req map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/messages" || r.Header.Get("x-api-key") != "key" || r.Header.Get("anthropic-version") == "" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		w.Write([]byte(`{"content": [{"type": "text", "text": "Parses "}, {"type": "text", "text": "the config."}]}`))
+	}))
+	defer server.Close()
+
+	c, err := NewAnthropicChat(config.LLMConfig{AnthropicAPIKey: "key", AnthropicModel: "claude-test", AnthropicBaseURL: server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	text, err := c.Generate(context.Background(), "summarize", WithMaxTokens(80), WithTemperature(0.2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if text != "Parses the config." {
+		t.Errorf("text = %q", text)
+	}
+	if req["model"] != "claude-test" || req["max_tokens"] != float64(80) || req["temperature"] != 0.2 {
+		t.Errorf("request = %v", req)
+	}
+
+	// max_tokens is required by the API
+	c.Generate(context.Background(), "summarize")
+	if req["max_tokens"] != float64(defaultChatMaxTokens) {
+		t.Errorf("max_tokens = %v, want the default", req["max_tokens"])
+	}
+
+	if _, err := NewAnthropicChat(config.LLMConfig{AnthropicModel: "claude-test"}); err == nil {
+		t.Error("missing API key should be rejected")
+	}
+}
+
+func TestGeminiChat(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/models/gemini-test:generateContent" || r.Header.Get("x-goog-api-key") != "key" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(`{"candidates": [{"content": {"parts": [{"text": "Parses the config."}]}}]}`))
+	}))
+	defer server.Close()
+
+	c, err := NewGeminiChat(config.LLMConfig{GeminiAPIKey: "key", GeminiModel: "gemini-test", GeminiBaseURL: server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if text, err := c.Generate(context.Background(), "summarize"); err != nil || text != "Parses the config." {
+		t.Errorf("Generate = %q, %v", text, err)
+	}
+
+	c.apiKey = "wrong"
+	if _, err := c.Generate(context.Background(), "summarize"); err == nil {
+		t.Error("expected the server's error status")
+	}
+}
+
+func TestNewProvider_ChatProvider(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"candidates": [{"content": {"parts": [{"text": "hosted"}]}}]}`))
+	}))
+	defer server.Close()
+
+	cfg := &config.LLMConfig{
+		Provider:      "openai",
+		OpenAIEmbed:   "unused",
+		ChatProvider:  "gemini",
+		GeminiAPIKey:  "key",
+		GeminiModel:   "gemini-test",
+		GeminiBaseURL: server.URL,
+	}
+	p, err := NewProvider(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if text, err := p.Generate(context.Background(), "hi"); err != nil || text != "hosted" {
+		t.Errorf("Generate = %q, %v; want the hosted model", text, err)
+	}
+	if p.Name() != "gemini+openai" {
+		t.Errorf("Name = %q", p.Name())
+	}
+	chunks, errs := p.GenerateStream(context.Background(), "hi")
+	if text := <-chunks; text != "hosted" {
+		t.Errorf("stream = %q", text)
+	}
+	if err := <-errs; err != nil {
+		t.Error(err)
+	}
+
+	cfg.ChatProvider = "cohere"
+	if _, err := NewProvider(cfg); err == nil {
+		t.Error("unknown chat provider should fail")
+	}
+}
@@ -0,0 +1,66 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// CommandEmbedder embeds text with an external command (llm.embed_command):
+// the text is written to its standard input and it prints the vector as JSON,
+// either [0.1, 0.2, ...] or {"embedding": [0.1, 0.2, ...]}. It lets any local
+// model, e.g. a sentence-transformers script, serve embeddings.
+type CommandEmbedder struct {
+	command string
+}
+
+// NewCommandEmbedder creates an embedder running command with the shell
+func NewCommandEmbedder(command string) (*CommandEmbedder, error) {
+	if strings.TrimSpace(command) == "" {
+		return nil, fmt.Errorf("embed command is required")
+	}
+	return &CommandEmbedder{command: command}, nil
+}
+
+// Embed runs the command once for text
+func (c *CommandEmbedder) Embed(ctx context.Context, text string) ([]float64, error) {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", c.command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", c.command)
+	}
+	cmd.Stdin = strings.NewReader(text)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("embed command: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return parseCommandEmbedding(out)
+}
+
+// parseCommandEmbedding decodes the vector printed by an embed command
+func parseCommandEmbedding(out []byte) ([]float64, error) {
+	out = bytes.TrimSpace(out)
+	var vector []float64
+	if len(out) > 0 && out[0] == '{' {
+		var obj struct {
+			Embedding []float64 `json:"embedding"`
+		}
+		if err := json.Unmarshal(out, &obj); err != nil {
+			return nil, fmt.Errorf("embed command printed invalid JSON: %w", err)
+		}
+		vector = obj.Embedding
+	} else if err := json.Unmarshal(out, &vector); err != nil {
+		return nil, fmt.Errorf("embed command printed invalid JSON: %w", err)
+	}
+	if len(vector) == 0 {
+		return nil, fmt.Errorf("embed command printed an empty embedding")
+	}
+	return vector, nil
+}
@@ -0,0 +1,76 @@
+package llm
+
+import (
+	"context"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/doITmagic/rag-code-mcp/internal/config"
+)
+
+func TestCommandEmbedder(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("test commands use sh")
+	}
+	ctx := context.Background()
+
+	// The vector is the byte count of stdin, so the text must reach the command
+	e, err := NewCommandEmbedder(`n=$(wc -c); echo "{\"embedding\": [$n, 0.5]}"`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err := e.Embed(ctx, "hello")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(v) != 2 || v[0] != 5 || v[1] != 0.5 {
+		t.Errorf("vector = %v, want [5 0.5]", v)
+	}
+
+	e, _ = NewCommandEmbedder(`cat >/dev/null; echo '[0.1, 0.2, 0.3]'`)
+	if v, err := e.Embed(ctx, "x"); err != nil || len(v) != 3 {
+		t.Errorf("array output: %v, %v", v, err)
+	}
+
+	e, _ = NewCommandEmbedder(`echo model missing >&2; exit 3`)
+	if _, err := e.Embed(ctx, "x"); err == nil || !strings.Contains(err.Error(), "model missing") {
+		t.Errorf("err = %v, want the command's stderr", err)
+	}
+
+	for _, out := range []string{`echo '[]'`, `echo 'not json'`, `echo '{"vector": [1]}'`} {
+		e, _ = NewCommandEmbedder(out)
+		if _, err := e.Embed(ctx, "x"); err == nil {
+			t.Errorf("%s: expected an error", out)
+		}
+	}
+
+	if _, err := NewCommandEmbedder("  "); err == nil {
+		t.Error("empty command should be rejected")
+	}
+}
+
+func TestNewProvider_EmbedCommand(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("test commands use sh")
+	}
+	cfg := &config.LLMConfig{
+		Provider:      "openai",
+		OpenAIEmbed:   "unused",
+		EmbedProvider: "command",
+		EmbedCommand:  `cat >/dev/null; echo '[1, 2, 3, 4]'`,
+	}
+	p, err := NewProvider(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dim, err := EmbeddingDimension(context.Background(), p)
+	if err != nil || dim != 4 {
+		t.Errorf("dimension = %d, %v; want 4 from the command", dim, err)
+	}
+
+	cfg.EmbedCommand = ""
+	if _, err := NewProvider(cfg); err == nil {
+		t.Error("embed_provider command without embed_command should fail")
+	}
+}
@@ -0,0 +1,310 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/doITmagic/rag-code-mcp/internal/config"
+)
+
+// Kinds of provider requests
+const (
+	KindEmbed = "embed"
+	KindChat  = "chat"
+)
+
+// ErrBudgetExceeded is returned instead of calling a provider whose daily
+// budget (llm.budgets) is used up
+var ErrBudgetExceeded = errors.New("daily provider budget exceeded")
+
+// ProviderUsage counts the requests and estimated tokens sent to a provider
+// for one kind of request
+type ProviderUsage struct {
+	Provider string  `json:"provider"`
+	Kind     string  `json:"kind"`
+	Requests int     `json:"requests"`
+	Tokens   int     `json:"tokens"`
+	CostUSD  float64 `json:"cost_usd,omitempty"`
+}
+
+// BudgetStatus is the use of a provider's daily budget so far today
+type BudgetStatus struct {
+	Provider      string  `json:"provider"`
+	Requests      int     `json:"requests"`
+	Tokens        int     `json:"tokens"`
+	CostUSD       float64 `json:"cost_usd,omitempty"`
+	DailyRequests int     `json:"daily_requests,omitempty"`
+	DailyTokens   int     `json:"daily_tokens,omitempty"`
+	Exceeded      bool    `json:"exceeded"`
+}
+
+// EstimateTokens approximates the tokens of text, about four characters
+// each. Providers count differently; the estimate is the same for all so
+// they can be compared.
+func EstimateTokens(text string) int {
+	return (len(text) + 3) / 4
+}
+
+// Meter keeps the requests and tokens of every provider for the current day
+// and enforces the daily budgets. Counters start at zero when the server
+// starts and at midnight.
+type Meter struct {
+	mu      sync.Mutex
+	now     func() time.Time
+	day     string
+	usage   map[string]*ProviderUsage // "provider/kind"
+	budgets map[string]config.ProviderBudget
+}
+
+// NewMeter creates a meter enforcing budgets, keyed by provider name
+func NewMeter(budgets map[string]config.ProviderBudget) *Meter {
+	return &Meter{now: time.Now, usage: make(map[string]*ProviderUsage), budgets: budgets}
+}
+
+// defaultMeter is shared by the providers of NewProvider: budgets are per
+// process, whichever provider instance spends them
+var defaultMeter = NewMeter(nil)
+
+// DefaultMeter returns the meter of the providers created by NewProvider
+func DefaultMeter() *Meter {
+	return defaultMeter
+}
+
+// SetBudgets replaces the daily budgets
+func (m *Meter) SetBudgets(budgets map[string]config.ProviderBudget) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.budgets = budgets
+}
+
+// rollover resets the counters on a new day; m.mu must be held
+func (m *Meter) rollover() {
+	if day := m.now().Format(time.DateOnly); day != m.day {
+		m.day = day
+		m.usage = make(map[string]*ProviderUsage)
+	}
+}
+
+// totals sums the usage of provider over all kinds; m.mu must be held
+func (m *Meter) totals(provider string) (requests, tokens int, cost float64) {
+	for _, u := range m.usage {
+		if u.Provider == provider {
+			requests += u.Requests
+			tokens += u.Tokens
+			cost += u.CostUSD
+		}
+	}
+	return requests, tokens, cost
+}
+
+// Allow returns ErrBudgetExceeded when provider used up its daily budget
+func (m *Meter) Allow(provider string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.rollover()
+	budget, ok := m.budgets[provider]
+	if !ok {
+		return nil
+	}
+	requests, tokens, _ := m.totals(provider)
+	if (budget.DailyRequests > 0 && requests >= budget.DailyRequests) || (budget.DailyTokens > 0 && tokens >= budget.DailyTokens) {
+		return fmt.Errorf("%w: %s", ErrBudgetExceeded, provider)
+	}
+	return nil
+}
+
+// Record counts requests and tokens sent to provider, today and in the call
+// usage of ctx
+func (m *Meter) Record(ctx context.Context, provider, kind string, requests, tokens int) {
+	m.mu.Lock()
+	m.rollover()
+	cost := float64(tokens) * m.budgets[provider].USDPerMillionTokens / 1e6
+	key := provider + "/" + kind
+	u := m.usage[key]
+	if u == nil {
+		u = &ProviderUsage{Provider: provider, Kind: kind}
+		m.usage[key] = u
+	}
+	u.Requests += requests
+	u.Tokens += tokens
+	u.CostUSD += cost
+	m.mu.Unlock()
+
+	if calls, ok := ctx.Value(callUsageKey{}).(*CallUsage); ok {
+		calls.add(ProviderUsage{Provider: provider, Kind: kind, Requests: requests, Tokens: tokens, CostUSD: cost})
+	}
+}
+
+// Budgets reports today's use of every provider that was called or has a
+// budget, by provider name
+func (m *Meter) Budgets() []BudgetStatus {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.rollover()
+	providers := make(map[string]bool)
+	for _, u := range m.usage {
+		providers[u.Provider] = true
+	}
+	for name := range m.budgets {
+		providers[name] = true
+	}
+	statuses := make([]BudgetStatus, 0, len(providers))
+	for name := range providers {
+		s := BudgetStatus{Provider: name}
+		s.Requests, s.Tokens, s.CostUSD = m.totals(name)
+		budget := m.budgets[name]
+		s.DailyRequests, s.DailyTokens = budget.DailyRequests, budget.DailyTokens
+		s.Exceeded = (budget.DailyRequests > 0 && s.Requests >= budget.DailyRequests) || (budget.DailyTokens > 0 && s.Tokens >= budget.DailyTokens)
+		statuses = append(statuses, s)
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Provider < statuses[j].Provider })
+	return statuses
+}
+
+// CallUsage collects the provider requests made for one tool call or
+// indexing run
+type CallUsage struct {
+	mu    sync.Mutex
+	usage map[string]*ProviderUsage
+}
+
+type callUsageKey struct{}
+
+// WithCallUsage returns a context whose provider requests are collected in
+// the returned CallUsage
+func WithCallUsage(ctx context.Context) (context.Context, *CallUsage) {
+	calls := &CallUsage{usage: make(map[string]*ProviderUsage)}
+	return context.WithValue(ctx, callUsageKey{}, calls), calls
+}
+
+func (c *CallUsage) add(u ProviderUsage) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	key := u.Provider + "/" + u.Kind
+	sum := c.usage[key]
+	if sum == nil {
+		sum = &ProviderUsage{Provider: u.Provider, Kind: u.Kind}
+		c.usage[key] = sum
+	}
+	sum.Requests += u.Requests
+	sum.Tokens += u.Tokens
+	sum.CostUSD += u.CostUSD
+}
+
+// Totals returns the collected usage per provider and kind, in a stable
+// order. It is safe to call on a nil CallUsage.
+func (c *CallUsage) Totals() []ProviderUsage {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	totals := make([]ProviderUsage, 0, len(c.usage))
+	for _, u := range c.usage {
+		totals = append(totals, *u)
+	}
+	sort.Slice(totals, func(i, j int) bool {
+		if totals[i].Provider != totals[j].Provider {
+			return totals[i].Provider < totals[j].Provider
+		}
+		return totals[i].Kind < totals[j].Kind
+	})
+	return totals
+}
+
+// meteredProvider counts the requests and tokens of a provider and enforces
+// the daily budgets. When the hosted chat model is over budget, generation
+// degrades to the local provider.
+type meteredProvider struct {
+	Provider
+	meter     *Meter
+	chatName  string   // provider name that generates text
+	embedName string   // provider name that embeds
+	local     Provider // generates when chatName is over budget, nil without a chat provider
+	localName string
+	warned    sync.Map // day -> logged the degraded mode
+}
+
+// Generate generates text within the chat budget
+func (p *meteredProvider) Generate(ctx context.Context, prompt string, opts ...GenerateOption) (string, error) {
+	gen, name := p.Provider, p.chatName
+	if err := p.meter.Allow(name); err != nil {
+		if p.local == nil {
+			return "", err
+		}
+		if _, logged := p.warned.LoadOrStore(time.Now().Format(time.DateOnly), true); !logged {
+			log.Printf("⚠️  %v: generating with %s until tomorrow", err, p.local.Name())
+		}
+		gen, name = p.local, p.localName
+		if err := p.meter.Allow(name); err != nil {
+			return "", err
+		}
+	}
+	text, err := gen.Generate(ctx, prompt, opts...)
+	tokens := EstimateTokens(prompt)
+	if err == nil {
+		tokens += EstimateTokens(text)
+	} else {
+		tokens = 0
+	}
+	p.meter.Record(ctx, name, KindChat, 1, tokens)
+	return text, err
+}
+
+// Embed embeds text within the embedding budget
+func (p *meteredProvider) Embed(ctx context.Context, text string) ([]float64, error) {
+	if err := p.meter.Allow(p.embedName); err != nil {
+		return nil, err
+	}
+	v, err := p.Provider.Embed(ctx, text)
+	tokens := 0
+	if err == nil {
+		tokens = EstimateTokens(text)
+	}
+	p.meter.Record(ctx, p.embedName, KindEmbed, 1, tokens)
+	return v, err
+}
+
+// EmbedBatch embeds texts with one request within the embedding budget
+func (p *meteredProvider) EmbedBatch(ctx context.Context, texts []string) ([][]float64, error) {
+	if err := p.meter.Allow(p.embedName); err != nil {
+		return nil, err
+	}
+	requests := 1
+	if _, ok := p.Provider.(BatchEmbedder); !ok {
+		requests = len(texts)
+	}
+	vectors, err := EmbedBatch(ctx, p.Provider, texts)
+	tokens := 0
+	if err == nil {
+		for _, text := range texts {
+			tokens += EstimateTokens(text)
+		}
+	}
+	p.meter.Record(ctx, p.embedName, KindEmbed, requests, tokens)
+	return vectors, err
+}
+
+// EmbeddingDimension returns the vector size of the wrapped provider
+func (p *meteredProvider) EmbeddingDimension(ctx context.Context) (int, error) {
+	return EmbeddingDimension(ctx, p.Provider)
+}
+
+// Close implements io.Closer
+func (p *meteredProvider) Close() error {
+	if closer, ok := p.Provider.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+var _ Provider = (*meteredProvider)(nil)
+var _ BatchEmbedder = (*meteredProvider)(nil)
+var _ DimensionReporter = (*meteredProvider)(nil)
+var _ io.Closer = (*meteredProvider)(nil)
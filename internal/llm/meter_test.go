@@ -0,0 +1,73 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/doITmagic/rag-code-mcp/internal/config"
+)
+
+func TestMeterBudgets(t *testing.T) {
+	day := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	meter := NewMeter(map[string]config.ProviderBudget{
+		"tei": {DailyRequests: 2, USDPerMillionTokens: 100},
+	})
+	meter.now = func() time.Time { return day }
+	p := &meteredProvider{Provider: &fakeProvider{name: "ollama", embedResult: []float64{1, 2}}, meter: meter, chatName: "ollama", embedName: "tei"}
+
+	ctx, calls := WithCallUsage(context.Background())
+	if _, err := p.Embed(ctx, "12345678"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := p.EmbedBatch(ctx, []string{"abcd", "efgh"}); err != nil {
+		t.Fatal(err)
+	}
+	// The fake has no batch endpoint: the batch costs one request per text
+	if _, err := p.Embed(ctx, "x"); !errors.Is(err, ErrBudgetExceeded) {
+		t.Errorf("err = %v, want the budget exceeded", err)
+	}
+
+	totals := calls.Totals()
+	if len(totals) != 1 || totals[0].Requests != 3 || totals[0].Tokens != 4 || totals[0].CostUSD != 0.0004 {
+		t.Errorf("call usage = %+v", totals)
+	}
+	budgets := meter.Budgets()
+	if len(budgets) != 1 || !budgets[0].Exceeded || budgets[0].DailyRequests != 2 {
+		t.Errorf("budgets = %+v", budgets)
+	}
+
+	// Providers without a budget are counted, never refused
+	if _, err := p.Generate(ctx, "hello"); err != nil {
+		t.Error(err)
+	}
+
+	// A new day starts from zero
+	day = day.AddDate(0, 0, 1)
+	if _, err := p.Embed(ctx, "x"); err != nil {
+		t.Errorf("budget not reset the next day: %v", err)
+	}
+}
+
+func TestMeterChatFallsBackToLocal(t *testing.T) {
+	meter := NewMeter(map[string]config.ProviderBudget{"anthropic": {DailyTokens: 5}})
+	hosted := &fakeProvider{name: "anthropic", generateResult: "anthropic reply"}
+	local := &fakeProvider{name: "ollama", generateResult: "ollama reply"}
+	p := &meteredProvider{Provider: hosted, meter: meter, chatName: "anthropic", embedName: "ollama", local: local, localName: "ollama"}
+
+	ctx := context.Background()
+	if text, _ := p.Generate(ctx, "summarize this function"); text != "anthropic reply" {
+		t.Errorf("first reply = %q, want the hosted model", text)
+	}
+	text, err := p.Generate(ctx, "summarize this function")
+	if err != nil || text != "ollama reply" {
+		t.Errorf("reply over budget = %q, %v; want the local provider", text, err)
+	}
+
+	// Without a local fallback the budget error is returned
+	p.local = nil
+	if _, err := p.Generate(ctx, "x"); !errors.Is(err, ErrBudgetExceeded) {
+		t.Errorf("err = %v", err)
+	}
+}
@@ -145,6 +145,37 @@ func (p *OllamaLLMProvider) Embed(ctx context.Context, text string) ([]float64,
 	return result, nil
 }
 
+// EmbedBatch generates the embeddings of several texts with one request to
+// the Ollama embedding model
+func (p *OllamaLLMProvider) EmbedBatch(ctx context.Context, texts []string) ([][]float64, error) {
+	embedder, ok := p.embedModel.(interface {
+		CreateEmbedding(ctx context.Context, texts []string) ([][]float32, error)
+	})
+	if !ok {
+		return nil, fmt.Errorf("Ollama model does not support embeddings")
+	}
+
+	embeddings, err := embedder.CreateEmbedding(ctx, texts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create embeddings: %w", err)
+	}
+	if len(embeddings) != len(texts) {
+		return nil, fmt.Errorf("got %d embeddings for %d texts", len(embeddings), len(texts))
+	}
+
+	result := make([][]float64, len(embeddings))
+	for i, emb := range embeddings {
+		if len(emb) == 0 {
+			return nil, fmt.Errorf("empty embedding returned")
+		}
+		result[i] = make([]float64, len(emb))
+		for j, v := range emb {
+			result[i][j] = float64(v)
+		}
+	}
+	return result, nil
+}
+
 // Name returns the provider name
 func (p *OllamaLLMProvider) Name() string {
 	return "ollama"
@@ -0,0 +1,196 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+
+	"github.com/doITmagic/rag-code-mcp/internal/config"
+	"github.com/tmc/langchaingo/llms"
+	"github.com/tmc/langchaingo/llms/openai"
+)
+
+// OpenAIProvider implements Provider for OpenAI-compatible APIs: OpenAI,
+// Azure OpenAI and local servers exposing /v1/embeddings and
+// /v1/chat/completions (vLLM, LM Studio, llama.cpp server)
+type OpenAIProvider struct {
+	client    *openai.LLM
+	chatName  string
+	embedName string
+	config    config.LLMConfig
+
+	dimMu sync.Mutex
+	dim   int
+}
+
+// NewOpenAIProvider creates a new provider for an OpenAI-compatible API
+func NewOpenAIProvider(cfg config.LLMConfig) (*OpenAIProvider, error) {
+	if cfg.OpenAIEmbed == "" {
+		return nil, fmt.Errorf("openai embedding model is required (set openai_embed)")
+	}
+
+	// Local servers accept any key, the client refuses an empty one
+	apiKey := cfg.OpenAIAPIKey
+	if apiKey == "" {
+		apiKey = "none"
+	}
+	opts := []openai.Option{
+		openai.WithToken(apiKey),
+		openai.WithEmbeddingModel(cfg.OpenAIEmbed),
+	}
+	if cfg.OpenAIBaseURL != "" {
+		opts = append(opts, openai.WithBaseURL(cfg.OpenAIBaseURL))
+	}
+	if cfg.OpenAIModel != "" {
+		opts = append(opts, openai.WithModel(cfg.OpenAIModel))
+	}
+	if cfg.OpenAIEmbedDimensions > 0 {
+		opts = append(opts, openai.WithEmbeddingDimensions(cfg.OpenAIEmbedDimensions))
+	}
+	if cfg.OpenAIAPIType == "azure" {
+		opts = append(opts, openai.WithAPIType(openai.APITypeAzure))
+		if cfg.OpenAIAPIVersion != "" {
+			opts = append(opts, openai.WithAPIVersion(cfg.OpenAIAPIVersion))
+		}
+	}
+
+	client, err := openai.New(opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OpenAI client: %w", err)
+	}
+	log.Printf("🎯 OpenAI-compatible API: chat=%s, embed=%s", cfg.OpenAIModel, cfg.OpenAIEmbed)
+
+	return &OpenAIProvider{
+		client:    client,
+		chatName:  cfg.OpenAIModel,
+		embedName: cfg.OpenAIEmbed,
+		config:    cfg,
+	}, nil
+}
+
+// Generate generates text using the chat model
+func (p *OpenAIProvider) Generate(ctx context.Context, prompt string, opts ...GenerateOption) (string, error) {
+	if p.chatName == "" {
+		return "", fmt.Errorf("openai chat model is not configured (set openai_model)")
+	}
+	return llms.GenerateFromSinglePrompt(ctx, p.client, prompt, p.convertOptions(opts...)...)
+}
+
+// GenerateStream generates streaming text using the chat model
+func (p *OpenAIProvider) GenerateStream(ctx context.Context, prompt string, opts ...GenerateOption) (<-chan string, <-chan error) {
+	textChan := make(chan string)
+	errChan := make(chan error, 1)
+
+	go func() {
+		defer close(textChan)
+		defer close(errChan)
+
+		if p.chatName == "" {
+			errChan <- fmt.Errorf("openai chat model is not configured (set openai_model)")
+			return
+		}
+		streamFunc := func(ctx context.Context, chunk []byte) error {
+			select {
+			case textChan <- string(chunk):
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+
+		lcOpts := append(p.convertOptions(opts...), llms.WithStreamingFunc(streamFunc))
+		if _, err := llms.GenerateFromSinglePrompt(ctx, p.client, prompt, lcOpts...); err != nil {
+			errChan <- err
+		}
+	}()
+
+	return textChan, errChan
+}
+
+// Embed generates the embedding of a text
+func (p *OpenAIProvider) Embed(ctx context.Context, text string) ([]float64, error) {
+	vectors, err := p.EmbedBatch(ctx, []string{text})
+	if err != nil {
+		return nil, err
+	}
+	return vectors[0], nil
+}
+
+// EmbedBatch generates the embeddings of several texts with one request
+func (p *OpenAIProvider) EmbedBatch(ctx context.Context, texts []string) ([][]float64, error) {
+	embeddings, err := p.client.CreateEmbedding(ctx, texts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create embeddings: %w", err)
+	}
+	if len(embeddings) != len(texts) {
+		return nil, fmt.Errorf("got %d embeddings for %d texts", len(embeddings), len(texts))
+	}
+
+	result := make([][]float64, len(embeddings))
+	for i, emb := range embeddings {
+		if len(emb) == 0 {
+			return nil, fmt.Errorf("empty embedding returned")
+		}
+		result[i] = make([]float64, len(emb))
+		for j, v := range emb {
+			result[i][j] = float64(v)
+		}
+	}
+	p.dimMu.Lock()
+	p.dim = len(result[0])
+	p.dimMu.Unlock()
+	return result, nil
+}
+
+// EmbeddingDimension returns the vector size of the embedding model, known
+// from the last embedding or probed with one request
+func (p *OpenAIProvider) EmbeddingDimension(ctx context.Context) (int, error) {
+	p.dimMu.Lock()
+	dim := p.dim
+	p.dimMu.Unlock()
+	if dim > 0 {
+		return dim, nil
+	}
+	v, err := p.Embed(ctx, "dimension probe")
+	if err != nil {
+		return 0, err
+	}
+	return len(v), nil
+}
+
+// Name returns the provider name
+func (p *OpenAIProvider) Name() string {
+	return "openai"
+}
+
+// convertOptions converts GenerateOption to langchaingo CallOption
+func (p *OpenAIProvider) convertOptions(opts ...GenerateOption) []llms.CallOption {
+	genOpts := &GenerateOptions{}
+	for _, opt := range opts {
+		opt(genOpts)
+	}
+
+	var lcOpts []llms.CallOption
+	if genOpts.Temperature != 0 {
+		lcOpts = append(lcOpts, llms.WithTemperature(genOpts.Temperature))
+	} else if p.config.Temperature != 0 {
+		lcOpts = append(lcOpts, llms.WithTemperature(p.config.Temperature))
+	}
+	if genOpts.MaxTokens != 0 {
+		lcOpts = append(lcOpts, llms.WithMaxTokens(genOpts.MaxTokens))
+	} else if p.config.MaxTokens != 0 {
+		lcOpts = append(lcOpts, llms.WithMaxTokens(p.config.MaxTokens))
+	}
+	if genOpts.TopP != 0 {
+		lcOpts = append(lcOpts, llms.WithTopP(genOpts.TopP))
+	}
+	if len(genOpts.StopSequences) > 0 {
+		lcOpts = append(lcOpts, llms.WithStopWords(genOpts.StopSequences))
+	}
+	return lcOpts
+}
+
+var _ Provider = (*OpenAIProvider)(nil)
+var _ BatchEmbedder = (*OpenAIProvider)(nil)
+var _ DimensionReporter = (*OpenAIProvider)(nil)
@@ -0,0 +1,82 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/doITmagic/rag-code-mcp/internal/config"
+)
+
+func TestOpenAIProvider_Embed(t *testing.T) {
+	requests := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Path != "/v1/embeddings" {
+			t.Errorf("request to %s, want /v1/embeddings", r.URL.Path)
+		}
+		var req struct {
+			Model string   `json:"model"`
+			Input []string `json:"input"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatal(err)
+		}
+		if req.Model != "bge-small" {
+			t.Errorf("model = %q, want bge-small", req.Model)
+		}
+		type item struct {
+			Index     int       `json:"index"`
+			Embedding []float32 `json:"embedding"`
+		}
+		resp := struct {
+			Data []item `json:"data"`
+		}{}
+		for i := range req.Input {
+			resp.Data = append(resp.Data, item{Index: i, Embedding: []float32{float32(i), 1, 2}})
+		}
+		json.NewEncoder(w).Encode(resp)
+	}))
+	defer srv.Close()
+
+	p, err := NewProvider(&config.LLMConfig{Provider: "openai", OpenAIBaseURL: srv.URL + "/v1", OpenAIEmbed: "bge-small"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Name() != "openai" {
+		t.Errorf("name = %q", p.Name())
+	}
+
+	vectors, err := EmbedBatch(context.Background(), p, []string{"a", "b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(vectors) != 2 || vectors[1][0] != 1 || len(vectors[1]) != 3 {
+		t.Errorf("vectors = %v", vectors)
+	}
+	if requests != 1 {
+		t.Errorf("%d requests for a batch, want 1", requests)
+	}
+
+	// The dimension is known from the last embedding
+	dim, err := EmbeddingDimension(context.Background(), p)
+	if err != nil || dim != 3 {
+		t.Errorf("dimension = %d, %v; want 3", dim, err)
+	}
+	if requests != 1 {
+		t.Errorf("dimension probed again: %d requests", requests)
+	}
+
+	// Generating needs a chat model
+	if _, err := p.Generate(context.Background(), "hi"); err == nil {
+		t.Error("Generate without openai_model should fail")
+	}
+}
+
+func TestNewProvider_OpenAIMissingEmbed(t *testing.T) {
+	if p, err := NewProvider(&config.LLMConfig{Provider: "openai"}); err == nil || p != nil {
+		t.Errorf("expected an error without openai_embed, got %v, %v", p, err)
+	}
+}
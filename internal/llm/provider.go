@@ -25,6 +25,60 @@ type Provider interface {
 	Name() string
 }
 
+// BatchEmbedder is implemented by providers that embed several texts with
+// one request
+type BatchEmbedder interface {
+	// EmbedBatch returns one embedding per text, in order
+	EmbedBatch(ctx context.Context, texts []string) ([][]float64, error)
+}
+
+// EmbedBatch embeds texts with a single request when the provider supports
+// it, else one text at a time
+func EmbedBatch(ctx context.Context, p Provider, texts []string) ([][]float64, error) {
+	if b, ok := p.(BatchEmbedder); ok {
+		vectors, err := b.EmbedBatch(ctx, texts)
+		if err != nil {
+			return nil, err
+		}
+		if len(vectors) != len(texts) {
+			return nil, fmt.Errorf("got %d embeddings for %d texts", len(vectors), len(texts))
+		}
+		return vectors, nil
+	}
+	vectors := make([][]float64, 0, len(texts))
+	for _, text := range texts {
+		v, err := p.Embed(ctx, text)
+		if err != nil {
+			return nil, err
+		}
+		vectors = append(vectors, v)
+	}
+	return vectors, nil
+}
+
+// DimensionReporter is implemented by providers that know the vector size of
+// their embedding model without a new request
+type DimensionReporter interface {
+	EmbeddingDimension(ctx context.Context) (int, error)
+}
+
+// EmbeddingDimension returns the vector size of the provider's embeddings,
+// probing with one embedding when the provider cannot report it. Collections
+// are created with this size.
+func EmbeddingDimension(ctx context.Context, p Provider) (int, error) {
+	if r, ok := p.(DimensionReporter); ok {
+		return r.EmbeddingDimension(ctx)
+	}
+	v, err := p.Embed(ctx, "test")
+	if err != nil {
+		return 0, err
+	}
+	if len(v) == 0 {
+		return 0, fmt.Errorf("empty embedding returned")
+	}
+	return len(v), nil
+}
+
 // GenerateOptions contains options for text generation
 type GenerateOptions struct {
 	Temperature   float64
@@ -60,9 +114,54 @@ func WithStopWords(words []string) GenerateOption {
 	}
 }
 
-// NewProvider creates a new LLM provider based on configuration
+// NewProvider creates a new LLM provider based on configuration. With
+// chat_provider "anthropic" or "gemini", text is generated by that hosted
+// model instead; with embed_provider "command" or "tei", embeddings come from
+// llm.embed_command or a TEI server instead. Every request is counted by
+// DefaultMeter, within the daily budgets of llm.budgets.
 func NewProvider(cfg *config.LLMConfig) (Provider, error) {
-	// MCP RagCode currently uses only Ollama as LLM provider
+	base, err := newProvider(cfg)
+	if err != nil {
+		return nil, err
+	}
+	baseName := cfg.Provider
+	if baseName == "" {
+		baseName = "ollama"
+	}
+	metered := &meteredProvider{meter: defaultMeter, chatName: baseName, embedName: baseName}
+	defaultMeter.SetBudgets(cfg.Budgets)
+
+	p := base
+	if cfg.ChatProvider != "" {
+		chat, err := NewChatter(*cfg)
+		if err != nil {
+			return nil, err
+		}
+		p = &chatProvider{Provider: p, chat: chat, name: cfg.ChatProvider}
+		metered.chatName, metered.local, metered.localName = cfg.ChatProvider, base, baseName
+	}
+	var embedder Embedder
+	switch cfg.EmbedProvider {
+	case "":
+	case "command":
+		embedder, err = NewCommandEmbedder(cfg.EmbedCommand)
+	case "tei":
+		embedder, err = NewTEIEmbedder(*cfg)
+	default:
+		err = fmt.Errorf("unknown embed provider: %s (supported: command, tei)", cfg.EmbedProvider)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if embedder != nil {
+		p = &embedProvider{Provider: p, embedder: embedder, name: cfg.EmbedProvider}
+		metered.embedName = cfg.EmbedProvider
+	}
+	metered.Provider = p
+	return metered, nil
+}
+
+func newProvider(cfg *config.LLMConfig) (Provider, error) {
 	switch cfg.Provider {
 	case "", "ollama":
 		p, err := NewOllamaLLMProvider(*cfg)
@@ -71,8 +170,14 @@ func NewProvider(cfg *config.LLMConfig) (Provider, error) {
 			return nil, err
 		}
 		return p, nil
+	case "openai":
+		p, err := NewOpenAIProvider(*cfg)
+		if err != nil {
+			return nil, err
+		}
+		return p, nil
 	default:
-		return nil, fmt.Errorf("unknown provider: %s (supported: ollama)", cfg.Provider)
+		return nil, fmt.Errorf("unknown provider: %s (supported: ollama, openai)", cfg.Provider)
 	}
 }
 
@@ -125,12 +230,43 @@ func (r *RetryableProvider) Embed(ctx context.Context, text string) ([]float64,
 	return result, err
 }
 
+// EmbedBatch generates the embeddings of several texts with retry logic
+func (r *RetryableProvider) EmbedBatch(ctx context.Context, texts []string) ([][]float64, error) {
+	var result [][]float64
+	err := utils.Retry(r.maxRetries, time.Second, func() error {
+		timeoutCtx, cancel := context.WithTimeout(ctx, r.timeout)
+		defer cancel()
+
+		var err error
+		result, err = EmbedBatch(timeoutCtx, r.provider, texts)
+		return err
+	})
+	return result, err
+}
+
+// EmbeddingDimension returns the vector size of the wrapped provider's
+// embeddings
+func (r *RetryableProvider) EmbeddingDimension(ctx context.Context) (int, error) {
+	var dim int
+	err := utils.Retry(r.maxRetries, time.Second, func() error {
+		timeoutCtx, cancel := context.WithTimeout(ctx, r.timeout)
+		defer cancel()
+
+		var err error
+		dim, err = EmbeddingDimension(timeoutCtx, r.provider)
+		return err
+	})
+	return dim, err
+}
+
 // Name returns the provider name
 func (r *RetryableProvider) Name() string {
 	return r.provider.Name()
 }
 
 var _ Provider = (*RetryableProvider)(nil)
+var _ BatchEmbedder = (*RetryableProvider)(nil)
+var _ DimensionReporter = (*RetryableProvider)(nil)
 var _ io.Closer = (*RetryableProvider)(nil)
 
 // Close implements io.Closer
@@ -140,3 +276,70 @@ func (r *RetryableProvider) Close() error {
 	}
 	return nil
 }
+
+// embedProvider generates text with a provider and embeds with a separate
+// embedder (llm.embed_provider: command or tei)
+type embedProvider struct {
+	Provider
+	embedder Embedder
+	name     string
+}
+
+// Embedder embeds text outside the generation provider
+type Embedder interface {
+	Embed(ctx context.Context, text string) ([]float64, error)
+}
+
+// Embed embeds text with the embedder
+func (p *embedProvider) Embed(ctx context.Context, text string) ([]float64, error) {
+	return p.embedder.Embed(ctx, text)
+}
+
+// EmbedBatch embeds texts with the embedder, batched when it supports it
+func (p *embedProvider) EmbedBatch(ctx context.Context, texts []string) ([][]float64, error) {
+	if b, ok := p.embedder.(BatchEmbedder); ok {
+		return b.EmbedBatch(ctx, texts)
+	}
+	vectors := make([][]float64, 0, len(texts))
+	for _, text := range texts {
+		v, err := p.embedder.Embed(ctx, text)
+		if err != nil {
+			return nil, err
+		}
+		vectors = append(vectors, v)
+	}
+	return vectors, nil
+}
+
+// EmbeddingDimension returns the vector size of the embedder
+func (p *embedProvider) EmbeddingDimension(ctx context.Context) (int, error) {
+	if r, ok := p.embedder.(DimensionReporter); ok {
+		return r.EmbeddingDimension(ctx)
+	}
+	v, err := p.embedder.Embed(ctx, "dimension probe")
+	if err != nil {
+		return 0, err
+	}
+	if len(v) == 0 {
+		return 0, fmt.Errorf("empty embedding returned")
+	}
+	return len(v), nil
+}
+
+// Name returns the provider name
+func (p *embedProvider) Name() string {
+	return p.Provider.Name() + "+" + p.name
+}
+
+// Close implements io.Closer
+func (p *embedProvider) Close() error {
+	if closer, ok := p.Provider.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+var _ Provider = (*embedProvider)(nil)
+var _ BatchEmbedder = (*embedProvider)(nil)
+var _ DimensionReporter = (*embedProvider)(nil)
+var _ io.Closer = (*embedProvider)(nil)
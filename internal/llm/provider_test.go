@@ -232,3 +232,20 @@ func TestRetryableProvider_CloseNoCloser(t *testing.T) {
 		t.Fatalf("expected nil error from Close, got %v", err)
 	}
 }
+
+func TestEmbedBatch_FallsBackToEmbed(t *testing.T) {
+	base := &fakeProvider{embedResult: []float64{1, 2}}
+
+	vectors, err := EmbedBatch(context.Background(), base, []string{"a", "b", "c"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(vectors) != 3 || base.embedCalls != 3 {
+		t.Errorf("got %d vectors with %d Embed calls, want 3 and 3", len(vectors), base.embedCalls)
+	}
+
+	r := NewRetryableProvider(base, 1, time.Second)
+	if vectors, err := r.EmbedBatch(context.Background(), []string{"a"}); err != nil || len(vectors) != 1 {
+		t.Errorf("retryable EmbedBatch = %v, %v", vectors, err)
+	}
+}
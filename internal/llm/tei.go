@@ -0,0 +1,178 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/doITmagic/rag-code-mcp/internal/config"
+)
+
+// defaultTEIBatchSize is the number of texts sent per request when neither
+// llm.tei_batch_size nor the server's /info sets it
+const defaultTEIBatchSize = 32
+
+// TEIEmbedder embeds with a Hugging Face text-embeddings-inference server
+// (POST /embed) or, when llm.tei_base_url ends in /embeddings, with any
+// OpenAI-style embeddings endpoint. Texts are sent in batches.
+type TEIEmbedder struct {
+	baseURL string
+	apiKey  string
+	model   string
+	client  *http.Client
+
+	mu        sync.Mutex
+	batchSize int  // texts per request, 0 until resolved
+	infoRead  bool // /info was queried for the batch limit
+	dim       int
+}
+
+// NewTEIEmbedder creates an embedder for the server of llm.tei_base_url
+func NewTEIEmbedder(cfg config.LLMConfig) (*TEIEmbedder, error) {
+	baseURL := strings.TrimRight(strings.TrimSpace(cfg.TEIBaseURL), "/")
+	if baseURL == "" {
+		return nil, fmt.Errorf("tei base URL is required (set tei_base_url)")
+	}
+	if cfg.TEIBatchSize < 0 {
+		return nil, fmt.Errorf("tei batch size must not be negative")
+	}
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = 60 * time.Second
+	}
+	return &TEIEmbedder{
+		baseURL:   baseURL,
+		apiKey:    cfg.TEIAPIKey,
+		model:     cfg.TEIModel,
+		client:    &http.Client{Timeout: timeout},
+		batchSize: cfg.TEIBatchSize,
+	}, nil
+}
+
+// openAIStyle reports whether the URL is an OpenAI-style /embeddings endpoint
+// rather than a TEI server
+func (e *TEIEmbedder) openAIStyle() bool {
+	return strings.HasSuffix(e.baseURL, "/embeddings")
+}
+
+// Embed embeds one text
+func (e *TEIEmbedder) Embed(ctx context.Context, text string) ([]float64, error) {
+	vectors, err := e.embed(ctx, []string{text})
+	if err != nil {
+		return nil, err
+	}
+	return vectors[0], nil
+}
+
+// EmbedBatch embeds texts in requests of at most the batch size
+func (e *TEIEmbedder) EmbedBatch(ctx context.Context, texts []string) ([][]float64, error) {
+	size := e.resolveBatchSize(ctx)
+	vectors := make([][]float64, 0, len(texts))
+	for start := 0; start < len(texts); start += size {
+		end := start + size
+		if end > len(texts) {
+			end = len(texts)
+		}
+		batch, err := e.embed(ctx, texts[start:end])
+		if err != nil {
+			return nil, err
+		}
+		vectors = append(vectors, batch...)
+	}
+	return vectors, nil
+}
+
+// EmbeddingDimension returns the vector size of the server's model, known
+// from the last embedding or probed with one request
+func (e *TEIEmbedder) EmbeddingDimension(ctx context.Context) (int, error) {
+	e.mu.Lock()
+	dim := e.dim
+	e.mu.Unlock()
+	if dim > 0 {
+		return dim, nil
+	}
+	v, err := e.Embed(ctx, "dimension probe")
+	if err != nil {
+		return 0, err
+	}
+	return len(v), nil
+}
+
+// resolveBatchSize returns the configured batch size or, once, reads the
+// server's max_client_batch_size from /info
+func (e *TEIEmbedder) resolveBatchSize(ctx context.Context) int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.batchSize > 0 {
+		return e.batchSize
+	}
+	if !e.infoRead && !e.openAIStyle() {
+		e.infoRead = true
+		var info struct {
+			MaxClientBatchSize int `json:"max_client_batch_size"`
+		}
+		if err := e.do(ctx, http.MethodGet, e.baseURL+"/info", nil, &info); err == nil && info.MaxClientBatchSize > 0 {
+			e.batchSize = info.MaxClientBatchSize
+			return e.batchSize
+		}
+	}
+	return defaultTEIBatchSize
+}
+
+// embed sends one request for texts
+func (e *TEIEmbedder) embed(ctx context.Context, texts []string) ([][]float64, error) {
+	var vectors [][]float64
+	if e.openAIStyle() {
+		body := map[string]interface{}{"input": texts}
+		if e.model != "" {
+			body["model"] = e.model
+		}
+		var resp struct {
+			Data []struct {
+				Index     int       `json:"index"`
+				Embedding []float64 `json:"embedding"`
+			} `json:"data"`
+		}
+		if err := e.do(ctx, http.MethodPost, e.baseURL, body, &resp); err != nil {
+			return nil, err
+		}
+		sort.SliceStable(resp.Data, func(i, j int) bool { return resp.Data[i].Index < resp.Data[j].Index })
+		for _, d := range resp.Data {
+			vectors = append(vectors, d.Embedding)
+		}
+	} else {
+		body := map[string]interface{}{"inputs": texts, "truncate": true}
+		if err := e.do(ctx, http.MethodPost, e.baseURL+"/embed", body, &vectors); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(vectors) != len(texts) {
+		return nil, fmt.Errorf("tei returned %d embeddings for %d texts", len(vectors), len(texts))
+	}
+	for _, v := range vectors {
+		if len(v) == 0 {
+			return nil, fmt.Errorf("empty embedding returned")
+		}
+	}
+	e.mu.Lock()
+	e.dim = len(vectors[0])
+	e.mu.Unlock()
+	return vectors, nil
+}
+
+// do sends a JSON request to the server
+func (e *TEIEmbedder) do(ctx context.Context, method, url string, body, out interface{}) error {
+	header := http.Header{}
+	if e.apiKey != "" {
+		header.Set("Authorization", "Bearer "+e.apiKey)
+	}
+	return doJSON(ctx, e.client, "tei", method, url, header, body, out)
+}
+
+var _ BatchEmbedder = (*TEIEmbedder)(nil)
+var _ DimensionReporter = (*TEIEmbedder)(nil)
@@ -0,0 +1,115 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/doITmagic/rag-code-mcp/internal/config"
+)
+
+func TestTEIEmbedder(t *testing.T) {
+	var batches []int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/info":
+			json.NewEncoder(w).Encode(map[string]interface{}{"model_id": "bge", "max_client_batch_size": 2})
+		case "/embed":
+			if r.Header.Get("Authorization") != "Bearer secret" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			var req struct {
+				Inputs []string `json:"inputs"`
+			}
+			json.NewDecoder(r.Body).Decode(&req)
+			batches = append(batches, len(req.Inputs))
+			out := make([][]float64, len(req.Inputs))
+			for i, in := range req.Inputs {
+				out[i] = []float64{float64(len(in)), 0, 1}
+			}
+			json.NewEncoder(w).Encode(out)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	e, err := NewTEIEmbedder(config.LLMConfig{TEIBaseURL: server.URL + "/", TEIAPIKey: "secret"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	vectors, err := e.EmbedBatch(ctx, []string{"a", "bb", "ccc", "dddd", "eeeee"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(vectors) != 5 || vectors[4][0] != 5 {
+		t.Errorf("vectors = %v, want one per text in order", vectors)
+	}
+	if len(batches) != 3 || batches[0] != 2 || batches[2] != 1 {
+		t.Errorf("batches = %v, want the server's max_client_batch_size of 2", batches)
+	}
+
+	// The dimension is known from the last embedding
+	requests := len(batches)
+	if dim, err := e.EmbeddingDimension(ctx); err != nil || dim != 3 {
+		t.Errorf("dimension = %d, %v; want 3", dim, err)
+	}
+	if len(batches) != requests {
+		t.Error("dimension probed again")
+	}
+
+	e, _ = NewTEIEmbedder(config.LLMConfig{TEIBaseURL: server.URL})
+	if _, err := e.Embed(ctx, "x"); err == nil {
+		t.Error("expected the server's 401 as an error")
+	}
+	if _, err := NewTEIEmbedder(config.LLMConfig{}); err == nil {
+		t.Error("empty base URL should be rejected")
+	}
+}
+
+func TestTEIEmbedder_OpenAIStyle(t *testing.T) {
+	var model string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/embeddings" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		var req struct {
+			Input []string `json:"input"`
+			Model string   `json:"model"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		model = req.Model
+		// Out of order: the embedder sorts by index
+		w.Write([]byte(`{"data": [{"index": 1, "embedding": [2, 2]}, {"index": 0, "embedding": [1, 1]}]}`))
+	}))
+	defer server.Close()
+
+	p, err := NewProvider(&config.LLMConfig{
+		Provider:      "openai",
+		OpenAIEmbed:   "unused",
+		EmbedProvider: "tei",
+		TEIBaseURL:    server.URL + "/v1/embeddings",
+		TEIModel:      "bge-small",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	vectors, err := EmbedBatch(context.Background(), p, []string{"a", "b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if vectors[0][0] != 1 || vectors[1][0] != 2 {
+		t.Errorf("vectors = %v, want them ordered by index", vectors)
+	}
+	if model != "bge-small" {
+		t.Errorf("model = %q, want tei_model", model)
+	}
+	if p.Name() != "openai+tei" {
+		t.Errorf("Name = %q", p.Name())
+	}
+}
@@ -3,6 +3,8 @@ package memory
 import (
 	"context"
 	"fmt"
+	"math"
+	"sort"
 )
 
 // Document represents a document stored in long-term memory
@@ -21,6 +23,10 @@ type LongTermMemory interface {
 	// Search searches for similar documents
 	Search(ctx context.Context, query []float64, limit int) ([]Document, error)
 
+	// Query searches the documents opts.Filter selects: by similarity to
+	// opts.Vector when it is set, by exact metadata match otherwise.
+	Query(ctx context.Context, opts SearchOptions) ([]Document, error)
+
 	// Delete deletes a document by ID
 	Delete(ctx context.Context, id string) error
 
@@ -29,6 +35,89 @@ type LongTermMemory interface {
 
 	// Clear clears all documents
 	Clear(ctx context.Context) error
+
+	// Count returns how many documents match filter
+	Count(ctx context.Context, filter Filter) (uint64, error)
+
+	// Scroll lists the documents matching filter, up to limit per call, in a
+	// stable order. The first call passes an empty cursor, the next ones the
+	// cursor returned by the previous call; it is empty after the last page.
+	Scroll(ctx context.Context, filter Filter, cursor string, limit int) ([]Document, string, error)
+
+	// Aggregate counts the documents matching filter per value of the
+	// metadata key groupBy. Documents without the key are not counted.
+	Aggregate(ctx context.Context, filter Filter, groupBy string) (map[string]uint64, error)
+}
+
+// Filter selects documents by exact metadata values: each Must key has to
+// equal one of its values and no MustNot key may equal any of its values.
+// The zero Filter selects every document.
+type Filter struct {
+	Must    map[string][]string
+	MustNot map[string][]string
+}
+
+// Match reports whether the document whose metadata values lookup returns
+// passes the filter. Values are compared as strings.
+func (f Filter) Match(lookup func(key string) (string, bool)) bool {
+	for key, values := range f.Must {
+		v, ok := lookup(key)
+		if !ok || !containsString(values, v) {
+			return false
+		}
+	}
+	for key, values := range f.MustNot {
+		if v, ok := lookup(key); ok && containsString(values, v) {
+			return false
+		}
+	}
+	return true
+}
+
+// CodeOnly is the filter leaving out markdown documentation chunks
+func CodeOnly() Filter {
+	return Filter{MustNot: map[string][]string{"chunk_type": {"markdown"}}}
+}
+
+// DocsOnly is the filter keeping only markdown documentation chunks
+func DocsOnly() Filter {
+	return Filter{Must: map[string][]string{"chunk_type": {"markdown"}}}
+}
+
+// DefaultSearchLimit is the number of documents a query returns when
+// SearchOptions.Limit is not set
+const DefaultSearchLimit = 10
+
+// SearchOptions describes a query pushed down to the backend. With a Vector
+// it returns the Limit documents most similar to it among those Filter
+// selects, best first. Without one it returns up to Limit documents Filter
+// selects, in a stable order, with a score of 1.
+type SearchOptions struct {
+	Vector []float64
+	Filter Filter
+	Limit  int
+}
+
+// Similarity reports whether the query ranks documents by vector similarity
+func (o SearchOptions) Similarity() bool {
+	return len(o.Vector) > 0
+}
+
+// LimitOrDefault returns Limit, DefaultSearchLimit when it is not set
+func (o SearchOptions) LimitOrDefault() int {
+	if o.Limit <= 0 {
+		return DefaultSearchLimit
+	}
+	return o.Limit
+}
+
+func containsString(values []string, v string) bool {
+	for _, s := range values {
+		if s == v {
+			return true
+		}
+	}
+	return false
 }
 
 // InMemoryLongTermMemory is a simple in-memory implementation for testing
@@ -52,6 +141,12 @@ func (m *InMemoryLongTermMemory) Store(ctx context.Context, doc Document) error
 	return nil
 }
 
+// GetByID returns the document stored under id
+func (m *InMemoryLongTermMemory) GetByID(ctx context.Context, id string) (Document, bool, error) {
+	doc, ok := m.documents[id]
+	return doc, ok, nil
+}
+
 // Search searches for similar documents (simplified cosine similarity)
 func (m *InMemoryLongTermMemory) Search(ctx context.Context, query []float64, limit int) ([]Document, error) {
 	// TODO: Implement proper similarity search
@@ -66,6 +161,53 @@ func (m *InMemoryLongTermMemory) Search(ctx context.Context, query []float64, li
 	return results, nil
 }
 
+// Query filters the documents and ranks them by cosine similarity to the
+// query vector, by ID without one
+func (m *InMemoryLongTermMemory) Query(ctx context.Context, opts SearchOptions) ([]Document, error) {
+	var results []Document
+	scores := make(map[string]float64)
+	for _, doc := range m.documents {
+		if !opts.Filter.Match(metadataLookup(doc)) {
+			continue
+		}
+		score := 1.0
+		if opts.Similarity() {
+			score = cosine(opts.Vector, doc.Embedding)
+		}
+		scores[doc.ID] = score
+		results = append(results, doc)
+	}
+	sort.Slice(results, func(i, j int) bool {
+		a, b := results[i].ID, results[j].ID
+		if scores[a] != scores[b] {
+			return scores[a] > scores[b]
+		}
+		return a < b
+	})
+	if limit := opts.LimitOrDefault(); len(results) > limit {
+		results = results[:limit]
+	}
+	return results, nil
+}
+
+// cosine returns the cosine similarity of two vectors, 0 when their
+// dimensions differ or one is zero
+func cosine(a, b []float64) float64 {
+	if len(a) != len(b) {
+		return 0
+	}
+	var dot, na, nb float64
+	for i := range a {
+		dot += a[i] * b[i]
+		na += a[i] * a[i]
+		nb += b[i] * b[i]
+	}
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return dot / math.Sqrt(na*nb)
+}
+
 // Delete deletes a document
 func (m *InMemoryLongTermMemory) Delete(ctx context.Context, id string) error {
 	delete(m.documents, id)
@@ -89,3 +231,59 @@ func (m *InMemoryLongTermMemory) Clear(ctx context.Context) error {
 	m.documents = make(map[string]Document)
 	return nil
 }
+
+// Count returns how many documents match filter
+func (m *InMemoryLongTermMemory) Count(ctx context.Context, filter Filter) (uint64, error) {
+	var n uint64
+	for _, doc := range m.documents {
+		if filter.Match(metadataLookup(doc)) {
+			n++
+		}
+	}
+	return n, nil
+}
+
+// Scroll lists the documents matching filter by ID; the cursor is the ID of
+// the first document of the next page
+func (m *InMemoryLongTermMemory) Scroll(ctx context.Context, filter Filter, cursor string, limit int) ([]Document, string, error) {
+	ids := make([]string, 0, len(m.documents))
+	for id, doc := range m.documents {
+		if id >= cursor && filter.Match(metadataLookup(doc)) {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	next := ""
+	if limit > 0 && len(ids) > limit {
+		next = ids[limit]
+		ids = ids[:limit]
+	}
+	docs := make([]Document, len(ids))
+	for i, id := range ids {
+		docs[i] = m.documents[id]
+	}
+	return docs, next, nil
+}
+
+// Aggregate counts the documents matching filter per value of groupBy
+func (m *InMemoryLongTermMemory) Aggregate(ctx context.Context, filter Filter, groupBy string) (map[string]uint64, error) {
+	counts := make(map[string]uint64)
+	for _, doc := range m.documents {
+		lookup := metadataLookup(doc)
+		if v, ok := lookup(groupBy); ok && filter.Match(lookup) {
+			counts[v]++
+		}
+	}
+	return counts, nil
+}
+
+// metadataLookup reads the metadata values of doc as strings
+func metadataLookup(doc Document) func(key string) (string, bool) {
+	return func(key string) (string, bool) {
+		v, ok := doc.Metadata[key]
+		if !ok {
+			return "", false
+		}
+		return fmt.Sprint(v), true
+	}
+}
@@ -0,0 +1,59 @@
+package notify
+
+import (
+	"context"
+	"time"
+
+	"github.com/doITmagic/rag-code-mcp/internal/healthcheck"
+)
+
+// HealthMonitor turns periodic health checks into health_degraded and
+// health_recovered events. Only state changes are sent, so an outage is
+// reported once rather than on every check.
+type HealthMonitor struct {
+	notifier *Notifier
+	check    func() []healthcheck.CheckResult
+	down     map[string]bool // service -> last check failed
+}
+
+// NewHealthMonitor creates a monitor running check.
+func NewHealthMonitor(n *Notifier, check func() []healthcheck.CheckResult) *HealthMonitor {
+	return &HealthMonitor{notifier: n, check: check, down: make(map[string]bool)}
+}
+
+// Run checks every interval until ctx is cancelled.
+func (h *HealthMonitor) Run(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		h.Check()
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Check runs the health checks once and notifies about changes. It returns
+// the events sent.
+func (h *HealthMonitor) Check() []Event {
+	var events []Event
+	for _, r := range h.check() {
+		failed := r.Status != "ok"
+		if failed == h.down[r.Service] {
+			continue
+		}
+		h.down[r.Service] = failed
+		ev := Event{Type: HealthRecovered, Service: r.Service, Message: r.Message}
+		if failed {
+			ev.Type = HealthDegraded
+		}
+		events = append(events, ev)
+		h.notifier.Notify(ev)
+	}
+	return events
+}
@@ -0,0 +1,178 @@
+// Package notify tells operators of a shared server about indexing results
+// and dependency outages, through a shell command hook and/or a webhook
+// (Slack incoming webhooks work as-is).
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/doITmagic/rag-code-mcp/internal/config"
+)
+
+// Event types
+const (
+	IndexCompleted  = "index_completed"
+	IndexFailed     = "index_failed"
+	HealthDegraded  = "health_degraded"
+	HealthRecovered = "health_recovered"
+)
+
+// Event is one notification. Text is a one-line human summary.
+type Event struct {
+	Type       string    `json:"event"`
+	Time       time.Time `json:"time"`
+	Host       string    `json:"host,omitempty"`
+	Workspace  string    `json:"workspace,omitempty"`
+	Language   string    `json:"language,omitempty"`
+	Service    string    `json:"service,omitempty"`
+	Files      int       `json:"files,omitempty"`
+	DurationMs int64     `json:"duration_ms,omitempty"`
+	Message    string    `json:"message"`
+	Text       string    `json:"text"`
+}
+
+// Notifier delivers events. A nil *Notifier drops them, so callers need no
+// checks.
+type Notifier struct {
+	command    string
+	webhookURL string
+	events     map[string]bool // nil = all
+	host       string
+	client     *http.Client
+}
+
+// New returns a Notifier for cfg, or nil when neither a command nor a
+// webhook URL is configured.
+func New(cfg config.NotificationsConfig) *Notifier {
+	if cfg.Command == "" && cfg.WebhookURL == "" {
+		return nil
+	}
+	n := &Notifier{
+		command:    cfg.Command,
+		webhookURL: cfg.WebhookURL,
+		client:     &http.Client{Timeout: 10 * time.Second},
+	}
+	if len(cfg.Events) > 0 {
+		n.events = make(map[string]bool)
+		for _, e := range cfg.Events {
+			n.events[strings.TrimSpace(e)] = true
+		}
+	}
+	n.host, _ = os.Hostname()
+	return n
+}
+
+// Notify sends ev in the background. Failures are logged, never returned:
+// a broken hook must not affect indexing.
+func (n *Notifier) Notify(ev Event) {
+	if n == nil || (n.events != nil && !n.events[ev.Type]) {
+		return
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		if err := n.Send(ctx, ev); err != nil {
+			log.Printf("⚠️  Notification %s failed: %v", ev.Type, err)
+		}
+	}()
+}
+
+// Send delivers ev to the command and the webhook and waits for both.
+func (n *Notifier) Send(ctx context.Context, ev Event) error {
+	if ev.Time.IsZero() {
+		ev.Time = time.Now()
+	}
+	if ev.Host == "" {
+		ev.Host = n.host
+	}
+	if ev.Text == "" {
+		ev.Text = summary(ev)
+	}
+	body, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+
+	var errs []string
+	if n.command != "" {
+		if err := n.runCommand(ctx, ev, body); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	if n.webhookURL != "" {
+		if err := n.post(ctx, body); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+func (n *Notifier) runCommand(ctx context.Context, ev Event, body []byte) error {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", n.command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", n.command)
+	}
+	cmd.Stdin = bytes.NewReader(body)
+	cmd.Env = append(os.Environ(),
+		"RAGCODE_EVENT="+ev.Type,
+		"RAGCODE_EVENT_WORKSPACE="+ev.Workspace,
+		"RAGCODE_EVENT_LANGUAGE="+ev.Language,
+		"RAGCODE_EVENT_SERVICE="+ev.Service,
+		"RAGCODE_EVENT_MESSAGE="+ev.Message,
+		"RAGCODE_EVENT_TEXT="+ev.Text,
+	)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("command hook: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+func (n *Notifier) post(ctx context.Context, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.webhookURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("webhook: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("webhook: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func summary(ev Event) string {
+	prefix := "RagCode"
+	if ev.Host != "" {
+		prefix += " on " + ev.Host
+	}
+	switch ev.Type {
+	case IndexCompleted:
+		return fmt.Sprintf("✅ %s: %s indexing of %s finished (%d file(s) changed, %v)", prefix, ev.Language, ev.Workspace, ev.Files, time.Duration(ev.DurationMs)*time.Millisecond)
+	case IndexFailed:
+		return fmt.Sprintf("❌ %s: %s indexing of %s failed: %s", prefix, ev.Language, ev.Workspace, ev.Message)
+	case HealthDegraded:
+		return fmt.Sprintf("🔴 %s: %s is unavailable: %s", prefix, ev.Service, ev.Message)
+	case HealthRecovered:
+		return fmt.Sprintf("🟢 %s: %s is available again", prefix, ev.Service)
+	}
+	return fmt.Sprintf("%s: %s %s", prefix, ev.Type, ev.Message)
+}
@@ -0,0 +1,86 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/doITmagic/rag-code-mcp/internal/config"
+	"github.com/doITmagic/rag-code-mcp/internal/healthcheck"
+)
+
+func TestNotifier(t *testing.T) {
+	if New(config.NotificationsConfig{}) != nil {
+		t.Fatal("notifier without command or webhook should be nil")
+	}
+	var nilNotifier *Notifier
+	nilNotifier.Notify(Event{Type: IndexFailed}) // must not panic
+
+	var got Event
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&got)
+	}))
+	defer srv.Close()
+
+	cfg := config.NotificationsConfig{WebhookURL: srv.URL}
+	out := filepath.Join(t.TempDir(), "event.txt")
+	if runtime.GOOS != "windows" {
+		cfg.Command = `printf '%s ' "$RAGCODE_EVENT" > "` + out + `"; cat >> "` + out + `"`
+	}
+	n := New(cfg)
+	ev := Event{Type: IndexFailed, Workspace: "/srv/api", Language: "go", Message: "qdrant unreachable"}
+	if err := n.Send(context.Background(), ev); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if got.Type != IndexFailed || got.Workspace != "/srv/api" || !strings.Contains(got.Text, "go indexing of /srv/api failed: qdrant unreachable") {
+		t.Errorf("webhook got %+v", got)
+	}
+	if cfg.Command != "" {
+		data, err := os.ReadFile(out)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasPrefix(string(data), "index_failed {") || !strings.Contains(string(data), `"workspace":"/srv/api"`) {
+			t.Errorf("command got %q", data)
+		}
+	}
+
+	failing := New(config.NotificationsConfig{Command: "exit 3"})
+	if runtime.GOOS != "windows" && failing.Send(context.Background(), ev) == nil {
+		t.Error("failing command hook should return an error")
+	}
+}
+
+func TestHealthMonitor(t *testing.T) {
+	status := "ok"
+	check := func() []healthcheck.CheckResult {
+		return []healthcheck.CheckResult{
+			{Service: "Ollama", Status: "ok"},
+			{Service: "Qdrant", Status: status, Message: "Cannot connect to Qdrant"},
+		}
+	}
+	h := NewHealthMonitor(nil, check)
+
+	if events := h.Check(); len(events) != 0 {
+		t.Errorf("healthy start: %+v", events)
+	}
+	status = "error"
+	events := h.Check()
+	if len(events) != 1 || events[0].Type != HealthDegraded || events[0].Service != "Qdrant" {
+		t.Errorf("outage: %+v", events)
+	}
+	if events := h.Check(); len(events) != 0 {
+		t.Errorf("ongoing outage reported again: %+v", events)
+	}
+	status = "ok"
+	events = h.Check()
+	if len(events) != 1 || events[0].Type != HealthRecovered {
+		t.Errorf("recovery: %+v", events)
+	}
+}
@@ -0,0 +1,222 @@
+// Package objstore uploads and downloads index artifacts to object storage
+// addressed by s3://bucket/key and gs://bucket/key URLs, so teams can share
+// snapshots and exports without a separate artifact pipeline. Credentials
+// come from the environment:
+//
+//   - s3://: AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY, AWS_SESSION_TOKEN
+//     (optional) and AWS_REGION or AWS_DEFAULT_REGION (default us-east-1).
+//     AWS_ENDPOINT_URL_S3 or AWS_ENDPOINT_URL selects an S3-compatible
+//     server (MinIO, R2), addressed path-style.
+//   - gs://: GOOGLE_OAUTH_ACCESS_TOKEN (e.g. from `gcloud auth
+//     print-access-token`), or the HMAC key GCS_HMAC_ACCESS_KEY_ID and
+//     GCS_HMAC_SECRET. STORAGE_EMULATOR_HOST selects an emulator.
+package objstore
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// ErrNotFound is returned by Get when the object does not exist
+var ErrNotFound = errors.New("object not found")
+
+// maxObjectSize bounds the objects Get reads into memory
+const maxObjectSize = 1 << 30
+
+// client sends the requests of all transfers
+var client = &http.Client{Timeout: 10 * time.Minute}
+
+// IsURL reports whether s addresses object storage rather than a local path
+func IsURL(s string) bool {
+	return strings.HasPrefix(s, "s3://") || strings.HasPrefix(s, "gs://")
+}
+
+// Join appends path elements to an object storage URL or prefix
+func Join(base string, elem ...string) string {
+	out := strings.TrimRight(base, "/")
+	for _, e := range elem {
+		out += "/" + strings.Trim(e, "/")
+	}
+	return out
+}
+
+// Put uploads data to the object at rawURL, replacing it
+func Put(ctx context.Context, rawURL string, data []byte) error {
+	req, err := newRequest(ctx, http.MethodPut, rawURL, data)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("upload %s: %w", rawURL, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("upload %s: %s", rawURL, responseError(resp))
+	}
+	return nil
+}
+
+// Get downloads the object at rawURL. It returns ErrNotFound when the object
+// does not exist.
+func Get(ctx context.Context, rawURL string) ([]byte, error) {
+	req, err := newRequest(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("download %s: %w", rawURL, err)
+	}
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, fmt.Errorf("download %s: %w", rawURL, ErrNotFound)
+	case resp.StatusCode/100 != 2:
+		return nil, fmt.Errorf("download %s: %s", rawURL, responseError(resp))
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxObjectSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("download %s: %w", rawURL, err)
+	}
+	if len(data) > maxObjectSize {
+		return nil, fmt.Errorf("download %s: object larger than %d bytes", rawURL, maxObjectSize)
+	}
+	return data, nil
+}
+
+// PutFile uploads the file at path to rawURL
+func PutFile(ctx context.Context, rawURL, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return Put(ctx, rawURL, data)
+}
+
+// location is a parsed object URL
+type location struct {
+	scheme string // s3 or gs
+	bucket string
+	key    string
+}
+
+func parseURL(rawURL string) (location, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return location{}, fmt.Errorf("invalid object storage URL %q: %w", rawURL, err)
+	}
+	loc := location{scheme: u.Scheme, bucket: u.Host, key: strings.TrimPrefix(u.Path, "/")}
+	if (loc.scheme != "s3" && loc.scheme != "gs") || loc.bucket == "" || loc.key == "" {
+		return location{}, fmt.Errorf("invalid object storage URL %q: use s3://bucket/key or gs://bucket/key", rawURL)
+	}
+	return loc, nil
+}
+
+// newRequest builds the signed request of method on the object at rawURL
+func newRequest(ctx context.Context, method, rawURL string, body []byte) (*http.Request, error) {
+	loc, err := parseURL(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	endpoint, sign, err := backend(loc)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.ContentLength = int64(len(body))
+	if body != nil {
+		req.Header.Set("Content-Type", "application/octet-stream")
+	}
+	sign(req, body)
+	return req, nil
+}
+
+// backend returns the HTTP URL of an object and the function signing its
+// requests, from the credentials in the environment
+func backend(loc location) (string, func(*http.Request, []byte), error) {
+	key := escapeKey(loc.key)
+	if loc.scheme == "gs" {
+		base := "https://storage.googleapis.com"
+		if host := os.Getenv("STORAGE_EMULATOR_HOST"); host != "" {
+			base = strings.TrimRight(host, "/")
+			if !strings.Contains(base, "://") {
+				base = "http://" + base
+			}
+		}
+		endpoint := base + "/" + loc.bucket + "/" + key
+		if token := os.Getenv("GOOGLE_OAUTH_ACCESS_TOKEN"); token != "" {
+			return endpoint, func(req *http.Request, _ []byte) {
+				req.Header.Set("Authorization", "Bearer "+token)
+			}, nil
+		}
+		creds := credentials{
+			accessKey: os.Getenv("GCS_HMAC_ACCESS_KEY_ID"),
+			secretKey: os.Getenv("GCS_HMAC_SECRET"),
+			region:    "auto",
+		}
+		if creds.accessKey == "" || creds.secretKey == "" {
+			return "", nil, fmt.Errorf("no credentials for gs://%s: set GOOGLE_OAUTH_ACCESS_TOKEN or GCS_HMAC_ACCESS_KEY_ID and GCS_HMAC_SECRET", loc.bucket)
+		}
+		return endpoint, creds.sign, nil
+	}
+
+	creds := credentials{
+		accessKey:    os.Getenv("AWS_ACCESS_KEY_ID"),
+		secretKey:    os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		sessionToken: os.Getenv("AWS_SESSION_TOKEN"),
+		region:       firstNonEmpty(os.Getenv("AWS_REGION"), os.Getenv("AWS_DEFAULT_REGION"), "us-east-1"),
+	}
+	if creds.accessKey == "" || creds.secretKey == "" {
+		return "", nil, fmt.Errorf("no credentials for s3://%s: set AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY", loc.bucket)
+	}
+	if base := firstNonEmpty(os.Getenv("AWS_ENDPOINT_URL_S3"), os.Getenv("AWS_ENDPOINT_URL")); base != "" {
+		return strings.TrimRight(base, "/") + "/" + loc.bucket + "/" + key, creds.sign, nil
+	}
+	return fmt.Sprintf("https://%s.s3.%s.amazonaws.com/%s", loc.bucket, creds.region, key), creds.sign, nil
+}
+
+// escapeKey escapes an object key for a URL path like SigV4 canonical URIs
+// do: every byte but letters, digits, '-', '.', '_', '~' and '/'
+func escapeKey(key string) string {
+	var sb strings.Builder
+	for i := 0; i < len(key); i++ {
+		c := key[i]
+		if 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' || strings.IndexByte("-._~/", c) >= 0 {
+			sb.WriteByte(c)
+		} else {
+			fmt.Fprintf(&sb, "%%%02X", c)
+		}
+	}
+	return sb.String()
+}
+
+// responseError describes a failed response with the start of its body,
+// where S3 and GCS put the error code
+func responseError(resp *http.Response) string {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	if msg := strings.TrimSpace(string(body)); msg != "" {
+		return fmt.Sprintf("%s: %s", resp.Status, msg)
+	}
+	return resp.Status
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
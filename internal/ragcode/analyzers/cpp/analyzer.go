@@ -0,0 +1,314 @@
+package cpp
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/doITmagic/rag-code-mcp/internal/codetypes"
+)
+
+// errNoBackend is returned by builds without the tree-sitter backend
+var errNoBackend = errors.New("C/C++ analysis needs the tree-sitter backend: build with CGO_ENABLED=1")
+
+// CodeAnalyzer implements PathAnalyzer for C and C++
+type CodeAnalyzer struct {
+	files []*FileInfo
+}
+
+// NewCodeAnalyzer creates a new C/C++ code analyzer. Check Available first:
+// without cgo every file fails to parse.
+func NewCodeAnalyzer() *CodeAnalyzer {
+	return &CodeAnalyzer{}
+}
+
+// AnalyzePaths implements the PathAnalyzer interface
+func (ca *CodeAnalyzer) AnalyzePaths(paths []string) ([]codetypes.CodeChunk, error) {
+	// Reset state for global analysis
+	ca.files = nil
+
+	for _, root := range paths {
+		info, err := os.Stat(root)
+		if err != nil {
+			return nil, fmt.Errorf("error accessing path %s: %w", root, err)
+		}
+
+		if !info.IsDir() {
+			if err := ca.analyzeFile(root); err != nil {
+				return nil, fmt.Errorf("error analyzing %s: %w", root, err)
+			}
+			continue
+		}
+
+		err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				if path != root && shouldSkipDir(d.Name()) {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if !IsSourceFile(d.Name()) {
+				return nil
+			}
+			if err := ca.analyzeFile(path); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: failed to analyze %s: %v\n", path, err)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error walking directory %s: %w", root, err)
+		}
+	}
+
+	return ca.convertToChunks(), nil
+}
+
+// AnalyzeFile analyzes a single C or C++ file
+func (ca *CodeAnalyzer) AnalyzeFile(filePath string) ([]codetypes.CodeChunk, error) {
+	ca.files = nil
+	if err := ca.analyzeFile(filePath); err != nil {
+		return nil, err
+	}
+	return ca.convertToChunks(), nil
+}
+
+// GetFiles returns the internal file information
+func (ca *CodeAnalyzer) GetFiles() []*FileInfo {
+	return ca.files
+}
+
+// IsSourceFile reports whether a file name has a C or C++ source or header
+// extension
+func IsSourceFile(name string) bool {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".c", ".h", ".cc", ".cpp", ".cxx", ".hh", ".hpp", ".hxx":
+		return true
+	}
+	return false
+}
+
+// shouldSkipDir reports whether a directory holds build output, dependencies
+// or tooling data rather than sources
+func shouldSkipDir(name string) bool {
+	return strings.HasPrefix(name, ".") || strings.HasPrefix(name, "cmake-build-") ||
+		name == "build" || name == "node_modules" || name == "vendor"
+}
+
+func (ca *CodeAnalyzer) analyzeFile(path string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read file: %w", err)
+	}
+	file, err := parseSource(path, content)
+	if err != nil {
+		return err
+	}
+	ca.files = append(ca.files, file)
+	return nil
+}
+
+// isDocComment reports whether a comment is a Doxygen comment: /** */,
+// /*! */, /// or //!
+func isDocComment(text string) bool {
+	switch {
+	case strings.HasPrefix(text, "/**") && !strings.HasPrefix(text, "/**/"):
+		return true
+	case strings.HasPrefix(text, "/*!"), strings.HasPrefix(text, "///"), strings.HasPrefix(text, "//!"):
+		return true
+	}
+	return false
+}
+
+// isTrailingDoc reports whether a comment documents the member before it
+// (///< or /**< ...)
+func isTrailingDoc(text string) bool {
+	for _, prefix := range []string{"///<", "//!<", "/**<", "/*!<"} {
+		if strings.HasPrefix(text, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// cleanDoxygen strips the comment markers of Doxygen comments and the
+// \brief and @brief commands, keeping the other commands as written
+func cleanDoxygen(comments []string) string {
+	var lines []string
+	for _, c := range comments {
+		if strings.HasPrefix(c, "//") {
+			line := strings.TrimPrefix(strings.TrimPrefix(c[2:], "/"), "!")
+			lines = append(lines, strings.TrimPrefix(line, "<"))
+			continue
+		}
+		body := strings.TrimSuffix(c[3:], "*/")
+		body = strings.TrimPrefix(body, "<")
+		for _, line := range strings.Split(body, "\n") {
+			line = strings.TrimSpace(line)
+			if line != "*" {
+				line = strings.TrimPrefix(line, "* ")
+			} else {
+				line = ""
+			}
+			lines = append(lines, line)
+		}
+	}
+	for i, line := range lines {
+		line = strings.TrimSpace(line)
+		for _, brief := range []string{"@brief ", "\\brief "} {
+			line = strings.TrimPrefix(line, brief)
+		}
+		lines[i] = line
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// deprecation returns the note of a @deprecated or \deprecated command
+func deprecation(doc string) (string, bool) {
+	for _, line := range strings.Split(doc, "\n") {
+		for _, cmd := range []string{"@deprecated", "\\deprecated"} {
+			if rest, ok := strings.CutPrefix(strings.TrimSpace(line), cmd); ok {
+				return strings.TrimSpace(rest), true
+			}
+		}
+	}
+	return "", false
+}
+
+// collapse joins the lines of text with single spaces
+func collapse(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// convertToChunks converts the parsed files to CodeChunks: one chunk per
+// function, type and macro, plus one per member function
+func (ca *CodeAnalyzer) convertToChunks() []codetypes.CodeChunk {
+	// Member functions defined outside their class, by qualified class name
+	defined := make(map[string][]string)
+	for _, file := range ca.files {
+		for _, it := range file.Items {
+			if it.Kind == "function" && it.Scope != "" {
+				defined[it.Scope] = append(defined[it.Scope], it.Name)
+			}
+		}
+	}
+
+	var chunks []codetypes.CodeChunk
+	for _, file := range ca.files {
+		for _, it := range file.Items {
+			switch it.Kind {
+			case "function":
+				chunks = append(chunks, functionChunk(it))
+			case "struct", "class", "union", "enum":
+				chunks = append(chunks, typeChunks(it, defined)...)
+			case "macro":
+				ch := itemChunk(it, "macro")
+				ch.Metadata["params"] = it.MacroParams
+				ch.Metadata["value"] = it.MacroValue
+				chunks = append(chunks, ch)
+			}
+		}
+	}
+	return chunks
+}
+
+// typeChunks converts a type to a chunk, followed by the chunks of its
+// member functions and nested types
+func typeChunks(it ItemInfo, defined map[string][]string) []codetypes.CodeChunk {
+	typ := "type"
+	if it.Kind == "class" {
+		typ = "class"
+	}
+	ch := itemChunk(it, typ)
+	ch.Metadata["kind"] = it.Kind
+	ch.Metadata["fields"] = it.Fields
+	ch.Metadata["enumerators"] = it.Enumerators
+	ch.Metadata["bases"] = it.Bases
+	ch.Metadata["methods"] = memberNames(it, defined)
+	chunks := []codetypes.CodeChunk{ch}
+	for _, m := range it.Methods {
+		chunks = append(chunks, functionChunk(m))
+	}
+	for _, nested := range it.Nested {
+		chunks = append(chunks, typeChunks(nested, defined)...)
+	}
+	return chunks
+}
+
+func itemChunk(it ItemInfo, typ string) codetypes.CodeChunk {
+	ch := codetypes.CodeChunk{
+		Name:               it.Name,
+		Type:               typ,
+		Language:           codetypes.LanguageCPP,
+		Package:            it.Namespace,
+		FilePath:           it.FilePath,
+		StartLine:          it.StartLine,
+		EndLine:            it.EndLine,
+		SelectionStartLine: it.SelectionLine,
+		SelectionEndLine:   it.SelectionLine,
+		Signature:          it.Signature,
+		Docstring:          it.Description,
+		Code:               it.Code,
+		Metadata: map[string]any{
+			"access":   it.Access,
+			"scope":    it.Scope,
+			"template": it.Template,
+		},
+	}
+	if note, ok := deprecation(it.Description); ok {
+		codetypes.MarkDeprecated(&ch, note)
+	}
+	return ch
+}
+
+// functionChunk converts a free function, or a method when it has a scope
+func functionChunk(it ItemInfo) codetypes.CodeChunk {
+	typ := "function"
+	if it.Scope != "" {
+		typ = "method"
+	}
+	ch := itemChunk(it, typ)
+	ch.Metadata["receiver"] = it.Scope
+	ch.Metadata["is_method"] = it.Scope != ""
+	ch.Metadata["params"] = it.Parameters
+	ch.Metadata["returns"] = it.ReturnType
+	ch.Metadata["is_declaration"] = it.IsDeclaration
+	ch.Metadata["is_static"] = it.IsStatic
+	ch.Metadata["is_inline"] = it.IsInline
+	ch.Metadata["is_virtual"] = it.IsVirtual
+	ch.Metadata["is_const"] = it.IsConst
+	return ch
+}
+
+// qualifiedName returns the name of an item within its enclosing classes
+func qualifiedName(it ItemInfo) string {
+	if it.Scope == "" {
+		return it.Name
+	}
+	return it.Scope + "::" + it.Name
+}
+
+// memberNames lists the member functions of a type, declared in its body or
+// defined outside it
+func memberNames(it ItemInfo, defined map[string][]string) []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, m := range it.Methods {
+		if !seen[m.Name] {
+			seen[m.Name] = true
+			names = append(names, m.Name)
+		}
+	}
+	for _, name := range defined[qualifiedName(it)] {
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	return names
+}
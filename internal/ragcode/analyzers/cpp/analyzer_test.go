@@ -0,0 +1,237 @@
+//go:build cgo
+
+package cpp
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/doITmagic/rag-code-mcp/internal/codetypes"
+	"github.com/stretchr/testify/require"
+)
+
+const clientSource = `#ifndef NET_CLIENT_H
+#define NET_CLIENT_H
+
+#include <string>
+
+/** Larger of two values. */
+#define MAX(a, b) ((a) > (b) ? (a) : (b))
+
+/// Opaque socket handle.
+typedef struct {
+    int fd; ///< File descriptor
+} handle_t;
+
+namespace app::net {
+
+/**
+ * @brief A TCP client.
+ *
+ * Reconnects on failure.
+ * @deprecated use Conn
+ */
+template <typename T>
+class Client : public Base, private Other<T> {
+public:
+    explicit Client(int port);
+    virtual ~Client() = default;
+
+    /// Sends data.
+    /// @param data bytes to send
+    virtual int send(const std::string& data, int flags = 0) const;
+
+    static Client* create() { return nullptr; }
+
+    int port_; ///< Remote port
+
+private:
+    /// Connection states.
+    enum class State { Idle = 0, Busy };
+    State state_;
+};
+
+// Not a doc comment
+struct Point { double x, y; };
+
+int Client::send(const std::string& data, int flags) const {
+    return 0;
+}
+
+} // namespace app::net
+
+extern "C" {
+/// Stable C entry point.
+int c_api(void);
+}
+
+static inline unsigned *make_buffer(int n, ...) { return 0; }
+
+/** Primary colors. */
+enum Color { RED, /**< Warm */ GREEN };
+
+union Value { int i; float f; };
+
+int (*handler)(int);
+
+#endif
+`
+
+func analyzeSource(t *testing.T, name, src string) []codetypes.CodeChunk {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(src), 0644))
+	chunks, err := NewCodeAnalyzer().AnalyzeFile(path)
+	require.NoError(t, err)
+	return chunks
+}
+
+func findChunk(t *testing.T, chunks []codetypes.CodeChunk, typ, name string) codetypes.CodeChunk {
+	t.Helper()
+	var found []codetypes.CodeChunk
+	for _, ch := range chunks {
+		if ch.Type == typ && ch.Name == name {
+			found = append(found, ch)
+		}
+	}
+	require.NotEmpty(t, found, "no %s chunk named %s", typ, name)
+	return found[0]
+}
+
+func TestAnalyzeClientHeader(t *testing.T) {
+	chunks := analyzeSource(t, "client.hpp", clientSource)
+
+	for _, ch := range chunks {
+		require.Equal(t, codetypes.LanguageCPP, ch.Language)
+		require.NotEqual(t, "NET_CLIENT_H", ch.Name, "include guards are not macros worth indexing")
+		require.NotEqual(t, "handler", ch.Name, "function pointers are not functions")
+	}
+
+	macro := findChunk(t, chunks, "macro", "MAX")
+	require.Equal(t, "Larger of two values.", macro.Docstring)
+	require.Equal(t, []string{"a", "b"}, macro.Metadata["params"])
+	require.Equal(t, "#define MAX(a, b)", macro.Signature)
+	require.Equal(t, 7, macro.StartLine)
+	require.Equal(t, 7, macro.EndLine)
+
+	handle := findChunk(t, chunks, "type", "handle_t")
+	require.Equal(t, "struct", handle.Metadata["kind"])
+	require.Equal(t, "Opaque socket handle.", handle.Docstring)
+	require.Equal(t, []FieldInfo{{Name: "fd", Type: "int", Access: "public", Description: "File descriptor"}}, handle.Metadata["fields"])
+
+	client := findChunk(t, chunks, "class", "Client")
+	require.Equal(t, "app::net", client.Package)
+	require.Equal(t, "<typename T>", client.Metadata["template"])
+	require.Equal(t, []string{"Base", "Other<T>"}, client.Metadata["bases"])
+	require.Equal(t, []string{"Client", "~Client", "send", "create"}, client.Metadata["methods"])
+	require.Equal(t, "A TCP client.\n\nReconnects on failure.\n@deprecated use Conn", client.Docstring)
+	deprecated, note := client.Deprecation()
+	require.True(t, deprecated)
+	require.Equal(t, "use Conn", note)
+	require.Equal(t, 22, client.StartLine)
+	require.Equal(t, 23, client.SelectionStartLine)
+	require.Equal(t, "template <typename T> class Client : public Base, private Other<T>", client.Signature)
+	fields := client.Metadata["fields"].([]FieldInfo)
+	require.Equal(t, []FieldInfo{
+		{Name: "port_", Type: "int", Access: "public", Description: "Remote port"},
+		{Name: "state_", Type: "State", Access: "private"},
+	}, fields)
+
+	state := findChunk(t, chunks, "type", "State")
+	require.Equal(t, "enum", state.Metadata["kind"])
+	require.Equal(t, "Client", state.Metadata["scope"])
+	require.Equal(t, "private", state.Metadata["access"])
+	require.Equal(t, []FieldInfo{{Name: "Idle", Type: "0"}, {Name: "Busy"}}, state.Metadata["enumerators"])
+
+	var declared, defined codetypes.CodeChunk
+	for _, ch := range chunks {
+		if ch.Type == "method" && ch.Name == "send" {
+			if ch.Metadata["is_declaration"] == true {
+				declared = ch
+			} else {
+				defined = ch
+			}
+		}
+	}
+	require.Equal(t, "Sends data.\n@param data bytes to send", declared.Docstring)
+	require.Equal(t, "Client", declared.Metadata["receiver"])
+	require.Equal(t, "public", declared.Metadata["access"])
+	require.Equal(t, true, declared.Metadata["is_virtual"])
+	require.Equal(t, true, declared.Metadata["is_const"])
+	require.Equal(t, "int", declared.Metadata["returns"])
+	require.Equal(t, []codetypes.ParamInfo{{Name: "data", Type: "const std::string&"}, {Name: "flags", Type: "int"}}, declared.Metadata["params"])
+	require.Equal(t, "Client", defined.Metadata["receiver"])
+	require.Equal(t, "app::net", defined.Package)
+	require.Equal(t, 45, defined.StartLine)
+	require.Equal(t, 47, defined.EndLine)
+	require.Equal(t, "int Client::send(const std::string& data, int flags) const", defined.Signature)
+
+	create := findChunk(t, chunks, "method", "create")
+	require.Equal(t, true, create.Metadata["is_static"])
+	require.Equal(t, "Client*", create.Metadata["returns"])
+
+	point := findChunk(t, chunks, "type", "Point")
+	require.Empty(t, point.Docstring, "plain comments are not documentation")
+	require.Len(t, point.Metadata["fields"], 2)
+
+	capi := findChunk(t, chunks, "function", "c_api")
+	require.Equal(t, "Stable C entry point.", capi.Docstring)
+	require.Equal(t, true, capi.Metadata["is_declaration"])
+	require.Empty(t, capi.Metadata["params"])
+
+	buffer := findChunk(t, chunks, "function", "make_buffer")
+	require.Equal(t, "unsigned*", buffer.Metadata["returns"])
+	require.Equal(t, true, buffer.Metadata["is_static"])
+	require.Equal(t, true, buffer.Metadata["is_inline"])
+	require.Equal(t, []codetypes.ParamInfo{{Name: "n", Type: "int"}, {Name: "...", Type: "..."}}, buffer.Metadata["params"])
+
+	color := findChunk(t, chunks, "type", "Color")
+	require.Equal(t, []FieldInfo{{Name: "RED", Description: "Warm"}, {Name: "GREEN"}}, color.Metadata["enumerators"])
+
+	value := findChunk(t, chunks, "type", "Value")
+	require.Equal(t, "union", value.Metadata["kind"])
+}
+
+func TestAnalyzeCSource(t *testing.T) {
+	chunks := analyzeSource(t, "list.c", `#include "list.h"
+
+/*!
+ * \brief Appends a node.
+ * \return the new length
+ */
+size_t list_append(struct list *l, const void *item)
+{
+    return ++l->len;
+}
+
+struct list {
+    size_t len;
+    struct node *head;
+};
+`)
+	fn := findChunk(t, chunks, "function", "list_append")
+	require.Equal(t, "Appends a node.\n\\return the new length", fn.Docstring)
+	require.Equal(t, "size_t", fn.Metadata["returns"])
+	require.Equal(t, []codetypes.ParamInfo{{Name: "l", Type: "struct list *"}, {Name: "item", Type: "const void *"}}, fn.Metadata["params"])
+	require.Equal(t, "", fn.Package)
+	require.Equal(t, 7, fn.StartLine)
+	require.Equal(t, 10, fn.EndLine)
+
+	list := findChunk(t, chunks, "type", "list")
+	require.Equal(t, []FieldInfo{{Name: "len", Type: "size_t", Access: "public"}, {Name: "head", Type: "struct node", Access: "public"}}, list.Metadata["fields"])
+}
+
+func TestAnalyzePathsSkipsBuildDirs(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "src"), 0755))
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "cmake-build-debug"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "src", "a.cc"), []byte("int a() { return 1; }\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "src", "notes.txt"), []byte("int b();\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "cmake-build-debug", "gen.cpp"), []byte("int gen() { return 0; }\n"), 0644))
+
+	chunks, err := NewCodeAnalyzer().AnalyzePaths([]string{root})
+	require.NoError(t, err)
+	require.Len(t, chunks, 1)
+	require.Equal(t, "a", chunks[0].Name)
+}
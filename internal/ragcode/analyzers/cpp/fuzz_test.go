@@ -0,0 +1,34 @@
+//go:build cgo
+
+package cpp
+
+import (
+	"strings"
+	"testing"
+)
+
+// FuzzParse feeds arbitrary text to the tree-sitter backend: it must not
+// panic, and every chunk must be named and lie within the source lines.
+func FuzzParse(f *testing.F) {
+	f.Add(clientSource)
+	f.Add("int main() { const char *s = \"}\"; char c = '{'; }\n")
+	f.Add("template <class T> struct S : T { void f() const &&; };\n")
+	f.Add("/** unterminated doc\nclass C {\n")
+	f.Add("#define F(x, ...) x##__VA_ARGS__\n#if X\nnamespace { enum { A } }\n#endif\n")
+	f.Fuzz(func(t *testing.T, src string) {
+		file, err := parseSource("fuzz.cpp", []byte(src))
+		if err != nil {
+			t.Fatal(err)
+		}
+		ca := &CodeAnalyzer{files: []*FileInfo{file}}
+		lines := strings.Count(src, "\n") + 1
+		for _, ch := range ca.convertToChunks() {
+			if ch.Name == "" || ch.Type == "" {
+				t.Fatalf("chunk without name or type: %+v", ch)
+			}
+			if ch.StartLine < 1 || ch.EndLine < ch.StartLine || ch.EndLine > lines {
+				t.Fatalf("%s %s: lines %d-%d outside the %d source lines", ch.Type, ch.Name, ch.StartLine, ch.EndLine, lines)
+			}
+		}
+	})
+}
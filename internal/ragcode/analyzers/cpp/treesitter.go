@@ -0,0 +1,525 @@
+//go:build cgo
+
+package cpp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/doITmagic/rag-code-mcp/internal/codetypes"
+	sitter "github.com/smacker/go-tree-sitter"
+	tscpp "github.com/smacker/go-tree-sitter/cpp"
+)
+
+// Available reports whether the tree-sitter backend is compiled in. It needs
+// cgo; builds without it have no C/C++ analyzer.
+const Available = true
+
+// language is the tree-sitter C++ grammar; it parses C as well
+var language = tscpp.GetLanguage()
+
+// parseSource parses one C or C++ source with tree-sitter. Syntax errors do
+// not fail the file: the items tree-sitter recovers are kept.
+func parseSource(path string, src []byte) (*FileInfo, error) {
+	parser := sitter.NewParser()
+	defer parser.Close()
+	parser.SetLanguage(language)
+	tree, err := parser.ParseCtx(context.Background(), nil, src)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse: %w", err)
+	}
+	defer tree.Close()
+
+	p := &tsParser{src: src, path: path}
+	return &FileInfo{Path: path, Items: p.declarations(tree.RootNode(), "")}, nil
+}
+
+// tsParser extracts the items of one syntax tree
+type tsParser struct {
+	src  []byte
+	path string
+}
+
+func (p *tsParser) text(n *sitter.Node) string {
+	return n.Content(p.src)
+}
+
+// lines returns the first and last line of a node; a node ending at the
+// start of a line (macros include their newline) ends on the line before
+func lines(n *sitter.Node) (int, int) {
+	start, end := int(n.StartPoint().Row)+1, int(n.EndPoint().Row)+1
+	if n.EndPoint().Column == 0 && end > start {
+		end--
+	}
+	return start, end
+}
+
+// docs collects the Doxygen comments right before the items of a list
+type docs struct {
+	comments []*sitter.Node
+}
+
+// add records a comment; a blank line separates it from earlier ones
+func (d *docs) add(n *sitter.Node) {
+	if k := len(d.comments); k > 0 && n.StartPoint().Row > d.comments[k-1].EndPoint().Row+1 {
+		d.comments = nil
+	}
+	d.comments = append(d.comments, n)
+}
+
+// take returns the documentation of an item starting at n and resets the
+// collected comments
+func (d *docs) take(p *tsParser, n *sitter.Node) string {
+	defer func() { d.comments = nil }()
+	k := len(d.comments)
+	if k == 0 || d.comments[k-1].EndPoint().Row+1 < n.StartPoint().Row {
+		return ""
+	}
+	var texts []string
+	for _, c := range d.comments {
+		if text := p.text(c); isDocComment(text) {
+			texts = append(texts, text)
+		}
+	}
+	return cleanDoxygen(texts)
+}
+
+// declarations extracts the items of a translation unit, namespace body,
+// extern "C" block or preprocessor conditional
+func (p *tsParser) declarations(list *sitter.Node, namespace string) []ItemInfo {
+	var out []ItemInfo
+	var d docs
+	for i := 0; i < int(list.NamedChildCount()); i++ {
+		n := list.NamedChild(i)
+		switch n.Type() {
+		case "comment":
+			d.add(n)
+			continue
+		case "preproc_ifdef", "preproc_if", "preproc_else", "preproc_elif", "preproc_elifdef":
+			out = append(out, p.declarations(n, namespace)...)
+		case "namespace_definition":
+			ns := namespace
+			if name := n.ChildByFieldName("name"); name != nil {
+				ns = joinScope(namespace, p.text(name))
+			}
+			if body := n.ChildByFieldName("body"); body != nil {
+				out = append(out, p.declarations(body, ns)...)
+			}
+		case "linkage_specification":
+			if body := n.ChildByFieldName("body"); body != nil {
+				if body.Type() == "declaration_list" {
+					out = append(out, p.declarations(body, namespace)...)
+				} else {
+					out = append(out, p.declaration(body, n, namespace, d.take(p, n), "")...)
+				}
+			}
+		default:
+			out = append(out, p.declaration(n, n, namespace, d.take(p, n), "")...)
+		}
+		d.comments = nil
+	}
+	return out
+}
+
+// declaration extracts the items of one declaration. outer is the node the
+// item's code starts at: the declaration itself, or its template.
+func (p *tsParser) declaration(n, outer *sitter.Node, namespace, doc, template string) []ItemInfo {
+	switch n.Type() {
+	case "template_declaration":
+		params := ""
+		if list := n.ChildByFieldName("parameters"); list != nil {
+			params = collapse(p.text(list))
+		}
+		for i := 0; i < int(n.NamedChildCount()); i++ {
+			if inner := n.NamedChild(i); inner.Type() != "template_parameter_list" {
+				return p.declaration(inner, outer, namespace, doc, params)
+			}
+		}
+	case "function_definition":
+		if it, ok := p.function(n, outer, namespace, doc, template); ok {
+			return []ItemInfo{it}
+		}
+	case "declaration", "type_definition":
+		if n.Type() == "declaration" {
+			if it, ok := p.function(n, outer, namespace, doc, template); ok {
+				return []ItemInfo{it}
+			}
+		}
+		if spec := n.ChildByFieldName("type"); spec != nil {
+			if it, ok := p.typeSpec(spec, n, outer, namespace, "", doc, template); ok {
+				return []ItemInfo{it}
+			}
+		}
+	case "struct_specifier", "class_specifier", "union_specifier", "enum_specifier":
+		if it, ok := p.typeSpec(n, nil, outer, namespace, "", doc, template); ok {
+			return []ItemInfo{it}
+		}
+	case "preproc_def", "preproc_function_def":
+		if it, ok := p.macro(n, namespace, doc); ok {
+			return []ItemInfo{it}
+		}
+	}
+	return nil
+}
+
+// functionDeclarator finds the function declarator under the pointer and
+// reference declarators of a declaration, counting them as the pointer or
+// reference part of the return type
+func functionDeclarator(n *sitter.Node) (*sitter.Node, string) {
+	suffix := ""
+	decl := n.ChildByFieldName("declarator")
+	for decl != nil {
+		switch decl.Type() {
+		case "function_declarator":
+			return decl, suffix
+		case "pointer_declarator":
+			suffix += "*"
+		case "reference_declarator":
+			suffix += "&"
+		default:
+			return nil, ""
+		}
+		next := decl.ChildByFieldName("declarator")
+		if next == nil && decl.NamedChildCount() > 0 {
+			// reference_declarator has no field name
+			next = decl.NamedChild(int(decl.NamedChildCount()) - 1)
+		}
+		decl = next
+	}
+	return nil, ""
+}
+
+// function reads a function definition or prototype; ok is false when n
+// does not declare a function
+func (p *tsParser) function(n, outer *sitter.Node, namespace, doc, template string) (ItemInfo, bool) {
+	fd, suffix := functionDeclarator(n)
+	if fd == nil {
+		return ItemInfo{}, false
+	}
+	nameNode := fd.ChildByFieldName("declarator")
+	if nameNode == nil {
+		return ItemInfo{}, false
+	}
+	switch nameNode.Type() {
+	case "identifier", "field_identifier", "destructor_name", "operator_name", "qualified_identifier", "template_function":
+	default:
+		// function pointers: int (*handler)(int);
+		return ItemInfo{}, false
+	}
+	scope, name := splitScope(p.text(nameNode))
+	if name == "" {
+		return ItemInfo{}, false
+	}
+
+	it := ItemInfo{
+		Kind:          "function",
+		Name:          name,
+		Namespace:     namespace,
+		Scope:         scope,
+		Description:   doc,
+		Template:      template,
+		FilePath:      p.path,
+		SelectionLine: int(nameNode.StartPoint().Row) + 1,
+	}
+	if typ := n.ChildByFieldName("type"); typ != nil {
+		it.ReturnType = collapse(p.text(typ)) + suffix
+	}
+	for i := 0; i < int(n.NamedChildCount()); i++ {
+		c := n.NamedChild(i)
+		switch c.Type() {
+		case "storage_class_specifier":
+			switch p.text(c) {
+			case "static":
+				it.IsStatic = true
+			case "inline":
+				it.IsInline = true
+			}
+		case "virtual":
+			it.IsVirtual = true
+		}
+	}
+	for i := 0; i < int(fd.NamedChildCount()); i++ {
+		if c := fd.NamedChild(i); c.Type() == "type_qualifier" && p.text(c) == "const" {
+			it.IsConst = true
+		}
+	}
+	if params := fd.ChildByFieldName("parameters"); params != nil {
+		it.Parameters = p.parameters(params)
+	}
+
+	body := n.ChildByFieldName("body")
+	it.IsDeclaration = body == nil && n.Type() != "function_definition"
+	sigEnd := n.EndByte()
+	if body != nil {
+		sigEnd = body.StartByte()
+	}
+	it.Signature = strings.TrimSuffix(collapse(string(p.src[outer.StartByte():sigEnd])), ";")
+	it.StartLine, it.EndLine = lines(outer)
+	it.Code = p.text(outer)
+	return it, true
+}
+
+// parameters reads a parameter list; (void) has none
+func (p *tsParser) parameters(list *sitter.Node) []codetypes.ParamInfo {
+	var params []codetypes.ParamInfo
+	for i := 0; i < int(list.ChildCount()); i++ {
+		c := list.Child(i)
+		switch c.Type() {
+		case "...", "variadic_parameter_declaration":
+			params = append(params, codetypes.ParamInfo{Name: "...", Type: collapse(p.text(c))})
+			continue
+		case "parameter_declaration", "optional_parameter_declaration":
+		default:
+			continue
+		}
+		end := c.EndByte()
+		if def := c.ChildByFieldName("default_value"); def != nil {
+			end = def.StartByte()
+		}
+		text := string(p.src[c.StartByte():end])
+		name := ""
+		if id := declaredName(c.ChildByFieldName("declarator")); id != nil {
+			name = p.text(id)
+			from, to := id.StartByte()-c.StartByte(), id.EndByte()-c.StartByte()
+			text = text[:from] + text[to:]
+		}
+		typ := strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(text), "="))
+		if name == "" && collapse(typ) == "void" {
+			continue
+		}
+		params = append(params, codetypes.ParamInfo{Name: name, Type: collapse(typ)})
+	}
+	return params
+}
+
+// declaredName finds the identifier a declarator declares
+func declaredName(n *sitter.Node) *sitter.Node {
+	for n != nil {
+		switch n.Type() {
+		case "identifier", "field_identifier", "type_identifier":
+			return n
+		}
+		next := n.ChildByFieldName("declarator")
+		if next == nil {
+			var last *sitter.Node
+			for i := 0; i < int(n.NamedChildCount()); i++ {
+				last = n.NamedChild(i)
+			}
+			next = last
+		}
+		n = next
+	}
+	return nil
+}
+
+// typeSpec reads a struct, class, union or enum with a body. decl is the
+// declaration or typedef around the specifier, if any: an anonymous type
+// takes the typedef name. scope is the enclosing class of nested types.
+func (p *tsParser) typeSpec(spec, decl, outer *sitter.Node, namespace, scope, doc, template string) (ItemInfo, bool) {
+	body := spec.ChildByFieldName("body")
+	if body == nil {
+		return ItemInfo{}, false
+	}
+	it := ItemInfo{
+		Kind:        strings.TrimSuffix(spec.Type(), "_specifier"),
+		Namespace:   namespace,
+		Scope:       scope,
+		Description: doc,
+		Template:    template,
+		FilePath:    p.path,
+	}
+	if name := spec.ChildByFieldName("name"); name != nil {
+		it.Name = collapse(p.text(name))
+		it.SelectionLine = int(name.StartPoint().Row) + 1
+	} else if decl != nil && decl.Type() == "type_definition" {
+		if name := declaredName(decl.ChildByFieldName("declarator")); name != nil {
+			it.Name = p.text(name)
+			it.SelectionLine = int(name.StartPoint().Row) + 1
+		}
+	}
+	if it.Name == "" {
+		return ItemInfo{}, false
+	}
+	it.Signature = strings.TrimSpace(collapse(string(p.src[outer.StartByte():body.StartByte()])))
+	it.StartLine, it.EndLine = lines(outer)
+	it.Code = p.text(outer)
+
+	if it.Kind == "enum" {
+		it.Enumerators = p.enumerators(body)
+		return it, true
+	}
+	for i := 0; i < int(spec.NamedChildCount()); i++ {
+		if c := spec.NamedChild(i); c.Type() == "base_class_clause" {
+			for j := 0; j < int(c.NamedChildCount()); j++ {
+				if b := c.NamedChild(j); b.Type() != "access_specifier" {
+					it.Bases = append(it.Bases, collapse(p.text(b)))
+				}
+			}
+		}
+	}
+	access := "public"
+	if it.Kind == "class" {
+		access = "private"
+	}
+	p.members(&it, body, access)
+	return it, true
+}
+
+// enumerators reads the constants of an enum body
+func (p *tsParser) enumerators(body *sitter.Node) []FieldInfo {
+	var out []FieldInfo
+	var d docs
+	for i := 0; i < int(body.NamedChildCount()); i++ {
+		c := body.NamedChild(i)
+		switch c.Type() {
+		case "comment":
+			if text := p.text(c); isTrailingDoc(text) && len(out) > 0 {
+				out[len(out)-1].Description = cleanDoxygen([]string{text})
+			} else {
+				d.add(c)
+			}
+		case "enumerator":
+			f := FieldInfo{Description: d.take(p, c)}
+			if name := c.ChildByFieldName("name"); name != nil {
+				f.Name = p.text(name)
+			}
+			if value := c.ChildByFieldName("value"); value != nil {
+				f.Type = collapse(p.text(value))
+			}
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+// members reads the data members, member functions and nested types of a
+// struct, class or union body. access is the default access of the body.
+func (p *tsParser) members(it *ItemInfo, body *sitter.Node, access string) {
+	owner := qualifiedName(*it)
+	var d docs
+	for i := 0; i < int(body.NamedChildCount()); i++ {
+		c := body.NamedChild(i)
+		switch c.Type() {
+		case "comment":
+			if text := p.text(c); isTrailingDoc(text) && len(it.Fields) > 0 {
+				it.Fields[len(it.Fields)-1].Description = cleanDoxygen([]string{text})
+			} else {
+				d.add(c)
+			}
+			continue
+		case "access_specifier":
+			access = p.text(c)
+		case "function_definition", "declaration", "field_declaration", "template_declaration":
+			doc := d.take(p, c)
+			decl, template := c, ""
+			if c.Type() == "template_declaration" {
+				if list := c.ChildByFieldName("parameters"); list != nil {
+					template = collapse(p.text(list))
+				}
+				for j := 0; j < int(c.NamedChildCount()); j++ {
+					if inner := c.NamedChild(j); inner.Type() != "template_parameter_list" {
+						decl = inner
+						break
+					}
+				}
+			}
+			if m, ok := p.function(decl, c, it.Namespace, doc, template); ok {
+				if m.Scope == "" {
+					m.Scope = owner
+				} else {
+					m.Scope = joinScope(owner, m.Scope)
+				}
+				m.Access = access
+				it.Methods = append(it.Methods, m)
+				break
+			}
+			if spec := decl.ChildByFieldName("type"); spec != nil {
+				if nested, ok := p.typeSpec(spec, decl, c, it.Namespace, owner, doc, template); ok {
+					nested.Access = access
+					it.Nested = append(it.Nested, nested)
+				}
+			}
+			if decl.Type() == "field_declaration" {
+				typ := ""
+				if t := decl.ChildByFieldName("type"); t != nil {
+					typ = collapse(p.text(t))
+					if t.ChildByFieldName("body") != nil {
+						typ = strings.TrimSuffix(t.Type(), "_specifier")
+						if name := t.ChildByFieldName("name"); name != nil {
+							typ += " " + p.text(name)
+						}
+					}
+				}
+				for j := 0; j < int(decl.ChildCount()); j++ {
+					if decl.FieldNameForChild(j) != "declarator" {
+						continue
+					}
+					if name := declaredName(decl.Child(j)); name != nil {
+						it.Fields = append(it.Fields, FieldInfo{Name: p.text(name), Type: typ, Access: access, Description: doc})
+					}
+				}
+			}
+		}
+		d.comments = nil
+	}
+}
+
+// macro reads a #define; include guards and other macros without a value
+// are skipped
+func (p *tsParser) macro(n *sitter.Node, namespace, doc string) (ItemInfo, bool) {
+	name := n.ChildByFieldName("name")
+	value := n.ChildByFieldName("value")
+	if name == nil || (value == nil && n.Type() == "preproc_def") {
+		return ItemInfo{}, false
+	}
+	it := ItemInfo{
+		Kind:          "macro",
+		Name:          p.text(name),
+		Namespace:     namespace,
+		Description:   doc,
+		FilePath:      p.path,
+		SelectionLine: int(name.StartPoint().Row) + 1,
+		Code:          strings.TrimRight(p.text(n), "\n"),
+	}
+	sigEnd := name.EndByte()
+	if params := n.ChildByFieldName("parameters"); params != nil {
+		sigEnd = params.EndByte()
+		for i := 0; i < int(params.NamedChildCount()); i++ {
+			it.MacroParams = append(it.MacroParams, p.text(params.NamedChild(i)))
+		}
+	}
+	if value != nil {
+		it.MacroValue = collapse(p.text(value))
+	}
+	it.Signature = collapse(string(p.src[n.StartByte():sigEnd]))
+	it.StartLine, it.EndLine = lines(n)
+	return it, true
+}
+
+// splitScope splits a qualified name at its last top-level ::
+func splitScope(name string) (scope, last string) {
+	name = collapse(name)
+	depth := 0
+	for i := len(name) - 1; i > 0; i-- {
+		switch name[i] {
+		case '>':
+			depth++
+		case '<':
+			depth--
+		case ':':
+			if depth == 0 && name[i-1] == ':' {
+				return strings.TrimSpace(name[:i-1]), strings.TrimSpace(name[i+1:])
+			}
+		}
+	}
+	return "", name
+}
+
+// joinScope appends a name to a scope with ::
+func joinScope(scope, name string) string {
+	if scope == "" {
+		return name
+	}
+	return scope + "::" + name
+}
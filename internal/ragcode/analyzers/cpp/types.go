@@ -0,0 +1,56 @@
+package cpp
+
+import "github.com/doITmagic/rag-code-mcp/internal/codetypes"
+
+// FileInfo contains the items declared in one C or C++ source file
+type FileInfo struct {
+	Path  string     `json:"path"`
+	Items []ItemInfo `json:"items"`
+}
+
+// ItemInfo describes a C/C++ item: a function, method, struct, class,
+// union, enum or macro
+type ItemInfo struct {
+	Kind        string `json:"kind"` // function | struct | class | union | enum | macro
+	Name        string `json:"name"`
+	Namespace   string `json:"namespace"`       // Enclosing namespaces (e.g., "app::net")
+	Scope       string `json:"scope,omitempty"` // Enclosing class of members and of out-of-class definitions
+	Access      string `json:"access,omitempty"`
+	Signature   string `json:"signature"`
+	Description string `json:"description"` // Doxygen comments: /** */, /*! */, /// and //!
+	Template    string `json:"template,omitempty"`
+
+	// Functions and methods
+	Parameters    []codetypes.ParamInfo `json:"parameters,omitempty"`
+	ReturnType    string                `json:"return_type,omitempty"`
+	IsDeclaration bool                  `json:"is_declaration,omitempty"` // Prototype without a body
+	IsStatic      bool                  `json:"is_static,omitempty"`
+	IsInline      bool                  `json:"is_inline,omitempty"`
+	IsVirtual     bool                  `json:"is_virtual,omitempty"`
+	IsConst       bool                  `json:"is_const,omitempty"`
+
+	// Structs, classes, unions and enums
+	Fields      []FieldInfo `json:"fields,omitempty"`
+	Enumerators []FieldInfo `json:"enumerators,omitempty"`
+	Bases       []string    `json:"bases,omitempty"`
+	Methods     []ItemInfo  `json:"methods,omitempty"`
+	Nested      []ItemInfo  `json:"nested,omitempty"` // Types declared in the body
+
+	// Macros
+	MacroParams []string `json:"macro_params,omitempty"`
+	MacroValue  string   `json:"macro_value,omitempty"`
+
+	FilePath      string `json:"file_path,omitempty"`
+	StartLine     int    `json:"start_line,omitempty"`
+	EndLine       int    `json:"end_line,omitempty"`
+	SelectionLine int    `json:"selection_line,omitempty"` // Line of the item name
+	Code          string `json:"code,omitempty"`
+}
+
+// FieldInfo describes a data member or an enumerator
+type FieldInfo struct {
+	Name        string `json:"name"`
+	Type        string `json:"type,omitempty"` // Member type, or enumerator value
+	Access      string `json:"access,omitempty"`
+	Description string `json:"description,omitempty"`
+}
@@ -0,0 +1,358 @@
+package csharp
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/doITmagic/rag-code-mcp/internal/codetypes"
+)
+
+// errNoBackend is returned by builds without the tree-sitter backend
+var errNoBackend = errors.New("C# analysis needs the tree-sitter backend: build with CGO_ENABLED=1")
+
+// CodeAnalyzer implements PathAnalyzer for C#
+type CodeAnalyzer struct {
+	files []*FileInfo
+}
+
+// NewCodeAnalyzer creates a new C# code analyzer. Check Available first:
+// without cgo every file fails to parse.
+func NewCodeAnalyzer() *CodeAnalyzer {
+	return &CodeAnalyzer{}
+}
+
+// AnalyzePaths implements the PathAnalyzer interface
+func (ca *CodeAnalyzer) AnalyzePaths(paths []string) ([]codetypes.CodeChunk, error) {
+	// Reset state for global analysis
+	ca.files = nil
+
+	for _, root := range paths {
+		info, err := os.Stat(root)
+		if err != nil {
+			return nil, fmt.Errorf("error accessing path %s: %w", root, err)
+		}
+
+		if !info.IsDir() {
+			if err := ca.analyzeFile(root); err != nil {
+				return nil, fmt.Errorf("error analyzing %s: %w", root, err)
+			}
+			continue
+		}
+
+		err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				if path != root && shouldSkipDir(d.Name()) {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if !IsSourceFile(d.Name()) {
+				return nil
+			}
+			if err := ca.analyzeFile(path); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: failed to analyze %s: %v\n", path, err)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error walking directory %s: %w", root, err)
+		}
+	}
+
+	return ca.convertToChunks(), nil
+}
+
+// AnalyzeFile analyzes a single C# file
+func (ca *CodeAnalyzer) AnalyzeFile(filePath string) ([]codetypes.CodeChunk, error) {
+	ca.files = nil
+	if err := ca.analyzeFile(filePath); err != nil {
+		return nil, err
+	}
+	return ca.convertToChunks(), nil
+}
+
+// GetFiles returns the internal file information
+func (ca *CodeAnalyzer) GetFiles() []*FileInfo {
+	return ca.files
+}
+
+// Imports implements codetypes.ImportReporter: the namespaces each file
+// imports with using directives. A file belongs to the namespace of its
+// first type.
+func (ca *CodeAnalyzer) Imports() []codetypes.FileImports {
+	out := make([]codetypes.FileImports, 0, len(ca.files))
+	for _, file := range ca.files {
+		fi := codetypes.FileImports{FilePath: file.Path, Language: codetypes.LanguageCSharp}
+		if len(file.Items) > 0 {
+			fi.Package = file.Items[0].Namespace
+		}
+		for _, u := range file.Usings {
+			if ns := usingNamespace(u); ns != "" {
+				fi.Imports = append(fi.Imports, ns)
+			}
+		}
+		out = append(out, fi)
+	}
+	return out
+}
+
+// usingNamespace returns the namespace or type a using directive imports:
+// "global using X", "using static X" and "using A = X" all import X
+func usingNamespace(using string) string {
+	using = strings.TrimPrefix(using, "global using ")
+	using = strings.TrimPrefix(using, "static ")
+	if _, target, ok := strings.Cut(using, "="); ok {
+		using = target
+	}
+	return strings.TrimSpace(using)
+}
+
+// IsSourceFile reports whether a file name is a C# source file
+func IsSourceFile(name string) bool {
+	return strings.EqualFold(filepath.Ext(name), ".cs")
+}
+
+// shouldSkipDir reports whether a directory holds build output, packages or
+// tooling data rather than sources
+func shouldSkipDir(name string) bool {
+	return strings.HasPrefix(name, ".") || name == "bin" || name == "obj" ||
+		name == "packages" || name == "node_modules"
+}
+
+func (ca *CodeAnalyzer) analyzeFile(path string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read file: %w", err)
+	}
+	file, err := parseSource(path, content)
+	if err != nil {
+		return err
+	}
+	ca.files = append(ca.files, file)
+	return nil
+}
+
+// isDocComment reports whether a comment is an XML doc comment: /// or /** */
+func isDocComment(text string) bool {
+	switch {
+	case strings.HasPrefix(text, "///"):
+		return !strings.HasPrefix(text, "////")
+	case strings.HasPrefix(text, "/**"):
+		return !strings.HasPrefix(text, "/**/")
+	}
+	return false
+}
+
+// xmlDoc is the content of an XML doc comment
+type xmlDoc struct {
+	Summary string
+	Remarks string
+	Returns string
+	Params  map[string]string
+}
+
+var (
+	docTag      = regexp.MustCompile(`(?s)<(summary|remarks|returns|value)>(.*?)</(?:summary|remarks|returns|value)>`)
+	docParam    = regexp.MustCompile(`(?s)<param\s+name="([^"]*)"\s*>(.*?)</param>`)
+	docRef      = regexp.MustCompile(`<(?:see|seealso|paramref|typeparamref)\s+(?:cref|name|langword)="(?:[A-Z]:)?([^"]*)"\s*/>`)
+	docElement  = regexp.MustCompile(`</?[a-zA-Z][^>]*>`)
+	docHasBlock = regexp.MustCompile(`<(summary|remarks|returns|param|value|example|exception|typeparam)\b`)
+)
+
+// parseXMLDoc strips the comment markers of XML doc comments and reads
+// their <summary>, <remarks>, <returns> and <param> elements. Comments
+// without elements are taken as the summary.
+func parseXMLDoc(comments []string) xmlDoc {
+	var lines []string
+	for _, c := range comments {
+		if strings.HasPrefix(c, "///") {
+			lines = append(lines, strings.TrimPrefix(c[3:], " "))
+			continue
+		}
+		body := strings.TrimSuffix(c[3:], "*/")
+		for _, line := range strings.Split(body, "\n") {
+			line = strings.TrimSpace(line)
+			if line != "*" {
+				line = strings.TrimPrefix(line, "* ")
+			} else {
+				line = ""
+			}
+			lines = append(lines, line)
+		}
+	}
+	text := strings.Join(lines, "\n")
+
+	doc := xmlDoc{}
+	if !docHasBlock.MatchString(text) {
+		doc.Summary = docText(text)
+		return doc
+	}
+	for _, m := range docTag.FindAllStringSubmatch(text, -1) {
+		switch m[1] {
+		case "summary", "value":
+			if doc.Summary == "" {
+				doc.Summary = docText(m[2])
+			}
+		case "remarks":
+			doc.Remarks = docText(m[2])
+		case "returns":
+			doc.Returns = docText(m[2])
+		}
+	}
+	for _, m := range docParam.FindAllStringSubmatch(text, -1) {
+		if doc.Params == nil {
+			doc.Params = make(map[string]string)
+		}
+		doc.Params[m[1]] = docText(m[2])
+	}
+	return doc
+}
+
+// description joins the summary and the remarks
+func (d xmlDoc) description() string {
+	if d.Remarks == "" {
+		return d.Summary
+	}
+	if d.Summary == "" {
+		return d.Remarks
+	}
+	return d.Summary + "\n\n" + d.Remarks
+}
+
+// docText renders the text of a doc element: references become their names,
+// other elements are dropped and lines are trimmed
+func docText(text string) string {
+	text = docRef.ReplaceAllString(text, "$1")
+	text = docElement.ReplaceAllString(text, "")
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		lines = append(lines, strings.TrimSpace(line))
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// collapse joins the lines of text with single spaces
+func collapse(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// convertToChunks converts the parsed files to CodeChunks: one chunk per
+// type, followed by one per member
+func (ca *CodeAnalyzer) convertToChunks() []codetypes.CodeChunk {
+	var chunks []codetypes.CodeChunk
+	for _, file := range ca.files {
+		for _, it := range file.Items {
+			chunks = append(chunks, typeChunks(it, file.Usings)...)
+		}
+	}
+	return chunks
+}
+
+// typeChunks converts a type to a chunk, followed by the chunks of its
+// members and nested types
+func typeChunks(it ItemInfo, usings []string) []codetypes.CodeChunk {
+	typ := "type"
+	switch it.Kind {
+	case "class", "record":
+		typ = "class"
+	case "interface":
+		typ = "interface"
+	}
+	ch := itemChunk(it, typ)
+	ch.Metadata["kind"] = it.Kind
+	ch.Metadata["bases"] = it.Bases
+	ch.Metadata["fields"] = it.Fields
+	ch.Metadata["enum_values"] = it.EnumValues
+	ch.Metadata["usings"] = usings
+	var methods, properties []string
+	for _, m := range it.Members {
+		if m.Kind == "property" {
+			properties = append(properties, m.Name)
+		} else {
+			methods = append(methods, m.Name)
+		}
+	}
+	ch.Metadata["methods"] = methods
+	ch.Metadata["properties"] = properties
+	if len(it.Parameters) > 0 || it.Kind == "delegate" {
+		// Record primary constructors and delegates
+		ch.Metadata["params"] = it.Parameters
+		ch.Metadata["returns"] = it.ReturnType
+	}
+
+	chunks := []codetypes.CodeChunk{ch}
+	for _, m := range it.Members {
+		chunks = append(chunks, memberChunk(m))
+	}
+	for _, nested := range it.Nested {
+		chunks = append(chunks, typeChunks(nested, usings)...)
+	}
+	return chunks
+}
+
+// memberChunk converts a method-like member or a property
+func memberChunk(it ItemInfo) codetypes.CodeChunk {
+	typ := "method"
+	if it.Kind == "property" {
+		typ = "property"
+	}
+	ch := itemChunk(it, typ)
+	ch.Metadata["kind"] = it.Kind
+	ch.Metadata["receiver"] = it.Scope
+	ch.Metadata["is_method"] = typ == "method"
+	ch.Metadata["params"] = it.Parameters
+	ch.Metadata["param_docs"] = it.ParamDocs
+	ch.Metadata["returns"] = it.ReturnType
+	ch.Metadata["return_doc"] = it.ReturnDoc
+	ch.Metadata["accessors"] = it.Accessors
+	ch.Metadata["is_static"] = hasModifier(it, "static")
+	ch.Metadata["is_async"] = hasModifier(it, "async")
+	ch.Metadata["is_abstract"] = hasModifier(it, "abstract")
+	ch.Metadata["is_virtual"] = hasModifier(it, "virtual") || hasModifier(it, "override")
+	return ch
+}
+
+func itemChunk(it ItemInfo, typ string) codetypes.CodeChunk {
+	ch := codetypes.CodeChunk{
+		Name:               it.Name,
+		Type:               typ,
+		Language:           codetypes.LanguageCSharp,
+		Package:            it.Namespace,
+		FilePath:           it.FilePath,
+		StartLine:          it.StartLine,
+		EndLine:            it.EndLine,
+		SelectionStartLine: it.SelectionLine,
+		SelectionEndLine:   it.SelectionLine,
+		Signature:          it.Signature,
+		Docstring:          it.Description,
+		Code:               it.Code,
+		Metadata: map[string]any{
+			"access":      it.Access,
+			"scope":       it.Scope,
+			"modifiers":   it.Modifiers,
+			"attributes":  it.Attributes,
+			"type_params": it.TypeParams,
+		},
+	}
+	if it.Obsolete {
+		codetypes.MarkDeprecated(&ch, it.ObsoleteMsg)
+	}
+	return ch
+}
+
+func hasModifier(it ItemInfo, modifier string) bool {
+	for _, m := range it.Modifiers {
+		if m == modifier {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,227 @@
+//go:build cgo
+
+package csharp
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/doITmagic/rag-code-mcp/internal/codetypes"
+	"github.com/stretchr/testify/require"
+)
+
+const invoiceSource = `using System;
+using System.Collections.Generic;
+
+namespace Acme.Billing;
+
+/// <summary>
+/// Computes invoices for <see cref="T:Acme.Billing.Customer"/>.
+/// </summary>
+/// <remarks>Thread safe.</remarks>
+[Serializable]
+public sealed class InvoiceService<T> : BaseService, IInvoiceService where T : class
+{
+    /// <summary>The rate.</summary>
+    private readonly decimal _rate = 1.0m;
+
+    public const int MaxLines = 100;
+
+    public event EventHandler Changed;
+
+    /// <summary>Display name.</summary>
+    public string Name { get; protected set; }
+
+    public InvoiceService(decimal rate) { _rate = rate; }
+
+    /// <summary>Totals the <paramref name="items"/>.</summary>
+    /// <param name="items">Line items.</param>
+    /// <returns>The total.</returns>
+    [Obsolete("Use TotalAsync")]
+    public static decimal Total<TItem>(IEnumerable<TItem> items, params int[] extra) => 0;
+
+    // Not a doc comment
+    void Reset(ref int count) { }
+
+    private class Cache { }
+}
+
+public record Person(string First, string Last) : Entity(First);
+
+internal interface IInvoiceService
+{
+    decimal Total(int x);
+}
+
+/// Invoice states.
+public enum Status { Draft = 1, Paid }
+
+public delegate void Handler(object sender);
+`
+
+func analyzeSource(t *testing.T, name, src string) []codetypes.CodeChunk {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(src), 0644))
+	chunks, err := NewCodeAnalyzer().AnalyzeFile(path)
+	require.NoError(t, err)
+	return chunks
+}
+
+func findChunk(t *testing.T, chunks []codetypes.CodeChunk, typ, name string) codetypes.CodeChunk {
+	t.Helper()
+	for _, ch := range chunks {
+		if ch.Type == typ && ch.Name == name {
+			return ch
+		}
+	}
+	require.FailNow(t, "chunk not found", "no %s chunk named %s", typ, name)
+	return codetypes.CodeChunk{}
+}
+
+func TestAnalyzeInvoiceService(t *testing.T) {
+	chunks := analyzeSource(t, "InvoiceService.cs", invoiceSource)
+	for _, ch := range chunks {
+		require.Equal(t, codetypes.LanguageCSharp, ch.Language)
+		require.Equal(t, "Acme.Billing", ch.Package)
+	}
+
+	svc := findChunk(t, chunks, "class", "InvoiceService")
+	require.Equal(t, "Computes invoices for Acme.Billing.Customer.\n\nThread safe.", svc.Docstring)
+	require.Equal(t, "public sealed class InvoiceService<T> : BaseService, IInvoiceService where T : class", svc.Signature)
+	require.Equal(t, "public", svc.Metadata["access"])
+	require.Equal(t, []string{"sealed"}, svc.Metadata["modifiers"])
+	require.Equal(t, []string{"Serializable"}, svc.Metadata["attributes"])
+	require.Equal(t, "<T>", svc.Metadata["type_params"])
+	require.Equal(t, []string{"BaseService", "IInvoiceService"}, svc.Metadata["bases"])
+	require.Equal(t, []string{"System", "System.Collections.Generic"}, svc.Metadata["usings"])
+	require.Equal(t, []string{"InvoiceService", "Total", "Reset"}, svc.Metadata["methods"])
+	require.Equal(t, []string{"Name"}, svc.Metadata["properties"])
+	require.Equal(t, []Field{
+		{Name: "_rate", Kind: "field", Type: "decimal", Access: "private", Description: "The rate."},
+		{Name: "MaxLines", Kind: "const", Type: "int", Access: "public"},
+		{Name: "Changed", Kind: "event", Type: "EventHandler", Access: "public"},
+	}, svc.Metadata["fields"])
+	require.Equal(t, 10, svc.StartLine)
+	require.Equal(t, 11, svc.SelectionStartLine)
+	require.Equal(t, 35, svc.EndLine)
+
+	name := findChunk(t, chunks, "property", "Name")
+	require.Equal(t, "Display name.", name.Docstring)
+	require.Equal(t, "public string Name { get; protected set; }", name.Signature)
+	require.Equal(t, []string{"get", "protected set"}, name.Metadata["accessors"])
+	require.Equal(t, "string", name.Metadata["returns"])
+	require.Equal(t, "InvoiceService", name.Metadata["receiver"])
+
+	ctor := findChunk(t, chunks, "method", "InvoiceService")
+	require.Equal(t, "constructor", ctor.Metadata["kind"])
+	require.Equal(t, []codetypes.ParamInfo{{Name: "rate", Type: "decimal"}}, ctor.Metadata["params"])
+
+	total := findChunk(t, chunks, "method", "Total")
+	require.Equal(t, "Totals the items.", total.Docstring)
+	require.Equal(t, map[string]string{"items": "Line items."}, total.Metadata["param_docs"])
+	require.Equal(t, "The total.", total.Metadata["return_doc"])
+	require.Equal(t, "decimal", total.Metadata["returns"])
+	require.Equal(t, "<TItem>", total.Metadata["type_params"])
+	require.Equal(t, true, total.Metadata["is_static"])
+	require.Equal(t, []codetypes.ParamInfo{{Name: "items", Type: "IEnumerable<TItem>"}, {Name: "extra", Type: "params int[]"}}, total.Metadata["params"])
+	deprecated, note := total.Deprecation()
+	require.True(t, deprecated)
+	require.Equal(t, "Use TotalAsync", note)
+	require.Equal(t, 28, total.StartLine)
+	require.Equal(t, 29, total.SelectionStartLine)
+
+	reset := findChunk(t, chunks, "method", "Reset")
+	require.Empty(t, reset.Docstring, "plain comments are not documentation")
+	require.Equal(t, "private", reset.Metadata["access"])
+	require.Equal(t, []codetypes.ParamInfo{{Name: "count", Type: "ref int"}}, reset.Metadata["params"])
+
+	cache := findChunk(t, chunks, "class", "Cache")
+	require.Equal(t, "InvoiceService", cache.Metadata["scope"])
+	require.Equal(t, "private", cache.Metadata["access"])
+
+	person := findChunk(t, chunks, "class", "Person")
+	require.Equal(t, "record", person.Metadata["kind"])
+	require.Equal(t, []string{"Entity"}, person.Metadata["bases"])
+	require.Equal(t, []codetypes.ParamInfo{{Name: "First", Type: "string"}, {Name: "Last", Type: "string"}}, person.Metadata["params"])
+
+	iface := findChunk(t, chunks, "interface", "IInvoiceService")
+	require.Equal(t, "internal", iface.Metadata["access"])
+	var ifaceTotal codetypes.CodeChunk
+	for _, ch := range chunks {
+		if ch.Name == "Total" && ch.Metadata["receiver"] == "IInvoiceService" {
+			ifaceTotal = ch
+		}
+	}
+	require.Equal(t, "public", ifaceTotal.Metadata["access"], "interface members are public")
+	require.Equal(t, "decimal Total(int x)", ifaceTotal.Signature)
+
+	status := findChunk(t, chunks, "type", "Status")
+	require.Equal(t, "enum", status.Metadata["kind"])
+	require.Equal(t, "Invoice states.", status.Docstring)
+	require.Equal(t, []Field{{Name: "Draft", Type: "1", Access: "public"}, {Name: "Paid", Access: "public"}}, status.Metadata["enum_values"])
+
+	handler := findChunk(t, chunks, "type", "Handler")
+	require.Equal(t, "delegate", handler.Metadata["kind"])
+	require.Equal(t, "void", handler.Metadata["returns"])
+	require.Equal(t, "public delegate void Handler(object sender)", handler.Signature)
+}
+
+func TestAnalyzeNamespacesAndMembers(t *testing.T) {
+	chunks := analyzeSource(t, "Shapes.cs", `namespace Acme {
+namespace Geometry.Shapes {
+    public record struct Point(int X, int Y);
+
+    class Shape : IComparable<Shape> {
+        ~Shape() { }
+        public static Shape operator +(Shape a, Shape b) => a;
+        public static implicit operator double(Shape s) => 0;
+        public int this[int i] => i;
+        public abstract double Area { get; }
+        int IComparable<Shape>.CompareTo(Shape other) => 0;
+    }
+}
+}
+`)
+	point := findChunk(t, chunks, "type", "Point")
+	require.Equal(t, "record struct", point.Metadata["kind"])
+	require.Equal(t, "Acme.Geometry.Shapes", point.Package)
+
+	shape := findChunk(t, chunks, "class", "Shape")
+	require.Equal(t, "internal", shape.Metadata["access"])
+	require.Equal(t, "class Shape : IComparable<Shape>", shape.Signature)
+	require.Equal(t, []string{"~Shape", "operator +", "operator double", "this[]", "IComparable<Shape>.CompareTo"}, shape.Metadata["methods"])
+
+	area := findChunk(t, chunks, "property", "Area")
+	require.Equal(t, true, area.Metadata["is_abstract"])
+	indexer := findChunk(t, chunks, "method", "this[]")
+	require.Equal(t, []string{"get"}, indexer.Metadata["accessors"])
+}
+
+func TestParseXMLDoc(t *testing.T) {
+	doc := parseXMLDoc([]string{
+		"/** <summary>",
+		"/// Sends <c>data</c>; see <see langword=\"null\"/>.",
+		"/// </summary>",
+		"/// <param name=\"data\">Bytes.</param>",
+	})
+	require.Equal(t, "Sends data; see null.", doc.Summary)
+	require.Equal(t, map[string]string{"data": "Bytes."}, doc.Params)
+
+	require.Equal(t, "Plain text doc.", parseXMLDoc([]string{"/// Plain text doc."}).Summary)
+}
+
+func TestAnalyzePathsSkipsBuildDirs(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "src"), 0755))
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "src", "obj", "Debug"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "src", "A.cs"), []byte("class A { }\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "src", "A.csproj"), []byte("<Project />\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "src", "obj", "Debug", "AssemblyInfo.cs"), []byte("class Generated { }\n"), 0644))
+
+	chunks, err := NewCodeAnalyzer().AnalyzePaths([]string{root})
+	require.NoError(t, err)
+	require.Len(t, chunks, 1)
+	require.Equal(t, "A", chunks[0].Name)
+}
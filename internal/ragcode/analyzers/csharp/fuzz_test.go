@@ -0,0 +1,34 @@
+//go:build cgo
+
+package csharp
+
+import (
+	"strings"
+	"testing"
+)
+
+// FuzzParse feeds arbitrary text to the tree-sitter backend: it must not
+// panic, and every chunk must be named and lie within the source lines.
+func FuzzParse(f *testing.F) {
+	f.Add(invoiceSource)
+	f.Add("class C { string s = \"}\"; char c = '{'; string v = $\"{x}\"; }\n")
+	f.Add("namespace N; record R(int X) : B(X) { int this[int i] => i; }\n")
+	f.Add("/// <summary>unterminated\nclass C {\n")
+	f.Add("#if DEBUG\n[Obsolete] enum E { A = 1 << 2, }\n#endif\n")
+	f.Fuzz(func(t *testing.T, src string) {
+		file, err := parseSource("fuzz.cs", []byte(src))
+		if err != nil {
+			t.Fatal(err)
+		}
+		ca := &CodeAnalyzer{files: []*FileInfo{file}}
+		lines := strings.Count(src, "\n") + 1
+		for _, ch := range ca.convertToChunks() {
+			if ch.Name == "" || ch.Type == "" {
+				t.Fatalf("chunk without name or type: %+v", ch)
+			}
+			if ch.StartLine < 1 || ch.EndLine < ch.StartLine || ch.EndLine > lines {
+				t.Fatalf("%s %s: lines %d-%d outside the %d source lines", ch.Type, ch.Name, ch.StartLine, ch.EndLine, lines)
+			}
+		}
+	})
+}
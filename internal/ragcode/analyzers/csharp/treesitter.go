@@ -0,0 +1,552 @@
+//go:build cgo
+
+package csharp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/doITmagic/rag-code-mcp/internal/codetypes"
+	sitter "github.com/smacker/go-tree-sitter"
+	tscsharp "github.com/smacker/go-tree-sitter/csharp"
+)
+
+// Available reports whether the tree-sitter backend is compiled in. It needs
+// cgo; builds without it have no C# analyzer.
+const Available = true
+
+// language is the tree-sitter C# grammar
+var language = tscsharp.GetLanguage()
+
+// typeKinds maps type declarations to item kinds
+var typeKinds = map[string]string{
+	"class_declaration":     "class",
+	"record_declaration":    "record",
+	"interface_declaration": "interface",
+	"struct_declaration":    "struct",
+	"enum_declaration":      "enum",
+	"delegate_declaration":  "delegate",
+}
+
+// memberKinds maps method-like member declarations to item kinds
+var memberKinds = map[string]string{
+	"method_declaration":              "method",
+	"constructor_declaration":         "constructor",
+	"destructor_declaration":          "destructor",
+	"operator_declaration":            "operator",
+	"conversion_operator_declaration": "operator",
+	"indexer_declaration":             "indexer",
+	"property_declaration":            "property",
+}
+
+// parseSource parses one C# source with tree-sitter. Syntax errors do not
+// fail the file: the items tree-sitter recovers are kept.
+func parseSource(path string, src []byte) (*FileInfo, error) {
+	parser := sitter.NewParser()
+	defer parser.Close()
+	parser.SetLanguage(language)
+	tree, err := parser.ParseCtx(context.Background(), nil, src)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse: %w", err)
+	}
+	defer tree.Close()
+
+	p := &tsParser{src: src, path: path}
+	items := p.declarations(tree.RootNode(), "")
+	return &FileInfo{Path: path, Usings: p.usings, Items: items}, nil
+}
+
+// tsParser extracts the items of one syntax tree
+type tsParser struct {
+	src    []byte
+	path   string
+	usings []string
+}
+
+func (p *tsParser) text(n *sitter.Node) string {
+	return n.Content(p.src)
+}
+
+// docs collects the XML doc comments right before the items of a list
+type docs struct {
+	comments []*sitter.Node
+}
+
+// add records a comment; a blank line separates it from earlier ones
+func (d *docs) add(n *sitter.Node) {
+	if k := len(d.comments); k > 0 && n.StartPoint().Row > d.comments[k-1].EndPoint().Row+1 {
+		d.comments = nil
+	}
+	d.comments = append(d.comments, n)
+}
+
+// take returns the documentation of an item starting at n and resets the
+// collected comments
+func (d *docs) take(p *tsParser, n *sitter.Node) xmlDoc {
+	defer func() { d.comments = nil }()
+	k := len(d.comments)
+	if k == 0 || d.comments[k-1].EndPoint().Row+1 < n.StartPoint().Row {
+		return xmlDoc{}
+	}
+	var texts []string
+	for _, c := range d.comments {
+		if text := p.text(c); isDocComment(text) {
+			texts = append(texts, text)
+		}
+	}
+	if len(texts) == 0 {
+		return xmlDoc{}
+	}
+	return parseXMLDoc(texts)
+}
+
+// declarations extracts the types of a compilation unit or namespace body. A
+// file-scoped namespace applies to the declarations after it.
+func (p *tsParser) declarations(list *sitter.Node, namespace string) []ItemInfo {
+	var out []ItemInfo
+	var d docs
+	for i := 0; i < int(list.NamedChildCount()); i++ {
+		n := list.NamedChild(i)
+		switch n.Type() {
+		case "comment":
+			d.add(n)
+			continue
+		case "using_directive":
+			p.usings = append(p.usings, collapse(strings.TrimSuffix(strings.TrimPrefix(p.text(n), "using"), ";")))
+		case "namespace_declaration":
+			ns := namespace
+			if name := n.ChildByFieldName("name"); name != nil {
+				ns = joinName(namespace, p.text(name))
+			}
+			if body := n.ChildByFieldName("body"); body != nil {
+				out = append(out, p.declarations(body, ns)...)
+			}
+		case "file_scoped_namespace_declaration":
+			if name := n.ChildByFieldName("name"); name != nil {
+				namespace = joinName(namespace, p.text(name))
+			}
+			// Older grammars nest the following declarations
+			out = append(out, p.declarations(n, namespace)...)
+		default:
+			if it, ok := p.typeDecl(n, namespace, "", d.take(p, n)); ok {
+				out = append(out, it)
+			}
+		}
+		d.comments = nil
+	}
+	return out
+}
+
+// typeDecl reads a type declaration; ok is false when n is not one. scope is
+// the enclosing type of nested types.
+func (p *tsParser) typeDecl(n *sitter.Node, namespace, scope string, doc xmlDoc) (ItemInfo, bool) {
+	kind, ok := typeKinds[n.Type()]
+	if !ok {
+		return ItemInfo{}, false
+	}
+	nameNode := n.ChildByFieldName("name")
+	if nameNode == nil || nameNode.IsMissing() || p.text(nameNode) == "" {
+		return ItemInfo{}, false
+	}
+	it := p.header(n, nameNode, doc)
+	it.Kind = kind
+	it.Name = p.text(nameNode)
+	it.Namespace = namespace
+	it.Scope = scope
+	if it.Access == "" {
+		it.Access = "internal"
+		if scope != "" {
+			it.Access = "private"
+		}
+	}
+
+	body := n.ChildByFieldName("body")
+	for i := 0; i < int(n.ChildCount()); i++ {
+		c := n.Child(i)
+		switch c.Type() {
+		case "struct":
+			if kind == "record" {
+				it.Kind = "record struct"
+			}
+		case "base_list":
+			for j := 0; j < int(c.NamedChildCount()); j++ {
+				base := c.NamedChild(j)
+				if t := base.ChildByFieldName("type"); t != nil {
+					// record Person(...) : Base(...)
+					base = t
+				}
+				it.Bases = append(it.Bases, collapse(p.text(base)))
+			}
+		case "parameter_list":
+			// record primary constructor
+			it.Parameters = p.parameters(c)
+		case "declaration_list", "enum_member_declaration_list":
+			body = c
+		}
+	}
+	if kind == "delegate" {
+		if t := n.ChildByFieldName("type"); t != nil {
+			it.ReturnType = collapse(p.text(t))
+		}
+		if params := n.ChildByFieldName("parameters"); params != nil {
+			it.Parameters = p.parameters(params)
+		}
+		it.ParamDocs = doc.Params
+		it.ReturnDoc = doc.Returns
+	}
+	it.Signature = p.signature(n, body)
+
+	if body != nil {
+		if body.Type() == "enum_member_declaration_list" {
+			it.EnumValues = p.enumValues(body)
+		} else {
+			p.members(body, &it)
+		}
+	}
+	return it, true
+}
+
+// header reads what types and members share: modifiers, attributes, type
+// parameters, documentation, lines and code
+func (p *tsParser) header(n, nameNode *sitter.Node, doc xmlDoc) ItemInfo {
+	it := ItemInfo{
+		Description:   doc.description(),
+		FilePath:      p.path,
+		SelectionLine: int(nameNode.StartPoint().Row) + 1,
+		StartLine:     int(n.StartPoint().Row) + 1,
+		EndLine:       int(n.EndPoint().Row) + 1,
+		Code:          p.text(n),
+	}
+	var access []string
+	for i := 0; i < int(n.NamedChildCount()); i++ {
+		c := n.NamedChild(i)
+		switch c.Type() {
+		case "modifier":
+			switch m := p.text(c); m {
+			case "public", "private", "protected", "internal", "file":
+				access = append(access, m)
+			default:
+				it.Modifiers = append(it.Modifiers, m)
+			}
+		case "attribute_list":
+			p.attributes(c, &it)
+		case "type_parameter_list":
+			it.TypeParams = collapse(p.text(c))
+		}
+	}
+	it.Access = strings.Join(access, " ")
+	return it
+}
+
+// attributes records the attributes of a list, and whether one of them is
+// [Obsolete]
+func (p *tsParser) attributes(list *sitter.Node, it *ItemInfo) {
+	for i := 0; i < int(list.NamedChildCount()); i++ {
+		attr := list.NamedChild(i)
+		if attr.Type() != "attribute" {
+			continue
+		}
+		it.Attributes = append(it.Attributes, collapse(p.text(attr)))
+		name := attr.ChildByFieldName("name")
+		if name == nil {
+			continue
+		}
+		short := p.text(name)
+		short = short[strings.LastIndex(short, ".")+1:]
+		if short != "Obsolete" && short != "ObsoleteAttribute" {
+			continue
+		}
+		it.Obsolete = true
+		if args := attr.NamedChild(int(attr.NamedChildCount()) - 1); args != nil && args.Type() == "attribute_argument_list" && args.NamedChildCount() > 0 {
+			it.ObsoleteMsg = strings.Trim(p.text(args.NamedChild(0)), `@"`)
+		}
+	}
+}
+
+// signature is the declaration of n up to its body, without attributes
+func (p *tsParser) signature(n, body *sitter.Node) string {
+	start := n.StartByte()
+	for i := 0; i < int(n.ChildCount()); i++ {
+		c := n.Child(i)
+		if c.Type() != "attribute_list" && c.Type() != "comment" {
+			start = c.StartByte()
+			break
+		}
+	}
+	end := n.EndByte()
+	if body != nil {
+		end = body.StartByte()
+	}
+	if start >= end {
+		return ""
+	}
+	sig := collapse(string(p.src[start:end]))
+	return strings.TrimSpace(strings.TrimSuffix(sig, ";"))
+}
+
+// members reads the members of a class, record, interface or struct body
+func (p *tsParser) members(body *sitter.Node, parent *ItemInfo) {
+	scope := joinName(parent.Scope, parent.Name)
+	defaultAccess := "private"
+	if parent.Kind == "interface" {
+		defaultAccess = "public"
+	}
+	var d docs
+	for i := 0; i < int(body.NamedChildCount()); i++ {
+		n := body.NamedChild(i)
+		if n.Type() == "comment" {
+			d.add(n)
+			continue
+		}
+		doc := d.take(p, n)
+		switch n.Type() {
+		case "field_declaration", "event_field_declaration":
+			parent.Fields = append(parent.Fields, p.fields(n, doc, defaultAccess)...)
+		case "event_declaration":
+			f := Field{Kind: "event", Access: p.header(n, n, doc).Access, Description: doc.description()}
+			if name := n.ChildByFieldName("name"); name != nil {
+				f.Name = p.text(name)
+			}
+			if t := n.ChildByFieldName("type"); t != nil {
+				f.Type = collapse(p.text(t))
+			}
+			if f.Access == "" {
+				f.Access = defaultAccess
+			}
+			parent.Fields = append(parent.Fields, f)
+		default:
+			if nested, ok := p.typeDecl(n, parent.Namespace, scope, doc); ok {
+				parent.Nested = append(parent.Nested, nested)
+			} else if m, ok := p.member(n, parent.Namespace, scope, doc); ok {
+				if m.Access == "" {
+					m.Access = defaultAccess
+				}
+				parent.Members = append(parent.Members, m)
+			}
+		}
+	}
+}
+
+// member reads a method-like member or a property; ok is false when n is
+// not one
+func (p *tsParser) member(n *sitter.Node, namespace, scope string, doc xmlDoc) (ItemInfo, bool) {
+	kind, ok := memberKinds[n.Type()]
+	if !ok {
+		return ItemInfo{}, false
+	}
+	nameNode := n.ChildByFieldName("name")
+	if nameNode == nil {
+		nameNode = n
+	}
+	it := p.header(n, nameNode, doc)
+	it.Kind = kind
+	it.Namespace = namespace
+	it.Scope = scope
+	it.ParamDocs = doc.Params
+	it.ReturnDoc = doc.Returns
+	if kind == "property" && it.Description == "" {
+		it.Description = doc.Returns
+	}
+
+	switch n.Type() {
+	case "operator_declaration", "conversion_operator_declaration":
+		it.Name = p.operatorName(n)
+	case "indexer_declaration":
+		it.Name = "this[]"
+	case "destructor_declaration":
+		it.Name = "~" + p.text(nameNode)
+	default:
+		it.Name = p.text(nameNode)
+	}
+	if it.Name == "" || it.Name == "~" {
+		return ItemInfo{}, false
+	}
+	if iface := explicitInterface(n); iface != nil {
+		it.Name = strings.TrimSuffix(collapse(p.text(iface)), ".") + "." + it.Name
+	}
+
+	for _, field := range []string{"returns", "type"} {
+		if t := n.ChildByFieldName(field); t != nil {
+			it.ReturnType = collapse(p.text(t))
+			break
+		}
+	}
+	if params := n.ChildByFieldName("parameters"); params != nil {
+		it.Parameters = p.parameters(params)
+	}
+
+	var body *sitter.Node
+	for _, field := range []string{"body", "accessors", "value"} {
+		if b := n.ChildByFieldName(field); b != nil && (body == nil || b.StartByte() < body.StartByte()) {
+			body = b
+		}
+	}
+	it.Signature = p.signature(n, body)
+	if accessors := n.ChildByFieldName("accessors"); accessors != nil {
+		it.Accessors = p.accessors(accessors)
+		it.Signature += " { " + strings.Join(it.Accessors, "; ") + "; }"
+	} else if kind == "property" || kind == "indexer" {
+		// expression-bodied: int X => 1;
+		it.Accessors = []string{"get"}
+	}
+	return it, true
+}
+
+// explicitInterface returns the interface of an explicit implementation
+// (int IList.Count), nil for other members
+func explicitInterface(n *sitter.Node) *sitter.Node {
+	for i := 0; i < int(n.NamedChildCount()); i++ {
+		if c := n.NamedChild(i); c.Type() == "explicit_interface_specifier" {
+			return c
+		}
+	}
+	return nil
+}
+
+// operatorName names an operator after its symbol (operator +) or, for
+// conversions, its target type (operator int)
+func (p *tsParser) operatorName(n *sitter.Node) string {
+	for i := 0; i+1 < int(n.ChildCount()); i++ {
+		if n.Child(i).Type() == "operator" {
+			return "operator " + collapse(p.text(n.Child(i+1)))
+		}
+	}
+	return "operator"
+}
+
+// accessors lists the accessors of a property, indexer or event, with their
+// access when it differs (protected set)
+func (p *tsParser) accessors(list *sitter.Node) []string {
+	var out []string
+	for i := 0; i < int(list.NamedChildCount()); i++ {
+		acc := list.NamedChild(i)
+		if acc.Type() != "accessor_declaration" {
+			continue
+		}
+		var parts []string
+		for j := 0; j < int(acc.ChildCount()); j++ {
+			c := acc.Child(j)
+			switch c.Type() {
+			case "modifier", "get", "set", "init", "add", "remove":
+				parts = append(parts, p.text(c))
+			}
+		}
+		if len(parts) > 0 {
+			out = append(out, strings.Join(parts, " "))
+		}
+	}
+	return out
+}
+
+// parameters reads a parameter list. Modifiers (ref, out, in, this, params)
+// are kept with the type.
+func (p *tsParser) parameters(list *sitter.Node) []codetypes.ParamInfo {
+	var out []codetypes.ParamInfo
+	var pending string
+	for i := 0; i < int(list.ChildCount()); i++ {
+		c := list.Child(i)
+		switch field := list.FieldNameForChild(i); {
+		case c.Type() == "parameter":
+			param := codetypes.ParamInfo{}
+			var mods []string
+			for j := 0; j < int(c.NamedChildCount()); j++ {
+				if m := c.NamedChild(j); m.Type() == "modifier" {
+					mods = append(mods, p.text(m))
+				}
+			}
+			if t := c.ChildByFieldName("type"); t != nil {
+				param.Type = collapse(p.text(t))
+			}
+			param.Type = strings.TrimSpace(strings.Join(append(mods, param.Type), " "))
+			if name := c.ChildByFieldName("name"); name != nil {
+				param.Name = p.text(name)
+			}
+			out = append(out, param)
+		case field == "type":
+			// params arrays are not wrapped in a parameter node
+			pending = "params " + collapse(p.text(c))
+		case field == "name":
+			out = append(out, codetypes.ParamInfo{Name: p.text(c), Type: pending})
+			pending = ""
+		}
+	}
+	return out
+}
+
+// fields reads the variables of a field or event field declaration
+func (p *tsParser) fields(n *sitter.Node, doc xmlDoc, defaultAccess string) []Field {
+	h := p.header(n, n, doc)
+	kind := "field"
+	if n.Type() == "event_field_declaration" {
+		kind = "event"
+	} else if hasModifier(h, "const") {
+		kind = "const"
+	}
+	access := h.Access
+	if access == "" {
+		access = defaultAccess
+	}
+	var out []Field
+	for i := 0; i < int(n.NamedChildCount()); i++ {
+		decl := n.NamedChild(i)
+		if decl.Type() != "variable_declaration" {
+			continue
+		}
+		typ := ""
+		if t := decl.ChildByFieldName("type"); t != nil {
+			typ = collapse(p.text(t))
+		}
+		for j := 0; j < int(decl.NamedChildCount()); j++ {
+			v := decl.NamedChild(j)
+			if v.Type() != "variable_declarator" {
+				continue
+			}
+			name := v.ChildByFieldName("name")
+			if name == nil {
+				continue
+			}
+			out = append(out, Field{Name: p.text(name), Kind: kind, Type: typ, Access: access, Description: doc.description()})
+		}
+	}
+	return out
+}
+
+// enumValues reads the members of an enum
+func (p *tsParser) enumValues(list *sitter.Node) []Field {
+	var out []Field
+	var d docs
+	for i := 0; i < int(list.NamedChildCount()); i++ {
+		n := list.NamedChild(i)
+		if n.Type() == "comment" {
+			d.add(n)
+			continue
+		}
+		doc := d.take(p, n)
+		if n.Type() != "enum_member_declaration" {
+			continue
+		}
+		name := n.ChildByFieldName("name")
+		if name == nil {
+			continue
+		}
+		f := Field{Name: p.text(name), Access: "public", Description: doc.description()}
+		if v := n.ChildByFieldName("value"); v != nil {
+			f.Type = collapse(p.text(v))
+		}
+		out = append(out, f)
+	}
+	return out
+}
+
+// joinName joins dotted namespace or type names
+func joinName(outer, inner string) string {
+	inner = collapse(inner)
+	if outer == "" {
+		return inner
+	}
+	if inner == "" {
+		return outer
+	}
+	return outer + "." + inner
+}
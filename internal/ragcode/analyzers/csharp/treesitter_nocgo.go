@@ -0,0 +1,12 @@
+//go:build !cgo
+
+package csharp
+
+// Available reports whether the tree-sitter backend is compiled in. It needs
+// cgo; builds without it have no C# analyzer.
+const Available = false
+
+// parseSource fails: tree-sitter is not compiled in
+func parseSource(path string, src []byte) (*FileInfo, error) {
+	return nil, errNoBackend
+}
@@ -0,0 +1,59 @@
+package csharp
+
+import "github.com/doITmagic/rag-code-mcp/internal/codetypes"
+
+// FileInfo contains the items declared in one C# source file
+type FileInfo struct {
+	Path   string     `json:"path"`
+	Usings []string   `json:"usings,omitempty"`
+	Items  []ItemInfo `json:"items"`
+}
+
+// ItemInfo describes a C# type (class, record, interface, struct, enum or
+// delegate) or member (method, constructor, operator, indexer or property)
+type ItemInfo struct {
+	Kind        string   `json:"kind"`
+	Name        string   `json:"name"`
+	Namespace   string   `json:"namespace"`       // Enclosing namespace (e.g., "Acme.Billing")
+	Scope       string   `json:"scope,omitempty"` // Enclosing types of members and nested types (e.g., "Outer.Inner")
+	Access      string   `json:"access"`          // Declared, or the C# default (internal for types, private for members)
+	Modifiers   []string `json:"modifiers,omitempty"`
+	Attributes  []string `json:"attributes,omitempty"`
+	Signature   string   `json:"signature"`
+	Description string   `json:"description"` // <summary> and <remarks> of the XML doc comment
+	TypeParams  string   `json:"type_params,omitempty"`
+	Obsolete    bool     `json:"obsolete,omitempty"` // [Obsolete] attribute
+	ObsoleteMsg string   `json:"obsolete_message,omitempty"`
+
+	// Methods, constructors, operators, indexers, delegates and record
+	// primary constructors
+	Parameters []codetypes.ParamInfo `json:"parameters,omitempty"`
+	ParamDocs  map[string]string     `json:"param_docs,omitempty"` // <param name="..."> by parameter
+	ReturnType string                `json:"return_type,omitempty"`
+	ReturnDoc  string                `json:"return_doc,omitempty"` // <returns>
+
+	// Properties and indexers
+	Accessors []string `json:"accessors,omitempty"` // get, set, init
+
+	// Types
+	Bases      []string   `json:"bases,omitempty"`
+	Fields     []Field    `json:"fields,omitempty"` // Fields, constants and events
+	Members    []ItemInfo `json:"members,omitempty"`
+	Nested     []ItemInfo `json:"nested,omitempty"`
+	EnumValues []Field    `json:"enum_values,omitempty"`
+
+	FilePath      string `json:"file_path,omitempty"`
+	StartLine     int    `json:"start_line,omitempty"`
+	EndLine       int    `json:"end_line,omitempty"`
+	SelectionLine int    `json:"selection_line,omitempty"` // Line of the item name
+	Code          string `json:"code,omitempty"`
+}
+
+// Field describes a field, constant, event or enum member
+type Field struct {
+	Name        string `json:"name"`
+	Kind        string `json:"kind,omitempty"` // field | const | event
+	Type        string `json:"type,omitempty"` // Field type, or enum member value
+	Access      string `json:"access,omitempty"`
+	Description string `json:"description,omitempty"`
+}
@@ -6,11 +6,13 @@ import (
 	"go/ast"
 	"go/doc"
 	"go/parser"
+	"go/scanner"
 	"go/token"
 	"go/types"
 	"io/fs"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/doITmagic/rag-code-mcp/internal/codetypes"
@@ -18,33 +20,238 @@ import (
 
 // CodeAnalyzer mirrors the tutorial's analyzer to extract rich package info.
 type CodeAnalyzer struct {
-	fset *token.FileSet
+	fset         *token.FileSet
+	parseErrors  map[string][]codetypes.ParseError // file -> syntax errors of the last AnalyzePaths
+	imports      map[string]codetypes.FileImports  // file -> imports of the last AnalyzePaths
+	modules      map[string]string                 // package directory -> import path
+	includeTests bool                              // Option to index the functions of _test.go files
 }
 
 func NewCodeAnalyzer() *CodeAnalyzer {
 	return &CodeAnalyzer{fset: token.NewFileSet()}
 }
 
-func (ca *CodeAnalyzer) AnalyzePackage(dir string) (*PackageInfo, error) {
-	// Create a new FileSet for each directory
-	fset := token.NewFileSet()
+// NewCodeAnalyzerWithOptions creates a Go code analyzer with options
+func NewCodeAnalyzerWithOptions(includeTests bool) *CodeAnalyzer {
+	return &CodeAnalyzer{fset: token.NewFileSet(), includeTests: includeTests}
+}
 
-	// Parse files individually to retain AST bodies
+// ParseErrors implements codetypes.ParseErrorReporter: the syntax errors of
+// the last AnalyzePaths call. Files whose package clause parses are analyzed
+// from the partial syntax tree the parser recovers.
+func (ca *CodeAnalyzer) ParseErrors() []codetypes.ParseError {
+	files := make([]string, 0, len(ca.parseErrors))
+	for file := range ca.parseErrors {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+	var out []codetypes.ParseError
+	for _, file := range files {
+		out = append(out, ca.parseErrors[file]...)
+	}
+	return out
+}
+
+// Imports implements codetypes.ImportReporter: the import paths of each file
+// of the last AnalyzePaths call. Packages are named by import path, derived
+// from the enclosing go.mod.
+func (ca *CodeAnalyzer) Imports() []codetypes.FileImports {
+	out := make([]codetypes.FileImports, 0, len(ca.imports))
+	for _, imp := range ca.imports {
+		out = append(out, imp)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].FilePath < out[j].FilePath })
+	return out
+}
+
+// recordImports keeps the imports of the parsed files of a package, only
+// those in keep when it is set
+func (ca *CodeAnalyzer) recordImports(dir, pkgName string, files map[string]*ast.File, keep map[string]bool) {
+	if ca.imports == nil {
+		ca.imports = make(map[string]codetypes.FileImports)
+	}
+	pkg := ca.importPath(dir, pkgName)
+	for path, f := range files {
+		if keep != nil && !keep[path] {
+			continue
+		}
+		var imports []string
+		for _, imp := range f.Imports {
+			imports = append(imports, strings.Trim(imp.Path.Value, "\"`"))
+		}
+		ca.imports[path] = codetypes.FileImports{FilePath: path, Language: codetypes.LanguageGo, Package: pkg, Imports: imports}
+	}
+}
+
+// importPath returns the import path of a package directory: the module path
+// of the enclosing go.mod joined with the directory below it, or the package
+// name outside modules
+func (ca *CodeAnalyzer) importPath(dir, pkgName string) string {
+	if p, ok := ca.modules[dir]; ok {
+		return p
+	}
+	if ca.modules == nil {
+		ca.modules = make(map[string]string)
+	}
+	p := pkgName
+	for d := dir; ; d = filepath.Dir(d) {
+		if module := modulePath(filepath.Join(d, "go.mod")); module != "" {
+			rel, err := filepath.Rel(d, dir)
+			if err == nil {
+				p = module
+				if rel != "." {
+					p += "/" + filepath.ToSlash(rel)
+				}
+			}
+			break
+		}
+		if filepath.Dir(d) == d {
+			break
+		}
+	}
+	ca.modules[dir] = p
+	return p
+}
+
+// modulePath reads the module path of a go.mod file, empty when there is none
+func modulePath(goMod string) string {
+	data, err := os.ReadFile(goMod)
+	if err != nil {
+		return ""
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if fields := strings.Fields(line); len(fields) >= 2 && fields[0] == "module" {
+			return strings.Trim(fields[1], "\"`")
+		}
+	}
+	return ""
+}
+
+// recordParseError keeps the syntax errors of a file. skipped is set when
+// nothing of the file could be analyzed.
+func (ca *CodeAnalyzer) recordParseError(file string, err error, skipped bool) {
+	if ca.parseErrors == nil {
+		ca.parseErrors = make(map[string][]codetypes.ParseError)
+	}
+	var errs []codetypes.ParseError
+	if list, ok := err.(scanner.ErrorList); ok {
+		for _, e := range list {
+			errs = append(errs, codetypes.ParseError{FilePath: file, Line: e.Pos.Line, Message: e.Msg, Skipped: skipped})
+		}
+	}
+	if len(errs) == 0 {
+		errs = append(errs, codetypes.ParseError{FilePath: file, Message: err.Error(), Skipped: skipped})
+	}
+	ca.parseErrors[file] = errs
+}
+
+func (ca *CodeAnalyzer) AnalyzePackage(dir string) (*PackageInfo, error) {
 	files, err := filepath.Glob(filepath.Join(dir, "*.go"))
 	if err != nil {
 		return nil, fmt.Errorf("globbing directory: %w", err)
 	}
 
-	var astFiles []*ast.File
-	fileMap := make(map[string]*ast.File)
-
+	// Group files by build constraint: platform variants of a symbol
+	// (foo_windows.go, foo_unix.go) would otherwise shadow each other
+	groups := make(map[string][]string)
+	constraints := make(map[string]string)
+	pkgNames := make(map[string]string)
+	headerFset := token.NewFileSet()
 	for _, file := range files {
 		if strings.HasSuffix(file, "_test.go") {
 			continue
 		}
+		f, err := parser.ParseFile(headerFset, file, nil, parser.PackageClauseOnly|parser.ParseComments)
+		if err != nil {
+			ca.recordParseError(file, err, true)
+			continue // Skip files without a package clause
+		}
+		c := constraintString(fileConstraint(file, f))
+		groups[c] = append(groups[c], file)
+		constraints[file] = c
+		pkgNames[c] = f.Name.Name
+	}
+	if len(groups) == 0 {
+		return nil, fmt.Errorf("no parseable Go files found in %s", dir)
+	}
+	if len(groups) == 1 {
+		for c, group := range groups {
+			info, err := ca.analyzeFiles(dir, group, nil)
+			if err != nil {
+				return nil, err
+			}
+			if c != "" {
+				info.BuildConstraints = constraints
+			}
+			return info, nil
+		}
+	}
+
+	// Analyze the unconstrained files alone, then each constrained group
+	// together with them (so shared types resolve), keeping only the
+	// symbols declared in the group's own files
+	base := groups[""]
+	var info *PackageInfo
+	if len(base) > 0 {
+		info, err = ca.analyzeFiles(dir, base, nil)
+		if err != nil {
+			return nil, err
+		}
+	}
+	var keys []string
+	for c := range groups {
+		if c != "" {
+			keys = append(keys, c)
+		}
+	}
+	sort.Strings(keys)
+	for _, c := range keys {
+		group := groups[c]
+		keep := make(map[string]bool, len(group))
+		for _, file := range group {
+			keep[file] = true
+		}
+		paths := group
+		if len(base) > 0 && pkgNames[c] == pkgNames[""] {
+			paths = append(append([]string{}, base...), group...)
+		}
+		variant, err := ca.analyzeFiles(dir, paths, keep)
+		if err != nil {
+			continue
+		}
+		if info == nil {
+			info = variant
+			continue
+		}
+		info.merge(variant)
+	}
+	if info == nil {
+		return nil, fmt.Errorf("no parseable Go files found in %s", dir)
+	}
+	info.BuildConstraints = constraints
+	return info, nil
+}
+
+// analyzeFiles analyzes the given files of a package directory. When keep is
+// set, only symbols declared in those files are returned.
+func (ca *CodeAnalyzer) analyzeFiles(dir string, paths []string, keep map[string]bool) (*PackageInfo, error) {
+	// Create a new FileSet for each directory
+	fset := token.NewFileSet()
+
+	var astFiles []*ast.File
+	fileMap := make(map[string]*ast.File)
+
+	// Parse files individually to retain AST bodies
+	for _, file := range paths {
 		f, err := parser.ParseFile(fset, file, nil, parser.ParseComments)
 		if err != nil {
-			continue // Skip files with parse errors
+			// The parser recovers from syntax errors: keep the declarations
+			// of files being edited, flagged partial
+			if f == nil || len(f.Decls) == 0 {
+				ca.recordParseError(file, err, true)
+				continue
+			}
+			ca.recordParseError(file, err, false)
 		}
 		astFiles = append(astFiles, f)
 		fileMap[file] = f
@@ -72,6 +279,7 @@ func (ca *CodeAnalyzer) AnalyzePackage(dir string) (*PackageInfo, error) {
 		Description: cleanDoc(docPkg.Doc),
 		Imports:     ca.extractImports(astFiles),
 	}
+	ca.recordImports(dir, docPkg.Name, fileMap, keep)
 
 	// Functions
 	for _, fn := range docPkg.Funcs {
@@ -106,9 +314,65 @@ func (ca *CodeAnalyzer) AnalyzePackage(dir string) (*PackageInfo, error) {
 		info.Variables = append(info.Variables, varInfo...)
 	}
 
+	if keep != nil {
+		info.filter(keep)
+	}
 	return info, nil
 }
 
+// filter keeps the symbols declared in the given files
+func (pi *PackageInfo) filter(keep map[string]bool) {
+	functions := pi.Functions[:0]
+	for _, fn := range pi.Functions {
+		if keep[fn.FilePath] {
+			functions = append(functions, fn)
+		}
+	}
+	pi.Functions = functions
+	typs := pi.Types[:0]
+	for _, tp := range pi.Types {
+		if keep[tp.FilePath] {
+			typs = append(typs, tp)
+		}
+	}
+	pi.Types = typs
+	constants := pi.Constants[:0]
+	for _, c := range pi.Constants {
+		if keep[c.FilePath] {
+			constants = append(constants, c)
+		}
+	}
+	pi.Constants = constants
+	variables := pi.Variables[:0]
+	for _, v := range pi.Variables {
+		if keep[v.FilePath] {
+			variables = append(variables, v)
+		}
+	}
+	pi.Variables = variables
+}
+
+// merge adds the symbols and imports of a build variant of the package
+func (pi *PackageInfo) merge(other *PackageInfo) {
+	pi.Functions = append(pi.Functions, other.Functions...)
+	pi.Types = append(pi.Types, other.Types...)
+	pi.Constants = append(pi.Constants, other.Constants...)
+	pi.Variables = append(pi.Variables, other.Variables...)
+	seen := make(map[string]bool, len(pi.Imports))
+	for _, imp := range pi.Imports {
+		seen[imp] = true
+	}
+	for _, imp := range other.Imports {
+		if !seen[imp] {
+			seen[imp] = true
+			pi.Imports = append(pi.Imports, imp)
+		}
+	}
+	if pi.Description == "" {
+		pi.Description = other.Description
+	}
+}
+
 // buildFunctionASTMap creates a map from function/method name to AST FuncDecl (with Body intact)
 func (ca *CodeAnalyzer) buildFunctionASTMap(files []*ast.File) map[string]*ast.BlockStmt {
 	funcMap := make(map[string]*ast.BlockStmt)
@@ -145,6 +409,42 @@ func (ca *CodeAnalyzer) buildFunctionASTMap(files []*ast.File) map[string]*ast.B
 	return funcMap
 }
 
+// extractCalls lists the calls made in a function body, closures included,
+// once per callee and receiver, in source order
+func (ca *CodeAnalyzer) extractCalls(body *ast.BlockStmt) []codetypes.CallSite {
+	var calls []codetypes.CallSite
+	seen := make(map[string]bool)
+	ast.Inspect(body, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		var site codetypes.CallSite
+		switch fun := ast.Unparen(call.Fun).(type) {
+		case *ast.Ident:
+			site.Name = fun.Name
+		case *ast.SelectorExpr:
+			site.Name = fun.Sel.Name
+			site.Receiver = types.ExprString(fun.X)
+		case *ast.IndexExpr: // generic instantiation, e.g. Map[int](xs)
+			if id, ok := fun.X.(*ast.Ident); ok {
+				site.Name = id.Name
+			}
+		default:
+			return true
+		}
+		key := site.Receiver + "." + site.Name
+		if site.Name == "" || seen[key] {
+			return true
+		}
+		seen[key] = true
+		site.Line = ca.fset.Position(call.Pos()).Line
+		calls = append(calls, site)
+		return true
+	})
+	return calls
+}
+
 func (ca *CodeAnalyzer) analyzeFunctionDecl(fn *doc.Func, astBodyMap map[string]*ast.BlockStmt, receiverName ...string) FunctionInfo {
 	info := FunctionInfo{
 		Name:        fn.Name,
@@ -179,6 +479,7 @@ func (ca *CodeAnalyzer) analyzeFunctionDecl(fn *doc.Func, astBodyMap map[string]
 			info.Parameters = ca.extractParameters(fn.Decl.Type.Params)
 			info.Returns = ca.extractReturns(fn.Decl.Type.Results)
 		}
+		info.Calls = ca.extractCalls(astBody)
 	} else if fn.Decl != nil {
 		// Fallback to doc.Func Decl (won't have Body)
 		// Extract position information
@@ -547,6 +848,12 @@ func (ca *CodeAnalyzer) typeToString(expr ast.Expr) string {
 		return fmt.Sprintf("%s.%s", ca.typeToString(t.X), t.Sel.Name)
 	case *ast.InterfaceType:
 		return "interface{}"
+	case *ast.Ellipsis:
+		return "..." + ca.typeToString(t.Elt)
+	case *ast.ChanType:
+		return "chan " + ca.typeToString(t.Value)
+	case *ast.FuncType:
+		return "func"
 	default:
 		return "unknown"
 	}
@@ -633,6 +940,8 @@ func cleanDoc(docstr string) string {
 func (ca *CodeAnalyzer) AnalyzePaths(paths []string) ([]codetypes.CodeChunk, error) {
 	var chunks []codetypes.CodeChunk
 	visited := make(map[string]bool)
+	ca.parseErrors = nil
+	ca.imports = nil
 	for _, root := range paths {
 		err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
 			if err != nil {
@@ -647,7 +956,11 @@ func (ca *CodeAnalyzer) AnalyzePaths(paths []string) ([]codetypes.CodeChunk, err
 				}
 				return nil
 			}
-			if !strings.HasSuffix(d.Name(), ".go") || strings.HasSuffix(d.Name(), "_test.go") {
+			// Assembly files are analyzed with the package they belong to
+			if !strings.HasSuffix(d.Name(), ".go") && !strings.HasSuffix(d.Name(), ".s") {
+				return nil
+			}
+			if strings.HasSuffix(d.Name(), "_test.go") && !ca.includeTests {
 				return nil
 			}
 			dir := filepath.Dir(path)
@@ -656,18 +969,28 @@ func (ca *CodeAnalyzer) AnalyzePaths(paths []string) ([]codetypes.CodeChunk, err
 			}
 			// mark and analyze the whole package directory
 			visited[dir] = true
+			var testChunks []codetypes.CodeChunk
+			if ca.includeTests {
+				testChunks = ca.testChunks(dir)
+			}
 			pkgInfo, perr := ca.AnalyzePackage(dir)
 			if perr != nil {
 				// Non-fatal: skip directories without proper Go package
+				chunks = append(chunks, testChunks...)
 				return nil
 			}
-			chunks = append(chunks, convertPackageInfoToChunks(pkgInfo)...)
+			pkgChunks := convertPackageInfoToChunks(pkgInfo)
+			pkgChunks = append(pkgChunks, ca.lowLevelChunks(dir, pkgInfo, pkgChunks)...)
+			groupVariants(pkgChunks)
+			chunks = append(chunks, pkgChunks...)
+			chunks = append(chunks, testChunks...)
 			return nil
 		})
 		if err != nil {
 			return nil, err
 		}
 	}
+	codetypes.MarkPartial(chunks, ca.ParseErrors())
 	return chunks, nil
 }
 
@@ -685,7 +1008,7 @@ func convertPackageInfoToChunks(pi *PackageInfo) []codetypes.CodeChunk {
 			Type:      kind,
 			Name:      fn.Name,
 			Package:   pi.Name,
-			Language:  "go",
+			Language:  codetypes.LanguageGo,
 			FilePath:  fn.FilePath,
 			StartLine: fn.StartLine,
 			EndLine:   fn.EndLine,
@@ -698,6 +1021,7 @@ func convertPackageInfoToChunks(pi *PackageInfo) []codetypes.CodeChunk {
 				"params":    fn.Parameters,
 				"returns":   fn.Returns,
 				"examples":  fn.Examples,
+				"calls":     fn.Calls,
 			},
 		})
 	}
@@ -712,7 +1036,7 @@ func convertPackageInfoToChunks(pi *PackageInfo) []codetypes.CodeChunk {
 			Type:      "type",
 			Name:      tp.Name,
 			Package:   pi.Name,
-			Language:  "go",
+			Language:  codetypes.LanguageGo,
 			FilePath:  tp.FilePath,
 			StartLine: tp.StartLine,
 			EndLine:   tp.EndLine,
@@ -733,7 +1057,7 @@ func convertPackageInfoToChunks(pi *PackageInfo) []codetypes.CodeChunk {
 			Type:      "const",
 			Name:      c.Name,
 			Package:   pi.Name,
-			Language:  "go",
+			Language:  codetypes.LanguageGo,
 			FilePath:  c.FilePath,
 			StartLine: c.StartLine,
 			EndLine:   c.EndLine,
@@ -752,7 +1076,7 @@ func convertPackageInfoToChunks(pi *PackageInfo) []codetypes.CodeChunk {
 			Type:      "var",
 			Name:      v.Name,
 			Package:   pi.Name,
-			Language:  "go",
+			Language:  codetypes.LanguageGo,
 			FilePath:  v.FilePath,
 			StartLine: v.StartLine,
 			EndLine:   v.EndLine,
@@ -764,6 +1088,13 @@ func convertPackageInfoToChunks(pi *PackageInfo) []codetypes.CodeChunk {
 			},
 		})
 	}
+
+	// Record build constraints; platform variants are grouped in AnalyzePaths
+	for i := range out {
+		if c := pi.BuildConstraints[out[i].FilePath]; c != "" {
+			out[i].Metadata["build_constraint"] = c
+		}
+	}
 	return out
 }
 
@@ -3,6 +3,7 @@ package golang
 import (
 	"os"
 	"path/filepath"
+	"reflect"
 	"testing"
 
 	"github.com/doITmagic/rag-code-mcp/internal/codetypes"
@@ -125,6 +126,51 @@ func TestCodeAnalyzer_EmptyDirectory(t *testing.T) {
 	}
 }
 
+func TestCodeAnalyzer_ParseErrors(t *testing.T) {
+	tmpDir := t.TempDir()
+	files := map[string]string{
+		"good.go":   "package p\n\nfunc Good() {}\n",
+		"broken.go": "package p\n\n// Keep is declared before the error.\nfunc Keep() int { return 1 }\n\nfunc Bad( {\n",
+		"nopkg.go":  "pakage p\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(tmpDir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	analyzer := NewCodeAnalyzer()
+	chunks, err := analyzer.AnalyzePaths([]string{tmpDir})
+	if err != nil {
+		t.Fatalf("AnalyzePaths failed: %v", err)
+	}
+
+	partial := make(map[string]bool)
+	for _, ch := range chunks {
+		partial[ch.Name] = ch.Partial()
+	}
+	if p, ok := partial["Keep"]; !ok || !p {
+		t.Errorf("Keep should be extracted from the broken file and flagged partial, got %v (found %v)", p, ok)
+	}
+	if p, ok := partial["Good"]; !ok || p {
+		t.Errorf("Good should be extracted and not flagged partial, got %v (found %v)", p, ok)
+	}
+
+	skipped := make(map[string]bool)
+	for _, e := range analyzer.ParseErrors() {
+		skipped[filepath.Base(e.FilePath)] = e.Skipped
+	}
+	if s, ok := skipped["broken.go"]; !ok || s {
+		t.Errorf("broken.go should have recovered parse errors, got skipped=%v (found %v)", s, ok)
+	}
+	if s, ok := skipped["nopkg.go"]; !ok || !s {
+		t.Errorf("nopkg.go should be skipped, got skipped=%v (found %v)", s, ok)
+	}
+	if _, ok := skipped["good.go"]; ok {
+		t.Error("good.go has no parse errors")
+	}
+}
+
 func TestCodeAnalyzer_NonExistentPath(t *testing.T) {
 	analyzer := NewCodeAnalyzer()
 	_, err := analyzer.AnalyzePaths([]string{"/nonexistent/path/that/does/not/exist"})
@@ -237,9 +283,314 @@ func ProcessData(input string) string {
 	}
 }
 
+func TestCodeAnalyzer_Calls(t *testing.T) {
+	tmpDir := t.TempDir()
+	testCode := `package billing
+
+func Charge(s *Store, amount int) error {
+	if err := validate(amount); err != nil {
+		return fmt.Errorf("invalid: %w", err)
+	}
+	validate(amount)
+	return s.Save(amount)
+}
+`
+	if err := os.WriteFile(filepath.Join(tmpDir, "billing.go"), []byte(testCode), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	chunks, err := NewCodeAnalyzer().AnalyzePaths([]string{tmpDir})
+	if err != nil {
+		t.Fatalf("AnalyzePaths failed: %v", err)
+	}
+	var calls []codetypes.CallSite
+	for _, ch := range chunks {
+		if ch.Name == "Charge" {
+			calls, _ = ch.Metadata["calls"].([]codetypes.CallSite)
+		}
+	}
+	want := []codetypes.CallSite{
+		{Name: "validate", Line: 4},
+		{Name: "Errorf", Receiver: "fmt", Line: 5},
+		{Name: "Save", Receiver: "s", Line: 8},
+	}
+	if len(calls) != len(want) {
+		t.Fatalf("calls = %+v, want %+v", calls, want)
+	}
+	for i := range want {
+		if calls[i] != want[i] {
+			t.Errorf("calls[%d] = %+v, want %+v", i, calls[i], want[i])
+		}
+	}
+}
+
 func TestCodeAnalyzer_ImplementsInterface(t *testing.T) {
 	analyzer := NewCodeAnalyzer()
 
 	// Verify that CodeAnalyzer implements codetypes.PathAnalyzer
 	var _ codetypes.PathAnalyzer = analyzer
 }
+
+func TestCodeAnalyzer_BuildConstraints(t *testing.T) {
+	tmpDir := t.TempDir()
+	files := map[string]string{
+		"file.go":             "package osfile\n\n// File is an open file\ntype File struct{ fd uintptr }\n\n// Name returns the file name\nfunc (f *File) Name() string { return \"\" }\n",
+		"file_windows.go":     "package osfile\n\n// Open opens a file with CreateFile\nfunc Open(name string) (uintptr, error) { return 0, nil }\n",
+		"file_unix.go":        "//go:build unix\n\npackage osfile\n\n// Open opens a file with open(2)\nfunc Open(name string) (uintptr, error) { return 0, nil }\n\nconst PathSep = '/'\n",
+		"stat_linux_arm64.go": "package osfile\n\nfunc StatArch() {}\n",
+		"slow.go":             "// +build integration\n\npackage osfile\n\nfunc SlowCheck() {}\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(tmpDir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+
+	chunks, err := NewCodeAnalyzer().AnalyzePaths([]string{tmpDir})
+	if err != nil {
+		t.Fatalf("AnalyzePaths failed: %v", err)
+	}
+
+	constraints := make(map[string]string)
+	opens := 0
+	for _, ch := range chunks {
+		bc, _ := ch.Metadata["build_constraint"].(string)
+		if ch.Name == "Open" {
+			opens++
+			variants, _ := ch.Metadata["variants"].([]string)
+			if len(variants) != 2 || variants[0] != "unix" || variants[1] != "windows" {
+				t.Errorf("Open variants = %v, want [unix windows]", variants)
+			}
+			if filepath.Base(ch.FilePath) == "file_windows.go" && bc != "windows" {
+				t.Errorf("Open in file_windows.go constraint = %q", bc)
+			}
+			continue
+		}
+		constraints[ch.Name] = bc
+	}
+	if opens != 2 {
+		t.Fatalf("expected both platform variants of Open, got %d", opens)
+	}
+
+	want := map[string]string{
+		"File":      "",
+		"Name":      "",
+		"PathSep":   "unix",
+		"StatArch":  "linux && arm64",
+		"SlowCheck": "integration",
+	}
+	for name, bc := range want {
+		got, ok := constraints[name]
+		if !ok {
+			t.Errorf("missing chunk %s", name)
+			continue
+		}
+		if got != bc {
+			t.Errorf("%s build_constraint = %q, want %q", name, got, bc)
+		}
+	}
+}
+
+func TestMatchBuildConstraint(t *testing.T) {
+	tests := []struct {
+		expr, goos, goarch string
+		tags               []string
+		want               bool
+	}{
+		{"", "windows", "", nil, true},
+		{"windows", "windows", "", nil, true},
+		{"windows", "linux", "", nil, false},
+		{"unix", "darwin", "", nil, true},
+		{"linux", "android", "", nil, true},
+		{"linux && arm64", "linux", "", nil, true},
+		{"linux && arm64", "linux", "amd64", nil, false},
+		{"integration", "linux", "", nil, false},
+		{"integration", "", "", []string{"integration"}, true},
+		{"!windows && go1.21", "linux", "", nil, true},
+	}
+	for _, tt := range tests {
+		if got := MatchBuildConstraint(tt.expr, tt.goos, tt.goarch, tt.tags); got != tt.want {
+			t.Errorf("MatchBuildConstraint(%q, %q, %q, %v) = %v, want %v", tt.expr, tt.goos, tt.goarch, tt.tags, got, tt.want)
+		}
+	}
+}
+
+func TestCodeAnalyzer_AssemblyAndCgo(t *testing.T) {
+	tmpDir := t.TempDir()
+	files := map[string]string{
+		"add.go": `package fastmath
+
+// Add is implemented in assembly
+func Add(a, b int64) int64
+
+func sum(xs []int64) int64
+`,
+		"add_amd64.s": `#include "textflag.h"
+
+// func Add(a, b int64) int64
+TEXT ·Add(SB), NOSPLIT, $0-24
+	MOVQ a+0(FP), AX
+	ADDQ b+8(FP), AX
+	MOVQ AX, ret+16(FP)
+	RET
+
+// sum adds a slice
+TEXT ·sum(SB), NOSPLIT, $0-32
+	RET
+`,
+		"clib.go": `package fastmath
+
+/*
+#include <stdlib.h>
+
+// c_add adds in C
+static int c_add(int a, int b) {
+	if (a > b) {
+		return a + b;
+	}
+	return b + a;
+}
+
+int c_external(const char *name);
+*/
+import "C"
+
+//export GoCallback
+func GoCallback(n C.int) C.int { return n }
+`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(tmpDir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+
+	chunks, err := NewCodeAnalyzer().AnalyzePaths([]string{filepath.Join(tmpDir, "add_amd64.s")})
+	if err != nil {
+		t.Fatalf("AnalyzePaths failed: %v", err)
+	}
+
+	asm := make(map[string]codetypes.CodeChunk)
+	cgo := make(map[string]codetypes.CodeChunk)
+	var callback *codetypes.CodeChunk
+	for i, ch := range chunks {
+		switch {
+		case ch.Metadata["assembly"] == true:
+			asm[ch.Name] = ch
+		case ch.Metadata["cgo"] == true:
+			cgo[ch.Name] = ch
+		case ch.Name == "GoCallback":
+			callback = &chunks[i]
+		}
+	}
+
+	add, ok := asm["Add"]
+	if !ok {
+		t.Fatalf("missing assembly chunk for Add; got %+v", asm)
+	}
+	if add.StartLine != 4 || add.EndLine != 8 {
+		t.Errorf("Add lines = %d-%d, want 4-8", add.StartLine, add.EndLine)
+	}
+	if add.Signature != "func Add (a int64, b int64) int64" {
+		t.Errorf("Add signature = %q", add.Signature)
+	}
+	if add.Metadata["frame"] != "$0-24" || add.Metadata["build_constraint"] != "amd64" {
+		t.Errorf("Add metadata = %+v", add.Metadata)
+	}
+	if sum := asm["sum"]; sum.Docstring != "sum adds a slice" || sum.Metadata["go_decl_line"] != 6 {
+		t.Errorf("sum chunk = %+v", sum)
+	}
+
+	cAdd, ok := cgo["c_add"]
+	if !ok {
+		t.Fatalf("missing cgo chunk for c_add; got %+v", cgo)
+	}
+	if cAdd.Signature != "static int c_add(int a, int b)" || cAdd.StartLine != 7 || cAdd.EndLine != 12 {
+		t.Errorf("c_add = %q lines %d-%d", cAdd.Signature, cAdd.StartLine, cAdd.EndLine)
+	}
+	if ext := cgo["c_external"]; ext.StartLine != 14 || ext.EndLine != 14 {
+		t.Errorf("c_external lines = %d-%d, want 14-14", ext.StartLine, ext.EndLine)
+	}
+	if len(cgo) != 2 {
+		t.Errorf("expected 2 cgo functions, got %v", cgo)
+	}
+	if callback == nil || callback.Metadata["cgo_export"] != true {
+		t.Errorf("GoCallback should be marked cgo_export: %+v", callback)
+	}
+}
+
+func TestCodeAnalyzer_Imports(t *testing.T) {
+	tmpDir := t.TempDir()
+	files := map[string]string{
+		"go.mod":     "module example.com/app\n\ngo 1.21\n",
+		"sub/sub.go": "package sub\n\nimport (\n\t\"fmt\"\n\n\t\"example.com/app/store\"\n)\n\nfunc Run() { fmt.Println(store.Name) }\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(tmpDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	analyzer := NewCodeAnalyzer()
+	if _, err := analyzer.AnalyzePaths([]string{filepath.Join(tmpDir, "sub")}); err != nil {
+		t.Fatalf("AnalyzePaths failed: %v", err)
+	}
+	imports := analyzer.Imports()
+	if len(imports) != 1 {
+		t.Fatalf("expected imports of 1 file, got %d", len(imports))
+	}
+	if imports[0].Package != "example.com/app/sub" {
+		t.Errorf("package = %q, want the import path example.com/app/sub", imports[0].Package)
+	}
+	if want := []string{"fmt", "example.com/app/store"}; !reflect.DeepEqual(imports[0].Imports, want) {
+		t.Errorf("imports = %v, want %v", imports[0].Imports, want)
+	}
+}
+
+func TestCodeAnalyzer_IncludeTests(t *testing.T) {
+	tmpDir := t.TempDir()
+	files := map[string]string{
+		"store.go":      "package store\n\ntype Store struct{}\n\nfunc (s *Store) Save() error { return nil }\n",
+		"store_test.go": "package store\n\nimport \"testing\"\n\n// TestSave saves\nfunc TestSave(t *testing.T) {\n\ts := &Store{}\n\tif err := s.Save(); err != nil {\n\t\tt.Fatal(err)\n\t}\n}\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(tmpDir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	chunks, err := NewCodeAnalyzer().AnalyzePaths([]string{tmpDir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, ch := range chunks {
+		if ch.Name == "TestSave" {
+			t.Fatal("test function indexed without includeTests")
+		}
+	}
+
+	chunks, err = NewCodeAnalyzerWithOptions(true).AnalyzePaths([]string{tmpDir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var test *codetypes.CodeChunk
+	for i := range chunks {
+		if chunks[i].Name == "TestSave" {
+			test = &chunks[i]
+		}
+	}
+	if test == nil {
+		t.Fatal("TestSave not indexed with includeTests")
+	}
+	if test.StartLine != 5 || test.EndLine != 11 || test.Package != "store" || test.Metadata["is_test"] != true {
+		t.Errorf("TestSave chunk = %s:%d-%d package %s metadata %v", test.FilePath, test.StartLine, test.EndLine, test.Package, test.Metadata)
+	}
+	calls, _ := test.Metadata["calls"].([]codetypes.CallSite)
+	if len(calls) < 1 || calls[0].Name != "Save" || calls[0].Receiver != "s" || calls[0].Line != 8 {
+		t.Errorf("calls = %+v", calls)
+	}
+}
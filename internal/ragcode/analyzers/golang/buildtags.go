@@ -0,0 +1,241 @@
+package golang
+
+import (
+	"go/ast"
+	"go/build/constraint"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/doITmagic/rag-code-mcp/internal/codetypes"
+)
+
+// knownOS and knownArch are the GOOS and GOARCH values recognized in file
+// name suffixes (foo_windows.go, foo_linux_arm64.go), as in go/build.
+var knownOS = map[string]bool{
+	"aix": true, "android": true, "darwin": true, "dragonfly": true, "freebsd": true,
+	"hurd": true, "illumos": true, "ios": true, "js": true, "linux": true, "nacl": true,
+	"netbsd": true, "openbsd": true, "plan9": true, "solaris": true, "wasip1": true,
+	"windows": true, "zos": true,
+}
+
+var knownArch = map[string]bool{
+	"386": true, "amd64": true, "amd64p32": true, "arm": true, "armbe": true, "arm64": true,
+	"arm64be": true, "loong64": true, "mips": true, "mipsle": true, "mips64": true,
+	"mips64le": true, "mips64p32": true, "mips64p32le": true, "ppc": true, "ppc64": true,
+	"ppc64le": true, "riscv": true, "riscv64": true, "s390": true, "s390x": true,
+	"sparc": true, "sparc64": true, "wasm": true,
+}
+
+// DefaultVariant labels the unconstrained definition in the variants of a
+// symbol
+const DefaultVariant = "default"
+
+// fileConstraint returns the build constraint of a parsed file: its
+// //go:build (or legacy // +build) lines combined with the GOOS/GOARCH
+// suffix of its name. Nil means the file is always built.
+func fileConstraint(path string, f *ast.File) constraint.Expr {
+	var lines []string
+	for _, group := range f.Comments {
+		// Build constraints must appear before the package clause
+		if group.Pos() >= f.Package {
+			break
+		}
+		for _, c := range group.List {
+			lines = append(lines, c.Text)
+		}
+	}
+	return headerConstraint(path, lines)
+}
+
+// sourceConstraint returns the build constraint of a non-Go source file
+// (assembly), whose constraint lines precede the first other line
+func sourceConstraint(path string, lines []string) constraint.Expr {
+	var header []string
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" {
+			continue
+		}
+		if !strings.HasPrefix(trimmed, "//") {
+			break
+		}
+		header = append(header, trimmed)
+	}
+	return headerConstraint(path, header)
+}
+
+// headerConstraint combines the constraint lines of a file header with its
+// file name constraint
+func headerConstraint(path string, lines []string) constraint.Expr {
+	var expr constraint.Expr
+	and := func(x constraint.Expr) {
+		if expr == nil {
+			expr = x
+		} else {
+			expr = &constraint.AndExpr{X: expr, Y: x}
+		}
+	}
+
+	var goBuild constraint.Expr
+	var plusBuild []constraint.Expr
+	for _, line := range lines {
+		if !constraint.IsGoBuild(line) && !constraint.IsPlusBuild(line) {
+			continue
+		}
+		x, err := constraint.Parse(line)
+		if err != nil {
+			continue
+		}
+		if constraint.IsGoBuild(line) {
+			goBuild = x
+		} else {
+			plusBuild = append(plusBuild, x)
+		}
+	}
+	// //go:build supersedes // +build lines when both are present
+	if goBuild != nil {
+		and(goBuild)
+	} else {
+		for _, x := range plusBuild {
+			and(x)
+		}
+	}
+
+	if x := fileNameConstraint(filepath.Base(path)); x != nil {
+		and(x)
+	}
+	return expr
+}
+
+// fileNameConstraint derives the implicit constraint of a _GOOS, _GOARCH or
+// _GOOS_GOARCH file name suffix
+func fileNameConstraint(name string) constraint.Expr {
+	name = strings.TrimSuffix(strings.TrimSuffix(name, ".go"), ".s")
+	name = strings.TrimSuffix(name, "_test")
+	i := strings.Index(name, "_")
+	if i < 0 {
+		return nil
+	}
+	parts := strings.Split(name[i:], "_")
+	n := len(parts)
+	if n >= 2 && knownOS[parts[n-2]] && knownArch[parts[n-1]] {
+		return &constraint.AndExpr{X: &constraint.TagExpr{Tag: parts[n-2]}, Y: &constraint.TagExpr{Tag: parts[n-1]}}
+	}
+	if knownOS[parts[n-1]] || knownArch[parts[n-1]] {
+		return &constraint.TagExpr{Tag: parts[n-1]}
+	}
+	return nil
+}
+
+// constraintString renders a constraint for metadata ("" when unconstrained)
+func constraintString(x constraint.Expr) string {
+	if x == nil {
+		return ""
+	}
+	return x.String()
+}
+
+// groupVariants records the build constraints of all definitions of a
+// symbol on each of them (metadata "variants") when a package defines it in
+// more than one constrained file. Assembly implementations are grouped
+// separately from Go declarations.
+func groupVariants(chunks []codetypes.CodeChunk) {
+	groups := make(map[string][]int)
+	for i, ch := range chunks {
+		receiver, _ := ch.Metadata["receiver"].(string)
+		key := ch.Type + ":" + receiver + "." + ch.Name
+		if asm, _ := ch.Metadata["assembly"].(bool); asm {
+			key = "asm:" + key
+		}
+		groups[key] = append(groups[key], i)
+	}
+	for _, idx := range groups {
+		if len(idx) < 2 {
+			continue
+		}
+		constrained := false
+		var variants []string
+		for _, i := range idx {
+			v, _ := chunks[i].Metadata["build_constraint"].(string)
+			if v == "" {
+				v = DefaultVariant
+			} else {
+				constrained = true
+			}
+			variants = append(variants, v)
+		}
+		if !constrained {
+			continue
+		}
+		sort.Strings(variants)
+		for _, i := range idx {
+			chunks[i].Metadata["variants"] = variants
+		}
+	}
+}
+
+// unixOS are the GOOS values satisfying the "unix" build tag
+var unixOS = map[string]bool{
+	"aix": true, "android": true, "darwin": true, "dragonfly": true, "freebsd": true,
+	"hurd": true, "illumos": true, "ios": true, "linux": true, "netbsd": true,
+	"openbsd": true, "solaris": true,
+}
+
+// impliedOS are the GOOS values that also satisfy another one's tag
+var impliedOS = map[string]string{"android": "linux", "ios": "darwin", "illumos": "solaris"}
+
+// MatchBuildConstraint reports whether a file with the given build
+// constraint expression is built for goos, goarch and the extra tags. An
+// empty goos or goarch matches any value; go1.x release tags are always
+// satisfied. Unparseable expressions match.
+func MatchBuildConstraint(expr, goos, goarch string, tags []string) bool {
+	if strings.TrimSpace(expr) == "" {
+		return true
+	}
+	x, err := constraint.Parse("//go:build " + expr)
+	if err != nil {
+		return true
+	}
+	extra := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		extra[tag] = true
+	}
+
+	oses := []string{goos}
+	if goos == "" {
+		oses = sortedKeys(knownOS)
+	}
+	arches := []string{goarch}
+	if goarch == "" {
+		arches = sortedKeys(knownArch)
+	}
+	for _, os := range oses {
+		for _, arch := range arches {
+			ok := x.Eval(func(tag string) bool {
+				switch {
+				case tag == os || tag == arch || extra[tag]:
+					return true
+				case tag == "unix":
+					return unixOS[os]
+				case strings.HasPrefix(tag, "go1."):
+					return true
+				}
+				return impliedOS[os] == tag
+			})
+			if ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,305 @@
+package golang
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/doITmagic/rag-code-mcp/internal/codetypes"
+)
+
+var (
+	// asmTextRe matches the TEXT directive of a Go assembly function:
+	// "TEXT ·add(SB), NOSPLIT, $0-24" or "TEXT runtime·memmove<ABIInternal>(SB)"
+	asmTextRe = regexp.MustCompile(`^TEXT\s+\S*?·(\w+)(?:<\w+>)?\(SB\)\s*(?:,\s*(.*?))?\s*$`)
+	// asmFrameRe matches the frame and argument size of a TEXT directive
+	asmFrameRe = regexp.MustCompile(`\$-?\d+(?:-\d+)?`)
+	// asmEndRe matches the directives that end an assembly function
+	asmEndRe = regexp.MustCompile(`^(?:TEXT|DATA|GLOBL)\s`)
+
+	// cFuncRe matches a C function declaration or definition at the top
+	// level of a cgo preamble: "static int add(int a, int b) {"
+	cFuncRe = regexp.MustCompile(`^\s*((?:[A-Za-z_]\w*[\s*]+)+?)\**\s*([A-Za-z_]\w*)\s*\(([^)]*)\)\s*(\{|;|$)`)
+	// cgoExportRe matches a //export directive
+	cgoExportRe = regexp.MustCompile(`^//export\s+(\w+)`)
+)
+
+// cKeywords are words that look like a return type in cFuncRe matches of
+// statements and macros
+var cKeywords = map[string]bool{
+	"return": true, "if": true, "while": true, "for": true, "switch": true,
+	"else": true, "sizeof": true, "case": true, "do": true, "typedef": true,
+	"define": true,
+}
+
+// bodylessDecl is a Go function declared without a body, implemented in
+// assembly or linked in with go:linkname
+type bodylessDecl struct {
+	signature string
+	file      string
+	line      int
+}
+
+// lowLevelChunks indexes what the Go analysis cannot see: functions of Go
+// assembly files (*.s) and C functions of cgo preambles. They are indexed
+// minimally (location, signature, comments) so lookups of low-level
+// functions find them. Go functions exported to C with //export are marked
+// with metadata "cgo_export".
+func (ca *CodeAnalyzer) lowLevelChunks(dir string, pi *PackageInfo, goChunks []codetypes.CodeChunk) []codetypes.CodeChunk {
+	var out []codetypes.CodeChunk
+
+	decls := make(map[string]bodylessDecl)
+	exports := make(map[string]bool)
+	goFiles, _ := filepath.Glob(filepath.Join(dir, "*.go"))
+	for _, file := range goFiles {
+		if strings.HasSuffix(file, "_test.go") {
+			continue
+		}
+		fset := token.NewFileSet()
+		f, err := parser.ParseFile(fset, file, nil, parser.ParseComments)
+		if err != nil {
+			continue
+		}
+		for _, d := range f.Decls {
+			if fn, ok := d.(*ast.FuncDecl); ok && fn.Body == nil && fn.Recv == nil {
+				decls[fn.Name.Name] = bodylessDecl{
+					signature: ca.getFunctionSignature(fn),
+					file:      file,
+					line:      fset.Position(fn.Pos()).Line,
+				}
+			}
+		}
+		if !importsC(f) {
+			continue
+		}
+		for _, group := range f.Comments {
+			for _, c := range group.List {
+				if m := cgoExportRe.FindStringSubmatch(c.Text); m != nil {
+					exports[file+":"+m[1]] = true
+				}
+			}
+		}
+		out = append(out, cgoPreambleChunks(fset, file, f, pi)...)
+	}
+
+	for i := range goChunks {
+		ch := &goChunks[i]
+		if ch.Type == "function" && exports[ch.FilePath+":"+ch.Name] {
+			ch.Metadata["cgo_export"] = true
+		}
+	}
+
+	asmFiles, _ := filepath.Glob(filepath.Join(dir, "*.s"))
+	for _, file := range asmFiles {
+		out = append(out, asmChunks(file, pi, decls)...)
+	}
+	return out
+}
+
+// asmChunks extracts the functions (TEXT ·name(SB)) of a Go assembly file.
+// The Go declaration of a function, when found, becomes its signature.
+func asmChunks(path string, pi *PackageInfo, decls map[string]bodylessDecl) []codetypes.CodeChunk {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	lines := strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n")
+	bc := constraintString(sourceConstraint(path, lines))
+
+	var out []codetypes.CodeChunk
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimSpace(lines[i])
+		m := asmTextRe.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		name := m[1]
+
+		// The function runs until the next TEXT, DATA or GLOBL directive;
+		// trailing comments belong to what follows
+		end := i
+		for j := i + 1; j < len(lines); j++ {
+			next := strings.TrimSpace(lines[j])
+			if asmEndRe.MatchString(next) {
+				break
+			}
+			if next != "" && !strings.HasPrefix(next, "//") {
+				end = j
+			}
+		}
+
+		// Comment lines directly above the TEXT directive document it
+		var doc []string
+		for j := i - 1; j >= 0; j-- {
+			prev := strings.TrimSpace(lines[j])
+			if !strings.HasPrefix(prev, "//") || constraintLine(prev) {
+				break
+			}
+			doc = append([]string{strings.TrimSpace(strings.TrimPrefix(prev, "//"))}, doc...)
+		}
+
+		metadata := map[string]any{
+			"assembly":      true,
+			"asm_directive": line,
+		}
+		if frame := asmFrameRe.FindString(m[2]); frame != "" {
+			metadata["frame"] = frame
+		}
+		signature := line
+		if decl, ok := decls[name]; ok {
+			signature = decl.signature
+			metadata["go_decl_file"] = decl.file
+			metadata["go_decl_line"] = decl.line
+		}
+		if bc != "" {
+			metadata["build_constraint"] = bc
+		}
+
+		out = append(out, codetypes.CodeChunk{
+			Type:      "function",
+			Name:      name,
+			Package:   pi.Name,
+			Language:  codetypes.LanguageGo,
+			FilePath:  path,
+			StartLine: i + 1,
+			EndLine:   end + 1,
+			Signature: signature,
+			Docstring: strings.Join(doc, "\n"),
+			Code:      strings.Join(lines[i:end+1], "\n"),
+			Metadata:  metadata,
+		})
+		i = end
+	}
+	return out
+}
+
+func constraintLine(line string) bool {
+	return strings.HasPrefix(line, "//go:build") || strings.HasPrefix(line, "// +build")
+}
+
+// importsC reports whether a file uses cgo
+func importsC(f *ast.File) bool {
+	for _, imp := range f.Imports {
+		if imp.Path.Value == `"C"` {
+			return true
+		}
+	}
+	return false
+}
+
+// cgoPreambleChunks extracts the C functions declared or defined in the cgo
+// preamble (the comment above import "C") of a file
+func cgoPreambleChunks(fset *token.FileSet, path string, f *ast.File, pi *PackageInfo) []codetypes.CodeChunk {
+	var preamble *ast.CommentGroup
+	for _, d := range f.Decls {
+		gen, ok := d.(*ast.GenDecl)
+		if !ok || gen.Tok != token.IMPORT {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			imp := spec.(*ast.ImportSpec)
+			if imp.Path.Value != `"C"` {
+				continue
+			}
+			preamble = imp.Doc
+			if preamble == nil && len(gen.Specs) == 1 {
+				preamble = gen.Doc
+			}
+		}
+	}
+	if preamble == nil {
+		return nil
+	}
+
+	// Rebuild the preamble source with its file line numbers
+	type cLine struct {
+		text string
+		line int
+	}
+	var src []cLine
+	for _, c := range preamble.List {
+		start := fset.Position(c.Pos()).Line
+		if strings.HasPrefix(c.Text, "//") {
+			src = append(src, cLine{strings.TrimPrefix(c.Text, "//"), start})
+			continue
+		}
+		body := strings.TrimSuffix(strings.TrimPrefix(c.Text, "/*"), "*/")
+		for i, text := range strings.Split(body, "\n") {
+			src = append(src, cLine{text, start + i})
+		}
+	}
+
+	var out []codetypes.CodeChunk
+	depth := 0
+	for i := 0; i < len(src); i++ {
+		text := src[i].text
+		if depth > 0 || strings.HasPrefix(strings.TrimSpace(text), "#") {
+			depth += strings.Count(text, "{") - strings.Count(text, "}")
+			continue
+		}
+		m := cFuncRe.FindStringSubmatch(text)
+		if m == nil || cKeywords[strings.Fields(m[1])[0]] {
+			depth += strings.Count(text, "{") - strings.Count(text, "}")
+			continue
+		}
+		name := m[2]
+		prototype := strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(text), "{"))
+		prototype = strings.TrimSuffix(prototype, ";")
+
+		// Definitions run until their braces balance
+		end := i
+		depth = strings.Count(text, "{") - strings.Count(text, "}")
+		if depth > 0 || (m[4] == "" && i+1 < len(src) && strings.HasPrefix(strings.TrimSpace(src[i+1].text), "{")) {
+			for end = i + 1; end < len(src); end++ {
+				depth += strings.Count(src[end].text, "{") - strings.Count(src[end].text, "}")
+				if depth <= 0 {
+					break
+				}
+			}
+			if end >= len(src) {
+				end = len(src) - 1
+			}
+			depth = 0
+		}
+
+		// Comment lines directly above document the function
+		var doc []string
+		for j := i - 1; j >= 0; j-- {
+			prev := strings.TrimSpace(src[j].text)
+			if !strings.HasPrefix(prev, "//") {
+				break
+			}
+			doc = append([]string{strings.TrimSpace(strings.TrimPrefix(prev, "//"))}, doc...)
+		}
+		doc = append(doc, "C function callable from Go as C."+name)
+
+		metadata := map[string]any{"cgo": true}
+		if bc := pi.BuildConstraints[path]; bc != "" {
+			metadata["build_constraint"] = bc
+		}
+
+		code := make([]string, 0, end-i+1)
+		for _, l := range src[i : end+1] {
+			code = append(code, l.text)
+		}
+		out = append(out, codetypes.CodeChunk{
+			Type:      "function",
+			Name:      name,
+			Package:   pi.Name,
+			Language:  codetypes.LanguageGo,
+			FilePath:  path,
+			StartLine: src[i].line,
+			EndLine:   src[end].line,
+			Signature: prototype,
+			Docstring: strings.Join(doc, "\n"),
+			Code:      strings.Join(code, "\n"),
+			Metadata:  metadata,
+		})
+		i = end
+	}
+	return out
+}
@@ -0,0 +1,83 @@
+package golang
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+
+	"github.com/doITmagic/rag-code-mcp/internal/codetypes"
+)
+
+// testChunks indexes the functions and methods of the _test.go files of dir:
+// tests, benchmarks, examples and their helpers, with their calls. go/doc
+// leaves test files out of packages, so they are read from the syntax tree.
+func (ca *CodeAnalyzer) testChunks(dir string) []codetypes.CodeChunk {
+	files, _ := filepath.Glob(filepath.Join(dir, "*_test.go"))
+	var out []codetypes.CodeChunk
+	for _, file := range files {
+		fset := token.NewFileSet()
+		f, err := parser.ParseFile(fset, file, nil, parser.ParseComments)
+		if f == nil || len(f.Decls) == 0 {
+			continue
+		}
+		if err != nil {
+			ca.recordParseError(file, err, false)
+		}
+		ca.fset = fset // extractCalls reads lines from ca.fset
+		for _, d := range f.Decls {
+			fn, ok := d.(*ast.FuncDecl)
+			if !ok || fn.Body == nil {
+				continue
+			}
+			start := fset.Position(fn.Pos()).Line
+			if fn.Doc != nil {
+				start = fset.Position(fn.Doc.Pos()).Line
+			}
+			end := fset.Position(fn.End()).Line
+			code, _ := ca.extractCodeFromFile(file, start, end)
+
+			kind, receiver := "function", ""
+			if fn.Recv != nil && len(fn.Recv.List) > 0 {
+				kind, receiver = "method", receiverTypeName(fn.Recv.List[0].Type)
+			}
+			out = append(out, codetypes.CodeChunk{
+				Type:      kind,
+				Name:      fn.Name.Name,
+				Package:   f.Name.Name,
+				Language:  codetypes.LanguageGo,
+				FilePath:  file,
+				StartLine: start,
+				EndLine:   end,
+				Signature: ca.getFunctionSignature(fn),
+				Docstring: cleanDoc(fn.Doc.Text()),
+				Code:      code,
+				Metadata: map[string]any{
+					"receiver":  receiver,
+					"is_method": receiver != "",
+					"params":    ca.extractParameters(fn.Type.Params),
+					"returns":   ca.extractReturns(fn.Type.Results),
+					"calls":     ca.extractCalls(fn.Body),
+					"is_test":   true,
+				},
+			})
+		}
+	}
+	return out
+}
+
+// receiverTypeName is the type name of a method receiver: T for T, *T and
+// generic T[K]
+func receiverTypeName(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		return receiverTypeName(t.X)
+	case *ast.IndexExpr:
+		return receiverTypeName(t.X)
+	case *ast.IndexListExpr:
+		return receiverTypeName(t.X)
+	case *ast.Ident:
+		return t.Name
+	}
+	return ""
+}
@@ -13,6 +13,9 @@ type PackageInfo struct {
 	Variables   []VariableInfo `json:"variables"`
 	Examples    []ExampleInfo  `json:"examples"`
 	Imports     []string       `json:"imports"`
+	// BuildConstraints maps constrained files to their build constraint
+	// (//go:build lines and _GOOS/_GOARCH file name suffixes)
+	BuildConstraints map[string]string `json:"build_constraints,omitempty"`
 }
 
 // FunctionInfo describes a function or method
@@ -26,6 +29,7 @@ type FunctionInfo struct {
 	IsExported  bool                   `json:"is_exported"`
 	IsMethod    bool                   `json:"is_method"`
 	Receiver    string                 `json:"receiver,omitempty"`
+	Calls       []codetypes.CallSite   `json:"calls,omitempty"`
 	FilePath    string                 `json:"file_path,omitempty"`
 	StartLine   int                    `json:"start_line,omitempty"`
 	EndLine     int                    `json:"end_line,omitempty"`
@@ -107,7 +107,7 @@ func (a *CodeAnalyzer) analyzeFile(path string) ([]codetypes.CodeChunk, error) {
 	chunk := codetypes.CodeChunk{
 		Type:      "file",
 		Name:      name,
-		Language:  "html",
+		Language:  codetypes.LanguageHTML,
 		FilePath:  path,
 		Signature: title,
 		Docstring: bodyText,
@@ -165,7 +165,7 @@ func buildSections(doc *goquery.Document, path, pageTitle string) []codetypes.Co
 		chunk := codetypes.CodeChunk{
 			Type:      "section",
 			Name:      title,
-			Language:  "html",
+			Language:  codetypes.LanguageHTML,
 			FilePath:  path,
 			Signature: fmt.Sprintf("<h%d>%s</h%d>", level, title, level),
 			Docstring: bodyText,
@@ -5,6 +5,7 @@ import (
 	"path/filepath"
 	"testing"
 
+	"github.com/doITmagic/rag-code-mcp/internal/codetypes"
 	"github.com/stretchr/testify/require"
 )
 
@@ -33,7 +34,7 @@ func TestCodeAnalyzer_ExtractsSections(t *testing.T) {
 	require.Len(t, chunks, 2)
 
 	first := chunks[0]
-	require.Equal(t, "html", first.Language)
+	require.Equal(t, codetypes.LanguageHTML, first.Language)
 	require.Equal(t, "section", first.Type)
 	require.Equal(t, "Introducere", first.Name)
 	require.Contains(t, first.Code, "Paragraf introductiv")
@@ -1,6 +1,7 @@
 package php
 
 import (
+	"bytes"
 	"fmt"
 	"io/fs"
 	"os"
@@ -22,6 +23,9 @@ import (
 type CodeAnalyzer struct {
 	currentNamespace string
 	packages         map[string]*PackageInfo
+	projectRoots     map[string]string // directory -> project root, see globalPackage
+	parseErrors      []codetypes.ParseError
+	imports          []codetypes.FileImports
 }
 
 // NewCodeAnalyzer creates a new PHP code analyzer
@@ -45,6 +49,8 @@ func (ca *CodeAnalyzer) GetPackages() []*PackageInfo {
 func (ca *CodeAnalyzer) AnalyzePaths(paths []string) ([]codetypes.CodeChunk, error) {
 	// Reset state for global analysis
 	ca.packages = make(map[string]*PackageInfo)
+	ca.parseErrors = nil
+	ca.imports = nil
 
 	for _, root := range paths {
 		// Check if it's a file or directory
@@ -104,13 +110,31 @@ func (ca *CodeAnalyzer) AnalyzePaths(paths []string) ([]codetypes.CodeChunk, err
 		}
 	}
 
-	return ca.convertToChunks(), nil
+	chunks := ca.convertToChunks()
+	codetypes.MarkPartial(chunks, ca.parseErrors)
+	return chunks, nil
+}
+
+// ParseErrors implements codetypes.ParseErrorReporter. The PHP parser
+// recovers from most syntax errors, so files with errors are usually still
+// indexed, their chunks flagged partial.
+func (ca *CodeAnalyzer) ParseErrors() []codetypes.ParseError {
+	return ca.parseErrors
+}
+
+// Imports implements codetypes.ImportReporter: the names each file of the
+// last AnalyzePaths call imports with use statements. Files are in their
+// namespace, or the global\<dir> package of list_package_exports.
+func (ca *CodeAnalyzer) Imports() []codetypes.FileImports {
+	return ca.imports
 }
 
 // AnalyzeFile analyzes a single PHP file
 func (ca *CodeAnalyzer) AnalyzeFile(filePath string) ([]codetypes.CodeChunk, error) {
 	// Reset state for this file
 	ca.packages = make(map[string]*PackageInfo)
+	ca.parseErrors = nil
+	ca.imports = nil
 
 	content, err := os.ReadFile(filePath)
 	if err != nil {
@@ -121,16 +145,31 @@ func (ca *CodeAnalyzer) AnalyzeFile(filePath string) ([]codetypes.CodeChunk, err
 		return nil, err
 	}
 
-	return ca.convertToChunks(), nil
+	chunks := ca.convertToChunks()
+	codetypes.MarkPartial(chunks, ca.parseErrors)
+	return chunks, nil
 }
 
 // parseAndCollect parses PHP source and collects symbols into ca.packages
 func (ca *CodeAnalyzer) parseAndCollect(filePath string, content []byte) error {
 	// Parse PHP source
 	rootNode, parserErrors, err := ca.parsePHPSource(content)
+	if err == nil && rootNode == nil {
+		if rootNode = ca.parsePrefix(content, parserErrors); rootNode == nil {
+			err = fmt.Errorf("no statements recovered from %d syntax error(s)", len(parserErrors))
+		}
+	}
 	if err != nil {
+		ca.parseErrors = append(ca.parseErrors, codetypes.ParseError{FilePath: filePath, Message: err.Error(), Skipped: true})
 		return fmt.Errorf("failed to parse PHP: %w", err)
 	}
+	for _, e := range parserErrors {
+		pe := codetypes.ParseError{FilePath: filePath, Message: e.Msg}
+		if e.Pos != nil {
+			pe.Line = e.Pos.StartLine
+		}
+		ca.parseErrors = append(ca.parseErrors, pe)
+	}
 
 	// Log parser errors but continue
 	if len(parserErrors) > 0 {
@@ -157,6 +196,12 @@ func (ca *CodeAnalyzer) parseAndCollect(filePath string, content []byte) error {
 	}
 
 	traverser.NewTraverser(collector).Traverse(rootNode)
+	ca.imports = append(ca.imports, codetypes.FileImports{
+		FilePath: filePath,
+		Language: codetypes.LanguagePHP,
+		Package:  collector.packageName(),
+		Imports:  collector.uses,
+	})
 	return nil
 }
 
@@ -178,6 +223,35 @@ func (ca *CodeAnalyzer) parsePHPSource(content []byte) (ast.Vertex, []*errors.Er
 	return rootNode, parserErrors, nil
 }
 
+// maxPrefixAttempts bounds the re-parses of parsePrefix
+const maxPrefixAttempts = 20
+
+// parsePrefix is the error tolerance fallback for files the PHP parser
+// returns no tree for (e.g. a declaration cut off while typing): it parses
+// the source above the first syntax error, dropping one more line whenever
+// that still fails, so the declarations before the error are kept. Line
+// numbers are those of the whole file.
+func (ca *CodeAnalyzer) parsePrefix(content []byte, errs []*errors.Error) ast.Vertex {
+	lines := bytes.SplitAfter(content, []byte("\n"))
+	cut := len(lines)
+	for attempt := 0; attempt < maxPrefixAttempts; attempt++ {
+		if len(errs) > 0 && errs[0].Pos != nil && errs[0].Pos.StartLine-1 < cut {
+			cut = errs[0].Pos.StartLine - 1
+		} else {
+			cut--
+		}
+		if cut <= 0 {
+			return nil
+		}
+		root, perrs, err := ca.parsePHPSource(bytes.Join(lines[:cut], nil))
+		if err == nil && root != nil {
+			return root
+		}
+		errs = perrs
+	}
+	return nil
+}
+
 // symbolCollector is a visitor that collects PHP symbols
 type symbolCollector struct {
 	visitor.Null // Embedded - provides default implementations for all visitor methods
@@ -186,6 +260,7 @@ type symbolCollector struct {
 	fileContent  []byte            // Source code content for extracting code snippets
 	currentClass *ClassInfo        // Track current class being processed
 	imports      map[string]string // Track imports for the current file
+	uses         []string          // Names imported by the file, in order
 }
 
 // StmtNamespace handles namespace declarations
@@ -219,6 +294,9 @@ func (v *symbolCollector) StmtUse(n *ast.StmtUseList) {
 			if alias != "" {
 				v.imports[alias] = name
 			}
+			if name != "" {
+				v.uses = append(v.uses, name)
+			}
 		}
 	}
 }
@@ -231,10 +309,7 @@ func (v *symbolCollector) StmtClass(n *ast.StmtClass) {
 	}
 
 	// Get or create package info
-	pkgName := v.analyzer.currentNamespace
-	if pkgName == "" {
-		pkgName = "global"
-	}
+	pkgName := v.packageName()
 
 	pkg := v.analyzer.getOrCreatePackage(pkgName)
 
@@ -252,21 +327,17 @@ func (v *symbolCollector) StmtClass(n *ast.StmtClass) {
 		Imports:    v.copyImports(),
 	}
 
-	// Extract code from file content (LIMIT to first 50 lines for better embedding matching)
+	// Extract code from file content. Large classes are capped at index
+	// time (rag_code.max_chunk_lines), not here.
 	if v.fileContent != nil && n.Position != nil {
-		endLine := n.Position.EndLine
-		// For large classes, only extract header/summary (first ~50 lines)
-		maxLines := 50
-		if endLine-n.Position.StartLine > maxLines {
-			endLine = n.Position.StartLine + maxLines
-		}
-		classInfo.Code = extractCodeFromContent(v.fileContent, n.Position.StartLine, endLine)
+		classInfo.Code = extractCodeFromContent(v.fileContent, n.Position.StartLine, n.Position.EndLine)
 	}
 
 	// Extract PHPDoc comment from ClassTkn
 	if n.ClassTkn != nil {
 		phpDoc := extractPHPDocFromToken(n.ClassTkn)
 		classInfo.Description = phpDoc.Description
+		classInfo.Deprecated = deprecationNote(phpDoc)
 	}
 
 	// Extract extends
@@ -337,6 +408,7 @@ func (v *symbolCollector) StmtClassMethod(n *ast.StmtClassMethod) {
 	// Extract PHPDoc from modifiers
 	phpDoc := v.extractPHPDocFromModifiers(n.Modifiers)
 	methodInfo.Description = phpDoc.Description
+	methodInfo.Deprecated = deprecationNote(phpDoc)
 	methodInfo.Signature = v.buildMethodSignature(methodName, n.Params, n.ReturnType, v.extractVisibility(n.Modifiers))
 
 	// Merge PHPDoc returns with type hint
@@ -374,10 +446,7 @@ func (v *symbolCollector) StmtFunction(n *ast.StmtFunction) {
 		return
 	}
 
-	pkgName := v.analyzer.currentNamespace
-	if pkgName == "" {
-		pkgName = "global"
-	}
+	pkgName := v.packageName()
 
 	pkg := v.analyzer.getOrCreatePackage(pkgName)
 
@@ -388,11 +457,19 @@ func (v *symbolCollector) StmtFunction(n *ast.StmtFunction) {
 		ReturnType: v.extractTypeNameString(n.ReturnType),
 		FilePath:   v.filePath,
 	}
+	if n.Position != nil {
+		funcInfo.StartLine = n.Position.StartLine
+		funcInfo.EndLine = n.Position.EndLine
+		if v.fileContent != nil {
+			funcInfo.Code = extractCodeFromContent(v.fileContent, n.Position.StartLine, n.Position.EndLine)
+		}
+	}
 
 	// Extract PHPDoc from FunctionTkn
 	if n.FunctionTkn != nil {
 		phpDoc := extractPHPDocFromToken(n.FunctionTkn)
 		funcInfo.Description = phpDoc.Description
+		funcInfo.Deprecated = deprecationNote(phpDoc)
 		funcInfo.Signature = v.buildMethodSignature(funcName, n.Params, n.ReturnType, "")
 
 		if len(phpDoc.Returns) > 0 {
@@ -473,6 +550,11 @@ func (v *symbolCollector) StmtClassConstList(n *ast.StmtClassConstList) {
 				Type:       "", // PHP constants don't have explicit types
 				Value:      v.extractConstValue(stmtConst.Expr),
 				Visibility: visibility,
+				FilePath:   v.filePath,
+			}
+			if stmtConst.Position != nil {
+				constInfo.StartLine = stmtConst.Position.StartLine
+				constInfo.EndLine = stmtConst.Position.EndLine
 			}
 
 			v.currentClass.Constants = append(v.currentClass.Constants, constInfo)
@@ -487,20 +569,25 @@ func (v *symbolCollector) StmtInterface(n *ast.StmtInterface) {
 		return
 	}
 
-	pkgName := v.analyzer.currentNamespace
-	if pkgName == "" {
-		pkgName = "global"
-	}
+	pkgName := v.packageName()
 
 	pkg := v.analyzer.getOrCreatePackage(pkgName)
 
 	interfaceInfo := InterfaceInfo{
 		Name:      interfaceName,
 		Namespace: pkgName,
+		FullName:  v.buildFullName(interfaceName),
 		Methods:   []MethodInfo{},
 		Extends:   []string{},
 		FilePath:  v.filePath,
 	}
+	if n.Position != nil {
+		interfaceInfo.StartLine = n.Position.StartLine
+		interfaceInfo.EndLine = n.Position.EndLine
+		if v.fileContent != nil {
+			interfaceInfo.Code = extractCodeFromContent(v.fileContent, n.Position.StartLine, n.Position.EndLine)
+		}
+	}
 
 	// Extract PHPDoc from InterfaceTkn
 	if n.InterfaceTkn != nil {
@@ -530,8 +617,9 @@ func (v *symbolCollector) StmtInterface(n *ast.StmtInterface) {
 		}
 	}
 
-	// Transfer collected methods to interface
+	// Transfer collected methods and constants to interface
 	interfaceInfo.Methods = v.currentClass.Methods
+	interfaceInfo.Constants = v.currentClass.Constants
 
 	// Add interface to package
 	pkg.Interfaces = append(pkg.Interfaces, interfaceInfo)
@@ -547,20 +635,25 @@ func (v *symbolCollector) StmtTrait(n *ast.StmtTrait) {
 		return
 	}
 
-	pkgName := v.analyzer.currentNamespace
-	if pkgName == "" {
-		pkgName = "global"
-	}
+	pkgName := v.packageName()
 
 	pkg := v.analyzer.getOrCreatePackage(pkgName)
 
 	traitInfo := TraitInfo{
 		Name:       traitName,
 		Namespace:  pkgName,
+		FullName:   v.buildFullName(traitName),
 		Methods:    []MethodInfo{},
 		Properties: []PropertyInfo{},
 		FilePath:   v.filePath,
 	}
+	if n.Position != nil {
+		traitInfo.StartLine = n.Position.StartLine
+		traitInfo.EndLine = n.Position.EndLine
+		if v.fileContent != nil {
+			traitInfo.Code = extractCodeFromContent(v.fileContent, n.Position.StartLine, n.Position.EndLine)
+		}
+	}
 
 	// Extract PHPDoc from TraitTkn
 	if n.TraitTkn != nil {
@@ -616,7 +709,7 @@ func (v *symbolCollector) extractPHPDocFromModifiers(modifiers []ast.Vertex) *PH
 		if identifier, ok := mod.(*ast.Identifier); ok {
 			if identifier.IdentifierTkn != nil {
 				phpDoc := extractPHPDocFromToken(identifier.IdentifierTkn)
-				if phpDoc.Description != "" || len(phpDoc.Params) > 0 || len(phpDoc.Returns) > 0 {
+				if phpDoc.Description != "" || len(phpDoc.Params) > 0 || len(phpDoc.Returns) > 0 || phpDoc.IsDeprecated {
 					return phpDoc
 				}
 			}
@@ -648,7 +741,7 @@ func (v *symbolCollector) buildMethodSignature(name string, params []ast.Vertex,
 			}
 
 			// Add name
-			paramStr += "$" + v.extractVariableName(p.Var)
+			paramStr += "$" + strings.TrimPrefix(v.extractVariableName(p.Var), "$")
 
 			paramStrs = append(paramStrs, paramStr)
 		}
@@ -820,6 +913,15 @@ func (v *symbolCollector) extractTypeNameString(node ast.Vertex) string {
 	return v.extractTypeName(node)
 }
 
+// packageName returns the package symbols of the current file belong to:
+// their namespace, or a directory pseudo-package of GlobalNamespace.
+func (v *symbolCollector) packageName() string {
+	if v.analyzer.currentNamespace != "" {
+		return v.analyzer.currentNamespace
+	}
+	return v.analyzer.globalPackage(v.filePath)
+}
+
 func (v *symbolCollector) buildFullName(name string) string {
 	if v.analyzer.currentNamespace == "" {
 		return name
@@ -858,7 +960,7 @@ func (ca *CodeAnalyzer) convertToChunks() []codetypes.CodeChunk {
 			chunk := codetypes.CodeChunk{
 				Name:      class.Name,
 				Type:      "class",
-				Language:  "php",
+				Language:  codetypes.LanguagePHP,
 				Package:   class.Namespace,
 				FilePath:  class.FilePath,
 				StartLine: class.StartLine,
@@ -869,6 +971,9 @@ func (ca *CodeAnalyzer) convertToChunks() []codetypes.CodeChunk {
 
 			// Add a simple class signature similar to Go type summaries
 			chunk.Signature = buildClassSignature(class)
+			if class.Deprecated != "" {
+				codetypes.MarkDeprecated(&chunk, class.Deprecated)
+			}
 
 			// Add Laravel metadata if applicable
 			if isLaravel {
@@ -882,7 +987,7 @@ func (ca *CodeAnalyzer) convertToChunks() []codetypes.CodeChunk {
 				methodChunk := codetypes.CodeChunk{
 					Name:      method.Name,
 					Type:      "method",
-					Language:  "php",
+					Language:  codetypes.LanguagePHP,
 					Package:   class.Namespace,
 					Signature: fmt.Sprintf("%s function %s()", method.Visibility, method.Name),
 					FilePath:  class.FilePath,
@@ -890,6 +995,12 @@ func (ca *CodeAnalyzer) convertToChunks() []codetypes.CodeChunk {
 					EndLine:   method.EndLine,
 					Docstring: method.Description,
 					Code:      method.Code,
+					Metadata: map[string]any{
+						"class_name": class.Name,
+					},
+				}
+				if method.Deprecated != "" {
+					codetypes.MarkDeprecated(&methodChunk, method.Deprecated)
 				}
 				chunks = append(chunks, methodChunk)
 			}
@@ -899,9 +1010,9 @@ func (ca *CodeAnalyzer) convertToChunks() []codetypes.CodeChunk {
 				propChunk := codetypes.CodeChunk{
 					Name:      prop.Name,
 					Type:      "property",
-					Language:  "php",
+					Language:  codetypes.LanguagePHP,
 					Package:   class.Namespace,
-					Signature: fmt.Sprintf("%s %s $%s", prop.Visibility, prop.Type, prop.Name),
+					Signature: fmt.Sprintf("%s %s $%s", prop.Visibility, prop.Type, strings.TrimPrefix(prop.Name, "$")),
 					FilePath:  class.FilePath,
 					StartLine: prop.StartLine,
 					EndLine:   prop.EndLine,
@@ -915,9 +1026,12 @@ func (ca *CodeAnalyzer) convertToChunks() []codetypes.CodeChunk {
 				constChunk := codetypes.CodeChunk{
 					Name:      constant.Name,
 					Type:      "constant",
-					Language:  "php",
+					Language:  codetypes.LanguagePHP,
 					Package:   class.Namespace,
 					Signature: fmt.Sprintf("%s const %s", constant.Visibility, constant.Name),
+					FilePath:  constant.FilePath,
+					StartLine: constant.StartLine,
+					EndLine:   constant.EndLine,
 				}
 				chunks = append(chunks, constChunk)
 			}
@@ -926,46 +1040,44 @@ func (ca *CodeAnalyzer) convertToChunks() []codetypes.CodeChunk {
 		// Convert interfaces
 		for _, iface := range pkg.Interfaces {
 			chunk := codetypes.CodeChunk{
-				Name:     iface.Name,
-				Type:     "interface",
-				Language: "php",
-				Package:  iface.Namespace,
+				Name:      iface.Name,
+				Type:      "interface",
+				Language:  codetypes.LanguagePHP,
+				Package:   iface.Namespace,
+				Signature: fmt.Sprintf("interface %s", iface.Name),
+				FilePath:  iface.FilePath,
+				StartLine: iface.StartLine,
+				EndLine:   iface.EndLine,
+				Docstring: iface.Description,
+				Code:      iface.Code,
 			}
 			chunks = append(chunks, chunk)
 
 			// Add chunks for interface methods
 			for _, method := range iface.Methods {
-				methodChunk := codetypes.CodeChunk{
-					Name:      method.Name,
-					Type:      "method",
-					Language:  "php",
-					Package:   iface.Namespace,
-					Signature: fmt.Sprintf("function %s()", method.Name),
-				}
-				chunks = append(chunks, methodChunk)
+				chunks = append(chunks, memberMethodChunk(method, iface.Name, iface.Namespace, iface.FilePath))
 			}
 		}
 
 		// Convert traits
 		for _, trait := range pkg.Traits {
 			chunk := codetypes.CodeChunk{
-				Name:     trait.Name,
-				Type:     "trait",
-				Language: "php",
-				Package:  trait.Namespace,
+				Name:      trait.Name,
+				Type:      "trait",
+				Language:  codetypes.LanguagePHP,
+				Package:   trait.Namespace,
+				Signature: fmt.Sprintf("trait %s", trait.Name),
+				FilePath:  trait.FilePath,
+				StartLine: trait.StartLine,
+				EndLine:   trait.EndLine,
+				Docstring: trait.Description,
+				Code:      trait.Code,
 			}
 			chunks = append(chunks, chunk)
 
 			// Add chunks for trait methods
 			for _, method := range trait.Methods {
-				methodChunk := codetypes.CodeChunk{
-					Name:      method.Name,
-					Type:      "method",
-					Language:  "php",
-					Package:   trait.Namespace,
-					Signature: fmt.Sprintf("%s function %s()", method.Visibility, method.Name),
-				}
-				chunks = append(chunks, methodChunk)
+				chunks = append(chunks, memberMethodChunk(method, trait.Name, trait.Namespace, trait.FilePath))
 			}
 
 			// Add chunks for trait properties
@@ -973,9 +1085,13 @@ func (ca *CodeAnalyzer) convertToChunks() []codetypes.CodeChunk {
 				propChunk := codetypes.CodeChunk{
 					Name:      prop.Name,
 					Type:      "property",
-					Language:  "php",
+					Language:  codetypes.LanguagePHP,
 					Package:   trait.Namespace,
-					Signature: fmt.Sprintf("%s %s $%s", prop.Visibility, prop.Type, prop.Name),
+					Signature: fmt.Sprintf("%s %s $%s", prop.Visibility, prop.Type, strings.TrimPrefix(prop.Name, "$")),
+					FilePath:  trait.FilePath,
+					StartLine: prop.StartLine,
+					EndLine:   prop.EndLine,
+					Docstring: prop.Description,
 				}
 				chunks = append(chunks, propChunk)
 			}
@@ -984,10 +1100,19 @@ func (ca *CodeAnalyzer) convertToChunks() []codetypes.CodeChunk {
 		// Convert global functions
 		for _, fn := range pkg.Functions {
 			chunk := codetypes.CodeChunk{
-				Name:     fn.Name,
-				Type:     "function",
-				Language: "php",
-				Package:  fn.Namespace,
+				Name:      fn.Name,
+				Type:      "function",
+				Language:  codetypes.LanguagePHP,
+				Package:   fn.Namespace,
+				Signature: fn.Signature,
+				FilePath:  fn.FilePath,
+				StartLine: fn.StartLine,
+				EndLine:   fn.EndLine,
+				Docstring: fn.Description,
+				Code:      fn.Code,
+			}
+			if fn.Deprecated != "" {
+				codetypes.MarkDeprecated(&chunk, fn.Deprecated)
 			}
 			chunks = append(chunks, chunk)
 		}
@@ -997,7 +1122,7 @@ func (ca *CodeAnalyzer) convertToChunks() []codetypes.CodeChunk {
 			constChunk := codetypes.CodeChunk{
 				Name:      constant.Name,
 				Type:      "constant",
-				Language:  "php",
+				Language:  codetypes.LanguagePHP,
 				Package:   pkg.Namespace,
 				Signature: fmt.Sprintf("const %s", constant.Name),
 			}
@@ -1008,6 +1133,33 @@ func (ca *CodeAnalyzer) convertToChunks() []codetypes.CodeChunk {
 	return chunks
 }
 
+// memberMethodChunk converts a method of an interface or trait
+func memberMethodChunk(method MethodInfo, owner, namespace, filePath string) codetypes.CodeChunk {
+	signature := method.Signature
+	if signature == "" {
+		signature = fmt.Sprintf("%s function %s()", method.Visibility, method.Name)
+	}
+	chunk := codetypes.CodeChunk{
+		Name:      method.Name,
+		Type:      "method",
+		Language:  codetypes.LanguagePHP,
+		Package:   namespace,
+		Signature: signature,
+		FilePath:  filePath,
+		StartLine: method.StartLine,
+		EndLine:   method.EndLine,
+		Docstring: method.Description,
+		Code:      method.Code,
+		Metadata: map[string]any{
+			"class_name": owner,
+		},
+	}
+	if method.Deprecated != "" {
+		codetypes.MarkDeprecated(&chunk, method.Deprecated)
+	}
+	return chunk
+}
+
 // extractCodeFromContent extracts code from file content based on line numbers (1-indexed)
 func extractCodeFromContent(content []byte, startLine, endLine int) string {
 	if content == nil || startLine < 1 || endLine < startLine {
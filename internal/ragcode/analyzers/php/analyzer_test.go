@@ -51,7 +51,7 @@ class User {
 		}
 	}
 	require.NotNil(t, classChunk, "Should find User class")
-	require.Equal(t, "php", classChunk.Language)
+	require.Equal(t, codetypes.LanguagePHP, classChunk.Language)
 	require.Equal(t, "App\\Models", classChunk.Package)
 
 	// Find method chunks
@@ -90,10 +90,38 @@ function world() {
 
 	for _, chunk := range chunks {
 		require.Equal(t, "function", chunk.Type)
-		require.Equal(t, "php", chunk.Language)
+		require.Equal(t, codetypes.LanguagePHP, chunk.Language)
 	}
 }
 
+func TestCodeAnalyzer_PartialFile(t *testing.T) {
+	tmpDir := t.TempDir()
+	phpFile := filepath.Join(tmpDir, "broken.php")
+
+	phpCode := `<?php
+function hello($name) {
+    return "Hello " . $name;
+}
+
+function broken( {
+`
+
+	err := os.WriteFile(phpFile, []byte(phpCode), 0644)
+	require.NoError(t, err)
+
+	analyzer := NewCodeAnalyzer()
+	chunks, err := analyzer.AnalyzeFile(phpFile)
+	require.NoError(t, err)
+	require.NotEmpty(t, analyzer.ParseErrors())
+
+	var hello bool
+	for _, chunk := range chunks {
+		require.True(t, chunk.Partial(), "%s should be flagged partial", chunk.Name)
+		hello = hello || chunk.Name == "hello"
+	}
+	require.True(t, hello, "hello should be recovered from the broken file")
+}
+
 func TestCodeAnalyzer_NamespacedFunction(t *testing.T) {
 	tmpDir := t.TempDir()
 	phpFile := filepath.Join(tmpDir, "helper.php")
@@ -481,3 +509,92 @@ func TestCodeAnalyzer_BarouUserClass(t *testing.T) {
 		}
 	}
 }
+
+func TestCodeAnalyzer_Deprecated(t *testing.T) {
+	tmpDir := t.TempDir()
+	phpFile := filepath.Join(tmpDir, "Billing.php")
+
+	phpCode := `<?php
+namespace App;
+
+/**
+ * @deprecated use Payments instead
+ */
+class Billing {
+    /**
+     * @deprecated
+     */
+    public function charge($amount) {
+        return $amount;
+    }
+
+    public function refund($amount) {
+        return $amount;
+    }
+}
+`
+	require.NoError(t, os.WriteFile(phpFile, []byte(phpCode), 0644))
+
+	chunks, err := NewCodeAnalyzer().AnalyzeFile(phpFile)
+	require.NoError(t, err)
+
+	notes := make(map[string]string)
+	for _, ch := range chunks {
+		if deprecated, note := ch.Deprecation(); deprecated {
+			notes[ch.Name] = note
+		}
+	}
+	require.Equal(t, "use Payments instead", notes["Billing"])
+	require.Contains(t, notes, "charge")
+	require.NotContains(t, notes, "refund")
+}
+
+func TestCodeAnalyzer_GlobalNamespacePackages(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(root, "composer.json"), []byte("{}"), 0644))
+	files := map[string]string{
+		"bootstrap.php":              "<?php\nfunction boot() {}\n",
+		"legacy/billing/invoice.php": "<?php\nclass Invoice {}\nfunction render_invoice() {}\n",
+		"legacy/users/helpers.php":   "<?php\nfunction render_invoice() {}\n",
+		"src/Order.php":              "<?php\nnamespace App;\nclass Order {}\n",
+	}
+	for name, code := range files {
+		path := filepath.Join(root, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(code), 0644))
+	}
+
+	analyzer := NewCodeAnalyzer()
+	chunks, err := analyzer.AnalyzePaths([]string{root})
+	require.NoError(t, err)
+
+	packages := make(map[string]string)
+	for _, ch := range chunks {
+		if ch.FilePath == "" {
+			continue
+		}
+		packages[filepath.ToSlash(mustRel(t, root, ch.FilePath))+":"+ch.Name] = ch.Package
+	}
+	require.Equal(t, "global", packages["bootstrap.php:boot"])
+	require.Equal(t, `global\legacy\billing`, packages["legacy/billing/invoice.php:Invoice"])
+	require.Equal(t, `global\legacy\billing`, packages["legacy/billing/invoice.php:render_invoice"])
+	require.Equal(t, `global\legacy\users`, packages["legacy/users/helpers.php:render_invoice"])
+	require.Equal(t, "App", packages["src/Order.php:Order"])
+
+	// Incremental runs see single files and must agree with the full run
+	single, err := NewCodeAnalyzer().AnalyzePaths([]string{filepath.Join(root, "legacy/users/helpers.php")})
+	require.NoError(t, err)
+	require.Len(t, single, 1)
+	require.Equal(t, `global\legacy\users`, single[0].Package)
+
+	require.True(t, IsGlobalNamespace("global"))
+	require.True(t, IsGlobalNamespace(`global\legacy`))
+	require.False(t, IsGlobalNamespace("globals"))
+}
+
+func mustRel(t *testing.T, root, path string) string {
+	t.Helper()
+	rel, err := filepath.Rel(root, path)
+	require.NoError(t, err)
+	return rel
+}
@@ -0,0 +1,68 @@
+package php
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// GlobalNamespace is the package of PHP code without a namespace declaration.
+//
+// Legacy codebases can have thousands of such files, so they are grouped
+// further into directory pseudo-packages relative to the project root:
+// legacy/billing/invoice.php is in package "global\legacy\billing". Files in
+// the root itself, or outside a recognizable project, stay in "global".
+const GlobalNamespace = "global"
+
+// projectRootMarkers identify the root of a PHP project
+var projectRootMarkers = []string{"composer.json", ".git", ".ragcode"}
+
+// IsGlobalNamespace reports whether pkg is GlobalNamespace or one of its
+// directory pseudo-packages.
+func IsGlobalNamespace(pkg string) bool {
+	return pkg == GlobalNamespace || strings.HasPrefix(pkg, GlobalNamespace+"\\")
+}
+
+// globalPackage returns the pseudo-package of a global-namespace file.
+func (ca *CodeAnalyzer) globalPackage(filePath string) string {
+	dir := filepath.Dir(filePath)
+	if abs, err := filepath.Abs(dir); err == nil {
+		dir = abs
+	}
+	root := ca.projectRoot(dir)
+	if root == "" {
+		return GlobalNamespace
+	}
+	rel, err := filepath.Rel(root, dir)
+	if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+		return GlobalNamespace
+	}
+	return GlobalNamespace + "\\" + strings.Join(strings.Split(filepath.ToSlash(rel), "/"), "\\")
+}
+
+// projectRoot returns the nearest directory at or above dir that contains a
+// project root marker, or "" if there is none. Lookups are cached per
+// directory since every file of a directory asks the same question.
+func (ca *CodeAnalyzer) projectRoot(dir string) string {
+	if ca.projectRoots == nil {
+		ca.projectRoots = make(map[string]string)
+	}
+	if root, ok := ca.projectRoots[dir]; ok {
+		return root
+	}
+
+	root := ""
+	for _, marker := range projectRootMarkers {
+		if _, err := os.Stat(filepath.Join(dir, marker)); err == nil {
+			root = dir
+			break
+		}
+	}
+	if root == "" {
+		if parent := filepath.Dir(dir); parent != dir {
+			root = ca.projectRoot(parent)
+		}
+	}
+	ca.projectRoots[dir] = root
+	return root
+}
@@ -66,4 +66,11 @@ class UserService {
 	assert.Equal(t, "App\\Models\\User", foundClass.Imports["User"])
 	assert.Equal(t, "App\\Models\\Post", foundClass.Imports["BlogPost"])
 	assert.Equal(t, "Illuminate\\Support\\Facades\\Log", foundClass.Imports["Log"])
+
+	// The file's imports, for get_package_dependencies
+	files := analyzer.Imports()
+	if assert.Len(t, files, 1) {
+		assert.Equal(t, "App\\Services", files[0].Package)
+		assert.Equal(t, []string{"App\\Models\\User", "App\\Models\\Post", "Illuminate\\Support\\Facades\\Log"}, files[0].Imports)
+	}
 }
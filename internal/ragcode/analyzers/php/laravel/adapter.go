@@ -24,6 +24,11 @@ func NewAdapter() *Adapter {
 	}
 }
 
+// ParseErrors implements codetypes.ParseErrorReporter
+func (a *Adapter) ParseErrors() []codetypes.ParseError {
+	return a.phpAnalyzer.ParseErrors()
+}
+
 // AnalyzePaths implements the PathAnalyzer interface
 func (a *Adapter) AnalyzePaths(paths []string) ([]codetypes.CodeChunk, error) {
 	// 1. Run standard PHP analysis
@@ -81,7 +86,7 @@ func (a *Adapter) enrichChunks(chunks []codetypes.CodeChunk, info *LaravelInfo)
 
 		// We need to reconstruct the full name to match
 		fullName := chunk.Name
-		if chunk.Package != "" && chunk.Package != "global" {
+		if chunk.Package != "" && !php.IsGlobalNamespace(chunk.Package) {
 			fullName = chunk.Package + "\\" + chunk.Name
 		}
 
@@ -171,7 +176,7 @@ func (a *Adapter) convertRoutesToChunks(routes []Route) []codetypes.CodeChunk {
 		chunk := codetypes.CodeChunk{
 			Name:      fmt.Sprintf("%s %s", route.Method, route.URI),
 			Type:      "route",
-			Language:  "php",
+			Language:  codetypes.LanguagePHP,
 			FilePath:  route.FilePath,
 			StartLine: route.Line,
 			EndLine:   route.Line, // Routes are usually one line
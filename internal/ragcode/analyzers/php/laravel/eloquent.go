@@ -222,7 +222,7 @@ func (a *EloquentAnalyzer) detectRelationFromAST(class php.ClassInfo, methodNode
 					// Check imports first
 					if fullClass, ok := class.Imports[relatedModel]; ok {
 						relatedModel = fullClass
-					} else if class.Namespace != "" && class.Namespace != "global" {
+					} else if class.Namespace != "" && !php.IsGlobalNamespace(class.Namespace) {
 						// Fallback to current namespace
 						relatedModel = class.Namespace + "\\" + relatedModel
 					}
@@ -17,8 +17,10 @@ type PHPDocInfo struct {
 	Var         string
 	VarType     string
 	Deprecated  string
-	See         []string
-	Examples    []string
+	// IsDeprecated is set for any @deprecated tag, including one without text
+	IsDeprecated bool
+	See          []string
+	Examples     []string
 }
 
 // ParamDoc represents a @param tag
@@ -143,6 +145,7 @@ func parseTag(line string, doc *PHPDocInfo) {
 	// @deprecated description
 	if strings.HasPrefix(line, "@deprecated") {
 		doc.Deprecated = strings.TrimSpace(strings.TrimPrefix(line, "@deprecated"))
+		doc.IsDeprecated = true
 		return
 	}
 
@@ -159,6 +162,18 @@ func parseTag(line string, doc *PHPDocInfo) {
 	}
 }
 
+// deprecationNote returns the @deprecated text, or "deprecated" for a bare
+// tag, so that an empty string always means "not deprecated".
+func deprecationNote(doc *PHPDocInfo) string {
+	if !doc.IsDeprecated {
+		return ""
+	}
+	if doc.Deprecated != "" {
+		return doc.Deprecated
+	}
+	return "deprecated"
+}
+
 // convertPHPDocToReturnInfo converts PHPDoc returns to codetypes.ReturnInfo
 func convertPHPDocToReturnInfo(docReturns []ReturnDoc) []codetypes.ReturnInfo {
 	returns := make([]codetypes.ReturnInfo, len(docReturns))
@@ -26,8 +26,9 @@ type PackageInfo struct {
 type ClassInfo struct {
 	Name        string            `json:"name"`
 	Namespace   string            `json:"namespace"`
-	FullName    string            `json:"full_name"`   // Fully qualified name (FQN)
-	Description string            `json:"description"` // PHPDoc description
+	FullName    string            `json:"full_name"`            // Fully qualified name (FQN)
+	Description string            `json:"description"`          // PHPDoc description
+	Deprecated  string            `json:"deprecated,omitempty"` // @deprecated note
 	Extends     string            `json:"extends,omitempty"`
 	Implements  []string          `json:"implements,omitempty"`
 	Uses        []string          `json:"uses,omitempty"` // Trait usage
@@ -84,6 +85,7 @@ type MethodInfo struct {
 	IsStatic    bool                   `json:"is_static"`
 	IsAbstract  bool                   `json:"is_abstract"`
 	IsFinal     bool                   `json:"is_final"`
+	Deprecated  string                 `json:"deprecated,omitempty"` // @deprecated note
 	ClassName   string                 `json:"class_name,omitempty"` // Parent class/interface/trait
 	FilePath    string                 `json:"file_path,omitempty"`
 	StartLine   int                    `json:"start_line,omitempty"`
@@ -101,6 +103,7 @@ type FunctionInfo struct {
 	Returns     []codetypes.ReturnInfo `json:"returns,omitempty"`
 	Namespace   string                 `json:"namespace,omitempty"`
 	IsMethod    bool                   `json:"is_method"`
+	Deprecated  string                 `json:"deprecated,omitempty"` // @deprecated note
 	ClassName   string                 `json:"class_name,omitempty"` // If method
 	Visibility  string                 `json:"visibility,omitempty"` // If method
 	IsStatic    bool                   `json:"is_static,omitempty"`  // If method
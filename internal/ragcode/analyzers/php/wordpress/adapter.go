@@ -0,0 +1,330 @@
+package wordpress
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/doITmagic/rag-code-mcp/internal/codetypes"
+)
+
+// maxTemplateLines bounds the code stored for a template chunk
+const maxTemplateLines = 200
+
+// Adapter implements codetypes.PathAnalyzer for WordPress themes and plugins.
+// It wraps another PHP analyzer and adds hook, shortcode and template chunks,
+// and marks functions and methods used as hook callbacks.
+type Adapter struct {
+	next codetypes.PathAnalyzer
+}
+
+// NewAdapter creates a WordPress adapter on top of next
+func NewAdapter(next codetypes.PathAnalyzer) *Adapter {
+	return &Adapter{next: next}
+}
+
+// Next returns the wrapped analyzer
+func (a *Adapter) Next() codetypes.PathAnalyzer {
+	return a.next
+}
+
+// ParseErrors implements codetypes.ParseErrorReporter for the wrapped
+// analyzer
+func (a *Adapter) ParseErrors() []codetypes.ParseError {
+	if r, ok := a.next.(codetypes.ParseErrorReporter); ok {
+		return r.ParseErrors()
+	}
+	return nil
+}
+
+// AnalyzePaths implements the PathAnalyzer interface
+func (a *Adapter) AnalyzePaths(paths []string) ([]codetypes.CodeChunk, error) {
+	chunks, err := a.next.AnalyzePaths(paths)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := Analyze(paths)
+	if err != nil || info.Empty() {
+		// WordPress analysis is best effort on top of the PHP chunks
+		return chunks, nil
+	}
+
+	ResolveCallbacks(info, chunks)
+	enrichCallbacks(chunks, info)
+	return append(chunks, info.Chunks()...), nil
+}
+
+// Analyze extracts hooks, shortcodes and templates from the PHP files of
+// paths (files or directories)
+func Analyze(paths []string) (*Info, error) {
+	info := &Info{}
+	themes := newThemeFinder()
+
+	analyze := func(path string) {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to read %s: %v\n", path, err)
+			return
+		}
+		hooks, shortcodes, err := AnalyzeFile(path, content)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to analyze WordPress hooks in %s: %v\n", path, err)
+		}
+		info.Hooks = append(info.Hooks, hooks...)
+		info.Shortcodes = append(info.Shortcodes, shortcodes...)
+		if tpl, ok := themes.template(path, content); ok {
+			info.Templates = append(info.Templates, tpl)
+		}
+	}
+
+	for _, root := range paths {
+		stat, err := os.Stat(root)
+		if err != nil {
+			return nil, fmt.Errorf("error accessing path %s: %w", root, err)
+		}
+		if !stat.IsDir() {
+			if strings.HasSuffix(root, ".php") {
+				analyze(root)
+			}
+			continue
+		}
+		err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				base := d.Name()
+				// Same exclusions as the PHP analyzer
+				if base == ".git" || base == "vendor" || base == "node_modules" ||
+					base == "storage" || base == "public" || strings.HasPrefix(base, ".") {
+					if path != root {
+						return filepath.SkipDir
+					}
+				}
+				return nil
+			}
+			if strings.HasSuffix(d.Name(), ".php") {
+				analyze(path)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error walking directory %s: %w", root, err)
+		}
+	}
+	return info, nil
+}
+
+// ResolveCallbacks fills in where hook and shortcode callbacks are defined,
+// using the function and method chunks of the PHP analyzer. PHP function
+// and method names are case-insensitive.
+func ResolveCallbacks(info *Info, chunks []codetypes.CodeChunk) {
+	defs := callbackDefinitions(chunks)
+	for i := range info.Hooks {
+		if ch, ok := defs[callbackKey(info.Hooks[i].Callback)]; ok {
+			info.Hooks[i].CallbackFile = ch.FilePath
+			info.Hooks[i].CallbackLine = ch.StartLine
+		}
+	}
+	for i := range info.Shortcodes {
+		if ch, ok := defs[callbackKey(info.Shortcodes[i].Callback)]; ok {
+			info.Shortcodes[i].CallbackFile = ch.FilePath
+			info.Shortcodes[i].CallbackLine = ch.StartLine
+		}
+	}
+}
+
+// callbackDefinitions indexes functions by name and methods by
+// Class::method, with the class name unqualified
+func callbackDefinitions(chunks []codetypes.CodeChunk) map[string]*codetypes.CodeChunk {
+	defs := make(map[string]*codetypes.CodeChunk)
+	for i := range chunks {
+		ch := &chunks[i]
+		switch ch.Type {
+		case "function":
+			defs[strings.ToLower(ch.Name)] = ch
+		case "method":
+			if class, _ := ch.Metadata["class_name"].(string); class != "" {
+				defs[callbackKey(class+"::"+ch.Name)] = ch
+			}
+		}
+	}
+	return defs
+}
+
+// callbackKey normalizes a callback for lookup: "\App\Foo::Bar" -> "foo::bar"
+func callbackKey(callback string) string {
+	class, method, ok := strings.Cut(callback, "::")
+	if !ok {
+		return strings.ToLower(strings.TrimPrefix(callback, "\\"))
+	}
+	if i := strings.LastIndex(class, "\\"); i >= 0 {
+		class = class[i+1:]
+	}
+	return strings.ToLower(class + "::" + method)
+}
+
+// enrichCallbacks stores the hooks and shortcodes a function or method is
+// registered for in its metadata (wp_hooks, wp_shortcodes)
+func enrichCallbacks(chunks []codetypes.CodeChunk, info *Info) {
+	hooks := make(map[string][]string)
+	for _, h := range info.Hooks {
+		if h.CallbackFile != "" {
+			key := h.CallbackFile + ":" + callbackKey(h.Callback)
+			hooks[key] = append(hooks[key], h.Name)
+		}
+	}
+	shortcodes := make(map[string][]string)
+	for _, s := range info.Shortcodes {
+		if s.CallbackFile != "" {
+			key := s.CallbackFile + ":" + callbackKey(s.Callback)
+			shortcodes[key] = append(shortcodes[key], s.Tag)
+		}
+	}
+
+	for i := range chunks {
+		ch := &chunks[i]
+		name := ch.Name
+		switch ch.Type {
+		case "function":
+		case "method":
+			class, _ := ch.Metadata["class_name"].(string)
+			name = class + "::" + ch.Name
+		default:
+			continue
+		}
+		key := ch.FilePath + ":" + callbackKey(name)
+		if len(hooks[key]) == 0 && len(shortcodes[key]) == 0 {
+			continue
+		}
+		if ch.Metadata == nil {
+			ch.Metadata = make(map[string]any)
+		}
+		ch.Metadata["framework"] = "wordpress"
+		if names := uniqueSorted(hooks[key]); len(names) > 0 {
+			ch.Metadata["wp_hooks"] = names
+		}
+		if tags := uniqueSorted(shortcodes[key]); len(tags) > 0 {
+			ch.Metadata["wp_shortcodes"] = tags
+		}
+	}
+}
+
+// Chunks converts the WordPress features into searchable chunks
+func (i *Info) Chunks() []codetypes.CodeChunk {
+	var chunks []codetypes.CodeChunk
+	files := make(map[string][]string)
+	fileLines := func(path string) []string {
+		lines, ok := files[path]
+		if !ok {
+			if data, err := os.ReadFile(path); err == nil {
+				lines = strings.Split(string(data), "\n")
+			}
+			files[path] = lines
+		}
+		return lines
+	}
+	source := func(path string, start, end int) string {
+		lines := fileLines(path)
+		if start < 1 || end > len(lines) || end < start {
+			return ""
+		}
+		return strings.Join(lines[start-1:end], "\n")
+	}
+
+	for _, h := range i.Hooks {
+		chunk := codetypes.CodeChunk{
+			Name:      h.Name,
+			Type:      "hook",
+			Language:  codetypes.LanguagePHP,
+			FilePath:  h.FilePath,
+			StartLine: h.StartLine,
+			EndLine:   h.EndLine,
+			Code:      source(h.FilePath, h.StartLine, h.EndLine),
+			Metadata: map[string]any{
+				"framework": "wordpress",
+				"hook":      h.Name,
+				"hook_kind": h.Kind,
+			},
+		}
+		if h.IsTrigger() {
+			chunk.Signature = fmt.Sprintf("%s('%s')", h.Call, h.Name)
+			chunk.Docstring = fmt.Sprintf("Fires the %s %s", h.Name, h.Kind)
+			chunk.Metadata["wp_type"] = "hook_trigger"
+		} else {
+			chunk.Signature = fmt.Sprintf("%s('%s', %s, %d)", h.Call, h.Name, h.Callback, h.Priority)
+			chunk.Docstring = fmt.Sprintf("Registers %s as %s callback for %s (priority %d)", h.Callback, h.Kind, h.Name, h.Priority)
+			chunk.Metadata["wp_type"] = "hook_callback"
+			chunk.Metadata["callback"] = h.Callback
+			chunk.Metadata["priority"] = h.Priority
+		}
+		chunks = append(chunks, chunk)
+	}
+
+	for _, s := range i.Shortcodes {
+		chunks = append(chunks, codetypes.CodeChunk{
+			Name:      s.Tag,
+			Type:      "shortcode",
+			Language:  codetypes.LanguagePHP,
+			FilePath:  s.FilePath,
+			StartLine: s.StartLine,
+			EndLine:   s.EndLine,
+			Signature: fmt.Sprintf("add_shortcode('%s', %s)", s.Tag, s.Callback),
+			Docstring: fmt.Sprintf("Shortcode [%s] rendered by %s", s.Tag, s.Callback),
+			Code:      source(s.FilePath, s.StartLine, s.EndLine),
+			Metadata: map[string]any{
+				"framework": "wordpress",
+				"wp_type":   "shortcode",
+				"shortcode": s.Tag,
+				"callback":  s.Callback,
+			},
+		})
+	}
+
+	for _, t := range i.Templates {
+		lines := len(fileLines(t.FilePath))
+		end := lines
+		if end > maxTemplateLines {
+			end = maxTemplateLines
+		}
+		docstring := t.Description
+		if t.Theme != "" {
+			docstring += " in theme " + t.Theme
+		}
+		chunks = append(chunks, codetypes.CodeChunk{
+			Name:      t.File,
+			Type:      "template",
+			Language:  codetypes.LanguagePHP,
+			FilePath:  t.FilePath,
+			StartLine: 1,
+			EndLine:   lines,
+			Signature: fmt.Sprintf("WordPress template %s (%s)", t.File, t.Kind),
+			Docstring: docstring,
+			Code:      source(t.FilePath, 1, end),
+			Metadata: map[string]any{
+				"framework":     "wordpress",
+				"wp_type":       "template",
+				"template_kind": t.Kind,
+				"theme":         t.Theme,
+			},
+		})
+	}
+	return chunks
+}
+
+func uniqueSorted(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	var out []string
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			out = append(out, v)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
@@ -0,0 +1,114 @@
+package wordpress
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/doITmagic/rag-code-mcp/internal/codetypes"
+	"github.com/doITmagic/rag-code-mcp/internal/ragcode/analyzers/php"
+	"github.com/stretchr/testify/require"
+)
+
+func writeFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+}
+
+func TestAdapter(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"shop/style.css": "/*\nTheme Name: Shop Theme\nVersion: 1.0\n*/\n",
+		"shop/functions.php": `<?php
+function shop_setup() {}
+add_action('after_setup_theme', 'shop_setup');
+add_filter('the_content', 'shop_filter_content', 20, 2);
+add_action("save_post_{$type}", function ($id) {});
+
+class Cart {
+    public function __construct() {
+        add_action('init', [$this, 'register'], 5);
+        add_shortcode('cart', array(__CLASS__, 'render'));
+    }
+    public function register() {
+        do_action('shop_cart_registered', $this);
+    }
+    public static function render($atts) {
+        return apply_filters('shop_cart_html', '');
+    }
+}
+`,
+		"shop/single-product.php":         "<?php get_header(); ?>\n<main><?php the_content(); ?></main>\n",
+		"shop/header.php":                 "<!doctype html>\n",
+		"shop/template-parts/card.php":    "<article></article>\n",
+		"shop/templates/landing.php":      "<?php\n/*\n * Template Name: Landing Page\n */\n",
+		"shop/inc/helpers.php":            "<?php\nfunction shop_filter_content($content) { return $content; }\n",
+		"plugins/other/single-orphan.php": "<?php // not in a theme\n",
+	})
+
+	adapter := NewAdapter(php.NewCodeAnalyzer())
+	chunks, err := adapter.AnalyzePaths([]string{root})
+	require.NoError(t, err)
+
+	byType := make(map[string]map[string]codetypes.CodeChunk)
+	for _, ch := range chunks {
+		if byType[ch.Type] == nil {
+			byType[ch.Type] = make(map[string]codetypes.CodeChunk)
+		}
+		key := ch.Name
+		if class, _ := ch.Metadata["class_name"].(string); class != "" {
+			key = class + "::" + ch.Name
+		}
+		if ch.Type == "hook" {
+			key = ch.Name + "/" + ch.Metadata["wp_type"].(string)
+		}
+		byType[ch.Type][key] = ch
+	}
+
+	hooks := byType["hook"]
+	require.Len(t, hooks, 6)
+	init := hooks["init/hook_callback"]
+	require.Equal(t, "Cart::register", init.Metadata["callback"])
+	require.Equal(t, 5, init.Metadata["priority"])
+	require.Equal(t, "add_filter('the_content', shop_filter_content, 20)", hooks["the_content/hook_callback"].Signature)
+	require.Equal(t, "Closure", hooks["save_post_{$type}/hook_callback"].Metadata["callback"])
+	require.Equal(t, "action", hooks["shop_cart_registered/hook_trigger"].Metadata["hook_kind"])
+	require.Equal(t, "filter", hooks["shop_cart_html/hook_trigger"].Metadata["hook_kind"])
+
+	require.Equal(t, "Cart::render", byType["shortcode"]["cart"].Metadata["callback"])
+
+	// Callbacks are marked with their hooks, also across files
+	require.Equal(t, []string{"after_setup_theme"}, byType["function"]["shop_setup"].Metadata["wp_hooks"])
+	require.Equal(t, []string{"the_content"}, byType["function"]["shop_filter_content"].Metadata["wp_hooks"])
+	require.Equal(t, []string{"init"}, byType["method"]["Cart::register"].Metadata["wp_hooks"])
+	require.Equal(t, []string{"cart"}, byType["method"]["Cart::render"].Metadata["wp_shortcodes"])
+
+	templates := byType["template"]
+	require.Len(t, templates, 4)
+	require.Equal(t, "Single post of type or slug product in theme Shop Theme", templates["single-product.php"].Docstring)
+	require.Equal(t, "template-part", templates["header.php"].Metadata["template_kind"])
+	require.Equal(t, "template-part", templates["template-parts/card.php"].Metadata["template_kind"])
+	require.Equal(t, `Custom page template "Landing Page" in theme Shop Theme`, templates["templates/landing.php"].Docstring)
+}
+
+func TestAdapterWithoutWordPress(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{"src/Order.php": "<?php\nnamespace App;\nclass Order {}\n"})
+
+	chunks, err := NewAdapter(php.NewCodeAnalyzer()).AnalyzePaths([]string{root})
+	require.NoError(t, err)
+	require.Len(t, chunks, 1)
+	require.Nil(t, chunks[0].Metadata["framework"])
+}
+
+func TestMatchHook(t *testing.T) {
+	require.True(t, MatchHook("init", "init"))
+	require.False(t, MatchHook("init", "admin_init"))
+	require.True(t, MatchHook("woocommerce_*", "woocommerce_before_cart"))
+	require.True(t, MatchHook("save_post_product", "save_post_{$post_type}"))
+	require.False(t, MatchHook("save_post", "save_post_{$post_type}"))
+}
@@ -0,0 +1,373 @@
+package wordpress
+
+import (
+	"bytes"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/VKCOM/php-parser/pkg/ast"
+	"github.com/VKCOM/php-parser/pkg/conf"
+	"github.com/VKCOM/php-parser/pkg/errors"
+	"github.com/VKCOM/php-parser/pkg/parser"
+	"github.com/VKCOM/php-parser/pkg/version"
+	"github.com/VKCOM/php-parser/pkg/visitor"
+	"github.com/VKCOM/php-parser/pkg/visitor/traverser"
+)
+
+// hookCalls are the WordPress functions that register or fire hooks
+var hookCalls = map[string]struct {
+	kind    string
+	trigger bool
+}{
+	"add_action":               {KindAction, false},
+	"add_filter":               {KindFilter, false},
+	"do_action":                {KindAction, true},
+	"do_action_ref_array":      {KindAction, true},
+	"do_action_deprecated":     {KindAction, true},
+	"apply_filters":            {KindFilter, true},
+	"apply_filters_ref_array":  {KindFilter, true},
+	"apply_filters_deprecated": {KindFilter, true},
+}
+
+// hookMarkers let files without hooks or shortcodes skip the second parse
+var hookMarkers = [][]byte{[]byte("add_action"), []byte("add_filter"), []byte("do_action"), []byte("apply_filters"), []byte("add_shortcode")}
+
+// AnalyzeFile extracts the hooks and shortcodes of one PHP file
+func AnalyzeFile(filePath string, content []byte) ([]Hook, []Shortcode, error) {
+	found := false
+	for _, marker := range hookMarkers {
+		if bytes.Contains(content, marker) {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return nil, nil, nil
+	}
+
+	var parserErrors []*errors.Error
+	rootNode, err := parser.Parse(content, conf.Config{
+		Version: &version.Version{Major: 8, Minor: 0},
+		ErrorHandlerFunc: func(e *errors.Error) {
+			parserErrors = append(parserErrors, e)
+		},
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	if rootNode == nil {
+		return nil, nil, nil
+	}
+
+	collector := &hookCollector{filePath: filePath}
+	traverser.NewTraverser(collector).Traverse(rootNode)
+	collector.resolveThis()
+	return collector.hooks, collector.shortcodes, nil
+}
+
+// classRange is the line range of a class, to resolve $this and self in
+// callbacks registered inside it
+type classRange struct {
+	name       string
+	start, end int
+}
+
+// hookCollector visits the AST to find hook and shortcode calls
+type hookCollector struct {
+	visitor.Null
+	filePath   string
+	hooks      []Hook
+	shortcodes []Shortcode
+	classes    []classRange
+}
+
+// StmtClass records the class ranges
+func (v *hookCollector) StmtClass(n *ast.StmtClass) {
+	if ident, ok := n.Name.(*ast.Identifier); ok && n.Position != nil {
+		v.classes = append(v.classes, classRange{name: string(ident.Value), start: n.Position.StartLine, end: n.Position.EndLine})
+	}
+}
+
+// StmtTrait records trait ranges, whose $this is the trait for our purpose
+func (v *hookCollector) StmtTrait(n *ast.StmtTrait) {
+	if ident, ok := n.Name.(*ast.Identifier); ok && n.Position != nil {
+		v.classes = append(v.classes, classRange{name: string(ident.Value), start: n.Position.StartLine, end: n.Position.EndLine})
+	}
+}
+
+// ExprFunctionCall handles add_action(), apply_filters(), add_shortcode(), ...
+func (v *hookCollector) ExprFunctionCall(n *ast.ExprFunctionCall) {
+	name := strings.ToLower(strings.TrimPrefix(functionName(n.Function), "\\"))
+	if name == "" || n.Position == nil {
+		return
+	}
+	args := n.Args
+
+	if name == "add_shortcode" {
+		if len(args) < 2 {
+			return
+		}
+		v.shortcodes = append(v.shortcodes, Shortcode{
+			Tag:       hookName(argExpr(args[0])),
+			Callback:  callbackName(argExpr(args[1])),
+			FilePath:  v.filePath,
+			StartLine: n.Position.StartLine,
+			EndLine:   n.Position.EndLine,
+		})
+		return
+	}
+
+	call, ok := hookCalls[name]
+	if !ok || len(args) == 0 {
+		return
+	}
+	hook := Hook{
+		Name:      hookName(argExpr(args[0])),
+		Kind:      call.kind,
+		Call:      name,
+		FilePath:  v.filePath,
+		StartLine: n.Position.StartLine,
+		EndLine:   n.Position.EndLine,
+	}
+	if !call.trigger {
+		if len(args) < 2 {
+			return
+		}
+		hook.Callback = callbackName(argExpr(args[1]))
+		hook.Priority = 10
+		hook.AcceptedArgs = 1
+		if len(args) > 2 {
+			if p, ok := intValue(argExpr(args[2])); ok {
+				hook.Priority = p
+			}
+		}
+		if len(args) > 3 {
+			if a, ok := intValue(argExpr(args[3])); ok {
+				hook.AcceptedArgs = a
+			}
+		}
+	}
+	v.hooks = append(v.hooks, hook)
+}
+
+// resolveThis replaces $this, self and static in callbacks with the class
+// the registration is in
+func (v *hookCollector) resolveThis() {
+	enclosing := func(line int) string {
+		name := ""
+		for _, c := range v.classes {
+			// Innermost wins: later ranges start inside earlier ones
+			if line >= c.start && line <= c.end {
+				name = c.name
+			}
+		}
+		return name
+	}
+	resolve := func(callback string, line int) string {
+		for _, self := range []string{"$this::", "self::", "static::"} {
+			if strings.HasPrefix(callback, self) {
+				if class := enclosing(line); class != "" {
+					return class + "::" + strings.TrimPrefix(callback, self)
+				}
+			}
+		}
+		return callback
+	}
+	for i := range v.hooks {
+		v.hooks[i].Callback = resolve(v.hooks[i].Callback, v.hooks[i].StartLine)
+	}
+	for i := range v.shortcodes {
+		v.shortcodes[i].Callback = resolve(v.shortcodes[i].Callback, v.shortcodes[i].StartLine)
+	}
+}
+
+// functionName returns the called function of a plain function call
+func functionName(node ast.Vertex) string {
+	switch n := node.(type) {
+	case *ast.Name:
+		return nameParts(n.Parts)
+	case *ast.NameFullyQualified:
+		return "\\" + nameParts(n.Parts)
+	case *ast.NameRelative:
+		return nameParts(n.Parts)
+	}
+	return ""
+}
+
+func nameParts(parts []ast.Vertex) string {
+	names := make([]string, 0, len(parts))
+	for _, part := range parts {
+		if p, ok := part.(*ast.NamePart); ok {
+			names = append(names, string(p.Value))
+		}
+	}
+	return strings.Join(names, "\\")
+}
+
+func argExpr(node ast.Vertex) ast.Vertex {
+	if arg, ok := node.(*ast.Argument); ok {
+		return arg.Expr
+	}
+	return node
+}
+
+// hookName renders a hook name expression. Dynamic parts are kept as
+// placeholders: "save_post_{$post_type}".
+func hookName(expr ast.Vertex) string {
+	switch n := expr.(type) {
+	case *ast.ScalarString:
+		return unquote(string(n.Value))
+	case *ast.ScalarEncapsed:
+		var sb strings.Builder
+		for _, part := range n.Parts {
+			if s, ok := part.(*ast.ScalarEncapsedStringPart); ok {
+				sb.Write(s.Value)
+			} else {
+				sb.WriteString("{" + placeholder(part) + "}")
+			}
+		}
+		return sb.String()
+	case *ast.ExprBinaryConcat:
+		return hookName(n.Left) + hookName(n.Right)
+	}
+	return "{" + placeholder(expr) + "}"
+}
+
+// placeholder names a dynamic part of a hook name
+func placeholder(expr ast.Vertex) string {
+	switch n := expr.(type) {
+	case *ast.ExprVariable:
+		if ident, ok := n.Name.(*ast.Identifier); ok {
+			return string(ident.Value)
+		}
+	case *ast.ScalarEncapsedStringVar:
+		return placeholder(n.Name)
+	case *ast.ScalarEncapsedStringBrackets:
+		return placeholder(n.Var)
+	case *ast.Identifier:
+		return "$" + strings.TrimPrefix(string(n.Value), "$")
+	case *ast.ExprPropertyFetch:
+		return placeholder(n.Var) + "->" + identifier(n.Prop)
+	case *ast.ExprConstFetch:
+		return functionName(n.Const)
+	case *ast.ExprClassConstFetch:
+		return functionName(n.Class) + "::" + identifier(n.Const)
+	}
+	return "..."
+}
+
+func identifier(node ast.Vertex) string {
+	if ident, ok := node.(*ast.Identifier); ok {
+		return string(ident.Value)
+	}
+	return ""
+}
+
+// callbackName renders a callback: "my_init", "Class::method", "$this::method"
+// (resolved to the enclosing class later) or "Closure"
+func callbackName(expr ast.Vertex) string {
+	switch n := expr.(type) {
+	case *ast.ScalarString:
+		return unquote(string(n.Value))
+	case *ast.ExprClosure, *ast.ExprArrowFunction:
+		return "Closure"
+	case *ast.ExprArray:
+		if len(n.Items) != 2 {
+			return ""
+		}
+		var parts [2]string
+		for i, item := range n.Items {
+			arrayItem, ok := item.(*ast.ExprArrayItem)
+			if !ok {
+				return ""
+			}
+			parts[i] = callbackPart(arrayItem.Val)
+		}
+		if parts[0] == "" || parts[1] == "" {
+			return ""
+		}
+		return parts[0] + "::" + parts[1]
+	case *ast.ExprVariable:
+		return placeholder(n)
+	}
+	return ""
+}
+
+// callbackPart renders the object or method of an array callback
+func callbackPart(expr ast.Vertex) string {
+	switch n := expr.(type) {
+	case *ast.ScalarString:
+		return strings.TrimPrefix(unquote(string(n.Value)), "\\")
+	case *ast.ExprClassConstFetch:
+		// Foo::class
+		class := strings.TrimPrefix(functionName(n.Class), "\\")
+		if class == "" {
+			class = identifier(n.Class)
+		}
+		return class
+	case *ast.ScalarMagicConstant:
+		if strings.EqualFold(string(n.Value), "__CLASS__") {
+			return "self"
+		}
+	case *ast.ExprVariable:
+		return placeholder(n)
+	}
+	return ""
+}
+
+func intValue(expr ast.Vertex) (int, bool) {
+	neg := false
+	if minus, ok := expr.(*ast.ExprUnaryMinus); ok {
+		neg = true
+		expr = minus.Expr
+	}
+	n, ok := expr.(*ast.ScalarLnumber)
+	if !ok {
+		return 0, false
+	}
+	value, err := strconv.Atoi(string(n.Value))
+	if err != nil {
+		return 0, false
+	}
+	if neg {
+		value = -value
+	}
+	return value, true
+}
+
+func unquote(s string) string {
+	if len(s) >= 2 && (s[0] == '\'' || s[0] == '"') && s[len(s)-1] == s[0] {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
+
+var placeholderRe = regexp.MustCompile(`\{[^}]*\}`)
+
+// MatchHook reports whether a hook name matches query. Queries may use
+// shell-style wildcards ("woocommerce_*"), and dynamic parts of registered
+// names match anything: "save_post_{$post_type}" matches "save_post_product".
+func MatchHook(query, name string) bool {
+	if query == name {
+		return true
+	}
+	if strings.ContainsAny(query, "*?[") {
+		if ok, _ := path.Match(query, name); ok {
+			return true
+		}
+	}
+	if !strings.Contains(name, "{") {
+		return false
+	}
+	pattern := "^"
+	last := 0
+	for _, loc := range placeholderRe.FindAllStringIndex(name, -1) {
+		pattern += regexp.QuoteMeta(name[last:loc[0]]) + ".+"
+		last = loc[1]
+	}
+	pattern += regexp.QuoteMeta(name[last:]) + "$"
+	re, err := regexp.Compile(pattern)
+	return err == nil && re.MatchString(query)
+}
@@ -0,0 +1,157 @@
+package wordpress
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// templateHierarchy maps theme file names to their place in the WordPress
+// template hierarchy. The first match wins; %s is the file name suffix
+// (post type, slug, taxonomy, ...).
+var templateHierarchy = []struct {
+	re          *regexp.Regexp
+	kind        string
+	description string
+}{
+	{regexp.MustCompile(`^index\.php$`), "index", "Fallback template for every request"},
+	{regexp.MustCompile(`^front-page\.php$`), "front-page", "Site front page"},
+	{regexp.MustCompile(`^home\.php$`), "home", "Blog posts index"},
+	{regexp.MustCompile(`^privacy-policy\.php$`), "privacy-policy", "Privacy policy page"},
+	{regexp.MustCompile(`^singular\.php$`), "singular", "Any single post or page"},
+	{regexp.MustCompile(`^single\.php$`), "single", "Single post"},
+	{regexp.MustCompile(`^single-(.+)\.php$`), "single", "Single post of type or slug %s"},
+	{regexp.MustCompile(`^page\.php$`), "page", "Static page"},
+	{regexp.MustCompile(`^page-(.+)\.php$`), "page", "Page with slug or ID %s"},
+	{regexp.MustCompile(`^attachment\.php$`), "attachment", "Attachment page"},
+	{regexp.MustCompile(`^archive\.php$`), "archive", "Archive"},
+	{regexp.MustCompile(`^archive-(.+)\.php$`), "archive", "Archive of post type %s"},
+	{regexp.MustCompile(`^category\.php$`), "category", "Category archive"},
+	{regexp.MustCompile(`^category-(.+)\.php$`), "category", "Archive of category %s"},
+	{regexp.MustCompile(`^tag\.php$`), "tag", "Tag archive"},
+	{regexp.MustCompile(`^tag-(.+)\.php$`), "tag", "Archive of tag %s"},
+	{regexp.MustCompile(`^taxonomy\.php$`), "taxonomy", "Custom taxonomy archive"},
+	{regexp.MustCompile(`^taxonomy-(.+)\.php$`), "taxonomy", "Archive of taxonomy (term) %s"},
+	{regexp.MustCompile(`^author\.php$`), "author", "Author archive"},
+	{regexp.MustCompile(`^author-(.+)\.php$`), "author", "Archive of author %s"},
+	{regexp.MustCompile(`^date\.php$`), "date", "Date archive"},
+	{regexp.MustCompile(`^search\.php$`), "search", "Search results"},
+	{regexp.MustCompile(`^404\.php$`), "404", "Page not found"},
+	{regexp.MustCompile(`^embed(-.+)?\.php$`), "embed", "Embedded post"},
+	{regexp.MustCompile(`^(header|footer|sidebar)(-.+)?\.php$`), "template-part", "Template part loaded with get_%s()"},
+	{regexp.MustCompile(`^comments\.php$`), "template-part", "Comments template"},
+	{regexp.MustCompile(`^searchform\.php$`), "template-part", "Search form"},
+}
+
+var (
+	themeNameRe    = regexp.MustCompile(`(?m)^[ \t/*#@]*Theme Name:\s*(.+?)\s*$`)
+	templateNameRe = regexp.MustCompile(`(?m)^[ \t/*#@]*Template Name:\s*(.+?)\s*(?:\*/)?\s*$`)
+)
+
+// headerSize is how much of a file WordPress reads for file headers
+const headerSize = 8192
+
+// themeFinder locates theme roots (a style.css with a "Theme Name:"
+// header), caching the answer per directory
+type themeFinder struct {
+	roots map[string]themeRoot
+}
+
+type themeRoot struct {
+	dir, name string
+}
+
+func newThemeFinder() *themeFinder {
+	return &themeFinder{roots: make(map[string]themeRoot)}
+}
+
+// find returns the theme containing dir, looking at most two directories up
+// (template-parts/content/...)
+func (f *themeFinder) find(dir string) (themeRoot, bool) {
+	for depth := 0; depth <= 2; depth++ {
+		if root, ok := f.rootAt(dir); ok {
+			return root, true
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+	}
+	return themeRoot{}, false
+}
+
+func (f *themeFinder) rootAt(dir string) (themeRoot, bool) {
+	if root, ok := f.roots[dir]; ok {
+		return root, root.dir != ""
+	}
+	root := themeRoot{}
+	if m := themeNameRe.FindSubmatch(readHeader(filepath.Join(dir, "style.css"))); m != nil {
+		root = themeRoot{dir: dir, name: string(m[1])}
+	}
+	f.roots[dir] = root
+	return root, root.dir != ""
+}
+
+// template classifies a PHP file of a theme. Custom page templates are
+// recognized anywhere by their header; hierarchy files only inside a theme.
+func (f *themeFinder) template(filePath string, content []byte) (Template, bool) {
+	header := content
+	if len(header) > headerSize {
+		header = header[:headerSize]
+	}
+	theme, inTheme := f.find(filepath.Dir(filePath))
+	rel := filepath.Base(filePath)
+	if inTheme {
+		if r, err := filepath.Rel(theme.dir, filePath); err == nil {
+			rel = filepath.ToSlash(r)
+		}
+	}
+	tpl := Template{File: rel, Theme: theme.name, FilePath: filePath}
+
+	if m := templateNameRe.FindSubmatch(header); m != nil {
+		tpl.Kind = "custom"
+		tpl.Name = string(m[1])
+		tpl.Description = fmt.Sprintf("Custom page template %q", tpl.Name)
+		return tpl, true
+	}
+	if !inTheme {
+		return Template{}, false
+	}
+	if strings.HasPrefix(rel, "template-parts/") {
+		tpl.Kind = "template-part"
+		tpl.Description = "Template part loaded with get_template_part()"
+		return tpl, true
+	}
+	if strings.Contains(rel, "/") {
+		// Hierarchy files live in the theme root
+		return Template{}, false
+	}
+	for _, t := range templateHierarchy {
+		m := t.re.FindStringSubmatch(rel)
+		if m == nil {
+			continue
+		}
+		tpl.Kind = t.kind
+		tpl.Description = t.description
+		if strings.Contains(t.description, "%s") {
+			tpl.Description = fmt.Sprintf(t.description, m[1])
+		}
+		return tpl, true
+	}
+	return Template{}, false
+}
+
+func readHeader(path string) []byte {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+	buf := make([]byte, headerSize)
+	n, _ := file.Read(buf)
+	return bytes.TrimPrefix(buf[:n], []byte("\xef\xbb\xbf"))
+}
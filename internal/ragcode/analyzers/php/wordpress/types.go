@@ -0,0 +1,63 @@
+package wordpress
+
+// Hook kinds
+const (
+	KindAction = "action"
+	KindFilter = "filter"
+)
+
+// Info contains the WordPress-specific features extracted from a project
+type Info struct {
+	Hooks      []Hook      `json:"hooks"`
+	Shortcodes []Shortcode `json:"shortcodes,omitempty"`
+	Templates  []Template  `json:"templates,omitempty"`
+}
+
+// Empty reports whether no WordPress features were found
+func (i *Info) Empty() bool {
+	return i == nil || len(i.Hooks) == 0 && len(i.Shortcodes) == 0 && len(i.Templates) == 0
+}
+
+// Hook is a registration (add_action, add_filter) or a trigger (do_action,
+// apply_filters) of a WordPress hook
+type Hook struct {
+	Name         string `json:"name"`                    // Hook name, dynamic parts as {$var}
+	Kind         string `json:"kind"`                    // action or filter
+	Call         string `json:"call"`                    // add_action, apply_filters, ...
+	Callback     string `json:"callback,omitempty"`      // my_init, Class::method or Closure
+	Priority     int    `json:"priority,omitempty"`      // Registrations only (default 10)
+	AcceptedArgs int    `json:"accepted_args,omitempty"` // Registrations only (default 1)
+	FilePath     string `json:"file_path"`
+	StartLine    int    `json:"start_line"`
+	EndLine      int    `json:"end_line"`
+	CallbackFile string `json:"callback_file,omitempty"` // Where the callback is defined, if found
+	CallbackLine int    `json:"callback_line,omitempty"`
+}
+
+// IsTrigger reports whether the hook is fired (do_action, apply_filters)
+// rather than registered
+func (h Hook) IsTrigger() bool {
+	return hookCalls[h.Call].trigger
+}
+
+// Shortcode is a shortcode registered with add_shortcode
+type Shortcode struct {
+	Tag          string `json:"tag"`
+	Callback     string `json:"callback"`
+	FilePath     string `json:"file_path"`
+	StartLine    int    `json:"start_line"`
+	EndLine      int    `json:"end_line"`
+	CallbackFile string `json:"callback_file,omitempty"`
+	CallbackLine int    `json:"callback_line,omitempty"`
+}
+
+// Template is a theme file of the WordPress template hierarchy, or a custom
+// page template (a "Template Name:" header)
+type Template struct {
+	File        string `json:"file"`           // Path relative to the theme root
+	Kind        string `json:"kind"`           // single, archive, page, template-part, custom, ...
+	Name        string `json:"name,omitempty"` // Custom page templates only
+	Description string `json:"description"`
+	Theme       string `json:"theme,omitempty"`
+	FilePath    string `json:"file_path"`
+}
@@ -6,6 +6,7 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strings"
 	"unicode"
 
@@ -16,12 +17,22 @@ import (
 var (
 	importRe     = regexp.MustCompile(`^import\s+(.+)$`)
 	fromImportRe = regexp.MustCompile(`^from\s+(\S+)\s+import\s+(.+)$`)
+	defRe        = regexp.MustCompile(`^(?:async\s+)?def\s+(\w+)\s*\(`)
+	decoratorRe  = regexp.MustCompile(`^@(\w+(?:\.\w+)*)(?:\(.*\))?$`)
+	// brokenRe matches the start of a def or class header that does not
+	// parse, e.g. one being typed
+	brokenRe = regexp.MustCompile(`^(?:async\s+)?(def|class)\s+(\w+)`)
 )
 
+// maxHeaderLines bounds how many physical lines a decorator or def/class
+// header may span, so an unbalanced bracket can't swallow the rest of a file
+const maxHeaderLines = 100
+
 // CodeAnalyzer implements PathAnalyzer for Python
 type CodeAnalyzer struct {
 	modules      map[string]*ModuleInfo
 	includeTests bool // Option to include test files
+	parseErrors  []codetypes.ParseError
 }
 
 // NewCodeAnalyzer creates a new Python code analyzer
@@ -44,6 +55,7 @@ func NewCodeAnalyzerWithOptions(includeTests bool) *CodeAnalyzer {
 func (ca *CodeAnalyzer) AnalyzePaths(paths []string) ([]codetypes.CodeChunk, error) {
 	// Reset state for global analysis
 	ca.modules = make(map[string]*ModuleInfo)
+	ca.parseErrors = nil
 
 	for _, root := range paths {
 		info, err := os.Stat(root)
@@ -111,12 +123,15 @@ func (ca *CodeAnalyzer) AnalyzePaths(paths []string) ([]codetypes.CodeChunk, err
 		}
 	}
 
-	return ca.convertToChunks(), nil
+	chunks := ca.convertToChunks()
+	codetypes.MarkPartial(chunks, ca.parseErrors)
+	return chunks, nil
 }
 
 // AnalyzeFile analyzes a single Python file
 func (ca *CodeAnalyzer) AnalyzeFile(filePath string) ([]codetypes.CodeChunk, error) {
 	ca.modules = make(map[string]*ModuleInfo)
+	ca.parseErrors = nil
 
 	content, err := os.ReadFile(filePath)
 	if err != nil {
@@ -127,7 +142,77 @@ func (ca *CodeAnalyzer) AnalyzeFile(filePath string) ([]codetypes.CodeChunk, err
 		return nil, err
 	}
 
-	return ca.convertToChunks(), nil
+	chunks := ca.convertToChunks()
+	codetypes.MarkPartial(chunks, ca.parseErrors)
+	return chunks, nil
+}
+
+// ParseErrors implements codetypes.ParseErrorReporter: the module-level def
+// and class headers of the last AnalyzePaths call that do not parse. Their
+// symbols are still indexed by name, flagged partial.
+func (ca *CodeAnalyzer) ParseErrors() []codetypes.ParseError {
+	sort.SliceStable(ca.parseErrors, func(i, j int) bool {
+		a, b := ca.parseErrors[i], ca.parseErrors[j]
+		if a.FilePath != b.FilePath {
+			return a.FilePath < b.FilePath
+		}
+		return a.Line < b.Line
+	})
+	return ca.parseErrors
+}
+
+// Imports implements codetypes.ImportReporter: the modules each module of
+// the last AnalyzePaths call imports, relative imports resolved. A package's
+// __init__.py is named after the package.
+func (ca *CodeAnalyzer) Imports() []codetypes.FileImports {
+	out := make([]codetypes.FileImports, 0, len(ca.modules))
+	for _, mod := range ca.modules {
+		name := strings.TrimSuffix(mod.Name, ".__init__")
+		fi := codetypes.FileImports{FilePath: mod.Path, Language: codetypes.LanguagePython, Package: name}
+		for _, imp := range mod.Imports {
+			if module := resolveImport(mod.Name, imp.Module); module != "" {
+				fi.Imports = append(fi.Imports, module)
+			}
+		}
+		out = append(out, fi)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].FilePath < out[j].FilePath })
+	return out
+}
+
+// resolveImport resolves the module of a relative import ("from .b import
+// c", "from .. import d") against the importing module
+func resolveImport(importer, module string) string {
+	dots := len(module) - len(strings.TrimLeft(module, "."))
+	if dots == 0 {
+		return module
+	}
+	parts := strings.Split(importer, ".")
+	// One dot is the package of the importer, each further dot its parent
+	if dots >= len(parts) {
+		return ""
+	}
+	base := strings.Join(parts[:len(parts)-dots], ".")
+	if rest := module[dots:]; rest != "" {
+		return base + "." + rest
+	}
+	return base
+}
+
+// brokenHeader is the indentation-based fallback for a module-level def or
+// class header that does not parse: it records the syntax error and returns
+// the symbol name, so the block below the header is still indexed.
+func (ca *CodeAnalyzer) brokenHeader(filePath, line string, idx int, keyword string) (string, bool) {
+	m := brokenRe.FindStringSubmatch(line)
+	if m == nil || m[1] != keyword {
+		return "", false
+	}
+	ca.parseErrors = append(ca.parseErrors, codetypes.ParseError{
+		FilePath: filePath,
+		Line:     idx + 1,
+		Message:  fmt.Sprintf("invalid %s header", keyword),
+	})
+	return m[2], true
 }
 
 // GetModules returns the internal module information
@@ -340,23 +425,30 @@ func (ca *CodeAnalyzer) extractClasses(lines []string, filePath string, content
 	var classes []ClassInfo
 
 	classRe := regexp.MustCompile(`^class\s+(\w+)(?:\s*\(([^)]*)\))?\s*:`)
-	decoratorRe := regexp.MustCompile(`^@(\w+(?:\.\w+)*)(?:\(.*\))?$`)
 
 	var currentDecorators []string
 
 	for i := 0; i < len(lines); i++ {
 		line := lines[i]
 		trimmed := strings.TrimSpace(line)
+		header, headerEnd := headerLine(lines, i)
 
 		// Collect decorators
-		if matches := decoratorRe.FindStringSubmatch(trimmed); matches != nil {
+		if matches := decoratorRe.FindStringSubmatch(header); matches != nil {
 			currentDecorators = append(currentDecorators, matches[1])
+			i = headerEnd
 			continue
 		}
 
 		// Check for class definition (must be at module level - no indentation)
 		if !strings.HasPrefix(line, " ") && !strings.HasPrefix(line, "\t") {
-			if matches := classRe.FindStringSubmatch(trimmed); matches != nil {
+			matches := classRe.FindStringSubmatch(header)
+			if matches == nil {
+				if name, ok := ca.brokenHeader(filePath, trimmed, i, "class"); ok {
+					matches = []string{trimmed, name, ""}
+				}
+			}
+			if matches != nil {
 				className := matches[1]
 				basesStr := ""
 				if len(matches) > 2 {
@@ -380,8 +472,8 @@ func (ca *CodeAnalyzer) extractClasses(lines []string, filePath string, content
 
 				// Extract class docstring
 				docstring := ""
-				if i+1 < len(lines) {
-					docstring = ca.extractDocstring(lines, i+1)
+				if headerEnd+1 < len(lines) {
+					docstring = ca.extractDocstring(lines, headerEnd+1)
 				}
 
 				// Check for special decorators
@@ -433,15 +525,16 @@ func (ca *CodeAnalyzer) extractClasses(lines []string, filePath string, content
 				}
 
 				// Extract methods and properties
-				classInfo.Methods = ca.extractMethods(lines, i, endLine-1, className, filePath, content)
+				classInfo.Methods = ca.extractMethods(lines, headerEnd, endLine-1, className, filePath, content)
 				classInfo.Properties = ca.extractProperties(classInfo.Methods)
-				classInfo.ClassVars = ca.extractClassVariables(lines, i, endLine-1, filePath)
+				classInfo.ClassVars = ca.extractClassVariables(lines, headerEnd, endLine-1, filePath)
 
 				// Extract class dependencies (after methods are extracted)
 				classInfo.Dependencies = ca.extractClassDependencies(&classInfo, nil)
 
 				classes = append(classes, classInfo)
 				currentDecorators = nil
+				i = headerEnd
 			} else if trimmed != "" && !strings.HasPrefix(trimmed, "#") && !strings.HasPrefix(trimmed, "@") {
 				// Reset decorators if we hit a non-decorator, non-class line
 				currentDecorators = nil
@@ -456,30 +549,23 @@ func (ca *CodeAnalyzer) extractClasses(lines []string, filePath string, content
 func (ca *CodeAnalyzer) extractMethods(lines []string, classStartIdx, classEndIdx int, className, filePath string, content []byte) []MethodInfo {
 	var methods []MethodInfo
 
-	funcRe := regexp.MustCompile(`^\s+(?:async\s+)?def\s+(\w+)\s*\(([^)]*)\)(?:\s*->\s*(\S+))?\s*:`)
-	decoratorRe := regexp.MustCompile(`^\s+@(\w+(?:\.\w+)*)(?:\(.*\))?$`)
-
 	var currentDecorators []string
 
 	for i := classStartIdx + 1; i <= classEndIdx && i < len(lines); i++ {
 		line := lines[i]
 		trimmed := strings.TrimSpace(line)
+		indented := getIndentation(line) > 0
+		header, headerEnd := headerLine(lines, i)
 
 		// Collect decorators
-		if matches := decoratorRe.FindStringSubmatch(line); matches != nil {
+		if matches := decoratorRe.FindStringSubmatch(header); indented && matches != nil {
 			currentDecorators = append(currentDecorators, matches[1])
+			i = headerEnd
 			continue
 		}
 
 		// Check for method definition
-		if matches := funcRe.FindStringSubmatch(line); matches != nil {
-			methodName := matches[1]
-			paramsStr := matches[2]
-			returnType := ""
-			if len(matches) > 3 {
-				returnType = matches[3]
-			}
-
+		if methodName, paramsStr, returnType, ok := parseDefHeader(header); indented && ok {
 			// Parse parameters
 			params := ca.parseParameters(paramsStr)
 
@@ -488,7 +574,7 @@ func (ca *CodeAnalyzer) extractMethods(lines []string, classStartIdx, classEndId
 			isClassMethod := false
 			isProperty := false
 			isAbstract := false
-			isAsync := strings.Contains(line, "async def")
+			isAsync := strings.HasPrefix(header, "async ")
 
 			for _, dec := range currentDecorators {
 				switch dec {
@@ -514,15 +600,15 @@ func (ca *CodeAnalyzer) extractMethods(lines []string, classStartIdx, classEndId
 
 			// Extract docstring
 			docstring := ""
-			if i+1 < len(lines) {
-				docstring = ca.extractDocstring(lines, i+1)
+			if headerEnd+1 < len(lines) {
+				docstring = ca.extractDocstring(lines, headerEnd+1)
 			}
 
 			// Build signature
 			signature := ca.buildMethodSignature(methodName, params, returnType, isAsync)
 
 			// Extract method calls and type dependencies
-			calls := ca.extractMethodCalls(lines, i+1, endLine-1)
+			calls := ca.extractMethodCalls(lines, headerEnd+1, endLine-1)
 			typeDeps := ca.extractTypeDependencies(params, returnType)
 
 			methodInfo := MethodInfo{
@@ -548,6 +634,7 @@ func (ca *CodeAnalyzer) extractMethods(lines []string, classStartIdx, classEndId
 
 			methods = append(methods, methodInfo)
 			currentDecorators = nil
+			i = headerEnd
 		} else if trimmed != "" && !strings.HasPrefix(trimmed, "#") && !strings.HasPrefix(trimmed, "@") {
 			currentDecorators = nil
 		}
@@ -664,80 +751,78 @@ func (ca *CodeAnalyzer) extractClassVariables(lines []string, classStartIdx, cla
 func (ca *CodeAnalyzer) extractFunctions(lines []string, filePath string, content []byte) []FunctionInfo {
 	var functions []FunctionInfo
 
-	funcRe := regexp.MustCompile(`^(?:async\s+)?def\s+(\w+)\s*\(([^)]*)\)(?:\s*->\s*(\S+))?\s*:`)
-	decoratorRe := regexp.MustCompile(`^@(\w+(?:\.\w+)*)(?:\(.*\))?$`)
-
 	var currentDecorators []string
 
 	for i := 0; i < len(lines); i++ {
 		line := lines[i]
 		trimmed := strings.TrimSpace(line)
 
-		// Collect decorators (at module level - no indentation)
-		if !strings.HasPrefix(line, " ") && !strings.HasPrefix(line, "\t") {
-			if matches := decoratorRe.FindStringSubmatch(trimmed); matches != nil {
-				currentDecorators = append(currentDecorators, matches[1])
-				continue
-			}
+		// Functions and their decorators are at module level (no indentation)
+		if strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t") {
+			continue
 		}
+		header, headerEnd := headerLine(lines, i)
 
-		// Check for function definition at module level (no indentation)
-		if !strings.HasPrefix(line, " ") && !strings.HasPrefix(line, "\t") {
-			if matches := funcRe.FindStringSubmatch(trimmed); matches != nil {
-				funcName := matches[1]
-				paramsStr := matches[2]
-				returnType := ""
-				if len(matches) > 3 {
-					returnType = matches[3]
-				}
-
-				// Parse parameters
-				params := ca.parseParameters(paramsStr)
-
-				isAsync := strings.HasPrefix(trimmed, "async ")
+		// Collect decorators
+		if matches := decoratorRe.FindStringSubmatch(header); matches != nil {
+			currentDecorators = append(currentDecorators, matches[1])
+			i = headerEnd
+			continue
+		}
 
-				// Find function end
-				startLine := i + 1
-				endLine := ca.findBlockEnd(lines, i)
+		// Check for function definition at module level (no indentation)
+		funcName, paramsStr, returnType, ok := parseDefHeader(header)
+		if !ok {
+			funcName, ok = ca.brokenHeader(filePath, trimmed, i, "def")
+		}
+		if ok {
+			// Parse parameters
+			params := ca.parseParameters(paramsStr)
 
-				// Extract docstring
-				docstring := ""
-				if i+1 < len(lines) {
-					docstring = ca.extractDocstring(lines, i+1)
-				}
+			isAsync := strings.HasPrefix(header, "async ")
 
-				// Check for generator (yield keyword)
-				isGenerator := false
-				for j := i + 1; j < endLine && j < len(lines); j++ {
-					if strings.Contains(lines[j], "yield") {
-						isGenerator = true
-						break
-					}
-				}
+			// Find function end
+			startLine := i + 1
+			endLine := ca.findBlockEnd(lines, i)
 
-				// Build signature
-				signature := ca.buildFunctionSignature(funcName, params, returnType, isAsync)
+			// Extract docstring
+			docstring := ""
+			if headerEnd+1 < len(lines) {
+				docstring = ca.extractDocstring(lines, headerEnd+1)
+			}
 
-				funcInfo := FunctionInfo{
-					Name:        funcName,
-					Signature:   signature,
-					Description: docstring,
-					Parameters:  params,
-					ReturnType:  returnType,
-					Decorators:  currentDecorators,
-					IsAsync:     isAsync,
-					IsGenerator: isGenerator,
-					FilePath:    filePath,
-					StartLine:   startLine,
-					EndLine:     endLine,
-					Code:        extractCodeFromContent(content, startLine, endLine),
+			// Check for generator (yield keyword)
+			isGenerator := false
+			for j := headerEnd + 1; j < endLine && j < len(lines); j++ {
+				if strings.Contains(lines[j], "yield") {
+					isGenerator = true
+					break
 				}
+			}
 
-				functions = append(functions, funcInfo)
-				currentDecorators = nil
-			} else if trimmed != "" && !strings.HasPrefix(trimmed, "#") && !strings.HasPrefix(trimmed, "@") {
-				currentDecorators = nil
+			// Build signature
+			signature := ca.buildFunctionSignature(funcName, params, returnType, isAsync)
+
+			funcInfo := FunctionInfo{
+				Name:        funcName,
+				Signature:   signature,
+				Description: docstring,
+				Parameters:  params,
+				ReturnType:  returnType,
+				Decorators:  currentDecorators,
+				IsAsync:     isAsync,
+				IsGenerator: isGenerator,
+				FilePath:    filePath,
+				StartLine:   startLine,
+				EndLine:     endLine,
+				Code:        extractCodeFromContent(content, startLine, endLine),
 			}
+
+			functions = append(functions, funcInfo)
+			currentDecorators = nil
+			i = headerEnd
+		} else if trimmed != "" && !strings.HasPrefix(trimmed, "#") && !strings.HasPrefix(trimmed, "@") {
+			currentDecorators = nil
 		}
 	}
 
@@ -924,8 +1009,11 @@ func (ca *CodeAnalyzer) findBlockEnd(lines []string, startIdx int) int {
 	// Get the indentation of the block header
 	baseIndent := getIndentation(lines[startIdx])
 
-	endLine := startIdx + 1
-	for i := startIdx + 1; i < len(lines); i++ {
+	// The body starts after the header, which may span several lines
+	_, headerEnd := headerLine(lines, startIdx)
+
+	endLine := headerEnd + 1
+	for i := headerEnd + 1; i < len(lines); i++ {
 		line := lines[i]
 
 		// Skip empty lines
@@ -955,8 +1043,11 @@ func (ca *CodeAnalyzer) findMethodEnd(lines []string, startIdx int) int {
 	// Get the indentation of the method definition
 	baseIndent := getIndentation(lines[startIdx])
 
-	endLine := startIdx + 1
-	for i := startIdx + 1; i < len(lines); i++ {
+	// The body starts after the header, which may span several lines
+	_, headerEnd := headerLine(lines, startIdx)
+
+	endLine := headerEnd + 1
+	for i := headerEnd + 1; i < len(lines); i++ {
 		line := lines[i]
 
 		// Skip empty lines
@@ -977,6 +1068,123 @@ func (ca *CodeAnalyzer) findMethodEnd(lines []string, startIdx int) int {
 	return endLine
 }
 
+// headerLine returns the decorator or def/class header starting at idx as a
+// single line, joining the physical lines it spans while brackets are open
+// (e.g. one parameter per line) or a line ends with a backslash, and the
+// index of its last line. Comments are dropped. Other statements are
+// returned as the trimmed line.
+func headerLine(lines []string, idx int) (string, int) {
+	trimmed := strings.TrimSpace(lines[idx])
+	if !strings.HasPrefix(trimmed, "@") && !strings.HasPrefix(trimmed, "def ") &&
+		!strings.HasPrefix(trimmed, "async def ") && !strings.HasPrefix(trimmed, "class ") {
+		return trimmed, idx
+	}
+
+	var parts []string
+	depth := 0
+	end := idx
+	for ; end < len(lines) && end-idx < maxHeaderLines; end++ {
+		var sb strings.Builder
+		var quote rune
+		escaped := false
+	scan:
+		for _, ch := range lines[end] {
+			switch {
+			case quote != 0:
+				if escaped {
+					escaped = false
+				} else if ch == '\\' {
+					escaped = true
+				} else if ch == quote {
+					quote = 0
+				}
+			case ch == '#':
+				break scan
+			case ch == '"' || ch == '\'':
+				quote = ch
+			case ch == '(' || ch == '[' || ch == '{':
+				depth++
+			case ch == ')' || ch == ']' || ch == '}':
+				depth--
+			}
+			sb.WriteRune(ch)
+		}
+		part := strings.TrimSpace(sb.String())
+		continued := strings.HasSuffix(part, "\\")
+		if part = strings.TrimSpace(strings.TrimSuffix(part, "\\")); part != "" {
+			parts = append(parts, part)
+		}
+		if depth <= 0 && !continued {
+			break
+		}
+	}
+	if end >= len(lines) || end-idx >= maxHeaderLines {
+		// Unbalanced brackets: fall back to the single line
+		return trimmed, idx
+	}
+
+	header := strings.Join(parts, " ")
+	// "( a" and "b )" from joined lines
+	header = strings.ReplaceAll(strings.ReplaceAll(header, "( ", "("), " )", ")")
+	return header, end
+}
+
+// parseDefHeader splits a def header into the function name, parameter list
+// and return annotation. The parameter list ends at the matching ")", so
+// defaults like timeout=Timeout(5) and annotations with spaces are kept.
+func parseDefHeader(header string) (name, params, returnType string, ok bool) {
+	loc := defRe.FindStringSubmatchIndex(header)
+	if loc == nil {
+		return "", "", "", false
+	}
+	name = header[loc[2]:loc[3]]
+
+	depth := 1
+	closing := -1
+	for j := loc[1]; j < len(header) && closing < 0; j++ {
+		switch header[j] {
+		case '(', '[', '{':
+			depth++
+		case ')', ']', '}':
+			if depth--; depth == 0 {
+				closing = j
+			}
+		}
+	}
+	if closing < 0 {
+		return "", "", "", false
+	}
+	params = header[loc[1]:closing]
+
+	rest := strings.TrimSpace(header[closing+1:])
+	if strings.HasPrefix(rest, "->") {
+		rest = strings.TrimSpace(rest[2:])
+		depth = 0
+		colon := -1
+		for j := 0; j < len(rest) && colon < 0; j++ {
+			switch rest[j] {
+			case '(', '[', '{':
+				depth++
+			case ')', ']', '}':
+				depth--
+			case ':':
+				if depth == 0 {
+					colon = j
+				}
+			}
+		}
+		if colon < 0 {
+			return "", "", "", false
+		}
+		returnType = strings.TrimSpace(rest[:colon])
+		rest = rest[colon:]
+	}
+	if !strings.HasPrefix(rest, ":") {
+		return "", "", "", false
+	}
+	return name, params, returnType, true
+}
+
 // buildMethodSignature creates a method signature string
 func (ca *CodeAnalyzer) buildMethodSignature(name string, params []codetypes.ParamInfo, returnType string, isAsync bool) string {
 	var sig strings.Builder
@@ -1022,7 +1230,7 @@ func (ca *CodeAnalyzer) convertToChunks() []codetypes.CodeChunk {
 			chunk := codetypes.CodeChunk{
 				Name:      class.Name,
 				Type:      "class",
-				Language:  "python",
+				Language:  codetypes.LanguagePython,
 				Package:   module.Name,
 				FilePath:  class.FilePath,
 				StartLine: class.StartLine,
@@ -1065,7 +1273,7 @@ func (ca *CodeAnalyzer) convertToChunks() []codetypes.CodeChunk {
 				methodChunk := codetypes.CodeChunk{
 					Name:      method.Name,
 					Type:      "method",
-					Language:  "python",
+					Language:  codetypes.LanguagePython,
 					Package:   module.Name,
 					FilePath:  method.FilePath,
 					StartLine: method.StartLine,
@@ -1091,7 +1299,7 @@ func (ca *CodeAnalyzer) convertToChunks() []codetypes.CodeChunk {
 				propChunk := codetypes.CodeChunk{
 					Name:      prop.Name,
 					Type:      "property",
-					Language:  "python",
+					Language:  codetypes.LanguagePython,
 					Package:   module.Name,
 					FilePath:  prop.FilePath,
 					StartLine: prop.StartLine,
@@ -1113,7 +1321,7 @@ func (ca *CodeAnalyzer) convertToChunks() []codetypes.CodeChunk {
 			chunk := codetypes.CodeChunk{
 				Name:      fn.Name,
 				Type:      "function",
-				Language:  "python",
+				Language:  codetypes.LanguagePython,
 				Package:   module.Name,
 				FilePath:  fn.FilePath,
 				StartLine: fn.StartLine,
@@ -1135,7 +1343,7 @@ func (ca *CodeAnalyzer) convertToChunks() []codetypes.CodeChunk {
 			chunk := codetypes.CodeChunk{
 				Name:      c.Name,
 				Type:      "const",
-				Language:  "python",
+				Language:  codetypes.LanguagePython,
 				Package:   module.Name,
 				FilePath:  c.FilePath,
 				StartLine: c.StartLine,
@@ -1152,7 +1360,7 @@ func (ca *CodeAnalyzer) convertToChunks() []codetypes.CodeChunk {
 			chunk := codetypes.CodeChunk{
 				Name:      v.Name,
 				Type:      "var",
-				Language:  "python",
+				Language:  codetypes.LanguagePython,
 				Package:   module.Name,
 				FilePath:  v.FilePath,
 				StartLine: v.StartLine,
@@ -1184,12 +1392,7 @@ func extractCodeFromContent(content []byte, startLine, endLine int) string {
 		endLine = len(lines)
 	}
 
-	// Limit code extraction to avoid huge chunks
-	maxLines := 100
-	if endLine-startLine > maxLines {
-		endLine = startLine + maxLines
-	}
-
+	// Large symbols are capped at index time (rag_code.max_chunk_lines)
 	return strings.Join(lines[startLine-1:endLine], "\n")
 }
 
@@ -1263,7 +1466,7 @@ func (ca *CodeAnalyzer) extractMetaclass(lines []string, classLineIdx int) strin
 		return ""
 	}
 
-	line := lines[classLineIdx]
+	line, _ := headerLine(lines, classLineIdx)
 
 	// Look for metaclass= in class definition
 	// class Foo(metaclass=ABCMeta):
@@ -98,6 +98,21 @@ from pathlib import Path`
 	}
 }
 
+func TestResolveImport(t *testing.T) {
+	tests := []struct{ importer, module, want string }{
+		{"app.views", "app.models", "app.models"},
+		{"app.views", ".models", "app.models"},
+		{"app.api.views", "..models", "app.models"},
+		{"app.views", ".", "app"},
+		{"views", "..models", ""},
+	}
+	for _, tt := range tests {
+		if got := resolveImport(tt.importer, tt.module); got != tt.want {
+			t.Errorf("resolveImport(%q, %q) = %q, want %q", tt.importer, tt.module, got, tt.want)
+		}
+	}
+}
+
 func TestExtractClasses(t *testing.T) {
 	analyzer := NewCodeAnalyzer()
 
@@ -946,3 +961,121 @@ class Order(BaseModel):
 		t.Error("BaseModel not found in User dependencies")
 	}
 }
+
+func TestMultiLineSignatures(t *testing.T) {
+	analyzer := NewCodeAnalyzer()
+
+	content := `@app.route(
+    "/orders",
+    methods=["GET", "POST"],
+)
+def handle_orders(
+    request: Request,
+    limit: int = 10,  # page size
+    timeout=Timeout(5),
+) -> Dict[str, int]:
+    """Lists orders."""
+    return fetch(request)
+
+class Repository(
+    Base,
+    metaclass=ABCMeta,
+):
+    """Stores orders."""
+
+    @retry(
+        attempts=3,
+    )
+    async def save(
+        self,
+        order: Order,
+    ) -> None:
+        """Saves an order."""
+        self.flush()
+
+def after():
+    pass
+`
+
+	lines := strings.Split(content, "\n")
+	functions := analyzer.extractFunctions(lines, "test.py", []byte(content))
+	if len(functions) != 2 {
+		t.Fatalf("expected 2 functions, got %d: %+v", len(functions), functions)
+	}
+	fn := functions[0]
+	if fn.Name != "handle_orders" || len(fn.Parameters) != 3 || fn.ReturnType != "Dict[str, int]" {
+		t.Errorf("handle_orders parsed as %+v", fn)
+	}
+	if len(fn.Decorators) != 1 || fn.Decorators[0] != "app.route" {
+		t.Errorf("decorators = %v", fn.Decorators)
+	}
+	if fn.Description != "Lists orders." || fn.StartLine != 5 || fn.EndLine != 12 {
+		t.Errorf("handle_orders doc %q, lines %d-%d", fn.Description, fn.StartLine, fn.EndLine)
+	}
+	if functions[1].Name != "after" || len(functions[1].Decorators) != 0 {
+		t.Errorf("expected undecorated after(), got %+v", functions[1])
+	}
+
+	classes := analyzer.extractClasses(lines, "test.py", []byte(content))
+	if len(classes) != 1 {
+		t.Fatalf("expected 1 class, got %d", len(classes))
+	}
+	class := classes[0]
+	if len(class.Bases) != 2 || class.Metaclass != "ABCMeta" || class.Description != "Stores orders." {
+		t.Errorf("Repository parsed as bases %v, metaclass %q, doc %q", class.Bases, class.Metaclass, class.Description)
+	}
+	if len(class.ClassVars) != 0 {
+		t.Errorf("header continuation parsed as class variables: %+v", class.ClassVars)
+	}
+	if len(class.Methods) != 1 {
+		t.Fatalf("expected 1 method, got %d", len(class.Methods))
+	}
+	m := class.Methods[0]
+	if m.Name != "save" || !m.IsAsync || len(m.Parameters) != 2 || m.ReturnType != "None" || m.Description != "Saves an order." {
+		t.Errorf("save parsed as %+v", m)
+	}
+	if len(m.Decorators) != 1 || m.Decorators[0] != "retry" || m.StartLine != 22 || m.EndLine != 28 {
+		t.Errorf("save decorators %v, lines %d-%d", m.Decorators, m.StartLine, m.EndLine)
+	}
+}
+
+func TestBrokenHeadersArePartial(t *testing.T) {
+	tmpDir := t.TempDir()
+	pyFile := filepath.Join(tmpDir, "orders.py")
+	content := `def total(items):
+    return sum(items)
+
+def refund(order, amount
+    return order.pay(-amount)
+
+class Cart(Base
+    def add(self, item):
+        pass
+`
+	if err := os.WriteFile(pyFile, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	analyzer := NewCodeAnalyzer()
+	chunks, err := analyzer.AnalyzeFile(pyFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	found := make(map[string]bool)
+	for _, ch := range chunks {
+		found[ch.Type+" "+ch.Name] = true
+		if !ch.Partial() {
+			t.Errorf("%s %s of a file with syntax errors should be flagged partial", ch.Type, ch.Name)
+		}
+	}
+	for _, want := range []string{"function total", "function refund", "class Cart"} {
+		if !found[want] {
+			t.Errorf("missing %s in %v", want, found)
+		}
+	}
+
+	errs := analyzer.ParseErrors()
+	if len(errs) != 2 || errs[0].Line != 4 || errs[1].Line != 7 || errs[0].Skipped {
+		t.Errorf("parse errors = %+v", errs)
+	}
+}
@@ -0,0 +1,32 @@
+package python
+
+import (
+	"strings"
+	"testing"
+)
+
+// FuzzParse feeds arbitrary text to the line-based parser: it must not
+// panic, and every chunk must be named and lie within the source lines.
+func FuzzParse(f *testing.F) {
+	f.Add("class A:\n    def m(self):\n        pass\n")
+	f.Add("@decorator\ndef f(a, b=1, *args, **kw) -> int:\n    \"\"\"Doc.\"\"\"\n    return 1\n")
+	f.Add("async def g(\n    x,\n):\n    await x\n")
+	f.Add("class Broken(\n    def oops\nX = 1\n")
+	f.Add("\"\"\"unterminated\nclass C:\n\tdef\n")
+	f.Fuzz(func(t *testing.T, src string) {
+		ca := NewCodeAnalyzer()
+		ca.modules = make(map[string]*ModuleInfo)
+		if err := ca.parseAndCollect("fuzz.py", []byte(src)); err != nil {
+			return
+		}
+		lines := strings.Count(src, "\n") + 1
+		for _, ch := range ca.convertToChunks() {
+			if ch.Name == "" || ch.Type == "" {
+				t.Fatalf("chunk without name or type: %+v", ch)
+			}
+			if ch.StartLine < 1 || ch.EndLine < ch.StartLine || ch.EndLine > lines {
+				t.Fatalf("%s %s: lines %d-%d outside the %d source lines", ch.Type, ch.Name, ch.StartLine, ch.EndLine, lines)
+			}
+		}
+	})
+}
@@ -0,0 +1,891 @@
+package rust
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/doITmagic/rag-code-mcp/internal/codetypes"
+)
+
+// Pre-compiled regex patterns for attributes and Cargo manifests
+var (
+	// #[deprecated], #[deprecated = "note"], #[deprecated(since = "1.2", note = "note")]
+	deprecatedRe = regexp.MustCompile(`^deprecated\b(?:\s*=\s*"([^"]*)"|\s*\(.*\bnote\s*=\s*"([^"]*)")?`)
+	// #[doc = "text"]
+	docAttrRe = regexp.MustCompile(`^doc\s*=\s*"(.*)"$`)
+	// name = "my-crate" in the [package] table of Cargo.toml
+	crateNameRe = regexp.MustCompile(`^name\s*=\s*"([^"]+)"`)
+)
+
+// CodeAnalyzer implements PathAnalyzer for Rust
+type CodeAnalyzer struct {
+	files        []*FileInfo
+	crates       map[string]string // Cargo.toml directory -> crate name ("" when not a package)
+	includeTests bool              // Option to include #[cfg(test)] modules and #[test] functions
+}
+
+// NewCodeAnalyzer creates a new Rust code analyzer
+func NewCodeAnalyzer() *CodeAnalyzer {
+	return &CodeAnalyzer{
+		crates:       make(map[string]string),
+		includeTests: false,
+	}
+}
+
+// NewCodeAnalyzerWithOptions creates a Rust code analyzer with options
+func NewCodeAnalyzerWithOptions(includeTests bool) *CodeAnalyzer {
+	return &CodeAnalyzer{
+		crates:       make(map[string]string),
+		includeTests: includeTests,
+	}
+}
+
+// AnalyzePaths implements the PathAnalyzer interface
+func (ca *CodeAnalyzer) AnalyzePaths(paths []string) ([]codetypes.CodeChunk, error) {
+	// Reset state for global analysis
+	ca.files = nil
+
+	for _, root := range paths {
+		info, err := os.Stat(root)
+		if err != nil {
+			return nil, fmt.Errorf("error accessing path %s: %w", root, err)
+		}
+
+		if !info.IsDir() {
+			if err := ca.analyzeFile(root); err != nil {
+				return nil, fmt.Errorf("error analyzing %s: %w", root, err)
+			}
+			continue
+		}
+
+		err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				if path != root && shouldSkipDir(d.Name()) {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if !strings.HasSuffix(d.Name(), ".rs") {
+				return nil
+			}
+			if err := ca.analyzeFile(path); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: failed to analyze %s: %v\n", path, err)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error walking directory %s: %w", root, err)
+		}
+	}
+
+	return ca.convertToChunks(), nil
+}
+
+// AnalyzeFile analyzes a single Rust file
+func (ca *CodeAnalyzer) AnalyzeFile(filePath string) ([]codetypes.CodeChunk, error) {
+	ca.files = nil
+	if err := ca.analyzeFile(filePath); err != nil {
+		return nil, err
+	}
+	return ca.convertToChunks(), nil
+}
+
+// GetFiles returns the internal file information
+func (ca *CodeAnalyzer) GetFiles() []*FileInfo {
+	return ca.files
+}
+
+// shouldSkipDir reports whether a directory holds build output, dependencies
+// or tooling data rather than sources
+func shouldSkipDir(name string) bool {
+	return strings.HasPrefix(name, ".") || name == "target" || name == "node_modules" || name == "vendor"
+}
+
+func (ca *CodeAnalyzer) analyzeFile(path string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read file: %w", err)
+	}
+	ca.files = append(ca.files, ca.parseFile(path, string(content)))
+	return nil
+}
+
+func (ca *CodeAnalyzer) parseFile(path, text string) *FileInfo {
+	module := ca.modulePath(path)
+	p := &parser{src: newSource(text), path: path, includeTests: ca.includeTests}
+	return &FileInfo{
+		Path:   path,
+		Module: module,
+		Items:  p.items(0, len(text), module),
+	}
+}
+
+// modulePath derives the module path of a file from the crate it belongs
+// to: src/lib.rs and src/main.rs are the crate root, src/net.rs and
+// src/net/mod.rs are <crate>::net. Files outside a Cargo package are named
+// after themselves.
+func (ca *CodeAnalyzer) modulePath(path string) string {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		abs = path
+	}
+	for dir := filepath.Dir(abs); ; {
+		if crate := ca.crateName(dir); crate != "" {
+			rel, err := filepath.Rel(dir, abs)
+			if err == nil {
+				return joinModule(crate, rel)
+			}
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+	}
+	return joinModule(filepath.Base(filepath.Dir(abs)), filepath.Base(abs))
+}
+
+// joinModule appends the module segments of a crate-relative file path to
+// the crate name
+func joinModule(crate, rel string) string {
+	parts := strings.Split(filepath.ToSlash(strings.TrimSuffix(rel, ".rs")), "/")
+	if len(parts) > 1 && (parts[0] == "src" || parts[0] == "bin") {
+		parts = parts[1:]
+	}
+	if len(parts) > 1 && parts[0] == "bin" {
+		parts = parts[1:]
+	}
+	switch parts[len(parts)-1] {
+	case "lib", "main", "mod":
+		parts = parts[:len(parts)-1]
+	}
+	if len(parts) == 0 {
+		return crate
+	}
+	return crate + "::" + strings.Join(parts, "::")
+}
+
+// crateName returns the package name declared by dir/Cargo.toml, with
+// dashes turned into underscores as rustc does, or "" when dir has no
+// package manifest (including virtual workspace manifests)
+func (ca *CodeAnalyzer) crateName(dir string) string {
+	if name, ok := ca.crates[dir]; ok {
+		return name
+	}
+	name := ""
+	if data, err := os.ReadFile(filepath.Join(dir, "Cargo.toml")); err == nil {
+		inPackage := false
+		scanner := bufio.NewScanner(bytes.NewReader(data))
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if strings.HasPrefix(line, "[") {
+				inPackage = line == "[package]"
+				continue
+			}
+			if m := crateNameRe.FindStringSubmatch(line); inPackage && m != nil {
+				name = strings.ReplaceAll(m[1], "-", "_")
+				break
+			}
+		}
+	}
+	ca.crates[dir] = name
+	return name
+}
+
+// parser extracts the items of one Rust source
+type parser struct {
+	src          *source
+	path         string
+	includeTests bool
+}
+
+// items scans the items between from and to, descending into inline mod
+// blocks. module is the path of the enclosing module.
+func (p *parser) items(from, to int, module string) []ItemInfo {
+	s := p.src
+	var out []ItemInfo
+	gap := from // end of the previous item: doc comments after it are ours
+	for pos := s.skipSpace(from, to); pos < to; pos = s.skipSpace(pos, to) {
+		start := pos
+		var attrs []string
+		var docs []string
+		for pos < to && s.masked[pos] == '#' {
+			j := s.skipSpace(pos+1, to)
+			inner := j < to && s.masked[j] == '!'
+			if inner {
+				j = s.skipSpace(j+1, to)
+			}
+			if j >= to || s.masked[j] != '[' {
+				break
+			}
+			e := s.matching(j, to)
+			// An unterminated attribute runs to the end of the range
+			attr := collapse(strings.TrimSuffix(s.text[j+1:e], "]"))
+			pos = s.skipSpace(e, to)
+			switch {
+			case inner:
+				// #![...] applies to the enclosing module
+				start, gap = pos, pos
+			case docAttrRe.MatchString(attr):
+				docs = append(docs, docAttrRe.FindStringSubmatch(attr)[1])
+			default:
+				attrs = append(attrs, attr)
+			}
+		}
+		if pos >= to {
+			break
+		}
+
+		sigStart := pos
+		var vis string
+		vis, pos = p.visibility(pos, to)
+		quals, kw, kwPos, next := p.keyword(pos, to)
+
+		it := ItemInfo{
+			Kind:       kw,
+			Module:     module,
+			Visibility: vis,
+			Attributes: attrs,
+			FilePath:   p.path,
+			IsAsync:    contains(quals, "async"),
+			IsUnsafe:   contains(quals, "unsafe"),
+			IsConst:    contains(quals, "const"),
+		}
+		end := -1
+		switch kw {
+		case "fn":
+			end = p.function(&it, sigStart, next, to)
+		case "struct", "union", "enum":
+			end = p.adt(&it, sigStart, next, to)
+		case "trait":
+			end = p.trait(&it, sigStart, next, to, module)
+		case "impl":
+			it.SelectionLine = s.line(kwPos)
+			end = p.impl(&it, sigStart, next, to, module)
+		case "type", "const", "static":
+			end = p.binding(&it, sigStart, next, to)
+		case "macro_rules!":
+			it.Kind = "macro"
+			end = p.macro(&it, sigStart, next, to)
+		case "mod":
+			name, n := s.word(s.skipSpace(next, to), to)
+			hpos, c := s.headerEnd(n, to)
+			if c != '{' {
+				// mod name; lives in its own file
+				pos, gap = min(hpos+1, to), min(hpos+1, to)
+				continue
+			}
+			end = s.matching(hpos, to)
+			if p.includeTests || !isTestOnly(attrs) {
+				out = append(out, p.items(hpos+1, end-1, module+"::"+name)...)
+			}
+			pos, gap = end, end
+			continue
+		}
+		if end < 0 || it.Name == "" {
+			// use, extern crate, extern blocks, macro invocations, ...
+			end = s.skipItem(kwPos, to)
+			pos, gap = max(end, kwPos+1), max(end, kwPos+1)
+			continue
+		}
+
+		if p.includeTests || !isTestOnly(attrs) {
+			it.Description = strings.TrimSpace(strings.Join(append([]string{s.docsIn(gap, kwPos)}, docs...), "\n"))
+			it.Derives = derives(attrs)
+			it.StartLine = s.line(start)
+			it.EndLine = s.line(max(end-1, start))
+			it.Code = s.text[start:end]
+			out = append(out, it)
+		}
+		pos, gap = end, end
+	}
+	return out
+}
+
+// visibility reads an optional pub, pub(crate), pub(super), pub(self) or
+// pub(in path) at pos
+func (p *parser) visibility(pos, to int) (string, int) {
+	s := p.src
+	w, next := s.word(pos, to)
+	if w != "pub" {
+		return "", pos
+	}
+	n := s.skipSpace(next, to)
+	if n < to && s.masked[n] == '(' {
+		scope, _ := s.word(s.skipSpace(n+1, to), to)
+		switch scope {
+		case "crate", "super", "self", "in":
+			e := s.matching(n, to)
+			return "pub(" + collapse(string(s.masked[n+1:e-1])) + ")", s.skipSpace(e, to)
+		}
+	}
+	return "pub", n
+}
+
+// keyword skips the qualifiers of an item (async, unsafe, const fn,
+// extern "C", default, auto) and returns them with the item keyword, its
+// offset and the offset after it
+func (p *parser) keyword(pos, to int) (quals []string, kw string, kwPos, next int) {
+	s := p.src
+	for {
+		w, n := s.word(pos, to)
+		after := s.skipSpace(n, to)
+		switch w {
+		case "default", "async", "unsafe", "auto":
+			quals = append(quals, w)
+			pos = after
+			continue
+		case "const":
+			switch nw, _ := s.word(after, to); nw {
+			case "fn", "unsafe", "async", "extern":
+				quals = append(quals, w)
+				pos = after
+				continue
+			}
+		case "extern":
+			// the ABI string is masked: extern "C" fn reads as extern fn
+			if nw, _ := s.word(after, to); nw != "crate" && nw != "" {
+				quals = append(quals, w)
+				pos = after
+				continue
+			}
+		}
+		return quals, w, pos, n
+	}
+}
+
+// function reads a fn header and body. It returns the offset after the
+// item.
+func (p *parser) function(it *ItemInfo, sigStart, next, to int) int {
+	s := p.src
+	n := s.skipSpace(next, to)
+	it.SelectionLine = s.line(n)
+	it.Name, n = s.word(n, to)
+	hpos, c := s.headerEnd(n, to)
+	it.Signature = collapse(s.text[sigStart:hpos])
+
+	n = s.skipSpace(n, hpos)
+	if n < hpos && s.masked[n] == '<' {
+		n = s.skipSpace(s.angleEnd(n, hpos), hpos)
+	}
+	if n < hpos && s.masked[n] == '(' {
+		closing := s.matching(n, hpos)
+		for _, sp := range s.splitTopLevel(n+1, closing-1) {
+			param := collapse(string(s.masked[sp.from:sp.to]))
+			switch {
+			case param == "":
+			case isReceiver(param):
+				it.Receiver = param
+			default:
+				name, typ := splitParam(param)
+				it.Parameters = append(it.Parameters, codetypes.ParamInfo{Name: name, Type: typ})
+			}
+		}
+		it.ReturnType = returnType(collapse(string(s.masked[closing:hpos])))
+	}
+	return itemEnd(s, hpos, c, to)
+}
+
+// adt reads a struct, union or enum with its fields or variants
+func (p *parser) adt(it *ItemInfo, sigStart, next, to int) int {
+	s := p.src
+	n := s.skipSpace(next, to)
+	it.SelectionLine = s.line(n)
+	it.Name, n = s.word(n, to)
+	if it.Name == "" {
+		return -1
+	}
+	hpos, c := s.headerEnd(n, to)
+	it.Signature = collapse(s.text[sigStart:hpos])
+
+	if c != '{' {
+		// tuple struct: struct Meters(pub f64);
+		n = s.skipSpace(n, hpos)
+		if n < hpos && s.masked[n] == '<' {
+			n = s.skipSpace(s.angleEnd(n, hpos), hpos)
+		}
+		if n < hpos && s.masked[n] == '(' {
+			closing := s.matching(n, hpos)
+			for i, sp := range s.splitTopLevel(n+1, closing-1) {
+				if f, ok := p.field(sp, false, true); ok {
+					f.Name = fmt.Sprintf("%d", i)
+					it.Fields = append(it.Fields, f)
+				}
+			}
+		}
+		return itemEnd(s, hpos, c, to)
+	}
+
+	closing := s.matching(hpos, to)
+	for _, sp := range s.splitTopLevel(hpos+1, closing-1) {
+		if it.Kind == "enum" {
+			if v, ok := p.field(sp, true, false); ok {
+				it.Variants = append(it.Variants, v)
+			}
+		} else if f, ok := p.field(sp, false, false); ok {
+			it.Fields = append(it.Fields, f)
+		}
+	}
+	return closing
+}
+
+// field reads a named field, a tuple field or an enum variant
+func (p *parser) field(sp span, variant, tuple bool) (FieldInfo, bool) {
+	s := p.src
+	pos := s.skipSpace(sp.from, sp.to)
+	for pos < sp.to && s.masked[pos] == '#' {
+		j := s.skipSpace(pos+1, sp.to)
+		if j >= sp.to || s.masked[j] != '[' {
+			break
+		}
+		pos = s.skipSpace(s.matching(j, sp.to), sp.to)
+	}
+	if pos >= sp.to {
+		return FieldInfo{}, false
+	}
+
+	f := FieldInfo{Description: s.docsIn(sp.from, pos)}
+	if !variant {
+		f.Visibility, pos = p.visibility(pos, sp.to)
+	}
+	if tuple {
+		f.Type = collapse(string(s.masked[pos:sp.to]))
+		return f, f.Type != ""
+	}
+	f.Name, pos = s.word(pos, sp.to)
+	if f.Name == "" {
+		return FieldInfo{}, false
+	}
+	rest := collapse(string(s.masked[pos:sp.to]))
+	if variant {
+		f.Type = rest
+	} else {
+		f.Type = strings.TrimSpace(strings.TrimPrefix(rest, ":"))
+	}
+	return f, true
+}
+
+// trait reads a trait with its supertraits and methods
+func (p *parser) trait(it *ItemInfo, sigStart, next, to int, module string) int {
+	s := p.src
+	n := s.skipSpace(next, to)
+	it.SelectionLine = s.line(n)
+	it.Name, n = s.word(n, to)
+	hpos, c := s.headerEnd(n, to)
+	it.Signature = collapse(s.text[sigStart:hpos])
+
+	bounds := strings.TrimSpace(skipGenerics(collapse(string(s.masked[n:hpos]))))
+	if strings.HasPrefix(bounds, ":") {
+		bounds = cutWhere(strings.TrimPrefix(bounds, ":"))
+		for _, b := range strings.Split(bounds, "+") {
+			if b = strings.TrimSpace(b); b != "" {
+				it.Supertraits = append(it.Supertraits, b)
+			}
+		}
+	}
+	if c != '{' {
+		return itemEnd(s, hpos, c, to)
+	}
+	closing := s.matching(hpos, to)
+	it.Methods = p.methods(hpos+1, closing-1, module)
+	return closing
+}
+
+// impl reads an impl block: impl<T> Trait for Type<T> where ... { ... }
+func (p *parser) impl(it *ItemInfo, sigStart, next, to int, module string) int {
+	s := p.src
+	hpos, c := s.headerEnd(next, to)
+	it.Signature = collapse(s.text[sigStart:hpos])
+
+	target := cutWhere(strings.TrimSpace(skipGenerics(collapse(string(s.masked[next:hpos])))))
+	if i := topLevelIndex(target, " for "); i >= 0 {
+		it.Trait, it.SelfType = strings.TrimSpace(target[:i]), strings.TrimSpace(target[i+len(" for "):])
+	} else {
+		it.SelfType = target
+	}
+	it.Name = typeName(it.SelfType)
+	if c != '{' {
+		return itemEnd(s, hpos, c, to)
+	}
+	closing := s.matching(hpos, to)
+	it.Methods = p.methods(hpos+1, closing-1, module)
+	return closing
+}
+
+// methods returns the fn items of a trait or impl body
+func (p *parser) methods(from, to int, module string) []ItemInfo {
+	var methods []ItemInfo
+	for _, m := range p.items(from, to, module) {
+		if m.Kind == "fn" {
+			methods = append(methods, m)
+		}
+	}
+	return methods
+}
+
+// binding reads a type alias, const or static
+func (p *parser) binding(it *ItemInfo, sigStart, next, to int) int {
+	s := p.src
+	n := s.skipSpace(next, to)
+	if w, after := s.word(n, to); it.Kind == "static" && w == "mut" {
+		n = s.skipSpace(after, to)
+	}
+	it.SelectionLine = s.line(n)
+	it.Name, n = s.word(n, to)
+	end := s.statementEnd(n, to)
+
+	header := end
+	if it.Kind != "type" {
+		if i := bytes.IndexByte(s.masked[n:end], '='); i >= 0 {
+			header = n + i
+		}
+	}
+	it.Signature = strings.TrimSuffix(collapse(s.text[sigStart:header]), ";")
+	return end
+}
+
+// macro reads a macro_rules! definition
+func (p *parser) macro(it *ItemInfo, sigStart, next, to int) int {
+	s := p.src
+	n := s.skipSpace(next, to)
+	it.SelectionLine = s.line(n)
+	it.Name, n = s.word(n, to)
+	it.Signature = "macro_rules! " + it.Name
+	n = s.skipSpace(n, to)
+	if n >= to {
+		return to
+	}
+	end := s.matching(n, to)
+	if e := s.skipSpace(end, to); e < to && s.masked[e] == ';' {
+		end = e + 1
+	}
+	return end
+}
+
+// skipItem returns the offset after an item that yields no chunk: its
+// first top-level block or its closing semicolon
+func (s *source) skipItem(pos, end int) int {
+	for i := pos; i < end; i++ {
+		switch s.masked[i] {
+		case '(', '[':
+			i = s.matching(i, end) - 1
+		case '{':
+			return s.matching(i, end)
+		case ';':
+			return i + 1
+		}
+	}
+	return end
+}
+
+// itemEnd returns the offset after an item whose header ends at hpos with
+// c: its body for {, the semicolon otherwise
+func itemEnd(s *source, hpos int, c byte, to int) int {
+	switch c {
+	case '{':
+		return s.matching(hpos, to)
+	case ';':
+		return hpos + 1
+	}
+	return hpos
+}
+
+// isTestOnly reports whether attributes restrict an item to test builds
+func isTestOnly(attrs []string) bool {
+	for _, a := range attrs {
+		if a == "test" || a == "cfg(test)" || strings.HasSuffix(a, "::test") {
+			return true
+		}
+	}
+	return false
+}
+
+// derives lists the traits of #[derive(...)] attributes
+func derives(attrs []string) []string {
+	var out []string
+	for _, a := range attrs {
+		if !strings.HasPrefix(a, "derive(") {
+			continue
+		}
+		for _, d := range strings.Split(strings.TrimSuffix(strings.TrimPrefix(a, "derive("), ")"), ",") {
+			if d = strings.TrimSpace(d); d != "" {
+				out = append(out, d)
+			}
+		}
+	}
+	return out
+}
+
+// deprecation returns the note of a #[deprecated] attribute
+func deprecation(attrs []string) (string, bool) {
+	for _, a := range attrs {
+		if m := deprecatedRe.FindStringSubmatch(a); m != nil {
+			for _, note := range m[1:] {
+				if note != "" {
+					return note, true
+				}
+			}
+			return "deprecated", true
+		}
+	}
+	return "", false
+}
+
+// isReceiver reports whether a parameter is a self receiver
+func isReceiver(param string) bool {
+	param = strings.TrimPrefix(param, "&")
+	if strings.HasPrefix(param, "'") {
+		if i := strings.IndexByte(param, ' '); i >= 0 {
+			param = param[i+1:]
+		}
+	}
+	param = strings.TrimPrefix(param, "mut ")
+	return param == "self" || strings.HasPrefix(param, "self:") || strings.HasPrefix(param, "self :")
+}
+
+// splitParam splits "name: Type" on its first single colon
+func splitParam(param string) (string, string) {
+	for i := 0; i < len(param); i++ {
+		if param[i] != ':' {
+			continue
+		}
+		if i+1 < len(param) && param[i+1] == ':' {
+			i++
+			continue
+		}
+		return strings.TrimSpace(param[:i]), strings.TrimSpace(param[i+1:])
+	}
+	return param, ""
+}
+
+// returnType extracts the type after -> from the rest of a fn header
+func returnType(rest string) string {
+	rest = strings.TrimSpace(rest)
+	if !strings.HasPrefix(rest, "->") {
+		return ""
+	}
+	return cutWhere(strings.TrimPrefix(rest, "->"))
+}
+
+// cutWhere drops a trailing where clause
+func cutWhere(text string) string {
+	if i := topLevelIndex(" "+text, " where "); i >= 0 {
+		text = text[:max(i-1, 0)]
+	}
+	return strings.TrimSpace(text)
+}
+
+// skipGenerics drops the <...> generics a header starts with
+func skipGenerics(text string) string {
+	text = strings.TrimSpace(text)
+	if !strings.HasPrefix(text, "<") {
+		return text
+	}
+	depth := 0
+	for i := 0; i < len(text); i++ {
+		switch text[i] {
+		case '<':
+			depth++
+		case '>':
+			if i > 0 && (text[i-1] == '-' || text[i-1] == '=') {
+				continue
+			}
+			depth--
+			if depth == 0 {
+				return text[i+1:]
+			}
+		}
+	}
+	return ""
+}
+
+// topLevelIndex returns the index of sep in text outside generics and
+// brackets, or -1
+func topLevelIndex(text, sep string) int {
+	depth := 0
+	for i := 0; i < len(text); i++ {
+		switch text[i] {
+		case '<', '(', '[':
+			depth++
+		case ')', ']':
+			depth--
+		case '>':
+			if i == 0 || (text[i-1] != '-' && text[i-1] != '=') {
+				depth--
+			}
+		}
+		if depth == 0 && strings.HasPrefix(text[i:], sep) {
+			return i
+		}
+	}
+	return -1
+}
+
+// typeName returns the bare name of a type: Vec for &mut std::vec::Vec<T>
+func typeName(typ string) string {
+	typ = strings.TrimSpace(strings.TrimPrefix(typ, "&"))
+	typ = strings.TrimPrefix(typ, "mut ")
+	typ = strings.TrimPrefix(typ, "dyn ")
+	if i := strings.IndexByte(typ, '<'); i >= 0 {
+		typ = typ[:i]
+	}
+	if i := strings.LastIndex(typ, "::"); i >= 0 {
+		typ = typ[i+2:]
+	}
+	return strings.TrimSpace(typ)
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// convertToChunks converts the parsed files to CodeChunks: one chunk per
+// function, type, trait, impl block, const, static and macro, plus one per
+// trait and impl method
+func (ca *CodeAnalyzer) convertToChunks() []codetypes.CodeChunk {
+	// Methods and implemented traits of each type, from its impl blocks
+	methods := make(map[string][]string)
+	traits := make(map[string][]string)
+	for _, file := range ca.files {
+		for _, it := range file.Items {
+			if it.Kind != "impl" {
+				continue
+			}
+			for _, m := range it.Methods {
+				methods[it.Name] = append(methods[it.Name], m.Name)
+			}
+			if it.Trait != "" {
+				traits[it.Name] = append(traits[it.Name], it.Trait)
+			}
+		}
+	}
+
+	var chunks []codetypes.CodeChunk
+	for _, file := range ca.files {
+		for _, it := range file.Items {
+			switch it.Kind {
+			case "fn":
+				chunks = append(chunks, functionChunk(it, "", ""))
+			case "struct", "enum", "union", "type":
+				kind := it.Kind
+				if kind == "type" {
+					kind = "alias"
+				}
+				ch := itemChunk(it, "type")
+				ch.Metadata["kind"] = kind
+				ch.Metadata["fields"] = it.Fields
+				ch.Metadata["variants"] = it.Variants
+				ch.Metadata["derives"] = it.Derives
+				ch.Metadata["methods"] = methods[it.Name]
+				ch.Metadata["traits"] = traits[it.Name]
+				chunks = append(chunks, ch)
+			case "trait":
+				ch := itemChunk(it, "trait")
+				ch.Metadata["supertraits"] = it.Supertraits
+				ch.Metadata["methods"] = methodNames(it.Methods)
+				ch.Metadata["implementors"] = implementors(ca.files, it.Name)
+				chunks = append(chunks, ch)
+				for _, m := range it.Methods {
+					chunks = append(chunks, functionChunk(m, it.Name, it.Name))
+				}
+			case "impl":
+				ch := itemChunk(it, "impl")
+				ch.Metadata["self_type"] = it.SelfType
+				ch.Metadata["trait"] = it.Trait
+				ch.Metadata["methods"] = methodNames(it.Methods)
+				chunks = append(chunks, ch)
+				for _, m := range it.Methods {
+					chunks = append(chunks, functionChunk(m, it.Name, it.Trait))
+				}
+			case "const":
+				chunks = append(chunks, itemChunk(it, "const"))
+			case "static":
+				chunks = append(chunks, itemChunk(it, "var"))
+			case "macro":
+				chunks = append(chunks, itemChunk(it, "macro"))
+			}
+		}
+	}
+	return chunks
+}
+
+func itemChunk(it ItemInfo, typ string) codetypes.CodeChunk {
+	ch := codetypes.CodeChunk{
+		Name:               it.Name,
+		Type:               typ,
+		Language:           codetypes.LanguageRust,
+		Package:            it.Module,
+		FilePath:           it.FilePath,
+		StartLine:          it.StartLine,
+		EndLine:            it.EndLine,
+		SelectionStartLine: it.SelectionLine,
+		SelectionEndLine:   it.SelectionLine,
+		Signature:          it.Signature,
+		Docstring:          it.Description,
+		Code:               it.Code,
+		Metadata: map[string]any{
+			"visibility": it.Visibility,
+			"attributes": it.Attributes,
+		},
+	}
+	if note, ok := deprecation(it.Attributes); ok {
+		codetypes.MarkDeprecated(&ch, note)
+	}
+	return ch
+}
+
+// functionChunk converts a free function, or a method when receiver (the
+// impl type or the trait) is set
+func functionChunk(it ItemInfo, receiver, trait string) codetypes.CodeChunk {
+	typ := "function"
+	if receiver != "" {
+		typ = "method"
+	}
+	ch := itemChunk(it, typ)
+	ch.Metadata["receiver"] = receiver
+	ch.Metadata["is_method"] = receiver != ""
+	ch.Metadata["trait"] = trait
+	ch.Metadata["self_param"] = it.Receiver
+	ch.Metadata["params"] = it.Parameters
+	ch.Metadata["returns"] = it.ReturnType
+	ch.Metadata["is_async"] = it.IsAsync
+	ch.Metadata["is_unsafe"] = it.IsUnsafe
+	ch.Metadata["is_const"] = it.IsConst
+	ch.Metadata["is_test"] = isTestOnly(it.Attributes)
+	return ch
+}
+
+func methodNames(methods []ItemInfo) []string {
+	var names []string
+	for _, m := range methods {
+		names = append(names, m.Name)
+	}
+	return names
+}
+
+// implementors lists the types implementing a trait in the analyzed files
+func implementors(files []*FileInfo, trait string) []string {
+	var types []string
+	for _, file := range files {
+		for _, it := range file.Items {
+			if it.Kind == "impl" && it.Trait != "" && typeName(it.Trait) == trait {
+				types = append(types, it.SelfType)
+			}
+		}
+	}
+	return types
+}
@@ -0,0 +1,227 @@
+package rust
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/doITmagic/rag-code-mcp/internal/codetypes"
+	"github.com/stretchr/testify/require"
+)
+
+const shapesSource = `//! Geometry primitives.
+#![allow(dead_code)]
+
+use std::fmt;
+
+/// A point in the plane.
+///
+/// Coordinates are in pixels.
+#[derive(Debug, Clone, Copy)]
+pub struct Point {
+    /// Horizontal offset, e.g. "{ x }"
+    pub x: f64,
+    y: f64, // not a doc comment
+}
+
+/// Distance in meters.
+pub struct Meters(pub f64);
+
+/** Shapes we can draw. */
+pub enum Shape {
+    Circle { center: Point, radius: f64 },
+    Square(Point, f64),
+    /// Nothing at all
+    Empty,
+}
+
+/// Anything with an area.
+pub trait Area: fmt::Debug + Send {
+    /// Area in square pixels.
+    fn area(&self) -> f64;
+
+    fn describe(&self) -> String {
+        format!("area {}", self.area())
+    }
+}
+
+impl Point {
+    /// Creates a point; braces in strings '{' and "}" are ignored.
+    pub const fn new(x: f64, y: f64) -> Self {
+        Point { x, y }
+    }
+
+    #[deprecated(since = "0.2.0", note = "use distance_to")]
+    pub fn dist<'a>(&'a self, other: &Point) -> f64 {
+        ((self.x - other.x).powi(2) + (self.y - other.y).powi(2)).sqrt()
+    }
+}
+
+impl<T> Area for Wrapper<T> where T: fmt::Debug {
+    fn area(&self) -> f64 {
+        0.0
+    }
+}
+
+pub const ORIGIN: Point = Point::new(0.0, 0.0);
+
+pub(crate) async fn load<R: std::io::Read>(reader: R, path: &str) -> std::io::Result<Vec<u8>> {
+    let _ = r#"fn fake() {}"#;
+    Ok(Vec::new())
+}
+
+macro_rules! square {
+    ($x:expr) => { $x * $x };
+}
+
+mod inner {
+    /// Helper inside an inline module.
+    pub fn helper() {}
+}
+
+#[cfg(test)]
+mod tests {
+    #[test]
+    fn it_works() {}
+}
+`
+
+func analyzeSource(t *testing.T, files map[string]string, paths ...string) []codetypes.CodeChunk {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	}
+	var roots []string
+	for _, p := range paths {
+		roots = append(roots, filepath.Join(dir, p))
+	}
+	chunks, err := NewCodeAnalyzer().AnalyzePaths(roots)
+	require.NoError(t, err)
+	return chunks
+}
+
+func findChunk(chunks []codetypes.CodeChunk, typ, name string) *codetypes.CodeChunk {
+	for i := range chunks {
+		if chunks[i].Type == typ && chunks[i].Name == name {
+			return &chunks[i]
+		}
+	}
+	return nil
+}
+
+func TestAnalyzePaths_Items(t *testing.T) {
+	chunks := analyzeSource(t, map[string]string{
+		"Cargo.toml":        "[package]\nname = \"geo-kit\"\nversion = \"0.1.0\"\n",
+		"src/lib.rs":        "pub mod shapes;\n",
+		"src/shapes.rs":     shapesSource,
+		"target/debug/x.rs": "pub fn generated() {}\n",
+	}, ".")
+
+	point := findChunk(chunks, "type", "Point")
+	require.NotNil(t, point)
+	require.Equal(t, codetypes.LanguageRust, point.Language)
+	require.Equal(t, "geo_kit::shapes", point.Package)
+	require.Equal(t, "struct", point.Metadata["kind"])
+	require.Equal(t, "A point in the plane.\n\nCoordinates are in pixels.", point.Docstring)
+	require.Equal(t, "pub struct Point", point.Signature)
+	require.Equal(t, []string{"Debug", "Clone", "Copy"}, point.Metadata["derives"])
+	require.Equal(t, []string{"new", "dist"}, point.Metadata["methods"])
+	require.Equal(t, 9, point.StartLine)
+	require.Equal(t, 14, point.EndLine)
+	fields := point.Metadata["fields"].([]FieldInfo)
+	require.Len(t, fields, 2)
+	require.Equal(t, FieldInfo{Name: "x", Type: "f64", Visibility: "pub", Description: `Horizontal offset, e.g. "{ x }"`}, fields[0])
+	require.Equal(t, FieldInfo{Name: "y", Type: "f64"}, fields[1])
+
+	meters := findChunk(chunks, "type", "Meters")
+	require.NotNil(t, meters)
+	require.Equal(t, []FieldInfo{{Name: "0", Type: "f64", Visibility: "pub"}}, meters.Metadata["fields"])
+
+	shape := findChunk(chunks, "type", "Shape")
+	require.NotNil(t, shape)
+	require.Equal(t, "enum", shape.Metadata["kind"])
+	require.Equal(t, "Shapes we can draw.", shape.Docstring)
+	variants := shape.Metadata["variants"].([]FieldInfo)
+	require.Len(t, variants, 3)
+	require.Equal(t, "{ center: Point, radius: f64 }", variants[0].Type)
+	require.Equal(t, "(Point, f64)", variants[1].Type)
+	require.Equal(t, "Nothing at all", variants[2].Description)
+
+	area := findChunk(chunks, "trait", "Area")
+	require.NotNil(t, area)
+	require.Equal(t, []string{"fmt::Debug", "Send"}, area.Metadata["supertraits"])
+	require.Equal(t, []string{"area", "describe"}, area.Metadata["methods"])
+	require.Equal(t, []string{"Wrapper<T>"}, area.Metadata["implementors"])
+
+	impl := findChunk(chunks, "impl", "Wrapper")
+	require.NotNil(t, impl)
+	require.Equal(t, "Area", impl.Metadata["trait"])
+	require.Equal(t, "Wrapper<T>", impl.Metadata["self_type"])
+
+	newFn := findChunk(chunks, "method", "new")
+	require.NotNil(t, newFn)
+	require.Equal(t, "Point", newFn.Metadata["receiver"])
+	require.Equal(t, true, newFn.Metadata["is_const"])
+	require.Equal(t, "Self", newFn.Metadata["returns"])
+	require.Equal(t, "pub const fn new(x: f64, y: f64) -> Self", newFn.Signature)
+	require.Contains(t, newFn.Docstring, "braces in strings")
+
+	dist := findChunk(chunks, "method", "dist")
+	require.NotNil(t, dist)
+	require.Equal(t, "&'a self", dist.Metadata["self_param"])
+	require.Equal(t, []codetypes.ParamInfo{{Name: "other", Type: "&Point"}}, dist.Metadata["params"])
+	deprecated, note := dist.Deprecation()
+	require.True(t, deprecated)
+	require.Equal(t, "use distance_to", note)
+
+	load := findChunk(chunks, "function", "load")
+	require.NotNil(t, load)
+	require.Equal(t, "pub(crate)", load.Metadata["visibility"])
+	require.Equal(t, true, load.Metadata["is_async"])
+	require.Equal(t, "std::io::Result<Vec<u8>>", load.Metadata["returns"])
+	require.Nil(t, findChunk(chunks, "function", "fake"))
+
+	require.NotNil(t, findChunk(chunks, "const", "ORIGIN"))
+	require.NotNil(t, findChunk(chunks, "macro", "square"))
+
+	helper := findChunk(chunks, "function", "helper")
+	require.NotNil(t, helper)
+	require.Equal(t, "geo_kit::shapes::inner", helper.Package)
+	require.Equal(t, "Helper inside an inline module.", helper.Docstring)
+
+	require.Nil(t, findChunk(chunks, "function", "it_works"), "test modules are skipped")
+	require.Nil(t, findChunk(chunks, "function", "generated"), "target/ is skipped")
+}
+
+func TestAnalyzePaths_SingleFileOutsideCrate(t *testing.T) {
+	chunks := analyzeSource(t, map[string]string{
+		"scripts/build.rs": "/// Entry point.\nfn main() {}\n",
+	}, "scripts/build.rs")
+
+	require.Len(t, chunks, 1)
+	require.Equal(t, "main", chunks[0].Name)
+	require.Equal(t, "scripts::build", chunks[0].Package)
+	require.Equal(t, "Entry point.", chunks[0].Docstring)
+	require.Equal(t, 2, chunks[0].StartLine)
+}
+
+func TestJoinModule(t *testing.T) {
+	tests := []struct {
+		rel, expected string
+	}{
+		{"src/lib.rs", "app"},
+		{"src/main.rs", "app"},
+		{"src/net/mod.rs", "app::net"},
+		{"src/net/http.rs", "app::net::http"},
+		{"src/bin/tool.rs", "app::tool"},
+		{"build.rs", "app::build"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.rel, func(t *testing.T) {
+			require.Equal(t, tt.expected, joinModule("app", tt.rel))
+		})
+	}
+}
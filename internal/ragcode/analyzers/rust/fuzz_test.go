@@ -0,0 +1,29 @@
+package rust
+
+import (
+	"strings"
+	"testing"
+)
+
+// FuzzParse feeds arbitrary text to the scanner-based parser: it must not
+// panic, and every chunk must be named and lie within the source lines.
+func FuzzParse(f *testing.F) {
+	f.Add(shapesSource)
+	f.Add("fn main() { let s = \"}\"; let c = '{'; }\n")
+	f.Add("impl<T: Clone> Foo<T> where T: Send {\n    fn bar(&self) {}\n")
+	f.Add("/* unterminated comment\nstruct S;\n")
+	f.Add("mod m { pub(crate) enum E { A, B } r#\"raw \" } string\"# }\n")
+	f.Fuzz(func(t *testing.T, src string) {
+		ca := NewCodeAnalyzer()
+		ca.files = []*FileInfo{ca.parseFile("fuzz.rs", src)}
+		lines := strings.Count(src, "\n") + 1
+		for _, ch := range ca.convertToChunks() {
+			if ch.Name == "" || ch.Type == "" {
+				t.Fatalf("chunk without name or type: %+v", ch)
+			}
+			if ch.StartLine < 1 || ch.EndLine < ch.StartLine || ch.EndLine > lines {
+				t.Fatalf("%s %s: lines %d-%d outside the %d source lines", ch.Type, ch.Name, ch.StartLine, ch.EndLine, lines)
+			}
+		}
+	})
+}
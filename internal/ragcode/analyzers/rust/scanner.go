@@ -0,0 +1,361 @@
+package rust
+
+import (
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// source is a Rust file prepared for item scanning: masked has the same
+// length and line breaks as text, with comments and the contents of string
+// and char literals blanked out, so braces, semicolons and keywords found in
+// it are real code.
+type source struct {
+	text       string
+	masked     []byte
+	lineStarts []int
+	docs       []docComment
+}
+
+// docComment is one outer doc comment (/// line or /** */ block)
+type docComment struct {
+	pos  int
+	text string
+}
+
+func newSource(text string) *source {
+	s := &source{text: text, masked: []byte(text), lineStarts: []int{0}}
+	for i := 0; i < len(text); i++ {
+		if text[i] == '\n' {
+			s.lineStarts = append(s.lineStarts, i+1)
+		}
+	}
+	s.mask()
+	return s
+}
+
+// line returns the 1-based line of a byte offset
+func (s *source) line(pos int) int {
+	return sort.Search(len(s.lineStarts), func(i int) bool { return s.lineStarts[i] > pos })
+}
+
+func (s *source) blank(from, to int) {
+	for i := from; i < to && i < len(s.masked); i++ {
+		if s.masked[i] != '\n' {
+			s.masked[i] = ' '
+		}
+	}
+}
+
+func (s *source) mask() {
+	t := s.text
+	for i := 0; i < len(t); {
+		switch {
+		case strings.HasPrefix(t[i:], "//"):
+			end := strings.IndexByte(t[i:], '\n')
+			if end < 0 {
+				end = len(t) - i
+			}
+			comment := t[i : i+end]
+			if strings.HasPrefix(comment, "///") && !strings.HasPrefix(comment, "////") {
+				s.docs = append(s.docs, docComment{pos: i, text: strings.TrimPrefix(strings.TrimPrefix(comment, "///"), " ")})
+			}
+			s.blank(i, i+end)
+			i += end
+		case strings.HasPrefix(t[i:], "/*"):
+			end := blockCommentEnd(t, i)
+			comment := t[i:end]
+			if strings.HasPrefix(comment, "/**") && !strings.HasPrefix(comment, "/***") && comment != "/**/" {
+				s.docs = append(s.docs, docComment{pos: i, text: blockDocText(comment)})
+			}
+			s.blank(i, end)
+			i = end
+		case t[i] == '"' || isRawStringStart(t, i) || (t[i] == 'b' && i+1 < len(t) && t[i+1] == '"' && !isIdentByte(prevByte(t, i))):
+			end := stringEnd(t, i)
+			s.blank(i, end)
+			i = end
+		case t[i] == '\'':
+			if end, ok := charEnd(t, i); ok {
+				s.blank(i, end)
+				i = end
+				continue
+			}
+			i++ // lifetime
+		default:
+			i++
+		}
+	}
+}
+
+func prevByte(t string, i int) byte {
+	if i == 0 {
+		return ' '
+	}
+	return t[i-1]
+}
+
+// blockCommentEnd returns the offset after the */ closing the (possibly
+// nested) block comment starting at i
+func blockCommentEnd(t string, i int) int {
+	depth := 0
+	for j := i; j+1 < len(t); j++ {
+		switch {
+		case t[j] == '/' && t[j+1] == '*':
+			depth++
+			j++
+		case t[j] == '*' && t[j+1] == '/':
+			depth--
+			j++
+			if depth == 0 {
+				return j + 1
+			}
+		}
+	}
+	return len(t)
+}
+
+func blockDocText(comment string) string {
+	body := strings.TrimSuffix(strings.TrimPrefix(comment, "/**"), "*/")
+	var lines []string
+	for _, l := range strings.Split(body, "\n") {
+		l = strings.TrimSpace(l)
+		l = strings.TrimSpace(strings.TrimPrefix(l, "*"))
+		lines = append(lines, l)
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// isRawStringStart reports whether a raw string (r"", r#""#, br"") starts at i
+func isRawStringStart(t string, i int) bool {
+	if isIdentByte(prevByte(t, i)) {
+		return false
+	}
+	j := i
+	if t[j] == 'b' {
+		j++
+	}
+	if j >= len(t) || t[j] != 'r' {
+		return false
+	}
+	j++
+	for j < len(t) && t[j] == '#' {
+		j++
+	}
+	return j < len(t) && t[j] == '"'
+}
+
+// stringEnd returns the offset after the string literal starting at i
+func stringEnd(t string, i int) int {
+	j := i
+	if t[j] == 'b' {
+		j++
+	}
+	if t[j] == 'r' {
+		j++
+		hashes := 0
+		for j < len(t) && t[j] == '#' {
+			hashes++
+			j++
+		}
+		closing := "\"" + strings.Repeat("#", hashes)
+		end := strings.Index(t[j+1:], closing)
+		if end < 0 {
+			return len(t)
+		}
+		return j + 1 + end + len(closing)
+	}
+	for j++; j < len(t); j++ {
+		switch t[j] {
+		case '\\':
+			j++
+		case '"':
+			return j + 1
+		}
+	}
+	return len(t)
+}
+
+// charEnd returns the offset after the char literal starting at i; false
+// for lifetimes ('a, 'static)
+func charEnd(t string, i int) (int, bool) {
+	if i+1 >= len(t) {
+		return 0, false
+	}
+	if t[i+1] == '\\' {
+		for j := i + 2; j < len(t) && t[j] != '\n'; j++ {
+			if t[j] == '\'' {
+				return j + 1, true
+			}
+		}
+		return 0, false
+	}
+	_, size := utf8.DecodeRuneInString(t[i+1:])
+	if i+1+size < len(t) && t[i+1+size] == '\'' {
+		return i + 2 + size, true
+	}
+	return 0, false
+}
+
+func isIdentByte(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
+
+func (s *source) skipSpace(pos, end int) int {
+	for pos < end && isSpace(s.masked[pos]) {
+		pos++
+	}
+	return pos
+}
+
+// word reads the identifier at pos (with a trailing ! for macro_rules!)
+func (s *source) word(pos, end int) (string, int) {
+	start := pos
+	for pos < end && isIdentByte(s.masked[pos]) {
+		pos++
+	}
+	if pos < end && s.masked[pos] == '!' && string(s.masked[start:pos]) == "macro_rules" {
+		pos++
+	}
+	return string(s.masked[start:pos]), pos
+}
+
+// matching returns the offset after the bracket closing the one at pos
+func (s *source) matching(pos, end int) int {
+	open := s.masked[pos]
+	var close byte
+	switch open {
+	case '{':
+		close = '}'
+	case '(':
+		close = ')'
+	case '[':
+		close = ']'
+	default:
+		return pos + 1
+	}
+	depth := 0
+	for i := pos; i < end; i++ {
+		switch s.masked[i] {
+		case open:
+			depth++
+		case close:
+			depth--
+			if depth == 0 {
+				return i + 1
+			}
+		}
+	}
+	return end
+}
+
+// headerEnd finds the { or ; ending an item header, outside parentheses
+// and brackets. It returns its offset and the byte (0 when none).
+func (s *source) headerEnd(pos, end int) (int, byte) {
+	depth := 0
+	for i := pos; i < end; i++ {
+		switch c := s.masked[i]; c {
+		case '(', '[':
+			depth++
+		case ')', ']':
+			depth--
+		case '{', ';':
+			if depth == 0 {
+				return i, c
+			}
+		}
+	}
+	return end, 0
+}
+
+// statementEnd returns the offset after the ; ending a statement-like item
+// (use, const, static, type), skipping nested brackets
+func (s *source) statementEnd(pos, end int) int {
+	for i := pos; i < end; i++ {
+		switch s.masked[i] {
+		case '(', '[', '{':
+			i = s.matching(i, end) - 1
+		case ';':
+			return i + 1
+		}
+	}
+	return end
+}
+
+// span is a [from, to) byte range of a source
+type span struct {
+	from, to int
+}
+
+// splitTopLevel splits the masked text between from and to on commas
+// outside brackets and generics
+func (s *source) splitTopLevel(from, to int) []span {
+	if to < from {
+		// unterminated list at the end of the file
+		return nil
+	}
+	var parts []span
+	depth, start := 0, from
+	for i := from; i < to; i++ {
+		switch s.masked[i] {
+		case '(', '[', '{', '<':
+			depth++
+		case ')', ']', '}':
+			depth--
+		case '>':
+			if i > 0 && s.masked[i-1] != '-' && s.masked[i-1] != '=' {
+				depth--
+			}
+		case ',':
+			if depth == 0 {
+				parts = append(parts, span{start, i})
+				start = i + 1
+			}
+		}
+	}
+	parts = append(parts, span{start, to})
+	return parts
+}
+
+// angleEnd returns the offset after the > closing the generics opened at
+// pos, ignoring -> and =>
+func (s *source) angleEnd(pos, end int) int {
+	depth := 0
+	for i := pos; i < end; i++ {
+		switch s.masked[i] {
+		case '<':
+			depth++
+		case '>':
+			if s.masked[i-1] == '-' || s.masked[i-1] == '=' {
+				continue
+			}
+			depth--
+			if depth == 0 {
+				return i + 1
+			}
+		case '(', '[':
+			i = s.matching(i, end) - 1
+		case '{', ';':
+			return i
+		}
+	}
+	return end
+}
+
+// docsIn joins the doc comments starting between from and to
+func (s *source) docsIn(from, to int) string {
+	i := sort.Search(len(s.docs), func(i int) bool { return s.docs[i].pos >= from })
+	var parts []string
+	for ; i < len(s.docs) && s.docs[i].pos < to; i++ {
+		parts = append(parts, s.docs[i].text)
+	}
+	return strings.TrimSpace(strings.Join(parts, "\n"))
+}
+
+// collapse joins the lines of a header into one, with single spaces
+func collapse(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
@@ -0,0 +1,55 @@
+package rust
+
+import "github.com/doITmagic/rag-code-mcp/internal/codetypes"
+
+// FileInfo contains the items declared in one Rust source file
+type FileInfo struct {
+	Path   string     `json:"path"`
+	Module string     `json:"module"` // Module path (e.g., "mycrate::net::http")
+	Items  []ItemInfo `json:"items"`
+}
+
+// ItemInfo describes a Rust item: a function, struct, enum, union, trait,
+// impl block, type alias, const, static or macro_rules! macro
+type ItemInfo struct {
+	Kind        string   `json:"kind"` // fn | struct | enum | union | trait | impl | type | const | static | macro
+	Name        string   `json:"name"`
+	Module      string   `json:"module"`     // Module path, including inline mod blocks
+	Visibility  string   `json:"visibility"` // "", pub, pub(crate), pub(super), ...
+	Signature   string   `json:"signature"`
+	Description string   `json:"description"` // /// and /** */ doc comments
+	Attributes  []string `json:"attributes,omitempty"`
+
+	// Functions and methods
+	Parameters []codetypes.ParamInfo `json:"parameters,omitempty"`
+	ReturnType string                `json:"return_type,omitempty"`
+	Receiver   string                `json:"receiver,omitempty"` // self, &self, &mut self, ...
+	IsAsync    bool                  `json:"is_async,omitempty"`
+	IsUnsafe   bool                  `json:"is_unsafe,omitempty"`
+	IsConst    bool                  `json:"is_const,omitempty"`
+
+	// Structs, unions and enums
+	Fields   []FieldInfo `json:"fields,omitempty"`
+	Variants []FieldInfo `json:"variants,omitempty"`
+	Derives  []string    `json:"derives,omitempty"`
+
+	// Traits and impl blocks
+	SelfType    string     `json:"self_type,omitempty"` // impl: the implementing type
+	Trait       string     `json:"trait,omitempty"`     // impl: the implemented trait
+	Supertraits []string   `json:"supertraits,omitempty"`
+	Methods     []ItemInfo `json:"methods,omitempty"`
+
+	FilePath      string `json:"file_path,omitempty"`
+	StartLine     int    `json:"start_line,omitempty"`
+	EndLine       int    `json:"end_line,omitempty"`
+	SelectionLine int    `json:"selection_line,omitempty"` // Line of the item name
+	Code          string `json:"code,omitempty"`
+}
+
+// FieldInfo describes a struct field or an enum variant
+type FieldInfo struct {
+	Name        string `json:"name"`
+	Type        string `json:"type,omitempty"` // Field type, or variant payload / discriminant
+	Visibility  string `json:"visibility,omitempty"`
+	Description string `json:"description,omitempty"`
+}
@@ -0,0 +1,257 @@
+package ragcode
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/doITmagic/rag-code-mcp/internal/codetypes"
+)
+
+// Boilerplate removes license headers, generated-code banners and comment
+// lines repeated across a workspace from the text that is embedded, so that
+// chunk vectors reflect the code rather than the header every file shares.
+// The stored chunk code is left untouched.
+//
+// Repeated lines are learned from the analyzed chunks (see Learn) and kept
+// per workspace, so incremental runs that see few files still strip them.
+type Boilerplate struct {
+	Lines map[string]bool `json:"lines"` // normalized comment lines
+	mu    sync.RWMutex
+}
+
+const (
+	// A comment line is boilerplate when it appears in at least
+	// boilerplateMinFiles files and boilerplateMinShare of the analyzed files
+	boilerplateMinFiles = 3
+	boilerplateMinShare = 0.3
+	// Shorter lines ("TODO", "end if") are too generic to learn
+	boilerplateMinLength = 12
+)
+
+var (
+	licenseMarker = regexp.MustCompile(`(?i)(copyright|\(c\)|©|spdx-license-identifier|licensed under|license, version|permission is hereby granted|all rights reserved|without warranties or conditions|gnu (general|lesser) public license|mit license)`)
+
+	generatedBanner = regexp.MustCompile(`(?i)(code generated .* do not edit|@generated|auto-generated|autogenerated|automatically generated|do not edit this file|generated by the protocol buffer compiler)`)
+
+	// languageBoilerplate are lines that carry no meaning for search, per
+	// language
+	languageBoilerplate = map[string][]*regexp.Regexp{
+		"go": {
+			regexp.MustCompile(`^//go:build `),
+			regexp.MustCompile(`^// \+build `),
+		},
+		"php": {
+			regexp.MustCompile(`^<\?php\s*$`),
+			regexp.MustCompile(`^\?>\s*$`),
+			regexp.MustCompile(`^declare\s*\(\s*strict_types\s*=\s*1\s*\)\s*;\s*$`),
+		},
+		"python": {
+			regexp.MustCompile(`^#!`),
+			regexp.MustCompile(`^#.*-\*-\s*coding[:=]`),
+			regexp.MustCompile(`^from __future__ import `),
+		},
+	}
+)
+
+// NewBoilerplate creates a filter without learned lines.
+func NewBoilerplate() *Boilerplate {
+	return &Boilerplate{Lines: make(map[string]bool)}
+}
+
+// LoadBoilerplate loads learned lines from path, returning an empty filter
+// if none have been written yet.
+func LoadBoilerplate(path string) (*Boilerplate, error) {
+	b := NewBoilerplate()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return b, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(data, b); err != nil {
+		return nil, err
+	}
+	if b.Lines == nil {
+		b.Lines = make(map[string]bool)
+	}
+	return b, nil
+}
+
+// Save writes the learned lines to path.
+func (b *Boilerplate) Save(path string) error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := json.Marshal(b)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// Learn records the comment lines that repeat across the files of chunks
+// and returns the newly learned ones.
+func (b *Boilerplate) Learn(chunks []codetypes.CodeChunk) []string {
+	files := make(map[string]bool)
+	lineFiles := make(map[string]map[string]bool)
+	for _, ch := range chunks {
+		files[ch.FilePath] = true
+		for _, text := range []string{ch.Docstring, ch.Code} {
+			for _, line := range strings.Split(text, "\n") {
+				norm, ok := commentText(line)
+				if !ok || len(norm) < boilerplateMinLength {
+					continue
+				}
+				if lineFiles[norm] == nil {
+					lineFiles[norm] = make(map[string]bool)
+				}
+				lineFiles[norm][ch.FilePath] = true
+			}
+		}
+	}
+
+	minFiles := int(float64(len(files))*boilerplateMinShare + 0.5)
+	if minFiles < boilerplateMinFiles {
+		minFiles = boilerplateMinFiles
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var learned []string
+	for line, in := range lineFiles {
+		if len(in) >= minFiles && !b.Lines[line] {
+			b.Lines[line] = true
+			learned = append(learned, line)
+		}
+	}
+	sort.Strings(learned)
+	return learned
+}
+
+// EmbeddingText strips boilerplate from text: comment blocks that contain a
+// license notice, generated-code banners, lines that are boilerplate for
+// language and learned repeated comment lines. If nothing would remain, text
+// is returned unchanged so the chunk still gets an embedding.
+func (b *Boilerplate) EmbeddingText(language, text string) string {
+	if b == nil {
+		return text
+	}
+	lines := strings.Split(text, "\n")
+	drop := make([]bool, len(lines))
+
+	// License notices span whole comment blocks: drop the block
+	for start := 0; start < len(lines); {
+		if _, ok := commentText(lines[start]); !ok {
+			start++
+			continue
+		}
+		end := start
+		license := false
+		for end < len(lines) {
+			if _, ok := commentText(lines[end]); !ok {
+				break
+			}
+			license = license || licenseMarker.MatchString(lines[end])
+			end++
+		}
+		if license {
+			for i := start; i < end; i++ {
+				drop[i] = true
+			}
+		}
+		start = end
+	}
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	patterns := languageBoilerplate[strings.ToLower(language)]
+	for i, line := range lines {
+		if drop[i] {
+			continue
+		}
+		trimmed := strings.TrimSpace(line)
+		if norm, ok := commentText(line); ok {
+			if b.Lines[norm] || generatedBanner.MatchString(norm) {
+				drop[i] = true
+				continue
+			}
+		}
+		for _, re := range patterns {
+			if re.MatchString(trimmed) {
+				drop[i] = true
+				break
+			}
+		}
+	}
+
+	kept := make([]string, 0, len(lines))
+	for i, line := range lines {
+		if drop[i] {
+			continue
+		}
+		// Collapse the blank lines left behind
+		if strings.TrimSpace(line) == "" && (len(kept) == 0 || strings.TrimSpace(kept[len(kept)-1]) == "") {
+			continue
+		}
+		kept = append(kept, line)
+	}
+	out := strings.TrimSpace(strings.Join(kept, "\n"))
+	if out == "" {
+		return text
+	}
+	return out
+}
+
+// DocstringText strips boilerplate from a doc comment, which analyzers
+// store without comment markers: paragraphs with a license notice or a
+// generated-code banner, and learned repeated lines.
+func (b *Boilerplate) DocstringText(doc string) string {
+	if b == nil {
+		return doc
+	}
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	var kept []string
+	for _, para := range strings.Split(doc, "\n\n") {
+		if licenseMarker.MatchString(para) || generatedBanner.MatchString(para) {
+			continue
+		}
+		var lines []string
+		for _, line := range strings.Split(para, "\n") {
+			if !b.Lines[strings.Join(strings.Fields(line), " ")] {
+				lines = append(lines, line)
+			}
+		}
+		if p := strings.TrimSpace(strings.Join(lines, "\n")); p != "" {
+			kept = append(kept, p)
+		}
+	}
+	return strings.Join(kept, "\n\n")
+}
+
+// commentText returns the text of a comment line without its markers,
+// with whitespace collapsed. Docstrings are usually stored without markers,
+// so callers also pass plain text lines; those are not comments.
+func commentText(line string) (string, bool) {
+	t := strings.TrimSpace(line)
+	if t == "*" || strings.HasPrefix(t, "* ") {
+		// Continuation of a /* */ block; "*p = x" is code
+		return strings.Join(strings.Fields(t[1:]), " "), true
+	}
+	for _, marker := range []string{"/**", "/*", "*/", "//", "#", "--", `"""`, "'''"} {
+		if strings.HasPrefix(t, marker) {
+			t = strings.TrimSpace(strings.TrimPrefix(t, marker))
+			t = strings.TrimSpace(strings.TrimSuffix(t, "*/"))
+			return strings.Join(strings.Fields(t), " "), true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,97 @@
+package ragcode
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/doITmagic/rag-code-mcp/internal/codetypes"
+)
+
+func TestBoilerplateEmbeddingText(t *testing.T) {
+	b := NewBoilerplate()
+
+	php := `<?php
+/*
+ * Copyright (c) 2024 Acme Corp.
+ * Licensed under the Apache License, Version 2.0.
+ */
+declare(strict_types=1);
+
+/**
+ * Charges the customer's card.
+ */
+class PaymentService
+{
+    // Retry failed charges once
+    public function charge() {}
+}`
+	got := b.EmbeddingText("php", php)
+	for _, gone := range []string{"Copyright", "Apache", "<?php", "strict_types"} {
+		if strings.Contains(got, gone) {
+			t.Errorf("%q not stripped:\n%s", gone, got)
+		}
+	}
+	for _, kept := range []string{"Charges the customer's card.", "class PaymentService", "// Retry failed charges once"} {
+		if !strings.Contains(got, kept) {
+			t.Errorf("%q missing:\n%s", kept, got)
+		}
+	}
+
+	goCode := "// Code generated by protoc-gen-go. DO NOT EDIT.\n\nfunc (x *Req) Reset() {\n\t*x = Req{}\n}"
+	if got := b.EmbeddingText("go", goCode); got != "func (x *Req) Reset() {\n\t*x = Req{}\n}" {
+		t.Errorf("generated banner not stripped, or code changed:\n%s", got)
+	}
+
+	// Only boilerplate: the text is kept so the chunk still gets embedded
+	only := "// Copyright 2024 Acme Corp. All rights reserved."
+	if got := b.EmbeddingText("go", only); got != only {
+		t.Errorf("boilerplate-only text changed to %q", got)
+	}
+
+	doc := "Copyright 2024 Acme Corp.\nSPDX-License-Identifier: MIT\n\nPackage billing computes invoices."
+	if got := b.DocstringText(doc); got != "Package billing computes invoices." {
+		t.Errorf("DocstringText = %q", got)
+	}
+
+	var nilFilter *Boilerplate
+	if nilFilter.EmbeddingText("go", php) != php || nilFilter.DocstringText(doc) != doc {
+		t.Error("nil filter must leave text unchanged")
+	}
+}
+
+func TestBoilerplateLearn(t *testing.T) {
+	var chunks []codetypes.CodeChunk
+	for i := 0; i < 6; i++ {
+		chunks = append(chunks, codetypes.CodeChunk{
+			FilePath: fmt.Sprintf("src/file%d.py", i),
+			Language: "python",
+			Code:     fmt.Sprintf("# This file is part of the Acme billing platform\n# handles case %d of the import\ndef f%d():\n    pass", i, i),
+		})
+	}
+	b := NewBoilerplate()
+	learned := b.Learn(chunks)
+	if len(learned) != 1 || learned[0] != "This file is part of the Acme billing platform" {
+		t.Fatalf("learned %q", learned)
+	}
+	got := b.EmbeddingText("python", chunks[0].Code)
+	if strings.Contains(got, "Acme billing platform") || !strings.Contains(got, "handles case 0") {
+		t.Errorf("EmbeddingText = %q", got)
+	}
+	if again := b.Learn(chunks); len(again) != 0 {
+		t.Errorf("relearned %q", again)
+	}
+
+	path := filepath.Join(t.TempDir(), "boilerplate.json")
+	if err := b.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadBoilerplate(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !loaded.Lines["This file is part of the Acme billing platform"] {
+		t.Errorf("loaded lines = %v", loaded.Lines)
+	}
+}
@@ -0,0 +1,288 @@
+package ragcode
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/doITmagic/rag-code-mcp/internal/codetypes"
+)
+
+// Call graph directions
+const (
+	CallGraphCallers = "callers"
+	CallGraphCallees = "callees"
+	CallGraphBoth    = "both"
+)
+
+// CallGraphNode is a function or method of a call graph. Callees that are
+// not in the symbol table (standard library, dependencies) are External and
+// have no location.
+type CallGraphNode struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	Kind      string `json:"kind,omitempty"`
+	Language  string `json:"language,omitempty"`
+	Package   string `json:"package,omitempty"`
+	Receiver  string `json:"receiver,omitempty"`
+	FilePath  string `json:"file_path,omitempty"`
+	StartLine int    `json:"start_line,omitempty"`
+	EndLine   int    `json:"end_line,omitempty"`
+	Depth     int    `json:"depth"` // calls away from the root symbol
+	External  bool   `json:"external,omitempty"`
+}
+
+// CallGraphEdge is a call from one node to another. The location is the
+// call site when the analyzer records it, else the caller. Ambiguous edges
+// go to one of several symbols sharing the called name.
+type CallGraphEdge struct {
+	From      string `json:"from"`
+	To        string `json:"to"`
+	FilePath  string `json:"file_path"`
+	Line      int    `json:"line,omitempty"`
+	Ambiguous bool   `json:"ambiguous,omitempty"`
+}
+
+// CallGraph is the neighbourhood of a symbol in the call graph of a
+// workspace
+type CallGraph struct {
+	Symbol    string          `json:"symbol"`
+	Direction string          `json:"direction"`
+	Depth     int             `json:"depth"`
+	Roots     []string        `json:"roots"` // IDs of the symbols matching Symbol
+	Nodes     []CallGraphNode `json:"nodes"`
+	Edges     []CallGraphEdge `json:"edges"`
+	Truncated bool            `json:"truncated,omitempty"` // the node limit was reached
+}
+
+// CallGraphOptions bound a call graph query
+type CallGraphOptions struct {
+	Direction       string // CallGraphCallers, CallGraphCallees or CallGraphBoth (default)
+	Depth           int    // levels of calls followed from the root, at least 1
+	MaxNodes        int    // 0 for no limit
+	IncludeExternal bool   // keep callees missing from the symbol table
+}
+
+// BuildCallGraph returns the callers and callees of symbol up to opts.Depth
+// levels, from the calls recorded in the symbol table. symbol is a function
+// or method name, optionally qualified by its receiver or package
+// ("Store.Save", "billing.Charge"). Calls are resolved by name within a
+// language, preferring symbols of the caller's class and package.
+func BuildCallGraph(entries []SymbolEntry, symbol string, opts CallGraphOptions) (*CallGraph, error) {
+	if opts.Depth < 1 {
+		opts.Depth = 1
+	}
+	if opts.Direction == "" {
+		opts.Direction = CallGraphBoth
+	}
+	g := newCallGraphIndex(entries)
+	roots := g.match(symbol)
+	if len(roots) == 0 {
+		return nil, fmt.Errorf("no function or method named '%s' in the symbol table", symbol)
+	}
+
+	graph := &CallGraph{Symbol: symbol, Direction: opts.Direction, Depth: opts.Depth}
+	depth := make(map[string]int)
+	addNode := func(id string, d int) bool {
+		if _, ok := depth[id]; ok {
+			return true
+		}
+		if opts.MaxNodes > 0 && len(depth) >= opts.MaxNodes {
+			graph.Truncated = true
+			return false
+		}
+		depth[id] = d
+		return true
+	}
+	for _, i := range roots {
+		id := g.ids[i]
+		graph.Roots = append(graph.Roots, id)
+		addNode(id, 0)
+	}
+
+	edgeSeen := make(map[callGraphEdgeKey]bool)
+	walk := func(next func(string) []CallGraphEdge, forward bool) {
+		frontier := append([]string(nil), graph.Roots...)
+		for d := 1; d <= opts.Depth && len(frontier) > 0; d++ {
+			var following []string
+			for _, id := range frontier {
+				for _, e := range next(id) {
+					other := e.To
+					if !forward {
+						other = e.From
+					}
+					if strings.HasPrefix(other, externalPrefix) && !opts.IncludeExternal {
+						continue
+					}
+					_, known := depth[other]
+					if !addNode(other, d) {
+						continue
+					}
+					key := callGraphEdgeKey{e.From, e.To}
+					if !edgeSeen[key] {
+						edgeSeen[key] = true
+						graph.Edges = append(graph.Edges, e)
+					}
+					if !known {
+						following = append(following, other)
+					}
+				}
+			}
+			frontier = following
+		}
+	}
+	if opts.Direction == CallGraphCallees || opts.Direction == CallGraphBoth {
+		walk(func(id string) []CallGraphEdge { return g.out[id] }, true)
+	}
+	if opts.Direction == CallGraphCallers || opts.Direction == CallGraphBoth {
+		walk(func(id string) []CallGraphEdge { return g.in[id] }, false)
+	}
+
+	for id, d := range depth {
+		graph.Nodes = append(graph.Nodes, g.node(id, d))
+	}
+	sort.Slice(graph.Nodes, func(i, j int) bool {
+		if graph.Nodes[i].Depth != graph.Nodes[j].Depth {
+			return graph.Nodes[i].Depth < graph.Nodes[j].Depth
+		}
+		return graph.Nodes[i].ID < graph.Nodes[j].ID
+	})
+	return graph, nil
+}
+
+// externalPrefix starts the IDs of callees missing from the symbol table
+const externalPrefix = "external:"
+
+type callGraphEdgeKey struct{ from, to string }
+
+// callGraphIndex holds every resolved call of a symbol table
+type callGraphIndex struct {
+	entries []SymbolEntry
+	ids     []string
+	byID    map[string]int
+	byName  map[string][]int // language + "\x00" + name -> callable entries
+	out     map[string][]CallGraphEdge
+	in      map[string][]CallGraphEdge
+}
+
+func newCallGraphIndex(entries []SymbolEntry) *callGraphIndex {
+	g := &callGraphIndex{
+		entries: entries,
+		ids:     make([]string, len(entries)),
+		byID:    make(map[string]int),
+		byName:  make(map[string][]int),
+		out:     make(map[string][]CallGraphEdge),
+		in:      make(map[string][]CallGraphEdge),
+	}
+	for i, e := range entries {
+		g.ids[i] = fmt.Sprintf("%s:%d", e.FilePath, e.StartLine)
+		g.byID[g.ids[i]] = i
+		if callable(e) {
+			key := e.Language + "\x00" + e.Name
+			g.byName[key] = append(g.byName[key], i)
+		}
+	}
+	for i, e := range entries {
+		if !callable(e) {
+			continue
+		}
+		sites := e.CallSites
+		if len(sites) == 0 {
+			for _, name := range e.Calls {
+				sites = append(sites, codetypes.CallSite{Name: name})
+			}
+		}
+		for _, site := range sites {
+			from := g.ids[i]
+			targets := g.resolve(e, site.Name, site.Receiver)
+			if len(targets) == 0 {
+				targets = []string{externalPrefix + site.Name}
+			}
+			for _, to := range targets {
+				edge := CallGraphEdge{From: from, To: to, FilePath: e.FilePath, Line: site.Line, Ambiguous: len(targets) > 1}
+				g.out[from] = append(g.out[from], edge)
+				g.in[to] = append(g.in[to], edge)
+			}
+		}
+	}
+	return g
+}
+
+// resolve returns the IDs of the symbols a call from caller may reach
+func (g *callGraphIndex) resolve(caller SymbolEntry, name, receiver string) []string {
+	candidates := g.byName[caller.Language+"\x00"+name]
+	if len(candidates) == 0 {
+		return nil
+	}
+	self := receiver == "self" || receiver == "this" || receiver == "$this" || receiver == "cls" || receiver == "static"
+	prefer := []func(SymbolEntry) bool{
+		// self.save() calls a method of the caller's class
+		func(e SymbolEntry) bool { return self && e.Receiver != "" && e.Receiver == caller.Receiver },
+		// a plain call reaches a function, not a method
+		func(e SymbolEntry) bool { return receiver == "" && e.Receiver == "" && e.Package == caller.Package },
+		func(e SymbolEntry) bool { return receiver == "" && e.Receiver == "" },
+		func(e SymbolEntry) bool { return receiver != "" && e.Receiver != "" && e.Package == caller.Package },
+		func(e SymbolEntry) bool { return receiver != "" && e.Receiver != "" },
+	}
+	for _, keep := range prefer {
+		var ids []string
+		for _, i := range candidates {
+			if keep(g.entries[i]) {
+				ids = append(ids, g.ids[i])
+			}
+		}
+		if len(ids) > 0 {
+			return ids
+		}
+	}
+	ids := make([]string, 0, len(candidates))
+	for _, i := range candidates {
+		ids = append(ids, g.ids[i])
+	}
+	return ids
+}
+
+// match returns the callable entries named by symbol: Name,
+// Receiver.Name or Package.Name
+func (g *callGraphIndex) match(symbol string) []int {
+	name, qualifier := symbol, ""
+	if i := strings.LastIndexAny(symbol, ".:"); i >= 0 {
+		qualifier, name = strings.TrimRight(symbol[:i], ".:"), symbol[i+1:]
+	}
+	var out []int
+	for i, e := range g.entries {
+		if !callable(e) || e.Name != name {
+			continue
+		}
+		if qualifier != "" && !strings.EqualFold(e.Receiver, qualifier) && !strings.EqualFold(e.Package, qualifier) &&
+			!strings.HasSuffix(e.Package, "/"+qualifier) && !strings.HasSuffix(e.Package, "\\"+qualifier) {
+			continue
+		}
+		out = append(out, i)
+	}
+	return out
+}
+
+func (g *callGraphIndex) node(id string, depth int) CallGraphNode {
+	i, ok := g.byID[id]
+	if !ok {
+		return CallGraphNode{ID: id, Name: strings.TrimPrefix(id, externalPrefix), Depth: depth, External: true}
+	}
+	e := g.entries[i]
+	return CallGraphNode{
+		ID:        id,
+		Name:      e.Name,
+		Kind:      e.Kind,
+		Language:  e.Language,
+		Package:   e.Package,
+		Receiver:  e.Receiver,
+		FilePath:  e.FilePath,
+		StartLine: e.StartLine,
+		EndLine:   e.EndLine,
+		Depth:     depth,
+	}
+}
+
+func callable(e SymbolEntry) bool {
+	return e.Kind == "function" || e.Kind == "method"
+}
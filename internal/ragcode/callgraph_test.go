@@ -0,0 +1,141 @@
+package ragcode
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/doITmagic/rag-code-mcp/internal/codetypes"
+)
+
+func callGraphFixture() []SymbolEntry {
+	return []SymbolEntry{
+		{Name: "Handle", Kind: "function", Language: "go", Package: "api", FilePath: "/ws/api.go", StartLine: 10, EndLine: 20,
+			CallSites: []codetypes.CallSite{{Name: "Charge", Receiver: "billing", Line: 12}, {Name: "Println", Receiver: "fmt", Line: 13}}},
+		{Name: "Charge", Kind: "function", Language: "go", Package: "billing", FilePath: "/ws/billing.go", StartLine: 5, EndLine: 15,
+			CallSites: []codetypes.CallSite{{Name: "validate", Line: 6}, {Name: "Save", Receiver: "s", Line: 9}}},
+		{Name: "validate", Kind: "function", Language: "go", Package: "billing", FilePath: "/ws/billing.go", StartLine: 20, EndLine: 25},
+		{Name: "Save", Kind: "method", Language: "go", Package: "billing", Receiver: "Store", FilePath: "/ws/store.go", StartLine: 3, EndLine: 8},
+		{Name: "Save", Kind: "method", Language: "go", Package: "cache", Receiver: "Cache", FilePath: "/ws/cache/cache.go", StartLine: 3, EndLine: 8},
+		{Name: "Store", Kind: "type", Language: "go", Package: "billing", FilePath: "/ws/store.go", StartLine: 1, EndLine: 2},
+		// PHP shares names with Go but never resolves across languages
+		{Name: "save", Kind: "method", Language: "php", Receiver: "Order", FilePath: "/ws/Order.php", StartLine: 4, EndLine: 9,
+			CallSites: []codetypes.CallSite{{Name: "validate", Receiver: "$this", Line: 5}}},
+		{Name: "validate", Kind: "method", Language: "php", Receiver: "Order", FilePath: "/ws/Order.php", StartLine: 11, EndLine: 14},
+		{Name: "validate", Kind: "method", Language: "php", Receiver: "User", FilePath: "/ws/User.php", StartLine: 11, EndLine: 14},
+	}
+}
+
+func nodeIDs(g *CallGraph) []string {
+	ids := make([]string, 0, len(g.Nodes))
+	for _, n := range g.Nodes {
+		ids = append(ids, n.ID)
+	}
+	return ids
+}
+
+func TestBuildCallGraph_Callees(t *testing.T) {
+	g, err := BuildCallGraph(callGraphFixture(), "Charge", CallGraphOptions{Direction: CallGraphCallees, Depth: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"/ws/billing.go:5", "/ws/billing.go:20", "/ws/store.go:3"}
+	if !reflect.DeepEqual(nodeIDs(g), want) {
+		t.Errorf("nodes = %v, want %v", nodeIDs(g), want)
+	}
+	for _, e := range g.Edges {
+		if e.To == "/ws/store.go:3" && (e.Ambiguous || e.Line != 9) {
+			t.Errorf("s.Save edge = %+v, want unambiguous call at line 9 (same package)", e)
+		}
+	}
+}
+
+func TestBuildCallGraph_CallersAndDepth(t *testing.T) {
+	g, err := BuildCallGraph(callGraphFixture(), "billing.validate", CallGraphOptions{Direction: CallGraphCallers, Depth: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"/ws/billing.go:20", "/ws/billing.go:5"}; !reflect.DeepEqual(nodeIDs(g), want) {
+		t.Errorf("depth 1 nodes = %v, want %v", nodeIDs(g), want)
+	}
+
+	g, err = BuildCallGraph(callGraphFixture(), "validate", CallGraphOptions{Direction: CallGraphCallers, Depth: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(g.Roots) != 3 {
+		t.Errorf("roots = %v, want the Go function and both PHP methods", g.Roots)
+	}
+	byID := make(map[string]CallGraphNode)
+	for _, n := range g.Nodes {
+		byID[n.ID] = n
+	}
+	if n, ok := byID["/ws/api.go:10"]; !ok || n.Depth != 2 {
+		t.Errorf("Handle should be a depth 2 caller, nodes = %v", nodeIDs(g))
+	}
+	// $this->validate() resolves to the caller's class only
+	if _, ok := byID["/ws/Order.php:4"]; !ok {
+		t.Errorf("Order::save should call Order::validate, nodes = %v", nodeIDs(g))
+	}
+	for _, e := range g.Edges {
+		if e.From == "/ws/Order.php:4" && e.To != "/ws/Order.php:11" {
+			t.Errorf("$this->validate() resolved to %s", e.To)
+		}
+	}
+}
+
+func TestBuildCallGraph_ExternalAndLimits(t *testing.T) {
+	g, err := BuildCallGraph(callGraphFixture(), "Handle", CallGraphOptions{Direction: CallGraphCallees, Depth: 1, IncludeExternal: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var external bool
+	for _, n := range g.Nodes {
+		if n.External && n.Name == "Println" {
+			external = true
+		}
+	}
+	if !external {
+		t.Errorf("nodes = %+v, want external Println", g.Nodes)
+	}
+
+	g, err = BuildCallGraph(callGraphFixture(), "Handle", CallGraphOptions{Direction: CallGraphCallees, Depth: 5, MaxNodes: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !g.Truncated || len(g.Nodes) != 2 {
+		t.Errorf("nodes = %v truncated = %v, want 2 nodes and truncated", nodeIDs(g), g.Truncated)
+	}
+	for _, e := range g.Edges {
+		if !containsID(g, e.From) || !containsID(g, e.To) {
+			t.Errorf("edge %+v references a node outside the graph", e)
+		}
+	}
+
+	if _, err := BuildCallGraph(callGraphFixture(), "Store", CallGraphOptions{}); err == nil {
+		t.Error("types are not call graph roots")
+	}
+}
+
+func containsID(g *CallGraph, id string) bool {
+	for _, n := range g.Nodes {
+		if n.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+func TestChunkCallSites_FromStoredPayload(t *testing.T) {
+	// Call sites decoded from the JSON payload of the index
+	ch := codetypes.CodeChunk{Name: "run", Metadata: map[string]any{"calls": []any{
+		map[string]any{"name": "Save", "receiver": "s", "line": float64(7)},
+		map[string]any{"name": "len", "line": float64(8)},
+	}}}
+	want := []codetypes.CallSite{{Name: "Save", Receiver: "s", Line: 7}}
+	if got := ChunkCallSites(ch); !reflect.DeepEqual(got, want) {
+		t.Errorf("ChunkCallSites = %+v, want %+v", got, want)
+	}
+	if got := ChunkCallees(ch); !reflect.DeepEqual(got, []string{"Save"}) {
+		t.Errorf("ChunkCallees = %v", got)
+	}
+}
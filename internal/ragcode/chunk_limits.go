@@ -0,0 +1,154 @@
+package ragcode
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/doITmagic/rag-code-mcp/internal/codetypes"
+)
+
+// ChunkLimiter caps the code stored per chunk, per language. A capped chunk
+// keeps its full StartLine-EndLine range and records in its metadata that
+// the code is truncated ("truncated") and how many lines were kept
+// ("code_lines"), so results can point to the rest. Capped classes also get
+// a structured summary of their members ("class_summary"), embedded in place
+// of the body that no longer fits.
+type ChunkLimiter struct {
+	maxLines map[codetypes.Language]int
+}
+
+// NewChunkLimiter returns a limiter for the per-language caps of
+// rag_code.max_chunk_lines, or nil when no language is capped.
+func NewChunkLimiter(maxLines map[string]int) *ChunkLimiter {
+	caps := make(map[codetypes.Language]int, len(maxLines))
+	for lang, n := range maxLines {
+		if n > 0 {
+			caps[codetypes.NormalizeLanguage(lang)] = n
+		}
+	}
+	if len(caps) == 0 {
+		return nil
+	}
+	return &ChunkLimiter{maxLines: caps}
+}
+
+func (l *ChunkLimiter) Name() string {
+	return "max_chunk_lines"
+}
+
+func (l *ChunkLimiter) Process(_ context.Context, chunks []codetypes.CodeChunk) ([]codetypes.CodeChunk, error) {
+	var byFile map[string][]int
+	for i := range chunks {
+		limit := l.maxLines[codetypes.NormalizeLanguage(string(chunks[i].Language))]
+		if limit <= 0 {
+			continue
+		}
+		lines := strings.Split(chunks[i].Code, "\n")
+		if len(lines) <= limit {
+			continue
+		}
+		chunks[i].Code = strings.Join(lines[:limit], "\n")
+		if chunks[i].Metadata == nil {
+			chunks[i].Metadata = make(map[string]any)
+		}
+		chunks[i].Metadata["truncated"] = true
+		chunks[i].Metadata["code_lines"] = limit
+
+		if classTypes[chunks[i].Type] {
+			if byFile == nil {
+				byFile = make(map[string][]int)
+				for j, ch := range chunks {
+					byFile[ch.FilePath] = append(byFile[ch.FilePath], j)
+				}
+			}
+			var members []codetypes.CodeChunk
+			for _, j := range byFile[chunks[i].FilePath] {
+				m := chunks[j]
+				if j != i && memberTypes[m.Type] && m.StartLine >= chunks[i].StartLine && m.EndLine <= chunks[i].EndLine {
+					members = append(members, m)
+				}
+			}
+			if summary := classSummary(chunks[i], members); summary != "" {
+				chunks[i].Metadata["class_summary"] = summary
+			}
+		}
+	}
+	return chunks, nil
+}
+
+// classTypes are the chunk types summarized when their code is capped;
+// memberTypes are the chunk types listed in the summary
+var (
+	classTypes  = map[string]bool{"class": true, "interface": true, "trait": true, "type": true, "struct": true, "impl": true}
+	memberTypes = map[string]bool{"method": true, "property": true, "field": true, "constant": true}
+)
+
+// maxResponsibilities bounds the member descriptions in a class summary
+const maxResponsibilities = 15
+
+// classSummary describes a class from its members: its fields, its methods
+// and what the documented methods do. It is empty for classes without
+// members in the chunk batch.
+func classSummary(class codetypes.CodeChunk, members []codetypes.CodeChunk) string {
+	if len(members) == 0 {
+		return ""
+	}
+	var fields, methods, duties []string
+	for _, m := range members {
+		switch m.Type {
+		case "method":
+			methods = append(methods, m.Name+"()")
+			if doc := firstSentence(m.Docstring); doc != "" && len(duties) < maxResponsibilities {
+				duties = append(duties, "- "+m.Name+": "+doc)
+			}
+		default:
+			fields = append(fields, m.Name)
+		}
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%s %s (%s, %d lines)\n", class.Type, class.Name, class.Language, class.EndLine-class.StartLine+1))
+	if doc := firstSentence(class.Docstring); doc != "" {
+		sb.WriteString(doc + "\n")
+	}
+	if len(fields) > 0 {
+		sb.WriteString("Fields: " + strings.Join(fields, ", ") + "\n")
+	}
+	if len(methods) > 0 {
+		sb.WriteString("Methods: " + strings.Join(methods, ", ") + "\n")
+	}
+	if len(duties) > 0 {
+		sb.WriteString("Responsibilities:\n" + strings.Join(duties, "\n") + "\n")
+	}
+	return strings.TrimSpace(sb.String())
+}
+
+// firstSentence returns the first sentence (or line) of a doc comment
+func firstSentence(doc string) string {
+	doc = strings.TrimSpace(doc)
+	if i := strings.IndexByte(doc, '\n'); i >= 0 {
+		doc = strings.TrimSpace(doc[:i])
+	}
+	if i := strings.Index(doc, ". "); i >= 0 {
+		doc = doc[:i+1]
+	}
+	return doc
+}
+
+// ChunkTruncated reports whether the code of a chunk was capped by a
+// ChunkLimiter, and how many lines were kept
+func ChunkTruncated(ch codetypes.CodeChunk) (bool, int) {
+	truncated, _ := ch.Metadata["truncated"].(bool)
+	if !truncated {
+		return false, 0
+	}
+	// Chunks read back from the index carry JSON numbers
+	switch n := ch.Metadata["code_lines"].(type) {
+	case int:
+		return true, n
+	case float64:
+		return true, int(n)
+	}
+	return true, 0
+}
@@ -0,0 +1,73 @@
+package ragcode
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/doITmagic/rag-code-mcp/internal/codetypes"
+)
+
+func TestChunkLimiter(t *testing.T) {
+	if NewChunkLimiter(map[string]int{"php": 0}) != nil {
+		t.Error("a limiter without caps should be nil")
+	}
+
+	long := strings.TrimSuffix(strings.Repeat("line\n", 10), "\n")
+	chunks := []codetypes.CodeChunk{
+		{Language: "php", Name: "Big", StartLine: 5, EndLine: 14, Code: long},
+		{Language: "php", Name: "Small", StartLine: 20, EndLine: 21, Code: "a\nb"},
+		{Language: "go", Name: "Uncapped", StartLine: 1, EndLine: 10, Code: long},
+	}
+	out, err := NewChunkLimiter(map[string]int{"PHP": 3}).Process(context.Background(), chunks)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	big := out[0]
+	if strings.Count(big.Code, "\n") != 2 || big.StartLine != 5 || big.EndLine != 14 {
+		t.Errorf("capped chunk: %d lines, range %d-%d", strings.Count(big.Code, "\n")+1, big.StartLine, big.EndLine)
+	}
+	if truncated, kept := ChunkTruncated(big); !truncated || kept != 3 {
+		t.Errorf("ChunkTruncated = %v, %d", truncated, kept)
+	}
+	for _, ch := range out[1:] {
+		if truncated, _ := ChunkTruncated(ch); truncated {
+			t.Errorf("%s should not be truncated", ch.Name)
+		}
+	}
+
+	// The flag survives the round trip through the stored chunk JSON
+	data, _ := json.Marshal(big)
+	var stored codetypes.CodeChunk
+	if err := json.Unmarshal(data, &stored); err != nil {
+		t.Fatal(err)
+	}
+	if truncated, kept := ChunkTruncated(stored); !truncated || kept != 3 {
+		t.Errorf("stored chunk: ChunkTruncated = %v, %d", truncated, kept)
+	}
+}
+
+func TestChunkLimiterClassSummary(t *testing.T) {
+	body := strings.TrimSuffix(strings.Repeat("    // ...\n", 40), "\n")
+	chunks := []codetypes.CodeChunk{
+		{Language: "php", Type: "class", Name: "Invoice", FilePath: "a.php", StartLine: 1, EndLine: 40, Code: body, Docstring: "Invoice of an order."},
+		{Language: "php", Type: "property", Name: "total", FilePath: "a.php", StartLine: 3, EndLine: 3},
+		{Language: "php", Type: "method", Name: "send", FilePath: "a.php", StartLine: 5, EndLine: 20, Docstring: "Sends the invoice by mail. Retries twice."},
+		{Language: "php", Type: "method", Name: "other", FilePath: "b.php", StartLine: 5, EndLine: 20},
+	}
+	out, err := NewChunkLimiter(map[string]int{"php": 10}).Process(context.Background(), chunks)
+	if err != nil {
+		t.Fatal(err)
+	}
+	summary, _ := out[0].Metadata["class_summary"].(string)
+	for _, want := range []string{"class Invoice (php, 40 lines)", "Invoice of an order.", "Fields: total", "Methods: send()", "- send: Sends the invoice by mail."} {
+		if !strings.Contains(summary, want) {
+			t.Errorf("summary lacks %q:\n%s", want, summary)
+		}
+	}
+	if strings.Contains(summary, "other") || strings.Contains(summary, "Retries") {
+		t.Errorf("summary should only list the class members, first sentences:\n%s", summary)
+	}
+}
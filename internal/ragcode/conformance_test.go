@@ -0,0 +1,212 @@
+package ragcode
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/doITmagic/rag-code-mcp/internal/codetypes"
+)
+
+// updateGolden rewrites the golden chunks of the conformance fixtures:
+//
+//	go test ./internal/ragcode -run TestAnalyzerConformance -update
+var updateGolden = flag.Bool("update", false, "rewrite testdata/conformance/*/golden.json from the analyzer output")
+
+// goldenChunk is the part of a chunk the conformance suite pins: the symbol,
+// its location and its documentation. Code and metadata vary too much
+// between harmless changes to be compared verbatim.
+type goldenChunk struct {
+	Type      string `json:"type"`
+	Name      string `json:"name"`
+	Package   string `json:"package,omitempty"`
+	File      string `json:"file"`
+	StartLine int    `json:"start_line,omitempty"`
+	EndLine   int    `json:"end_line,omitempty"`
+	Signature string `json:"signature,omitempty"`
+	Docstring string `json:"docstring,omitempty"`
+	Partial   bool   `json:"partial,omitempty"`
+}
+
+// TestAnalyzerConformance runs every analyzer on its fixture repository in
+// testdata/conformance/<language> and compares the chunks with golden.json.
+// Every analyzer must also satisfy the invariants of checkChunkInvariants.
+func TestAnalyzerConformance(t *testing.T) {
+	dirs, err := os.ReadDir(filepath.Join("testdata", "conformance"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, lang := range []Language{LanguageGo, LanguagePHP, LanguagePython, LanguageRust, LanguageHTML} {
+		found := false
+		for _, d := range dirs {
+			found = found || d.Name() == string(lang)
+		}
+		if !found {
+			t.Errorf("no conformance fixture for %s", lang)
+		}
+	}
+
+	for _, d := range dirs {
+		if !d.IsDir() {
+			continue
+		}
+		lang := d.Name()
+		t.Run(lang, func(t *testing.T) {
+			root, err := filepath.Abs(filepath.Join("testdata", "conformance", lang))
+			if err != nil {
+				t.Fatal(err)
+			}
+			analyzer := NewAnalyzerManager().CodeAnalyzerForProjectType(lang)
+			if analyzer == nil {
+				t.Fatalf("no analyzer for fixture %s", lang)
+			}
+			chunks, err := analyzer.AnalyzePaths([]string{root})
+			if err != nil {
+				t.Fatal(err)
+			}
+			checkChunkInvariants(t, lang, root, chunks)
+
+			got := goldenChunks(t, root, chunks)
+			goldenPath := filepath.Join(root, "golden.json")
+			if *updateGolden {
+				data, err := json.MarshalIndent(got, "", "  ")
+				if err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(goldenPath, append(data, '\n'), 0644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+
+			data, err := os.ReadFile(goldenPath)
+			if err != nil {
+				t.Fatalf("%v (run with -update to create it)", err)
+			}
+			var want []goldenChunk
+			if err := json.Unmarshal(data, &want); err != nil {
+				t.Fatal(err)
+			}
+			diffGolden(t, want, got)
+		})
+	}
+}
+
+// checkChunkInvariants holds for every analyzer: chunks are named and typed,
+// tagged with the analyzer language, located inside the analyzed tree with a
+// line range within the file, and not duplicated.
+func checkChunkInvariants(t *testing.T, lang, root string, chunks []codetypes.CodeChunk) {
+	t.Helper()
+	if len(chunks) == 0 {
+		t.Fatal("no chunks")
+	}
+	lineCounts := make(map[string]int)
+	seen := make(map[string]bool)
+	for _, ch := range chunks {
+		id := ch.FilePath + ":" + ch.Type + ":" + ch.Name + ":" + strconv.Itoa(ch.StartLine)
+		if ch.Name == "" || ch.Type == "" {
+			t.Errorf("chunk without name or type: %s", id)
+		}
+		if string(ch.Language) != lang || !ch.Language.Valid() {
+			t.Errorf("%s: language %q, want %q", id, ch.Language, lang)
+		}
+		if seen[id] {
+			t.Errorf("duplicate chunk %s", id)
+		}
+		seen[id] = true
+
+		abs, err := filepath.Abs(ch.FilePath)
+		if err != nil || !strings.HasPrefix(abs, root+string(filepath.Separator)) {
+			t.Errorf("%s: file outside the analyzed tree", id)
+			continue
+		}
+		n, ok := lineCounts[abs]
+		if !ok {
+			data, err := os.ReadFile(abs)
+			if err != nil {
+				t.Errorf("%s: %v", id, err)
+				continue
+			}
+			n = strings.Count(string(data), "\n") + 1
+			lineCounts[abs] = n
+		}
+		if ch.StartLine < 0 || ch.EndLine < ch.StartLine || ch.EndLine > n {
+			t.Errorf("%s: lines %d-%d outside the %d lines of the file", id, ch.StartLine, ch.EndLine, n)
+		}
+		if ch.SelectionStartLine != 0 && (ch.SelectionStartLine < ch.StartLine || ch.SelectionStartLine > ch.EndLine) {
+			t.Errorf("%s: selection line %d outside the chunk %d-%d", id, ch.SelectionStartLine, ch.StartLine, ch.EndLine)
+		}
+	}
+}
+
+// goldenChunks projects chunks on goldenChunk, with paths relative to the
+// fixture root, in a stable order
+func goldenChunks(t *testing.T, root string, chunks []codetypes.CodeChunk) []goldenChunk {
+	t.Helper()
+	out := make([]goldenChunk, 0, len(chunks))
+	for _, ch := range chunks {
+		abs, _ := filepath.Abs(ch.FilePath)
+		rel, err := filepath.Rel(root, abs)
+		if err != nil {
+			t.Fatal(err)
+		}
+		out = append(out, goldenChunk{
+			Type:      ch.Type,
+			Name:      ch.Name,
+			Package:   ch.Package,
+			File:      filepath.ToSlash(rel),
+			StartLine: ch.StartLine,
+			EndLine:   ch.EndLine,
+			Signature: ch.Signature,
+			Docstring: ch.Docstring,
+			Partial:   ch.Partial(),
+		})
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		a, b := out[i], out[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		if a.StartLine != b.StartLine {
+			return a.StartLine < b.StartLine
+		}
+		if a.Type != b.Type {
+			return a.Type < b.Type
+		}
+		return a.Name < b.Name
+	})
+	return out
+}
+
+// diffGolden reports the chunks missing, added or changed compared to the
+// golden file, keyed by file, type and name
+func diffGolden(t *testing.T, want, got []goldenChunk) {
+	t.Helper()
+	key := func(c goldenChunk) string { return c.File + " " + c.Type + " " + c.Name }
+	wantByKey := make(map[string][]goldenChunk)
+	for _, c := range want {
+		wantByKey[key(c)] = append(wantByKey[key(c)], c)
+	}
+	for _, c := range got {
+		k := key(c)
+		if len(wantByKey[k]) == 0 {
+			t.Errorf("new chunk %s at lines %d-%d (run with -update if expected)", k, c.StartLine, c.EndLine)
+			continue
+		}
+		w := wantByKey[k][0]
+		wantByKey[k] = wantByKey[k][1:]
+		if w != c {
+			t.Errorf("chunk %s changed:\n got  %+v\n want %+v", k, c, w)
+		}
+	}
+	for k, rest := range wantByKey {
+		for range rest {
+			t.Errorf("chunk %s no longer extracted", k)
+		}
+	}
+}
@@ -0,0 +1,289 @@
+package ragcode
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CoverageBlock is a range of source lines with its statement count and the
+// number of times it was executed. Line-based formats produce one block per line.
+type CoverageBlock struct {
+	StartLine  int `json:"start_line"`
+	EndLine    int `json:"end_line"`
+	Statements int `json:"statements"`
+	Hits       int `json:"hits"`
+}
+
+// FileCoverage holds the coverage blocks of a single source file.
+type FileCoverage struct {
+	Blocks []CoverageBlock `json:"blocks"`
+}
+
+// CoverageReport is a parsed coverage file, keyed by the file paths it reports.
+type CoverageReport struct {
+	Format   string                   `json:"format"` // go | clover | cobertura | coveragepy
+	Files    map[string]*FileCoverage `json:"files"`
+	LoadedAt time.Time                `json:"loaded_at"`
+}
+
+// Range returns the covered and total statements of blocks overlapping [start, end].
+func (f *FileCoverage) Range(start, end int) (covered, total int) {
+	if f == nil {
+		return 0, 0
+	}
+	for _, b := range f.Blocks {
+		if b.EndLine < start || (end > 0 && b.StartLine > end) {
+			continue
+		}
+		total += b.Statements
+		if b.Hits > 0 {
+			covered += b.Statements
+		}
+	}
+	return covered, total
+}
+
+// Percent returns the statement coverage of the given line range in file.
+// ok is false when the file is unknown or the range has no statements.
+func (r *CoverageReport) Percent(file string, start, end int) (percent float64, ok bool) {
+	if r == nil {
+		return 0, false
+	}
+	covered, total := r.Files[file].Range(start, end)
+	if total == 0 {
+		return 0, false
+	}
+	return 100 * float64(covered) / float64(total), true
+}
+
+// Totals returns the covered and total statements across the whole report.
+func (r *CoverageReport) Totals() (covered, total int) {
+	for _, f := range r.Files {
+		c, t := f.Range(0, 0)
+		covered += c
+		total += t
+	}
+	return covered, total
+}
+
+// ParseCoverage detects the format of a coverage file and parses it. Supported
+// formats are Go coverprofiles, Clover XML (PHPUnit), Cobertura XML
+// (coverage.py xml) and coverage.py JSON.
+func ParseCoverage(data []byte) (*CoverageReport, error) {
+	trimmed := bytes.TrimSpace(data)
+	switch {
+	case bytes.HasPrefix(trimmed, []byte("mode:")):
+		return parseGoCoverProfile(trimmed)
+	case bytes.HasPrefix(trimmed, []byte("{")):
+		return parseCoveragePyJSON(trimmed)
+	case bytes.HasPrefix(trimmed, []byte("<")):
+		return parseCoverageXML(trimmed)
+	default:
+		return nil, fmt.Errorf("unrecognised coverage format (expected Go coverprofile, Clover/Cobertura XML or coverage.py JSON)")
+	}
+}
+
+func newCoverageReport(format string) *CoverageReport {
+	return &CoverageReport{
+		Format:   format,
+		Files:    make(map[string]*FileCoverage),
+		LoadedAt: time.Now(),
+	}
+}
+
+func (r *CoverageReport) file(name string) *FileCoverage {
+	fc, ok := r.Files[name]
+	if !ok {
+		fc = &FileCoverage{}
+		r.Files[name] = fc
+	}
+	return fc
+}
+
+// parseGoCoverProfile parses `go test -coverprofile` output:
+//
+//	github.com/acme/app/store/db.go:12.34,15.2 3 1
+//
+// Blocks repeated across packages (e.g. with -coverpkg) are merged.
+func parseGoCoverProfile(data []byte) (*CoverageReport, error) {
+	report := newCoverageReport("go")
+	type blockKey struct {
+		file       string
+		start, end int
+	}
+	merged := make(map[blockKey]int)
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "mode:") {
+			continue
+		}
+		colon := strings.LastIndex(line, ":")
+		if colon < 0 {
+			return nil, fmt.Errorf("invalid coverprofile line: %q", line)
+		}
+		file := line[:colon]
+		var sl, sc, el, ec, stmts, hits int
+		if _, err := fmt.Sscanf(line[colon+1:], "%d.%d,%d.%d %d %d", &sl, &sc, &el, &ec, &stmts, &hits); err != nil {
+			return nil, fmt.Errorf("invalid coverprofile line %q: %w", line, err)
+		}
+
+		key := blockKey{file, sl, el}
+		if idx, ok := merged[key]; ok {
+			b := &report.Files[file].Blocks[idx]
+			if hits > b.Hits {
+				b.Hits = hits
+			}
+			continue
+		}
+		fc := report.file(file)
+		merged[key] = len(fc.Blocks)
+		fc.Blocks = append(fc.Blocks, CoverageBlock{StartLine: sl, EndLine: el, Statements: stmts, Hits: hits})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// parseCoveragePyJSON parses the output of `coverage json`.
+func parseCoveragePyJSON(data []byte) (*CoverageReport, error) {
+	var raw struct {
+		Files map[string]struct {
+			ExecutedLines []int `json:"executed_lines"`
+			MissingLines  []int `json:"missing_lines"`
+		} `json:"files"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("invalid coverage.py JSON: %w", err)
+	}
+	if raw.Files == nil {
+		return nil, fmt.Errorf("invalid coverage.py JSON: missing \"files\"")
+	}
+
+	report := newCoverageReport("coveragepy")
+	for name, f := range raw.Files {
+		fc := report.file(name)
+		for _, l := range f.ExecutedLines {
+			fc.Blocks = append(fc.Blocks, CoverageBlock{StartLine: l, EndLine: l, Statements: 1, Hits: 1})
+		}
+		for _, l := range f.MissingLines {
+			fc.Blocks = append(fc.Blocks, CoverageBlock{StartLine: l, EndLine: l, Statements: 1})
+		}
+		sortBlocks(fc)
+	}
+	return report, nil
+}
+
+// parseCoverageXML parses Clover (<file name><line num count type>) and
+// Cobertura (<class filename><line number hits>) reports. Lines listed more
+// than once, as Cobertura does for method and class line lists, count once.
+func parseCoverageXML(data []byte) (*CoverageReport, error) {
+	report := newCoverageReport("")
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+
+	var (
+		current string
+		source  string
+		inSrc   bool
+		seen    = make(map[string]map[int]bool)
+	)
+	attr := func(el xml.StartElement, name string) string {
+		for _, a := range el.Attr {
+			if a.Name.Local == name {
+				return a.Value
+			}
+		}
+		return ""
+	}
+
+	for {
+		tok, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid coverage XML: %w", err)
+		}
+
+		switch el := tok.(type) {
+		case xml.StartElement:
+			switch el.Name.Local {
+			case "coverage":
+				// Cobertura roots carry line-rate; Clover roots do not
+				if attr(el, "line-rate") != "" {
+					report.Format = "cobertura"
+				} else {
+					report.Format = "clover"
+				}
+			case "source":
+				inSrc = true
+			case "file":
+				current = attr(el, "path")
+				if current == "" {
+					current = attr(el, "name")
+				}
+			case "class":
+				if name := attr(el, "filename"); name != "" {
+					if source != "" && !path.IsAbs(name) {
+						name = path.Join(source, name)
+					}
+					current = name
+				}
+			case "line":
+				if current == "" {
+					continue
+				}
+				if t := attr(el, "type"); t == "method" {
+					continue
+				}
+				num, _ := strconv.Atoi(firstNonEmpty(attr(el, "num"), attr(el, "number")))
+				hits, _ := strconv.Atoi(firstNonEmpty(attr(el, "count"), attr(el, "hits")))
+				if seen[current] == nil {
+					seen[current] = make(map[int]bool)
+				}
+				if num <= 0 || seen[current][num] {
+					continue
+				}
+				seen[current][num] = true
+				fc := report.file(current)
+				fc.Blocks = append(fc.Blocks, CoverageBlock{StartLine: num, EndLine: num, Statements: 1, Hits: hits})
+			}
+		case xml.CharData:
+			if inSrc && source == "" {
+				source = strings.TrimSpace(string(el))
+			}
+		case xml.EndElement:
+			switch el.Name.Local {
+			case "source":
+				inSrc = false
+			case "file":
+				current = ""
+			}
+		}
+	}
+
+	if report.Format == "" {
+		return nil, fmt.Errorf("invalid coverage XML: no <coverage> root element")
+	}
+	for _, fc := range report.Files {
+		sortBlocks(fc)
+	}
+	return report, nil
+}
+
+func sortBlocks(fc *FileCoverage) {
+	sort.Slice(fc.Blocks, func(i, j int) bool {
+		return fc.Blocks[i].StartLine < fc.Blocks[j].StartLine
+	})
+}
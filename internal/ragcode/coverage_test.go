@@ -0,0 +1,87 @@
+package ragcode
+
+import "testing"
+
+func TestParseCoverage_GoProfile(t *testing.T) {
+	profile := `mode: set
+github.com/acme/app/store/db.go:10.20,12.2 2 1
+github.com/acme/app/store/db.go:14.30,18.2 3 0
+github.com/acme/app/store/db.go:14.30,18.2 3 1
+`
+	report, err := ParseCoverage([]byte(profile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Format != "go" {
+		t.Errorf("format = %q, want go", report.Format)
+	}
+	fc := report.Files["github.com/acme/app/store/db.go"]
+	if fc == nil || len(fc.Blocks) != 2 {
+		t.Fatalf("expected 2 merged blocks, got %+v", fc)
+	}
+	if pct, ok := report.Percent("github.com/acme/app/store/db.go", 14, 18); !ok || pct != 100 {
+		t.Errorf("Percent = %v, %v; want 100 (duplicate block merged with max hits)", pct, ok)
+	}
+}
+
+func TestParseCoverage_Clover(t *testing.T) {
+	clover := `<?xml version="1.0" encoding="UTF-8"?>
+<coverage generated="1700000000">
+  <project timestamp="1700000000">
+    <file name="/app/src/Billing.php">
+      <line num="10" type="method" name="charge" count="1"/>
+      <line num="11" type="stmt" count="1"/>
+      <line num="12" type="stmt" count="0"/>
+    </file>
+  </project>
+</coverage>`
+	report, err := ParseCoverage([]byte(clover))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Format != "clover" {
+		t.Errorf("format = %q, want clover", report.Format)
+	}
+	if pct, ok := report.Percent("/app/src/Billing.php", 10, 12); !ok || pct != 50 {
+		t.Errorf("Percent = %v, %v; want 50", pct, ok)
+	}
+}
+
+func TestParseCoverage_CoberturaAndCoveragePy(t *testing.T) {
+	cobertura := `<?xml version="1.0" ?>
+<coverage version="7.3" line-rate="0.5">
+  <sources><source>/srv/app</source></sources>
+  <packages><package name="jobs"><classes>
+    <class name="worker.py" filename="jobs/worker.py">
+      <methods/>
+      <lines><line number="1" hits="1"/><line number="2" hits="0"/></lines>
+    </class>
+  </classes></package></packages>
+</coverage>`
+	report, err := ParseCoverage([]byte(cobertura))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Format != "cobertura" {
+		t.Errorf("format = %q, want cobertura", report.Format)
+	}
+	if _, ok := report.Files["/srv/app/jobs/worker.py"]; !ok {
+		t.Errorf("expected filename joined with source, got %v", report.Files)
+	}
+
+	js := `{"meta": {"version": "7.3"}, "files": {"jobs/worker.py": {"executed_lines": [1, 3], "missing_lines": [2, 4]}}}`
+	report, err = ParseCoverage([]byte(js))
+	if err != nil {
+		t.Fatal(err)
+	}
+	covered, total := report.Totals()
+	if report.Format != "coveragepy" || covered != 2 || total != 4 {
+		t.Errorf("format=%q covered=%d total=%d", report.Format, covered, total)
+	}
+}
+
+func TestParseCoverage_Unknown(t *testing.T) {
+	if _, err := ParseCoverage([]byte("not a coverage file")); err == nil {
+		t.Error("expected error for unknown format")
+	}
+}
@@ -0,0 +1,202 @@
+package ragcode
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/doITmagic/rag-code-mcp/internal/codetypes"
+)
+
+// PackageDependency is a package imported by, or importing, the package of a
+// dependency query, with the files that carry the import
+type PackageDependency struct {
+	Package  string   `json:"package"`
+	External bool     `json:"external,omitempty"` // not a package of the workspace
+	Files    []string `json:"files"`
+}
+
+// PackageDependencies is the import graph around one package of a workspace
+type PackageDependencies struct {
+	Package    string              `json:"package"`
+	Language   string              `json:"language"`
+	Files      []string            `json:"files"`
+	Imports    []PackageDependency `json:"imports"`     // workspace packages first, then external ones
+	ImportedBy []PackageDependency `json:"imported_by"` // workspace packages only
+}
+
+// packageKey identifies a package: names are only unique within a language
+type packageKey struct {
+	language codetypes.Language
+	name     string
+}
+
+// BuildDependencies returns what a package imports and which packages of the
+// workspace import it, from the imports recorded in the symbol table. pkg is
+// matched against the package names (Go import paths, Python modules, PHP
+// and C# namespaces), then their last element ("billing" for
+// "example.com/app/billing"), then the directory of their files relative to
+// root. Imports name a workspace package exactly, or, outside Go, a module
+// or class inside it ("app.models.User", "App\Models\User").
+func BuildDependencies(files []codetypes.FileImports, pkg, root string) (*PackageDependencies, error) {
+	packages := make(map[packageKey][]codetypes.FileImports)
+	for _, f := range files {
+		k := packageKey{f.Language, f.Package}
+		packages[k] = append(packages[k], f)
+	}
+
+	target, err := matchPackage(packages, pkg, root)
+	if err != nil {
+		return nil, err
+	}
+	resolve := func(imp string) string {
+		return resolveImport(packages, target.language, imp)
+	}
+
+	deps := &PackageDependencies{
+		Package:    target.name,
+		Language:   string(target.language),
+		Imports:    []PackageDependency{},
+		ImportedBy: []PackageDependency{},
+	}
+	imports := make(map[string]*PackageDependency)
+	for _, f := range packages[target] {
+		deps.Files = append(deps.Files, f.FilePath)
+		for _, imp := range f.Imports {
+			name := resolve(imp)
+			if name == target.name {
+				continue
+			}
+			external := name == ""
+			if external {
+				name = imp
+			}
+			d, ok := imports[name]
+			if !ok {
+				d = &PackageDependency{Package: name, External: external}
+				imports[name] = d
+			}
+			d.Files = appendUnique(d.Files, f.FilePath)
+		}
+	}
+
+	importers := make(map[string]*PackageDependency)
+	for k, pkgFiles := range packages {
+		if k.language != target.language || k == target {
+			continue
+		}
+		for _, f := range pkgFiles {
+			for _, imp := range f.Imports {
+				if resolve(imp) != target.name {
+					continue
+				}
+				d, ok := importers[k.name]
+				if !ok {
+					d = &PackageDependency{Package: k.name}
+					importers[k.name] = d
+				}
+				d.Files = appendUnique(d.Files, f.FilePath)
+			}
+		}
+	}
+
+	sort.Strings(deps.Files)
+	deps.Imports = sortedDependencies(imports)
+	deps.ImportedBy = sortedDependencies(importers)
+	return deps, nil
+}
+
+// matchPackage finds the package named pkg, see BuildDependencies
+func matchPackage(packages map[packageKey][]codetypes.FileImports, pkg, root string) (packageKey, error) {
+	pkg = strings.TrimSpace(strings.Trim(pkg, `\`))
+	dir := filepath.ToSlash(filepath.Clean(pkg))
+	matchers := []func(k packageKey, files []codetypes.FileImports) bool{
+		func(k packageKey, _ []codetypes.FileImports) bool { return k.name == pkg },
+		func(k packageKey, _ []codetypes.FileImports) bool { return lastElement(k.name) == pkg },
+		func(_ packageKey, files []codetypes.FileImports) bool {
+			for _, f := range files {
+				rel, err := filepath.Rel(root, filepath.Dir(f.FilePath))
+				if err == nil && filepath.ToSlash(rel) == dir {
+					return true
+				}
+			}
+			return false
+		},
+	}
+	for _, match := range matchers {
+		var found []packageKey
+		for k, files := range packages {
+			if k.name != "" && match(k, files) {
+				found = append(found, k)
+			}
+		}
+		switch len(found) {
+		case 0:
+			continue
+		case 1:
+			return found[0], nil
+		}
+		names := make([]string, 0, len(found))
+		for _, k := range found {
+			names = append(names, fmt.Sprintf("%s (%s)", k.name, k.language))
+		}
+		sort.Strings(names)
+		return packageKey{}, fmt.Errorf("package '%s' is ambiguous, use one of: %s", pkg, strings.Join(names, ", "))
+	}
+	return packageKey{}, fmt.Errorf("package '%s' not found in the workspace imports", pkg)
+}
+
+// resolveImport returns the workspace package an import refers to, empty for
+// external imports. Go imports name a package; elsewhere an import may name
+// a module or class inside a package and resolves to the longest package
+// prefixing it.
+func resolveImport(packages map[packageKey][]codetypes.FileImports, language codetypes.Language, imp string) string {
+	imp = strings.TrimPrefix(imp, `\`)
+	for name := imp; name != ""; {
+		if _, ok := packages[packageKey{language, name}]; ok {
+			return name
+		}
+		if language == codetypes.LanguageGo {
+			return ""
+		}
+		i := strings.LastIndexAny(name, `.\`)
+		if i < 0 {
+			return ""
+		}
+		name = name[:i]
+	}
+	return ""
+}
+
+// lastElement returns the last element of a package name: after the last /,
+// . or \
+func lastElement(name string) string {
+	return name[strings.LastIndexAny(name, `/.\`)+1:]
+}
+
+func appendUnique(list []string, s string) []string {
+	for _, v := range list {
+		if v == s {
+			return list
+		}
+	}
+	return append(list, s)
+}
+
+// sortedDependencies orders dependencies: workspace packages first, then by
+// name, each with its files sorted
+func sortedDependencies(deps map[string]*PackageDependency) []PackageDependency {
+	out := make([]PackageDependency, 0, len(deps))
+	for _, d := range deps {
+		sort.Strings(d.Files)
+		out = append(out, *d)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].External != out[j].External {
+			return !out[i].External
+		}
+		return out[i].Package < out[j].Package
+	})
+	return out
+}
@@ -0,0 +1,66 @@
+package ragcode
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/doITmagic/rag-code-mcp/internal/codetypes"
+)
+
+func dependenciesFixture() []codetypes.FileImports {
+	return []codetypes.FileImports{
+		{FilePath: "/ws/api/api.go", Language: "go", Package: "example.com/app/api", Imports: []string{"fmt", "example.com/app/billing"}},
+		{FilePath: "/ws/billing/charge.go", Language: "go", Package: "example.com/app/billing", Imports: []string{"context", "example.com/app/store"}},
+		{FilePath: "/ws/billing/refund.go", Language: "go", Package: "example.com/app/billing", Imports: []string{"example.com/app/store", "example.com/app/billing/internal"}},
+		{FilePath: "/ws/store/store.go", Language: "go", Package: "example.com/app/store"},
+		{FilePath: "/ws/app/Http/OrderController.php", Language: "php", Package: `App\Http`, Imports: []string{`App\Models\Order`, `Illuminate\Http\Request`}},
+		{FilePath: "/ws/app/Models/Order.php", Language: "php", Package: `App\Models`},
+		{FilePath: "/ws/py/app/views.py", Language: "python", Package: "app.views", Imports: []string{"app.models", "django.http"}},
+		{FilePath: "/ws/py/app/models.py", Language: "python", Package: "app.models"},
+	}
+}
+
+func TestBuildDependencies(t *testing.T) {
+	deps, err := BuildDependencies(dependenciesFixture(), "billing", "/ws")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deps.Package != "example.com/app/billing" || len(deps.Files) != 2 {
+		t.Fatalf("package = %s with files %v", deps.Package, deps.Files)
+	}
+	// Go imports name a package exactly: a subpackage missing from the
+	// workspace is external, not its parent
+	want := []PackageDependency{
+		{Package: "example.com/app/store", Files: []string{"/ws/billing/charge.go", "/ws/billing/refund.go"}},
+		{Package: "context", External: true, Files: []string{"/ws/billing/charge.go"}},
+		{Package: "example.com/app/billing/internal", External: true, Files: []string{"/ws/billing/refund.go"}},
+	}
+	if !reflect.DeepEqual(deps.Imports, want) {
+		t.Errorf("imports = %+v", deps.Imports)
+	}
+	if len(deps.ImportedBy) != 1 || deps.ImportedBy[0].Package != "example.com/app/api" {
+		t.Errorf("imported by = %+v", deps.ImportedBy)
+	}
+
+	// PHP uses name classes inside a namespace
+	deps, err = BuildDependencies(dependenciesFixture(), `App\Models`, "/ws")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(deps.ImportedBy) != 1 || deps.ImportedBy[0].Package != `App\Http` {
+		t.Errorf("PHP imported by = %+v", deps.ImportedBy)
+	}
+
+	// Directories relative to the root name packages too
+	deps, err = BuildDependencies(dependenciesFixture(), "py/app", "/ws")
+	if err == nil {
+		t.Fatalf("directory with two modules resolved to %s", deps.Package)
+	}
+	deps, err = BuildDependencies(dependenciesFixture(), "store", "/ws")
+	if err != nil || deps.Package != "example.com/app/store" || len(deps.ImportedBy) != 1 {
+		t.Fatalf("store = %+v, %v", deps, err)
+	}
+	if _, err := BuildDependencies(dependenciesFixture(), "missing", "/ws"); err == nil {
+		t.Error("unknown package resolved")
+	}
+}
@@ -0,0 +1,56 @@
+package ragcode
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/doITmagic/rag-code-mcp/internal/codetypes"
+)
+
+var (
+	// Go convention: a doc paragraph starting with "Deprecated: "
+	goDeprecatedRe = regexp.MustCompile(`(?m)^Deprecated:\s*(.*)$`)
+	// warnings.warn("use bar()", DeprecationWarning) / category=DeprecationWarning
+	pyDeprecationWarnRe = regexp.MustCompile(`warnings\.warn\(\s*[fFrR]?(?:"([^"]*)"|'([^']*)')\s*,\s*(?:category\s*=\s*)?(?:Pending)?DeprecationWarning`)
+	// @deprecated("use bar()") / @warnings.deprecated(...) / @typing_extensions.deprecated(...)
+	pyDeprecatedDecoratorRe = regexp.MustCompile(`@(?:\w+\.)*deprecated\(\s*[fFrR]?(?:"([^"]*)"|'([^']*)')`)
+)
+
+// AnnotateDeprecations normalizes deprecation markers into chunk metadata
+// (see codetypes.MarkDeprecated). Chunks already marked by their analyzer,
+// e.g. PHP @deprecated, are left untouched.
+func AnnotateDeprecations(chunks []codetypes.CodeChunk) {
+	for i := range chunks {
+		ch := &chunks[i]
+		if deprecated, _ := ch.Deprecation(); deprecated {
+			continue
+		}
+		if note, ok := detectDeprecation(*ch); ok {
+			codetypes.MarkDeprecated(ch, note)
+		}
+	}
+}
+
+func detectDeprecation(ch codetypes.CodeChunk) (string, bool) {
+	switch ch.Language {
+	case "go":
+		if m := goDeprecatedRe.FindStringSubmatch(ch.Docstring); m != nil {
+			return firstNonEmpty(strings.TrimSpace(m[1]), "deprecated"), true
+		}
+	case "python":
+		if m := pyDeprecatedDecoratorRe.FindStringSubmatch(ch.Code); m != nil {
+			return firstNonEmpty(m[1], m[2], "deprecated"), true
+		}
+		if decorators, ok := ch.Metadata["decorators"].([]string); ok {
+			for _, d := range decorators {
+				if d == "deprecated" || strings.HasSuffix(d, ".deprecated") {
+					return "deprecated", true
+				}
+			}
+		}
+		if m := pyDeprecationWarnRe.FindStringSubmatch(ch.Code); m != nil {
+			return firstNonEmpty(m[1], m[2], "deprecated"), true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,41 @@
+package ragcode
+
+import (
+	"testing"
+
+	"github.com/doITmagic/rag-code-mcp/internal/codetypes"
+)
+
+func TestAnnotateDeprecations(t *testing.T) {
+	marked := codetypes.CodeChunk{Name: "Old", Language: "php"}
+	codetypes.MarkDeprecated(&marked, "from analyzer")
+
+	chunks := []codetypes.CodeChunk{
+		{Name: "Dial", Language: "go", Docstring: "Dial connects.\n\nDeprecated: use DialContext instead."},
+		{Name: "Listen", Language: "go", Docstring: "Listen listens."},
+		{Name: "load", Language: "python", Code: "@deprecated(\"use load_v2\")\ndef load():\n    pass"},
+		{Name: "save", Language: "python", Code: "def save():\n    warnings.warn('use store()', DeprecationWarning)\n"},
+		{Name: "fetch", Language: "python", Metadata: map[string]interface{}{"decorators": []string{"typing_extensions.deprecated"}}},
+		marked,
+	}
+	AnnotateDeprecations(chunks)
+
+	want := map[string]string{
+		"Dial":  "use DialContext instead.",
+		"load":  "use load_v2",
+		"save":  "use store()",
+		"fetch": "deprecated",
+		"Old":   "from analyzer",
+	}
+	for _, ch := range chunks {
+		deprecated, note := ch.Deprecation()
+		expected, ok := want[ch.Name]
+		if deprecated != ok {
+			t.Errorf("%s: deprecated = %v, want %v", ch.Name, deprecated, ok)
+			continue
+		}
+		if note != expected {
+			t.Errorf("%s: note = %q, want %q", ch.Name, note, expected)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package ragcode
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// docLanguages are the ISO 639-1 codes recognised in documentation file
+// names and directories (README.zh.md, docs/fr/setup.md).
+var docLanguages = map[string]bool{
+	"ar": true, "de": true, "el": true, "en": true, "es": true, "fr": true,
+	"he": true, "it": true, "ja": true, "ko": true, "nl": true, "pl": true,
+	"pt": true, "ro": true, "ru": true, "tr": true, "uk": true, "vi": true,
+	"zh": true,
+}
+
+var (
+	// README.zh.md, README.zh-CN.md, README_pt-BR.md, guide-de.md
+	docLangSuffixRe = regexp.MustCompile(`(?i)[._-]([a-z]{2})(?:[-_][a-z]{2,4})?\.(?:md|markdown|mdx)$`)
+	docCodeBlockRe  = regexp.MustCompile("(?s)```.*?```|`[^`\n]*`|https?://\\S+")
+	docWordRe       = regexp.MustCompile(`\p{L}+`)
+)
+
+// docStopwords are frequent function words used to tell Latin-script
+// languages apart.
+var docStopwords = map[string][]string{
+	"en": {"the", "and", "is", "to", "of", "you", "with", "for", "this", "are"},
+	"de": {"der", "die", "und", "ist", "nicht", "mit", "sie", "das", "ein", "für"},
+	"fr": {"le", "la", "les", "et", "est", "des", "une", "pour", "vous", "avec"},
+	"es": {"el", "los", "las", "y", "es", "una", "para", "con", "que", "por"},
+	"it": {"il", "gli", "e", "è", "una", "per", "che", "con", "sono", "della"},
+	"pt": {"o", "os", "as", "e", "é", "uma", "para", "com", "não", "você"},
+	"ro": {"și", "este", "în", "pentru", "cu", "sunt", "care", "nu", "o", "să"},
+	"nl": {"de", "het", "en", "is", "een", "van", "voor", "met", "niet", "je"},
+}
+
+// DetectDocLanguage returns the ISO 639-1 code of a documentation file, or ""
+// when it cannot be told. A language tag in the file name or a directory
+// (README.zh.md, docs/fr/) wins over the content; otherwise the dominant
+// script decides, and Latin-script text is told apart by its stopwords.
+func DetectDocLanguage(path, text string) string {
+	if m := docLangSuffixRe.FindStringSubmatch(filepath.Base(path)); m != nil {
+		if code := strings.ToLower(m[1]); docLanguages[code] {
+			return code
+		}
+	}
+	for _, dir := range strings.Split(filepath.ToSlash(filepath.Dir(path)), "/") {
+		if code := strings.ToLower(dir); len(code) == 2 && docLanguages[code] {
+			return code
+		}
+	}
+	return detectTextLanguage(docCodeBlockRe.ReplaceAllString(text, " "))
+}
+
+func detectTextLanguage(text string) string {
+	var letters, latin, han, kana, hangul, cyrillic, ukrainian, arabic, hebrew, greek int
+	for _, r := range text {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		letters++
+		switch {
+		case unicode.Is(unicode.Latin, r):
+			latin++
+		case unicode.Is(unicode.Han, r):
+			han++
+		case unicode.In(r, unicode.Hiragana, unicode.Katakana):
+			kana++
+		case unicode.Is(unicode.Hangul, r):
+			hangul++
+		case unicode.Is(unicode.Cyrillic, r):
+			cyrillic++
+			if strings.ContainsRune("ієїґІЄЇҐ", r) {
+				ukrainian++
+			}
+		case unicode.Is(unicode.Arabic, r):
+			arabic++
+		case unicode.Is(unicode.Hebrew, r):
+			hebrew++
+		case unicode.Is(unicode.Greek, r):
+			greek++
+		}
+	}
+	if letters < 20 {
+		return ""
+	}
+
+	// Docs in any language are full of Latin identifiers; a non-Latin script
+	// covering 30% of the letters is the language of the prose.
+	dominant := func(n int) bool { return n*10 >= letters*3 }
+	switch {
+	case dominant(han + kana):
+		if kana*10 >= han+kana {
+			return "ja"
+		}
+		return "zh"
+	case dominant(hangul):
+		return "ko"
+	case dominant(cyrillic):
+		if ukrainian*50 >= cyrillic {
+			return "uk"
+		}
+		return "ru"
+	case dominant(arabic):
+		return "ar"
+	case dominant(hebrew):
+		return "he"
+	case dominant(greek):
+		return "el"
+	case latin == 0:
+		return ""
+	}
+
+	counts := make(map[string]int)
+	for _, w := range docWordRe.FindAllString(strings.ToLower(text), -1) {
+		counts[w]++
+	}
+	best, bestScore := "", 0
+	for _, lang := range []string{"en", "de", "fr", "es", "it", "pt", "ro", "nl"} {
+		score := 0
+		for _, w := range docStopwords[lang] {
+			score += counts[w]
+		}
+		if score > bestScore {
+			best, bestScore = lang, score
+		}
+	}
+	return best
+}
@@ -0,0 +1,28 @@
+package ragcode
+
+import "testing"
+
+func TestDetectDocLanguage(t *testing.T) {
+	english := "This is the guide to install the server. You need Docker and the Ollama models for this to work."
+	cases := []struct {
+		path, text, want string
+	}{
+		{"README.md", english, "en"},
+		{"README.zh.md", english, "zh"},
+		{"README.zh-CN.md", "", "zh"},
+		{"README_pt-BR.md", "", "pt"},
+		{"docs/fr/install.md", english, "fr"},
+		{"docs/setup.md", "本项目提供代码的语义搜索功能，可以通过 `search_code` 工具使用。请先运行索引命令，然后在编辑器中配置服务器。", "zh"},
+		{"docs/setup.md", "このプロジェクトはコードのセマンティック検索を提供します。まずインデックスを作成してください。", "ja"},
+		{"docs/setup.md", "Этот проект предоставляет семантический поиск по коду. Сначала запустите индексацию, затем настройте сервер.", "ru"},
+		{"docs/setup.md", "Dieses Projekt ist ein Server für die Codesuche. Sie müssen die Modelle installieren und das ist nicht schwer.", "de"},
+		{"docs/setup.md", "Acest proiect este un server pentru căutare în cod și nu este greu să îl instalați cu Docker.", "ro"},
+		{"docs/setup.md", "```go\nfunc main() {}\n```", ""},
+		{"CHANGELOG.md", "v1.2", ""},
+	}
+	for _, c := range cases {
+		if got := DetectDocLanguage(c.path, c.text); got != c.want {
+			t.Errorf("DetectDocLanguage(%q, %.30q) = %q, want %q", c.path, c.text, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,173 @@
+package ragcode
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Front matter keys read when the configuration leaves them unset
+var (
+	DefaultFrontMatterTitleKeys = []string{"title"}
+	DefaultFrontMatterTagKeys   = []string{"tags", "keywords", "categories"}
+	DefaultFrontMatterFields    = []string{"owners"}
+)
+
+// FrontMatterOptions selects the front matter keys mapped to metadata. It
+// mirrors config.FrontMatterConfig, which converts to it.
+type FrontMatterOptions struct {
+	Disabled  bool
+	TitleKeys []string
+	TagKeys   []string
+	Fields    []string
+}
+
+// FrontMatter is the YAML front matter of a Markdown doc, mapped to the
+// metadata of its chunks
+type FrontMatter struct {
+	Title  string
+	Tags   []string // lower-cased, sorted
+	Fields []FrontMatterField
+	// Lines is the number of lines of the block, both delimiters included
+	Lines int
+}
+
+// FrontMatterField is a front matter value kept as metadata, e.g. owners
+type FrontMatterField struct {
+	Key   string
+	Value string
+}
+
+// FieldsText renders the fields one per line: "owners: alice, bob"
+func (fm *FrontMatter) FieldsText() string {
+	lines := make([]string, len(fm.Fields))
+	for i, f := range fm.Fields {
+		lines[i] = f.Key + ": " + f.Value
+	}
+	return strings.Join(lines, "\n")
+}
+
+// WithTags returns the sorted union of tags, e.g. those of tag rules, and
+// the front matter tags. fm may be nil.
+func (fm *FrontMatter) WithTags(tags []string) []string {
+	if fm == nil || len(fm.Tags) == 0 {
+		return tags
+	}
+	set := make(map[string]bool, len(tags)+len(fm.Tags))
+	for _, tag := range append(append([]string{}, tags...), fm.Tags...) {
+		set[tag] = true
+	}
+	return sortedKeys(set)
+}
+
+// ParseFrontMatter splits a YAML front matter block, between "---" lines at
+// the start of a Markdown doc, from its body. It returns nil and the whole
+// content when there is none, when it is not a YAML mapping, or when
+// opts.Disabled: such a block stays text.
+func ParseFrontMatter(content string, opts FrontMatterOptions) (*FrontMatter, string) {
+	if opts.Disabled {
+		return nil, content
+	}
+	text := strings.TrimPrefix(content, "\ufeff")
+	lines := strings.SplitAfter(text, "\n")
+	if len(lines) < 2 || strings.TrimRight(lines[0], "\r\n") != "---" {
+		return nil, content
+	}
+	end := -1
+	for i := 1; i < len(lines); i++ {
+		if l := strings.TrimRight(lines[i], "\r\n"); l == "---" || l == "..." {
+			end = i
+			break
+		}
+	}
+	if end < 0 {
+		return nil, content
+	}
+	var values map[string]interface{}
+	if err := yaml.Unmarshal([]byte(strings.Join(lines[1:end], "")), &values); err != nil || values == nil {
+		return nil, content
+	}
+
+	fm := &FrontMatter{Lines: end + 1}
+	for _, key := range orDefault(opts.TitleKeys, DefaultFrontMatterTitleKeys) {
+		title, ok := values[key].(string)
+		if !ok && values[key] != nil {
+			title = fmt.Sprint(values[key])
+		}
+		if title = strings.TrimSpace(title); title != "" {
+			fm.Title = title
+			break
+		}
+	}
+	seen := make(map[string]bool)
+	for _, key := range orDefault(opts.TagKeys, DefaultFrontMatterTagKeys) {
+		for _, tag := range frontMatterStrings(values[key]) {
+			if tag = strings.ToLower(tag); !seen[tag] {
+				seen[tag] = true
+				fm.Tags = append(fm.Tags, tag)
+			}
+		}
+	}
+	sort.Strings(fm.Tags)
+	for _, key := range orDefault(opts.Fields, DefaultFrontMatterFields) {
+		if value := strings.Join(frontMatterStrings(values[key]), ", "); value != "" {
+			fm.Fields = append(fm.Fields, FrontMatterField{Key: key, Value: value})
+		}
+	}
+	return fm, strings.Join(lines[end+1:], "")
+}
+
+// frontMatterStrings flattens a front matter value: a scalar, a list, or a
+// comma-separated string as some generators write tags
+func frontMatterStrings(v interface{}) []string {
+	var out []string
+	switch v := v.(type) {
+	case nil:
+	case string:
+		for _, s := range strings.Split(v, ",") {
+			if s = strings.TrimSpace(s); s != "" {
+				out = append(out, s)
+			}
+		}
+	case []interface{}:
+		for _, item := range v {
+			out = append(out, frontMatterStrings(item)...)
+		}
+	case map[string]interface{}:
+		// e.g. owners: {team: payments}
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			out = append(out, fmt.Sprintf("%s=%s", k, strings.Join(frontMatterStrings(v[k]), " ")))
+		}
+	default:
+		out = append(out, fmt.Sprint(v))
+	}
+	return out
+}
+
+func orDefault(keys, defaults []string) []string {
+	if len(keys) == 0 {
+		return defaults
+	}
+	return keys
+}
+
+// TitledBreadcrumb puts the title of a doc before the headings of one of
+// its chunks, unless the outermost heading already repeats it
+func TitledBreadcrumb(title, breadcrumb string) string {
+	switch {
+	case title == "":
+		return breadcrumb
+	case breadcrumb == "":
+		return title
+	case breadcrumb == title || strings.HasPrefix(breadcrumb, title+" > "):
+		return breadcrumb
+	}
+	return title + " > " + breadcrumb
+}
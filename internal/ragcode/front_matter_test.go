@@ -0,0 +1,65 @@
+package ragcode
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseFrontMatter(t *testing.T) {
+	content := "---\ntitle: \"Billing, invoices\"\nkeywords: Payments, PDF\ncategories:\n  - payments\nowners: {team: billing}\nstatus: draft\n---\n# Billing\n\nText.\n"
+
+	fm, body := ParseFrontMatter(content, FrontMatterOptions{})
+	if fm == nil {
+		t.Fatal("no front matter")
+	}
+	if body != "# Billing\n\nText.\n" || fm.Lines != 8 {
+		t.Errorf("body = %q after %d lines", body, fm.Lines)
+	}
+	if fm.Title != "Billing, invoices" {
+		t.Errorf("title = %q", fm.Title)
+	}
+	if want := []string{"payments", "pdf"}; !reflect.DeepEqual(fm.Tags, want) {
+		t.Errorf("tags = %v, want %v", fm.Tags, want)
+	}
+	if fm.FieldsText() != "owners: team=billing" {
+		t.Errorf("fields = %q", fm.FieldsText())
+	}
+	if got := fm.WithTags([]string{"docs", "pdf"}); !reflect.DeepEqual(got, []string{"docs", "payments", "pdf"}) {
+		t.Errorf("WithTags = %v", got)
+	}
+
+	// Configured keys replace the defaults
+	fm, _ = ParseFrontMatter(content, FrontMatterOptions{TitleKeys: []string{"name", "status"}, TagKeys: []string{"categories"}, Fields: []string{"status"}})
+	if fm.Title != "draft" || !reflect.DeepEqual(fm.Tags, []string{"payments"}) || fm.FieldsText() != "status: draft" {
+		t.Errorf("configured = %+v", fm)
+	}
+
+	for name, text := range map[string]string{
+		"none":       "# Title\n\n---\ntitle: x\n---\n",
+		"unclosed":   "---\ntitle: x\n# Title\n",
+		"not a map":  "---\n- a\n- b\n---\nText\n",
+		"bad yaml":   "---\ntitle: [x\n---\nText\n",
+		"rule first": "---\n\nText\n",
+	} {
+		if fm, body := ParseFrontMatter(text, FrontMatterOptions{}); fm != nil || body != text {
+			t.Errorf("%s: parsed %+v", name, fm)
+		}
+	}
+	if fm, body := ParseFrontMatter(content, FrontMatterOptions{Disabled: true}); fm != nil || body != content {
+		t.Error("disabled front matter parsed")
+	}
+}
+
+func TestTitledBreadcrumb(t *testing.T) {
+	tests := []struct{ title, breadcrumb, want string }{
+		{"", "Install > Docker", "Install > Docker"},
+		{"Guide", "", "Guide"},
+		{"Guide", "Guide > Install", "Guide > Install"},
+		{"Deploy guide", "Install", "Deploy guide > Install"},
+	}
+	for _, tt := range tests {
+		if got := TitledBreadcrumb(tt.title, tt.breadcrumb); got != tt.want {
+			t.Errorf("TitledBreadcrumb(%q, %q) = %q, want %q", tt.title, tt.breadcrumb, got, tt.want)
+		}
+	}
+}
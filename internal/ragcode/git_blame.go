@@ -0,0 +1,110 @@
+package ragcode
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/doITmagic/rag-code-mcp/internal/codetypes"
+)
+
+// blameTimeout bounds a single `git blame` run
+const blameTimeout = 30 * time.Second
+
+// BlameLine is the last change of a single source line.
+type BlameLine struct {
+	Commit string
+	Author string
+	Time   time.Time
+}
+
+// AnnotateGitBlame records, for every chunk, when its most recently changed
+// line was committed and by whom (see codetypes.SetLastModified). Each file is
+// blamed once; files outside a git repository are skipped.
+func AnnotateGitBlame(chunks []codetypes.CodeChunk) {
+	blamed := make(map[string]map[int]BlameLine)
+	for i := range chunks {
+		ch := &chunks[i]
+		if ch.FilePath == "" || ch.StartLine <= 0 {
+			continue
+		}
+		lines, ok := blamed[ch.FilePath]
+		if !ok {
+			lines, _ = BlameFile(ch.FilePath)
+			blamed[ch.FilePath] = lines
+		}
+
+		end := ch.EndLine
+		if end < ch.StartLine {
+			end = ch.StartLine
+		}
+		var latest BlameLine
+		for n := ch.StartLine; n <= end; n++ {
+			if l, ok := lines[n]; ok && l.Time.After(latest.Time) {
+				latest = l
+			}
+		}
+		if !latest.Time.IsZero() {
+			codetypes.SetLastModified(ch, latest.Time, latest.Author)
+		}
+	}
+}
+
+// BlameFile runs `git blame` on path and returns the last change of each
+// line, keyed by 1-based line number. Uncommitted lines are reported by git
+// with the current time.
+func BlameFile(path string) (map[int]BlameLine, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), blameTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "git", "-C", filepath.Dir(path), "blame", "--line-porcelain", "--", filepath.Base(path))
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+	return parseBlamePorcelain(out), nil
+}
+
+// parseBlamePorcelain parses `git blame --line-porcelain` output, where every
+// line starts with "<sha> <orig-line> <final-line>", followed by headers and
+// the tab-prefixed source line.
+func parseBlamePorcelain(out []byte) map[int]BlameLine {
+	lines := make(map[int]BlameLine)
+	var (
+		current BlameLine
+		final   int
+	)
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "\t"):
+			if final > 0 {
+				lines[final] = current
+			}
+			current, final = BlameLine{}, 0
+		case strings.HasPrefix(line, "author "):
+			current.Author = strings.TrimPrefix(line, "author ")
+		case strings.HasPrefix(line, "committer-time "):
+			if sec, err := strconv.ParseInt(strings.TrimPrefix(line, "committer-time "), 10, 64); err == nil {
+				current.Time = time.Unix(sec, 0)
+			}
+		default:
+			fields := strings.Fields(line)
+			// SHA-1 or SHA-256 object names
+			if len(fields) >= 3 && (len(fields[0]) == 40 || len(fields[0]) == 64) {
+				if n, err := strconv.Atoi(fields[2]); err == nil {
+					current.Commit = fields[0]
+					final = n
+				}
+			}
+		}
+	}
+	return lines
+}
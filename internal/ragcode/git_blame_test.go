@@ -0,0 +1,79 @@
+package ragcode
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/doITmagic/rag-code-mcp/internal/codetypes"
+)
+
+func TestParseBlamePorcelain(t *testing.T) {
+	out := "1111111111111111111111111111111111111111 1 1 2\n" +
+		"author Ana\n" +
+		"author-mail <ana@example.com>\n" +
+		"committer-time 1700000000\n" +
+		"summary init\n" +
+		"filename main.go\n" +
+		"\tpackage main\n" +
+		"2222222222222222222222222222222222222222 2 2\n" +
+		"author Bo\n" +
+		"committer-time 1710000000\n" +
+		"previous 1111111111111111111111111111111111111111 main.go\n" +
+		"filename main.go\n" +
+		"\tfunc main() {}\n"
+
+	lines := parseBlamePorcelain([]byte(out))
+	if len(lines) != 2 {
+		t.Fatalf("len = %d, want 2", len(lines))
+	}
+	if l := lines[1]; l.Author != "Ana" || l.Time.Unix() != 1700000000 {
+		t.Errorf("line 1 = %+v", l)
+	}
+	if l := lines[2]; l.Author != "Bo" || l.Commit[:4] != "2222" {
+		t.Errorf("line 2 = %+v", l)
+	}
+}
+
+func TestAnnotateGitBlame(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	dir := t.TempDir()
+	git := func(args ...string) {
+		cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME=Ana", "GIT_AUTHOR_EMAIL=ana@example.com",
+			"GIT_COMMITTER_NAME=Ana", "GIT_COMMITTER_EMAIL=ana@example.com",
+			"GIT_COMMITTER_DATE=2024-01-02T03:04:05Z")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	file := filepath.Join(dir, "main.go")
+	if err := os.WriteFile(file, []byte("package main\n\nfunc main() {}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	git("init", "-q")
+	git("add", "main.go")
+	git("commit", "-q", "-m", "init")
+
+	chunks := []codetypes.CodeChunk{
+		{Name: "main", FilePath: file, StartLine: 3, EndLine: 3},
+		{Name: "other", FilePath: filepath.Join(t.TempDir(), "x.go"), StartLine: 1, EndLine: 1},
+	}
+	AnnotateGitBlame(chunks)
+
+	got, ok := chunks[0].LastModified()
+	if want := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC); !ok || !got.Equal(want) {
+		t.Errorf("LastModified = %v, %v; want %v", got, ok, want)
+	}
+	if chunks[0].Metadata[codetypes.MetaLastAuthor] != "Ana" {
+		t.Errorf("author = %v", chunks[0].Metadata[codetypes.MetaLastAuthor])
+	}
+	if _, ok := chunks[1].LastModified(); ok {
+		t.Errorf("file outside git should not be annotated")
+	}
+}
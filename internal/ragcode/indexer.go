@@ -5,8 +5,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"hash/fnv"
+	"log"
 	"path/filepath"
 	"strings"
+	"sync"
 
 	"github.com/doITmagic/rag-code-mcp/internal/codetypes"
 	"github.com/doITmagic/rag-code-mcp/internal/llm"
@@ -15,15 +17,79 @@ import (
 
 // Indexer indexes CodeChunks into LongTermMemory using an embedding Provider.
 type Indexer struct {
-	analyzer codetypes.PathAnalyzer
-	embedder llm.Provider
-	ltm      memory.LongTermMemory
+	analyzer   codetypes.PathAnalyzer
+	embedder   llm.Provider
+	ltm        memory.LongTermMemory
+	onAnalyzed func([]codetypes.CodeChunk)
+	onFile     func(path string)
+	onStart    func(path string)
+	gitBlame   bool
+	pipeline   ChunkPipeline
+	boiler     *Boilerplate
+	batchSize  int
+	workers    int
+	onProgress func(done, total int)
 }
 
+// Defaults of SetEmbedConcurrency
+const (
+	DefaultEmbedBatchSize = 32
+	DefaultEmbedWorkers   = 4
+)
+
 func NewIndexer(analyzer codetypes.PathAnalyzer, embedder llm.Provider, ltm memory.LongTermMemory) *Indexer {
 	return &Indexer{analyzer: analyzer, embedder: embedder, ltm: ltm}
 }
 
+// OnAnalyzed registers a callback that receives all chunks produced by the
+// analyzer before they are embedded, e.g. to maintain a symbol table.
+func (i *Indexer) OnAnalyzed(fn func([]codetypes.CodeChunk)) {
+	i.onAnalyzed = fn
+}
+
+// OnFileIndexed registers a callback called once all chunks of a file are
+// stored, e.g. to checkpoint an indexing run that gets interrupted.
+func (i *Indexer) OnFileIndexed(fn func(path string)) {
+	i.onFile = fn
+}
+
+// OnFileStarted registers a callback called when the chunks of a file start
+// being stored, e.g. to report the file an indexing run is at.
+func (i *Indexer) OnFileStarted(fn func(path string)) {
+	i.onStart = fn
+}
+
+// SetEmbedConcurrency sets how many chunk texts are embedded per request and
+// how many requests run at once. Values <= 0 keep the defaults.
+func (i *Indexer) SetEmbedConcurrency(batchSize, workers int) {
+	i.batchSize = batchSize
+	i.workers = workers
+}
+
+// OnProgress registers a callback called as batches of chunks are stored,
+// with the chunks stored so far and the chunks to embed in this run.
+func (i *Indexer) OnProgress(fn func(done, total int)) {
+	i.onProgress = fn
+}
+
+// EnableGitBlame annotates chunks with the time and author of their most
+// recent change (see AnnotateGitBlame) before they are stored.
+func (i *Indexer) EnableGitBlame() {
+	i.gitBlame = true
+}
+
+// SetPostProcessors runs the pipeline on the analyzed chunks before they are
+// embedded and stored (see ChunkProcessor).
+func (i *Indexer) SetPostProcessors(pipeline ChunkPipeline) {
+	i.pipeline = pipeline
+}
+
+// SetBoilerplate strips license headers and repeated boilerplate from the
+// embedded text (see Boilerplate). The filter learns from every batch.
+func (i *Indexer) SetBoilerplate(b *Boilerplate) {
+	i.boiler = b
+}
+
 // IndexPaths analyzes, embeds and stores all code chunks under the given paths.
 // collection and dimension management should be handled by the caller (Qdrant client).
 func (i *Indexer) IndexPaths(ctx context.Context, paths []string, sourceTag string) (int, error) {
@@ -31,57 +97,229 @@ func (i *Indexer) IndexPaths(ctx context.Context, paths []string, sourceTag stri
 	if err != nil {
 		return 0, err
 	}
+	codetypes.NormalizeChunkLanguages(chunks)
+	AnnotateDeprecations(chunks)
+	if i.gitBlame {
+		AnnotateGitBlame(chunks)
+	}
+	if len(i.pipeline) > 0 {
+		if chunks, err = i.pipeline.Process(ctx, chunks); err != nil {
+			return 0, fmt.Errorf("chunk post-processing failed: %w", err)
+		}
+	}
+	if i.onAnalyzed != nil {
+		i.onAnalyzed(chunks)
+	}
+	if i.boiler != nil {
+		if learned := i.boiler.Learn(chunks); len(learned) > 0 {
+			log.Printf("🧹 Learned %d repeated boilerplate line(s), left out of embeddings", len(learned))
+		}
+	}
 
-	indexed := 0
+	// Chunks left to store per file; files without chunks are done
+	remaining := make(map[string]int)
+	for _, ch := range chunks {
+		remaining[ch.FilePath]++
+	}
+	fileDone := func(path string) {
+		remaining[path]--
+		if remaining[path] == 0 && i.onFile != nil {
+			i.onFile(path)
+		}
+	}
+	if i.onFile != nil {
+		for _, path := range paths {
+			if remaining[path] == 0 {
+				i.onFile(path)
+			}
+		}
+	}
+
+	var items []embedItem
 	for _, ch := range chunks {
-		text := strings.TrimSpace(strings.Join(filterNonEmpty([]string{
-			ch.Docstring,
-			ch.Signature,
-			ch.Code,
-		}), "\n\n"))
+		text := ChunkEmbeddingText(ch, i.boiler)
 		if text == "" {
+			fileDone(ch.FilePath)
 			continue
 		}
+		items = append(items, embedItem{chunk: ch, text: text})
+	}
+	return i.embedAndStore(ctx, items, sourceTag, fileDone)
+}
 
-		emb, err := i.embedder.Embed(ctx, text)
-		if err != nil {
-			return indexed, fmt.Errorf("embed failed for %s:%s: %w", ch.FilePath, ch.Name, err)
-		}
+// embedItem is a chunk with the text embedded for it
+type embedItem struct {
+	chunk codetypes.CodeChunk
+	text  string
+}
 
-		h := fnv.New64a()
-		h.Write([]byte(fmt.Sprintf("%s:%d-%d:%s", ch.FilePath, ch.StartLine, ch.EndLine, ch.Name)))
-		id := fmt.Sprintf("%d", h.Sum64())
+// embeddedBatch is the outcome of one embedding request
+type embeddedBatch struct {
+	items   []embedItem
+	vectors [][]float64
+	err     error
+}
 
-		chunkJSON, err := json.Marshal(ch)
-		if err != nil {
-			return indexed, fmt.Errorf("marshal chunk failed for %s: %w", ch.Name, err)
-		}
+// embedAndStore embeds items in batches on a pool of workers and stores the
+// documents as batches complete. Stores run one at a time; workers wait for
+// them once a batch per worker is pending, so memory stays bounded however
+// many chunks there are.
+func (i *Indexer) embedAndStore(ctx context.Context, items []embedItem, sourceTag string, fileDone func(string)) (int, error) {
+	if len(items) == 0 {
+		return 0, nil
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	batchSize, workers := i.batchSize, i.workers
+	if batchSize <= 0 {
+		batchSize = DefaultEmbedBatchSize
+	}
+	if workers <= 0 {
+		workers = DefaultEmbedWorkers
+	}
 
-		doc := memory.Document{
-			ID:        id,
-			Content:   string(chunkJSON),
-			Embedding: emb,
-			Metadata: map[string]interface{}{
-				"file":       ch.FilePath,
-				"package":    ch.Package,
-				"name":       ch.Name,
-				"type":       ch.Type,
-				"signature":  ch.Signature,
-				"start_line": ch.StartLine,
-				"end_line":   ch.EndLine,
-				"source":     sourceTag,
-				"basename":   filepath.Base(ch.FilePath),
-			},
+	jobs := make(chan []embedItem)
+	go func() {
+		defer close(jobs)
+		for start := 0; start < len(items); start += batchSize {
+			end := start + batchSize
+			if end > len(items) {
+				end = len(items)
+			}
+			select {
+			case jobs <- items[start:end]:
+			case <-ctx.Done():
+				return
+			}
 		}
+	}()
+
+	results := make(chan embeddedBatch, workers)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for batch := range jobs {
+				texts := make([]string, len(batch))
+				for k, item := range batch {
+					texts[k] = item.text
+				}
+				vectors, err := llm.EmbedBatch(ctx, i.embedder, texts)
+				select {
+				case results <- embeddedBatch{items: batch, vectors: vectors, err: err}:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
 
-		if err := i.ltm.Store(ctx, doc); err != nil {
-			return indexed, fmt.Errorf("store failed for %s: %w", id, err)
+	indexed := 0
+	current := ""
+	for res := range results {
+		if res.err != nil {
+			first := res.items[0].chunk
+			return indexed, fmt.Errorf("embed failed for %s:%s (batch of %d): %w", first.FilePath, first.Name, len(res.items), res.err)
+		}
+		for k, item := range res.items {
+			if err := ctx.Err(); err != nil {
+				return indexed, err
+			}
+			if path := item.chunk.FilePath; path != current && i.onStart != nil {
+				current = path
+				i.onStart(path)
+			}
+			doc, err := chunkDocument(item.chunk, res.vectors[k], sourceTag)
+			if err != nil {
+				return indexed, err
+			}
+			if err := i.ltm.Store(ctx, doc); err != nil {
+				return indexed, fmt.Errorf("store failed for %s: %w", doc.ID, err)
+			}
+			indexed++
+			fileDone(item.chunk.FilePath)
 		}
-		indexed++
+		if i.onProgress != nil {
+			i.onProgress(indexed, len(items))
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return indexed, err
 	}
 	return indexed, nil
 }
 
+// chunkDocument is the stored form of an embedded chunk. Chunks must carry a
+// known language, stored as the "language" metadata.
+func chunkDocument(ch codetypes.CodeChunk, emb []float64, sourceTag string) (memory.Document, error) {
+	if !ch.Language.Valid() {
+		return memory.Document{}, fmt.Errorf("chunk %s of %s has unknown language %q", ch.Name, ch.FilePath, ch.Language)
+	}
+
+	h := fnv.New64a()
+	h.Write([]byte(fmt.Sprintf("%s:%d-%d:%s", ch.FilePath, ch.StartLine, ch.EndLine, ch.Name)))
+	id := fmt.Sprintf("%d", h.Sum64())
+
+	chunkJSON, err := json.Marshal(ch)
+	if err != nil {
+		return memory.Document{}, fmt.Errorf("marshal chunk failed for %s: %w", ch.Name, err)
+	}
+
+	doc := memory.Document{
+		ID:        id,
+		Content:   string(chunkJSON),
+		Embedding: emb,
+		Metadata: map[string]interface{}{
+			"file":       ch.FilePath,
+			"package":    ch.Package,
+			"name":       ch.Name,
+			"type":       ch.Type,
+			"language":   string(ch.Language),
+			"signature":  ch.Signature,
+			"start_line": ch.StartLine,
+			"end_line":   ch.EndLine,
+			"source":     sourceTag,
+			"basename":   filepath.Base(ch.FilePath),
+		},
+	}
+	if tags := ChunkTags(ch); len(tags) > 0 {
+		doc.Metadata["tags"] = strings.Join(tags, ",")
+	}
+	if bc, _ := ch.Metadata["build_constraint"].(string); bc != "" {
+		doc.Metadata["build_constraint"] = bc
+	}
+	if IsTestChunk(ch) {
+		doc.Metadata["chunk_type"] = "test"
+	}
+	if ch.Type == "function" || ch.Type == "method" {
+		if calls := ChunkCallees(ch); len(calls) > 0 {
+			doc.Metadata["calls"] = strings.Join(calls, ",")
+		}
+	}
+	return doc, nil
+}
+
+// ChunkEmbeddingText is the text embedded for a chunk: its doc comment,
+// summaries, signature and code, without boilerplate. boiler may be nil.
+func ChunkEmbeddingText(ch codetypes.CodeChunk, boiler *Boilerplate) string {
+	summary, _ := ch.Metadata["summary"].(string)
+	// Classes too large to embed whole are described by their members
+	classSummary, _ := ch.Metadata["class_summary"].(string)
+	return strings.TrimSpace(strings.Join(filterNonEmpty([]string{
+		boiler.DocstringText(ch.Docstring),
+		summary,
+		classSummary,
+		ch.Signature,
+		boiler.EmbeddingText(string(ch.Language), ch.Code),
+	}), "\n\n"))
+}
+
 func filterNonEmpty(parts []string) []string {
 	out := make([]string, 0, len(parts))
 	for _, part := range parts {
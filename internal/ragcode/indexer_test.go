@@ -0,0 +1,146 @@
+package ragcode
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/doITmagic/rag-code-mcp/internal/codetypes"
+	"github.com/doITmagic/rag-code-mcp/internal/memory"
+)
+
+type staticAnalyzer []codetypes.CodeChunk
+
+func (s staticAnalyzer) AnalyzePaths(paths []string) ([]codetypes.CodeChunk, error) {
+	return s, nil
+}
+
+// cancellingStore cancels the indexing context after storing n documents
+type cancellingStore struct {
+	mockMemoryStore
+	n      int
+	cancel context.CancelFunc
+}
+
+func (s *cancellingStore) Store(ctx context.Context, doc memory.Document) error {
+	if err := s.mockMemoryStore.Store(ctx, doc); err != nil {
+		return err
+	}
+	if s.n--; s.n == 0 {
+		s.cancel()
+	}
+	return nil
+}
+
+func TestIndexerOnFileIndexed(t *testing.T) {
+	chunks := staticAnalyzer{
+		{Name: "A1", FilePath: "a.go", Code: "func A1() {}", Language: "go", Metadata: map[string]any{}},
+		{Name: "A2", FilePath: "a.go", Code: "func A2() {}", Language: "go", Metadata: map[string]any{}},
+		{Name: "B", FilePath: "b.go", Code: "func B() {}", Language: "go", Metadata: map[string]any{}},
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	store := &cancellingStore{mockMemoryStore: mockMemoryStore{docs: map[string]memory.Document{}}, n: 2, cancel: cancel}
+
+	var done []string
+	indexer := NewIndexer(chunks, &mockProvider{}, store)
+	indexer.OnFileIndexed(func(path string) {
+		done = append(done, path)
+	})
+
+	indexed, err := indexer.IndexPaths(ctx, []string{"a.go", "b.go", "empty.go"}, "test")
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want context.Canceled", err)
+	}
+	if indexed != 2 {
+		t.Errorf("indexed = %d, want 2", indexed)
+	}
+	sort.Strings(done)
+	if len(done) != 2 || done[0] != "a.go" || done[1] != "empty.go" {
+		t.Errorf("files done = %v, want [a.go empty.go]", done)
+	}
+}
+
+// batchProvider embeds batches and records their sizes
+type batchProvider struct {
+	mockProvider
+	mu      sync.Mutex
+	batches []int
+	fail    bool
+}
+
+func (p *batchProvider) EmbedBatch(ctx context.Context, texts []string) ([][]float64, error) {
+	p.mu.Lock()
+	p.batches = append(p.batches, len(texts))
+	p.mu.Unlock()
+	if p.fail {
+		return nil, errors.New("model unavailable")
+	}
+	out := make([][]float64, len(texts))
+	for i := range texts {
+		out[i] = []float64{float64(i)}
+	}
+	return out, nil
+}
+
+func TestIndexerEmbedBatches(t *testing.T) {
+	var chunks staticAnalyzer
+	for n := 0; n < 70; n++ {
+		chunks = append(chunks, codetypes.CodeChunk{Name: fmt.Sprintf("F%d", n), FilePath: fmt.Sprintf("f%d.go", n%7), Code: "func F() {}", Language: "go", StartLine: n + 1, Metadata: map[string]any{}})
+	}
+	provider := &batchProvider{}
+	store := &mockMemoryStore{docs: map[string]memory.Document{}}
+	indexer := NewIndexer(chunks, provider, store)
+	indexer.SetEmbedConcurrency(32, 3)
+	var progress [][2]int
+	indexer.OnProgress(func(done, total int) {
+		progress = append(progress, [2]int{done, total})
+	})
+
+	indexed, err := indexer.IndexPaths(context.Background(), nil, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if indexed != 70 || len(store.docs) != 70 {
+		t.Fatalf("indexed = %d, stored = %d, want 70", indexed, len(store.docs))
+	}
+	sort.Ints(provider.batches)
+	if fmt.Sprint(provider.batches) != "[6 32 32]" {
+		t.Errorf("batches = %v, want [6 32 32]", provider.batches)
+	}
+	if len(progress) != 3 || progress[2] != [2]int{70, 70} {
+		t.Errorf("progress = %v, want 3 reports ending at 70/70", progress)
+	}
+
+	provider = &batchProvider{fail: true}
+	_, err = NewIndexer(chunks, provider, &mockMemoryStore{docs: map[string]memory.Document{}}).IndexPaths(context.Background(), nil, "test")
+	if err == nil {
+		t.Error("a failed batch should fail the run")
+	}
+}
+
+func TestIndexerNormalizesLanguages(t *testing.T) {
+	chunks := staticAnalyzer{
+		{Name: "A", FilePath: "a.go", Code: "func A() {}", Language: "Golang", Metadata: map[string]any{}},
+		{Name: "b", FilePath: "b.py", Code: "def b(): pass", Language: " PY ", Metadata: map[string]any{}},
+	}
+	store := &mockMemoryStore{docs: map[string]memory.Document{}}
+	if _, err := NewIndexer(chunks, &mockProvider{}, store).IndexPaths(context.Background(), []string{"a.go", "b.py"}, "test"); err != nil {
+		t.Fatal(err)
+	}
+	languages := make(map[string]string)
+	for _, doc := range store.docs {
+		languages[doc.Metadata["name"].(string)] = doc.Metadata["language"].(string)
+	}
+	if languages["A"] != "go" || languages["b"] != "python" {
+		t.Errorf("stored languages = %v, want go and python", languages)
+	}
+
+	unknown := staticAnalyzer{{Name: "X", FilePath: "x.cob", Code: "DISPLAY 'X'.", Language: "cobol", Metadata: map[string]any{}}}
+	if _, err := NewIndexer(unknown, &mockProvider{}, store).IndexPaths(context.Background(), []string{"x.cob"}, "test"); err == nil {
+		t.Error("chunks of an unknown language should not be stored")
+	}
+}
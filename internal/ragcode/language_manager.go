@@ -4,30 +4,46 @@ import (
 	"strings"
 
 	"github.com/doITmagic/rag-code-mcp/internal/codetypes"
+	"github.com/doITmagic/rag-code-mcp/internal/ragcode/analyzers/cpp"
+	"github.com/doITmagic/rag-code-mcp/internal/ragcode/analyzers/csharp"
 	"github.com/doITmagic/rag-code-mcp/internal/ragcode/analyzers/golang"
 	htmlanalyzer "github.com/doITmagic/rag-code-mcp/internal/ragcode/analyzers/html"
 	"github.com/doITmagic/rag-code-mcp/internal/ragcode/analyzers/php/laravel"
+	"github.com/doITmagic/rag-code-mcp/internal/ragcode/analyzers/php/wordpress"
 	"github.com/doITmagic/rag-code-mcp/internal/ragcode/analyzers/python"
+	"github.com/doITmagic/rag-code-mcp/internal/ragcode/analyzers/rust"
 )
 
 // Language identifies a programming language family for code analysis.
-type Language string
+type Language = codetypes.Language
 
 const (
-	LanguageGo     Language = "go"
-	LanguagePHP    Language = "php"
-	LanguageHTML   Language = "html"
-	LanguagePython Language = "python"
+	LanguageGo     = codetypes.LanguageGo
+	LanguagePHP    = codetypes.LanguagePHP
+	LanguageHTML   = codetypes.LanguageHTML
+	LanguagePython = codetypes.LanguagePython
+	LanguageRust   = codetypes.LanguageRust
+	LanguageCPP    = codetypes.LanguageCPP
+	LanguageCSharp = codetypes.LanguageCSharp
 )
 
 // AnalyzerManager selects analyzers based on language or workspace project type.
-type AnalyzerManager struct{}
+type AnalyzerManager struct {
+	includeTests bool // analyze test files and test functions too
+}
 
 // NewAnalyzerManager creates a new analyzer manager.
 func NewAnalyzerManager() *AnalyzerManager {
 	return &AnalyzerManager{}
 }
 
+// NewAnalyzerManagerWithOptions creates an analyzer manager whose analyzers
+// also index test code when includeTests is set. PHP and C# analyzers index
+// test files either way.
+func NewAnalyzerManagerWithOptions(includeTests bool) *AnalyzerManager {
+	return &AnalyzerManager{includeTests: includeTests}
+}
+
 // normalizeProjectType maps a workspace/project type string to a Language value.
 // For backward compatibility, unknown/empty types default to Go for now.
 func normalizeProjectType(projectType string) Language {
@@ -39,10 +55,15 @@ func normalizeProjectType(projectType string) Language {
 		return LanguagePHP
 	case "html", "web", "static-html":
 		return LanguageHTML
-	case "python", "py", "django", "flask", "fastapi":
+	case "django", "flask", "fastapi":
 		return LanguagePython
+	case "c", "h", "hpp":
+		// C is analyzed with the C++ grammar
+		return LanguageCPP
+	case "dotnet", ".net":
+		return LanguageCSharp
 	default:
-		return Language(pt)
+		return codetypes.NormalizeLanguage(pt)
 	}
 }
 
@@ -52,14 +73,42 @@ func (m *AnalyzerManager) CodeAnalyzerForProjectType(projectType string) codetyp
 	lang := normalizeProjectType(projectType)
 	switch lang {
 	case LanguageGo:
-		return golang.NewCodeAnalyzer()
+		return golang.NewCodeAnalyzerWithOptions(m.includeTests)
 	case LanguagePHP:
-		return laravel.NewAdapter()
+		// WordPress hooks, shortcodes and templates on top of PHP and Laravel
+		return wordpress.NewAdapter(laravel.NewAdapter())
 	case LanguageHTML:
 		return htmlanalyzer.NewCodeAnalyzer()
 	case LanguagePython:
-		return python.NewCodeAnalyzer()
+		return python.NewCodeAnalyzerWithOptions(m.includeTests)
+	case LanguageRust:
+		return rust.NewCodeAnalyzerWithOptions(m.includeTests)
+	case LanguageCPP:
+		// tree-sitter needs cgo; builds without it skip C/C++
+		if !cpp.Available {
+			return nil
+		}
+		return cpp.NewCodeAnalyzer()
+	case LanguageCSharp:
+		// tree-sitter needs cgo; builds without it skip C#
+		if !csharp.Available {
+			return nil
+		}
+		return csharp.NewCodeAnalyzer()
 	default:
 		return nil
 	}
 }
+
+// NeedsCgo reports whether the analyzer of the project type is tree-sitter
+// based and missing because the binary was built without cgo.
+func NeedsCgo(projectType string) bool {
+	switch normalizeProjectType(projectType) {
+	case LanguageCPP:
+		return !cpp.Available
+	case LanguageCSharp:
+		return !csharp.Available
+	default:
+		return false
+	}
+}
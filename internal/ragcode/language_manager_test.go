@@ -2,6 +2,9 @@ package ragcode
 
 import (
 	"testing"
+
+	"github.com/doITmagic/rag-code-mcp/internal/ragcode/analyzers/cpp"
+	"github.com/doITmagic/rag-code-mcp/internal/ragcode/analyzers/csharp"
 )
 
 func TestAnalyzerManager_CodeAnalyzerForProjectType_Go(t *testing.T) {
@@ -88,6 +91,59 @@ func TestAnalyzerManager_CodeAnalyzerForProjectType_Python(t *testing.T) {
 	}
 }
 
+func TestAnalyzerManager_CodeAnalyzerForProjectType_Rust(t *testing.T) {
+	mgr := NewAnalyzerManager()
+
+	for _, projectType := range []string{"rust", "Rust", "rs"} {
+		if mgr.CodeAnalyzerForProjectType(projectType) == nil {
+			t.Errorf("Expected non-nil analyzer for project type '%s'", projectType)
+		}
+	}
+}
+
+func TestAnalyzerManager_CodeAnalyzerForProjectType_CPP(t *testing.T) {
+	mgr := NewAnalyzerManager()
+
+	for _, projectType := range []string{"cpp", "C++", "c", "cc", "hpp"} {
+		analyzer := mgr.CodeAnalyzerForProjectType(projectType)
+		if cpp.Available && analyzer == nil {
+			t.Errorf("Expected non-nil analyzer for project type '%s'", projectType)
+		}
+		if !cpp.Available && analyzer != nil {
+			t.Errorf("Expected nil analyzer for project type '%s' without cgo", projectType)
+		}
+	}
+}
+
+func TestAnalyzerManager_CodeAnalyzerForProjectType_CSharp(t *testing.T) {
+	mgr := NewAnalyzerManager()
+
+	for _, projectType := range []string{"csharp", "C#", "cs", "dotnet"} {
+		analyzer := mgr.CodeAnalyzerForProjectType(projectType)
+		if csharp.Available && analyzer == nil {
+			t.Errorf("Expected non-nil analyzer for project type '%s'", projectType)
+		}
+		if !csharp.Available && analyzer != nil {
+			t.Errorf("Expected nil analyzer for project type '%s' without cgo", projectType)
+		}
+	}
+}
+
+func TestNeedsCgo(t *testing.T) {
+	for projectType, want := range map[string]bool{
+		"csharp": !csharp.Available,
+		"dotnet": !csharp.Available,
+		"cpp":    !cpp.Available,
+		"c":      !cpp.Available,
+		"go":     false,
+		"ruby":   false,
+	} {
+		if got := NeedsCgo(projectType); got != want {
+			t.Errorf("NeedsCgo(%q) = %v, want %v", projectType, got, want)
+		}
+	}
+}
+
 func TestAnalyzerManager_CodeAnalyzerForProjectType_Unknown(t *testing.T) {
 	mgr := NewAnalyzerManager()
 
@@ -96,7 +152,7 @@ func TestAnalyzerManager_CodeAnalyzerForProjectType_Unknown(t *testing.T) {
 		projectType string
 		shouldExist bool
 	}{
-		{"rust (not implemented)", "rust", false},
+		{"ruby (not implemented)", "ruby", false},
 		{"javascript (not implemented)", "javascript", false},
 		{"java (not implemented)", "java", false},
 	}
@@ -136,7 +192,9 @@ func TestNormalizeProjectType(t *testing.T) {
 		{"django", LanguagePython},
 		{"flask", LanguagePython},
 		{"fastapi", LanguagePython},
-		{"rust", Language("rust")},
+		{"rust", LanguageRust},
+		{"rs", LanguageRust},
+		{"kotlin", Language("kotlin")},
 	}
 
 	for _, tt := range tests {
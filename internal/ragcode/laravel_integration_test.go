@@ -6,9 +6,11 @@ import (
 	"path/filepath"
 	"testing"
 
+	"github.com/doITmagic/rag-code-mcp/internal/codetypes"
 	"github.com/doITmagic/rag-code-mcp/internal/llm"
 	"github.com/doITmagic/rag-code-mcp/internal/memory"
 	"github.com/doITmagic/rag-code-mcp/internal/ragcode/analyzers/php/laravel"
+	"github.com/doITmagic/rag-code-mcp/internal/ragcode/analyzers/php/wordpress"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -88,6 +90,10 @@ func (m *mockMemoryStore) Search(ctx context.Context, query []float64, limit int
 	return nil, nil
 }
 
+func (m *mockMemoryStore) Query(ctx context.Context, opts memory.SearchOptions) ([]memory.Document, error) {
+	return nil, nil
+}
+
 func (m *mockMemoryStore) GetAll() []memory.Document {
 	docs := make([]memory.Document, 0, len(m.docs))
 	for _, doc := range m.docs {
@@ -101,6 +107,18 @@ func (m *mockMemoryStore) Clear(ctx context.Context) error {
 	return nil
 }
 
+func (m *mockMemoryStore) Count(ctx context.Context, filter memory.Filter) (uint64, error) {
+	return uint64(len(m.docs)), nil
+}
+
+func (m *mockMemoryStore) Scroll(ctx context.Context, filter memory.Filter, cursor string, limit int) ([]memory.Document, string, error) {
+	return nil, "", nil
+}
+
+func (m *mockMemoryStore) Aggregate(ctx context.Context, filter memory.Filter, groupBy string) (map[string]uint64, error) {
+	return nil, nil
+}
+
 func (m *mockMemoryStore) Delete(ctx context.Context, id string) error {
 	delete(m.docs, id)
 	return nil
@@ -132,23 +150,31 @@ func TestAnalyzerManager_WithBarouProject(t *testing.T) {
 	analyzer := mgr.CodeAnalyzerForProjectType("laravel")
 	require.NotNil(t, analyzer)
 
-	// Verify it's the Laravel adapter by checking type
-	_, ok := analyzer.(*laravel.Adapter)
+	// Verify it's the Laravel adapter (under the WordPress layer) by checking type
+	_, ok := unwrapWordPress(analyzer).(*laravel.Adapter)
 	assert.True(t, ok, "Should return Laravel adapter for 'laravel' project type")
 
 	// Test with php-laravel
 	analyzer = mgr.CodeAnalyzerForProjectType("php-laravel")
 	require.NotNil(t, analyzer)
-	_, ok = analyzer.(*laravel.Adapter)
+	_, ok = unwrapWordPress(analyzer).(*laravel.Adapter)
 	assert.True(t, ok, "Should return Laravel adapter for 'php-laravel' project type")
 
 	// Test with php
 	analyzer = mgr.CodeAnalyzerForProjectType("php")
 	require.NotNil(t, analyzer)
-	_, ok = analyzer.(*laravel.Adapter)
+	_, ok = unwrapWordPress(analyzer).(*laravel.Adapter)
 	assert.True(t, ok, "Should return Laravel adapter for 'php' project type")
 }
 
+// unwrapWordPress returns the analyzer below the WordPress layer
+func unwrapWordPress(analyzer codetypes.PathAnalyzer) codetypes.PathAnalyzer {
+	if wp, ok := analyzer.(*wordpress.Adapter); ok {
+		return wp.Next()
+	}
+	return analyzer
+}
+
 // mockProvider implements llm.Provider for testing
 type mockProvider struct{}
 
@@ -0,0 +1,194 @@
+package ragcode
+
+import (
+	"bufio"
+	"bytes"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// LogTemplate is a logging (or error construction) call site together with its
+// format string. Runtime log lines are matched back to their origin by turning
+// the format string into a pattern with wildcards for interpolated values.
+type LogTemplate struct {
+	Format   string `json:"format"`
+	Call     string `json:"call"`            // e.g. log.Printf, $this->logger->error, logging.warning
+	Level    string `json:"level,omitempty"` // error | warning | info | debug ...
+	Language string `json:"language"`
+	FilePath string `json:"file_path"`
+	Line     int    `json:"line"`
+}
+
+// MinLogLiteral is the minimum number of literal characters a format string
+// must have before it is trusted to identify a log line.
+const MinLogLiteral = 8
+
+// LogMatch is a template that matched a runtime log line.
+type LogMatch struct {
+	Template LogTemplate `json:"template"`
+	// Specificity is the number of literal (non-placeholder) characters that
+	// matched; higher means a more reliable match.
+	Specificity int `json:"specificity"`
+}
+
+var (
+	goLogCallRe  = regexp.MustCompile(`((?:[\w.]*\.)?(?:log|logger|Logger|fmt|errors|slog|zap|logrus))\.(Printf|Println|Print|Fatalf|Fatal|Panicf|Panic|Errorf|Warnf|Warningf|Infof|Debugf|Error|Warn|Info|Debug|New)\(\s*(?:ctx,\s*)?(?:"((?:[^"\\]|\\.)*)"|` + "`([^`]*)`" + `)`)
+	phpLogCallRe = regexp.MustCompile(`((?:\$this->)?\$?\w*(?:logger|log|Log)(?:\(\))?(?:->|::))(emergency|alert|critical|error|warning|notice|info|debug|log)\(\s*(?:'((?:[^'\\]|\\.)*)'|"((?:[^"\\]|\\.)*)")`)
+	phpThrowRe   = regexp.MustCompile(`throw new ([\w\\]+)\(\s*(?:'((?:[^'\\]|\\.)*)'|"((?:[^"\\]|\\.)*)")`)
+	pyLogCallRe  = regexp.MustCompile(`((?:self\.)?(?:logging|logger|log|_logger|_log|LOGGER|LOG)|(?:logging\.)?getLogger\([^)]*\))\.(debug|info|warning|warn|error|exception|critical|fatal)\(\s*[fFrR]?(?:'((?:[^'\\]|\\.)*)'|"((?:[^"\\]|\\.)*)")`)
+	pyRaiseRe    = regexp.MustCompile(`raise (\w+)\(\s*[fFrR]?(?:'((?:[^'\\]|\\.)*)'|"((?:[^"\\]|\\.)*)")`)
+
+	// %v, %-10s, %(name)s, {}, {name}, {user.id!r}, PSR-3 {placeholder} and
+	// PHP interpolation ($id, {$user->id})
+	logPlaceholderRe = regexp.MustCompile(`%%|%(?:\([^)]*\))?[-+# 0-9.*]*[a-zA-Z]|\{[^{}]*\}|\$\w+(?:->\w+)*`)
+)
+
+// ExtractLogTemplates scans source code for logging calls, error
+// constructors and throw/raise statements with literal messages. Only calls
+// whose format string starts on the same line as the call are recognised.
+func ExtractLogTemplates(path string, src []byte) []LogTemplate {
+	language := languageFromExt(filepath.Ext(path))
+	if language == "" {
+		return nil
+	}
+
+	var out []LogTemplate
+	scanner := bufio.NewScanner(bytes.NewReader(src))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := scanner.Text()
+
+		add := func(call, level, format string) {
+			if strings.TrimSpace(format) == "" {
+				return
+			}
+			out = append(out, LogTemplate{
+				Format:   format,
+				Call:     call,
+				Level:    level,
+				Language: language,
+				FilePath: path,
+				Line:     lineNo,
+			})
+		}
+
+		switch language {
+		case "go":
+			for _, m := range goLogCallRe.FindAllStringSubmatch(line, -1) {
+				add(m[1]+"."+m[2], goLogLevel(m[1], m[2]), firstNonEmpty(m[3], m[4]))
+			}
+		case "php":
+			for _, m := range phpLogCallRe.FindAllStringSubmatch(line, -1) {
+				add(m[1]+m[2], m[2], firstNonEmpty(m[3], m[4]))
+			}
+			for _, m := range phpThrowRe.FindAllStringSubmatch(line, -1) {
+				add("throw new "+m[1], "error", firstNonEmpty(m[2], m[3]))
+			}
+		case "python":
+			for _, m := range pyLogCallRe.FindAllStringSubmatch(line, -1) {
+				add(m[1]+"."+m[2], m[2], firstNonEmpty(m[3], m[4]))
+			}
+			for _, m := range pyRaiseRe.FindAllStringSubmatch(line, -1) {
+				add("raise "+m[1], "error", firstNonEmpty(m[2], m[3]))
+			}
+		}
+	}
+	return out
+}
+
+// MatchLogLine returns the templates whose format string matches line,
+// most specific first. Templates with fewer than minLiteral literal
+// characters (e.g. a bare "%v") are ignored because they match anything.
+func MatchLogLine(templates []LogTemplate, line string, minLiteral int) []LogMatch {
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return nil
+	}
+
+	var matches []LogMatch
+	for _, tpl := range templates {
+		re, literal := templatePattern(tpl.Format)
+		if re == nil || literal < minLiteral {
+			continue
+		}
+		if re.MatchString(line) {
+			matches = append(matches, LogMatch{Template: tpl, Specificity: literal})
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].Specificity > matches[j].Specificity
+	})
+	return matches
+}
+
+// templatePattern converts a format string into an unanchored regexp and
+// reports how many literal characters it contains.
+func templatePattern(format string) (*regexp.Regexp, int) {
+	format = strings.NewReplacer(`\n`, " ", `\t`, " ", `\"`, `"`, `\'`, `'`).Replace(format)
+
+	var sb strings.Builder
+	literal := 0
+	last := 0
+	for _, loc := range logPlaceholderRe.FindAllStringIndex(format, -1) {
+		text := format[last:loc[0]]
+		sb.WriteString(regexp.QuoteMeta(text))
+		literal += len(strings.TrimSpace(text))
+		if format[loc[0]:loc[1]] == "%%" {
+			sb.WriteString("%")
+		} else {
+			sb.WriteString(".*?")
+		}
+		last = loc[1]
+	}
+	text := strings.TrimRight(format[last:], " :\n")
+	sb.WriteString(regexp.QuoteMeta(text))
+	literal += len(strings.TrimSpace(text))
+
+	re, err := regexp.Compile(sb.String())
+	if err != nil {
+		return nil, 0
+	}
+	return re, literal
+}
+
+func goLogLevel(receiver, method string) string {
+	switch {
+	case strings.HasPrefix(method, "Fatal"), strings.HasPrefix(method, "Panic"):
+		return "fatal"
+	case strings.HasPrefix(method, "Error"), receiver == "errors", receiver == "fmt":
+		return "error"
+	case strings.HasPrefix(method, "Warn"):
+		return "warning"
+	case strings.HasPrefix(method, "Debug"):
+		return "debug"
+	default:
+		return "info"
+	}
+}
+
+func languageFromExt(ext string) string {
+	switch strings.ToLower(ext) {
+	case ".go":
+		return "go"
+	case ".php":
+		return "php"
+	case ".py":
+		return "python"
+	default:
+		return ""
+	}
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,85 @@
+package ragcode
+
+import "testing"
+
+func TestExtractLogTemplates_Go(t *testing.T) {
+	src := []byte(`package main
+
+func run(id int) error {
+	log.Printf("processing order %d for %s", id, name)
+	s.logger.Warnf("retrying in %v", d)
+	return fmt.Errorf("order %d not found: %w", id, err)
+}
+`)
+	got := ExtractLogTemplates("/app/main.go", src)
+	if len(got) != 3 {
+		t.Fatalf("len = %d, want 3: %+v", len(got), got)
+	}
+	if got[0].Call != "log.Printf" || got[0].Line != 4 || got[0].Level != "info" {
+		t.Errorf("unexpected first template: %+v", got[0])
+	}
+	if got[1].Call != "s.logger.Warnf" || got[1].Level != "warning" {
+		t.Errorf("unexpected second template: %+v", got[1])
+	}
+	if got[2].Level != "error" || got[2].Format != "order %d not found: %w" {
+		t.Errorf("unexpected third template: %+v", got[2])
+	}
+}
+
+func TestExtractLogTemplates_PHPAndPython(t *testing.T) {
+	php := []byte(`<?php
+$this->logger->error('Payment {id} failed for user {user}', ['id' => $id]);
+throw new RuntimeException("Invoice $id is locked");
+`)
+	got := ExtractLogTemplates("/app/Billing.php", php)
+	if len(got) != 2 || got[0].Call != "$this->logger->error" || got[1].Call != "throw new RuntimeException" {
+		t.Fatalf("unexpected php templates: %+v", got)
+	}
+
+	py := []byte(`logger = logging.getLogger(__name__)
+logger.warning("cache miss for key %s", key)
+raise ValueError(f"bad tenant {tenant_id}")
+`)
+	got = ExtractLogTemplates("/app/cache.py", py)
+	if len(got) != 2 || got[0].Level != "warning" || got[1].Call != "raise ValueError" {
+		t.Fatalf("unexpected python templates: %+v", got)
+	}
+
+	inline := []byte(`logging.getLogger(__name__).error("sync failed for %s", tenant)
+getLogger("audit").info("user {} signed in", user)
+`)
+	got = ExtractLogTemplates("/app/sync.py", inline)
+	if len(got) != 2 || got[0].Call != "logging.getLogger(__name__).error" || got[0].Level != "error" || got[1].Format != "user {} signed in" {
+		t.Fatalf("unexpected getLogger templates: %+v", got)
+	}
+}
+
+func TestMatchLogLine(t *testing.T) {
+	templates := []LogTemplate{
+		{Format: "order %d not found: %w", FilePath: "a.go", Line: 1},
+		{Format: "%v", FilePath: "b.go", Line: 2},
+		{Format: "Payment {id} failed for user {user}", FilePath: "c.php", Line: 3},
+		{Format: "cache miss for key %s", FilePath: "d.py", Line: 4},
+	}
+
+	matches := MatchLogLine(templates, "2024/01/02 10:00:00 order 42 not found: sql: no rows", 8)
+	if len(matches) != 1 || matches[0].Template.FilePath != "a.go" {
+		t.Fatalf("unexpected matches: %+v", matches)
+	}
+
+	matches = MatchLogLine(templates, "[error] Payment 17 failed for user bob@example.com", 8)
+	if len(matches) != 1 || matches[0].Template.FilePath != "c.php" {
+		t.Fatalf("unexpected matches: %+v", matches)
+	}
+
+	if matches := MatchLogLine(templates, "something unrelated", 8); len(matches) != 0 {
+		t.Errorf("expected no matches, got %+v", matches)
+	}
+}
+
+func TestMatchLogLine_PHPInterpolation(t *testing.T) {
+	templates := []LogTemplate{{Format: "Invoice $id is locked", FilePath: "Billing.php", Line: 3}}
+	if matches := MatchLogLine(templates, "RuntimeException: Invoice 981 is locked", 8); len(matches) != 1 {
+		t.Fatalf("expected interpolated PHP string to match, got %+v", matches)
+	}
+}
@@ -0,0 +1,240 @@
+package ragcode
+
+import (
+	"strings"
+
+	"github.com/doITmagic/rag-code-mcp/internal/llm"
+)
+
+// Markdown chunk sizes, in tokens as llm.EstimateTokens counts them, when
+// the configuration leaves them unset
+const (
+	DefaultMarkdownMaxTokens = 300
+	DefaultMarkdownOverlap   = 40
+)
+
+// MarkdownChunk is a piece of a markdown document: a section, or part of
+// one too long for a chunk
+type MarkdownChunk struct {
+	Text      string
+	Headings  []string // enclosing headings, outermost first
+	StartLine int      // 1-based, inclusive
+	EndLine   int
+}
+
+// Breadcrumb joins the enclosing headings, e.g. "Install > Docker"
+func (c MarkdownChunk) Breadcrumb() string {
+	return strings.Join(c.Headings, " > ")
+}
+
+// MarkdownEmbeddingText is the text embedded for a markdown chunk: the
+// chunk with its breadcrumb, so a section matches the topics of the
+// headings above it
+func MarkdownEmbeddingText(text, breadcrumb string) string {
+	if breadcrumb == "" {
+		return text
+	}
+	return breadcrumb + "\n\n" + text
+}
+
+// markdownBlock is a paragraph, list, table or code fence: the unit chunks
+// are built from
+type markdownBlock struct {
+	lines     []string
+	startLine int
+	fence     bool
+	heading   bool
+}
+
+func (b markdownBlock) text() string {
+	return strings.Join(b.lines, "\n")
+}
+
+func (b markdownBlock) tokens() int {
+	return llm.EstimateTokens(b.text())
+}
+
+// ChunkMarkdown splits a markdown document into chunks along its headings.
+// A chunk never crosses a heading and never cuts a code fence: a section
+// longer than maxTokens is split between paragraphs, each chunk repeating
+// the last paragraphs of the previous one up to overlap tokens, and a
+// fence longer than maxTokens stays whole. Zero or negative sizes take the
+// defaults.
+func ChunkMarkdown(content string, maxTokens, overlap int) []MarkdownChunk {
+	if maxTokens <= 0 {
+		maxTokens = DefaultMarkdownMaxTokens
+	}
+	if overlap < 0 || overlap >= maxTokens {
+		overlap = DefaultMarkdownOverlap
+		if overlap >= maxTokens {
+			overlap = 0
+		}
+	}
+
+	var (
+		chunks   []MarkdownChunk
+		headings [6]string // by level
+		section  []markdownBlock
+		block    *markdownBlock
+		fence    string // marker of the open fence
+		level    int    // of the heading of the section
+	)
+	breadcrumb := func() []string {
+		var out []string
+		for _, h := range headings {
+			if h != "" {
+				out = append(out, h)
+			}
+		}
+		return out
+	}
+	endBlock := func() {
+		if block != nil {
+			section = append(section, *block)
+			block = nil
+		}
+	}
+	// endSection ends the section before a heading of level next, 0 at the
+	// end of the document
+	endSection := func(next int) {
+		endBlock()
+		// A heading directly followed by a subsection lives on in its breadcrumb
+		if len(section) == 1 && section[0].heading && next > level {
+			section = nil
+		}
+		if len(section) > 0 {
+			chunks = append(chunks, splitSection(section, breadcrumb(), maxTokens, overlap)...)
+		}
+		section = nil
+	}
+
+	lines := strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n")
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if fence != "" {
+			block.lines = append(block.lines, line)
+			if strings.HasPrefix(trimmed, fence) && strings.Trim(trimmed, fence[:1]) == "" {
+				fence = ""
+				endBlock()
+			}
+			continue
+		}
+		if marker := fenceMarker(trimmed); marker != "" {
+			endBlock()
+			block = &markdownBlock{lines: []string{line}, startLine: i + 1, fence: true}
+			fence = marker
+			continue
+		}
+		if n, title := headingLevel(trimmed); n > 0 {
+			endSection(n)
+			level = n
+			headings[level-1] = title
+			for j := level; j < len(headings); j++ {
+				headings[j] = ""
+			}
+			block = &markdownBlock{lines: []string{line}, startLine: i + 1, heading: true}
+			endBlock()
+			continue
+		}
+		if trimmed == "" {
+			endBlock()
+			continue
+		}
+		if block == nil {
+			block = &markdownBlock{startLine: i + 1}
+		}
+		block.lines = append(block.lines, line)
+	}
+	endSection(0) // an unclosed fence runs to the end of the document
+	return chunks
+}
+
+// splitSection groups the blocks of one section into chunks of at most
+// maxTokens, but for single blocks longer than that
+func splitSection(blocks []markdownBlock, headings []string, maxTokens, overlap int) []MarkdownChunk {
+	// Paragraphs longer than a chunk are split between lines; fences never
+	var parts []markdownBlock
+	for _, b := range blocks {
+		if b.fence || b.tokens() <= maxTokens {
+			parts = append(parts, b)
+			continue
+		}
+		cur := markdownBlock{startLine: b.startLine}
+		for j, line := range b.lines {
+			if len(cur.lines) > 0 && llm.EstimateTokens(cur.text()+"\n"+line) > maxTokens {
+				parts = append(parts, cur)
+				cur = markdownBlock{startLine: b.startLine + j}
+			}
+			cur.lines = append(cur.lines, line)
+		}
+		parts = append(parts, cur)
+	}
+
+	var chunks []MarkdownChunk
+	var cur []markdownBlock
+	tokens := 0
+	emit := func() {
+		if len(cur) == 0 {
+			return
+		}
+		texts := make([]string, len(cur))
+		for i, b := range cur {
+			texts[i] = b.text()
+		}
+		last := cur[len(cur)-1]
+		chunks = append(chunks, MarkdownChunk{
+			Text:      strings.Join(texts, "\n\n"),
+			Headings:  headings,
+			StartLine: cur[0].startLine,
+			EndLine:   last.startLine + len(last.lines) - 1,
+		})
+	}
+	for _, b := range parts {
+		t := b.tokens()
+		if len(cur) > 0 && tokens+t > maxTokens {
+			emit()
+			// Carry the trailing blocks that fit in the overlap
+			var carried []markdownBlock
+			kept := 0
+			for j := len(cur) - 1; j >= 0; j-- {
+				bt := cur[j].tokens()
+				if kept+bt > overlap || kept+bt+t > maxTokens {
+					break
+				}
+				kept += bt
+				carried = append([]markdownBlock{cur[j]}, carried...)
+			}
+			cur, tokens = carried, kept
+		}
+		cur = append(cur, b)
+		tokens += t
+	}
+	emit()
+	return chunks
+}
+
+// fenceMarker returns the marker opening a code fence (``` or ~~~, three
+// or more), "" for other lines
+func fenceMarker(line string) string {
+	for _, c := range []string{"`", "~"} {
+		n := len(line) - len(strings.TrimLeft(line, c))
+		if n >= 3 {
+			return strings.Repeat(c, n)
+		}
+	}
+	return ""
+}
+
+// headingLevel returns the level and text of an ATX heading ("## Docker"),
+// 0 for other lines
+func headingLevel(line string) (int, string) {
+	n := len(line) - len(strings.TrimLeft(line, "#"))
+	if n == 0 || n > 6 || (len(line) > n && line[n] != ' ' && line[n] != '\t') {
+		return 0, ""
+	}
+	title := strings.TrimSpace(strings.TrimRight(strings.TrimSpace(line[n:]), "#"))
+	if title == "" {
+		return 0, ""
+	}
+	return n, title
+}
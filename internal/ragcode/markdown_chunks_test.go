@@ -0,0 +1,92 @@
+package ragcode
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestChunkMarkdownFollowsHeadings(t *testing.T) {
+	doc := `# Guide
+
+Intro paragraph.
+
+## Install
+
+### Docker
+
+Run the container:
+
+` + "```bash\ndocker run ragcode\n\n# not a heading\n```" + `
+
+## Usage
+Call search_code.`
+
+	chunks := ChunkMarkdown(doc, 0, 0)
+	want := []struct {
+		breadcrumb, prefix string
+		start, end         int
+	}{
+		{"Guide", "# Guide\n\nIntro paragraph.", 1, 3},
+		{"Guide > Install > Docker", "### Docker", 7, 15},
+		{"Guide > Usage", "## Usage\n\nCall search_code.", 17, 18},
+	}
+	if len(chunks) != len(want) {
+		t.Fatalf("got %d chunks: %+v", len(chunks), chunks)
+	}
+	for i, w := range want {
+		c := chunks[i]
+		if c.Breadcrumb() != w.breadcrumb || !strings.HasPrefix(c.Text, w.prefix) || c.StartLine != w.start || c.EndLine != w.end {
+			t.Errorf("chunk %d = %q %q lines %d-%d, want %q %q lines %d-%d", i, c.Breadcrumb(), c.Text, c.StartLine, c.EndLine, w.breadcrumb, w.prefix, w.start, w.end)
+		}
+	}
+	if !strings.Contains(chunks[1].Text, "# not a heading\n```") {
+		t.Errorf("code fence cut: %q", chunks[1].Text)
+	}
+}
+
+func TestChunkMarkdownSplitsLongSections(t *testing.T) {
+	var paragraphs []string
+	for i := 0; i < 6; i++ {
+		paragraphs = append(paragraphs, strings.Repeat(string(rune('a'+i)), 150)) // 38 tokens
+	}
+	fence := "```go\n" + strings.Repeat("x := 1\n", 40) + "```" // longer than a chunk
+	doc := "# Long\n\n" + strings.Join(paragraphs, "\n\n") + "\n\n" + fence
+
+	chunks := ChunkMarkdown(doc, 100, 40)
+	if len(chunks) < 3 {
+		t.Fatalf("got %d chunks, want the section split", len(chunks))
+	}
+	for i, c := range chunks {
+		if c.Breadcrumb() != "Long" {
+			t.Errorf("chunk %d breadcrumb = %q", i, c.Breadcrumb())
+		}
+	}
+	// Each split repeats the last paragraph of the previous chunk
+	for i := 1; i < len(chunks)-1; i++ {
+		prev := chunks[i-1].Text
+		last := prev[strings.LastIndex(prev, "\n\n")+2:]
+		if !strings.HasPrefix(chunks[i].Text, last) {
+			t.Errorf("chunk %d does not start with the overlap %q", i, last[:10])
+		}
+	}
+	if last := chunks[len(chunks)-1]; !strings.HasSuffix(last.Text, fence) {
+		t.Errorf("fence not kept whole in the last chunk: %q", last.Text)
+	}
+
+	// Paragraphs longer than a chunk are split between lines
+	long := strings.Repeat(strings.Repeat("w", 60)+"\n", 20)
+	for i, c := range ChunkMarkdown(long, 100, 0) {
+		if n := len(c.Text); n > 400 {
+			t.Errorf("chunk %d of a long paragraph has %d bytes", i, n)
+		}
+	}
+}
+
+func TestMarkdownEmbeddingText(t *testing.T) {
+	if got := MarkdownEmbeddingText("Run it.", "Install > Docker"); got != "Install > Docker\n\nRun it." {
+		t.Errorf("MarkdownEmbeddingText = %q", got)
+	}
+	if got := MarkdownEmbeddingText("Run it.", ""); got != "Run it." {
+		t.Errorf("without headings = %q", got)
+	}
+}
@@ -0,0 +1,233 @@
+package ragcode
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/doITmagic/rag-code-mcp/internal/codetypes"
+	"github.com/doITmagic/rag-code-mcp/internal/llm"
+)
+
+// ChunkProcessor enriches or rewrites code chunks after analysis, before they
+// are embedded and stored. A processor may change chunk content and metadata,
+// or drop chunks by leaving them out of the returned slice.
+type ChunkProcessor interface {
+	Name() string
+	Process(ctx context.Context, chunks []codetypes.CodeChunk) ([]codetypes.CodeChunk, error)
+}
+
+// ChunkProcessorSpec selects a processor type and its options, as written in
+// the rag_code.post_processors list of config.yaml.
+type ChunkProcessorSpec struct {
+	Type    string
+	Options map[string]interface{}
+}
+
+// ChunkProcessorFactory builds a processor from its options. provider is the
+// configured LLM, for processors that generate text.
+type ChunkProcessorFactory func(options map[string]interface{}, provider llm.Provider) (ChunkProcessor, error)
+
+var chunkProcessorFactories = map[string]ChunkProcessorFactory{
+	"redact":    newRedactProcessor,
+	"summarize": newSummarizeProcessor,
+	"link":      newLinkProcessor,
+	"metadata":  newMetadataProcessor,
+}
+
+// RegisterChunkProcessor makes a processor type available to config.yaml.
+// Call it from an init function to add organization-specific enrichment;
+// registering an existing type replaces it.
+func RegisterChunkProcessor(typ string, factory ChunkProcessorFactory) {
+	chunkProcessorFactories[typ] = factory
+}
+
+// ChunkPipeline runs processors in order, each on the output of the previous.
+type ChunkPipeline []ChunkProcessor
+
+// NewChunkPipeline builds the processors of specs, in order.
+func NewChunkPipeline(specs []ChunkProcessorSpec, provider llm.Provider) (ChunkPipeline, error) {
+	pipeline := make(ChunkPipeline, 0, len(specs))
+	for i, spec := range specs {
+		factory, ok := chunkProcessorFactories[spec.Type]
+		if !ok {
+			return nil, fmt.Errorf("post-processor %d: unknown type %q", i+1, spec.Type)
+		}
+		p, err := factory(spec.Options, provider)
+		if err != nil {
+			return nil, fmt.Errorf("post-processor %d (%s): %w", i+1, spec.Type, err)
+		}
+		pipeline = append(pipeline, p)
+	}
+	return pipeline, nil
+}
+
+// Process runs all processors. It stops at the first error so that a failed
+// step, e.g. redaction, never lets unprocessed chunks through.
+func (p ChunkPipeline) Process(ctx context.Context, chunks []codetypes.CodeChunk) ([]codetypes.CodeChunk, error) {
+	var err error
+	for _, proc := range p {
+		if chunks, err = proc.Process(ctx, chunks); err != nil {
+			return nil, fmt.Errorf("%s: %w", proc.Name(), err)
+		}
+	}
+	return chunks, nil
+}
+
+// optString, optBool, optInt and optStrings read processor options as decoded
+// from YAML, falling back to def when the option is missing.
+func optString(options map[string]interface{}, key, def string) (string, error) {
+	v, ok := options[key]
+	if !ok {
+		return def, nil
+	}
+	s, ok := v.(string)
+	if !ok {
+		return "", fmt.Errorf("option %q must be a string", key)
+	}
+	return s, nil
+}
+
+func optBool(options map[string]interface{}, key string, def bool) (bool, error) {
+	v, ok := options[key]
+	if !ok {
+		return def, nil
+	}
+	b, ok := v.(bool)
+	if !ok {
+		return false, fmt.Errorf("option %q must be true or false", key)
+	}
+	return b, nil
+}
+
+func optInt(options map[string]interface{}, key string, def int) (int, error) {
+	switch v := options[key].(type) {
+	case nil:
+		return def, nil
+	case int:
+		return v, nil
+	case float64:
+		return int(v), nil
+	default:
+		return 0, fmt.Errorf("option %q must be a number", key)
+	}
+}
+
+func optStrings(options map[string]interface{}, key string) ([]string, error) {
+	switch v := options[key].(type) {
+	case nil:
+		return nil, nil
+	case string:
+		return []string{v}, nil
+	case []string:
+		return v, nil
+	case []interface{}:
+		out := make([]string, 0, len(v))
+		for _, item := range v {
+			s, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("option %q must be a list of strings", key)
+			}
+			out = append(out, s)
+		}
+		return out, nil
+	default:
+		return nil, fmt.Errorf("option %q must be a list of strings", key)
+	}
+}
+
+// MatchGlob reports whether a slash-separated path matches a glob. "*" and
+// "?" stay within a path segment, "**" spans any number of segments.
+func MatchGlob(glob, rel string) bool {
+	var match func(g, p []string) bool
+	match = func(g, p []string) bool {
+		for len(g) > 0 {
+			if g[0] == "**" {
+				for i := 0; i <= len(p); i++ {
+					if match(g[1:], p[i:]) {
+						return true
+					}
+				}
+				return false
+			}
+			if len(p) == 0 {
+				return false
+			}
+			if ok, _ := path.Match(g[0], p[0]); !ok {
+				return false
+			}
+			g, p = g[1:], p[1:]
+		}
+		return len(p) == 0
+	}
+	return match(strings.Split(glob, "/"), strings.Split(rel, "/"))
+}
+
+// pathFilter matches chunk file paths against globs; no globs match everything.
+// Relative globs match at any directory boundary, so "internal/payment/**"
+// matches /src/app/internal/payment/api.go.
+type pathFilter []string
+
+func newPathFilter(globs []string) (pathFilter, error) {
+	for _, g := range globs {
+		if _, err := path.Match(g, ""); err != nil {
+			return nil, fmt.Errorf("invalid path glob %q: %w", g, err)
+		}
+	}
+	return pathFilter(globs), nil
+}
+
+func (f pathFilter) match(file string) bool {
+	if len(f) == 0 {
+		return true
+	}
+	file = strings.ReplaceAll(file, "\\", "/")
+	segments := strings.Split(file, "/")
+	for _, g := range f {
+		if strings.HasPrefix(g, "/") {
+			if MatchGlob(g, file) {
+				return true
+			}
+			continue
+		}
+		for i := range segments {
+			if MatchGlob(g, strings.Join(segments[i:], "/")) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// typeFilter matches chunk types; an empty filter matches every type.
+type typeFilter map[string]bool
+
+func newTypeFilter(types []string) typeFilter {
+	f := make(typeFilter, len(types))
+	for _, t := range types {
+		f[t] = true
+	}
+	return f
+}
+
+func (f typeFilter) match(typ string) bool {
+	return len(f) == 0 || f[typ]
+}
+
+func setChunkMetadata(ch *codetypes.CodeChunk, key string, value any) {
+	if ch.Metadata == nil {
+		ch.Metadata = make(map[string]any)
+	}
+	ch.Metadata[key] = value
+}
+
+func sortedKeys(set map[string]bool) []string {
+	out := make([]string, 0, len(set))
+	for k := range set {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
+}
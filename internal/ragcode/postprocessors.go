@@ -0,0 +1,278 @@
+package ragcode
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+
+	"github.com/doITmagic/rag-code-mcp/internal/codetypes"
+	"github.com/doITmagic/rag-code-mcp/internal/llm"
+)
+
+// redactRule replaces matches of re with repl (which may reference groups).
+type redactRule struct {
+	re   *regexp.Regexp
+	repl string
+}
+
+// defaultRedactRules catch common credentials. Assignments keep the variable
+// name so the code still reads naturally.
+var defaultRedactRules = []redactRule{
+	{regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----[\s\S]*?-----END [A-Z ]*PRIVATE KEY-----`), "${r}"},
+	{regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`), "${r}"},
+	{regexp.MustCompile(`\bgh[pousr]_[A-Za-z0-9]{36,}\b`), "${r}"},
+	{regexp.MustCompile(`\bxox[abprs]-[A-Za-z0-9-]{10,}`), "${r}"},
+	{regexp.MustCompile(`(?i)\b(bearer\s+)[A-Za-z0-9\-._~+/]{20,}=*`), "${1}${r}"},
+	{regexp.MustCompile(`(?i)((?:password|passwd|secret|api[_-]?key|token|access[_-]?key)\w*["']?\s*(?::=|=>|[:=])\s*)(["'])[^"'\n]{4,}["']`), "${1}${2}${r}${2}"},
+}
+
+// redactProcessor masks secrets in chunk code, signatures and doc comments
+// so they never reach the embedding model or the vector store.
+type redactProcessor struct {
+	rules []redactRule
+	paths pathFilter
+}
+
+func newRedactProcessor(options map[string]interface{}, _ llm.Provider) (ChunkProcessor, error) {
+	replacement, err := optString(options, "replacement", "[REDACTED]")
+	if err != nil {
+		return nil, err
+	}
+	useDefaults, err := optBool(options, "defaults", true)
+	if err != nil {
+		return nil, err
+	}
+	patterns, err := optStrings(options, "patterns")
+	if err != nil {
+		return nil, err
+	}
+	globs, err := optStrings(options, "paths")
+	if err != nil {
+		return nil, err
+	}
+
+	p := &redactProcessor{}
+	if p.paths, err = newPathFilter(globs); err != nil {
+		return nil, err
+	}
+	// "$" in the replacement must not be read as a group reference
+	r := strings.ReplaceAll(replacement, "$", "$$")
+	if useDefaults {
+		for _, rule := range defaultRedactRules {
+			p.rules = append(p.rules, redactRule{rule.re, strings.ReplaceAll(rule.repl, "${r}", r)})
+		}
+	}
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+		}
+		p.rules = append(p.rules, redactRule{re, r})
+	}
+	if len(p.rules) == 0 {
+		return nil, fmt.Errorf("no patterns: set patterns or keep defaults enabled")
+	}
+	return p, nil
+}
+
+func (p *redactProcessor) Name() string { return "redact" }
+
+func (p *redactProcessor) Process(ctx context.Context, chunks []codetypes.CodeChunk) ([]codetypes.CodeChunk, error) {
+	for i := range chunks {
+		ch := &chunks[i]
+		if !p.paths.match(ch.FilePath) {
+			continue
+		}
+		count := 0
+		for _, field := range []*string{&ch.Code, &ch.Signature, &ch.Docstring} {
+			for _, rule := range p.rules {
+				if n := len(rule.re.FindAllStringIndex(*field, -1)); n > 0 {
+					count += n
+					*field = rule.re.ReplaceAllString(*field, rule.repl)
+				}
+			}
+		}
+		if count > 0 {
+			setChunkMetadata(ch, "redacted", count)
+		}
+	}
+	return chunks, nil
+}
+
+// summarizeProcessor asks the LLM for a one-sentence summary of each chunk,
+// stored as the "summary" metadata and embedded with the chunk. It helps
+// undocumented code match natural-language questions.
+type summarizeProcessor struct {
+	provider         llm.Provider
+	types            typeFilter
+	paths            pathFilter
+	onlyUndocumented bool
+	maxChunks        int
+	maxCodeChars     int
+}
+
+func newSummarizeProcessor(options map[string]interface{}, provider llm.Provider) (ChunkProcessor, error) {
+	if provider == nil {
+		return nil, fmt.Errorf("an LLM provider is required")
+	}
+	p := &summarizeProcessor{provider: provider}
+	var err error
+	if p.onlyUndocumented, err = optBool(options, "only_undocumented", true); err != nil {
+		return nil, err
+	}
+	if p.maxChunks, err = optInt(options, "max_chunks", 100); err != nil {
+		return nil, err
+	}
+	if p.maxCodeChars, err = optInt(options, "max_code_chars", 2000); err != nil {
+		return nil, err
+	}
+	types, err := optStrings(options, "types")
+	if err != nil {
+		return nil, err
+	}
+	if len(types) == 0 {
+		types = []string{"function", "method"}
+	}
+	p.types = newTypeFilter(types)
+	globs, err := optStrings(options, "paths")
+	if err != nil {
+		return nil, err
+	}
+	if p.paths, err = newPathFilter(globs); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (p *summarizeProcessor) Name() string { return "summarize" }
+
+func (p *summarizeProcessor) Process(ctx context.Context, chunks []codetypes.CodeChunk) ([]codetypes.CodeChunk, error) {
+	done, failed := 0, 0
+	for i := range chunks {
+		ch := &chunks[i]
+		if p.maxChunks > 0 && done >= p.maxChunks {
+			break
+		}
+		if !p.types.match(ch.Type) || !p.paths.match(ch.FilePath) || ch.Code == "" {
+			continue
+		}
+		if p.onlyUndocumented && strings.TrimSpace(ch.Docstring) != "" {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		code := ch.Code
+		if len(code) > p.maxCodeChars {
+			code = code[:p.maxCodeChars]
+		}
+		prompt := fmt.Sprintf("Summarize in one sentence what this %s %s `%s` does. Reply with the sentence only.\n\n```%s\n%s\n```",
+			ch.Language, ch.Type, ch.Name, ch.Language, code)
+		summary, err := p.provider.Generate(ctx, prompt, llm.WithMaxTokens(80), llm.WithTemperature(0))
+		done++
+		if err != nil {
+			failed++
+			continue
+		}
+		if summary = strings.TrimSpace(summary); summary != "" {
+			setChunkMetadata(ch, "summary", summary)
+		}
+	}
+	if failed > 0 {
+		log.Printf("⚠️ summarize: %d of %d chunk(s) could not be summarized", failed, done)
+	}
+	return chunks, nil
+}
+
+var identifierRe = regexp.MustCompile(`[A-Za-z_][A-Za-z0-9_]*`)
+
+// linkProcessor records, for each chunk, the other symbols indexed in the same
+// run that its code references, as the "links" metadata.
+type linkProcessor struct {
+	maxLinks int
+}
+
+func newLinkProcessor(options map[string]interface{}, _ llm.Provider) (ChunkProcessor, error) {
+	maxLinks, err := optInt(options, "max_links", 10)
+	if err != nil {
+		return nil, err
+	}
+	return &linkProcessor{maxLinks: maxLinks}, nil
+}
+
+func (p *linkProcessor) Name() string { return "link" }
+
+func (p *linkProcessor) Process(ctx context.Context, chunks []codetypes.CodeChunk) ([]codetypes.CodeChunk, error) {
+	symbols := make(map[string]bool)
+	for _, ch := range chunks {
+		// Short names (i, id, db) would link almost everything
+		if ch.Type != "file" && len(ch.Name) >= 3 {
+			symbols[ch.Name] = true
+		}
+	}
+
+	for i := range chunks {
+		ch := &chunks[i]
+		links := make(map[string]bool)
+		for _, ident := range identifierRe.FindAllString(ch.Code, -1) {
+			if symbols[ident] && ident != ch.Name {
+				links[ident] = true
+			}
+		}
+		if len(links) == 0 {
+			continue
+		}
+		names := sortedKeys(links)
+		if p.maxLinks > 0 && len(names) > p.maxLinks {
+			names = names[:p.maxLinks]
+		}
+		setChunkMetadata(ch, "links", names)
+	}
+	return chunks, nil
+}
+
+// metadataProcessor sets fixed metadata on the chunks matching path globs and
+// chunk types, e.g. owner: payments-team for internal/payment/**.
+type metadataProcessor struct {
+	paths pathFilter
+	types typeFilter
+	set   map[string]interface{}
+}
+
+func newMetadataProcessor(options map[string]interface{}, _ llm.Provider) (ChunkProcessor, error) {
+	set, ok := options["set"].(map[string]interface{})
+	if !ok || len(set) == 0 {
+		return nil, fmt.Errorf("option \"set\" must map metadata keys to values")
+	}
+	globs, err := optStrings(options, "paths")
+	if err != nil {
+		return nil, err
+	}
+	types, err := optStrings(options, "types")
+	if err != nil {
+		return nil, err
+	}
+	p := &metadataProcessor{set: set, types: newTypeFilter(types)}
+	if p.paths, err = newPathFilter(globs); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (p *metadataProcessor) Name() string { return "metadata" }
+
+func (p *metadataProcessor) Process(ctx context.Context, chunks []codetypes.CodeChunk) ([]codetypes.CodeChunk, error) {
+	for i := range chunks {
+		ch := &chunks[i]
+		if !p.paths.match(ch.FilePath) || !p.types.match(ch.Type) {
+			continue
+		}
+		for k, v := range p.set {
+			setChunkMetadata(ch, k, v)
+		}
+	}
+	return chunks, nil
+}
@@ -57,7 +57,7 @@ func TestRagCodeIndexAndSearch(t *testing.T) {
 	t.Logf("✓ Collection '%s' created", collection)
 
 	// Create long-term memory
-	ltm := storage.NewQdrantLongTermMemory(qdrantClient)
+	ltm := storage.NewVectorStoreMemory(qdrantClient)
 
 	// Create analyzer and indexer
 	mgr := NewAnalyzerManager()
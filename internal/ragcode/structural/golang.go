@@ -0,0 +1,92 @@
+package structural
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"strings"
+)
+
+// goNodes parses a Go file into its calls, defer/go statements, composite
+// literals (new), declarations and imports.
+func goNodes(path string, src []byte) ([]Node, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, path, src, parser.SkipObjectResolution)
+	if err != nil && file == nil {
+		return nil, err
+	}
+	text := func(n ast.Node) string {
+		start, end := fset.Position(n.Pos()).Offset, fset.Position(n.End()).Offset
+		if start < 0 || end > len(src) || start > end {
+			return ""
+		}
+		return string(src[start:end])
+	}
+	node := func(kind, name string, n ast.Node) Node {
+		pos, end := fset.Position(n.Pos()), fset.Position(n.End())
+		return Node{
+			Kind:    kind,
+			Name:    name,
+			Line:    pos.Line,
+			EndLine: end.Line,
+			Column:  pos.Column,
+			Start:   pos.Offset,
+			End:     end.Offset,
+			Text:    firstLine(text(n)),
+		}
+	}
+	exprs := func(list []ast.Expr) []string {
+		out := make([]string, 0, len(list))
+		for _, e := range list {
+			out = append(out, text(e))
+		}
+		return out
+	}
+
+	var nodes []Node
+	ast.Inspect(file, func(n ast.Node) bool {
+		switch x := n.(type) {
+		case *ast.CallExpr:
+			nd := node("call", text(x.Fun), x)
+			nd.Args = exprs(x.Args)
+			nodes = append(nodes, nd)
+		case *ast.DeferStmt:
+			// The deferred call is also visited as a call
+			nd := node("defer", text(x.Call.Fun), x)
+			nd.Args = exprs(x.Call.Args)
+			nodes = append(nodes, nd)
+		case *ast.GoStmt:
+			nd := node("go", text(x.Call.Fun), x)
+			nd.Args = exprs(x.Call.Args)
+			nodes = append(nodes, nd)
+		case *ast.CompositeLit:
+			if x.Type != nil {
+				nd := node("new", text(x.Type), x)
+				nd.Args = exprs(x.Elts)
+				nodes = append(nodes, nd)
+			}
+		case *ast.FuncDecl:
+			name := x.Name.Name
+			if x.Recv != nil && len(x.Recv.List) > 0 {
+				name = "(" + text(x.Recv.List[0].Type) + ")." + name
+			}
+			nd := node("func", name, x)
+			for _, f := range x.Type.Params.List {
+				typ := text(f.Type)
+				if len(f.Names) == 0 {
+					nd.Args = append(nd.Args, typ)
+				}
+				for _, n := range f.Names {
+					nd.Args = append(nd.Args, n.Name+" "+typ)
+				}
+			}
+			nodes = append(nodes, nd)
+		case *ast.TypeSpec:
+			nodes = append(nodes, node("class", x.Name.Name, x))
+		case *ast.ImportSpec:
+			nodes = append(nodes, node("import", strings.Trim(x.Path.Value, "\"`"), x))
+		}
+		return true
+	})
+	return nodes, nil
+}
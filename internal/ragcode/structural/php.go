@@ -0,0 +1,146 @@
+package structural
+
+import (
+	"github.com/VKCOM/php-parser/pkg/ast"
+	"github.com/VKCOM/php-parser/pkg/conf"
+	"github.com/VKCOM/php-parser/pkg/errors"
+	"github.com/VKCOM/php-parser/pkg/parser"
+	"github.com/VKCOM/php-parser/pkg/version"
+	"github.com/VKCOM/php-parser/pkg/visitor"
+	"github.com/VKCOM/php-parser/pkg/visitor/traverser"
+)
+
+// phpNodes parses a PHP file into its calls, object creations, declarations,
+// use imports and catch blocks. Method calls are named "$var->method" and
+// static calls "Class::method", as written.
+func phpNodes(src []byte) ([]Node, error) {
+	root, err := parser.Parse(src, conf.Config{
+		Version:          &version.Version{Major: 8, Minor: 0},
+		ErrorHandlerFunc: func(e *errors.Error) {},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if root == nil {
+		return nil, nil
+	}
+	c := &phpCollector{src: src}
+	traverser.NewTraverser(c).Traverse(root)
+	return c.nodes, nil
+}
+
+type phpCollector struct {
+	visitor.Null
+	src   []byte
+	nodes []Node
+}
+
+func (c *phpCollector) text(v ast.Vertex) string {
+	if v == nil || v.GetPosition() == nil {
+		return ""
+	}
+	p := v.GetPosition()
+	if p.StartPos < 0 || p.EndPos > len(c.src) || p.StartPos > p.EndPos {
+		return ""
+	}
+	return string(c.src[p.StartPos:p.EndPos])
+}
+
+func (c *phpCollector) texts(list []ast.Vertex) []string {
+	out := make([]string, 0, len(list))
+	for _, v := range list {
+		out = append(out, c.text(v))
+	}
+	return out
+}
+
+func (c *phpCollector) add(kind, name string, v ast.Vertex, args []string) *Node {
+	p := v.GetPosition()
+	if p == nil {
+		return nil
+	}
+	column := 1
+	for i := p.StartPos - 1; i >= 0 && c.src[i] != '\n'; i-- {
+		column++
+	}
+	c.nodes = append(c.nodes, Node{
+		Kind:    kind,
+		Name:    name,
+		Args:    args,
+		Line:    p.StartLine,
+		EndLine: p.EndLine,
+		Column:  column,
+		Start:   p.StartPos,
+		End:     p.EndPos,
+		Text:    firstLine(c.text(v)),
+	})
+	return &c.nodes[len(c.nodes)-1]
+}
+
+func (c *phpCollector) ExprNew(n *ast.ExprNew) {
+	c.add("new", c.text(n.Class), n, c.texts(n.Args))
+}
+
+func (c *phpCollector) ExprFunctionCall(n *ast.ExprFunctionCall) {
+	c.add("call", c.text(n.Function), n, c.texts(n.Args))
+}
+
+func (c *phpCollector) ExprMethodCall(n *ast.ExprMethodCall) {
+	c.add("call", c.text(n.Var)+"->"+c.text(n.Method), n, c.texts(n.Args))
+}
+
+func (c *phpCollector) ExprNullsafeMethodCall(n *ast.ExprNullsafeMethodCall) {
+	c.add("call", c.text(n.Var)+"?->"+c.text(n.Method), n, c.texts(n.Args))
+}
+
+func (c *phpCollector) ExprStaticCall(n *ast.ExprStaticCall) {
+	c.add("call", c.text(n.Class)+"::"+c.text(n.Call), n, c.texts(n.Args))
+}
+
+func (c *phpCollector) StmtFunction(n *ast.StmtFunction) {
+	c.add("func", c.text(n.Name), n, c.texts(n.Params))
+}
+
+func (c *phpCollector) StmtClassMethod(n *ast.StmtClassMethod) {
+	c.add("func", c.text(n.Name), n, c.texts(n.Params))
+}
+
+func (c *phpCollector) StmtClass(n *ast.StmtClass) {
+	if n.Name == nil {
+		return // anonymous class, matched as new
+	}
+	var bases []string
+	if n.Extends != nil {
+		bases = append(bases, c.text(n.Extends))
+	}
+	c.add("class", c.text(n.Name), n, append(bases, c.texts(n.Implements)...))
+}
+
+func (c *phpCollector) StmtInterface(n *ast.StmtInterface) {
+	c.add("class", c.text(n.Name), n, c.texts(n.Extends))
+}
+
+func (c *phpCollector) StmtTrait(n *ast.StmtTrait) {
+	c.add("class", c.text(n.Name), n, nil)
+}
+
+func (c *phpCollector) StmtUseDeclaration(n *ast.StmtUse) {
+	c.add("import", c.text(n.Use), n, nil)
+}
+
+// StmtCatch records one node per caught type, so except:PDOException matches
+// catch (PDOException | RuntimeException $e).
+func (c *phpCollector) StmtCatch(n *ast.StmtCatch) {
+	body := ""
+	if len(n.Stmts) > 0 {
+		first, last := n.Stmts[0].GetPosition(), n.Stmts[len(n.Stmts)-1].GetPosition()
+		if first != nil && last != nil && first.StartPos <= last.EndPos && last.EndPos <= len(c.src) {
+			body = string(c.src[first.StartPos:last.EndPos])
+		}
+	}
+	for _, t := range n.Types {
+		if node := c.add("except", c.text(t), n, nil); node != nil {
+			node.Body = body
+		}
+	}
+}
@@ -0,0 +1,255 @@
+package structural
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var (
+	pyCallRe   = regexp.MustCompile(`[A-Za-z_]\w*(?:\s*\.\s*[A-Za-z_]\w*)*\s*\(`)
+	pyDefRe    = regexp.MustCompile(`^([ \t]*)(?:async[ \t]+)?def[ \t]+(\w+)[ \t]*\(`)
+	pyClassRe  = regexp.MustCompile(`^([ \t]*)class[ \t]+(\w+)[ \t]*(\()?`)
+	pyImportRe = regexp.MustCompile(`^[ \t]*import[ \t]+(.+)$`)
+	pyFromRe   = regexp.MustCompile(`^[ \t]*from[ \t]+(\S+)[ \t]+import\b`)
+	pyExceptRe = regexp.MustCompile(`^([ \t]*)except\b`)
+)
+
+// pyKeywords are followed by "(" without being calls.
+var pyKeywords = map[string]bool{
+	"if": true, "elif": true, "while": true, "for": true, "return": true, "and": true,
+	"or": true, "not": true, "in": true, "is": true, "with": true, "assert": true,
+	"yield": true, "lambda": true, "except": true, "raise": true, "del": true,
+	"await": true, "async": true, "else": true, "import": true, "from": true, "as": true,
+	"def": true, "class": true,
+}
+
+// pythonNodes scans a Python file for calls, def and class declarations,
+// imports and except handlers. Python has no parser in the Go toolchain, so
+// the scan works on the source with strings and comments blanked out, which
+// is exact for the constructs matched here.
+func pythonNodes(src []byte) []Node {
+	code, masked := pyMask(string(src))
+
+	lineStarts := []int{0}
+	for i := 0; i < len(masked); i++ {
+		if masked[i] == '\n' {
+			lineStarts = append(lineStarts, i+1)
+		}
+	}
+	lineOf := func(off int) (int, int) {
+		i := sort.Search(len(lineStarts), func(i int) bool { return lineStarts[i] > off }) - 1
+		return i + 1, off - lineStarts[i] + 1
+	}
+	maskedLines := strings.Split(masked, "\n")
+	codeLines := strings.Split(code, "\n")
+
+	// blockEnd returns the last line of the block opened at line (1-based),
+	// the last non-blank line before the indentation drops back.
+	blockEnd := func(line int, indent string) int {
+		end := line
+		for i := line; i < len(maskedLines); i++ {
+			l := maskedLines[i]
+			if strings.TrimSpace(l) == "" {
+				continue
+			}
+			if len(l)-len(strings.TrimLeft(l, " \t")) <= len(indent) {
+				break
+			}
+			end = i + 1
+		}
+		return end
+	}
+
+	var nodes []Node
+
+	// Calls, anywhere in the file
+	for _, loc := range pyCallRe.FindAllStringIndex(masked, -1) {
+		start, open := loc[0], loc[1]-1
+		name := strings.Join(strings.Fields(masked[start:open]), "")
+		first := name
+		if i := strings.IndexByte(first, '.'); i >= 0 {
+			first = first[:i]
+		}
+		if pyKeywords[first] {
+			continue
+		}
+		line, col := lineOf(start)
+		if prefix := strings.TrimRight(masked[lineStarts[line-1]:start], " \t"); strings.HasSuffix(prefix, "def") || strings.HasSuffix(prefix, "class") {
+			continue
+		}
+		closeIdx := matchingParen(masked, open)
+		if closeIdx < 0 {
+			continue
+		}
+		endLine, _ := lineOf(closeIdx)
+		nodes = append(nodes, Node{
+			Kind:    "call",
+			Name:    name,
+			Args:    splitTopLevel(code[open+1 : closeIdx]),
+			Line:    line,
+			EndLine: endLine,
+			Column:  col,
+			Start:   start,
+			End:     closeIdx + 1,
+			Text:    firstLine(code[start : closeIdx+1]),
+		})
+	}
+
+	// span returns the offsets from the indentation of line to the end of
+	// endLine (1-based)
+	span := func(line, endLine int, indent string) (int, int) {
+		return lineStarts[line-1] + len(indent), lineStarts[endLine-1] + len(maskedLines[endLine-1])
+	}
+
+	// Line-level constructs
+	for i, l := range maskedLines {
+		line := i + 1
+		text := firstLine(codeLines[i])
+		if m := pyDefRe.FindStringSubmatchIndex(l); m != nil {
+			indent, name := l[m[2]:m[3]], l[m[4]:m[5]]
+			nd := Node{Kind: "func", Name: name, Line: line, EndLine: blockEnd(line, indent), Column: len(indent) + 1, Text: text}
+			nd.Start, nd.End = span(nd.Line, nd.EndLine, indent)
+			open := lineStarts[i] + m[1] - 1
+			if closeIdx := matchingParen(masked, open); closeIdx >= 0 {
+				nd.Args = splitTopLevel(code[open+1 : closeIdx])
+			}
+			nodes = append(nodes, nd)
+			continue
+		}
+		if m := pyClassRe.FindStringSubmatchIndex(l); m != nil {
+			indent, name := l[m[2]:m[3]], l[m[4]:m[5]]
+			nd := Node{Kind: "class", Name: name, Line: line, EndLine: blockEnd(line, indent), Column: len(indent) + 1, Text: text}
+			nd.Start, nd.End = span(nd.Line, nd.EndLine, indent)
+			if m[6] >= 0 {
+				open := lineStarts[i] + m[6]
+				if closeIdx := matchingParen(masked, open); closeIdx >= 0 {
+					nd.Args = splitTopLevel(code[open+1 : closeIdx])
+				}
+			}
+			nodes = append(nodes, nd)
+			continue
+		}
+		if m := pyFromRe.FindStringSubmatch(l); m != nil {
+			start, end := span(line, line, "")
+			nodes = append(nodes, Node{Kind: "import", Name: m[1], Line: line, EndLine: line, Column: 1, Start: start, End: end, Text: text})
+			continue
+		}
+		if m := pyImportRe.FindStringSubmatch(l); m != nil {
+			start, end := span(line, line, "")
+			for _, mod := range strings.Split(m[1], ",") {
+				if f := strings.Fields(mod); len(f) > 0 {
+					nodes = append(nodes, Node{Kind: "import", Name: f[0], Line: line, EndLine: line, Column: 1, Start: start, End: end, Text: text})
+				}
+			}
+			continue
+		}
+		if m := pyExceptRe.FindStringSubmatchIndex(l); m != nil {
+			indent := l[m[2]:m[3]]
+			colon := topLevelIndex(l[m[1]:], ':')
+			if colon < 0 {
+				continue
+			}
+			colon += m[1]
+			clause := strings.TrimSpace(codeLines[i][m[1]:colon])
+			if j := strings.LastIndex(clause, " as "); j >= 0 {
+				clause = strings.TrimSpace(clause[:j])
+			}
+			clause = strings.TrimPrefix(strings.TrimSpace(clause), "*") // except* groups
+
+			body := strings.TrimSpace(codeLines[i][colon+1:])
+			end := line
+			if body == "" {
+				end = blockEnd(line, indent)
+				body = strings.Join(codeLines[line:end], "\n")
+			}
+			types := []string{clause}
+			if strings.HasPrefix(clause, "(") && strings.HasSuffix(clause, ")") {
+				types = splitTopLevel(clause[1 : len(clause)-1])
+			}
+			start, stop := span(line, end, indent)
+			for _, t := range types {
+				nodes = append(nodes, Node{Kind: "except", Name: t, Body: body, Line: line, EndLine: end, Column: len(indent) + 1, Start: start, End: stop, Text: text})
+			}
+		}
+	}
+
+	sort.SliceStable(nodes, func(a, b int) bool {
+		if nodes[a].Line != nodes[b].Line {
+			return nodes[a].Line < nodes[b].Line
+		}
+		return nodes[a].Column < nodes[b].Column
+	})
+	return nodes
+}
+
+// pyMask returns src with comments blanked (code) and with comments and the
+// contents of string literals blanked (masked). Offsets and newlines are kept.
+func pyMask(src string) (string, string) {
+	code, masked := []byte(src), []byte(src)
+	for i := 0; i < len(src); i++ {
+		c := src[i]
+		switch {
+		case c == '#':
+			for ; i < len(src) && src[i] != '\n'; i++ {
+				code[i], masked[i] = ' ', ' '
+			}
+		case c == '"' || c == '\'':
+			quote := string(c)
+			if strings.HasPrefix(src[i:], strings.Repeat(quote, 3)) {
+				quote = strings.Repeat(quote, 3)
+			}
+			j := i + len(quote)
+			for j < len(src) && !strings.HasPrefix(src[j:], quote) {
+				if src[j] == '\\' {
+					j++
+				} else if src[j] == '\n' && len(quote) == 1 {
+					break
+				}
+				j++
+			}
+			for k := i + len(quote); k < j && k < len(src); k++ {
+				if src[k] != '\n' {
+					masked[k] = ' '
+				}
+			}
+			i = j + len(quote) - 1
+		}
+	}
+	return string(code), string(masked)
+}
+
+// matchingParen returns the index of the ")" closing the "(" at open.
+func matchingParen(s string, open int) int {
+	depth := 0
+	for i := open; i < len(s); i++ {
+		switch s[i] {
+		case '(', '[', '{':
+			depth++
+		case ')', ']', '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// topLevelIndex returns the index of c in s outside brackets.
+func topLevelIndex(s string, c byte) int {
+	depth := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '(', '[', '{':
+			depth++
+		case ')', ']', '}':
+			depth--
+		case c:
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
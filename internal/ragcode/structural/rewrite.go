@@ -0,0 +1,230 @@
+package structural
+
+import (
+	"fmt"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/VKCOM/php-parser/pkg/conf"
+	"github.com/VKCOM/php-parser/pkg/errors"
+	phpparser "github.com/VKCOM/php-parser/pkg/parser"
+	"github.com/VKCOM/php-parser/pkg/version"
+)
+
+// diffContext is the number of unchanged lines shown around each hunk.
+const diffContext = 3
+
+// Rewrite is the proposed change of one file.
+type Rewrite struct {
+	Path         string `json:"path"`
+	Replacements int    `json:"replacements"`
+	Skipped      int    `json:"skipped,omitempty"`     // matches nested in a replaced match
+	Diff         string `json:"diff"`                  // unified diff
+	ParseError   string `json:"parse_error,omitempty"` // the rewritten file no longer parses
+}
+
+// Expand substitutes the metavariables bound by a match in template. A
+// metavariable the pattern did not bind is an error.
+func Expand(template string, bindings map[string]string) (string, error) {
+	var err error
+	out := metaVarRe.ReplaceAllStringFunc(template, func(name string) string {
+		v, ok := bindings[name]
+		if !ok && err == nil {
+			err = fmt.Errorf("%s is not bound by the pattern", name)
+		}
+		return v
+	})
+	return out, err
+}
+
+// Rewrites replaces every match by the expanded template and returns the
+// proposed change of each file as a unified diff, with paths relative to root.
+// Nothing is written. When matches nest, as in f(f(x)), the outer one is
+// replaced and the inner one skipped.
+func Rewrites(root string, matches []Match, template string) ([]Rewrite, error) {
+	byFile := make(map[string][]Match)
+	var paths []string
+	for _, m := range matches {
+		if _, ok := byFile[m.Path]; !ok {
+			paths = append(paths, m.Path)
+		}
+		byFile[m.Path] = append(byFile[m.Path], m)
+	}
+
+	var out []Rewrite
+	for _, path := range paths {
+		src, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		fileMatches := byFile[path]
+		sort.SliceStable(fileMatches, func(i, j int) bool {
+			if fileMatches[i].Node.Start != fileMatches[j].Node.Start {
+				return fileMatches[i].Node.Start < fileMatches[j].Node.Start
+			}
+			return fileMatches[i].Node.End > fileMatches[j].Node.End
+		})
+
+		rw := Rewrite{Path: path}
+		var edits []edit
+		end := -1
+		for _, m := range fileMatches {
+			if m.Node.Start < end {
+				rw.Skipped++
+				continue
+			}
+			if m.Node.Start < 0 || m.Node.End > len(src) || m.Node.Start > m.Node.End {
+				continue
+			}
+			text, err := Expand(template, m.Bindings)
+			if err != nil {
+				return nil, err
+			}
+			if text == string(src[m.Node.Start:m.Node.End]) {
+				continue
+			}
+			edits = append(edits, edit{start: m.Node.Start, end: m.Node.End, text: text})
+			end = m.Node.End
+		}
+		if len(edits) == 0 {
+			continue
+		}
+		rw.Replacements = len(edits)
+		newSrc := applyEdits(src, edits)
+		label := filepath.ToSlash(path)
+		if rel, err := filepath.Rel(root, path); err == nil && !strings.HasPrefix(rel, "..") {
+			label = filepath.ToSlash(rel)
+		}
+		rw.Diff = unifiedDiff(label, src, newSrc, edits)
+		if err := check(path, newSrc); err != nil {
+			rw.ParseError = err.Error()
+		}
+		out = append(out, rw)
+	}
+	return out, nil
+}
+
+type edit struct {
+	start, end int
+	text       string
+}
+
+func applyEdits(src []byte, edits []edit) []byte {
+	var sb strings.Builder
+	last := 0
+	for _, e := range edits {
+		sb.Write(src[last:e.start])
+		sb.WriteString(e.text)
+		last = e.end
+	}
+	sb.Write(src[last:])
+	return []byte(sb.String())
+}
+
+// unifiedDiff renders the changed lines of the edits with diffContext lines
+// of context. Edits are sorted and do not overlap.
+func unifiedDiff(path string, oldSrc, newSrc []byte, edits []edit) string {
+	oldLines, newLines := splitLines(oldSrc), splitLines(newSrc)
+
+	// Changed line ranges, 0-based and end-exclusive, in the old and new file
+	type change struct{ oldStart, oldEnd, newStart, newEnd int }
+	var changes []change
+	delta := 0 // bytes added before the current edit
+	for _, e := range edits {
+		c := change{
+			oldStart: strings.Count(string(oldSrc[:e.start]), "\n"),
+			oldEnd:   strings.Count(string(oldSrc[:e.end]), "\n") + 1,
+		}
+		newStart, newEnd := e.start+delta, e.start+delta+len(e.text)
+		c.newStart = strings.Count(string(newSrc[:newStart]), "\n")
+		c.newEnd = strings.Count(string(newSrc[:newEnd]), "\n") + 1
+		delta += len(e.text) - (e.end - e.start)
+		// Edits on the same or adjacent lines form one change
+		if n := len(changes); n > 0 && c.oldStart <= changes[n-1].oldEnd {
+			changes[n-1].oldEnd, changes[n-1].newEnd = c.oldEnd, c.newEnd
+			continue
+		}
+		changes = append(changes, c)
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("--- a/%s\n+++ b/%s\n", path, path))
+	for i := 0; i < len(changes); {
+		// Group changes whose context overlaps into one hunk
+		j := i
+		for j+1 < len(changes) && changes[j+1].oldStart-changes[j].oldEnd <= 2*diffContext {
+			j++
+		}
+		first, last := changes[i], changes[j]
+		oldFrom := max(0, first.oldStart-diffContext)
+		oldTo := min(len(oldLines), last.oldEnd+diffContext)
+		newFrom := first.newStart - (first.oldStart - oldFrom)
+		newTo := last.newEnd + (oldTo - last.oldEnd)
+
+		sb.WriteString(fmt.Sprintf("@@ -%d,%d +%d,%d @@\n", oldFrom+1, oldTo-oldFrom, newFrom+1, newTo-newFrom))
+		pos := oldFrom
+		for k := i; k <= j; k++ {
+			c := changes[k]
+			for ; pos < c.oldStart; pos++ {
+				writeDiffLine(&sb, " ", oldLines[pos])
+			}
+			for _, l := range oldLines[c.oldStart:c.oldEnd] {
+				writeDiffLine(&sb, "-", l)
+			}
+			for _, l := range newLines[c.newStart:min(c.newEnd, len(newLines))] {
+				writeDiffLine(&sb, "+", l)
+			}
+			pos = c.oldEnd
+		}
+		for ; pos < oldTo; pos++ {
+			writeDiffLine(&sb, " ", oldLines[pos])
+		}
+		i = j + 1
+	}
+	return sb.String()
+}
+
+func splitLines(src []byte) []string {
+	lines := strings.SplitAfter(string(src), "\n")
+	if len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+func writeDiffLine(sb *strings.Builder, prefix, line string) {
+	sb.WriteString(prefix)
+	sb.WriteString(strings.TrimSuffix(line, "\n"))
+	sb.WriteString("\n")
+}
+
+// check parses rewritten Go and PHP sources and reports the first syntax
+// error. Python sources are not checked.
+func check(path string, src []byte) error {
+	switch {
+	case strings.HasSuffix(path, ".go"):
+		_, err := parser.ParseFile(token.NewFileSet(), path, src, parser.SkipObjectResolution)
+		return err
+	case strings.HasSuffix(path, ".php"):
+		var first *errors.Error
+		_, err := phpparser.Parse(src, conf.Config{
+			Version: &version.Version{Major: 8, Minor: 0},
+			ErrorHandlerFunc: func(e *errors.Error) {
+				if first == nil {
+					first = e
+				}
+			},
+		})
+		if err != nil {
+			return err
+		}
+		if first != nil {
+			return fmt.Errorf("%s", first.String())
+		}
+	}
+	return nil
+}
@@ -0,0 +1,383 @@
+// Package structural matches simple structural patterns (calls, object
+// creation, declarations, imports, exception handlers) against parsed source
+// files, for precise matches semantic search cannot express.
+//
+// Patterns have the form kind:target(args):
+//
+//	call:db.Query(*)          Go call of db.Query with any arguments
+//	call:*.Exec($Q, *)        any Exec method, binding the first argument to $Q
+//	new PDO(*)                PHP object creation ("new" needs no kind prefix)
+//	func:(*Server).Handle*    Go method declarations
+//	except:$X: pass           Python handler that only passes
+//	catch:*: _                PHP catch with an empty body
+//
+// In targets and arguments "*" matches any text and "$NAME" one expression;
+// a variable used twice must match the same text. A lone "*" argument matches
+// any number of arguments, "_" exactly one.
+package structural
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Languages are the languages patterns can be evaluated on.
+var Languages = []string{"go", "php", "python"}
+
+// Node is a structural element of a source file.
+type Node struct {
+	Kind    string   // call, new, func, class, import, except, defer, go
+	Name    string   // callee, declared or imported name, exception type
+	Args    []string // source of the arguments, parameters or base classes
+	Body    string   // source of the handler body (except)
+	Line    int
+	EndLine int
+	Column  int
+	Start   int    // byte offset of the node source
+	End     int    // byte offset just past the node source
+	Text    string // source of the node, first line
+}
+
+// kindAliases maps pattern kinds to node kinds.
+var kindAliases = map[string]string{
+	"call": "call", "new": "new", "defer": "defer", "go": "go",
+	"func": "func", "def": "func", "function": "func", "method": "func",
+	"class": "class", "type": "class", "struct": "class", "interface": "class", "trait": "class",
+	"import": "import", "use": "import", "from": "import",
+	"except": "except", "catch": "except",
+}
+
+// Pattern is a parsed structural pattern.
+type Pattern struct {
+	Kind   string
+	target *glob
+	args   []*glob // nil: any arguments
+	body   *glob   // nil: any body
+}
+
+// Parse parses a pattern. A pattern without a kind prefix is a call, or an
+// object creation when it starts with "new ".
+func Parse(pattern string) (*Pattern, error) {
+	pattern = strings.TrimSpace(pattern)
+	if pattern == "" {
+		return nil, fmt.Errorf("empty pattern")
+	}
+	kind, rest := "call", pattern
+	if strings.HasPrefix(pattern, "new ") {
+		kind, rest = "new", strings.TrimSpace(pattern[4:])
+	} else if i := strings.Index(pattern, ":"); i > 0 && kindAliases[strings.ToLower(pattern[:i])] != "" {
+		kind, rest = kindAliases[strings.ToLower(pattern[:i])], strings.TrimSpace(pattern[i+1:])
+	}
+	p := &Pattern{Kind: kind}
+
+	if kind == "except" {
+		// except:TYPE: BODY
+		target := rest
+		if i := strings.Index(rest, ":"); i >= 0 {
+			target = strings.TrimSpace(rest[:i])
+			body := normalize(rest[i+1:])
+			if body == "_" {
+				body = ""
+			}
+			p.body = compileGlob(body, true)
+		}
+		p.target = compileGlob(target, true)
+		return p, nil
+	}
+
+	target := rest
+	if open := strings.Index(rest, "("); open >= 0 {
+		// A leading "(" is a Go receiver and belongs to the target,
+		// e.g. func:(*Server).Handle(*)
+		if open == 0 {
+			if next := strings.Index(rest[1:], "("); next >= 0 {
+				open = next + 1
+			} else {
+				open = -1
+			}
+		}
+		if open > 0 {
+			if !strings.HasSuffix(rest, ")") {
+				return nil, fmt.Errorf("unbalanced parentheses in %q", pattern)
+			}
+			target = rest[:open]
+			inner := strings.TrimSpace(rest[open+1 : len(rest)-1])
+			if inner != "*" {
+				p.args = []*glob{}
+				for _, a := range splitTopLevel(inner) {
+					p.args = append(p.args, compileGlob(a, false))
+				}
+			}
+		}
+	}
+	p.target = compileGlob(strings.ReplaceAll(target, " ", ""), true)
+	return p, nil
+}
+
+// Match reports whether n matches the pattern and returns the bound variables.
+func (p *Pattern) Match(n Node) (map[string]string, bool) {
+	if n.Kind != p.Kind {
+		return nil, false
+	}
+	bindings := make(map[string]string)
+	if !p.target.match(strings.ReplaceAll(n.Name, " ", ""), bindings) {
+		return nil, false
+	}
+	if p.args != nil && !matchArgs(p.args, n.Args, bindings) {
+		return nil, false
+	}
+	if p.body != nil && !p.body.match(normalize(n.Body), bindings) {
+		return nil, false
+	}
+	return bindings, true
+}
+
+// Vars returns the metavariables of the pattern, mapped to themselves, for
+// validating templates before searching.
+func (p *Pattern) Vars() map[string]string {
+	vars := make(map[string]string)
+	for _, g := range append(append([]*glob{p.target}, p.args...), p.body) {
+		if g == nil {
+			continue
+		}
+		for _, name := range g.vars {
+			vars[name] = name
+		}
+	}
+	return vars
+}
+
+func matchArgs(pattern []*glob, args []string, bindings map[string]string) bool {
+	if len(pattern) == 0 {
+		return len(args) == 0
+	}
+	if pattern[0].anySequence {
+		for i := 0; i <= len(args); i++ {
+			if matchArgs(pattern[1:], args[i:], copyBindings(bindings)) {
+				return matchArgs(pattern[1:], args[i:], bindings)
+			}
+		}
+		return false
+	}
+	if len(args) == 0 {
+		return false
+	}
+	trial := copyBindings(bindings)
+	if !pattern[0].match(normalize(args[0]), trial) || !matchArgs(pattern[1:], args[1:], trial) {
+		return false
+	}
+	for k, v := range trial {
+		bindings[k] = v
+	}
+	return true
+}
+
+func copyBindings(b map[string]string) map[string]string {
+	out := make(map[string]string, len(b))
+	for k, v := range b {
+		out[k] = v
+	}
+	return out
+}
+
+// glob is a compiled target, argument or body pattern.
+type glob struct {
+	re          *regexp.Regexp
+	vars        []string // metavariable of each capture group
+	anySequence bool     // lone "*" argument
+}
+
+var metaVarRe = regexp.MustCompile(`\$[A-Z][A-Z0-9_]*`)
+
+// compileGlob compiles "*" to any text and "$X" to a captured expression. In
+// targets a metavariable does not span spaces and may be empty, so that
+// except:$X also matches a bare except. A lone "_" argument is any single
+// argument.
+func compileGlob(s string, target bool) *glob {
+	s = normalize(s)
+	if !target && s == "*" {
+		return &glob{anySequence: true}
+	}
+	if !target && s == "_" {
+		return &glob{re: regexp.MustCompile(`^.+$`)}
+	}
+	g := &glob{}
+	var sb strings.Builder
+	sb.WriteString("^")
+	for len(s) > 0 {
+		switch {
+		case s[0] == '*':
+			sb.WriteString(".*")
+			s = s[1:]
+		case s[0] == '$' && metaVarRe.FindStringIndex(s) != nil && metaVarRe.FindStringIndex(s)[0] == 0:
+			name := metaVarRe.FindString(s)
+			g.vars = append(g.vars, name)
+			if target {
+				sb.WriteString(`(\S*)`)
+			} else {
+				sb.WriteString(`(.+)`)
+			}
+			s = s[len(name):]
+		default:
+			sb.WriteString(regexp.QuoteMeta(s[:1]))
+			s = s[1:]
+		}
+	}
+	sb.WriteString("$")
+	g.re = regexp.MustCompile(sb.String())
+	return g
+}
+
+func (g *glob) match(s string, bindings map[string]string) bool {
+	if g.anySequence {
+		return true
+	}
+	m := g.re.FindStringSubmatch(s)
+	if m == nil {
+		return false
+	}
+	for i, name := range g.vars {
+		if prev, ok := bindings[name]; ok && prev != m[i+1] {
+			return false
+		}
+		bindings[name] = m[i+1]
+	}
+	return true
+}
+
+var spaceRe = regexp.MustCompile(`\s+`)
+
+// normalize collapses whitespace so formatting does not affect matching.
+func normalize(s string) string {
+	return strings.TrimSpace(spaceRe.ReplaceAllString(s, " "))
+}
+
+// splitTopLevel splits s at commas outside brackets and string literals.
+func splitTopLevel(s string) []string {
+	var out []string
+	depth, start := 0, 0
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'' || c == '`':
+			quote = c
+		case c == '(' || c == '[' || c == '{':
+			depth++
+		case c == ')' || c == ']' || c == '}':
+			depth--
+		case c == ',' && depth == 0:
+			out = append(out, strings.TrimSpace(s[start:i]))
+			start = i + 1
+		}
+	}
+	if last := strings.TrimSpace(s[start:]); last != "" || len(out) > 0 {
+		out = append(out, last)
+	}
+	return out
+}
+
+// Match is a node of a file matching a pattern.
+type Match struct {
+	Path     string
+	Node     Node
+	Bindings map[string]string
+}
+
+// Cache keeps the nodes of parsed files until the file changes, so repeated
+// searches do not parse the workspace again.
+type Cache struct {
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+}
+
+type cacheEntry struct {
+	modTime time.Time
+	size    int64
+	nodes   []Node
+}
+
+// NewCache creates an empty cache
+func NewCache() *Cache {
+	return &Cache{entries: make(map[string]cacheEntry)}
+}
+
+// Nodes returns the nodes of a file, parsing it when it is not cached or has
+// changed. Files of unsupported languages have no nodes.
+func (c *Cache) Nodes(path string) ([]Node, error) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	e, ok := c.entries[path]
+	c.mu.Unlock()
+	if ok && e.modTime.Equal(fi.ModTime()) && e.size == fi.Size() {
+		return e.nodes, nil
+	}
+
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var nodes []Node
+	switch {
+	case strings.HasSuffix(path, ".go"):
+		nodes, err = goNodes(path, src)
+	case strings.HasSuffix(path, ".php"):
+		nodes, err = phpNodes(src)
+	case strings.HasSuffix(path, ".py"):
+		nodes = pythonNodes(src)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	c.entries[path] = cacheEntry{modTime: fi.ModTime(), size: fi.Size(), nodes: nodes}
+	c.mu.Unlock()
+	return nodes, nil
+}
+
+// Search matches the pattern against files, in order, up to limit matches.
+// Files that fail to parse are skipped. It reports whether limit was reached.
+func (c *Cache) Search(files []string, p *Pattern, limit int) ([]Match, bool) {
+	var out []Match
+	for _, path := range files {
+		nodes, err := c.Nodes(path)
+		if err != nil {
+			continue
+		}
+		for _, n := range nodes {
+			if bindings, ok := p.Match(n); ok {
+				out = append(out, Match{Path: path, Node: n, Bindings: bindings})
+				if limit > 0 && len(out) >= limit {
+					return out, true
+				}
+			}
+		}
+	}
+	return out, false
+}
+
+// firstLine returns the first line of s, shortened to 200 bytes.
+func firstLine(s string) string {
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		s = s[:i]
+	}
+	s = strings.TrimSpace(s)
+	if len(s) > 200 {
+		s = s[:200] + "..."
+	}
+	return s
+}
@@ -0,0 +1,252 @@
+package structural
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func search(t *testing.T, name, src, pattern string) []Match {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(src), 0644); err != nil {
+		t.Fatal(err)
+	}
+	p, err := Parse(pattern)
+	if err != nil {
+		t.Fatalf("Parse(%q): %v", pattern, err)
+	}
+	matches, _ := NewCache().Search([]string{path}, p, 0)
+	return matches
+}
+
+func lines(matches []Match) []int {
+	var out []int
+	for _, m := range matches {
+		out = append(out, m.Node.Line)
+	}
+	return out
+}
+
+func equalInts(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+const goSrc = `package store
+
+import "database/sql"
+
+type Store struct{ db *sql.DB }
+
+func (s *Store) Get(id int) error {
+	rows, err := s.db.Query("SELECT * FROM t WHERE id = ?", id)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	s.db.Exec("DELETE", id, id)
+	s.db.Exec("UPDATE", id, 1)
+	return nil
+}
+`
+
+func TestGoPatterns(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    []int
+	}{
+		{"call:s.db.Query(*)", []int{8}},
+		{"*.Query(*)", []int{8}},
+		{"call:s.db.Query(_)", nil},
+		{"call:s.db.Query(_, id)", []int{8}},
+		{"call:*.Exec($Q, $X, $X)", []int{13}},
+		{"call:*.Exec(*, 1)", []int{14}},
+		{"defer:*.Close()", []int{12}},
+		{"func:(*Store).Get", []int{7}},
+		{"type:Store", []int{5}},
+		{"import:database/*", []int{3}},
+	}
+	for _, tt := range tests {
+		if got := lines(search(t, "store.go", goSrc, tt.pattern)); !equalInts(got, tt.want) {
+			t.Errorf("%s: lines %v, want %v", tt.pattern, got, tt.want)
+		}
+	}
+}
+
+func TestGoBindings(t *testing.T) {
+	m := search(t, "store.go", goSrc, "call:*.Exec($Q, *)")
+	if len(m) != 2 || m[0].Bindings["$Q"] != `"DELETE"` {
+		t.Fatalf("unexpected matches %+v", m)
+	}
+}
+
+const phpSrc = `<?php
+use App\Models\User;
+
+class Repo extends Base {
+    public function find($id) {
+        $pdo = new PDO('sqlite::memory:');
+        try {
+            return $this->db->query("SELECT 1", $id);
+        } catch (PDOException $e) {
+        }
+        User::where('id', $id);
+    }
+}
+`
+
+func TestPHPPatterns(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    []int
+	}{
+		{"new PDO(*)", []int{6}},
+		{"call:$this->db->query(*)", []int{8}},
+		{"User::where(_, $ID)", []int{11}},
+		{"catch:PDOException: _", []int{9}},
+		{"class:Repo", []int{4}},
+		{"method:find", []int{5}},
+		{`use:App\Models\*`, []int{2}},
+	}
+	for _, tt := range tests {
+		if got := lines(search(t, "repo.php", phpSrc, tt.pattern)); !equalInts(got, tt.want) {
+			t.Errorf("%s: lines %v, want %v", tt.pattern, got, tt.want)
+		}
+	}
+}
+
+const pySrc = `import os, json
+from db import connect
+
+class Loader(Base):
+    def load(self, path):
+        try:
+            data = json.loads(open(path).read())  # parse(
+        except ValueError as e:
+            pass
+        except (KeyError, TypeError):
+            log.warning("bad key: call(x)")
+        try:
+            os.remove(path)
+        except: pass
+`
+
+func TestPythonPatterns(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    []int
+	}{
+		{"except:$X: pass", []int{8, 14}},
+		{"except:KeyError", []int{10}},
+		{"except:*: log.*(*)", []int{10, 10}},
+		{"json.loads(*)", []int{7}},
+		{"call:open(path)", []int{7}},
+		{"call:parse(*)", nil},
+		{"call:call(*)", nil},
+		{"def:load(self, *)", []int{5}},
+		{"class:Loader(Base)", []int{4}},
+		{"import:json", []int{1}},
+		{"from:db", []int{2}},
+	}
+	for _, tt := range tests {
+		if got := lines(search(t, "loader.py", pySrc, tt.pattern)); !equalInts(got, tt.want) {
+			t.Errorf("%s: lines %v, want %v", tt.pattern, got, tt.want)
+		}
+	}
+}
+
+func TestCacheReparsesChangedFiles(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "a.go")
+	os.WriteFile(path, []byte("package a\n\nfunc A() { f() }\n"), 0644)
+	c := NewCache()
+	p, _ := Parse("call:f()")
+	if m, _ := c.Search([]string{path}, p, 0); len(m) != 1 {
+		t.Fatalf("expected 1 match, got %d", len(m))
+	}
+	os.WriteFile(path, []byte("package a\n\nfunc A() { g(); g() }\n"), 0644)
+	p, _ = Parse("call:g()")
+	if m, _ := c.Search([]string{path}, p, 0); len(m) != 2 {
+		t.Fatalf("expected the changed file to be parsed again, got %d matches", len(m))
+	}
+}
+
+func TestRewrites(t *testing.T) {
+	root := t.TempDir()
+	path := filepath.Join(root, "store.go")
+	if err := os.WriteFile(path, []byte(goSrc), 0644); err != nil {
+		t.Fatal(err)
+	}
+	p, _ := Parse("call:s.db.Exec($Q, *)")
+	matches, _ := NewCache().Search([]string{path}, p, 0)
+
+	rewrites, err := Rewrites(root, matches, "s.db.ExecContext(ctx, $Q)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rewrites) != 1 || rewrites[0].Replacements != 2 || rewrites[0].ParseError != "" {
+		t.Fatalf("rewrites = %+v", rewrites)
+	}
+	want := `--- a/store.go
++++ b/store.go
+@@ -10,7 +10,7 @@
+ 		return err
+ 	}
+ 	defer rows.Close()
+-	s.db.Exec("DELETE", id, id)
+-	s.db.Exec("UPDATE", id, 1)
++	s.db.ExecContext(ctx, "DELETE")
++	s.db.ExecContext(ctx, "UPDATE")
+ 	return nil
+ }
+`
+	if rewrites[0].Diff != want {
+		t.Errorf("diff:\n%s\nwant:\n%s", rewrites[0].Diff, want)
+	}
+	if src, _ := os.ReadFile(path); string(src) != goSrc {
+		t.Error("Rewrites must not modify the file")
+	}
+
+	if _, err := Rewrites(root, matches, "f($Y)"); err == nil {
+		t.Error("expected an error for an unbound metavariable")
+	}
+	rewrites, _ = Rewrites(root, matches, "s.db.Exec(")
+	if len(rewrites) != 1 || rewrites[0].ParseError == "" {
+		t.Errorf("expected a parse error for a broken rewrite, got %+v", rewrites)
+	}
+}
+
+func TestRewritesSkipNestedMatches(t *testing.T) {
+	root := t.TempDir()
+	path := filepath.Join(root, "a.py")
+	os.WriteFile(path, []byte("x = wrap(wrap(1))\n"), 0644)
+	p, _ := Parse("wrap($X)")
+	matches, _ := NewCache().Search([]string{path}, p, 0)
+	rewrites, err := Rewrites(root, matches, "unwrap($X)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rewrites) != 1 || rewrites[0].Replacements != 1 || rewrites[0].Skipped != 1 ||
+		!strings.Contains(rewrites[0].Diff, "+x = unwrap(wrap(1))") {
+		t.Errorf("rewrites = %+v", rewrites)
+	}
+}
+
+func TestPatternVars(t *testing.T) {
+	p, _ := Parse("call:$F($A, *, $A)")
+	vars := p.Vars()
+	if len(vars) != 2 || vars["$F"] == "" || vars["$A"] == "" {
+		t.Errorf("vars = %v", vars)
+	}
+	if _, err := Expand("$F($B)", vars); err == nil {
+		t.Error("expected an error for $B")
+	}
+}
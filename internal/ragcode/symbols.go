@@ -0,0 +1,304 @@
+package ragcode
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/doITmagic/rag-code-mcp/internal/codetypes"
+)
+
+// SymbolEntry is the lightweight, persisted form of an indexed symbol. The
+// per-workspace symbol table built from these entries is the basis for
+// graph queries (callers, dependencies) that vector search cannot answer.
+type SymbolEntry struct {
+	Name            string   `json:"name"`
+	Kind            string   `json:"kind"`
+	Language        string   `json:"language"`
+	Package         string   `json:"package,omitempty"`
+	Receiver        string   `json:"receiver,omitempty"`
+	Signature       string   `json:"signature,omitempty"`
+	FilePath        string   `json:"file_path"`
+	StartLine       int      `json:"start_line"`
+	EndLine         int      `json:"end_line"`
+	Exported        bool     `json:"exported"`
+	Deprecated      bool     `json:"deprecated,omitempty"`
+	DeprecationNote string   `json:"deprecation_note,omitempty"`
+	Calls           []string `json:"calls,omitempty"` // names of functions/methods called from the body
+	// CallSites are the calls resolved by the analyzer, with their receiver
+	// and line; empty for languages where Calls is matched by name only
+	CallSites []codetypes.CallSite `json:"call_sites,omitempty"`
+	Test      bool                 `json:"test,omitempty"` // test code: test files and test functions
+	// Imported is the artifact format (ctags, lsif, scip) the entry was
+	// imported from, until indexing analyses its file
+	Imported string `json:"imported,omitempty"`
+}
+
+// identifier immediately followed by "(", e.g. foo(, obj.foo(, $x->foo(, Foo::bar(
+var callSiteRe = regexp.MustCompile(`([A-Za-z_][A-Za-z0-9_]*)\s*\(`)
+
+var callKeywords = map[string]struct{}{
+	"if": {}, "for": {}, "while": {}, "switch": {}, "return": {}, "func": {},
+	"function": {}, "fn": {}, "foreach": {}, "elif": {}, "catch": {}, "def": {},
+	"class": {}, "array": {}, "isset": {}, "empty": {}, "unset": {}, "list": {},
+	"print": {}, "echo": {}, "new": {}, "not": {}, "and": {}, "or": {}, "in": {},
+	"make": {}, "len": {}, "append": {}, "cap": {}, "panic": {}, "recover": {},
+	"string": {}, "int": {}, "bool": {}, "float64": {}, "byte": {}, "rune": {},
+	"super": {}, "self": {}, "str": {}, "dict": {}, "set": {}, "tuple": {}, "range": {},
+}
+
+// SymbolEntriesFromChunks converts analyzer output into symbol table entries.
+// Markdown sections and file-level chunks are skipped.
+func SymbolEntriesFromChunks(chunks []codetypes.CodeChunk) []SymbolEntry {
+	out := make([]SymbolEntry, 0, len(chunks))
+	for _, ch := range chunks {
+		if ch.Name == "" || ch.Type == "file" || ch.Type == "markdown" {
+			continue
+		}
+		entry := SymbolEntry{
+			Name:      ch.Name,
+			Kind:      ch.Type,
+			Language:  string(ch.Language),
+			Package:   ch.Package,
+			Signature: ch.Signature,
+			FilePath:  ch.FilePath,
+			StartLine: ch.StartLine,
+			EndLine:   ch.EndLine,
+			Exported:  IsPublicSymbol(ch),
+			Test:      IsTestChunk(ch),
+		}
+		if recv, ok := ch.Metadata["receiver"].(string); ok && recv != "" {
+			entry.Receiver = recv
+		} else if class, ok := ch.Metadata["class_name"].(string); ok {
+			entry.Receiver = class
+		}
+		entry.Deprecated, entry.DeprecationNote = ch.Deprecation()
+		if ch.Type == "function" || ch.Type == "method" {
+			entry.CallSites = ChunkCallSites(ch)
+			entry.Calls = ChunkCallees(ch)
+		}
+		out = append(out, entry)
+	}
+	return out
+}
+
+// IsPublicSymbol applies each language's visibility rules: exported
+// identifiers in Go, non-private/protected members in PHP and C++, names
+// without a leading underscore in Python, pub items in Rust and public or
+// protected items in C#.
+func IsPublicSymbol(ch codetypes.CodeChunk) bool {
+	switch ch.Language {
+	case "go":
+		for _, r := range ch.Name {
+			return unicode.IsUpper(r)
+		}
+		return false
+	case "php":
+		sig := strings.TrimSpace(ch.Signature)
+		return !strings.HasPrefix(sig, "private") && !strings.HasPrefix(sig, "protected")
+	case "python":
+		return !strings.HasPrefix(ch.Name, "_")
+	case "rust":
+		// trait methods take the visibility of their trait
+		if trait, _ := ch.Metadata["trait"].(string); trait != "" {
+			return true
+		}
+		vis, _ := ch.Metadata["visibility"].(string)
+		return vis == "pub"
+	case "cpp":
+		// file-local functions and non-public members
+		if static, _ := ch.Metadata["is_static"].(bool); static && ch.Type == "function" {
+			return false
+		}
+		access, _ := ch.Metadata["access"].(string)
+		return access != "private" && access != "protected"
+	case "csharp":
+		// members default to private and types to internal; protected
+		// members are visible to subclasses in other assemblies
+		access, _ := ch.Metadata["access"].(string)
+		return access == "public" || strings.HasPrefix(access, "protected")
+	default:
+		return true
+	}
+}
+
+// ExtractCallees returns the sorted, de-duplicated names called in code,
+// excluding language keywords, common builtins and the symbol itself.
+func ExtractCallees(code, self string) []string {
+	if code == "" {
+		return nil
+	}
+	seen := make(map[string]struct{})
+	for _, m := range callSiteRe.FindAllStringSubmatch(code, -1) {
+		name := m[1]
+		if name == self {
+			continue
+		}
+		if _, skip := callKeywords[strings.ToLower(name)]; skip {
+			continue
+		}
+		seen[name] = struct{}{}
+	}
+	out := make([]string, 0, len(seen))
+	for name := range seen {
+		out = append(out, name)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// ChunkCallSites returns the calls an analyzer stored in the "calls" metadata
+// of a chunk, without keywords and builtins. The metadata may come straight
+// from the analyzer or decoded from the JSON stored in the index.
+func ChunkCallSites(ch codetypes.CodeChunk) []codetypes.CallSite {
+	var sites []codetypes.CallSite
+	switch calls := ch.Metadata["calls"].(type) {
+	case []codetypes.CallSite:
+		sites = calls
+	case []map[string]any:
+		for _, c := range calls {
+			sites = append(sites, callSiteFromMap(c))
+		}
+	case []any:
+		for _, c := range calls {
+			if m, ok := c.(map[string]any); ok {
+				sites = append(sites, callSiteFromMap(m))
+			}
+		}
+	}
+	out := make([]codetypes.CallSite, 0, len(sites))
+	for _, site := range sites {
+		if site.Name == "" {
+			continue
+		}
+		if _, skip := callKeywords[strings.ToLower(site.Name)]; skip {
+			continue
+		}
+		out = append(out, site)
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	return out
+}
+
+func callSiteFromMap(m map[string]any) codetypes.CallSite {
+	site := codetypes.CallSite{}
+	site.Name, _ = m["name"].(string)
+	site.Receiver, _ = m["receiver"].(string)
+	switch line := m["line"].(type) {
+	case int:
+		site.Line = line
+	case float64:
+		site.Line = int(line)
+	}
+	return site
+}
+
+// ChunkCallees returns the sorted, de-duplicated names a function or method
+// chunk calls: from the analyzer's call sites when it stores them, else
+// matched in the code.
+func ChunkCallees(ch codetypes.CodeChunk) []string {
+	sites := ChunkCallSites(ch)
+	if len(sites) == 0 {
+		return ExtractCallees(ch.Code, ch.Name)
+	}
+	seen := make(map[string]struct{})
+	for _, site := range sites {
+		seen[site.Name] = struct{}{}
+	}
+	out := make([]string, 0, len(seen))
+	for name := range seen {
+		out = append(out, name)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// CallerIndex maps a symbol name to the entries that call it. Calls are
+// matched by name only, so overloaded names across packages share callers.
+func CallerIndex(entries []SymbolEntry) map[string][]int {
+	index := make(map[string][]int)
+	for i, e := range entries {
+		for _, callee := range e.Calls {
+			index[callee] = append(index[callee], i)
+		}
+	}
+	return index
+}
+
+// SignatureChange is a public symbol whose signature differs between two
+// symbol tables.
+type SignatureChange struct {
+	Symbol       SymbolEntry `json:"symbol"`
+	OldSignature string      `json:"old_signature"`
+	NewSignature string      `json:"new_signature"`
+}
+
+// SymbolDiff is the difference between the public API of two symbol tables.
+type SymbolDiff struct {
+	Added   []SymbolEntry     `json:"added"`
+	Removed []SymbolEntry     `json:"removed"`
+	Changed []SignatureChange `json:"changed"`
+}
+
+// Empty reports whether the diff has no changes
+func (d SymbolDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+// symbolKey identifies a symbol independently of the file that declares it,
+// so moving code between files is not reported as an API change.
+func symbolKey(e SymbolEntry) string {
+	return strings.Join([]string{e.Language, e.Package, e.Receiver, e.Kind, e.Name}, "|")
+}
+
+// DiffSymbols compares the exported symbols of two tables. Whitespace-only
+// signature differences are ignored.
+func DiffSymbols(old, cur []SymbolEntry) SymbolDiff {
+	index := func(entries []SymbolEntry) map[string]SymbolEntry {
+		out := make(map[string]SymbolEntry)
+		for _, e := range entries {
+			if e.Exported {
+				out[symbolKey(e)] = e
+			}
+		}
+		return out
+	}
+	before, after := index(old), index(cur)
+
+	var diff SymbolDiff
+	for key, e := range after {
+		prev, ok := before[key]
+		if !ok {
+			diff.Added = append(diff.Added, e)
+			continue
+		}
+		if normalizeSignature(prev.Signature) != normalizeSignature(e.Signature) {
+			diff.Changed = append(diff.Changed, SignatureChange{Symbol: e, OldSignature: prev.Signature, NewSignature: e.Signature})
+		}
+	}
+	for key, e := range before {
+		if _, ok := after[key]; !ok {
+			diff.Removed = append(diff.Removed, e)
+		}
+	}
+
+	sortEntries(diff.Added)
+	sortEntries(diff.Removed)
+	sort.Slice(diff.Changed, func(i, j int) bool {
+		return symbolKey(diff.Changed[i].Symbol) < symbolKey(diff.Changed[j].Symbol)
+	})
+	return diff
+}
+
+func normalizeSignature(sig string) string {
+	return strings.Join(strings.Fields(sig), " ")
+}
+
+func sortEntries(entries []SymbolEntry) {
+	sort.Slice(entries, func(i, j int) bool {
+		return symbolKey(entries[i]) < symbolKey(entries[j])
+	})
+}
@@ -0,0 +1,67 @@
+package ragcode
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/doITmagic/rag-code-mcp/internal/codetypes"
+)
+
+func TestSymbolEntriesFromChunks(t *testing.T) {
+	chunks := []codetypes.CodeChunk{
+		{Type: "function", Name: "Charge", Language: "go", FilePath: "/ws/billing.go",
+			Code: "func Charge(a int) error {\n\tif err := validate(a); err != nil {\n\t\treturn fmt.Errorf(\"x\")\n\t}\n\treturn store.Save(a)\n}"},
+		{Type: "function", Name: "validate", Language: "go", FilePath: "/ws/billing.go"},
+		{Type: "method", Name: "refund", Language: "php", Signature: "private function refund()", FilePath: "/ws/Billing.php"},
+		{Type: "function", Name: "_helper", Language: "python", FilePath: "/ws/util.py"},
+		{Type: "markdown", Name: "README", FilePath: "/ws/README.md"},
+	}
+
+	entries := SymbolEntriesFromChunks(chunks)
+	if len(entries) != 4 {
+		t.Fatalf("len = %d, want 4", len(entries))
+	}
+	if want := []string{"Errorf", "Save", "validate"}; !reflect.DeepEqual(entries[0].Calls, want) {
+		t.Errorf("Calls = %v, want %v", entries[0].Calls, want)
+	}
+	exported := []bool{true, false, false, false}
+	for i, e := range entries {
+		if e.Exported != exported[i] {
+			t.Errorf("%s: Exported = %v, want %v", e.Name, e.Exported, exported[i])
+		}
+	}
+
+	callers := CallerIndex(entries)
+	if got := callers["validate"]; len(got) != 1 || entries[got[0]].Name != "Charge" {
+		t.Errorf("callers[validate] = %v", got)
+	}
+}
+
+func TestDiffSymbols(t *testing.T) {
+	old := []SymbolEntry{
+		{Name: "Charge", Kind: "function", Language: "go", Package: "billing", Signature: "func Charge(amount int) error", Exported: true},
+		{Name: "Refund", Kind: "function", Language: "go", Package: "billing", Signature: "func Refund(id string) error", Exported: true},
+		{Name: "Total", Kind: "function", Language: "go", Package: "billing", Signature: "func Total() int", Exported: true, FilePath: "/ws/a.go"},
+		{Name: "helper", Kind: "function", Language: "go", Package: "billing", Signature: "func helper()"},
+	}
+	cur := []SymbolEntry{
+		{Name: "Charge", Kind: "function", Language: "go", Package: "billing", Signature: "func Charge(ctx context.Context, amount int) error", Exported: true},
+		{Name: "Total", Kind: "function", Language: "go", Package: "billing", Signature: "func  Total()  int", Exported: true, FilePath: "/ws/b.go"},
+		{Name: "Void", Kind: "function", Language: "go", Package: "billing", Signature: "func Void(id string) error", Exported: true},
+		{Name: "helper2", Kind: "function", Language: "go", Package: "billing", Signature: "func helper2()"},
+	}
+
+	diff := DiffSymbols(old, cur)
+	if len(diff.Added) != 1 || diff.Added[0].Name != "Void" {
+		t.Errorf("Added = %+v", diff.Added)
+	}
+	if len(diff.Removed) != 1 || diff.Removed[0].Name != "Refund" {
+		t.Errorf("Removed = %+v", diff.Removed)
+	}
+	if len(diff.Changed) != 1 || diff.Changed[0].Symbol.Name != "Charge" {
+		t.Errorf("Changed = %+v (moves and whitespace must be ignored)", diff.Changed)
+	}
+	if DiffSymbols(old, old).Empty() != true {
+		t.Error("expected empty diff for identical tables")
+	}
+}
@@ -0,0 +1,132 @@
+package ragcode
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/doITmagic/rag-code-mcp/internal/codetypes"
+)
+
+// TagRule adds Tags to the chunks whose file matches one of Paths (globs) or
+// PathRegex, and whose code matches Content. Empty conditions always match.
+type TagRule struct {
+	Tags      []string
+	Paths     []string
+	PathRegex string
+	Content   string
+}
+
+type compiledTagRule struct {
+	tags      []string
+	paths     pathFilter
+	pathRegex *regexp.Regexp
+	content   *regexp.Regexp
+}
+
+// Tagger applies tag rules at index time. Tags are lower-case and stored in
+// the "tags" metadata of chunks (see ChunkTags).
+type Tagger struct {
+	rules []compiledTagRule
+}
+
+// NewTagger compiles rules. A rule needs at least one tag and one condition.
+func NewTagger(rules []TagRule) (*Tagger, error) {
+	t := &Tagger{}
+	for i, r := range rules {
+		var c compiledTagRule
+		for _, tag := range r.Tags {
+			if tag = strings.ToLower(strings.TrimSpace(tag)); tag != "" {
+				c.tags = append(c.tags, tag)
+			}
+		}
+		if len(c.tags) == 0 {
+			return nil, fmt.Errorf("tag rule %d: no tags", i+1)
+		}
+		if len(r.Paths) == 0 && r.PathRegex == "" && r.Content == "" {
+			return nil, fmt.Errorf("tag rule %d (%s): set paths, regex or content", i+1, strings.Join(c.tags, ", "))
+		}
+
+		var err error
+		if c.paths, err = newPathFilter(r.Paths); err != nil {
+			return nil, fmt.Errorf("tag rule %d: %w", i+1, err)
+		}
+		if r.PathRegex != "" {
+			if c.pathRegex, err = regexp.Compile(r.PathRegex); err != nil {
+				return nil, fmt.Errorf("tag rule %d: invalid regex: %w", i+1, err)
+			}
+		}
+		if r.Content != "" {
+			if c.content, err = regexp.Compile(r.Content); err != nil {
+				return nil, fmt.Errorf("tag rule %d: invalid content regex: %w", i+1, err)
+			}
+		}
+		t.rules = append(t.rules, c)
+	}
+	return t, nil
+}
+
+// Tags returns the sorted tags of all rules matching a file and its content.
+func (t *Tagger) Tags(path, content string) []string {
+	if t == nil {
+		return nil
+	}
+	slashPath := strings.ReplaceAll(path, "\\", "/")
+	set := make(map[string]bool)
+	for _, r := range t.rules {
+		if len(r.paths) > 0 || r.pathRegex != nil {
+			byGlob := len(r.paths) > 0 && r.paths.match(slashPath)
+			byRegex := r.pathRegex != nil && r.pathRegex.MatchString(slashPath)
+			if !byGlob && !byRegex {
+				continue
+			}
+		}
+		if r.content != nil && !r.content.MatchString(content) {
+			continue
+		}
+		for _, tag := range r.tags {
+			set[tag] = true
+		}
+	}
+	if len(set) == 0 {
+		return nil
+	}
+	return sortedKeys(set)
+}
+
+func (t *Tagger) Name() string { return "tags" }
+
+// Process adds the matching tags to each chunk, keeping tags set earlier.
+func (t *Tagger) Process(ctx context.Context, chunks []codetypes.CodeChunk) ([]codetypes.CodeChunk, error) {
+	for i := range chunks {
+		ch := &chunks[i]
+		tags := t.Tags(ch.FilePath, ch.Code)
+		if len(tags) == 0 {
+			continue
+		}
+		set := make(map[string]bool)
+		for _, tag := range append(ChunkTags(*ch), tags...) {
+			set[tag] = true
+		}
+		setChunkMetadata(ch, "tags", sortedKeys(set))
+	}
+	return chunks, nil
+}
+
+// ChunkTags returns the tags of a chunk, as set by a Tagger.
+func ChunkTags(ch codetypes.CodeChunk) []string {
+	switch tags := ch.Metadata["tags"].(type) {
+	case []string:
+		return tags
+	case []interface{}:
+		out := make([]string, 0, len(tags))
+		for _, t := range tags {
+			if s, ok := t.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	}
+	return nil
+}
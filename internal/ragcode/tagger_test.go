@@ -0,0 +1,55 @@
+package ragcode
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/doITmagic/rag-code-mcp/internal/codetypes"
+)
+
+func TestTagger(t *testing.T) {
+	tagger, err := NewTagger([]TagRule{
+		{Tags: []string{"Payment"}, Paths: []string{"internal/payment/**"}},
+		{Tags: []string{"auth"}, PathRegex: `(?i)/(auth|login)`},
+		{Tags: []string{"legacy"}, Paths: []string{"src/legacy/**"}, Content: `mysql_query\(`},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		path, content string
+		want          []string
+	}{
+		{"/app/internal/payment/login.go", "", []string{"auth", "payment"}},
+		{"/app/src/legacy/user.php", "mysql_query($sql);", []string{"legacy"}},
+		{"/app/src/legacy/user.php", "$pdo->query($sql);", nil},
+		{"/app/internal/db/db.go", "", nil},
+	}
+	for _, c := range cases {
+		if got := tagger.Tags(c.path, c.content); !reflect.DeepEqual(got, c.want) {
+			t.Errorf("Tags(%s) = %v, want %v", c.path, got, c.want)
+		}
+	}
+
+	chunks, _ := tagger.Process(context.Background(), []codetypes.CodeChunk{{
+		FilePath: "/app/internal/payment/charge.go",
+		Metadata: map[string]any{"tags": []string{"core"}},
+	}})
+	if got := ChunkTags(chunks[0]); !reflect.DeepEqual(got, []string{"core", "payment"}) {
+		t.Errorf("chunk tags = %v, want [core payment]", got)
+	}
+}
+
+func TestNewTaggerErrors(t *testing.T) {
+	for _, rule := range []TagRule{
+		{Paths: []string{"internal/**"}},
+		{Tags: []string{"payment"}},
+		{Tags: []string{"auth"}, PathRegex: "("},
+	} {
+		if _, err := NewTagger([]TagRule{rule}); err == nil {
+			t.Errorf("%+v: expected an error", rule)
+		}
+	}
+}
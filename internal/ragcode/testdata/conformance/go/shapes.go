@@ -0,0 +1,37 @@
+// Package shapes computes areas of plane figures.
+package shapes
+
+import "math"
+
+// Pi is the ratio of a circle's circumference to its diameter.
+const Pi = math.Pi
+
+// Shape is a closed plane figure.
+type Shape interface {
+	// Area returns the surface of the shape.
+	Area() float64
+}
+
+// Circle is a shape with a radius.
+type Circle struct {
+	Radius float64
+}
+
+// Area returns the surface of the circle.
+func (c Circle) Area() float64 {
+	return Pi * c.Radius * c.Radius
+}
+
+// Scale grows the circle by a factor.
+func (c *Circle) Scale(f float64) {
+	c.Radius *= f
+}
+
+// Total sums the areas of shapes.
+func Total(shapes ...Shape) float64 {
+	var sum float64
+	for _, s := range shapes {
+		sum += s.Area()
+	}
+	return sum
+}
@@ -0,0 +1,43 @@
+<?php
+
+namespace App\Billing;
+
+use App\Models\Customer;
+
+/**
+ * An invoice sent to a customer.
+ */
+class Invoice
+{
+    private array $lines = [];
+
+    public function __construct(private Customer $customer)
+    {
+    }
+
+    /**
+     * Adds a priced line to the invoice.
+     */
+    public function addLine(string $label, int $cents): void
+    {
+        $this->lines[] = [$label, $cents];
+    }
+
+    public function total(): int
+    {
+        return array_sum(array_column($this->lines, 1));
+    }
+}
+
+interface Payable
+{
+    public function pay(Invoice $invoice): bool;
+}
+
+/**
+ * Formats cents as a currency string.
+ */
+function format_cents(int $cents): string
+{
+    return sprintf('%.2f', $cents / 100);
+}
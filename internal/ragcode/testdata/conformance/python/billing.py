@@ -0,0 +1,40 @@
+"""Invoices and payments."""
+
+import decimal
+from dataclasses import dataclass
+
+
+TAX_RATE = decimal.Decimal("0.2")
+
+
+@dataclass
+class Line:
+    """A priced invoice line."""
+
+    label: str
+    cents: int
+
+
+class Invoice:
+    """An invoice sent to a customer."""
+
+    def __init__(self, customer):
+        self.customer = customer
+        self.lines = []
+
+    def add_line(self, label, cents):
+        """Adds a priced line."""
+        self.lines.append(Line(label, cents))
+
+    @property
+    def total(self):
+        return sum(line.cents for line in self.lines)
+
+
+def format_cents(cents):
+    """Formats cents as a currency string."""
+    return f"{cents / 100:.2f}"
+
+
+async def send(invoice):
+    return invoice
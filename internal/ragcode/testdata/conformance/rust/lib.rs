@@ -0,0 +1,44 @@
+//! Invoices and payments.
+
+use std::fmt;
+
+/// A priced invoice line.
+#[derive(Debug, Clone)]
+pub struct Line {
+    pub label: String,
+    pub cents: u64,
+}
+
+/// Something that can be paid.
+pub trait Payable {
+    /// Pays the amount due.
+    fn pay(&mut self) -> bool;
+}
+
+/// An invoice sent to a customer.
+pub struct Invoice {
+    lines: Vec<Line>,
+}
+
+impl Invoice {
+    /// Creates an empty invoice.
+    pub fn new() -> Self {
+        Invoice { lines: Vec::new() }
+    }
+
+    /// Sum of the lines, in cents.
+    pub fn total(&self) -> u64 {
+        self.lines.iter().map(|l| l.cents).sum()
+    }
+}
+
+impl fmt::Display for Invoice {
+    fn fmt(&self, f: &mut fmt::Formatter) -> fmt::Result {
+        write!(f, "{} cents", self.total())
+    }
+}
+
+/// Formats cents as a currency string.
+pub fn format_cents(cents: u64) -> String {
+    format!("{}.{:02}", cents / 100, cents % 100)
+}
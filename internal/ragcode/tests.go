@@ -0,0 +1,273 @@
+package ragcode
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/doITmagic/rag-code-mcp/internal/codetypes"
+)
+
+// Why a test is linked to a symbol, strongest first
+const (
+	TestReasonCalls           = "calls"            // the test calls the symbol
+	TestReasonName            = "name"             // the test is named after the symbol
+	TestReasonCallsIndirectly = "calls_indirectly" // the test calls a test helper that calls the symbol
+)
+
+// testNamePrefixes start the names of test functions, benchmarks and
+// examples across languages
+var testNamePrefixes = []string{"Benchmark", "Example", "Fuzz", "Test", "test_", "test"}
+
+// IsTestFile reports whether path is a test file by the conventions of the
+// supported languages: Go _test.go, Python test_*.py and *_test.py, PHP and
+// C# *Test(s) classes, and files under test or tests directories.
+func IsTestFile(path string) bool {
+	slashed := filepath.ToSlash(path)
+	base := filepath.Base(slashed)
+	stem := strings.TrimSuffix(base, filepath.Ext(base))
+	switch {
+	case strings.HasSuffix(base, "_test.go"),
+		strings.HasSuffix(base, ".py") && (strings.HasPrefix(base, "test_") || strings.HasSuffix(stem, "_test")),
+		(strings.HasSuffix(base, ".php") || strings.HasSuffix(base, ".cs")) && (strings.HasSuffix(stem, "Test") || strings.HasSuffix(stem, "Tests")):
+		return true
+	}
+	return strings.Contains(slashed, "/tests/") || strings.Contains(slashed, "/test/")
+}
+
+// IsTestChunk reports whether a chunk is test code: it comes from a test
+// file, or the analyzer marked it (Go test files, Rust #[test] functions)
+func IsTestChunk(ch codetypes.CodeChunk) bool {
+	if test, _ := ch.Metadata["is_test"].(bool); test {
+		return true
+	}
+	return IsTestFile(ch.FilePath)
+}
+
+// TestLink is a test linked to the symbol it exercises
+type TestLink struct {
+	Name      string   `json:"name"`
+	Receiver  string   `json:"receiver,omitempty"` // test class or suite
+	Language  string   `json:"language"`
+	FilePath  string   `json:"file_path"`
+	StartLine int      `json:"start_line"`
+	EndLine   int      `json:"end_line"`
+	Reasons   []string `json:"reasons"`       // TestReason* values, strongest first
+	Via       string   `json:"via,omitempty"` // the helper of TestReasonCallsIndirectly
+}
+
+// SymbolTests are the tests of a symbol
+type SymbolTests struct {
+	Symbol  string     `json:"symbol"`
+	Targets []string   `json:"targets"` // the matched symbols, Receiver.Name for methods
+	Tests   []TestLink `json:"tests"`   // strongest link first
+}
+
+// FindTests links the test functions of the symbol table to symbol, a
+// function, method or type name, optionally qualified by its receiver or
+// package. A test is linked when it calls the symbol (for a type, one of
+// its methods or its New<Type> constructor), directly or through a test
+// helper, or when it is named after it (TestCharge, TestStore_Save,
+// test_charge_card, StoreTest::testSave).
+func FindTests(entries []SymbolEntry, symbol string) (*SymbolTests, error) {
+	g := newCallGraphIndex(entries)
+	targets := g.match(symbol)
+	_, name := splitQualified(symbol)
+	var types []SymbolEntry
+	if len(targets) == 0 {
+		for _, e := range entries {
+			if e.Name == name && !callable(e) && !e.Test {
+				types = append(types, e)
+			}
+		}
+		for i, e := range entries {
+			if !callable(e) || e.Test {
+				continue
+			}
+			for _, t := range types {
+				if e.Language == t.Language && (e.Receiver == t.Name || e.Receiver == "" && e.Name == "New"+t.Name) {
+					targets = append(targets, i)
+					break
+				}
+			}
+		}
+		if len(types) == 0 {
+			return nil, fmt.Errorf("no function, method or type named '%s' in the symbol table", symbol)
+		}
+	}
+
+	result := &SymbolTests{Symbol: symbol}
+	links := make(map[int]*TestLink)
+	link := func(i int, reason, via string) {
+		l, ok := links[i]
+		if !ok {
+			e := entries[i]
+			l = &TestLink{Name: e.Name, Receiver: e.Receiver, Language: e.Language, FilePath: e.FilePath, StartLine: e.StartLine, EndLine: e.EndLine}
+			links[i] = l
+		}
+		for _, r := range l.Reasons {
+			if r == reason {
+				return
+			}
+		}
+		l.Reasons = append(l.Reasons, reason)
+		if via != "" && l.Via == "" {
+			l.Via = via
+		}
+	}
+	callersOf := func(id string) []int {
+		var out []int
+		for _, edge := range g.in[id] {
+			if i, ok := g.byID[edge.From]; ok && entries[i].Test {
+				out = append(out, i)
+			}
+		}
+		return out
+	}
+
+	seen := make(map[int]bool)
+	for _, t := range targets {
+		e := entries[t]
+		if e.Receiver != "" {
+			result.Targets = append(result.Targets, e.Receiver+"."+e.Name)
+		} else {
+			result.Targets = append(result.Targets, e.Name)
+		}
+		for _, caller := range callersOf(g.ids[t]) {
+			link(caller, TestReasonCalls, "")
+			if seen[caller] {
+				continue
+			}
+			seen[caller] = true
+			for _, test := range callersOf(g.ids[caller]) {
+				if test != caller {
+					link(test, TestReasonCallsIndirectly, entries[caller].Name)
+				}
+			}
+		}
+	}
+	for i, e := range entries {
+		if !e.Test || !callable(e) {
+			continue
+		}
+		for _, t := range targets {
+			if entries[t].Language == e.Language && testNamedAfter(e, entries[t].Receiver, entries[t].Name) {
+				link(i, TestReasonName, "")
+				break
+			}
+		}
+		for _, t := range types {
+			if t.Language == e.Language && testNamedAfter(e, "", t.Name) {
+				link(i, TestReasonName, "")
+				break
+			}
+		}
+	}
+	if len(types) > 0 {
+		result.Targets = append([]string{name}, result.Targets...)
+	}
+
+	result.Tests = make([]TestLink, 0, len(links))
+	for _, l := range links {
+		sort.Slice(l.Reasons, func(i, j int) bool { return testReasonRank(l.Reasons[i]) < testReasonRank(l.Reasons[j]) })
+		result.Tests = append(result.Tests, *l)
+	}
+	sort.Slice(result.Tests, func(i, j int) bool {
+		a, b := result.Tests[i], result.Tests[j]
+		if ra, rb := testReasonRank(a.Reasons[0]), testReasonRank(b.Reasons[0]); ra != rb {
+			return ra < rb
+		}
+		if len(a.Reasons) != len(b.Reasons) {
+			return len(a.Reasons) > len(b.Reasons)
+		}
+		if a.FilePath != b.FilePath {
+			return a.FilePath < b.FilePath
+		}
+		return a.StartLine < b.StartLine
+	})
+	return result, nil
+}
+
+func testReasonRank(reason string) int {
+	switch reason {
+	case TestReasonCalls:
+		return 0
+	case TestReasonName:
+		return 1
+	}
+	return 2
+}
+
+// testNamedAfter reports whether test is named after the symbol name of
+// receiver: its name without the test prefix starts with Name or
+// Receiver_Name at an underscore boundary. Methods of test classes named
+// after a type (StoreTest, TestStore) are named after that type.
+func testNamedAfter(test SymbolEntry, receiver, name string) bool {
+	subject := testClassSubject(test.Receiver)
+	if receiver == "" && subject != "" && strings.EqualFold(subject, name) {
+		return true
+	}
+	if receiver != "" && subject != "" && !strings.EqualFold(subject, receiver) {
+		return false
+	}
+	stripped, ok := stripTestPrefix(test.Name)
+	// Rust tests are marked by #[test], not named
+	if !ok && test.Language != "rust" {
+		return false
+	}
+	want := []string{normalizeTestName(name)}
+	if receiver != "" {
+		want = append(want, normalizeTestName(receiver+name))
+	}
+	segments := strings.Split(stripped, "_")
+	for k := 1; k <= len(segments); k++ {
+		got := normalizeTestName(strings.Join(segments[:k], ""))
+		for _, w := range want {
+			if got == w {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// testClassSubject is the type a test class is named after: Store for
+// StoreTest, StoreTests and TestStore
+func testClassSubject(class string) string {
+	for _, suffix := range []string{"Tests", "Test"} {
+		if s, ok := strings.CutSuffix(class, suffix); ok && s != "" {
+			return s
+		}
+	}
+	if s, ok := strings.CutPrefix(class, "Test"); ok && s != "" {
+		return s
+	}
+	return ""
+}
+
+// stripTestPrefix removes the test prefix of a test function name. After
+// Test or test the name continues with an upper case letter or an
+// underscore (TestSave, testSave, test_save), not testdata or Testify.
+func stripTestPrefix(name string) (string, bool) {
+	for _, p := range testNamePrefixes {
+		rest, ok := strings.CutPrefix(name, p)
+		if !ok || rest == "" || (!strings.HasSuffix(p, "_") && rest[0] >= 'a' && rest[0] <= 'z') {
+			continue
+		}
+		return strings.TrimPrefix(rest, "_"), true
+	}
+	return name, false
+}
+
+func normalizeTestName(s string) string {
+	return strings.ToLower(strings.ReplaceAll(s, "_", ""))
+}
+
+// splitQualified splits Receiver.Name, Package.Name or Class::Name
+func splitQualified(symbol string) (qualifier, name string) {
+	if i := strings.LastIndexAny(symbol, ".:"); i >= 0 {
+		return strings.TrimRight(symbol[:i], ".:"), symbol[i+1:]
+	}
+	return "", symbol
+}
@@ -0,0 +1,103 @@
+package ragcode
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/doITmagic/rag-code-mcp/internal/codetypes"
+)
+
+func testsFixture() []SymbolEntry {
+	return []SymbolEntry{
+		{Name: "Charge", Kind: "function", Language: "go", Package: "billing", FilePath: "/ws/billing/charge.go", StartLine: 5, EndLine: 15,
+			CallSites: []codetypes.CallSite{{Name: "Save", Receiver: "s", Line: 9}}},
+		{Name: "Store", Kind: "type", Language: "go", Package: "billing", FilePath: "/ws/billing/store.go", StartLine: 1, EndLine: 2},
+		{Name: "Save", Kind: "method", Language: "go", Package: "billing", Receiver: "Store", FilePath: "/ws/billing/store.go", StartLine: 3, EndLine: 8},
+		{Name: "TestCharge_declined", Kind: "function", Language: "go", Package: "billing", FilePath: "/ws/billing/charge_test.go", StartLine: 10, EndLine: 20, Test: true,
+			CallSites: []codetypes.CallSite{{Name: "Charge", Line: 12}}},
+		{Name: "mustCharge", Kind: "function", Language: "go", Package: "billing", FilePath: "/ws/billing/charge_test.go", StartLine: 22, EndLine: 30, Test: true,
+			CallSites: []codetypes.CallSite{{Name: "Charge", Line: 24}}},
+		{Name: "TestRefund", Kind: "function", Language: "go", Package: "billing", FilePath: "/ws/billing/refund_test.go", StartLine: 5, EndLine: 15, Test: true,
+			CallSites: []codetypes.CallSite{{Name: "mustCharge", Line: 6}}},
+		{Name: "TestStore_Save", Kind: "function", Language: "go", Package: "billing", FilePath: "/ws/billing/store_test.go", StartLine: 5, EndLine: 15, Test: true},
+		{Name: "TestChargeback", Kind: "function", Language: "go", Package: "billing", FilePath: "/ws/billing/chargeback_test.go", StartLine: 5, EndLine: 15, Test: true},
+		{Name: "charge", Kind: "function", Language: "python", Package: "app.billing", FilePath: "/ws/app/billing.py", StartLine: 1, EndLine: 5},
+		{Name: "test_charge_declined", Kind: "function", Language: "python", Package: "tests.test_billing", FilePath: "/ws/tests/test_billing.py", StartLine: 1, EndLine: 5, Test: true},
+		{Name: "testSave", Kind: "method", Language: "php", Receiver: "StoreTest", FilePath: "/ws/tests/StoreTest.php", StartLine: 8, EndLine: 12, Test: true},
+		{Name: "testSave", Kind: "method", Language: "php", Receiver: "CacheTest", FilePath: "/ws/tests/CacheTest.php", StartLine: 8, EndLine: 12, Test: true},
+		{Name: "Store", Kind: "class", Language: "php", FilePath: "/ws/src/Store.php", StartLine: 3, EndLine: 10},
+		{Name: "save", Kind: "method", Language: "php", Receiver: "Store", FilePath: "/ws/src/Store.php", StartLine: 4, EndLine: 9},
+	}
+}
+
+func testNames(r *SymbolTests) map[string][]string {
+	out := make(map[string][]string)
+	for _, test := range r.Tests {
+		out[test.Receiver+"."+test.Name] = test.Reasons
+	}
+	return out
+}
+
+func TestFindTests(t *testing.T) {
+	r, err := FindTests(testsFixture(), "Charge")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// TestChargeback is named after another symbol; Python charge is
+	// another language
+	want := map[string][]string{
+		".TestCharge_declined": {TestReasonCalls, TestReasonName},
+		".mustCharge":          {TestReasonCalls},
+		".TestRefund":          {TestReasonCallsIndirectly},
+	}
+	if got := testNames(r); !reflect.DeepEqual(got, want) {
+		t.Fatalf("tests = %v, want %v", got, want)
+	}
+	if r.Tests[0].Name != "TestCharge_declined" || r.Tests[2].Via != "mustCharge" {
+		t.Errorf("order or via = %+v", r.Tests)
+	}
+
+	// A type gathers the tests of its methods
+	r, err = FindTests(testsFixture(), "Store")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = map[string][]string{
+		".TestStore_Save":    {TestReasonName},
+		"StoreTest.testSave": {TestReasonName},
+	}
+	if got := testNames(r); !reflect.DeepEqual(got, want) {
+		t.Errorf("type tests = %v, want %v", got, want)
+	}
+
+	r, err = FindTests(testsFixture(), "charge")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := testNames(r); len(got) != 1 || got[".test_charge_declined"] == nil {
+		t.Errorf("python tests = %v", got)
+	}
+
+	if _, err := FindTests(testsFixture(), "missing"); err == nil {
+		t.Error("unknown symbol resolved")
+	}
+}
+
+func TestIsTestFile(t *testing.T) {
+	for path, want := range map[string]bool{
+		"/ws/billing/charge_test.go":      true,
+		"/ws/billing/charge.go":           false,
+		"/ws/app/test_views.py":           true,
+		"/ws/app/views_test.py":           true,
+		"/ws/app/testing.py":              false,
+		"/ws/src/StoreTest.php":           true,
+		"/ws/src/Contest.php":             false,
+		"/ws/Billing.Tests/StoreTests.cs": true,
+		"/ws/tests/conftest.py":           true,
+		"/ws/src/lib.rs":                  false,
+	} {
+		if got := IsTestFile(path); got != want {
+			t.Errorf("IsTestFile(%s) = %v, want %v", path, got, want)
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/qdrant/go-client/qdrant"
+)
+
+// GenerationKey is the payload field holding the index generation of a
+// point. Every indexing run writes a new generation; searches only see the
+// generations committed so far (see SetVisibleGeneration), so a run in
+// progress never mixes its chunks with the ones it replaces.
+const GenerationKey = "generation"
+
+// SetVisibleGeneration limits searches of the client to points of
+// generation <= gen and to points written before generations existed.
+// Clients that never call it see every point.
+func (c *QdrantClient) SetVisibleGeneration(gen uint64) {
+	defer c.markWrite()
+	c.visibleGen.Store(gen)
+	c.genFilter.Store(true)
+}
+
+// VisibleGeneration returns the generation set with SetVisibleGeneration
+func (c *QdrantClient) VisibleGeneration() (uint64, bool) {
+	return c.visibleGen.Load(), c.genFilter.Load()
+}
+
+// searchFilter adds to filter the conditions hiding points from searches:
+// tombstoned points, points of hidden files, and points of generations not
+// visible yet
+func (c *QdrantClient) searchFilter(filter *qdrant.Filter) *qdrant.Filter {
+	if filter == nil {
+		filter = &qdrant.Filter{}
+	}
+	filter.Must = append(filter.Must, qdrant.NewIsEmpty(TombstoneKey))
+	filter = c.hideFiles(filter)
+	gen, ok := c.VisibleGeneration()
+	if !ok {
+		return filter
+	}
+	filter.Must = append(filter.Must, qdrant.NewFilterAsCondition(&qdrant.Filter{
+		Should: []*qdrant.Condition{
+			qdrant.NewIsEmpty(GenerationKey),
+			qdrant.NewRange(GenerationKey, &qdrant.Range{Lte: qdrant.PtrOf(float64(gen))}),
+		},
+	}))
+	return filter
+}
+
+// visible reports whether a payload read back from Qdrant belongs to a
+// visible generation
+func (c *QdrantClient) visible(payload map[string]interface{}) bool {
+	gen, ok := c.VisibleGeneration()
+	if !ok {
+		return true
+	}
+	s, _ := payload[GenerationKey].(string)
+	if s == "" {
+		return true
+	}
+	n, err := strconv.ParseUint(s, 10, 64)
+	return err != nil || n <= gen
+}
+
+// DeleteFileExceptGeneration deletes the points of a file written by other
+// generations than gen: what a committed run replaced
+func (c *QdrantClient) DeleteFileExceptGeneration(ctx context.Context, file string, gen uint64) error {
+	return c.deleteWhere(ctx, &qdrant.Filter{
+		Must:    []*qdrant.Condition{qdrant.NewMatchKeyword("file", file)},
+		MustNot: []*qdrant.Condition{qdrant.NewMatchInt(GenerationKey, int64(gen))},
+	})
+}
+
+// DeleteFileGeneration deletes the points of a file written by generation gen
+func (c *QdrantClient) DeleteFileGeneration(ctx context.Context, file string, gen uint64) error {
+	return c.deleteWhere(ctx, &qdrant.Filter{
+		Must: []*qdrant.Condition{
+			qdrant.NewMatchKeyword("file", file),
+			qdrant.NewMatchInt(GenerationKey, int64(gen)),
+		},
+	})
+}
+
+// DeleteNewerGenerations deletes the points of generations above gen, left
+// behind by runs that never committed
+func (c *QdrantClient) DeleteNewerGenerations(ctx context.Context, gen uint64) error {
+	return c.deleteWhere(ctx, &qdrant.Filter{
+		Must: []*qdrant.Condition{
+			qdrant.NewRange(GenerationKey, &qdrant.Range{Gt: qdrant.PtrOf(float64(gen))}),
+		},
+	})
+}
+
+func (c *QdrantClient) deleteWhere(ctx context.Context, filter *qdrant.Filter) error {
+	defer c.markWrite()
+	_, err := c.client.Delete(ctx, &qdrant.DeletePoints{
+		CollectionName: c.config.Collection,
+		Points: &qdrant.PointsSelector{
+			PointsSelectorOneOf: &qdrant.PointsSelector_Filter{Filter: filter},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete points by filter: %w", err)
+	}
+	return nil
+}
+
+// payloadValue converts a payload value to the form stored by Upsert
+func payloadValue(key string, val interface{}) *qdrant.Value {
+	if key == GenerationKey || key == TombstoneKey || key == SchemaVersionKey {
+		switch n := val.(type) {
+		case uint64:
+			return qdrant.NewValueInt(int64(n))
+		case int:
+			return qdrant.NewValueInt(int64(n))
+		case int64:
+			return qdrant.NewValueInt(n)
+		case string:
+			// Payloads read back and stored again, when points are copied
+			if i, err := strconv.ParseInt(n, 10, 64); err == nil {
+				return qdrant.NewValueInt(i)
+			}
+		}
+	}
+	return qdrant.NewValueString(fmt.Sprintf("%v", val))
+}
+
+// readPayload converts a payload read from Qdrant to strings
+func readPayload(payload map[string]*qdrant.Value) map[string]interface{} {
+	out := make(map[string]interface{}, len(payload))
+	for key, val := range payload {
+		if _, ok := val.GetKind().(*qdrant.Value_IntegerValue); ok {
+			out[key] = strconv.FormatInt(val.GetIntegerValue(), 10)
+			continue
+		}
+		out[key] = val.GetStringValue()
+	}
+	return out
+}
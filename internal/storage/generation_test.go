@@ -0,0 +1,64 @@
+package storage
+
+import (
+	"testing"
+
+	"github.com/qdrant/go-client/qdrant"
+)
+
+func TestGenerationVisibility(t *testing.T) {
+	c := &QdrantClient{}
+	if f := c.searchFilter(nil); len(f.Must) != 1 {
+		t.Errorf("clients without a visible generation should only skip tombstones, got %d conditions", len(f.Must))
+	}
+	if !c.visible(map[string]interface{}{GenerationKey: "7"}) {
+		t.Error("clients without a visible generation see every point")
+	}
+
+	c.SetVisibleGeneration(3)
+	filter := c.searchFilter(&qdrant.Filter{Must: []*qdrant.Condition{qdrant.NewMatchKeyword("file", "a.go")}})
+	if len(filter.Must) != 3 {
+		t.Errorf("must conditions = %d, want the file, tombstone and generation conditions", len(filter.Must))
+	}
+	for payload, want := range map[string]bool{"": true, "2": true, "3": true, "4": false} {
+		p := map[string]interface{}{}
+		if payload != "" {
+			p[GenerationKey] = payload
+		}
+		if got := c.visible(p); got != want {
+			t.Errorf("visible(generation=%q) = %v, want %v", payload, got, want)
+		}
+	}
+}
+
+func TestGenerationPayload(t *testing.T) {
+	stored := map[string]*qdrant.Value{
+		GenerationKey: payloadValue(GenerationKey, uint64(5)),
+		"start_line":  payloadValue("start_line", 12),
+	}
+	if _, ok := stored[GenerationKey].GetKind().(*qdrant.Value_IntegerValue); !ok {
+		t.Error("generation should be stored as an integer for range filters")
+	}
+	payload := readPayload(stored)
+	if payload[GenerationKey] != "5" || payload["start_line"] != "12" {
+		t.Errorf("payload = %v", payload)
+	}
+	if _, ok := payloadValue(GenerationKey, payload[GenerationKey]).GetKind().(*qdrant.Value_IntegerValue); !ok {
+		t.Error("a generation read back should be stored again as an integer")
+	}
+}
+
+func TestHiddenFilesFilter(t *testing.T) {
+	c := &QdrantClient{}
+	if f := c.searchFilter(nil); len(f.MustNot) != 0 {
+		t.Errorf("clients without hidden files should not exclude any, got %d conditions", len(f.MustNot))
+	}
+	c.SetHiddenFiles([]string{"a.go", "b.go"})
+	if f := c.searchFilter(nil); len(f.MustNot) != 1 {
+		t.Errorf("must_not conditions = %d, want one for the hidden files", len(f.MustNot))
+	}
+	c.SetHiddenFiles(nil)
+	if len(c.HiddenFiles()) != 0 {
+		t.Error("SetHiddenFiles(nil) should show every file")
+	}
+}
@@ -0,0 +1,74 @@
+package storage
+
+import (
+	"sync/atomic"
+
+	"github.com/qdrant/go-client/qdrant"
+)
+
+// hiddenFiles is the set of files whose points searches leave out, see
+// VectorStore.SetHiddenFiles
+type hiddenFiles struct {
+	files atomic.Pointer[map[string]bool]
+}
+
+func (h *hiddenFiles) set(files []string) {
+	if len(files) == 0 {
+		h.files.Store(nil)
+		return
+	}
+	set := make(map[string]bool, len(files))
+	for _, f := range files {
+		set[f] = true
+	}
+	h.files.Store(&set)
+}
+
+func (h *hiddenFiles) list() []string {
+	set := h.files.Load()
+	if set == nil {
+		return nil
+	}
+	files := make([]string, 0, len(*set))
+	for f := range *set {
+		files = append(files, f)
+	}
+	return files
+}
+
+func (h *hiddenFiles) has(file string) bool {
+	set := h.files.Load()
+	return set != nil && (*set)[file]
+}
+
+// SetHiddenFiles leaves the points of files out of searches, counts and
+// scrolls until they are re-indexed, e.g. files that differ between the
+// commit they were indexed at and the checked out branch. nil shows every
+// file again.
+func (c *QdrantClient) SetHiddenFiles(files []string) {
+	c.hidden.set(files)
+}
+
+// HiddenFiles returns the files set with SetHiddenFiles
+func (c *QdrantClient) HiddenFiles() []string {
+	return c.hidden.list()
+}
+
+// hideFiles adds the condition leaving out hidden files to filter
+func (c *QdrantClient) hideFiles(filter *qdrant.Filter) *qdrant.Filter {
+	if files := c.hidden.list(); len(files) > 0 {
+		filter.MustNot = append(filter.MustNot, qdrant.NewMatchKeywords("file", files...))
+	}
+	return filter
+}
+
+// SetHiddenFiles leaves the points of files out of searches, see
+// QdrantClient.SetHiddenFiles
+func (s *LocalStore) SetHiddenFiles(files []string) {
+	s.hidden.set(files)
+}
+
+// HiddenFiles returns the files set with SetHiddenFiles
+func (s *LocalStore) HiddenFiles() []string {
+	return s.hidden.list()
+}
@@ -0,0 +1,778 @@
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/doITmagic/rag-code-mcp/internal/memory"
+)
+
+// LocalConfig contains the settings of a local vector store
+type LocalConfig struct {
+	Dir        string
+	Collection string
+}
+
+// LocalStore is an embedded vector store: each collection is a file of the
+// directory, loaded in memory and searched exhaustively by cosine
+// similarity. It needs no server, which suits single-user setups up to a
+// few hundred thousand chunks.
+//
+// Stores of one process opening the same directory share their
+// collections; two processes must not write the same directory at once.
+type LocalStore struct {
+	dir        string
+	collection string
+
+	// Searches see generations up to visibleGen when genFilter is set
+	visibleGen atomic.Uint64
+	genFilter  atomic.Bool
+
+	// Searches leave out the points of these files (hidden.go)
+	hidden hiddenFiles
+}
+
+// NewLocalStore creates a store for the collections of a directory
+func NewLocalStore(config LocalConfig) (*LocalStore, error) {
+	if config.Dir == "" {
+		return nil, fmt.Errorf("local vector store directory is required")
+	}
+	if err := os.MkdirAll(config.Dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create local vector store directory: %w", err)
+	}
+	return &LocalStore{dir: config.Dir, collection: config.Collection}, nil
+}
+
+// localFileMagic starts every collection file, followed by the vector
+// dimension and the records of the log
+const localFileMagic = "ragcode-vectors 1\n"
+
+// Record operations of a collection file
+const (
+	localPut    byte = 1 // point with its payload and vector
+	localDelete byte = 2 // point removed
+	localSet    byte = 3 // payload fields set on a point
+)
+
+// localPoint is a point of a local collection. Vectors are normalized on
+// write, like Qdrant does for cosine collections.
+type localPoint struct {
+	vector  []float32
+	payload map[string]string
+}
+
+// localCollection is a collection loaded from its file. Writes are appended
+// to the file as they happen; the file is compacted when it is loaded.
+type localCollection struct {
+	mu     sync.RWMutex
+	path   string
+	dim    int
+	points map[string]*localPoint
+	file   *os.File
+}
+
+// localCollections holds the collections loaded by the process, by path
+var localCollections = struct {
+	sync.Mutex
+	open map[string]*localCollection
+}{open: make(map[string]*localCollection)}
+
+// collectionPath returns the file of a collection. Characters unsafe in
+// file names are replaced.
+func (s *LocalStore) collectionPath(name string) string {
+	safe := []byte(name)
+	for i, c := range safe {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '_' || c == '.') {
+			safe[i] = '_'
+		}
+	}
+	return filepath.Join(s.dir, string(safe)+".vec")
+}
+
+// load returns a collection, reading its file the first time. It returns
+// nil when the collection does not exist.
+func (s *LocalStore) load(name string) (*localCollection, error) {
+	path := s.collectionPath(name)
+	localCollections.Lock()
+	defer localCollections.Unlock()
+	if c, ok := localCollections.open[path]; ok {
+		return c, nil
+	}
+	c, err := openLocalCollection(path)
+	if err != nil || c == nil {
+		return nil, err
+	}
+	localCollections.open[path] = c
+	return c, nil
+}
+
+// coll returns the collection of the store, which must exist
+func (s *LocalStore) coll() (*localCollection, error) {
+	c, err := s.load(s.collection)
+	if err != nil {
+		return nil, err
+	}
+	if c == nil {
+		return nil, fmt.Errorf("collection %s not found", s.collection)
+	}
+	return c, nil
+}
+
+// CreateCollection creates a new collection
+func (s *LocalStore) CreateCollection(ctx context.Context, name string, dimension int) error {
+	if dimension <= 0 {
+		return fmt.Errorf("invalid vector dimension %d", dimension)
+	}
+	path := s.collectionPath(name)
+	localCollections.Lock()
+	defer localCollections.Unlock()
+	if _, ok := localCollections.open[path]; ok {
+		return nil
+	}
+	if _, err := os.Stat(path); err == nil {
+		return nil // Collection already exists
+	}
+
+	c := &localCollection{path: path, dim: dimension, points: make(map[string]*localPoint)}
+	if err := c.rewrite(); err != nil {
+		return fmt.Errorf("failed to create collection: %w", err)
+	}
+	localCollections.open[path] = c
+	return nil
+}
+
+// CollectionExists checks if a collection exists in the directory
+func (s *LocalStore) CollectionExists(ctx context.Context, name string) (bool, error) {
+	c, err := s.load(name)
+	return c != nil, err
+}
+
+// GetCollectionPointCount returns the number of points (documents) in a collection
+func (s *LocalStore) GetCollectionPointCount(ctx context.Context, name string) (uint64, error) {
+	c, err := s.load(name)
+	if err != nil || c == nil {
+		return 0, err
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return uint64(len(c.points)), nil
+}
+
+// DeleteCollection deletes an entire collection (DANGEROUS: removes all points)
+func (s *LocalStore) DeleteCollection(ctx context.Context, name string) error {
+	path := s.collectionPath(name)
+	localCollections.Lock()
+	defer localCollections.Unlock()
+	if c, ok := localCollections.open[path]; ok {
+		c.mu.Lock()
+		c.file.Close()
+		c.points = make(map[string]*localPoint)
+		c.mu.Unlock()
+		delete(localCollections.open, path)
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to delete collection %s: %w", name, err)
+	}
+	return nil
+}
+
+// Upsert inserts or updates vectors
+func (s *LocalStore) Upsert(ctx context.Context, id string, vector []float64, payload map[string]interface{}) error {
+	if len(vector) == 0 {
+		return fmt.Errorf("upsert called with an empty vector for id=%s", id)
+	}
+	c, err := s.coll()
+	if err != nil {
+		return err
+	}
+	if len(vector) != c.dim {
+		return fmt.Errorf("failed to upsert point: vector dimension %d, collection has %d", len(vector), c.dim)
+	}
+
+	p := &localPoint{vector: normalize(vector), payload: make(map[string]string, len(payload))}
+	for key, val := range payload {
+		p.payload[key] = fmt.Sprintf("%v", val)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.append(localPut, id, p.payload, p.vector); err != nil {
+		return fmt.Errorf("failed to upsert point: %w", err)
+	}
+	c.points[id] = p
+	return nil
+}
+
+// Search searches for similar vectors
+func (s *LocalStore) Search(ctx context.Context, vector []float64, limit int) ([]SearchResult, error) {
+	return s.search(vector, limit, nil)
+}
+
+// Query searches the points opts.Filter selects, by similarity when
+// opts.Vector is set and by ID otherwise
+func (s *LocalStore) Query(ctx context.Context, opts memory.SearchOptions) ([]SearchResult, error) {
+	keep := func(payload map[string]string) bool {
+		return opts.Filter.Match(localLookup(payload))
+	}
+	if opts.Similarity() {
+		return s.search(opts.Vector, opts.Limit, keep)
+	}
+	return s.match(opts.LimitOrDefault(), keep)
+}
+
+// search scores the searchable points accepted by keep and returns the
+// limit best ones
+func (s *LocalStore) search(vector []float64, limit int, keep func(map[string]string) bool) ([]SearchResult, error) {
+	c, err := s.coll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to search: %w", err)
+	}
+	if len(vector) != c.dim {
+		return nil, fmt.Errorf("failed to search: vector dimension %d, collection has %d", len(vector), c.dim)
+	}
+	if limit <= 0 {
+		limit = memory.DefaultSearchLimit
+	}
+	query := normalize(vector)
+
+	c.mu.RLock()
+	results := make([]SearchResult, 0, limit)
+	for id, p := range c.points {
+		if !s.searchable(p.payload) || (keep != nil && !keep(p.payload)) {
+			continue
+		}
+		var score float64
+		for i, v := range p.vector {
+			score += float64(v) * float64(query[i])
+		}
+		results = append(results, SearchResult{ID: id, Score: score, Payload: readLocalPayload(p.payload)})
+	}
+	c.mu.RUnlock()
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].ID < results[j].ID
+	})
+	if len(results) > limit {
+		results = results[:limit]
+	}
+	return results, nil
+}
+
+// match returns up to limit searchable points accepted by keep, by ID
+func (s *LocalStore) match(limit int, keep func(map[string]string) bool) ([]SearchResult, error) {
+	c, err := s.coll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to scroll: %w", err)
+	}
+	c.mu.RLock()
+	var results []SearchResult
+	for id, p := range c.points {
+		if s.searchable(p.payload) && keep(p.payload) {
+			results = append(results, SearchResult{ID: id, Score: 1.0, Payload: readLocalPayload(p.payload)})
+		}
+	}
+	c.mu.RUnlock()
+
+	sort.Slice(results, func(i, j int) bool { return results[i].ID < results[j].ID })
+	if len(results) > limit {
+		results = results[:limit]
+	}
+	return results, nil
+}
+
+// GetByID returns the point with the given ID, or nil when it does not
+// exist. Like in Qdrant, tombstoned points are still returned.
+func (s *LocalStore) GetByID(ctx context.Context, id string) (*SearchResult, error) {
+	c, err := s.coll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get point: %w", err)
+	}
+	c.mu.RLock()
+	p, ok := c.points[id]
+	c.mu.RUnlock()
+	if !ok || !s.visible(p.payload) {
+		return nil, nil
+	}
+	return &SearchResult{ID: id, Score: 1.0, Payload: readLocalPayload(p.payload)}, nil
+}
+
+// ScrollVectors calls fn with every point of the collection, vector
+// included, in ID order. It stops at the first error of fn.
+func (s *LocalStore) ScrollVectors(ctx context.Context, batchSize int, fn func(VectorPoint) error) error {
+	c, err := s.coll()
+	if err != nil {
+		return fmt.Errorf("failed to scroll vectors: %w", err)
+	}
+	c.mu.RLock()
+	points := make([]VectorPoint, 0, len(c.points))
+	for id, p := range c.points {
+		points = append(points, VectorPoint{ID: id, Vector: p.vector, Payload: readLocalPayload(p.payload)})
+	}
+	c.mu.RUnlock()
+
+	sort.Slice(points, func(i, j int) bool { return points[i].ID < points[j].ID })
+	for _, p := range points {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := fn(p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Count returns how many searchable points match filter
+func (s *LocalStore) Count(ctx context.Context, filter memory.Filter) (uint64, error) {
+	c, err := s.coll()
+	if err != nil {
+		return 0, fmt.Errorf("failed to count points: %w", err)
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	var n uint64
+	for _, p := range c.points {
+		if s.searchable(p.payload) && filter.Match(localLookup(p.payload)) {
+			n++
+		}
+	}
+	return n, nil
+}
+
+// Scroll returns up to limit searchable points matching filter, in ID
+// order, and the cursor of the next page: the ID of its first point, empty
+// after the last page
+func (s *LocalStore) Scroll(ctx context.Context, filter memory.Filter, cursor string, limit int) ([]SearchResult, string, error) {
+	if limit <= 0 {
+		limit = 200
+	}
+	c, err := s.coll()
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to scroll: %w", err)
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	var ids []string
+	for id, p := range c.points {
+		if id >= cursor && s.searchable(p.payload) && filter.Match(localLookup(p.payload)) {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+
+	next := ""
+	if len(ids) > limit {
+		next = ids[limit]
+		ids = ids[:limit]
+	}
+	results := make([]SearchResult, len(ids))
+	for i, id := range ids {
+		results[i] = SearchResult{ID: id, Score: 1.0, Payload: readLocalPayload(c.points[id].payload)}
+	}
+	return results, next, nil
+}
+
+// Aggregate counts the searchable points matching filter per value of the
+// payload field groupBy
+func (s *LocalStore) Aggregate(ctx context.Context, filter memory.Filter, groupBy string) (map[string]uint64, error) {
+	c, err := s.coll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate by %s: %w", groupBy, err)
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	counts := make(map[string]uint64)
+	for _, p := range c.points {
+		if v, ok := p.payload[groupBy]; ok && s.searchable(p.payload) && filter.Match(localLookup(p.payload)) {
+			counts[v]++
+		}
+	}
+	return counts, nil
+}
+
+// localLookup reads the fields of a stored payload for memory.Filter
+func localLookup(payload map[string]string) func(key string) (string, bool) {
+	return func(key string) (string, bool) {
+		v, ok := payload[key]
+		return v, ok
+	}
+}
+
+// Delete deletes a vector by ID
+func (s *LocalStore) Delete(ctx context.Context, id string) error {
+	return s.deleteWhere(func(pid string, _ map[string]string) bool { return pid == id })
+}
+
+// DeleteByFilter deletes vectors whose payload field key equals value
+func (s *LocalStore) DeleteByFilter(ctx context.Context, key, value string) error {
+	return s.deleteWhere(func(_ string, payload map[string]string) bool {
+		v, ok := payload[key]
+		return ok && v == value
+	})
+}
+
+// SetVisibleGeneration limits searches of the store to points of
+// generation <= gen and to points written before generations existed
+func (s *LocalStore) SetVisibleGeneration(gen uint64) {
+	s.visibleGen.Store(gen)
+	s.genFilter.Store(true)
+}
+
+// VisibleGeneration returns the generation set with SetVisibleGeneration
+func (s *LocalStore) VisibleGeneration() (uint64, bool) {
+	return s.visibleGen.Load(), s.genFilter.Load()
+}
+
+// DeleteFileExceptGeneration deletes the points of a file written by other
+// generations than gen
+func (s *LocalStore) DeleteFileExceptGeneration(ctx context.Context, file string, gen uint64) error {
+	return s.deleteWhere(func(_ string, payload map[string]string) bool {
+		return payload["file"] == file && !isGeneration(payload, gen)
+	})
+}
+
+// DeleteFileGeneration deletes the points of a file written by generation gen
+func (s *LocalStore) DeleteFileGeneration(ctx context.Context, file string, gen uint64) error {
+	return s.deleteWhere(func(_ string, payload map[string]string) bool {
+		return payload["file"] == file && isGeneration(payload, gen)
+	})
+}
+
+// DeleteNewerGenerations deletes the points of generations above gen
+func (s *LocalStore) DeleteNewerGenerations(ctx context.Context, gen uint64) error {
+	return s.deleteWhere(func(_ string, payload map[string]string) bool {
+		n, ok := payloadUint(payload, GenerationKey)
+		return ok && n > gen
+	})
+}
+
+// TombstoneFileExceptGeneration tombstones the points of a file written by
+// other generations than gen. Points already tombstoned keep their time.
+func (s *LocalStore) TombstoneFileExceptGeneration(ctx context.Context, file string, gen uint64, at time.Time) error {
+	c, err := s.coll()
+	if err != nil {
+		return fmt.Errorf("failed to tombstone points: %w", err)
+	}
+	set := map[string]string{TombstoneKey: strconv.FormatInt(at.Unix(), 10)}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for id, p := range c.points {
+		if p.payload["file"] != file || p.payload[TombstoneKey] != "" || isGeneration(p.payload, gen) {
+			continue
+		}
+		if err := c.append(localSet, id, set, nil); err != nil {
+			return fmt.Errorf("failed to tombstone points: %w", err)
+		}
+		c.points[id] = p.with(set)
+	}
+	return nil
+}
+
+// PurgeTombstones deletes the points tombstoned before t
+func (s *LocalStore) PurgeTombstones(ctx context.Context, before time.Time) error {
+	return s.deleteWhere(func(_ string, payload map[string]string) bool {
+		at, err := strconv.ParseInt(payload[TombstoneKey], 10, 64)
+		return err == nil && at < before.Unix()
+	})
+}
+
+// Close releases the store. Collections stay loaded for the other stores
+// of the process; every write is already in the file.
+func (s *LocalStore) Close() error {
+	return nil
+}
+
+// deleteWhere deletes the points matched by match
+func (s *LocalStore) deleteWhere(match func(id string, payload map[string]string) bool) error {
+	c, err := s.coll()
+	if err != nil {
+		return fmt.Errorf("failed to delete points: %w", err)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for id, p := range c.points {
+		if !match(id, p.payload) {
+			continue
+		}
+		if err := c.append(localDelete, id, nil, nil); err != nil {
+			return fmt.Errorf("failed to delete points: %w", err)
+		}
+		delete(c.points, id)
+	}
+	return nil
+}
+
+// searchable reports whether searches see a point: not tombstoned and of a
+// visible generation
+func (s *LocalStore) searchable(payload map[string]string) bool {
+	return payload[TombstoneKey] == "" && s.visible(payload) && !s.hidden.has(payload["file"])
+}
+
+// visible reports whether a point belongs to a visible generation
+func (s *LocalStore) visible(payload map[string]string) bool {
+	gen, ok := s.VisibleGeneration()
+	if !ok {
+		return true
+	}
+	n, ok := payloadUint(payload, GenerationKey)
+	return !ok || n <= gen
+}
+
+// isGeneration reports whether a point was written by generation gen
+func isGeneration(payload map[string]string, gen uint64) bool {
+	n, ok := payloadUint(payload, GenerationKey)
+	return ok && n == gen
+}
+
+// payloadUint returns a numeric payload field
+func payloadUint(payload map[string]string, key string) (uint64, bool) {
+	n, err := strconv.ParseUint(payload[key], 10, 64)
+	return n, err == nil
+}
+
+// readLocalPayload copies a stored payload to the form searches return
+func readLocalPayload(payload map[string]string) map[string]interface{} {
+	out := make(map[string]interface{}, len(payload))
+	for key, val := range payload {
+		out[key] = val
+	}
+	return out
+}
+
+// with returns a copy of the point with the fields of set
+func (p *localPoint) with(set map[string]string) *localPoint {
+	payload := make(map[string]string, len(p.payload)+len(set))
+	for key, val := range p.payload {
+		payload[key] = val
+	}
+	for key, val := range set {
+		payload[key] = val
+	}
+	return &localPoint{vector: p.vector, payload: payload}
+}
+
+// normalize returns the vector scaled to unit length, as float32
+func normalize(vector []float64) []float32 {
+	var sum float64
+	for _, v := range vector {
+		sum += v * v
+	}
+	norm := math.Sqrt(sum)
+	if norm == 0 {
+		norm = 1
+	}
+	out := make([]float32, len(vector))
+	for i, v := range vector {
+		out[i] = float32(v / norm)
+	}
+	return out
+}
+
+// openLocalCollection reads a collection file, or returns nil when it does
+// not exist. A record cut short by a crash is dropped, and files holding
+// mostly replaced records are compacted.
+func openLocalCollection(path string) (*localCollection, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open collection file: %w", err)
+	}
+	defer f.Close()
+
+	r := bufio.NewReader(f)
+	magic := make([]byte, len(localFileMagic))
+	if _, err := io.ReadFull(r, magic); err != nil || string(magic) != localFileMagic {
+		return nil, fmt.Errorf("%s is not a ragcode vector file", path)
+	}
+	dim, err := binary.ReadUvarint(r)
+	if err != nil || dim == 0 {
+		return nil, fmt.Errorf("%s: invalid vector dimension", path)
+	}
+
+	c := &localCollection{path: path, dim: int(dim), points: make(map[string]*localPoint)}
+	records := 0
+	for {
+		op, id, payload, vector, err := readLocalRecord(r)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			log.Printf("⚠️  %s: dropping the records after #%d: %v", path, records, err)
+			break
+		}
+		records++
+		switch op {
+		case localPut:
+			c.points[id] = &localPoint{vector: vector, payload: payload}
+		case localDelete:
+			delete(c.points, id)
+		case localSet:
+			if p, ok := c.points[id]; ok {
+				c.points[id] = p.with(payload)
+			}
+		}
+	}
+
+	// Rewriting drops the replaced records and any truncated one
+	if err := c.rewrite(); err != nil {
+		return nil, fmt.Errorf("failed to compact collection file: %w", err)
+	}
+	return c, nil
+}
+
+// rewrite writes the points of the collection to a new file that replaces
+// the current one, and opens it for appending
+func (c *localCollection) rewrite() error {
+	tmp := c.path + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	w.WriteString(localFileMagic)
+	w.Write(binary.AppendUvarint(nil, uint64(c.dim)))
+	for id, p := range c.points {
+		w.Write(encodeLocalRecord(localPut, id, p.payload, p.vector))
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return err
+	}
+	f.Close()
+	if err := os.Rename(tmp, c.path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+
+	if c.file != nil {
+		c.file.Close()
+	}
+	c.file, err = os.OpenFile(c.path, os.O_WRONLY|os.O_APPEND, 0644)
+	return err
+}
+
+// append writes a record at the end of the collection file. The caller
+// holds c.mu.
+func (c *localCollection) append(op byte, id string, payload map[string]string, vector []float32) error {
+	if c.file == nil {
+		return fmt.Errorf("collection file %s is closed", c.path)
+	}
+	_, err := c.file.Write(encodeLocalRecord(op, id, payload, vector))
+	return err
+}
+
+// encodeLocalRecord encodes a record: the operation, the point ID, the
+// payload as JSON and the vector as little-endian float32, each prefixed by
+// its length
+func encodeLocalRecord(op byte, id string, payload map[string]string, vector []float32) []byte {
+	var fields []byte
+	if len(payload) > 0 {
+		fields, _ = json.Marshal(payload)
+	}
+	buf := make([]byte, 0, 1+3*binary.MaxVarintLen64+len(id)+len(fields)+4*len(vector))
+	buf = append(buf, op)
+	buf = binary.AppendUvarint(buf, uint64(len(id)))
+	buf = append(buf, id...)
+	buf = binary.AppendUvarint(buf, uint64(len(fields)))
+	buf = append(buf, fields...)
+	buf = binary.AppendUvarint(buf, uint64(len(vector)))
+	for _, v := range vector {
+		buf = binary.LittleEndian.AppendUint32(buf, math.Float32bits(v))
+	}
+	return buf
+}
+
+// readLocalRecord decodes the next record. It returns io.EOF at the end of
+// the file and another error for a truncated or corrupt record.
+func readLocalRecord(r *bufio.Reader) (op byte, id string, payload map[string]string, vector []float32, err error) {
+	op, err = r.ReadByte()
+	if err != nil {
+		return 0, "", nil, nil, err
+	}
+	if op != localPut && op != localDelete && op != localSet {
+		return 0, "", nil, nil, fmt.Errorf("unknown record type %d", op)
+	}
+	field := func() ([]byte, error) {
+		n, err := binary.ReadUvarint(r)
+		if err != nil {
+			return nil, unexpectedEOF(err)
+		}
+		if n > 1<<30 {
+			return nil, fmt.Errorf("record field of %d bytes", n)
+		}
+		b := make([]byte, n)
+		if _, err := io.ReadFull(r, b); err != nil {
+			return nil, unexpectedEOF(err)
+		}
+		return b, nil
+	}
+
+	idBytes, err := field()
+	if err != nil {
+		return 0, "", nil, nil, err
+	}
+	fields, err := field()
+	if err != nil {
+		return 0, "", nil, nil, err
+	}
+	if len(fields) > 0 {
+		if err := json.NewDecoder(bytes.NewReader(fields)).Decode(&payload); err != nil {
+			return 0, "", nil, nil, fmt.Errorf("invalid payload: %w", err)
+		}
+	}
+	if payload == nil {
+		payload = make(map[string]string)
+	}
+
+	n, err := binary.ReadUvarint(r)
+	if err != nil {
+		return 0, "", nil, nil, unexpectedEOF(err)
+	}
+	if n > 1<<20 {
+		return 0, "", nil, nil, fmt.Errorf("vector of %d dimensions", n)
+	}
+	vector = make([]float32, n)
+	var word [4]byte
+	for i := range vector {
+		if _, err := io.ReadFull(r, word[:]); err != nil {
+			return 0, "", nil, nil, unexpectedEOF(err)
+		}
+		vector[i] = math.Float32frombits(binary.LittleEndian.Uint32(word[:]))
+	}
+	return op, string(idBytes), payload, vector, nil
+}
+
+// unexpectedEOF turns an end of file inside a record into an error
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
@@ -0,0 +1,376 @@
+package storage
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/doITmagic/rag-code-mcp/internal/config"
+	"github.com/doITmagic/rag-code-mcp/internal/memory"
+)
+
+// newTestLocalStore returns a store on a fresh collection of dimension 2
+func newTestLocalStore(t *testing.T) *LocalStore {
+	t.Helper()
+	s, err := NewLocalStore(LocalConfig{Dir: t.TempDir(), Collection: "test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.CreateCollection(context.Background(), "test", 2); err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+// reopen forgets the loaded collections so the next call reads the files
+func reopen(t *testing.T, s *LocalStore) *LocalStore {
+	t.Helper()
+	localCollections.Lock()
+	for path, c := range localCollections.open {
+		c.file.Close()
+		delete(localCollections.open, path)
+	}
+	localCollections.Unlock()
+	fresh, err := NewLocalStore(LocalConfig{Dir: s.dir, Collection: s.collection})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return fresh
+}
+
+func ids(results []SearchResult) []string {
+	out := make([]string, len(results))
+	for i, r := range results {
+		out[i] = r.ID
+	}
+	return out
+}
+
+func TestLocalStoreSearch(t *testing.T) {
+	ctx := context.Background()
+	s := newTestLocalStore(t)
+	points := map[string][]float64{"east": {1, 0}, "north": {0, 3}, "northeast": {2, 2}}
+	for id, v := range points {
+		if err := s.Upsert(ctx, id, v, map[string]interface{}{"name": id, "start_line": 4}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.Upsert(ctx, "readme", []float64{1, 0.1}, map[string]interface{}{"chunk_type": "markdown"}); err != nil {
+		t.Fatal(err)
+	}
+
+	results, err := s.Search(ctx, []float64{1, 0.2}, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := ids(results); len(got) != 2 || got[0] != "readme" || got[1] != "east" {
+		t.Errorf("Search = %v, want [readme east]", got)
+	}
+	if results[1].Payload["start_line"] != "4" {
+		t.Errorf("payload values are read back as strings, got %#v", results[1].Payload["start_line"])
+	}
+
+	results, err = s.Query(ctx, memory.SearchOptions{Vector: []float64{1, 0.2}, Filter: memory.CodeOnly(), Limit: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := ids(results); len(got) != 3 || got[0] != "east" || got[1] != "northeast" {
+		t.Errorf("code only Query = %v, want markdown excluded", got)
+	}
+
+	results, err = s.Query(ctx, memory.SearchOptions{Filter: memory.Filter{Must: map[string][]string{"name": {"north", "east"}}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := ids(results); len(got) != 2 || got[0] != "east" || got[1] != "north" || results[0].Score != 1 {
+		t.Errorf("Query without a vector = %v, want the exact matches by ID", got)
+	}
+
+	if _, err := s.Search(ctx, []float64{1, 0, 0}, 1); err == nil {
+		t.Error("searching with a vector of another dimension should fail")
+	}
+}
+
+func TestLocalStorePersistence(t *testing.T) {
+	ctx := context.Background()
+	s := newTestLocalStore(t)
+	for _, id := range []string{"a", "b", "c"} {
+		if err := s.Upsert(ctx, id, []float64{1, 1}, map[string]interface{}{"file": id + ".go"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.Upsert(ctx, "a", []float64{0, 1}, map[string]interface{}{"file": "a.go", "name": "A"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.DeleteByFilter(ctx, "file", "b.go"); err != nil {
+		t.Fatal(err)
+	}
+
+	s = reopen(t, s)
+	if n, err := s.GetCollectionPointCount(ctx, "test"); err != nil || n != 2 {
+		t.Fatalf("point count after reload = %d, %v; want 2", n, err)
+	}
+	got, err := s.GetByID(ctx, "a")
+	if err != nil || got == nil || got.Payload["name"] != "A" {
+		t.Fatalf("GetByID(a) after reload = %+v, %v; want the last upsert", got, err)
+	}
+
+	var vectors int
+	err = s.ScrollVectors(ctx, 1, func(p VectorPoint) error {
+		if p.ID == "a" && (p.Vector[0] != 0 || p.Vector[1] != 1) {
+			t.Errorf("vector of a = %v, want [0 1]", p.Vector)
+		}
+		vectors++
+		return nil
+	})
+	if err != nil || vectors != 2 {
+		t.Errorf("ScrollVectors = %d points, %v; want 2", vectors, err)
+	}
+
+	if err := s.DeleteCollection(ctx, "test"); err != nil {
+		t.Fatal(err)
+	}
+	if exists, _ := s.CollectionExists(ctx, "test"); exists {
+		t.Error("collection still exists after DeleteCollection")
+	}
+}
+
+func TestLocalStoreTruncatedRecord(t *testing.T) {
+	ctx := context.Background()
+	s := newTestLocalStore(t)
+	for _, id := range []string{"a", "b"} {
+		if err := s.Upsert(ctx, id, []float64{1, 0}, map[string]interface{}{"file": "x.go"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// A crash in the middle of the last write
+	path := s.collectionPath("test")
+	st, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Truncate(path, st.Size()-3); err != nil {
+		t.Fatal(err)
+	}
+
+	s = reopen(t, s)
+	if n, err := s.GetCollectionPointCount(ctx, "test"); err != nil || n != 1 {
+		t.Fatalf("point count = %d, %v; want the point written before the crash", n, err)
+	}
+	if err := s.Upsert(ctx, "c", []float64{0, 1}, nil); err != nil {
+		t.Fatal(err)
+	}
+	s = reopen(t, s)
+	if n, _ := s.GetCollectionPointCount(ctx, "test"); n != 2 {
+		t.Errorf("point count = %d, want 2 after writing past the dropped record", n)
+	}
+}
+
+func TestLocalStoreGenerations(t *testing.T) {
+	ctx := context.Background()
+	s := newTestLocalStore(t)
+	put := func(id, file string, gen uint64) {
+		t.Helper()
+		payload := map[string]interface{}{"file": file, "name": "Run"}
+		if gen > 0 {
+			payload[GenerationKey] = gen
+		}
+		if err := s.Upsert(ctx, id, []float64{1, 0}, payload); err != nil {
+			t.Fatal(err)
+		}
+	}
+	put("legacy", "a.go", 0)
+	put("a1", "a.go", 1)
+	put("a2", "a.go", 2)
+	put("b3", "b.go", 3)
+
+	s.SetVisibleGeneration(1)
+	results, _ := s.Search(ctx, []float64{1, 0}, 10)
+	if got := ids(results); len(got) != 2 || got[0] != "a1" || got[1] != "legacy" {
+		t.Errorf("visible generation 1: Search = %v, want [a1 legacy]", got)
+	}
+	if got, _ := s.GetByID(ctx, "a2"); got != nil {
+		t.Error("GetByID returned a point of a generation not visible yet")
+	}
+
+	if err := s.DeleteNewerGenerations(ctx, 2); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := s.GetByID(ctx, "b3"); got != nil {
+		t.Error("DeleteNewerGenerations kept generation 3")
+	}
+
+	at := time.Unix(1000, 0)
+	if err := s.TombstoneFileExceptGeneration(ctx, "a.go", 2, at); err != nil {
+		t.Fatal(err)
+	}
+	s.SetVisibleGeneration(2)
+	results, _ = s.Query(ctx, memory.SearchOptions{Filter: memory.Filter{Must: map[string][]string{"name": {"Run"}}}})
+	if got := ids(results); len(got) != 1 || got[0] != "a2" {
+		t.Errorf("after tombstoning: Query = %v, want [a2]", got)
+	}
+	old, _ := s.GetByID(ctx, "a1")
+	if old == nil || !Tombstoned(old.Payload) || old.Payload[TombstoneKey] != "1000" {
+		t.Errorf("GetByID should still return tombstoned points, got %+v", old)
+	}
+
+	s = reopen(t, s)
+	if err := s.PurgeTombstones(ctx, at.Add(time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	results, _ = s.Query(ctx, memory.SearchOptions{Filter: memory.Filter{Must: map[string][]string{"file": {"a.go"}}}})
+	if got := ids(results); len(got) != 1 || got[0] != "a2" {
+		t.Errorf("after purging: Query by file = %v, want [a2]", got)
+	}
+
+	if err := s.DeleteFileExceptGeneration(ctx, "a.go", 1); err != nil {
+		t.Fatal(err)
+	}
+	if n, _ := s.GetCollectionPointCount(ctx, "test"); n != 0 {
+		t.Errorf("point count = %d after deleting the other generations of a.go, want 0", n)
+	}
+}
+
+func TestLocalStoreListing(t *testing.T) {
+	ctx := context.Background()
+	s := newTestLocalStore(t)
+	points := []struct{ id, pkg, typ string }{
+		{"1", "billing", "function"}, {"2", "billing", "method"}, {"3", "billing", "type"},
+		{"4", "orders", "function"}, {"5", "orders", "function"},
+	}
+	for _, p := range points {
+		if err := s.Upsert(ctx, p.id, []float64{1, 0}, map[string]interface{}{"package": p.pkg, "type": p.typ, "file": p.pkg + ".go"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.Upsert(ctx, "6", []float64{0, 1}, map[string]interface{}{"chunk_type": "markdown", "package": "billing"}); err != nil {
+		t.Fatal(err)
+	}
+	// Tombstoned points are not listed, like they are not searched
+	if err := s.Upsert(ctx, "7", []float64{0, 1}, map[string]interface{}{"package": "billing", TombstoneKey: "1"}); err != nil {
+		t.Fatal(err)
+	}
+
+	code := memory.Filter{MustNot: map[string][]string{"chunk_type": {"markdown"}}}
+	if n, err := s.Count(ctx, code); err != nil || n != 5 {
+		t.Errorf("Count(code) = %d, %v; want 5", n, err)
+	}
+	billing := memory.Filter{Must: map[string][]string{"package": {"billing"}, "type": {"function", "method"}}}
+	if n, err := s.Count(ctx, billing); err != nil || n != 2 {
+		t.Errorf("Count(billing functions) = %d, %v; want 2", n, err)
+	}
+
+	var listed []string
+	cursor := ""
+	for pages := 0; ; pages++ {
+		page, next, err := s.Scroll(ctx, code, cursor, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		listed = append(listed, ids(page)...)
+		if next == "" {
+			break
+		}
+		if pages > 5 {
+			t.Fatal("Scroll does not end")
+		}
+		cursor = next
+	}
+	if strings.Join(listed, ",") != "1,2,3,4,5" {
+		t.Errorf("Scroll pages = %v, want 1..5 in order", listed)
+	}
+
+	counts, err := s.Aggregate(ctx, code, "package")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(counts) != 2 || counts["billing"] != 3 || counts["orders"] != 2 {
+		t.Errorf("Aggregate(package) = %v, want billing:3 orders:2", counts)
+	}
+}
+
+func TestOpenProvider(t *testing.T) {
+	s, err := Open(config.VectorDBConfig{Provider: "local", Path: t.TempDir()}, "ragcode-x/go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	local, ok := s.(*LocalStore)
+	if !ok {
+		t.Fatalf("Open(local) = %T, want *LocalStore", s)
+	}
+	if got := filepath.Base(local.collectionPath("ragcode-x/go")); got != "ragcode-x_go.vec" {
+		t.Errorf("collection file = %s, want ragcode-x_go.vec", got)
+	}
+	if _, err := Open(config.VectorDBConfig{Provider: "chromadb"}, "x"); err == nil {
+		t.Error("Open with an unknown provider should fail")
+	}
+}
+
+func TestLocalStoreHiddenFiles(t *testing.T) {
+	ctx := context.Background()
+	s := newTestLocalStore(t)
+	for id, file := range map[string]string{"a": "a.go", "b": "b.go"} {
+		if err := s.Upsert(ctx, id, []float64{1, 0}, map[string]interface{}{"file": file}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	s.SetHiddenFiles([]string{"a.go"})
+	results, _ := s.Search(ctx, []float64{1, 0}, 10)
+	if got := ids(results); len(got) != 1 || got[0] != "b" {
+		t.Errorf("Search = %v, want [b] with a.go hidden", got)
+	}
+	if n, _ := s.Count(ctx, memory.Filter{}); n != 1 {
+		t.Errorf("Count = %d, want 1", n)
+	}
+	if got, _ := s.GetByID(ctx, "a"); got == nil {
+		t.Error("GetByID should still resolve points of hidden files")
+	}
+
+	s.SetHiddenFiles(nil)
+	if n, _ := s.Count(ctx, memory.Filter{}); n != 2 || len(s.HiddenFiles()) != 0 {
+		t.Errorf("Count = %d after showing every file, want 2", n)
+	}
+}
+
+func TestLocalStoreSwapCollection(t *testing.T) {
+	ctx := context.Background()
+	s := newTestLocalStore(t)
+	if err := s.Upsert(ctx, "old", []float64{1, 0}, map[string]interface{}{"name": "old"}); err != nil {
+		t.Fatal(err)
+	}
+	next, err := NewLocalStore(LocalConfig{Dir: s.dir, Collection: "test-v2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := next.CreateCollection(ctx, "test-v2", 3); err != nil {
+		t.Fatal(err)
+	}
+	if err := next.Upsert(ctx, "new", []float64{0, 1, 0}, map[string]interface{}{"name": "new"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.SwapCollection(ctx, "test", "test-v2"); err != nil {
+		t.Fatal(err)
+	}
+	if dim, err := s.CollectionDimension(ctx, "test"); err != nil || dim != 3 {
+		t.Fatalf("dimension = %d, %v; want 3", dim, err)
+	}
+	results, err := s.Search(ctx, []float64{0, 1, 0}, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := ids(results); len(got) != 1 || got[0] != "new" {
+		t.Errorf("results = %v, want the points of the replacement", got)
+	}
+	if exists, _ := s.CollectionExists(ctx, "test-v2"); exists {
+		t.Error("replacement collection still exists under its own name")
+	}
+	if err := s.SwapCollection(ctx, "test", "missing"); err == nil {
+		t.Error("swap with a missing collection should fail")
+	}
+}
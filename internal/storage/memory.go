@@ -0,0 +1,197 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/doITmagic/rag-code-mcp/internal/memory"
+)
+
+// VectorStoreMemory implements memory.LongTermMemory on a VectorStore
+type VectorStoreMemory struct {
+	store VectorStore
+}
+
+// NewVectorStoreMemory creates a long-term memory backed by a vector store
+func NewVectorStoreMemory(store VectorStore) *VectorStoreMemory {
+	return &VectorStoreMemory{
+		store: store,
+	}
+}
+
+// Store stores a document with its embedding
+func (m *VectorStoreMemory) Store(ctx context.Context, doc memory.Document) error {
+	if doc.ID == "" {
+		return fmt.Errorf("document ID is required")
+	}
+
+	if len(doc.Embedding) == 0 {
+		return fmt.Errorf("document embedding is required")
+	}
+
+	// Prepare payload
+	payload := make(map[string]interface{})
+	payload["content"] = doc.Content
+	payload[SchemaVersionKey] = SchemaVersion
+
+	// Add metadata to payload
+	for key, val := range doc.Metadata {
+		payload[key] = val
+	}
+
+	// Store in the vector store
+	if err := m.store.Upsert(ctx, doc.ID, doc.Embedding, payload); err != nil {
+		return fmt.Errorf("failed to store document: %w", err)
+	}
+
+	return nil
+}
+
+// Search searches for similar documents
+func (m *VectorStoreMemory) Search(ctx context.Context, query []float64, limit int) ([]memory.Document, error) {
+	if len(query) == 0 {
+		return nil, fmt.Errorf("query embedding is required")
+	}
+
+	// Search in the vector store
+	results, err := m.store.Search(ctx, query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search: %w", err)
+	}
+
+	return convertSearchResultsToDocuments(results), nil
+}
+
+// Query searches the documents opts.Filter selects, pushing the filter
+// down to the vector store
+func (m *VectorStoreMemory) Query(ctx context.Context, opts memory.SearchOptions) ([]memory.Document, error) {
+	results, err := m.store.Query(ctx, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query: %w", err)
+	}
+	return convertSearchResultsToDocuments(results), nil
+}
+
+// GetByID returns the document stored under id. The boolean is false when no
+// such document exists.
+func (m *VectorStoreMemory) GetByID(ctx context.Context, id string) (memory.Document, bool, error) {
+	result, err := m.store.GetByID(ctx, id)
+	if err != nil {
+		return memory.Document{}, false, fmt.Errorf("failed to get document by id: %w", err)
+	}
+	if result == nil {
+		return memory.Document{}, false, nil
+	}
+	return convertSearchResultsToDocuments([]SearchResult{*result})[0], true, nil
+}
+
+// Count returns how many documents match filter
+func (m *VectorStoreMemory) Count(ctx context.Context, filter memory.Filter) (uint64, error) {
+	n, err := m.store.Count(ctx, filter)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count documents: %w", err)
+	}
+	return n, nil
+}
+
+// Scroll lists the documents matching filter, a page at a time
+func (m *VectorStoreMemory) Scroll(ctx context.Context, filter memory.Filter, cursor string, limit int) ([]memory.Document, string, error) {
+	results, next, err := m.store.Scroll(ctx, filter, cursor, limit)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to scroll documents: %w", err)
+	}
+	return convertSearchResultsToDocuments(results), next, nil
+}
+
+// Aggregate counts the documents matching filter per value of groupBy
+func (m *VectorStoreMemory) Aggregate(ctx context.Context, filter memory.Filter, groupBy string) (map[string]uint64, error) {
+	counts, err := m.store.Aggregate(ctx, filter, groupBy)
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate documents: %w", err)
+	}
+	return counts, nil
+}
+
+// convertSearchResultsToDocuments converts search results to documents,
+// upgrading payloads of older schemas on the way (schema.go)
+func convertSearchResultsToDocuments(results []SearchResult) []memory.Document {
+	documents := make([]memory.Document, 0, len(results))
+	for _, result := range results {
+		if _, err := MigratePayload(result.Payload); err != nil {
+			log.Printf("⚠️  Point %s: %v", result.ID, err)
+		}
+		doc := memory.Document{
+			ID:       result.ID,
+			Content:  fmt.Sprintf("%v", result.Payload["content"]),
+			Metadata: make(map[string]interface{}),
+		}
+
+		// Extract metadata (skip 'content' field)
+		for key, val := range result.Payload {
+			if key != "content" {
+				doc.Metadata[key] = val
+			}
+		}
+
+		// Add score to metadata
+		doc.Metadata["score"] = result.Score
+
+		documents = append(documents, doc)
+	}
+
+	return documents
+}
+
+// Delete deletes a document by ID
+func (m *VectorStoreMemory) Delete(ctx context.Context, id string) error {
+	if err := m.store.Delete(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete document: %w", err)
+	}
+	return nil
+}
+
+// DeleteByMetadata deletes documents matching a metadata key-value pair
+func (m *VectorStoreMemory) DeleteByMetadata(ctx context.Context, key, value string) error {
+	if err := m.store.DeleteByFilter(ctx, key, value); err != nil {
+		return fmt.Errorf("failed to delete documents by metadata: %w", err)
+	}
+	return nil
+}
+
+// Clear clears all documents (not implemented for safety - use with caution)
+func (m *VectorStoreMemory) Clear(ctx context.Context) error {
+	// For safety, we don't implement collection deletion
+	// You would need to delete the collection and recreate it
+	return fmt.Errorf("clear operation not implemented for safety - please delete and recreate collection manually")
+}
+
+// CollectionExists checks if the collection exists
+func (m *VectorStoreMemory) CollectionExists(ctx context.Context, collectionName string) (bool, error) {
+	return m.store.CollectionExists(ctx, collectionName)
+}
+
+// GetCollectionPointCount returns the number of points (documents) in a collection
+func (m *VectorStoreMemory) GetCollectionPointCount(ctx context.Context, collectionName string) (uint64, error) {
+	return m.store.GetCollectionPointCount(ctx, collectionName)
+}
+
+// SetVisibleGeneration limits searches to committed index generations (see
+// VectorStore.SetVisibleGeneration)
+func (m *VectorStoreMemory) SetVisibleGeneration(gen uint64) {
+	m.store.SetVisibleGeneration(gen)
+}
+
+// SetHiddenFiles leaves the chunks of files out of searches (see
+// VectorStore.SetHiddenFiles)
+func (m *VectorStoreMemory) SetHiddenFiles(files []string) {
+	m.store.SetHiddenFiles(files)
+}
+
+// Close closes the vector store of the memory
+func (m *VectorStoreMemory) Close() error {
+	return m.store.Close()
+}
+
+// Ensure VectorStoreMemory implements memory.LongTermMemory
+var _ memory.LongTermMemory = (*VectorStoreMemory)(nil)
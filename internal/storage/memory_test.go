@@ -7,8 +7,8 @@ import (
 	"github.com/doITmagic/rag-code-mcp/internal/memory"
 )
 
-func TestQdrantLongTermMemoryStoreValidation(t *testing.T) {
-	m := &QdrantLongTermMemory{}
+func TestVectorStoreMemoryStoreValidation(t *testing.T) {
+	m := &VectorStoreMemory{}
 	ctx := context.Background()
 
 	// Missing ID
@@ -22,8 +22,8 @@ func TestQdrantLongTermMemoryStoreValidation(t *testing.T) {
 	}
 }
 
-func TestQdrantLongTermMemorySearchValidation(t *testing.T) {
-	m := &QdrantLongTermMemory{}
+func TestVectorStoreMemorySearchValidation(t *testing.T) {
+	m := &VectorStoreMemory{}
 	ctx := context.Background()
 
 	if _, err := m.Search(ctx, nil, 10); err == nil {
@@ -3,13 +3,20 @@ package storage
 import (
 	"context"
 	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"sync/atomic"
+	"time"
 
+	"github.com/doITmagic/rag-code-mcp/internal/memory"
 	"github.com/qdrant/go-client/qdrant"
 )
 
 // QdrantConfig contains Qdrant-specific configuration
 type QdrantConfig struct {
 	URL        string
+	ReadURLs   []string // Read replicas; reads use URL without them
 	APIKey     string
 	Collection string
 }
@@ -18,6 +25,21 @@ type QdrantConfig struct {
 type QdrantClient struct {
 	config QdrantConfig
 	client *qdrant.Client
+
+	// Similarity searches rotate over the replicas; everything else, and
+	// searches shortly after a write (lastWrite, unix nanoseconds), goes to
+	// client
+	readers   []*qdrant.Client
+	nextRead  atomic.Uint32
+	lastWrite atomic.Int64
+
+	// Searches see generations up to visibleGen when genFilter is set
+	// (generation.go)
+	visibleGen atomic.Uint64
+	genFilter  atomic.Bool
+
+	// Searches leave out the points of these files (hidden.go)
+	hidden hiddenFiles
 }
 
 // NewQdrantClient creates a new Qdrant client
@@ -26,9 +48,29 @@ func NewQdrantClient(config QdrantConfig) (*QdrantClient, error) {
 		return nil, fmt.Errorf("qdrant URL is required")
 	}
 
+	client, err := dialQdrant(config.URL, config.APIKey)
+	if err != nil {
+		return nil, err
+	}
+	c := &QdrantClient{
+		config: config,
+		client: client,
+	}
+	for _, url := range config.ReadURLs {
+		reader, err := dialQdrant(url, config.APIKey)
+		if err != nil {
+			c.Close()
+			return nil, fmt.Errorf("read replica %s: %w", url, err)
+		}
+		c.readers = append(c.readers, reader)
+	}
+	return c, nil
+}
+
+// dialQdrant creates the gRPC client of one Qdrant endpoint
+func dialQdrant(url, apiKey string) (*qdrant.Client, error) {
 	// Parse URL to extract host and determine if TLS is needed
 	// Expected format: http://localhost:6333 or https://host:6333
-	url := config.URL
 	useTLS := false
 
 	if len(url) > 8 && url[:8] == "https://" {
@@ -61,8 +103,8 @@ func NewQdrantClient(config QdrantConfig) (*QdrantClient, error) {
 	}
 
 	// Only set API key if it's not empty
-	if config.APIKey != "" {
-		qdrantConfig.APIKey = config.APIKey
+	if apiKey != "" {
+		qdrantConfig.APIKey = apiKey
 	}
 
 	// Create Qdrant client - SDK uses gRPC by default
@@ -70,17 +112,40 @@ func NewQdrantClient(config QdrantConfig) (*QdrantClient, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to create qdrant client: %w", err)
 	}
+	return client, nil
+}
 
-	return &QdrantClient{
-		config: config,
-		client: client,
-	}, nil
+// replicaLag is how long similarity searches stay on the primary after a
+// write, so they see it while the asynchronous replicas catch up
+const replicaLag = 5 * time.Second
+
+// markWrite records a write, see replicaLag
+func (c *QdrantClient) markWrite() {
+	c.lastWrite.Store(time.Now().UnixNano())
+}
+
+// search runs a similarity query on the next read replica, round-robin, and
+// again on the primary when the replica fails. It runs on the primary
+// without replicas and within replicaLag of a write. Lookups, counts and
+// scrolls always read the primary: replicas may not have the latest writes.
+func (c *QdrantClient) search(ctx context.Context, fn func(*qdrant.Client) error) error {
+	if len(c.readers) == 0 || time.Since(time.Unix(0, c.lastWrite.Load())) < replicaLag {
+		return fn(c.client)
+	}
+	i := int(c.nextRead.Add(1)-1) % len(c.readers)
+	err := fn(c.readers[i])
+	if err == nil || ctx.Err() != nil {
+		return err
+	}
+	log.Printf("⚠️  Qdrant read replica %s failed, searching the primary: %v", c.config.ReadURLs[i], err)
+	return fn(c.client)
 }
 
 // CreateCollection creates a new collection
 func (c *QdrantClient) CreateCollection(ctx context.Context, name string, dimension int) error {
+	defer c.markWrite()
 	// Check if collection exists
-	exists, err := c.client.CollectionExists(ctx, name)
+	exists, err := c.CollectionExists(ctx, name)
 	if err != nil {
 		return fmt.Errorf("failed to check collection existence: %w", err)
 	}
@@ -107,9 +172,15 @@ func (c *QdrantClient) CreateCollection(ctx context.Context, name string, dimens
 	return nil
 }
 
-// CollectionExists checks if a collection exists in Qdrant
+// CollectionExists checks if a collection exists in Qdrant, as a
+// collection or as an alias set by SwapCollection
 func (c *QdrantClient) CollectionExists(ctx context.Context, name string) (bool, error) {
-	return c.client.CollectionExists(ctx, name)
+	exists, err := c.client.CollectionExists(ctx, name)
+	if err != nil || exists {
+		return exists, err
+	}
+	target, err := c.aliasTarget(ctx, name)
+	return target != "", err
 }
 
 // GetCollectionPointCount returns the number of points (documents) in a collection
@@ -126,8 +197,20 @@ func (c *QdrantClient) GetCollectionPointCount(ctx context.Context, name string)
 	return collectionInfo.GetPointsCount(), nil
 }
 
-// DeleteCollection deletes an entire collection (DANGEROUS: removes all points)
+// DeleteCollection deletes an entire collection (DANGEROUS: removes all points).
+// An alias is deleted with the collection it points at.
 func (c *QdrantClient) DeleteCollection(ctx context.Context, name string) error {
+	defer c.markWrite()
+	target, err := c.aliasTarget(ctx, name)
+	if err != nil {
+		return err
+	}
+	if target != "" {
+		if err := c.client.DeleteAlias(ctx, name); err != nil {
+			return fmt.Errorf("failed to delete alias %s: %w", name, err)
+		}
+		name = target
+	}
 	if err := c.client.DeleteCollection(ctx, name); err != nil {
 		return fmt.Errorf("failed to delete collection %s: %w", name, err)
 	}
@@ -136,6 +219,7 @@ func (c *QdrantClient) DeleteCollection(ctx context.Context, name string) error
 
 // Upsert inserts or updates vectors
 func (c *QdrantClient) Upsert(ctx context.Context, id string, vector []float64, payload map[string]interface{}) error {
+	defer c.markWrite()
 	// DEBUG: Check if vector is empty
 	if len(vector) == 0 {
 		return fmt.Errorf("⚠️ UPSERT CALLED WITH EMPTY VECTOR for id=%s", id)
@@ -144,7 +228,7 @@ func (c *QdrantClient) Upsert(ctx context.Context, id string, vector []float64,
 	// Convert payload to Qdrant format
 	qdrantPayload := make(map[string]*qdrant.Value)
 	for key, val := range payload {
-		qdrantPayload[key] = qdrant.NewValueString(fmt.Sprintf("%v", val))
+		qdrantPayload[key] = payloadValue(key, val)
 	}
 
 	// Convert float64 to float32
@@ -182,185 +266,273 @@ func (c *QdrantClient) Upsert(ctx context.Context, id string, vector []float64,
 
 // Search searches for similar vectors
 func (c *QdrantClient) Search(ctx context.Context, vector []float64, limit int) ([]SearchResult, error) {
+	return c.Query(ctx, memory.SearchOptions{Vector: vector, Limit: limit})
+}
+
+// Query searches the points opts.Filter selects: the nearest to opts.Vector
+// when it is set, otherwise an exact match scroll scoring every point 1
+func (c *QdrantClient) Query(ctx context.Context, opts memory.SearchOptions) ([]SearchResult, error) {
+	filter := c.searchFilter(qdrantFilter(opts.Filter))
+	limit := opts.LimitOrDefault()
+
+	if !opts.Similarity() {
+		points, err := c.client.Scroll(ctx, &qdrant.ScrollPoints{
+			CollectionName: c.config.Collection,
+			Filter:         filter,
+			Limit:          qdrant.PtrOf(uint32(limit)),
+			WithPayload:    qdrant.NewWithPayload(true),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to scroll: %w", err)
+		}
+		results := make([]SearchResult, 0, len(points))
+		for _, point := range points {
+			results = append(results, SearchResult{
+				ID:      pointIDString(point.Id),
+				Score:   1.0, // Exact match
+				Payload: readPayload(point.Payload),
+			})
+		}
+		return results, nil
+	}
+
 	// Convert float64 to float32
-	vector32 := make([]float32, len(vector))
-	for i, v := range vector {
+	vector32 := make([]float32, len(opts.Vector))
+	for i, v := range opts.Vector {
 		vector32[i] = float32(v)
 	}
 
-	// Search for similar vectors
-	searchResult, err := c.client.Query(ctx, &qdrant.QueryPoints{
-		CollectionName: c.config.Collection,
-		Query:          qdrant.NewQuery(vector32...),
-		Limit:          qdrant.PtrOf(uint64(limit)),
-		WithPayload:    qdrant.NewWithPayload(true),
+	var points []*qdrant.ScoredPoint
+	err := c.search(ctx, func(client *qdrant.Client) (err error) {
+		points, err = client.Query(ctx, &qdrant.QueryPoints{
+			CollectionName: c.config.Collection,
+			Query:          qdrant.NewQuery(vector32...),
+			Limit:          qdrant.PtrOf(uint64(limit)),
+			WithPayload:    qdrant.NewWithPayload(true),
+			Filter:         filter,
+		})
+		return err
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to search: %w", err)
 	}
 
-	// Convert results
-	results := make([]SearchResult, 0, len(searchResult))
-	for _, point := range searchResult {
-		payload := make(map[string]interface{})
-		for key, val := range point.Payload {
-			payload[key] = val.GetStringValue()
-		}
-
-		// Extract ID as string
-		var idStr string
-		if point.Id != nil && point.Id.GetNum() != 0 {
-			idStr = fmt.Sprintf("%d", point.Id.GetNum())
-		} else if point.Id != nil && point.Id.GetUuid() != "" {
-			idStr = point.Id.GetUuid()
-		}
-
+	results := make([]SearchResult, 0, len(points))
+	for _, point := range points {
 		results = append(results, SearchResult{
-			ID:      idStr,
+			ID:      pointIDString(point.Id),
 			Score:   float64(point.Score),
-			Payload: payload,
+			Payload: readPayload(point.Payload),
 		})
 	}
-
 	return results, nil
 }
 
-// SearchCodeOnly searches for similar vectors, excluding markdown documentation chunks
-func (c *QdrantClient) SearchCodeOnly(ctx context.Context, vector []float64, limit int) ([]SearchResult, error) {
-	// Convert float64 to float32
-	vector32 := make([]float32, len(vector))
-	for i, v := range vector {
-		vector32[i] = float32(v)
+// GetByID returns the point with the given ID, or nil when it does not exist.
+// IDs are converted like in Upsert: numeric IDs first, then string/UUID.
+func (c *QdrantClient) GetByID(ctx context.Context, id string) (*SearchResult, error) {
+	var pointID *qdrant.PointId
+	var numID uint64
+	if _, scanErr := fmt.Sscanf(id, "%d", &numID); scanErr == nil {
+		pointID = qdrant.NewIDNum(numID)
+	} else {
+		pointID = qdrant.NewID(id)
 	}
 
-	// Search for similar vectors, excluding markdown chunks
-	// Markdown chunks have chunk_type="markdown", code chunks have type="class|method|function|etc"
-	searchResult, err := c.client.Query(ctx, &qdrant.QueryPoints{
+	points, err := c.client.Get(ctx, &qdrant.GetPoints{
 		CollectionName: c.config.Collection,
-		Query:          qdrant.NewQuery(vector32...),
-		Limit:          qdrant.PtrOf(uint64(limit)),
+		Ids:            []*qdrant.PointId{pointID},
 		WithPayload:    qdrant.NewWithPayload(true),
-		Filter: &qdrant.Filter{
-			MustNot: []*qdrant.Condition{
-				{
-					ConditionOneOf: &qdrant.Condition_Field{
-						Field: &qdrant.FieldCondition{
-							Key: "chunk_type",
-							Match: &qdrant.Match{
-								MatchValue: &qdrant.Match_Keyword{
-									Keyword: "markdown",
-								},
-							},
-						},
-					},
-				},
-			},
-		},
 	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to search code: %w", err)
+		return nil, fmt.Errorf("failed to get point: %w", err)
+	}
+	if len(points) == 0 {
+		return nil, nil
 	}
 
-	// Convert results
-	results := make([]SearchResult, 0, len(searchResult))
-	for _, point := range searchResult {
-		payload := make(map[string]interface{})
-		for key, val := range point.Payload {
-			payload[key] = val.GetStringValue()
+	payload := readPayload(points[0].Payload)
+	if !c.visible(payload) {
+		return nil, nil
+	}
+	return &SearchResult{
+		ID:      id,
+		Score:   1.0, // Exact match
+		Payload: payload,
+	}, nil
+}
+
+// ScrollVectors calls fn with every point of the collection, vector included,
+// fetching batchSize points per request. It stops at the first error of fn.
+func (c *QdrantClient) ScrollVectors(ctx context.Context, batchSize int, fn func(VectorPoint) error) error {
+	if batchSize <= 0 {
+		batchSize = 256
+	}
+
+	var offset *qdrant.PointId
+	for {
+		points, next, err := c.client.ScrollAndOffset(ctx, &qdrant.ScrollPoints{
+			CollectionName: c.config.Collection,
+			Offset:         offset,
+			Limit:          qdrant.PtrOf(uint32(batchSize)),
+			WithPayload:    qdrant.NewWithPayload(true),
+			WithVectors:    qdrant.NewWithVectors(true),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to scroll vectors: %w", err)
 		}
 
-		// Extract ID as string
-		var idStr string
-		if point.Id != nil && point.Id.GetNum() != 0 {
-			idStr = fmt.Sprintf("%d", point.Id.GetNum())
-		} else if point.Id != nil && point.Id.GetUuid() != "" {
-			idStr = point.Id.GetUuid()
+		for _, point := range points {
+			payload := readPayload(point.Payload)
+
+			var idStr string
+			if point.Id != nil && point.Id.GetNum() != 0 {
+				idStr = fmt.Sprintf("%d", point.Id.GetNum())
+			} else if point.Id != nil && point.Id.GetUuid() != "" {
+				idStr = point.Id.GetUuid()
+			}
+
+			vector := point.GetVectors().GetVector()
+			data := vector.GetDense().GetData()
+			if len(data) == 0 {
+				data = vector.GetData()
+			}
+
+			if err := fn(VectorPoint{ID: idStr, Vector: data, Payload: payload}); err != nil {
+				return err
+			}
 		}
 
-		results = append(results, SearchResult{
-			ID:      idStr,
-			Score:   float64(point.Score),
-			Payload: payload,
-		})
+		if next == nil || len(points) == 0 {
+			return nil
+		}
+		offset = next
 	}
-
-	return results, nil
 }
 
-// SearchByNameAndType searches for a specific symbol by exact name and type match
-// This is useful for find_type_definition where semantic search may not find the exact match
-func (c *QdrantClient) SearchByNameAndType(ctx context.Context, name string, types []string) ([]SearchResult, error) {
-	// Build type conditions
-	typeConditions := make([]*qdrant.Condition, 0, len(types))
-	for _, t := range types {
-		typeConditions = append(typeConditions, &qdrant.Condition{
-			ConditionOneOf: &qdrant.Condition_Field{
-				Field: &qdrant.FieldCondition{
-					Key: "type",
-					Match: &qdrant.Match{
-						MatchValue: &qdrant.Match_Keyword{
-							Keyword: t,
-						},
-					},
-				},
-			},
-		})
-	}
+// aggregatePageSize is the number of points fetched per request by Aggregate
+const aggregatePageSize = 1000
 
-	// Scroll with filter for exact name match and type in list
-	scrollResult, err := c.client.Scroll(ctx, &qdrant.ScrollPoints{
+// Count returns how many searchable points match filter
+func (c *QdrantClient) Count(ctx context.Context, filter memory.Filter) (uint64, error) {
+	n, err := c.client.Count(ctx, &qdrant.CountPoints{
 		CollectionName: c.config.Collection,
-		Filter: &qdrant.Filter{
-			Must: []*qdrant.Condition{
-				{
-					ConditionOneOf: &qdrant.Condition_Field{
-						Field: &qdrant.FieldCondition{
-							Key: "name",
-							Match: &qdrant.Match{
-								MatchValue: &qdrant.Match_Keyword{
-									Keyword: name,
-								},
-							},
-						},
-					},
-				},
-			},
-			Should: typeConditions,
-		},
-		Limit:       qdrant.PtrOf(uint32(10)),
-		WithPayload: qdrant.NewWithPayload(true),
+		Filter:         c.searchFilter(qdrantFilter(filter)),
+		Exact:          qdrant.PtrOf(true),
 	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to scroll: %w", err)
+		return 0, fmt.Errorf("failed to count points: %w", err)
 	}
+	return n, nil
+}
 
-	// Convert results
-	results := make([]SearchResult, 0, len(scrollResult))
-	for _, point := range scrollResult {
-		payload := make(map[string]interface{})
-		for key, val := range point.Payload {
-			payload[key] = val.GetStringValue()
-		}
+// Scroll returns up to limit searchable points matching filter, in ID
+// order, and the cursor of the next page: the ID of its first point, empty
+// after the last page
+func (c *QdrantClient) Scroll(ctx context.Context, filter memory.Filter, cursor string, limit int) ([]SearchResult, string, error) {
+	if limit <= 0 {
+		limit = 200
+	}
+	var offset *qdrant.PointId
+	if cursor != "" {
+		offset = pointID(cursor)
+	}
 
-		var idStr string
-		if point.Id != nil && point.Id.GetNum() != 0 {
-			idStr = fmt.Sprintf("%d", point.Id.GetNum())
-		} else if point.Id != nil && point.Id.GetUuid() != "" {
-			idStr = point.Id.GetUuid()
-		}
+	points, next, err := c.client.ScrollAndOffset(ctx, &qdrant.ScrollPoints{
+		CollectionName: c.config.Collection,
+		Filter:         c.searchFilter(qdrantFilter(filter)),
+		Offset:         offset,
+		Limit:          qdrant.PtrOf(uint32(limit)),
+		WithPayload:    qdrant.NewWithPayload(true),
+	})
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to scroll: %w", err)
+	}
 
+	results := make([]SearchResult, 0, len(points))
+	for _, point := range points {
 		results = append(results, SearchResult{
-			ID:      idStr,
+			ID:      pointIDString(point.Id),
 			Score:   1.0, // Exact match
-			Payload: payload,
+			Payload: readPayload(point.Payload),
 		})
 	}
+	return results, pointIDString(next), nil
+}
 
-	return results, nil
+// Aggregate counts the searchable points matching filter per value of the
+// payload field groupBy. It scrolls the field alone, so it needs no payload
+// index.
+func (c *QdrantClient) Aggregate(ctx context.Context, filter memory.Filter, groupBy string) (map[string]uint64, error) {
+	counts := make(map[string]uint64)
+	var offset *qdrant.PointId
+	for {
+		points, next, err := c.client.ScrollAndOffset(ctx, &qdrant.ScrollPoints{
+			CollectionName: c.config.Collection,
+			Filter:         c.searchFilter(qdrantFilter(filter)),
+			Offset:         offset,
+			Limit:          qdrant.PtrOf(uint32(aggregatePageSize)),
+			WithPayload:    qdrant.NewWithPayloadInclude(groupBy),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to aggregate by %s: %w", groupBy, err)
+		}
+		for _, point := range points {
+			if v, ok := readPayload(point.Payload)[groupBy].(string); ok {
+				counts[v]++
+			}
+		}
+		if next == nil || len(points) == 0 {
+			return counts, nil
+		}
+		offset = next
+	}
+}
+
+// qdrantFilter converts a memory filter to keyword conditions
+func qdrantFilter(f memory.Filter) *qdrant.Filter {
+	filter := &qdrant.Filter{}
+	for _, key := range sortedKeys(f.Must) {
+		filter.Must = append(filter.Must, qdrant.NewMatchKeywords(key, f.Must[key]...))
+	}
+	for _, key := range sortedKeys(f.MustNot) {
+		filter.MustNot = append(filter.MustNot, qdrant.NewMatchKeywords(key, f.MustNot[key]...))
+	}
+	return filter
+}
+
+func sortedKeys(m map[string][]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// pointID converts an ID like Upsert does: numeric IDs first, then
+// string/UUID
+func pointID(id string) *qdrant.PointId {
+	if n, err := strconv.ParseUint(id, 10, 64); err == nil {
+		return qdrant.NewIDNum(n)
+	}
+	return qdrant.NewID(id)
+}
+
+// pointIDString returns the string form of a point ID, empty for nil
+func pointIDString(id *qdrant.PointId) string {
+	switch {
+	case id == nil:
+		return ""
+	case id.GetUuid() != "":
+		return id.GetUuid()
+	}
+	return strconv.FormatUint(id.GetNum(), 10)
 }
 
 // Delete deletes a vector by ID
 func (c *QdrantClient) Delete(ctx context.Context, id string) error {
+	defer c.markWrite()
 	_, err := c.client.Delete(ctx, &qdrant.DeletePoints{
 		CollectionName: c.config.Collection,
 		Points: &qdrant.PointsSelector{
@@ -382,6 +554,7 @@ func (c *QdrantClient) Delete(ctx context.Context, id string) error {
 
 // DeleteByFilter deletes vectors matching a filter
 func (c *QdrantClient) DeleteByFilter(ctx context.Context, key, value string) error {
+	defer c.markWrite()
 	_, err := c.client.Delete(ctx, &qdrant.DeletePoints{
 		CollectionName: c.config.Collection,
 		Points: &qdrant.PointsSelector{
@@ -412,12 +585,20 @@ func (c *QdrantClient) DeleteByFilter(ctx context.Context, key, value string) er
 	return nil
 }
 
-// Close closes the Qdrant client connection
+// Close closes the Qdrant client connections
 func (c *QdrantClient) Close() error {
+	var err error
+	for _, reader := range c.readers {
+		if closeErr := reader.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+	}
 	if c.client != nil {
-		return c.client.Close()
+		if closeErr := c.client.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
 	}
-	return nil
+	return err
 }
 
 // SearchResult represents a search result
@@ -426,3 +607,10 @@ type SearchResult struct {
 	Score   float64
 	Payload map[string]interface{}
 }
+
+// VectorPoint is a stored point with its vector
+type VectorPoint struct {
+	ID      string
+	Vector  []float32
+	Payload map[string]interface{}
+}
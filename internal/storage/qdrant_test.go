@@ -0,0 +1,69 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/qdrant/go-client/qdrant"
+)
+
+func TestQdrantSearchReplicas(t *testing.T) {
+	primary, a, b := &qdrant.Client{}, &qdrant.Client{}, &qdrant.Client{}
+	c := &QdrantClient{
+		config:  QdrantConfig{ReadURLs: []string{"http://a:6333", "http://b:6333"}},
+		client:  primary,
+		readers: []*qdrant.Client{a, b},
+	}
+	ctx := context.Background()
+
+	var used []*qdrant.Client
+	for i := 0; i < 3; i++ {
+		_ = c.search(ctx, func(client *qdrant.Client) error {
+			used = append(used, client)
+			return nil
+		})
+	}
+	if len(used) != 3 || used[0] != a || used[1] != b || used[2] != a {
+		t.Fatalf("searches did not rotate over the replicas")
+	}
+
+	// A failing replica falls back to the primary
+	used = nil
+	err := c.search(ctx, func(client *qdrant.Client) error {
+		used = append(used, client)
+		if client != primary {
+			return errors.New("unavailable")
+		}
+		return nil
+	})
+	if err != nil || len(used) != 2 || used[0] != b || used[1] != primary {
+		t.Fatalf("fallback read err = %v, used %d clients", err, len(used))
+	}
+
+	// Searches right after a write read the primary, so they see it
+	c.markWrite()
+	_ = c.search(ctx, func(client *qdrant.Client) error {
+		if client != primary {
+			t.Error("search right after a write did not use the primary")
+		}
+		return nil
+	})
+	c.lastWrite.Store(time.Now().Add(-replicaLag).UnixNano())
+	_ = c.search(ctx, func(client *qdrant.Client) error {
+		if client == primary {
+			t.Error("search long after a write did not use a replica")
+		}
+		return nil
+	})
+
+	// Without replicas searches use the primary
+	c = &QdrantClient{client: primary}
+	_ = c.search(ctx, func(client *qdrant.Client) error {
+		if client != primary {
+			t.Error("search without replicas did not use the primary")
+		}
+		return nil
+	})
+}
@@ -0,0 +1,114 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/doITmagic/rag-code-mcp/internal/codetypes"
+)
+
+// SchemaVersionKey is the payload field holding the version of the payload
+// schema a point was written with. Points written before versioning have
+// none: version 0.
+const SchemaVersionKey = "schema_version"
+
+// SchemaVersion is the payload schema written by this version. Bump it with
+// a migration in payloadMigrations whenever the stored payload or the chunk
+// JSON of its content changes shape.
+const SchemaVersion = 1
+
+// PayloadMigration upgrades a payload read back from version From to
+// From+1, in place
+type PayloadMigration struct {
+	From        int
+	Description string
+	Migrate     func(payload map[string]interface{}) error
+}
+
+// payloadMigrations upgrade payloads one version at a time, in order
+var payloadMigrations = []PayloadMigration{
+	{From: 0, Description: "canonical chunk languages", Migrate: migrateChunkLanguage},
+}
+
+// PayloadVersion returns the schema version of a payload read back
+func PayloadVersion(payload map[string]interface{}) int {
+	s := fmt.Sprintf("%v", payload[SchemaVersionKey])
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return 0
+	}
+	return n
+}
+
+// newerSchemaOnce logs payloads of a newer schema once per process
+var newerSchemaOnce sync.Once
+
+// MigratePayload upgrades a payload read back to SchemaVersion in place and
+// reports whether it changed. Payloads of a newer schema, written by a
+// newer version, are returned as they are.
+func MigratePayload(payload map[string]interface{}) (bool, error) {
+	if payload == nil {
+		return false, nil
+	}
+	version := PayloadVersion(payload)
+	if version > SchemaVersion {
+		newerSchemaOnce.Do(func() {
+			log.Printf("⚠️  Points of payload schema %d, newer than %d: upgrade rag-code-mcp or re-index", version, SchemaVersion)
+		})
+		return false, nil
+	}
+	if version == SchemaVersion {
+		return false, nil
+	}
+	for _, m := range payloadMigrations {
+		if m.From < version {
+			continue
+		}
+		if err := m.Migrate(payload); err != nil {
+			return false, fmt.Errorf("payload schema %d → %d (%s): %w", m.From, m.From+1, m.Description, err)
+		}
+	}
+	payload[SchemaVersionKey] = strconv.Itoa(SchemaVersion)
+	return true, nil
+}
+
+// migrateChunkLanguage rewrites the language of code chunks written before
+// languages were canonical ("golang", "Go") to its canonical form, in the
+// payload and in the chunk JSON of the content
+func migrateChunkLanguage(payload map[string]interface{}) error {
+	if lang, ok := payload["language"].(string); ok && lang != "" {
+		payload["language"] = string(codetypes.NormalizeLanguage(lang))
+	}
+	content, _ := payload["content"].(string)
+	if !strings.HasPrefix(content, "{") {
+		return nil // documentation and glossary chunks are text
+	}
+	var chunk map[string]interface{}
+	if err := json.Unmarshal([]byte(content), &chunk); err != nil {
+		return nil // not a chunk
+	}
+	changed := false
+	for key, val := range chunk {
+		lang, ok := val.(string)
+		if !strings.EqualFold(key, "language") || !ok {
+			continue
+		}
+		if canonical := string(codetypes.NormalizeLanguage(lang)); canonical != lang {
+			chunk[key] = canonical
+			changed = true
+		}
+	}
+	if !changed {
+		return nil
+	}
+	data, err := json.Marshal(chunk)
+	if err != nil {
+		return err
+	}
+	payload["content"] = string(data)
+	return nil
+}
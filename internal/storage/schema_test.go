@@ -0,0 +1,50 @@
+package storage
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestMigratePayload(t *testing.T) {
+	payload := map[string]interface{}{
+		"language": "Golang",
+		"content":  `{"name":"Charge","language":"golang","code":"func Charge() {}"}`,
+	}
+	if v := PayloadVersion(payload); v != 0 {
+		t.Fatalf("unversioned payload version = %d, want 0", v)
+	}
+	changed, err := MigratePayload(payload)
+	if err != nil || !changed {
+		t.Fatalf("MigratePayload = %v, %v", changed, err)
+	}
+	if PayloadVersion(payload) != SchemaVersion || payload["language"] != "go" {
+		t.Errorf("payload = %v", payload)
+	}
+	var chunk map[string]interface{}
+	if err := json.Unmarshal([]byte(payload["content"].(string)), &chunk); err != nil {
+		t.Fatal(err)
+	}
+	if chunk["language"] != "go" || chunk["name"] != "Charge" {
+		t.Errorf("chunk = %v", chunk)
+	}
+
+	// Current payloads are left alone
+	if changed, _ := MigratePayload(payload); changed {
+		t.Error("current payload migrated again")
+	}
+
+	// Documentation is text
+	doc := map[string]interface{}{"content": "# Payments", "chunk_type": "markdown"}
+	if _, err := MigratePayload(doc); err != nil || doc["content"] != "# Payments" {
+		t.Errorf("markdown payload = %v, %v", doc, err)
+	}
+
+	// Newer payloads are read as they are
+	newer := map[string]interface{}{SchemaVersionKey: "99", "language": "Golang"}
+	if changed, err := MigratePayload(newer); changed || err != nil || newer["language"] != "Golang" {
+		t.Errorf("newer payload = %v, %v, %v", newer, changed, err)
+	}
+	if changed, _ := MigratePayload(nil); changed {
+		t.Error("nil payload migrated")
+	}
+}
@@ -0,0 +1,96 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/doITmagic/rag-code-mcp/internal/config"
+	"github.com/doITmagic/rag-code-mcp/internal/memory"
+)
+
+// VectorStore is the collection of vectors and payloads behind a long-term
+// memory. QdrantClient keeps it in a Qdrant server, LocalStore in files on
+// disk. Payload values are read back as strings.
+type VectorStore interface {
+	CreateCollection(ctx context.Context, name string, dimension int) error
+	CollectionExists(ctx context.Context, name string) (bool, error)
+	GetCollectionPointCount(ctx context.Context, name string) (uint64, error)
+	DeleteCollection(ctx context.Context, name string) error
+	CollectionDimension(ctx context.Context, name string) (int, error)
+	// SwapCollection makes name serve the points of the collection
+	// replacement and deletes what name served before (swap.go)
+	SwapCollection(ctx context.Context, name, replacement string) error
+
+	Upsert(ctx context.Context, id string, vector []float64, payload map[string]interface{}) error
+	Search(ctx context.Context, vector []float64, limit int) ([]SearchResult, error)
+	Query(ctx context.Context, opts memory.SearchOptions) ([]SearchResult, error)
+	GetByID(ctx context.Context, id string) (*SearchResult, error)
+	ScrollVectors(ctx context.Context, batchSize int, fn func(VectorPoint) error) error
+	Count(ctx context.Context, filter memory.Filter) (uint64, error)
+	Scroll(ctx context.Context, filter memory.Filter, cursor string, limit int) ([]SearchResult, string, error)
+	Aggregate(ctx context.Context, filter memory.Filter, groupBy string) (map[string]uint64, error)
+	Delete(ctx context.Context, id string) error
+	DeleteByFilter(ctx context.Context, key, value string) error
+
+	// Index generations, tombstones and hidden files (generation.go,
+	// tombstone.go, hidden.go)
+	SetVisibleGeneration(gen uint64)
+	VisibleGeneration() (uint64, bool)
+	SetHiddenFiles(files []string)
+	HiddenFiles() []string
+	DeleteFileExceptGeneration(ctx context.Context, file string, gen uint64) error
+	DeleteFileGeneration(ctx context.Context, file string, gen uint64) error
+	DeleteNewerGenerations(ctx context.Context, gen uint64) error
+	TombstoneFileExceptGeneration(ctx context.Context, file string, gen uint64, at time.Time) error
+	PurgeTombstones(ctx context.Context, before time.Time) error
+
+	Close() error
+}
+
+// DefaultLocalPath is the directory of the local vector store when
+// storage.vector_db.path is not set
+const DefaultLocalPath = "~/.local/share/ragcode/vectors"
+
+// Open returns the store of a collection for the configured provider:
+// "qdrant" (the default) or "local"
+func Open(cfg config.VectorDBConfig, collection string) (VectorStore, error) {
+	switch cfg.Provider {
+	case "", "qdrant":
+		return NewQdrantClient(QdrantConfig{
+			URL:        cfg.URL,
+			ReadURLs:   cfg.ReadURLs,
+			APIKey:     cfg.APIKey,
+			Collection: collection,
+		})
+	case "local":
+		dir, err := expandHome(cfg.Path)
+		if err != nil {
+			return nil, err
+		}
+		return NewLocalStore(LocalConfig{Dir: dir, Collection: collection})
+	default:
+		return nil, fmt.Errorf("unknown vector store provider %q", cfg.Provider)
+	}
+}
+
+// expandHome resolves a leading ~ of the local store path
+func expandHome(path string) (string, error) {
+	if path == "" {
+		path = DefaultLocalPath
+	}
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s: %w", path, err)
+	}
+	return filepath.Join(home, strings.TrimPrefix(path[1:], "/")), nil
+}
+
+var _ VectorStore = (*QdrantClient)(nil)
+var _ VectorStore = (*LocalStore)(nil)
@@ -0,0 +1,112 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/qdrant/go-client/qdrant"
+)
+
+// CollectionDimension returns the vector dimension of a collection
+func (c *QdrantClient) CollectionDimension(ctx context.Context, name string) (int, error) {
+	info, err := c.client.GetCollectionInfo(ctx, name)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get collection info: %w", err)
+	}
+	params := info.GetConfig().GetParams().GetVectorsConfig().GetParams()
+	if params == nil {
+		return 0, fmt.Errorf("collection %s has no default vector", name)
+	}
+	return int(params.GetSize()), nil
+}
+
+// SwapCollection points the alias name at replacement and deletes the
+// collection name pointed at before. The alias switch is atomic: searches
+// see the old points or the new ones. A collection created before aliases
+// is deleted first, as an alias can not take its name while it exists, so
+// searches fail for that moment.
+func (c *QdrantClient) SwapCollection(ctx context.Context, name, replacement string) error {
+	defer c.markWrite()
+	previous, err := c.aliasTarget(ctx, name)
+	if err != nil {
+		return err
+	}
+	var actions []*qdrant.AliasOperations
+	if previous != "" {
+		actions = append(actions, qdrant.NewAliasDelete(name))
+	} else if exists, err := c.client.CollectionExists(ctx, name); err != nil {
+		return fmt.Errorf("failed to check collection existence: %w", err)
+	} else if exists {
+		previous = name
+		if err := c.client.DeleteCollection(ctx, name); err != nil {
+			return fmt.Errorf("failed to delete collection %s: %w", name, err)
+		}
+	}
+	actions = append(actions, qdrant.NewAliasCreate(name, replacement))
+	if err := c.client.UpdateAliases(ctx, actions); err != nil {
+		return fmt.Errorf("failed to point alias %s at %s: %w", name, replacement, err)
+	}
+	if previous != "" && previous != name && previous != replacement {
+		if err := c.client.DeleteCollection(ctx, previous); err != nil {
+			return fmt.Errorf("failed to delete collection %s: %w", previous, err)
+		}
+	}
+	return nil
+}
+
+// aliasTarget returns the collection the alias name points at, or "" when
+// name is not an alias
+func (c *QdrantClient) aliasTarget(ctx context.Context, name string) (string, error) {
+	aliases, err := c.client.ListAliases(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to list aliases: %w", err)
+	}
+	for _, a := range aliases {
+		if a.GetAliasName() == name {
+			return a.GetCollectionName(), nil
+		}
+	}
+	return "", nil
+}
+
+// CollectionDimension returns the vector dimension of a collection
+func (s *LocalStore) CollectionDimension(ctx context.Context, name string) (int, error) {
+	c, err := s.load(name)
+	if err != nil {
+		return 0, err
+	}
+	if c == nil {
+		return 0, fmt.Errorf("collection %s not found", name)
+	}
+	return c.dim, nil
+}
+
+// SwapCollection renames the file of replacement over the file of name,
+// which replaces it atomically
+func (s *LocalStore) SwapCollection(ctx context.Context, name, replacement string) error {
+	from, to := s.collectionPath(replacement), s.collectionPath(name)
+	localCollections.Lock()
+	defer localCollections.Unlock()
+	if _, err := os.Stat(from); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("collection %s not found", replacement)
+		}
+		return err
+	}
+	// Both are read again from the file on their next use
+	for _, path := range []string{from, to} {
+		if c, ok := localCollections.open[path]; ok {
+			c.mu.Lock()
+			c.file.Close()
+			c.file = nil
+			c.mu.Unlock()
+			delete(localCollections.open, path)
+		}
+	}
+	if err := os.Rename(from, to); err != nil {
+		return fmt.Errorf("failed to replace collection %s: %w", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/qdrant/go-client/qdrant"
+)
+
+// TombstoneKey is the payload field holding the Unix time a point was
+// replaced by a committed generation. Searches skip tombstoned points, but
+// GetByID still returns them until PurgeTombstones removes them, so chunk
+// IDs handed out just before a re-index keep resolving for a while.
+const TombstoneKey = "tombstoned_at"
+
+// TombstoneFileExceptGeneration tombstones the points of a file written by
+// other generations than gen. Points already tombstoned keep their time.
+func (c *QdrantClient) TombstoneFileExceptGeneration(ctx context.Context, file string, gen uint64, at time.Time) error {
+	defer c.markWrite()
+	filter := &qdrant.Filter{
+		Must: []*qdrant.Condition{
+			qdrant.NewMatchKeyword("file", file),
+			qdrant.NewIsEmpty(TombstoneKey),
+		},
+		MustNot: []*qdrant.Condition{qdrant.NewMatchInt(GenerationKey, int64(gen))},
+	}
+	wait := true
+	_, err := c.client.SetPayload(ctx, &qdrant.SetPayloadPoints{
+		CollectionName: c.config.Collection,
+		Wait:           &wait,
+		Payload:        map[string]*qdrant.Value{TombstoneKey: payloadValue(TombstoneKey, at.Unix())},
+		PointsSelector: qdrant.NewPointsSelectorFilter(filter),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to tombstone points: %w", err)
+	}
+	return nil
+}
+
+// PurgeTombstones deletes the points tombstoned before t
+func (c *QdrantClient) PurgeTombstones(ctx context.Context, before time.Time) error {
+	return c.deleteWhere(ctx, &qdrant.Filter{
+		Must: []*qdrant.Condition{
+			qdrant.NewRange(TombstoneKey, &qdrant.Range{Lt: qdrant.PtrOf(float64(before.Unix()))}),
+		},
+	})
+}
+
+// Tombstoned reports whether a payload read back from Qdrant belongs to a
+// tombstoned point
+func Tombstoned(payload map[string]interface{}) bool {
+	s, _ := payload[TombstoneKey].(string)
+	return s != ""
+}
@@ -0,0 +1,21 @@
+package storage
+
+import (
+	"testing"
+
+	"github.com/qdrant/go-client/qdrant"
+)
+
+func TestTombstonePayload(t *testing.T) {
+	stored := map[string]*qdrant.Value{TombstoneKey: payloadValue(TombstoneKey, int64(1700000000))}
+	if _, ok := stored[TombstoneKey].GetKind().(*qdrant.Value_IntegerValue); !ok {
+		t.Fatal("tombstone time should be stored as an integer for range filters")
+	}
+	payload := readPayload(stored)
+	if !Tombstoned(payload) {
+		t.Errorf("payload %v should read back as tombstoned", payload)
+	}
+	if Tombstoned(map[string]interface{}{"file": "a.go"}) {
+		t.Error("points without a tombstone are live")
+	}
+}
@@ -0,0 +1,183 @@
+package symbolimport
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/doITmagic/rag-code-mcp/internal/codetypes"
+	"github.com/doITmagic/rag-code-mcp/internal/ragcode"
+)
+
+// ctagsTag is a tag of Universal Ctags, as its JSON output writes it and as
+// parseTagLine reads it from a classic tags file
+type ctagsTag struct {
+	Type      string `json:"_type"`
+	Name      string `json:"name"`
+	Path      string `json:"path"`
+	Pattern   string `json:"pattern"`
+	Language  string `json:"language"`
+	Line      int    `json:"line"`
+	End       int    `json:"end"`
+	Kind      string `json:"kind"`
+	Scope     string `json:"scope"`
+	ScopeKind string `json:"scopeKind"`
+	Signature string `json:"signature"`
+	Typeref   string `json:"typeref"`
+	Access    string `json:"access"`
+}
+
+// ctagsScopeKinds are the scope kinds whose functions are methods
+var ctagsScopeKinds = map[string]bool{
+	"class": true, "struct": true, "interface": true, "trait": true, "enum": true,
+	"type": true, "talias": true, "union": true, "unknown": true, "implementation": true,
+}
+
+// ctagsLetterKinds expands the one-letter kinds of classic tags files
+// written without --fields=+K, for the languages whose letters are
+// unambiguous
+var ctagsLetterKinds = map[codetypes.Language]map[string]string{
+	codetypes.LanguageGo:     {"f": "func", "c": "const", "t": "type", "v": "var", "s": "struct", "i": "interface", "a": "talias"},
+	codetypes.LanguagePython: {"c": "class", "f": "function", "m": "member"},
+	codetypes.LanguagePHP:    {"c": "class", "i": "interface", "t": "trait", "f": "function", "d": "define"},
+}
+
+// readCtags reads Universal Ctags output: JSON lines (--output-format=json)
+// or a classic tags file. Lines need line numbers, which both write with
+// --fields=+n.
+func readCtags(r io.Reader, b *builder) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		var tag ctagsTag
+		switch {
+		case strings.HasPrefix(line, "{"):
+			if err := json.Unmarshal([]byte(line), &tag); err != nil || tag.Type != "tag" {
+				continue // pseudo tags and malformed lines
+			}
+		case line == "" || strings.HasPrefix(line, "!_TAG_"):
+			continue
+		default:
+			var ok bool
+			if tag, ok = parseTagLine(line); !ok {
+				continue
+			}
+		}
+		addCtagsTag(b, tag)
+	}
+	return scanner.Err()
+}
+
+// parseTagLine reads a line of a classic tags file:
+// name<TAB>path<TAB>pattern;"<TAB>kind<TAB>key:value...
+func parseTagLine(line string) (ctagsTag, bool) {
+	head, ext, found := strings.Cut(line, ";\"\t")
+	if !found {
+		return ctagsTag{}, false
+	}
+	parts := strings.SplitN(head, "\t", 3)
+	if len(parts) < 3 {
+		return ctagsTag{}, false
+	}
+	tag := ctagsTag{Name: parts[0], Path: parts[1], Pattern: parts[2]}
+	if n, err := strconv.Atoi(tag.Pattern); err == nil {
+		tag.Line = n
+	}
+	for _, field := range strings.Split(ext, "\t") {
+		key, value, found := strings.Cut(field, ":")
+		if !found {
+			if tag.Kind == "" {
+				tag.Kind = field
+			}
+			continue
+		}
+		switch key {
+		case "kind":
+			tag.Kind = value
+		case "line":
+			tag.Line, _ = strconv.Atoi(value)
+		case "end":
+			tag.End, _ = strconv.Atoi(value)
+		case "language":
+			tag.Language = value
+		case "signature":
+			tag.Signature = value
+		case "typeref":
+			tag.Typeref = value
+		case "access":
+			tag.Access = value
+		case "scope":
+			// --fields=+Z writes scope:kind:name
+			tag.ScopeKind, tag.Scope, _ = strings.Cut(value, ":")
+		case "file", "roles", "extras", "inherits", "implements", "nth":
+		default:
+			tag.ScopeKind, tag.Scope = key, value
+		}
+	}
+	return tag, true
+}
+
+// addCtagsTag records a tag if it is a definition the symbol table holds
+func addCtagsTag(b *builder, tag ctagsTag) {
+	lang := tag.Language
+	if lang == "" {
+		lang = extOf(tag.Path)
+	}
+	canonical := codetypes.NormalizeLanguage(lang)
+	kind := tag.Kind
+	if len(kind) == 1 {
+		if kind = ctagsLetterKinds[canonical][kind]; kind == "" {
+			return
+		}
+	}
+	member := ctagsScopeKinds[strings.ToLower(tag.ScopeKind)]
+	if strings.EqualFold(kind, "member") {
+		// Python methods; fields elsewhere
+		if canonical != codetypes.LanguagePython {
+			return
+		}
+		kind = "method"
+	}
+	if canonical == codetypes.LanguageGo && tag.ScopeKind != "" && !member && kind != "func" && tag.ScopeKind != "package" {
+		return // locals and fields of function literals
+	}
+	entry := ragcode.SymbolEntry{
+		Name:      tag.Name,
+		Kind:      symbolKind(lang, kind, member),
+		StartLine: tag.Line,
+		EndLine:   tag.End,
+	}
+	switch {
+	case member:
+		entry.Receiver = lastScopeSegment(tag.Scope)
+	case tag.ScopeKind == "package" || tag.ScopeKind == "namespace":
+		entry.Package = tag.Scope
+	}
+	if tag.Signature != "" {
+		entry.Signature = tag.Name + tag.Signature
+		if result := strings.TrimPrefix(tag.Typeref, "typename:"); result != "" && canonical == codetypes.LanguageGo {
+			entry.Signature += " " + result
+		}
+	}
+	private := tag.Access == "private" || tag.Access == "protected"
+	b.add(tag.Path, tag.Language, entry, private)
+}
+
+// lastScopeSegment returns the innermost name of a scope: Cart of
+// shop.Cart or App\Cart
+func lastScopeSegment(scope string) string {
+	if i := strings.LastIndexAny(scope, `.\:`); i >= 0 {
+		return scope[i+1:]
+	}
+	return scope
+}
+
+func extOf(path string) string {
+	if i := strings.LastIndexByte(path, '.'); i >= 0 && !strings.ContainsAny(path[i:], `/\`) {
+		return path[i:]
+	}
+	return ""
+}
@@ -0,0 +1,293 @@
+// Package symbolimport reads the symbol definitions of code intelligence
+// artifacts built elsewhere, typically in CI: ctags tag files, LSIF dumps
+// and SCIP indexes. They seed the symbol table of a workspace so structural
+// tools answer before its first indexing run.
+package symbolimport
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/doITmagic/rag-code-mcp/internal/codetypes"
+	"github.com/doITmagic/rag-code-mcp/internal/ragcode"
+)
+
+// Format is the kind of artifact symbols are imported from
+type Format string
+
+const (
+	FormatCtags Format = "ctags" // Universal Ctags, JSON or classic tags
+	FormatLSIF  Format = "lsif"  // LSIF dump, JSON lines
+	FormatSCIP  Format = "scip"  // SCIP index, protobuf
+)
+
+// ParseFormat returns the format of a name; "" and "auto" give "", for
+// Detect
+func ParseFormat(name string) (Format, error) {
+	switch f := Format(strings.ToLower(strings.TrimSpace(name))); f {
+	case "", "auto":
+		return "", nil
+	case FormatCtags, FormatLSIF, FormatSCIP:
+		return f, nil
+	}
+	return "", fmt.Errorf("unknown symbol artifact format %q: use ctags, lsif or scip", name)
+}
+
+// Detect guesses the format of an artifact from its name and first bytes
+func Detect(path string, head []byte) Format {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".scip":
+		return FormatSCIP
+	case ".lsif":
+		return FormatLSIF
+	}
+	line := bytes.TrimSpace(head)
+	if i := bytes.IndexByte(line, '\n'); i >= 0 {
+		line = line[:i]
+	}
+	switch {
+	case bytes.HasPrefix(line, []byte("{")) && bytes.Contains(line, []byte(`"_type"`)):
+		return FormatCtags
+	case bytes.HasPrefix(line, []byte("{")):
+		return FormatLSIF
+	case bytes.HasPrefix(line, []byte("!_TAG_")) || bytes.Contains(line, []byte(";\"\t")):
+		return FormatCtags
+	case len(head) > 0 && !isText(head):
+		return FormatSCIP
+	}
+	return ""
+}
+
+// isText reports whether head looks like UTF-8 text rather than protobuf
+func isText(head []byte) bool {
+	for _, r := range string(head) {
+		if r == unicode.ReplacementChar || (r < 0x20 && r != '\n' && r != '\r' && r != '\t') {
+			return false
+		}
+	}
+	return true
+}
+
+// Result holds the definitions read from an artifact
+type Result struct {
+	Format Format
+	// Files are the definitions by absolute file path under the root, in
+	// line order
+	Files map[string][]ragcode.SymbolEntry
+	// Outside counts the definitions of files outside the root, left out
+	Outside int
+}
+
+// ReadFile reads the definitions of the artifact at path, of the given
+// format or the detected one when it is "". Artifact paths are resolved
+// against root: relative ones, and absolute ones under the project root the
+// artifact records, so an artifact built in a CI checkout applies to a
+// local clone.
+func ReadFile(path, root string, format Format) (*Result, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	r := bufio.NewReaderSize(f, 1<<16)
+	if format == "" {
+		head, _ := r.Peek(4096)
+		if format = Detect(path, head); format == "" {
+			return nil, fmt.Errorf("cannot tell the format of %s: pass ctags, lsif or scip", path)
+		}
+	}
+	b := newBuilder(format, root)
+	switch format {
+	case FormatCtags:
+		err = readCtags(r, b)
+	case FormatLSIF:
+		err = readLSIF(r, b)
+	case FormatSCIP:
+		var data []byte
+		if data, err = io.ReadAll(r); err == nil {
+			err = readSCIP(data, b)
+		}
+	default:
+		err = fmt.Errorf("unknown symbol artifact format %q", format)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s %s: %w", format, path, err)
+	}
+	return b.result(), nil
+}
+
+// builder collects the definitions of an artifact
+type builder struct {
+	format Format
+	root   string
+	// projectRoot is the root the artifact was built in, when it records it
+	projectRoot string
+	files       map[string][]ragcode.SymbolEntry
+	seen        map[string]bool
+	outside     int
+}
+
+func newBuilder(format Format, root string) *builder {
+	return &builder{
+		format: format,
+		root:   filepath.Clean(root),
+		files:  make(map[string][]ragcode.SymbolEntry),
+		seen:   make(map[string]bool),
+	}
+}
+
+// resolve maps a path of the artifact to an absolute path under the root
+func (b *builder) resolve(path string) (string, bool) {
+	path = filepath.FromSlash(path)
+	if filepath.IsAbs(path) {
+		rel := ""
+		if b.projectRoot != "" {
+			if r, err := filepath.Rel(b.projectRoot, path); err == nil && !strings.HasPrefix(r, "..") {
+				rel = r
+			}
+		}
+		if rel == "" {
+			r, err := filepath.Rel(b.root, path)
+			if err != nil || strings.HasPrefix(r, "..") {
+				return "", false
+			}
+			rel = r
+		}
+		path = rel
+	}
+	path = filepath.Clean(path)
+	if path == "." || strings.HasPrefix(path, "..") {
+		return "", false
+	}
+	return filepath.Join(b.root, path), true
+}
+
+// add records a definition of the artifact file path. language is the one
+// the artifact records, if any; private marks members the artifact reports
+// as private or protected.
+func (b *builder) add(path, language string, e ragcode.SymbolEntry, private bool) {
+	if e.Name == "" || e.Kind == "" || e.StartLine <= 0 {
+		return
+	}
+	file, ok := b.resolve(path)
+	if !ok {
+		b.outside++
+		return
+	}
+	if language == "" {
+		language = filepath.Ext(file)
+	}
+	e.Language = string(codetypes.NormalizeLanguage(language))
+	e.FilePath = file
+	if e.EndLine < e.StartLine {
+		e.EndLine = e.StartLine
+	}
+	e.Exported = exported(e, private)
+	e.Test = ragcode.IsTestFile(file)
+	e.Imported = string(b.format)
+
+	key := fmt.Sprintf("%s\x00%s\x00%s\x00%s\x00%d", file, e.Receiver, e.Name, e.Kind, e.StartLine)
+	if b.seen[key] {
+		return
+	}
+	b.seen[key] = true
+	b.files[file] = append(b.files[file], e)
+}
+
+// exported applies the visibility rules of languages that encode it in the
+// name; for others the artifact's access, where it records one, decides
+func exported(e ragcode.SymbolEntry, private bool) bool {
+	switch e.Language {
+	case "go", "python":
+		return ragcode.IsPublicSymbol(codetypes.CodeChunk{Name: e.Name, Language: codetypes.Language(e.Language)})
+	}
+	return !private
+}
+
+func (b *builder) result() *Result {
+	for file, entries := range b.files {
+		sort.SliceStable(entries, func(i, j int) bool { return entries[i].StartLine < entries[j].StartLine })
+		if entries[0].Language == "go" {
+			fillGoPackage(file, entries)
+		}
+	}
+	return &Result{Format: b.format, Files: b.files, Outside: b.outside}
+}
+
+// fillGoPackage sets the package of Go definitions the artifact left
+// without one from the package clause of their file
+func fillGoPackage(file string, entries []ragcode.SymbolEntry) {
+	pkg := ""
+	for i := range entries {
+		if entries[i].Package != "" {
+			continue
+		}
+		if pkg == "" {
+			if pkg = goPackageClause(file); pkg == "" {
+				return
+			}
+		}
+		entries[i].Package = pkg
+	}
+}
+
+func goPackageClause(file string) string {
+	f, err := os.Open(file)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) >= 2 && fields[0] == "package" {
+			return fields[1]
+		}
+	}
+	return ""
+}
+
+// symbolKind maps the kind of a definition, in any of the artifacts'
+// vocabularies, to the chunk type the language's analyzer gives it, and ""
+// for definitions the symbol table does not hold (fields, locals,
+// parameters). member tells whether it is nested in a type.
+func symbolKind(language, kind string, member bool) string {
+	golang := codetypes.NormalizeLanguage(language) == codetypes.LanguageGo
+	switch strings.ToLower(kind) {
+	case "func", "function", "method", "constructor", "procedure", "subroutine", "singletonmethod":
+		if member {
+			return "method"
+		}
+		return "function"
+	case "class", "interface", "trait", "enum", "struct", "union", "type", "typedef", "talias", "alias":
+		if golang {
+			return "type"
+		}
+		switch k := strings.ToLower(kind); k {
+		case "class", "interface", "trait", "enum", "struct":
+			return k
+		case "union":
+			return "struct"
+		}
+		return "type"
+	case "const", "constant", "define":
+		if golang {
+			return "const"
+		}
+		return "constant"
+	case "var", "variable":
+		// Only Go indexes package variables
+		if golang && !member {
+			return "var"
+		}
+	}
+	return ""
+}
@@ -0,0 +1,283 @@
+package symbolimport
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"google.golang.org/protobuf/encoding/protowire"
+
+	"github.com/doITmagic/rag-code-mcp/internal/ragcode"
+)
+
+const invoiceGo = `package billing
+
+type Invoice struct {
+	Lines []float64
+}
+
+func (i Invoice) Total() float64 {
+	return 0
+}
+
+func calculate(i Invoice) float64 {
+	return i.Total()
+}
+`
+
+// writeWorkspace writes the sample files under a new root
+func writeWorkspace(t *testing.T) string {
+	t.Helper()
+	root := t.TempDir()
+	files := map[string]string{
+		"billing/invoice.go": invoiceGo,
+		"shop/cart.py":       "class Cart:\n    def add(self, item):\n        pass\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+func writeArtifact(t *testing.T, name string, data []byte) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// find returns the entry of a file named name, failing the test without one
+func find(t *testing.T, res *Result, file, name string) ragcode.SymbolEntry {
+	t.Helper()
+	for _, e := range res.Files[file] {
+		if e.Name == name {
+			return e
+		}
+	}
+	t.Fatalf("no %s in %s: %+v", name, file, res.Files[file])
+	return ragcode.SymbolEntry{}
+}
+
+// checkInvoice checks the Go definitions every format records
+func checkInvoice(t *testing.T, res *Result, root string, format Format) {
+	t.Helper()
+	file := filepath.Join(root, "billing", "invoice.go")
+	invoice := find(t, res, file, "Invoice")
+	if invoice.Kind != "type" || invoice.StartLine != 3 || invoice.Package != "billing" || !invoice.Exported || invoice.Language != "go" {
+		t.Errorf("Invoice = %+v", invoice)
+	}
+	if invoice.Imported != string(format) {
+		t.Errorf("Invoice imported from %q, want %q", invoice.Imported, format)
+	}
+	total := find(t, res, file, "Total")
+	if total.Kind != "method" || total.Receiver != "Invoice" || total.StartLine != 7 || total.EndLine != 9 {
+		t.Errorf("Total = %+v", total)
+	}
+	calc := find(t, res, file, "calculate")
+	if calc.Kind != "function" || calc.Exported {
+		t.Errorf("calculate = %+v", calc)
+	}
+	for _, e := range res.Files[file] {
+		if e.Name == "Lines" {
+			t.Errorf("field imported: %+v", e)
+		}
+	}
+}
+
+func TestReadCtagsJSON(t *testing.T) {
+	root := writeWorkspace(t)
+	artifact := writeArtifact(t, "tags.json", []byte(`{"_type": "ptag", "name": "JSON_OUTPUT_VERSION"}
+{"_type": "tag", "name": "billing", "path": "billing/invoice.go", "language": "Go", "line": 1, "kind": "package"}
+{"_type": "tag", "name": "Invoice", "path": "billing/invoice.go", "language": "Go", "line": 3, "kind": "struct", "scope": "billing", "scopeKind": "package", "end": 5}
+{"_type": "tag", "name": "Lines", "path": "billing/invoice.go", "language": "Go", "line": 4, "kind": "member", "scope": "Invoice", "scopeKind": "struct"}
+{"_type": "tag", "name": "Total", "path": "billing/invoice.go", "language": "Go", "line": 7, "kind": "func", "scope": "Invoice", "scopeKind": "struct", "signature": "()", "typeref": "typename:float64", "end": 9}
+{"_type": "tag", "name": "calculate", "path": "billing/invoice.go", "language": "Go", "line": 11, "kind": "func", "scope": "billing", "scopeKind": "package", "end": 13}
+{"_type": "tag", "name": "Cart", "path": "shop/cart.py", "language": "Python", "line": 1, "kind": "class"}
+{"_type": "tag", "name": "add", "path": "shop/cart.py", "language": "Python", "line": 2, "kind": "member", "scope": "Cart", "scopeKind": "class"}
+{"_type": "tag", "name": "Elsewhere", "path": "../other/x.go", "language": "Go", "line": 1, "kind": "func"}
+`))
+
+	res, err := ReadFile(artifact, root, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Format != FormatCtags || res.Outside != 1 {
+		t.Fatalf("format %s, outside %d", res.Format, res.Outside)
+	}
+	checkInvoice(t, res, root, FormatCtags)
+	if total := find(t, res, filepath.Join(root, "billing", "invoice.go"), "Total"); total.Signature != "Total() float64" {
+		t.Errorf("Total signature = %q", total.Signature)
+	}
+	add := find(t, res, filepath.Join(root, "shop", "cart.py"), "add")
+	if add.Kind != "method" || add.Receiver != "Cart" || add.Language != "python" {
+		t.Errorf("add = %+v", add)
+	}
+}
+
+func TestReadCtagsClassic(t *testing.T) {
+	root := writeWorkspace(t)
+	artifact := writeArtifact(t, "tags", []byte("!_TAG_FILE_FORMAT\t2\t/extended format/\n"+
+		"Invoice\tbilling/invoice.go\t/^type Invoice struct {$/;\"\tkind:struct\tline:3\tpackage:billing\tend:5\n"+
+		"Lines\tbilling/invoice.go\t/^\tLines []float64$/;\"\tm\tline:4\tstruct:Invoice\n"+
+		"Total\tbilling/invoice.go\t/^func (i Invoice) Total() float64 {$/;\"\tf\tline:7\tstruct:Invoice\tend:9\n"+
+		"calculate\tbilling/invoice.go\t11;\"\tf\tpackage:billing\n"))
+
+	res, err := ReadFile(artifact, root, FormatCtags)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkInvoice(t, res, root, FormatCtags)
+}
+
+func TestReadLSIF(t *testing.T) {
+	root := writeWorkspace(t)
+	// Built in another checkout: paths under its project root map to root
+	artifact := writeArtifact(t, "dump.lsif", []byte(`{"id":1,"type":"vertex","label":"metaData","version":"0.5.0","projectRoot":"file:///ci/build"}
+{"id":2,"type":"vertex","label":"document","uri":"file:///ci/build/billing/invoice.go","languageId":"go"}
+{"id":3,"type":"vertex","label":"range","start":{"line":2,"character":5},"end":{"line":2,"character":12},"tag":{"type":"definition","text":"Invoice","kind":23,"fullRange":{"start":{"line":2,"character":0},"end":{"line":4,"character":1}}}}
+{"id":4,"type":"vertex","label":"range","start":{"line":3,"character":1},"end":{"line":3,"character":6},"tag":{"type":"definition","text":"Lines","kind":8,"fullRange":{"start":{"line":3,"character":1},"end":{"line":3,"character":16}}}}
+{"id":5,"type":"vertex","label":"range","start":{"line":6,"character":17},"end":{"line":6,"character":22},"tag":{"type":"definition","text":"Total","kind":6,"fullRange":{"start":{"line":6,"character":0},"end":{"line":8,"character":1}}}}
+{"id":6,"type":"vertex","label":"range","start":{"line":10,"character":5},"end":{"line":10,"character":14},"tag":{"type":"definition","text":"calculate","kind":12,"fullRange":{"start":{"line":10,"character":0},"end":{"line":12,"character":1}}}}
+{"id":7,"type":"edge","label":"contains","outV":2,"inVs":[3,4,5,6]}
+{"id":8,"type":"vertex","label":"documentSymbolResult","result":[{"id":3,"children":[{"id":4},{"id":5}]},{"id":6}]}
+{"id":9,"type":"edge","label":"textDocument/documentSymbol","outV":2,"inV":8}
+{"id":10,"type":"vertex","label":"hoverResult","result":{"contents":[]}}
+{"id":11,"type":"vertex","label":"document","uri":"file:///elsewhere/x.go","languageId":"go"}
+{"id":12,"type":"vertex","label":"range","start":{"line":0,"character":5},"end":{"line":0,"character":6},"tag":{"type":"definition","text":"X","kind":12}}
+{"id":13,"type":"edge","label":"contains","outV":11,"inVs":[12]}
+`))
+
+	res, err := ReadFile(artifact, root, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Format != FormatLSIF || res.Outside != 1 {
+		t.Fatalf("format %s, outside %d", res.Format, res.Outside)
+	}
+	checkInvoice(t, res, root, FormatLSIF)
+}
+
+// scipMessage encodes protobuf fields: strings and nested messages as bytes,
+// ints as varints, []int32 packed
+func scipMessage(fields ...any) []byte {
+	var b []byte
+	for i := 0; i < len(fields); i += 2 {
+		num := protowire.Number(fields[i].(int))
+		switch v := fields[i+1].(type) {
+		case string:
+			b = protowire.AppendTag(b, num, protowire.BytesType)
+			b = protowire.AppendString(b, v)
+		case []byte:
+			b = protowire.AppendTag(b, num, protowire.BytesType)
+			b = protowire.AppendBytes(b, v)
+		case int:
+			b = protowire.AppendTag(b, num, protowire.VarintType)
+			b = protowire.AppendVarint(b, uint64(v))
+		case []int32:
+			var packed []byte
+			for _, n := range v {
+				packed = protowire.AppendVarint(packed, uint64(n))
+			}
+			b = protowire.AppendTag(b, num, protowire.BytesType)
+			b = protowire.AppendBytes(b, packed)
+		}
+	}
+	return b
+}
+
+func TestReadSCIP(t *testing.T) {
+	root := writeWorkspace(t)
+	const pkg = "scip-go gomod example.com/shop v1.0.0 `example.com/shop/billing`/"
+	occurrence := func(symbol string, rng, enclosing []int32, roles int) []byte {
+		return scipMessage(scipOccurrenceRange, rng, scipOccurrenceSymbol, symbol, scipOccurrenceRoles, roles, scipOccurrenceEnclosingRange, enclosing)
+	}
+	doc := scipMessage(
+		scipDocumentPath, "billing/invoice.go",
+		scipDocumentLanguage, "Go",
+		scipDocumentOccurrences, occurrence(pkg+"Invoice#", []int32{2, 5, 12}, []int32{2, 0, 4, 1}, scipRoleDefinition),
+		scipDocumentOccurrences, occurrence(pkg+"Invoice#Lines.", []int32{3, 1, 6}, nil, scipRoleDefinition),
+		scipDocumentOccurrences, occurrence(pkg+"Invoice#Total().", []int32{6, 17, 22}, []int32{6, 0, 8, 1}, scipRoleDefinition),
+		scipDocumentOccurrences, occurrence(pkg+"calculate().", []int32{10, 5, 14}, []int32{10, 0, 12, 1}, scipRoleDefinition),
+		scipDocumentOccurrences, occurrence(pkg+"Invoice#Total().", []int32{11, 10, 15}, nil, 0), // a reference
+		scipDocumentOccurrences, occurrence("local 1", []int32{10, 15, 16}, nil, scipRoleDefinition),
+		scipDocumentSymbols, scipMessage(scipSymbolSymbol, pkg+"Invoice#", scipSymbolKind, 49),
+		scipDocumentSymbols, scipMessage(scipSymbolSymbol, pkg+"calculate().", scipSymbolSignatureDocs,
+			scipMessage(scipDocumentText, "func calculate(i Invoice) float64")),
+	)
+	index := scipMessage(
+		scipIndexMetadata, scipMessage(scipMetadataProjectRoot, "file:///ci/build"),
+		scipIndexDocuments, doc,
+	)
+	artifact := writeArtifact(t, "index.scip", index)
+
+	res, err := ReadFile(artifact, root, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Format != FormatSCIP {
+		t.Fatalf("format %s", res.Format)
+	}
+	checkInvoice(t, res, root, FormatSCIP)
+	file := filepath.Join(root, "billing", "invoice.go")
+	if calc := find(t, res, file, "calculate"); calc.Signature != "func calculate(i Invoice) float64" {
+		t.Errorf("calculate signature = %q", calc.Signature)
+	}
+	if n := len(res.Files[file]); n != 3 {
+		t.Errorf("%d definitions, want 3: %+v", n, res.Files[file])
+	}
+}
+
+func TestParseSCIPSymbol(t *testing.T) {
+	tests := []struct {
+		symbol string
+		want   []scipDescriptor
+	}{
+		{"scip-go gomod example.com/shop v1 `example.com/shop/billing`/Invoice#Total().",
+			[]scipDescriptor{{"example.com/shop/billing", '/'}, {"Invoice", '#'}, {"Total", 'm'}}},
+		{"scip-python python shop  app 0.1 `shop.cart`/Cart#add(+1).(item)",
+			[]scipDescriptor{{"shop.cart", '/'}, {"Cart", '#'}, {"add", 'm'}, {"item", '('}}},
+		{"scip-typescript npm . . src/`a``b.ts`/Map#[K]",
+			[]scipDescriptor{{"src", '/'}, {"a`b.ts", '/'}, {"Map", '#'}, {"K", '['}}},
+	}
+	for _, tt := range tests {
+		got, ok := parseSCIPSymbol(tt.symbol)
+		if !ok || len(got) != len(tt.want) {
+			t.Errorf("parseSCIPSymbol(%q) = %v, %v", tt.symbol, got, ok)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("parseSCIPSymbol(%q)[%d] = %v, want %v", tt.symbol, i, got[i], tt.want[i])
+			}
+		}
+	}
+	if _, ok := parseSCIPSymbol("local 12"); ok {
+		t.Error("local symbol parsed")
+	}
+}
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		path string
+		head string
+		want Format
+	}{
+		{"index.scip", "", FormatSCIP},
+		{"out", "\x0a\x10\x0a\x05", FormatSCIP},
+		{"dump.json", `{"id":1,"type":"vertex","label":"metaData"}`, FormatLSIF},
+		{"tags.json", `{"_type": "tag", "name": "x"}`, FormatCtags},
+		{"tags", "!_TAG_FILE_FORMAT\t2\n", FormatCtags},
+		{"notes.txt", "hello\n", ""},
+	}
+	for _, tt := range tests {
+		if got := Detect(tt.path, []byte(tt.head)); got != tt.want {
+			t.Errorf("Detect(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
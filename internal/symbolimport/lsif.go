@@ -0,0 +1,241 @@
+package symbolimport
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"net/url"
+	"path/filepath"
+	"strings"
+
+	"github.com/doITmagic/rag-code-mcp/internal/ragcode"
+)
+
+// LSP symbol kinds, as LSIF range tags and document symbols record them
+var lspSymbolKinds = map[int]string{
+	2:  "module",
+	3:  "namespace",
+	4:  "package",
+	5:  "class",
+	6:  "method",
+	9:  "constructor",
+	10: "enum",
+	11: "interface",
+	12: "function",
+	13: "variable",
+	14: "constant",
+	23: "struct",
+}
+
+type lspPosition struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+}
+
+type lspRange struct {
+	Start lspPosition `json:"start"`
+	End   lspPosition `json:"end"`
+}
+
+// lsifTag is the tag of a range vertex
+type lsifTag struct {
+	Type      string    `json:"type"`
+	Text      string    `json:"text"`
+	Kind      int       `json:"kind"`
+	FullRange *lspRange `json:"fullRange"`
+	Detail    string    `json:"detail"`
+}
+
+// lsifSymbol is an entry of a documentSymbolResult: a range-based one
+// pointing at a range vertex by ID, or a plain LSP DocumentSymbol
+type lsifSymbol struct {
+	ID       json.RawMessage `json:"id"`
+	Name     string          `json:"name"`
+	Detail   string          `json:"detail"`
+	Kind     int             `json:"kind"`
+	Range    lspRange        `json:"range"`
+	Children []lsifSymbol    `json:"children"`
+}
+
+// lsifElement is a vertex or an edge of an LSIF dump, with the fields of
+// the labels readLSIF uses
+type lsifElement struct {
+	ID          json.RawMessage   `json:"id"`
+	Type        string            `json:"type"`
+	Label       string            `json:"label"`
+	URI         string            `json:"uri"`
+	LanguageID  string            `json:"languageId"`
+	ProjectRoot string            `json:"projectRoot"`
+	Start       lspPosition       `json:"start"`
+	End         lspPosition       `json:"end"`
+	Tag         *lsifTag          `json:"tag"`
+	Result      json.RawMessage   `json:"result"`
+	OutV        json.RawMessage   `json:"outV"`
+	InV         json.RawMessage   `json:"inV"`
+	InVs        []json.RawMessage `json:"inVs"`
+}
+
+type lsifDocument struct {
+	path     string
+	language string
+	ranges   []string
+	symbols  string // ID of its documentSymbolResult
+}
+
+// lsifID returns an element ID, a number or a string, as a map key
+func lsifID(raw json.RawMessage) string {
+	return strings.Trim(string(raw), `"`)
+}
+
+// readLSIF reads the definitions of an LSIF dump: from the
+// documentSymbolResult of each document where there is one, which nests
+// methods in their types, else from the range vertices tagged as
+// definitions.
+func readLSIF(r io.Reader, b *builder) error {
+	var (
+		documents = make(map[string]*lsifDocument)
+		order     []string
+		ranges    = make(map[string]lsifElement)
+		results   = make(map[string][]lsifSymbol)
+	)
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		var el lsifElement
+		if err := json.Unmarshal(line, &el); err != nil {
+			return err
+		}
+		id := lsifID(el.ID)
+		switch el.Label {
+		case "metaData":
+			if root := uriPath(el.ProjectRoot); root != "" {
+				b.projectRoot = filepath.Clean(root)
+			}
+		case "document":
+			documents[id] = &lsifDocument{path: uriPath(el.URI), language: el.LanguageID}
+			order = append(order, id)
+		case "range":
+			if el.Tag != nil && el.Tag.Type == "definition" {
+				ranges[id] = el
+			}
+		case "documentSymbolResult":
+			var symbols []lsifSymbol
+			if err := json.Unmarshal(el.Result, &symbols); err != nil {
+				return err
+			}
+			results[id] = symbols
+		case "contains":
+			if doc := documents[lsifID(el.OutV)]; doc != nil {
+				for _, in := range el.InVs {
+					doc.ranges = append(doc.ranges, lsifID(in))
+				}
+			}
+		case "textDocument/documentSymbol":
+			if doc := documents[lsifID(el.OutV)]; doc != nil {
+				doc.symbols = lsifID(el.InV)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	for _, id := range order {
+		doc := documents[id]
+		if symbols, ok := results[doc.symbols]; ok && doc.symbols != "" {
+			for _, s := range symbols {
+				addLSIFSymbol(b, doc, ranges, s, "", "")
+			}
+			continue
+		}
+		for _, rid := range doc.ranges {
+			if el, ok := ranges[rid]; ok {
+				addLSIFRange(b, doc, el)
+			}
+		}
+	}
+	return nil
+}
+
+// addLSIFSymbol records a document symbol and its children but for the
+// locals of functions; parent is the type it is nested in, pkg its
+// namespace
+func addLSIFSymbol(b *builder, doc *lsifDocument, ranges map[string]lsifElement, s lsifSymbol, parent, pkg string) {
+	name, kind, rng, detail := s.Name, s.Kind, s.Range, s.Detail
+	if len(s.ID) > 0 {
+		el, ok := ranges[lsifID(s.ID)]
+		if !ok {
+			return
+		}
+		name, kind, detail = el.Tag.Text, el.Tag.Kind, el.Tag.Detail
+		rng = lspRange{Start: el.Start, End: el.End}
+		if el.Tag.FullRange != nil {
+			rng = *el.Tag.FullRange
+		}
+	}
+	childParent, childPkg := parent, pkg
+	switch lspSymbolKinds[kind] {
+	case "module", "namespace", "package":
+		childParent, childPkg = "", name
+	case "class", "interface", "struct", "enum":
+		childParent = name
+	}
+	entry := ragcode.SymbolEntry{
+		Name:      name,
+		Kind:      symbolKind(doc.language, lspSymbolKinds[kind], parent != ""),
+		Receiver:  parent,
+		Package:   pkg,
+		StartLine: rng.Start.Line + 1,
+		EndLine:   rng.End.Line + 1,
+	}
+	if detail != "" {
+		entry.Signature = name + " " + detail
+	}
+	if entry.Kind != "method" {
+		entry.Receiver = ""
+	}
+	b.add(doc.path, doc.language, entry, false)
+	if entry.Kind == "function" || entry.Kind == "method" {
+		return // locals
+	}
+	for _, child := range s.Children {
+		addLSIFSymbol(b, doc, ranges, child, childParent, childPkg)
+	}
+}
+
+// addLSIFRange records a range tagged as a definition. Tags do not nest, so
+// methods come without their receiver, and variables are left out: locals
+// cannot be told from package variables.
+func addLSIFRange(b *builder, doc *lsifDocument, el lsifElement) {
+	kind := lspSymbolKinds[el.Tag.Kind]
+	if kind == "variable" {
+		return
+	}
+	rng := lspRange{Start: el.Start, End: el.End}
+	if el.Tag.FullRange != nil {
+		rng = *el.Tag.FullRange
+	}
+	entry := ragcode.SymbolEntry{
+		Name:      el.Tag.Text,
+		Kind:      symbolKind(doc.language, kind, kind == "method" || kind == "constructor"),
+		StartLine: rng.Start.Line + 1,
+		EndLine:   rng.End.Line + 1,
+	}
+	b.add(doc.path, doc.language, entry, false)
+}
+
+// uriPath returns the path of a file: URI, and other strings as they are
+func uriPath(uri string) string {
+	if !strings.HasPrefix(uri, "file:") {
+		return uri
+	}
+	u, err := url.Parse(uri)
+	if err != nil {
+		return strings.TrimPrefix(uri, "file://")
+	}
+	return filepath.FromSlash(u.Path)
+}
@@ -0,0 +1,418 @@
+package symbolimport
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"google.golang.org/protobuf/encoding/protowire"
+
+	"github.com/doITmagic/rag-code-mcp/internal/codetypes"
+	"github.com/doITmagic/rag-code-mcp/internal/ragcode"
+)
+
+// Field numbers of the SCIP messages readSCIP reads (scip.proto)
+const (
+	scipIndexMetadata  = 1
+	scipIndexDocuments = 2
+
+	scipMetadataProjectRoot = 3
+
+	scipDocumentPath        = 1
+	scipDocumentOccurrences = 2
+	scipDocumentSymbols     = 3
+	scipDocumentLanguage    = 4
+	scipDocumentText        = 5
+
+	scipOccurrenceRange          = 1
+	scipOccurrenceSymbol         = 2
+	scipOccurrenceRoles          = 3
+	scipOccurrenceEnclosingRange = 7
+
+	scipSymbolSymbol        = 1
+	scipSymbolKind          = 5
+	scipSymbolDisplayName   = 6
+	scipSymbolSignatureDocs = 7
+
+	scipRoleDefinition = 0x1
+)
+
+// SCIP symbol kinds (SymbolInformation.Kind) refining the descriptors
+var scipSymbolKinds = map[uint64]string{
+	7:  "class",
+	8:  "constant",
+	11: "enum",
+	21: "interface",
+	49: "struct",
+	53: "trait",
+	54: "type",
+	55: "talias",
+}
+
+// protoField is a field of a protobuf message, bytes or varint
+type protoField struct {
+	num    protowire.Number
+	typ    protowire.Type
+	bytes  []byte
+	varint uint64
+}
+
+// protoFields splits a protobuf message into its fields, skipping those of
+// other wire types
+func protoFields(b []byte) ([]protoField, error) {
+	var fields []protoField
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return nil, protowire.ParseError(n)
+		}
+		b = b[n:]
+		f := protoField{num: num, typ: typ}
+		switch typ {
+		case protowire.BytesType:
+			f.bytes, n = protowire.ConsumeBytes(b)
+		case protowire.VarintType:
+			f.varint, n = protowire.ConsumeVarint(b)
+		default:
+			n = protowire.ConsumeFieldValue(num, typ, b)
+		}
+		if n < 0 {
+			return nil, protowire.ParseError(n)
+		}
+		b = b[n:]
+		if typ == protowire.BytesType || typ == protowire.VarintType {
+			fields = append(fields, f)
+		}
+	}
+	return fields, nil
+}
+
+// int32s decodes a repeated int32 field, packed or not, appending to out
+func (f protoField) int32s(out []int32) []int32 {
+	if f.typ == protowire.VarintType {
+		return append(out, int32(f.varint))
+	}
+	b := f.bytes
+	for len(b) > 0 {
+		v, n := protowire.ConsumeVarint(b)
+		if n < 0 {
+			return out
+		}
+		out = append(out, int32(v))
+		b = b[n:]
+	}
+	return out
+}
+
+type scipOccurrence struct {
+	symbol    string
+	rng       []int32
+	enclosing []int32
+	roles     uint64
+}
+
+type scipSymbolInfo struct {
+	kind        uint64
+	displayName string
+	signature   string
+}
+
+// readSCIP reads the definitions of a SCIP index: the occurrences with the
+// definition role of global symbols, named and kinded by their descriptors.
+func readSCIP(data []byte, b *builder) error {
+	fields, err := protoFields(data)
+	if err != nil {
+		return err
+	}
+	for _, f := range fields {
+		if f.num != scipIndexMetadata || f.typ != protowire.BytesType {
+			continue
+		}
+		meta, err := protoFields(f.bytes)
+		if err != nil {
+			return fmt.Errorf("metadata: %w", err)
+		}
+		for _, m := range meta {
+			if m.num == scipMetadataProjectRoot {
+				if root := uriPath(string(m.bytes)); root != "" {
+					b.projectRoot = root
+				}
+			}
+		}
+	}
+	for _, f := range fields {
+		if f.num != scipIndexDocuments || f.typ != protowire.BytesType {
+			continue
+		}
+		if err := readSCIPDocument(f.bytes, b); err != nil {
+			return fmt.Errorf("document: %w", err)
+		}
+	}
+	return nil
+}
+
+func readSCIPDocument(data []byte, b *builder) error {
+	fields, err := protoFields(data)
+	if err != nil {
+		return err
+	}
+	var (
+		relPath, language string
+		occurrences       []scipOccurrence
+		infos             = make(map[string]scipSymbolInfo)
+	)
+	for _, f := range fields {
+		switch f.num {
+		case scipDocumentPath:
+			relPath = string(f.bytes)
+		case scipDocumentLanguage:
+			language = string(f.bytes)
+		case scipDocumentOccurrences:
+			occ, err := readSCIPOccurrence(f.bytes)
+			if err != nil {
+				return err
+			}
+			if occ.roles&scipRoleDefinition != 0 && !strings.HasPrefix(occ.symbol, "local ") {
+				occurrences = append(occurrences, occ)
+			}
+		case scipDocumentSymbols:
+			symbol, info, err := readSCIPSymbolInfo(f.bytes)
+			if err != nil {
+				return err
+			}
+			infos[symbol] = info
+		}
+	}
+	if relPath == "" {
+		return nil
+	}
+	if !codetypes.NormalizeLanguage(language).Valid() {
+		language = "" // by extension
+	}
+	for _, occ := range occurrences {
+		if len(occ.rng) < 3 {
+			continue
+		}
+		entry, ok := scipEntry(occ.symbol, infos[occ.symbol], language, relPath)
+		if !ok {
+			continue
+		}
+		entry.StartLine = int(occ.rng[0]) + 1
+		switch len(occ.enclosing) {
+		case 4:
+			entry.EndLine = int(occ.enclosing[2]) + 1
+		case 3:
+			entry.EndLine = int(occ.enclosing[0]) + 1
+		}
+		b.add(relPath, language, entry, false)
+	}
+	return nil
+}
+
+func readSCIPOccurrence(data []byte) (scipOccurrence, error) {
+	var occ scipOccurrence
+	fields, err := protoFields(data)
+	if err != nil {
+		return occ, err
+	}
+	for _, f := range fields {
+		switch f.num {
+		case scipOccurrenceRange:
+			occ.rng = f.int32s(occ.rng)
+		case scipOccurrenceSymbol:
+			occ.symbol = string(f.bytes)
+		case scipOccurrenceRoles:
+			occ.roles = f.varint
+		case scipOccurrenceEnclosingRange:
+			occ.enclosing = f.int32s(occ.enclosing)
+		}
+	}
+	return occ, nil
+}
+
+func readSCIPSymbolInfo(data []byte) (string, scipSymbolInfo, error) {
+	var (
+		symbol string
+		info   scipSymbolInfo
+	)
+	fields, err := protoFields(data)
+	if err != nil {
+		return "", info, err
+	}
+	for _, f := range fields {
+		switch f.num {
+		case scipSymbolSymbol:
+			symbol = string(f.bytes)
+		case scipSymbolKind:
+			info.kind = f.varint
+		case scipSymbolDisplayName:
+			info.displayName = string(f.bytes)
+		case scipSymbolSignatureDocs:
+			// a Document whose text is the signature
+			doc, err := protoFields(f.bytes)
+			if err != nil {
+				return "", info, err
+			}
+			for _, d := range doc {
+				if d.num == scipDocumentText {
+					info.signature = string(d.bytes)
+				}
+			}
+		}
+	}
+	return symbol, info, nil
+}
+
+// scipDescriptor is a descriptor of a SCIP symbol, by its suffix: '/'
+// namespace, '#' type, '.' term, 'm' method, ':' meta, '!' macro, '['
+// type parameter, '(' parameter
+type scipDescriptor struct {
+	name   string
+	suffix byte
+}
+
+// scipEntry maps a global SCIP symbol to a symbol table entry, without its
+// lines
+func scipEntry(symbol string, info scipSymbolInfo, language, relPath string) (ragcode.SymbolEntry, bool) {
+	descriptors, ok := parseSCIPSymbol(symbol)
+	if !ok || len(descriptors) == 0 {
+		return ragcode.SymbolEntry{}, false
+	}
+	if language == "" {
+		language = extOf(relPath)
+	}
+	last := descriptors[len(descriptors)-1]
+	var parent *scipDescriptor
+	if len(descriptors) > 1 {
+		parent = &descriptors[len(descriptors)-2]
+	}
+	member := parent != nil && parent.suffix == '#'
+
+	entry := ragcode.SymbolEntry{Name: last.name, Signature: info.signature}
+	if info.displayName != "" {
+		entry.Name = info.displayName
+	}
+	kind := ""
+	switch last.suffix {
+	case '#':
+		kind = "type"
+		if k := scipSymbolKinds[info.kind]; k != "" && k != "constant" {
+			kind = k
+		}
+		member = false
+	case 'm':
+		kind = "function"
+	case '.':
+		if member {
+			return entry, false // fields
+		}
+		kind = "var"
+		if scipSymbolKinds[info.kind] == "constant" {
+			kind = "constant"
+		}
+	default:
+		return entry, false
+	}
+	if entry.Kind = symbolKind(language, kind, member); entry.Kind == "" {
+		return entry, false
+	}
+	if member {
+		entry.Receiver = parent.name
+	}
+	for i := len(descriptors) - 1; i >= 0; i-- {
+		if descriptors[i].suffix == '/' {
+			entry.Package = path.Base(descriptors[i].name)
+			break
+		}
+	}
+	return entry, true
+}
+
+// parseSCIPSymbol returns the descriptors of a global SCIP symbol:
+// <scheme> <manager> <package> <version> <descriptors>, where spaces in the
+// first four are doubled
+func parseSCIPSymbol(symbol string) ([]scipDescriptor, bool) {
+	rest := symbol
+	for i := 0; i < 4; i++ {
+		j := 0
+		for {
+			k := strings.IndexByte(rest[j:], ' ')
+			if k < 0 {
+				return nil, false
+			}
+			j += k
+			if j+1 < len(rest) && rest[j+1] == ' ' {
+				j += 2 // escaped space
+				continue
+			}
+			break
+		}
+		rest = rest[j+1:]
+	}
+
+	var descriptors []scipDescriptor
+	for rest != "" {
+		switch rest[0] {
+		case '[', '(':
+			closing := map[byte]byte{'[': ']', '(': ')'}[rest[0]]
+			name, tail, ok := scipName(rest[1:])
+			if !ok || tail == "" || tail[0] != closing {
+				return nil, false
+			}
+			descriptors = append(descriptors, scipDescriptor{name: name, suffix: rest[0]})
+			rest = tail[1:]
+			continue
+		}
+		name, tail, ok := scipName(rest)
+		if !ok || tail == "" {
+			return nil, false
+		}
+		switch tail[0] {
+		case '/', '#', '.', ':', '!':
+			descriptors = append(descriptors, scipDescriptor{name: name, suffix: tail[0]})
+			rest = tail[1:]
+		case '(':
+			// method(disambiguator).
+			end := strings.Index(tail, ").")
+			if end < 0 {
+				return nil, false
+			}
+			descriptors = append(descriptors, scipDescriptor{name: name, suffix: 'm'})
+			rest = tail[end+2:]
+		default:
+			return nil, false
+		}
+	}
+	return descriptors, true
+}
+
+// scipName reads a descriptor name, plain or between backticks with
+// doubled backticks inside
+func scipName(s string) (name, rest string, ok bool) {
+	if strings.HasPrefix(s, "`") {
+		var sb strings.Builder
+		for i := 1; i < len(s); i++ {
+			if s[i] != '`' {
+				sb.WriteByte(s[i])
+				continue
+			}
+			if i+1 < len(s) && s[i+1] == '`' {
+				sb.WriteByte('`')
+				i++
+				continue
+			}
+			return sb.String(), s[i+1:], true
+		}
+		return "", "", false
+	}
+	i := 0
+	for i < len(s) && isSCIPNameChar(s[i]) {
+		i++
+	}
+	return s[:i], s[i:], i > 0
+}
+
+func isSCIPNameChar(c byte) bool {
+	return c == '_' || c == '+' || c == '-' || c == '$' ||
+		('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || ('0' <= c && c <= '9') || c >= 0x80
+}
@@ -0,0 +1,316 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/doITmagic/rag-code-mcp/internal/llm"
+	"github.com/doITmagic/rag-code-mcp/internal/memory"
+	"github.com/doITmagic/rag-code-mcp/internal/workspace"
+)
+
+// ABSearchTool runs the same query against the main embedding model and the
+// A/B model (llm.ab_embed) and compares the two result lists, to evaluate an
+// embedding model on a real codebase before switching to it.
+type ABSearchTool struct {
+	workspaceManager *workspace.Manager
+	embedder         llm.Provider
+}
+
+// NewABSearchTool creates a new ab_search tool
+func NewABSearchTool(wm *workspace.Manager, embedder llm.Provider) *ABSearchTool {
+	return &ABSearchTool{
+		workspaceManager: wm,
+		embedder:         embedder,
+	}
+}
+
+// ABResult is one entry of an ab_search result list.
+type ABResult struct {
+	Rank    int     `json:"rank"`
+	ChunkID string  `json:"chunk_id"`
+	Name    string  `json:"name"`
+	Kind    string  `json:"kind,omitempty"`
+	File    string  `json:"file,omitempty"`
+	Line    int     `json:"line,omitempty"`
+	Score   float64 `json:"score"`
+	InOther bool    `json:"in_other"` // also returned by the other model
+}
+
+// ABMetrics measures how much the two result lists agree.
+type ABMetrics struct {
+	Overlap      int     `json:"overlap"`        // results returned by both models
+	Jaccard      float64 `json:"jaccard"`        // overlap / size of the union
+	RBO          float64 `json:"rbo"`            // rank-biased overlap (p=0.9): 1 = same order, weighted to the top
+	Top1Agree    bool    `json:"top1_agree"`     // both models rank the same result first
+	MeanRankDiff float64 `json:"mean_rank_diff"` // mean rank distance of the shared results
+}
+
+// ABReport is the JSON output of ab_search.
+type ABReport struct {
+	Query    string     `json:"query"`
+	Language string     `json:"language"`
+	ModelA   string     `json:"model_a"`
+	ModelB   string     `json:"model_b"`
+	ResultsA []ABResult `json:"results_a"`
+	ResultsB []ABResult `json:"results_b"`
+	Metrics  ABMetrics  `json:"metrics"`
+}
+
+func (t *ABSearchTool) Name() string {
+	return "ab_search"
+}
+
+func (t *ABSearchTool) Description() string {
+	return "Diagnostic: compare two embedding models on this codebase - runs the query against the main index and a parallel index built with llm.ab_embed, and shows both result lists side by side with overlap metrics (overlap, Jaccard, rank-biased overlap). The first call builds the second index in the background; rebuild=true re-embeds it after code changes. Use output_format=json to feed an evaluation script."
+}
+
+func (t *ABSearchTool) Execute(ctx context.Context, params map[string]interface{}) (string, error) {
+	query, _ := params["query"].(string)
+	if strings.TrimSpace(query) == "" {
+		return "", fmt.Errorf("query parameter is required")
+	}
+	limit := 10
+	if l, ok := params["limit"].(float64); ok && l > 0 {
+		limit = int(l)
+	} else if l, ok := params["limit"].(int); ok && l > 0 {
+		limit = l
+	}
+	rebuild, _ := params["rebuild"].(bool)
+	outputFormat := outputFormatFrom(params, formatMarkdown)
+
+	if t.workspaceManager == nil {
+		return "", fmt.Errorf("workspace manager not configured")
+	}
+	embedderB, modelB := t.workspaceManager.ABEmbedder()
+	if embedderB == nil {
+		return "❌ A/B testing is not configured.\n\n" +
+			"Set a second embedding model in config.yaml and restart the server:\n" +
+			"llm:\n" +
+			"  ab_embed: mxbai-embed-large", nil
+	}
+
+	filePath := extractFilePathFromParams(params)
+	if filePath == "" {
+		return "", fmt.Errorf("file_path parameter is required for ab_search. Please provide a file path from your workspace")
+	}
+	info, err := t.workspaceManager.DetectWorkspace(params)
+	if err != nil {
+		return "", fmt.Errorf("failed to detect workspace: %w", err)
+	}
+	language := inferLanguageFromPath(filePath)
+	if language == "" && len(info.Languages) > 0 {
+		language = info.Languages[0]
+	}
+	if language == "" {
+		language = info.ProjectType
+	}
+
+	memA, err := t.workspaceManager.GetMemoryForWorkspaceLanguage(ctx, info, language)
+	if err != nil {
+		return "", fmt.Errorf("failed to open index of workspace '%s': %w", info.Root, err)
+	}
+	if t.workspaceManager.IsIndexing(info.ID + "-" + language) {
+		return fmt.Sprintf("⏳ Workspace '%s' language '%s' is currently being indexed in the background.\n"+
+			"Please try again in a few moments.", info.Root, language), nil
+	}
+
+	abKey := workspace.ABIndexKey(info, language)
+	if t.workspaceManager.IsIndexing(abKey) {
+		return fmt.Sprintf("⏳ The %s index of workspace '%s' language '%s' is being built in the background.\n"+
+			"Please try again in a few moments.", modelB, info.Root, language), nil
+	}
+	memB, err := t.workspaceManager.ABMemory(ctx, info, language)
+	if err != nil {
+		return "", err
+	}
+	if memB == nil || rebuild {
+		t.workspaceManager.StartABIndexing(info, language)
+		return fmt.Sprintf("⏳ Building the %s index of workspace '%s' language '%s' in the background.\n"+
+			"All code is embedded again with the second model, which takes about as long as the first indexing.\n"+
+			"Call ab_search again once it is done.\n"+
+			"Collection: %s", modelB, info.Root, language,
+			workspace.ABCollectionName(info.CollectionNameForLanguage(language), modelB)), nil
+	}
+
+	vectorA, err := embedQuery(ctx, t.workspaceManager, params, t.embedder, query)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate query embedding: %w", err)
+	}
+	vectorB, err := embedderB.Embed(ctx, query)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate query embedding with %s: %w", modelB, err)
+	}
+	docsA, err := searchCodeOnly(ctx, memA, vectorA, limit)
+	if err != nil {
+		return "", fmt.Errorf("search failed: %w", err)
+	}
+	docsB, err := searchCodeOnly(ctx, memB, vectorB, limit)
+	if err != nil {
+		return "", fmt.Errorf("search with %s failed: %w", modelB, err)
+	}
+
+	report := ABReport{
+		Query:    query,
+		Language: language,
+		ModelA:   t.workspaceManager.EmbedModel(),
+		ModelB:   modelB,
+	}
+	report.ResultsA, report.ResultsB, report.Metrics = compareABResults(docsA, docsB)
+
+	if outputFormat == formatJSON {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return "", fmt.Errorf("failed to marshal ab_search results: %w", err)
+		}
+		return string(data), nil
+	}
+	return formatABReport(report), nil
+}
+
+// searchCodeOnly searches code chunks, excluding docs.
+func searchCodeOnly(ctx context.Context, mem memory.LongTermMemory, vector []float64, limit int) ([]memory.Document, error) {
+	return mem.Query(ctx, memory.SearchOptions{Vector: vector, Filter: memory.CodeOnly(), Limit: limit})
+}
+
+// compareABResults turns both result lists into ABResults, matched by chunk ID
+// (IDs are derived from file, lines and name, so they agree across models),
+// and computes the agreement metrics.
+func compareABResults(docsA, docsB []memory.Document) ([]ABResult, []ABResult, ABMetrics) {
+	toResults := func(docs []memory.Document) []ABResult {
+		out := make([]ABResult, 0, len(docs))
+		for i, desc := range buildSymbolDescriptorsFromDocs(docs) {
+			r := ABResult{
+				Rank:    i + 1,
+				ChunkID: stableChunkID(docs[i]),
+				Name:    desc.Name,
+				Kind:    desc.Kind,
+				File:    desc.Location.FilePath,
+				Line:    desc.Location.StartLine,
+				Score:   getFloat(docs[i].Metadata["score"]),
+			}
+			if r.File == "" {
+				r.File, _ = docs[i].Metadata["file"].(string)
+			}
+			out = append(out, r)
+		}
+		return out
+	}
+	a, b := toResults(docsA), toResults(docsB)
+
+	rankA := make(map[string]int, len(a))
+	for _, r := range a {
+		rankA[r.ChunkID] = r.Rank
+	}
+	rankB := make(map[string]int, len(b))
+	for _, r := range b {
+		rankB[r.ChunkID] = r.Rank
+	}
+
+	var m ABMetrics
+	rankDiff := 0
+	for i := range a {
+		if rb, ok := rankB[a[i].ChunkID]; ok {
+			a[i].InOther = true
+			m.Overlap++
+			rankDiff += int(math.Abs(float64(a[i].Rank - rb)))
+		}
+	}
+	for i := range b {
+		_, b[i].InOther = rankA[b[i].ChunkID]
+	}
+	if union := len(a) + len(b) - m.Overlap; union > 0 {
+		m.Jaccard = round3(float64(m.Overlap) / float64(union))
+	}
+	if m.Overlap > 0 {
+		m.MeanRankDiff = round3(float64(rankDiff) / float64(m.Overlap))
+	}
+	m.Top1Agree = len(a) > 0 && len(b) > 0 && a[0].ChunkID == b[0].ChunkID
+	m.RBO = round3(rankBiasedOverlap(a, b, 0.9))
+	return a, b, m
+}
+
+// rankBiasedOverlap is the extrapolated rank-biased overlap of two rankings
+// (Webber et al., 2010), up to the depth of the shorter one: the agreement at
+// each depth, weighted by p^d so the top of the lists counts most.
+func rankBiasedOverlap(a, b []ABResult, p float64) float64 {
+	depth := len(a)
+	if len(b) < depth {
+		depth = len(b)
+	}
+	if depth == 0 {
+		if len(a) == len(b) {
+			return 1
+		}
+		return 0
+	}
+	seenA := make(map[string]bool)
+	seenB := make(map[string]bool)
+	overlap, sum := 0, 0.0
+	for d := 1; d <= depth; d++ {
+		idA, idB := a[d-1].ChunkID, b[d-1].ChunkID
+		if idA == idB {
+			overlap++
+		} else {
+			if seenB[idA] {
+				overlap++
+			}
+			if seenA[idB] {
+				overlap++
+			}
+		}
+		seenA[idA], seenB[idB] = true, true
+		sum += float64(overlap) / float64(d) * math.Pow(p, float64(d))
+	}
+	return float64(overlap)/float64(depth)*math.Pow(p, float64(depth)) + (1-p)/p*sum
+}
+
+func round3(v float64) float64 {
+	return math.Round(v*1000) / 1000
+}
+
+func formatABReport(r ABReport) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("🆎 A/B search for %q (%s)\n\n", r.Query, r.Language))
+	sb.WriteString(fmt.Sprintf("- Model A: %s (%d results)\n", r.ModelA, len(r.ResultsA)))
+	sb.WriteString(fmt.Sprintf("- Model B: %s (%d results)\n", r.ModelB, len(r.ResultsB)))
+	sb.WriteString(fmt.Sprintf("- Overlap: %d | Jaccard: %.3f | RBO: %.3f | Same top result: %v | Mean rank shift: %.1f\n\n",
+		r.Metrics.Overlap, r.Metrics.Jaccard, r.Metrics.RBO, r.Metrics.Top1Agree, r.Metrics.MeanRankDiff))
+
+	label := func(res ABResult) string {
+		mark := ""
+		if res.InOther {
+			mark = " ✓"
+		}
+		loc := res.File
+		if res.Line > 0 {
+			loc = fmt.Sprintf("%s:%d", res.File, res.Line)
+		}
+		name := res.Name
+		if name == "" {
+			name = res.Kind
+		}
+		return fmt.Sprintf("`%s` %s (%.3f)%s", name, loc, res.Score, mark)
+	}
+
+	sb.WriteString(fmt.Sprintf("| # | A: %s | B: %s |\n|---|---|---|\n", r.ModelA, r.ModelB))
+	rows := len(r.ResultsA)
+	if len(r.ResultsB) > rows {
+		rows = len(r.ResultsB)
+	}
+	for i := 0; i < rows; i++ {
+		cellA, cellB := "", ""
+		if i < len(r.ResultsA) {
+			cellA = label(r.ResultsA[i])
+		}
+		if i < len(r.ResultsB) {
+			cellB = label(r.ResultsB[i])
+		}
+		sb.WriteString(fmt.Sprintf("| %d | %s | %s |\n", i+1, cellA, cellB))
+	}
+	sb.WriteString("\n✓ = also returned by the other model\n")
+	return sb.String()
+}
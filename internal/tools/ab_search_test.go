@@ -0,0 +1,43 @@
+package tools
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/doITmagic/rag-code-mcp/internal/memory"
+)
+
+func TestCompareABResults(t *testing.T) {
+	doc := func(id string) memory.Document {
+		return memory.Document{ID: id, Content: "func " + id + "() {}", Metadata: map[string]interface{}{"file": "/app/" + id + ".go", "score": 0.5}}
+	}
+	docsA := []memory.Document{doc("a"), doc("b"), doc("c"), doc("d")}
+	docsB := []memory.Document{doc("b"), doc("a"), doc("e"), doc("c")}
+
+	resultsA, resultsB, m := compareABResults(docsA, docsB)
+	if m.Overlap != 3 || m.Jaccard != 0.6 || m.Top1Agree || m.MeanRankDiff != 1 {
+		t.Errorf("metrics = %+v, want overlap 3, jaccard 0.6, mean rank diff 1", m)
+	}
+	if !resultsA[0].InOther || resultsA[3].InOther || resultsB[2].InOther {
+		t.Errorf("in_other flags wrong: A=%+v B=%+v", resultsA, resultsB)
+	}
+	if resultsA[0].File != "/app/a.go" || resultsA[0].Score != 0.5 {
+		t.Errorf("result = %+v", resultsA[0])
+	}
+
+	if _, _, same := compareABResults(docsA, docsA); same.RBO != 1 || !same.Top1Agree {
+		t.Errorf("identical lists: %+v, want rbo 1", same)
+	}
+	if _, _, none := compareABResults(docsA[:2], docsB[2:3]); none.RBO != 0 || none.Overlap != 0 {
+		t.Errorf("disjoint lists: %+v, want rbo 0", none)
+	}
+	if m.RBO <= 0 || m.RBO >= 1 || math.IsNaN(m.RBO) {
+		t.Errorf("rbo = %v, want between 0 and 1", m.RBO)
+	}
+
+	out := formatABReport(ABReport{Query: "q", ModelA: "a-model", ModelB: "b-model", ResultsA: resultsA, ResultsB: resultsB, Metrics: m})
+	if !strings.Contains(out, "| # | A: a-model | B: b-model |") || !strings.Contains(out, "Overlap: 3") {
+		t.Errorf("report:\n%s", out)
+	}
+}
@@ -0,0 +1,112 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/doITmagic/rag-code-mcp/internal/codetypes"
+	"github.com/doITmagic/rag-code-mcp/internal/workspace"
+)
+
+// AnalyzeBufferTool analyzes the unsaved content of an editor buffer. The
+// result is kept in memory for the calling client and shadows the indexed
+// chunks of the file in search_code and hybrid_search until the buffer is
+// saved or cleared.
+type AnalyzeBufferTool struct {
+	workspaceManager *workspace.Manager
+}
+
+// NewAnalyzeBufferTool creates a new analyze_buffer tool
+func NewAnalyzeBufferTool(wm *workspace.Manager) *AnalyzeBufferTool {
+	return &AnalyzeBufferTool{
+		workspaceManager: wm,
+	}
+}
+
+// AnalyzeBufferResult lists the chunks found in a buffer, or the buffers
+// cleared
+type AnalyzeBufferResult struct {
+	File     string                       `json:"file"`
+	Language string                       `json:"language,omitempty"`
+	Cleared  int                          `json:"cleared,omitempty"`
+	Chunks   []codetypes.SymbolDescriptor `json:"chunks"`
+}
+
+func (t *AnalyzeBufferTool) Name() string {
+	return "analyze_buffer"
+}
+
+func (t *AnalyzeBufferTool) Description() string {
+	return "Analyze the unsaved content of an editor buffer (file_path + content) without writing it to disk. Until the file is saved or the buffer cleared (clear=true), search_code and hybrid_search for your session see the buffer instead of the indexed file. Returns the symbols found in the buffer."
+}
+
+func (t *AnalyzeBufferTool) Execute(ctx context.Context, args map[string]interface{}) (string, error) {
+	if t.workspaceManager == nil {
+		return "", fmt.Errorf("workspace manager not configured")
+	}
+	filePath := extractFilePathFromParams(args)
+	if filePath == "" {
+		return "", fmt.Errorf("file_path parameter is required for analyze_buffer: the file the buffer belongs to")
+	}
+	info, err := t.workspaceManager.DetectWorkspace(args)
+	if err != nil {
+		return "", fmt.Errorf("failed to detect workspace: %w", err)
+	}
+	client := ClientSession(ctx)
+
+	result := AnalyzeBufferResult{File: filePath, Chunks: []codetypes.SymbolDescriptor{}}
+	if clear, _ := args["clear"].(bool); clear {
+		path := filePath
+		if all, _ := args["all"].(bool); all {
+			path = ""
+		}
+		result.Cleared = t.workspaceManager.ClearOverlay(info, client, path)
+	} else {
+		content, ok := args["content"].(string)
+		if !ok {
+			return "", fmt.Errorf("content parameter is required for analyze_buffer: the current text of the buffer (or clear=true to drop it)")
+		}
+		overlay, err := t.workspaceManager.SetOverlay(ctx, info, client, filePath, []byte(content))
+		if err != nil {
+			return "", err
+		}
+		result.File = overlay.Path
+		result.Language = overlay.Language
+		for _, desc := range buildSymbolDescriptorsFromDocs(overlay.Docs) {
+			delete(desc.Metadata, "snippet")
+			result.Chunks = append(result.Chunks, desc)
+		}
+	}
+
+	switch outputFormatFrom(args, formatMarkdown) {
+	case formatJSON:
+		data, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return "", fmt.Errorf("failed to marshal analyze_buffer result: %w", err)
+		}
+		return string(data), nil
+	case formatMinimal:
+		return formatMinimalDescriptors(result.Chunks), nil
+	}
+	return formatAnalyzeBufferResult(result, args), nil
+}
+
+func formatAnalyzeBufferResult(r AnalyzeBufferResult, args map[string]interface{}) string {
+	var sb strings.Builder
+	if clear, _ := args["clear"].(bool); clear {
+		sb.WriteString(fmt.Sprintf("🧹 Cleared %d unsaved buffer(s); searches see the indexed files again.\n", r.Cleared))
+		return sb.String()
+	}
+	sb.WriteString(fmt.Sprintf("📝 Analyzed unsaved buffer of `%s` (%s): %d chunk(s)\n\n", r.File, r.Language, len(r.Chunks)))
+	for _, c := range r.Chunks {
+		sb.WriteString(fmt.Sprintf("- `%s` (%s) lines %d-%d", c.Name, c.Kind, c.Location.StartLine, c.Location.EndLine))
+		if partial, _ := c.Metadata[codetypes.MetaPartial].(bool); partial {
+			sb.WriteString(" [partial]")
+		}
+		sb.WriteString("\n")
+	}
+	sb.WriteString("\nSearches in this session use the buffer instead of the file on disk until it is saved or cleared.\n")
+	return sb.String()
+}
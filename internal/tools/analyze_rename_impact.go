@@ -0,0 +1,291 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/doITmagic/rag-code-mcp/internal/ragcode"
+	"github.com/doITmagic/rag-code-mcp/internal/workspace"
+)
+
+// AnalyzeRenameImpactTool lists every line a rename of a symbol touches, in
+// code, string literals, config files and documentation, so a rename patch
+// can be complete.
+type AnalyzeRenameImpactTool struct {
+	workspaceManager *workspace.Manager
+}
+
+// NewAnalyzeRenameImpactTool creates a new analyze_rename_impact tool
+func NewAnalyzeRenameImpactTool(wm *workspace.Manager) *AnalyzeRenameImpactTool {
+	return &AnalyzeRenameImpactTool{
+		workspaceManager: wm,
+	}
+}
+
+// Kinds of rename sites
+const (
+	renameDefinition = "definition"
+	renameReference  = "reference"
+	renameString     = "string"
+	renameConfig     = "config"
+	renameDoc        = "doc"
+)
+
+// renameKindOrder orders the kinds in reports
+var renameKindOrder = []string{renameDefinition, renameReference, renameString, renameConfig, renameDoc}
+
+// identifierRe matches the names analyze_rename_impact accepts
+var identifierRe = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// RenameSite is a line that mentions the renamed symbol.
+type RenameSite struct {
+	FilePath    string `json:"file_path"`
+	Line        int    `json:"line"`
+	Column      int    `json:"column"`
+	Kind        string `json:"kind"`
+	Language    string `json:"language,omitempty"`
+	Symbol      string `json:"symbol,omitempty"` // enclosing function or class
+	Code        string `json:"code"`
+	Replacement string `json:"replacement"`
+}
+
+// RenameImpact is the result of analyze_rename_impact.
+type RenameImpact struct {
+	Symbol    string                `json:"symbol"`
+	NewName   string                `json:"new_name"`
+	Files     int                   `json:"files"`
+	Counts    map[string]int        `json:"counts"`
+	Conflicts []ragcode.SymbolEntry `json:"conflicts,omitempty"` // symbols already named new_name
+	Sites     []RenameSite          `json:"sites"`
+	Truncated bool                  `json:"truncated,omitempty"`
+}
+
+func (t *AnalyzeRenameImpactTool) Name() string {
+	return "analyze_rename_impact"
+}
+
+func (t *AnalyzeRenameImpactTool) Description() string {
+	return "Before renaming a symbol, list every file and line that has to change - definitions, code references, string literals (reflection, routes, container keys), config files and doc/comment mentions - across all languages of the workspace, each with the rewritten line. Also reports existing symbols that already use the new name. Use it to produce complete rename patches instead of partial ones."
+}
+
+func (t *AnalyzeRenameImpactTool) Execute(ctx context.Context, params map[string]interface{}) (string, error) {
+	symbol, _ := params["symbol"].(string)
+	newName, _ := params["new_name"].(string)
+	symbol, newName = strings.TrimSpace(symbol), strings.TrimSpace(newName)
+	if symbol == "" || newName == "" {
+		return "", fmt.Errorf("symbol and new_name parameters are required")
+	}
+	if !identifierRe.MatchString(symbol) || !identifierRe.MatchString(newName) {
+		return "", fmt.Errorf("symbol and new_name must be plain identifiers, got %q and %q", symbol, newName)
+	}
+	if symbol == newName {
+		return "", fmt.Errorf("new_name is the same as symbol")
+	}
+	if t.workspaceManager == nil {
+		return "", fmt.Errorf("workspace manager not configured")
+	}
+	if extractFilePathFromParams(params) == "" {
+		return "", fmt.Errorf("file_path parameter is required for analyze_rename_impact. Please provide a file path from your workspace")
+	}
+	info, err := t.workspaceManager.DetectWorkspace(params)
+	if err != nil {
+		return "", fmt.Errorf("failed to detect workspace: %w", err)
+	}
+
+	limit := 500
+	if l, ok := params["limit"].(float64); ok && l > 0 {
+		limit = int(l)
+	}
+	matches, truncated, err := workspace.Grep(ctx, info.Root, workspace.GrepOptions{
+		Pattern:       `\b` + symbol + `\b`,
+		Regex:         true,
+		CaseSensitive: true,
+		MaxMatches:    limit,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	var symbols []ragcode.SymbolEntry
+	if table, err := t.workspaceManager.Symbols(info); err == nil {
+		symbols = table.All()
+	}
+	impact := renameImpact(symbol, newName, matches, symbols)
+	impact.Truncated = truncated
+
+	switch outputFormatFrom(params, formatJSON) {
+	case formatMinimal:
+		return formatRenameImpactMinimal(impact), nil
+	case formatMarkdown:
+		return formatRenameImpact(impact, info.Root), nil
+	}
+	data, err := json.MarshalIndent(impact, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal analyze_rename_impact results: %w", err)
+	}
+	return string(data), nil
+}
+
+// renameImpact classifies the whole-word matches of symbol and rewrites each
+// line with newName. A match is a definition when it is the first mention of
+// symbol inside the lines of a symbol table entry of that name.
+func renameImpact(symbol, newName string, matches []workspace.GrepMatch, symbols []ragcode.SymbolEntry) RenameImpact {
+	impact := RenameImpact{
+		Symbol:  symbol,
+		NewName: newName,
+		Counts:  make(map[string]int),
+		Sites:   []RenameSite{},
+	}
+	byFile := make(map[string][]ragcode.SymbolEntry)
+	var defs []ragcode.SymbolEntry
+	for _, s := range symbols {
+		byFile[s.FilePath] = append(byFile[s.FilePath], s)
+		switch s.Name {
+		case symbol:
+			defs = append(defs, s)
+		case newName:
+			impact.Conflicts = append(impact.Conflicts, s)
+		}
+	}
+
+	wordRe := regexp.MustCompile(`\b` + symbol + `\b`)
+	claimed := make(map[int]bool)
+	files := make(map[string]bool)
+	for _, m := range matches {
+		kind := renameSiteKind(m.Path, m.Text, m.Column)
+		if kind == renameReference {
+			for i, d := range defs {
+				if !claimed[i] && d.FilePath == m.Path && d.StartLine <= m.Line && m.Line <= d.EndLine {
+					claimed[i] = true
+					kind = renameDefinition
+					break
+				}
+			}
+		}
+		site := RenameSite{
+			FilePath:    m.Path,
+			Line:        m.Line,
+			Column:      m.Column,
+			Kind:        kind,
+			Language:    inferLanguageFromPath(m.Path),
+			Code:        strings.TrimSpace(m.Text),
+			Replacement: strings.TrimSpace(wordRe.ReplaceAllLiteralString(m.Text, newName)),
+		}
+		if s, ok := enclosingSymbol(byFile[m.Path], m.Line); ok {
+			site.Symbol = s.Name
+		}
+		impact.Sites = append(impact.Sites, site)
+		impact.Counts[kind]++
+		files[m.Path] = true
+	}
+	impact.Files = len(files)
+	return impact
+}
+
+// renameSiteKind classifies a match at the 1-based column of line by the
+// file type and by whether it sits in a comment or a string literal. Code
+// matches are reported as references.
+func renameSiteKind(path, line string, column int) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".md", ".mdx", ".rst", ".txt", ".adoc":
+		return renameDoc
+	case ".json", ".yaml", ".yml", ".toml", ".ini", ".env", ".xml", ".properties", ".neon", ".conf":
+		return renameConfig
+	}
+	if strings.HasPrefix(filepath.Base(path), ".env") {
+		return renameConfig
+	}
+
+	before := line[:column-1]
+	indented := strings.TrimLeft(before, " \t")
+	for _, prefix := range []string{"//", "/*", "* ", "<!--", "-- "} {
+		if strings.HasPrefix(indented, prefix) {
+			return renameDoc
+		}
+	}
+	if strings.HasPrefix(indented, "#") && !strings.HasPrefix(indented, "#[") {
+		return renameDoc // shell, Python, YAML-style comment; #[...] is an attribute
+	}
+	var quote rune
+	for i, r := range before {
+		switch {
+		case quote != 0 && r == quote && (i == 0 || before[i-1] != '\\'):
+			quote = 0
+		case quote == 0 && (r == '"' || r == '\'' || r == '`'):
+			quote = r
+		case quote == 0 && r == '/' && i > 0 && before[i-1] == '/' && (i < 2 || before[i-2] != ':'):
+			return renameDoc
+		}
+	}
+	if quote != 0 {
+		return renameString
+	}
+	return renameReference
+}
+
+func formatRenameImpact(impact RenameImpact, root string) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("# ✏️ Renaming `%s` to `%s`\n\n", impact.Symbol, impact.NewName))
+	if len(impact.Sites) == 0 {
+		sb.WriteString(fmt.Sprintf("No mentions of `%s` in workspace '%s'.\n", impact.Symbol, root))
+		return sb.String()
+	}
+	counts := make([]string, 0, len(renameKindOrder))
+	for _, kind := range renameKindOrder {
+		if n := impact.Counts[kind]; n > 0 {
+			counts = append(counts, fmt.Sprintf("%d %s", n, kind))
+		}
+	}
+	sb.WriteString(fmt.Sprintf("%d line(s) in %d file(s): %s\n\n", len(impact.Sites), impact.Files, strings.Join(counts, ", ")))
+	if impact.Counts[renameDefinition] == 0 {
+		sb.WriteString(fmt.Sprintf("⚠️ No definition of `%s` in the symbol table - it may be external or the workspace needs indexing.\n\n", impact.Symbol))
+	}
+	if len(impact.Conflicts) > 0 {
+		sb.WriteString(fmt.Sprintf("⚠️ `%s` is already used by:\n", impact.NewName))
+		for _, c := range impact.Conflicts {
+			sb.WriteString(fmt.Sprintf("- %s `%s:%d`\n", c.Kind, c.FilePath, c.StartLine))
+		}
+		sb.WriteString("\n")
+	}
+
+	sites := append([]RenameSite(nil), impact.Sites...)
+	rank := make(map[string]int)
+	for i, kind := range renameKindOrder {
+		rank[kind] = i
+	}
+	sort.SliceStable(sites, func(i, j int) bool {
+		return rank[sites[i].Kind] < rank[sites[j].Kind]
+	})
+	kind := ""
+	for _, s := range sites {
+		if s.Kind != kind {
+			kind = s.Kind
+			sb.WriteString(fmt.Sprintf("## %s\n\n", kind))
+		}
+		in := ""
+		if s.Symbol != "" {
+			in = fmt.Sprintf(" (in %s)", s.Symbol)
+		}
+		sb.WriteString(fmt.Sprintf("- `%s:%d:%d`%s\n  - `%s`\n  + `%s`\n", s.FilePath, s.Line, s.Column, in, s.Code, s.Replacement))
+	}
+	if impact.Truncated {
+		sb.WriteString("\n⚠️ Stopped at the match limit - raise limit to see every site.\n")
+	}
+	return sb.String()
+}
+
+func formatRenameImpactMinimal(impact RenameImpact) string {
+	if len(impact.Sites) == 0 {
+		return "No matches."
+	}
+	var sb strings.Builder
+	for _, s := range impact.Sites {
+		sb.WriteString(minimalLine(s.Kind, s.Symbol, s.FilePath, s.Line, 0, s.Replacement) + "\n")
+	}
+	return sb.String()
+}
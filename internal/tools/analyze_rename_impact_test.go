@@ -0,0 +1,84 @@
+package tools
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/doITmagic/rag-code-mcp/internal/ragcode"
+	"github.com/doITmagic/rag-code-mcp/internal/workspace"
+)
+
+func TestRenameImpact(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"client.go":   "package client\n\n// Dial opens a connection.\nfunc Dial() {}\n\nfunc Run() {\n\tDial()\n\tlog.Print(\"Dial failed\")\n\tRedial()\n}\n",
+		"config.yaml": "handler: Dial\n",
+		"README.md":   "Call `Dial` first.\n",
+	}
+	for name, src := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(src), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	goFile := filepath.Join(dir, "client.go")
+	symbols := []ragcode.SymbolEntry{
+		{Name: "Dial", Kind: "function", FilePath: goFile, StartLine: 3, EndLine: 4},
+		{Name: "Run", Kind: "function", FilePath: goFile, StartLine: 6, EndLine: 10},
+		{Name: "Connect", Kind: "function", FilePath: goFile, StartLine: 12, EndLine: 12},
+	}
+
+	matches, _, err := workspace.Grep(context.Background(), dir, workspace.GrepOptions{Pattern: `\bDial\b`, Regex: true, CaseSensitive: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	impact := renameImpact("Dial", "Connect", matches, symbols)
+
+	want := map[string]int{renameDefinition: 1, renameReference: 1, renameString: 1, renameConfig: 1, renameDoc: 2}
+	for kind, n := range want {
+		if impact.Counts[kind] != n {
+			t.Errorf("counts = %v, want %v", impact.Counts, want)
+			break
+		}
+	}
+	if impact.Files != 3 {
+		t.Errorf("files = %d, want 3", impact.Files)
+	}
+	if len(impact.Conflicts) != 1 || impact.Conflicts[0].Name != "Connect" {
+		t.Errorf("conflicts = %+v, want Connect", impact.Conflicts)
+	}
+	for _, s := range impact.Sites {
+		if s.Kind == renameDefinition && (s.Line != 4 || s.Replacement != "func Connect() {}") {
+			t.Errorf("definition site = %+v", s)
+		}
+		if s.Kind == renameReference && (s.Line != 7 || s.Symbol != "Run" || s.Replacement != "Connect()") {
+			t.Errorf("reference site = %+v", s)
+		}
+	}
+}
+
+func TestRenameSiteKind(t *testing.T) {
+	tests := []struct {
+		path, line string
+		want       string
+	}{
+		{"a.go", "x := Dial()", renameReference},
+		{"a.go", "x := Dial() // Dial here", renameDoc},
+		{"a.go", "u := \"http://host/Dial\"", renameString},
+		{"a.php", "$c->call('Dial');", renameString},
+		{"a.php", " * @see Dial", renameDoc},
+		{"a.go", "*Dial = nil", renameReference},
+		{"a.rs", "#[derive(Dial)]", renameReference},
+		{"a.py", "# Dial it", renameDoc},
+		{"conf.toml", "name = Dial", renameConfig},
+		{"docs/guide.md", "Dial", renameDoc},
+	}
+	for _, tt := range tests {
+		col := strings.LastIndex(tt.line, "Dial") + 1
+		if got := renameSiteKind(tt.path, tt.line, col); got != tt.want {
+			t.Errorf("renameSiteKind(%q, %q) = %s, want %s", tt.path, tt.line, got, tt.want)
+		}
+	}
+}
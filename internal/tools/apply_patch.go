@@ -0,0 +1,113 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/doITmagic/rag-code-mcp/internal/workspace"
+)
+
+// ApplyPatchTool applies unified diffs to workspace files. It is registered
+// only when edits.enabled is set; paths are confined to the workspace and the
+// changed files are re-indexed.
+type ApplyPatchTool struct {
+	workspaceManager *workspace.Manager
+}
+
+// NewApplyPatchTool creates a new apply_patch tool
+func NewApplyPatchTool(wm *workspace.Manager) *ApplyPatchTool {
+	return &ApplyPatchTool{
+		workspaceManager: wm,
+	}
+}
+
+func (t *ApplyPatchTool) Name() string {
+	return "apply_patch"
+}
+
+func (t *ApplyPatchTool) Description() string {
+	return "Apply a unified diff (git diff / diff -u format, e.g. from suggest_rewrites) to workspace files. All hunks must apply or nothing is written; paths outside the workspace, .git, .ragcode and configured protected paths are rejected. Originals are backed up under .ragcode/backups and the changed files are re-indexed. Use dry_run=true to check a patch first."
+}
+
+func (t *ApplyPatchTool) Execute(ctx context.Context, params map[string]interface{}) (string, error) {
+	patch, _ := params["patch"].(string)
+	if strings.TrimSpace(patch) == "" {
+		return "", fmt.Errorf("patch parameter is required")
+	}
+	if t.workspaceManager == nil {
+		return "", fmt.Errorf("workspace manager not configured")
+	}
+	edits := t.workspaceManager.Edits()
+	if !edits.Enabled {
+		return "", fmt.Errorf("apply_patch is disabled: set edits.enabled in the config (or EDITS_ENABLED=true) to allow file changes")
+	}
+	if extractFilePathFromParams(params) == "" {
+		return "", fmt.Errorf("file_path parameter is required for apply_patch. Please provide a file path from your workspace")
+	}
+	info, err := t.workspaceManager.DetectWorkspace(params)
+	if err != nil {
+		return "", fmt.Errorf("failed to detect workspace: %w", err)
+	}
+
+	dryRun, _ := params["dry_run"].(bool)
+	result, err := workspace.ApplyPatch(info.Root, patch, workspace.PatchOptions{
+		DryRun:    dryRun,
+		MaxFiles:  edits.MaxFiles,
+		Protected: edits.ProtectedPaths,
+	})
+	if err != nil {
+		return "", fmt.Errorf("patch not applied: %w", err)
+	}
+
+	var reindexed []string
+	if !dryRun {
+		paths := make([]string, 0, len(result.Files)*2)
+		for _, f := range result.Files {
+			paths = append(paths, filepath.Join(info.Root, f.Path))
+			if f.From != "" {
+				paths = append(paths, filepath.Join(info.Root, f.From))
+			}
+		}
+		reindexed = t.workspaceManager.ReindexFiles(info, paths)
+	}
+
+	if outputFormatFrom(params, formatMarkdown) == formatJSON {
+		data, err := json.MarshalIndent(struct {
+			*workspace.PatchResult
+			Reindexing []string `json:"reindexing,omitempty"`
+		}{result, reindexed}, "", "  ")
+		if err != nil {
+			return "", fmt.Errorf("failed to marshal apply_patch result: %w", err)
+		}
+		return string(data), nil
+	}
+	return formatPatchResult(result, reindexed), nil
+}
+
+func formatPatchResult(result *workspace.PatchResult, reindexed []string) string {
+	var sb strings.Builder
+	if result.DryRun {
+		sb.WriteString(fmt.Sprintf("🧪 Dry run: the patch applies cleanly to %d file(s). Nothing was written.\n\n", len(result.Files)))
+	} else {
+		sb.WriteString(fmt.Sprintf("✅ Patch applied to %d file(s).\n\n", len(result.Files)))
+	}
+	for _, f := range result.Files {
+		switch f.Action {
+		case "rename":
+			sb.WriteString(fmt.Sprintf("- rename %s → %s (+%d -%d)\n", f.From, f.Path, f.Added, f.Removed))
+		default:
+			sb.WriteString(fmt.Sprintf("- %s %s (+%d -%d)\n", f.Action, f.Path, f.Added, f.Removed))
+		}
+	}
+	if result.BackupDir != "" {
+		sb.WriteString(fmt.Sprintf("\n💾 Originals backed up to %s\n", result.BackupDir))
+		sb.WriteString(fmt.Sprintf("↩️ Undo with rollback_change (change_id: %s)\n", result.ChangeID))
+	}
+	if len(reindexed) > 0 {
+		sb.WriteString(fmt.Sprintf("♻️ Re-indexing %s in the background.\n", strings.Join(reindexed, ", ")))
+	}
+	return sb.String()
+}
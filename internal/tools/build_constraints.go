@@ -0,0 +1,75 @@
+package tools
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/doITmagic/rag-code-mcp/internal/memory"
+	"github.com/doITmagic/rag-code-mcp/internal/ragcode/analyzers/golang"
+)
+
+// buildFilter selects Go results by the build configuration they are
+// compiled in (goos, goarch, build_tags parameters)
+type buildFilter struct {
+	goos   string
+	goarch string
+	tags   []string
+}
+
+func parseBuildFilter(params map[string]interface{}) buildFilter {
+	goos, _ := params["goos"].(string)
+	goarch, _ := params["goarch"].(string)
+	return buildFilter{
+		goos:   strings.ToLower(strings.TrimSpace(goos)),
+		goarch: strings.ToLower(strings.TrimSpace(goarch)),
+		tags:   parseListParam(params["build_tags"]),
+	}
+}
+
+func (f buildFilter) active() bool {
+	return f.goos != "" || f.goarch != "" || len(f.tags) > 0
+}
+
+// String describes the filter for messages: "GOOS=windows, tags integration"
+func (f buildFilter) String() string {
+	var parts []string
+	if f.goos != "" {
+		parts = append(parts, "GOOS="+f.goos)
+	}
+	if f.goarch != "" {
+		parts = append(parts, "GOARCH="+f.goarch)
+	}
+	if len(f.tags) > 0 {
+		parts = append(parts, "tags "+strings.Join(f.tags, ","))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// filterByBuild keeps the docs built under the filter. Docs without a
+// build constraint (every non-Go result) are always kept.
+func filterByBuild(docs []memory.Document, f buildFilter) []memory.Document {
+	if !f.active() {
+		return docs
+	}
+	out := make([]memory.Document, 0, len(docs))
+	for _, doc := range docs {
+		if golang.MatchBuildConstraint(docBuildConstraint(doc), f.goos, f.goarch, f.tags) {
+			out = append(out, doc)
+		}
+	}
+	return out
+}
+
+func docBuildConstraint(doc memory.Document) string {
+	bc, _ := doc.Metadata["build_constraint"].(string)
+	return bc
+}
+
+// buildConstraintLabel renders the build constraint of a result for
+// markdown output.
+func buildConstraintLabel(doc memory.Document) string {
+	if bc := docBuildConstraint(doc); bc != "" {
+		return fmt.Sprintf(" [build %s]", bc)
+	}
+	return ""
+}
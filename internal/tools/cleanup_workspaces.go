@@ -0,0 +1,126 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/doITmagic/rag-code-mcp/internal/workspace"
+)
+
+// CleanupWorkspacesTool deletes the collections and index state of unused
+// workspaces
+type CleanupWorkspacesTool struct {
+	workspaceManager *workspace.Manager
+}
+
+// NewCleanupWorkspacesTool creates a new cleanup_workspaces tool
+func NewCleanupWorkspacesTool(wm *workspace.Manager) *CleanupWorkspacesTool {
+	return &CleanupWorkspacesTool{
+		workspaceManager: wm,
+	}
+}
+
+func (t *CleanupWorkspacesTool) Name() string {
+	return "cleanup_workspaces"
+}
+
+func (t *CleanupWorkspacesTool) Description() string {
+	return "Delete the vector collections and .ragcode index state of unused workspaces: those unused longer than older_than (default: workspace.collection_ttl), the least recently used beyond max_workspaces, and those whose directory was deleted. Evicted workspaces are re-indexed on their next query. Use dry_run to preview."
+}
+
+func (t *CleanupWorkspacesTool) Execute(ctx context.Context, params map[string]interface{}) (string, error) {
+	if t.workspaceManager == nil {
+		return "", fmt.Errorf("workspace manager not configured")
+	}
+	opts := t.workspaceManager.GCOptions()
+	opts.DryRun, _ = params["dry_run"].(bool)
+	if s, ok := params["older_than"].(string); ok && s != "" {
+		ttl, err := time.ParseDuration(s)
+		if err != nil || ttl < 0 {
+			return "", fmt.Errorf("invalid older_than %q: use a duration like 720h", s)
+		}
+		opts.TTL = ttl
+	}
+	if n, ok := params["max_workspaces"].(float64); ok {
+		if n < 0 {
+			return "", fmt.Errorf("max_workspaces must not be negative")
+		}
+		opts.MaxWorkspaces = int(n)
+	}
+
+	report, err := t.workspaceManager.CollectGarbage(ctx, opts)
+	if err != nil {
+		return "", err
+	}
+
+	if outputFormatFrom(params, formatMarkdown) == formatJSON {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return "", fmt.Errorf("failed to marshal cleanup_workspaces results: %w", err)
+		}
+		return string(data), nil
+	}
+	return FormatCleanupReport(report, time.Now()), nil
+}
+
+// FormatCleanupReport renders a garbage collection report as markdown.
+func FormatCleanupReport(r *workspace.GCReport, now time.Time) string {
+	var sb strings.Builder
+	if r.DryRun {
+		sb.WriteString("# 🧹 Workspace cleanup (dry run)\n\n")
+	} else {
+		sb.WriteString("# 🧹 Workspace cleanup\n\n")
+	}
+	if len(r.Evicted) == 0 {
+		sb.WriteString(fmt.Sprintf("Nothing to clean up: %d workspace(s) kept.\n", r.Kept))
+		return sb.String()
+	}
+
+	verb := "Evicted"
+	if r.DryRun {
+		verb = "Would evict"
+	}
+	sb.WriteString(fmt.Sprintf("%s %d workspace(s), kept %d.\n\n", verb, len(r.Evicted), r.Kept))
+	sb.WriteString("| Workspace | Reason | Last used | Collections |\n|-----------|--------|-----------|-------------|\n")
+	for _, ev := range r.Evicted {
+		sb.WriteString(fmt.Sprintf("| %s | %s | %s ago | %s |\n",
+			ev.Root, evictReasonLabel(ev.Reason), formatAge(now.Sub(ev.LastUsed)), strings.Join(ev.Collections, ", ")))
+	}
+	if len(r.Errors) > 0 {
+		sb.WriteString("\n## Errors\n\n")
+		for _, e := range r.Errors {
+			sb.WriteString("- " + e + "\n")
+		}
+	}
+	if r.DryRun {
+		sb.WriteString("\nRun again without dry_run to delete them.\n")
+	}
+	return sb.String()
+}
+
+// evictReasonLabel renders an eviction reason for humans
+func evictReasonLabel(reason string) string {
+	switch reason {
+	case workspace.EvictStale:
+		return "unused past the TTL"
+	case workspace.EvictLRU:
+		return "least recently used beyond max_workspaces"
+	case workspace.EvictMissing:
+		return "directory deleted"
+	}
+	return reason
+}
+
+// formatAge renders a duration in its largest whole unit
+func formatAge(d time.Duration) string {
+	switch {
+	case d >= 48*time.Hour:
+		return fmt.Sprintf("%dd", int(d.Hours()/24))
+	case d >= time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	}
+	return fmt.Sprintf("%dm", int(d.Minutes()))
+}
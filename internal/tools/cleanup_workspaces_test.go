@@ -0,0 +1,38 @@
+package tools
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/doITmagic/rag-code-mcp/internal/workspace"
+)
+
+func TestFormatCleanupReport(t *testing.T) {
+	now := time.Now()
+	report := &workspace.GCReport{
+		DryRun: true,
+		Kept:   2,
+		Evicted: []workspace.EvictedWorkspace{
+			{Root: "/work/old", Reason: workspace.EvictStale, LastUsed: now.Add(-10 * 24 * time.Hour), Collections: []string{"ragcode-old-go", "ragcode-old-python"}},
+			{Root: "/work/gone", Reason: workspace.EvictMissing, LastUsed: now.Add(-3 * time.Hour), Collections: []string{"ragcode-gone-go"}},
+		},
+	}
+	out := FormatCleanupReport(report, now)
+	for _, want := range []string{
+		"(dry run)",
+		"Would evict 2 workspace(s), kept 2.",
+		"| /work/old | unused past the TTL | 10d ago | ragcode-old-go, ragcode-old-python |",
+		"| /work/gone | directory deleted | 3h ago | ragcode-gone-go |",
+		"without dry_run",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output misses %q:\n%s", want, out)
+		}
+	}
+
+	out = FormatCleanupReport(&workspace.GCReport{Kept: 3}, now)
+	if !strings.Contains(out, "Nothing to clean up: 3 workspace(s) kept.") {
+		t.Errorf("empty report = %s", out)
+	}
+}
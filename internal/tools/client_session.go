@@ -0,0 +1,23 @@
+package tools
+
+import "context"
+
+type clientSessionKey struct{}
+
+// WithClientSession tags ctx with the MCP session of the client making a
+// tool call. Over HTTP several clients share the server; state a tool keeps
+// between calls (edit sessions, paginated result sets) is only visible to
+// the session that created it.
+func WithClientSession(ctx context.Context, id string) context.Context {
+	if id == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, clientSessionKey{}, id)
+}
+
+// ClientSession returns the MCP session of the client making a tool call,
+// "" over stdio where the server has a single client
+func ClientSession(ctx context.Context) string {
+	id, _ := ctx.Value(clientSessionKey{}).(string)
+	return id
+}
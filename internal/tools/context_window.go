@@ -0,0 +1,154 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/doITmagic/rag-code-mcp/internal/codetypes"
+	"github.com/doITmagic/rag-code-mcp/internal/memory"
+	"github.com/doITmagic/rag-code-mcp/internal/workspace"
+)
+
+type contextWindowKey struct{}
+
+// WithContextWindow tags ctx with the context window of the client's model,
+// in tokens. Search tools size their default results to fit it.
+func WithContextWindow(ctx context.Context, tokens int) context.Context {
+	if tokens <= 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, contextWindowKey{}, tokens)
+}
+
+// sessionContextWindow returns the context window the client announced for
+// its session, 0 when it did not
+func sessionContextWindow(ctx context.Context) int {
+	tokens, _ := ctx.Value(contextWindowKey{}).(int)
+	return tokens
+}
+
+// ContextWindowFromMeta reads the context window a client announces in the
+// _meta of its initialize request, as contextWindow or context_window
+func ContextWindowFromMeta(meta map[string]any) int {
+	for _, key := range []string{"contextWindow", "context_window"} {
+		switch v := meta[key].(type) {
+		case float64:
+			return int(v)
+		case int:
+			return v
+		}
+	}
+	return 0
+}
+
+// Tuning of the default result budget to a context window
+const (
+	defaultResults      = 5  // results without a known context window
+	defaultContextLines = 5  // get_code_context lines without a known window
+	responseShare       = 10 // a response may use 1/responseShare of the window
+	tokensPerResult     = 400
+	tokensPerLine       = 10
+	minResults          = 3
+	maxResults          = 25
+	minSnippetLines     = 8
+)
+
+// resultBudget sizes the default output of search tools. Without a known
+// context window it keeps the fixed defaults: 5 results, unclipped code and
+// 5 lines of context.
+type resultBudget struct {
+	Window       int // tokens, 0 when unknown
+	Results      int
+	SnippetLines int // code lines per result, 0 for unclipped
+	ContextLines int
+}
+
+// contextWindow returns the context window of the client making a call: the
+// context_window parameter, the window announced for its session, or
+// output.context_window
+func contextWindow(ctx context.Context, wm *workspace.Manager, params map[string]interface{}) int {
+	switch v := params["context_window"].(type) {
+	case float64:
+		if v > 0 {
+			return int(v)
+		}
+	case int:
+		if v > 0 {
+			return v
+		}
+	}
+	if tokens := sessionContextWindow(ctx); tokens > 0 {
+		return tokens
+	}
+	return wm.ContextWindow()
+}
+
+// budgetFor returns the result budget of a call. A response gets a tenth of
+// the window, shared by results of about 400 tokens (3 to 25 of them), and
+// the code of each result is clipped to its share.
+func budgetFor(ctx context.Context, wm *workspace.Manager, params map[string]interface{}) resultBudget {
+	window := contextWindow(ctx, wm, params)
+	if window <= 0 {
+		return resultBudget{Results: defaultResults, ContextLines: defaultContextLines}
+	}
+	tokens := window / responseShare
+	b := resultBudget{Window: window, Results: clampInt(tokens/tokensPerResult, minResults, maxResults)}
+	b.SnippetLines = max(tokens/b.Results/tokensPerLine, minSnippetLines)
+	b.ContextLines = clampInt(window/4000, 2, 20)
+	return b
+}
+
+// limit returns the limit parameter, or the budget's result count when the
+// call has none
+func (b resultBudget) limit(params map[string]interface{}) int {
+	switch v := params["limit"].(type) {
+	case float64:
+		if v > 0 {
+			return int(v)
+		}
+	case int:
+		if v > 0 {
+			return v
+		}
+	}
+	return b.Results
+}
+
+// clip cuts the code of each result to the budget's snippet lines. Clipped
+// chunks are marked truncated like chunks capped at indexing, so the output
+// points to get_code_context for the rest. docs is not modified.
+func (b resultBudget) clip(docs []memory.Document) []memory.Document {
+	if b.SnippetLines <= 0 {
+		return docs
+	}
+	out := make([]memory.Document, len(docs))
+	for i, doc := range docs {
+		out[i] = doc
+		var chunk codetypes.CodeChunk
+		if err := json.Unmarshal([]byte(doc.Content), &chunk); err != nil || chunk.Code == "" {
+			if lines := strings.Split(doc.Content, "\n"); len(lines) > b.SnippetLines && err != nil {
+				out[i].Content = strings.Join(lines[:b.SnippetLines], "\n") + "\n…"
+			}
+			continue
+		}
+		lines := strings.Split(chunk.Code, "\n")
+		if len(lines) <= b.SnippetLines {
+			continue
+		}
+		chunk.Code = strings.Join(lines[:b.SnippetLines], "\n")
+		if chunk.Metadata == nil {
+			chunk.Metadata = make(map[string]any)
+		}
+		chunk.Metadata["truncated"] = true
+		chunk.Metadata["code_lines"] = b.SnippetLines
+		if data, err := json.Marshal(chunk); err == nil {
+			out[i].Content = string(data)
+		}
+	}
+	return out
+}
+
+func clampInt(n, lo, hi int) int {
+	return min(max(n, lo), hi)
+}
@@ -0,0 +1,85 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/doITmagic/rag-code-mcp/internal/codetypes"
+	"github.com/doITmagic/rag-code-mcp/internal/memory"
+	"github.com/doITmagic/rag-code-mcp/internal/ragcode"
+	"github.com/doITmagic/rag-code-mcp/internal/workspace"
+)
+
+func TestBudgetFor(t *testing.T) {
+	wm := workspace.NewManager(nil, nil, nil)
+	ctx := context.Background()
+
+	b := budgetFor(ctx, wm, nil)
+	if b.Results != 5 || b.SnippetLines != 0 || b.ContextLines != 5 {
+		t.Fatalf("unknown window budget = %+v", b)
+	}
+
+	small := budgetFor(WithContextWindow(ctx, 8000), wm, nil)
+	if small.Results != 3 || small.SnippetLines != 26 || small.ContextLines != 2 {
+		t.Fatalf("8k budget = %+v", small)
+	}
+	large := budgetFor(WithContextWindow(ctx, 200000), wm, nil)
+	if large.Results != 25 || large.ContextLines != 20 {
+		t.Fatalf("200k budget = %+v", large)
+	}
+
+	// The parameter wins over the session's window
+	b = budgetFor(WithContextWindow(ctx, 200000), wm, map[string]interface{}{"context_window": float64(8000)})
+	if b != small {
+		t.Fatalf("parameter budget = %+v, want %+v", b, small)
+	}
+	if got := large.limit(map[string]interface{}{"limit": float64(7)}); got != 7 {
+		t.Fatalf("explicit limit = %d", got)
+	}
+	if got := large.limit(nil); got != 25 {
+		t.Fatalf("default limit = %d", got)
+	}
+}
+
+func TestBudgetClip(t *testing.T) {
+	code := strings.Repeat("line\n", 40)
+	data, _ := json.Marshal(codetypes.CodeChunk{Name: "Long", Code: code})
+	docs := []memory.Document{{ID: "a", Content: string(data)}}
+
+	clipped := resultBudget{Results: 3, SnippetLines: 10}.clip(docs)
+	if docs[0].Content != string(data) {
+		t.Fatal("clip modified its input")
+	}
+	var chunk codetypes.CodeChunk
+	if err := json.Unmarshal([]byte(clipped[0].Content), &chunk); err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Count(chunk.Code, "\n") + 1; lines != 10 {
+		t.Fatalf("clipped to %d lines", lines)
+	}
+	if truncated, shown := ragcode.ChunkTruncated(chunk); !truncated || shown != 10 {
+		t.Fatalf("ChunkTruncated = %v, %d", truncated, shown)
+	}
+
+	if got := (resultBudget{Results: 5}).clip(docs); got[0].Content != docs[0].Content {
+		t.Fatal("budget without snippet lines clipped code")
+	}
+}
+
+func TestContextWindowFromMeta(t *testing.T) {
+	for _, tc := range []struct {
+		meta map[string]any
+		want int
+	}{
+		{map[string]any{"contextWindow": float64(128000)}, 128000},
+		{map[string]any{"context_window": 32000}, 32000},
+		{map[string]any{"contextWindow": "large"}, 0},
+		{nil, 0},
+	} {
+		if got := ContextWindowFromMeta(tc.meta); got != tc.want {
+			t.Errorf("ContextWindowFromMeta(%v) = %d, want %d", tc.meta, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package tools
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// referenceWords point back to something said earlier ("that parser", "fix it").
+var referenceWords = map[string]bool{
+	"that": true, "this": true, "those": true, "these": true, "it": true, "its": true,
+	"them": true, "they": true, "same": true, "above": true, "previous": true,
+	"mentioned": true, "aforementioned": true, "former": true, "latter": true,
+}
+
+// hintStopWords are skipped when looking for the nouns of a query or for the
+// modifiers of a noun in the hint.
+var hintStopWords = map[string]bool{
+	"a": true, "an": true, "the": true, "of": true, "in": true, "on": true, "for": true,
+	"to": true, "from": true, "with": true, "and": true, "or": true, "is": true, "are": true,
+	"was": true, "be": true, "how": true, "does": true, "do": true, "where": true, "what": true,
+	"why": true, "which": true, "who": true, "work": true, "works": true, "find": true,
+	"show": true, "me": true, "my": true, "we": true, "our": true, "i": true, "you": true,
+	"can": true, "should": true, "used": true, "use": true, "called": true, "one": true,
+	"code": true, "function": true, "method": true, "class": true, "file": true,
+}
+
+var (
+	hintWordRe = regexp.MustCompile(`[A-Za-z_][A-Za-z0-9_]*`)
+	// Backticked spans, qualified names (pkg.Func, path/to/file.go), camelCase,
+	// PascalCase with an inner capital, snake_case and calls
+	hintTermRe = regexp.MustCompile("`([^`\n]+)`|[A-Za-z_][A-Za-z0-9_]*(?:[./][A-Za-z_][A-Za-z0-9_]*)+|[a-z][a-z0-9]*[A-Z][A-Za-z0-9]*|[A-Z][a-z0-9]+[A-Z][A-Za-z0-9]*|[A-Za-z][A-Za-z0-9]*_[A-Za-z0-9_]+|[A-Za-z_][A-Za-z0-9_]*\\(\\)")
+)
+
+// maxHintTerms bounds the words added to a query from the conversation.
+const maxHintTerms = 6
+
+// applyConversationHint resolves references in terse queries ("that parser",
+// "where is it called") against conversation_hint, the last user message
+// passed by the client. Code terms of the hint that the query refers to are
+// appended to the query before it is embedded. It returns the query to search
+// with and the added terms.
+func applyConversationHint(params map[string]interface{}, query string) (string, []string) {
+	hint, _ := params["conversation_hint"].(string)
+	terms := resolveConversationHint(query, hint)
+	if len(terms) == 0 {
+		return query, nil
+	}
+	return query + " " + strings.Join(terms, " "), terms
+}
+
+// resolveConversationHint returns the terms of hint the query refers to. A
+// query needs resolving when it uses a reference word or is at most four
+// words long; other queries are taken as self-contained.
+func resolveConversationHint(query, hint string) []string {
+	if strings.TrimSpace(hint) == "" {
+		return nil
+	}
+	queryWords := lowerWords(query)
+	hasReference := false
+	var nouns []string
+	for _, w := range queryWords {
+		switch {
+		case referenceWords[w]:
+			hasReference = true
+		case !hintStopWords[w]:
+			nouns = append(nouns, w)
+		}
+	}
+	if !hasReference && len(queryWords) > 4 {
+		return nil
+	}
+
+	lowerQuery := strings.ToLower(query)
+	seen := make(map[string]bool)
+	var out []string
+	add := func(term string) {
+		key := strings.ToLower(term)
+		if len(out) >= maxHintTerms || seen[key] || strings.Contains(lowerQuery, key) {
+			return
+		}
+		seen[key] = true
+		out = append(out, term)
+	}
+
+	terms := hintTerms(hint)
+	hintWords := lowerWords(hint)
+	for _, noun := range nouns {
+		stem := strings.TrimSuffix(noun, "s")
+		if len(stem) < 3 {
+			continue
+		}
+		// Code terms naming the noun: "that parser" -> ConfigParser
+		for _, term := range terms {
+			if strings.Contains(strings.ToLower(term), stem) {
+				add(term)
+			}
+		}
+		// Words qualifying the noun in the hint: "the YAML config parser"
+		for i, w := range hintWords {
+			if strings.TrimSuffix(w, "s") != stem {
+				continue
+			}
+			for j := i - 1; j >= 0 && j >= i-2; j-- {
+				if hintStopWords[hintWords[j]] || referenceWords[hintWords[j]] {
+					break
+				}
+				add(hintWords[j])
+			}
+		}
+	}
+
+	// "where is it called": nothing in the query to match, assume the last
+	// code terms of the hint are meant
+	if len(out) == 0 && hasReference {
+		for i := len(terms) - 1; i >= 0 && len(out) < 3; i-- {
+			add(terms[i])
+		}
+	}
+	return out
+}
+
+// hintTerms returns the code-like terms of a message, in order of appearance.
+func hintTerms(text string) []string {
+	var out []string
+	seen := make(map[string]bool)
+	for _, m := range hintTermRe.FindAllStringSubmatch(text, -1) {
+		term := m[0]
+		if m[1] != "" {
+			term = m[1]
+		}
+		term = strings.TrimSuffix(strings.TrimSpace(term), "()")
+		if term == "" || seen[term] {
+			continue
+		}
+		seen[term] = true
+		out = append(out, term)
+	}
+	return out
+}
+
+func lowerWords(text string) []string {
+	words := hintWordRe.FindAllString(text, -1)
+	for i, w := range words {
+		words[i] = strings.ToLower(w)
+	}
+	return words
+}
+
+// formatConversationTerms renders the terms taken from conversation_hint,
+// shown above markdown results so the interpretation of the query is visible.
+func formatConversationTerms(terms []string) string {
+	if len(terms) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("💬 Resolved from conversation: %s\n\n", strings.Join(terms, ", "))
+}
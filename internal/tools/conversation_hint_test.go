@@ -0,0 +1,28 @@
+package tools
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestResolveConversationHint(t *testing.T) {
+	cases := []struct {
+		query, hint string
+		want        []string
+	}{
+		{"that parser", "The YAML config parser in ConfigParser.Load fails on tabs", []string{"ConfigParser.Load", "config", "yaml"}},
+		{"where is it called", "Why does `retryRequest` give up after the first timeout?", []string{"retryRequest"}},
+		{"how does the indexer skip vendored files", "the `Indexer` is slow", nil},
+		{"that parser", "", nil},
+	}
+	for _, c := range cases {
+		if got := resolveConversationHint(c.query, c.hint); !reflect.DeepEqual(got, c.want) {
+			t.Errorf("resolve(%q, %q) = %v, want %v", c.query, c.hint, got, c.want)
+		}
+	}
+
+	query, terms := applyConversationHint(map[string]interface{}{"conversation_hint": "fix `retryRequest` please"}, "fix it")
+	if query != "fix it retryRequest" || len(terms) != 1 {
+		t.Errorf("query = %q, terms = %v", query, terms)
+	}
+}
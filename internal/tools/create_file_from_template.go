@@ -0,0 +1,139 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/doITmagic/rag-code-mcp/internal/workspace"
+)
+
+// CreateFileFromTemplateTool scaffolds files from built-in or workspace
+// templates. It is registered only when edits.enabled is set; files are
+// created through the apply_patch checks and indexed right away.
+type CreateFileFromTemplateTool struct {
+	workspaceManager *workspace.Manager
+}
+
+// NewCreateFileFromTemplateTool creates a new create_file_from_template tool
+func NewCreateFileFromTemplateTool(wm *workspace.Manager) *CreateFileFromTemplateTool {
+	return &CreateFileFromTemplateTool{
+		workspaceManager: wm,
+	}
+}
+
+func (t *CreateFileFromTemplateTool) Name() string {
+	return "create_file_from_template"
+}
+
+func (t *CreateFileFromTemplateTool) Description() string {
+	return "Create new files from a template: built-in go-package, laravel-controller and pytest-module, or the workspace's own templates in .ragcode/templates (one directory per template, Go text/template syntax). Pass the template name and its vars, e.g. {\"Name\": \"InvoiceController\"}; dir places the files below a workspace directory. Existing files are never overwritten; new files are journaled for rollback_change and indexed immediately. Use list=true to see the templates and their variables."
+}
+
+func (t *CreateFileFromTemplateTool) Execute(ctx context.Context, params map[string]interface{}) (string, error) {
+	if t.workspaceManager == nil {
+		return "", fmt.Errorf("workspace manager not configured")
+	}
+	edits := t.workspaceManager.Edits()
+	if !edits.Enabled {
+		return "", fmt.Errorf("create_file_from_template is disabled: set edits.enabled in the config (or EDITS_ENABLED=true) to allow file changes")
+	}
+	if extractFilePathFromParams(params) == "" {
+		return "", fmt.Errorf("file_path parameter is required for create_file_from_template. Please provide a file path from your workspace")
+	}
+	info, err := t.workspaceManager.DetectWorkspace(params)
+	if err != nil {
+		return "", fmt.Errorf("failed to detect workspace: %w", err)
+	}
+	jsonOutput := outputFormatFrom(params, formatMarkdown) == formatJSON
+
+	name, _ := params["template"].(string)
+	if list, _ := params["list"].(bool); list || name == "" {
+		templates, err := workspace.ListTemplates(info.Root)
+		if err != nil {
+			return "", fmt.Errorf("failed to load templates: %w", err)
+		}
+		if jsonOutput {
+			data, err := json.MarshalIndent(templates, "", "  ")
+			if err != nil {
+				return "", fmt.Errorf("failed to marshal templates: %w", err)
+			}
+			return string(data), nil
+		}
+		return formatTemplates(templates), nil
+	}
+
+	tmpl, err := workspace.FindTemplate(info.Root, name)
+	if err != nil {
+		return "", err
+	}
+	vars := make(map[string]string)
+	if raw, ok := params["vars"].(map[string]interface{}); ok {
+		for k, v := range raw {
+			vars[k] = fmt.Sprint(v)
+		}
+	}
+	dir, _ := params["dir"].(string)
+	dryRun, _ := params["dry_run"].(bool)
+	result, err := workspace.CreateFromTemplate(info.Root, tmpl, vars, dir, workspace.PatchOptions{
+		DryRun:    dryRun,
+		MaxFiles:  edits.MaxFiles,
+		Protected: edits.ProtectedPaths,
+	})
+	if err != nil {
+		return "", fmt.Errorf("files not created: %w", err)
+	}
+
+	var reindexed []string
+	if !dryRun {
+		paths := make([]string, 0, len(result.Files))
+		for _, f := range result.Files {
+			paths = append(paths, filepath.Join(info.Root, f.Path))
+		}
+		reindexed = t.workspaceManager.ReindexFiles(info, paths)
+	}
+
+	if jsonOutput {
+		data, err := json.MarshalIndent(struct {
+			*workspace.PatchResult
+			Template   string   `json:"template"`
+			Reindexing []string `json:"reindexing,omitempty"`
+		}{result, tmpl.Name, reindexed}, "", "  ")
+		if err != nil {
+			return "", fmt.Errorf("failed to marshal create_file_from_template result: %w", err)
+		}
+		return string(data), nil
+	}
+	return formatPatchResult(result, reindexed), nil
+}
+
+func formatTemplates(templates []*workspace.FileTemplate) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("📐 %d template(s):\n", len(templates)))
+	for _, tmpl := range templates {
+		sb.WriteString(fmt.Sprintf("\n### %s", tmpl.Name))
+		if tmpl.Source != "builtin" {
+			sb.WriteString(" (workspace)")
+		}
+		sb.WriteString("\n")
+		if tmpl.Description != "" {
+			sb.WriteString(tmpl.Description + "\n")
+		}
+		for _, v := range tmpl.Vars {
+			line := fmt.Sprintf("- var `%s`", v.Name)
+			if v.Description != "" {
+				line += ": " + v.Description
+			}
+			if v.Default != "" {
+				line += fmt.Sprintf(" (default %q)", v.Default)
+			} else {
+				line += " (required)"
+			}
+			sb.WriteString(line + "\n")
+		}
+		sb.WriteString(fmt.Sprintf("- files: %s\n", strings.Join(tmpl.Files, ", ")))
+	}
+	return sb.String()
+}
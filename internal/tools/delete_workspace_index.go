@@ -0,0 +1,102 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/doITmagic/rag-code-mcp/internal/workspace"
+)
+
+// DeleteWorkspaceIndexTool deletes the index of a workspace, or of one of its
+// languages
+type DeleteWorkspaceIndexTool struct {
+	workspaceManager *workspace.Manager
+}
+
+// NewDeleteWorkspaceIndexTool creates a new delete_workspace_index tool
+func NewDeleteWorkspaceIndexTool(wm *workspace.Manager) *DeleteWorkspaceIndexTool {
+	return &DeleteWorkspaceIndexTool{
+		workspaceManager: wm,
+	}
+}
+
+func (t *DeleteWorkspaceIndexTool) Name() string {
+	return "delete_workspace_index"
+}
+
+func (t *DeleteWorkspaceIndexTool) Description() string {
+	return "Delete the index of the workspace containing file_path: its vector collections, .ragcode/state.json and the cached clients and results, in one step. With language, only that language's collection and state entries are deleted. The workspace is indexed again from scratch on its next query. Use dry_run to list what would be deleted."
+}
+
+func (t *DeleteWorkspaceIndexTool) Execute(ctx context.Context, params map[string]interface{}) (string, error) {
+	if t.workspaceManager == nil {
+		return "", fmt.Errorf("workspace manager not configured")
+	}
+	if extractFilePathFromParams(params) == "" {
+		return "", fmt.Errorf("file_path parameter is required for delete_workspace_index. Please provide a file path from your workspace")
+	}
+	info, err := t.workspaceManager.DetectWorkspace(params)
+	if err != nil {
+		return "", fmt.Errorf("failed to detect workspace: %w", err)
+	}
+	language, _ := params["language"].(string)
+	dryRun, _ := params["dry_run"].(bool)
+
+	report, err := t.workspaceManager.DeleteIndex(ctx, info, strings.TrimSpace(language), dryRun)
+	if err != nil {
+		return "", err
+	}
+
+	if outputFormatFrom(params, formatMarkdown) == formatJSON {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return "", fmt.Errorf("failed to marshal delete_workspace_index results: %w", err)
+		}
+		return string(data), nil
+	}
+	return FormatDeleteReport(report), nil
+}
+
+// FormatDeleteReport renders a deleted index as markdown.
+func FormatDeleteReport(r *workspace.DeleteReport) string {
+	var sb strings.Builder
+	scope := "every language"
+	if r.Language != "" {
+		scope = r.Language
+	}
+	if r.DryRun {
+		sb.WriteString(fmt.Sprintf("# 🗑️ Deleting the index of %s (%s, dry run)\n\n", r.Root, scope))
+	} else {
+		sb.WriteString(fmt.Sprintf("# 🗑️ Deleted the index of %s (%s)\n\n", r.Root, scope))
+	}
+
+	verb := "Deleted"
+	if r.DryRun {
+		verb = "Would delete"
+	}
+	if len(r.Collections) == 0 {
+		sb.WriteString("No collections found.\n")
+	} else {
+		sb.WriteString(fmt.Sprintf("%s %d collection(s):\n", verb, len(r.Collections)))
+		for _, c := range r.Collections {
+			sb.WriteString("- " + c + "\n")
+		}
+	}
+	if len(r.StateFiles) > 0 {
+		sb.WriteString("\nRemoved state files:\n")
+		for _, f := range r.StateFiles {
+			sb.WriteString("- " + f + "\n")
+		}
+	}
+	if r.StateEntries > 0 {
+		sb.WriteString(fmt.Sprintf("\nDropped %d file(s) from .ragcode/state.json.\n", r.StateEntries))
+	}
+	if r.DryRun {
+		sb.WriteString("\nRun again without dry_run to delete them.\n")
+	} else {
+		sb.WriteString("\nThe next query indexes the workspace again.\n")
+	}
+	return sb.String()
+}
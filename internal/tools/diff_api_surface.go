@@ -0,0 +1,203 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/doITmagic/rag-code-mcp/internal/ragcode"
+	"github.com/doITmagic/rag-code-mcp/internal/workspace"
+)
+
+// DiffAPISurfaceTool reports public symbols added, removed or changed since
+// the previous index run or a pinned snapshot.
+type DiffAPISurfaceTool struct {
+	workspaceManager *workspace.Manager
+}
+
+// NewDiffAPISurfaceTool creates a new diff_api_surface tool
+func NewDiffAPISurfaceTool(wm *workspace.Manager) *DiffAPISurfaceTool {
+	return &DiffAPISurfaceTool{
+		workspaceManager: wm,
+	}
+}
+
+// APISurfaceDiff is the result of diff_api_surface.
+type APISurfaceDiff struct {
+	Base          string    `json:"base"`
+	BaseCreatedAt time.Time `json:"base_created_at"`
+	Language      string    `json:"language,omitempty"`
+	Breaking      bool      `json:"breaking"`
+	ragcode.SymbolDiff
+}
+
+func (t *DiffAPISurfaceTool) Name() string {
+	return "diff_api_surface"
+}
+
+func (t *DiffAPISurfaceTool) Description() string {
+	return "Report public API changes - added, removed and signature-changed exported symbols - since the previous index or a pinned snapshot. Use pin_snapshot to save the current API (e.g. at a release tag), then diff against it later for changelogs and breaking-change detection. Supports Go, PHP, Python."
+}
+
+func (t *DiffAPISurfaceTool) Execute(ctx context.Context, args map[string]interface{}) (string, error) {
+	base := workspace.PreviousSnapshot
+	if v, ok := args["snapshot"].(string); ok && strings.TrimSpace(v) != "" {
+		base = strings.TrimSpace(v)
+	}
+
+	pin := ""
+	if v, ok := args["pin_snapshot"].(string); ok {
+		pin = strings.TrimSpace(v)
+	}
+
+	language := ""
+	if v, ok := args["language"].(string); ok {
+		language = strings.ToLower(v)
+	}
+
+	outputFormat := outputFormatFrom(args, formatJSON)
+
+	if t.workspaceManager == nil {
+		return "", fmt.Errorf("workspace manager not configured")
+	}
+	if extractFilePathFromParams(args) == "" {
+		return "", fmt.Errorf("file_path parameter is required for diff_api_surface. Please provide a file path from your workspace")
+	}
+	info, err := t.workspaceManager.DetectWorkspace(args)
+	if err != nil {
+		return "", fmt.Errorf("failed to detect workspace: %w", err)
+	}
+
+	if pin != "" {
+		snap, err := t.workspaceManager.PinSymbolSnapshot(ctx, info, pin)
+		if err != nil {
+			return "", fmt.Errorf("failed to pin snapshot: %w", err)
+		}
+		return fmt.Sprintf("📌 Pinned snapshot '%s' with %d public symbol(s). Diff against it later with {\"snapshot\": \"%s\"}.",
+			snap.Name, len(snap.Entries("")), snap.Name), nil
+	}
+
+	snap, err := t.workspaceManager.SymbolSnapshot(ctx, info, base)
+	if err != nil {
+		return "", fmt.Errorf("failed to load snapshot '%s': %w", base, err)
+	}
+	if snap == nil {
+		if base == workspace.PreviousSnapshot {
+			return "ℹ️  No previous index generation recorded yet. It is saved the next time index_workspace picks up changed files; or pin a snapshot with pin_snapshot.", nil
+		}
+		return fmt.Sprintf("❌ Snapshot '%s' not found. Create it with {\"pin_snapshot\": \"%s\"}.", base, base), nil
+	}
+
+	table, err := t.workspaceManager.Symbols(info)
+	if err != nil {
+		return "", fmt.Errorf("failed to load symbol table: %w", err)
+	}
+
+	current := table.All()
+	if base == workspace.PreviousSnapshot && language == "" {
+		// The previous generation only holds languages that were re-indexed;
+		// compare those languages only.
+		var filtered []ragcode.SymbolEntry
+		for _, e := range current {
+			if _, ok := snap.Languages[e.Language]; ok {
+				filtered = append(filtered, e)
+			}
+		}
+		current = filtered
+	} else if language != "" {
+		var filtered []ragcode.SymbolEntry
+		for _, e := range current {
+			if e.Language == language {
+				filtered = append(filtered, e)
+			}
+		}
+		current = filtered
+	}
+
+	diff := APISurfaceDiff{
+		Base:          snap.Name,
+		BaseCreatedAt: snap.CreatedAt,
+		Language:      language,
+		SymbolDiff:    ragcode.DiffSymbols(snap.Entries(language), current),
+	}
+	diff.Breaking = len(diff.Removed) > 0 || len(diff.Changed) > 0
+
+	if outputFormat == formatMinimal {
+		return formatAPISurfaceDiffMinimal(diff), nil
+	}
+	if outputFormat == "markdown" {
+		return formatAPISurfaceDiff(diff), nil
+	}
+
+	data, err := json.MarshalIndent(diff, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal diff_api_surface results: %w", err)
+	}
+	return string(data), nil
+}
+
+func formatAPISurfaceDiff(diff APISurfaceDiff) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("# 🔀 API changes since '%s' (%s)\n\n", diff.Base, diff.BaseCreatedAt.Format(time.RFC3339)))
+	if diff.Empty() {
+		sb.WriteString("No public API changes.\n")
+		return sb.String()
+	}
+	if diff.Breaking {
+		sb.WriteString("⚠️  **Contains breaking changes**\n\n")
+	}
+
+	if len(diff.Added) > 0 {
+		sb.WriteString("## Added\n\n")
+		for _, e := range diff.Added {
+			sb.WriteString(fmt.Sprintf("- `%s` (%s) - `%s:%d`\n", qualifiedSymbolName(e), e.Kind, e.FilePath, e.StartLine))
+		}
+		sb.WriteString("\n")
+	}
+	if len(diff.Changed) > 0 {
+		sb.WriteString("## Changed\n\n")
+		for _, c := range diff.Changed {
+			sb.WriteString(fmt.Sprintf("- `%s`: `%s` → `%s`\n", qualifiedSymbolName(c.Symbol), c.OldSignature, c.NewSignature))
+		}
+		sb.WriteString("\n")
+	}
+	if len(diff.Removed) > 0 {
+		sb.WriteString("## Removed\n\n")
+		for _, e := range diff.Removed {
+			sb.WriteString(fmt.Sprintf("- `%s` (%s)\n", qualifiedSymbolName(e), e.Kind))
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// qualifiedSymbolName returns package.Receiver.Name, leaving out empty parts.
+func qualifiedSymbolName(e ragcode.SymbolEntry) string {
+	name := e.Name
+	if e.Receiver != "" {
+		name = e.Receiver + "." + name
+	}
+	if e.Package != "" {
+		name = e.Package + "." + name
+	}
+	return name
+}
+
+func formatAPISurfaceDiffMinimal(diff APISurfaceDiff) string {
+	var sb strings.Builder
+	for _, e := range diff.Added {
+		sb.WriteString(minimalLine("added "+e.Kind, qualifiedSymbolName(e), e.FilePath, e.StartLine, 0, e.Signature) + "\n")
+	}
+	for _, c := range diff.Changed {
+		sb.WriteString(minimalLine("changed "+c.Symbol.Kind, qualifiedSymbolName(c.Symbol), c.Symbol.FilePath, c.Symbol.StartLine, 0, c.OldSignature+" → "+c.NewSignature) + "\n")
+	}
+	for _, e := range diff.Removed {
+		sb.WriteString(minimalLine("removed "+e.Kind, qualifiedSymbolName(e), "", 0, 0, e.Signature) + "\n")
+	}
+	if sb.Len() == 0 {
+		return "No public API changes.\n"
+	}
+	return sb.String()
+}
@@ -0,0 +1,168 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/doITmagic/rag-code-mcp/internal/workspace"
+)
+
+// EditSessionTool collects the edits of a multi-step refactor and applies
+// them together: begin opens a session, stage adds a patch or a whole file,
+// preview shows the combined diff, commit writes everything as one
+// journaled change with a single re-index pass, discard drops it. It is
+// registered only when edits.enabled is set.
+type EditSessionTool struct {
+	workspaceManager *workspace.Manager
+	sessions         *workspace.EditSessions
+	infos            sync.Map // session id -> *workspace.Info, for re-indexing
+}
+
+// NewEditSessionTool creates a new edit_session tool
+func NewEditSessionTool(wm *workspace.Manager) *EditSessionTool {
+	return &EditSessionTool{
+		workspaceManager: wm,
+		sessions:         workspace.NewEditSessions(),
+	}
+}
+
+func (t *EditSessionTool) Name() string {
+	return "edit_session"
+}
+
+func (t *EditSessionTool) Description() string {
+	return "Transactional multi-file edits for refactors: action=begin returns a session_id; action=stage adds a unified diff (patch) or a whole file (path + content, or delete=true) on top of the edits staged before; action=preview shows the combined diff; action=commit writes all files atomically as one change (undo with rollback_change) and re-indexes once; action=discard drops the session. Nothing touches disk before commit."
+}
+
+func (t *EditSessionTool) Execute(ctx context.Context, params map[string]interface{}) (string, error) {
+	if t.workspaceManager == nil {
+		return "", fmt.Errorf("workspace manager not configured")
+	}
+	edits := t.workspaceManager.Edits()
+	if !edits.Enabled {
+		return "", fmt.Errorf("edit_session is disabled: set edits.enabled in the config (or EDITS_ENABLED=true) to allow file changes")
+	}
+	action, _ := params["action"].(string)
+	jsonOutput := outputFormatFrom(params, formatMarkdown) == formatJSON
+
+	if action == "begin" {
+		if extractFilePathFromParams(params) == "" {
+			return "", fmt.Errorf("file_path parameter is required to begin an edit session. Please provide a file path from your workspace")
+		}
+		info, err := t.workspaceManager.DetectWorkspace(params)
+		if err != nil {
+			return "", fmt.Errorf("failed to detect workspace: %w", err)
+		}
+		session, err := t.sessions.Begin(ClientSession(ctx), info.Root, workspace.PatchOptions{
+			MaxFiles:  edits.MaxFiles,
+			Protected: edits.ProtectedPaths,
+		})
+		if err != nil {
+			return "", err
+		}
+		t.infos.Store(session.ID, info)
+		if jsonOutput {
+			return marshalEditSession(map[string]interface{}{"session_id": session.ID, "root": session.Root})
+		}
+		return fmt.Sprintf("📝 Edit session %s started for %s. Stage edits with action=stage, then preview and commit.", session.ID, session.Root), nil
+	}
+
+	id, _ := params["session_id"].(string)
+	if id == "" {
+		return "", fmt.Errorf("session_id parameter is required for action=%s", action)
+	}
+	session, err := t.sessions.Get(ClientSession(ctx), id)
+	if err != nil {
+		return "", err
+	}
+
+	switch action {
+	case "stage":
+		if patch, _ := params["patch"].(string); strings.TrimSpace(patch) != "" {
+			err = session.StagePatch(patch)
+		} else if path, _ := params["path"].(string); path != "" {
+			content, hasContent := params["content"].(string)
+			remove, _ := params["delete"].(bool)
+			if !hasContent && !remove {
+				return "", fmt.Errorf("stage needs content for %s, or delete=true", path)
+			}
+			err = session.StageFile(path, content, remove)
+		} else {
+			return "", fmt.Errorf("stage needs a patch, or a path with content")
+		}
+		if err != nil {
+			return "", fmt.Errorf("edit not staged: %w", err)
+		}
+		_, files := session.Preview()
+		if jsonOutput {
+			return marshalEditSession(map[string]interface{}{"session_id": id, "stages": session.Stages(), "files": files})
+		}
+		return fmt.Sprintf("✅ Staged. Session %s: %d edit(s) changing %d file(s); nothing written yet.", id, session.Stages(), len(files)), nil
+
+	case "preview":
+		diff, files := session.Preview()
+		if jsonOutput {
+			return marshalEditSession(map[string]interface{}{"session_id": id, "stages": session.Stages(), "files": files, "diff": diff})
+		}
+		return formatEditSessionPreview(id, diff, files), nil
+
+	case "commit":
+		result, err := session.Commit()
+		if err != nil {
+			return "", fmt.Errorf("session not committed: %w", err)
+		}
+		paths := make([]string, 0, len(result.Files))
+		for _, f := range result.Files {
+			paths = append(paths, filepath.Join(session.Root, f.Path))
+		}
+		var reindexed []string
+		if info, ok := t.infos.Load(id); ok {
+			reindexed = t.workspaceManager.ReindexFiles(info.(*workspace.Info), paths)
+		}
+		t.close(id)
+		if jsonOutput {
+			return marshalEditSession(struct {
+				*workspace.PatchResult
+				Reindexing []string `json:"reindexing,omitempty"`
+			}{result, reindexed})
+		}
+		return formatPatchResult(result, reindexed), nil
+
+	case "discard":
+		t.close(id)
+		return fmt.Sprintf("🗑️ Edit session %s discarded; nothing was written.", id), nil
+	}
+	return "", fmt.Errorf("unknown action %q: use begin, stage, preview, commit or discard", action)
+}
+
+func (t *EditSessionTool) close(id string) {
+	t.sessions.Close(id)
+	t.infos.Delete(id)
+}
+
+func marshalEditSession(v interface{}) (string, error) {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal edit_session result: %w", err)
+	}
+	return string(data), nil
+}
+
+func formatEditSessionPreview(id, diff string, files []workspace.PatchedFile) string {
+	if len(files) == 0 {
+		return fmt.Sprintf("Edit session %s has no changes staged.", id)
+	}
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("🔍 Edit session %s would change %d file(s):\n\n", id, len(files)))
+	for _, f := range files {
+		sb.WriteString(fmt.Sprintf("- %s %s (+%d -%d)\n", f.Action, f.Path, f.Added, f.Removed))
+	}
+	sb.WriteString("\n```diff\n")
+	sb.WriteString(diff)
+	sb.WriteString("```\n")
+	return sb.String()
+}
@@ -0,0 +1,170 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/doITmagic/rag-code-mcp/internal/ragcode"
+	"github.com/doITmagic/rag-code-mcp/internal/workspace"
+)
+
+// FindErrorOriginTool matches runtime log lines and error messages against the
+// logging call sites recorded during indexing.
+type FindErrorOriginTool struct {
+	workspaceManager *workspace.Manager
+}
+
+// NewFindErrorOriginTool creates a new find_error_origin tool
+func NewFindErrorOriginTool(wm *workspace.Manager) *FindErrorOriginTool {
+	return &FindErrorOriginTool{
+		workspaceManager: wm,
+	}
+}
+
+// ErrorOrigin is a log line together with the call sites that may have produced it.
+type ErrorOrigin struct {
+	Line    string             `json:"line"`
+	Matches []ragcode.LogMatch `json:"matches"`
+	Snippet string             `json:"snippet,omitempty"`
+}
+
+func (t *FindErrorOriginTool) Name() string {
+	return "find_error_origin"
+}
+
+func (t *FindErrorOriginTool) Description() string {
+	return "Find where a runtime log line or error message was emitted - matches messages containing interpolated values (IDs, paths, timestamps) against logging/error call sites and their format strings (log.Printf, fmt.Errorf, $logger->error, logging.warning, raise/throw). Accepts a single message or a pasted log excerpt. Use when you have log output but no stack trace."
+}
+
+func (t *FindErrorOriginTool) Execute(ctx context.Context, args map[string]interface{}) (string, error) {
+	message, ok := args["message"].(string)
+	if !ok || strings.TrimSpace(message) == "" {
+		return "", fmt.Errorf("message is required")
+	}
+
+	limit := 5
+	if v, ok := args["limit"].(float64); ok && v > 0 {
+		limit = int(v)
+	}
+
+	contextLines := 2
+	if v, ok := args["context_lines"].(float64); ok && v >= 0 {
+		contextLines = int(v)
+	}
+
+	outputFormat := outputFormatFrom(args, formatJSON)
+
+	if t.workspaceManager == nil {
+		return "", fmt.Errorf("workspace manager not configured")
+	}
+	if extractFilePathFromParams(args) == "" {
+		return "", fmt.Errorf("file_path parameter is required for find_error_origin. Please provide a file path from your workspace")
+	}
+	info, err := t.workspaceManager.DetectWorkspace(args)
+	if err != nil {
+		return "", fmt.Errorf("failed to detect workspace: %w", err)
+	}
+
+	table, err := t.workspaceManager.LogTemplates(info)
+	if err != nil {
+		return "", fmt.Errorf("failed to load log templates: %w", err)
+	}
+	if len(table.Files) == 0 {
+		return fmt.Sprintf("❌ No logging call sites recorded for workspace '%s'.\n\n"+
+			"Log templates are collected during indexing. Please call 'index_workspace' with:\n"+
+			"{\n"+
+			"  \"file_path\": \"%s\"\n"+
+			"}\n", info.Root, info.Root), nil
+	}
+
+	templates := table.All()
+	var origins []ErrorOrigin
+	seen := make(map[string]bool)
+	for _, line := range strings.Split(message, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || seen[line] {
+			continue
+		}
+		seen[line] = true
+
+		matches := ragcode.MatchLogLine(templates, line, ragcode.MinLogLiteral)
+		if len(matches) == 0 {
+			continue
+		}
+		if len(matches) > limit {
+			matches = matches[:limit]
+		}
+		best := matches[0].Template
+		origins = append(origins, ErrorOrigin{
+			Line:    line,
+			Matches: matches,
+			Snippet: numberedSnippet(info.Root, best.FilePath, best.Line, contextLines),
+		})
+	}
+
+	if len(origins) == 0 {
+		return fmt.Sprintf("No logging call site matches the given message (%d templates searched).", len(templates)), nil
+	}
+
+	if outputFormat == formatMinimal {
+		return formatErrorOriginsMinimal(origins), nil
+	}
+	if outputFormat == "markdown" {
+		return formatErrorOrigins(origins, t.workspaceManager.CodeFences()), nil
+	}
+
+	data, err := json.MarshalIndent(origins, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal find_error_origin results: %w", err)
+	}
+	return string(data), nil
+}
+
+// matchLogOrigins returns the best call sites for message, or nil when the
+// workspace has no recorded templates.
+func matchLogOrigins(wm *workspace.Manager, info *workspace.Info, message string, limit int) []ragcode.LogMatch {
+	if wm == nil || info == nil {
+		return nil
+	}
+	table, err := wm.LogTemplates(info)
+	if err != nil {
+		log.Printf("⚠️  Failed to load log templates: %v", err)
+		return nil
+	}
+	matches := ragcode.MatchLogLine(table.All(), message, ragcode.MinLogLiteral)
+	if len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches
+}
+
+func formatErrorOrigins(origins []ErrorOrigin, fences string) string {
+	var sb strings.Builder
+	sb.WriteString("# 🔎 Error origins\n\n")
+	for _, o := range origins {
+		sb.WriteString(fmt.Sprintf("## `%s`\n\n", o.Line))
+		for _, m := range o.Matches {
+			sb.WriteString(fmt.Sprintf("- `%s` at `%s:%d` (%s): %q\n",
+				m.Template.Call, m.Template.FilePath, m.Template.Line, m.Template.Level, m.Template.Format))
+		}
+		if o.Snippet != "" && len(o.Matches) > 0 {
+			sb.WriteString("\n" + codeBlock(fences, "", o.Matches[0].Template.FilePath, o.Snippet))
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// formatErrorOriginsMinimal renders one line per matching call site.
+func formatErrorOriginsMinimal(origins []ErrorOrigin) string {
+	var sb strings.Builder
+	for _, o := range origins {
+		for _, m := range o.Matches {
+			sb.WriteString(minimalLine(m.Template.Level, m.Template.Call, m.Template.FilePath, m.Template.Line, 0, m.Template.Format) + "\n")
+		}
+	}
+	return sb.String()
+}
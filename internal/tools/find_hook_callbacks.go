@@ -0,0 +1,173 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/doITmagic/rag-code-mcp/internal/ragcode/analyzers/php"
+	"github.com/doITmagic/rag-code-mcp/internal/ragcode/analyzers/php/wordpress"
+	"github.com/doITmagic/rag-code-mcp/internal/workspace"
+)
+
+// FindHookCallbacksTool lists the callbacks registered for a WordPress hook
+// and the places that fire it.
+type FindHookCallbacksTool struct {
+	workspaceManager *workspace.Manager
+}
+
+// NewFindHookCallbacksTool creates a new find_hook_callbacks tool
+func NewFindHookCallbacksTool(wm *workspace.Manager) *FindHookCallbacksTool {
+	return &FindHookCallbacksTool{
+		workspaceManager: wm,
+	}
+}
+
+// HookCallbacks is the result of find_hook_callbacks.
+type HookCallbacks struct {
+	Hook      string           `json:"hook"`
+	Callbacks []wordpress.Hook `json:"callbacks"`
+	Triggers  []wordpress.Hook `json:"triggers,omitempty"`
+}
+
+func (t *FindHookCallbacksTool) Name() string {
+	return "find_hook_callbacks"
+}
+
+func (t *FindHookCallbacksTool) Description() string {
+	return "Find the callbacks registered for a WordPress action or filter (add_action/add_filter) in priority order, with where each callback is defined, and the places that fire the hook (do_action/apply_filters). Hook names may use wildcards (woocommerce_*); dynamic hooks like save_post_{$post_type} match concrete names. Use to trace what runs on a hook in themes and plugins. PHP only."
+}
+
+func (t *FindHookCallbacksTool) Execute(ctx context.Context, args map[string]interface{}) (string, error) {
+	hook, _ := args["hook"].(string)
+	hook = strings.TrimSpace(hook)
+	if hook == "" {
+		return "", fmt.Errorf("hook is required")
+	}
+	includeTriggers := true
+	if v, ok := args["include_triggers"].(bool); ok {
+		includeTriggers = v
+	}
+	outputFormat := outputFormatFrom(args, formatMarkdown)
+
+	if t.workspaceManager == nil {
+		return "", fmt.Errorf("workspace manager not configured")
+	}
+	if extractFilePathFromParams(args) == "" {
+		return "", fmt.Errorf("file_path parameter is required for find_hook_callbacks. Please provide a file path from your workspace")
+	}
+	info, err := t.workspaceManager.DetectWorkspace(args)
+	if err != nil {
+		return "", fmt.Errorf("failed to detect workspace: %w", err)
+	}
+
+	// Analyze the whole workspace directly so callbacks defined in other
+	// files resolve, as list_package_exports does for PHP
+	wp, err := wordpress.Analyze([]string{info.Root})
+	if err != nil {
+		return "", fmt.Errorf("WordPress analysis failed for workspace '%s': %w", info.Root, err)
+	}
+	if len(wp.Hooks) == 0 {
+		return fmt.Sprintf("No WordPress hooks (add_action, add_filter, do_action, apply_filters) found in workspace '%s'.", info.Root), nil
+	}
+	if chunks, err := php.NewCodeAnalyzer().AnalyzePaths([]string{info.Root}); err == nil {
+		wordpress.ResolveCallbacks(wp, chunks)
+	}
+
+	result := HookCallbacks{Hook: hook, Callbacks: []wordpress.Hook{}}
+	for _, h := range wp.Hooks {
+		if !wordpress.MatchHook(hook, h.Name) {
+			continue
+		}
+		if h.IsTrigger() {
+			if includeTriggers {
+				result.Triggers = append(result.Triggers, h)
+			}
+			continue
+		}
+		result.Callbacks = append(result.Callbacks, h)
+	}
+	if len(result.Callbacks) == 0 && len(result.Triggers) == 0 {
+		return fmt.Sprintf("No callbacks or triggers found for hook '%s'.", hook), nil
+	}
+
+	// WordPress runs callbacks by priority, then in registration order
+	sort.SliceStable(result.Callbacks, func(i, j int) bool {
+		a, b := result.Callbacks[i], result.Callbacks[j]
+		if a.Priority != b.Priority {
+			return a.Priority < b.Priority
+		}
+		if a.FilePath != b.FilePath {
+			return a.FilePath < b.FilePath
+		}
+		return a.StartLine < b.StartLine
+	})
+	sort.SliceStable(result.Triggers, func(i, j int) bool {
+		a, b := result.Triggers[i], result.Triggers[j]
+		if a.FilePath != b.FilePath {
+			return a.FilePath < b.FilePath
+		}
+		return a.StartLine < b.StartLine
+	})
+
+	switch outputFormat {
+	case formatJSON:
+		data, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return "", fmt.Errorf("failed to marshal find_hook_callbacks results: %w", err)
+		}
+		return string(data), nil
+	case formatMinimal:
+		return formatHookCallbacksMinimal(result), nil
+	}
+	return formatHookCallbacks(result), nil
+}
+
+func formatHookCallbacks(r HookCallbacks) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("# 🪝 Hook `%s`\n\n", r.Hook))
+
+	sb.WriteString(fmt.Sprintf("## Callbacks (%d)\n\n", len(r.Callbacks)))
+	if len(r.Callbacks) == 0 {
+		sb.WriteString("No callbacks registered.\n")
+	}
+	for _, h := range r.Callbacks {
+		name := ""
+		if h.Name != r.Hook {
+			name = fmt.Sprintf(" on `%s`", h.Name)
+		}
+		sb.WriteString(fmt.Sprintf("- **%d** `%s` (%s%s, %d arg(s)) - registered at `%s:%d`",
+			h.Priority, h.Callback, h.Kind, name, h.AcceptedArgs, h.FilePath, h.StartLine))
+		if h.CallbackFile != "" {
+			sb.WriteString(fmt.Sprintf(", defined at `%s:%d`", h.CallbackFile, h.CallbackLine))
+		}
+		sb.WriteString("\n")
+	}
+
+	if len(r.Triggers) > 0 {
+		sb.WriteString(fmt.Sprintf("\n## Fired at (%d)\n\n", len(r.Triggers)))
+		for _, h := range r.Triggers {
+			sb.WriteString(fmt.Sprintf("- `%s('%s')` at `%s:%d`\n", h.Call, h.Name, h.FilePath, h.StartLine))
+		}
+	}
+	return sb.String()
+}
+
+func formatHookCallbacksMinimal(r HookCallbacks) string {
+	var sb strings.Builder
+	for _, h := range r.Callbacks {
+		file, line := h.CallbackFile, h.CallbackLine
+		if file == "" {
+			file, line = h.FilePath, h.StartLine
+		}
+		sb.WriteString(minimalLine(h.Kind, h.Callback, file, line, 0, fmt.Sprintf("%s priority %d", h.Name, h.Priority)))
+		sb.WriteString("\n")
+	}
+	for _, h := range r.Triggers {
+		sb.WriteString(minimalLine(h.Call, h.Name, h.FilePath, h.StartLine, 0, ""))
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
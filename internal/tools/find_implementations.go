@@ -126,17 +126,8 @@ func (t *FindImplementationsTool) Execute(ctx context.Context, args map[string]i
 		return "", fmt.Errorf("failed to generate query embedding: %w", err)
 	}
 
-	// Prefer SearchCodeOnly to exclude markdown documentation
-	type CodeSearcher interface {
-		SearchCodeOnly(ctx context.Context, query []float64, limit int) ([]memory.Document, error)
-	}
-
-	var results []memory.Document
-	if codeSearcher, ok := searchMemory.(CodeSearcher); ok {
-		results, err = codeSearcher.SearchCodeOnly(ctx, queryEmbedding, 50)
-	} else {
-		results, err = searchMemory.Search(ctx, queryEmbedding, 50)
-	}
+	// Exclude markdown documentation
+	results, err := searchMemory.Query(ctx, memory.SearchOptions{Vector: queryEmbedding, Filter: memory.CodeOnly(), Limit: 50})
 	if err != nil {
 		return "", fmt.Errorf("search failed: %w", err)
 	}
@@ -189,6 +180,7 @@ func (t *FindImplementationsTool) Execute(ctx context.Context, args map[string]i
 			Name:        chunk.Name,
 			Type:        chunk.Type,
 			Package:     chunk.Package,
+			Language:    string(chunk.Language),
 			FilePath:    chunk.FilePath,
 			StartLine:   chunk.StartLine,
 			EndLine:     chunk.EndLine,
@@ -232,9 +224,9 @@ func (t *FindImplementationsTool) Execute(ctx context.Context, args map[string]i
 		response.WriteString(fmt.Sprintf("**Occurrences:** %d\n\n", impl.Occurrences))
 
 		if impl.Snippet != "" {
-			response.WriteString("**Code snippet:**\n```go\n")
-			response.WriteString(impl.Snippet)
-			response.WriteString("\n```\n\n")
+			response.WriteString("**Code snippet:**\n")
+			response.WriteString(codeBlock(t.workspaceManager.CodeFences(), impl.Language, impl.FilePath, impl.Snippet))
+			response.WriteString("\n")
 		}
 	}
 
@@ -245,6 +237,7 @@ type Implementation struct {
 	Name        string
 	Type        string
 	Package     string
+	Language    string
 	FilePath    string
 	StartLine   int
 	EndLine     int
@@ -0,0 +1,209 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/doITmagic/rag-code-mcp/internal/codetypes"
+	"github.com/doITmagic/rag-code-mcp/internal/llm"
+	"github.com/doITmagic/rag-code-mcp/internal/memory"
+	"github.com/doITmagic/rag-code-mcp/internal/workspace"
+)
+
+// FindSimilarCodeTool finds chunks of the workspace whose embedding is close
+// to a code snippet or to an indexed symbol: duplicate and near-duplicate
+// code, for refactoring and DRY reviews
+type FindSimilarCodeTool struct {
+	workspaceManager *workspace.Manager
+	embedder         llm.Provider
+}
+
+// NewFindSimilarCodeTool creates a new find_similar_code tool
+func NewFindSimilarCodeTool(wm *workspace.Manager, embedder llm.Provider) *FindSimilarCodeTool {
+	return &FindSimilarCodeTool{
+		workspaceManager: wm,
+		embedder:         embedder,
+	}
+}
+
+const (
+	defaultSimilarCodeLimit = 10
+	maxSimilarCodeLimit     = 50
+)
+
+// SimilarCodeReport is the JSON output of find_similar_code
+type SimilarCodeReport struct {
+	Symbol    string                       `json:"symbol,omitempty"` // the symbol compared against, empty for a snippet
+	Source    *codetypes.SymbolLocation    `json:"source,omitempty"`
+	Threshold float64                      `json:"threshold"`
+	Results   []codetypes.SymbolDescriptor `json:"results"` // best first, metadata.score is the similarity
+}
+
+func (t *FindSimilarCodeTool) Name() string {
+	return "find_similar_code"
+}
+
+func (t *FindSimilarCodeTool) Description() string {
+	return "Find duplicate and near-duplicate code: returns the workspace chunks most similar to a code snippet or to an indexed symbol, above a similarity threshold (default queries.similarity_threshold, 0.85), best first with their similarity. Use for refactoring and DRY reviews, or before writing a helper that may already exist. Pass either code or symbol_name."
+}
+
+func (t *FindSimilarCodeTool) Execute(ctx context.Context, params map[string]interface{}) (string, error) {
+	code, _ := params["code"].(string)
+	symbol, _ := params["symbol_name"].(string)
+	code, symbol = strings.TrimSpace(code), strings.TrimSpace(symbol)
+	if code == "" && symbol == "" {
+		return "", fmt.Errorf("code or symbol_name is required")
+	}
+	if code != "" && symbol != "" {
+		return "", fmt.Errorf("pass either code or symbol_name, not both")
+	}
+	threshold := t.workspaceManager.SimilarityThreshold()
+	if v, ok := params["min_similarity"].(float64); ok {
+		if v <= 0 || v > 1 {
+			return "", fmt.Errorf("min_similarity must be between 0 and 1, got %g", v)
+		}
+		threshold = v
+	}
+	limit := defaultSimilarCodeLimit
+	if v, ok := params["limit"].(float64); ok && v > 0 {
+		limit = min(int(v), maxSimilarCodeLimit)
+	}
+	outputFormat := outputFormatFrom(params, formatJSON)
+
+	if t.workspaceManager == nil {
+		return "", fmt.Errorf("workspace manager not configured")
+	}
+	filePath := extractFilePathFromParams(params)
+	if filePath == "" {
+		return "", fmt.Errorf("file_path parameter is required for find_similar_code. Please provide a file path from your workspace")
+	}
+	info, err := t.workspaceManager.DetectWorkspace(params)
+	if err != nil {
+		return "", fmt.Errorf("failed to detect workspace: %w", err)
+	}
+	language := inferLanguageFromPath(filePath)
+	if language == "" && len(info.Languages) > 0 {
+		language = info.Languages[0]
+	}
+	if language == "" {
+		language = info.ProjectType
+	}
+	mem, msg, err := resolveLanguageMemory(ctx, t.workspaceManager, info, language)
+	if err != nil {
+		return "", fmt.Errorf("failed to open index of workspace '%s': %w", info.Root, err)
+	}
+	if msg != "" {
+		return msg, nil
+	}
+
+	report := SimilarCodeReport{Symbol: symbol, Threshold: threshold}
+	sourceID := ""
+	if symbol != "" {
+		source, ok, err := findSymbolChunk(ctx, mem, symbol)
+		if err != nil {
+			return "", fmt.Errorf("failed to look up symbol '%s': %w", symbol, err)
+		}
+		if !ok {
+			return fmt.Sprintf("❌ Symbol '%s' not found in the %s index of workspace '%s'.", symbol, language, info.Root), nil
+		}
+		sourceID = source.ID
+		chunk := source.chunk
+		report.Source = &codetypes.SymbolLocation{FilePath: chunk.FilePath, StartLine: chunk.StartLine, EndLine: chunk.EndLine}
+		code = strings.TrimSpace(chunk.Signature + "\n\n" + chunk.Code)
+	}
+
+	vector, err := t.embedder.Embed(ctx, code)
+	if err != nil {
+		return "", fmt.Errorf("failed to embed code: %w", err)
+	}
+	// One more than the limit: the symbol's own chunk is the best match
+	docs, err := mem.Query(ctx, memory.SearchOptions{Vector: vector, Filter: memory.CodeOnly(), Limit: limit + 1})
+	if err != nil {
+		return "", fmt.Errorf("failed to search similar code: %w", err)
+	}
+	similar := make([]memory.Document, 0, len(docs))
+	for _, doc := range docs {
+		if doc.ID == sourceID || getFloat(doc.Metadata["score"]) < threshold {
+			continue
+		}
+		similar = append(similar, doc)
+	}
+	if len(similar) > limit {
+		similar = similar[:limit]
+	}
+	report.Results = buildSymbolDescriptorsFromDocs(similar)
+
+	switch outputFormat {
+	case formatMarkdown:
+		return formatSimilarCode(report, info.Root, t.workspaceManager.CodeFences()), nil
+	case formatMinimal:
+		if len(report.Results) == 0 {
+			return fmt.Sprintf("No code with similarity ≥ %.2f found.", threshold), nil
+		}
+		return formatMinimalDescriptors(report.Results), nil
+	}
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal find_similar_code results: %w", err)
+	}
+	return string(data), nil
+}
+
+// symbolChunk is an indexed chunk with its decoded content
+type symbolChunk struct {
+	ID    string
+	chunk codetypes.CodeChunk
+}
+
+// findSymbolChunk returns the indexed chunk of the symbol name, a function
+// or method when the name is also used by other kinds of symbols
+func findSymbolChunk(ctx context.Context, mem memory.LongTermMemory, name string) (symbolChunk, bool, error) {
+	docs, err := mem.Query(ctx, memory.SearchOptions{Filter: symbolFilter(name), Limit: maxFileChunks})
+	if err != nil {
+		return symbolChunk{}, false, err
+	}
+	var found symbolChunk
+	ok := false
+	for _, doc := range docs {
+		var chunk codetypes.CodeChunk
+		if err := json.Unmarshal([]byte(doc.Content), &chunk); err != nil || chunk.Code == "" {
+			continue
+		}
+		if !ok || (!isCallable(found.chunk) && isCallable(chunk)) {
+			found, ok = symbolChunk{ID: doc.ID, chunk: chunk}, true
+		}
+	}
+	return found, ok, nil
+}
+
+func isCallable(chunk codetypes.CodeChunk) bool {
+	return chunk.Type == "function" || chunk.Type == "method"
+}
+
+// formatSimilarCode renders find_similar_code results as markdown
+func formatSimilarCode(r SimilarCodeReport, root, fences string) string {
+	var sb strings.Builder
+	subject := "the snippet"
+	if r.Symbol != "" {
+		subject = fmt.Sprintf("`%s`", r.Symbol)
+		if r.Source != nil {
+			subject += fmt.Sprintf(" (`%s:%d-%d`)", groupLabel(r.Source.FilePath, root), r.Source.StartLine, r.Source.EndLine)
+		}
+	}
+	sb.WriteString(fmt.Sprintf("# ♻️ Code similar to %s (similarity ≥ %.2f)\n\n", subject, r.Threshold))
+	if len(r.Results) == 0 {
+		sb.WriteString("No similar code found.\n")
+		return sb.String()
+	}
+	for i, d := range r.Results {
+		sb.WriteString(fmt.Sprintf("## %d. %s %s — %.2f\n\n", i+1, d.Kind, d.Name, getFloat(d.Metadata["score"])))
+		sb.WriteString(fmt.Sprintf("`%s:%d-%d`\n\n", groupLabel(d.Location.FilePath, root), d.Location.StartLine, d.Location.EndLine))
+		if snippet, _ := d.Metadata["snippet"].(string); snippet != "" {
+			sb.WriteString(codeBlock(fences, d.Language, d.Location.FilePath, snippet))
+			sb.WriteString("\n")
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,37 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/doITmagic/rag-code-mcp/internal/codetypes"
+	"github.com/doITmagic/rag-code-mcp/internal/memory"
+)
+
+func TestFindSymbolChunk(t *testing.T) {
+	mem := memory.NewInMemoryLongTermMemory()
+	for i, ch := range []codetypes.CodeChunk{
+		{Name: "Parse", Type: "type", Language: "go", FilePath: "/ws/parse.go", StartLine: 1, EndLine: 3, Code: "type Parse struct{}"},
+		{Name: "Parse", Type: "function", Language: "go", FilePath: "/ws/parse.go", StartLine: 5, EndLine: 9, Code: "func Parse() {}"},
+		{Name: "Other", Type: "function", Language: "go", FilePath: "/ws/other.go", StartLine: 1, EndLine: 2, Code: "func Other() {}"},
+	} {
+		content, _ := json.Marshal(ch)
+		doc := memory.Document{ID: []string{"1", "2", "3"}[i], Content: string(content), Metadata: map[string]interface{}{"name": ch.Name}}
+		if err := mem.Store(context.Background(), doc); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	found, ok, err := findSymbolChunk(context.Background(), mem, "Parse")
+	if err != nil || !ok {
+		t.Fatalf("findSymbolChunk: found=%v err=%v", ok, err)
+	}
+	if found.ID != "2" || found.chunk.Type != "function" {
+		t.Errorf("found %s (%s), want the function Parse", found.ID, found.chunk.Type)
+	}
+
+	if _, ok, _ := findSymbolChunk(context.Background(), mem, "Missing"); ok {
+		t.Error("unknown symbol found")
+	}
+}
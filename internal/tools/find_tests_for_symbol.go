@@ -0,0 +1,118 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/doITmagic/rag-code-mcp/internal/ragcode"
+	"github.com/doITmagic/rag-code-mcp/internal/workspace"
+)
+
+// FindTestsForSymbolTool returns the tests exercising a function, method or
+// type, linked by the calls and names recorded in the workspace symbol table
+type FindTestsForSymbolTool struct {
+	workspaceManager *workspace.Manager
+}
+
+// NewFindTestsForSymbolTool creates a new find_tests_for_symbol tool
+func NewFindTestsForSymbolTool(wm *workspace.Manager) *FindTestsForSymbolTool {
+	return &FindTestsForSymbolTool{
+		workspaceManager: wm,
+	}
+}
+
+func (t *FindTestsForSymbolTool) Name() string {
+	return "find_tests_for_symbol"
+}
+
+func (t *FindTestsForSymbolTool) Description() string {
+	return "Find the tests that exercise a function, method or type: tests calling it directly or through a test helper, and tests named after it (TestCharge, TestStore_Save, test_charge_card, StoreTest::testSave), strongest link first with the reasons. Use before changing code to know which tests to run or update. Accepts 'Name', 'Receiver.Name' or 'package.Name'. Go, Python and Rust tests are indexed when rag_code.index_tests is enabled."
+}
+
+func (t *FindTestsForSymbolTool) Execute(ctx context.Context, params map[string]interface{}) (string, error) {
+	symbol, _ := params["symbol_name"].(string)
+	symbol = strings.TrimSpace(symbol)
+	if symbol == "" {
+		return "", fmt.Errorf("symbol_name is required")
+	}
+
+	if t.workspaceManager == nil {
+		return "", fmt.Errorf("workspace manager not configured")
+	}
+	if extractFilePathFromParams(params) == "" {
+		return "", fmt.Errorf("file_path parameter is required for find_tests_for_symbol. Please provide a file path from your workspace")
+	}
+	info, err := t.workspaceManager.DetectWorkspace(params)
+	if err != nil {
+		return "", fmt.Errorf("failed to detect workspace: %w", err)
+	}
+	table, err := t.workspaceManager.Symbols(info)
+	if err != nil {
+		return "", fmt.Errorf("failed to load symbol table: %w", err)
+	}
+	entries := table.All()
+	if len(entries) == 0 {
+		return fmt.Sprintf("❌ No symbols recorded for workspace '%s'.\n\n"+
+			"The symbol table is built during indexing. Please call 'index_workspace' with:\n"+
+			"{\n"+
+			"  \"file_path\": \"%s\"\n"+
+			"}\n", info.Root, info.Root), nil
+	}
+	hasTests := false
+	for _, e := range entries {
+		if e.Test {
+			hasTests = true
+			break
+		}
+	}
+	if !hasTests {
+		return fmt.Sprintf("❌ No test code indexed for workspace '%s'.\n\n"+
+			"Go, Python and Rust tests are indexed only when enabled: set `rag_code.index_tests: true` "+
+			"(or CODE_RAG_INDEX_TESTS=true), then call 'index_workspace' with:\n"+
+			"{\n"+
+			"  \"file_path\": \"%s\"\n"+
+			"}\n", info.Root, info.Root), nil
+	}
+
+	tests, err := ragcode.FindTests(entries, symbol)
+	if err != nil {
+		return "", err
+	}
+
+	if outputFormatFrom(params, formatJSON) == formatMarkdown {
+		return formatSymbolTests(tests, info.Root), nil
+	}
+	data, err := json.MarshalIndent(tests, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal find_tests_for_symbol results: %w", err)
+	}
+	return string(data), nil
+}
+
+// formatSymbolTests renders the tests of a symbol as markdown, with file
+// paths relative to root
+func formatSymbolTests(r *ragcode.SymbolTests, root string) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("# 🧪 Tests of `%s` (%d)\n\n", r.Symbol, len(r.Tests)))
+	if len(r.Targets) > 1 || (len(r.Targets) == 1 && r.Targets[0] != r.Symbol) {
+		sb.WriteString(fmt.Sprintf("Matched: `%s`\n\n", strings.Join(r.Targets, "`, `")))
+	}
+	if len(r.Tests) == 0 {
+		sb.WriteString("No tests call it or are named after it.\n")
+		return sb.String()
+	}
+	for _, test := range r.Tests {
+		name := test.Name
+		if test.Receiver != "" {
+			name = test.Receiver + "." + test.Name
+		}
+		reasons := strings.Join(test.Reasons, ", ")
+		if test.Via != "" {
+			reasons += fmt.Sprintf(" via `%s`", test.Via)
+		}
+		sb.WriteString(fmt.Sprintf("- `%s` — `%s:%d-%d` (%s)\n", name, groupLabel(test.FilePath, root), test.StartLine, test.EndLine, reasons))
+	}
+	return sb.String()
+}
@@ -55,11 +55,7 @@ func (t *FindTypeDefinitionTool) Execute(ctx context.Context, args map[string]in
 		packagePath = pkg
 	}
 
-	// Optional output format: markdown (default) or json
-	outputFormat := "markdown"
-	if of, ok := args["output_format"].(string); ok && of != "" {
-		outputFormat = strings.ToLower(of)
-	}
+	outputFormat := outputFormatFrom(args, formatMarkdown)
 
 	// file_path is required for workspace detection
 	filePath := extractFilePathFromParams(args)
@@ -147,39 +143,15 @@ func (t *FindTypeDefinitionTool) Execute(ctx context.Context, args map[string]in
 	}
 
 	// First, try exact name+type search (faster and more accurate)
-	type ExactSearcher interface {
-		SearchByNameAndType(ctx context.Context, name string, types []string) ([]memory.Document, error)
-	}
-
-	typeKinds := []string{"type", "class", "interface", "trait", "model"}
-
-	var results []memory.Document
-	if exactSearcher, ok := searchMemory.(ExactSearcher); ok {
-		results, err = exactSearcher.SearchByNameAndType(ctx, typeName, typeKinds)
-		if err == nil && len(results) > 0 {
-			// Found exact match, use it directly
-			goto processResults
-		}
-	}
-
-	// Fallback to semantic search if exact search didn't find anything
-	{
-		type CodeSearcher interface {
-			SearchCodeOnly(ctx context.Context, query []float64, limit int) ([]memory.Document, error)
-		}
-
-		if codeSearcher, ok := searchMemory.(CodeSearcher); ok {
-			results, err = codeSearcher.SearchCodeOnly(ctx, queryEmbedding, 50)
-		} else {
-			results, err = searchMemory.Search(ctx, queryEmbedding, 50)
-		}
+	results, err := searchMemory.Query(ctx, memory.SearchOptions{Filter: symbolFilter(typeName, "type", "class", "interface", "trait", "model")})
+	if err != nil || len(results) == 0 {
+		// Fallback to semantic search if exact search didn't find anything
+		results, err = searchMemory.Query(ctx, memory.SearchOptions{Vector: queryEmbedding, Filter: memory.CodeOnly(), Limit: 50})
 		if err != nil {
 			return "", fmt.Errorf("search failed: %w", err)
 		}
 	}
 
-processResults:
-
 	if len(results) == 0 {
 		// Check if this is a workspace search with empty collection
 		if workspacePath != "" && collectionName != "" {
@@ -242,6 +214,10 @@ processResults:
 		}
 	}
 
+	if outputFormat == formatMinimal {
+		return formatMinimalCode(symbolDescriptorFromChunk(chunk), codeBody), nil
+	}
+
 	// PHP: use PHP analyzer directly on the source file to build a rich type view
 	if chunk.Language == "php" {
 		return t.buildPHPTypeResponse(&chunk, codeBody, outputFormat)
@@ -260,7 +236,7 @@ processResults:
 	// Go TypeInfo metadata when available.
 	if strings.ToLower(outputFormat) == "json" {
 		desc := codetypes.ClassDescriptor{
-			Language:    chunk.Language,
+			Language:    string(chunk.Language),
 			Kind:        chunk.Type,
 			Name:        chunk.Name,
 			Namespace:   chunk.Package,
@@ -290,7 +266,7 @@ processResults:
 			if len(typeInfo.Methods) > 0 {
 				for _, m := range typeInfo.Methods {
 					md := codetypes.FunctionDescriptor{
-						Language:    chunk.Language,
+						Language:    string(chunk.Language),
 						Kind:        "method",
 						Name:        "", // method name may not be present in TypeInfo; rely on signature
 						Namespace:   chunk.Package,
@@ -354,9 +330,8 @@ processResults:
 	}
 
 	if codeBody != "" {
-		response.WriteString("**Code:**\n```go\n")
-		response.WriteString(codeBody)
-		response.WriteString("\n```\n")
+		response.WriteString("**Code:**\n")
+		response.WriteString(codeBlock(t.workspaceManager.CodeFences(), string(chunk.Language), chunk.FilePath, codeBody))
 	}
 
 	return response.String(), nil
@@ -429,7 +404,7 @@ func (t *FindTypeDefinitionTool) buildPHPTypeResponse(chunk *codetypes.CodeChunk
 	// Helper to build a ClassDescriptor from whatever information we have.
 	buildDescriptor := func(classInfo *php.ClassInfo, eloquentModel *laravel.EloquentModel) codetypes.ClassDescriptor {
 		desc := codetypes.ClassDescriptor{
-			Language:  chunk.Language,
+			Language:  string(chunk.Language),
 			Kind:      chunk.Type,
 			Name:      chunk.Name,
 			Namespace: chunk.Package,
@@ -498,7 +473,7 @@ func (t *FindTypeDefinitionTool) buildPHPTypeResponse(chunk *codetypes.CodeChunk
 					visibility = "public"
 				}
 				md := codetypes.FunctionDescriptor{
-					Language:    chunk.Language,
+					Language:    string(chunk.Language),
 					Kind:        "method",
 					Name:        method.Name,
 					Namespace:   classInfo.Namespace,
@@ -635,9 +610,8 @@ func (t *FindTypeDefinitionTool) buildPHPTypeResponse(chunk *codetypes.CodeChunk
 		}
 		response.WriteString(fmt.Sprintf("\n**Location:** `%s:%d-%d`\n\n", chunk.FilePath, chunk.StartLine, chunk.EndLine))
 		if codeBody != "" {
-			response.WriteString("**Code:**\n```php\n")
-			response.WriteString(codeBody)
-			response.WriteString("\n```\n")
+			response.WriteString("**Code:**\n")
+			response.WriteString(codeBlock(t.workspaceManager.CodeFences(), string(chunk.Language), chunk.FilePath, codeBody))
 		}
 		return response.String(), nil
 	}
@@ -661,9 +635,8 @@ func (t *FindTypeDefinitionTool) buildPHPTypeResponse(chunk *codetypes.CodeChunk
 		response.WriteString(fmt.Sprintf("**Namespace:** %s\n", chunk.Package))
 		response.WriteString(fmt.Sprintf("\n**Location:** `%s:%d-%d`\n\n", chunk.FilePath, chunk.StartLine, chunk.EndLine))
 		if codeBody != "" {
-			response.WriteString("**Code:**\n```php\n")
-			response.WriteString(codeBody)
-			response.WriteString("\n```\n")
+			response.WriteString("**Code:**\n")
+			response.WriteString(codeBlock(t.workspaceManager.CodeFences(), string(chunk.Language), chunk.FilePath, codeBody))
 		}
 		return response.String(), nil
 	}
@@ -856,9 +829,8 @@ func (t *FindTypeDefinitionTool) buildPHPTypeResponse(chunk *codetypes.CodeChunk
 
 	// Code snippet
 	if codeBody != "" {
-		response.WriteString("**Code:**\n```php\n")
-		response.WriteString(codeBody)
-		response.WriteString("\n```\n")
+		response.WriteString("**Code:**\n")
+		response.WriteString(codeBlock(t.workspaceManager.CodeFences(), string(chunk.Language), chunk.FilePath, codeBody))
 	}
 
 	return response.String(), nil
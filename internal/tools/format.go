@@ -0,0 +1,161 @@
+package tools
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/doITmagic/rag-code-mcp/internal/codetypes"
+)
+
+// Output formats accepted by the output_format parameter. json and markdown
+// are the defaults of most tools; minimal is terse plain text for models
+// with small context windows: one result per line, no headers or fences.
+const (
+	formatJSON     = "json"
+	formatMarkdown = "markdown"
+	formatMinimal  = "minimal"
+)
+
+// minimalSummaryLen bounds the summary at the end of a minimal line
+const minimalSummaryLen = 120
+
+// outputFormatFrom returns the requested output format: output_format, or
+// its short alias format, lowercased. def is used when neither is set.
+func outputFormatFrom(params map[string]interface{}, def string) string {
+	for _, key := range []string{"output_format", "format"} {
+		if of, ok := params[key].(string); ok && of != "" {
+			return strings.ToLower(of)
+		}
+	}
+	return def
+}
+
+// minimalLine renders one result as "kind name file:start-end — summary".
+// Empty parts are left out.
+func minimalLine(kind, name, file string, start, end int, summary string) string {
+	parts := make([]string, 0, 3)
+	for _, p := range []string{kind, name} {
+		if p != "" {
+			parts = append(parts, p)
+		}
+	}
+	if file != "" {
+		loc := file
+		if start > 0 {
+			loc = fmt.Sprintf("%s:%d", file, start)
+			if end > start {
+				loc = fmt.Sprintf("%s-%d", loc, end)
+			}
+		}
+		parts = append(parts, loc)
+	}
+	line := strings.Join(parts, " ")
+	if summary = minimalSummary(summary); summary != "" {
+		line += " — " + summary
+	}
+	return line
+}
+
+// minimalSummary keeps the first non-empty line of text, with whitespace
+// collapsed and cut to minimalSummaryLen characters.
+func minimalSummary(text string) string {
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.Join(strings.Fields(line), " ")
+		if line == "" {
+			continue
+		}
+		if r := []rune(line); len(r) > minimalSummaryLen {
+			line = string(r[:minimalSummaryLen-1]) + "…"
+		}
+		return line
+	}
+	return ""
+}
+
+// descriptorSummary prefers the doc comment of a symbol over its signature.
+func descriptorSummary(d codetypes.SymbolDescriptor) string {
+	if s := minimalSummary(d.Description); s != "" {
+		return s
+	}
+	return d.Signature
+}
+
+// formatMinimalDescriptors renders one line per symbol.
+func formatMinimalDescriptors(descs []codetypes.SymbolDescriptor) string {
+	var sb strings.Builder
+	for _, d := range descs {
+		sb.WriteString(minimalLine(d.Kind, d.Name, d.Location.FilePath, d.Location.StartLine, d.Location.EndLine, descriptorSummary(d)))
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// formatMinimalCode renders a symbol line followed by its code as plain text,
+// for tools returning a single definition.
+func formatMinimalCode(d codetypes.SymbolDescriptor, code string) string {
+	out := minimalLine(d.Kind, d.Name, d.Location.FilePath, d.Location.StartLine, d.Location.EndLine, descriptorSummary(d)) + "\n"
+	if code = strings.TrimRight(code, "\n"); code != "" {
+		out += code + "\n"
+	}
+	return out
+}
+
+// Code fence modes of output.code_fences
+const (
+	fenceLanguage = "language"
+	fencePlain    = "plain"
+	fenceNone     = "none"
+)
+
+// fenceLanguages maps chunk languages and file extensions to the info
+// string markdown renderers highlight.
+var fenceLanguages = map[string]string{
+	"go": "go", "golang": "go",
+	"php": "php", "laravel": "php", "blade": "blade",
+	"python": "python", "py": "python",
+	"javascript": "javascript", "js": "javascript", "jsx": "jsx", "mjs": "javascript",
+	"typescript": "typescript", "ts": "typescript", "tsx": "tsx",
+	"html": "html", "htm": "html",
+	"java": "java", "kotlin": "kotlin", "kt": "kotlin",
+	"rust": "rust", "rs": "rust",
+	"ruby": "ruby", "rb": "ruby",
+	"c": "c", "h": "c", "cpp": "cpp", "cc": "cpp", "hpp": "cpp",
+	"csharp": "csharp", "cs": "csharp",
+	"sql": "sql", "sh": "bash", "bash": "bash", "shell": "bash",
+	"yaml": "yaml", "yml": "yaml", "json": "json", "toml": "toml",
+	"markdown": "markdown", "md": "markdown",
+}
+
+// fenceLanguageFor returns the fence info string for code of language, or of
+// the file at path when the language is unknown. It is "" when neither
+// is recognised, which renders as an untagged fence.
+func fenceLanguageFor(language, path string) string {
+	if lang, ok := fenceLanguages[strings.ToLower(language)]; ok {
+		return lang
+	}
+	if path != "" {
+		ext := strings.ToLower(strings.TrimPrefix(filepath.Ext(path), "."))
+		if strings.HasSuffix(strings.ToLower(path), ".blade.php") {
+			ext = "blade"
+		}
+		if lang, ok := fenceLanguages[ext]; ok {
+			return lang
+		}
+	}
+	return ""
+}
+
+// codeBlock renders code for a markdown response according to mode (see
+// Manager.CodeFences), tagging fences with the language of the chunk or
+// file. The block ends with a newline.
+func codeBlock(mode, language, path, code string) string {
+	code = strings.TrimRight(code, "\n")
+	switch mode {
+	case fenceNone:
+		return code + "\n"
+	case fencePlain:
+		return "```\n" + code + "\n```\n"
+	}
+	return "```" + fenceLanguageFor(language, path) + "\n" + code + "\n```\n"
+}
@@ -0,0 +1,73 @@
+package tools
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/doITmagic/rag-code-mcp/internal/codetypes"
+)
+
+func TestOutputFormatFrom(t *testing.T) {
+	if got := outputFormatFrom(map[string]interface{}{}, formatMarkdown); got != formatMarkdown {
+		t.Errorf("default = %q", got)
+	}
+	if got := outputFormatFrom(map[string]interface{}{"format": "Minimal"}, formatJSON); got != formatMinimal {
+		t.Errorf("format alias = %q", got)
+	}
+	if got := outputFormatFrom(map[string]interface{}{"output_format": "json", "format": "minimal"}, formatMarkdown); got != formatJSON {
+		t.Errorf("output_format should win over format, got %q", got)
+	}
+}
+
+func TestMinimalLine(t *testing.T) {
+	cases := []struct {
+		kind, name, file string
+		start, end       int
+		summary, want    string
+	}{
+		{"function", "Open", "db.go", 3, 9, "Open opens the DB.\nMore text.", "function Open db.go:3-9 — Open opens the DB."},
+		{"usage", "Run", "main.go", 12, 0, "  Dial(x)  ", "usage Run main.go:12 — Dial(x)"},
+		{"error", "Missing", "", 0, 0, "", "error Missing"},
+	}
+	for _, c := range cases {
+		if got := minimalLine(c.kind, c.name, c.file, c.start, c.end, c.summary); got != c.want {
+			t.Errorf("minimalLine(%q, %q) = %q, want %q", c.kind, c.name, got, c.want)
+		}
+	}
+
+	long := minimalSummary(strings.Repeat("x", 300))
+	if n := len([]rune(long)); n != minimalSummaryLen || !strings.HasSuffix(long, "…") {
+		t.Errorf("long summary not truncated: %d runes", n)
+	}
+}
+
+func TestFormatMinimalDescriptors(t *testing.T) {
+	descs := []codetypes.SymbolDescriptor{
+		{Kind: "method", Name: "Charge", Signature: "func (b *Billing) Charge() error", Location: codetypes.SymbolLocation{FilePath: "billing.go", StartLine: 10, EndLine: 20}},
+		{Kind: "type", Name: "Invoice", Description: "Invoice is a bill.", Signature: "type Invoice struct", Location: codetypes.SymbolLocation{FilePath: "invoice.go", StartLine: 3, EndLine: 8}},
+	}
+	want := "method Charge billing.go:10-20 — func (b *Billing) Charge() error\n" +
+		"type Invoice invoice.go:3-8 — Invoice is a bill.\n"
+	if got := formatMinimalDescriptors(descs); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestCodeBlock(t *testing.T) {
+	cases := []struct {
+		mode, language, path, code, want string
+	}{
+		{fenceLanguage, "python", "app/models.py", "def f():\n    pass\n", "```python\ndef f():\n    pass\n```\n"},
+		{fenceLanguage, "", "src/Http/Controller.php", "<?php", "```php\n<?php\n```\n"},
+		{fenceLanguage, "", "resources/views/home.blade.php", "@if($x)", "```blade\n@if($x)\n```\n"},
+		{fenceLanguage, "golang", "", "func f() {}", "```go\nfunc f() {}\n```\n"},
+		{fenceLanguage, "", "Makefile", "all:", "```\nall:\n```\n"},
+		{fencePlain, "go", "main.go", "func f() {}", "```\nfunc f() {}\n```\n"},
+		{fenceNone, "go", "main.go", "func f() {}\n\n", "func f() {}\n"},
+	}
+	for _, c := range cases {
+		if got := codeBlock(c.mode, c.language, c.path, c.code); got != c.want {
+			t.Errorf("codeBlock(%q, %q, %q) = %q, want %q", c.mode, c.language, c.path, got, c.want)
+		}
+	}
+}
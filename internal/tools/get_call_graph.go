@@ -0,0 +1,157 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/doITmagic/rag-code-mcp/internal/ragcode"
+	"github.com/doITmagic/rag-code-mcp/internal/workspace"
+)
+
+// GetCallGraphTool returns the callers and callees of a function or method
+// as a graph of nodes and edges, built from the workspace symbol table
+type GetCallGraphTool struct {
+	workspaceManager *workspace.Manager
+}
+
+// NewGetCallGraphTool creates a new get_call_graph tool
+func NewGetCallGraphTool(wm *workspace.Manager) *GetCallGraphTool {
+	return &GetCallGraphTool{
+		workspaceManager: wm,
+	}
+}
+
+const (
+	defaultCallGraphDepth   = 2
+	maxCallGraphDepth       = 5
+	maxCallGraphNodes       = 200
+	maxListedCallGraphEdges = 100
+)
+
+func (t *GetCallGraphTool) Name() string {
+	return "get_call_graph"
+}
+
+func (t *GetCallGraphTool) Description() string {
+	return "Get the call graph around a function or method: its callers and/or callees up to N levels deep (default 2, max 5), as JSON nodes (name, kind, file, lines) and edges (caller -> callee with the call line). Use to understand impact before changing a function or to trace a request path. Accepts 'Name', 'Receiver.Name' or 'package.Name'. Supports Go, PHP, Python."
+}
+
+func (t *GetCallGraphTool) Execute(ctx context.Context, params map[string]interface{}) (string, error) {
+	symbol, _ := params["symbol_name"].(string)
+	symbol = strings.TrimSpace(symbol)
+	if symbol == "" {
+		return "", fmt.Errorf("symbol_name is required")
+	}
+	opts := ragcode.CallGraphOptions{
+		Direction: ragcode.CallGraphBoth,
+		Depth:     defaultCallGraphDepth,
+		MaxNodes:  maxCallGraphNodes,
+	}
+	if d, ok := params["depth"].(float64); ok && d >= 1 {
+		opts.Depth = int(d)
+	}
+	if opts.Depth > maxCallGraphDepth {
+		opts.Depth = maxCallGraphDepth
+	}
+	if dir, ok := params["direction"].(string); ok && dir != "" {
+		switch dir {
+		case ragcode.CallGraphCallers, ragcode.CallGraphCallees, ragcode.CallGraphBoth:
+			opts.Direction = dir
+		default:
+			return "", fmt.Errorf("invalid direction '%s': use callers, callees or both", dir)
+		}
+	}
+	if ext, ok := params["include_external"].(bool); ok {
+		opts.IncludeExternal = ext
+	}
+
+	if t.workspaceManager == nil {
+		return "", fmt.Errorf("workspace manager not configured")
+	}
+	if extractFilePathFromParams(params) == "" {
+		return "", fmt.Errorf("file_path parameter is required for get_call_graph. Please provide a file path from your workspace")
+	}
+	info, err := t.workspaceManager.DetectWorkspace(params)
+	if err != nil {
+		return "", fmt.Errorf("failed to detect workspace: %w", err)
+	}
+	table, err := t.workspaceManager.Symbols(info)
+	if err != nil {
+		return "", fmt.Errorf("failed to load symbol table: %w", err)
+	}
+	entries := table.All()
+	if len(entries) == 0 {
+		return fmt.Sprintf("❌ No symbols recorded for workspace '%s'.\n\n"+
+			"The symbol table is built during indexing. Please call 'index_workspace' with:\n"+
+			"{\n"+
+			"  \"file_path\": \"%s\"\n"+
+			"}\n", info.Root, info.Root), nil
+	}
+
+	graph, err := ragcode.BuildCallGraph(entries, symbol, opts)
+	if err != nil {
+		return "", err
+	}
+
+	if outputFormatFrom(params, formatJSON) == formatMarkdown {
+		return formatCallGraph(graph), nil
+	}
+	data, err := json.MarshalIndent(graph, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal get_call_graph results: %w", err)
+	}
+	return string(data), nil
+}
+
+// formatCallGraph renders a call graph as markdown: the nodes by depth and
+// one line per call
+func formatCallGraph(g *ragcode.CallGraph) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("# 🕸️ Call graph of `%s` (%s, depth %d)\n\n", g.Symbol, g.Direction, g.Depth))
+
+	nodes := make(map[string]ragcode.CallGraphNode, len(g.Nodes))
+	for _, n := range g.Nodes {
+		nodes[n.ID] = n
+	}
+	label := func(id string) string {
+		n := nodes[id]
+		if n.Receiver != "" {
+			return n.Receiver + "." + n.Name
+		}
+		return n.Name
+	}
+
+	sb.WriteString("## Symbols\n\n")
+	for _, n := range g.Nodes {
+		loc := "external"
+		if !n.External {
+			loc = fmt.Sprintf("`%s:%d-%d`", n.FilePath, n.StartLine, n.EndLine)
+		}
+		sb.WriteString(fmt.Sprintf("- [%d] **%s** %s %s\n", n.Depth, label(n.ID), n.Kind, loc))
+	}
+
+	sb.WriteString("\n## Calls\n\n")
+	if len(g.Edges) == 0 {
+		sb.WriteString("No calls found.\n")
+	}
+	for i, e := range g.Edges {
+		if i == maxListedCallGraphEdges {
+			sb.WriteString(fmt.Sprintf("- ... and %d more\n", len(g.Edges)-i))
+			break
+		}
+		line := fmt.Sprintf("- %s → %s", label(e.From), label(e.To))
+		if e.Line > 0 {
+			line += fmt.Sprintf(" (`%s:%d`)", e.FilePath, e.Line)
+		}
+		if e.Ambiguous {
+			line += " ⚠️ ambiguous"
+		}
+		sb.WriteString(line + "\n")
+	}
+	if g.Truncated {
+		sb.WriteString(fmt.Sprintf("\n⚠️ Graph truncated at %d symbols; lower depth or pick a direction.\n", len(g.Nodes)))
+	}
+	return sb.String()
+}
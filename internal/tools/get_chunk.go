@@ -0,0 +1,228 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/doITmagic/rag-code-mcp/internal/codetypes"
+	"github.com/doITmagic/rag-code-mcp/internal/memory"
+	"github.com/doITmagic/rag-code-mcp/internal/storage"
+	"github.com/doITmagic/rag-code-mcp/internal/workspace"
+)
+
+// GetChunkTool fetches an indexed chunk by the stable ID returned with search
+// results, so follow-up retrieval does not repeat the semantic search.
+type GetChunkTool struct {
+	workspaceManager *workspace.Manager
+}
+
+// NewGetChunkTool creates a new get_chunk tool
+func NewGetChunkTool(wm *workspace.Manager) *GetChunkTool {
+	return &GetChunkTool{
+		workspaceManager: wm,
+	}
+}
+
+// ChunkResult is a chunk with the chunks declared around it in the same file.
+type ChunkResult struct {
+	Chunk     codetypes.SymbolDescriptor   `json:"chunk"`
+	Neighbors []codetypes.SymbolDescriptor `json:"neighbors,omitempty"`
+	// Superseded is set for chunks a re-index replaced: they stay readable
+	// for workspace.tombstone_grace, but the code may have changed since
+	Superseded bool `json:"superseded,omitempty"`
+}
+
+func (t *GetChunkTool) Name() string {
+	return "get_chunk"
+}
+
+func (t *GetChunkTool) Description() string {
+	return "Fetch an indexed chunk by its chunk_id (returned in the metadata of search_code, hybrid_search and search_docs results) - returns the full code, metadata and the neighbouring chunks of the same file without repeating the semantic search. Chunk IDs are derived from file path, line range and name, so they stay stable across re-indexing until the chunk changes. Supports Go, PHP, Python."
+}
+
+func (t *GetChunkTool) Execute(ctx context.Context, args map[string]interface{}) (string, error) {
+	chunkID := ""
+	if v, ok := args["chunk_id"].(string); ok {
+		chunkID = strings.TrimSpace(v)
+	}
+	if chunkID == "" {
+		return "", fmt.Errorf("chunk_id is required")
+	}
+
+	neighbors := 2
+	if v, ok := args["neighbors"].(float64); ok && v >= 0 {
+		neighbors = int(v)
+	}
+
+	outputFormat := outputFormatFrom(args, formatJSON)
+
+	if t.workspaceManager == nil {
+		return "", fmt.Errorf("workspace manager not configured")
+	}
+	filePath := extractFilePathFromParams(args)
+	if filePath == "" {
+		return "", fmt.Errorf("file_path parameter is required for get_chunk. Please provide a file path from your workspace")
+	}
+	info, err := t.workspaceManager.DetectWorkspace(args)
+	if err != nil {
+		return "", fmt.Errorf("failed to detect workspace: %w", err)
+	}
+
+	// Chunk IDs do not encode the language: try the language of file_path first
+	languages := info.Languages
+	if lang := inferLanguageFromPath(filePath); lang != "" {
+		languages = append([]string{lang}, languages...)
+	}
+
+	status := ""
+	tried := make(map[string]bool)
+	for _, language := range languages {
+		if tried[language] {
+			continue
+		}
+		tried[language] = true
+
+		mem, msg, err := resolveLanguageMemory(ctx, t.workspaceManager, info, language)
+		if err != nil || msg != "" {
+			if status == "" {
+				status = msg
+			}
+			continue
+		}
+		doc, found, err := lookupChunk(ctx, mem, chunkID)
+		if err != nil {
+			return "", fmt.Errorf("failed to fetch chunk %s: %w", chunkID, err)
+		}
+		if !found {
+			continue
+		}
+
+		result := buildChunkResult(ctx, mem, doc, neighbors)
+		if outputFormat == formatMinimal {
+			return formatChunkResultMinimal(result), nil
+		}
+		if outputFormat == "markdown" {
+			return formatChunkResult(result, t.workspaceManager.CodeFences()), nil
+		}
+		data, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return "", fmt.Errorf("failed to marshal get_chunk result: %w", err)
+		}
+		return string(data), nil
+	}
+
+	if status != "" {
+		return status, nil
+	}
+	return fmt.Sprintf("❌ No chunk with id '%s' in workspace '%s'.\n\n"+
+		"Chunk IDs change when the code is edited and re-indexed. Run the search again to get current IDs.", chunkID, info.Root), nil
+}
+
+// lookupChunk resolves a chunk_id: the stable ID of a chunk of the visible
+// generation, or else a point ID, which finds chunks stored before
+// generations existed and tombstoned ones
+func lookupChunk(ctx context.Context, mem memory.LongTermMemory, id string) (memory.Document, bool, error) {
+	docs, err := mem.Query(ctx, memory.SearchOptions{
+		Filter: memory.Filter{Must: map[string][]string{workspace.StableIDKey: {id}}},
+		Limit:  1,
+	})
+	if err != nil {
+		return memory.Document{}, false, err
+	}
+	if len(docs) > 0 {
+		return docs[0], true, nil
+	}
+	getter, ok := mem.(interface {
+		GetByID(ctx context.Context, id string) (memory.Document, bool, error)
+	})
+	if !ok {
+		return memory.Document{}, false, nil
+	}
+	return getter.GetByID(ctx, id)
+}
+
+// buildChunkResult renders doc with its full code and up to n neighbouring
+// chunks of the same file. Neighbours are compact: their code is left out.
+func buildChunkResult(ctx context.Context, mem memory.LongTermMemory, doc memory.Document, n int) ChunkResult {
+	result := ChunkResult{
+		Chunk:      buildSymbolDescriptorsFromDocs([]memory.Document{doc})[0],
+		Superseded: storage.Tombstoned(doc.Metadata),
+	}
+
+	var chunk codetypes.CodeChunk
+	if err := json.Unmarshal([]byte(doc.Content), &chunk); err != nil || chunk.FilePath == "" {
+		// Documentation chunks are stored as plain text: return all of it
+		if result.Chunk.Metadata == nil {
+			result.Chunk.Metadata = make(map[string]any)
+		}
+		result.Chunk.Metadata["snippet"] = doc.Content
+		return result
+	}
+	if n == 0 {
+		return result
+	}
+	chunks, err := loadFileChunks(ctx, mem, nil, chunk.FilePath, "")
+	if err != nil {
+		return result
+	}
+	self := locatedChunk{doc: doc, chunk: chunk}
+	for _, desc := range buildSymbolDescriptorsFromDocs(chunkDocuments(neighbourChunks(chunks, &self, chunk.StartLine, n))) {
+		delete(desc.Metadata, "snippet")
+		result.Neighbors = append(result.Neighbors, desc)
+	}
+	return result
+}
+
+const supersededNote = "⚠️ This chunk was replaced by a re-index and will be removed shortly. Run the search again for the current code."
+
+func formatChunkResult(result ChunkResult, fences string) string {
+	var sb strings.Builder
+	c := result.Chunk
+	sb.WriteString(fmt.Sprintf("# `%s` (%s) - `%s:%d-%d`\n\n", c.Name, c.Kind, c.Location.FilePath, c.Location.StartLine, c.Location.EndLine))
+	if result.Superseded {
+		sb.WriteString(supersededNote + "\n\n")
+	}
+	if c.Signature != "" {
+		sb.WriteString(fmt.Sprintf("`%s`\n\n", c.Signature))
+	}
+	if c.Description != "" {
+		sb.WriteString(c.Description + "\n\n")
+	}
+	if code, ok := c.Metadata["snippet"].(string); ok {
+		sb.WriteString(codeBlock(fences, c.Language, c.Location.FilePath, code) + "\n")
+	}
+	if partial, _ := c.Metadata[codetypes.MetaPartial].(bool); partial {
+		sb.WriteString("_Partial: extracted from a file with syntax errors, the code may be incomplete._\n\n")
+	}
+	if truncated, _ := c.Metadata["truncated"].(bool); truncated {
+		sb.WriteString(fmt.Sprintf("_Code truncated to %v lines: call get_code_context with file_path `%s`, start_line %d, end_line %d for all of it._\n\n",
+			c.Metadata["code_lines"], c.Location.FilePath, c.Location.StartLine, c.Location.EndLine))
+		if summary, ok := c.Metadata["class_summary"].(string); ok {
+			sb.WriteString("**Summary:**\n\n" + summary + "\n\n")
+		}
+	}
+	if len(result.Neighbors) > 0 {
+		sb.WriteString("## Neighbors\n\n")
+		for _, n := range result.Neighbors {
+			sb.WriteString(fmt.Sprintf("- `%s` (%s) `%s:%d-%d`", n.Name, n.Kind, n.Location.FilePath, n.Location.StartLine, n.Location.EndLine))
+			if id, ok := n.Metadata["chunk_id"].(string); ok {
+				sb.WriteString(fmt.Sprintf(" [chunk_id %s]", id))
+			}
+			sb.WriteString("\n")
+		}
+	}
+	return sb.String()
+}
+
+// formatChunkResultMinimal renders the chunk line and code, then one line per
+// neighbour.
+func formatChunkResultMinimal(result ChunkResult) string {
+	code, _ := result.Chunk.Metadata["snippet"].(string)
+	out := formatMinimalCode(result.Chunk, code) + formatMinimalDescriptors(result.Neighbors)
+	if result.Superseded {
+		out = supersededNote + "\n" + out
+	}
+	return out
+}
@@ -0,0 +1,77 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/doITmagic/rag-code-mcp/internal/codetypes"
+	"github.com/doITmagic/rag-code-mcp/internal/memory"
+	"github.com/doITmagic/rag-code-mcp/internal/workspace"
+)
+
+func TestBuildChunkResult(t *testing.T) {
+	mem := memory.NewInMemoryLongTermMemory()
+	for i, ch := range []codetypes.CodeChunk{
+		{Name: "Open", Type: "function", Language: "go", FilePath: "/ws/db.go", StartLine: 1, EndLine: 5, Code: "func Open() {}"},
+		{Name: "Close", Type: "function", Language: "go", FilePath: "/ws/db.go", StartLine: 7, EndLine: 9, Code: "func Close() {}"},
+		{Name: "Query", Type: "function", Language: "go", FilePath: "/ws/db.go", StartLine: 30, EndLine: 40, Code: "func Query() {}"},
+	} {
+		content, _ := json.Marshal(ch)
+		doc := memory.Document{ID: []string{"1", "2", "3"}[i], Content: string(content), Metadata: map[string]interface{}{"file": ch.FilePath}}
+		if err := mem.Store(context.Background(), doc); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	doc, found, err := mem.GetByID(context.Background(), "2")
+	if err != nil || !found {
+		t.Fatalf("GetByID: found=%v err=%v", found, err)
+	}
+
+	result := buildChunkResult(context.Background(), mem, doc, 1)
+	if result.Chunk.Name != "Close" || result.Chunk.Metadata["chunk_id"] != "2" || result.Chunk.Metadata["snippet"] != "func Close() {}" {
+		t.Errorf("chunk = %+v", result.Chunk)
+	}
+	if len(result.Neighbors) != 1 || result.Neighbors[0].Name != "Open" {
+		t.Fatalf("neighbors = %+v, want the closest chunk Open", result.Neighbors)
+	}
+	if _, ok := result.Neighbors[0].Metadata["snippet"]; ok {
+		t.Errorf("neighbors should not carry code")
+	}
+
+	if got := buildChunkResult(context.Background(), mem, doc, 0); len(got.Neighbors) != 0 {
+		t.Errorf("neighbors=0 should skip neighbours, got %+v", got.Neighbors)
+	}
+
+	section := memory.Document{ID: "99", Content: "# Setup\n\nRun make.", Metadata: map[string]interface{}{"file": "/ws/README.md", "chunk_id": "0", "chunk_type": "markdown"}}
+	got := buildChunkResult(context.Background(), mem, section, 2)
+	if got.Chunk.Metadata["chunk_id"] != "99" {
+		t.Errorf("chunk_id = %v, want the document ID, not the section index", got.Chunk.Metadata["chunk_id"])
+	}
+	if got.Chunk.Metadata["snippet"] != section.Content {
+		t.Errorf("documentation chunk should return its full text, got %v", got.Chunk.Metadata["snippet"])
+	}
+}
+
+func TestLookupChunkByStableID(t *testing.T) {
+	ctx := context.Background()
+	mem := memory.NewInMemoryLongTermMemory()
+	// Point IDs change with every index generation, the stable ID does not
+	_ = mem.Store(ctx, memory.Document{ID: "9001", Content: "func Open() {}", Metadata: map[string]interface{}{workspace.StableIDKey: "42"}})
+	_ = mem.Store(ctx, memory.Document{ID: "7", Content: "func Legacy() {}"})
+
+	doc, found, err := lookupChunk(ctx, mem, "42")
+	if err != nil || !found || doc.ID != "9001" {
+		t.Fatalf("lookupChunk(42) = %+v, %v, %v", doc, found, err)
+	}
+	if got := chunkIDLabel(doc); got != " [chunk_id 42]" {
+		t.Errorf("label = %q, want the stable ID", got)
+	}
+	if doc, found, _ := lookupChunk(ctx, mem, "7"); !found || doc.ID != "7" {
+		t.Errorf("points stored before generations should resolve by point ID")
+	}
+	if _, found, _ := lookupChunk(ctx, mem, "9002"); found {
+		t.Error("unknown ID resolved")
+	}
+}
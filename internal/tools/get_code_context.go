@@ -6,16 +6,26 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+
+	"github.com/doITmagic/rag-code-mcp/internal/workspace"
 )
 
 // GetCodeContextTool reads code from a file with surrounding context lines
-type GetCodeContextTool struct{}
+type GetCodeContextTool struct {
+	workspaceManager *workspace.Manager
+}
 
 // NewGetCodeContextTool creates a new code context tool
 func NewGetCodeContextTool() *GetCodeContextTool {
 	return &GetCodeContextTool{}
 }
 
+// SetWorkspaceManager sets the workspace manager, whose output settings
+// (output.code_fences) the tool follows
+func (t *GetCodeContextTool) SetWorkspaceManager(wm *workspace.Manager) {
+	t.workspaceManager = wm
+}
+
 func (t *GetCodeContextTool) Name() string {
 	return "get_code_context"
 }
@@ -40,8 +50,8 @@ func (t *GetCodeContextTool) Execute(ctx context.Context, args map[string]interf
 		return "", fmt.Errorf("end_line is required")
 	}
 
-	// Optional context lines (default: 5)
-	contextLines := 5
+	// Optional context lines (default: 5, or sized to the client's context window)
+	contextLines := budgetFor(ctx, t.workspaceManager, args).ContextLines
 	if ctx, ok := args["context_lines"].(float64); ok {
 		contextLines = int(ctx)
 	}
@@ -92,32 +102,32 @@ func (t *GetCodeContextTool) Execute(ctx context.Context, args map[string]interf
 	response.WriteString(fmt.Sprintf("**Lines:** %d-%d (with %d lines context)\n", start, end, contextLines))
 	response.WriteString(fmt.Sprintf("**Total file lines:** %d\n\n", totalLines))
 
-	response.WriteString("```go\n")
+	var code strings.Builder
 
 	// Add context before (dimmed)
 	if contextStart < start {
 		for i := contextStart; i < start; i++ {
-			response.WriteString(fmt.Sprintf("%4d │ %s\n", i, lines[i-1]))
+			code.WriteString(fmt.Sprintf("%4d │ %s\n", i, lines[i-1]))
 		}
 		if contextStart < start {
-			response.WriteString("     ┄┄┄┄┄┄┄┄┄┄┄┄┄┄┄┄┄┄┄┄┄┄┄┄┄┄┄┄┄┄┄┄┄┄┄┄┄┄┄┄\n")
+			code.WriteString("     ┄┄┄┄┄┄┄┄┄┄┄┄┄┄┄┄┄┄┄┄┄┄┄┄┄┄┄┄┄┄┄┄┄┄┄┄┄┄┄┄\n")
 		}
 	}
 
 	// Add main content (highlighted)
 	for i := start; i <= end; i++ {
-		response.WriteString(fmt.Sprintf("%4d ┃ %s\n", i, lines[i-1]))
+		code.WriteString(fmt.Sprintf("%4d ┃ %s\n", i, lines[i-1]))
 	}
 
 	// Add context after (dimmed)
 	if end < contextEnd {
-		response.WriteString("     ┄┄┄┄┄┄┄┄┄┄┄┄┄┄┄┄┄┄┄┄┄┄┄┄┄┄┄┄┄┄┄┄┄┄┄┄┄┄┄┄\n")
+		code.WriteString("     ┄┄┄┄┄┄┄┄┄┄┄┄┄┄┄┄┄┄┄┄┄┄┄┄┄┄┄┄┄┄┄┄┄┄┄┄┄┄┄┄\n")
 		for i := end + 1; i <= contextEnd; i++ {
-			response.WriteString(fmt.Sprintf("%4d │ %s\n", i, lines[i-1]))
+			code.WriteString(fmt.Sprintf("%4d │ %s\n", i, lines[i-1]))
 		}
 	}
 
-	response.WriteString("```\n")
+	response.WriteString(codeBlock(t.workspaceManager.CodeFences(), "", resolvedPath, code.String()))
 
 	return response.String(), nil
 }
@@ -55,11 +55,7 @@ func (t *GetFunctionDetailsTool) Execute(ctx context.Context, args map[string]in
 		packagePath = pkg
 	}
 
-	// Optional output format: markdown (default) or json
-	outputFormat := "markdown"
-	if of, ok := args["output_format"].(string); ok && of != "" {
-		outputFormat = strings.ToLower(of)
-	}
+	outputFormat := outputFormatFrom(args, formatMarkdown)
 
 	// file_path is required for workspace detection
 	filePath := extractFilePathFromParams(args)
@@ -135,39 +131,15 @@ func (t *GetFunctionDetailsTool) Execute(ctx context.Context, args map[string]in
 	}
 
 	// First, try exact name+type search (faster and more accurate)
-	type ExactSearcher interface {
-		SearchByNameAndType(ctx context.Context, name string, types []string) ([]memory.Document, error)
-	}
-
-	functionKinds := []string{"function", "method"}
-
-	var results []memory.Document
-	if exactSearcher, ok := searchMemory.(ExactSearcher); ok {
-		results, err = exactSearcher.SearchByNameAndType(ctx, functionName, functionKinds)
-		if err == nil && len(results) > 0 {
-			// Found exact match, use it directly
-			goto processResults
-		}
-	}
-
-	// Fallback to semantic search if exact search didn't find anything
-	{
-		type CodeSearcher interface {
-			SearchCodeOnly(ctx context.Context, query []float64, limit int) ([]memory.Document, error)
-		}
-
-		if codeSearcher, ok := searchMemory.(CodeSearcher); ok {
-			results, err = codeSearcher.SearchCodeOnly(ctx, queryEmbedding, 50)
-		} else {
-			results, err = searchMemory.Search(ctx, queryEmbedding, 50)
-		}
+	results, err := searchMemory.Query(ctx, memory.SearchOptions{Filter: symbolFilter(functionName, "function", "method")})
+	if err != nil || len(results) == 0 {
+		// Fallback to semantic search if exact search didn't find anything
+		results, err = searchMemory.Query(ctx, memory.SearchOptions{Vector: queryEmbedding, Filter: memory.CodeOnly(), Limit: 50})
 		if err != nil {
 			return "", fmt.Errorf("search failed: %w", err)
 		}
 	}
 
-processResults:
-
 	if len(results) == 0 {
 		// Check if this is a workspace search with empty collection
 		if workspacePath != "" && collectionName != "" {
@@ -227,6 +199,10 @@ processResults:
 		}
 	}
 
+	if outputFormat == formatMinimal {
+		return formatMinimalCode(symbolDescriptorFromChunk(chunk), codeBody), nil
+	}
+
 	// PHP: use PHP analyzer directly on the source file to build a rich function/method view
 	if chunk.Language == "php" {
 		return t.buildPHPFunctionResponse(&chunk, codeBody, outputFormat)
@@ -242,7 +218,7 @@ processResults:
 			desc = buildGoFunctionDescriptor(&chunk, codeBody)
 		} else {
 			desc = codetypes.FunctionDescriptor{
-				Language:    chunk.Language,
+				Language:    string(chunk.Language),
 				Kind:        chunk.Type,
 				Name:        chunk.Name,
 				Namespace:   chunk.Package,
@@ -277,9 +253,8 @@ processResults:
 	response.WriteString(fmt.Sprintf("**Location:** `%s:%d-%d`\n\n", chunk.FilePath, chunk.StartLine, chunk.EndLine))
 
 	if codeBody != "" {
-		response.WriteString("**Code:**\n```go\n")
-		response.WriteString(codeBody)
-		response.WriteString("\n```\n")
+		response.WriteString("**Code:**\n")
+		response.WriteString(codeBlock(t.workspaceManager.CodeFences(), string(chunk.Language), chunk.FilePath, codeBody))
 	}
 
 	return response.String(), nil
@@ -290,7 +265,7 @@ processResults:
 // (receiver, parameters, returns).
 func buildGoFunctionDescriptor(chunk *codetypes.CodeChunk, codeBody string) codetypes.FunctionDescriptor {
 	fd := codetypes.FunctionDescriptor{
-		Language:    chunk.Language,
+		Language:    string(chunk.Language),
 		Kind:        chunk.Type,
 		Name:        chunk.Name,
 		Namespace:   chunk.Package,
@@ -305,7 +280,7 @@ func buildGoFunctionDescriptor(chunk *codetypes.CodeChunk, codeBody string) code
 	}
 
 	// Go-specific enrichment based on analyzer metadata
-	if strings.ToLower(chunk.Language) != "go" {
+	if strings.ToLower(string(chunk.Language)) != "go" {
 		return fd
 	}
 
@@ -402,7 +377,7 @@ func (t *GetFunctionDetailsTool) buildPHPFunctionResponse(chunk *codetypes.CodeC
 	// Helper to build a FunctionDescriptor from MethodInfo/FunctionInfo.
 	buildDescriptor := func(methodInfo *php.MethodInfo, funcInfo *php.FunctionInfo, className, namespace string, eloquentModel *laravel.EloquentModel) codetypes.FunctionDescriptor {
 		fd := codetypes.FunctionDescriptor{
-			Language:  chunk.Language,
+			Language:  string(chunk.Language),
 			Kind:      chunk.Type,
 			Name:      chunk.Name,
 			Namespace: namespace,
@@ -535,7 +510,7 @@ func (t *GetFunctionDetailsTool) buildPHPFunctionResponse(chunk *codetypes.CodeC
 		if format == "json" {
 			// Minimal descriptor from the chunk only
 			desc := codetypes.FunctionDescriptor{
-				Language:    chunk.Language,
+				Language:    string(chunk.Language),
 				Kind:        chunk.Type,
 				Name:        chunk.Name,
 				Namespace:   chunk.Package,
@@ -561,7 +536,7 @@ func (t *GetFunctionDetailsTool) buildPHPFunctionResponse(chunk *codetypes.CodeC
 		// Degrade gracefully to a simple representation
 		if format == "json" {
 			desc := codetypes.FunctionDescriptor{
-				Language:    chunk.Language,
+				Language:    string(chunk.Language),
 				Kind:        chunk.Type,
 				Name:        chunk.Name,
 				Namespace:   chunk.Package,
@@ -590,9 +565,8 @@ func (t *GetFunctionDetailsTool) buildPHPFunctionResponse(chunk *codetypes.CodeC
 			response.WriteString(fmt.Sprintf("**Signature:** `%s`\n\n", chunk.Signature))
 		}
 		if codeBody != "" {
-			response.WriteString("**Code:**\n```php\n")
-			response.WriteString(codeBody)
-			response.WriteString("\n```\n")
+			response.WriteString("**Code:**\n")
+			response.WriteString(codeBlock(t.workspaceManager.CodeFences(), string(chunk.Language), chunk.FilePath, codeBody))
 		}
 		return response.String(), nil
 	}
@@ -832,9 +806,8 @@ func (t *GetFunctionDetailsTool) buildPHPFunctionResponse(chunk *codetypes.CodeC
 
 	// Code snippet
 	if codeBody != "" {
-		response.WriteString("**Code:**\n```php\n")
-		response.WriteString(codeBody)
-		response.WriteString("\n```\n")
+		response.WriteString("**Code:**\n")
+		response.WriteString(codeBlock(t.workspaceManager.CodeFences(), string(chunk.Language), chunk.FilePath, codeBody))
 	}
 
 	return response.String(), nil
@@ -0,0 +1,161 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/doITmagic/rag-code-mcp/internal/workspace"
+)
+
+// GetLanguageCoverageTool reports, per language, how many source files of a
+// workspace are indexed and why the others are not
+type GetLanguageCoverageTool struct {
+	workspaceManager *workspace.Manager
+}
+
+// NewGetLanguageCoverageTool creates a new get_language_coverage tool
+func NewGetLanguageCoverageTool(wm *workspace.Manager) *GetLanguageCoverageTool {
+	return &GetLanguageCoverageTool{
+		workspaceManager: wm,
+	}
+}
+
+func (t *GetLanguageCoverageTool) Name() string {
+	return "get_language_coverage"
+}
+
+func (t *GetLanguageCoverageTool) Description() string {
+	return "Report index coverage per language: source files found, indexed, pending and skipped, with the reason for skipped files (excluded, too large, parse error, language disabled, no analyzer: C/C++ and C# need a cgo build) and parser error counts. Use when expected code is not found by search_code."
+}
+
+func (t *GetLanguageCoverageTool) Execute(ctx context.Context, params map[string]interface{}) (string, error) {
+	if t.workspaceManager == nil {
+		return "", fmt.Errorf("workspace manager not configured")
+	}
+	if extractFilePathFromParams(params) == "" {
+		return "", fmt.Errorf("file_path parameter is required for get_language_coverage. Please provide a file path from your workspace")
+	}
+	info, err := t.workspaceManager.DetectWorkspace(params)
+	if err != nil {
+		return "", fmt.Errorf("failed to detect workspace: %w", err)
+	}
+	report, err := t.workspaceManager.LanguageCoverage(info)
+	if err != nil {
+		return "", err
+	}
+
+	if outputFormatFrom(params, formatMarkdown) == formatJSON {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return "", fmt.Errorf("failed to marshal get_language_coverage results: %w", err)
+		}
+		return string(data), nil
+	}
+	return FormatLanguageCoverage(report), nil
+}
+
+// maxListedParseFailures bounds the files listed with parse errors per
+// language in markdown
+const maxListedParseFailures = 10
+
+// FormatLanguageCoverage renders a language coverage report as markdown.
+func FormatLanguageCoverage(r *workspace.LanguageCoverageReport) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("# 🗺️ Language coverage for %s\n\n", r.Root))
+	if len(r.Languages) == 0 {
+		sb.WriteString("No source files found.\n")
+		return sb.String()
+	}
+
+	sb.WriteString("| Language | Found | Indexed | Pending | Skipped | Parse errors |\n|----------|------:|------:|------:|------:|------:|\n")
+	for _, lc := range r.Languages {
+		errs := "-"
+		if lc.ParseErrors > 0 {
+			errs = fmt.Sprintf("%d in %d file(s)", lc.ParseErrors, lc.ErrorFiles)
+		}
+		sb.WriteString(fmt.Sprintf("| %s | %d | %d | %d | %d | %s |\n",
+			lc.Language, lc.FilesFound, lc.FilesIndexed, lc.FilesPending, lc.FilesSkipped, errs))
+	}
+
+	for _, lc := range r.Languages {
+		if len(lc.Skipped) == 0 {
+			continue
+		}
+		sb.WriteString(fmt.Sprintf("\n## %s: skipped files\n\n", lc.Language))
+		for _, skip := range lc.Skipped {
+			line := fmt.Sprintf("- **%s**: %d file(s)", skipReasonLabel(skip.Reason), skip.Files)
+			if skip.Detail != "" {
+				line += " - " + skip.Detail
+			}
+			sb.WriteString(line + "\n")
+			for _, ex := range skip.Examples {
+				sb.WriteString(fmt.Sprintf("  - `%s`\n", ex))
+			}
+			if more := skip.Files - len(skip.Examples); len(skip.Examples) > 0 && more > 0 {
+				sb.WriteString(fmt.Sprintf("  - ... and %d more\n", more))
+			}
+		}
+	}
+
+	for _, lc := range r.Languages {
+		if len(lc.Failures) == 0 {
+			continue
+		}
+		sb.WriteString(fmt.Sprintf("\n## %s: parse errors\n\n", lc.Language))
+		for i, f := range lc.Failures {
+			if i == maxListedParseFailures {
+				sb.WriteString(fmt.Sprintf("- ... and %d more file(s)\n", len(lc.Failures)-i))
+				break
+			}
+			e := f.Errors[0]
+			loc := f.Path
+			if e.Line > 0 {
+				loc = fmt.Sprintf("%s:%d", f.Path, e.Line)
+			}
+			line := fmt.Sprintf("- `%s` (%s): %s", loc, f.Analyzer, e.Message)
+			if more := len(f.Errors) - 1; more > 0 {
+				line += fmt.Sprintf(" (+%d more)", more)
+			}
+			sb.WriteString(line + "\n")
+		}
+	}
+	if hasParseFailures(r) {
+		sb.WriteString("\nFiles that failed to parse are not analyzed again until their content changes.\n")
+	}
+
+	for _, lc := range r.Languages {
+		if lc.FilesPending > 0 {
+			sb.WriteString("\nPending files are indexed by the next index_workspace run (or automatically on the next search).\n")
+			break
+		}
+	}
+	return sb.String()
+}
+
+// skipReasonLabel renders a skip reason for humans
+func skipReasonLabel(reason string) string {
+	switch reason {
+	case workspace.SkipExcluded:
+		return "excluded"
+	case workspace.SkipTooLarge:
+		return "too large"
+	case workspace.SkipParseError:
+		return "parse error"
+	case workspace.SkipNoAnalyzer:
+		return "no analyzer"
+	case workspace.SkipDisabled:
+		return "language disabled"
+	}
+	return reason
+}
+
+func hasParseFailures(r *workspace.LanguageCoverageReport) bool {
+	for _, lc := range r.Languages {
+		if len(lc.Failures) > 0 {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,131 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/doITmagic/rag-code-mcp/internal/ragcode"
+	"github.com/doITmagic/rag-code-mcp/internal/workspace"
+)
+
+// GetPackageDependenciesTool returns the import graph around a package: what
+// it imports and which workspace packages import it, built from the imports
+// recorded in the workspace symbol table
+type GetPackageDependenciesTool struct {
+	workspaceManager *workspace.Manager
+}
+
+// NewGetPackageDependenciesTool creates a new get_package_dependencies tool
+func NewGetPackageDependenciesTool(wm *workspace.Manager) *GetPackageDependenciesTool {
+	return &GetPackageDependenciesTool{
+		workspaceManager: wm,
+	}
+}
+
+// maxListedDependencyFiles bounds the files listed per dependency in markdown
+const maxListedDependencyFiles = 5
+
+func (t *GetPackageDependenciesTool) Name() string {
+	return "get_package_dependencies"
+}
+
+func (t *GetPackageDependenciesTool) Description() string {
+	return "Get the import graph of a package/module: the workspace packages and external dependencies it imports, and the workspace packages that import it, each with the importing files. Use to reason about layering and the impact of changing a package. Accepts a Go import path or package name, a Python module, a PHP or C# namespace, or a directory; defaults to the package of file_path. Supports Go, PHP, Python, C#."
+}
+
+func (t *GetPackageDependenciesTool) Execute(ctx context.Context, params map[string]interface{}) (string, error) {
+	if t.workspaceManager == nil {
+		return "", fmt.Errorf("workspace manager not configured")
+	}
+	filePath := extractFilePathFromParams(params)
+	if filePath == "" {
+		return "", fmt.Errorf("file_path parameter is required for get_package_dependencies. Please provide a file path from your workspace")
+	}
+	info, err := t.workspaceManager.DetectWorkspace(params)
+	if err != nil {
+		return "", fmt.Errorf("failed to detect workspace: %w", err)
+	}
+	table, err := t.workspaceManager.Symbols(info)
+	if err != nil {
+		return "", fmt.Errorf("failed to load symbol table: %w", err)
+	}
+	files := table.AllImports()
+	if len(files) == 0 {
+		return fmt.Sprintf("❌ No imports recorded for workspace '%s'.\n\n"+
+			"Imports are recorded during indexing. Please call 'index_workspace' with:\n"+
+			"{\n"+
+			"  \"file_path\": \"%s\"\n"+
+			"}\n", info.Root, info.Root), nil
+	}
+
+	pkg, _ := params["package"].(string)
+	if pkg = strings.TrimSpace(pkg); pkg == "" {
+		abs, _ := filepath.Abs(filePath)
+		for _, f := range files {
+			if f.FilePath == abs || f.FilePath == filePath {
+				pkg = f.Package
+				break
+			}
+		}
+		if pkg == "" {
+			return "", fmt.Errorf("no imports recorded for %s: pass the package parameter", filePath)
+		}
+	}
+
+	deps, err := ragcode.BuildDependencies(files, pkg, info.Root)
+	if err != nil {
+		return "", err
+	}
+
+	if outputFormatFrom(params, formatJSON) == formatMarkdown {
+		return formatPackageDependencies(deps, info.Root), nil
+	}
+	data, err := json.MarshalIndent(deps, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal get_package_dependencies results: %w", err)
+	}
+	return string(data), nil
+}
+
+// formatPackageDependencies renders the import graph of a package as
+// markdown, with file paths relative to root
+func formatPackageDependencies(d *ragcode.PackageDependencies, root string) string {
+	var internal, external []ragcode.PackageDependency
+	for _, dep := range d.Imports {
+		if dep.External {
+			external = append(external, dep)
+		} else {
+			internal = append(internal, dep)
+		}
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("# 📦 Dependencies of `%s` (%s, %d %s)\n\n", d.Package, d.Language, len(d.Files), plural(len(d.Files), "file", "files")))
+	writeDependencies(&sb, "Imports from the workspace", internal, root)
+	writeDependencies(&sb, "External imports", external, root)
+	writeDependencies(&sb, "Imported by", d.ImportedBy, root)
+	return sb.String()
+}
+
+func writeDependencies(sb *strings.Builder, title string, deps []ragcode.PackageDependency, root string) {
+	sb.WriteString(fmt.Sprintf("## %s (%d)\n\n", title, len(deps)))
+	if len(deps) == 0 {
+		sb.WriteString("None.\n\n")
+		return
+	}
+	for _, dep := range deps {
+		files := make([]string, 0, maxListedDependencyFiles)
+		for i, f := range dep.Files {
+			if i == maxListedDependencyFiles {
+				files = append(files, fmt.Sprintf("%d more", len(dep.Files)-i))
+				break
+			}
+			files = append(files, groupLabel(f, root))
+		}
+		sb.WriteString(fmt.Sprintf("- `%s` (%s)\n", dep.Package, strings.Join(files, ", ")))
+	}
+	sb.WriteString("\n")
+}
@@ -0,0 +1,302 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/doITmagic/rag-code-mcp/internal/codetypes"
+	"github.com/doITmagic/rag-code-mcp/internal/ragcode"
+	"github.com/doITmagic/rag-code-mcp/internal/workspace"
+)
+
+// GetSymbolsBulkTool resolves many symbols in one call from the persisted
+// symbol table, without running a semantic search per symbol.
+type GetSymbolsBulkTool struct {
+	workspaceManager *workspace.Manager
+}
+
+// NewGetSymbolsBulkTool creates a new get_symbols_bulk tool
+func NewGetSymbolsBulkTool(wm *workspace.Manager) *GetSymbolsBulkTool {
+	return &GetSymbolsBulkTool{
+		workspaceManager: wm,
+	}
+}
+
+// maxBulkSymbols bounds the number of requested symbols per call
+const maxBulkSymbols = 50
+
+// SymbolRequest is one requested symbol of get_symbols_bulk.
+type SymbolRequest struct {
+	Name    string `json:"name"`
+	Kind    string `json:"kind,omitempty"`
+	Package string `json:"package,omitempty"`
+}
+
+// SymbolResult is the outcome for one requested symbol. Error is set when the
+// request was invalid or nothing matched.
+type SymbolResult struct {
+	Request   SymbolRequest                `json:"request"`
+	Symbols   []codetypes.SymbolDescriptor `json:"symbols,omitempty"`
+	Truncated bool                         `json:"truncated,omitempty"`
+	Error     string                       `json:"error,omitempty"`
+}
+
+func (t *GetSymbolsBulkTool) Name() string {
+	return "get_symbols_bulk"
+}
+
+func (t *GetSymbolsBulkTool) Description() string {
+	return "Fetch the definitions of many symbols at once - pass a list of {name, kind, package} (up to 50) and get compact descriptors (kind, signature, location, optionally code) for all of them in one call, with a per-item error for symbols that are not found. Use instead of calling get_function_details / find_type_definition repeatedly. Supports Go, PHP, Python."
+}
+
+func (t *GetSymbolsBulkTool) Execute(ctx context.Context, args map[string]interface{}) (string, error) {
+	requests, err := parseSymbolRequests(args["symbols"])
+	if err != nil {
+		return "", err
+	}
+
+	maxMatches := 3
+	if v, ok := args["max_matches"].(float64); ok && v > 0 {
+		maxMatches = int(v)
+	}
+	includeCode, _ := args["include_code"].(bool)
+
+	outputFormat := outputFormatFrom(args, formatJSON)
+
+	if t.workspaceManager == nil {
+		return "", fmt.Errorf("workspace manager not configured")
+	}
+	if extractFilePathFromParams(args) == "" {
+		return "", fmt.Errorf("file_path parameter is required for get_symbols_bulk. Please provide a file path from your workspace")
+	}
+	info, err := t.workspaceManager.DetectWorkspace(args)
+	if err != nil {
+		return "", fmt.Errorf("failed to detect workspace: %w", err)
+	}
+
+	table, err := t.workspaceManager.Symbols(info)
+	if err != nil {
+		return "", fmt.Errorf("failed to load symbol table: %w", err)
+	}
+	entries := table.All()
+	if len(entries) == 0 {
+		return fmt.Sprintf("❌ No symbols recorded for workspace '%s'.\n\n"+
+			"The symbol table is built during indexing. Please call 'index_workspace' with:\n"+
+			"{\n"+
+			"  \"file_path\": \"%s\"\n"+
+			"}\n", info.Root, info.Root), nil
+	}
+
+	results := resolveSymbols(entries, requests, maxMatches, includeCode)
+
+	if outputFormat == formatMinimal {
+		return formatSymbolResultsMinimal(results), nil
+	}
+	if outputFormat == "markdown" {
+		return formatSymbolResults(results, t.workspaceManager.CodeFences()), nil
+	}
+
+	data, err := json.MarshalIndent(results, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal get_symbols_bulk results: %w", err)
+	}
+	return string(data), nil
+}
+
+// parseSymbolRequests accepts a list of {name, kind, package} objects or of
+// plain symbol names.
+func parseSymbolRequests(raw interface{}) ([]SymbolRequest, error) {
+	list, ok := raw.([]interface{})
+	if !ok || len(list) == 0 {
+		return nil, fmt.Errorf("symbols is required: a list of {name, kind, package}")
+	}
+	if len(list) > maxBulkSymbols {
+		return nil, fmt.Errorf("too many symbols requested (%d), the maximum is %d", len(list), maxBulkSymbols)
+	}
+
+	requests := make([]SymbolRequest, 0, len(list))
+	for _, item := range list {
+		var req SymbolRequest
+		switch v := item.(type) {
+		case string:
+			req.Name = v
+		case map[string]interface{}:
+			req.Name, _ = v["name"].(string)
+			req.Kind, _ = v["kind"].(string)
+			req.Package, _ = v["package"].(string)
+		}
+		req.Name = strings.TrimSpace(req.Name)
+		requests = append(requests, req)
+	}
+	return requests, nil
+}
+
+// resolveSymbols looks up every request in the symbol table, keeping at most
+// maxMatches symbols per request.
+func resolveSymbols(entries []ragcode.SymbolEntry, requests []SymbolRequest, maxMatches int, includeCode bool) []SymbolResult {
+	files := make(map[string][]string)
+	results := make([]SymbolResult, 0, len(requests))
+	for _, req := range requests {
+		res := SymbolResult{Request: req}
+		if req.Name == "" {
+			res.Error = "name is required"
+			results = append(results, res)
+			continue
+		}
+
+		for _, e := range entries {
+			if !symbolMatches(e, req) {
+				continue
+			}
+			if len(res.Symbols) == maxMatches {
+				res.Truncated = true
+				break
+			}
+			desc := symbolDescriptorFromEntry(e)
+			if includeCode {
+				lines, ok := files[e.FilePath]
+				if !ok {
+					lines = readLines(e.FilePath)
+					files[e.FilePath] = lines
+				}
+				if e.StartLine >= 1 && e.EndLine <= len(lines) && e.StartLine <= e.EndLine {
+					desc.Metadata["code"] = strings.Join(lines[e.StartLine-1:e.EndLine], "\n")
+				}
+			}
+			res.Symbols = append(res.Symbols, desc)
+		}
+		if len(res.Symbols) == 0 {
+			res.Error = fmt.Sprintf("symbol '%s' not found", req.Name)
+		}
+		results = append(results, res)
+	}
+	return results
+}
+
+// symbolMatches compares a table entry with a request. Names may be
+// qualified with their receiver or class ("Billing.charge", "Billing::charge"),
+// their package or namespace ("http.Get", "strings.Builder",
+// `App\Models\User`) or both ("http.Client.Do", `App\Models\User::find`);
+// packages match by full path or trailing segment.
+func symbolMatches(e ragcode.SymbolEntry, req SymbolRequest) bool {
+	name := req.Name
+	for _, sep := range []string{"::", "->", `\`, "."} {
+		if i := strings.LastIndex(name, sep); i > 0 {
+			if !qualifierMatches(e, name[:i]) {
+				return false
+			}
+			name = name[i+len(sep):]
+			break
+		}
+	}
+	if e.Name != name {
+		return false
+	}
+	if req.Kind != "" && !strings.EqualFold(e.Kind, req.Kind) {
+		return false
+	}
+	if req.Package != "" && !packageMatches(e.Package, req.Package) {
+		return false
+	}
+	return true
+}
+
+// qualifierMatches reports whether the qualifier of a requested name names
+// the receiver of e, its package, or its package and receiver
+func qualifierMatches(e ragcode.SymbolEntry, qualifier string) bool {
+	qualifier = strings.TrimPrefix(qualifier, "*")
+	if e.Receiver == "" {
+		return packageMatches(e.Package, qualifier)
+	}
+	if strings.EqualFold(e.Receiver, qualifier) {
+		return true
+	}
+	for _, sep := range []string{"::", `\`, "."} {
+		if i := strings.LastIndex(qualifier, sep); i > 0 && strings.EqualFold(e.Receiver, strings.TrimPrefix(qualifier[i+len(sep):], "*")) {
+			return packageMatches(e.Package, qualifier[:i])
+		}
+	}
+	return false
+}
+
+// packageMatches compares the package of an entry with a requested one, by
+// full path or trailing segment
+func packageMatches(pkg, want string) bool {
+	want = strings.Trim(want, `/\`)
+	return want != "" && (pkg == want || strings.HasSuffix(pkg, "/"+want) || strings.HasSuffix(pkg, `\`+want))
+}
+
+func symbolDescriptorFromEntry(e ragcode.SymbolEntry) codetypes.SymbolDescriptor {
+	desc := codetypes.SymbolDescriptor{
+		Language:  e.Language,
+		Kind:      e.Kind,
+		Name:      e.Name,
+		Namespace: e.Package,
+		Package:   e.Package,
+		Signature: e.Signature,
+		Location: codetypes.SymbolLocation{
+			FilePath:  e.FilePath,
+			StartLine: e.StartLine,
+			EndLine:   e.EndLine,
+		},
+		Metadata: make(map[string]any),
+	}
+	if e.Receiver != "" {
+		desc.Metadata["receiver"] = e.Receiver
+	}
+	if e.Deprecated {
+		desc.Tags = append(desc.Tags, "deprecated")
+		if e.DeprecationNote != "" {
+			desc.Metadata["deprecation_note"] = e.DeprecationNote
+		}
+	}
+	return desc
+}
+
+func formatSymbolResults(results []SymbolResult, fences string) string {
+	var sb strings.Builder
+	found := 0
+	for _, r := range results {
+		if r.Error == "" {
+			found++
+		}
+	}
+	sb.WriteString(fmt.Sprintf("# 📦 %d/%d symbol(s) found\n\n", found, len(results)))
+	for _, r := range results {
+		if r.Error != "" {
+			sb.WriteString(fmt.Sprintf("## ❌ `%s`\n\n%s\n\n", r.Request.Name, r.Error))
+			continue
+		}
+		for _, s := range r.Symbols {
+			name := s.Name
+			if recv, ok := s.Metadata["receiver"].(string); ok {
+				name = recv + "." + name
+			}
+			sb.WriteString(fmt.Sprintf("## `%s` (%s) - `%s:%d-%d`\n\n", name, s.Kind, s.Location.FilePath, s.Location.StartLine, s.Location.EndLine))
+			if s.Signature != "" {
+				sb.WriteString(fmt.Sprintf("`%s`\n\n", s.Signature))
+			}
+			if code, ok := s.Metadata["code"].(string); ok {
+				sb.WriteString(codeBlock(fences, s.Language, s.Location.FilePath, code) + "\n")
+			}
+		}
+		if r.Truncated {
+			sb.WriteString(fmt.Sprintf("_More symbols named `%s` exist - add kind or package to narrow down._\n\n", r.Request.Name))
+		}
+	}
+	return sb.String()
+}
+
+func formatSymbolResultsMinimal(results []SymbolResult) string {
+	var sb strings.Builder
+	for _, r := range results {
+		if r.Error != "" {
+			sb.WriteString(minimalLine("error", r.Request.Name, "", 0, 0, r.Error) + "\n")
+			continue
+		}
+		sb.WriteString(formatMinimalDescriptors(r.Symbols))
+	}
+	return sb.String()
+}
@@ -0,0 +1,92 @@
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/doITmagic/rag-code-mcp/internal/ragcode"
+)
+
+func TestResolveSymbols(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "billing.go")
+	src := "package billing\n\nfunc (b *Billing) Charge() error {\n\treturn nil\n}\n"
+	if err := os.WriteFile(file, []byte(src), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	entries := []ragcode.SymbolEntry{
+		{Name: "Charge", Kind: "method", Package: "example.com/app/billing", Receiver: "Billing", FilePath: file, StartLine: 3, EndLine: 5},
+		{Name: "Charge", Kind: "function", Package: "example.com/app/legacy", FilePath: "legacy.go", StartLine: 1, EndLine: 2, Deprecated: true},
+		{Name: "Invoice", Kind: "type", Package: "example.com/app/billing", FilePath: file, StartLine: 7, EndLine: 9},
+		{Name: "Get", Kind: "function", Package: "net/http", FilePath: "client.go", StartLine: 1, EndLine: 3},
+		{Name: "User", Kind: "class", Package: `App\Models`, FilePath: "User.php", StartLine: 5, EndLine: 30},
+		{Name: "find", Kind: "method", Package: `App\Models`, Receiver: "User", FilePath: "User.php", StartLine: 10, EndLine: 14},
+	}
+
+	requests, err := parseSymbolRequests([]interface{}{
+		map[string]interface{}{"name": "Charge", "package": "billing"},
+		"Billing.Charge",
+		map[string]interface{}{"name": "Charge", "kind": "function"},
+		map[string]interface{}{"name": "Missing"},
+		map[string]interface{}{"kind": "type"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	results := resolveSymbols(entries, requests, 3, true)
+	if len(results) != 5 {
+		t.Fatalf("len = %d, want 5", len(results))
+	}
+	if r := results[0]; r.Error != "" || len(r.Symbols) != 1 || r.Symbols[0].Kind != "method" {
+		t.Errorf("package filter: %+v", r)
+	}
+	if r := results[1]; len(r.Symbols) != 1 || r.Symbols[0].Metadata["code"] != "func (b *Billing) Charge() error {\n\treturn nil\n}" {
+		t.Errorf("receiver-qualified name: %+v", r)
+	}
+	if r := results[2]; len(r.Symbols) != 1 || len(r.Symbols[0].Tags) != 1 || r.Symbols[0].Tags[0] != "deprecated" {
+		t.Errorf("kind filter: %+v", r)
+	}
+	if r := results[3]; r.Error == "" || len(r.Symbols) != 0 {
+		t.Errorf("missing symbol should report an error: %+v", r)
+	}
+	if r := results[4]; r.Error != "name is required" {
+		t.Errorf("empty name: %+v", r)
+	}
+
+	for _, name := range []string{"http.Get", "net/http.Get"} {
+		if got := resolveSymbols(entries, []SymbolRequest{{Name: name}}, 3, false); len(got[0].Symbols) != 1 || got[0].Symbols[0].Package != "net/http" {
+			t.Errorf("package-qualified %s: %+v", name, got[0])
+		}
+	}
+	if got := resolveSymbols(entries, []SymbolRequest{{Name: "billing.Billing.Charge"}}, 3, false); len(got[0].Symbols) != 1 || got[0].Symbols[0].Kind != "method" {
+		t.Errorf("package and receiver qualified name: %+v", got[0])
+	}
+	if got := resolveSymbols(entries, []SymbolRequest{{Name: "url.Get"}}, 3, false); len(got[0].Symbols) != 0 {
+		t.Errorf("wrong package qualifier matched: %+v", got[0])
+	}
+	for _, name := range []string{`App\Models\User`, `\App\Models\User`, `Models\User`} {
+		if got := resolveSymbols(entries, []SymbolRequest{{Name: name}}, 3, false); len(got[0].Symbols) != 1 || got[0].Symbols[0].Kind != "class" {
+			t.Errorf("namespace-qualified %s: %+v", name, got[0])
+		}
+	}
+	if got := resolveSymbols(entries, []SymbolRequest{{Name: `App\Models\User::find`}}, 3, false); len(got[0].Symbols) != 1 || got[0].Symbols[0].Kind != "method" {
+		t.Errorf("namespace and class qualified method: %+v", got[0])
+	}
+	if got := resolveSymbols(entries, []SymbolRequest{{Name: `App\Http\User`}}, 3, false); len(got[0].Symbols) != 0 {
+		t.Errorf("wrong namespace matched: %+v", got[0])
+	}
+
+	if got := resolveSymbols(entries, requests[:1], 1, false); got[0].Truncated {
+		t.Errorf("single match should not be truncated")
+	}
+	if got := resolveSymbols(entries, []SymbolRequest{{Name: "Charge"}}, 1, false); !got[0].Truncated {
+		t.Errorf("two matches with max_matches=1 should be truncated")
+	}
+
+	if _, err := parseSymbolRequests(make([]interface{}, maxBulkSymbols+1)); err == nil {
+		t.Errorf("expected error above %d symbols", maxBulkSymbols)
+	}
+}
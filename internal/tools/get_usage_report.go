@@ -0,0 +1,220 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/doITmagic/rag-code-mcp/internal/llm"
+	"github.com/doITmagic/rag-code-mcp/internal/workspace"
+)
+
+// GetUsageReportTool reports the usage statistics of a workspace: which
+// tools are called, how often they find something, how fast they are and
+// which queries come back empty.
+type GetUsageReportTool struct {
+	workspaceManager *workspace.Manager
+}
+
+// NewGetUsageReportTool creates a new get_usage_report tool
+func NewGetUsageReportTool(wm *workspace.Manager) *GetUsageReportTool {
+	return &GetUsageReportTool{
+		workspaceManager: wm,
+	}
+}
+
+func (t *GetUsageReportTool) Name() string {
+	return "get_usage_report"
+}
+
+func (t *GetUsageReportTool) Description() string {
+	return "Report anonymized usage statistics of the workspace for the last N days (default 7): calls, hit rate, not-found rate, error rate and average latency per tool, the top queries and the most frequent queries that found nothing, plus embedding and chat requests, estimated tokens and cost per provider and per tool, and today's use of the daily provider budgets. Use to find the parts of the index that need quality work. Statistics are collected when queries.usage is enabled."
+}
+
+func (t *GetUsageReportTool) Execute(ctx context.Context, params map[string]interface{}) (string, error) {
+	if t.workspaceManager == nil {
+		return "", fmt.Errorf("workspace manager not configured")
+	}
+	if extractFilePathFromParams(params) == "" {
+		return "", fmt.Errorf("file_path parameter is required for get_usage_report. Please provide a file path from your workspace")
+	}
+	info, err := t.workspaceManager.DetectWorkspace(params)
+	if err != nil {
+		return "", fmt.Errorf("failed to detect workspace: %w", err)
+	}
+
+	days := 7
+	if v, ok := params["days"].(float64); ok && v > 0 {
+		days = int(v)
+	}
+	top := 10
+	if v, ok := params["limit"].(float64); ok && v > 0 {
+		top = int(v)
+	}
+	report, err := workspace.BuildUsageReport(info.Root, days, top, time.Now())
+	if err != nil {
+		return "", fmt.Errorf("failed to load usage statistics: %w", err)
+	}
+	report.Budgets = llm.DefaultMeter().Budgets()
+
+	if outputFormatFrom(params, formatMarkdown) == formatJSON {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return "", fmt.Errorf("failed to marshal get_usage_report results: %w", err)
+		}
+		return string(data), nil
+	}
+	return FormatUsageReport(info.Root, report), nil
+}
+
+// FormatUsageReport renders a usage report as markdown.
+func FormatUsageReport(root string, r *workspace.UsageReport) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("# 📈 Usage report for %s\n\n%s to %s\n\n", root, r.From, r.To))
+	if r.Summary.Calls == 0 {
+		sb.WriteString("No tool calls recorded in this period. Usage statistics are collected when queries.usage is enabled (or USAGE_STATS_ENABLED=true).\n")
+		sb.WriteString(formatBudgets(r.Budgets))
+		return sb.String()
+	}
+	s := r.Summary
+	sb.WriteString(fmt.Sprintf("**%d calls** · hit rate %s · not found %s · errors %s · avg %dms\n\n",
+		s.Calls, percent(s.HitRate), percent(s.NotFoundRate), percent(s.ErrorRate), s.AvgLatencyMs))
+
+	sb.WriteString("## Tools\n\n| Tool | Calls | Hit rate | Not found | Errors | Avg latency | Tokens | Cost |\n|------|------:|------:|------:|------:|------:|------:|------:|\n")
+	for _, tr := range r.Tools {
+		sb.WriteString(fmt.Sprintf("| %s | %d | %s | %s | %s | %dms | %d | %s |\n",
+			tr.Tool, tr.Calls, percent(tr.HitRate), percent(tr.NotFoundRate), percent(tr.ErrorRate), tr.AvgLatencyMs, tr.Tokens, usd(tr.CostUSD)))
+	}
+
+	if len(r.Providers) > 0 {
+		sb.WriteString("\n## Providers\n\nTokens are estimated (about 4 characters each); cost uses llm.budgets prices.\n\n| Provider | Kind | Requests | Tokens | Cost |\n|------|------|------:|------:|------:|\n")
+		for _, p := range r.Providers {
+			sb.WriteString(fmt.Sprintf("| %s | %s | %d | %d | %s |\n", p.Provider, p.Kind, p.Requests, p.Tokens, usd(p.CostUSD)))
+		}
+	}
+	sb.WriteString(formatBudgets(r.Budgets))
+
+	if len(r.Misses) > 0 {
+		sb.WriteString("\n## Queries that found nothing\n\nCandidates for glossary entries, tag rules or missing code in the index.\n\n")
+		for _, q := range r.Misses {
+			sb.WriteString(fmt.Sprintf("- `%s` - %d of %d\n", q.Query, q.NotFound, q.Count))
+		}
+	}
+	if len(r.Queries) > 0 {
+		sb.WriteString("\n## Top queries\n\n")
+		for _, q := range r.Queries {
+			sb.WriteString(fmt.Sprintf("- `%s` - %d\n", q.Query, q.Count))
+		}
+	}
+
+	days := make([]string, 0, len(r.Days))
+	for d := range r.Days {
+		days = append(days, d)
+	}
+	sort.Strings(days)
+	sb.WriteString("\n## Calls per day\n\n")
+	for _, d := range days {
+		sb.WriteString(fmt.Sprintf("- %s: %d\n", d, r.Days[d]))
+	}
+	return sb.String()
+}
+
+// formatBudgets renders today's use of the provider budgets
+func formatBudgets(budgets []llm.BudgetStatus) string {
+	if len(budgets) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	sb.WriteString("\n## Budgets today\n\n")
+	for _, b := range budgets {
+		line := fmt.Sprintf("- %s: %d request(s), %d token(s)", b.Provider, b.Requests, b.Tokens)
+		if b.CostUSD > 0 {
+			line += ", " + usd(b.CostUSD)
+		}
+		var limits []string
+		if b.DailyRequests > 0 {
+			limits = append(limits, fmt.Sprintf("%d requests", b.DailyRequests))
+		}
+		if b.DailyTokens > 0 {
+			limits = append(limits, fmt.Sprintf("%d tokens", b.DailyTokens))
+		}
+		if len(limits) > 0 {
+			line += " of " + strings.Join(limits, " / ")
+		}
+		if b.Exceeded {
+			line += " - ⚠️ exceeded, degraded until tomorrow"
+		}
+		sb.WriteString(line + "\n")
+	}
+	return sb.String()
+}
+
+func usd(cost float64) string {
+	if cost == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("$%.4f", cost)
+}
+
+func percent(rate float64) string {
+	return fmt.Sprintf("%.0f%%", rate*100)
+}
+
+// usageQueryParams are the parameters whose value is recorded as the query
+// of a tool call. Paths and other parameters are never recorded.
+var usageQueryParams = []string{"query", "function_name", "type_name", "symbol_name", "symbol", "pattern"}
+
+// RecordToolCall adds a finished tool call to the usage statistics of its
+// workspace (queries.usage);
+// providers are the embedding and chat requests the call made.
+func RecordToolCall(wm *workspace.Manager, tool string, params map[string]interface{}, result string, runErr error, duration time.Duration, providers []llm.ProviderUsage) {
+	if wm == nil || !wm.UsageEnabled() {
+		return
+	}
+	info, err := wm.DetectWorkspace(params)
+	if err != nil || info == nil {
+		return
+	}
+	ev := workspace.UsageEvent{
+		Time:      time.Now(),
+		Tool:      tool,
+		Outcome:   usageOutcome(result, runErr),
+		Duration:  duration,
+		Providers: providers,
+	}
+	for _, key := range usageQueryParams {
+		if q, ok := params[key].(string); ok && q != "" {
+			ev.Query = q
+			break
+		}
+	}
+	if err := wm.RecordUsage(info, ev); err != nil {
+		log.Printf("⚠️  Failed to record usage statistics: %v", err)
+	}
+}
+
+// usageOutcome classifies a tool result. Tools answer "nothing found" and
+// "not indexed" with a message rather than an error.
+func usageOutcome(result string, err error) string {
+	if err != nil {
+		return workspace.UsageError
+	}
+	text := strings.TrimSpace(result)
+	firstLine, _, _ := strings.Cut(text, "\n")
+	lower := strings.ToLower(firstLine)
+	switch {
+	case strings.HasPrefix(text, "⏳"):
+		return workspace.UsagePending
+	case text == "" || text == "[]" || text == "null" || text == "{}",
+		strings.HasPrefix(text, "❌"),
+		strings.HasPrefix(lower, "no "),
+		strings.Contains(lower, " not found"),
+		strings.Contains(lower, "no matches"):
+		return workspace.UsageNotFound
+	}
+	return workspace.UsageHit
+}
@@ -0,0 +1,30 @@
+package tools
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/doITmagic/rag-code-mcp/internal/workspace"
+)
+
+func TestUsageOutcome(t *testing.T) {
+	tests := []struct {
+		result string
+		err    error
+		want   string
+	}{
+		{result: "# Results\n\n1. func Retry", want: workspace.UsageHit},
+		{result: `[{"name":"Retry"}]`, want: workspace.UsageHit},
+		{result: "No relevant code found.", want: workspace.UsageNotFound},
+		{result: "Function 'Retry' not found in workspace '/srv/api'", want: workspace.UsageNotFound},
+		{result: "[]", want: workspace.UsageNotFound},
+		{result: "❌ No symbols recorded for workspace '/srv/api'.", want: workspace.UsageNotFound},
+		{result: "⏳ Indexing in progress", want: workspace.UsagePending},
+		{err: fmt.Errorf("file_path parameter is required"), want: workspace.UsageError},
+	}
+	for _, tt := range tests {
+		if got := usageOutcome(tt.result, tt.err); got != tt.want {
+			t.Errorf("usageOutcome(%q, %v) = %s, want %s", tt.result, tt.err, got, tt.want)
+		}
+	}
+}
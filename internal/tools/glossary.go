@@ -0,0 +1,48 @@
+package tools
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/doITmagic/rag-code-mcp/internal/workspace"
+)
+
+// applyGlossary expands the query with the workspace glossary (.ragcode.yaml).
+// It returns the query to search with and the entries the query mentions.
+func applyGlossary(wm *workspace.Manager, params map[string]interface{}, query string) (string, []workspace.GlossaryEntry) {
+	if wm == nil {
+		return query, nil
+	}
+	info, err := wm.DetectWorkspace(params)
+	if err != nil || info == nil {
+		return query, nil
+	}
+	glossary, err := wm.Glossary(info)
+	if err != nil || glossary == nil {
+		return query, nil
+	}
+	entries := glossary.Match(query)
+	return workspace.ExpandQuery(query, entries), entries
+}
+
+// formatGlossaryEntries renders the glossary entries mentioned by the query,
+// shown above markdown results so domain terms are explained.
+func formatGlossaryEntries(entries []workspace.GlossaryEntry) string {
+	if len(entries) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	sb.WriteString("📖 Glossary:\n")
+	for _, e := range entries {
+		sb.WriteString(fmt.Sprintf("- **%s**", e.Term))
+		if e.Definition != "" {
+			sb.WriteString(": " + e.Definition)
+		}
+		if len(e.Synonyms) > 0 {
+			sb.WriteString(fmt.Sprintf(" (also: %s)", strings.Join(e.Synonyms, ", ")))
+		}
+		sb.WriteString("\n")
+	}
+	sb.WriteString("\n")
+	return sb.String()
+}
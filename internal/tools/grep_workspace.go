@@ -0,0 +1,184 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/doITmagic/rag-code-mcp/internal/codetypes"
+	"github.com/doITmagic/rag-code-mcp/internal/ragcode"
+	"github.com/doITmagic/rag-code-mcp/internal/workspace"
+)
+
+// GrepWorkspaceTool finds exact text or regex matches in the workspace files,
+// for the searches embeddings are bad at: identifiers, error strings, config
+// keys. It reads the files directly and needs no index.
+type GrepWorkspaceTool struct {
+	workspaceManager *workspace.Manager
+}
+
+// NewGrepWorkspaceTool creates a new grep_workspace tool
+func NewGrepWorkspaceTool(wm *workspace.Manager) *GrepWorkspaceTool {
+	return &GrepWorkspaceTool{
+		workspaceManager: wm,
+	}
+}
+
+func (t *GrepWorkspaceTool) Name() string {
+	return "grep_workspace"
+}
+
+func (t *GrepWorkspaceTool) Description() string {
+	return "Exact text or regex search over the workspace files (like ripgrep) - use for literal identifiers, error messages, config keys or TODOs, where search_code's semantic matching is too fuzzy. Returns file, line, column and the enclosing function or class for each match, in the same result format as search_code. Skips .gitignore'd paths, vendor/node_modules and binary files. Works for any file type, no index needed."
+}
+
+func (t *GrepWorkspaceTool) Execute(ctx context.Context, params map[string]interface{}) (string, error) {
+	pattern, _ := params["pattern"].(string)
+	if pattern == "" {
+		return "", fmt.Errorf("pattern parameter is required")
+	}
+	if t.workspaceManager == nil {
+		return "", fmt.Errorf("workspace manager not configured")
+	}
+	if extractFilePathFromParams(params) == "" {
+		return "", fmt.Errorf("file_path parameter is required for grep_workspace. Please provide a file path from your workspace")
+	}
+	info, err := t.workspaceManager.DetectWorkspace(params)
+	if err != nil {
+		return "", fmt.Errorf("failed to detect workspace: %w", err)
+	}
+
+	opts := workspace.GrepOptions{Pattern: pattern, MaxMatches: 50}
+	opts.Regex, _ = params["regex"].(bool)
+	opts.CaseSensitive, _ = params["case_sensitive"].(bool)
+	if l, ok := params["limit"].(float64); ok && l > 0 {
+		opts.MaxMatches = int(l)
+	} else if l, ok := params["limit"].(int); ok && l > 0 {
+		opts.MaxMatches = l
+	}
+	if c, ok := params["context"].(float64); ok && c > 0 {
+		opts.Context = int(c)
+	}
+	switch include := params["include"].(type) {
+	case string:
+		for _, g := range strings.Split(include, ",") {
+			if g = strings.TrimSpace(g); g != "" {
+				opts.Include = append(opts.Include, g)
+			}
+		}
+	case []interface{}:
+		for _, g := range include {
+			if s, ok := g.(string); ok && s != "" {
+				opts.Include = append(opts.Include, s)
+			}
+		}
+	}
+
+	matches, truncated, err := workspace.Grep(ctx, info.Root, opts)
+	if err != nil {
+		return "", err
+	}
+
+	var symbols []ragcode.SymbolEntry
+	if table, err := t.workspaceManager.Symbols(info); err == nil {
+		symbols = table.All()
+	}
+	descriptors := grepDescriptors(matches, symbols)
+
+	switch outputFormatFrom(params, formatJSON) {
+	case formatMinimal:
+		if len(descriptors) == 0 {
+			return "No matches.", nil
+		}
+		return formatMinimalDescriptors(descriptors), nil
+	case formatMarkdown:
+		return formatGrepMatches(pattern, info.Root, matches, descriptors, truncated), nil
+	}
+	data, err := json.MarshalIndent(descriptors, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal grep_workspace results: %w", err)
+	}
+	return string(data), nil
+}
+
+// grepDescriptors maps matches to the search result schema. The enclosing
+// symbol, when the symbol table knows it, gives the match a name and kind.
+func grepDescriptors(matches []workspace.GrepMatch, symbols []ragcode.SymbolEntry) []codetypes.SymbolDescriptor {
+	byFile := make(map[string][]ragcode.SymbolEntry)
+	for _, s := range symbols {
+		byFile[s.FilePath] = append(byFile[s.FilePath], s)
+	}
+
+	out := make([]codetypes.SymbolDescriptor, 0, len(matches))
+	for _, m := range matches {
+		desc := codetypes.SymbolDescriptor{
+			Language:    inferLanguageFromPath(m.Path),
+			Kind:        "match",
+			Description: strings.TrimSpace(m.Text),
+			Location: codetypes.SymbolLocation{
+				FilePath:  m.Path,
+				StartLine: m.Line,
+				EndLine:   m.Line,
+			},
+			Metadata: map[string]any{
+				"column": m.Column,
+				"line":   m.Text,
+			},
+		}
+		if len(m.Before) > 0 || len(m.After) > 0 {
+			desc.Metadata["context"] = strings.Join(append(append(append([]string{}, m.Before...), m.Text), m.After...), "\n")
+		}
+		if s, ok := enclosingSymbol(byFile[m.Path], m.Line); ok {
+			desc.Name = s.Name
+			desc.Package = s.Package
+			desc.Namespace = s.Package
+			desc.Metadata["symbol_kind"] = s.Kind
+			desc.Metadata["symbol_lines"] = fmt.Sprintf("%d-%d", s.StartLine, s.EndLine)
+		}
+		out = append(out, desc)
+	}
+	return out
+}
+
+// enclosingSymbol returns the innermost symbol spanning line.
+func enclosingSymbol(symbols []ragcode.SymbolEntry, line int) (ragcode.SymbolEntry, bool) {
+	var best ragcode.SymbolEntry
+	found := false
+	for _, s := range symbols {
+		if s.StartLine <= line && line <= s.EndLine && (!found || s.EndLine-s.StartLine < best.EndLine-best.StartLine) {
+			best, found = s, true
+		}
+	}
+	return best, found
+}
+
+func formatGrepMatches(pattern, root string, matches []workspace.GrepMatch, descs []codetypes.SymbolDescriptor, truncated bool) string {
+	if len(matches) == 0 {
+		return fmt.Sprintf("No matches for `%s` in workspace '%s'.", pattern, root)
+	}
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("🔎 %d match(es) for `%s` in workspace '%s':\n", len(matches), pattern, root))
+	file := ""
+	for i, m := range matches {
+		if m.Path != file {
+			file = m.Path
+			sb.WriteString(fmt.Sprintf("\n%s\n", file))
+		}
+		in := ""
+		if descs[i].Name != "" {
+			in = fmt.Sprintf(" (in %s)", descs[i].Name)
+		}
+		for j, l := range m.Before {
+			sb.WriteString(fmt.Sprintf("  %d- %s\n", m.Line-len(m.Before)+j, l))
+		}
+		sb.WriteString(fmt.Sprintf("  %d:%d: %s%s\n", m.Line, m.Column, m.Text, in))
+		for j, l := range m.After {
+			sb.WriteString(fmt.Sprintf("  %d- %s\n", m.Line+1+j, l))
+		}
+	}
+	if truncated {
+		sb.WriteString("\n⚠️ Stopped at the match limit - narrow the pattern or use include, or raise limit.\n")
+	}
+	return sb.String()
+}
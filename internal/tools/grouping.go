@@ -0,0 +1,187 @@
+package tools
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/doITmagic/rag-code-mcp/internal/codetypes"
+	"github.com/doITmagic/rag-code-mcp/internal/memory"
+)
+
+// Values of the group_by parameter of search_code and hybrid_search
+const (
+	groupNone    = "none"
+	groupFile    = "file"
+	groupPackage = "package"
+)
+
+// autoGroupMin is the number of results one file must hold, and at least half
+// of them, for markdown and minimal output to be grouped without group_by
+const autoGroupMin = 3
+
+// resultGroup is the results sharing a file or package, best match first
+type resultGroup struct {
+	Key  string
+	Docs []memory.Document
+}
+
+// groupByFrom returns the group_by parameter, empty when it is not set
+func groupByFrom(params map[string]interface{}) (string, error) {
+	by, _ := params["group_by"].(string)
+	switch by = strings.ToLower(by); by {
+	case "", groupNone, groupFile, groupPackage:
+		return by, nil
+	}
+	return "", fmt.Errorf("group_by must be 'file', 'package' or 'none', got %q", by)
+}
+
+// grouping returns how to group results: by as requested, otherwise by file
+// when the text output of a broad query clusters in one file. JSON output
+// keeps its flat shape unless group_by asks otherwise.
+func grouping(by, outputFormat string, docs []memory.Document) string {
+	if by != "" {
+		return by
+	}
+	if outputFormat == formatJSON {
+		return groupNone
+	}
+	largest := 0
+	for _, g := range groupResults(docs, groupFile) {
+		largest = max(largest, len(g.Docs))
+	}
+	if largest >= autoGroupMin && 2*largest >= len(docs) {
+		return groupFile
+	}
+	return groupNone
+}
+
+// groupResults groups docs by file or package. Groups are ordered by their
+// best match, and each keeps the ranking of its results.
+func groupResults(docs []memory.Document, by string) []resultGroup {
+	var groups []resultGroup
+	index := make(map[string]int)
+	for _, doc := range docs {
+		key := groupKey(doc, by)
+		i, ok := index[key]
+		if !ok {
+			i = len(groups)
+			index[key] = i
+			groups = append(groups, resultGroup{Key: key})
+		}
+		groups[i].Docs = append(groups[i].Docs, doc)
+	}
+	return groups
+}
+
+// groupKey returns the file of a result, or its package (the directory of
+// the file for languages without one)
+func groupKey(doc memory.Document, by string) string {
+	var chunk codetypes.CodeChunk
+	if err := json.Unmarshal([]byte(doc.Content), &chunk); err != nil {
+		path, _ := doc.Metadata["file_path"].(string)
+		chunk.FilePath = path
+	}
+	if by == groupPackage {
+		if chunk.Package != "" {
+			return chunk.Package
+		}
+		if chunk.FilePath != "" {
+			return filepath.Dir(chunk.FilePath)
+		}
+	}
+	return chunk.FilePath
+}
+
+// groupLabel names a group in text output, with file paths relative to root
+func groupLabel(key, root string) string {
+	if key == "" {
+		return "(unknown)"
+	}
+	if root != "" && filepath.IsAbs(key) {
+		if rel, err := filepath.Rel(root, key); err == nil && !strings.HasPrefix(rel, "..") {
+			return rel
+		}
+	}
+	return key
+}
+
+// groupedResults is the JSON output of grouped results
+type groupedResults struct {
+	GroupBy string        `json:"group_by"`
+	Groups  []groupResult `json:"groups"`
+}
+
+type groupResult struct {
+	Key     string                       `json:"key"`
+	Count   int                          `json:"count"`
+	Results []codetypes.SymbolDescriptor `json:"results"`
+}
+
+// formatGrouped renders grouped results. In markdown the best match of each
+// group is rendered in full by entry, and the other matches as one line each
+// with the chunk_id that expands them through get_chunk.
+func formatGrouped(docs []memory.Document, by, outputFormat, root string, entry func(n int, doc memory.Document) string) (string, error) {
+	groups := groupResults(docs, by)
+	switch outputFormat {
+	case formatMinimal:
+		var sb strings.Builder
+		for _, g := range groups {
+			sb.WriteString(fmt.Sprintf("%s (%d)\n", groupLabel(g.Key, root), len(g.Docs)))
+			for _, d := range buildSymbolDescriptorsFromDocs(g.Docs) {
+				sb.WriteString("  " + minimalLine(d.Kind, d.Name, d.Location.FilePath, d.Location.StartLine, d.Location.EndLine, descriptorSummary(d)) + "\n")
+			}
+		}
+		return sb.String(), nil
+	case formatMarkdown:
+		var sb strings.Builder
+		n := 0
+		for _, g := range groups {
+			sb.WriteString(fmt.Sprintf("=== %s: %d %s ===\n\n", groupLabel(g.Key, root), len(g.Docs), plural(len(g.Docs), "match", "matches")))
+			descs := buildSymbolDescriptorsFromDocs(g.Docs)
+			for i, doc := range g.Docs {
+				n++
+				if i == 0 {
+					sb.WriteString(entry(n, doc))
+					continue
+				}
+				d := descs[i]
+				sb.WriteString(fmt.Sprintf("  ↳ Result %d%s: %s\n", n, chunkIDLabel(doc), minimalLine(d.Kind, d.Name, "", 0, 0, "")+lineRange(d.Location)))
+			}
+			if len(g.Docs) > 1 {
+				sb.WriteString("  (call get_chunk with a chunk_id for the code of a sub-match)\n")
+			}
+			sb.WriteString("\n")
+		}
+		return sb.String(), nil
+	}
+
+	out := groupedResults{GroupBy: by, Groups: make([]groupResult, 0, len(groups))}
+	for _, g := range groups {
+		out.Groups = append(out.Groups, groupResult{Key: g.Key, Count: len(g.Docs), Results: buildSymbolDescriptorsFromDocs(g.Docs)})
+	}
+	data, err := json.MarshalIndent(out, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal grouped results: %w", err)
+	}
+	return string(data), nil
+}
+
+// lineRange renders " (lines start-end)" of a location, empty without lines
+func lineRange(loc codetypes.SymbolLocation) string {
+	switch {
+	case loc.StartLine <= 0:
+		return ""
+	case loc.EndLine > loc.StartLine:
+		return fmt.Sprintf(" (lines %d-%d)", loc.StartLine, loc.EndLine)
+	}
+	return fmt.Sprintf(" (line %d)", loc.StartLine)
+}
+
+func plural(n int, one, many string) string {
+	if n == 1 {
+		return one
+	}
+	return many
+}
@@ -0,0 +1,84 @@
+package tools
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/doITmagic/rag-code-mcp/internal/codetypes"
+	"github.com/doITmagic/rag-code-mcp/internal/memory"
+)
+
+func groupingDoc(t *testing.T, id, file, pkg string, line int) memory.Document {
+	t.Helper()
+	data, err := json.Marshal(codetypes.CodeChunk{Name: "F" + id, Type: "function", FilePath: file, Package: pkg, StartLine: line, EndLine: line + 4, Code: "func F" + id + "() {}"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return memory.Document{ID: id, Content: string(data)}
+}
+
+func TestGroupResults(t *testing.T) {
+	docs := []memory.Document{
+		groupingDoc(t, "1", "/ws/a/x.go", "a", 1),
+		groupingDoc(t, "2", "/ws/b/y.go", "b", 1),
+		groupingDoc(t, "3", "/ws/a/x.go", "a", 20),
+		groupingDoc(t, "4", "/ws/a/z.go", "a", 5),
+	}
+
+	groups := groupResults(docs, groupFile)
+	if len(groups) != 3 || groups[0].Key != "/ws/a/x.go" || len(groups[0].Docs) != 2 || groups[0].Docs[1].ID != "3" {
+		t.Fatalf("file groups = %+v", groups)
+	}
+	groups = groupResults(docs, groupPackage)
+	if len(groups) != 2 || groups[0].Key != "a" || len(groups[0].Docs) != 3 {
+		t.Fatalf("package groups = %+v", groups)
+	}
+
+	if by := grouping("", formatMarkdown, docs); by != groupNone {
+		t.Fatalf("2 of 4 results in one file grouped by %q", by)
+	}
+	docs = append(docs, groupingDoc(t, "5", "/ws/a/x.go", "a", 40))
+	if by := grouping("", formatMarkdown, docs); by != groupFile {
+		t.Fatalf("3 of 5 results in one file grouped by %q", by)
+	}
+	if by := grouping("", formatJSON, docs); by != groupNone {
+		t.Fatalf("JSON grouped by %q without group_by", by)
+	}
+	if _, err := groupByFrom(map[string]interface{}{"group_by": "module"}); err == nil {
+		t.Fatal("invalid group_by accepted")
+	}
+}
+
+func TestFormatGrouped(t *testing.T) {
+	docs := []memory.Document{
+		groupingDoc(t, "1", "/ws/a/x.go", "a", 1),
+		groupingDoc(t, "2", "/ws/a/x.go", "a", 20),
+		groupingDoc(t, "3", "/ws/b/y.go", "b", 1),
+	}
+
+	out, err := formatGrouped(docs, groupFile, formatMarkdown, "/ws", func(n int, doc memory.Document) string {
+		return fmt.Sprintf("--- Result %d ---\n", n)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"=== a/x.go: 2 matches ===", "--- Result 1 ---", "↳ Result 2 [chunk_id 2]: function F2 (lines 20-24)", "=== b/y.go: 1 match ===", "--- Result 3 ---"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("markdown output lacks %q:\n%s", want, out)
+		}
+	}
+
+	out, err = formatGrouped(docs, groupPackage, formatJSON, "/ws", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var grouped groupedResults
+	if err := json.Unmarshal([]byte(out), &grouped); err != nil {
+		t.Fatal(err)
+	}
+	if grouped.GroupBy != groupPackage || len(grouped.Groups) != 2 || grouped.Groups[0].Count != 2 || len(grouped.Groups[0].Results) != 2 {
+		t.Fatalf("JSON output = %+v", grouped)
+	}
+}
@@ -3,18 +3,22 @@ package tools
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log"
 	"math"
 	"sort"
 	"strings"
 
+	"github.com/doITmagic/rag-code-mcp/internal/codetypes"
 	"github.com/doITmagic/rag-code-mcp/internal/llm"
 	"github.com/doITmagic/rag-code-mcp/internal/memory"
+	"github.com/doITmagic/rag-code-mcp/internal/ragcode"
 	"github.com/doITmagic/rag-code-mcp/internal/workspace"
 )
 
-// HybridSearchTool combines basic lexical matching with semantic scoring from vector search
-// to get more relevant results when exact matches exist.
+// HybridSearchTool fuses BM25 keyword search over identifiers with vector
+// search, using reciprocal rank fusion, so exact matches rank first.
 type HybridSearchTool struct {
 	memory           memory.LongTermMemory
 	embedder         llm.Provider
@@ -32,6 +36,7 @@ func NewHybridSearchTool(mem memory.LongTermMemory, embedder llm.Provider) *Hybr
 // SetWorkspaceManager sets the workspace manager for workspace-aware searching
 func (t *HybridSearchTool) SetWorkspaceManager(wm *workspace.Manager) {
 	t.workspaceManager = wm
+	wm.RegisterQueryPrimer(t.Name(), queryPrimer(t.execute))
 }
 
 // Name returns the MCP tool name.
@@ -39,37 +44,34 @@ func (t *HybridSearchTool) Name() string { return "hybrid_search" }
 
 // Description provides a description for the tool.
 func (t *HybridSearchTool) Description() string {
-	return "Combined keyword + semantic search - use ONLY when you need EXACT matches (variable names, error messages, specific identifiers). Returns complete source code with file path, line numbers, and metadata. Use search_code FIRST for general exploration; use this when search_code misses exact terms. Supports Go, PHP, Python, HTML."
-}
-
-type hybridScore struct {
-	doc      memory.Document
-	combined float64
-	semantic float64
-	lexical  float64
+	return "Combined keyword + semantic search - use ONLY when you need EXACT matches (variable names, error messages, specific identifiers). BM25 keyword results are fused with semantic results; symbols named exactly like a query term come first. Returns complete source code with file path, line numbers, and metadata. Use search_code FIRST for general exploration; use this when search_code misses exact terms. Supports Go, PHP, Python, HTML."
 }
 
 // Execute runs the hybrid search.
 func (t *HybridSearchTool) Execute(ctx context.Context, params map[string]interface{}) (string, error) {
+	return cachedSearch(ctx, t.workspaceManager, t.Name(), params, func(ctx context.Context) (string, error) {
+		return t.execute(ctx, params)
+	})
+}
+
+func (t *HybridSearchTool) execute(ctx context.Context, params map[string]interface{}) (string, error) {
+	if cursor := cursorFrom(params); cursor != "" {
+		return continueSearch(ctx, t.workspaceManager, t.Name(), params, cursor)
+	}
 	query, ok := params["query"].(string)
 	if !ok || strings.TrimSpace(query) == "" {
 		return "", fmt.Errorf("query parameter is required")
 	}
+	query, resolved := applyConversationHint(params, query)
+	query, glossary := applyGlossary(t.workspaceManager, params, query)
 
-	limit := 5
-	if v, ok := params["limit"].(float64); ok {
-		limit = int(v)
-	} else if v, ok := params["limit"].(int); ok {
-		limit = v
-	}
-	if limit <= 0 {
-		limit = 5
-	}
+	budget := budgetFor(ctx, t.workspaceManager, params)
+	limit := budget.limit(params)
 
-	// Optional output format: json (default) or markdown
-	outputFormat := "json"
-	if of, ok := params["output_format"].(string); ok && of != "" {
-		outputFormat = strings.ToLower(of)
+	outputFormat := outputFormatFrom(params, formatJSON)
+	groupBy, err := groupByFrom(params)
+	if err != nil {
+		return "", err
 	}
 
 	// file_path is required for workspace detection
@@ -78,18 +80,26 @@ func (t *HybridSearchTool) Execute(ctx context.Context, params map[string]interf
 		return "", fmt.Errorf("file_path parameter is required for hybrid_search. Please provide a file path from your workspace")
 	}
 
+	coverageOpts := parseCoverageOptions(params)
+	tags := parseListParam(params["tags"])
+	build := parseBuildFilter(params)
+
 	// Try workspace detection
 	var workspaceMem memory.LongTermMemory
+	var workspaceInfo *workspace.Info
+	var coverage *ragcode.CoverageReport
 	var workspacePath string
 	var collectionName string
+	var language string
 
 	if t.workspaceManager != nil {
-		workspaceInfo, err := t.workspaceManager.DetectWorkspace(params)
-		if err == nil && workspaceInfo != nil {
+		info, err := t.workspaceManager.DetectWorkspace(params)
+		if err == nil && info != nil {
+			workspaceInfo = info
 			workspacePath = workspaceInfo.Root
 
 			// Detect language from file path or use first detected language
-			language := inferLanguageFromPath(filePath)
+			language = inferLanguageFromPath(filePath)
 			if language == "" && len(workspaceInfo.Languages) > 0 {
 				language = workspaceInfo.Languages[0]
 			}
@@ -120,6 +130,7 @@ func (t *HybridSearchTool) Execute(ctx context.Context, params map[string]interf
 				}
 
 				workspaceMem = mem
+				coverage, _ = t.workspaceManager.Coverage(workspaceInfo)
 			}
 		}
 	}
@@ -135,31 +146,59 @@ func (t *HybridSearchTool) Execute(ctx context.Context, params map[string]interf
 	}
 
 	// 1. Generate embedding for query
-	queryEmbedding, err := t.embedder.Embed(ctx, query)
-	if err != nil {
+	// Over the daily embedding budget, the search degrades to keywords only
+	// Past the search deadline too, whatever the keyword index finds is returned
+	queryEmbedding, err := embedQuery(ctx, t.workspaceManager, params, t.embedder, query)
+	keywordsOnly := errors.Is(err, llm.ErrBudgetExceeded)
+	if deadlineExceeded(ctx, err) {
+		markTimedOut(ctx, outputFormat)
+		keywordsOnly = true
+	}
+	if err != nil && !keywordsOnly {
 		return "", fmt.Errorf("failed to generate query embedding: %w", err)
 	}
 
 	// 2. Gather semantic candidates (more than the limit to allow lexical filtering)
-	// Prefer SearchCodeOnly to exclude markdown documentation
-	type CodeSearcher interface {
-		SearchCodeOnly(ctx context.Context, query []float64, limit int) ([]memory.Document, error)
-	}
-
+	// Exclude markdown documentation
 	fetchLimit := int(math.Max(float64(limit*5), 10))
+	// Pages come from one saved result set, only for workspace searches
+	pageSize := 0
+	if workspaceMem != nil {
+		pageSize = pageSizeFrom(params)
+	}
+	if pageSize > 0 {
+		fetchLimit = maxPagedResults
+	}
+	if len(tags) > 0 || build.active() {
+		fetchLimit *= 4
+	}
 	var docs []memory.Document
-	if codeSearcher, ok := searchMemory.(CodeSearcher); ok {
-		docs, err = codeSearcher.SearchCodeOnly(ctx, queryEmbedding, fetchLimit)
+	if keywordsOnly {
+		log.Printf("⚠️  hybrid_search: %v, using keyword results only", err)
 	} else {
-		docs, err = searchMemory.Search(ctx, queryEmbedding, fetchLimit)
-	}
-	if err != nil {
-		return "", fmt.Errorf("search failed: %w", err)
+		docs, err = searchMemory.Query(ctx, memory.SearchOptions{Vector: queryEmbedding, Filter: memory.CodeOnly(), Limit: fetchLimit})
+		if deadlineExceeded(ctx, err) {
+			log.Printf("⚠️  hybrid_search: semantic search timed out, using keyword results only")
+			markTimedOut(ctx, outputFormat)
+			docs, err = nil, nil
+		}
+		if err != nil {
+			return "", fmt.Errorf("search failed: %w", err)
+		}
+		if workspaceMem != nil {
+			docs = t.workspaceManager.ApplyOverlays(workspaceInfo, ClientSession(ctx), language, queryEmbedding, docs)
+		}
 	}
 
+	// 3. Gather keyword candidates: BM25 over the identifiers of the collection
+	hits := t.keywordCandidates(ctx, workspaceInfo, language, workspaceMem, searchMemory, query, fetchLimit)
+
+	// 4. Fuse both rankings
+	docs = fuseRankings(docs, hits)
+
 	if len(docs) == 0 {
 		// Check if this is a workspace search with empty collection
-		if workspaceMem != nil && collectionName != "" {
+		if workspaceMem != nil && collectionName != "" && searchTimedOut(ctx) == nil {
 			if msg, err := CheckSearchResults(0, collectionName, workspacePath); err != nil || msg != "" {
 				if err != nil {
 					return "", err
@@ -168,7 +207,7 @@ func (t *HybridSearchTool) Execute(ctx context.Context, params map[string]interf
 			}
 		}
 
-		if outputFormat == "markdown" {
+		if outputFormat != formatJSON {
 			if workspaceMem != nil {
 				return fmt.Sprintf("No relevant code found in workspace '%s'.", workspacePath), nil
 			}
@@ -177,36 +216,39 @@ func (t *HybridSearchTool) Execute(ctx context.Context, params map[string]interf
 		return "[]", nil
 	}
 
-	lowerQuery := strings.ToLower(query)
-	tokens := filterTokens(strings.Fields(lowerQuery))
-
-	maxLexical := 0.0
-	matches := make([]hybridScore, 0, len(docs))
-
-	for _, doc := range docs {
-		content := strings.ToLower(fmt.Sprintf("%v", doc.Content))
-		lexicalScore := lexicalMatchScore(content, tokens)
-		semanticScore := 0.0
-		if sc, ok := doc.Metadata["score"].(float64); ok {
-			semanticScore = sc
+	if docs = filterByTags(docs, tags); len(docs) == 0 {
+		if outputFormat != formatJSON {
+			return fmt.Sprintf("No relevant code tagged '%s'.", strings.Join(tags, ", ")), nil
 		}
-
-		if lexicalScore > 0 {
-			if lexicalScore > maxLexical {
-				maxLexical = lexicalScore
-			}
-			matches = append(matches, hybridScore{doc: doc, semantic: semanticScore, lexical: lexicalScore})
+		return "[]", nil
+	}
+	if docs = filterByBuild(docs, build); len(docs) == 0 {
+		if outputFormat != formatJSON {
+			return fmt.Sprintf("No relevant code built for %s.", build), nil
 		}
+		return "[]", nil
 	}
 
-	// If no lexical matches, fall back to top semantic results
-	if len(matches) == 0 {
-		topSemantic := docs
+	ranker := rankerFor(t.workspaceManager).withParams(params).near(workspacePath, filePath)
+
+	// If no keyword matches, fall back to top semantic results
+	if len(hits) == 0 {
+		topSemantic := applyCoverage(ranker.rankDocs(query, docs), coverage, coverageOpts)
+		if pageSize > 0 {
+			page := firstPage(ctx, t.workspaceManager, workspaceInfo, t.Name(), topSemantic, pageSize)
+			return formatPage(page, t.Name(), outputFormat, workspacePath, formatConversationTerms(resolved)+formatGlossaryEntries(glossary))
+		}
 		if len(topSemantic) > limit {
 			topSemantic = topSemantic[:limit]
 		}
+		if by := grouping(groupBy, outputFormat, topSemantic); by != groupNone {
+			return formatGroupedHybrid(topSemantic, by, outputFormat, false, workspacePath, formatConversationTerms(resolved)+formatGlossaryEntries(glossary))
+		}
+		if outputFormat == formatMinimal {
+			return formatMinimalDescriptors(buildSymbolDescriptorsFromDocs(topSemantic)), nil
+		}
 		if outputFormat == "markdown" {
-			return formatHybridResults(topSemantic, false, workspaceMem != nil, workspacePath), nil
+			return formatConversationTerms(resolved) + formatGlossaryEntries(glossary) + formatHybridResults(topSemantic, false, workspaceMem != nil, workspacePath), nil
 		}
 		descriptors := buildSymbolDescriptorsFromDocs(topSemantic)
 		data, err := json.MarshalIndent(descriptors, "", "  ")
@@ -216,37 +258,31 @@ func (t *HybridSearchTool) Execute(ctx context.Context, params map[string]interf
 		return string(data), nil
 	}
 
-	// Combine scores (60% semantic + 40% normalized lexical)
-	for i := range matches {
-		lexicalNorm := 0.0
-		if maxLexical > 0 {
-			lexicalNorm = matches[i].lexical / maxLexical
-		}
-		matches[i].combined = 0.6*matches[i].semantic + 0.4*lexicalNorm
+	finalDocs := make([]memory.Document, 0, len(docs))
+	for _, res := range exactNamesFirst(query, ranker.rankBy(query, docs, fusedRelevance)) {
+		// Attach combined scores for transparency
+		res.doc.Metadata["hybrid_score"] = res.score
+		finalDocs = append(finalDocs, res.doc)
 	}
 
-	sort.Slice(matches, func(i, j int) bool {
-		return matches[i].combined > matches[j].combined
-	})
-
-	if len(matches) > limit {
-		matches = matches[:limit]
+	finalDocs = applyCoverage(finalDocs, coverage, coverageOpts)
+	if pageSize > 0 {
+		page := firstPage(ctx, t.workspaceManager, workspaceInfo, t.Name(), finalDocs, pageSize)
+		return formatPage(page, t.Name(), outputFormat, workspacePath, formatConversationTerms(resolved)+formatGlossaryEntries(glossary))
 	}
-
-	finalDocs := make([]memory.Document, 0, len(matches))
-	for _, res := range matches {
-		// Attach combined scores for transparency
-		if res.doc.Metadata == nil {
-			res.doc.Metadata = make(map[string]interface{})
-		}
-		res.doc.Metadata["hybrid_score"] = res.combined
-		res.doc.Metadata["semantic_score"] = res.semantic
-		res.doc.Metadata["lexical_score"] = res.lexical
-		finalDocs = append(finalDocs, res.doc)
+	if len(finalDocs) > limit {
+		finalDocs = finalDocs[:limit]
 	}
+	finalDocs = budget.clip(finalDocs)
 
+	if by := grouping(groupBy, outputFormat, finalDocs); by != groupNone {
+		return formatGroupedHybrid(finalDocs, by, outputFormat, true, workspacePath, formatConversationTerms(resolved)+formatGlossaryEntries(glossary))
+	}
+	if outputFormat == formatMinimal {
+		return formatMinimalDescriptors(buildSymbolDescriptorsFromDocs(finalDocs)), nil
+	}
 	if outputFormat == "markdown" {
-		return formatHybridResults(finalDocs, true, workspaceMem != nil, workspacePath), nil
+		return formatConversationTerms(resolved) + formatGlossaryEntries(glossary) + formatHybridResults(finalDocs, true, workspaceMem != nil, workspacePath), nil
 	}
 
 	descriptors := buildSymbolDescriptorsFromDocs(finalDocs)
@@ -257,6 +293,129 @@ func (t *HybridSearchTool) Execute(ctx context.Context, params map[string]interf
 	return string(data), nil
 }
 
+// keywordCandidates searches the keyword index of the searched collection.
+// Workspace collections keep their index between queries; the default memory
+// is indexed for each query. Chunks of files shadowed by unsaved buffers are
+// left out. Failures only cost the keyword half of the search.
+func (t *HybridSearchTool) keywordCandidates(ctx context.Context, info *workspace.Info, language string, workspaceMem, searchMemory memory.LongTermMemory, query string, limit int) []workspace.KeywordHit {
+	var index *workspace.KeywordIndex
+	var err error
+	if workspaceMem != nil {
+		index, err = t.workspaceManager.KeywordIndex(ctx, info, language, workspaceMem)
+	} else {
+		index, err = workspace.BuildKeywordIndex(ctx, searchMemory)
+	}
+	if err != nil {
+		log.Printf("⚠️  hybrid_search: keyword index unavailable, using semantic results only: %v", err)
+		return nil
+	}
+	hits := index.Search(query, limit)
+	if workspaceMem == nil {
+		return hits
+	}
+	overlays := t.workspaceManager.Overlays(info, ClientSession(ctx), language)
+	if len(overlays) == 0 {
+		return hits
+	}
+	shadowed := make(map[string]bool, len(overlays))
+	for _, o := range overlays {
+		shadowed[o.Path] = true
+	}
+	kept := hits[:0]
+	for _, hit := range hits {
+		if file, _ := hit.Doc.Metadata["file"].(string); !shadowed[file] {
+			kept = append(kept, hit)
+		}
+	}
+	return kept
+}
+
+// rrfK damps the difference between top ranks in reciprocal rank fusion; 60
+// is the constant of the original paper
+const rrfK = 60
+
+// fuseRankings merges semantic results (best first) and keyword hits with
+// reciprocal rank fusion: a document scores the sum of 1/(rrfK+rank) over
+// the lists it appears in. Documents are annotated with "rrf_score",
+// "semantic_score" and "lexical_score" (BM25); "score" stays the vector
+// similarity and is absent for keyword-only hits. Ties keep semantic order,
+// then keyword order, so the fusion is deterministic.
+func fuseRankings(semantic []memory.Document, hits []workspace.KeywordHit) []memory.Document {
+	fused := make([]memory.Document, 0, len(semantic)+len(hits))
+	rrf := make(map[string]float64, cap(fused))
+	index := make(map[string]int, cap(fused))
+	add := func(doc memory.Document, rank int) int {
+		if i, ok := index[doc.ID]; ok && doc.ID != "" {
+			rrf[doc.ID] += 1 / float64(rrfK+rank+1)
+			return i
+		}
+		// Copy the metadata: keyword hits are shared by the cached index
+		meta := make(map[string]interface{}, len(doc.Metadata)+4)
+		for k, v := range doc.Metadata {
+			meta[k] = v
+		}
+		doc.Metadata = meta
+		index[doc.ID] = len(fused)
+		rrf[doc.ID] += 1 / float64(rrfK+rank+1)
+		fused = append(fused, doc)
+		return len(fused) - 1
+	}
+	for rank, doc := range semantic {
+		i := add(doc, rank)
+		fused[i].Metadata["semantic_score"] = getFloat(doc.Metadata["score"])
+		fused[i].Metadata["lexical_score"] = 0.0
+	}
+	for rank, hit := range hits {
+		i := add(hit.Doc, rank)
+		meta := fused[i].Metadata
+		if _, ok := meta["semantic_score"]; !ok {
+			delete(meta, "score")
+			meta["semantic_score"] = 0.0
+		}
+		meta["lexical_score"] = hit.Score
+	}
+	for i := range fused {
+		fused[i].Metadata["rrf_score"] = rrf[fused[i].ID]
+	}
+	sort.SliceStable(fused, func(i, j int) bool {
+		return rrf[fused[i].ID] > rrf[fused[j].ID]
+	})
+	return fused
+}
+
+// fusedRelevance is the RRF score of a fused result relative to the best
+// possible one, first in both lists
+func fusedRelevance(i int, docs []memory.Document) float64 {
+	return getFloat(docs[i].Metadata["rrf_score"]) * float64(rrfK+1) / 2
+}
+
+// exactNamesFirst moves the results whose symbol is named in query to the
+// front, keeping the order within both groups, so searching for an exact
+// identifier such as NewQdrantClient returns its definition first.
+func exactNamesFirst(query string, ranked []rankedDoc) []rankedDoc {
+	names := queryNames(query)
+	out := make([]rankedDoc, 0, len(ranked))
+	var rest []rankedDoc
+	for _, res := range ranked {
+		if name := chunkName(res.doc); name != "" && names[strings.ToLower(name)] {
+			out = append(out, res)
+		} else {
+			rest = append(rest, res)
+		}
+	}
+	return append(out, rest...)
+}
+
+// chunkName returns the symbol name of a result
+func chunkName(doc memory.Document) string {
+	if name, ok := doc.Metadata["name"].(string); ok && name != "" {
+		return name
+	}
+	var chunk codetypes.CodeChunk
+	_ = json.Unmarshal([]byte(doc.Content), &chunk)
+	return chunk.Name
+}
+
 func filterTokens(tokens []string) []string {
 	filtered := make([]string, 0, len(tokens))
 	for _, tok := range tokens {
@@ -293,20 +452,43 @@ func formatHybridResults(docs []memory.Document, includeScores bool, isWorkspace
 		sb.WriteString(fmt.Sprintf("Hybrid search found %d snippet(s):\n\n", len(docs)))
 	}
 	for i, doc := range docs {
-		if includeScores {
-			sb.WriteString(fmt.Sprintf("--- Result %d (hybrid %.4f | semantic %.4f | lexical %.1f) ---\n",
-				i+1,
-				getFloat(doc.Metadata["hybrid_score"]),
-				getFloat(doc.Metadata["semantic_score"]),
-				getFloat(doc.Metadata["lexical_score"])))
-		} else {
-			sb.WriteString(fmt.Sprintf("--- Result %d ---\n", i+1))
-		}
-		sb.WriteString(fmt.Sprintf("%v\n\n", doc.Content))
+		sb.WriteString(hybridResultEntry(i+1, doc, includeScores))
 	}
 	return sb.String()
 }
 
+// hybridResultEntry renders result n of hybrid_search markdown output
+func hybridResultEntry(n int, doc memory.Document, includeScores bool) string {
+	var header string
+	if includeScores {
+		header = fmt.Sprintf("--- Result %d%s (hybrid %.4f | semantic %.4f | bm25 %.2f)%s ---\n",
+			n,
+			chunkIDLabel(doc)+truncatedLabel(doc),
+			getFloat(doc.Metadata["hybrid_score"]),
+			getFloat(doc.Metadata["semantic_score"]),
+			getFloat(doc.Metadata["lexical_score"]),
+			coverageLabel(doc)+tagsLabel(doc)+buildConstraintLabel(doc))
+	} else {
+		header = fmt.Sprintf("--- Result %d%s%s%s%s ---\n", n, chunkIDLabel(doc)+truncatedLabel(doc), coverageLabel(doc), tagsLabel(doc), buildConstraintLabel(doc))
+	}
+	return header + fmt.Sprintf("%v\n\n", doc.Content)
+}
+
+// formatGroupedHybrid renders hybrid_search results grouped by file or
+// package; prefix leads markdown output
+func formatGroupedHybrid(docs []memory.Document, by, outputFormat string, includeScores bool, workspacePath, prefix string) (string, error) {
+	body, err := formatGrouped(docs, by, outputFormat, workspacePath, func(n int, doc memory.Document) string {
+		return hybridResultEntry(n, doc, includeScores)
+	})
+	if err != nil || outputFormat != formatMarkdown {
+		return body, err
+	}
+	if workspacePath != "" {
+		return prefix + fmt.Sprintf("🔍 Hybrid search found %d snippet(s) in workspace '%s', grouped by %s:\n\n", len(docs), workspacePath, by) + body, nil
+	}
+	return prefix + fmt.Sprintf("Hybrid search found %d snippet(s), grouped by %s:\n\n", len(docs), by) + body, nil
+}
+
 func getFloat(val interface{}) float64 {
 	if f, ok := val.(float64); ok {
 		return f
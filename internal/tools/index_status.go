@@ -0,0 +1,192 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/doITmagic/rag-code-mcp/internal/workspace"
+)
+
+// Index states of a workspace language
+const (
+	indexStateIndexing   = "indexing"    // a run is in progress
+	indexStateIndexed    = "indexed"     // every source file is indexed
+	indexStateStale      = "stale"       // files changed since the last run
+	indexStateNotIndexed = "not_indexed" // never indexed
+)
+
+// IndexStatusTool reports how far the indexing of each language of a
+// workspace has got
+type IndexStatusTool struct {
+	workspaceManager *workspace.Manager
+}
+
+// NewIndexStatusTool creates a new index_status tool
+func NewIndexStatusTool(wm *workspace.Manager) *IndexStatusTool {
+	return &IndexStatusTool{
+		workspaceManager: wm,
+	}
+}
+
+func (t *IndexStatusTool) Name() string {
+	return "index_status"
+}
+
+func (t *IndexStatusTool) Description() string {
+	return "Report indexing progress per language of a workspace: files discovered, files indexed, chunks stored, the file being processed, percentage complete and estimated time remaining. Use while index_workspace runs in the background, or to check whether a workspace is up to date."
+}
+
+// IndexStatusReport is the indexing progress of a workspace
+type IndexStatusReport struct {
+	Root      string               `json:"root"`
+	Languages []LanguageIndexState `json:"languages"`
+}
+
+// LanguageIndexState is the indexing progress of one workspace language
+type LanguageIndexState struct {
+	Language        string  `json:"language"`
+	Collection      string  `json:"collection"`
+	Status          string  `json:"status"`
+	FilesDiscovered int     `json:"files_discovered"`
+	FilesIndexed    int     `json:"files_indexed"`
+	ChunksStored    uint64  `json:"chunks_stored"`
+	Phase           string  `json:"phase,omitempty"`
+	CurrentFile     string  `json:"current_file,omitempty"`
+	PercentComplete float64 `json:"percent_complete"`
+	// HiddenFiles changed since the commit the language was indexed at;
+	// searches leave them out until they are re-indexed
+	HiddenFiles int `json:"hidden_files,omitempty"`
+	// EstimatedSecondsRemaining is set while a run is in progress
+	EstimatedSecondsRemaining *float64 `json:"estimated_seconds_remaining,omitempty"`
+}
+
+func (t *IndexStatusTool) Execute(ctx context.Context, params map[string]interface{}) (string, error) {
+	if t.workspaceManager == nil {
+		return "", fmt.Errorf("workspace manager not configured")
+	}
+	if extractFilePathFromParams(params) == "" {
+		return "", fmt.Errorf("file_path parameter is required for index_status. Please provide a file path from your workspace")
+	}
+	info, err := t.workspaceManager.DetectWorkspace(params)
+	if err != nil {
+		return "", fmt.Errorf("failed to detect workspace: %w", err)
+	}
+
+	languages := info.Languages
+	if lang, _ := params["language"].(string); lang != "" {
+		languages = []string{lang}
+	}
+	plan, err := t.workspaceManager.PlanIndexing(info, languages)
+	if err != nil {
+		return "", err
+	}
+
+	report := &IndexStatusReport{Root: info.Root}
+	now := time.Now()
+	for _, lp := range plan.Languages {
+		chunks, err := t.workspaceManager.StoredChunks(ctx, info, lp.Language)
+		if err != nil {
+			return "", fmt.Errorf("failed to count chunks of language '%s': %w", lp.Language, err)
+		}
+		var progress *workspace.IndexProgress
+		if p, ok := t.workspaceManager.IndexProgress(info, lp.Language); ok {
+			progress = &p
+		}
+		state := languageIndexState(lp, progress, chunks, now)
+		state.HiddenFiles = len(t.workspaceManager.StaleFiles(info, lp.Language))
+		report.Languages = append(report.Languages, state)
+	}
+
+	if outputFormatFrom(params, formatMarkdown) == formatJSON {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return "", fmt.Errorf("failed to marshal index_status results: %w", err)
+		}
+		return string(data), nil
+	}
+	return FormatIndexStatus(report), nil
+}
+
+// languageIndexState combines the plan of a language, the progress of its
+// running indexing run if any and the chunks its collection holds. Files the
+// run has stored are not in the workspace state until the run ends, so they
+// are added to the files the plan finds indexed.
+func languageIndexState(lp workspace.LanguagePlan, progress *workspace.IndexProgress, chunks uint64, now time.Time) LanguageIndexState {
+	s := LanguageIndexState{
+		Language:        lp.Language,
+		Collection:      lp.Collection,
+		FilesDiscovered: lp.Files,
+		FilesIndexed:    lp.Files - lp.FilesQueued,
+		ChunksStored:    chunks,
+	}
+	switch {
+	case progress != nil:
+		s.Status = indexStateIndexing
+		s.Phase = progress.Phase
+		s.CurrentFile = progress.CurrentFile
+		s.FilesIndexed = min(s.FilesIndexed+progress.FilesIndexed, lp.Files)
+		s.PercentComplete = progress.Percent()
+		if d, ok := progress.Remaining(now); ok {
+			secs := d.Seconds()
+			s.EstimatedSecondsRemaining = &secs
+		}
+		return s
+	case lp.Indexing:
+		// Started, not yet past change detection
+		s.Status = indexStateIndexing
+	case lp.FilesQueued == 0:
+		s.Status = indexStateIndexed
+	case chunks == 0 && s.FilesIndexed == 0:
+		s.Status = indexStateNotIndexed
+	default:
+		s.Status = indexStateStale
+	}
+	if lp.Files > 0 {
+		s.PercentComplete = 100 * float64(s.FilesIndexed) / float64(lp.Files)
+	} else if s.Status == indexStateIndexed {
+		s.PercentComplete = 100
+	}
+	return s
+}
+
+// FormatIndexStatus renders an index status report as markdown.
+func FormatIndexStatus(r *IndexStatusReport) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("# ⏳ Index status for %s\n\n", r.Root))
+	if len(r.Languages) == 0 {
+		sb.WriteString("No indexable languages found.\n")
+		return sb.String()
+	}
+
+	sb.WriteString("| Language | Status | Files | Indexed | Chunks | Complete | Remaining |\n|----------|--------|------:|------:|------:|------:|------:|\n")
+	for _, s := range r.Languages {
+		status := s.Status
+		if s.Phase != "" {
+			status += " (" + s.Phase + ")"
+		}
+		remaining := "-"
+		if s.EstimatedSecondsRemaining != nil {
+			remaining = "~" + formatEstimate(*s.EstimatedSecondsRemaining)
+		}
+		sb.WriteString(fmt.Sprintf("| %s | %s | %d | %d | %d | %.0f%% | %s |\n",
+			s.Language, status, s.FilesDiscovered, s.FilesIndexed, s.ChunksStored, s.PercentComplete, remaining))
+	}
+
+	stale := false
+	for _, s := range r.Languages {
+		if s.CurrentFile != "" {
+			sb.WriteString(fmt.Sprintf("\n%s: processing `%s`\n", s.Language, s.CurrentFile))
+		}
+		if s.HiddenFiles > 0 {
+			sb.WriteString(fmt.Sprintf("\n%s: %d file(s) changed since the indexed commit are hidden from searches until re-indexed\n", s.Language, s.HiddenFiles))
+		}
+		stale = stale || s.Status == indexStateStale || s.Status == indexStateNotIndexed
+	}
+	if stale {
+		sb.WriteString("\nRun index_workspace to index new and modified files.\n")
+	}
+	return sb.String()
+}
@@ -0,0 +1,57 @@
+package tools
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/doITmagic/rag-code-mcp/internal/workspace"
+)
+
+func TestLanguageIndexState(t *testing.T) {
+	now := time.Now()
+	lp := workspace.LanguagePlan{Language: "go", Collection: "ragcode-ws-go", Files: 40, FilesQueued: 10}
+
+	s := languageIndexState(lp, nil, 120, now)
+	if s.Status != indexStateStale || s.FilesIndexed != 30 || s.PercentComplete != 75 || s.EstimatedSecondsRemaining != nil {
+		t.Errorf("stale language = %+v", s)
+	}
+
+	s = languageIndexState(workspace.LanguagePlan{Language: "go", Files: 40, FilesQueued: 40}, nil, 0, now)
+	if s.Status != indexStateNotIndexed || s.PercentComplete != 0 {
+		t.Errorf("new language = %+v", s)
+	}
+
+	s = languageIndexState(workspace.LanguagePlan{Language: "go", Files: 40}, nil, 300, now)
+	if s.Status != indexStateIndexed || s.PercentComplete != 100 {
+		t.Errorf("indexed language = %+v", s)
+	}
+
+	progress := &workspace.IndexProgress{Phase: workspace.PhaseAnalyzing, FilesQueued: 10, FilesIndexed: 4, CurrentFile: "/ws/a.go"}
+	s = languageIndexState(lp, progress, 120, now)
+	if s.Status != indexStateIndexing || s.FilesIndexed != 34 || s.CurrentFile != "/ws/a.go" || s.Phase != workspace.PhaseAnalyzing {
+		t.Errorf("indexing language = %+v", s)
+	}
+	if s.EstimatedSecondsRemaining == nil || *s.EstimatedSecondsRemaining <= 0 {
+		t.Errorf("indexing language should have an estimate, got %+v", s)
+	}
+}
+
+func TestFormatIndexStatus(t *testing.T) {
+	remaining := 90.0
+	out := FormatIndexStatus(&IndexStatusReport{Root: "/ws", Languages: []LanguageIndexState{
+		{Language: "go", Status: indexStateIndexing, Phase: workspace.PhaseEmbedding, FilesDiscovered: 40, FilesIndexed: 34,
+			ChunksStored: 500, CurrentFile: "/ws/a.go", PercentComplete: 62.5, EstimatedSecondsRemaining: &remaining},
+		{Language: "python", Status: indexStateStale, FilesDiscovered: 5, FilesIndexed: 4, PercentComplete: 80},
+	}})
+	for _, want := range []string{
+		"| go | indexing (embedding) | 40 | 34 | 500 | 62% | ~2m |",
+		"| python | stale | 5 | 4 | 0 | 80% | - |",
+		"go: processing `/ws/a.go`",
+		"Run index_workspace",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output misses %q:\n%s", want, out)
+		}
+	}
+}
@@ -2,11 +2,12 @@ package tools
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"log"
+	"math"
 	"strings"
 
-	"github.com/doITmagic/rag-code-mcp/internal/memory"
 	"github.com/doITmagic/rag-code-mcp/internal/workspace"
 )
 
@@ -29,7 +30,15 @@ func (t *IndexWorkspaceTool) Name() string {
 
 // Description returns the tool description
 func (t *IndexWorkspaceTool) Description() string {
-	return "Index/reindex the codebase for search - USUALLY AUTOMATIC on first search. Call manually only if search returns 'workspace not indexed' or after major code changes (git pull, branch switch). Analyzes Go, PHP, Python, HTML files and stores vectors for semantic search."
+	return "Index/reindex the codebase for search - USUALLY AUTOMATIC on first search. Call manually only if search returns 'workspace not indexed' or after major code changes (git pull, branch switch) - pass since_ref (a commit, branch, or 'last_indexed') to re-index only the files git reports as changed. Analyzes Go, PHP, Python, HTML files and stores vectors for semantic search."
+}
+
+// IndexWorkspaceSummary is the structured result of index_workspace, so
+// IDE extensions can render indexing progress
+type IndexWorkspaceSummary struct {
+	Status  string `json:"status"` // "started" or "already_indexing"
+	Message string `json:"message"`
+	*workspace.IndexPlan
 }
 
 // Execute indexes the workspace
@@ -37,6 +46,7 @@ func (t *IndexWorkspaceTool) Execute(ctx context.Context, params map[string]inte
 	if t.workspaceManager == nil {
 		return "", fmt.Errorf("workspace manager not configured")
 	}
+	outputFormat := outputFormatFrom(params, formatJSON)
 
 	// Detect workspace from params
 	workspaceInfo, err := t.workspaceManager.DetectWorkspace(params)
@@ -50,6 +60,15 @@ func (t *IndexWorkspaceTool) Execute(ctx context.Context, params map[string]inte
 		language = lang
 	}
 
+	// Optional: only check the files git reports as changed since a ref
+	sinceRef := ""
+	if ref, ok := params["since_ref"].(string); ok && strings.TrimSpace(ref) != "" {
+		sinceRef = strings.TrimSpace(ref)
+		if err := workspace.ValidateSinceRef(ctx, workspaceInfo.Root, sinceRef); err != nil {
+			return "", fmt.Errorf("invalid since_ref: %w", err)
+		}
+	}
+
 	// If no language specified, index all detected languages
 	if language == "" {
 		if len(workspaceInfo.Languages) == 0 {
@@ -63,27 +82,29 @@ func (t *IndexWorkspaceTool) Execute(ctx context.Context, params map[string]inte
 
 	// If still no specific language, index all languages
 	if language == "" {
+		plan, err := t.workspaceManager.PlanIndexingSince(ctx, workspaceInfo, workspaceInfo.Languages, sinceRef)
+		if err != nil {
+			return "", err
+		}
+
 		// Index all detected languages
 		memories, err := t.workspaceManager.GetMemoriesForAllLanguages(ctx, workspaceInfo)
 		if err != nil {
 			return "", fmt.Errorf("failed to initialize indexing for workspace: %w", err)
 		}
-
-		languageList := ""
-		for lang := range memories {
-			if languageList != "" {
-				languageList += ", "
+		if sinceRef != "" {
+			for lang := range memories {
+				if err := t.workspaceManager.StartIndexingSince(ctx, workspaceInfo, lang, sinceRef); err != nil {
+					log.Printf("⚠️  Failed to start indexing of '%s' since %s: %v", lang, sinceRef, err)
+				}
 			}
-			languageList += lang
 		}
 
-		return fmt.Sprintf("✓ Indexing started for workspace '%s'\n"+
-			"Languages: %s\n"+
-			"Collections will be created: %s\n"+
-			"Indexing is running in the background. You can use search_code immediately - results will appear as indexing progresses.",
-			workspaceInfo.Root,
-			languageList,
-			getCollectionNames(workspaceInfo, memories)), nil
+		return formatIndexWorkspaceSummary(IndexWorkspaceSummary{
+			Status:    "started",
+			Message:   fmt.Sprintf("Indexing started for workspace '%s'. It runs in the background; search_code can be used immediately and results appear as indexing progresses.", workspaceInfo.Root),
+			IndexPlan: plan,
+		}, outputFormat)
 	}
 
 	// Index specific language
@@ -93,14 +114,23 @@ func (t *IndexWorkspaceTool) Execute(ctx context.Context, params map[string]inte
 	}
 
 	collectionName := workspaceInfo.CollectionNameForLanguage(language)
+	plan, err := t.workspaceManager.PlanIndexingSince(ctx, workspaceInfo, []string{language}, sinceRef)
+	if err != nil {
+		return "", err
+	}
 
 	// SCENARIO 1: Check if currently indexing
 	indexKey := workspaceInfo.ID + "-" + language
 	if t.workspaceManager.IsIndexing(indexKey) {
-		return fmt.Sprintf("⏳ Workspace '%s' language '%s' is already being indexed in the background.\n"+
-			"Collection: %s\n"+
-			"You can use search_code immediately - results will appear as indexing progresses.",
-			workspaceInfo.Root, language, collectionName), nil
+		progress := ""
+		if p, ok := t.workspaceManager.IndexProgress(workspaceInfo, language); ok && p.ChunksTotal > 0 {
+			progress = fmt.Sprintf(" (%d/%d chunks embedded)", p.ChunksDone, p.ChunksTotal)
+		}
+		return formatIndexWorkspaceSummary(IndexWorkspaceSummary{
+			Status:    "already_indexing",
+			Message:   fmt.Sprintf("Workspace '%s' language '%s' is already being indexed in the background%s. search_code can be used immediately and results appear as indexing progresses.", workspaceInfo.Root, language, progress),
+			IndexPlan: plan,
+		}, outputFormat)
 	}
 
 	// SCENARIO 2 & 3: Check if collection exists and has data
@@ -118,7 +148,7 @@ func (t *IndexWorkspaceTool) Execute(ctx context.Context, params map[string]inte
 
 	// SCENARIO 2: Start indexing (collection doesn't exist or is empty)
 	// Force indexing to start (or restart if stopped)
-	if err := t.workspaceManager.StartIndexing(ctx, workspaceInfo, language); err != nil {
+	if err := t.workspaceManager.StartIndexingSince(ctx, workspaceInfo, language, sinceRef); err != nil {
 		// If error is "already indexing", that's fine
 		if !t.workspaceManager.IsIndexing(indexKey) {
 			return "", fmt.Errorf("failed to start indexing: %w", err)
@@ -129,32 +159,58 @@ func (t *IndexWorkspaceTool) Execute(ctx context.Context, params map[string]inte
 		workspaceInfo.Root, language, collectionName)
 
 	// Explicitly start indexing using StartIndexing method
-	if err := t.workspaceManager.StartIndexing(ctx, workspaceInfo, language); err != nil {
+	if err := t.workspaceManager.StartIndexingSince(ctx, workspaceInfo, language, sinceRef); err != nil {
 		// If error is "already in progress", that's okay (race condition)
 		if !strings.Contains(err.Error(), "already in progress") {
 			return "", fmt.Errorf("failed to start indexing: %w", err)
 		}
 	}
 
-	return fmt.Sprintf("✓ Indexing started for workspace '%s'\n"+
-		"Language: %s\n"+
-		"Collection: %s\n"+
-		"Memory instance: %T\n"+
-		"Indexing is running in the background. You can use search_code immediately - results will appear as indexing progresses.",
-		workspaceInfo.Root,
-		language,
-		collectionName,
-		mem), nil
+	return formatIndexWorkspaceSummary(IndexWorkspaceSummary{
+		Status:    "started",
+		Message:   fmt.Sprintf("Indexing started for workspace '%s' language '%s'. It runs in the background; search_code can be used immediately and results appear as indexing progresses.", workspaceInfo.Root, language),
+		IndexPlan: plan,
+	}, outputFormat)
 }
 
-// Helper to get collection names from memories map
-func getCollectionNames(info *workspace.Info, memories map[string]memory.LongTermMemory) string {
-	result := ""
-	for lang := range memories {
-		if result != "" {
-			result += ", "
+func formatIndexWorkspaceSummary(summary IndexWorkspaceSummary, outputFormat string) (string, error) {
+	if outputFormat == formatJSON {
+		data, err := json.MarshalIndent(summary, "", "  ")
+		if err != nil {
+			return "", fmt.Errorf("failed to marshal index_workspace summary: %w", err)
 		}
-		result += info.CollectionNameForLanguage(lang)
+		return string(data), nil
+	}
+
+	var sb strings.Builder
+	icon := "✓"
+	if summary.Status == "already_indexing" {
+		icon = "⏳"
+	}
+	sb.WriteString(fmt.Sprintf("%s %s\n\n", icon, summary.Message))
+	if summary.SinceRef != "" {
+		sb.WriteString(fmt.Sprintf("Only files changed since %s are checked.\n\n", summary.SinceRef))
+	}
+	for _, lp := range summary.Languages {
+		sb.WriteString(fmt.Sprintf("- %s: %d file(s), %d queued, collection %s\n", lp.Language, lp.Files, lp.FilesQueued, lp.Collection))
+	}
+	if summary.DocFiles > 0 {
+		sb.WriteString(fmt.Sprintf("- docs: %d markdown file(s)\n", summary.DocFiles))
+	}
+	sb.WriteString(fmt.Sprintf("\nEstimated time: ~%s\n", formatEstimate(summary.EstimatedSeconds)))
+	if len(summary.Skipped) > 0 {
+		sb.WriteString("\nSkipped:\n")
+		for _, sp := range summary.Skipped {
+			sb.WriteString(fmt.Sprintf("- %s (%s)\n", sp.Path, sp.Reason))
+		}
+	}
+	return sb.String(), nil
+}
+
+// formatEstimate renders an estimated duration: "45s", "3m"
+func formatEstimate(seconds float64) string {
+	if seconds < 60 {
+		return fmt.Sprintf("%.0fs", math.Ceil(seconds))
 	}
-	return result
+	return fmt.Sprintf("%.0fm", math.Ceil(seconds/60))
 }
@@ -0,0 +1,240 @@
+package tools
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/doITmagic/rag-code-mcp/internal/ragcode"
+	"github.com/doITmagic/rag-code-mcp/internal/workspace"
+)
+
+// ListDeprecatedUsagesTool lists call sites that still use deprecated
+// functions, methods and classes.
+type ListDeprecatedUsagesTool struct {
+	workspaceManager *workspace.Manager
+}
+
+// NewListDeprecatedUsagesTool creates a new list_deprecated_usages tool
+func NewListDeprecatedUsagesTool(wm *workspace.Manager) *ListDeprecatedUsagesTool {
+	return &ListDeprecatedUsagesTool{
+		workspaceManager: wm,
+	}
+}
+
+// DeprecatedUsageSite is a single line calling a deprecated symbol.
+type DeprecatedUsageSite struct {
+	Caller   string `json:"caller"`
+	FilePath string `json:"file_path"`
+	Line     int    `json:"line"`
+	Code     string `json:"code,omitempty"`
+}
+
+// DeprecatedUsage is a deprecated symbol with the call sites still using it.
+type DeprecatedUsage struct {
+	Name      string                `json:"name"`
+	Kind      string                `json:"kind"`
+	Receiver  string                `json:"receiver,omitempty"`
+	Note      string                `json:"note,omitempty"`
+	FilePath  string                `json:"file_path"`
+	StartLine int                   `json:"start_line"`
+	Usages    []DeprecatedUsageSite `json:"usages"`
+}
+
+func (t *ListDeprecatedUsagesTool) Name() string {
+	return "list_deprecated_usages"
+}
+
+func (t *ListDeprecatedUsagesTool) Description() string {
+	return "List call sites that still use deprecated APIs - Go '// Deprecated:', PHPDoc @deprecated, Python @deprecated / DeprecationWarning. Returns each deprecated symbol with its deprecation note and the exact calling lines. Use when planning migrations or cleaning up before removing old APIs. Supports Go, PHP, Python."
+}
+
+func (t *ListDeprecatedUsagesTool) Execute(ctx context.Context, args map[string]interface{}) (string, error) {
+	symbol := ""
+	if v, ok := args["symbol"].(string); ok {
+		symbol = strings.TrimSpace(v)
+	}
+
+	limit := 20
+	if v, ok := args["limit"].(float64); ok && v > 0 {
+		limit = int(v)
+	}
+
+	outputFormat := outputFormatFrom(args, formatJSON)
+
+	if t.workspaceManager == nil {
+		return "", fmt.Errorf("workspace manager not configured")
+	}
+	if extractFilePathFromParams(args) == "" {
+		return "", fmt.Errorf("file_path parameter is required for list_deprecated_usages. Please provide a file path from your workspace")
+	}
+	info, err := t.workspaceManager.DetectWorkspace(args)
+	if err != nil {
+		return "", fmt.Errorf("failed to detect workspace: %w", err)
+	}
+
+	table, err := t.workspaceManager.Symbols(info)
+	if err != nil {
+		return "", fmt.Errorf("failed to load symbol table: %w", err)
+	}
+	entries := table.All()
+	if len(entries) == 0 {
+		return fmt.Sprintf("❌ No symbols recorded for workspace '%s'.\n\n"+
+			"The symbol table is built during indexing. Please call 'index_workspace' with:\n"+
+			"{\n"+
+			"  \"file_path\": \"%s\"\n"+
+			"}\n", info.Root, info.Root), nil
+	}
+
+	usages := findDeprecatedUsages(entries, symbol)
+	if len(usages) == 0 {
+		if symbol != "" {
+			return fmt.Sprintf("No deprecated symbol named '%s' found.", symbol), nil
+		}
+		return "✅ No deprecated symbols found in the workspace.", nil
+	}
+	if len(usages) > limit {
+		usages = usages[:limit]
+	}
+
+	if outputFormat == formatMinimal {
+		return formatDeprecatedUsagesMinimal(usages), nil
+	}
+	if outputFormat == "markdown" {
+		return formatDeprecatedUsages(usages), nil
+	}
+
+	data, err := json.MarshalIndent(usages, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal list_deprecated_usages results: %w", err)
+	}
+	return string(data), nil
+}
+
+// findDeprecatedUsages resolves callers of every deprecated symbol (optionally
+// only the one named symbol) down to the calling lines. Callers that are
+// deprecated themselves are skipped. Symbols with the most usages come first.
+func findDeprecatedUsages(entries []ragcode.SymbolEntry, symbol string) []DeprecatedUsage {
+	callers := ragcode.CallerIndex(entries)
+	files := make(map[string][]string)
+
+	var out []DeprecatedUsage
+	for _, e := range entries {
+		if !e.Deprecated || (symbol != "" && e.Name != symbol) {
+			continue
+		}
+		usage := DeprecatedUsage{
+			Name:      e.Name,
+			Kind:      e.Kind,
+			Receiver:  e.Receiver,
+			Note:      e.DeprecationNote,
+			FilePath:  e.FilePath,
+			StartLine: e.StartLine,
+			Usages:    []DeprecatedUsageSite{},
+		}
+
+		callRe := regexp.MustCompile(`\b` + regexp.QuoteMeta(e.Name) + `\s*\(`)
+		for _, i := range callers[e.Name] {
+			caller := entries[i]
+			if caller.Deprecated {
+				continue
+			}
+			lines, ok := files[caller.FilePath]
+			if !ok {
+				lines = readLines(caller.FilePath)
+				files[caller.FilePath] = lines
+			}
+			found := false
+			for n := caller.StartLine; n <= caller.EndLine && n <= len(lines); n++ {
+				if n < 1 || !callRe.MatchString(lines[n-1]) {
+					continue
+				}
+				found = true
+				usage.Usages = append(usage.Usages, DeprecatedUsageSite{
+					Caller:   caller.Name,
+					FilePath: caller.FilePath,
+					Line:     n,
+					Code:     strings.TrimSpace(lines[n-1]),
+				})
+			}
+			if !found {
+				// File changed since indexing; still report the caller
+				usage.Usages = append(usage.Usages, DeprecatedUsageSite{
+					Caller:   caller.Name,
+					FilePath: caller.FilePath,
+					Line:     caller.StartLine,
+				})
+			}
+		}
+		out = append(out, usage)
+	}
+
+	sort.SliceStable(out, func(i, j int) bool {
+		return len(out[i].Usages) > len(out[j].Usages)
+	})
+	return out
+}
+
+func readLines(path string) []string {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	return lines
+}
+
+func formatDeprecatedUsages(usages []DeprecatedUsage) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("# ⚠️ %d deprecated symbol(s)\n\n", len(usages)))
+	for _, u := range usages {
+		name := u.Name
+		if u.Receiver != "" {
+			name = u.Receiver + "." + u.Name
+		}
+		sb.WriteString(fmt.Sprintf("## `%s` (%s) - `%s:%d`\n\n", name, u.Kind, u.FilePath, u.StartLine))
+		if u.Note != "" {
+			sb.WriteString(fmt.Sprintf("**Deprecated:** %s\n\n", u.Note))
+		}
+		if len(u.Usages) == 0 {
+			sb.WriteString("No remaining usages - safe to remove.\n\n")
+			continue
+		}
+		for _, site := range u.Usages {
+			sb.WriteString(fmt.Sprintf("- `%s:%d` in `%s`", site.FilePath, site.Line, site.Caller))
+			if site.Code != "" {
+				sb.WriteString(fmt.Sprintf(": `%s`", site.Code))
+			}
+			sb.WriteString("\n")
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+func formatDeprecatedUsagesMinimal(usages []DeprecatedUsage) string {
+	var sb strings.Builder
+	for _, u := range usages {
+		name := u.Name
+		if u.Receiver != "" {
+			name = u.Receiver + "." + u.Name
+		}
+		sb.WriteString(minimalLine("deprecated "+u.Kind, name, u.FilePath, u.StartLine, 0, u.Note) + "\n")
+		for _, site := range u.Usages {
+			sb.WriteString("  " + minimalLine("usage", site.Caller, site.FilePath, site.Line, 0, site.Code) + "\n")
+		}
+	}
+	return sb.String()
+}
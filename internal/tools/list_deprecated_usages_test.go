@@ -0,0 +1,40 @@
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/doITmagic/rag-code-mcp/internal/ragcode"
+)
+
+func TestFindDeprecatedUsages(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "client.go")
+	src := "package client\n\nfunc Run() {\n\tx := 1\n\tDial(x)\n}\n\nfunc Legacy() {\n\tDial(2)\n}\n"
+	if err := os.WriteFile(file, []byte(src), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	entries := []ragcode.SymbolEntry{
+		{Name: "Dial", Kind: "function", FilePath: file, StartLine: 20, Deprecated: true, DeprecationNote: "use DialContext"},
+		{Name: "Run", Kind: "function", FilePath: file, StartLine: 3, EndLine: 6, Calls: []string{"Dial"}},
+		{Name: "Legacy", Kind: "function", FilePath: file, StartLine: 8, EndLine: 10, Calls: []string{"Dial"}, Deprecated: true},
+	}
+
+	usages := findDeprecatedUsages(entries, "")
+	if len(usages) != 2 {
+		t.Fatalf("len = %d, want 2", len(usages))
+	}
+	dial := usages[0]
+	if dial.Name != "Dial" || len(dial.Usages) != 1 {
+		t.Fatalf("usages[0] = %+v, want Dial with one usage (deprecated callers skipped)", dial)
+	}
+	if site := dial.Usages[0]; site.Caller != "Run" || site.Line != 5 || site.Code != "Dial(x)" {
+		t.Errorf("site = %+v", site)
+	}
+
+	if got := findDeprecatedUsages(entries, "Run"); len(got) != 0 {
+		t.Errorf("symbol filter: got %+v, want none", got)
+	}
+}
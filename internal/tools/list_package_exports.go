@@ -42,7 +42,7 @@ func (t *ListPackageExportsTool) Name() string {
 }
 
 func (t *ListPackageExportsTool) Description() string {
-	return "List all public functions, classes, and types in a package/module. Returns a structured list with symbol names, types, and signatures. Use to explore an unfamiliar package or find the right function to call. Works for Go packages, PHP namespaces, Python modules."
+	return "List all public functions, classes, and types in a package/module. Returns a structured list with symbol names, types, and signatures. Use to explore an unfamiliar package or find the right function to call. Works for Go packages, PHP namespaces, Python modules. PHP code without a namespace is grouped by directory as global\\<dir> (e.g. global\\legacy\\billing); each symbol is listed with its file path."
 }
 
 func (t *ListPackageExportsTool) Execute(ctx context.Context, args map[string]interface{}) (string, error) {
@@ -57,11 +57,7 @@ func (t *ListPackageExportsTool) Execute(ctx context.Context, args map[string]in
 		filterType = ft
 	}
 
-	// Optional output format: markdown (default) or json
-	outputFormat := "markdown"
-	if of, ok := args["output_format"].(string); ok && of != "" {
-		outputFormat = strings.ToLower(of)
-	}
+	outputFormat := outputFormatFrom(args, formatMarkdown)
 
 	// file_path is required for workspace detection
 	filePath := extractFilePathFromParams(args)
@@ -129,25 +125,9 @@ func (t *ListPackageExportsTool) Execute(ctx context.Context, args map[string]in
 		return "", fmt.Errorf("no long-term memory configured")
 	}
 
-	// Search for package contents in the vector index
-	query := fmt.Sprintf("package %s exports", packageName)
-	queryEmbedding, err := t.embedder.Embed(ctx, query)
-	if err != nil {
-		return "", fmt.Errorf("failed to generate query embedding: %w", err)
-	}
-
-	// Get more results to cover all package symbols
-	// Prefer SearchCodeOnly to exclude markdown documentation
-	type CodeSearcher interface {
-		SearchCodeOnly(ctx context.Context, query []float64, limit int) ([]memory.Document, error)
-	}
-
-	var results []memory.Document
-	if codeSearcher, ok := searchMemory.(CodeSearcher); ok {
-		results, err = codeSearcher.SearchCodeOnly(ctx, queryEmbedding, 100)
-	} else {
-		results, err = searchMemory.Search(ctx, queryEmbedding, 100)
-	}
+	// List every chunk of the matching packages rather than the nearest
+	// neighbours of a query, so large packages are listed in full
+	results, err := listPackageChunks(ctx, searchMemory, packageName, filterType)
 	if err != nil {
 		return "", fmt.Errorf("search failed: %w", err)
 	}
@@ -172,21 +152,11 @@ func (t *ListPackageExportsTool) Execute(ctx context.Context, args map[string]in
 			continue
 		}
 
-		// Filter by package name
-		if !strings.Contains(chunk.Package, packageName) {
-			continue
-		}
-
 		// Check if exported (starts with uppercase)
 		if len(chunk.Name) == 0 || !isExported(chunk.Name) {
 			continue
 		}
 
-		// Apply type filter if specified
-		if filterType != "" && chunk.Type != filterType {
-			continue
-		}
-
 		// Avoid duplicates
 		key := fmt.Sprintf("%s:%s", chunk.Type, chunk.Name)
 		if seenNames[key] {
@@ -202,7 +172,7 @@ func (t *ListPackageExportsTool) Execute(ctx context.Context, args map[string]in
 			FilePath:    chunk.FilePath,
 			StartLine:   chunk.StartLine,
 			Package:     chunk.Package,
-			Language:    chunk.Language,
+			Language:    string(chunk.Language),
 		}
 
 		exports[chunk.Type] = append(exports[chunk.Type], symbol)
@@ -213,7 +183,7 @@ func (t *ListPackageExportsTool) Execute(ctx context.Context, args map[string]in
 	}
 
 	// JSON output for Go and other non-PHP languages
-	if strings.ToLower(outputFormat) == "json" {
+	if outputFormat == formatJSON || outputFormat == formatMinimal {
 		var descriptors []codetypes.SymbolDescriptor
 		types := make([]string, 0, len(exports))
 		for t := range exports {
@@ -244,6 +214,9 @@ func (t *ListPackageExportsTool) Execute(ctx context.Context, args map[string]in
 			}
 		}
 
+		if outputFormat == formatMinimal {
+			return formatMinimalDescriptors(descriptors), nil
+		}
 		data, err := json.MarshalIndent(descriptors, "", "  ")
 		if err != nil {
 			return "", fmt.Errorf("failed to marshal Go package exports: %w", err)
@@ -303,6 +276,31 @@ type ExportedSymbol struct {
 	Language    string
 }
 
+// listPackageChunks returns the code chunks of every indexed package whose
+// name contains packageName, of symbol type filterType when set
+func listPackageChunks(ctx context.Context, mem memory.LongTermMemory, packageName, filterType string) ([]memory.Document, error) {
+	counts, err := mem.Aggregate(ctx, memory.CodeOnly(), "package")
+	if err != nil {
+		return nil, err
+	}
+	var packages []string
+	for pkg := range counts {
+		if strings.Contains(pkg, packageName) {
+			packages = append(packages, pkg)
+		}
+	}
+	if len(packages) == 0 {
+		return nil, nil
+	}
+
+	filter := memory.CodeOnly()
+	filter.Must = map[string][]string{"package": packages}
+	if filterType != "" {
+		filter.Must["type"] = []string{filterType}
+	}
+	return scrollAll(ctx, mem, filter)
+}
+
 func isExported(name string) bool {
 	if len(name) == 0 {
 		return false
@@ -353,7 +351,8 @@ func listPHPExports(ctx context.Context, info *workspace.Info, packageName strin
 			continue
 		}
 
-		key := fmt.Sprintf("%s:%s", ch.Type, ch.Name)
+		// Global-namespace code often repeats names across files
+		key := fmt.Sprintf("%s:%s:%s", ch.Type, ch.Name, ch.FilePath)
 		if seenNames[key] {
 			continue
 		}
@@ -367,7 +366,7 @@ func listPHPExports(ctx context.Context, info *workspace.Info, packageName strin
 			FilePath:    ch.FilePath,
 			StartLine:   ch.StartLine,
 			Package:     ch.Package,
-			Language:    ch.Language,
+			Language:    string(ch.Language),
 		}
 		exports[ch.Type] = append(exports[ch.Type], symbol)
 	}
@@ -378,7 +377,7 @@ func listPHPExports(ctx context.Context, info *workspace.Info, packageName strin
 
 	// JSON output
 	format := strings.ToLower(outputFormat)
-	if format == "json" {
+	if format == formatJSON || format == formatMinimal {
 		// Flatten grouped exports into a stable, sorted list of SymbolDescriptor
 		var descriptors []codetypes.SymbolDescriptor
 		types := make([]string, 0, len(exports))
@@ -410,6 +409,9 @@ func listPHPExports(ctx context.Context, info *workspace.Info, packageName strin
 			}
 		}
 
+		if format == formatMinimal {
+			return formatMinimalDescriptors(descriptors), nil
+		}
 		data, err := json.MarshalIndent(descriptors, "", "  ")
 		if err != nil {
 			return "", fmt.Errorf("failed to marshal PHP package exports: %w", err)
@@ -0,0 +1,250 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/doITmagic/rag-code-mcp/internal/codetypes"
+	"github.com/doITmagic/rag-code-mcp/internal/memory"
+	"github.com/doITmagic/rag-code-mcp/internal/ragcode"
+	"github.com/doITmagic/rag-code-mcp/internal/workspace"
+)
+
+// LoadCoverageTool ingests a coverage report so that search results can be
+// annotated, filtered and sorted by test coverage.
+type LoadCoverageTool struct {
+	workspaceManager *workspace.Manager
+}
+
+// NewLoadCoverageTool creates a new load_coverage tool
+func NewLoadCoverageTool(wm *workspace.Manager) *LoadCoverageTool {
+	return &LoadCoverageTool{
+		workspaceManager: wm,
+	}
+}
+
+func (t *LoadCoverageTool) Name() string {
+	return "load_coverage"
+}
+
+func (t *LoadCoverageTool) Description() string {
+	return "Load a test coverage report (Go coverprofile, PHPUnit clover.xml, coverage.py JSON or Cobertura XML) for the workspace. Afterwards search_code and hybrid_search report a coverage percentage per result and accept max_coverage / sort_by=coverage to focus on untested code. Use before suggesting or writing tests."
+}
+
+// FileCoverageSummary is the coverage of a single file in a load_coverage report.
+type FileCoverageSummary struct {
+	File       string  `json:"file"`
+	Percent    float64 `json:"percent"`
+	Statements int     `json:"statements"`
+}
+
+// CoverageSummary is the result of load_coverage.
+type CoverageSummary struct {
+	Format       string                `json:"format"`
+	Files        int                   `json:"files"`
+	Unresolved   []string              `json:"unresolved_files,omitempty"`
+	Percent      float64               `json:"percent"`
+	LeastCovered []FileCoverageSummary `json:"least_covered"`
+}
+
+func (t *LoadCoverageTool) Execute(ctx context.Context, args map[string]interface{}) (string, error) {
+	coverageFile, ok := args["coverage_file"].(string)
+	if !ok || strings.TrimSpace(coverageFile) == "" {
+		return "", fmt.Errorf("coverage_file is required")
+	}
+
+	outputFormat := outputFormatFrom(args, formatJSON)
+
+	if t.workspaceManager == nil {
+		return "", fmt.Errorf("workspace manager not configured")
+	}
+	if extractFilePathFromParams(args) == "" {
+		return "", fmt.Errorf("file_path parameter is required for load_coverage. Please provide a file path from your workspace")
+	}
+	info, err := t.workspaceManager.DetectWorkspace(args)
+	if err != nil {
+		return "", fmt.Errorf("failed to detect workspace: %w", err)
+	}
+
+	if !filepath.IsAbs(coverageFile) {
+		coverageFile = filepath.Join(info.Root, coverageFile)
+	}
+	data, err := os.ReadFile(coverageFile)
+	if err != nil {
+		return "", fmt.Errorf("failed to read coverage file: %w", err)
+	}
+
+	parsed, err := ragcode.ParseCoverage(data)
+	if err != nil {
+		return "", err
+	}
+
+	// Coverage tools report import paths (Go), paths relative to the project or
+	// paths from another checkout (CI). Re-key everything to workspace paths so
+	// lookups match the indexed chunks.
+	report := &ragcode.CoverageReport{
+		Format:   parsed.Format,
+		Files:    make(map[string]*ragcode.FileCoverage, len(parsed.Files)),
+		LoadedAt: parsed.LoadedAt,
+	}
+	summary := CoverageSummary{Format: parsed.Format}
+	for name, fc := range parsed.Files {
+		resolved := resolveReportedPath(info.Root, name)
+		if !withinRoot(info.Root, resolved) {
+			summary.Unresolved = append(summary.Unresolved, name)
+			continue
+		}
+		if existing, ok := report.Files[resolved]; ok {
+			existing.Blocks = append(existing.Blocks, fc.Blocks...)
+			continue
+		}
+		report.Files[resolved] = fc
+	}
+	sort.Strings(summary.Unresolved)
+
+	if len(report.Files) == 0 {
+		return fmt.Sprintf("❌ None of the %d file(s) in the coverage report could be mapped to workspace '%s'.", len(parsed.Files), info.Root), nil
+	}
+	if err := t.workspaceManager.SaveCoverage(info, report); err != nil {
+		return "", fmt.Errorf("failed to save coverage: %w", err)
+	}
+
+	covered, total := report.Totals()
+	summary.Files = len(report.Files)
+	summary.Percent = roundPercent(covered, total)
+	for name, fc := range report.Files {
+		c, n := fc.Range(0, 0)
+		if n == 0 {
+			continue
+		}
+		summary.LeastCovered = append(summary.LeastCovered, FileCoverageSummary{File: name, Percent: roundPercent(c, n), Statements: n})
+	}
+	sort.Slice(summary.LeastCovered, func(i, j int) bool {
+		a, b := summary.LeastCovered[i], summary.LeastCovered[j]
+		if a.Percent != b.Percent {
+			return a.Percent < b.Percent
+		}
+		return a.Statements > b.Statements
+	})
+	if len(summary.LeastCovered) > 10 {
+		summary.LeastCovered = summary.LeastCovered[:10]
+	}
+
+	if outputFormat == formatMinimal {
+		var sb strings.Builder
+		sb.WriteString(minimalLine("coverage", summary.Format, "", 0, 0, fmt.Sprintf("%d file(s), %.1f%% of statements covered", summary.Files, summary.Percent)) + "\n")
+		for _, f := range summary.LeastCovered {
+			sb.WriteString(minimalLine("file", "", f.File, 0, 0, fmt.Sprintf("%.1f%% (%d statements)", f.Percent, f.Statements)) + "\n")
+		}
+		return sb.String(), nil
+	}
+	if outputFormat == "markdown" {
+		var sb strings.Builder
+		sb.WriteString(fmt.Sprintf("✅ Loaded %s coverage for %d file(s): %.1f%% of statements covered\n\n", summary.Format, summary.Files, summary.Percent))
+		if len(summary.Unresolved) > 0 {
+			sb.WriteString(fmt.Sprintf("⚠️  %d file(s) outside the workspace were skipped\n\n", len(summary.Unresolved)))
+		}
+		sb.WriteString("## Least covered files\n\n")
+		for _, f := range summary.LeastCovered {
+			sb.WriteString(fmt.Sprintf("- `%s` %.1f%% (%d statements)\n", f.File, f.Percent, f.Statements))
+		}
+		return sb.String(), nil
+	}
+
+	out, err := json.MarshalIndent(summary, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal load_coverage results: %w", err)
+	}
+	return string(out), nil
+}
+
+func roundPercent(covered, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return math.Round(1000*float64(covered)/float64(total)) / 10
+}
+
+// coverageOptions are the coverage-related parameters shared by search tools.
+type coverageOptions struct {
+	maxCoverage float64 // results above this percentage are dropped; < 0 disables
+	sortByLow   bool    // order results by ascending coverage
+}
+
+func parseCoverageOptions(params map[string]interface{}) coverageOptions {
+	opts := coverageOptions{maxCoverage: -1}
+	if v, ok := params["max_coverage"].(float64); ok && v >= 0 {
+		opts.maxCoverage = v
+	}
+	if s, ok := params["sort_by"].(string); ok && strings.EqualFold(s, "coverage") {
+		opts.sortByLow = true
+	}
+	return opts
+}
+
+func (o coverageOptions) active() bool {
+	return o.maxCoverage >= 0 || o.sortByLow
+}
+
+// applyCoverage records the statement coverage of each result in its
+// metadata ("coverage", percent) and applies the filter/sort options. Results
+// without coverage data are kept unless a max_coverage filter is set.
+func applyCoverage(docs []memory.Document, report *ragcode.CoverageReport, opts coverageOptions) []memory.Document {
+	if report == nil {
+		return docs
+	}
+
+	type scored struct {
+		doc     memory.Document
+		percent float64
+		known   bool
+	}
+	results := make([]scored, 0, len(docs))
+	for _, doc := range docs {
+		var chunk codetypes.CodeChunk
+		percent, known := 0.0, false
+		if err := json.Unmarshal([]byte(doc.Content), &chunk); err == nil && chunk.FilePath != "" {
+			percent, known = report.Percent(chunk.FilePath, chunk.StartLine, chunk.EndLine)
+		}
+		if known {
+			percent = math.Round(percent*10) / 10
+			if doc.Metadata == nil {
+				doc.Metadata = make(map[string]interface{})
+			}
+			doc.Metadata["coverage"] = percent
+		}
+		if opts.maxCoverage >= 0 && (!known || percent > opts.maxCoverage) {
+			continue
+		}
+		results = append(results, scored{doc: doc, percent: percent, known: known})
+	}
+
+	if opts.sortByLow {
+		sort.SliceStable(results, func(i, j int) bool {
+			if results[i].known != results[j].known {
+				return results[i].known
+			}
+			return results[i].percent < results[j].percent
+		})
+	}
+
+	out := make([]memory.Document, 0, len(results))
+	for _, r := range results {
+		out = append(out, r.doc)
+	}
+	return out
+}
+
+// coverageLabel formats the coverage recorded by applyCoverage for markdown output.
+func coverageLabel(doc memory.Document) string {
+	if pct, ok := doc.Metadata["coverage"].(float64); ok {
+		return fmt.Sprintf(" (coverage %.1f%%)", pct)
+	}
+	return ""
+}
@@ -0,0 +1,47 @@
+package tools
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/doITmagic/rag-code-mcp/internal/codetypes"
+	"github.com/doITmagic/rag-code-mcp/internal/memory"
+	"github.com/doITmagic/rag-code-mcp/internal/ragcode"
+)
+
+func coverageDoc(t *testing.T, name string, start, end int) memory.Document {
+	t.Helper()
+	data, err := json.Marshal(codetypes.CodeChunk{Name: name, Type: "function", FilePath: "/ws/a.go", StartLine: start, EndLine: end})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return memory.Document{ID: name, Content: string(data)}
+}
+
+func TestApplyCoverage(t *testing.T) {
+	report := &ragcode.CoverageReport{Files: map[string]*ragcode.FileCoverage{
+		"/ws/a.go": {Blocks: []ragcode.CoverageBlock{
+			{StartLine: 1, EndLine: 5, Statements: 4, Hits: 1},
+			{StartLine: 10, EndLine: 12, Statements: 2, Hits: 0},
+			{StartLine: 13, EndLine: 14, Statements: 2, Hits: 3},
+		}},
+	}}
+	docs := []memory.Document{
+		coverageDoc(t, "Covered", 1, 5),
+		coverageDoc(t, "Half", 10, 14),
+		coverageDoc(t, "Unknown", 30, 40),
+	}
+
+	out := applyCoverage(docs, report, coverageOptions{maxCoverage: -1, sortByLow: true})
+	if len(out) != 3 || out[0].ID != "Half" || out[1].ID != "Covered" || out[2].ID != "Unknown" {
+		t.Fatalf("unexpected order: %v, %v, %v", out[0].ID, out[1].ID, out[2].ID)
+	}
+	if got := out[0].Metadata["coverage"]; got != 50.0 {
+		t.Errorf("coverage metadata = %v, want 50", got)
+	}
+
+	out = applyCoverage(docs, report, coverageOptions{maxCoverage: 60})
+	if len(out) != 1 || out[0].ID != "Half" {
+		t.Fatalf("max_coverage filter kept %d docs", len(out))
+	}
+}
@@ -0,0 +1,281 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/doITmagic/rag-code-mcp/internal/codetypes"
+	"github.com/doITmagic/rag-code-mcp/internal/llm"
+	"github.com/doITmagic/rag-code-mcp/internal/memory"
+	"github.com/doITmagic/rag-code-mcp/internal/workspace"
+)
+
+// LocalizeBuildErrorTool maps compiler/type-checker diagnostics to the indexed
+// symbols that contain them.
+type LocalizeBuildErrorTool struct {
+	longTermMemory   memory.LongTermMemory
+	embedder         llm.Provider
+	workspaceManager *workspace.Manager
+}
+
+// NewLocalizeBuildErrorTool creates a new build error localization tool
+func NewLocalizeBuildErrorTool(ltm memory.LongTermMemory, embedder llm.Provider) *LocalizeBuildErrorTool {
+	return &LocalizeBuildErrorTool{
+		longTermMemory: ltm,
+		embedder:       embedder,
+	}
+}
+
+// SetWorkspaceManager sets the workspace manager for workspace-aware searching
+func (t *LocalizeBuildErrorTool) SetWorkspaceManager(wm *workspace.Manager) {
+	t.workspaceManager = wm
+}
+
+func (t *LocalizeBuildErrorTool) Name() string {
+	return "localize_build_error"
+}
+
+func (t *LocalizeBuildErrorTool) Description() string {
+	return "Map compiler output to code - paste the output of go build/go vet, php -l or mypy and get, for each error, the function/class that contains it, nearby related code and the offending lines. Use to fix build or type errors without reading whole files. Works for Go, PHP, Python."
+}
+
+// BuildDiagnostic is a single error reported by a compiler or type checker.
+type BuildDiagnostic struct {
+	Tool     string `json:"tool"` // go | php | mypy
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+	Column   int    `json:"column,omitempty"`
+	Severity string `json:"severity"` // error | warning | note
+	Message  string `json:"message"`
+	Code     string `json:"code,omitempty"` // mypy error code, e.g. arg-type
+}
+
+// BuildErrorContext is the fix-context bundle returned for one diagnostic.
+type BuildErrorContext struct {
+	Diagnostic BuildDiagnostic              `json:"diagnostic"`
+	Symbol     *codetypes.SymbolDescriptor  `json:"symbol,omitempty"`
+	Related    []codetypes.SymbolDescriptor `json:"related,omitempty"`
+	Snippet    string                       `json:"snippet,omitempty"`
+}
+
+var (
+	// ./pkg/file.go:12:5: undefined: foo   (go build, go vet, gopls)
+	goDiagnosticRe = regexp.MustCompile(`^(?:vet: )?(\S+\.go):(\d+)(?::(\d+))?:\s*(.+)$`)
+	// PHP Parse error:  syntax error, unexpected '}' in /app/src/Foo.php on line 12
+	phpDiagnosticRe = regexp.MustCompile(`^(?:PHP )?(Parse|Fatal|Warning|Deprecated)(?: error)?:\s*(.+?) in (\S+\.php) on line (\d+)`)
+	// app/models.py:12: error: Incompatible types in assignment  [assignment]
+	mypyDiagnosticRe = regexp.MustCompile(`^(\S+\.pyi?):(\d+)(?::(\d+))?: (error|warning|note): (.+?)(?:\s+\[([\w-]+)\])?$`)
+)
+
+// parseBuildOutput extracts diagnostics from go build/vet, php -l and mypy
+// output. Unrecognised lines (summaries, package headers) are ignored and
+// duplicate reports of the same location and message are collapsed.
+func parseBuildOutput(output string) []BuildDiagnostic {
+	var diags []BuildDiagnostic
+	seen := make(map[string]bool)
+
+	for _, raw := range strings.Split(output, "\n") {
+		line := strings.TrimSpace(raw)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		var d BuildDiagnostic
+		if m := mypyDiagnosticRe.FindStringSubmatch(line); m != nil {
+			d = BuildDiagnostic{Tool: "mypy", File: m[1], Severity: m[4], Message: m[5], Code: m[6]}
+			d.Line, _ = strconv.Atoi(m[2])
+			d.Column, _ = strconv.Atoi(m[3])
+		} else if m := goDiagnosticRe.FindStringSubmatch(line); m != nil {
+			d = BuildDiagnostic{Tool: "go", File: m[1], Severity: "error", Message: m[4]}
+			d.Line, _ = strconv.Atoi(m[2])
+			d.Column, _ = strconv.Atoi(m[3])
+		} else if m := phpDiagnosticRe.FindStringSubmatch(line); m != nil {
+			severity := "error"
+			if m[1] == "Warning" || m[1] == "Deprecated" {
+				severity = "warning"
+			}
+			d = BuildDiagnostic{Tool: "php", File: m[3], Severity: severity, Message: strings.TrimSpace(m[2])}
+			d.Line, _ = strconv.Atoi(m[4])
+		} else {
+			continue
+		}
+
+		key := fmt.Sprintf("%s:%d:%s", d.File, d.Line, d.Message)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		diags = append(diags, d)
+	}
+
+	return diags
+}
+
+func (t *LocalizeBuildErrorTool) Execute(ctx context.Context, args map[string]interface{}) (string, error) {
+	buildOutput, ok := args["build_output"].(string)
+	if !ok || strings.TrimSpace(buildOutput) == "" {
+		return "", fmt.Errorf("build_output is required")
+	}
+
+	maxErrors := 10
+	if v, ok := args["max_errors"].(float64); ok && v > 0 {
+		maxErrors = int(v)
+	}
+
+	relatedLimit := 3
+	if v, ok := args["related_limit"].(float64); ok && v >= 0 {
+		relatedLimit = int(v)
+	}
+
+	outputFormat := outputFormatFrom(args, formatJSON)
+
+	// file_path is required for workspace detection
+	filePath := extractFilePathFromParams(args)
+	if filePath == "" {
+		return "", fmt.Errorf("file_path parameter is required for localize_build_error. Please provide a file path from your workspace")
+	}
+
+	diags := parseBuildOutput(buildOutput)
+	if len(diags) == 0 {
+		return "No go build, php -l or mypy diagnostics recognised in build_output.", nil
+	}
+	if len(diags) > maxErrors {
+		diags = diags[:maxErrors]
+	}
+
+	var workspaceInfo *workspace.Info
+	if t.workspaceManager != nil {
+		info, err := t.workspaceManager.DetectWorkspace(args)
+		if err == nil && info != nil {
+			workspaceInfo = info
+		}
+	}
+
+	// One memory per language; diagnostics may span several collections.
+	memories := make(map[string]memory.LongTermMemory)
+	memoryFor := func(language string) (memory.LongTermMemory, string, error) {
+		if mem, ok := memories[language]; ok {
+			return mem, "", nil
+		}
+		if workspaceInfo == nil {
+			return t.longTermMemory, "", nil
+		}
+		mem, msg, err := resolveLanguageMemory(ctx, t.workspaceManager, workspaceInfo, language)
+		if err != nil || msg != "" {
+			return nil, msg, err
+		}
+		memories[language] = mem
+		return mem, "", nil
+	}
+
+	// Without a workspace, paths resolve and snippets are read in the
+	// directory of file_path only
+	root := filepath.Dir(filePath)
+	if workspaceInfo != nil {
+		root = workspaceInfo.Root
+	}
+
+	bundles := make([]BuildErrorContext, 0, len(diags))
+	for _, d := range diags {
+		bundle := BuildErrorContext{Diagnostic: d}
+		resolved := resolveReportedPath(root, d.File)
+		bundle.Snippet = numberedSnippet(root, resolved, d.Line, 3)
+
+		language := inferLanguageFromPath(d.File)
+		mem, msg, err := memoryFor(language)
+		if err != nil {
+			return "", err
+		}
+		if msg != "" {
+			return msg, nil
+		}
+		if mem == nil {
+			bundles = append(bundles, bundle)
+			continue
+		}
+
+		chunks, err := loadFileChunks(ctx, mem, t.embedder, resolved, d.Message)
+		if err != nil {
+			return "", err
+		}
+
+		enclosing := enclosingChunk(chunks, d.Line)
+		if enclosing != nil {
+			descs := buildSymbolDescriptorsFromDocs([]memory.Document{enclosing.doc})
+			bundle.Symbol = &descs[0]
+		}
+		if related := neighbourChunks(chunks, enclosing, d.Line, relatedLimit); len(related) > 0 {
+			bundle.Related = buildSymbolDescriptorsFromDocs(chunkDocuments(related))
+		}
+
+		bundles = append(bundles, bundle)
+	}
+
+	if outputFormat == formatMinimal {
+		return formatBuildErrorContextsMinimal(bundles), nil
+	}
+	if outputFormat == "markdown" {
+		return formatBuildErrorContexts(bundles, t.workspaceManager.CodeFences()), nil
+	}
+
+	data, err := json.MarshalIndent(bundles, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal localize_build_error results: %w", err)
+	}
+	return string(data), nil
+}
+
+func formatBuildErrorContexts(bundles []BuildErrorContext, fences string) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("# 🧯 %d build error(s) localized\n\n", len(bundles)))
+
+	for i, b := range bundles {
+		d := b.Diagnostic
+		sb.WriteString(fmt.Sprintf("## %d. `%s:%d` (%s %s)\n\n", i+1, d.File, d.Line, d.Tool, d.Severity))
+		sb.WriteString(fmt.Sprintf("**Message:** %s\n", d.Message))
+		if d.Code != "" {
+			sb.WriteString(fmt.Sprintf("**Code:** %s\n", d.Code))
+		}
+
+		if b.Symbol != nil {
+			sb.WriteString(fmt.Sprintf("**Inside:** `%s` (%s) at `%s:%d-%d`\n",
+				b.Symbol.Name, b.Symbol.Kind, b.Symbol.Location.FilePath, b.Symbol.Location.StartLine, b.Symbol.Location.EndLine))
+		} else {
+			sb.WriteString("**Inside:** no indexed symbol covers this line\n")
+		}
+
+		if b.Snippet != "" {
+			sb.WriteString("\n" + codeBlock(fences, "", d.File, b.Snippet))
+		}
+
+		if len(b.Related) > 0 {
+			sb.WriteString("\n**Related:**\n")
+			for _, r := range b.Related {
+				sb.WriteString(fmt.Sprintf("- `%s` (%s) `%s:%d-%d`\n",
+					r.Name, r.Kind, r.Location.FilePath, r.Location.StartLine, r.Location.EndLine))
+			}
+		}
+		sb.WriteString("\n")
+	}
+
+	return sb.String()
+}
+
+// formatBuildErrorContextsMinimal renders each diagnostic followed by the
+// symbol containing it, indented.
+func formatBuildErrorContextsMinimal(bundles []BuildErrorContext) string {
+	var sb strings.Builder
+	for _, b := range bundles {
+		d := b.Diagnostic
+		sb.WriteString(minimalLine(d.Severity, d.Code, d.File, d.Line, 0, d.Message) + "\n")
+		if b.Symbol != nil {
+			sb.WriteString("  " + formatMinimalDescriptors([]codetypes.SymbolDescriptor{*b.Symbol}))
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,99 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/doITmagic/rag-code-mcp/internal/codetypes"
+	"github.com/doITmagic/rag-code-mcp/internal/memory"
+)
+
+func TestParseBuildOutput(t *testing.T) {
+	output := `# github.com/acme/app/internal/store
+internal/store/db.go:42:9: undefined: sqlx
+internal/store/db.go:42:9: undefined: sqlx
+PHP Parse error:  syntax error, unexpected '}' in /app/src/User.php on line 17
+app/models.py:12: error: Incompatible types in assignment  [assignment]
+Found 1 error in 1 file (checked 3 source files)`
+
+	diags := parseBuildOutput(output)
+	if len(diags) != 3 {
+		t.Fatalf("len(diags) = %d, want 3: %+v", len(diags), diags)
+	}
+
+	if d := diags[0]; d.Tool != "go" || d.File != "internal/store/db.go" || d.Line != 42 || d.Column != 9 || d.Message != "undefined: sqlx" {
+		t.Errorf("unexpected go diagnostic: %+v", d)
+	}
+	if d := diags[1]; d.Tool != "php" || d.File != "/app/src/User.php" || d.Line != 17 {
+		t.Errorf("unexpected php diagnostic: %+v", d)
+	}
+	if d := diags[2]; d.Tool != "mypy" || d.Line != 12 || d.Code != "assignment" || d.Severity != "error" {
+		t.Errorf("unexpected mypy diagnostic: %+v", d)
+	}
+}
+
+func TestPathsMatch(t *testing.T) {
+	cases := []struct {
+		a, b string
+		want bool
+	}{
+		{"/home/me/app/internal/store/db.go", "internal/store/db.go", true},
+		{"/home/me/app/internal/store/db.go", "./internal/store/db.go", true},
+		{"/go/src/app/internal/store/db.go", "/home/me/app/internal/store/db.go", false},
+		{"/home/me/app/internal/store/db.go", "store/xdb.go", false},
+		{"/home/me/app/db.go", "", false},
+	}
+	for _, c := range cases {
+		if got := pathsMatch(c.a, c.b); got != c.want {
+			t.Errorf("pathsMatch(%q, %q) = %v, want %v", c.a, c.b, got, c.want)
+		}
+	}
+}
+
+func TestLocalizeBuildErrorTool_FallbackMemory(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	file := filepath.Join(dir, "db.go")
+	src := "package store\n\nfunc Open() {\n\tsqlx.Open()\n}\n\nfunc Close() {}\n"
+	if err := os.WriteFile(file, []byte(src), 0o644); err != nil {
+		t.Fatalf("failed to write temp file: %v", err)
+	}
+
+	ltm := memory.NewInMemoryLongTermMemory()
+	for i, ch := range []codetypes.CodeChunk{
+		{Name: "Open", Type: "function", Language: "go", FilePath: file, StartLine: 3, EndLine: 5},
+		{Name: "Close", Type: "function", Language: "go", FilePath: file, StartLine: 7, EndLine: 7},
+	} {
+		b, _ := json.Marshal(ch)
+		_ = ltm.Store(ctx, memory.Document{ID: string(rune('a' + i)), Content: string(b)})
+	}
+
+	tool := NewLocalizeBuildErrorTool(ltm, &mockProvider{})
+	out, err := tool.Execute(ctx, map[string]interface{}{
+		"build_output": file + ":4:2: undefined: sqlx",
+		"file_path":    file,
+	})
+	if err != nil {
+		t.Fatalf("Execute returned error: %v", err)
+	}
+
+	var bundles []BuildErrorContext
+	if err := json.Unmarshal([]byte(out), &bundles); err != nil {
+		t.Fatalf("failed to unmarshal output: %v\n%s", err, out)
+	}
+	if len(bundles) != 1 {
+		t.Fatalf("len(bundles) = %d, want 1", len(bundles))
+	}
+	if bundles[0].Symbol == nil || bundles[0].Symbol.Name != "Open" {
+		t.Errorf("expected enclosing symbol Open, got %+v", bundles[0].Symbol)
+	}
+	if len(bundles[0].Related) != 1 || bundles[0].Related[0].Name != "Close" {
+		t.Errorf("expected Close as related chunk, got %+v", bundles[0].Related)
+	}
+	if bundles[0].Snippet == "" {
+		t.Errorf("expected snippet read from disk")
+	}
+}
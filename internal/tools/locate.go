@@ -0,0 +1,239 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/doITmagic/rag-code-mcp/internal/codetypes"
+	"github.com/doITmagic/rag-code-mcp/internal/llm"
+	"github.com/doITmagic/rag-code-mcp/internal/memory"
+)
+
+// locatedChunk pairs a stored document with its decoded CodeChunk.
+type locatedChunk struct {
+	doc   memory.Document
+	chunk codetypes.CodeChunk
+}
+
+// maxFileChunks caps the chunks an exact file lookup returns
+const maxFileChunks = 200
+
+// loadFileChunks returns the code chunks indexed for file. The file is looked
+// up exactly first; when that misses (the path was reported relative to
+// another root) the chunks
+// of files with the same base name are listed and filtered down to those whose
+// path matches file. Indexes without base names fall back to a semantic
+// search seeded with hint.
+func loadFileChunks(ctx context.Context, mem memory.LongTermMemory, embedder llm.Provider, file, hint string) ([]locatedChunk, error) {
+	docs, err := mem.Query(ctx, memory.SearchOptions{Filter: memory.Filter{Must: map[string][]string{"file": {file}}}, Limit: maxFileChunks})
+	if err == nil {
+		if chunks := decodeChunks(docs, file); len(chunks) > 0 {
+			return chunks, nil
+		}
+	}
+
+	docs, err = scrollAll(ctx, mem, memory.Filter{Must: map[string][]string{"basename": {filepath.Base(file)}}})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list chunks of %s: %w", file, err)
+	}
+	if chunks := decodeChunks(docs, file); len(chunks) > 0 {
+		return chunks, nil
+	}
+
+	if embedder == nil {
+		return nil, nil
+	}
+
+	query := strings.TrimSpace(filepath.Base(file) + " " + hint)
+	queryEmbedding, err := embedder.Embed(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate query embedding: %w", err)
+	}
+
+	docs, err = mem.Query(ctx, memory.SearchOptions{Vector: queryEmbedding, Filter: memory.CodeOnly(), Limit: 100})
+	if err != nil {
+		return nil, fmt.Errorf("search failed: %w", err)
+	}
+
+	return decodeChunks(docs, file), nil
+}
+
+// decodeChunks parses CodeChunk documents and keeps those belonging to file.
+func decodeChunks(docs []memory.Document, file string) []locatedChunk {
+	out := make([]locatedChunk, 0, len(docs))
+	for _, doc := range docs {
+		var chunk codetypes.CodeChunk
+		if err := json.Unmarshal([]byte(doc.Content), &chunk); err != nil || chunk.Name == "" {
+			continue
+		}
+		if !pathsMatch(chunk.FilePath, file) {
+			continue
+		}
+		out = append(out, locatedChunk{doc: doc, chunk: chunk})
+	}
+	return out
+}
+
+// pathsMatch reports whether a and b refer to the same file. Paths printed by
+// compilers and runtimes are often relative or rooted elsewhere (containers, CI
+// checkouts), so the shorter path is compared as a suffix of the longer one on
+// path-component boundaries.
+func pathsMatch(a, b string) bool {
+	a = filepath.ToSlash(filepath.Clean(a))
+	b = filepath.ToSlash(filepath.Clean(b))
+	if a == "" || b == "" || a == "." || b == "." {
+		return false
+	}
+	if a == b {
+		return true
+	}
+	if len(a) < len(b) {
+		a, b = b, a
+	}
+	b = strings.TrimPrefix(b, "./")
+	return strings.HasSuffix(a, "/"+strings.TrimPrefix(b, "/"))
+}
+
+// enclosingChunk returns the narrowest chunk whose line range contains line.
+func enclosingChunk(chunks []locatedChunk, line int) *locatedChunk {
+	var best *locatedChunk
+	for i := range chunks {
+		ch := &chunks[i]
+		if line < ch.chunk.StartLine || line > ch.chunk.EndLine {
+			continue
+		}
+		if best == nil || ch.chunk.EndLine-ch.chunk.StartLine < best.chunk.EndLine-best.chunk.StartLine {
+			best = ch
+		}
+	}
+	return best
+}
+
+// neighbourChunks returns up to n chunks other than exclude, ordered by their
+// distance from line.
+func neighbourChunks(chunks []locatedChunk, exclude *locatedChunk, line, n int) []locatedChunk {
+	if n <= 0 {
+		return nil
+	}
+
+	distance := func(ch codetypes.CodeChunk) int {
+		switch {
+		case line < ch.StartLine:
+			return ch.StartLine - line
+		case line > ch.EndLine:
+			return line - ch.EndLine
+		default:
+			return 0
+		}
+	}
+
+	candidates := make([]locatedChunk, 0, len(chunks))
+	for _, ch := range chunks {
+		if exclude != nil && ch.doc.ID == exclude.doc.ID && ch.chunk.StartLine == exclude.chunk.StartLine {
+			continue
+		}
+		candidates = append(candidates, ch)
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return distance(candidates[i].chunk) < distance(candidates[j].chunk)
+	})
+
+	if len(candidates) > n {
+		candidates = candidates[:n]
+	}
+	return candidates
+}
+
+// chunkDocuments unwraps the stored documents so they can be rendered with
+// buildSymbolDescriptorsFromDocs.
+func chunkDocuments(chunks []locatedChunk) []memory.Document {
+	docs := make([]memory.Document, 0, len(chunks))
+	for _, ch := range chunks {
+		docs = append(docs, ch.doc)
+	}
+	return docs
+}
+
+// numberedSnippet reads radius lines around line from disk, marking the target
+// line. It returns an empty string when the file cannot be read or is not
+// inside the workspace at root.
+func numberedSnippet(root, path string, line, radius int) string {
+	if !withinRoot(root, path) {
+		return ""
+	}
+	content, err := os.ReadFile(path)
+	if err != nil || line < 1 {
+		return ""
+	}
+
+	lines := strings.Split(string(content), "\n")
+	if line > len(lines) {
+		return ""
+	}
+
+	start := line - radius
+	if start < 1 {
+		start = 1
+	}
+	end := line + radius
+	if end > len(lines) {
+		end = len(lines)
+	}
+
+	var sb strings.Builder
+	for i := start; i <= end; i++ {
+		marker := "│"
+		if i == line {
+			marker = "┃"
+		}
+		sb.WriteString(fmt.Sprintf("%4d %s %s\n", i, marker, lines[i-1]))
+	}
+	return sb.String()
+}
+
+// resolveReportedPath maps a path printed by an external tool onto the
+// workspace. Relative paths are joined with root; absolute paths from another
+// checkout (containers, CI, deployed servers) are re-rooted by trying ever
+// shorter path suffixes under root until one exists on disk. Only files
+// inside root are resolved; other paths are returned unchanged.
+func resolveReportedPath(root, file string) string {
+	if file == "" || root == "" {
+		return file
+	}
+	if filepath.IsAbs(file) && withinRoot(root, file) {
+		return file
+	}
+
+	parts := strings.Split(strings.TrimPrefix(filepath.ToSlash(file), "/"), "/")
+	for i := range parts {
+		candidate := filepath.Join(root, filepath.FromSlash(strings.Join(parts[i:], "/")))
+		if withinRoot(root, candidate) {
+			return candidate
+		}
+	}
+	return file
+}
+
+// withinRoot reports whether path exists and is inside root once symlinks
+// are resolved, so neither ".." nor a link can lead outside the workspace
+func withinRoot(root, path string) bool {
+	if root == "" || !filepath.IsAbs(path) {
+		return false
+	}
+	realRoot, err := filepath.EvalSymlinks(root)
+	if err != nil {
+		return false
+	}
+	realPath, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return false
+	}
+	rel, err := filepath.Rel(realRoot, realPath)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
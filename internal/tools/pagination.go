@@ -0,0 +1,204 @@
+package tools
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/doITmagic/rag-code-mcp/internal/codetypes"
+	"github.com/doITmagic/rag-code-mcp/internal/memory"
+	"github.com/doITmagic/rag-code-mcp/internal/workspace"
+)
+
+// maxPagedResults bounds the results ranked for a paginated search
+const maxPagedResults = 100
+
+// pageSizeFrom returns the page_size parameter: 0 when the call is not
+// paginated
+func pageSizeFrom(params map[string]interface{}) int {
+	switch v := params["page_size"].(type) {
+	case float64:
+		return int(v)
+	case int:
+		return v
+	}
+	return 0
+}
+
+// cursorFrom returns the cursor of a call asking for a next page
+func cursorFrom(params map[string]interface{}) string {
+	cursor, _ := params["cursor"].(string)
+	return strings.TrimSpace(cursor)
+}
+
+// paginated reports whether a call asks for a page of results. Such calls
+// bypass the query cache: their cursors point to result sets that expire.
+func paginated(params map[string]interface{}) bool {
+	return pageSizeFrom(params) > 0 || cursorFrom(params) != ""
+}
+
+// pageCursor is what an opaque cursor encodes: the result set and the page
+type pageCursor struct {
+	set    string
+	offset int
+	size   int
+}
+
+func (c pageCursor) String() string {
+	return base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf("%s:%d:%d", c.set, c.offset, c.size)))
+}
+
+func parseCursor(s string) (pageCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return pageCursor{}, fmt.Errorf("malformed cursor")
+	}
+	parts := strings.Split(string(raw), ":")
+	if len(parts) != 3 {
+		return pageCursor{}, fmt.Errorf("malformed cursor")
+	}
+	offset, err1 := strconv.Atoi(parts[1])
+	size, err2 := strconv.Atoi(parts[2])
+	if parts[0] == "" || err1 != nil || err2 != nil || offset < 0 || size <= 0 {
+		return pageCursor{}, fmt.Errorf("malformed cursor")
+	}
+	return pageCursor{set: parts[0], offset: offset, size: size}, nil
+}
+
+// resultPage is one page of a result set
+type resultPage struct {
+	docs       []memory.Document
+	offset     int
+	total      int
+	next       string // cursor of the next page, empty on the last one
+	generation uint64 // index generation the result set was ranked at
+	stale      bool   // the workspace was re-indexed since the first page
+}
+
+func pageOf(set *workspace.ResultSet, offset, size int) resultPage {
+	end := offset + size
+	if end > len(set.Docs) {
+		end = len(set.Docs)
+	}
+	page := resultPage{offset: offset, total: len(set.Docs), generation: set.Generation}
+	if offset < end {
+		page.docs = set.Docs[offset:end]
+	}
+	if end < len(set.Docs) {
+		page.next = pageCursor{set: set.ID, offset: end, size: size}.String()
+	}
+	return page
+}
+
+// firstPage keeps the ranked results of a paginated search and returns their
+// first page
+func firstPage(ctx context.Context, wm *workspace.Manager, info *workspace.Info, tool string, docs []memory.Document, size int) resultPage {
+	if len(docs) > maxPagedResults {
+		docs = docs[:maxPagedResults]
+	}
+	return pageOf(wm.SaveResultSet(info, ClientSession(ctx), tool, docs), 0, size)
+}
+
+// nextPage returns the page a cursor points to. The message explains why
+// the cursor cannot be used (expired, or from another tool, workspace or
+// client).
+func nextPage(ctx context.Context, wm *workspace.Manager, info *workspace.Info, tool, cursor string) (resultPage, string) {
+	c, err := parseCursor(cursor)
+	if err != nil {
+		return resultPage{}, fmt.Sprintf("❌ Invalid cursor '%s'. Pass the next_cursor of a previous %s call unchanged.", cursor, tool)
+	}
+	set, ok := wm.ResultSet(c.set)
+	if !ok || set.Client != ClientSession(ctx) {
+		return resultPage{}, fmt.Sprintf("❌ Cursor expired: result sets are kept for 10 minutes. Run the %s search again without a cursor.", tool)
+	}
+	if set.Tool != tool {
+		return resultPage{}, fmt.Sprintf("❌ This cursor was returned by %s, not %s.", set.Tool, tool)
+	}
+	if set.WorkspaceID != info.ID {
+		return resultPage{}, fmt.Sprintf("❌ This cursor belongs to another workspace than '%s'.", info.Root)
+	}
+	page := pageOf(set, c.offset, c.size)
+	page.stale = wm.IndexGeneration(info) != set.Generation
+	return page, ""
+}
+
+// continueSearch answers a search call that passes the cursor of a previous
+// page: the page comes from the saved result set, without searching again
+func continueSearch(ctx context.Context, wm *workspace.Manager, tool string, params map[string]interface{}, cursor string) (string, error) {
+	if wm == nil {
+		return "", fmt.Errorf("cursor requires workspace-aware search")
+	}
+	info, err := wm.DetectWorkspace(params)
+	if err != nil {
+		return "", fmt.Errorf("failed to detect workspace: %w", err)
+	}
+	page, msg := nextPage(ctx, wm, info, tool, cursor)
+	if msg != "" {
+		return msg, nil
+	}
+	return formatPage(page, tool, outputFormatFrom(params, formatJSON), info.Root, "")
+}
+
+// pagedResults is the JSON output of paginated searches
+type pagedResults struct {
+	Results    []codetypes.SymbolDescriptor `json:"results"`
+	Offset     int                          `json:"offset"`
+	Total      int                          `json:"total"`
+	NextCursor string                       `json:"next_cursor,omitempty"`
+	Generation uint64                       `json:"index_generation"`
+	Stale      bool                         `json:"stale,omitempty"`
+}
+
+const staleNote = "ℹ️ The workspace was re-indexed since the first page: results are still from the index of the first page, chunk IDs may be superseded."
+
+// formatPage renders a page of results. prefix precedes markdown output
+// (conversation terms, glossary entries).
+func formatPage(page resultPage, tool, outputFormat, root, prefix string) (string, error) {
+	switch outputFormat {
+	case formatMinimal:
+		var sb strings.Builder
+		if page.stale {
+			sb.WriteString(staleNote + "\n")
+		}
+		sb.WriteString(formatMinimalDescriptors(buildSymbolDescriptorsFromDocs(page.docs)))
+		if page.next != "" {
+			sb.WriteString(fmt.Sprintf("next_cursor: %s\n", page.next))
+		}
+		return sb.String(), nil
+	case formatMarkdown:
+		var sb strings.Builder
+		sb.WriteString(prefix)
+		if page.stale {
+			sb.WriteString(staleNote + "\n\n")
+		}
+		if len(page.docs) == 0 {
+			sb.WriteString(fmt.Sprintf("No more results in workspace '%s'.\n", root))
+			return sb.String(), nil
+		}
+		sb.WriteString(fmt.Sprintf("🔍 Results %d-%d of %d in workspace '%s' (index generation %d):\n\n",
+			page.offset+1, page.offset+len(page.docs), page.total, root, page.generation))
+		for i, doc := range page.docs {
+			sb.WriteString(fmt.Sprintf("--- Result %d%s%s%s%s ---\n%s\n\n", page.offset+i+1, chunkIDLabel(doc)+truncatedLabel(doc), coverageLabel(doc), tagsLabel(doc), buildConstraintLabel(doc), doc.Content))
+		}
+		if page.next != "" {
+			sb.WriteString(fmt.Sprintf("➡️ More results: call %s again with cursor \"%s\".\n", tool, page.next))
+		}
+		return sb.String(), nil
+	}
+
+	data, err := json.MarshalIndent(pagedResults{
+		Results:    buildSymbolDescriptorsFromDocs(page.docs),
+		Offset:     page.offset,
+		Total:      page.total,
+		NextCursor: page.next,
+		Generation: page.generation,
+		Stale:      page.stale,
+	}, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal %s results: %w", tool, err)
+	}
+	return string(data), nil
+}
@@ -0,0 +1,77 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/doITmagic/rag-code-mcp/internal/memory"
+	"github.com/doITmagic/rag-code-mcp/internal/workspace"
+)
+
+func TestPaginatedResults(t *testing.T) {
+	wm := workspace.NewManager(nil, nil, nil)
+	info := &workspace.Info{ID: "ws1", Root: "/ws1"}
+	ctx := WithClientSession(context.Background(), "client-a")
+	docs := make([]memory.Document, 5)
+	for i := range docs {
+		docs[i] = memory.Document{ID: fmt.Sprintf("c%d", i), Content: fmt.Sprintf("chunk %d", i)}
+	}
+
+	page := firstPage(ctx, wm, info, "search_code", docs, 2)
+	if len(page.docs) != 2 || page.next == "" || page.total != 5 {
+		t.Fatalf("first page = %d docs of %d, next %q", len(page.docs), page.total, page.next)
+	}
+
+	var seen []string
+	for _, d := range page.docs {
+		seen = append(seen, d.ID)
+	}
+	cursor := page.next
+	for cursor != "" {
+		next, msg := nextPage(ctx, wm, info, "search_code", cursor)
+		if msg != "" {
+			t.Fatalf("nextPage: %s", msg)
+		}
+		for _, d := range next.docs {
+			seen = append(seen, d.ID)
+		}
+		cursor = next.next
+	}
+	if got := strings.Join(seen, ","); got != "c0,c1,c2,c3,c4" {
+		t.Errorf("pages = %s", got)
+	}
+
+	if _, msg := nextPage(ctx, wm, info, "hybrid_search", page.next); msg == "" {
+		t.Error("cursors should only work with the tool that returned them")
+	}
+	if _, msg := nextPage(ctx, wm, &workspace.Info{ID: "ws2"}, "search_code", page.next); msg == "" {
+		t.Error("cursors should only work in their workspace")
+	}
+	if _, msg := nextPage(WithClientSession(context.Background(), "client-b"), wm, info, "search_code", page.next); msg == "" {
+		t.Error("cursors should only work for the client session that searched")
+	}
+	if _, msg := nextPage(ctx, wm, info, "search_code", pageCursor{set: "gone", offset: 2, size: 2}.String()); !strings.Contains(msg, "expired") {
+		t.Errorf("unknown result set: %q", msg)
+	}
+	if _, msg := nextPage(ctx, wm, info, "search_code", "not a cursor"); msg == "" {
+		t.Error("malformed cursors should be rejected")
+	}
+}
+
+func TestFormatPageJSON(t *testing.T) {
+	page := resultPage{docs: []memory.Document{{ID: "c1", Content: "x"}}, total: 3, next: "abc", generation: 2}
+	out, err := formatPage(page, "search_code", formatJSON, "/ws", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var parsed pagedResults
+	if err := json.Unmarshal([]byte(out), &parsed); err != nil {
+		t.Fatalf("output is not a page object: %v", err)
+	}
+	if len(parsed.Results) != 1 || parsed.NextCursor != "abc" || parsed.Generation != 2 || parsed.Total != 3 {
+		t.Errorf("page = %+v", parsed)
+	}
+}
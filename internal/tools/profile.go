@@ -0,0 +1,145 @@
+package tools
+
+import (
+	"sort"
+
+	"github.com/doITmagic/rag-code-mcp/internal/config"
+)
+
+// Tool profiles, the base sets of tools.profile
+const (
+	ProfileFull     = "full"      // every tool
+	ProfileReadOnly = "read-only" // no tool changing workspace files or the index
+	ProfileMinimal  = "minimal"   // search and navigation
+)
+
+// writeTools change workspace files or the index, or build indexes on
+// demand: the read-only profile leaves them out. Automatic indexing
+// (workspace.auto_index) still keeps the index current.
+var writeTools = map[string]bool{
+	"apply_patch":               true,
+	"rollback_change":           true,
+	"create_file_from_template": true,
+	"edit_session":              true,
+	"setup_workspace":           true, // write=true creates .ragcode.yaml
+	"index_workspace":           true,
+	"reindex_file":              true,
+	"delete_workspace_index":    true,
+	"cleanup_workspaces":        true,
+	"reembed_workspace":         true,
+}
+
+// minimalTools are the tools of the minimal profile
+var minimalTools = map[string]bool{
+	"search_code":          true,
+	"hybrid_search":        true,
+	"get_function_details": true,
+	"find_type_definition": true,
+	"get_code_context":     true,
+	"list_package_exports": true,
+	"find_implementations": true,
+	"search_docs":          true,
+	"index_status":         true,
+}
+
+// ToolSelection decides which tools the server registers: those of the
+// profile, plus tools.enabled, minus tools.disabled. It records the tools
+// it was asked about, for Summary.
+type ToolSelection struct {
+	profile  string
+	enabled  map[string]bool
+	disabled map[string]bool
+	used     []string
+	skipped  []string
+}
+
+// NewToolSelection returns the selection of the tools configuration. An
+// empty profile is the full one.
+func NewToolSelection(cfg config.ToolsConfig) *ToolSelection {
+	s := &ToolSelection{
+		profile:  cfg.Profile,
+		enabled:  make(map[string]bool),
+		disabled: make(map[string]bool),
+	}
+	if s.profile == "" {
+		s.profile = ProfileFull
+	}
+	for _, name := range cfg.Enabled {
+		s.enabled[name] = true
+	}
+	for _, name := range cfg.Disabled {
+		s.disabled[name] = true
+	}
+	return s
+}
+
+// Allowed reports whether the tool name is selected
+func (s *ToolSelection) Allowed(name string) bool {
+	if s.disabled[name] {
+		return false
+	}
+	if s.enabled[name] {
+		return true
+	}
+	switch s.profile {
+	case ProfileReadOnly:
+		return !writeTools[name]
+	case ProfileMinimal:
+		return minimalTools[name]
+	}
+	return true
+}
+
+// Use reports whether the tool name is selected and records the answer
+func (s *ToolSelection) Use(name string) bool {
+	if s.Allowed(name) {
+		s.used = append(s.used, name)
+		return true
+	}
+	s.skipped = append(s.skipped, name)
+	return false
+}
+
+// Omit records tools left out for another reason than the selection, such
+// as the edit tools without edits.enabled
+func (s *ToolSelection) Omit(names ...string) {
+	s.skipped = append(s.skipped, names...)
+}
+
+// Unknown returns the names of tools.enabled and tools.disabled that
+// neither Use nor Omit saw: misspelled, or tools of another version
+func (s *ToolSelection) Unknown() []string {
+	known := make(map[string]bool, len(s.used)+len(s.skipped))
+	for _, name := range append(append([]string{}, s.used...), s.skipped...) {
+		known[name] = true
+	}
+	var unknown []string
+	for _, names := range []map[string]bool{s.enabled, s.disabled} {
+		for name := range names {
+			if !known[name] {
+				unknown = append(unknown, name)
+			}
+		}
+	}
+	sort.Strings(unknown)
+	return unknown
+}
+
+// ToolProfileSummary describes the active tool selection
+type ToolProfileSummary struct {
+	Profile  string   `json:"profile"`
+	Tools    []string `json:"tools"`              // registered, sorted
+	Disabled []string `json:"disabled,omitempty"` // left out, sorted
+}
+
+// Summary returns the profile and the tools registered and left out so far
+func (s *ToolSelection) Summary() ToolProfileSummary {
+	sum := ToolProfileSummary{
+		Profile:  s.profile,
+		Tools:    append([]string{}, s.used...),
+		Disabled: append([]string{}, s.skipped...),
+	}
+	sort.Strings(sum.Tools)
+	sort.Strings(sum.Disabled)
+	return sum
+}
@@ -0,0 +1,46 @@
+package tools
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/doITmagic/rag-code-mcp/internal/config"
+)
+
+func TestToolSelection(t *testing.T) {
+	tests := []struct {
+		cfg     config.ToolsConfig
+		allowed map[string]bool
+	}{
+		{config.ToolsConfig{}, map[string]bool{"search_code": true, "apply_patch": true, "ab_search": true}},
+		{config.ToolsConfig{Profile: ProfileReadOnly}, map[string]bool{"search_code": true, "grep_workspace": true, "index_workspace": false, "apply_patch": false}},
+		{config.ToolsConfig{Profile: ProfileMinimal}, map[string]bool{"search_code": true, "get_function_details": true, "grep_workspace": false, "reembed_workspace": false}},
+		{config.ToolsConfig{Profile: ProfileMinimal, Enabled: []string{"grep_workspace"}, Disabled: []string{"hybrid_search"}},
+			map[string]bool{"grep_workspace": true, "hybrid_search": false, "search_code": true}},
+		{config.ToolsConfig{Enabled: []string{"ab_search"}, Disabled: []string{"ab_search"}}, map[string]bool{"ab_search": false}},
+	}
+	for _, tt := range tests {
+		s := NewToolSelection(tt.cfg)
+		for name, want := range tt.allowed {
+			if got := s.Allowed(name); got != want {
+				t.Errorf("%+v: Allowed(%s) = %v, want %v", tt.cfg, name, got, want)
+			}
+		}
+	}
+}
+
+func TestToolSelectionSummary(t *testing.T) {
+	s := NewToolSelection(config.ToolsConfig{Profile: ProfileReadOnly, Enabled: []string{"reindex_file", "serch_code"}})
+	for _, name := range []string{"search_code", "index_workspace", "reindex_file"} {
+		s.Use(name)
+	}
+	s.Omit("apply_patch")
+
+	want := ToolProfileSummary{Profile: ProfileReadOnly, Tools: []string{"reindex_file", "search_code"}, Disabled: []string{"apply_patch", "index_workspace"}}
+	if got := s.Summary(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Summary() = %+v, want %+v", got, want)
+	}
+	if got := s.Unknown(); !reflect.DeepEqual(got, []string{"serch_code"}) {
+		t.Errorf("Unknown() = %v, want [serch_code]", got)
+	}
+}
@@ -0,0 +1,133 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/doITmagic/rag-code-mcp/internal/llm"
+	"github.com/doITmagic/rag-code-mcp/internal/workspace"
+)
+
+// cachedSearch runs a search tool call through the workspace query log and
+// query cache (both opt-in, see config "queries"), bounded by the tool's
+// deadline (queries.timeout). Only real results are cached; status messages
+// such as "indexing in progress" and partial results of timed out searches
+// are not.
+func cachedSearch(ctx context.Context, wm *workspace.Manager, tool string, params map[string]interface{}, run func(context.Context) (string, error)) (string, error) {
+	ctx, cancel := withSearchDeadline(ctx, wm, tool)
+	defer cancel()
+	search := func() (string, error) {
+		result, err := run(ctx)
+		if d := searchTimedOut(ctx); d != nil && err == nil {
+			result = d.flag(result)
+		}
+		return result, err
+	}
+
+	if wm == nil {
+		return search()
+	}
+	info, err := wm.DetectWorkspace(params)
+	if err != nil || info == nil {
+		return search()
+	}
+
+	start := time.Now()
+	cache := wm.QueryCache(info)
+	// Results shadowed by a client's unsaved buffers are not shared
+	if paginated(params) || len(wm.Overlays(info, ClientSession(ctx), "")) > 0 {
+		cache = nil
+	}
+	// Read the generation before searching so a re-index during the search
+	// leaves the stored result stale rather than wrongly current
+	generation := wm.IndexGeneration(info)
+	// Results are sized to the client's context window: sessions announcing
+	// different windows do not share them. The window is kept as a parameter
+	// so primed re-runs size results the same way.
+	key := params
+	if _, ok := params["context_window"]; !ok && sessionContextWindow(ctx) > 0 {
+		key = make(map[string]interface{}, len(params)+1)
+		for k, v := range params {
+			key[k] = v
+		}
+		key["context_window"] = float64(sessionContextWindow(ctx))
+	}
+	if cache != nil {
+		if result, ok := cache.Lookup(tool, key, generation); ok {
+			logQuery(wm, info, tool, params, start, true, false, nil)
+			return result, nil
+		}
+	}
+
+	result, err := search()
+	timedOut := searchTimedOut(ctx) != nil
+	if cache != nil && err == nil && !timedOut && cacheableResult(result) {
+		cache.Store(tool, key, result, generation)
+	}
+	logQuery(wm, info, tool, params, start, false, timedOut, err)
+	return result, err
+}
+
+// queryPrimer adapts a search tool's uncached execute for priming the query
+// cache after a re-index.
+func queryPrimer(execute func(context.Context, map[string]interface{}) (string, error)) workspace.QueryPrimer {
+	return func(ctx context.Context, params map[string]interface{}) (string, error) {
+		result, err := execute(ctx, params)
+		if err == nil && !cacheableResult(result) {
+			return "", fmt.Errorf("result not cacheable")
+		}
+		return result, err
+	}
+}
+
+func cacheableResult(result string) bool {
+	return !strings.HasPrefix(result, "⏳") && !strings.HasPrefix(result, "❌")
+}
+
+func logQuery(wm *workspace.Manager, info *workspace.Info, tool string, params map[string]interface{}, start time.Time, cached, timedOut bool, runErr error) {
+	entry := workspace.QueryLogEntry{
+		Time:       start,
+		Tool:       tool,
+		Params:     make(map[string]interface{}, len(params)),
+		DurationMs: time.Since(start).Milliseconds(),
+		Cached:     cached,
+		TimedOut:   timedOut,
+	}
+	for k, v := range params {
+		if k == "query" {
+			entry.Query, _ = v.(string)
+			continue
+		}
+		entry.Params[k] = v
+	}
+	if runErr != nil {
+		entry.Error = runErr.Error()
+	}
+	if err := wm.LogQuery(info, entry); err != nil {
+		log.Printf("⚠️  Failed to write query log: %v", err)
+	}
+}
+
+// embedQuery embeds a search query, reusing embeddings from the workspace
+// query cache when it is enabled.
+func embedQuery(ctx context.Context, wm *workspace.Manager, params map[string]interface{}, embedder llm.Provider, query string) ([]float64, error) {
+	var cache *workspace.QueryCache
+	if wm != nil {
+		if info, err := wm.DetectWorkspace(params); err == nil && info != nil {
+			cache = wm.QueryCache(info)
+		}
+	}
+	if cache != nil {
+		if vector, ok := cache.Embedding(query); ok {
+			return vector, nil
+		}
+	}
+	vector, err := embedder.Embed(ctx, query)
+	if err == nil && cache != nil {
+		cache.SetEmbedding(query, vector)
+	}
+	return vector, err
+}
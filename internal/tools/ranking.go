@@ -0,0 +1,281 @@
+package tools
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/doITmagic/rag-code-mcp/internal/codetypes"
+	"github.com/doITmagic/rag-code-mcp/internal/config"
+	"github.com/doITmagic/rag-code-mcp/internal/memory"
+	"github.com/doITmagic/rag-code-mcp/internal/ragcode"
+	"github.com/doITmagic/rag-code-mcp/internal/workspace"
+)
+
+// ranker scores search results with the configured ranking weights
+// (config.RankingConfig). Every search tool orders its results through it, so
+// tuning the weights changes them all alike.
+type ranker struct {
+	weights config.RankingConfig
+	now     func() time.Time
+
+	// Proximity origin: the file_path of the query and its workspace root
+	root       string
+	originFile string
+	originDir  string
+}
+
+func newRanker(weights config.RankingConfig) *ranker {
+	return &ranker{weights: weights, now: time.Now}
+}
+
+// rankerFor returns a ranker using the weights configured on the workspace
+// manager, or the defaults when there is none.
+func rankerFor(wm *workspace.Manager) *ranker {
+	return newRanker(wm.Ranking())
+}
+
+// preferRecentWeight is the recency weight used for prefer_recent=true when
+// ranking.recency_weight is not configured.
+const preferRecentWeight = 0.25
+
+// withParams applies per-query overrides: prefer_recent=true turns the recency
+// term on and prefer_recent=false turns it off.
+func (r *ranker) withParams(params map[string]interface{}) *ranker {
+	prefer, ok := params["prefer_recent"].(bool)
+	if !ok {
+		return r
+	}
+	out := *r
+	switch {
+	case !prefer:
+		out.weights.RecencyWeight = 0
+	case out.weights.RecencyWeight == 0:
+		out.weights.RecencyWeight = preferRecentWeight
+	}
+	return &out
+}
+
+// near sets the file the query was issued from, so results close to it rank
+// higher. filePath may also be a directory.
+func (r *ranker) near(root, filePath string) *ranker {
+	if filePath == "" {
+		return r
+	}
+	if abs, err := filepath.Abs(filePath); err == nil {
+		filePath = abs
+	}
+	out := *r
+	out.root = filepath.Clean(root)
+	out.originFile = filePath
+	out.originDir = out.originFile
+	if st, err := os.Stat(out.originFile); err != nil || !st.IsDir() {
+		out.originDir = filepath.Dir(out.originFile)
+	}
+	return &out
+}
+
+// proximity scores how close path is to the query origin: same file 1, same
+// directory (package) 0.6, same top-level directory of the workspace 0.3.
+func (r *ranker) proximity(path string) float64 {
+	if r.originFile == "" || path == "" {
+		return 0
+	}
+	path = filepath.Clean(path)
+	switch {
+	case path == r.originFile:
+		return 1
+	case filepath.Dir(path) == r.originDir:
+		return 0.6
+	}
+	top := func(p string) string {
+		rel, err := filepath.Rel(r.root, p)
+		if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+			return ""
+		}
+		return strings.SplitN(filepath.ToSlash(rel), "/", 2)[0]
+	}
+	if t := top(filepath.Dir(path)); t != "" && t == top(r.originDir) {
+		return 0.3
+	}
+	return 0
+}
+
+// rankedDoc is a search result with the signals that produced its score.
+type rankedDoc struct {
+	doc     memory.Document
+	score   float64
+	vector  float64
+	keyword float64 // raw keyword match count
+}
+
+// rank scores docs against query and returns them best first. Results are
+// annotated with metadata "rank_score" and, where they apply, "deprecated",
+// "test" and "generated"; the vector "score" is left unchanged. Results
+// without a vector score are ranked by their original position.
+func (r *ranker) rank(query string, docs []memory.Document) []rankedDoc {
+	return r.rankBy(query, docs, vectorRelevance)
+}
+
+// vectorRelevance is the vector score of a result, else a score decreasing
+// with its position
+func vectorRelevance(i int, docs []memory.Document) float64 {
+	if vector, ok := docs[i].Metadata["score"].(float64); ok {
+		return vector
+	}
+	return 1 - float64(i)/float64(len(docs))
+}
+
+// rankBy is rank with the base relevance (0..1) weighted by
+// ranking.vector_weight taken from relevance instead of the vector score.
+func (r *ranker) rankBy(query string, docs []memory.Document, relevance func(i int, docs []memory.Document) float64) []rankedDoc {
+	w := r.weights
+	tokens := filterTokens(strings.Fields(strings.ToLower(query)))
+	names := queryNames(query)
+
+	results := make([]rankedDoc, len(docs))
+	maxKeyword := 0.0
+	for i, doc := range docs {
+		vector := relevance(i, docs)
+		keyword := lexicalMatchScore(strings.ToLower(doc.Content), tokens)
+		if keyword > maxKeyword {
+			maxKeyword = keyword
+		}
+		results[i] = rankedDoc{doc: doc, vector: vector, keyword: keyword}
+	}
+
+	headers := make(map[string]bool)
+	modTimes := make(map[string]time.Time)
+	for i := range results {
+		res := &results[i]
+		var chunk codetypes.CodeChunk
+		_ = json.Unmarshal([]byte(res.doc.Content), &chunk)
+
+		keywordNorm := 0.0
+		if maxKeyword > 0 {
+			keywordNorm = res.keyword / maxKeyword
+		}
+		score := w.VectorWeight*res.vector + w.KeywordWeight*keywordNorm
+		if chunk.Name != "" && names[strings.ToLower(chunk.Name)] {
+			score += w.ExactNameBonus
+		}
+		if w.ProximityWeight > 0 {
+			score += w.ProximityWeight * r.proximity(chunk.FilePath)
+		}
+		if w.RecencyWeight > 0 {
+			// Prefer the git blame time of the chunk itself, else the file mtime
+			mod, ok := chunk.LastModified()
+			if !ok && chunk.FilePath != "" {
+				if mod, ok = modTimes[chunk.FilePath]; !ok {
+					if st, err := os.Stat(chunk.FilePath); err == nil {
+						mod = st.ModTime()
+					}
+					modTimes[chunk.FilePath] = mod
+				}
+			}
+			if !mod.IsZero() {
+				days := math.Max(r.now().Sub(mod).Hours()/24, 0)
+				score += w.RecencyWeight * math.Exp(-math.Ln2*days/w.RecencyHalfLifeDays)
+			}
+		}
+
+		if res.doc.Metadata == nil {
+			res.doc.Metadata = make(map[string]interface{})
+		}
+		flag := func(key string) { res.doc.Metadata[key] = true }
+		if deprecated, _ := chunk.Deprecation(); deprecated {
+			score *= w.DeprecatedPenalty
+			flag("deprecated")
+		}
+		if chunk.FilePath != "" && isTestSymbol(ragcode.SymbolEntry{Name: chunk.Name, FilePath: chunk.FilePath}) {
+			score *= w.TestPenalty
+			flag("test")
+		}
+		if isGeneratedChunk(chunk, headers) {
+			score *= w.GeneratedPenalty
+			flag("generated")
+		}
+
+		res.score = score
+		res.doc.Metadata["rank_score"] = score
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].score > results[j].score
+	})
+	return results
+}
+
+// rankDocs is rank without the per-result signals.
+func (r *ranker) rankDocs(query string, docs []memory.Document) []memory.Document {
+	ranked := r.rank(query, docs)
+	out := make([]memory.Document, len(ranked))
+	for i, res := range ranked {
+		out[i] = res.doc
+	}
+	return out
+}
+
+// queryNames returns the identifiers in query, lowercased, so that
+// "Billing.charge()" matches symbols named Billing or charge.
+func queryNames(query string) map[string]bool {
+	names := make(map[string]bool)
+	for _, f := range strings.FieldsFunc(query, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_'
+	}) {
+		names[strings.ToLower(f)] = true
+	}
+	return names
+}
+
+var generatedFileSuffixes = []string{
+	".pb.go", "_gen.go", ".gen.go", "_generated.go", "_pb2.py", "_pb2_grpc.py",
+}
+
+// isGeneratedChunk reports whether a chunk belongs to generated code, judged
+// by its file name, a generated marker in the code or in the file header.
+// File headers are read once per file and cached in headers.
+func isGeneratedChunk(chunk codetypes.CodeChunk, headers map[string]bool) bool {
+	if chunk.FilePath == "" {
+		return false
+	}
+	base := strings.ToLower(filepath.Base(chunk.FilePath))
+	for _, suffix := range generatedFileSuffixes {
+		if strings.HasSuffix(base, suffix) {
+			return true
+		}
+	}
+	if strings.HasPrefix(base, "zz_generated") || strings.Contains(filepath.ToSlash(chunk.FilePath), "/generated/") {
+		return true
+	}
+	if hasGeneratedMarker([]byte(chunk.Code)) {
+		return true
+	}
+
+	generated, ok := headers[chunk.FilePath]
+	if !ok {
+		if f, err := os.Open(chunk.FilePath); err == nil {
+			head := make([]byte, 1024)
+			n, _ := f.Read(head)
+			f.Close()
+			generated = hasGeneratedMarker(head[:n])
+		}
+		headers[chunk.FilePath] = generated
+	}
+	return generated
+}
+
+// hasGeneratedMarker matches "// Code generated ... DO NOT EDIT." (Go) and
+// the "@generated" marker used by PHP and Python generators.
+func hasGeneratedMarker(src []byte) bool {
+	if bytes.Contains(src, []byte("@generated")) {
+		return true
+	}
+	return bytes.Contains(src, []byte("Code generated")) && bytes.Contains(src, []byte("DO NOT EDIT"))
+}
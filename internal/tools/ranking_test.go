@@ -0,0 +1,136 @@
+package tools
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/doITmagic/rag-code-mcp/internal/codetypes"
+	"github.com/doITmagic/rag-code-mcp/internal/config"
+	"github.com/doITmagic/rag-code-mcp/internal/memory"
+)
+
+func rankingDoc(chunk codetypes.CodeChunk, score float64) memory.Document {
+	data, _ := json.Marshal(chunk)
+	return memory.Document{Content: string(data), Metadata: map[string]interface{}{"score": score}}
+}
+
+func rankedNames(t *testing.T, docs []memory.Document) []string {
+	t.Helper()
+	names := make([]string, len(docs))
+	for i, doc := range docs {
+		var chunk codetypes.CodeChunk
+		if err := json.Unmarshal([]byte(doc.Content), &chunk); err != nil {
+			t.Fatal(err)
+		}
+		names[i] = chunk.Name
+	}
+	return names
+}
+
+func TestRankerPenalties(t *testing.T) {
+	deprecated := codetypes.CodeChunk{Name: "Dial", FilePath: "/ws/net/dial.go"}
+	codetypes.MarkDeprecated(&deprecated, "use DialContext")
+
+	docs := []memory.Document{
+		rankingDoc(deprecated, 0.9),
+		rankingDoc(codetypes.CodeChunk{Name: "TestDialContext", FilePath: "/ws/net/dial_test.go"}, 0.88),
+		rankingDoc(codetypes.CodeChunk{Name: "Dialer", FilePath: "/ws/net/dialer.pb.go"}, 0.87),
+		rankingDoc(codetypes.CodeChunk{Name: "DialContext", FilePath: "/ws/net/dial.go"}, 0.8),
+	}
+
+	ranked := newRanker(config.DefaultRankingConfig()).rankDocs("connect to server", docs)
+	if got := rankedNames(t, ranked); got[0] != "DialContext" {
+		t.Errorf("order = %v, want DialContext first", got)
+	}
+	for _, doc := range ranked {
+		if _, ok := doc.Metadata["rank_score"].(float64); !ok {
+			t.Errorf("rank_score missing: %v", doc.Metadata)
+		}
+	}
+	if ranked[len(ranked)-1].Metadata["generated"] != true {
+		t.Errorf("generated result should rank last: %v", rankedNames(t, ranked))
+	}
+
+	// Penalties of 1 disable demotion entirely
+	neutral := config.DefaultRankingConfig()
+	neutral.KeywordWeight, neutral.TestPenalty, neutral.GeneratedPenalty, neutral.DeprecatedPenalty = 0, 1, 1, 1
+	if got := rankedNames(t, newRanker(neutral).rankDocs("connect", docs)); got[0] != "Dial" {
+		t.Errorf("order = %v, want vector order", got)
+	}
+}
+
+func TestRankerKeywordAndExactName(t *testing.T) {
+	docs := []memory.Document{
+		rankingDoc(codetypes.CodeChunk{Name: "Open", Code: "func Open() {}"}, 0.9),
+		rankingDoc(codetypes.CodeChunk{Name: "ParseConfig", Code: "func ParseConfig() { config config }"}, 0.7),
+	}
+
+	ranked := newRanker(config.DefaultRankingConfig()).rank("ParseConfig", docs)
+	if ranked[0].keyword == 0 || rankedNames(t, []memory.Document{ranked[0].doc})[0] != "ParseConfig" {
+		t.Errorf("keyword/exact name match should win, got %+v", ranked)
+	}
+
+	vectorOnly := config.RankingConfig{VectorWeight: 1, TestPenalty: 1, GeneratedPenalty: 1, DeprecatedPenalty: 1}
+	if got := rankedNames(t, newRanker(vectorOnly).rankDocs("ParseConfig", docs)); got[0] != "Open" {
+		t.Errorf("order = %v, want Open first with vector-only weights", got)
+	}
+}
+
+func TestRankerPreferRecent(t *testing.T) {
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	old := codetypes.CodeChunk{Name: "Legacy"}
+	codetypes.SetLastModified(&old, now.AddDate(-2, 0, 0), "ana")
+	recent := codetypes.CodeChunk{Name: "Fresh"}
+	codetypes.SetLastModified(&recent, now.AddDate(0, 0, -1), "bo")
+	docs := []memory.Document{rankingDoc(old, 0.8), rankingDoc(recent, 0.75)}
+
+	r := newRanker(config.DefaultRankingConfig())
+	r.now = func() time.Time { return now }
+
+	if got := rankedNames(t, r.rankDocs("q", docs)); got[0] != "Legacy" {
+		t.Errorf("without prefer_recent: order = %v, want Legacy first", got)
+	}
+	preferred := r.withParams(map[string]interface{}{"prefer_recent": true})
+	if got := rankedNames(t, preferred.rankDocs("q", docs)); got[0] != "Fresh" {
+		t.Errorf("with prefer_recent: order = %v, want Fresh first", got)
+	}
+	if r.weights.RecencyWeight != 0 {
+		t.Errorf("withParams must not modify the base ranker")
+	}
+
+	configured := config.DefaultRankingConfig()
+	configured.RecencyWeight = 0.5
+	if w := newRanker(configured).withParams(map[string]interface{}{"prefer_recent": false}).weights.RecencyWeight; w != 0 {
+		t.Errorf("prefer_recent=false: recency weight = %v, want 0", w)
+	}
+}
+
+func TestRankerProximity(t *testing.T) {
+	root := "/ws"
+	r := newRanker(config.DefaultRankingConfig()).near(root, "/ws/internal/billing/charge.go")
+
+	cases := map[string]float64{
+		"/ws/internal/billing/charge.go": 1,
+		"/ws/internal/billing/refund.go": 0.6,
+		"/ws/internal/cart/cart.go":      0.3,
+		"/ws/cmd/app/main.go":            0,
+		"/other/internal/billing/x.go":   0,
+	}
+	for path, want := range cases {
+		if got := r.proximity(path); got != want {
+			t.Errorf("proximity(%s) = %v, want %v", path, got, want)
+		}
+	}
+
+	docs := []memory.Document{
+		rankingDoc(codetypes.CodeChunk{Name: "Far", FilePath: "/ws/cmd/app/main.go"}, 0.82),
+		rankingDoc(codetypes.CodeChunk{Name: "Near", FilePath: "/ws/internal/billing/refund.go"}, 0.8),
+	}
+	if got := rankedNames(t, r.rankDocs("q", docs)); got[0] != "Near" {
+		t.Errorf("order = %v, want Near first", got)
+	}
+	if got := rankedNames(t, newRanker(config.DefaultRankingConfig()).rankDocs("q", docs)); got[0] != "Far" {
+		t.Errorf("without origin: order = %v, want Far first", got)
+	}
+}
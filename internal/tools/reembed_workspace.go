@@ -0,0 +1,93 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/doITmagic/rag-code-mcp/internal/workspace"
+)
+
+// ReembedWorkspaceTool re-embeds the collections of a workspace with the
+// configured embedding model
+type ReembedWorkspaceTool struct {
+	workspaceManager *workspace.Manager
+}
+
+// NewReembedWorkspaceTool creates a new reembed_workspace tool
+func NewReembedWorkspaceTool(wm *workspace.Manager) *ReembedWorkspaceTool {
+	return &ReembedWorkspaceTool{
+		workspaceManager: wm,
+	}
+}
+
+func (t *ReembedWorkspaceTool) Name() string {
+	return "reembed_workspace"
+}
+
+func (t *ReembedWorkspaceTool) Description() string {
+	return "Re-embed the index of the workspace containing file_path after the embedding model changed (e.g. nomic-embed-text → mxbai-embed-large), when searches fail on a vector dimension mismatch. Collections whose stored dimension differs from the configured model's are embedded again from their stored chunks into a new collection that replaces the old one atomically; no file is analyzed again. Use dry_run to compare the dimensions, force to re-embed collections that already match, language to limit it to one language."
+}
+
+func (t *ReembedWorkspaceTool) Execute(ctx context.Context, params map[string]interface{}) (string, error) {
+	if t.workspaceManager == nil {
+		return "", fmt.Errorf("workspace manager not configured")
+	}
+	if extractFilePathFromParams(params) == "" {
+		return "", fmt.Errorf("file_path parameter is required for reembed_workspace. Please provide a file path from your workspace")
+	}
+	info, err := t.workspaceManager.DetectWorkspace(params)
+	if err != nil {
+		return "", fmt.Errorf("failed to detect workspace: %w", err)
+	}
+	language, _ := params["language"].(string)
+	force, _ := params["force"].(bool)
+	dryRun, _ := params["dry_run"].(bool)
+
+	report, err := t.workspaceManager.Reembed(ctx, info, strings.TrimSpace(language), force, dryRun)
+	if err != nil {
+		return "", err
+	}
+
+	if outputFormatFrom(params, formatMarkdown) == formatJSON {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return "", fmt.Errorf("failed to marshal reembed_workspace results: %w", err)
+		}
+		return string(data), nil
+	}
+	return FormatReembedReport(report), nil
+}
+
+// FormatReembedReport renders re-embedded collections as markdown.
+func FormatReembedReport(r *workspace.ReembedReport) string {
+	var sb strings.Builder
+	if r.DryRun {
+		sb.WriteString(fmt.Sprintf("# 🔁 Re-embedding %s with %s (dry run)\n\n", r.Root, r.Model))
+	} else {
+		sb.WriteString(fmt.Sprintf("# 🔁 Re-embedded %s with %s\n\n", r.Root, r.Model))
+	}
+	sb.WriteString(fmt.Sprintf("Configured dimension: %d\n\n", r.Dimension))
+	if len(r.Collections) == 0 {
+		sb.WriteString("No collections found.\n")
+		return sb.String()
+	}
+
+	pending := 0
+	for _, c := range r.Collections {
+		status := "up to date"
+		switch {
+		case c.Reembedded && r.DryRun:
+			status = "would be re-embedded"
+			pending++
+		case c.Reembedded:
+			status = "re-embedded"
+		}
+		sb.WriteString(fmt.Sprintf("- `%s` (%s): %d points, dimension %d — %s\n", c.Name, c.Language, c.Points, c.Dimension, status))
+	}
+	if pending > 0 {
+		sb.WriteString("\nRun again without dry_run to re-embed them.\n")
+	}
+	return sb.String()
+}
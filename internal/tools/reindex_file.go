@@ -0,0 +1,124 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/doITmagic/rag-code-mcp/internal/codetypes"
+	"github.com/doITmagic/rag-code-mcp/internal/memory"
+	"github.com/doITmagic/rag-code-mcp/internal/workspace"
+)
+
+// ReindexFileTool re-indexes one file on request, for agents that edited it
+// outside apply_patch and cannot wait for the file watcher.
+type ReindexFileTool struct {
+	workspaceManager *workspace.Manager
+}
+
+// NewReindexFileTool creates a new reindex_file tool
+func NewReindexFileTool(wm *workspace.Manager) *ReindexFileTool {
+	return &ReindexFileTool{
+		workspaceManager: wm,
+	}
+}
+
+// ReindexFileResult lists the chunks indexed for a file after re-indexing it
+type ReindexFileResult struct {
+	File       string                       `json:"file"`
+	Language   string                       `json:"language"`
+	Collection string                       `json:"collection"`
+	Deleted    bool                         `json:"deleted,omitempty"`
+	Chunks     []codetypes.SymbolDescriptor `json:"chunks"`
+}
+
+func (t *ReindexFileTool) Name() string {
+	return "reindex_file"
+}
+
+func (t *ReindexFileTool) Description() string {
+	return "Re-index one file right now and return its new chunk IDs. Use after editing a file yourself (not through apply_patch) so the next search sees the change without waiting for the file watcher. Chunk IDs from before the edit are superseded."
+}
+
+func (t *ReindexFileTool) Execute(ctx context.Context, args map[string]interface{}) (string, error) {
+	if t.workspaceManager == nil {
+		return "", fmt.Errorf("workspace manager not configured")
+	}
+	filePath := extractFilePathFromParams(args)
+	if filePath == "" {
+		return "", fmt.Errorf("file_path parameter is required for reindex_file: the file to re-index")
+	}
+	info, err := t.workspaceManager.DetectWorkspace(args)
+	if err != nil {
+		return "", fmt.Errorf("failed to detect workspace: %w", err)
+	}
+
+	language, path, err := t.workspaceManager.ReindexFile(ctx, info, filePath)
+	if err != nil {
+		if language != "" && t.workspaceManager.IsIndexing(info.ID+"-"+language) {
+			return fmt.Sprintf("⏳ %v", err), nil
+		}
+		return "", err
+	}
+
+	result := ReindexFileResult{
+		File:       path,
+		Language:   language,
+		Collection: info.CollectionNameForLanguage(language),
+		Chunks:     []codetypes.SymbolDescriptor{},
+	}
+	if _, statErr := os.Stat(path); os.IsNotExist(statErr) {
+		result.Deleted = true
+	} else {
+		mem, msg, err := resolveLanguageMemory(ctx, t.workspaceManager, info, language)
+		if err != nil {
+			return "", err
+		}
+		if msg != "" {
+			return msg, nil
+		}
+		chunks, err := loadFileChunks(ctx, mem, nil, path, "")
+		if err != nil {
+			return "", fmt.Errorf("failed to load the chunks of %s: %w", path, err)
+		}
+		docs := make([]memory.Document, 0, len(chunks))
+		for _, c := range chunks {
+			docs = append(docs, c.doc)
+		}
+		for _, desc := range buildSymbolDescriptorsFromDocs(docs) {
+			delete(desc.Metadata, "snippet")
+			result.Chunks = append(result.Chunks, desc)
+		}
+	}
+
+	switch outputFormatFrom(args, formatMarkdown) {
+	case formatJSON:
+		data, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return "", fmt.Errorf("failed to marshal reindex_file result: %w", err)
+		}
+		return string(data), nil
+	case formatMinimal:
+		return formatMinimalDescriptors(result.Chunks), nil
+	}
+	return formatReindexFileResult(result), nil
+}
+
+func formatReindexFileResult(r ReindexFileResult) string {
+	var sb strings.Builder
+	if r.Deleted {
+		sb.WriteString(fmt.Sprintf("🗑️ `%s` no longer exists: its chunks were removed from %s.\n", r.File, r.Collection))
+		return sb.String()
+	}
+	sb.WriteString(fmt.Sprintf("♻️ Re-indexed `%s` (%s): %d chunk(s)\n\n", r.File, r.Language, len(r.Chunks)))
+	for _, c := range r.Chunks {
+		sb.WriteString(fmt.Sprintf("- `%s` (%s) lines %d-%d", c.Name, c.Kind, c.Location.StartLine, c.Location.EndLine))
+		if id, ok := c.Metadata["chunk_id"].(string); ok {
+			sb.WriteString(fmt.Sprintf(" [chunk_id %s]", id))
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
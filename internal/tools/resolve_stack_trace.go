@@ -0,0 +1,403 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/doITmagic/rag-code-mcp/internal/codetypes"
+	"github.com/doITmagic/rag-code-mcp/internal/llm"
+	"github.com/doITmagic/rag-code-mcp/internal/memory"
+	"github.com/doITmagic/rag-code-mcp/internal/ragcode"
+	"github.com/doITmagic/rag-code-mcp/internal/workspace"
+)
+
+// ResolveStackTraceTool maps stack frames from Go panics, PHP exceptions and
+// Python tracebacks to the indexed symbols that contain them.
+type ResolveStackTraceTool struct {
+	longTermMemory   memory.LongTermMemory
+	embedder         llm.Provider
+	workspaceManager *workspace.Manager
+}
+
+// NewResolveStackTraceTool creates a new stack trace resolution tool
+func NewResolveStackTraceTool(ltm memory.LongTermMemory, embedder llm.Provider) *ResolveStackTraceTool {
+	return &ResolveStackTraceTool{
+		longTermMemory: ltm,
+		embedder:       embedder,
+	}
+}
+
+// SetWorkspaceManager sets the workspace manager for workspace-aware searching
+func (t *ResolveStackTraceTool) SetWorkspaceManager(wm *workspace.Manager) {
+	t.workspaceManager = wm
+}
+
+func (t *ResolveStackTraceTool) Name() string {
+	return "resolve_stack_trace"
+}
+
+func (t *ResolveStackTraceTool) Description() string {
+	return "Resolve a stack trace to code - paste a Go panic, PHP exception or Python traceback and get the function containing each of the top frames with its source and surrounding lines. Paths from containers or CI are matched to the workspace by suffix. Works for Go, PHP, Python."
+}
+
+// StackFrame is a single frame parsed from a stack trace, ordered innermost first.
+type StackFrame struct {
+	Function string `json:"function,omitempty"`
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+}
+
+// ResolvedFrame is a stack frame together with the indexed code it points at.
+type ResolvedFrame struct {
+	Frame        StackFrame                  `json:"frame"`
+	ResolvedPath string                      `json:"resolved_path,omitempty"`
+	Symbol       *codetypes.SymbolDescriptor `json:"symbol,omitempty"`
+	Code         string                      `json:"code,omitempty"`
+	Context      string                      `json:"context,omitempty"`
+}
+
+// StackTraceResolution is the result of resolve_stack_trace.
+type StackTraceResolution struct {
+	Language string          `json:"language"` // go | php | python
+	Message  string          `json:"message,omitempty"`
+	Frames   []ResolvedFrame `json:"frames"`
+	Omitted  int             `json:"omitted_frames,omitempty"`
+	// LogOrigins are logging/raise call sites whose format string matches the
+	// error message, useful when the message was produced by a wrapped error.
+	LogOrigins []ragcode.LogMatch `json:"log_origins,omitempty"`
+}
+
+var (
+	// \t/home/me/app/main.go:12 +0x1d
+	goFrameLocationRe = regexp.MustCompile(`^\s+(\S+\.go):(\d+)(?: \+0x[0-9a-f]+)?$`)
+	// #0 /app/src/Foo.php(34): App\Foo->bar()
+	phpFrameRe = regexp.MustCompile(`^#\d+ (\S+\.php)\((\d+)\): (.+)$`)
+	// PHP Fatal error:  Uncaught RuntimeException: boom in /app/src/Foo.php:12
+	phpOriginRe = regexp.MustCompile(`(?:Uncaught )?([\w\\]+(?:Exception|Error)):? (.*?) in (\S+\.php)(?::| on line )(\d+)`)
+	//   File "/app/x.py", line 10, in handler
+	pythonFrameRe = regexp.MustCompile(`^\s*File "(.+)", line (\d+)(?:, in (.+))?$`)
+)
+
+// parseStackTrace detects the trace flavour and returns its frames ordered
+// innermost (where the error happened) first.
+func parseStackTrace(trace string) (string, string, []StackFrame) {
+	lines := strings.Split(strings.ReplaceAll(trace, "\r\n", "\n"), "\n")
+
+	switch {
+	case strings.Contains(trace, "Traceback (most recent call last)") || firstMatchingLine(lines, pythonFrameRe) != "":
+		return "python", pythonMessage(lines), parsePythonFrames(lines)
+	case strings.Contains(trace, "goroutine ") || strings.HasPrefix(strings.TrimSpace(trace), "panic:"):
+		return "go", goMessage(lines), parseGoFrames(lines)
+	default:
+		message, frames := parsePHPFrames(lines)
+		return "php", message, frames
+	}
+}
+
+func firstMatchingLine(lines []string, re *regexp.Regexp) string {
+	for _, line := range lines {
+		if re.MatchString(line) {
+			return line
+		}
+	}
+	return ""
+}
+
+func parseGoFrames(lines []string) []StackFrame {
+	var frames []StackFrame
+	for i, line := range lines {
+		m := goFrameLocationRe.FindStringSubmatch(line)
+		if m == nil || i == 0 {
+			continue
+		}
+		fn := strings.TrimSpace(lines[i-1])
+		fn = strings.TrimPrefix(fn, "created by ")
+		if idx := strings.Index(fn, " in goroutine"); idx >= 0 {
+			fn = fn[:idx]
+		}
+		if idx := strings.LastIndex(fn, "("); idx > 0 {
+			fn = fn[:idx]
+		}
+		lineNo, _ := strconv.Atoi(m[2])
+		frames = append(frames, StackFrame{Function: fn, File: m[1], Line: lineNo})
+	}
+	return frames
+}
+
+func goMessage(lines []string) string {
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "panic:") || strings.HasPrefix(trimmed, "fatal error:") {
+			return trimmed
+		}
+	}
+	return ""
+}
+
+func parsePythonFrames(lines []string) []StackFrame {
+	var frames []StackFrame
+	for _, line := range lines {
+		m := pythonFrameRe.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		lineNo, _ := strconv.Atoi(m[2])
+		frames = append(frames, StackFrame{Function: m[3], File: m[1], Line: lineNo})
+	}
+	// Python prints the most recent call last.
+	for i, j := 0, len(frames)-1; i < j; i, j = i+1, j-1 {
+		frames[i], frames[j] = frames[j], frames[i]
+	}
+	return frames
+}
+
+func pythonMessage(lines []string) string {
+	for i := len(lines) - 1; i >= 0; i-- {
+		line := lines[i]
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t") {
+			continue
+		}
+		if strings.HasPrefix(line, "Traceback") {
+			return ""
+		}
+		return strings.TrimSpace(line)
+	}
+	return ""
+}
+
+// parsePHPFrames handles "Uncaught X in file:line" headers followed by the
+// "#N file(line): call()" listing used by PHP and Laravel logs. Each listed
+// location executes the call printed on the next frame up, so the function
+// names are shifted by one to describe where the location lives.
+func parsePHPFrames(lines []string) (string, []StackFrame) {
+	var message string
+	var origin *StackFrame
+	var listed []StackFrame
+	var calls []string
+
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if origin == nil {
+			if m := phpOriginRe.FindStringSubmatch(trimmed); m != nil {
+				lineNo, _ := strconv.Atoi(m[4])
+				origin = &StackFrame{File: m[3], Line: lineNo}
+				message = m[1] + ": " + m[2]
+				continue
+			}
+		}
+		if m := phpFrameRe.FindStringSubmatch(trimmed); m != nil {
+			lineNo, _ := strconv.Atoi(m[2])
+			listed = append(listed, StackFrame{File: m[1], Line: lineNo})
+			calls = append(calls, phpCallee(m[3]))
+		}
+	}
+
+	var frames []StackFrame
+	if origin != nil {
+		if len(calls) > 0 {
+			origin.Function = calls[0]
+		}
+		frames = append(frames, *origin)
+	}
+	for i := range listed {
+		if i+1 < len(calls) {
+			listed[i].Function = calls[i+1]
+		}
+		frames = append(frames, listed[i])
+	}
+	return message, frames
+}
+
+// phpCallee strips the argument list from a PHP frame call expression.
+func phpCallee(call string) string {
+	if idx := strings.Index(call, "("); idx > 0 {
+		return call[:idx]
+	}
+	return call
+}
+
+// shortFunctionName reduces a qualified frame function (pkg.(*T).Method,
+// App\Foo->bar, module.func) to the bare symbol name stored in the index.
+func shortFunctionName(fn string) string {
+	for _, sep := range []string{"->", "::", "."} {
+		if idx := strings.LastIndex(fn, sep); idx >= 0 {
+			fn = fn[idx+len(sep):]
+		}
+	}
+	return strings.Trim(fn, "()*")
+}
+
+func (t *ResolveStackTraceTool) Execute(ctx context.Context, args map[string]interface{}) (string, error) {
+	trace, ok := args["stack_trace"].(string)
+	if !ok || strings.TrimSpace(trace) == "" {
+		return "", fmt.Errorf("stack_trace is required")
+	}
+
+	maxFrames := 5
+	if v, ok := args["max_frames"].(float64); ok && v > 0 {
+		maxFrames = int(v)
+	}
+
+	contextLines := 3
+	if v, ok := args["context_lines"].(float64); ok && v >= 0 {
+		contextLines = int(v)
+	}
+
+	outputFormat := outputFormatFrom(args, formatJSON)
+
+	// file_path is required for workspace detection
+	filePath := extractFilePathFromParams(args)
+	if filePath == "" {
+		return "", fmt.Errorf("file_path parameter is required for resolve_stack_trace. Please provide a file path from your workspace")
+	}
+
+	language, message, frames := parseStackTrace(trace)
+	if len(frames) == 0 {
+		return "No Go, PHP or Python stack frames recognised in stack_trace.", nil
+	}
+
+	var workspaceInfo *workspace.Info
+	if t.workspaceManager != nil {
+		info, err := t.workspaceManager.DetectWorkspace(args)
+		if err == nil && info != nil {
+			workspaceInfo = info
+		}
+	}
+
+	searchMemory := t.longTermMemory
+	root := ""
+	// Without a workspace, snippets are read in the directory of file_path only
+	snippetRoot := filepath.Dir(filePath)
+	if workspaceInfo != nil {
+		root = workspaceInfo.Root
+		snippetRoot = root
+		mem, msg, err := resolveLanguageMemory(ctx, t.workspaceManager, workspaceInfo, language)
+		if err != nil {
+			return "", err
+		}
+		if msg != "" {
+			return msg, nil
+		}
+		searchMemory = mem
+	}
+
+	result := StackTraceResolution{Language: language, Message: message}
+	resolvedCount := 0
+	for _, frame := range frames {
+		resolved := ResolvedFrame{Frame: frame}
+		path := resolveReportedPath(root, frame.File)
+		if path != frame.File {
+			resolved.ResolvedPath = path
+		}
+
+		if searchMemory != nil {
+			chunks, err := loadFileChunks(ctx, searchMemory, t.embedder, path, shortFunctionName(frame.Function))
+			if err != nil {
+				return "", err
+			}
+			if enclosing := enclosingChunk(chunks, frame.Line); enclosing != nil {
+				descs := buildSymbolDescriptorsFromDocs([]memory.Document{enclosing.doc})
+				resolved.Symbol = &descs[0]
+				resolved.Code = enclosing.chunk.Code
+			}
+		}
+
+		// Frames outside the workspace (stdlib, vendor) are kept for orientation
+		// but do not count towards the frames whose code is returned.
+		if resolved.Symbol == nil && resolved.ResolvedPath == "" && root != "" {
+			result.Frames = append(result.Frames, ResolvedFrame{Frame: frame})
+			continue
+		}
+
+		if resolvedCount >= maxFrames {
+			result.Omitted++
+			continue
+		}
+		resolved.Context = numberedSnippet(snippetRoot, path, frame.Line, contextLines)
+		result.Frames = append(result.Frames, resolved)
+		resolvedCount++
+	}
+
+	if workspaceInfo != nil && message != "" {
+		result.LogOrigins = matchLogOrigins(t.workspaceManager, workspaceInfo, message, 5)
+	}
+
+	if outputFormat == formatMinimal {
+		return formatStackTraceResolutionMinimal(result), nil
+	}
+	if outputFormat == "markdown" {
+		return formatStackTraceResolution(result, t.workspaceManager.CodeFences()), nil
+	}
+
+	data, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal resolve_stack_trace results: %w", err)
+	}
+	return string(data), nil
+}
+
+func formatStackTraceResolution(res StackTraceResolution, fences string) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("# 🧵 %s stack trace\n\n", res.Language))
+	if res.Message != "" {
+		sb.WriteString(fmt.Sprintf("**Error:** %s\n\n", res.Message))
+	}
+
+	for i, f := range res.Frames {
+		loc := fmt.Sprintf("%s:%d", f.Frame.File, f.Frame.Line)
+		if f.ResolvedPath != "" {
+			loc = fmt.Sprintf("%s:%d", f.ResolvedPath, f.Frame.Line)
+		}
+		sb.WriteString(fmt.Sprintf("## #%d `%s` at `%s`\n\n", i, f.Frame.Function, loc))
+
+		if f.Symbol != nil {
+			sb.WriteString(fmt.Sprintf("**Symbol:** `%s` (%s) lines %d-%d\n",
+				f.Symbol.Name, f.Symbol.Kind, f.Symbol.Location.StartLine, f.Symbol.Location.EndLine))
+		}
+		if f.Context != "" {
+			sb.WriteString("\n" + codeBlock(fences, res.Language, f.ResolvedPath, f.Context))
+		}
+		sb.WriteString("\n")
+	}
+
+	if res.Omitted > 0 {
+		sb.WriteString(fmt.Sprintf("... %d more workspace frame(s) omitted\n", res.Omitted))
+	}
+
+	if len(res.LogOrigins) > 0 {
+		sb.WriteString("\n## 🔎 Message origins\n\n")
+		for _, m := range res.LogOrigins {
+			sb.WriteString(fmt.Sprintf("- `%s` at `%s:%d`: %q\n", m.Template.Call, m.Template.FilePath, m.Template.Line, m.Template.Format))
+		}
+	}
+	return sb.String()
+}
+
+// formatStackTraceResolutionMinimal renders the error message, then one line
+// per frame and per message origin.
+func formatStackTraceResolutionMinimal(res StackTraceResolution) string {
+	var sb strings.Builder
+	if res.Message != "" {
+		sb.WriteString(minimalLine("error", "", "", 0, 0, res.Message) + "\n")
+	}
+	for _, f := range res.Frames {
+		file := f.Frame.File
+		if f.ResolvedPath != "" {
+			file = f.ResolvedPath
+		}
+		kind, name := "frame", f.Frame.Function
+		if f.Symbol != nil {
+			kind, name = f.Symbol.Kind, f.Symbol.Name
+		}
+		sb.WriteString(minimalLine(kind, name, file, f.Frame.Line, 0, f.Code) + "\n")
+	}
+	for _, m := range res.LogOrigins {
+		sb.WriteString(minimalLine("origin", m.Template.Call, m.Template.FilePath, m.Template.Line, 0, m.Template.Format) + "\n")
+	}
+	return sb.String()
+}
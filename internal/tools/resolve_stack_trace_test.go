@@ -0,0 +1,123 @@
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseStackTrace_Go(t *testing.T) {
+	trace := `panic: runtime error: invalid memory address or nil pointer dereference
+[signal SIGSEGV: segmentation violation code=0x1 addr=0x0 pc=0x47b7a3]
+
+goroutine 1 [running]:
+github.com/acme/app/internal/store.(*DB).Get(0x0, {0x4b1e2c, 0x3})
+	/go/src/app/internal/store/db.go:42 +0x23
+main.main()
+	/go/src/app/main.go:12 +0x1d
+exit status 2`
+
+	lang, msg, frames := parseStackTrace(trace)
+	if lang != "go" {
+		t.Fatalf("language = %q, want go", lang)
+	}
+	if msg == "" || msg[:6] != "panic:" {
+		t.Errorf("unexpected message %q", msg)
+	}
+	if len(frames) != 2 {
+		t.Fatalf("len(frames) = %d, want 2: %+v", len(frames), frames)
+	}
+	if frames[0].Function != "github.com/acme/app/internal/store.(*DB).Get" || frames[0].Line != 42 {
+		t.Errorf("unexpected top frame: %+v", frames[0])
+	}
+	if got := shortFunctionName(frames[0].Function); got != "Get" {
+		t.Errorf("shortFunctionName = %q, want Get", got)
+	}
+}
+
+func TestParseStackTrace_PHP(t *testing.T) {
+	trace := `PHP Fatal error:  Uncaught RuntimeException: boom in /var/www/app/Services/Billing.php:27
+Stack trace:
+#0 /var/www/app/Http/Controllers/PayController.php(18): App\Services\Billing->charge()
+#1 /var/www/public/index.php(5): App\Http\Controllers\PayController->store()
+#2 {main}
+  thrown in /var/www/app/Services/Billing.php on line 27`
+
+	lang, msg, frames := parseStackTrace(trace)
+	if lang != "php" {
+		t.Fatalf("language = %q, want php", lang)
+	}
+	if msg != "RuntimeException: boom" {
+		t.Errorf("message = %q", msg)
+	}
+	if len(frames) != 3 {
+		t.Fatalf("len(frames) = %d, want 3: %+v", len(frames), frames)
+	}
+	if frames[0].Function != `App\Services\Billing->charge` || frames[0].Line != 27 {
+		t.Errorf("unexpected origin frame: %+v", frames[0])
+	}
+	if frames[1].Function != `App\Http\Controllers\PayController->store` || frames[1].Line != 18 {
+		t.Errorf("unexpected caller frame: %+v", frames[1])
+	}
+}
+
+func TestParseStackTrace_Python(t *testing.T) {
+	trace := `Traceback (most recent call last):
+  File "/srv/app/main.py", line 10, in <module>
+    run()
+  File "/srv/app/jobs/worker.py", line 3, in run
+    raise ValueError("bad")
+ValueError: bad`
+
+	lang, msg, frames := parseStackTrace(trace)
+	if lang != "python" || msg != "ValueError: bad" {
+		t.Fatalf("lang=%q msg=%q", lang, msg)
+	}
+	if len(frames) != 2 || frames[0].Function != "run" || frames[0].File != "/srv/app/jobs/worker.py" {
+		t.Errorf("unexpected frames: %+v", frames)
+	}
+}
+
+func TestResolveReportedPath_SuffixMatch(t *testing.T) {
+	root := t.TempDir()
+	target := filepath.Join(root, "jobs", "worker.py")
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(target, []byte("x = 1\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := resolveReportedPath(root, "/srv/app/jobs/worker.py"); got != target {
+		t.Errorf("resolveReportedPath = %q, want %q", got, target)
+	}
+	if got := resolveReportedPath(root, "/srv/app/jobs/missing.py"); got != "/srv/app/jobs/missing.py" {
+		t.Errorf("expected unresolved path to be returned unchanged, got %q", got)
+	}
+}
+
+func TestResolveReportedPath_StaysInRoot(t *testing.T) {
+	base := t.TempDir()
+	root := filepath.Join(base, "ws")
+	secret := filepath.Join(base, "secret.txt")
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(secret, []byte("token\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(secret, filepath.Join(root, "link.txt")); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, file := range []string{secret, "../secret.txt", "link.txt"} {
+		if path := resolveReportedPath(root, file); path != file {
+			t.Errorf("resolveReportedPath(%q) = %q, want it left unresolved", file, path)
+		}
+	}
+	for _, path := range []string{secret, filepath.Join(root, "..", "secret.txt"), filepath.Join(root, "link.txt")} {
+		if snippet := numberedSnippet(root, path, 1, 3); snippet != "" {
+			t.Errorf("numberedSnippet read %s outside the workspace: %q", path, snippet)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/doITmagic/rag-code-mcp/internal/workspace"
+)
+
+// RollbackChangeTool undoes patches applied with apply_patch, using the change
+// journal kept in .ragcode/backups, and re-indexes the restored files.
+type RollbackChangeTool struct {
+	workspaceManager *workspace.Manager
+}
+
+// NewRollbackChangeTool creates a new rollback_change tool
+func NewRollbackChangeTool(wm *workspace.Manager) *RollbackChangeTool {
+	return &RollbackChangeTool{
+		workspaceManager: wm,
+	}
+}
+
+func (t *RollbackChangeTool) Name() string {
+	return "rollback_change"
+}
+
+func (t *RollbackChangeTool) Description() string {
+	return "Undo a patch applied with apply_patch: restores the previous file contents (removing files the patch created) and re-indexes them. Without change_id the most recent change is rolled back. Refuses when the files were edited after the patch unless force=true. Use list=true to see the change journal."
+}
+
+func (t *RollbackChangeTool) Execute(ctx context.Context, params map[string]interface{}) (string, error) {
+	if t.workspaceManager == nil {
+		return "", fmt.Errorf("workspace manager not configured")
+	}
+	if !t.workspaceManager.Edits().Enabled {
+		return "", fmt.Errorf("rollback_change is disabled: set edits.enabled in the config (or EDITS_ENABLED=true) to allow file changes")
+	}
+	if extractFilePathFromParams(params) == "" {
+		return "", fmt.Errorf("file_path parameter is required for rollback_change. Please provide a file path from your workspace")
+	}
+	info, err := t.workspaceManager.DetectWorkspace(params)
+	if err != nil {
+		return "", fmt.Errorf("failed to detect workspace: %w", err)
+	}
+	jsonOutput := outputFormatFrom(params, formatMarkdown) == formatJSON
+
+	if list, _ := params["list"].(bool); list {
+		changes, err := workspace.ListChanges(info.Root)
+		if err != nil {
+			return "", fmt.Errorf("failed to read change journal: %w", err)
+		}
+		if jsonOutput {
+			return marshalRollback(changes)
+		}
+		return formatChangeJournal(changes), nil
+	}
+
+	id, _ := params["change_id"].(string)
+	force, _ := params["force"].(bool)
+	change, restored, err := workspace.RollbackChange(info.Root, id, force)
+	if len(restored) > 0 {
+		t.workspaceManager.ReindexFiles(info, restored)
+	}
+	if err != nil {
+		return "", err
+	}
+	if jsonOutput {
+		return marshalRollback(change)
+	}
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("↩️ Rolled back change %s (applied %s):\n\n", change.ID, change.AppliedAt.Format("2006-01-02 15:04:05")))
+	for _, f := range change.Files {
+		sb.WriteString(fmt.Sprintf("- undo %s %s\n", f.Action, f.Path))
+	}
+	sb.WriteString("\n♻️ Restored files are being re-indexed.\n")
+	return sb.String(), nil
+}
+
+func marshalRollback(v interface{}) (string, error) {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal rollback_change result: %w", err)
+	}
+	return string(data), nil
+}
+
+func formatChangeJournal(changes []workspace.Change) string {
+	if len(changes) == 0 {
+		return "No changes have been applied with apply_patch in this workspace."
+	}
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("📜 %d change(s), newest first:\n\n", len(changes)))
+	for _, c := range changes {
+		paths := make([]string, 0, len(c.Files))
+		for _, f := range c.Files {
+			paths = append(paths, f.Path)
+		}
+		status := ""
+		if c.RolledBackAt != nil {
+			status = fmt.Sprintf(" (rolled back %s)", c.RolledBackAt.Format("2006-01-02 15:04:05"))
+		}
+		sb.WriteString(fmt.Sprintf("- %s%s: %s\n", c.ID, status, strings.Join(paths, ", ")))
+	}
+	return sb.String()
+}
@@ -0,0 +1,90 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/doITmagic/rag-code-mcp/internal/workspace"
+)
+
+// searchDeadline tracks whether a search call ran out of time
+// (queries.timeout). A search that hits the deadline keeps the results it
+// gathered and marks the call instead of failing it.
+type searchDeadline struct {
+	timeout  time.Duration
+	timedOut bool
+	format   string
+}
+
+type searchDeadlineKey struct{}
+
+// withSearchDeadline bounds ctx by the timeout of tool, when one is set
+func withSearchDeadline(ctx context.Context, wm *workspace.Manager, tool string) (context.Context, context.CancelFunc) {
+	timeout := wm.SearchTimeout(tool)
+	if timeout <= 0 {
+		return ctx, func() {}
+	}
+	ctx = context.WithValue(ctx, searchDeadlineKey{}, &searchDeadline{timeout: timeout})
+	return context.WithTimeout(ctx, timeout)
+}
+
+// deadlineExceeded reports whether err comes from the search deadline of ctx
+// expiring. Backends wrap the context error differently, so the context
+// itself is checked.
+func deadlineExceeded(ctx context.Context, err error) bool {
+	return err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded)
+}
+
+// markTimedOut records that the search returns partial results in
+// outputFormat because its deadline expired
+func markTimedOut(ctx context.Context, outputFormat string) {
+	if d, ok := ctx.Value(searchDeadlineKey{}).(*searchDeadline); ok {
+		d.timedOut = true
+		d.format = outputFormat
+	}
+}
+
+// searchTimedOut returns the deadline of a search that ran out of time, nil
+// otherwise
+func searchTimedOut(ctx context.Context) *searchDeadline {
+	if d, ok := ctx.Value(searchDeadlineKey{}).(*searchDeadline); ok && d.timedOut {
+		return d
+	}
+	return nil
+}
+
+// flag adds timed_out: true to a result: as a field of JSON objects (arrays
+// become {"timed_out": true, "results": [...]}), a trailing line of minimal
+// output and a notice above markdown.
+func (d *searchDeadline) flag(result string) string {
+	switch d.format {
+	case formatJSON:
+		var fields map[string]json.RawMessage
+		trimmed := strings.TrimSpace(result)
+		switch {
+		case strings.HasPrefix(trimmed, "["):
+			fields = map[string]json.RawMessage{"results": json.RawMessage(trimmed)}
+		case json.Unmarshal([]byte(trimmed), &fields) == nil:
+		default:
+			fields = map[string]json.RawMessage{"results": json.RawMessage("[]")}
+			message, _ := json.Marshal(trimmed)
+			fields["message"] = message
+		}
+		fields["timed_out"] = json.RawMessage("true")
+		data, err := json.MarshalIndent(fields, "", "  ")
+		if err != nil {
+			return result
+		}
+		return string(data)
+	case formatMinimal:
+		if result != "" && !strings.HasSuffix(result, "\n") {
+			result += "\n"
+		}
+		return result + "timed_out: true\n"
+	}
+	return fmt.Sprintf("⏱️ Search timed out after %s (timed_out: true): showing the results gathered so far.\n\n", d.timeout) + result
+}
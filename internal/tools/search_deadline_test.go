@@ -0,0 +1,76 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/doITmagic/rag-code-mcp/internal/config"
+	"github.com/doITmagic/rag-code-mcp/internal/workspace"
+)
+
+func TestCachedSearchDeadline(t *testing.T) {
+	cfg := &config.Config{Queries: config.QueriesConfig{
+		Timeout:      time.Hour,
+		ToolTimeouts: map[string]time.Duration{"search_code": 20 * time.Millisecond},
+	}}
+	wm := workspace.NewManager(nil, nil, cfg)
+
+	// A slow backend: the search keeps what it found when the deadline expires
+	out, err := cachedSearch(context.Background(), wm, "search_code", map[string]interface{}{}, func(ctx context.Context) (string, error) {
+		<-ctx.Done()
+		if deadlineExceeded(ctx, ctx.Err()) {
+			markTimedOut(ctx, formatJSON)
+		}
+		return `[{"name": "Foo"}]`, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var partial struct {
+		TimedOut bool              `json:"timed_out"`
+		Results  []json.RawMessage `json:"results"`
+	}
+	if err := json.Unmarshal([]byte(out), &partial); err != nil {
+		t.Fatalf("unmarshal %q: %v", out, err)
+	}
+	if !partial.TimedOut || len(partial.Results) != 1 {
+		t.Fatalf("partial results = %s", out)
+	}
+
+	// Searches finishing in time are not flagged
+	out, err = cachedSearch(context.Background(), wm, "hybrid_search", map[string]interface{}{}, func(ctx context.Context) (string, error) {
+		if _, ok := ctx.Deadline(); !ok {
+			t.Error("hybrid_search has no deadline")
+		}
+		return "[]", nil
+	})
+	if err != nil || out != "[]" {
+		t.Fatalf("search in time = %q, %v", out, err)
+	}
+}
+
+func TestSearchDeadlineFlag(t *testing.T) {
+	d := &searchDeadline{timeout: 5 * time.Second, timedOut: true}
+
+	d.format = formatJSON
+	var paged map[string]interface{}
+	if err := json.Unmarshal([]byte(d.flag(`{"results": [], "next_cursor": "abc"}`)), &paged); err != nil {
+		t.Fatal(err)
+	}
+	if paged["timed_out"] != true || paged["next_cursor"] != "abc" {
+		t.Fatalf("flagged object = %v", paged)
+	}
+
+	d.format = formatMinimal
+	if out := d.flag("function Foo a.go:1"); out != "function Foo a.go:1\ntimed_out: true\n" {
+		t.Fatalf("flagged minimal output = %q", out)
+	}
+
+	d.format = formatMarkdown
+	if out := d.flag("No relevant code found."); !strings.HasPrefix(out, "⏱️ Search timed out after 5s (timed_out: true)") {
+		t.Fatalf("flagged markdown = %q", out)
+	}
+}
@@ -3,6 +3,9 @@ package tools
 import (
 	"context"
 	"fmt"
+	"log"
+	"sort"
+	"strings"
 
 	"github.com/doITmagic/rag-code-mcp/internal/llm"
 	"github.com/doITmagic/rag-code-mcp/internal/memory"
@@ -16,6 +19,13 @@ type SearchDocsTool struct {
 	workspaceManager *workspace.Manager
 }
 
+// docTarget is a collection search_docs queries
+type docTarget struct {
+	name   string
+	origin string // "" for the workspace collection, else a workspace.DocOrigin*
+	mem    memory.LongTermMemory
+}
+
 // NewSearchDocsTool creates a new search docs tool
 func NewSearchDocsTool(ltm memory.LongTermMemory, embedder llm.Provider) *SearchDocsTool {
 	return &SearchDocsTool{
@@ -27,6 +37,7 @@ func NewSearchDocsTool(ltm memory.LongTermMemory, embedder llm.Provider) *Search
 // SetWorkspaceManager sets the workspace manager for workspace-aware searching
 func (t *SearchDocsTool) SetWorkspaceManager(wm *workspace.Manager) {
 	t.workspaceManager = wm
+	wm.RegisterQueryPrimer(t.Name(), queryPrimer(t.execute))
 }
 
 // Name returns the tool name
@@ -36,19 +47,35 @@ func (t *SearchDocsTool) Name() string {
 
 // Description returns the tool description
 func (t *SearchDocsTool) Description() string {
-	return "Search project documentation (README, guides, API docs) - use when you need to understand project setup, architecture decisions, or usage examples. Returns relevant documentation snippets with file paths. Searches Markdown files ONLY, not code - use search_code for code."
+	return "Search project documentation (README, guides, API docs) - use when you need to understand project setup, architecture decisions, or usage examples. Returns relevant documentation snippets with file paths. Searches Markdown files ONLY, not code - use search_code for code. Filter by language with lang (e.g. 'en', 'zh') and by tags, from config tag rules or the front matter of the docs, with tags (e.g. 'payment'). Searches the workspace docs together with the shared docs and API docs collections, each result labelled with its source; narrow with sources ('readme', 'docs', 'api', 'external')."
 }
 
 // Execute executes a search in the docs index
 func (t *SearchDocsTool) Execute(ctx context.Context, params map[string]interface{}) (string, error) {
+	return cachedSearch(ctx, t.workspaceManager, t.Name(), params, func(ctx context.Context) (string, error) {
+		return t.execute(ctx, params)
+	})
+}
+
+func (t *SearchDocsTool) execute(ctx context.Context, params map[string]interface{}) (string, error) {
 	// file_path is required for workspace detection
 	filePath := extractFilePathFromParams(params)
 	if filePath == "" {
 		return "", fmt.Errorf("file_path parameter is required for search_docs. Please provide a file path from your workspace")
 	}
 
-	// Try workspace detection
-	var searchMemory memory.LongTermMemory
+	sources := parseListParam(params["sources"])
+	for _, source := range sources {
+		if !wantsDocSource([]string{source}, workspace.DocSources...) {
+			return "", fmt.Errorf("unknown source '%s': use %s", source, strings.Join(workspace.DocSources, ", "))
+		}
+	}
+
+	// The Markdown chunks of the workspace and the shared doc collections
+	// are searched together; notice explains a workspace collection that
+	// cannot be searched yet
+	var targets []docTarget
+	var notice string
 	var workspacePath string
 	var collectionName string
 
@@ -72,33 +99,47 @@ func (t *SearchDocsTool) Execute(ctx context.Context, params map[string]interfac
 				// Check if indexing is in progress
 				indexKey := workspaceInfo.ID + "-" + language
 				if t.workspaceManager.IsIndexing(indexKey) {
-					return fmt.Sprintf("⏳ Workspace '%s' language '%s' is currently being indexed in the background.\n"+
+					notice = fmt.Sprintf("⏳ Workspace '%s' language '%s' is currently being indexed in the background.\n"+
 						"Please try again in a few moments.\n"+
 						"Workspace: %s\n"+
 						"Language: %s\n"+
 						"Collection: %s",
-						workspaceInfo.Root, language, workspaceInfo.Root, language, collectionName), nil
-				}
-
-				// Check if collection exists before proceeding
-				if msg, err := CheckCollectionStatus(ctx, mem, collectionName, workspacePath); err != nil || msg != "" {
+						workspaceInfo.Root, language, workspaceInfo.Root, language, collectionName)
+				} else if msg, err := CheckCollectionStatus(ctx, mem, collectionName, workspacePath); err != nil || msg != "" {
+					// Check if collection exists before proceeding
 					if err != nil {
 						return "", err
 					}
-					return msg, nil
+					notice = msg
+				} else if wantsDocSource(sources, workspace.DocSourceReadme, workspace.DocSourceDocs) {
+					targets = append(targets, docTarget{name: collectionName, mem: mem})
 				}
+			}
+		}
 
-				searchMemory = mem
+		for _, c := range t.workspaceManager.DocCollections(ctx) {
+			wanted := wantsDocSource(sources, workspace.DocSourceAPI)
+			if c.Origin == workspace.DocOriginDocs {
+				wanted = wantsDocSource(sources, workspace.DocSourceReadme, workspace.DocSourceDocs, workspace.DocSourceExternal)
+			}
+			if wanted {
+				targets = append(targets, docTarget{name: c.Name, origin: c.Origin, mem: c.Memory})
 			}
 		}
 	}
 
-	// Use workspace-specific memory or fallback to default
-	if searchMemory == nil {
-		searchMemory = t.longTermMemory
+	// Fallback to the default docs memory
+	if len(targets) == 0 && notice == "" && t.longTermMemory != nil {
+		targets = append(targets, docTarget{name: workspace.DocOriginDocs, origin: workspace.DocOriginDocs, mem: t.longTermMemory})
 	}
 
-	if searchMemory == nil {
+	if len(targets) == 0 {
+		switch {
+		case notice != "":
+			return notice, nil
+		case len(sources) > 0 && workspacePath != "":
+			return fmt.Sprintf("No documentation collection holds source(s) '%s'. Set docs.collection or api_docs.collection in config.yaml and rebuild the docs index.", strings.Join(sources, ", ")), nil
+		}
 		return "Documentation search is not configured. Set docs.collection in config.yaml and rebuild the docs index.", nil
 	}
 
@@ -110,51 +151,292 @@ func (t *SearchDocsTool) Execute(ctx context.Context, params map[string]interfac
 	if !ok {
 		return "", fmt.Errorf("query parameter is required")
 	}
+	query, resolved := applyConversationHint(params, query)
+	query, glossary := applyGlossary(t.workspaceManager, params, query)
+
+	budget := budgetFor(ctx, t.workspaceManager, params)
+	limit := budget.limit(params)
 
-	limit := 5
-	if l, ok := params["limit"].(float64); ok {
-		limit = int(l)
-	} else if l, ok := params["limit"].(int); ok {
-		limit = l
+	langs := parseListParam(params["lang"])
+	tags := parseListParam(params["tags"])
+	preferred := t.workspaceManager.DocLanguages()
+
+	// Over-fetch when results are filtered by tags, by language or by
+	// source, or re-ordered by language
+	fetchLimit := limit
+	if len(langs) > 0 || len(preferred) > 0 || len(tags) > 0 || len(sources) > 0 {
+		fetchLimit = limit * 4
 	}
 
 	// Generate embedding for query
-	queryEmbedding, err := t.embedder.Embed(ctx, query)
+	queryEmbedding, err := embedQuery(ctx, t.workspaceManager, params, t.embedder, query)
+	if deadlineExceeded(ctx, err) {
+		return t.timedOut(ctx, params), nil
+	}
 	if err != nil {
 		return "", fmt.Errorf("failed to generate query embedding: %w", err)
 	}
 
-	docs, err := searchMemory.Search(ctx, queryEmbedding, limit)
-	if err != nil {
-		return "", fmt.Errorf("search failed: %w", err)
+	var found [][]memory.Document
+	for _, target := range targets {
+		var docs []memory.Document
+		if target.origin == "" {
+			// The workspace collection holds code too
+			docs, err = target.mem.Query(ctx, memory.SearchOptions{Vector: queryEmbedding, Filter: memory.DocsOnly(), Limit: fetchLimit})
+		} else {
+			docs, err = target.mem.Search(ctx, queryEmbedding, fetchLimit)
+		}
+		if deadlineExceeded(ctx, err) {
+			return t.timedOut(ctx, params), nil
+		}
+		if err != nil {
+			if len(targets) == 1 {
+				return "", fmt.Errorf("search failed: %w", err)
+			}
+			log.Printf("⚠️  search_docs: collection '%s': %v", target.name, err)
+			continue
+		}
+		for i := range docs {
+			// Copied, as a store may hand out the metadata it holds
+			metadata := make(map[string]interface{}, len(docs[i].Metadata)+2)
+			for k, v := range docs[i].Metadata {
+				metadata[k] = v
+			}
+			docs[i].Metadata = metadata
+			docs[i].Metadata["doc_source"] = workspace.DocSource(workspacePath, target.origin, docs[i])
+			docs[i].Metadata["collection"] = target.name
+		}
+		found = append(found, docs)
 	}
+	docs := filterDocSources(mergeDocResults(found), sources)
 
+	if notice != "" {
+		notice += "\n\n"
+	}
 	if len(docs) == 0 {
+		if len(sources) > 0 {
+			return notice + fmt.Sprintf("No relevant documentation found in source(s) '%s'.", strings.Join(sources, ", ")), nil
+		}
 		// Check if this is a workspace search with empty collection
 		if workspacePath != "" && collectionName != "" {
 			if msg, err := CheckSearchResults(0, collectionName, workspacePath); err != nil || msg != "" {
 				if err != nil {
 					return "", err
 				}
-				return msg, nil
+				return notice + msg, nil
 			}
-			return fmt.Sprintf("No relevant documentation found in workspace '%s'.", workspacePath), nil
+			return notice + fmt.Sprintf("No relevant documentation found in workspace '%s'.", workspacePath), nil
 		}
-		return "No relevant documentation found.", nil
+		return notice + "No relevant documentation found.", nil
+	}
+	docs = rankerFor(t.workspaceManager).near(workspacePath, filePath).rankDocs(query, docs)
+	docs = applyDocLanguages(docs, langs, preferred)
+	if len(docs) == 0 {
+		return notice + fmt.Sprintf("No relevant documentation found in language(s) '%s'.", strings.Join(langs, ", ")), nil
+	}
+	if docs = filterByTags(docs, tags); len(docs) == 0 {
+		return notice + fmt.Sprintf("No relevant documentation tagged '%s'.", strings.Join(tags, ", ")), nil
+	}
+	if len(docs) > limit {
+		docs = docs[:limit]
+	}
+	docs = budget.clip(docs)
+
+	if outputFormatFrom(params, formatMarkdown) == formatMinimal {
+		return formatMinimalDescriptors(buildSymbolDescriptorsFromDocs(docs)), nil
 	}
 
 	if workspacePath != "" {
-		result := fmt.Sprintf("🔍 Found %d relevant documentation snippets in workspace '%s':\n\n", len(docs), workspacePath)
+		result := notice + formatConversationTerms(resolved) + formatGlossaryEntries(glossary) + fmt.Sprintf("🔍 Found %d relevant documentation snippets in workspace '%s':\n\n", len(docs), workspacePath)
 		for i, doc := range docs {
-			result += fmt.Sprintf("--- Result %d ---\n%s\n\n", i+1, doc.Content)
+			result += fmt.Sprintf("--- Result %d%s%s%s%s%s ---\n%s%s\n\n", i+1, docTitleLabel(doc), chunkIDLabel(doc), docSourceLabel(doc), docLanguageLabel(doc), tagsLabel(doc), docHeadingsLine(doc), doc.Content)
 		}
 		return result, nil
 	}
 
-	result := fmt.Sprintf("Found %d relevant documentation snippets:\n\n", len(docs))
+	result := notice + formatConversationTerms(resolved) + formatGlossaryEntries(glossary) + fmt.Sprintf("Found %d relevant documentation snippets:\n\n", len(docs))
 	for i, doc := range docs {
-		result += fmt.Sprintf("--- Result %d ---\n%s\n\n", i+1, doc.Content)
+		result += fmt.Sprintf("--- Result %d%s%s%s%s%s ---\n%s%s\n\n", i+1, docTitleLabel(doc), chunkIDLabel(doc), docSourceLabel(doc), docLanguageLabel(doc), tagsLabel(doc), docHeadingsLine(doc), doc.Content)
 	}
 
 	return result, nil
 }
+
+// timedOut is the result of a search whose deadline expired before any
+// documentation was found
+func (t *SearchDocsTool) timedOut(ctx context.Context, params map[string]interface{}) string {
+	outputFormat := formatMarkdown
+	if outputFormatFrom(params, formatMarkdown) == formatMinimal {
+		outputFormat = formatMinimal
+	}
+	markTimedOut(ctx, outputFormat)
+	return "No relevant documentation found."
+}
+
+// applyDocLanguages keeps only docs in langs when given. Otherwise docs in a
+// preferred language come first, then docs without a detected language, then
+// the rest; the order within each group is kept.
+func applyDocLanguages(docs []memory.Document, langs, preferred []string) []memory.Document {
+	docLang := func(doc memory.Document) string {
+		lang, _ := doc.Metadata["lang"].(string)
+		return lang
+	}
+	contains := func(list []string, lang string) bool {
+		for _, l := range list {
+			if l == lang {
+				return true
+			}
+		}
+		return false
+	}
+
+	if len(langs) > 0 {
+		out := make([]memory.Document, 0, len(docs))
+		for _, doc := range docs {
+			if contains(langs, docLang(doc)) {
+				out = append(out, doc)
+			}
+		}
+		return out
+	}
+	if len(preferred) == 0 {
+		return docs
+	}
+
+	group := func(doc memory.Document) int {
+		switch lang := docLang(doc); {
+		case contains(preferred, lang):
+			return 0
+		case lang == "":
+			return 1
+		default:
+			return 2
+		}
+	}
+	out := append([]memory.Document(nil), docs...)
+	sort.SliceStable(out, func(i, j int) bool {
+		return group(out[i]) < group(out[j])
+	})
+	return out
+}
+
+// wantsDocSource reports whether the sources filter keeps any of candidates;
+// an empty filter keeps every source
+func wantsDocSource(sources []string, candidates ...string) bool {
+	if len(sources) == 0 {
+		return true
+	}
+	for _, source := range sources {
+		for _, c := range candidates {
+			if source == c {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// filterDocSources keeps the docs whose doc_source is in sources, all of
+// them when sources is empty
+func filterDocSources(docs []memory.Document, sources []string) []memory.Document {
+	if len(sources) == 0 {
+		return docs
+	}
+	out := make([]memory.Document, 0, len(docs))
+	for _, doc := range docs {
+		if source, _ := doc.Metadata["doc_source"].(string); wantsDocSource(sources, source) {
+			out = append(out, doc)
+		}
+	}
+	return out
+}
+
+// mergeDocResults merges the results of several collections by score. A
+// chunk found in more than one, e.g. a README indexed both with the
+// workspace and by index-all, is kept once, with the best score, and lists
+// every collection holding it in "collections".
+func mergeDocResults(results [][]memory.Document) []memory.Document {
+	var all []memory.Document
+	for _, docs := range results {
+		all = append(all, docs...)
+	}
+	if len(results) > 1 {
+		sort.SliceStable(all, func(i, j int) bool {
+			return getFloat(all[i].Metadata["score"]) > getFloat(all[j].Metadata["score"])
+		})
+	}
+
+	out := make([]memory.Document, 0, len(all))
+	index := make(map[string]int, len(all))
+	for _, doc := range all {
+		key := doc.Content
+		if file, _ := doc.Metadata["file"].(string); file != "" {
+			key = fmt.Sprintf("%s:%v", file, doc.Metadata["start_line"])
+		}
+		i, dup := index[key]
+		if !dup {
+			index[key] = len(out)
+			out = append(out, doc)
+			continue
+		}
+		name, _ := doc.Metadata["collection"].(string)
+		kept, _ := out[i].Metadata["collections"].(string)
+		if kept == "" {
+			kept, _ = out[i].Metadata["collection"].(string)
+		}
+		if name != "" && !wantsDocSource(strings.Split(kept, ", "), name) {
+			out[i].Metadata["collections"] = kept + ", " + name
+		}
+	}
+	return out
+}
+
+// docSourceLabel renders the provenance of a doc for the header of a
+// markdown result, e.g. " [readme · ragcode-docs]"
+func docSourceLabel(doc memory.Document) string {
+	source, _ := doc.Metadata["doc_source"].(string)
+	collections, _ := doc.Metadata["collections"].(string)
+	if collections == "" {
+		collections, _ = doc.Metadata["collection"].(string)
+	}
+	switch {
+	case source == "":
+		return ""
+	case collections == "":
+		return fmt.Sprintf(" [%s]", source)
+	}
+	return fmt.Sprintf(" [%s · %s]", source, collections)
+}
+
+// docTitleLabel renders the front matter title of a doc for the header of
+// a markdown result
+func docTitleLabel(doc memory.Document) string {
+	if title, ok := doc.Metadata["title"].(string); ok && title != "" {
+		return ": " + title
+	}
+	return ""
+}
+
+// docHeadingsLine renders the other front matter fields of a doc chunk, e.g.
+// "🏷️ owners: alice", and the headings enclosing it, e.g. "📑 Install >
+// Docker", as lines of markdown output
+func docHeadingsLine(doc memory.Document) string {
+	var out string
+	if fields, ok := doc.Metadata["front_matter"].(string); ok && fields != "" {
+		for _, line := range strings.Split(fields, "\n") {
+			out += fmt.Sprintf("🏷️ %s\n", line)
+		}
+	}
+	if headings, ok := doc.Metadata["headings"].(string); ok && headings != "" {
+		out += fmt.Sprintf("📑 %s\n", headings)
+	}
+	return out
+}
+
+// docLanguageLabel renders the detected language of a doc for markdown output.
+func docLanguageLabel(doc memory.Document) string {
+	if lang, ok := doc.Metadata["lang"].(string); ok && lang != "" {
+		return fmt.Sprintf(" [lang %s]", lang)
+	}
+	return ""
+}
@@ -34,6 +34,7 @@ func NewSearchLocalIndexTool(ltm memory.LongTermMemory, embedder llm.Provider, a
 // SetWorkspaceManager sets the workspace manager for workspace-aware searching
 func (t *SearchLocalIndexTool) SetWorkspaceManager(wm *workspace.Manager) {
 	t.workspaceManager = wm
+	wm.RegisterQueryPrimer(t.Name(), queryPrimer(t.execute))
 }
 
 // Name returns the tool name
@@ -48,26 +49,41 @@ func (t *SearchLocalIndexTool) Description() string {
 
 // Execute executes a search in the local index
 func (t *SearchLocalIndexTool) Execute(ctx context.Context, params map[string]interface{}) (string, error) {
+	return cachedSearch(ctx, t.workspaceManager, t.Name(), params, func(ctx context.Context) (string, error) {
+		return t.execute(ctx, params)
+	})
+}
+
+func (t *SearchLocalIndexTool) execute(ctx context.Context, params map[string]interface{}) (string, error) {
+	if cursor := cursorFrom(params); cursor != "" {
+		return continueSearch(ctx, t.workspaceManager, t.Name(), params, cursor)
+	}
 	query, ok := params["query"].(string)
 	if !ok {
 		return "", fmt.Errorf("query parameter is required")
 	}
+	query, resolved := applyConversationHint(params, query)
+	query, glossary := applyGlossary(t.workspaceManager, params, query)
 
-	limit := 5
-	if l, ok := params["limit"].(float64); ok {
-		limit = int(l)
-	} else if l, ok := params["limit"].(int); ok {
-		limit = l
-	}
+	budget := budgetFor(ctx, t.workspaceManager, params)
+	limit := budget.limit(params)
 
-	// Optional output format: json (default) or markdown
-	outputFormat := "json"
-	if of, ok := params["output_format"].(string); ok && of != "" {
-		outputFormat = strings.ToLower(of)
+	outputFormat := outputFormatFrom(params, formatJSON)
+	groupBy, err := groupByFrom(params)
+	if err != nil {
+		return "", err
 	}
 
+	coverageOpts := parseCoverageOptions(params)
+	tags := parseListParam(params["tags"])
+	build := parseBuildFilter(params)
+
 	// Generate embedding for query
-	queryEmbedding, err := t.embedder.Embed(ctx, query)
+	queryEmbedding, err := embedQuery(ctx, t.workspaceManager, params, t.embedder, query)
+	if deadlineExceeded(ctx, err) {
+		markTimedOut(ctx, outputFormat)
+		return noCodeFound(outputFormat), nil
+	}
 	if err != nil {
 		return "", fmt.Errorf("failed to generate query embedding: %w", err)
 	}
@@ -167,20 +183,31 @@ func (t *SearchLocalIndexTool) Execute(ctx context.Context, params map[string]in
 			}
 		}
 
-		// Search in workspace-specific collection, preferring code-only search
-		// Try SearchCodeOnly first (excludes markdown), fall back to Search
-		var docs []memory.Document
-		var searchErr error
+		// Search in workspace-specific collection, excluding markdown
+		// Over-fetch when results are filtered or re-ordered by tags, build
+		// constraints, coverage or recency
+		fetchLimit := limit
+		pageSize := pageSizeFrom(params)
+		if pageSize > 0 {
+			fetchLimit = maxPagedResults
+		}
+		if preferRecent, _ := params["prefer_recent"].(bool); coverageOpts.active() || preferRecent || len(tags) > 0 || build.active() {
+			fetchLimit *= 4
+		}
 
-		// Type assertion to check if this memory supports code-only search
-		type CodeSearcher interface {
-			SearchCodeOnly(ctx context.Context, query []float64, limit int) ([]memory.Document, error)
+		docs, searchErr := workspaceMem.Query(ctx, memory.SearchOptions{Vector: queryEmbedding, Filter: memory.CodeOnly(), Limit: fetchLimit})
+		// Past the deadline only the client's unsaved buffers are searched
+		timedOut := deadlineExceeded(ctx, searchErr)
+		if timedOut {
+			markTimedOut(ctx, outputFormat)
+			docs, searchErr = nil, nil
 		}
 
-		if codeSearcher, ok := workspaceMem.(CodeSearcher); ok {
-			docs, searchErr = codeSearcher.SearchCodeOnly(ctx, queryEmbedding, limit)
-		} else {
-			docs, searchErr = workspaceMem.Search(ctx, queryEmbedding, limit)
+		if searchErr == nil {
+			docs = t.workspaceManager.ApplyOverlays(workspaceInfo, ClientSession(ctx), language, queryEmbedding, docs)
+		}
+		if timedOut && len(docs) == 0 {
+			return noCodeFound(outputFormat), nil
 		}
 
 		// If search succeeds but returns no results, check if collection is empty
@@ -204,11 +231,44 @@ func (t *SearchLocalIndexTool) Execute(ctx context.Context, params map[string]in
 		}
 
 		if searchErr == nil && len(docs) > 0 {
+			if docs = filterByTags(docs, tags); len(docs) == 0 {
+				return fmt.Sprintf("No results tagged '%s' in workspace '%s'.", strings.Join(tags, ", "), workspaceInfo.Root), nil
+			}
+			if docs = filterByBuild(docs, build); len(docs) == 0 {
+				return fmt.Sprintf("No results built for %s in workspace '%s'.", build, workspaceInfo.Root), nil
+			}
+			docs = rankerFor(t.workspaceManager).withParams(params).near(workspaceInfo.Root, filePath).rankDocs(query, docs)
+			if report, err := t.workspaceManager.Coverage(workspaceInfo); err == nil && report != nil {
+				docs = applyCoverage(docs, report, coverageOpts)
+				if len(docs) == 0 {
+					return fmt.Sprintf("No results with coverage at or below %.1f%% in workspace '%s'.", coverageOpts.maxCoverage, workspaceInfo.Root), nil
+				}
+			}
+			if pageSize > 0 {
+				page := firstPage(ctx, t.workspaceManager, workspaceInfo, t.Name(), docs, pageSize)
+				return formatPage(page, t.Name(), outputFormat, workspaceInfo.Root, formatConversationTerms(resolved)+formatGlossaryEntries(glossary))
+			}
+			if len(docs) > limit {
+				docs = docs[:limit]
+			}
+			docs = budget.clip(docs)
+
+			if by := grouping(groupBy, outputFormat, docs); by != groupNone {
+				body, err := formatGrouped(docs, by, outputFormat, workspaceInfo.Root, codeResultEntry)
+				if err != nil || outputFormat != formatMarkdown {
+					return body, err
+				}
+				return formatConversationTerms(resolved) + formatGlossaryEntries(glossary) + fmt.Sprintf("🔍 Found %d relevant code snippets in workspace '%s', grouped by %s:\n\n",
+					len(docs), workspaceInfo.Root, by) + body, nil
+			}
+			if outputFormat == formatMinimal {
+				return formatMinimalDescriptors(buildSymbolDescriptorsFromDocs(docs)), nil
+			}
 			if outputFormat == "markdown" {
-				result := fmt.Sprintf("🔍 Found %d relevant code snippets in workspace '%s':\n\n",
+				result := formatConversationTerms(resolved) + formatGlossaryEntries(glossary) + fmt.Sprintf("🔍 Found %d relevant code snippets in workspace '%s':\n\n",
 					len(docs), workspaceInfo.Root)
 				for i, doc := range docs {
-					result += fmt.Sprintf("--- Result %d ---\n%s\n\n", i+1, doc.Content)
+					result += codeResultEntry(i+1, doc)
 				}
 				return result, nil
 			}
@@ -234,26 +294,47 @@ func (t *SearchLocalIndexTool) Execute(ctx context.Context, params map[string]in
 		if remaining <= 0 {
 			break
 		}
-		docs, err := ltm.Search(ctx, queryEmbedding, remaining)
+		fetch := remaining
+		if len(tags) > 0 || build.active() {
+			fetch = remaining * 4
+		}
+		docs, err := ltm.Search(ctx, queryEmbedding, fetch)
+		if deadlineExceeded(ctx, err) {
+			// Keep the results of the memories searched so far
+			markTimedOut(ctx, outputFormat)
+			break
+		}
 		if err != nil {
 			return "", fmt.Errorf("search failed: %w", err)
 		}
+		docs = filterByBuild(filterByTags(docs, tags), build)
+		if len(docs) > remaining {
+			docs = docs[:remaining]
+		}
 		collected = append(collected, docs...)
 		remaining = limit - len(collected)
 	}
 
 	if len(collected) == 0 {
-		if outputFormat == "markdown" {
-			return "No relevant code found.", nil
-		}
-		// Empty JSON array to indicate no results in a structured way
-		return "[]", nil
+		return noCodeFound(outputFormat), nil
 	}
+	collected = rankerFor(t.workspaceManager).withParams(params).near("", filePath).rankDocs(query, collected)
+	collected = budget.clip(collected)
 
+	if by := grouping(groupBy, outputFormat, collected); by != groupNone {
+		body, err := formatGrouped(collected, by, outputFormat, "", codeResultEntry)
+		if err != nil || outputFormat != formatMarkdown {
+			return body, err
+		}
+		return formatConversationTerms(resolved) + formatGlossaryEntries(glossary) + fmt.Sprintf("Found %d relevant code snippets, grouped by %s:\n\n", len(collected), by) + body, nil
+	}
+	if outputFormat == formatMinimal {
+		return formatMinimalDescriptors(buildSymbolDescriptorsFromDocs(collected)), nil
+	}
 	if outputFormat == "markdown" {
-		result := fmt.Sprintf("Found %d relevant code snippets:\n\n", len(collected))
+		result := formatConversationTerms(resolved) + formatGlossaryEntries(glossary) + fmt.Sprintf("Found %d relevant code snippets:\n\n", len(collected))
 		for i, doc := range collected {
-			result += fmt.Sprintf("--- Result %d ---\n%s\n\n", i+1, doc.Content)
+			result += codeResultEntry(i+1, doc)
 		}
 		return result, nil
 	}
@@ -265,3 +346,17 @@ func (t *SearchLocalIndexTool) Execute(ctx context.Context, params map[string]in
 	}
 	return string(data), nil
 }
+
+// codeResultEntry renders result n of search_code markdown output
+func codeResultEntry(n int, doc memory.Document) string {
+	return fmt.Sprintf("--- Result %d%s%s%s%s ---\n%s\n\n", n, chunkIDLabel(doc)+truncatedLabel(doc), coverageLabel(doc), tagsLabel(doc), buildConstraintLabel(doc), doc.Content)
+}
+
+// noCodeFound is the result of a search without matches
+func noCodeFound(outputFormat string) string {
+	if outputFormat != formatJSON {
+		return "No relevant code found."
+	}
+	// Empty JSON array to indicate no results in a structured way
+	return "[]"
+}
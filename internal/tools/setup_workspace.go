@@ -0,0 +1,131 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/doITmagic/rag-code-mcp/internal/workspace"
+)
+
+// SetupWorkspaceTool recommends settings for a workspace on first use and
+// optionally writes a starter .ragcode.yaml
+type SetupWorkspaceTool struct {
+	workspaceManager *workspace.Manager
+}
+
+// NewSetupWorkspaceTool creates a new setup_workspace tool
+func NewSetupWorkspaceTool(wm *workspace.Manager) *SetupWorkspaceTool {
+	return &SetupWorkspaceTool{
+		workspaceManager: wm,
+	}
+}
+
+func (t *SetupWorkspaceTool) Name() string {
+	return "setup_workspace"
+}
+
+func (t *SetupWorkspaceTool) Description() string {
+	return "First-use wizard: inspect a workspace and recommend settings - languages to index, paths to exclude (from .gitignore), estimated index size and time, and an embedding model. With write=true, creates a starter .ragcode.yaml (never overwrites). Use before the first index_workspace of a large or unfamiliar project."
+}
+
+func (t *SetupWorkspaceTool) Execute(ctx context.Context, args map[string]interface{}) (string, error) {
+	if t.workspaceManager == nil {
+		return "", fmt.Errorf("workspace manager not configured")
+	}
+	if extractFilePathFromParams(args) == "" {
+		return "", fmt.Errorf("file_path parameter is required for setup_workspace. Please provide a file path from your workspace")
+	}
+	write, _ := args["write"].(bool)
+	outputFormat := outputFormatFrom(args, formatMarkdown)
+
+	info, err := t.workspaceManager.DetectWorkspace(args)
+	if err != nil {
+		return "", fmt.Errorf("failed to detect workspace: %w", err)
+	}
+	rec, err := t.workspaceManager.RecommendSetup(info)
+	if err != nil {
+		return "", err
+	}
+
+	written := false
+	if write {
+		if err := rec.WriteConfig(); err != nil {
+			return "", err
+		}
+		written = true
+	}
+
+	if outputFormat == formatJSON {
+		data, err := json.MarshalIndent(struct {
+			*workspace.SetupRecommendation
+			Written bool `json:"written"`
+		}{rec, written}, "", "  ")
+		if err != nil {
+			return "", fmt.Errorf("failed to marshal setup_workspace results: %w", err)
+		}
+		return string(data), nil
+	}
+	return formatSetupRecommendation(rec, written), nil
+}
+
+func formatSetupRecommendation(rec *workspace.SetupRecommendation, written bool) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("# 🧭 Setup for `%s`\n\n", rec.Root))
+
+	sb.WriteString("## Languages\n\n")
+	if len(rec.Languages) == 0 {
+		sb.WriteString("No supported source files found.\n")
+	}
+	for _, lang := range rec.Languages {
+		mark := "✅"
+		if !lang.Enable {
+			mark = "⏭️"
+		}
+		sb.WriteString(fmt.Sprintf("- %s **%s**: %d file(s)", mark, lang.Language, lang.Files))
+		if lang.Reason != "" {
+			sb.WriteString(" - " + lang.Reason)
+		}
+		sb.WriteString("\n")
+	}
+
+	if len(rec.Exclude) > 0 {
+		sb.WriteString("\n## Suggested excludes (from .gitignore)\n\n")
+		for _, p := range rec.Exclude {
+			sb.WriteString(fmt.Sprintf("- `%s`\n", p))
+		}
+	}
+	if len(rec.Skipped) > 0 {
+		sb.WriteString("\n## Always skipped\n\n")
+		for _, sp := range rec.Skipped {
+			sb.WriteString(fmt.Sprintf("- `%s` (%s)\n", sp.Path, sp.Reason))
+		}
+	}
+
+	e := rec.Estimate
+	sb.WriteString("\n## Estimate\n\n")
+	sb.WriteString(fmt.Sprintf("- %d file(s), %d line(s), ~%d chunk(s)\n", e.Files, e.Lines, e.Chunks))
+	sb.WriteString(fmt.Sprintf("- Index size: ~%.1f MB\n", e.IndexSizeMB))
+	sb.WriteString(fmt.Sprintf("- Indexing time: ~%s\n", formatEstimate(e.EstimatedSeconds)))
+
+	m := rec.EmbeddingModel
+	sb.WriteString("\n## Embedding model\n\n")
+	if m.Suggested == m.Current {
+		sb.WriteString(fmt.Sprintf("Keep `%s`: %s.\n", m.Current, m.Reason))
+	} else {
+		sb.WriteString(fmt.Sprintf("Suggested `%s` (configured: `%s`): %s. Set `llm.ollama_embed` in config.yaml and re-index.\n", m.Suggested, m.Current, m.Reason))
+	}
+
+	sb.WriteString(fmt.Sprintf("\n## %s\n\n", workspace.ProjectConfigFile))
+	switch {
+	case written:
+		sb.WriteString(fmt.Sprintf("✅ Wrote `%s`:\n\n", rec.ConfigFile))
+	case rec.ConfigExists:
+		sb.WriteString(fmt.Sprintf("`%s` already exists; recommended content:\n\n", rec.ConfigFile))
+	default:
+		sb.WriteString("Call again with write=true to create it with:\n\n")
+	}
+	sb.WriteString("```yaml\n" + rec.ConfigYAML + "```\n")
+	return sb.String()
+}
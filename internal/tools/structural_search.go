@@ -0,0 +1,198 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/doITmagic/rag-code-mcp/internal/codetypes"
+	"github.com/doITmagic/rag-code-mcp/internal/ragcode"
+	"github.com/doITmagic/rag-code-mcp/internal/ragcode/structural"
+	"github.com/doITmagic/rag-code-mcp/internal/workspace"
+)
+
+// StructuralSearchTool matches structural patterns (calls, object creation,
+// declarations, exception handlers) against the parsed source files of the
+// workspace. Parsed files are cached until they change.
+type StructuralSearchTool struct {
+	workspaceManager *workspace.Manager
+	cache            *structural.Cache
+}
+
+// NewStructuralSearchTool creates a new structural_search tool
+func NewStructuralSearchTool(wm *workspace.Manager) *StructuralSearchTool {
+	return &StructuralSearchTool{
+		workspaceManager: wm,
+		cache:            structural.NewCache(),
+	}
+}
+
+func (t *StructuralSearchTool) Name() string {
+	return "structural_search"
+}
+
+func (t *StructuralSearchTool) Description() string {
+	return "Find code by syntax structure in Go, PHP and Python - e.g. 'call:db.Query(*)', 'new PDO(*)', 'call:*.Exec($Q, *)', 'except:$X: pass', 'func:(*Server).Handle*', 'import:database/*'. Kinds: call, new, func, class, import, except (catch), defer, go. In patterns '*' matches anything, '$X' one expression (repeated $X must be equal), '_' one argument. Use for precise matches semantic search can't express: every call of an API, swallowed exceptions, constructors with given arguments."
+}
+
+func (t *StructuralSearchTool) Execute(ctx context.Context, params map[string]interface{}) (string, error) {
+	raw, _ := params["pattern"].(string)
+	if raw == "" {
+		return "", fmt.Errorf("pattern parameter is required")
+	}
+	pattern, err := structural.Parse(raw)
+	if err != nil {
+		return "", fmt.Errorf("invalid pattern: %w", err)
+	}
+	if t.workspaceManager == nil {
+		return "", fmt.Errorf("workspace manager not configured")
+	}
+	if extractFilePathFromParams(params) == "" {
+		return "", fmt.Errorf("file_path parameter is required for structural_search. Please provide a file path from your workspace")
+	}
+	info, err := t.workspaceManager.DetectWorkspace(params)
+	if err != nil {
+		return "", fmt.Errorf("failed to detect workspace: %w", err)
+	}
+
+	files, err := structuralFiles(t.workspaceManager, info, params)
+	if err != nil {
+		return "", err
+	}
+
+	limit := 50
+	if l, ok := params["limit"].(float64); ok && l > 0 {
+		limit = int(l)
+	} else if l, ok := params["limit"].(int); ok && l > 0 {
+		limit = l
+	}
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	matches, truncated := t.cache.Search(files, pattern, limit)
+
+	var symbols []ragcode.SymbolEntry
+	if table, err := t.workspaceManager.Symbols(info); err == nil {
+		symbols = table.All()
+	}
+	descriptors := structuralDescriptors(matches, symbols)
+
+	switch outputFormatFrom(params, formatJSON) {
+	case formatMinimal:
+		if len(descriptors) == 0 {
+			return "No matches.", nil
+		}
+		return formatMinimalDescriptors(descriptors), nil
+	case formatMarkdown:
+		return formatStructuralMatches(raw, info.Root, len(files), matches, descriptors, truncated), nil
+	}
+	data, err := json.MarshalIndent(descriptors, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal structural_search results: %w", err)
+	}
+	return string(data), nil
+}
+
+// structuralFiles lists the workspace files a structural pattern is evaluated
+// on: those of the language parameter, or of every supported language.
+func structuralFiles(wm *workspace.Manager, info *workspace.Info, params map[string]interface{}) ([]string, error) {
+	languages := structural.Languages
+	if lang, _ := params["language"].(string); lang != "" {
+		lang = strings.ToLower(lang)
+		supported := false
+		for _, l := range structural.Languages {
+			supported = supported || l == lang
+		}
+		if !supported {
+			return nil, fmt.Errorf("structural patterns support %s, not '%s'", strings.Join(structural.Languages, ", "), lang)
+		}
+		languages = []string{lang}
+	}
+	var files []string
+	for _, lang := range languages {
+		langFiles, err := wm.SourceFiles(info, lang)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, langFiles...)
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// structuralDescriptors maps matches to the search result schema, named after
+// the enclosing symbol like grep_workspace results.
+func structuralDescriptors(matches []structural.Match, symbols []ragcode.SymbolEntry) []codetypes.SymbolDescriptor {
+	byFile := make(map[string][]ragcode.SymbolEntry)
+	for _, s := range symbols {
+		byFile[s.FilePath] = append(byFile[s.FilePath], s)
+	}
+
+	out := make([]codetypes.SymbolDescriptor, 0, len(matches))
+	for _, m := range matches {
+		desc := codetypes.SymbolDescriptor{
+			Language:    inferLanguageFromPath(m.Path),
+			Kind:        "match",
+			Description: m.Node.Text,
+			Location: codetypes.SymbolLocation{
+				FilePath:  m.Path,
+				StartLine: m.Node.Line,
+				EndLine:   m.Node.EndLine,
+			},
+			Metadata: map[string]any{
+				"column":    m.Node.Column,
+				"node_kind": m.Node.Kind,
+				"node_name": m.Node.Name,
+			},
+		}
+		if len(m.Bindings) > 0 {
+			desc.Metadata["bindings"] = m.Bindings
+		}
+		if s, ok := enclosingSymbol(byFile[m.Path], m.Node.Line); ok {
+			desc.Name = s.Name
+			desc.Package = s.Package
+			desc.Namespace = s.Package
+			desc.Metadata["symbol_kind"] = s.Kind
+			desc.Metadata["symbol_lines"] = fmt.Sprintf("%d-%d", s.StartLine, s.EndLine)
+		}
+		out = append(out, desc)
+	}
+	return out
+}
+
+func formatStructuralMatches(pattern, root string, scanned int, matches []structural.Match, descs []codetypes.SymbolDescriptor, truncated bool) string {
+	if len(matches) == 0 {
+		return fmt.Sprintf("No matches for `%s` in %d file(s) of workspace '%s'.", pattern, scanned, root)
+	}
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("🧩 %d match(es) for `%s` in workspace '%s':\n", len(matches), pattern, root))
+	file := ""
+	for i, m := range matches {
+		if m.Path != file {
+			file = m.Path
+			sb.WriteString(fmt.Sprintf("\n%s\n", file))
+		}
+		in := ""
+		if descs[i].Name != "" {
+			in = fmt.Sprintf(" (in %s)", descs[i].Name)
+		}
+		sb.WriteString(fmt.Sprintf("  %d:%d: %s%s\n", m.Node.Line, m.Node.Column, m.Node.Text, in))
+		if len(m.Bindings) > 0 {
+			names := make([]string, 0, len(m.Bindings))
+			for name := range m.Bindings {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			for j, name := range names {
+				names[j] = fmt.Sprintf("%s = %s", name, m.Bindings[name])
+			}
+			sb.WriteString(fmt.Sprintf("      %s\n", strings.Join(names, ", ")))
+		}
+	}
+	if truncated {
+		sb.WriteString("\n⚠️ Stopped at the match limit - make the pattern more specific or raise limit.\n")
+	}
+	return sb.String()
+}
@@ -0,0 +1,125 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/doITmagic/rag-code-mcp/internal/ragcode/structural"
+	"github.com/doITmagic/rag-code-mcp/internal/workspace"
+)
+
+// SuggestRewritesTool proposes a mechanical refactor: every match of a
+// structural pattern is replaced by a template, and the result is returned as
+// unified diffs for review. Files are never modified.
+type SuggestRewritesTool struct {
+	workspaceManager *workspace.Manager
+	cache            *structural.Cache
+}
+
+// NewSuggestRewritesTool creates a new suggest_rewrites tool
+func NewSuggestRewritesTool(wm *workspace.Manager) *SuggestRewritesTool {
+	return &SuggestRewritesTool{
+		workspaceManager: wm,
+		cache:            structural.NewCache(),
+	}
+}
+
+func (t *SuggestRewritesTool) Name() string {
+	return "suggest_rewrites"
+}
+
+func (t *SuggestRewritesTool) Description() string {
+	return "Propose a mechanical refactor across the workspace as unified diffs, without applying it. Takes a structural_search pattern and a replacement template using the pattern's variables, e.g. pattern 'call:*.Exec($Q, *)' with replacement 'db.ExecContext(ctx, $Q)', or 'new PDO($DSN)' with 'DB::connect($DSN)'. Go, PHP and Python; rewritten Go and PHP files are re-parsed and syntax errors flagged. Review the diffs, then apply them with your editing tools."
+}
+
+func (t *SuggestRewritesTool) Execute(ctx context.Context, params map[string]interface{}) (string, error) {
+	raw, _ := params["pattern"].(string)
+	if raw == "" {
+		return "", fmt.Errorf("pattern parameter is required")
+	}
+	replacement, ok := params["replacement"].(string)
+	if !ok {
+		return "", fmt.Errorf("replacement parameter is required")
+	}
+	pattern, err := structural.Parse(raw)
+	if err != nil {
+		return "", fmt.Errorf("invalid pattern: %w", err)
+	}
+	if _, err := structural.Expand(replacement, pattern.Vars()); err != nil {
+		return "", fmt.Errorf("invalid replacement: %w", err)
+	}
+	if t.workspaceManager == nil {
+		return "", fmt.Errorf("workspace manager not configured")
+	}
+	if extractFilePathFromParams(params) == "" {
+		return "", fmt.Errorf("file_path parameter is required for suggest_rewrites. Please provide a file path from your workspace")
+	}
+	info, err := t.workspaceManager.DetectWorkspace(params)
+	if err != nil {
+		return "", fmt.Errorf("failed to detect workspace: %w", err)
+	}
+
+	files, err := structuralFiles(t.workspaceManager, info, params)
+	if err != nil {
+		return "", err
+	}
+	limit := 200
+	if l, ok := params["limit"].(float64); ok && l > 0 {
+		limit = int(l)
+	} else if l, ok := params["limit"].(int); ok && l > 0 {
+		limit = l
+	}
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	matches, truncated := t.cache.Search(files, pattern, limit)
+	rewrites, err := structural.Rewrites(info.Root, matches, replacement)
+	if err != nil {
+		return "", err
+	}
+
+	if outputFormatFrom(params, formatMarkdown) == formatJSON {
+		data, err := json.MarshalIndent(rewrites, "", "  ")
+		if err != nil {
+			return "", fmt.Errorf("failed to marshal suggest_rewrites results: %w", err)
+		}
+		return string(data), nil
+	}
+	return formatRewrites(raw, info.Root, rewrites, truncated), nil
+}
+
+func formatRewrites(pattern, root string, rewrites []structural.Rewrite, truncated bool) string {
+	if len(rewrites) == 0 {
+		return fmt.Sprintf("No changes: nothing matches `%s` in workspace '%s', or the replacement leaves every match unchanged.", pattern, root)
+	}
+	total, broken := 0, 0
+	for _, rw := range rewrites {
+		total += rw.Replacements
+		if rw.ParseError != "" {
+			broken++
+		}
+	}
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("✏️ Proposed %d replacement(s) of `%s` in %d file(s) - not applied:\n", total, pattern, len(rewrites)))
+	if broken > 0 {
+		sb.WriteString(fmt.Sprintf("⚠️ %d file(s) would no longer parse - check the replacement.\n", broken))
+	}
+	for _, rw := range rewrites {
+		sb.WriteString("\n")
+		if rw.ParseError != "" {
+			sb.WriteString(fmt.Sprintf("⚠️ %s: %s\n", rw.Path, rw.ParseError))
+		}
+		if rw.Skipped > 0 {
+			sb.WriteString(fmt.Sprintf("ℹ️ %s: %d nested match(es) left for a second pass\n", rw.Path, rw.Skipped))
+		}
+		sb.WriteString("```diff\n")
+		sb.WriteString(rw.Diff)
+		sb.WriteString("```\n")
+	}
+	if truncated {
+		sb.WriteString("\n⚠️ Stopped at the match limit - apply these and run again, or raise limit.\n")
+	}
+	return sb.String()
+}
@@ -0,0 +1,319 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/doITmagic/rag-code-mcp/internal/ragcode"
+	"github.com/doITmagic/rag-code-mcp/internal/workspace"
+)
+
+// SuggestTestTargetsTool ranks public functions and methods by how risky they
+// are to leave untested, combining coverage, callers and recent changes.
+type SuggestTestTargetsTool struct {
+	workspaceManager *workspace.Manager
+	now              func() time.Time
+}
+
+// NewSuggestTestTargetsTool creates a new suggest_test_targets tool
+func NewSuggestTestTargetsTool(wm *workspace.Manager) *SuggestTestTargetsTool {
+	return &SuggestTestTargetsTool{
+		workspaceManager: wm,
+		now:              time.Now,
+	}
+}
+
+// TestTarget is a symbol suggested for testing together with the signals that
+// ranked it.
+type TestTarget struct {
+	Name         string   `json:"name"`
+	Kind         string   `json:"kind"`
+	Language     string   `json:"language"`
+	Package      string   `json:"package,omitempty"`
+	Receiver     string   `json:"receiver,omitempty"`
+	Signature    string   `json:"signature,omitempty"`
+	FilePath     string   `json:"file_path"`
+	StartLine    int      `json:"start_line"`
+	EndLine      int      `json:"end_line"`
+	Risk         float64  `json:"risk"`
+	Coverage     *float64 `json:"coverage,omitempty"`
+	Callers      int      `json:"callers"`
+	CallerNames  []string `json:"caller_names,omitempty"`
+	Dependencies []string `json:"dependencies,omitempty"`
+	ModifiedDays int      `json:"modified_days_ago"`
+	Reasons      []string `json:"reasons"`
+}
+
+func (t *SuggestTestTargetsTool) Name() string {
+	return "suggest_test_targets"
+}
+
+func (t *SuggestTestTargetsTool) Description() string {
+	return "Rank public functions and methods by risk (low test coverage, many callers, recently changed) and return the top candidates with signatures, callers and workspace dependencies - use to decide WHAT to test before generating tests. Load a coverage report with load_coverage first for best results. Supports Go, PHP, Python."
+}
+
+// Risk weights; the coverage weight is redistributed when no coverage is loaded.
+const (
+	riskWeightCoverage = 0.45
+	riskWeightCallers  = 0.30
+	riskWeightRecency  = 0.25
+	// Recency half-life in days
+	recencyHalfLife = 14.0
+)
+
+func (t *SuggestTestTargetsTool) Execute(ctx context.Context, args map[string]interface{}) (string, error) {
+	limit := 10
+	if v, ok := args["limit"].(float64); ok && v > 0 {
+		limit = int(v)
+	}
+
+	language := ""
+	if v, ok := args["language"].(string); ok {
+		language = strings.ToLower(v)
+	}
+
+	outputFormat := outputFormatFrom(args, formatJSON)
+
+	if t.workspaceManager == nil {
+		return "", fmt.Errorf("workspace manager not configured")
+	}
+	if extractFilePathFromParams(args) == "" {
+		return "", fmt.Errorf("file_path parameter is required for suggest_test_targets. Please provide a file path from your workspace")
+	}
+	info, err := t.workspaceManager.DetectWorkspace(args)
+	if err != nil {
+		return "", fmt.Errorf("failed to detect workspace: %w", err)
+	}
+
+	table, err := t.workspaceManager.Symbols(info)
+	if err != nil {
+		return "", fmt.Errorf("failed to load symbol table: %w", err)
+	}
+	entries := table.All()
+	if len(entries) == 0 {
+		return fmt.Sprintf("❌ No symbols recorded for workspace '%s'.\n\n"+
+			"The symbol table is built during indexing. Please call 'index_workspace' with:\n"+
+			"{\n"+
+			"  \"file_path\": \"%s\"\n"+
+			"}\n", info.Root, info.Root), nil
+	}
+
+	coverage, err := t.workspaceManager.Coverage(info)
+	if err != nil {
+		return "", fmt.Errorf("failed to load coverage: %w", err)
+	}
+
+	targets := rankTestTargets(entries, coverage, language, t.now())
+	if len(targets) == 0 {
+		return "No public functions or methods found to suggest tests for.", nil
+	}
+	if len(targets) > limit {
+		targets = targets[:limit]
+	}
+
+	if outputFormat == formatMinimal {
+		return formatTestTargetsMinimal(targets), nil
+	}
+	if outputFormat == "markdown" {
+		return formatTestTargets(targets, coverage != nil), nil
+	}
+
+	data, err := json.MarshalIndent(targets, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal suggest_test_targets results: %w", err)
+	}
+	return string(data), nil
+}
+
+// rankTestTargets scores every public function/method (optionally of one
+// language) and returns them by descending risk. Test code and fully covered
+// symbols are excluded.
+func rankTestTargets(entries []ragcode.SymbolEntry, coverage *ragcode.CoverageReport, language string, now time.Time) []TestTarget {
+	callers := ragcode.CallerIndex(entries)
+	defined := make(map[string]struct{}, len(entries))
+	for _, e := range entries {
+		defined[e.Name] = struct{}{}
+	}
+
+	maxCallers := 0
+	for _, idx := range callers {
+		if len(idx) > maxCallers {
+			maxCallers = len(idx)
+		}
+	}
+
+	modTimes := make(map[string]time.Time)
+	var targets []TestTarget
+	for _, e := range entries {
+		if e.Kind != "function" && e.Kind != "method" {
+			continue
+		}
+		if !e.Exported || isTestSymbol(e) {
+			continue
+		}
+		if language != "" && e.Language != language {
+			continue
+		}
+
+		target := TestTarget{
+			Name:      e.Name,
+			Kind:      e.Kind,
+			Language:  e.Language,
+			Package:   e.Package,
+			Receiver:  e.Receiver,
+			Signature: e.Signature,
+			FilePath:  e.FilePath,
+			StartLine: e.StartLine,
+			EndLine:   e.EndLine,
+		}
+
+		// Coverage: unknown symbols count as untested
+		uncovered := 1.0
+		if pct, ok := coverage.Percent(e.FilePath, e.StartLine, e.EndLine); ok {
+			if pct >= 100 {
+				continue
+			}
+			rounded := math.Round(pct*10) / 10
+			target.Coverage = &rounded
+			uncovered = 1 - pct/100
+			target.Reasons = append(target.Reasons, fmt.Sprintf("coverage %.1f%%", rounded))
+		} else if coverage != nil {
+			target.Reasons = append(target.Reasons, "no coverage data")
+		}
+
+		// Callers (excluding tests and self-recursion)
+		seen := make(map[string]struct{})
+		for _, i := range callers[e.Name] {
+			caller := entries[i]
+			if isTestSymbol(caller) || (caller.Name == e.Name && caller.FilePath == e.FilePath) {
+				continue
+			}
+			target.Callers++
+			if _, dup := seen[caller.Name]; !dup && len(target.CallerNames) < 5 {
+				seen[caller.Name] = struct{}{}
+				target.CallerNames = append(target.CallerNames, caller.Name)
+			}
+		}
+		callerScore := 0.0
+		if maxCallers > 0 {
+			callerScore = math.Log1p(float64(target.Callers)) / math.Log1p(float64(maxCallers))
+		}
+		if target.Callers > 0 {
+			target.Reasons = append(target.Reasons, fmt.Sprintf("%d caller(s)", target.Callers))
+		}
+
+		// Dependencies: calls that resolve to workspace symbols
+		for _, callee := range e.Calls {
+			if _, ok := defined[callee]; ok {
+				target.Dependencies = append(target.Dependencies, callee)
+			}
+		}
+
+		// Recency from file modification time
+		mod, ok := modTimes[e.FilePath]
+		if !ok {
+			if st, err := os.Stat(e.FilePath); err == nil {
+				mod = st.ModTime()
+			}
+			modTimes[e.FilePath] = mod
+		}
+		recency := 0.0
+		if !mod.IsZero() {
+			days := now.Sub(mod).Hours() / 24
+			if days < 0 {
+				days = 0
+			}
+			target.ModifiedDays = int(days)
+			recency = math.Exp(-math.Ln2 * days / recencyHalfLife)
+			if target.ModifiedDays <= 7 {
+				target.Reasons = append(target.Reasons, fmt.Sprintf("changed %d day(s) ago", target.ModifiedDays))
+			}
+		}
+
+		if coverage != nil {
+			target.Risk = riskWeightCoverage*uncovered + riskWeightCallers*callerScore + riskWeightRecency*recency
+		} else {
+			scale := 1 / (riskWeightCallers + riskWeightRecency)
+			target.Risk = scale * (riskWeightCallers*callerScore + riskWeightRecency*recency)
+		}
+		target.Risk = math.Round(target.Risk*1000) / 1000
+		targets = append(targets, target)
+	}
+
+	sort.SliceStable(targets, func(i, j int) bool {
+		if targets[i].Risk != targets[j].Risk {
+			return targets[i].Risk > targets[j].Risk
+		}
+		return targets[i].Callers > targets[j].Callers
+	})
+	return targets
+}
+
+// isTestSymbol reports whether a symbol is test code (test files or test functions).
+func isTestSymbol(e ragcode.SymbolEntry) bool {
+	if e.Test {
+		return true
+	}
+	base := strings.ToLower(e.FilePath)
+	switch {
+	case strings.HasSuffix(base, "_test.go"),
+		strings.HasSuffix(base, "test.php"),
+		strings.Contains(base, "/tests/"),
+		strings.Contains(base, "/test_"),
+		strings.HasSuffix(base, "_test.py"):
+		return true
+	}
+	return strings.HasPrefix(e.Name, "Test") || strings.HasPrefix(e.Name, "test_") || strings.HasPrefix(e.Name, "Benchmark")
+}
+
+func formatTestTargets(targets []TestTarget, withCoverage bool) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("# 🎯 %d suggested test target(s)\n\n", len(targets)))
+	if !withCoverage {
+		sb.WriteString("⚠️  No coverage loaded - ranking uses callers and recent changes only. Call load_coverage for better results.\n\n")
+	}
+	for i, t := range targets {
+		name := t.Name
+		if t.Receiver != "" {
+			name = t.Receiver + "." + t.Name
+		}
+		sb.WriteString(fmt.Sprintf("## %d. `%s` (risk %.3f)\n\n", i+1, name, t.Risk))
+		sb.WriteString(fmt.Sprintf("- **Location:** `%s:%d-%d`\n", t.FilePath, t.StartLine, t.EndLine))
+		if t.Signature != "" {
+			sb.WriteString(fmt.Sprintf("- **Signature:** `%s`\n", t.Signature))
+		}
+		if len(t.Reasons) > 0 {
+			sb.WriteString(fmt.Sprintf("- **Why:** %s\n", strings.Join(t.Reasons, ", ")))
+		}
+		if len(t.CallerNames) > 0 {
+			sb.WriteString(fmt.Sprintf("- **Called by:** %s\n", strings.Join(t.CallerNames, ", ")))
+		}
+		if len(t.Dependencies) > 0 {
+			sb.WriteString(fmt.Sprintf("- **Depends on:** %s\n", strings.Join(t.Dependencies, ", ")))
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+func formatTestTargetsMinimal(targets []TestTarget) string {
+	var sb strings.Builder
+	for _, t := range targets {
+		name := t.Name
+		if t.Receiver != "" {
+			name = t.Receiver + "." + t.Name
+		}
+		summary := fmt.Sprintf("risk %.3f", t.Risk)
+		if len(t.Reasons) > 0 {
+			summary += ": " + strings.Join(t.Reasons, ", ")
+		}
+		sb.WriteString(minimalLine(t.Kind, name, t.FilePath, t.StartLine, t.EndLine, summary) + "\n")
+	}
+	return sb.String()
+}
@@ -0,0 +1,41 @@
+package tools
+
+import (
+	"testing"
+	"time"
+
+	"github.com/doITmagic/rag-code-mcp/internal/ragcode"
+)
+
+func TestRankTestTargets(t *testing.T) {
+	entries := []ragcode.SymbolEntry{
+		{Name: "Charge", Kind: "function", Language: "go", FilePath: "/ws/billing.go", StartLine: 1, EndLine: 10, Exported: true, Calls: []string{"validate"}},
+		{Name: "Refund", Kind: "function", Language: "go", FilePath: "/ws/billing.go", StartLine: 12, EndLine: 20, Exported: true, Calls: []string{"Charge"}},
+		{Name: "Checkout", Kind: "function", Language: "go", FilePath: "/ws/cart.go", StartLine: 1, EndLine: 5, Exported: true, Calls: []string{"Charge"}},
+		{Name: "Covered", Kind: "function", Language: "go", FilePath: "/ws/billing.go", StartLine: 30, EndLine: 35, Exported: true},
+		{Name: "validate", Kind: "function", Language: "go", FilePath: "/ws/billing.go", StartLine: 40, EndLine: 45},
+		{Name: "TestCharge", Kind: "function", Language: "go", FilePath: "/ws/billing_test.go", Exported: true, Calls: []string{"Charge", "Refund"}},
+	}
+	coverage := &ragcode.CoverageReport{Files: map[string]*ragcode.FileCoverage{
+		"/ws/billing.go": {Blocks: []ragcode.CoverageBlock{
+			{StartLine: 2, EndLine: 9, Statements: 4, Hits: 0},
+			{StartLine: 13, EndLine: 19, Statements: 4, Hits: 1},
+			{StartLine: 31, EndLine: 34, Statements: 2, Hits: 5},
+		}},
+	}}
+
+	targets := rankTestTargets(entries, coverage, "", time.Now())
+	if len(targets) != 2 {
+		t.Fatalf("len = %d, want 2 (private, test and fully covered symbols excluded): %+v", len(targets), targets)
+	}
+	top := targets[0]
+	if top.Name != "Charge" {
+		t.Fatalf("top target = %s, want Charge", top.Name)
+	}
+	if top.Callers != 2 || top.Coverage == nil || *top.Coverage != 0 {
+		t.Errorf("unexpected signals for Charge: callers=%d coverage=%v", top.Callers, top.Coverage)
+	}
+	if len(top.Dependencies) != 1 || top.Dependencies[0] != "validate" {
+		t.Errorf("Dependencies = %v, want [validate]", top.Dependencies)
+	}
+}
@@ -0,0 +1,72 @@
+package tools
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/doITmagic/rag-code-mcp/internal/memory"
+)
+
+// parseListParam reads a list parameter given as a comma-separated string or
+// a JSON array, lower-cased.
+func parseListParam(raw interface{}) []string {
+	var items []string
+	switch v := raw.(type) {
+	case string:
+		items = strings.Split(v, ",")
+	case []string:
+		items = v
+	case []interface{}:
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				items = append(items, s)
+			}
+		}
+	}
+	var out []string
+	for _, item := range items {
+		if item = strings.ToLower(strings.TrimSpace(item)); item != "" {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
+// docTags returns the tags set on a result by rag_code.tag_rules. They are
+// stored as a comma-separated string.
+func docTags(doc memory.Document) []string {
+	return parseListParam(doc.Metadata["tags"])
+}
+
+// filterByTags keeps the docs carrying all of tags.
+func filterByTags(docs []memory.Document, tags []string) []memory.Document {
+	if len(tags) == 0 {
+		return docs
+	}
+	out := make([]memory.Document, 0, len(docs))
+	for _, doc := range docs {
+		have := make(map[string]bool)
+		for _, tag := range docTags(doc) {
+			have[tag] = true
+		}
+		all := true
+		for _, tag := range tags {
+			if !have[tag] {
+				all = false
+				break
+			}
+		}
+		if all {
+			out = append(out, doc)
+		}
+	}
+	return out
+}
+
+// tagsLabel renders the tags of a result for markdown output.
+func tagsLabel(doc memory.Document) string {
+	if tags := docTags(doc); len(tags) > 0 {
+		return fmt.Sprintf(" [tags %s]", strings.Join(tags, ", "))
+	}
+	return ""
+}
@@ -3,14 +3,18 @@ package tools
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"testing"
 
 	"github.com/doITmagic/rag-code-mcp/internal/codetypes"
 	"github.com/doITmagic/rag-code-mcp/internal/llm"
 	"github.com/doITmagic/rag-code-mcp/internal/memory"
+	"github.com/doITmagic/rag-code-mcp/internal/ragcode"
+	"github.com/doITmagic/rag-code-mcp/internal/ragcode/structural"
 	"github.com/doITmagic/rag-code-mcp/internal/workspace"
 )
 
@@ -165,6 +169,146 @@ func TestSearchDocsTool_HappyPath(t *testing.T) {
 	}
 }
 
+func TestSearchDocsTool_LangFilter(t *testing.T) {
+	ltm := memory.NewInMemoryLongTermMemory()
+	ctx := context.Background()
+	_ = ltm.Store(ctx, memory.Document{ID: "1", Content: "install guide", Metadata: map[string]interface{}{"lang": "en"}})
+	_ = ltm.Store(ctx, memory.Document{ID: "2", Content: "安装指南", Metadata: map[string]interface{}{"lang": "zh"}})
+
+	tool := NewSearchDocsTool(ltm, &mockProvider{})
+
+	out, err := tool.Execute(ctx, map[string]interface{}{"query": "install", "lang": "ZH", "file_path": "/tmp/test.go"})
+	if err != nil {
+		t.Fatalf("Execute returned error: %v", err)
+	}
+	if !strings.Contains(out, "安装指南") || !strings.Contains(out, "[lang zh]") || strings.Contains(out, "install guide") {
+		t.Errorf("expected only the zh doc, got: %s", out)
+	}
+
+	out, err = tool.Execute(ctx, map[string]interface{}{"query": "install", "lang": "fr", "file_path": "/tmp/test.go"})
+	if err != nil {
+		t.Fatalf("Execute returned error: %v", err)
+	}
+	if !strings.Contains(out, "language(s) 'fr'") {
+		t.Errorf("expected no-results message, got: %s", out)
+	}
+}
+
+func TestApplyDocLanguagesPreferred(t *testing.T) {
+	doc := func(id, lang string) memory.Document {
+		md := map[string]interface{}{}
+		if lang != "" {
+			md["lang"] = lang
+		}
+		return memory.Document{ID: id, Metadata: md}
+	}
+	docs := []memory.Document{doc("1", "de"), doc("2", ""), doc("3", "en"), doc("4", "de"), doc("5", "en")}
+
+	var ids []string
+	for _, d := range applyDocLanguages(docs, nil, []string{"en"}) {
+		ids = append(ids, d.ID)
+	}
+	if got := strings.Join(ids, ","); got != "3,5,2,1,4" {
+		t.Errorf("order = %s, want preferred, then untagged, then the rest: 3,5,2,1,4", got)
+	}
+}
+
+func TestSearchDocsTool_SourcesFilter(t *testing.T) {
+	ltm := memory.NewInMemoryLongTermMemory()
+	ctx := context.Background()
+	_ = ltm.Store(ctx, memory.Document{ID: "1", Content: "project readme", Metadata: map[string]interface{}{"file": "README.md"}})
+	_ = ltm.Store(ctx, memory.Document{ID: "2", Content: "handbook page"})
+
+	tool := NewSearchDocsTool(ltm, &mockProvider{})
+
+	out, err := tool.Execute(ctx, map[string]interface{}{"query": "readme", "sources": "readme", "file_path": "/tmp/test.go"})
+	if err != nil {
+		t.Fatalf("Execute returned error: %v", err)
+	}
+	if !strings.Contains(out, "project readme") || !strings.Contains(out, "[readme · docs]") || strings.Contains(out, "handbook page") {
+		t.Errorf("expected only the readme, got: %s", out)
+	}
+
+	if _, err := tool.Execute(ctx, map[string]interface{}{"query": "readme", "sources": "wiki", "file_path": "/tmp/test.go"}); err == nil {
+		t.Error("expected an error for an unknown source")
+	}
+}
+
+func TestMergeDocResults(t *testing.T) {
+	doc := func(id, collection string, score float64) memory.Document {
+		return memory.Document{ID: id, Content: "install", Metadata: map[string]interface{}{
+			"file": "/ws/README.md", "start_line": 1, "collection": collection, "score": score,
+		}}
+	}
+	other := memory.Document{ID: "3", Content: "deploy", Metadata: map[string]interface{}{"collection": "project-docs", "score": 0.7}}
+
+	merged := mergeDocResults([][]memory.Document{
+		{doc("1", "ws-go", 0.6)},
+		{doc("2", "project-docs", 0.9), other},
+	})
+	if len(merged) != 2 || merged[0].ID != "2" || merged[1].ID != "3" {
+		t.Fatalf("merged = %+v, want 2 then 3", merged)
+	}
+	if got := merged[0].Metadata["collections"]; got != "project-docs, ws-go" {
+		t.Errorf("collections = %v", got)
+	}
+	merged[0].Metadata["doc_source"] = "readme"
+	if got := docSourceLabel(merged[0]); got != " [readme · project-docs, ws-go]" {
+		t.Errorf("label = %q", got)
+	}
+}
+
+func TestFilterByTags(t *testing.T) {
+	docs := []memory.Document{
+		{ID: "1", Metadata: map[string]interface{}{"tags": "auth,payment"}},
+		{ID: "2", Metadata: map[string]interface{}{"tags": "payment"}},
+		{ID: "3", Metadata: map[string]interface{}{}},
+	}
+
+	var ids []string
+	for _, d := range filterByTags(docs, parseListParam("Payment")) {
+		ids = append(ids, d.ID)
+	}
+	if got := strings.Join(ids, ","); got != "1,2" {
+		t.Errorf("tagged payment = %s, want 1,2", got)
+	}
+	if out := filterByTags(docs, parseListParam([]interface{}{"payment", "auth"})); len(out) != 1 || out[0].ID != "1" {
+		t.Errorf("tagged payment and auth = %+v, want only 1", out)
+	}
+	if got := tagsLabel(docs[0]); got != " [tags auth, payment]" {
+		t.Errorf("label = %q", got)
+	}
+}
+
+func TestFilterByBuild(t *testing.T) {
+	docs := []memory.Document{
+		{ID: "1", Metadata: map[string]interface{}{"build_constraint": "windows"}},
+		{ID: "2", Metadata: map[string]interface{}{"build_constraint": "unix"}},
+		{ID: "3", Metadata: map[string]interface{}{}},
+		{ID: "4", Metadata: map[string]interface{}{"build_constraint": "integration"}},
+	}
+
+	ids := func(out []memory.Document) string {
+		var ids []string
+		for _, d := range out {
+			ids = append(ids, d.ID)
+		}
+		return strings.Join(ids, ",")
+	}
+	if got := ids(filterByBuild(docs, parseBuildFilter(map[string]interface{}{"goos": "Windows"}))); got != "1,3" {
+		t.Errorf("goos windows = %s, want 1,3", got)
+	}
+	if got := ids(filterByBuild(docs, parseBuildFilter(map[string]interface{}{"goos": "linux", "build_tags": "integration"}))); got != "2,3,4" {
+		t.Errorf("goos linux with integration = %s, want 2,3,4", got)
+	}
+	if got := ids(filterByBuild(docs, parseBuildFilter(map[string]interface{}{}))); got != "1,2,3,4" {
+		t.Errorf("no filter = %s, want all", got)
+	}
+	if got := buildConstraintLabel(docs[0]); got != " [build windows]" {
+		t.Errorf("label = %q", got)
+	}
+}
+
 func TestHybridSearchTool_NoMemoryConfigured(t *testing.T) {
 	tool := NewHybridSearchTool(nil, &mockProvider{})
 	ctx := context.Background()
@@ -230,6 +374,66 @@ func TestHybridSearchTool_WithLexicalMatches(t *testing.T) {
 	}
 }
 
+func TestHybridSearchRanksExactSymbolFirst(t *testing.T) {
+	ltm := memory.NewInMemoryLongTermMemory()
+	ctx := context.Background()
+	for _, c := range []struct {
+		id, name, code string
+		score          float64
+	}{
+		{"a", "Connect", "func Connect() { client := NewQdrantClient(cfg); client.Ping() }", 0.95},
+		{"b", "NewQdrantClient", "func NewQdrantClient(cfg QdrantConfig) (*QdrantClient, error) {}", 0.40},
+		{"c", "NewClient", "func NewClient() *Client { return &Client{} }", 0.90},
+	} {
+		content, _ := json.Marshal(codetypes.CodeChunk{Name: c.name, Type: "function", Code: c.code})
+		_ = ltm.Store(ctx, memory.Document{ID: c.id, Content: string(content), Metadata: map[string]interface{}{"name": c.name, "score": c.score}})
+	}
+
+	tool := NewHybridSearchTool(ltm, &mockProvider{})
+	out, err := tool.Execute(ctx, map[string]interface{}{"query": "NewQdrantClient", "limit": float64(3), "file_path": "/tmp/test.go"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var results []map[string]interface{}
+	if err := json.Unmarshal([]byte(out), &results); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out)
+	}
+	if len(results) == 0 || results[0]["name"] != "NewQdrantClient" {
+		t.Fatalf("first result = %v, want the NewQdrantClient definition", results)
+	}
+}
+
+func TestFuseRankings(t *testing.T) {
+	doc := func(id string, score float64) memory.Document {
+		return memory.Document{ID: id, Metadata: map[string]interface{}{"score": score}}
+	}
+	semantic := []memory.Document{doc("a", 0.9), doc("b", 0.8), doc("c", 0.7)}
+	hits := []workspace.KeywordHit{
+		{Doc: memory.Document{ID: "c", Metadata: map[string]interface{}{"score": 1.0}}, Score: 7},
+		{Doc: memory.Document{ID: "d", Metadata: map[string]interface{}{"score": 1.0}}, Score: 3},
+	}
+
+	fused := fuseRankings(semantic, hits)
+	var ids []string
+	for _, d := range fused {
+		ids = append(ids, d.ID)
+	}
+	// c is in both lists; a leads the semantic list; b and d tie at the
+	// second place of one list each, semantic order first
+	if got := strings.Join(ids, ","); got != "c,a,b,d" {
+		t.Errorf("fused order = %s, want c,a,b,d", got)
+	}
+	if fused[0].Metadata["semantic_score"] != 0.7 || fused[0].Metadata["lexical_score"] != 7.0 {
+		t.Errorf("c metadata = %v", fused[0].Metadata)
+	}
+	if _, ok := fused[3].Metadata["score"]; ok {
+		t.Errorf("keyword-only hits should carry no vector score: %v", fused[3].Metadata)
+	}
+	if _, ok := hits[1].Doc.Metadata["rrf_score"]; ok {
+		t.Error("fusion should not modify the metadata of keyword hits")
+	}
+}
+
 func TestListPackageExportsTool_ValidationAndHappyPath(t *testing.T) {
 	ctx := context.Background()
 
@@ -252,7 +456,10 @@ func TestListPackageExportsTool_ValidationAndHappyPath(t *testing.T) {
 	if err != nil {
 		t.Fatalf("failed to marshal chunk: %v", err)
 	}
-	_ = ltm.Store(ctx, memory.Document{ID: "1", Content: string(b)})
+	// Packages are listed by the metadata the indexer stores with chunks
+	_ = ltm.Store(ctx, memory.Document{ID: "1", Content: string(b), Metadata: map[string]interface{}{
+		"package": chunk.Package, "type": chunk.Type, "name": chunk.Name,
+	}})
 
 	tool := NewListPackageExportsTool(ltm, &mockProvider{})
 
@@ -266,6 +473,39 @@ func TestListPackageExportsTool_ValidationAndHappyPath(t *testing.T) {
 	}
 }
 
+func TestListPackageExportsListsLargePackages(t *testing.T) {
+	ctx := context.Background()
+	ltm := memory.NewInMemoryLongTermMemory()
+	// More symbols than a semantic search returns, in two packages matching
+	// the name and one that does not
+	for i := 0; i < 150; i++ {
+		pkg := []string{"billing", "billingv2", "orders"}[i%3]
+		chunk := codetypes.CodeChunk{Name: fmt.Sprintf("Func%03d", i), Type: "function", Package: pkg, FilePath: "/tmp/" + pkg + ".go"}
+		b, _ := json.Marshal(chunk)
+		_ = ltm.Store(ctx, memory.Document{ID: strconv.Itoa(i), Content: string(b), Metadata: map[string]interface{}{
+			"package": chunk.Package, "type": chunk.Type, "name": chunk.Name,
+		}})
+	}
+
+	tool := NewListPackageExportsTool(ltm, &mockProvider{})
+	out, err := tool.Execute(ctx, map[string]interface{}{"package": "billing", "file_path": "/tmp/billing.go", "output_format": "json"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var symbols []codetypes.SymbolDescriptor
+	if err := json.Unmarshal([]byte(out), &symbols); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out)
+	}
+	if len(symbols) != 100 {
+		t.Errorf("listed %d symbols, want the 100 of billing and billingv2", len(symbols))
+	}
+	for _, sym := range symbols {
+		if sym.Package == "orders" {
+			t.Errorf("listed %s of package orders", sym.Name)
+		}
+	}
+}
+
 func TestGetFunctionDetailsTool_HappyPathAndNotFound(t *testing.T) {
 	ctx := context.Background()
 	ltm := memory.NewInMemoryLongTermMemory()
@@ -644,3 +884,57 @@ func TestListPackageExports_PHPApp_JSON(t *testing.T) {
 		t.Errorf("expected to find User symbol in JSON exports for App")
 	}
 }
+
+func TestGrepDescriptors(t *testing.T) {
+	matches := []workspace.GrepMatch{
+		{Path: "/app/db.go", Line: 12, Column: 5, Text: "\treturn sql.Open(dsn)", Before: []string{"func Open() {"}},
+		{Path: "/app/db.go", Line: 40, Column: 1, Text: "var dsn = \"x\""},
+	}
+	symbols := []ragcode.SymbolEntry{
+		{Name: "DB", Kind: "type", FilePath: "/app/db.go", StartLine: 1, EndLine: 30},
+		{Name: "Open", Kind: "method", FilePath: "/app/db.go", StartLine: 11, EndLine: 14},
+	}
+
+	descs := grepDescriptors(matches, symbols)
+	if descs[0].Name != "Open" || descs[0].Metadata["symbol_kind"] != "method" || descs[0].Location.StartLine != 12 {
+		t.Errorf("first match = %+v, want inside method Open", descs[0])
+	}
+	if descs[0].Metadata["context"] != "func Open() {\n\treturn sql.Open(dsn)" {
+		t.Errorf("context = %q", descs[0].Metadata["context"])
+	}
+	if descs[1].Name != "" || descs[1].Kind != "match" || descs[1].Language != "go" {
+		t.Errorf("second match = %+v, want no enclosing symbol", descs[1])
+	}
+}
+
+func TestStructuralDescriptors(t *testing.T) {
+	matches := []structural.Match{{
+		Path:     "/app/repo.php",
+		Node:     structural.Node{Kind: "new", Name: "PDO", Line: 6, EndLine: 6, Column: 16, Text: "new PDO($dsn)"},
+		Bindings: map[string]string{"$DSN": "$dsn"},
+	}}
+	symbols := []ragcode.SymbolEntry{{Name: "find", Kind: "method", Package: "App", FilePath: "/app/repo.php", StartLine: 5, EndLine: 12}}
+
+	descs := structuralDescriptors(matches, symbols)
+	if len(descs) != 1 || descs[0].Name != "find" || descs[0].Language != "php" {
+		t.Fatalf("descriptors = %+v, want one match inside find", descs)
+	}
+	if descs[0].Metadata["node_kind"] != "new" || descs[0].Metadata["bindings"].(map[string]string)["$DSN"] != "$dsn" {
+		t.Errorf("metadata = %+v", descs[0].Metadata)
+	}
+}
+
+func TestFormatRewrites(t *testing.T) {
+	out := formatRewrites("f($X)", "/app", []structural.Rewrite{
+		{Path: "a.go", Replacements: 2, Diff: "--- a/a.go\n+++ b/a.go\n@@ -1,1 +1,1 @@\n-f(1)\n+g(1)\n"},
+		{Path: "b.go", Replacements: 1, ParseError: "b.go:3:1: expected ')'", Diff: "--- a/b.go\n"},
+	}, false)
+	for _, want := range []string{"3 replacement(s)", "in 2 file(s) - not applied", "1 file(s) would no longer parse", "```diff\n--- a/a.go", "⚠️ b.go: b.go:3:1"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+	if out := formatRewrites("f($X)", "/app", nil, false); !strings.Contains(out, "No changes") {
+		t.Errorf("unexpected empty output: %s", out)
+	}
+}
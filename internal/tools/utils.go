@@ -1,6 +1,7 @@
 package tools
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -9,8 +10,43 @@ import (
 
 	"github.com/doITmagic/rag-code-mcp/internal/codetypes"
 	"github.com/doITmagic/rag-code-mcp/internal/memory"
+	"github.com/doITmagic/rag-code-mcp/internal/ragcode"
+	"github.com/doITmagic/rag-code-mcp/internal/workspace"
 )
 
+// maxListedDocuments bounds the documents scrollAll returns, so a filter
+// matching most of a large index cannot exhaust memory
+const maxListedDocuments = 5000
+
+// scrollAll lists the documents of mem matching filter, up to
+// maxListedDocuments, by scrolling page after page
+func scrollAll(ctx context.Context, mem memory.LongTermMemory, filter memory.Filter) ([]memory.Document, error) {
+	var docs []memory.Document
+	cursor := ""
+	for len(docs) < maxListedDocuments {
+		page, next, err := mem.Scroll(ctx, filter, cursor, min(500, maxListedDocuments-len(docs)))
+		if err != nil {
+			return nil, err
+		}
+		docs = append(docs, page...)
+		if next == "" || len(page) == 0 {
+			break
+		}
+		cursor = next
+	}
+	return docs, nil
+}
+
+// symbolFilter selects the chunks of the symbol name, of one of types when
+// any are given
+func symbolFilter(name string, types ...string) memory.Filter {
+	filter := memory.Filter{Must: map[string][]string{"name": {name}}}
+	if len(types) > 0 {
+		filter.Must["type"] = types
+	}
+	return filter
+}
+
 // readFileLines reads specific lines from a file
 func readFileLines(filePath string, startLine, endLine int) (string, error) {
 	content, err := os.ReadFile(filePath)
@@ -37,27 +73,31 @@ func buildSymbolDescriptorsFromDocs(docs []memory.Document) []codetypes.SymbolDe
 		var desc codetypes.SymbolDescriptor
 		var chunk codetypes.CodeChunk
 		if err := json.Unmarshal([]byte(doc.Content), &chunk); err == nil && chunk.Name != "" {
-			desc.Language = chunk.Language
-			desc.Kind = chunk.Type
-			desc.Name = chunk.Name
-			desc.Namespace = chunk.Package
-			desc.Package = chunk.Package
-			desc.Signature = chunk.Signature
-			desc.Description = chunk.Docstring
-			desc.Location = codetypes.SymbolLocation{
-				FilePath:  chunk.FilePath,
-				StartLine: chunk.StartLine,
-				EndLine:   chunk.EndLine,
-			}
+			desc = symbolDescriptorFromChunk(chunk)
 			if chunk.Code != "" {
 				if desc.Metadata == nil {
 					desc.Metadata = make(map[string]any)
 				}
 				desc.Metadata["snippet"] = chunk.Code
 			}
+			if truncated, kept := ragcode.ChunkTruncated(chunk); truncated {
+				desc.Metadata["truncated"] = true
+				desc.Metadata["code_lines"] = kept
+				desc.Metadata["full_code"] = fullCodeCall(chunk)
+				if summary, _ := chunk.Metadata["class_summary"].(string); summary != "" {
+					desc.Metadata["class_summary"] = summary
+				}
+			}
+			if chunk.Partial() {
+				if desc.Metadata == nil {
+					desc.Metadata = make(map[string]any)
+				}
+				desc.Metadata[codetypes.MetaPartial] = true
+			}
 		} else {
 			// Fallback: treat content as opaque text
 			desc.Kind = "document"
+			desc.Name, _ = doc.Metadata["title"].(string) // front matter title of docs
 			desc.Description = truncateString(doc.Content, 400)
 		}
 
@@ -71,11 +111,90 @@ func buildSymbolDescriptorsFromDocs(docs []memory.Document) []codetypes.SymbolDe
 			}
 		}
 
+		// Stable chunk ID for follow-up retrieval with get_chunk. Set after the merge:
+		// markdown chunks store their position in the file under the same key
+		if id := stableChunkID(doc); id != "" {
+			if desc.Metadata == nil {
+				desc.Metadata = make(map[string]any)
+			}
+			desc.Metadata["chunk_id"] = id
+		}
+
 		out = append(out, desc)
 	}
 	return out
 }
 
+// symbolDescriptorFromChunk maps the symbol fields of a chunk, without code
+// or metadata.
+func symbolDescriptorFromChunk(chunk codetypes.CodeChunk) codetypes.SymbolDescriptor {
+	return codetypes.SymbolDescriptor{
+		Language:    string(chunk.Language),
+		Kind:        chunk.Type,
+		Name:        chunk.Name,
+		Namespace:   chunk.Package,
+		Package:     chunk.Package,
+		Signature:   chunk.Signature,
+		Description: chunk.Docstring,
+		Location: codetypes.SymbolLocation{
+			FilePath:  chunk.FilePath,
+			StartLine: chunk.StartLine,
+			EndLine:   chunk.EndLine,
+		},
+	}
+}
+
+// chunkIDLabel renders the stable chunk ID of a result for markdown output.
+func chunkIDLabel(doc memory.Document) string {
+	id := stableChunkID(doc)
+	if id == "" {
+		return ""
+	}
+	return fmt.Sprintf(" [chunk_id %s]", id)
+}
+
+// stableChunkID is the chunk_id shown for a document: the ID of the chunk
+// without its index generation, or the point ID of chunks stored before
+// generations existed
+func stableChunkID(doc memory.Document) string {
+	if id, ok := doc.Metadata[workspace.StableIDKey].(string); ok && id != "" {
+		return id
+	}
+	return doc.ID
+}
+
+// fullCodeCall is the get_code_context call returning the whole code of a
+// chunk stored truncated (rag_code.max_chunk_lines)
+func fullCodeCall(chunk codetypes.CodeChunk) map[string]any {
+	return map[string]any{
+		"tool":       "get_code_context",
+		"file_path":  chunk.FilePath,
+		"start_line": chunk.StartLine,
+		"end_line":   chunk.EndLine,
+	}
+}
+
+// truncatedLabel points markdown output to the rest of a truncated chunk,
+// and flags chunks extracted from a file with syntax errors or from an
+// unsaved editor buffer.
+func truncatedLabel(doc memory.Document) string {
+	var chunk codetypes.CodeChunk
+	if err := json.Unmarshal([]byte(doc.Content), &chunk); err != nil {
+		return ""
+	}
+	label := ""
+	if truncated, kept := ragcode.ChunkTruncated(chunk); truncated {
+		label = fmt.Sprintf(" [truncated to %d lines: get_code_context file_path=%s start_line=%d end_line=%d]", kept, chunk.FilePath, chunk.StartLine, chunk.EndLine)
+	}
+	if chunk.Partial() {
+		label += " [partial: file has syntax errors]"
+	}
+	if overlay, _ := doc.Metadata["overlay"].(bool); overlay {
+		label += " [unsaved buffer]"
+	}
+	return label
+}
+
 func truncateString(s string, max int) string {
 	if len(s) <= max {
 		return s
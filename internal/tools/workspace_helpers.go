@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	"github.com/doITmagic/rag-code-mcp/internal/memory"
+	"github.com/doITmagic/rag-code-mcp/internal/workspace"
 )
 
 // CheckCollectionStatus verifies if a collection exists and has data.
@@ -62,3 +63,30 @@ func CheckSearchResults(resultCount int, collectionName, workspacePath string) (
 
 	return "", nil
 }
+
+// resolveLanguageMemory returns the workspace collection for a language. When the
+// collection cannot be searched yet (still indexing or not created) it returns a
+// user-facing status message instead of a memory.
+func resolveLanguageMemory(ctx context.Context, wm *workspace.Manager, info *workspace.Info, language string) (memory.LongTermMemory, string, error) {
+	collectionName := info.CollectionNameForLanguage(language)
+	mem, err := wm.GetMemoryForWorkspaceLanguage(ctx, info, language)
+	if err != nil {
+		return nil, "", err
+	}
+
+	indexKey := info.ID + "-" + language
+	if wm.IsIndexing(indexKey) {
+		return nil, fmt.Sprintf("⏳ Workspace '%s' language '%s' is currently being indexed in the background.\n"+
+			"Please try again in a few moments.\n"+
+			"Workspace: %s\n"+
+			"Language: %s\n"+
+			"Collection: %s",
+			info.Root, language, info.Root, language, collectionName), nil
+	}
+
+	if msg, err := CheckCollectionStatus(ctx, mem, collectionName, info.Root); err != nil || msg != "" {
+		return nil, msg, err
+	}
+
+	return mem, "", nil
+}
@@ -0,0 +1,179 @@
+package workspace
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/doITmagic/rag-code-mcp/internal/llm"
+	"github.com/doITmagic/rag-code-mcp/internal/memory"
+	"github.com/doITmagic/rag-code-mcp/internal/ragcode"
+	"github.com/doITmagic/rag-code-mcp/internal/storage"
+)
+
+var collectionUnsafe = regexp.MustCompile(`[^a-z0-9_-]+`)
+
+// ABCollectionName returns the collection holding the code of collectionName
+// embedded with the A/B model, e.g. ragcode-a1b2c3d4e5f6-go-ab-mxbai-embed-large.
+// The model is part of the name: models differ in vector dimension.
+func ABCollectionName(collectionName, model string) string {
+	slug := strings.Trim(collectionUnsafe.ReplaceAllString(strings.ToLower(model), "-"), "-")
+	return collectionName + "-ab-" + slug
+}
+
+// SetABEmbedder sets the second embedding model (llm.ab_embed) used to build
+// a parallel vector space for A/B comparison with ab_search.
+func (m *Manager) SetABEmbedder(provider llm.Provider, model string) {
+	m.abLLM = provider
+	m.abModel = model
+}
+
+// ABEmbedder returns the A/B embedding provider and its model, or nil when
+// A/B testing is not configured.
+func (m *Manager) ABEmbedder() (llm.Provider, string) {
+	if m == nil {
+		return nil, ""
+	}
+	return m.abLLM, m.abModel
+}
+
+// EmbedModel returns the name of the main embedding model (llm.ollama_embed,
+// nomic-embed-text by default).
+func (m *Manager) EmbedModel() string {
+	if m == nil || m.config == nil || m.config.LLM.OllamaEmbed == "" {
+		return "nomic-embed-text"
+	}
+	return m.config.LLM.OllamaEmbed
+}
+
+// ABIndexKey is the IsIndexing key of the A/B index of a workspace language.
+func ABIndexKey(info *Info, language string) string {
+	return info.ID + "-" + language + "-ab"
+}
+
+// ABMemory returns the memory of the A/B collection of a workspace language,
+// or nil when it has not been built yet.
+func (m *Manager) ABMemory(ctx context.Context, info *Info, language string) (memory.LongTermMemory, error) {
+	if m.abLLM == nil {
+		return nil, fmt.Errorf("A/B testing is not configured (set llm.ab_embed)")
+	}
+	collectionName := ABCollectionName(info.CollectionNameForLanguage(language), m.abModel)
+
+	if mem, ok := m.cachedMemory(collectionName); ok {
+		return mem, nil
+	}
+	unlock := m.collectionLocks.Lock(collectionName)
+	defer unlock()
+	if mem, ok := m.cachedMemory(collectionName); ok {
+		return mem, nil
+	}
+
+	client, err := storage.Open(m.config.Storage.VectorDB, collectionName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create collection client: %w", err)
+	}
+	exists, err := client.CollectionExists(ctx, collectionName)
+	if err != nil || !exists {
+		client.Close()
+		return nil, err
+	}
+
+	mem := storage.NewVectorStoreMemory(client)
+	m.memoryMu.Lock()
+	m.memories[collectionName] = mem
+	m.memoryMu.Unlock()
+	return mem, nil
+}
+
+// StartABIndexing builds the A/B collection of a workspace language in the
+// background.
+func (m *Manager) StartABIndexing(info *Info, language string) {
+	m.background(func(ctx context.Context) {
+		if err := m.IndexABVariant(ctx, info, language); err != nil {
+			log.Printf("❌ A/B indexing failed: %v", err)
+		}
+	})
+}
+
+// IndexABVariant re-embeds all code of a workspace language with the A/B
+// model into its own collection. Unlike IndexLanguage it always rebuilds the
+// collection from scratch: it is a snapshot for comparison, not kept up to
+// date by the watcher. Docs are not included.
+func (m *Manager) IndexABVariant(ctx context.Context, info *Info, language string) error {
+	if m.abLLM == nil {
+		return fmt.Errorf("A/B testing is not configured (set llm.ab_embed)")
+	}
+
+	indexKey := ABIndexKey(info, language)
+	m.indexingMu.Lock()
+	if m.indexing[indexKey] {
+		m.indexingMu.Unlock()
+		return fmt.Errorf("A/B index of workspace '%s' language '%s' is already being built", info.Root, language)
+	}
+	m.indexing[indexKey] = true
+	m.indexingMu.Unlock()
+	defer func() {
+		m.indexingMu.Lock()
+		delete(m.indexing, indexKey)
+		m.indexingMu.Unlock()
+	}()
+
+	analyzer := ragcode.NewAnalyzerManager().CodeAnalyzerForProjectType(language)
+	if analyzer == nil {
+		return fmt.Errorf("no code analyzer available for language '%s'", language)
+	}
+	pipeline, err := m.chunkPipeline()
+	if err != nil {
+		return err
+	}
+	scan, err := m.scanWorkspace(info)
+	if err != nil {
+		return fmt.Errorf("failed to scan workspace '%s': %w", info.Root, err)
+	}
+	files := scan.LanguageFiles[strings.ToLower(language)]
+	if len(files) == 0 {
+		return fmt.Errorf("no %s source files detected in workspace '%s'", language, info.Root)
+	}
+
+	collectionName := ABCollectionName(info.CollectionNameForLanguage(language), m.abModel)
+	log.Printf("🆎 Building A/B index for workspace: %s", info.Root)
+	log.Printf("   Collection: %s", collectionName)
+	log.Printf("   Model: %s", m.abModel)
+
+	client, err := storage.Open(m.config.Storage.VectorDB, collectionName)
+	if err != nil {
+		return fmt.Errorf("failed to create collection client: %w", err)
+	}
+	defer client.Close()
+
+	if exists, err := client.CollectionExists(ctx, collectionName); err != nil {
+		return fmt.Errorf("failed to check collection: %w", err)
+	} else if exists {
+		if err := client.DeleteCollection(ctx, collectionName); err != nil {
+			return fmt.Errorf("failed to delete collection: %w", err)
+		}
+	}
+	dim, err := llm.EmbeddingDimension(ctx, m.abLLM)
+	if err != nil {
+		return fmt.Errorf("failed to get embedding dimension: %w", err)
+	}
+	if err := client.CreateCollection(ctx, collectionName, dim); err != nil {
+		return fmt.Errorf("failed to create collection: %w", err)
+	}
+
+	indexer := ragcode.NewIndexer(analyzer, m.abLLM, storage.NewVectorStoreMemory(client))
+	indexer.SetPostProcessors(pipeline)
+	// Same embedded text as the main index, or the comparison is skewed
+	indexer.SetBoilerplate(m.boilerplate(info))
+
+	startTime := time.Now()
+	numChunks, err := indexer.IndexPaths(ctx, files, collectionName)
+	if err != nil {
+		return fmt.Errorf("A/B indexing failed: %w", err)
+	}
+	log.Printf("✅ A/B index: %d chunks embedded with %s in %v", numChunks, m.abModel, time.Since(startTime))
+	return nil
+}
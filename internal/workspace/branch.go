@@ -0,0 +1,141 @@
+package workspace
+
+import (
+	"context"
+	"log"
+	"path/filepath"
+
+	"github.com/doITmagic/rag-code-mcp/internal/memory"
+)
+
+// Payload fields recording the git branch and commit a chunk was indexed
+// from
+const (
+	GitBranchKey = "git_branch"
+	GitHeadKey   = "git_head"
+)
+
+// shortHead abbreviates a commit for logs
+func shortHead(head string) string {
+	if len(head) > 8 {
+		return head[:8]
+	}
+	return head
+}
+
+// branchView is what searches of a collection hide while the checked out
+// commit differs from the one the collection was indexed at
+type branchView struct {
+	indexed string   // commit of the last indexing run
+	head    string   // commit checked out
+	stale   []string // files that differ between the two
+}
+
+// indexedHead returns the commit the last indexing run of a collection saw
+// checked out, read from the workspace state the first time
+func (m *Manager) indexedHead(info *Info, collection string) string {
+	m.branchMu.Lock()
+	defer m.branchMu.Unlock()
+	if head, ok := m.indexedHeads[collection]; ok {
+		return head
+	}
+	head := ""
+	if state, err := LoadState(filepath.Join(info.Root, ".ragcode", "state.json")); err == nil {
+		head = state.GitHead(collection)
+	}
+	if m.indexedHeads == nil {
+		m.indexedHeads = make(map[string]string)
+	}
+	m.indexedHeads[collection] = head
+	return head
+}
+
+// setIndexedHead records the commit an indexing run of a collection
+// committed
+func (m *Manager) setIndexedHead(collection, head string) {
+	m.branchMu.Lock()
+	defer m.branchMu.Unlock()
+	if m.indexedHeads == nil {
+		m.indexedHeads = make(map[string]string)
+	}
+	m.indexedHeads[collection] = head
+}
+
+// syncBranch compares the commit checked out in a workspace with the one a
+// collection was indexed at. After a branch switch or a pull, the chunks of
+// the files that differ between them are hidden from the searches of mem and,
+// with auto_index, those files are re-indexed in the background; once the
+// run commits, the chunks are visible again. Chunks of unchanged files stay
+// visible, whatever branch they were indexed on.
+func (m *Manager) syncBranch(ctx context.Context, info *Info, language, collection string, mem memory.LongTermMemory) {
+	hider, ok := mem.(interface{ SetHiddenFiles([]string) })
+	if !ok {
+		return
+	}
+	head, err := GitHead(ctx, info.Root)
+	if err != nil {
+		return // not a git work tree
+	}
+	indexed := m.indexedHead(info, collection)
+
+	m.branchMu.Lock()
+	view := m.branchViews[collection]
+	m.branchMu.Unlock()
+
+	if indexed == "" || indexed == head {
+		if view != nil {
+			m.branchMu.Lock()
+			delete(m.branchViews, collection)
+			m.branchMu.Unlock()
+			hider.SetHiddenFiles(nil)
+			m.bumpIndexGeneration(info)
+		}
+		return
+	}
+	if view != nil && view.indexed == indexed && view.head == head {
+		return
+	}
+
+	stale, err := GitCommitDiff(ctx, info.Root, indexed, head)
+	if err != nil {
+		log.Printf("⚠️  Failed to compare %s with the indexed commit %s: %v", shortHead(head), shortHead(indexed), err)
+		return
+	}
+	m.branchMu.Lock()
+	if m.branchViews == nil {
+		m.branchViews = make(map[string]*branchView)
+	}
+	m.branchViews[collection] = &branchView{indexed: indexed, head: head, stale: stale}
+	m.branchMu.Unlock()
+	hider.SetHiddenFiles(stale)
+	m.bumpIndexGeneration(info)
+	log.Printf("🔀 %s moved from %s to %s: %d changed file(s) hidden from searches until re-indexed",
+		info.Root, shortHead(indexed), shortHead(head), len(stale))
+
+	if m.config != nil && m.config.Workspace.AutoIndex && !m.IsIndexing(info.ID+"-"+language) {
+		if err := m.StartIndexingSince(ctx, info, language, indexed); err != nil {
+			log.Printf("⚠️  Failed to re-index files changed since %s: %v", shortHead(indexed), err)
+		}
+	}
+}
+
+// StaleFiles returns the files of a collection hidden from searches because
+// they changed since the commit it was indexed at
+func (m *Manager) StaleFiles(info *Info, language string) []string {
+	m.branchMu.Lock()
+	defer m.branchMu.Unlock()
+	if view := m.branchViews[info.CollectionNameForLanguage(language)]; view != nil {
+		return append([]string(nil), view.stale...)
+	}
+	return nil
+}
+
+// dropBranchViews forgets the hidden files of collections
+func (m *Manager) dropBranchViews(collections map[string]bool) {
+	m.branchMu.Lock()
+	defer m.branchMu.Unlock()
+	for collection := range collections {
+		delete(m.branchViews, collection)
+		delete(m.indexedHeads, collection)
+	}
+}
@@ -0,0 +1,81 @@
+package workspace
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/doITmagic/rag-code-mcp/internal/memory"
+)
+
+// hidingMemory records the files hidden from its searches
+type hidingMemory struct {
+	MockLongTermMemory
+	hidden []string
+}
+
+func (h *hidingMemory) SetHiddenFiles(files []string) {
+	h.hidden = files
+}
+
+func TestSyncBranchHidesChangedFiles(t *testing.T) {
+	ctx := context.Background()
+	root, indexed := gitRepo(t, map[string]string{
+		"a.go": "package main\n",
+		"b.go": "package main\n",
+	})
+	os.WriteFile(filepath.Join(root, "a.go"), []byte("package main\n\nfunc A() {}\n"), 0644)
+	for _, args := range [][]string{
+		{"checkout", "-q", "-b", "feature"},
+		{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "-am", "change a"},
+	} {
+		if out, err := exec.Command("git", append([]string{"-C", root}, args...)...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	head, _ := GitHead(ctx, root)
+	if branch, err := GitBranch(ctx, root); err != nil || branch != "feature" {
+		t.Errorf("GitBranch = %q, %v; want feature", branch, err)
+	}
+
+	m := &Manager{}
+	info := &Info{Root: root, ID: "ws", Languages: []string{"go"}}
+	collection := info.CollectionNameForLanguage("go")
+	mem := &hidingMemory{}
+
+	// Never indexed with a commit: nothing to compare with
+	m.syncBranch(ctx, info, "go", collection, mem)
+	if mem.hidden != nil {
+		t.Errorf("hidden = %v without an indexed commit", mem.hidden)
+	}
+
+	m.setIndexedHead(collection, indexed)
+	m.syncBranch(ctx, info, "go", collection, mem)
+	if len(mem.hidden) != 1 || mem.hidden[0] != filepath.Join(root, "a.go") {
+		t.Fatalf("hidden = %v, want only a.go", mem.hidden)
+	}
+	if got := m.StaleFiles(info, "go"); len(got) != 1 {
+		t.Errorf("StaleFiles = %v", got)
+	}
+
+	// The re-index of the branch commits: every file is visible again
+	m.setIndexedHead(collection, head)
+	m.syncBranch(ctx, info, "go", collection, mem)
+	if len(mem.hidden) != 0 || len(m.StaleFiles(info, "go")) != 0 {
+		t.Errorf("hidden = %v after re-indexing the branch", mem.hidden)
+	}
+}
+
+func TestGenerationMemoryStampsGitBranch(t *testing.T) {
+	mock := &MockLongTermMemory{}
+	g := generationMemory{LongTermMemory: mock, gen: 1, branch: "main", head: "abc123"}
+	if err := g.Store(context.Background(), memory.Document{ID: "1", Metadata: map[string]interface{}{}}); err != nil {
+		t.Fatal(err)
+	}
+	md := mock.docs[0].Metadata
+	if md[GitBranchKey] != "main" || md[GitHeadKey] != "abc123" {
+		t.Errorf("metadata = %v, want the branch and commit", md)
+	}
+}
@@ -0,0 +1,49 @@
+package workspace
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	"github.com/doITmagic/rag-code-mcp/internal/ragcode"
+)
+
+func coveragePath(root string) string {
+	return filepath.Join(root, ".ragcode", "coverage.json")
+}
+
+// SaveCoverage stores a coverage report for the workspace, replacing any
+// previously loaded report. File paths should already be absolute.
+func (m *Manager) SaveCoverage(info *Info, report *ragcode.CoverageReport) error {
+	path := coveragePath(info.Root)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := json.Marshal(report)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return err
+	}
+	// Search results carry coverage, so cached results are stale
+	m.bumpIndexGeneration(info)
+	return nil
+}
+
+// Coverage returns the coverage report loaded for the workspace, or nil if
+// load_coverage has not been called yet.
+func (m *Manager) Coverage(info *Info) (*ragcode.CoverageReport, error) {
+	data, err := os.ReadFile(coveragePath(info.Root))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var report ragcode.CoverageReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
@@ -0,0 +1,209 @@
+package workspace
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/doITmagic/rag-code-mcp/internal/codetypes"
+	"github.com/doITmagic/rag-code-mcp/internal/storage"
+)
+
+// DeleteReport describes what DeleteIndex removed, or would remove with
+// DryRun
+type DeleteReport struct {
+	Root        string   `json:"root"`
+	Language    string   `json:"language,omitempty"` // empty: every language
+	DryRun      bool     `json:"dry_run,omitempty"`
+	Collections []string `json:"collections"` // existing collections deleted
+	StateFiles  []string `json:"state_files,omitempty"`
+	// StateEntries counts the files of the language dropped from state.json
+	StateEntries int `json:"state_entries,omitempty"`
+}
+
+// DeleteIndex deletes the index of a workspace: with a language, the
+// language's collection and its entries in the workspace state and symbol
+// table; without one, every collection of the workspace and its index state
+// files. The workspace is unloaded from memory first. Runs in progress make
+// it fail, and indexing waits until the deletion is done, so the collections
+// and the state never disagree. The next query indexes the workspace again.
+func (m *Manager) DeleteIndex(ctx context.Context, info *Info, language string, dryRun bool) (*DeleteReport, error) {
+	report := &DeleteReport{Root: info.Root, DryRun: dryRun}
+	if language != "" {
+		lang := codetypes.NormalizeLanguage(language)
+		if !lang.Valid() {
+			return nil, fmt.Errorf("unknown language %q", language)
+		}
+		report.Language = string(lang)
+	}
+
+	unlock := m.workspaceLocks.Lock(info.ID)
+	defer unlock()
+	if m.indexingWorkspace(info.ID) {
+		return nil, fmt.Errorf("workspace '%s' is being indexed; try again when indexing is done", info.Root)
+	}
+
+	// The language's files are known before anything is deleted
+	var languageFiles []string
+	if report.Language != "" {
+		scan, err := m.scanWorkspace(info)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan workspace '%s': %w", info.Root, err)
+		}
+		languageFiles = append(languageFiles, scan.LanguageFiles[report.Language]...)
+		languageFiles = append(languageFiles, scan.DocFiles...)
+	}
+
+	candidates, err := m.workspaceCollections(info, report.Language)
+	if err != nil {
+		return nil, err
+	}
+	for _, collection := range candidates {
+		exists, err := m.collectionExists(ctx, collection)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check collection '%s': %w", collection, err)
+		}
+		if exists {
+			report.Collections = append(report.Collections, collection)
+		}
+	}
+	if dryRun {
+		return report, nil
+	}
+
+	if report.Language == "" {
+		files, errs := m.evictWorkspace(ctx, &registeredWorkspace{root: info.Root, id: info.ID, collections: candidates}, true)
+		report.StateFiles = files
+		if len(errs) > 0 {
+			return report, fmt.Errorf("failed to delete the index of '%s': %s", info.Root, strings.Join(errs, "; "))
+		}
+		return report, nil
+	}
+
+	collection := info.CollectionNameForLanguage(report.Language)
+	m.unloadCollection(info, collection)
+	if err := m.deleteCollection(ctx, collection); err != nil {
+		return report, fmt.Errorf("failed to delete collection '%s': %w", collection, err)
+	}
+	if err := m.forgetCollections([]string{collection}); err != nil {
+		log.Printf("⚠️  Failed to update the workspace registry: %v", err)
+	}
+	report.StateEntries, err = m.forgetLanguageState(info, report.Language, collection, languageFiles)
+	if err != nil {
+		return report, err
+	}
+	log.Printf("🗑️  Deleted the %s index of %s: collection %s, %d state entries", report.Language, info.Root, collection, report.StateEntries)
+	return report, nil
+}
+
+// workspaceCollections lists the collections a workspace may have: those of
+// its detected languages and those the registry recorded for its root,
+// limited to one language when language is set
+func (m *Manager) workspaceCollections(info *Info, language string) ([]string, error) {
+	if language != "" {
+		return []string{info.CollectionNameForLanguage(language)}, nil
+	}
+	seen := make(map[string]bool)
+	for _, lang := range info.Languages {
+		seen[info.CollectionNameForLanguage(lang)] = true
+	}
+	uses, err := m.CollectionUses()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read workspace registry: %w", err)
+	}
+	for _, use := range uses {
+		if use.Root == info.Root {
+			seen[use.Collection] = true
+		}
+	}
+	collections := make([]string, 0, len(seen))
+	for collection := range seen {
+		collections = append(collections, collection)
+	}
+	sort.Strings(collections)
+	return collections, nil
+}
+
+// collectionExists reports whether the vector store has a collection
+func (m *Manager) collectionExists(ctx context.Context, collection string) (bool, error) {
+	if m.config == nil {
+		return false, fmt.Errorf("no vector store configured")
+	}
+	client, err := storage.Open(m.config.Storage.VectorDB, collection)
+	if err != nil {
+		return false, fmt.Errorf("failed to create collection client: %w", err)
+	}
+	defer client.Close()
+	return client.CollectionExists(ctx, collection)
+}
+
+// unloadCollection releases what one collection of a workspace holds in
+// memory, keeping the watcher for the other languages
+func (m *Manager) unloadCollection(info *Info, collection string) {
+	m.memoryMu.Lock()
+	if mem, ok := m.memories[collection]; ok {
+		delete(m.memories, collection)
+		if closer, ok := mem.(io.Closer); ok {
+			if err := closer.Close(); err != nil {
+				log.Printf("⚠️  Failed to close collection client '%s': %v", collection, err)
+			}
+		}
+	}
+	m.memoryMu.Unlock()
+
+	m.idleMu.Lock()
+	if use, ok := m.lastUse[info.Root]; ok {
+		delete(use.collections, collection)
+	}
+	m.idleMu.Unlock()
+
+	m.queryMu.Lock()
+	delete(m.queryCaches, info.ID)
+	m.queryMu.Unlock()
+	m.dropKeywordIndexes(map[string]bool{collection: true})
+	m.dropBranchViews(map[string]bool{collection: true})
+}
+
+// forgetLanguageState drops a language from the workspace state and symbol
+// table, so its next run indexes every file again. Documentation files are
+// dropped as well: they were indexed into the deleted collection.
+func (m *Manager) forgetLanguageState(info *Info, language, collection string, files []string) (int, error) {
+	statePath := filepath.Join(info.Root, ".ragcode", "state.json")
+	state, err := LoadState(statePath)
+	if err != nil {
+		return 0, fmt.Errorf("failed to load workspace state: %w", err)
+	}
+	dropped := 0
+	for _, path := range files {
+		if _, ok := state.GetFileState(path); ok {
+			state.RemoveFile(path)
+			dropped++
+		}
+	}
+	for path := range state.LanguageParseFailures(language) {
+		state.ClearParseFailure(path)
+	}
+	state.mu.Lock()
+	delete(state.Generations, collection)
+	delete(state.GitHeads, collection)
+	state.mu.Unlock()
+	if err := state.Save(statePath); err != nil {
+		return dropped, fmt.Errorf("failed to save workspace state: %w", err)
+	}
+
+	m.symbolsMu.Lock()
+	defer m.symbolsMu.Unlock()
+	table, err := LoadSymbolTable(symbolsPath(info.Root))
+	if err != nil {
+		return dropped, fmt.Errorf("failed to load symbol table: %w", err)
+	}
+	table.RemoveLanguage(language)
+	if err := table.Save(symbolsPath(info.Root)); err != nil {
+		return dropped, fmt.Errorf("failed to save symbol table: %w", err)
+	}
+	return dropped, nil
+}
@@ -0,0 +1,113 @@
+package workspace
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/doITmagic/rag-code-mcp/internal/config"
+	"github.com/doITmagic/rag-code-mcp/internal/ragcode"
+	"github.com/doITmagic/rag-code-mcp/internal/storage"
+)
+
+func TestDeleteIndex(t *testing.T) {
+	ctx := context.Background()
+	cfg := &config.Config{}
+	cfg.Storage.VectorDB = config.VectorDBConfig{Provider: "local", Path: t.TempDir()}
+	cfg.Workspace.RegistryPath = filepath.Join(t.TempDir(), "workspaces.json")
+	m := &Manager{config: cfg, indexing: make(map[string]bool)}
+
+	root := t.TempDir()
+	goFile, pyFile, doc := filepath.Join(root, "main.go"), filepath.Join(root, "util.py"), filepath.Join(root, "README.md")
+	for path, content := range map[string]string{goFile: "package main\n", pyFile: "def f(): pass\n", doc: "# Readme\n"} {
+		os.WriteFile(path, []byte(content), 0644)
+	}
+	info := &Info{Root: root, ID: "ws1", Languages: []string{"go", "python"}}
+	for _, lang := range info.Languages {
+		collection := info.CollectionNameForLanguage(lang)
+		store, err := storage.Open(cfg.Storage.VectorDB, collection)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := store.CreateCollection(ctx, collection, 2); err != nil {
+			t.Fatal(err)
+		}
+		store.Close()
+	}
+
+	statePath := filepath.Join(root, ".ragcode", "state.json")
+	state := NewWorkspaceState()
+	for _, path := range []string{goFile, pyFile, doc} {
+		fi, _ := os.Stat(path)
+		state.UpdateFile(path, fi)
+	}
+	state.SetGeneration(info.CollectionNameForLanguage("go"), 3)
+	state.SetGeneration(info.CollectionNameForLanguage("python"), 2)
+	if err := state.Save(statePath); err != nil {
+		t.Fatal(err)
+	}
+	table := NewSymbolTable()
+	table.SetFile(goFile, []ragcode.SymbolEntry{{Name: "main", Language: "go", FilePath: goFile}})
+	table.SetFile(pyFile, []ragcode.SymbolEntry{{Name: "f", Language: "python", FilePath: pyFile}})
+	table.Save(symbolsPath(root))
+
+	// A dry run only lists the collection
+	report, err := m.DeleteIndex(ctx, info, "golang", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Language != "go" || len(report.Collections) != 1 {
+		t.Fatalf("dry run = %+v", report)
+	}
+	if exists, _ := m.collectionExists(ctx, info.CollectionNameForLanguage("go")); !exists {
+		t.Fatal("dry run deleted the collection")
+	}
+
+	// One language: its collection, files, docs and symbols go; the rest stays
+	report, err = m.DeleteIndex(ctx, info, "go", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.StateEntries != 2 {
+		t.Errorf("state entries = %d, want main.go and README.md", report.StateEntries)
+	}
+	if exists, _ := m.collectionExists(ctx, info.CollectionNameForLanguage("go")); exists {
+		t.Error("go collection not deleted")
+	}
+	if exists, _ := m.collectionExists(ctx, info.CollectionNameForLanguage("python")); !exists {
+		t.Error("python collection deleted")
+	}
+	state, _ = LoadState(statePath)
+	if _, ok := state.GetFileState(pyFile); !ok || len(state.Files) != 1 {
+		t.Errorf("state files = %v, want only util.py", state.Files)
+	}
+	if state.Generation(info.CollectionNameForLanguage("go")) != 0 || state.Generation(info.CollectionNameForLanguage("python")) != 2 {
+		t.Errorf("generations = %v", state.Generations)
+	}
+	table, _ = LoadSymbolTable(symbolsPath(root))
+	if table.HasLanguage("go") || !table.HasLanguage("python") {
+		t.Errorf("symbols = %v", table.Files)
+	}
+
+	// The whole workspace: every collection and the state files
+	report, err = m.DeleteIndex(ctx, info, "", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Collections) != 1 || report.Collections[0] != info.CollectionNameForLanguage("python") {
+		t.Errorf("collections = %v, want the remaining python one", report.Collections)
+	}
+	if _, err := os.Stat(statePath); !os.IsNotExist(err) {
+		t.Error("state.json not removed")
+	}
+
+	// Never while the workspace is being indexed
+	m.indexing[info.ID+"-go"] = true
+	if _, err := m.DeleteIndex(ctx, info, "", false); err == nil {
+		t.Error("expected an error while indexing")
+	}
+	if _, err := m.DeleteIndex(ctx, info, "cobol", false); err == nil {
+		t.Error("expected an error for an unknown language")
+	}
+}
@@ -1,22 +1,44 @@
 package workspace
 
 import (
+	"context"
 	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 )
 
+// Workspace identities: how a workspace ID is derived
+const (
+	// IdentityPath derives the ID from the absolute workspace path, so
+	// every clone has collections and index state of its own (default)
+	IdentityPath = "path"
+	// IdentityGitRemote derives the ID from the normalized git remote URL
+	// and the path of the workspace in the repository, so every clone of
+	// a repository shares the same collections. Workspaces outside a git
+	// repository, or without a remote, fall back to IdentityPath.
+	IdentityGitRemote = "git_remote"
+)
+
+// gitIdentityTimeout bounds the git commands reading the remote of a workspace
+const gitIdentityTimeout = 2 * time.Second
+
 // Detector detects workspace roots from file paths
 type Detector struct {
-	// Markers to identify workspace root, in priority order
+	// Markers to identify workspace root, in priority order. Markers with
+	// glob characters (*.sln) match any file of that pattern.
 	markers []string
 
 	// ExcludePatterns are path patterns to exclude from workspace detection
 	excludePatterns []string
+
+	// identity is IdentityGitRemote or IdentityPath
+	identity string
+	ids      sync.Map // root -> workspace ID
 }
 
 // NewDetector creates a new workspace detector with default markers
@@ -33,6 +55,8 @@ func NewDetector() *Detector {
 			"setup.py",       // Python project (legacy)
 			"pom.xml",        // Maven project (Java)
 			"build.gradle",   // Gradle project (Java/Kotlin)
+			"*.sln",          // .NET solution
+			"*.csproj",       // .NET project
 			".project",       // Generic project marker
 			".vscode",        // VS Code workspace
 		},
@@ -43,6 +67,7 @@ func NewDetector() *Detector {
 			"/node_modules/",
 			"/vendor/",
 		},
+		identity: IdentityPath,
 	}
 }
 
@@ -68,6 +93,16 @@ func (d *Detector) SetExcludePatterns(patterns []string) {
 	d.excludePatterns = patterns
 }
 
+// SetIdentity selects how workspace IDs are derived: IdentityPath
+// (default) or IdentityGitRemote
+func (d *Detector) SetIdentity(identity string) {
+	if identity == "" {
+		identity = IdentityPath
+	}
+	d.identity = identity
+	d.ids = sync.Map{}
+}
+
 // DetectFromPath detects workspace from a file path
 func (d *Detector) DetectFromPath(filePath string) (*Info, error) {
 	// Normalize to absolute path
@@ -95,7 +130,7 @@ func (d *Detector) DetectFromPath(filePath string) (*Info, error) {
 			// Found workspace root
 			return &Info{
 				Root:        current,
-				ID:          generateWorkspaceID(current),
+				ID:          d.workspaceID(current),
 				ProjectType: projectType,
 				Languages:   languages,
 				Markers:     foundMarkers,
@@ -134,7 +169,7 @@ func (d *Detector) DetectFromPath(filePath string) (*Info, error) {
 
 	return &Info{
 		Root:        fallbackDir,
-		ID:          generateWorkspaceID(fallbackDir),
+		ID:          d.workspaceID(fallbackDir),
 		ProjectType: "unknown",
 		Languages:   []string{},
 		Markers:     []string{},
@@ -184,8 +219,7 @@ func (d *Detector) findMarkers(dir string) ([]string, string, []string) {
 	projectType := "unknown"
 
 	for _, marker := range d.markers {
-		markerPath := filepath.Join(dir, marker)
-		if exists(markerPath) {
+		if markerExists(dir, marker) {
 			found = append(found, marker)
 
 			// Determine project type from first marker
@@ -215,6 +249,25 @@ func (d *Detector) shouldExclude(path string) bool {
 	return false
 }
 
+// workspaceID returns the ID of the workspace at root, from its git remote
+// when the identity allows it and root is in a clone with a remote
+func (d *Detector) workspaceID(root string) string {
+	if d.identity != IdentityGitRemote {
+		return generateWorkspaceID(root)
+	}
+	if id, ok := d.ids.Load(root); ok {
+		return id.(string)
+	}
+	id := generateWorkspaceID(root)
+	ctx, cancel := context.WithTimeout(context.Background(), gitIdentityTimeout)
+	defer cancel()
+	if remote, rel, err := GitRemoteIdentity(ctx, root); err == nil {
+		id = generateWorkspaceID("git:" + remote + "\x00" + rel)
+	}
+	d.ids.Store(root, id)
+	return id
+}
+
 // generateWorkspaceID creates a stable, unique ID from workspace root path
 func generateWorkspaceID(rootPath string) string {
 	// Use SHA256 hash of absolute path
@@ -244,6 +297,8 @@ func inferProjectType(marker string) string {
 		return "maven"
 	case "build.gradle":
 		return "gradle"
+	case "*.sln", "*.csproj":
+		return "csharp"
 	case ".git":
 		return "git"
 	default:
@@ -271,6 +326,8 @@ func inferLanguageFromMarker(marker string) string {
 		return "ruby"
 	case "Package.swift":
 		return "swift"
+	case "*.sln", "*.csproj":
+		return "csharp"
 	default:
 		return ""
 	}
@@ -278,6 +335,24 @@ func inferLanguageFromMarker(marker string) string {
 
 // Helper functions
 
+// markerExists reports whether dir holds a marker: a file or directory of
+// that name, or a file matching it when it is a glob pattern
+func markerExists(dir, marker string) bool {
+	if !strings.ContainsAny(marker, "*?[") {
+		return exists(filepath.Join(dir, marker))
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return false
+	}
+	for _, entry := range entries {
+		if ok, _ := filepath.Match(marker, entry.Name()); ok && !entry.IsDir() {
+			return true
+		}
+	}
+	return false
+}
+
 func exists(path string) bool {
 	_, err := os.Stat(path)
 	return err == nil
@@ -87,6 +87,36 @@ func TestDetector_DetectFromPath_NoMarkers(t *testing.T) {
 	}
 }
 
+func TestDetector_DetectFromPath_DotNet(t *testing.T) {
+	tmpDir := t.TempDir()
+	projectDir := filepath.Join(tmpDir, "Acme.Billing")
+	srcDir := filepath.Join(projectDir, "Services")
+	if err := os.MkdirAll(srcDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(projectDir, "Acme.Billing.csproj"), []byte("<Project />"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	testFile := filepath.Join(srcDir, "InvoiceService.cs")
+	if err := os.WriteFile(testFile, []byte("class InvoiceService {}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := NewDetector().DetectFromPath(testFile)
+	if err != nil {
+		t.Fatalf("DetectFromPath failed: %v", err)
+	}
+	if info.Root != projectDir {
+		t.Errorf("Expected root %s, got %s", projectDir, info.Root)
+	}
+	if info.ProjectType != "csharp" || len(info.Languages) != 1 || info.Languages[0] != "csharp" {
+		t.Errorf("Expected a csharp project, got type %q and languages %v", info.ProjectType, info.Languages)
+	}
+	if len(info.Markers) != 1 || info.Markers[0] != "*.csproj" {
+		t.Errorf("Expected the *.csproj marker, got %v", info.Markers)
+	}
+}
+
 func TestDetector_DetectFromParams(t *testing.T) {
 	// Create test workspace
 	tmpDir := t.TempDir()
@@ -294,6 +324,8 @@ func TestInferProjectType(t *testing.T) {
 		{"setup.py", "python"},
 		{"pom.xml", "maven"},
 		{"build.gradle", "gradle"},
+		{"*.sln", "csharp"},
+		{"*.csproj", "csharp"},
 		{".git", "git"},
 		{"unknown.file", "unknown"},
 	}
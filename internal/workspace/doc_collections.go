@@ -0,0 +1,129 @@
+package workspace
+
+import (
+	"context"
+	"log"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/doITmagic/rag-code-mcp/internal/memory"
+	"github.com/doITmagic/rag-code-mcp/internal/storage"
+)
+
+// Sources of search_docs results, for its sources filter
+const (
+	DocSourceReadme   = "readme"   // README files of the workspace
+	DocSourceDocs     = "docs"     // other Markdown files of the workspace
+	DocSourceAPI      = "api"      // the api_docs.collection
+	DocSourceExternal = "external" // docs.collection chunks from outside the workspace
+)
+
+// DocSources lists the doc sources in display order
+var DocSources = []string{DocSourceReadme, DocSourceDocs, DocSourceAPI, DocSourceExternal}
+
+// Origins of the shared doc collections
+const (
+	DocOriginDocs = "docs" // docs.collection, built by index-all
+	DocOriginAPI  = "api"  // api_docs.collection
+)
+
+// docCollectionRecheck is how long the existence of a shared doc collection
+// is trusted before it is checked again
+const docCollectionRecheck = time.Minute
+
+// DocCollection is a shared collection search_docs queries next to the
+// Markdown chunks of the workspace
+type DocCollection struct {
+	Name   string
+	Origin string // DocOriginDocs or DocOriginAPI
+	Memory memory.LongTermMemory
+}
+
+// docCollectionEntry caches a shared doc collection; mem is nil while it
+// does not exist
+type docCollectionEntry struct {
+	mem     memory.LongTermMemory
+	checked time.Time
+}
+
+// docCollectionCache holds the shared doc collections of a Manager
+type docCollectionCache struct {
+	mu      sync.Mutex
+	entries map[string]*docCollectionEntry
+}
+
+// DocCollections returns the shared doc collections that exist:
+// docs.collection and api_docs.collection. Missing ones are checked again
+// after a minute, so a collection built by index-all shows up without a
+// restart.
+func (m *Manager) DocCollections(ctx context.Context) []DocCollection {
+	if m == nil || m.config == nil {
+		return nil
+	}
+	m.docCollections.mu.Lock()
+	defer m.docCollections.mu.Unlock()
+	if m.docCollections.entries == nil {
+		m.docCollections.entries = make(map[string]*docCollectionEntry)
+	}
+
+	var out []DocCollection
+	seen := make(map[string]bool)
+	for _, c := range []struct{ name, origin string }{
+		{m.config.Docs.Collection, DocOriginDocs},
+		{m.config.APIDocs.Collection, DocOriginAPI},
+	} {
+		if c.name == "" || seen[c.name] {
+			continue
+		}
+		seen[c.name] = true
+		entry := m.docCollections.entries[c.name]
+		if entry == nil || (entry.mem == nil && time.Since(entry.checked) > docCollectionRecheck) {
+			entry = &docCollectionEntry{mem: m.openDocCollection(ctx, c.name), checked: time.Now()}
+			m.docCollections.entries[c.name] = entry
+		}
+		if entry.mem != nil {
+			out = append(out, DocCollection{Name: c.name, Origin: c.origin, Memory: entry.mem})
+		}
+	}
+	return out
+}
+
+// openDocCollection opens a shared doc collection, nil when it does not
+// exist or the store cannot be reached
+func (m *Manager) openDocCollection(ctx context.Context, name string) memory.LongTermMemory {
+	client, err := storage.Open(m.config.Storage.VectorDB, name)
+	if err != nil {
+		log.Printf("⚠️  Doc collection '%s': %v", name, err)
+		return nil
+	}
+	exists, err := client.CollectionExists(ctx, name)
+	if err != nil || !exists {
+		client.Close()
+		return nil
+	}
+	return storage.NewVectorStoreMemory(client)
+}
+
+// DocSource classifies a doc chunk found in a collection of the given
+// origin ("" for the workspace collection) for the workspace at root
+func DocSource(root, origin string, doc memory.Document) string {
+	if origin == DocOriginAPI {
+		return DocSourceAPI
+	}
+	file, _ := doc.Metadata["file"].(string)
+	if file == "" {
+		return DocSourceExternal
+	}
+	if origin != "" && root != "" {
+		rel, err := filepath.Rel(root, file)
+		if err != nil || !filepath.IsAbs(file) || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return DocSourceExternal
+		}
+	}
+	if strings.HasPrefix(strings.ToLower(filepath.Base(file)), "readme") {
+		return DocSourceReadme
+	}
+	return DocSourceDocs
+}
@@ -0,0 +1,65 @@
+package workspace
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/doITmagic/rag-code-mcp/internal/config"
+	"github.com/doITmagic/rag-code-mcp/internal/memory"
+	"github.com/doITmagic/rag-code-mcp/internal/storage"
+)
+
+func TestDocCollections(t *testing.T) {
+	ctx := context.Background()
+	cfg := &config.Config{}
+	cfg.Storage.VectorDB = config.VectorDBConfig{Provider: "local", Path: t.TempDir()}
+	cfg.Docs.Collection = "project-docs"
+	cfg.APIDocs.Collection = "api-docs"
+	m := &Manager{config: cfg}
+	defer m.Shutdown(ctx)
+
+	store, err := storage.Open(cfg.Storage.VectorDB, "api-docs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.CreateCollection(ctx, "api-docs", 2); err != nil {
+		t.Fatal(err)
+	}
+	store.Close()
+
+	// project-docs was never built: only api-docs is searched
+	collections := m.DocCollections(ctx)
+	if len(collections) != 1 || collections[0].Name != "api-docs" || collections[0].Origin != DocOriginAPI {
+		t.Fatalf("collections = %+v", collections)
+	}
+	if again := m.DocCollections(ctx); len(again) != 1 || again[0].Memory != collections[0].Memory {
+		t.Fatal("the open collection was not reused")
+	}
+}
+
+func TestDocSource(t *testing.T) {
+	root := t.TempDir()
+	doc := func(file string) memory.Document {
+		return memory.Document{Metadata: map[string]interface{}{"file": file}}
+	}
+	tests := []struct {
+		name   string
+		origin string
+		doc    memory.Document
+		want   string
+	}{
+		{"workspace readme", "", doc(filepath.Join(root, "README.md")), DocSourceReadme},
+		{"workspace guide", "", doc(filepath.Join(root, "docs", "install.md")), DocSourceDocs},
+		{"shared readme in workspace", DocOriginDocs, doc(filepath.Join(root, "sub", "readme.md")), DocSourceReadme},
+		{"shared doc elsewhere", DocOriginDocs, doc("/srv/handbook/deploy.md"), DocSourceExternal},
+		{"shared relative path", DocOriginDocs, doc("docs/deploy.md"), DocSourceExternal},
+		{"no file", DocOriginDocs, memory.Document{}, DocSourceExternal},
+		{"api docs", DocOriginAPI, doc(filepath.Join(root, "README.md")), DocSourceAPI},
+	}
+	for _, tt := range tests {
+		if got := DocSource(root, tt.origin, tt.doc); got != tt.want {
+			t.Errorf("%s: DocSource = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
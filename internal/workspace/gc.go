@@ -0,0 +1,226 @@
+package workspace
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/doITmagic/rag-code-mcp/internal/storage"
+)
+
+// Reasons a workspace is evicted
+const (
+	EvictStale   = "stale"   // unused for longer than the TTL
+	EvictLRU     = "lru"     // least recently used beyond max_workspaces
+	EvictMissing = "missing" // its root no longer exists
+)
+
+// gcInterval is how often RunGarbageCollector looks for stale workspaces
+const gcInterval = time.Hour
+
+// indexStateFiles are the files of .ragcode derived from the index: they
+// describe collections that no longer exist once a workspace is evicted.
+// Templates, backups, snapshots, coverage and usage data are kept.
+var indexStateFiles = []string{
+	"state.json",
+	"state.json.bak",
+	"symbols.json",
+	"boilerplate.json",
+	"log_templates.json",
+}
+
+// GCOptions selects the workspaces a garbage collection evicts. Workspaces
+// whose root was deleted are always evicted.
+type GCOptions struct {
+	TTL           time.Duration // evict workspaces unused this long; 0 disables
+	MaxWorkspaces int           // keep the most recently used ones; 0 disables
+	DryRun        bool          // report without deleting
+}
+
+// EvictedWorkspace is a workspace removed by a garbage collection
+type EvictedWorkspace struct {
+	Root        string    `json:"root"`
+	Reason      string    `json:"reason"`
+	LastUsed    time.Time `json:"last_used"`
+	Collections []string  `json:"collections"`
+	StateFiles  []string  `json:"state_files,omitempty"` // removed from .ragcode
+}
+
+// GCReport is the outcome of a garbage collection
+type GCReport struct {
+	DryRun  bool               `json:"dry_run"`
+	Evicted []EvictedWorkspace `json:"evicted"`
+	Kept    int                `json:"kept"`
+	Errors  []string           `json:"errors,omitempty"`
+}
+
+// GCOptions returns the configured eviction policy: workspace.collection_ttl
+// and workspace.max_workspaces
+func (m *Manager) GCOptions() GCOptions {
+	if m == nil || m.config == nil {
+		return GCOptions{}
+	}
+	return GCOptions{TTL: m.config.Workspace.CollectionTTL, MaxWorkspaces: m.config.Workspace.MaxWorkspaces}
+}
+
+// RunGarbageCollector evicts stale workspaces every hour until ctx is done.
+// It returns right away when workspace.collection_ttl is not set.
+func (m *Manager) RunGarbageCollector(ctx context.Context) {
+	opts := m.GCOptions()
+	if opts.TTL <= 0 {
+		return
+	}
+	ticker := time.NewTicker(gcInterval)
+	defer ticker.Stop()
+	for {
+		if report, err := m.CollectGarbage(ctx, opts); err != nil {
+			log.Printf("⚠️  Workspace garbage collection failed: %v", err)
+		} else if len(report.Evicted) > 0 {
+			log.Printf("🧹 Evicted %d unused workspace(s)", len(report.Evicted))
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// registeredWorkspace groups the registered collections of a workspace
+type registeredWorkspace struct {
+	root        string
+	id          string
+	lastUsed    time.Time
+	collections []string
+}
+
+// CollectGarbage deletes the collections and index state of the registered
+// workspaces opts selects, least recently used first. Workspaces being
+// indexed are kept. Evicted workspaces are indexed again from scratch on
+// their next query.
+func (m *Manager) CollectGarbage(ctx context.Context, opts GCOptions) (*GCReport, error) {
+	uses, err := m.CollectionUses()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read workspace registry: %w", err)
+	}
+	byRoot := make(map[string]*registeredWorkspace)
+	for _, use := range uses {
+		ws, ok := byRoot[use.Root]
+		if !ok {
+			ws = &registeredWorkspace{root: use.Root, id: use.WorkspaceID}
+			byRoot[use.Root] = ws
+		}
+		ws.collections = append(ws.collections, use.Collection)
+		if use.LastUsed.After(ws.lastUsed) {
+			ws.lastUsed = use.LastUsed
+		}
+	}
+	workspaces := make([]*registeredWorkspace, 0, len(byRoot))
+	for _, ws := range byRoot {
+		sort.Strings(ws.collections)
+		workspaces = append(workspaces, ws)
+	}
+	// Most recently used first
+	sort.Slice(workspaces, func(i, j int) bool { return workspaces[i].lastUsed.After(workspaces[j].lastUsed) })
+
+	report := &GCReport{DryRun: opts.DryRun}
+	now := time.Now()
+	kept := 0
+	for _, ws := range workspaces {
+		reason := ""
+		switch {
+		case m.indexingWorkspace(ws.id):
+		case !rootExists(ws.root):
+			reason = EvictMissing
+		case opts.TTL > 0 && now.Sub(ws.lastUsed) > opts.TTL:
+			reason = EvictStale
+		case opts.MaxWorkspaces > 0 && kept >= opts.MaxWorkspaces:
+			reason = EvictLRU
+		}
+		if reason == "" {
+			kept++
+			continue
+		}
+		evicted := EvictedWorkspace{Root: ws.root, Reason: reason, LastUsed: ws.lastUsed, Collections: ws.collections}
+		if !opts.DryRun {
+			files, errs := m.evictWorkspace(ctx, ws, reason != EvictMissing)
+			evicted.StateFiles = files
+			report.Errors = append(report.Errors, errs...)
+		}
+		report.Evicted = append(report.Evicted, evicted)
+	}
+	report.Kept = kept
+	return report, nil
+}
+
+// evictWorkspace unloads a workspace, deletes its collections and, when
+// removeState is set, the index state files of its root. It returns the
+// removed files and the errors met; collections that failed to delete stay
+// registered.
+func (m *Manager) evictWorkspace(ctx context.Context, ws *registeredWorkspace, removeState bool) ([]string, []string) {
+	m.idleMu.Lock()
+	use, loaded := m.lastUse[ws.root]
+	delete(m.lastUse, ws.root)
+	m.idleMu.Unlock()
+	if loaded {
+		m.unloadWorkspace(ws.root, use)
+	}
+
+	var errs, deleted []string
+	for _, collection := range ws.collections {
+		if err := m.deleteCollection(ctx, collection); err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", collection, err))
+			continue
+		}
+		deleted = append(deleted, collection)
+	}
+	if err := m.forgetCollections(deleted); err != nil {
+		errs = append(errs, fmt.Sprintf("registry: %v", err))
+	}
+
+	var removed []string
+	if removeState && len(deleted) == len(ws.collections) {
+		dir := filepath.Join(ws.root, ".ragcode")
+		for _, name := range indexStateFiles {
+			path := filepath.Join(dir, name)
+			if err := os.Remove(path); err == nil {
+				removed = append(removed, path)
+			} else if !errors.Is(err, fs.ErrNotExist) {
+				errs = append(errs, err.Error())
+			}
+		}
+		// Only removed when nothing else is left in it
+		os.Remove(dir)
+	}
+	log.Printf("🧹 Evicted workspace %s: %d collection(s) deleted, %d state file(s) removed", ws.root, len(deleted), len(removed))
+	return removed, errs
+}
+
+// deleteCollection deletes a collection of the vector store if it exists
+func (m *Manager) deleteCollection(ctx context.Context, collection string) error {
+	if m.config == nil {
+		return fmt.Errorf("no vector store configured")
+	}
+	client, err := storage.Open(m.config.Storage.VectorDB, collection)
+	if err != nil {
+		return fmt.Errorf("failed to create collection client: %w", err)
+	}
+	defer client.Close()
+	exists, err := client.CollectionExists(ctx, collection)
+	if err != nil || !exists {
+		return err
+	}
+	return client.DeleteCollection(ctx, collection)
+}
+
+// rootExists reports whether a workspace root is still a directory
+func rootExists(root string) bool {
+	fi, err := os.Stat(root)
+	return err == nil && fi.IsDir()
+}
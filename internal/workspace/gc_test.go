@@ -0,0 +1,121 @@
+package workspace
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/doITmagic/rag-code-mcp/internal/config"
+	"github.com/doITmagic/rag-code-mcp/internal/storage"
+)
+
+func TestCollectGarbage(t *testing.T) {
+	ctx := context.Background()
+	cfg := &config.Config{}
+	cfg.Storage.VectorDB = config.VectorDBConfig{Provider: "local", Path: t.TempDir()}
+	cfg.Workspace.RegistryPath = filepath.Join(t.TempDir(), "workspaces.json")
+	m := &Manager{config: cfg, indexing: make(map[string]bool)}
+
+	// Four workspaces: used now, an hour ago, a week ago, and one deleted
+	now := time.Now()
+	fresh, recent, stale := t.TempDir(), t.TempDir(), t.TempDir()
+	gone := filepath.Join(t.TempDir(), "gone")
+	lastUsed := map[string]time.Time{fresh: now, recent: now.Add(-time.Hour), stale: now.Add(-7 * 24 * time.Hour), gone: now.Add(-time.Minute)}
+	for root, at := range lastUsed {
+		collection := "ragcode-" + filepath.Base(root) + "-go"
+		store, err := storage.Open(cfg.Storage.VectorDB, collection)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := store.CreateCollection(ctx, collection, 2); err != nil {
+			t.Fatal(err)
+		}
+		err = updateRegistry(cfg.Workspace.RegistryPath, func(r *registry) {
+			r.Collections[collection] = CollectionUse{Collection: collection, Root: root, WorkspaceID: filepath.Base(root), Language: "go", LastUsed: at}
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, root := range []string{recent, stale} {
+		os.MkdirAll(filepath.Join(root, ".ragcode", "templates"), 0755)
+		os.WriteFile(filepath.Join(root, ".ragcode", "state.json"), []byte("{}"), 0644)
+		os.WriteFile(filepath.Join(root, ".ragcode", "templates", "handler.tmpl"), []byte("x"), 0644)
+	}
+
+	opts := GCOptions{TTL: 24 * time.Hour, MaxWorkspaces: 1}
+	dryRun := opts
+	dryRun.DryRun = true
+	report, err := m.CollectGarbage(ctx, dryRun)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Evicted) != 3 || report.Kept != 1 {
+		t.Fatalf("dry run = %+v, want 3 evicted and 1 kept", report)
+	}
+	if uses, _ := m.CollectionUses(); len(uses) != 4 {
+		t.Fatalf("dry run removed registry entries: %d left", len(uses))
+	}
+
+	report, err = m.CollectGarbage(ctx, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reasons := make(map[string]string)
+	for _, ev := range report.Evicted {
+		reasons[ev.Root] = ev.Reason
+	}
+	if reasons[gone] != EvictMissing || reasons[stale] != EvictStale || reasons[recent] != EvictLRU || reasons[fresh] != "" {
+		t.Errorf("evictions = %v", reasons)
+	}
+	if len(report.Errors) > 0 {
+		t.Errorf("errors = %v", report.Errors)
+	}
+
+	uses, err := m.CollectionUses()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(uses) != 1 || uses[0].Root != fresh {
+		t.Errorf("registry after collection = %+v, want only %s", uses, fresh)
+	}
+	store, _ := storage.Open(cfg.Storage.VectorDB, "")
+	for root := range lastUsed {
+		exists, _ := store.CollectionExists(ctx, "ragcode-"+filepath.Base(root)+"-go")
+		if exists != (root == fresh) {
+			t.Errorf("collection of %s exists = %v", root, exists)
+		}
+	}
+
+	// Index state is removed, user files of .ragcode are kept
+	if _, err := os.Stat(filepath.Join(stale, ".ragcode", "state.json")); !os.IsNotExist(err) {
+		t.Errorf("state.json of an evicted workspace should be removed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(stale, ".ragcode", "templates", "handler.tmpl")); err != nil {
+		t.Errorf("templates should be kept: %v", err)
+	}
+}
+
+func TestCollectGarbageKeepsIndexingWorkspaces(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Storage.VectorDB = config.VectorDBConfig{Provider: "local", Path: t.TempDir()}
+	cfg.Workspace.RegistryPath = filepath.Join(t.TempDir(), "workspaces.json")
+	m := &Manager{config: cfg, indexing: map[string]bool{"busy-go": true}}
+
+	root := t.TempDir()
+	err := updateRegistry(cfg.Workspace.RegistryPath, func(r *registry) {
+		r.Collections["ragcode-busy-go"] = CollectionUse{Collection: "ragcode-busy-go", Root: root, WorkspaceID: "busy", LastUsed: time.Now().Add(-48 * time.Hour)}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	report, err := m.CollectGarbage(context.Background(), GCOptions{TTL: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Evicted) != 0 || report.Kept != 1 {
+		t.Errorf("report = %+v, want the indexing workspace kept", report)
+	}
+}
@@ -0,0 +1,193 @@
+package workspace
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"log"
+	"time"
+
+	"github.com/doITmagic/rag-code-mcp/internal/memory"
+	"github.com/doITmagic/rag-code-mcp/internal/storage"
+)
+
+// cleanupTimeout bounds the vector store calls that drop the points of an
+// interrupted run, which run after its context was cancelled
+const cleanupTimeout = 5 * time.Second
+
+// Generation returns the last committed index generation of a collection
+// (0 before the first committed run)
+func (s *WorkspaceState) Generation(collection string) uint64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.Generations[collection]
+}
+
+// SetGeneration records the committed index generation of a collection
+func (s *WorkspaceState) SetGeneration(collection string, gen uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.Generations == nil {
+		s.Generations = make(map[string]uint64)
+	}
+	s.Generations[collection] = gen
+}
+
+// StableIDKey is the payload field keeping the ID of a chunk before the
+// generation was mixed in. It only changes with the location of the chunk,
+// so it is the chunk_id tools show and get_chunk resolves.
+const StableIDKey = "stable_id"
+
+// generationMemory stamps every stored document with an index generation,
+// and with the git branch and commit of the run when the workspace is a git
+// work tree. Point IDs include the generation, so new points never overwrite
+// the ones they replace before the run is committed; the ID without it is
+// kept as StableIDKey.
+type generationMemory struct {
+	memory.LongTermMemory
+	gen    uint64
+	branch string
+	head   string
+}
+
+func (g generationMemory) Store(ctx context.Context, doc memory.Document) error {
+	metadata := make(map[string]interface{}, len(doc.Metadata)+4)
+	for k, v := range doc.Metadata {
+		metadata[k] = v
+	}
+	metadata[storage.GenerationKey] = g.gen
+	if _, ok := metadata[StableIDKey]; !ok {
+		metadata[StableIDKey] = doc.ID
+	}
+	if g.head != "" {
+		metadata[GitBranchKey] = g.branch
+		metadata[GitHeadKey] = g.head
+	}
+	doc.Metadata = metadata
+
+	h := fnv.New64a()
+	h.Write([]byte(fmt.Sprintf("%s:%d", doc.ID, g.gen)))
+	doc.ID = fmt.Sprintf("%d", h.Sum64())
+	return g.LongTermMemory.Store(ctx, doc)
+}
+
+// generationRun is one indexing run of a collection, writing generation gen
+type generationRun struct {
+	client     storage.VectorStore
+	collection string
+	gen        uint64
+	written    []string // files the run writes new points for
+	deleted    []string // files removed from the workspace
+}
+
+// commit records the generation of a run in the state, saves the state,
+// makes the generation visible to searches and purges the points it
+// replaced, after TombstoneGrace. The new points of files in pending (not
+// fully indexed when the run was interrupted) are dropped and the files left
+// out of the state, so the next run indexes them again.
+func (m *Manager) commitGeneration(ctx context.Context, run *generationRun, state *WorkspaceState, stateFile string, pending map[string]bool) {
+	if len(pending) > 0 {
+		cleanupCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), cleanupTimeout)
+		defer cancel()
+		ctx = cleanupCtx
+		for path := range pending {
+			if err := run.client.DeleteFileGeneration(ctx, path, run.gen); err != nil {
+				// Keep the previous generation: the whole run is redone
+				// and its points are purged by the next run
+				log.Printf("⚠️  Failed to drop partial chunks of %s: %v", path, err)
+				for _, p := range run.written {
+					pending[p] = true
+				}
+				checkpoint(state, stateFile, pending)
+				return
+			}
+		}
+	}
+
+	for path := range pending {
+		state.RemoveFile(path)
+	}
+	state.SetGeneration(run.collection, run.gen)
+	if err := state.Save(stateFile); err != nil {
+		log.Printf("⚠️  Failed to save workspace state: %v", err)
+		return
+	}
+	if len(pending) > 0 {
+		log.Printf("💾 State saved, %d file(s) left for the next run", len(pending))
+	}
+	m.publishGeneration(run.collection, run.gen)
+
+	// Searches no longer see the replaced points: remove them, or tombstone
+	// them so their IDs resolve during the grace period
+	grace := m.TombstoneGrace()
+	now := time.Now()
+	for _, list := range [][]string{run.written, run.deleted} {
+		for _, path := range list {
+			if pending[path] {
+				continue
+			}
+			var err error
+			if grace > 0 {
+				err = run.client.TombstoneFileExceptGeneration(ctx, path, run.gen, now)
+			} else {
+				err = run.client.DeleteFileExceptGeneration(ctx, path, run.gen)
+			}
+			if err != nil {
+				log.Printf("⚠️  Failed to purge replaced chunks of %s: %v", path, err)
+			}
+		}
+	}
+	if grace > 0 {
+		if err := run.client.PurgeTombstones(ctx, now.Add(-grace)); err != nil {
+			log.Printf("⚠️  Failed to purge expired tombstones: %v", err)
+		}
+		m.schedulePurge(run.collection, grace)
+	}
+}
+
+// TombstoneGrace returns how long chunks replaced by a re-index stay
+// resolvable by ID (workspace.tombstone_grace). 0 deletes them at once.
+func (m *Manager) TombstoneGrace() time.Duration {
+	if m == nil || m.config == nil {
+		return 0
+	}
+	return m.config.Workspace.TombstoneGrace
+}
+
+// schedulePurge deletes the tombstones of a collection once grace has
+// passed. Tombstones left by a shutdown are purged by the next commit.
+func (m *Manager) schedulePurge(collection string, grace time.Duration) {
+	if m.config == nil {
+		return
+	}
+	m.background(func(ctx context.Context) {
+		timer := time.NewTimer(grace)
+		defer timer.Stop()
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+		client, err := storage.Open(m.config.Storage.VectorDB, collection)
+		if err != nil {
+			log.Printf("⚠️  Failed to purge tombstones of %s: %v", collection, err)
+			return
+		}
+		defer client.Close()
+		if err := client.PurgeTombstones(ctx, time.Now().Add(-grace)); err != nil {
+			log.Printf("⚠️  Failed to purge tombstones of %s: %v", collection, err)
+		}
+	})
+}
+
+// publishGeneration makes a committed generation visible to the searches of
+// the cached memory of a collection
+func (m *Manager) publishGeneration(collection string, gen uint64) {
+	mem, ok := m.cachedMemory(collection)
+	if !ok {
+		return
+	}
+	if g, ok := mem.(interface{ SetVisibleGeneration(uint64) }); ok {
+		g.SetVisibleGeneration(gen)
+	}
+}
@@ -0,0 +1,55 @@
+package workspace
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/doITmagic/rag-code-mcp/internal/memory"
+	"github.com/doITmagic/rag-code-mcp/internal/storage"
+)
+
+func TestGenerationMemoryStamps(t *testing.T) {
+	mock := &MockLongTermMemory{}
+	doc := memory.Document{ID: "42", Metadata: map[string]interface{}{"file": "/src/a.go"}}
+
+	for _, gen := range []uint64{1, 2} {
+		if err := (generationMemory{LongTermMemory: mock, gen: gen}).Store(context.Background(), doc); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(mock.docs) != 2 {
+		t.Fatalf("stored %d docs, want 2", len(mock.docs))
+	}
+	if mock.docs[0].ID == mock.docs[1].ID || mock.docs[0].ID == "42" {
+		t.Errorf("IDs %q and %q should differ per generation", mock.docs[0].ID, mock.docs[1].ID)
+	}
+	if mock.docs[0].Metadata[storage.GenerationKey] != uint64(1) || mock.docs[1].Metadata[storage.GenerationKey] != uint64(2) {
+		t.Errorf("generations = %v, %v", mock.docs[0].Metadata[storage.GenerationKey], mock.docs[1].Metadata[storage.GenerationKey])
+	}
+	if mock.docs[0].Metadata[StableIDKey] != "42" || mock.docs[1].Metadata[StableIDKey] != "42" {
+		t.Errorf("stable IDs = %v, %v; want the ID without the generation", mock.docs[0].Metadata[StableIDKey], mock.docs[1].Metadata[StableIDKey])
+	}
+	if _, ok := doc.Metadata[storage.GenerationKey]; ok {
+		t.Error("the caller's metadata was modified")
+	}
+}
+
+func TestStateGenerations(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	state := NewWorkspaceState()
+	if state.Generation("ragcode-ws-go") != 0 {
+		t.Fatal("a new state should start at generation 0")
+	}
+	state.SetGeneration("ragcode-ws-go", 3)
+	if err := state.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadState(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := loaded.Generation("ragcode-ws-go"); got != 3 {
+		t.Errorf("generation = %d, want 3", got)
+	}
+}
@@ -0,0 +1,263 @@
+package workspace
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/doITmagic/rag-code-mcp/internal/memory"
+)
+
+// ProjectConfigFile is the per-workspace configuration file, read from the
+// workspace root and meant to be committed with the project.
+const ProjectConfigFile = ".ragcode.yaml"
+
+// ProjectConfig is the per-workspace configuration of .ragcode.yaml.
+type ProjectConfig struct {
+	// Glossary is the path of the glossary file, relative to the workspace root
+	Glossary string `yaml:"glossary"`
+
+	// Templates is the directory of file templates for
+	// create_file_from_template, relative to the workspace root
+	// (default: .ragcode/templates)
+	Templates string `yaml:"templates"`
+
+	// Languages limits indexing to these languages (default: all detected)
+	Languages []string `yaml:"languages"`
+
+	// Exclude lists paths left out of indexing in .gitignore syntax,
+	// relative to the workspace root (e.g. generated/, *.pb.go)
+	Exclude []string `yaml:"exclude"`
+
+	// MaxFileSizeKB leaves source files larger than this out of indexing;
+	// they are almost always generated (default: 1024)
+	MaxFileSizeKB int `yaml:"max_file_size_kb"`
+}
+
+// defaultMaxFileSizeKB is the default of ProjectConfig.MaxFileSizeKB
+const defaultMaxFileSizeKB = 1024
+
+// MaxFileSize returns the size in bytes above which source files are not
+// indexed
+func (c *ProjectConfig) MaxFileSize() int64 {
+	if c.MaxFileSizeKB > 0 {
+		return int64(c.MaxFileSizeKB) << 10
+	}
+	return defaultMaxFileSizeKB << 10
+}
+
+// FilterLanguages keeps the detected languages enabled in Languages. All
+// detected languages are kept when Languages is empty.
+func (c *ProjectConfig) FilterLanguages(detected []string) []string {
+	if len(c.Languages) == 0 {
+		return detected
+	}
+	enabled := make(map[string]bool, len(c.Languages))
+	for _, lang := range c.Languages {
+		enabled[strings.ToLower(strings.TrimSpace(lang))] = true
+	}
+	var out []string
+	for _, lang := range detected {
+		if enabled[strings.ToLower(lang)] {
+			out = append(out, lang)
+		}
+	}
+	return out
+}
+
+// LoadProjectConfig reads the .ragcode.yaml of a workspace. A missing file
+// yields an empty configuration.
+func LoadProjectConfig(root string) (*ProjectConfig, error) {
+	data, err := os.ReadFile(filepath.Join(root, ProjectConfigFile))
+	if os.IsNotExist(err) {
+		return &ProjectConfig{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var cfg ProjectConfig
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", ProjectConfigFile, err)
+	}
+	return &cfg, nil
+}
+
+// GlossaryEntry is a domain term, what it means in this codebase and the
+// words the code uses for it.
+type GlossaryEntry struct {
+	Term       string   `json:"term"`
+	Definition string   `json:"definition,omitempty"`
+	Synonyms   []string `json:"synonyms,omitempty"`
+
+	pattern *regexp.Regexp // term or any synonym, as whole words
+}
+
+// Glossary holds the entries of a workspace glossary file:
+//
+//	ACL:
+//	  definition: Access control list - who may read or change a resource.
+//	  synonyms: [permissions, access rules]
+//	ledger: Append-only record of all account movements.
+type Glossary struct {
+	Path    string
+	Entries []GlossaryEntry
+}
+
+// LoadGlossary parses a glossary file. Entries are sorted by term.
+func LoadGlossary(path string) (*Glossary, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var raw map[string]interface{}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("invalid glossary %s: %w", path, err)
+	}
+
+	g := &Glossary{Path: path}
+	for term, v := range raw {
+		entry := GlossaryEntry{Term: strings.TrimSpace(term)}
+		if entry.Term == "" {
+			continue
+		}
+		switch v := v.(type) {
+		case string:
+			entry.Definition = v
+		case map[string]interface{}:
+			entry.Definition, _ = v["definition"].(string)
+			switch syn := v["synonyms"].(type) {
+			case string:
+				entry.Synonyms = []string{syn}
+			case []interface{}:
+				for _, s := range syn {
+					if s, ok := s.(string); ok && strings.TrimSpace(s) != "" {
+						entry.Synonyms = append(entry.Synonyms, strings.TrimSpace(s))
+					}
+				}
+			}
+		case nil:
+		default:
+			return nil, fmt.Errorf("invalid glossary %s: term %q must have a definition or definition/synonyms", path, term)
+		}
+		entry.Definition = strings.TrimSpace(entry.Definition)
+
+		words := make([]string, 0, 1+len(entry.Synonyms))
+		for _, w := range append([]string{entry.Term}, entry.Synonyms...) {
+			words = append(words, regexp.QuoteMeta(w))
+		}
+		entry.pattern = regexp.MustCompile(`(?i)(?:^|\W)(?:` + strings.Join(words, "|") + `)(?:\W|$)`)
+		g.Entries = append(g.Entries, entry)
+	}
+	sort.Slice(g.Entries, func(i, j int) bool { return g.Entries[i].Term < g.Entries[j].Term })
+	return g, nil
+}
+
+// Match returns the entries whose term or a synonym appears in text as a
+// whole word, ignoring case.
+func (g *Glossary) Match(text string) []GlossaryEntry {
+	if g == nil {
+		return nil
+	}
+	var out []GlossaryEntry
+	for _, e := range g.Entries {
+		if e.pattern.MatchString(text) {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+// ExpandQuery appends the terms and synonyms of the matched entries that the
+// query does not already contain, so a search for "ACL" also reaches code
+// that only talks about permissions.
+func ExpandQuery(query string, entries []GlossaryEntry) string {
+	lower := strings.ToLower(query)
+	seen := make(map[string]bool)
+	var extra []string
+	for _, e := range entries {
+		for _, w := range append([]string{e.Term}, e.Synonyms...) {
+			key := strings.ToLower(w)
+			if seen[key] || strings.Contains(lower, key) {
+				continue
+			}
+			seen[key] = true
+			extra = append(extra, w)
+		}
+	}
+	if len(extra) == 0 {
+		return query
+	}
+	return query + " " + strings.Join(extra, " ")
+}
+
+// GlossaryPath returns the absolute path of the configured glossary file, or
+// "" when there is none.
+func (c *ProjectConfig) GlossaryPath(root string) string {
+	if c.Glossary == "" || filepath.IsAbs(c.Glossary) {
+		return c.Glossary
+	}
+	return filepath.Join(root, c.Glossary)
+}
+
+// Glossary returns the glossary configured in the .ragcode.yaml of the
+// workspace, or nil when none is configured. The file is read on every call
+// so edits apply without re-indexing.
+func (m *Manager) Glossary(info *Info) (*Glossary, error) {
+	if info == nil {
+		return nil, nil
+	}
+	cfg, err := LoadProjectConfig(info.Root)
+	if err != nil {
+		return nil, err
+	}
+	path := cfg.GlossaryPath(info.Root)
+	if path == "" {
+		return nil, nil
+	}
+	return LoadGlossary(path)
+}
+
+// indexGlossary stores one document per glossary entry next to the docs of
+// the collection, so search_docs finds terms and their definitions.
+func (m *Manager) indexGlossary(ctx context.Context, path, collectionName string, ltm memory.LongTermMemory) (int, error) {
+	g, err := LoadGlossary(path)
+	if err != nil {
+		return 0, err
+	}
+	for i, e := range g.Entries {
+		text := fmt.Sprintf("%s: %s", e.Term, e.Definition)
+		if len(e.Synonyms) > 0 {
+			text += fmt.Sprintf("\nAlso called: %s", strings.Join(e.Synonyms, ", "))
+		}
+
+		emb, err := m.llm.Embed(ctx, text)
+		if err != nil {
+			return i, fmt.Errorf("embed failed for glossary term %s: %w", e.Term, err)
+		}
+
+		h := fnv.New64a()
+		h.Write([]byte(fmt.Sprintf("%s#%s", path, e.Term)))
+		doc := memory.Document{
+			ID:        fmt.Sprintf("%d", h.Sum64()),
+			Content:   text,
+			Embedding: emb,
+			Metadata: map[string]interface{}{
+				"file":       path,
+				"term":       e.Term,
+				"source":     collectionName,
+				"chunk_type": "glossary",
+			},
+		}
+		if err := ltm.Store(ctx, doc); err != nil {
+			return i, fmt.Errorf("store failed for glossary term %s: %w", e.Term, err)
+		}
+	}
+	return len(g.Entries), nil
+}
@@ -0,0 +1,56 @@
+package workspace
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestGlossaryFromProjectConfig(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, ProjectConfigFile), []byte("glossary: docs/glossary.yaml\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(root, "docs"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	glossary := `
+ACL:
+  definition: Access control list - who may read or change a resource.
+  synonyms: [permissions, access rules]
+ledger: Append-only record of all account movements.
+`
+	if err := os.WriteFile(filepath.Join(root, "docs", "glossary.yaml"), []byte(glossary), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	m := &Manager{}
+	g, err := m.Glossary(&Info{Root: root})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g == nil || len(g.Entries) != 2 || g.Entries[0].Term != "ACL" || g.Entries[1].Definition != "Append-only record of all account movements." {
+		t.Fatalf("glossary = %+v", g)
+	}
+
+	entries := g.Match("where are acl checks done?")
+	if len(entries) != 1 || entries[0].Term != "ACL" {
+		t.Fatalf("Match = %+v, want ACL", entries)
+	}
+	if got := ExpandQuery("where are acl checks done?", entries); got != "where are acl checks done? permissions access rules" {
+		t.Errorf("ExpandQuery = %q", got)
+	}
+	if got := g.Match("update the Ledger balance"); len(got) != 1 || got[0].Term != "ledger" {
+		t.Errorf("Match by term ignoring case = %+v", got)
+	}
+	if got := g.Match("check user permissions"); len(got) != 1 || got[0].Term != "ACL" {
+		t.Errorf("Match by synonym = %+v", got)
+	}
+	if got := g.Match("oracle ledgers"); len(got) != 0 {
+		t.Errorf("Match should only match whole words, got %+v", got)
+	}
+
+	if g, err := m.Glossary(&Info{Root: t.TempDir()}); err != nil || g != nil {
+		t.Errorf("workspace without .ragcode.yaml: glossary = %+v, err = %v", g, err)
+	}
+}
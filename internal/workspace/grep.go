@@ -0,0 +1,233 @@
+package workspace
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/doITmagic/rag-code-mcp/internal/ragcode"
+)
+
+// grepMaxFileSize skips large files, which are almost always generated or data.
+const grepMaxFileSize = 2 << 20
+
+// GrepOptions configures a literal or regex scan of a workspace.
+type GrepOptions struct {
+	Pattern       string
+	Regex         bool     // Pattern is a regular expression instead of a literal
+	CaseSensitive bool     // literal and regex matching ignore case unless set
+	Include       []string // globs on the file name (*.go) or the path relative to the root (internal/**/*.go)
+	MaxMatches    int      // stop after this many matches (0 = 100)
+	Context       int      // lines of context before and after each match
+}
+
+// GrepMatch is one matching line.
+type GrepMatch struct {
+	Path   string   `json:"path"`
+	Line   int      `json:"line"`
+	Column int      `json:"column"` // 1-based byte offset of the match in the line
+	Text   string   `json:"text"`
+	Before []string `json:"before,omitempty"`
+	After  []string `json:"after,omitempty"`
+}
+
+// Grep scans the text files below root, ripgrep-style: the directories skipped
+// by indexing, the patterns of the root .gitignore, .ragcode, binary files and
+// files over 2 MB are left out. Files are visited in lexical order. It reports
+// whether the scan stopped at MaxMatches.
+func Grep(ctx context.Context, root string, opts GrepOptions) ([]GrepMatch, bool, error) {
+	if opts.Pattern == "" {
+		return nil, false, fmt.Errorf("pattern is required")
+	}
+	expr := opts.Pattern
+	if !opts.Regex {
+		expr = regexp.QuoteMeta(expr)
+	}
+	if !opts.CaseSensitive {
+		expr = "(?i)" + expr
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, false, fmt.Errorf("invalid regex: %w", err)
+	}
+	for _, g := range opts.Include {
+		if _, err := path.Match(g, ""); err != nil {
+			return nil, false, fmt.Errorf("invalid include glob %q: %w", g, err)
+		}
+	}
+	maxMatches := opts.MaxMatches
+	if maxMatches <= 0 {
+		maxMatches = 100
+	}
+	ignore := loadGitignore(root)
+
+	var matches []GrepMatch
+	truncated := false
+	err = filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(root, p)
+		rel = filepath.ToSlash(rel)
+		if d.IsDir() {
+			if p == root {
+				return nil
+			}
+			if _, skip := defaultSkipDirs[d.Name()]; skip || d.Name() == ".ragcode" || ignore.match(rel, true) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() || ignore.match(rel, false) || !grepIncluded(rel, opts.Include) {
+			return nil
+		}
+		if fi, err := d.Info(); err != nil || fi.Size() > grepMaxFileSize {
+			return nil
+		}
+
+		found, err := grepFile(p, re, opts.Context, maxMatches-len(matches))
+		if err != nil {
+			return nil
+		}
+		matches = append(matches, found...)
+		if len(matches) >= maxMatches {
+			truncated = true
+			return filepath.SkipAll
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	return matches, truncated, nil
+}
+
+func grepFile(p string, re *regexp.Regexp, contextLines, limit int) ([]GrepMatch, error) {
+	data, err := os.ReadFile(p)
+	if err != nil {
+		return nil, err
+	}
+	head := data
+	if len(head) > 8000 {
+		head = head[:8000]
+	}
+	if bytes.IndexByte(head, 0) >= 0 {
+		return nil, nil // binary
+	}
+
+	var lines []string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), grepMaxFileSize)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+
+	var out []GrepMatch
+	for i, line := range lines {
+		loc := re.FindStringIndex(line)
+		if loc == nil {
+			continue
+		}
+		m := GrepMatch{Path: p, Line: i + 1, Column: loc[0] + 1, Text: line}
+		if contextLines > 0 {
+			m.Before = lines[max(0, i-contextLines):i]
+			m.After = lines[i+1 : min(len(lines), i+1+contextLines)]
+		}
+		out = append(out, m)
+		if len(out) >= limit {
+			break
+		}
+	}
+	return out, nil
+}
+
+// grepIncluded reports whether a file matches one of the include globs. Globs
+// without a slash match the file name, others the path relative to the root,
+// where "**/" spans directories.
+func grepIncluded(rel string, include []string) bool {
+	if len(include) == 0 {
+		return true
+	}
+	for _, g := range include {
+		if !strings.Contains(g, "/") {
+			if ok, _ := path.Match(g, path.Base(rel)); ok {
+				return true
+			}
+			continue
+		}
+		if ragcode.MatchGlob(g, rel) {
+			return true
+		}
+	}
+	return false
+}
+
+// gitignore holds the patterns of a root .gitignore. Negations are not
+// supported: a negated pattern is ignored, which errs on scanning more.
+type gitignore []gitignorePattern
+
+type gitignorePattern struct {
+	raw      string // the pattern as written
+	glob     string
+	dirOnly  bool
+	anchored bool // contains a slash: matches from the root only
+}
+
+func loadGitignore(root string) gitignore {
+	data, err := os.ReadFile(filepath.Join(root, ".gitignore"))
+	if err != nil {
+		return nil
+	}
+	return parseIgnorePatterns(strings.Split(string(data), "\n"))
+}
+
+// parseIgnorePatterns parses patterns in .gitignore syntax
+func parseIgnorePatterns(lines []string) gitignore {
+	var out gitignore
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "!") {
+			continue
+		}
+		p := gitignorePattern{raw: line}
+		if strings.HasSuffix(line, "/") {
+			p.dirOnly = true
+			line = strings.TrimSuffix(line, "/")
+		}
+		if strings.Contains(line, "/") {
+			p.anchored = true
+			line = strings.TrimPrefix(line, "/")
+		}
+		p.glob = line
+		out = append(out, p)
+	}
+	return out
+}
+
+func (g gitignore) match(rel string, isDir bool) bool {
+	for _, p := range g {
+		if p.dirOnly && !isDir {
+			continue
+		}
+		if p.anchored {
+			if ragcode.MatchGlob(p.glob, rel) {
+				return true
+			}
+			continue
+		}
+		if ok, _ := path.Match(p.glob, path.Base(rel)); ok {
+			return true
+		}
+	}
+	return false
+}
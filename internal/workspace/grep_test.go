@@ -0,0 +1,66 @@
+package workspace
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestGrep(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		".gitignore":        "/gen/\n*.log\n",
+		"main.go":           "package main\n\nfunc main() {\n\tconnect(\"db.internal:5432\")\n}\n",
+		"internal/db/db.go": "package db\n\n// Connect dials DB.INTERNAL:5432\nfunc Connect() {}\n",
+		"gen/models.go":     "const host = \"db.internal:5432\"\n",
+		"server.log":        "db.internal:5432 refused\n",
+		"vendor/x/x.go":     "db.internal:5432\n",
+		"assets/logo.bin":   "db.internal:5432\x00\x01",
+		"docs/setup.md":     "Point the app to db.internal:5432.\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	matches, truncated, err := Grep(context.Background(), root, GrepOptions{Pattern: "db.internal:5432"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, m := range matches {
+		rel, _ := filepath.Rel(root, m.Path)
+		got = append(got, filepath.ToSlash(rel))
+	}
+	want := []string{"docs/setup.md", "internal/db/db.go", "main.go"}
+	if truncated || len(got) != len(want) || got[0] != want[0] || got[1] != want[1] || got[2] != want[2] {
+		t.Fatalf("matched files = %v (truncated %v), want %v", got, truncated, want)
+	}
+	if m := matches[2]; m.Line != 4 || m.Column != 11 {
+		t.Errorf("main.go match at %d:%d, want 4:11", m.Line, m.Column)
+	}
+
+	// The literal "." must not match any character; case and include apply
+	matches, _, _ = Grep(context.Background(), root, GrepOptions{Pattern: "DB.INTERNAL", CaseSensitive: true, Include: []string{"internal/**/*.go"}, Context: 1})
+	if len(matches) != 1 || matches[0].Line != 3 || len(matches[0].Before) != 1 || len(matches[0].After) != 1 {
+		t.Errorf("case-sensitive include matches = %+v", matches)
+	}
+	if matches, _, _ = Grep(context.Background(), root, GrepOptions{Pattern: "dbXinternal"}); len(matches) != 0 {
+		t.Errorf("literal pattern matched as regex: %+v", matches)
+	}
+
+	matches, truncated, _ = Grep(context.Background(), root, GrepOptions{Pattern: `func \w+\(`, Regex: true, MaxMatches: 1})
+	if len(matches) != 1 || !truncated {
+		t.Errorf("regex with limit 1: %d match(es), truncated %v", len(matches), truncated)
+	}
+
+	if _, _, err := Grep(context.Background(), root, GrepOptions{Pattern: "(", Regex: true}); err == nil {
+		t.Error("expected an invalid regex error")
+	}
+}
@@ -0,0 +1,141 @@
+package workspace
+
+import (
+	"context"
+	"io"
+	"log"
+	"sort"
+	"strings"
+	"time"
+)
+
+// workspaceUse records when a workspace was last queried and the collection
+// clients loaded for it
+type workspaceUse struct {
+	id          string
+	lastUsed    time.Time
+	collections map[string]bool
+}
+
+// IdleTimeout returns how long a workspace stays loaded without queries
+// (workspace.idle_timeout). 0 keeps workspaces loaded.
+func (m *Manager) IdleTimeout() time.Duration {
+	if m == nil || m.config == nil {
+		return 0
+	}
+	return m.config.Workspace.IdleTimeout
+}
+
+// touchWorkspace marks a workspace as used now. A non-empty collection is
+// recorded as loaded for it.
+func (m *Manager) touchWorkspace(info *Info, collection string) {
+	m.idleMu.Lock()
+	defer m.idleMu.Unlock()
+	if m.lastUse == nil {
+		m.lastUse = make(map[string]*workspaceUse)
+	}
+	use, ok := m.lastUse[info.Root]
+	if !ok {
+		use = &workspaceUse{collections: make(map[string]bool)}
+		m.lastUse[info.Root] = use
+	}
+	use.id = info.ID
+	use.lastUsed = time.Now()
+	if collection != "" {
+		use.collections[collection] = true
+	}
+}
+
+// RunIdleReaper unloads idle workspaces every minute until ctx is done. It
+// returns right away when the idle timeout is disabled.
+func (m *Manager) RunIdleReaper(ctx context.Context) {
+	timeout := m.IdleTimeout()
+	if timeout <= 0 {
+		return
+	}
+	interval := time.Minute
+	if timeout < interval {
+		interval = timeout
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			m.UnloadIdle(now.Add(-timeout))
+		}
+	}
+}
+
+// UnloadIdle stops the file watchers, closes the collection clients and
+// drops the query caches of workspaces last used before cutoff. Workspaces
+// being indexed stay loaded. Everything is recreated on the next query of
+// the workspace, which also re-indexes files changed in the meantime. It
+// returns the roots of the unloaded workspaces.
+func (m *Manager) UnloadIdle(cutoff time.Time) []string {
+	m.idleMu.Lock()
+	var idle []string
+	uses := make(map[string]*workspaceUse)
+	for root, use := range m.lastUse {
+		if use.lastUsed.Before(cutoff) && !m.indexingWorkspace(use.id) {
+			idle = append(idle, root)
+			uses[root] = use
+			delete(m.lastUse, root)
+		}
+	}
+	m.idleMu.Unlock()
+
+	sort.Strings(idle)
+	for _, root := range idle {
+		m.unloadWorkspace(root, uses[root])
+	}
+	return idle
+}
+
+// unloadWorkspace releases what a workspace holds in memory
+func (m *Manager) unloadWorkspace(root string, use *workspaceUse) {
+	m.watchersMu.Lock()
+	if watcher, ok := m.watchers[root]; ok {
+		watcher.Stop()
+		delete(m.watchers, root)
+	}
+	m.watchersMu.Unlock()
+
+	m.memoryMu.Lock()
+	for collection := range use.collections {
+		mem, ok := m.memories[collection]
+		if !ok {
+			continue
+		}
+		delete(m.memories, collection)
+		if closer, ok := mem.(io.Closer); ok {
+			if err := closer.Close(); err != nil {
+				log.Printf("⚠️  Failed to close collection client '%s': %v", collection, err)
+			}
+		}
+	}
+	m.memoryMu.Unlock()
+
+	m.queryMu.Lock()
+	delete(m.queryCaches, use.id)
+	m.queryMu.Unlock()
+	m.dropKeywordIndexes(use.collections)
+	m.dropBranchViews(use.collections)
+
+	log.Printf("💤 Workspace %s idle: watcher stopped, %d collection client(s) closed", root, len(use.collections))
+}
+
+// indexingWorkspace reports whether any language of a workspace is being
+// indexed
+func (m *Manager) indexingWorkspace(id string) bool {
+	m.indexingMu.RLock()
+	defer m.indexingMu.RUnlock()
+	for key, running := range m.indexing {
+		if running && strings.HasPrefix(key, id+"-") {
+			return true
+		}
+	}
+	return false
+}
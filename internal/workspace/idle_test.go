@@ -0,0 +1,70 @@
+package workspace
+
+import (
+	"testing"
+	"time"
+
+	"github.com/doITmagic/rag-code-mcp/internal/memory"
+)
+
+// closingMemory records whether its client was closed
+type closingMemory struct {
+	memory.LongTermMemory
+	closed bool
+}
+
+func (c *closingMemory) Close() error {
+	c.closed = true
+	return nil
+}
+
+func TestUnloadIdle(t *testing.T) {
+	m := &Manager{
+		memories: make(map[string]memory.LongTermMemory),
+		watchers: make(map[string]*FileWatcher),
+		indexing: make(map[string]bool),
+	}
+	idle := &Info{Root: "/work/idle", ID: "idle"}
+	busy := &Info{Root: "/work/busy", ID: "busy"}
+
+	idleMem, busyMem := &closingMemory{}, &closingMemory{}
+	m.memories["ragcode-idle-go"] = idleMem
+	m.memories["ragcode-busy-go"] = busyMem
+	m.touchWorkspace(idle, "ragcode-idle-go")
+	m.touchWorkspace(busy, "ragcode-busy-go")
+	m.indexing["busy-go"] = true
+
+	watcher, err := NewFileWatcher(t.TempDir(), m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.watchers[idle.Root] = watcher
+
+	// Nothing is idle yet
+	if got := m.UnloadIdle(time.Now().Add(-time.Minute)); len(got) != 0 {
+		t.Fatalf("unloaded %v, want none", got)
+	}
+
+	got := m.UnloadIdle(time.Now().Add(time.Minute))
+	if len(got) != 1 || got[0] != idle.Root {
+		t.Fatalf("unloaded %v, want only %s", got, idle.Root)
+	}
+	if !idleMem.closed || busyMem.closed {
+		t.Errorf("closed idle=%v busy=%v, want only the idle client closed", idleMem.closed, busyMem.closed)
+	}
+	if _, ok := m.memories["ragcode-idle-go"]; ok {
+		t.Error("idle memory still cached")
+	}
+	if _, ok := m.memories["ragcode-busy-go"]; !ok {
+		t.Error("memory of a workspace being indexed was dropped")
+	}
+	if _, ok := m.watchers[idle.Root]; ok {
+		t.Error("idle watcher still registered")
+	}
+
+	// Once indexing is done the other workspace is unloaded too
+	m.indexing["busy-go"] = false
+	if got := m.UnloadIdle(time.Now().Add(time.Minute)); len(got) != 1 || got[0] != busy.Root {
+		t.Errorf("unloaded %v, want %s", got, busy.Root)
+	}
+}
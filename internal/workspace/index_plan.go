@@ -0,0 +1,113 @@
+package workspace
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/doITmagic/rag-code-mcp/internal/ragcode"
+)
+
+// estimatedSecondsPerFile is a rough cost of analyzing and embedding one
+// source file, used to estimate how long indexing takes
+const estimatedSecondsPerFile = 0.2
+
+// SkippedPath is a path left out of indexing
+type SkippedPath struct {
+	Path   string `json:"path"`
+	Reason string `json:"reason"`
+}
+
+// LanguagePlan is what indexing does for one language of a workspace
+type LanguagePlan struct {
+	Language    string `json:"language"`
+	Collection  string `json:"collection"`
+	Files       int    `json:"files"`
+	FilesQueued int    `json:"files_queued"` // new or modified since the last run
+	Indexing    bool   `json:"indexing"`     // already running in the background
+}
+
+// IndexPlan summarizes what indexing a workspace will do
+type IndexPlan struct {
+	Root             string         `json:"root"`
+	Languages        []LanguagePlan `json:"languages"`
+	DocFiles         int            `json:"doc_files"`
+	FilesQueued      int            `json:"files_queued"`
+	EstimatedSeconds float64        `json:"estimated_seconds"`
+	LastIndexed      *time.Time     `json:"last_indexed,omitempty"`
+	SinceRef         string         `json:"since_ref,omitempty"` // only files git reports as changed since it are checked
+	Skipped          []SkippedPath  `json:"skipped,omitempty"`
+}
+
+// PlanIndexing scans a workspace and reports, per language, how many files
+// indexing will analyze, which collections it uses and which paths it
+// leaves out. Languages without an analyzer are reported as skipped.
+func (m *Manager) PlanIndexing(info *Info, languages []string) (*IndexPlan, error) {
+	return m.PlanIndexingSince(context.Background(), info, languages, "")
+}
+
+// PlanIndexingSince is PlanIndexing for a run that only checks the files
+// changed since a git ref, see IndexLanguageSince
+func (m *Manager) PlanIndexingSince(ctx context.Context, info *Info, languages []string, sinceRef string) (*IndexPlan, error) {
+	scan, err := m.scanWorkspace(info)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan workspace '%s': %w", info.Root, err)
+	}
+	state, err := LoadState(filepath.Join(info.Root, ".ragcode", "state.json"))
+	if err != nil {
+		log.Printf("⚠️  Failed to load workspace state: %v", err)
+		state = NewWorkspaceState()
+	}
+
+	plan := &IndexPlan{
+		Root:     info.Root,
+		SinceRef: sinceRef,
+		DocFiles: len(scan.DocFiles),
+		Skipped:  append([]SkippedPath(nil), scan.Skipped...),
+	}
+	if !state.LastIndexed.IsZero() {
+		lastIndexed := state.LastIndexed
+		plan.LastIndexed = &lastIndexed
+	}
+
+	analyzers := ragcode.NewAnalyzerManager()
+	for _, language := range languages {
+		lang := strings.ToLower(language)
+		if analyzers.CodeAnalyzerForProjectType(lang) == nil {
+			plan.Skipped = append(plan.Skipped, SkippedPath{Path: ".", Reason: fmt.Sprintf("no analyzer for language '%s'", language)})
+			continue
+		}
+		files := scan.LanguageFiles[lang]
+		lp := LanguagePlan{
+			Language:   language,
+			Collection: info.CollectionNameForLanguage(language),
+			Files:      len(files),
+			Indexing:   m.IsIndexing(info.ID + "-" + language),
+		}
+		gitChanged, err := gitChangedSet(ctx, info.Root, sinceRef, state, lp.Collection)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list files changed since %s: %w", sinceRef, err)
+		}
+		for _, path := range files {
+			if gitChanged != nil && !gitChanged[path] {
+				continue
+			}
+			fi, err := os.Stat(path)
+			if err != nil {
+				continue
+			}
+			fs, ok := state.GetFileState(path)
+			if gitChanged != nil || !ok || fi.ModTime().After(fs.ModTime) || fi.Size() != fs.Size {
+				lp.FilesQueued++
+			}
+		}
+		plan.FilesQueued += lp.FilesQueued
+		plan.Languages = append(plan.Languages, lp)
+	}
+	plan.EstimatedSeconds = float64(plan.FilesQueued) * estimatedSecondsPerFile
+	return plan, nil
+}
@@ -0,0 +1,90 @@
+package workspace
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestPlanIndexing(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"main.go":                 "package main\n",
+		"pkg/util.go":             "package pkg\n",
+		"vendor/dep/dep.go":       "package dep\n",
+		"node_modules/x/index.js": "",
+		"README.md":               "# Readme\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// main.go was indexed before and did not change since
+	state := NewWorkspaceState()
+	fi, err := os.Stat(filepath.Join(root, "main.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	state.UpdateFile(filepath.Join(root, "main.go"), fi)
+	if err := state.Save(filepath.Join(root, ".ragcode", "state.json")); err != nil {
+		t.Fatal(err)
+	}
+
+	info := &Info{Root: root, ID: "ws", Languages: []string{"go", "cobol"}}
+	plan, err := (&Manager{}).PlanIndexing(info, info.Languages)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(plan.Languages) != 1 {
+		t.Fatalf("languages = %+v, want only go", plan.Languages)
+	}
+	goPlan := plan.Languages[0]
+	if goPlan.Files != 2 || goPlan.FilesQueued != 1 || goPlan.Collection != info.CollectionNameForLanguage("go") {
+		t.Errorf("go plan = %+v, want 2 files with 1 queued", goPlan)
+	}
+	if plan.FilesQueued != 1 || plan.EstimatedSeconds <= 0 || plan.DocFiles != 1 || plan.LastIndexed == nil {
+		t.Errorf("plan = %+v", plan)
+	}
+
+	reasons := make(map[string]string)
+	for _, sp := range plan.Skipped {
+		reasons[sp.Path] = sp.Reason
+	}
+	if reasons["vendor"] != "third-party dependencies" || reasons["node_modules"] != "third-party dependencies" {
+		t.Errorf("skipped = %+v", plan.Skipped)
+	}
+	if reasons["."] != "no analyzer for language 'cobol'" {
+		t.Errorf("expected cobol to be skipped, got %+v", plan.Skipped)
+	}
+}
+
+func TestIndexProgressRemaining(t *testing.T) {
+	now := time.Now()
+
+	p := IndexProgress{Phase: PhaseAnalyzing, FilesQueued: 10, FilesIndexed: 5}
+	if d, ok := p.Remaining(now); !ok || d != time.Second {
+		t.Errorf("before embedding: %v, %v; want 1s from the per-file estimate", d, ok)
+	}
+
+	// A quarter of the chunks in 10s leaves 30s
+	p = IndexProgress{Phase: PhaseEmbedding, ChunksDone: 25, ChunksTotal: 100, embedStart: now.Add(-10 * time.Second)}
+	if d, ok := p.Remaining(now); !ok || d != 30*time.Second {
+		t.Errorf("while embedding: %v, %v; want 30s", d, ok)
+	}
+	if p.Percent() != 25 {
+		t.Errorf("percent = %v, want 25", p.Percent())
+	}
+
+	p = IndexProgress{Phase: PhaseDocs, ChunksDone: 100, ChunksTotal: 100}
+	if _, ok := p.Remaining(now); ok || p.Percent() != 100 {
+		t.Errorf("docs phase: remaining should be unknown and percent 100, got %v", p.Percent())
+	}
+}
@@ -0,0 +1,247 @@
+package workspace
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// Change is the journal entry of an applied patch. It is stored as
+// manifest.json in the backup directory of the patch, next to copies of the
+// files the patch changed and the patch itself.
+type Change struct {
+	ID           string            `json:"id"`
+	AppliedAt    time.Time         `json:"applied_at"`
+	Files        []PatchedFile     `json:"files"`
+	Changed      []string          `json:"changed"` // existed before the patch, backed up
+	Created      []string          `json:"created"` // did not exist before the patch
+	After        map[string]string `json:"after"`   // sha256 of each file after the patch, "" when removed
+	RolledBackAt *time.Time        `json:"rolled_back_at,omitempty"`
+}
+
+func backupsDir(root string) string {
+	return filepath.Join(root, ".ragcode", "backups")
+}
+
+func fileHash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// currentHash returns the hash of a file, or "" when it does not exist.
+func currentHash(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return fileHash(data), nil
+}
+
+// backupPatchedFiles copies the files a patch is about to change and writes
+// its journal entry.
+func backupPatchedFiles(root, diff string, files []PatchedFile, writes []patchWrite) (*Change, string, error) {
+	now := time.Now()
+	change := &Change{
+		ID:        now.Format("20060102-150405.000"),
+		AppliedAt: now,
+		Files:     files,
+		After:     make(map[string]string),
+	}
+	if err := os.MkdirAll(backupsDir(root), 0755); err != nil {
+		return nil, "", fmt.Errorf("failed to create backup directory: %w", err)
+	}
+	dir := filepath.Join(backupsDir(root), change.ID)
+	for n := 2; ; n++ {
+		err := os.Mkdir(dir, 0755)
+		if err == nil {
+			break
+		}
+		if !os.IsExist(err) {
+			return nil, "", fmt.Errorf("failed to create backup directory: %w", err)
+		}
+		change.ID = fmt.Sprintf("%s-%d", now.Format("20060102-150405.000"), n)
+		dir = filepath.Join(backupsDir(root), change.ID)
+	}
+	for _, w := range writes {
+		rel, _ := filepath.Rel(root, w.path)
+		rel = filepath.ToSlash(rel)
+		if w.remove {
+			change.After[rel] = ""
+		} else {
+			change.After[rel] = fileHash(w.content)
+		}
+
+		fi, err := os.Stat(w.path)
+		if os.IsNotExist(err) {
+			change.Created = append(change.Created, rel)
+			continue
+		}
+		data, err := os.ReadFile(w.path)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to back up %s: %w", rel, err)
+		}
+		target := filepath.Join(dir, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return nil, "", fmt.Errorf("failed to back up %s: %w", rel, err)
+		}
+		if err := os.WriteFile(target, data, fi.Mode().Perm()); err != nil {
+			return nil, "", fmt.Errorf("failed to back up %s: %w", rel, err)
+		}
+		change.Changed = append(change.Changed, rel)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "patch.diff"), []byte(diff), 0644); err != nil {
+		return nil, "", fmt.Errorf("failed to save patch: %w", err)
+	}
+	if err := saveChange(dir, change); err != nil {
+		return nil, "", err
+	}
+	return change, dir, nil
+}
+
+func saveChange(dir string, change *Change) error {
+	data, err := json.MarshalIndent(change, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(dir, "manifest.json"), data, 0644); err != nil {
+		return fmt.Errorf("failed to save change journal: %w", err)
+	}
+	return nil
+}
+
+// restorePatchedFiles undoes the writes already made from the backup.
+func restorePatchedFiles(root, backupDir string, done []patchWrite) {
+	for _, w := range done {
+		rel, _ := filepath.Rel(root, w.path)
+		if err := restoreFile(backupDir, rel, w.path); err != nil {
+			log.Printf("⚠️  Failed to restore %s from %s: %v", w.path, backupDir, err)
+		}
+	}
+}
+
+// restoreFile puts back the backed up copy of rel, or removes the file when
+// there is none (the patch created it).
+func restoreFile(backupDir, rel, target string) error {
+	backup := filepath.Join(backupDir, filepath.FromSlash(rel))
+	fi, err := os.Stat(backup)
+	if os.IsNotExist(err) {
+		if err := os.Remove(target); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(backup)
+	if err != nil {
+		return err
+	}
+	return writePatchedFile(patchWrite{path: target, content: data, mode: fi.Mode().Perm()})
+}
+
+// ListChanges returns the journaled patches of a workspace, newest first.
+func ListChanges(root string) ([]Change, error) {
+	entries, err := os.ReadDir(backupsDir(root))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var changes []Change
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(backupsDir(root), e.Name(), "manifest.json"))
+		if err != nil {
+			continue
+		}
+		var c Change
+		if err := json.Unmarshal(data, &c); err != nil {
+			continue
+		}
+		if c.ID == "" {
+			c.ID = e.Name()
+		}
+		changes = append(changes, c)
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].ID > changes[j].ID })
+	return changes, nil
+}
+
+// RollbackChange restores the files of a journaled patch to their content
+// before the patch: changed files are restored from the backup and created
+// files removed. id "" selects the newest change not rolled back yet. Files
+// edited since the patch are a conflict and nothing is restored unless force
+// is set. It returns the change and the absolute paths restored.
+func RollbackChange(root, id string, force bool) (*Change, []string, error) {
+	changes, err := ListChanges(root)
+	if err != nil {
+		return nil, nil, err
+	}
+	var change *Change
+	for i := range changes {
+		if (id == "" && changes[i].RolledBackAt == nil) || (id != "" && changes[i].ID == id) {
+			change = &changes[i]
+			break
+		}
+	}
+	if change == nil {
+		if id == "" {
+			return nil, nil, fmt.Errorf("no applied change to roll back")
+		}
+		return nil, nil, fmt.Errorf("change %s not found", id)
+	}
+	if change.RolledBackAt != nil {
+		return nil, nil, fmt.Errorf("change %s was already rolled back at %s", change.ID, change.RolledBackAt.Format(time.RFC3339))
+	}
+
+	rels := make([]string, 0, len(change.After))
+	for rel := range change.After {
+		rels = append(rels, rel)
+	}
+	sort.Strings(rels)
+	if !force {
+		var conflicts []string
+		for _, rel := range rels {
+			hash, err := currentHash(filepath.Join(root, filepath.FromSlash(rel)))
+			if err != nil {
+				return nil, nil, err
+			}
+			if hash != change.After[rel] {
+				conflicts = append(conflicts, rel)
+			}
+		}
+		if len(conflicts) > 0 {
+			return nil, nil, fmt.Errorf("files changed since change %s was applied: %v; roll back later changes first or force", change.ID, conflicts)
+		}
+	}
+
+	dir := filepath.Join(backupsDir(root), change.ID)
+	var restored []string
+	for _, rel := range rels {
+		target := filepath.Join(root, filepath.FromSlash(rel))
+		if err := restoreFile(dir, rel, target); err != nil {
+			return nil, restored, fmt.Errorf("failed to restore %s (restored so far: %v): %w", rel, restored, err)
+		}
+		restored = append(restored, target)
+	}
+
+	now := time.Now()
+	change.RolledBackAt = &now
+	if err := saveChange(dir, change); err != nil {
+		return change, restored, err
+	}
+	return change, restored, nil
+}
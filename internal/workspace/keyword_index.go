@@ -0,0 +1,311 @@
+package workspace
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/doITmagic/rag-code-mcp/internal/codetypes"
+	"github.com/doITmagic/rag-code-mcp/internal/memory"
+)
+
+// BM25 parameters: term frequency saturation and length normalisation
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+// keywordNameBoost counts the tokens of a symbol name this many times, so a
+// chunk named after the query outranks chunks that only mention it
+const keywordNameBoost = 3
+
+// maxKeywordIndexDocs caps the chunks a keyword index holds; larger
+// collections are indexed partially and the index reports Truncated
+const maxKeywordIndexDocs = 50000
+
+// keywordScrollPage is how many chunks are read per page while building an
+// index
+const keywordScrollPage = 256
+
+// KeywordIndex is an in-memory inverted index of the code chunks of a
+// collection, scored with BM25. It finds exact identifiers such as
+// NewQdrantClient that vector search only ranks approximately.
+type KeywordIndex struct {
+	docs      []memory.Document
+	lengths   []int
+	postings  map[string][]keywordPosting
+	avgLen    float64
+	truncated bool
+}
+
+// keywordPosting is the frequency of a token in one document
+type keywordPosting struct {
+	doc int
+	tf  int
+}
+
+// KeywordHit is a document found by a keyword search
+type KeywordHit struct {
+	Doc   memory.Document
+	Score float64
+}
+
+// NewKeywordIndex indexes docs
+func NewKeywordIndex(docs []memory.Document) *KeywordIndex {
+	ix := &KeywordIndex{
+		docs:     docs,
+		lengths:  make([]int, len(docs)),
+		postings: make(map[string][]keywordPosting),
+	}
+	total := 0
+	for i, doc := range docs {
+		name, body := keywordFields(doc)
+		tf := make(map[string]int)
+		for _, tok := range KeywordTokens(body) {
+			tf[tok]++
+		}
+		for _, tok := range KeywordTokens(name) {
+			tf[tok] += keywordNameBoost
+		}
+		for tok, n := range tf {
+			ix.postings[tok] = append(ix.postings[tok], keywordPosting{doc: i, tf: n})
+			ix.lengths[i] += n
+		}
+		total += ix.lengths[i]
+	}
+	if len(docs) > 0 {
+		ix.avgLen = float64(total) / float64(len(docs))
+	}
+	return ix
+}
+
+// BuildKeywordIndex indexes the code chunks of mem, up to maxKeywordIndexDocs
+func BuildKeywordIndex(ctx context.Context, mem memory.LongTermMemory) (*KeywordIndex, error) {
+	return buildKeywordIndex(ctx, mem, maxKeywordIndexDocs)
+}
+
+func buildKeywordIndex(ctx context.Context, mem memory.LongTermMemory, maxDocs int) (*KeywordIndex, error) {
+	var docs []memory.Document
+	cursor := ""
+	truncated := false
+	for {
+		page, next, err := mem.Scroll(ctx, memory.CodeOnly(), cursor, keywordScrollPage)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list chunks: %w", err)
+		}
+		docs = append(docs, page...)
+		if len(docs) > maxDocs || (len(docs) == maxDocs && next != "") {
+			truncated = true
+			break
+		}
+		if next == "" {
+			break
+		}
+		cursor = next
+	}
+	if truncated {
+		docs = docs[:maxDocs]
+		total, err := mem.Count(ctx, memory.CodeOnly())
+		if err != nil {
+			log.Printf("⚠️  Keyword index truncated to %d chunks; the others are only found by semantic search", maxDocs)
+		} else {
+			log.Printf("⚠️  Keyword index truncated to %d of %d chunks; the others are only found by semantic search", maxDocs, total)
+		}
+	}
+	ix := NewKeywordIndex(docs)
+	ix.truncated = truncated
+	return ix, nil
+}
+
+// Truncated reports whether the collection held more chunks than the index
+func (ix *KeywordIndex) Truncated() bool {
+	return ix.truncated
+}
+
+// Len returns the number of indexed documents
+func (ix *KeywordIndex) Len() int {
+	return len(ix.docs)
+}
+
+// Search returns up to limit documents matching the tokens of query, best
+// BM25 score first. Equal scores are ordered by document ID, so results are
+// deterministic.
+func (ix *KeywordIndex) Search(query string, limit int) []KeywordHit {
+	n := float64(len(ix.docs))
+	scores := make(map[int]float64)
+	seen := make(map[string]bool)
+	for _, tok := range KeywordTokens(query) {
+		if seen[tok] {
+			continue
+		}
+		seen[tok] = true
+		postings := ix.postings[tok]
+		if len(postings) == 0 {
+			continue
+		}
+		df := float64(len(postings))
+		idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+		for _, p := range postings {
+			tf := float64(p.tf)
+			norm := 1 - bm25B + bm25B*float64(ix.lengths[p.doc])/ix.avgLen
+			scores[p.doc] += idf * tf * (bm25K1 + 1) / (tf + bm25K1*norm)
+		}
+	}
+
+	hits := make([]KeywordHit, 0, len(scores))
+	for i, score := range scores {
+		hits = append(hits, KeywordHit{Doc: ix.docs[i], Score: score})
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return hits[i].Doc.ID < hits[j].Doc.ID
+	})
+	if limit > 0 && len(hits) > limit {
+		hits = hits[:limit]
+	}
+	return hits
+}
+
+// KeywordTokens splits text into lowercase identifier tokens. A compound
+// identifier yields itself and its camelCase and snake_case parts:
+// "NewQdrantClient" gives newqdrantclient, new, qdrant and client.
+func KeywordTokens(text string) []string {
+	var tokens []string
+	for _, word := range strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_'
+	}) {
+		word = strings.Trim(word, "_")
+		if word == "" {
+			continue
+		}
+		tokens = append(tokens, strings.ToLower(word))
+		if parts := identifierParts(word); len(parts) > 1 {
+			for _, part := range parts {
+				tokens = append(tokens, strings.ToLower(part))
+			}
+		}
+	}
+	return tokens
+}
+
+// identifierParts splits an identifier at underscores and case changes:
+// "HTTPServer_start" gives HTTP, Server and start
+func identifierParts(word string) []string {
+	var parts []string
+	for _, segment := range strings.Split(word, "_") {
+		runes := []rune(segment)
+		start := 0
+		for i := 1; i < len(runes); i++ {
+			lowerToUpper := !unicode.IsUpper(runes[i-1]) && unicode.IsUpper(runes[i])
+			acronymEnd := unicode.IsUpper(runes[i-1]) && unicode.IsUpper(runes[i]) && i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if lowerToUpper || acronymEnd {
+				parts = append(parts, string(runes[start:i]))
+				start = i
+			}
+		}
+		if start < len(runes) {
+			parts = append(parts, string(runes[start:]))
+		}
+	}
+	return parts
+}
+
+// keywordFields returns the symbol name and the searchable text of a chunk.
+// Documents that are not CodeChunk JSON are searched by their content.
+func keywordFields(doc memory.Document) (string, string) {
+	var chunk codetypes.CodeChunk
+	if err := json.Unmarshal([]byte(doc.Content), &chunk); err != nil || (chunk.Name == "" && chunk.Code == "") {
+		name, _ := doc.Metadata["name"].(string)
+		return name, doc.Content
+	}
+	return chunk.Name, strings.Join([]string{chunk.Package, chunk.Signature, chunk.Docstring, chunk.Code}, "\n")
+}
+
+// cachedKeywordIndex is the keyword index of a collection at an index
+// generation of its workspace
+type cachedKeywordIndex struct {
+	generation uint64
+	index      *KeywordIndex
+	rebuilding bool
+}
+
+// KeywordIndex returns the keyword index of the collection of a workspace
+// language. The first call builds it; once the workspace is re-indexed the
+// previous index keeps being served while a background job rebuilds it.
+// Concurrent calls share one build per collection.
+func (m *Manager) KeywordIndex(ctx context.Context, info *Info, language string, mem memory.LongTermMemory) (*KeywordIndex, error) {
+	collection := info.CollectionNameForLanguage(language)
+	generation := m.IndexGeneration(info)
+
+	m.keywordMu.Lock()
+	cached, ok := m.keywordIndexes[collection]
+	if ok && cached.generation != generation && !cached.rebuilding {
+		cached.rebuilding = true
+		m.background(func(ctx context.Context) {
+			m.rebuildKeywordIndex(ctx, collection, cached, info, mem)
+		})
+	}
+	m.keywordMu.Unlock()
+	if ok {
+		return cached.index, nil
+	}
+
+	unlock := m.keywordLocks.Lock(collection)
+	defer unlock()
+	m.keywordMu.Lock()
+	cached, ok = m.keywordIndexes[collection]
+	m.keywordMu.Unlock()
+	if ok {
+		// built by the call this one waited for
+		return cached.index, nil
+	}
+	index, err := BuildKeywordIndex(ctx, mem)
+	if err != nil {
+		return nil, err
+	}
+	m.keywordMu.Lock()
+	if m.keywordIndexes == nil {
+		m.keywordIndexes = make(map[string]*cachedKeywordIndex)
+	}
+	m.keywordIndexes[collection] = &cachedKeywordIndex{generation: generation, index: index}
+	m.keywordMu.Unlock()
+	return index, nil
+}
+
+// rebuildKeywordIndex replaces the stale keyword index of collection. The
+// result is dropped when the index was forgotten in the meantime, so a
+// deleted collection is not brought back.
+func (m *Manager) rebuildKeywordIndex(ctx context.Context, collection string, stale *cachedKeywordIndex, info *Info, mem memory.LongTermMemory) {
+	unlock := m.keywordLocks.Lock(collection)
+	defer unlock()
+	generation := m.IndexGeneration(info)
+	index, err := BuildKeywordIndex(ctx, mem)
+
+	m.keywordMu.Lock()
+	defer m.keywordMu.Unlock()
+	if m.keywordIndexes[collection] != stale {
+		return
+	}
+	if err != nil {
+		log.Printf("⚠️  Failed to rebuild the keyword index of %s, serving the previous one: %v", collection, err)
+		stale.rebuilding = false
+		return
+	}
+	m.keywordIndexes[collection] = &cachedKeywordIndex{generation: generation, index: index}
+}
+
+// dropKeywordIndexes forgets the keyword indexes of collections
+func (m *Manager) dropKeywordIndexes(collections map[string]bool) {
+	m.keywordMu.Lock()
+	defer m.keywordMu.Unlock()
+	for collection := range collections {
+		delete(m.keywordIndexes, collection)
+	}
+}
@@ -0,0 +1,135 @@
+package workspace
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/doITmagic/rag-code-mcp/internal/codetypes"
+	"github.com/doITmagic/rag-code-mcp/internal/memory"
+)
+
+func TestKeywordTokens(t *testing.T) {
+	tests := map[string][]string{
+		"NewQdrantClient":          {"newqdrantclient", "new", "qdrant", "client"},
+		"HTTPServer_start":         {"httpserver_start", "http", "server", "start"},
+		"c.Close() // close it":    {"c", "close", "close", "it"},
+		"__init__ parse2 utf8Name": {"init", "parse2", "utf8name", "utf8", "name"},
+	}
+	for text, want := range tests {
+		if got := KeywordTokens(text); !reflect.DeepEqual(got, want) {
+			t.Errorf("KeywordTokens(%q) = %v, want %v", text, got, want)
+		}
+	}
+}
+
+func TestKeywordIndexSearch(t *testing.T) {
+	chunk := func(id, name, code string) memory.Document {
+		content, _ := json.Marshal(codetypes.CodeChunk{Name: name, Type: "function", Code: code})
+		return memory.Document{ID: id, Content: string(content), Metadata: map[string]interface{}{"name": name}}
+	}
+	mem := memory.NewInMemoryLongTermMemory()
+	for _, doc := range []memory.Document{
+		chunk("1", "Open", "func Open() { c := NewQdrantClient(cfg); c.Ping(); NewQdrantClient(cfg) }"),
+		chunk("2", "NewQdrantClient", "func NewQdrantClient(cfg Config) *QdrantClient { return &QdrantClient{} }"),
+		chunk("3", "Close", "func Close() { client.Close() }"),
+		{ID: "4", Content: "# Qdrant\n\nRun NewQdrantClient.", Metadata: map[string]interface{}{"chunk_type": "markdown"}},
+	} {
+		if err := mem.Store(context.Background(), doc); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	index, err := BuildKeywordIndex(context.Background(), mem)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if index.Len() != 3 {
+		t.Fatalf("Len = %d, want markdown left out", index.Len())
+	}
+
+	hits := index.Search("NewQdrantClient", 10)
+	if len(hits) < 2 || hits[0].Doc.ID != "2" || hits[1].Doc.ID != "1" {
+		t.Fatalf("hits = %+v, want the definition before its callers", hits)
+	}
+	if hits := index.Search("client", 10); len(hits) != 3 {
+		t.Errorf("identifier parts should match: %+v", hits)
+	}
+	if hits := index.Search("missing", 10); len(hits) != 0 {
+		t.Errorf("hits = %+v, want none", hits)
+	}
+	first := index.Search("client close", 10)
+	for i := 0; i < 5; i++ {
+		if again := index.Search("client close", 10); !reflect.DeepEqual(again, first) {
+			t.Fatalf("search is not deterministic: %+v then %+v", first, again)
+		}
+	}
+}
+
+func TestKeywordIndexTruncated(t *testing.T) {
+	ctx := context.Background()
+	mem := memory.NewInMemoryLongTermMemory()
+	for _, id := range []string{"1", "2", "3"} {
+		if err := mem.Store(ctx, memory.Document{ID: id, Content: "func F" + id + "() {}"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	index, err := buildKeywordIndex(ctx, mem, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if index.Len() != 2 || !index.Truncated() {
+		t.Errorf("Len = %d, Truncated = %v, want 2 chunks and truncated", index.Len(), index.Truncated())
+	}
+	if index, _ := buildKeywordIndex(ctx, mem, 3); index.Len() != 3 || index.Truncated() {
+		t.Errorf("Len = %d, Truncated = %v, want every chunk", index.Len(), index.Truncated())
+	}
+}
+
+func TestManagerKeywordIndexRebuild(t *testing.T) {
+	ctx := context.Background()
+	m := &Manager{}
+	info := &Info{Root: t.TempDir(), ID: "ws"}
+	mem := memory.NewInMemoryLongTermMemory()
+	store := func(id, code string) {
+		if err := mem.Store(ctx, memory.Document{ID: id, Content: code}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	store("1", "func Open() {}")
+
+	first, err := m.KeywordIndex(ctx, info, "go", mem)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again, _ := m.KeywordIndex(ctx, info, "go", mem); again != first {
+		t.Fatal("the index was rebuilt for the same generation")
+	}
+
+	store("2", "func Close() {}")
+	m.bumpIndexGeneration(info)
+	if stale, _ := m.KeywordIndex(ctx, info, "go", mem); stale != first {
+		t.Fatal("a re-index should keep serving the previous index while it is rebuilt")
+	}
+	m.jobs.Wait()
+	fresh, err := m.KeywordIndex(ctx, info, "go", mem)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fresh == first || fresh.Len() != 2 {
+		t.Fatalf("Len = %d, want the rebuilt index", fresh.Len())
+	}
+
+	// a rebuild finishing after the index was dropped does not bring it back
+	m.bumpIndexGeneration(info)
+	m.KeywordIndex(ctx, info, "go", mem)
+	m.dropKeywordIndexes(map[string]bool{info.CollectionNameForLanguage("go"): true})
+	m.jobs.Wait()
+	m.keywordMu.Lock()
+	_, ok := m.keywordIndexes[info.CollectionNameForLanguage("go")]
+	m.keywordMu.Unlock()
+	if ok {
+		t.Error("a dropped index was brought back by its rebuild")
+	}
+}
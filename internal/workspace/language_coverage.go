@@ -0,0 +1,293 @@
+package workspace
+
+import (
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/doITmagic/rag-code-mcp/internal/codetypes"
+	"github.com/doITmagic/rag-code-mcp/internal/ragcode"
+)
+
+// Reasons a source file is not indexed
+const (
+	SkipExcluded   = "excluded"
+	SkipTooLarge   = "too_large"
+	SkipParseError = "parse_error"
+	SkipNoAnalyzer = "no_analyzer"
+	SkipDisabled   = "disabled"
+)
+
+// maxCoverageExamples bounds the example paths listed per skip reason
+const maxCoverageExamples = 5
+
+// CoverageSkip groups the files of a language left out for one reason
+type CoverageSkip struct {
+	Reason   string   `json:"reason"`
+	Detail   string   `json:"detail,omitempty"`
+	Files    int      `json:"files"`
+	Examples []string `json:"examples,omitempty"` // relative to the workspace root
+}
+
+// LanguageCoverage is how much of one language of a workspace is indexed
+type LanguageCoverage struct {
+	Language     string             `json:"language"`
+	Collection   string             `json:"collection,omitempty"`
+	FilesFound   int                `json:"files_found"`
+	FilesIndexed int                `json:"files_indexed"`
+	FilesPending int                `json:"files_pending"` // new or modified since the last run
+	FilesSkipped int                `json:"files_skipped"`
+	Skipped      []CoverageSkip     `json:"skipped,omitempty"`
+	ParseErrors  int                `json:"parse_errors"`            // syntax errors reported by the parser
+	ErrorFiles   int                `json:"files_with_parse_errors"` // files with at least one
+	Failures     []FileParseFailure `json:"parse_failures,omitempty"`
+}
+
+// FileParseFailure lists the syntax errors recorded for one file
+type FileParseFailure struct {
+	Path     string                 `json:"path"` // relative to the workspace root
+	Analyzer string                 `json:"analyzer"`
+	Errors   []codetypes.ParseError `json:"errors"`
+}
+
+// LanguageCoverageReport lists the coverage of every language found in a
+// workspace
+type LanguageCoverageReport struct {
+	Root      string             `json:"root"`
+	Languages []LanguageCoverage `json:"languages"`
+}
+
+// LanguageCoverage reports, per language, how many source files the
+// workspace has, how many are indexed and why the others are not:
+// excluded (default skipped directories and .ragcode.yaml excludes), too
+// large, parse errors, language disabled or without an analyzer. Parse
+// errors are those recorded in the workspace state by the last indexing
+// runs.
+func (m *Manager) LanguageCoverage(info *Info) (*LanguageCoverageReport, error) {
+	scan, err := m.scanWorkspace(info)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan workspace '%s': %w", info.Root, err)
+	}
+	state, err := LoadState(filepath.Join(info.Root, ".ragcode", "state.json"))
+	if err != nil {
+		log.Printf("⚠️  Failed to load workspace state: %v", err)
+		state = NewWorkspaceState()
+	}
+	projectCfg, err := LoadProjectConfig(info.Root)
+	if err != nil {
+		log.Printf("⚠️  %v", err)
+		projectCfg = &ProjectConfig{}
+	}
+	dirFiles := skippedDirFiles(info.Root, scan.Skipped)
+
+	langs := make(map[string]bool)
+	for _, files := range []map[string][]string{scan.LanguageFiles, scan.Excluded, scan.TooLarge} {
+		for lang := range files {
+			langs[lang] = true
+		}
+	}
+	for lang := range scan.Unsupported {
+		langs[lang] = true
+	}
+	for _, byLang := range dirFiles {
+		for lang := range byLang {
+			langs[lang] = true
+		}
+	}
+	names := make([]string, 0, len(langs))
+	for lang := range langs {
+		names = append(names, lang)
+	}
+	sort.Strings(names)
+
+	analyzers := ragcode.NewAnalyzerManager()
+	enabled := make(map[string]bool)
+	for _, lang := range projectCfg.FilterLanguages(names) {
+		enabled[lang] = true
+	}
+
+	report := &LanguageCoverageReport{Root: info.Root}
+	for _, lang := range names {
+		lc := LanguageCoverage{Language: lang}
+		files := scan.LanguageFiles[lang]
+
+		switch {
+		case analyzers.CodeAnalyzerForProjectType(lang) == nil:
+			skip := CoverageSkip{Reason: SkipNoAnalyzer, Files: scan.Unsupported[lang] + len(files)}
+			if ragcode.NeedsCgo(lang) {
+				skip.Detail = "the analyzer needs a cgo build (CGO_ENABLED=1); this binary was built without cgo"
+			}
+			lc.add(skip, nil)
+		case !enabled[lang]:
+			lc.add(CoverageSkip{Reason: SkipDisabled, Detail: "not listed in languages of " + ProjectConfigFile, Files: len(files)}, relPaths(info.Root, files))
+		default:
+			lc.Collection = info.CollectionNameForLanguage(lang)
+			failures := state.LanguageParseFailures(lang)
+			var failed []string
+			for _, path := range files {
+				if failure, ok := failures[path]; ok {
+					lc.ErrorFiles++
+					lc.ParseErrors += len(failure.Errors)
+					lc.Failures = append(lc.Failures, FileParseFailure{
+						Path:     relPaths(info.Root, []string{path})[0],
+						Analyzer: failure.Analyzer,
+						Errors:   failure.Errors,
+					})
+					if failure.Skipped() {
+						failed = append(failed, path)
+						continue
+					}
+				}
+				fi, err := os.Stat(path)
+				if err != nil {
+					continue
+				}
+				st, ok := state.GetFileState(path)
+				if !ok || fi.ModTime().After(st.ModTime) || fi.Size() != st.Size {
+					lc.FilesPending++
+				} else {
+					lc.FilesIndexed++
+				}
+			}
+			lc.FilesFound += lc.FilesIndexed + lc.FilesPending
+			if len(failed) > 0 {
+				lc.add(CoverageSkip{Reason: SkipParseError, Detail: "the parser could not read the file", Files: len(failed)}, relPaths(info.Root, failed))
+			}
+		}
+
+		if tooLarge := scan.TooLarge[lang]; len(tooLarge) > 0 {
+			lc.add(CoverageSkip{Reason: SkipTooLarge, Detail: fmt.Sprintf("over %d KB (max_file_size_kb)", projectCfg.MaxFileSize()>>10), Files: len(tooLarge)}, relPaths(info.Root, tooLarge))
+		}
+		if excluded := scan.Excluded[lang]; len(excluded) > 0 {
+			lc.add(CoverageSkip{Reason: SkipExcluded, Detail: "exclude patterns of " + ProjectConfigFile, Files: len(excluded)}, relPaths(info.Root, excluded))
+		}
+		for _, dir := range scan.Skipped {
+			if n := dirFiles[dir.Path][lang]; n > 0 {
+				lc.add(CoverageSkip{Reason: SkipExcluded, Detail: dir.Path + "/ (" + dir.Reason + ")", Files: n}, nil)
+			}
+		}
+		report.Languages = append(report.Languages, lc)
+	}
+	return report, nil
+}
+
+// add records skipped files, which also count as found
+func (lc *LanguageCoverage) add(skip CoverageSkip, paths []string) {
+	if skip.Files == 0 {
+		return
+	}
+	if len(paths) > maxCoverageExamples {
+		paths = paths[:maxCoverageExamples]
+	}
+	skip.Examples = paths
+	lc.Skipped = append(lc.Skipped, skip)
+	lc.FilesSkipped += skip.Files
+	lc.FilesFound += skip.Files
+}
+
+// relPaths returns paths relative to root, sorted
+func relPaths(root string, paths []string) []string {
+	out := make([]string, 0, len(paths))
+	for _, p := range paths {
+		if rel, err := filepath.Rel(root, p); err == nil {
+			p = filepath.ToSlash(rel)
+		}
+		out = append(out, p)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// skippedDirFiles counts the source files per language inside the
+// directories indexing skips. Version control data holds no sources and is
+// not walked.
+func skippedDirFiles(root string, skipped []SkippedPath) map[string]map[string]int {
+	out := make(map[string]map[string]int)
+	for _, dir := range skipped {
+		if filepath.Base(dir.Path) == ".git" {
+			continue
+		}
+		counts := make(map[string]int)
+		_ = filepath.WalkDir(filepath.Join(root, filepath.FromSlash(dir.Path)), func(path string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return nil
+			}
+			lang := sourceLanguage(path)
+			if lang == "" {
+				lang = extensionLanguage(filepath.Ext(path))
+			}
+			if lang != "" {
+				counts[lang]++
+			}
+			return nil
+		})
+		out[dir.Path] = counts
+	}
+	return out
+}
+
+// recordParseFailures keeps the syntax errors the analyzer reported for the
+// files it analyzed in the workspace state. Failures of files that parse
+// again, or that are no longer part of the language, are dropped.
+func recordParseFailures(state *WorkspaceState, language string, analyzer codetypes.PathAnalyzer, analyzed, current []string) {
+	reporter, ok := analyzer.(codetypes.ParseErrorReporter)
+	if !ok {
+		return
+	}
+	for _, path := range analyzed {
+		state.ClearParseFailure(path)
+	}
+
+	byFile := make(map[string][]codetypes.ParseError)
+	for _, e := range reporter.ParseErrors() {
+		byFile[e.FilePath] = append(byFile[e.FilePath], e)
+	}
+	name := analyzerName(analyzer)
+	now := time.Now()
+	for path, errs := range byFile {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		state.SetParseFailure(path, ParseFailure{
+			Language: strings.ToLower(language),
+			Analyzer: name,
+			Hash:     fileHash(data),
+			Errors:   errs,
+			FailedAt: now,
+		})
+		log.Printf("⚠️  %s: %s (%s analyzer, %d error(s))", path, errs[0].Message, name, len(errs))
+	}
+
+	keep := make(map[string]bool, len(current))
+	for _, path := range current {
+		keep[path] = true
+	}
+	for path := range state.LanguageParseFailures(language) {
+		if !keep[path] {
+			state.ClearParseFailure(path)
+		}
+	}
+}
+
+// quarantined reports whether a file failed to parse and its content is
+// unchanged since, so analyzing it again would fail the same way
+func quarantined(state *WorkspaceState, path string) bool {
+	failure, ok := state.GetParseFailure(path)
+	if !ok {
+		return false
+	}
+	data, err := os.ReadFile(path)
+	return err == nil && fileHash(data) == failure.Hash
+}
+
+// analyzerName names the analyzer type for parse failure reports, e.g.
+// "golang.CodeAnalyzer"
+func analyzerName(analyzer codetypes.PathAnalyzer) string {
+	return strings.TrimPrefix(fmt.Sprintf("%T", analyzer), "*")
+}
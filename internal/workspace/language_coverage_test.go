@@ -0,0 +1,148 @@
+package workspace
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/doITmagic/rag-code-mcp/internal/codetypes"
+)
+
+type fakeParseErrorAnalyzer struct {
+	errs []codetypes.ParseError
+}
+
+func (f *fakeParseErrorAnalyzer) AnalyzePaths([]string) ([]codetypes.CodeChunk, error) {
+	return nil, nil
+}
+
+func (f *fakeParseErrorAnalyzer) ParseErrors() []codetypes.ParseError {
+	return f.errs
+}
+
+func TestLanguageCoverage(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"main.go":              "package main\n",
+		"broken.go":            "package main\nfunc {\n",
+		"warn.go":              "package main\n",
+		"gen/big.go":           "package gen\n" + strings.Repeat("// filler\n", 200),
+		"legacy/old.go":        "package legacy\n",
+		"vendor/dep/dep.go":    "package dep\n",
+		"scripts/build.rb":     "puts 1\n",
+		"app/models/user.py":   "class User: pass\n",
+		ProjectConfigFile:      "languages: [go]\nmax_file_size_kb: 1\nexclude:\n  - legacy/\n",
+		"node_modules/x/a.txt": "",
+	}
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	state := NewWorkspaceState()
+	for _, name := range []string{"main.go", "warn.go", "broken.go"} {
+		path := filepath.Join(root, name)
+		fi, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		state.UpdateFile(path, fi)
+	}
+	broken := filepath.Join(root, "broken.go")
+	warn := filepath.Join(root, "warn.go")
+	recordParseFailures(state, "go", &fakeParseErrorAnalyzer{errs: []codetypes.ParseError{
+		{FilePath: broken, Line: 2, Message: "expected 'IDENT'", Skipped: true},
+		{FilePath: warn, Line: 1, Message: "missing ','"},
+		{FilePath: filepath.Join(root, "deleted.go"), Message: "gone", Skipped: true},
+	}}, []string{broken, warn}, []string{broken, warn, filepath.Join(root, "main.go")})
+	if err := state.Save(filepath.Join(root, ".ragcode", "state.json")); err != nil {
+		t.Fatal(err)
+	}
+
+	m := &Manager{}
+	info := &Info{Root: root, ID: "ws"}
+	report, err := m.LanguageCoverage(info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	byLang := make(map[string]LanguageCoverage)
+	for _, lc := range report.Languages {
+		byLang[lc.Language] = lc
+	}
+
+	goCov := byLang["go"]
+	if goCov.FilesIndexed != 2 || goCov.FilesPending != 0 || goCov.ParseErrors != 2 || goCov.ErrorFiles != 2 {
+		t.Errorf("go coverage = %+v, want 2 indexed and 2 parse errors in 2 files", goCov)
+	}
+	if len(goCov.Failures) != 2 || goCov.Failures[0].Analyzer != "workspace.fakeParseErrorAnalyzer" {
+		t.Errorf("go parse failures = %+v", goCov.Failures)
+	}
+	reasons := make(map[string]int)
+	for _, skip := range goCov.Skipped {
+		reasons[skip.Reason] += skip.Files
+	}
+	if reasons[SkipParseError] != 1 || reasons[SkipTooLarge] != 1 || reasons[SkipExcluded] != 2 {
+		t.Errorf("go skips = %+v, want 1 parse error, 1 too large and 2 excluded", goCov.Skipped)
+	}
+	if goCov.FilesFound != 6 || goCov.FilesSkipped != 4 {
+		t.Errorf("go found/skipped = %d/%d, want 6/4", goCov.FilesFound, goCov.FilesSkipped)
+	}
+
+	if py := byLang["python"]; py.FilesSkipped != 1 || py.Skipped[0].Reason != SkipDisabled {
+		t.Errorf("python coverage = %+v, want 1 disabled file", py)
+	}
+	if rb := byLang["ruby"]; rb.FilesSkipped != 1 || rb.Skipped[0].Reason != SkipNoAnalyzer {
+		t.Errorf("ruby coverage = %+v, want 1 file without analyzer", rb)
+	}
+}
+
+func TestRecordParseFailures_Quarantine(t *testing.T) {
+	root := t.TempDir()
+	path := filepath.Join(root, "broken.go")
+	if err := os.WriteFile(path, []byte("package main\nfunc {\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	fi, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	state := NewWorkspaceState()
+	state.UpdateFile(path, fi)
+	analyzer := &fakeParseErrorAnalyzer{errs: []codetypes.ParseError{{FilePath: path, Message: "expected 'IDENT'", Skipped: true}}}
+	recordParseFailures(state, "go", analyzer, []string{path}, []string{path})
+	if !quarantined(state, path) {
+		t.Fatal("unchanged file that failed to parse should be quarantined")
+	}
+
+	// The failure survives a reload of the state
+	stateFile := filepath.Join(root, ".ragcode", "state.json")
+	if err := state.Save(stateFile); err != nil {
+		t.Fatal(err)
+	}
+	state, err = LoadState(stateFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if failure, ok := state.GetParseFailure(path); !ok || failure.Analyzer != "workspace.fakeParseErrorAnalyzer" || !failure.Skipped() {
+		t.Fatalf("parse failure after reload = %+v, %v", failure, ok)
+	}
+
+	// Editing the file releases it; parsing it cleanly clears the failure
+	if err := os.WriteFile(path, []byte("package main\nfunc f() {}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if quarantined(state, path) {
+		t.Fatal("changed file should be retried")
+	}
+	recordParseFailures(state, "go", &fakeParseErrorAnalyzer{}, []string{path}, []string{path})
+	if _, ok := state.GetParseFailure(path); ok {
+		t.Error("parse failure should be cleared once the file parses")
+	}
+}
@@ -43,30 +43,8 @@ func (ld *LanguageDetector) DetectLanguages(rootPath string) ([]string, error) {
 		}
 
 		// Detect language by file extension
-		ext := strings.ToLower(filepath.Ext(path))
-		switch ext {
-		case ".go":
-			languageMap["go"] = true
-		case ".py":
-			languageMap["python"] = true
-		case ".php":
-			languageMap["php"] = true
-		case ".js", ".jsx", ".mjs":
-			languageMap["javascript"] = true
-		case ".ts", ".tsx":
-			languageMap["typescript"] = true
-		case ".java":
-			languageMap["java"] = true
-		case ".rs":
-			languageMap["rust"] = true
-		case ".rb":
-			languageMap["ruby"] = true
-		case ".c", ".h":
-			languageMap["c"] = true
-		case ".cpp", ".cc", ".cxx", ".hpp":
-			languageMap["cpp"] = true
-		case ".cs":
-			languageMap["csharp"] = true
+		if lang := extensionLanguage(filepath.Ext(path)); lang != "" {
+			languageMap[lang] = true
 		}
 
 		return nil
@@ -85,6 +63,35 @@ func (ld *LanguageDetector) DetectLanguages(rootPath string) ([]string, error) {
 	return languages, nil
 }
 
+// extensionLanguage returns the programming language of a file extension,
+// or "" for other files
+func extensionLanguage(ext string) string {
+	switch strings.ToLower(ext) {
+	case ".go":
+		return "go"
+	case ".py":
+		return "python"
+	case ".php":
+		return "php"
+	case ".js", ".jsx", ".mjs":
+		return "javascript"
+	case ".ts", ".tsx":
+		return "typescript"
+	case ".java":
+		return "java"
+	case ".rs":
+		return "rust"
+	case ".rb":
+		return "ruby"
+	case ".c", ".h", ".cpp", ".cc", ".cxx", ".hh", ".hpp", ".hxx":
+		// C shares the C/C++ analyzer and collection
+		return "cpp"
+	case ".cs":
+		return "csharp"
+	}
+	return ""
+}
+
 // GetPrimaryLanguage returns the primary language based on project markers
 // This is a heuristic-based approach for workspace-level detection
 func (ld *LanguageDetector) GetPrimaryLanguage(rootPath string, markers []string) string {
@@ -109,6 +116,8 @@ func (ld *LanguageDetector) GetPrimaryLanguage(rootPath string, markers []string
 			return "java"
 		case "Gemfile":
 			return "ruby"
+		case "*.sln", "*.csproj":
+			return "csharp"
 		}
 	}
 
@@ -145,7 +154,7 @@ func LanguageFileExtensions(language string) []string {
 	case "c":
 		return []string{".c", ".h"}
 	case "cpp", "c++":
-		return []string{".cpp", ".cc", ".cxx", ".hpp", ".h"}
+		return []string{".c", ".h", ".cpp", ".cc", ".cxx", ".hh", ".hpp", ".hxx"}
 	case "csharp", "c#":
 		return []string{".cs"}
 	default:
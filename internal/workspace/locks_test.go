@@ -0,0 +1,44 @@
+package workspace
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestKeyLocks(t *testing.T) {
+	var locks keyLocks
+	var wg sync.WaitGroup
+	counters := map[string]int{}
+	var countersMu sync.Mutex
+	running := map[string]int{}
+
+	for i := 0; i < 50; i++ {
+		key := []string{"ws-go", "ws-php"}[i%2]
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			unlock := locks.Lock(key)
+			defer unlock()
+
+			countersMu.Lock()
+			running[key]++
+			if running[key] > 1 {
+				t.Errorf("two holders of %s", key)
+			}
+			counters[key]++
+			countersMu.Unlock()
+
+			countersMu.Lock()
+			running[key]--
+			countersMu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	if counters["ws-go"] != 25 || counters["ws-php"] != 25 {
+		t.Errorf("counters = %v", counters)
+	}
+	if len(locks.locks) != 0 {
+		t.Errorf("%d lock(s) not released", len(locks.locks))
+	}
+}
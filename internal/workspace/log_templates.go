@@ -0,0 +1,166 @@
+package workspace
+
+import (
+	"encoding/json"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/doITmagic/rag-code-mcp/internal/ragcode"
+)
+
+// LogTemplateTable stores the logging call sites of a workspace, keyed by file.
+// It is persisted next to state.json and updated incrementally with it.
+type LogTemplateTable struct {
+	Files map[string][]ragcode.LogTemplate `json:"files"`
+	mu    sync.RWMutex
+}
+
+// NewLogTemplateTable creates an empty table
+func NewLogTemplateTable() *LogTemplateTable {
+	return &LogTemplateTable{
+		Files: make(map[string][]ragcode.LogTemplate),
+	}
+}
+
+func logTemplatesPath(root string) string {
+	return filepath.Join(root, ".ragcode", "log_templates.json")
+}
+
+// LoadLogTemplateTable loads the table from disk, returning an empty table if
+// none has been written yet.
+func LoadLogTemplateTable(path string) (*LogTemplateTable, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return NewLogTemplateTable(), nil
+		}
+		return nil, err
+	}
+
+	table := NewLogTemplateTable()
+	if err := json.Unmarshal(data, table); err != nil {
+		return nil, err
+	}
+	if table.Files == nil {
+		table.Files = make(map[string][]ragcode.LogTemplate)
+	}
+	return table, nil
+}
+
+// Save writes the table to disk
+func (t *LogTemplateTable) Save(path string) error {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := json.Marshal(t)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// SetFile replaces the templates recorded for a file
+func (t *LogTemplateTable) SetFile(path string, templates []ragcode.LogTemplate) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if len(templates) == 0 {
+		delete(t.Files, path)
+		return
+	}
+	t.Files[path] = templates
+}
+
+// RemoveFile drops all templates recorded for a file
+func (t *LogTemplateTable) RemoveFile(path string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.Files, path)
+}
+
+// All returns every template in the table in a stable order
+func (t *LogTemplateTable) All() []ragcode.LogTemplate {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	files := make([]string, 0, len(t.Files))
+	for f := range t.Files {
+		files = append(files, f)
+	}
+	sort.Strings(files)
+
+	var out []ragcode.LogTemplate
+	for _, f := range files {
+		out = append(out, t.Files[f]...)
+	}
+	return out
+}
+
+// HasLanguage reports whether the table holds any template of the language
+func (t *LogTemplateTable) HasLanguage(language string) bool {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	for _, templates := range t.Files {
+		for _, tpl := range templates {
+			if tpl.Language == language {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Match returns the templates matching a runtime log line, most specific first
+func (t *LogTemplateTable) Match(line string) []ragcode.LogMatch {
+	return ragcode.MatchLogLine(t.All(), line, ragcode.MinLogLiteral)
+}
+
+// updateLogTemplates re-extracts templates for indexed files and drops those of
+// deleted files. Languages are indexed concurrently, so updates are serialised.
+// When the table has no templates for the language yet (workspaces indexed
+// before templates were collected), all files of the language are scanned.
+func (m *Manager) updateLogTemplates(info *Info, language string, indexed, deleted, all []string) {
+	m.logTemplatesMu.Lock()
+	defer m.logTemplatesMu.Unlock()
+
+	path := logTemplatesPath(info.Root)
+	table, err := LoadLogTemplateTable(path)
+	if err != nil {
+		log.Printf("⚠️  Failed to load log templates: %v", err)
+		table = NewLogTemplateTable()
+	}
+
+	if !table.HasLanguage(language) {
+		indexed = all
+	}
+	if len(indexed) == 0 && len(deleted) == 0 {
+		return
+	}
+
+	for _, file := range deleted {
+		table.RemoveFile(file)
+	}
+	for _, file := range indexed {
+		src, err := os.ReadFile(file)
+		if err != nil {
+			continue
+		}
+		table.SetFile(file, ragcode.ExtractLogTemplates(file, src))
+	}
+
+	if err := table.Save(path); err != nil {
+		log.Printf("⚠️  Failed to save log templates: %v", err)
+	}
+}
+
+// LogTemplates returns the logging call sites recorded for a workspace
+func (m *Manager) LogTemplates(info *Info) (*LogTemplateTable, error) {
+	m.logTemplatesMu.Lock()
+	defer m.logTemplatesMu.Unlock()
+	return LoadLogTemplateTable(logTemplatesPath(info.Root))
+}
@@ -1,7 +1,6 @@
 package workspace
 
 import (
-	"bufio"
 	"context"
 	"fmt"
 	"hash/fnv"
@@ -14,9 +13,11 @@ import (
 	"sync"
 	"time"
 
+	"github.com/doITmagic/rag-code-mcp/internal/codetypes"
 	"github.com/doITmagic/rag-code-mcp/internal/config"
 	"github.com/doITmagic/rag-code-mcp/internal/llm"
 	"github.com/doITmagic/rag-code-mcp/internal/memory"
+	"github.com/doITmagic/rag-code-mcp/internal/notify"
 	"github.com/doITmagic/rag-code-mcp/internal/ragcode"
 	"github.com/doITmagic/rag-code-mcp/internal/storage"
 )
@@ -25,13 +26,14 @@ import (
 type Manager struct {
 	detector *Detector
 	cache    *Cache
-	qdrant   *storage.QdrantClient
+	store    storage.VectorStore
 	llm      llm.Provider
 	config   *config.Config
 
 	// Indexing state
 	indexingMu sync.RWMutex
-	indexing   map[string]bool // workspace ID -> is indexing
+	indexing   map[string]bool          // workspace ID -> is indexing
+	progress   map[string]IndexProgress // workspace ID -> chunks embedded by the running run
 
 	// Memory cache
 	memoryMu sync.RWMutex
@@ -44,12 +46,89 @@ type Manager struct {
 	// File watchers
 	watchersMu sync.Mutex
 	watchers   map[string]*FileWatcher
+
+	// Last query per workspace root, to unload idle workspaces (idle.go)
+	idleMu  sync.Mutex
+	lastUse map[string]*workspaceUse
+
+	// Last write of each collection to the registry (registry.go)
+	registryMu    sync.Mutex
+	registrySaved map[string]time.Time
+
+	// Serialise collection creation and memory cache population per
+	// collection, re-index checks per workspace language and indexing runs
+	// per workspace (they share .ragcode/state.json)
+	collectionLocks keyLocks
+	reindexLocks    keyLocks
+	workspaceLocks  keyLocks
+	exclusiveLocks  keyLocks // RunExclusive jobs
+
+	// Background indexing jobs, waited for by Shutdown (shutdown.go)
+	jobsMu     sync.Mutex
+	jobs       sync.WaitGroup
+	jobsCtx    context.Context
+	jobsCancel context.CancelFunc
+	closing    bool
+
+	// Serialises load/modify/save of .ragcode/log_templates.json
+	logTemplatesMu sync.Mutex
+
+	// Serialises load/modify/save of .ragcode/symbols.json
+	symbolsMu sync.Mutex
+
+	// Serialises load/modify/save of .ragcode/usage.json
+	usageMu sync.Mutex
+
+	// Tag rules of rag_code.tag_rules, compiled once
+	taggerOnce sync.Once
+	tagger     *ragcode.Tagger
+	taggerErr  error
+
+	// Second embedding model for A/B comparison (llm.ab_embed), see ab.go
+	abLLM   llm.Provider
+	abModel string
+
+	// Query caches, index generations and the query log, per workspace ID
+	queryMu      sync.Mutex
+	queryCaches  map[string]*QueryCache
+	generations  map[string]uint64
+	queryPrimers map[string]QueryPrimer // tool name -> primer
+	priming      map[string]bool
+
+	// Indexed git commit and files hidden after a branch switch, per
+	// collection (branch.go)
+	branchMu     sync.Mutex
+	indexedHeads map[string]string
+	branchViews  map[string]*branchView
+
+	// BM25 keyword indexes of hybrid search, per collection (keyword_index.go)
+	keywordMu      sync.Mutex
+	keywordLocks   keyLocks
+	keywordIndexes map[string]*cachedKeywordIndex
+
+	// Shared doc collections federated by search_docs (doc_collections.go)
+	docCollections docCollectionCache
+
+	// Ranked result sets kept for paginated searches (pages.go)
+	resultSetsMu sync.Mutex
+	resultSets   map[string]*ResultSet
+
+	// Unsaved editor buffers shadowing indexed files (overlay.go)
+	overlaysMu sync.Mutex
+	overlays   map[overlayKey]*Overlay
+
+	// Receives index_completed and index_failed events (notifications)
+	notifier *notify.Notifier
 }
 
 type workspaceScan struct {
 	LanguageDirs  map[string][]string
 	LanguageFiles map[string][]string // Track individual files per language
 	DocFiles      []string
+	Skipped       []SkippedPath       // Directories left out, relative to the root
+	Excluded      map[string][]string // Source files excluded in .ragcode.yaml, per language
+	TooLarge      map[string][]string // Source files over the size limit, per language
+	Unsupported   map[string]int      // Files of languages without an analyzer
 	TotalFiles    int
 	GeneratedAt   time.Time
 }
@@ -64,6 +143,25 @@ var defaultSkipDirs = map[string]struct{}{
 	"build":        {},
 	"storage":      {},
 	"public":       {},
+	"target":       {},
+	"CMakeFiles":   {},
+	"obj":          {},
+}
+
+// skipReasons explains why each of defaultSkipDirs is not indexed
+var skipReasons = map[string]string{
+	".git":         "version control data",
+	".idea":        "editor settings",
+	".vscode":      "editor settings",
+	"node_modules": "third-party dependencies",
+	"vendor":       "third-party dependencies",
+	"dist":         "build output",
+	"build":        "build output",
+	"storage":      "runtime data",
+	"public":       "public assets",
+	"target":       "build output",
+	"CMakeFiles":   "build output",
+	"obj":          "build output",
 }
 
 func addDirForLanguage(scan *workspaceScan, cache map[string]map[string]struct{}, language, dir string) {
@@ -84,6 +182,30 @@ func addDirForLanguage(scan *workspaceScan, cache map[string]map[string]struct{}
 	scan.LanguageDirs[lang] = append(scan.LanguageDirs[lang], dir)
 }
 
+// sourceLanguage returns the indexed language of a source file, or "" for
+// other files.
+func sourceLanguage(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".go", ".s":
+		// Go assembly is indexed with its package
+		return "go"
+	case ".php":
+		return "php"
+	case ".py":
+		return "python"
+	case ".rs":
+		return "rust"
+	case ".c", ".h", ".cc", ".cpp", ".cxx", ".hh", ".hpp", ".hxx":
+		// C is indexed with C++
+		return "cpp"
+	case ".cs":
+		return "csharp"
+	case ".html", ".htm":
+		return "html"
+	}
+	return ""
+}
+
 func addFileForLanguage(scan *workspaceScan, language, path string) {
 	lang := strings.ToLower(language)
 	if scan.LanguageFiles == nil {
@@ -92,47 +214,85 @@ func addFileForLanguage(scan *workspaceScan, language, path string) {
 	scan.LanguageFiles[lang] = append(scan.LanguageFiles[lang], path)
 }
 
+// SourceFiles lists the source files of a language in the workspace, or of
+// all detected languages when language is empty, skipping the directories
+// indexing skips.
+func (m *Manager) SourceFiles(info *Info, language string) ([]string, error) {
+	scan, err := m.scanWorkspace(info)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan workspace '%s': %w", info.Root, err)
+	}
+	if language != "" {
+		return scan.LanguageFiles[strings.ToLower(language)], nil
+	}
+	var files []string
+	for _, lang := range []string{"go", "php", "python", "rust", "cpp", "csharp", "html"} {
+		files = append(files, scan.LanguageFiles[lang]...)
+	}
+	return files, nil
+}
+
 func (m *Manager) scanWorkspace(info *Info) (*workspaceScan, error) {
 	scan := &workspaceScan{
 		LanguageDirs:  make(map[string][]string),
 		LanguageFiles: make(map[string][]string),
+		Excluded:      make(map[string][]string),
+		TooLarge:      make(map[string][]string),
+		Unsupported:   make(map[string]int),
 		DocFiles:      make([]string, 0),
 		GeneratedAt:   time.Now(),
 	}
 	dirCache := make(map[string]map[string]struct{})
-	err := filepath.WalkDir(info.Root, func(path string, d fs.DirEntry, err error) error {
+	projectCfg, err := LoadProjectConfig(info.Root)
+	if err != nil {
+		log.Printf("⚠️  %v", err)
+		projectCfg = &ProjectConfig{}
+	}
+	exclude := parseIgnorePatterns(projectCfg.Exclude)
+	maxSize := projectCfg.MaxFileSize()
+	err = filepath.WalkDir(info.Root, func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
 			return nil
 		}
+		rel, _ := filepath.Rel(info.Root, path)
+		rel = filepath.ToSlash(rel)
 		if d.IsDir() {
 			if path == info.Root {
 				return nil
 			}
 			if _, skip := defaultSkipDirs[d.Name()]; skip {
+				scan.Skipped = append(scan.Skipped, SkippedPath{Path: rel, Reason: skipReasons[d.Name()]})
+				return filepath.SkipDir
+			}
+			if exclude.match(rel, true) {
+				scan.Skipped = append(scan.Skipped, SkippedPath{Path: rel, Reason: "excluded in " + ProjectConfigFile})
 				return filepath.SkipDir
 			}
 			return nil
 		}
+		lang := sourceLanguage(path)
+		if exclude.match(rel, false) {
+			if lang != "" {
+				scan.Excluded[lang] = append(scan.Excluded[lang], path)
+			}
+			return nil
+		}
 
 		scan.TotalFiles++
-		ext := strings.ToLower(filepath.Ext(path))
-		switch ext {
-		case ".go":
-			addDirForLanguage(scan, dirCache, "go", filepath.Dir(path))
-			addFileForLanguage(scan, "go", path)
-		case ".php":
-			addDirForLanguage(scan, dirCache, "php", filepath.Dir(path))
-			addFileForLanguage(scan, "php", path)
-		case ".py":
-			addDirForLanguage(scan, dirCache, "python", filepath.Dir(path))
-			addFileForLanguage(scan, "python", path)
-		case ".html", ".htm":
-			addDirForLanguage(scan, dirCache, "html", filepath.Dir(path))
-			addFileForLanguage(scan, "html", path)
-		case ".md":
+		switch {
+		case lang != "":
+			if fi, err := d.Info(); err == nil && fi.Size() > maxSize {
+				scan.TooLarge[lang] = append(scan.TooLarge[lang], path)
+				return nil
+			}
+			addDirForLanguage(scan, dirCache, lang, filepath.Dir(path))
+			addFileForLanguage(scan, lang, path)
+		case strings.EqualFold(filepath.Ext(path), ".md"):
 			scan.DocFiles = append(scan.DocFiles, path)
 		default:
-			// ignored
+			if other := extensionLanguage(filepath.Ext(path)); other != "" {
+				scan.Unsupported[other]++
+			}
 		}
 		return nil
 	})
@@ -198,7 +358,7 @@ func (m *Manager) NeedsReindex(info *Info, language string) (bool, error) {
 }
 
 // NewManager creates a new workspace manager
-func NewManager(qdrant *storage.QdrantClient, llm llm.Provider, cfg *config.Config) *Manager {
+func NewManager(store storage.VectorStore, llm llm.Provider, cfg *config.Config) *Manager {
 	// Create detector with config or defaults
 	var detector *Detector
 	if cfg != nil && cfg.Workspace.Enabled {
@@ -209,13 +369,16 @@ func NewManager(qdrant *storage.QdrantClient, llm llm.Provider, cfg *config.Conf
 	} else {
 		detector = NewDetector()
 	}
+	if cfg != nil {
+		detector.SetIdentity(cfg.Workspace.Identity)
+	}
 
 	log.Printf("🔧 Workspace Manager initialized (logging verified)")
 
 	return &Manager{
 		detector: detector,
 		cache:    NewCache(5 * time.Minute),
-		qdrant:   qdrant,
+		store:    store,
 		llm:      llm,
 		config:   cfg,
 		indexing: make(map[string]bool),
@@ -224,6 +387,92 @@ func NewManager(qdrant *storage.QdrantClient, llm llm.Provider, cfg *config.Conf
 	}
 }
 
+// SetNotifier sets where indexing results are reported (notifications).
+func (m *Manager) SetNotifier(n *notify.Notifier) {
+	m.notifier = n
+}
+
+// Ranking returns the configured search ranking weights, with the defaults
+// for those left unset, e.g. by a config not built by config.Load.
+func (m *Manager) Ranking() config.RankingConfig {
+	def := config.DefaultRankingConfig()
+	if m == nil || m.config == nil {
+		return def
+	}
+	r := m.config.Ranking
+	for _, w := range []struct{ value, def *float64 }{
+		{&r.VectorWeight, &def.VectorWeight},
+		{&r.KeywordWeight, &def.KeywordWeight},
+		{&r.ExactNameBonus, &def.ExactNameBonus},
+		{&r.RecencyWeight, &def.RecencyWeight},
+		{&r.RecencyHalfLifeDays, &def.RecencyHalfLifeDays},
+		{&r.ProximityWeight, &def.ProximityWeight},
+		{&r.TestPenalty, &def.TestPenalty},
+		{&r.GeneratedPenalty, &def.GeneratedPenalty},
+		{&r.DeprecatedPenalty, &def.DeprecatedPenalty},
+	} {
+		if *w.value == 0 {
+			*w.value = *w.def
+		}
+	}
+	return r
+}
+
+// DocLanguages returns the preferred documentation languages (docs.languages).
+func (m *Manager) DocLanguages() []string {
+	if m == nil || m.config == nil {
+		return nil
+	}
+	return m.config.Docs.Languages
+}
+
+// Edits returns the settings of the file-modifying tools (edits).
+func (m *Manager) Edits() config.EditsConfig {
+	if m == nil || m.config == nil {
+		return config.EditsConfig{}
+	}
+	return m.config.Edits
+}
+
+// CodeFences returns how tool responses fence code (output.code_fences):
+// language, plain or none.
+func (m *Manager) CodeFences() string {
+	if m == nil || m.config == nil || m.config.Output.CodeFences == "" {
+		return "language"
+	}
+	return m.config.Output.CodeFences
+}
+
+// ContextWindow returns the context window of clients that do not announce
+// one (output.context_window), 0 when unknown
+func (m *Manager) ContextWindow() int {
+	if m == nil || m.config == nil {
+		return 0
+	}
+	return m.config.Output.ContextWindow
+}
+
+// SearchTimeout returns the deadline of one call of a search tool
+// (queries.tool_timeouts, else queries.timeout), 0 for none
+func (m *Manager) SearchTimeout(tool string) time.Duration {
+	if m == nil || m.config == nil {
+		return 0
+	}
+	if timeout, ok := m.config.Queries.ToolTimeouts[tool]; ok {
+		return timeout
+	}
+	return m.config.Queries.Timeout
+}
+
+// SimilarityThreshold returns the similarity from which find_similar_code
+// reports a chunk (queries.similarity_threshold)
+func (m *Manager) SimilarityThreshold() float64 {
+	if m == nil || m.config == nil || m.config.Queries.SimilarityThreshold <= 0 {
+		return config.DefaultQueriesConfig().SimilarityThreshold
+	}
+	return m.config.Queries.SimilarityThreshold
+}
+
 // DetectWorkspace detects workspace from tool parameters
 func (m *Manager) DetectWorkspace(params map[string]interface{}) (*Info, error) {
 	// Try to extract file path for cache key
@@ -255,6 +504,11 @@ func (m *Manager) DetectWorkspace(params map[string]interface{}) (*Info, error)
 		info.CollectionPrefix = m.config.Workspace.CollectionPrefix
 	}
 
+	// .ragcode.yaml may limit the indexed languages
+	if projectCfg, err := LoadProjectConfig(info.Root); err == nil {
+		info.Languages = projectCfg.FilterLanguages(info.Languages)
+	}
+
 	// Cache result
 	if cacheKey != "" {
 		m.cache.Set(cacheKey, info)
@@ -278,7 +532,18 @@ func (m *Manager) GetMemoryForWorkspace(ctx context.Context, info *Info) (memory
 
 // GetMemoryForWorkspaceLanguage returns a memory instance for a specific language in the workspace
 // Creates collection and triggers indexing if needed
+// Searches of the memory leave out the files that changed since the
+// commit the collection was indexed at (see syncBranch).
 func (m *Manager) GetMemoryForWorkspaceLanguage(ctx context.Context, info *Info, language string) (memory.LongTermMemory, error) {
+	mem, err := m.memoryForWorkspaceLanguage(ctx, info, language)
+	if err != nil {
+		return nil, err
+	}
+	m.syncBranch(ctx, info, language, info.CollectionNameForLanguage(language), mem)
+	return mem, nil
+}
+
+func (m *Manager) memoryForWorkspaceLanguage(ctx context.Context, info *Info, language string) (memory.LongTermMemory, error) {
 	// Validate workspace root - reject suspicious directories
 	homeDir, _ := os.UserHomeDir()
 	if info.Root == "/" || info.Root == homeDir || strings.HasPrefix(info.Root, "/tmp") {
@@ -294,28 +559,29 @@ func (m *Manager) GetMemoryForWorkspaceLanguage(ctx context.Context, info *Info,
 	m.StartWatcher(info.Root)
 
 	collectionName := info.CollectionNameForLanguage(language)
+	m.touchWorkspace(info, collectionName)
+	m.recordCollectionUse(info, language, collectionName)
 
 	// Check memory cache
-	m.memoryMu.RLock()
-	if mem, ok := m.memories[collectionName]; ok {
-		m.memoryMu.RUnlock()
+	if mem, ok := m.cachedMemory(collectionName); ok {
 		return mem, nil
 	}
-	m.memoryMu.RUnlock()
 
-	// Create collection-specific client FIRST (before checking existence)
-	collectionConfig := storage.QdrantConfig{
-		URL:        m.config.Storage.VectorDB.URL,
-		APIKey:     m.config.Storage.VectorDB.APIKey,
-		Collection: collectionName,
+	// Concurrent calls wait for the first one to create the collection and
+	// then find its memory in the cache
+	unlock := m.collectionLocks.Lock(collectionName)
+	defer unlock()
+	if mem, ok := m.cachedMemory(collectionName); ok {
+		return mem, nil
 	}
 
-	collectionClient, err := storage.NewQdrantClient(collectionConfig)
+	// Create collection-specific client FIRST (before checking existence)
+	collectionClient, err := storage.Open(m.config.Storage.VectorDB, collectionName)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create collection client: %w", err)
 	}
 
-	// Check if collection exists in Qdrant using collection-specific client
+	// Check if collection exists using collection-specific client
 	exists, err := collectionClient.CollectionExists(ctx, collectionName)
 	if err != nil {
 		collectionClient.Close()
@@ -338,18 +604,17 @@ func (m *Manager) GetMemoryForWorkspaceLanguage(ctx context.Context, info *Info,
 
 			if currentCount >= m.config.Workspace.MaxWorkspaces {
 				collectionClient.Close()
-				return nil, fmt.Errorf("workspace limit reached (%d/%d). Increase max_workspaces in config or clean up old workspaces",
+				return nil, fmt.Errorf("workspace limit reached (%d/%d). Increase max_workspaces in config or remove unused workspaces with cleanup_workspaces",
 					currentCount, m.config.Workspace.MaxWorkspaces)
 			}
 		}
 
 		// Get embedding dimension from LLM
-		testEmbed, err := m.llm.Embed(ctx, "test")
+		vectorDim, err := llm.EmbeddingDimension(ctx, m.llm)
 		if err != nil {
 			collectionClient.Close()
 			return nil, fmt.Errorf("failed to get embedding dimension: %w", err)
 		}
-		vectorDim := len(testEmbed)
 
 		// Create collection using collection-specific client
 		if err := collectionClient.CreateCollection(ctx, collectionName, vectorDim); err != nil {
@@ -361,25 +626,31 @@ func (m *Manager) GetMemoryForWorkspaceLanguage(ctx context.Context, info *Info,
 
 		// Trigger background indexing only if auto_index is enabled
 		if m.config != nil && m.config.Workspace.AutoIndex {
-			// Pass a long-lived context for background indexing
-			indexCtx := context.Background()
-			go func() {
-				if err := m.IndexLanguage(indexCtx, info, language, collectionName); err != nil {
+			// Background indexing outlives the request, until shutdown
+			m.background(func(ctx context.Context) {
+				if err := m.IndexLanguage(ctx, info, language, collectionName); err != nil {
 					log.Printf("❌ Background indexing failed: %v", err)
 				}
-			}()
+			})
 		} else {
 			log.Printf("⏸️  Auto-indexing disabled for workspace '%s' language '%s'. Run manual indexing.", info.Root, language)
 		}
 	} else {
+		m.warnDimensionMismatch(ctx, collectionClient, collectionName)
 		// Collection exists - check if files have changed and trigger incremental re-indexing
 		if m.config != nil && m.config.Workspace.AutoIndex {
-			go m.checkAndReindexIfNeeded(context.Background(), info, language, collectionName)
+			m.background(func(ctx context.Context) {
+				m.checkAndReindexIfNeeded(ctx, info, language, collectionName)
+			})
 		}
 	}
 
-	// Create memory instance with collection-specific client
-	mem := storage.NewQdrantLongTermMemory(collectionClient)
+	// Create memory instance with collection-specific client. Searches see
+	// the last committed index generation.
+	mem := storage.NewVectorStoreMemory(collectionClient)
+	if state, err := LoadState(filepath.Join(info.Root, ".ragcode", "state.json")); err == nil {
+		mem.SetVisibleGeneration(state.Generation(collectionName))
+	}
 
 	m.memoryMu.Lock()
 	m.memories[collectionName] = mem
@@ -388,6 +659,14 @@ func (m *Manager) GetMemoryForWorkspaceLanguage(ctx context.Context, info *Info,
 	return mem, nil
 }
 
+// cachedMemory returns the memory of a collection loaded before
+func (m *Manager) cachedMemory(collectionName string) (memory.LongTermMemory, bool) {
+	m.memoryMu.RLock()
+	defer m.memoryMu.RUnlock()
+	mem, ok := m.memories[collectionName]
+	return mem, ok
+}
+
 // GetMemoriesForAllLanguages returns memory instances for all detected languages in the workspace
 // Creates collections and triggers indexing if needed
 func (m *Manager) GetMemoriesForAllLanguages(ctx context.Context, info *Info) (map[string]memory.LongTermMemory, error) {
@@ -425,6 +704,14 @@ func (m *Manager) GetMemoriesForAllLanguages(ctx context.Context, info *Info) (m
 // IndexLanguage indexes a specific language in a workspace
 // It runs synchronously. Use StartIndexing for background execution.
 func (m *Manager) IndexLanguage(ctx context.Context, info *Info, language string, collectionName string) error {
+	return m.IndexLanguageSince(ctx, info, language, collectionName, "")
+}
+
+// IndexLanguageSince is IndexLanguage checking only the files git reports as
+// changed since sinceRef, instead of the modification time of every file.
+// SinceLastIndexed names the commit of the last run; the first run without
+// one scans everything.
+func (m *Manager) IndexLanguageSince(ctx context.Context, info *Info, language string, collectionName string, sinceRef string) (err error) {
 	// Check if already indexing
 	indexKey := info.ID + "-" + language
 	m.indexingMu.Lock()
@@ -435,11 +722,65 @@ func (m *Manager) IndexLanguage(ctx context.Context, info *Info, language string
 	m.indexing[indexKey] = true
 	m.indexingMu.Unlock()
 
-	// Ensure we clear indexing flag when done
+	// Ensure we clear indexing flag when done. Priming the query cache
+	// waits for it: searches answer "being indexed" while it is set.
+	prime := false
 	defer func() {
 		m.indexingMu.Lock()
 		delete(m.indexing, indexKey)
+		delete(m.progress, indexKey)
 		m.indexingMu.Unlock()
+		if prime {
+			m.primeQueryCache(info)
+		}
+	}()
+
+	// Languages of a workspace index one at a time: each run loads, updates
+	// and saves the same state file
+	unlockWorkspace := m.workspaceLocks.Lock(info.ID)
+	defer unlockWorkspace()
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("indexing cancelled: %w", err)
+	}
+
+	// Report the outcome to operators. Runs without changes are not
+	// reported: they happen on every auto-reindex check.
+	start := time.Now()
+	changed := 0
+	defer func() {
+		ev := notify.Event{Type: notify.IndexFailed, Workspace: info.Root, Language: language}
+		if err != nil {
+			ev.Message = err.Error()
+		} else if changed > 0 {
+			ev.Type = notify.IndexCompleted
+			ev.Files = changed
+			ev.DurationMs = time.Since(start).Milliseconds()
+			ev.Message = fmt.Sprintf("%d file(s) changed", changed)
+		} else {
+			return
+		}
+		m.notifier.Notify(ev)
+	}()
+
+	// Record the embedding requests of the run with the tool calls
+	ctx, calls := llm.WithCallUsage(ctx)
+	defer func() {
+		providers := calls.Totals()
+		if len(providers) == 0 {
+			return
+		}
+		outcome := UsageHit
+		if err != nil {
+			outcome = UsageError
+		}
+		if recErr := m.RecordUsage(info, UsageEvent{
+			Tool:      IndexingUsageTool,
+			Outcome:   outcome,
+			Duration:  time.Since(start),
+			Providers: providers,
+		}); recErr != nil {
+			log.Printf("⚠️  Failed to record usage statistics: %v", recErr)
+		}
 	}()
 
 	log.Printf("🚀 Starting indexing for workspace: %s", info.Root)
@@ -447,33 +788,25 @@ func (m *Manager) IndexLanguage(ctx context.Context, info *Info, language string
 	log.Printf("   Language: %s", language)
 	log.Printf("   Project type: %s", info.ProjectType)
 
-	// Create collection-specific memory
-	collectionConfig := storage.QdrantConfig{
-		URL:        m.config.Storage.VectorDB.URL,
-		APIKey:     m.config.Storage.VectorDB.APIKey,
-		Collection: collectionName,
-	}
-
-	collectionClient, err := storage.NewQdrantClient(collectionConfig)
+	// Create collection-specific memory; the client only serves this run
+	collectionClient, err := storage.Open(m.config.Storage.VectorDB, collectionName)
 	if err != nil {
 		return fmt.Errorf("failed to create collection client: %w", err)
 	}
-	// We should close the client, but LongTermMemory might need it?
-	// QdrantLongTermMemory takes *QdrantClient.
-	// If we close it here, LTM might fail if it uses it later?
-	// But LTM is used within this function scope mostly.
-	// Actually, NewQdrantLongTermMemory just stores the reference.
 	defer collectionClient.Close()
 
-	ltm := storage.NewQdrantLongTermMemory(collectionClient)
-
 	// Select analyzer based on language (not ProjectType)
-	analyzerManager := ragcode.NewAnalyzerManager()
+	analyzerManager := ragcode.NewAnalyzerManagerWithOptions(m.config.RagCode.IndexTests)
 	analyzer := analyzerManager.CodeAnalyzerForProjectType(language)
 	if analyzer == nil {
 		return fmt.Errorf("no code analyzer available for language '%s'", language)
 	}
 
+	pipeline, err := m.chunkPipeline()
+	if err != nil {
+		return err
+	}
+
 	// Scan workspace once to determine relevant paths per language
 	scan, err := m.scanWorkspace(info)
 	if err != nil {
@@ -493,6 +826,34 @@ func (m *Manager) IndexLanguage(ctx context.Context, info *Info, language string
 		state = NewWorkspaceState()
 	}
 
+	// The run writes the next index generation; searches keep seeing the
+	// committed one until the run is done. Points of runs that never
+	// committed are dropped first.
+	committed := state.Generation(collectionName)
+	if err := collectionClient.DeleteNewerGenerations(ctx, committed); err != nil {
+		log.Printf("⚠️  Failed to drop uncommitted chunks: %v", err)
+	}
+	run := &generationRun{client: collectionClient, collection: collectionName, gen: committed + 1}
+	ltm := generationMemory{LongTermMemory: storage.NewVectorStoreMemory(collectionClient), gen: run.gen}
+	// Chunks record the branch and commit they were indexed from
+	head, headErr := GitHead(ctx, info.Root)
+	if headErr == nil {
+		ltm.head = head
+		ltm.branch, _ = GitBranch(ctx, info.Root)
+	}
+
+	gitChanged, err := gitChangedSet(ctx, info.Root, sinceRef, state, collectionName)
+	if err != nil {
+		return fmt.Errorf("failed to list files changed since %s: %w", sinceRef, err)
+	}
+	if gitChanged != nil {
+		log.Printf("🔀 git reports %d changed file(s) since %s", len(gitChanged), sinceRef)
+	}
+	// checked reports whether a file may have changed since the last run
+	checked := func(path string) bool {
+		return gitChanged == nil || gitChanged[path]
+	}
+
 	// Identify changes
 	var filesToIndex []string
 	var filesToDelete []string
@@ -508,16 +869,23 @@ func (m *Manager) IndexLanguage(ctx context.Context, info *Info, language string
 
 	// Check for added or modified files (Code)
 	for _, path := range currentFiles {
+		if !checked(path) {
+			continue
+		}
 		info, err := os.Stat(path)
 		if err != nil {
 			continue
 		}
 
 		fileState, exists := state.GetFileState(path)
-		if !exists || info.ModTime().After(fileState.ModTime) || info.Size() != fileState.Size {
-			filesToIndex = append(filesToIndex, path)
-			if exists {
-				filesToDelete = append(filesToDelete, path)
+		if gitChanged != nil || !exists || info.ModTime().After(fileState.ModTime) || info.Size() != fileState.Size {
+			if exists && quarantined(state, path) {
+				log.Printf("⏭️  Skipping %s: content unchanged since it failed to parse", path)
+			} else {
+				filesToIndex = append(filesToIndex, path)
+				if exists {
+					filesToDelete = append(filesToDelete, path)
+				}
 			}
 		}
 
@@ -530,13 +898,16 @@ func (m *Manager) IndexLanguage(ctx context.Context, info *Info, language string
 	var docsToDelete []string
 
 	for _, path := range currentDocs {
+		if !checked(path) {
+			continue
+		}
 		info, err := os.Stat(path)
 		if err != nil {
 			continue
 		}
 
 		fileState, exists := state.GetFileState(path)
-		if !exists || info.ModTime().After(fileState.ModTime) || info.Size() != fileState.Size {
+		if gitChanged != nil || !exists || info.ModTime().After(fileState.ModTime) || info.Size() != fileState.Size {
 			docsToIndex = append(docsToIndex, path)
 			if exists {
 				docsToDelete = append(docsToDelete, path)
@@ -547,12 +918,29 @@ func (m *Manager) IndexLanguage(ctx context.Context, info *Info, language string
 		state.UpdateFile(path, info)
 	}
 
+	// The glossary configured in .ragcode.yaml is indexed with the docs
+	glossaryFile := ""
+	if projectCfg, err := LoadProjectConfig(info.Root); err != nil {
+		log.Printf("⚠️  %v", err)
+	} else if path := projectCfg.GlossaryPath(info.Root); path != "" {
+		if fi, err := os.Stat(path); err == nil {
+			fileState, exists := state.GetFileState(path)
+			if !exists || fi.ModTime().After(fileState.ModTime) || fi.Size() != fileState.Size {
+				glossaryFile = path
+			}
+			state.UpdateFile(path, fi)
+		}
+	}
+
 	// Check for deleted files (both code and docs)
 	// We scan the state and check if files still exist in current scan
 	// But scan only has current files.
 	// Better: iterate state.Files and check if they exist on disk.
 	state.mu.RLock()
 	for path := range state.Files {
+		if !checked(path) {
+			continue
+		}
 		if _, err := os.Stat(path); os.IsNotExist(err) {
 			// It's deleted. Determine if it was code or doc based on extension
 			ext := strings.ToLower(filepath.Ext(path))
@@ -565,41 +953,93 @@ func (m *Manager) IndexLanguage(ctx context.Context, info *Info, language string
 	}
 	state.mu.RUnlock()
 
-	// Process deletions (Code)
-	if len(filesToDelete) > 0 {
-		log.Printf("🗑️  Deleting %d modified/deleted code files from index...", len(filesToDelete))
-		for _, path := range filesToDelete {
-			if err := ltm.DeleteByMetadata(ctx, "file", path); err != nil {
-				log.Printf("⚠️  Failed to delete chunks for %s: %v", path, err)
+	// Modified files are in both lists
+	changedFiles := make(map[string]bool)
+	for _, list := range [][]string{filesToIndex, filesToDelete, docsToIndex, docsToDelete} {
+		for _, path := range list {
+			changedFiles[path] = true
+		}
+	}
+	changed = len(changedFiles)
+
+	// Files of this run not fully indexed yet: left out of the state saved
+	// when the run is interrupted
+	pending := make(map[string]bool)
+	for _, list := range [][]string{filesToIndex, docsToIndex, {glossaryFile}} {
+		for _, path := range list {
+			if path != "" {
+				pending[path] = true
+				run.written = append(run.written, path)
 			}
-			state.RemoveFile(path)
 		}
 	}
 
-	// Process deletions (Docs)
-	if len(docsToDelete) > 0 {
-		log.Printf("🗑️  Deleting %d modified/deleted doc files from index...", len(docsToDelete))
-		for _, path := range docsToDelete {
-			if err := ltm.DeleteByMetadata(ctx, "file", path); err != nil {
-				log.Printf("⚠️  Failed to delete chunks for %s: %v", path, err)
+	// Chunks of modified and deleted files are purged once the run is
+	// committed, so searches never see a file half re-indexed
+	for _, list := range [][]string{filesToDelete, docsToDelete} {
+		for _, path := range list {
+			if _, err := os.Stat(path); os.IsNotExist(err) {
+				run.deleted = append(run.deleted, path)
+				state.RemoveFile(path)
 			}
-			state.RemoveFile(path)
 		}
 	}
+	if len(run.deleted) > 0 {
+		log.Printf("🗑️  %d deleted file(s) leave the index with this run", len(run.deleted))
+	}
+
+	m.updateProgress(indexKey, func(p *IndexProgress) {
+		*p = IndexProgress{Phase: PhaseAnalyzing, FilesQueued: len(filesToIndex), StartedAt: time.Now()}
+	})
 
 	// Process indexing (Code)
+	var analyzedChunks []codetypes.CodeChunk
 	if len(filesToIndex) > 0 {
 		log.Printf("📝 Indexing %d new/modified code files...", len(filesToIndex))
 
 		indexer := ragcode.NewIndexer(analyzer, m.llm, ltm)
+		if m.config != nil && m.config.RagCode.GitBlame {
+			indexer.EnableGitBlame()
+		}
+		indexer.SetPostProcessors(pipeline)
+		boilerplate := m.boilerplate(info)
+		indexer.SetBoilerplate(boilerplate)
+		if m.config != nil {
+			indexer.SetEmbedConcurrency(m.config.RagCode.EmbedBatchSize, m.config.RagCode.EmbedWorkers)
+		}
+		indexer.OnProgress(m.progressReporter(indexKey))
+		indexer.OnAnalyzed(func(chunks []codetypes.CodeChunk) {
+			analyzedChunks = chunks
+		})
+		indexer.OnFileStarted(func(path string) {
+			m.updateProgress(indexKey, func(p *IndexProgress) {
+				if p.embedStart.IsZero() {
+					p.embedStart = time.Now()
+				}
+				p.CurrentFile = path
+			})
+		})
+		indexer.OnFileIndexed(func(path string) {
+			delete(pending, path)
+			m.updateProgress(indexKey, func(p *IndexProgress) { p.FilesIndexed++ })
+		})
 
 		startTime := time.Now()
 		numChunks, err := indexer.IndexPaths(ctx, filesToIndex, collectionName)
 		duration := time.Since(startTime)
 
 		if err != nil {
+			if ctx.Err() != nil {
+				m.commitGeneration(ctx, run, state, stateFile, pending)
+			}
 			return fmt.Errorf("indexing failed: %w", err)
 		}
+		recordParseFailures(state, language, analyzer, filesToIndex, currentFiles)
+		if boilerplate != nil {
+			if err := boilerplate.Save(boilerplatePath(info.Root)); err != nil {
+				log.Printf("⚠️  Failed to save boilerplate lines: %v", err)
+			}
+		}
 		log.Printf("✅ Indexed %d chunks in %v", numChunks, duration)
 	} else {
 		log.Printf("✨ No code changes detected for language '%s'", language)
@@ -608,29 +1048,113 @@ func (m *Manager) IndexLanguage(ctx context.Context, info *Info, language string
 	// Process indexing (Docs)
 	if len(docsToIndex) > 0 {
 		log.Printf("📚 Indexing %d new/modified doc files...", len(docsToIndex))
+		m.updateProgress(indexKey, func(p *IndexProgress) {
+			p.Phase = PhaseDocs
+			p.CurrentFile = ""
+		})
 		// We use indexMarkdownFiles but only for the changed list
-		numDocs := m.indexMarkdownFiles(ctx, docsToIndex, collectionName, ltm)
+		numDocs := m.indexMarkdownFiles(ctx, info.Root, docsToIndex, collectionName, ltm, func(path string) {
+			delete(pending, path)
+		})
 		if numDocs > 0 {
 			log.Printf("   Docs chunks indexed: %d", numDocs)
 		}
+		if err := ctx.Err(); err != nil {
+			m.commitGeneration(ctx, run, state, stateFile, pending)
+			return fmt.Errorf("indexing failed: %w", err)
+		}
 	} else {
 		if len(currentDocs) > 0 {
 			log.Printf("✨ No documentation changes detected")
 		}
 	}
 
-	// Save state
-	if err := state.Save(stateFile); err != nil {
+	if glossaryFile != "" {
+		// Entries of earlier generations are purged on commit
+		numTerms, err := m.indexGlossary(ctx, glossaryFile, collectionName, ltm)
+		if err != nil {
+			log.Printf("⚠️  Glossary indexing failed: %v", err)
+		} else {
+			log.Printf("📖 Indexed %d glossary term(s) from %s", numTerms, glossaryFile)
+		}
+		delete(pending, glossaryFile)
+	}
+
+	// Refresh the symbol table and logging call sites for changed files
+	symbolFiles := filesToIndex
+	if m.symbolsNeedBackfill(info, language, analyzer) && len(currentFiles) > len(filesToIndex) {
+		if chunks, err := analyzer.AnalyzePaths(currentFiles); err == nil {
+			recordParseFailures(state, language, analyzer, currentFiles, currentFiles)
+			codetypes.NormalizeChunkLanguages(chunks)
+			ragcode.AnnotateDeprecations(chunks)
+			symbolFiles, analyzedChunks = currentFiles, chunks
+		} else {
+			log.Printf("⚠️  Failed to analyze files for symbol table: %v", err)
+		}
+	}
+	var imports []codetypes.FileImports
+	if reporter, ok := analyzer.(codetypes.ImportReporter); ok && len(symbolFiles) > 0 {
+		imports = reporter.Imports()
+	}
+	m.updateSymbols(info, language, symbolFiles, filesToDelete, analyzedChunks, imports)
+	m.updateLogTemplates(info, language, filesToIndex, filesToDelete, currentFiles)
+
+	// Record the commit this run indexed, for since_ref=last_indexed and
+	// to detect branch switches
+	if headErr == nil {
+		state.SetGitHead(collectionName, head)
+	}
+
+	// Save state, committing the new generation when anything changed
+	if len(run.written) > 0 || len(run.deleted) > 0 {
+		m.commitGeneration(ctx, run, state, stateFile, pending)
+	} else if err := state.Save(stateFile); err != nil {
 		log.Printf("⚠️  Failed to save workspace state: %v", err)
 	}
+	if headErr == nil {
+		m.setIndexedHead(collectionName, head)
+	}
+
+	// Cached query results are stale once anything was re-indexed
+	if len(filesToIndex) > 0 || len(filesToDelete) > 0 || len(docsToIndex) > 0 || len(docsToDelete) > 0 || glossaryFile != "" {
+		m.bumpIndexGeneration(info)
+		prime = true
+	}
 
 	m.recordFingerprint(info, language, scan)
 	return nil
 }
 
+func boilerplatePath(root string) string {
+	return filepath.Join(root, ".ragcode", "boilerplate.json")
+}
+
+// boilerplate returns the boilerplate filter of a workspace with the lines
+// learned so far, or nil when rag_code.keep_boilerplate is set.
+func (m *Manager) boilerplate(info *Info) *ragcode.Boilerplate {
+	if m.config != nil && m.config.RagCode.KeepBoilerplate {
+		return nil
+	}
+	b, err := ragcode.LoadBoilerplate(boilerplatePath(info.Root))
+	if err != nil {
+		log.Printf("⚠️  Failed to load boilerplate lines, relearning: %v", err)
+		return ragcode.NewBoilerplate()
+	}
+	return b
+}
+
 // checkAndReindexIfNeeded checks if any files have changed and triggers incremental re-indexing if needed
 // This is called automatically when a tool accesses an existing workspace collection
 func (m *Manager) checkAndReindexIfNeeded(ctx context.Context, info *Info, language string, collectionName string) {
+	// One check per workspace language at a time; a check started while
+	// indexing runs has nothing to add
+	indexKey := info.ID + "-" + language
+	unlock := m.reindexLocks.Lock(indexKey)
+	defer unlock()
+	if m.IsIndexing(indexKey) {
+		return
+	}
+
 	// Load workspace state
 	stateFile := filepath.Join(info.Root, ".ragcode", "state.json")
 	state, err := LoadState(stateFile)
@@ -695,8 +1219,57 @@ func (m *Manager) checkAndReindexIfNeeded(ctx context.Context, info *Info, langu
 	}
 }
 
+// Tagger returns the tag rules of rag_code.tag_rules, or nil when none are
+// configured.
+func (m *Manager) Tagger() (*ragcode.Tagger, error) {
+	m.taggerOnce.Do(func() {
+		if m.config == nil || len(m.config.RagCode.TagRules) == 0 {
+			return
+		}
+		rules := make([]ragcode.TagRule, 0, len(m.config.RagCode.TagRules))
+		for _, r := range m.config.RagCode.TagRules {
+			rules = append(rules, ragcode.TagRule{Tags: r.Tags, Paths: r.Paths, PathRegex: r.Regex, Content: r.Content})
+		}
+		if m.tagger, m.taggerErr = ragcode.NewTagger(rules); m.taggerErr != nil {
+			m.taggerErr = fmt.Errorf("invalid rag_code.tag_rules: %w", m.taggerErr)
+		}
+	})
+	return m.tagger, m.taggerErr
+}
+
+// chunkPipeline builds the processors run on analyzed chunks: the tag rules,
+// then rag_code.post_processors, then the rag_code.max_chunk_lines caps (so
+// the other processors see the whole code).
+func (m *Manager) chunkPipeline() (ragcode.ChunkPipeline, error) {
+	var pipeline ragcode.ChunkPipeline
+	tagger, err := m.Tagger()
+	if err != nil {
+		return nil, err
+	}
+	if tagger != nil {
+		pipeline = append(pipeline, tagger)
+	}
+	if m.config != nil && len(m.config.RagCode.PostProcessors) > 0 {
+		specs := make([]ragcode.ChunkProcessorSpec, 0, len(m.config.RagCode.PostProcessors))
+		for _, pc := range m.config.RagCode.PostProcessors {
+			specs = append(specs, ragcode.ChunkProcessorSpec{Type: pc.Type, Options: pc.Options})
+		}
+		processors, err := ragcode.NewChunkPipeline(specs, m.llm)
+		if err != nil {
+			return nil, fmt.Errorf("invalid rag_code.post_processors: %w", err)
+		}
+		pipeline = append(pipeline, processors...)
+	}
+	if m.config != nil {
+		if limiter := ragcode.NewChunkLimiter(m.config.RagCode.MaxChunkLines); limiter != nil {
+			pipeline = append(pipeline, limiter)
+		}
+	}
+	return pipeline, nil
+}
+
 // indexMarkdownFiles indexes provided markdown files (already discovered during scan)
-func (m *Manager) indexMarkdownFiles(ctx context.Context, markdownFiles []string, collectionName string, ltm memory.LongTermMemory) int {
+func (m *Manager) indexMarkdownFiles(ctx context.Context, root string, markdownFiles []string, collectionName string, ltm memory.LongTermMemory, done func(path string)) int {
 	if len(markdownFiles) == 0 {
 		return 0
 	}
@@ -705,65 +1278,71 @@ func (m *Manager) indexMarkdownFiles(ctx context.Context, markdownFiles []string
 
 	totalChunks := 0
 	for _, path := range markdownFiles {
-		chunks, err := m.indexMarkdownFile(ctx, path, collectionName, ltm)
+		if ctx.Err() != nil {
+			break
+		}
+		chunks, err := m.indexMarkdownFile(ctx, root, path, collectionName, ltm)
 		if err != nil {
 			log.Printf("⚠️  Failed to index markdown file %s: %v", path, err)
 			continue
 		}
 		totalChunks += chunks
+		if done != nil {
+			done(path)
+		}
 	}
 
 	return totalChunks
 }
 
-// indexMarkdownFile chunks and indexes a single markdown file
-func (m *Manager) indexMarkdownFile(ctx context.Context, path string, collectionName string, ltm memory.LongTermMemory) (int, error) {
-	f, err := os.Open(path)
+// indexMarkdownFile chunks and indexes a single markdown file along its
+// headings (docs.chunk_max_tokens, docs.chunk_overlap). Every chunk records
+// the detected language of the file under "lang" and its enclosing headings
+// under "headings". YAML front matter (docs.front_matter) is not chunked: its
+// title is stored under "title", its tags join those of tag rules, and its
+// other fields are stored under "front_matter".
+func (m *Manager) indexMarkdownFile(ctx context.Context, root, path string, collectionName string, ltm memory.LongTermMemory) (int, error) {
+	data, err := os.ReadFile(path)
 	if err != nil {
-		return 0, fmt.Errorf("open %s: %w", path, err)
+		return 0, fmt.Errorf("read %s: %w", path, err)
 	}
-	defer f.Close()
-
-	scanner := bufio.NewScanner(f)
-	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
-
 	var (
-		chunks   []string
-		current  strings.Builder
-		maxChars = 1000
+		maxTokens, overlap int
+		frontMatter        ragcode.FrontMatterOptions
 	)
-
-	flushChunk := func() {
-		text := strings.TrimSpace(current.String())
-		if text != "" {
-			chunks = append(chunks, text)
+	if m.config != nil {
+		maxTokens, overlap = m.config.Docs.ChunkMaxTokens, m.config.Docs.ChunkOverlap
+		frontMatter = ragcode.FrontMatterOptions(m.config.Docs.FrontMatter)
+	}
+	fm, body := ragcode.ParseFrontMatter(string(data), frontMatter)
+	chunks := ragcode.ChunkMarkdown(body, maxTokens, overlap)
+	var title, fields string
+	if fm != nil {
+		// Lines of the file, after the front matter
+		for i := range chunks {
+			chunks[i].StartLine += fm.Lines
+			chunks[i].EndLine += fm.Lines
 		}
-		current.Reset()
+		title, fields = fm.Title, fm.FieldsText()
 	}
 
-	for scanner.Scan() {
-		line := scanner.Text()
-		if strings.TrimSpace(line) == "" && current.Len() > 0 {
-			flushChunk()
-			continue
-		}
-
-		if current.Len()+len(line)+1 > maxChars {
-			flushChunk()
-		}
-		if current.Len() > 0 {
-			current.WriteString("\n")
-		}
-		current.WriteString(line)
+	// Detect on the path relative to the workspace, so directories above it
+	// are not mistaken for language tags
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		rel = filepath.Base(path)
 	}
-	if err := scanner.Err(); err != nil {
-		return 0, fmt.Errorf("scan %s: %w", path, err)
+	lang := ragcode.DetectDocLanguage(rel, string(data))
+	tagger, err := m.Tagger()
+	if err != nil {
+		return 0, err
 	}
-	flushChunk()
+	tags := fm.WithTags(tagger.Tags(path, string(data)))
 
 	// Index each chunk
-	for i, text := range chunks {
-		emb, err := m.llm.Embed(ctx, text)
+	for i, chunk := range chunks {
+		breadcrumb := chunk.Breadcrumb()
+		emb, err := m.llm.Embed(ctx, ragcode.MarkdownEmbeddingText(chunk.Text, ragcode.TitledBreadcrumb(title, breadcrumb)))
 		if err != nil {
 			return i, fmt.Errorf("embed failed for %s chunk %d: %w", path, i, err)
 		}
@@ -774,15 +1353,32 @@ func (m *Manager) indexMarkdownFile(ctx context.Context, path string, collection
 
 		doc := memory.Document{
 			ID:        id,
-			Content:   text,
+			Content:   chunk.Text,
 			Embedding: emb,
 			Metadata: map[string]interface{}{
 				"file":       path,
 				"chunk_id":   i,
 				"source":     collectionName,
 				"chunk_type": "markdown",
+				"start_line": chunk.StartLine,
+				"end_line":   chunk.EndLine,
 			},
 		}
+		if breadcrumb != "" {
+			doc.Metadata["headings"] = breadcrumb
+		}
+		if title != "" {
+			doc.Metadata["title"] = title
+		}
+		if fields != "" {
+			doc.Metadata["front_matter"] = fields
+		}
+		if lang != "" {
+			doc.Metadata["lang"] = lang
+		}
+		if len(tags) > 0 {
+			doc.Metadata["tags"] = strings.Join(tags, ",")
+		}
 
 		if err := ltm.Store(ctx, doc); err != nil {
 			return i, fmt.Errorf("store failed for %s: %w", id, err)
@@ -799,17 +1395,173 @@ func (m *Manager) IsIndexing(workspaceID string) bool {
 	return m.indexing[workspaceID]
 }
 
+// LanguageIndexStatus is the index state of one workspace language.
+type LanguageIndexStatus struct {
+	Language   string         `json:"language"`
+	Collection string         `json:"collection"`
+	Indexing   bool           `json:"indexing"`
+	Progress   *IndexProgress `json:"progress,omitempty"` // while indexing
+}
+
+// Phases of an indexing run, in order
+const (
+	PhaseAnalyzing = "analyzing" // parsing the new and modified files
+	PhaseEmbedding = "embedding" // embedding and storing their chunks
+	PhaseDocs      = "docs"      // indexing markdown documentation
+)
+
+// IndexProgress is the progress of a running indexing run: the files it
+// indexes, the chunks it has embedded and stored, and the file it is at
+type IndexProgress struct {
+	Phase        string    `json:"phase"`
+	FilesQueued  int       `json:"files_queued"` // new or modified source files of the run
+	FilesIndexed int       `json:"files_indexed"`
+	CurrentFile  string    `json:"current_file,omitempty"`
+	ChunksDone   int       `json:"chunks_done"`
+	ChunksTotal  int       `json:"chunks_total"`
+	StartedAt    time.Time `json:"started_at"`
+
+	embedStart time.Time // first chunk stored, for the embedding rate
+}
+
+// Percent returns how much of the run is done. Embedding dominates the
+// run, so it is measured in chunks; documentation comes last.
+func (p IndexProgress) Percent() float64 {
+	switch {
+	case p.Phase == PhaseDocs:
+		return 100
+	case p.ChunksTotal > 0:
+		return 100 * float64(p.ChunksDone) / float64(p.ChunksTotal)
+	}
+	return 0
+}
+
+// Remaining estimates the time left from the embedding rate so far, or
+// from estimatedSecondsPerFile before the first chunk is stored. It returns
+// false when nothing is left to estimate.
+func (p IndexProgress) Remaining(now time.Time) (time.Duration, bool) {
+	if p.Phase == PhaseDocs {
+		return 0, false
+	}
+	if p.ChunksDone > 0 && !p.embedStart.IsZero() {
+		elapsed := now.Sub(p.embedStart)
+		return time.Duration(float64(elapsed) * float64(p.ChunksTotal-p.ChunksDone) / float64(p.ChunksDone)), true
+	}
+	left := p.FilesQueued - p.FilesIndexed
+	if left <= 0 {
+		return 0, false
+	}
+	return time.Duration(float64(left) * estimatedSecondsPerFile * float64(time.Second)), true
+}
+
+// IndexProgress returns the progress of the running indexing of a workspace
+// language, false when none is running
+func (m *Manager) IndexProgress(info *Info, language string) (IndexProgress, bool) {
+	m.indexingMu.RLock()
+	defer m.indexingMu.RUnlock()
+	p, ok := m.progress[info.ID+"-"+language]
+	return p, ok
+}
+
+// updateProgress changes the progress of a running indexing run
+func (m *Manager) updateProgress(indexKey string, update func(*IndexProgress)) {
+	m.indexingMu.Lock()
+	defer m.indexingMu.Unlock()
+	if m.progress == nil {
+		m.progress = make(map[string]IndexProgress)
+	}
+	p := m.progress[indexKey]
+	update(&p)
+	m.progress[indexKey] = p
+}
+
+// progressReporter records the chunks an indexing run has stored and logs
+// them every 10% of the chunks
+func (m *Manager) progressReporter(indexKey string) func(done, total int) {
+	lastDecile := 0
+	return func(done, total int) {
+		m.updateProgress(indexKey, func(p *IndexProgress) {
+			p.Phase = PhaseEmbedding
+			p.ChunksDone, p.ChunksTotal = done, total
+		})
+		if decile := done * 10 / total; decile > lastDecile {
+			lastDecile = decile
+			log.Printf("📊 %s: embedded %d/%d chunks (%d%%)", indexKey, done, total, decile*10)
+		}
+	}
+}
+
+// IndexStatus is the index state of a workspace, read from its state file.
+// LastIndexed is zero when the workspace was never indexed.
+type IndexStatus struct {
+	Languages    []LanguageIndexStatus `json:"languages"`
+	IndexedFiles int                   `json:"indexed_files"`
+	LastIndexed  time.Time             `json:"last_indexed"`
+}
+
+// IndexStatus reports the languages of a workspace, whether they are being
+// indexed and what the last indexing run recorded.
+func (m *Manager) IndexStatus(info *Info) (*IndexStatus, error) {
+	status := &IndexStatus{}
+	for _, lang := range info.Languages {
+		status.Languages = append(status.Languages, LanguageIndexStatus{
+			Language:   lang,
+			Collection: info.CollectionNameForLanguage(lang),
+			Indexing:   m.IsIndexing(info.ID + "-" + lang),
+		})
+		if p, ok := m.IndexProgress(info, lang); ok {
+			status.Languages[len(status.Languages)-1].Progress = &p
+		}
+	}
+	state, err := LoadState(filepath.Join(info.Root, ".ragcode", "state.json"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load workspace state: %w", err)
+	}
+	status.IndexedFiles = len(state.Files)
+	status.LastIndexed = state.LastIndexed
+	return status, nil
+}
+
+// StoredChunks returns how many chunks the collection of a workspace
+// language holds, 0 when it has not been created. Unlike
+// GetMemoryForWorkspaceLanguage it never creates the collection.
+func (m *Manager) StoredChunks(ctx context.Context, info *Info, language string) (uint64, error) {
+	if m.config == nil {
+		return 0, fmt.Errorf("no vector store configured")
+	}
+	collectionName := info.CollectionNameForLanguage(language)
+	client, err := storage.Open(m.config.Storage.VectorDB, collectionName)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create collection client: %w", err)
+	}
+	defer client.Close()
+	exists, err := client.CollectionExists(ctx, collectionName)
+	if err != nil {
+		return 0, fmt.Errorf("failed to check collection: %w", err)
+	}
+	if !exists {
+		return 0, nil
+	}
+	return client.GetCollectionPointCount(ctx, collectionName)
+}
+
 // StartIndexing explicitly starts background indexing for a workspace language
 // This is used by the index_workspace tool to manually trigger indexing
 func (m *Manager) StartIndexing(ctx context.Context, info *Info, language string) error {
+	return m.StartIndexingSince(ctx, info, language, "")
+}
+
+// StartIndexingSince starts background indexing of the files changed since a
+// git ref, see IndexLanguageSince
+func (m *Manager) StartIndexingSince(ctx context.Context, info *Info, language string, sinceRef string) error {
 	collectionName := info.CollectionNameForLanguage(language)
 
 	// Start background indexing
-	go func() {
-		if err := m.IndexLanguage(context.Background(), info, language, collectionName); err != nil {
+	m.background(func(ctx context.Context) {
+		if err := m.IndexLanguageSince(ctx, info, language, collectionName, sinceRef); err != nil {
 			log.Printf("❌ Background indexing failed: %v", err)
 		}
-	}()
+	})
 
 	return nil
 }
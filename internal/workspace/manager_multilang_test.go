@@ -162,3 +162,17 @@ func TestIndexingKeyGeneration(t *testing.T) {
 		}
 	}
 }
+
+func TestRankingDefaults(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Ranking.KeywordWeight = 0.9
+	m := &Manager{config: cfg}
+
+	r, def := m.Ranking(), config.DefaultRankingConfig()
+	if r.KeywordWeight != 0.9 {
+		t.Errorf("KeywordWeight = %v, want the configured 0.9", r.KeywordWeight)
+	}
+	if r.VectorWeight != def.VectorWeight || r.TestPenalty != def.TestPenalty || r.ProximityWeight != def.ProximityWeight {
+		t.Errorf("unset weights = %+v, want the defaults %+v", r, def)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/doITmagic/rag-code-mcp/internal/config"
@@ -49,6 +50,10 @@ func (m *MockLongTermMemory) Search(ctx context.Context, query []float64, limit
 	return m.docs, nil
 }
 
+func (m *MockLongTermMemory) Query(ctx context.Context, opts memory.SearchOptions) ([]memory.Document, error) {
+	return m.docs, nil
+}
+
 func (m *MockLongTermMemory) Delete(ctx context.Context, id string) error {
 	for i, doc := range m.docs {
 		if doc.ID == id {
@@ -78,6 +83,18 @@ func (m *MockLongTermMemory) Clear(ctx context.Context) error {
 	return nil
 }
 
+func (m *MockLongTermMemory) Count(ctx context.Context, filter memory.Filter) (uint64, error) {
+	return uint64(len(m.docs)), nil
+}
+
+func (m *MockLongTermMemory) Scroll(ctx context.Context, filter memory.Filter, cursor string, limit int) ([]memory.Document, string, error) {
+	return nil, "", nil
+}
+
+func (m *MockLongTermMemory) Aggregate(ctx context.Context, filter memory.Filter, groupBy string) (map[string]uint64, error) {
+	return nil, nil
+}
+
 func TestMarkdownIndexing(t *testing.T) {
 	// Create a temporary workspace with markdown files
 	tmpDir := t.TempDir()
@@ -153,7 +170,7 @@ Use this tool to do things.
 	if err != nil {
 		t.Fatalf("Failed to scan workspace: %v", err)
 	}
-	numChunks := manager.indexMarkdownFiles(ctx, scan.DocFiles, "test-collection", mockLTM)
+	numChunks := manager.indexMarkdownFiles(ctx, info.Root, scan.DocFiles, "test-collection", mockLTM, nil)
 
 	if numChunks == 0 {
 		t.Error("Expected to index markdown chunks, got 0")
@@ -222,7 +239,7 @@ func TestMarkdownIndexing_SkipsCommonDirs(t *testing.T) {
 	if err != nil {
 		t.Fatalf("Failed to scan workspace: %v", err)
 	}
-	numChunks := manager.indexMarkdownFiles(ctx, scan.DocFiles, "test-collection", mockLTM)
+	numChunks := manager.indexMarkdownFiles(ctx, info.Root, scan.DocFiles, "test-collection", mockLTM, nil)
 
 	// Should only index the root README, not the ones in skip dirs
 	if numChunks == 0 {
@@ -242,3 +259,52 @@ func TestMarkdownIndexing_SkipsCommonDirs(t *testing.T) {
 
 	t.Logf("Correctly indexed %d chunks, skipping common directories", numChunks)
 }
+
+func TestMarkdownIndexing_FrontMatter(t *testing.T) {
+	tmpDir := t.TempDir()
+	content := `---
+title: Deploy guide
+tags: [Ops, kubernetes]
+owners:
+  - alice
+  - bob
+---
+# Deploy
+
+Roll out with kubectl apply.
+`
+	path := filepath.Join(tmpDir, "deploy.md")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	mockLTM := &MockLongTermMemory{}
+	manager := &Manager{llm: &MockLLMProvider{}, config: &config.Config{}}
+	n, err := manager.indexMarkdownFile(context.Background(), tmpDir, path, "test-collection", mockLTM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 1 || len(mockLTM.docs) != 1 {
+		t.Fatalf("indexed %d chunks, want 1: %+v", n, mockLTM.docs)
+	}
+	doc := mockLTM.docs[0]
+	if doc.Content != "# Deploy\n\nRoll out with kubectl apply." {
+		t.Errorf("content = %q, want the body without front matter", doc.Content)
+	}
+	if doc.Metadata["title"] != "Deploy guide" || doc.Metadata["tags"] != "kubernetes,ops" || doc.Metadata["front_matter"] != "owners: alice, bob" {
+		t.Errorf("metadata = %+v", doc.Metadata)
+	}
+	if doc.Metadata["start_line"] != 8 || doc.Metadata["end_line"] != 10 {
+		t.Errorf("lines %v-%v, want 8-10", doc.Metadata["start_line"], doc.Metadata["end_line"])
+	}
+
+	// Disabled, the front matter is text
+	mockLTM = &MockLongTermMemory{}
+	manager.config.Docs.FrontMatter.Disabled = true
+	if _, err := manager.indexMarkdownFile(context.Background(), tmpDir, path, "test-collection", mockLTM); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := mockLTM.docs[0].Metadata["title"]; ok || !strings.Contains(mockLTM.docs[0].Content, "owners:") {
+		t.Errorf("disabled front matter parsed: %+v", mockLTM.docs[0])
+	}
+}
@@ -0,0 +1,115 @@
+package workspace
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/doITmagic/rag-code-mcp/internal/codetypes"
+	"github.com/doITmagic/rag-code-mcp/internal/storage"
+)
+
+// MigrateReport describes the points Migrate upgraded to the current
+// payload schema, or would with DryRun
+type MigrateReport struct {
+	Root          string              `json:"root"`
+	SchemaVersion int                 `json:"schema_version"`
+	DryRun        bool                `json:"dry_run,omitempty"`
+	Collections   []MigrateCollection `json:"collections"`
+}
+
+// MigrateCollection is a collection of a MigrateReport
+type MigrateCollection struct {
+	Name     string `json:"name"`
+	Language string `json:"language"`
+	Points   int    `json:"points"`
+	Outdated int    `json:"outdated"` // points of an older schema, upgraded unless DryRun
+	Failed   int    `json:"failed,omitempty"`
+}
+
+// Migrate rewrites the points of a workspace written with an older payload
+// schema (storage.SchemaVersion) in the current one. Reads upgrade old
+// payloads on the fly; Migrate stores the upgrade, with the same vectors, so
+// it is done once. language limits it to one language. Runs in progress make
+// it fail, and indexing waits until it is done.
+func (m *Manager) Migrate(ctx context.Context, info *Info, language string, dryRun bool) (*MigrateReport, error) {
+	if m.config == nil {
+		return nil, fmt.Errorf("no vector store configured")
+	}
+	languages := info.Languages
+	if language != "" {
+		lang := codetypes.NormalizeLanguage(language)
+		if !lang.Valid() {
+			return nil, fmt.Errorf("unknown language %q", language)
+		}
+		languages = []string{string(lang)}
+	}
+
+	unlock := m.workspaceLocks.Lock(info.ID)
+	defer unlock()
+	if m.indexingWorkspace(info.ID) {
+		return nil, fmt.Errorf("workspace '%s' is being indexed; try again when indexing is done", info.Root)
+	}
+
+	report := &MigrateReport{Root: info.Root, SchemaVersion: storage.SchemaVersion, DryRun: dryRun, Collections: []MigrateCollection{}}
+	for _, lang := range languages {
+		collection := info.CollectionNameForLanguage(lang)
+		client, err := storage.Open(m.config.Storage.VectorDB, collection)
+		if err != nil {
+			return report, fmt.Errorf("failed to create collection client: %w", err)
+		}
+		entry, err := migrateCollection(ctx, client, collection, dryRun)
+		client.Close()
+		if err != nil {
+			return report, fmt.Errorf("failed to migrate collection '%s': %w", collection, err)
+		}
+		if entry == nil {
+			continue
+		}
+		entry.Language = lang
+		report.Collections = append(report.Collections, *entry)
+		if entry.Outdated > 0 && !dryRun {
+			m.unloadCollection(info, collection)
+			log.Printf("🧬 Migrated %d points of '%s' to payload schema %d", entry.Outdated-entry.Failed, collection, storage.SchemaVersion)
+		}
+	}
+	return report, nil
+}
+
+// migrateCollection upgrades the outdated points of one collection. It
+// returns nil when the collection does not exist. Points whose migration
+// fails are counted and left as they are.
+func migrateCollection(ctx context.Context, client storage.VectorStore, collection string, dryRun bool) (*MigrateCollection, error) {
+	exists, err := client.CollectionExists(ctx, collection)
+	if err != nil || !exists {
+		return nil, err
+	}
+	entry := &MigrateCollection{Name: collection}
+	err = client.ScrollVectors(ctx, 256, func(p storage.VectorPoint) error {
+		entry.Points++
+		if storage.PayloadVersion(p.Payload) >= storage.SchemaVersion {
+			return nil
+		}
+		entry.Outdated++
+		if dryRun {
+			return nil
+		}
+		if _, err := storage.MigratePayload(p.Payload); err != nil {
+			log.Printf("⚠️  Point %s of '%s': %v", p.ID, collection, err)
+			entry.Failed++
+			return nil
+		}
+		vector := make([]float64, len(p.Vector))
+		for i, v := range p.Vector {
+			vector[i] = float64(v)
+		}
+		if err := client.Upsert(ctx, p.ID, vector, p.Payload); err != nil {
+			return fmt.Errorf("store failed for %s: %w", p.ID, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
@@ -0,0 +1,68 @@
+package workspace
+
+import (
+	"context"
+	"testing"
+
+	"github.com/doITmagic/rag-code-mcp/internal/config"
+	"github.com/doITmagic/rag-code-mcp/internal/storage"
+)
+
+func TestMigrate(t *testing.T) {
+	ctx := context.Background()
+	cfg := &config.Config{}
+	cfg.Storage.VectorDB = config.VectorDBConfig{Provider: "local", Path: t.TempDir()}
+	m := &Manager{config: cfg, indexing: make(map[string]bool)}
+
+	info := &Info{Root: t.TempDir(), ID: "ws1", Languages: []string{"go"}}
+	collection := info.CollectionNameForLanguage("go")
+	store, err := storage.Open(cfg.Storage.VectorDB, collection)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.CreateCollection(ctx, collection, 2); err != nil {
+		t.Fatal(err)
+	}
+	points := map[string]map[string]interface{}{
+		"1": {"content": `{"name":"Charge","language":"golang"}`, "language": "golang"},
+		"2": {"content": `{"name":"Refund","language":"go"}`, "language": "go", storage.SchemaVersionKey: storage.SchemaVersion},
+	}
+	for id, payload := range points {
+		if err := store.Upsert(ctx, id, []float64{1, 0}, payload); err != nil {
+			t.Fatal(err)
+		}
+	}
+	store.Close()
+
+	report, err := m.Migrate(ctx, info, "", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Collections) != 1 || report.Collections[0].Points != 2 || report.Collections[0].Outdated != 1 {
+		t.Fatalf("dry run = %+v", report)
+	}
+
+	if _, err := m.Migrate(ctx, info, "", false); err != nil {
+		t.Fatal(err)
+	}
+	store, err = storage.Open(cfg.Storage.VectorDB, collection)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	got, err := store.GetByID(ctx, "1")
+	if err != nil || got == nil {
+		t.Fatalf("point 1 = %v, %v", got, err)
+	}
+	if storage.PayloadVersion(got.Payload) != storage.SchemaVersion || got.Payload["language"] != "go" {
+		t.Errorf("payload = %v", got.Payload)
+	}
+
+	report, err = m.Migrate(ctx, info, "", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Collections[0].Outdated != 0 {
+		t.Errorf("after migrate = %+v", report.Collections[0])
+	}
+}
@@ -0,0 +1,279 @@
+package workspace
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/doITmagic/rag-code-mcp/internal/codetypes"
+	"github.com/doITmagic/rag-code-mcp/internal/memory"
+	"github.com/doITmagic/rag-code-mcp/internal/ragcode"
+)
+
+// maxOverlaysPerClient bounds the unsaved buffers kept per client; the least
+// recently updated is dropped first
+const maxOverlaysPerClient = 32
+
+// Overlay is the analysis of an unsaved editor buffer. It lives in memory
+// only and, for the client that sent it, shadows the indexed chunks of its
+// file until the buffer is saved, cleared or the server stops.
+type Overlay struct {
+	Path      string
+	Language  string
+	Client    string // MCP session that sent the buffer, "" over stdio
+	Chunks    []codetypes.CodeChunk
+	Docs      []memory.Document // chunks as embedded and stored by indexing
+	UpdatedAt time.Time
+	hash      string
+}
+
+type overlayKey struct {
+	workspace, client, path string
+}
+
+// SetOverlay analyzes content as the current text of path, a file of the
+// workspace, and keeps the chunks embedded in memory for client. Nothing is
+// written to disk or to the index.
+func (m *Manager) SetOverlay(ctx context.Context, info *Info, client, path string, content []byte) (*Overlay, error) {
+	path, err := workspacePath(info, path)
+	if err != nil {
+		return nil, err
+	}
+	lang := sourceLanguage(path)
+	analyzer := ragcode.NewAnalyzerManagerWithOptions(m.config.RagCode.IndexTests).CodeAnalyzerForProjectType(lang)
+	if analyzer == nil {
+		return nil, fmt.Errorf("no code analyzer for %s", filepath.Base(path))
+	}
+	if m.llm == nil {
+		return nil, fmt.Errorf("no embedding provider configured")
+	}
+	pipeline, err := m.chunkPipeline()
+	if err != nil {
+		return nil, err
+	}
+
+	// Analyzers read files, so the buffer is analyzed from a private copy
+	// under the same name and its chunks moved back to the real path
+	dir, err := os.MkdirTemp("", "ragcode-overlay-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+	tmp := filepath.Join(dir, filepath.Base(path))
+	if err := os.WriteFile(tmp, content, 0600); err != nil {
+		return nil, err
+	}
+
+	var chunks []codetypes.CodeChunk
+	mem := memory.NewInMemoryLongTermMemory()
+	indexer := ragcode.NewIndexer(&overlayAnalyzer{inner: analyzer, from: tmp, to: path}, m.llm, mem)
+	indexer.SetPostProcessors(pipeline)
+	indexer.SetBoilerplate(m.boilerplate(info))
+	indexer.OnAnalyzed(func(analyzed []codetypes.CodeChunk) {
+		chunks = analyzed
+	})
+	if _, err := indexer.IndexPaths(ctx, []string{tmp}, info.CollectionNameForLanguage(lang)); err != nil {
+		return nil, fmt.Errorf("failed to analyze buffer of %s: %w", path, err)
+	}
+	docs, err := mem.Search(ctx, nil, math.MaxInt)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(docs, func(i, j int) bool {
+		return docStartLine(docs[i]) < docStartLine(docs[j])
+	})
+
+	o := &Overlay{
+		Path:      path,
+		Language:  lang,
+		Client:    client,
+		Chunks:    chunks,
+		Docs:      docs,
+		UpdatedAt: time.Now(),
+		hash:      fileHash(content),
+	}
+	m.overlaysMu.Lock()
+	defer m.overlaysMu.Unlock()
+	if m.overlays == nil {
+		m.overlays = make(map[overlayKey]*Overlay)
+	}
+	key := overlayKey{info.ID, client, path}
+	if _, ok := m.overlays[key]; !ok {
+		m.evictOverlayLocked(info.ID, client)
+	}
+	m.overlays[key] = o
+	return o, nil
+}
+
+// ClearOverlay drops the buffer of path kept for client, or all of the
+// client's buffers in the workspace when path is empty. It returns the
+// number of buffers dropped.
+func (m *Manager) ClearOverlay(info *Info, client, path string) int {
+	if path != "" {
+		var err error
+		if path, err = workspacePath(info, path); err != nil {
+			return 0
+		}
+	}
+	m.overlaysMu.Lock()
+	defer m.overlaysMu.Unlock()
+	n := 0
+	for key := range m.overlays {
+		if key.workspace == info.ID && key.client == client && (path == "" || key.path == path) {
+			delete(m.overlays, key)
+			n++
+		}
+	}
+	return n
+}
+
+// Overlays returns the buffers client keeps in the workspace for language
+// ("" for all), sorted by path. A buffer whose file on disk now has the same
+// content was saved: it is dropped, the index takes over.
+func (m *Manager) Overlays(info *Info, client, language string) []*Overlay {
+	if m == nil || info == nil {
+		return nil
+	}
+	m.overlaysMu.Lock()
+	defer m.overlaysMu.Unlock()
+	var out []*Overlay
+	for key, o := range m.overlays {
+		if key.workspace != info.ID || key.client != client {
+			continue
+		}
+		if data, err := os.ReadFile(o.Path); err == nil && fileHash(data) == o.hash {
+			delete(m.overlays, key)
+			continue
+		}
+		if language == "" || strings.EqualFold(o.Language, language) {
+			out = append(out, o)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Path < out[j].Path })
+	return out
+}
+
+// ApplyOverlays shadows search results with the unsaved buffers of client:
+// indexed chunks of a buffered file are dropped and the buffer's chunks are
+// scored against the query embedding instead. Results stay ordered by score.
+func (m *Manager) ApplyOverlays(info *Info, client, language string, query []float64, docs []memory.Document) []memory.Document {
+	overlays := m.Overlays(info, client, language)
+	if len(overlays) == 0 {
+		return docs
+	}
+	shadowed := make(map[string]bool, len(overlays))
+	for _, o := range overlays {
+		shadowed[o.Path] = true
+	}
+	out := make([]memory.Document, 0, len(docs))
+	for _, doc := range docs {
+		if file, _ := doc.Metadata["file"].(string); !shadowed[file] {
+			out = append(out, doc)
+		}
+	}
+	for _, o := range overlays {
+		for _, doc := range o.Docs {
+			meta := make(map[string]interface{}, len(doc.Metadata)+2)
+			for k, v := range doc.Metadata {
+				meta[k] = v
+			}
+			meta["score"] = cosineSimilarity(query, doc.Embedding)
+			meta["overlay"] = true
+			doc.Metadata = meta
+			out = append(out, doc)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		return docScore(out[i]) > docScore(out[j])
+	})
+	return out
+}
+
+// evictOverlayLocked makes room for one more buffer of client
+func (m *Manager) evictOverlayLocked(workspaceID, client string) {
+	var oldest *overlayKey
+	n := 0
+	for key, o := range m.overlays {
+		if key.workspace != workspaceID || key.client != client {
+			continue
+		}
+		n++
+		if oldest == nil || o.UpdatedAt.Before(m.overlays[*oldest].UpdatedAt) {
+			k := key
+			oldest = &k
+		}
+	}
+	if n >= maxOverlaysPerClient {
+		delete(m.overlays, *oldest)
+	}
+}
+
+// overlayAnalyzer analyzes the private copy of a buffer and reports its
+// chunks and parse errors under the buffered file's path
+type overlayAnalyzer struct {
+	inner    codetypes.PathAnalyzer
+	from, to string
+}
+
+func (a *overlayAnalyzer) AnalyzePaths(paths []string) ([]codetypes.CodeChunk, error) {
+	chunks, err := a.inner.AnalyzePaths(paths)
+	for i := range chunks {
+		if chunks[i].FilePath == a.from {
+			chunks[i].FilePath = a.to
+		}
+	}
+	return chunks, err
+}
+
+// workspacePath returns path as an absolute, clean path inside the workspace
+func workspacePath(info *Info, path string) (string, error) {
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(info.Root, path)
+	}
+	path = filepath.Clean(path)
+	if rel, err := filepath.Rel(info.Root, path); err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return path, fmt.Errorf("%s is outside workspace %s", path, info.Root)
+	}
+	return path, nil
+}
+
+func docStartLine(doc memory.Document) int {
+	switch v := doc.Metadata["start_line"].(type) {
+	case int:
+		return v
+	case float64:
+		return int(v)
+	}
+	return 0
+}
+
+func docScore(doc memory.Document) float64 {
+	switch v := doc.Metadata["score"].(type) {
+	case float64:
+		return v
+	case float32:
+		return float64(v)
+	}
+	return 0
+}
+
+func cosineSimilarity(a, b []float64) float64 {
+	if len(a) == 0 || len(a) != len(b) {
+		return 0
+	}
+	var dot, na, nb float64
+	for i := range a {
+		dot += a[i] * b[i]
+		na += a[i] * a[i]
+		nb += b[i] * b[i]
+	}
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(na) * math.Sqrt(nb))
+}
@@ -0,0 +1,85 @@
+package workspace
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/doITmagic/rag-code-mcp/internal/config"
+	"github.com/doITmagic/rag-code-mcp/internal/memory"
+)
+
+func TestOverlay_ShadowsIndexedFile(t *testing.T) {
+	root := t.TempDir()
+	path := filepath.Join(root, "billing.go")
+	saved := "package billing\n\nfunc Charge() {}\n"
+	if err := os.WriteFile(path, []byte(saved), 0644); err != nil {
+		t.Fatal(err)
+	}
+	m := &Manager{llm: &MockLLMProvider{}, config: &config.Config{}}
+	info := &Info{Root: root, ID: "ws"}
+
+	buffer := "package billing\n\nfunc Charge() {}\n\nfunc Refund() {}\n"
+	o, err := m.SetOverlay(context.Background(), info, "alice", "billing.go", []byte(buffer))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if o.Path != path || o.Language != "go" || len(o.Docs) != 2 {
+		t.Fatalf("overlay = %s %s with %d docs, want 2 chunks of %s", o.Path, o.Language, len(o.Docs), path)
+	}
+	if file, _ := o.Docs[1].Metadata["file"].(string); file != path {
+		t.Errorf("overlay chunk file = %q, want the buffered file", file)
+	}
+
+	indexed := []memory.Document{
+		{ID: "old", Metadata: map[string]interface{}{"file": path, "name": "Charge", "score": 0.9}},
+		{ID: "other", Metadata: map[string]interface{}{"file": filepath.Join(root, "other.go"), "name": "Other", "score": 0.5}},
+	}
+	docs := m.ApplyOverlays(info, "alice", "go", make([]float64, 768), indexed)
+	if len(docs) != 3 || docs[0].ID != "other" {
+		t.Fatalf("shadowed results = %+v, want other.go then the 2 buffer chunks", docs)
+	}
+	for _, doc := range docs[1:] {
+		if overlay, _ := doc.Metadata["overlay"].(bool); !overlay {
+			t.Errorf("buffer chunk %s not flagged overlay", doc.ID)
+		}
+	}
+
+	// Other clients keep seeing the index
+	if docs := m.ApplyOverlays(info, "bob", "go", nil, indexed); len(docs) != 2 || docs[0].ID != "old" {
+		t.Errorf("other client results = %+v, want the indexed docs unchanged", docs)
+	}
+
+	// Saving the buffer hands the file back to the index
+	if err := os.WriteFile(path, []byte(buffer), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(m.Overlays(info, "alice", "")); n != 0 {
+		t.Errorf("%d overlay(s) left after the buffer was saved", n)
+	}
+}
+
+func TestOverlay_Clear(t *testing.T) {
+	root := t.TempDir()
+	m := &Manager{llm: &MockLLMProvider{}, config: &config.Config{}}
+	info := &Info{Root: root, ID: "ws"}
+	for _, name := range []string{"a.go", "b.go"} {
+		if _, err := m.SetOverlay(context.Background(), info, "", name, []byte("package x\n\nfunc F() {}\n")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := m.SetOverlay(context.Background(), info, "", "../outside.go", []byte("package x\n")); err == nil {
+		t.Error("buffers outside the workspace should be rejected")
+	}
+
+	if n := m.ClearOverlay(info, "", "a.go"); n != 1 {
+		t.Errorf("cleared %d, want 1", n)
+	}
+	if n := m.ClearOverlay(info, "", ""); n != 1 {
+		t.Errorf("cleared %d, want the remaining buffer", n)
+	}
+	if n := len(m.Overlays(info, "", "")); n != 0 {
+		t.Errorf("%d overlay(s) left", n)
+	}
+}
@@ -0,0 +1,84 @@
+package workspace
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+
+	"github.com/doITmagic/rag-code-mcp/internal/memory"
+)
+
+const (
+	// resultSetTTL is how long a paginated result set stays available
+	resultSetTTL = 10 * time.Minute
+	// maxResultSets bounds the result sets kept across workspaces; the
+	// oldest is dropped first
+	maxResultSets = 64
+)
+
+// ResultSet is the ranked results of a search, kept so the pages of a
+// paginated search all come from the index generation of the first page, even
+// when a re-index lands in between.
+type ResultSet struct {
+	ID          string
+	Tool        string
+	WorkspaceID string
+	Client      string // MCP session that ran the search, "" over stdio
+	Generation  uint64 // IndexGeneration of the workspace at the first page
+	Docs        []memory.Document
+	created     time.Time
+}
+
+// SaveResultSet keeps the ranked results of a search call of tool by client
+// for its next pages
+func (m *Manager) SaveResultSet(info *Info, client, tool string, docs []memory.Document) *ResultSet {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	set := &ResultSet{
+		ID:          hex.EncodeToString(b),
+		Tool:        tool,
+		WorkspaceID: info.ID,
+		Client:      client,
+		Generation:  m.IndexGeneration(info),
+		Docs:        docs,
+		created:     time.Now(),
+	}
+
+	m.resultSetsMu.Lock()
+	defer m.resultSetsMu.Unlock()
+	if m.resultSets == nil {
+		m.resultSets = make(map[string]*ResultSet)
+	}
+	m.expireResultSetsLocked(set.created)
+	if len(m.resultSets) >= maxResultSets {
+		var oldest *ResultSet
+		for _, s := range m.resultSets {
+			if oldest == nil || s.created.Before(oldest.created) {
+				oldest = s
+			}
+		}
+		delete(m.resultSets, oldest.ID)
+	}
+	m.resultSets[set.ID] = set
+	return set
+}
+
+// ResultSet returns a result set saved by SaveResultSet, unless it expired
+func (m *Manager) ResultSet(id string) (*ResultSet, bool) {
+	if m == nil {
+		return nil, false
+	}
+	m.resultSetsMu.Lock()
+	defer m.resultSetsMu.Unlock()
+	m.expireResultSetsLocked(time.Now())
+	set, ok := m.resultSets[id]
+	return set, ok
+}
+
+func (m *Manager) expireResultSetsLocked(now time.Time) {
+	for id, s := range m.resultSets {
+		if now.Sub(s.created) > resultSetTTL {
+			delete(m.resultSets, id)
+		}
+	}
+}
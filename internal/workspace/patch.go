@@ -0,0 +1,542 @@
+package workspace
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// defaultPatchMaxFiles limits the files one patch may change when
+// edits.max_files is not set.
+const defaultPatchMaxFiles = 20
+
+// FilePatch is the part of a unified diff that changes one file. OldPath is
+// empty when the file is created and NewPath when it is deleted.
+type FilePatch struct {
+	OldPath string
+	NewPath string
+	Hunks   []Hunk
+
+	noNewlineAtEnd bool // "\ No newline at end of file" after the last new line
+}
+
+// Hunk is one @@ section of a file patch. Lines keep their ' ', '-' or '+'
+// prefix.
+type Hunk struct {
+	OldStart, OldLines int
+	NewStart, NewLines int
+	Lines              []string
+}
+
+// PatchOptions configures ApplyPatch.
+type PatchOptions struct {
+	DryRun    bool     // validate and report, write nothing
+	MaxFiles  int      // reject patches changing more files (0 = 20)
+	Protected []string // globs relative to the root that may not be changed
+}
+
+// PatchedFile reports the change of one file.
+type PatchedFile struct {
+	Path    string `json:"path"`
+	Action  string `json:"action"` // modify, create, delete or rename
+	From    string `json:"from,omitempty"`
+	Added   int    `json:"added"`
+	Removed int    `json:"removed"`
+}
+
+// PatchResult reports an applied or dry-run patch.
+type PatchResult struct {
+	Files     []PatchedFile `json:"files"`
+	DryRun    bool          `json:"dry_run"`
+	ChangeID  string        `json:"change_id,omitempty"` // journal entry, for rollback_change
+	BackupDir string        `json:"backup_dir,omitempty"`
+}
+
+// ParsePatch parses a unified diff, as produced by git diff or diff -u.
+// Lines outside file sections (diff --git, index, commit messages) are
+// ignored.
+func ParsePatch(diff string) ([]FilePatch, error) {
+	lines := strings.Split(strings.ReplaceAll(diff, "\r\n", "\n"), "\n")
+	var patches []FilePatch
+	for i := 0; i < len(lines); {
+		if !strings.HasPrefix(lines[i], "--- ") || i+1 >= len(lines) || !strings.HasPrefix(lines[i+1], "+++ ") {
+			i++
+			continue
+		}
+		fp := FilePatch{
+			OldPath: patchHeaderPath(lines[i][4:], "a/"),
+			NewPath: patchHeaderPath(lines[i+1][4:], "b/"),
+		}
+		if fp.OldPath == "" && fp.NewPath == "" {
+			return nil, fmt.Errorf("line %d: file header names no file", i+1)
+		}
+		i += 2
+		for i < len(lines) && strings.HasPrefix(lines[i], "@@") {
+			h, err := parseHunkHeader(lines[i])
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", i+1, err)
+			}
+			i++
+			oldSeen, newSeen := 0, 0
+			for i < len(lines) && (oldSeen < h.OldLines || newSeen < h.NewLines) {
+				l := lines[i]
+				if l == "" {
+					l = " " // blank context line with its space stripped
+				}
+				switch l[0] {
+				case ' ':
+					oldSeen++
+					newSeen++
+				case '-':
+					oldSeen++
+				case '+':
+					newSeen++
+				case '\\':
+					i++
+					continue
+				default:
+					return nil, fmt.Errorf("line %d: unexpected %q in hunk", i+1, l)
+				}
+				h.Lines = append(h.Lines, l)
+				i++
+			}
+			if oldSeen != h.OldLines || newSeen != h.NewLines {
+				return nil, fmt.Errorf("hunk %q of %s is truncated", fmt.Sprintf("@@ -%d,%d +%d,%d @@", h.OldStart, h.OldLines, h.NewStart, h.NewLines), fp.displayPath())
+			}
+			if i < len(lines) && strings.HasPrefix(lines[i], `\`) {
+				if n := len(h.Lines); n > 0 && h.Lines[n-1][0] != '-' {
+					fp.noNewlineAtEnd = true
+				}
+				i++
+			}
+			fp.Hunks = append(fp.Hunks, h)
+		}
+		if len(fp.Hunks) == 0 && fp.OldPath == fp.NewPath {
+			return nil, fmt.Errorf("no hunks for %s", fp.displayPath())
+		}
+		patches = append(patches, fp)
+	}
+	if len(patches) == 0 {
+		return nil, fmt.Errorf("no file changes found: expected a unified diff with ---/+++ headers and @@ hunks")
+	}
+	return patches, nil
+}
+
+func (fp FilePatch) displayPath() string {
+	if fp.NewPath != "" {
+		return fp.NewPath
+	}
+	return fp.OldPath
+}
+
+// patchHeaderPath extracts the path of a ---/+++ line, dropping the git
+// a/ or b/ prefix and a trailing timestamp. /dev/null is "".
+func patchHeaderPath(s, prefix string) string {
+	if i := strings.IndexByte(s, '\t'); i >= 0 {
+		s = s[:i]
+	}
+	s = strings.TrimSpace(s)
+	if s == "/dev/null" {
+		return ""
+	}
+	return strings.TrimPrefix(s, prefix)
+}
+
+func parseHunkHeader(line string) (Hunk, error) {
+	var h Hunk
+	fields := strings.Fields(line)
+	if len(fields) < 4 || fields[0] != "@@" || fields[3] != "@@" || !strings.HasPrefix(fields[1], "-") || !strings.HasPrefix(fields[2], "+") {
+		return h, fmt.Errorf("invalid hunk header %q", line)
+	}
+	var err error
+	if h.OldStart, h.OldLines, err = parseHunkRange(fields[1][1:]); err != nil {
+		return h, fmt.Errorf("invalid hunk header %q", line)
+	}
+	if h.NewStart, h.NewLines, err = parseHunkRange(fields[2][1:]); err != nil {
+		return h, fmt.Errorf("invalid hunk header %q", line)
+	}
+	return h, nil
+}
+
+func parseHunkRange(s string) (int, int, error) {
+	start, count, found := strings.Cut(s, ",")
+	a, err := strconv.Atoi(start)
+	if err != nil {
+		return 0, 0, err
+	}
+	if !found {
+		return a, 1, nil
+	}
+	b, err := strconv.Atoi(count)
+	return a, b, err
+}
+
+// applyHunks applies the hunks to content. A hunk whose lines moved is
+// searched for around its recorded position, as patch(1) does; a hunk whose
+// context is not found fails the whole file.
+func applyHunks(content string, fp FilePatch) (string, int, int, error) {
+	crlf := strings.Contains(content, "\r\n")
+	if crlf {
+		content = strings.ReplaceAll(content, "\r\n", "\n")
+	}
+	lines := strings.Split(content, "\n")
+	hadNewline := strings.HasSuffix(content, "\n")
+	if hadNewline || content == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	added, removed := 0, 0
+	delta, minPos := 0, 0
+	reachesEnd := false
+	for n, h := range fp.Hunks {
+		var oldLines, newLines []string
+		for _, l := range h.Lines {
+			switch l[0] {
+			case ' ':
+				oldLines = append(oldLines, l[1:])
+				newLines = append(newLines, l[1:])
+			case '-':
+				oldLines = append(oldLines, l[1:])
+				removed++
+			case '+':
+				newLines = append(newLines, l[1:])
+				added++
+			}
+		}
+		want := h.OldStart - 1 + delta
+		if h.OldLines == 0 {
+			want = h.OldStart + delta // insertion after line OldStart
+		}
+		pos := findLines(lines, oldLines, max(want, minPos), minPos)
+		if pos < 0 {
+			return "", 0, 0, fmt.Errorf("hunk %d of %s does not apply: its context was not found near line %d", n+1, fp.displayPath(), h.OldStart)
+		}
+		lines = append(lines[:pos], append(append([]string{}, newLines...), lines[pos+len(oldLines):]...)...)
+		delta += len(newLines) - len(oldLines)
+		minPos = pos + len(newLines)
+		reachesEnd = pos+len(newLines) == len(lines)
+	}
+
+	out := strings.Join(lines, "\n")
+	newline := hadNewline || content == ""
+	if reachesEnd {
+		newline = !fp.noNewlineAtEnd
+	}
+	if newline && len(lines) > 0 {
+		out += "\n"
+	}
+	if crlf {
+		out = strings.ReplaceAll(out, "\n", "\r\n")
+	}
+	return out, added, removed, nil
+}
+
+// findLines returns the position of want in lines closest to near, not
+// before minPos, or -1.
+func findLines(lines, want []string, near, minPos int) int {
+	matches := func(pos int) bool {
+		if pos < minPos || pos+len(want) > len(lines) {
+			return false
+		}
+		for i, l := range want {
+			if lines[pos+i] != l {
+				return false
+			}
+		}
+		return true
+	}
+	if len(want) == 0 {
+		return min(max(near, minPos), len(lines))
+	}
+	for d := 0; d <= len(lines); d++ {
+		if matches(near + d) {
+			return near + d
+		}
+		if d > 0 && matches(near-d) {
+			return near - d
+		}
+	}
+	return -1
+}
+
+// patchWrite is a planned change of one file.
+type patchWrite struct {
+	path    string // absolute
+	content []byte
+	remove  bool
+	mode    os.FileMode
+}
+
+// ApplyPatch applies a unified diff to the files below root. Every path is
+// checked first: it must stay inside root, also through symlinks, and not
+// match a protected glob; .git and .ragcode are always protected. All hunks
+// are applied in memory before anything is written, so a patch that does not
+// apply changes nothing. The original files are copied to
+// .ragcode/backups/<change id>/, which journals the change for
+// RollbackChange, and each file is replaced atomically; if a write fails the
+// files already written are restored.
+func ApplyPatch(root, diff string, opts PatchOptions) (*PatchResult, error) {
+	patches, err := ParsePatch(diff)
+	if err != nil {
+		return nil, err
+	}
+	maxFiles := opts.MaxFiles
+	if maxFiles <= 0 {
+		maxFiles = defaultPatchMaxFiles
+	}
+	if len(patches) > maxFiles {
+		return nil, fmt.Errorf("patch changes %d files, more than the limit of %d (edits.max_files)", len(patches), maxFiles)
+	}
+	realRoot, err := filepath.EvalSymlinks(root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve workspace root: %w", err)
+	}
+
+	result := &PatchResult{DryRun: opts.DryRun}
+	var writes []patchWrite
+	planned := make(map[string]bool)
+	for _, fp := range patches {
+		var oldAbs, newAbs string
+		if fp.OldPath != "" {
+			if oldAbs, err = resolvePatchPath(root, realRoot, fp.OldPath, opts.Protected); err != nil {
+				return nil, err
+			}
+		}
+		if fp.NewPath != "" {
+			if newAbs, err = resolvePatchPath(root, realRoot, fp.NewPath, opts.Protected); err != nil {
+				return nil, err
+			}
+		}
+		for _, p := range []string{oldAbs, newAbs} {
+			if p != "" && planned[p] {
+				return nil, fmt.Errorf("%s is changed twice in the patch", p)
+			}
+		}
+
+		content, mode := "", os.FileMode(0644)
+		if oldAbs != "" {
+			data, err := os.ReadFile(oldAbs)
+			if err != nil {
+				return nil, fmt.Errorf("cannot patch %s: %w", fp.OldPath, err)
+			}
+			if fi, err := os.Stat(oldAbs); err == nil {
+				mode = fi.Mode().Perm()
+			}
+			content = string(data)
+		} else if _, err := os.Stat(newAbs); err == nil {
+			return nil, fmt.Errorf("cannot create %s: the file already exists", fp.NewPath)
+		}
+		if newAbs != "" && oldAbs != newAbs && oldAbs != "" {
+			if _, err := os.Stat(newAbs); err == nil {
+				return nil, fmt.Errorf("cannot rename %s to %s: the target exists", fp.OldPath, fp.NewPath)
+			}
+		}
+
+		updated, added, removed, err := applyHunks(content, fp)
+		if err != nil {
+			return nil, err
+		}
+		file := PatchedFile{Path: fp.NewPath, Action: "modify", Added: added, Removed: removed}
+		switch {
+		case oldAbs == "":
+			file.Action = "create"
+		case newAbs == "":
+			if updated != "" {
+				return nil, fmt.Errorf("patch deletes %s but leaves lines in it", fp.OldPath)
+			}
+			file.Path, file.Action = fp.OldPath, "delete"
+		case oldAbs != newAbs:
+			file.Action, file.From = "rename", fp.OldPath
+		}
+		result.Files = append(result.Files, file)
+
+		if newAbs != "" {
+			writes = append(writes, patchWrite{path: newAbs, content: []byte(updated), mode: mode})
+			planned[newAbs] = true
+		}
+		if oldAbs != "" && oldAbs != newAbs {
+			writes = append(writes, patchWrite{path: oldAbs, remove: true})
+			planned[oldAbs] = true
+		}
+	}
+	if opts.DryRun {
+		return result, nil
+	}
+	if err := writeChange(root, diff, result, writes); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// writeChange backs up and journals the files of a planned change, then
+// writes them. If a write fails the files already written are restored and
+// the change is dropped from the journal.
+func writeChange(root, diff string, result *PatchResult, writes []patchWrite) error {
+	change, backupDir, err := backupPatchedFiles(root, diff, result.Files, writes)
+	if err != nil {
+		return err
+	}
+	result.ChangeID, result.BackupDir = change.ID, backupDir
+	for i, w := range writes {
+		if err := writePatchedFile(w); err != nil {
+			restorePatchedFiles(root, backupDir, writes[:i])
+			os.RemoveAll(backupDir) // not journaled: nothing changed
+			return fmt.Errorf("failed to write %s, patch rolled back: %w", w.path, err)
+		}
+	}
+	return nil
+}
+
+// resolvePatchPath returns the absolute path of a patch path, rejecting paths
+// outside root and protected paths.
+func resolvePatchPath(root, realRoot, p string, protected []string) (string, error) {
+	rel := filepath.FromSlash(p)
+	if filepath.IsAbs(rel) {
+		r, err := filepath.Rel(root, rel)
+		if err != nil {
+			return "", fmt.Errorf("%s is outside the workspace", p)
+		}
+		rel = r
+	}
+	rel = filepath.Clean(rel)
+	if rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%s is outside the workspace", p)
+	}
+	slashRel := filepath.ToSlash(rel)
+	if first := strings.Split(slashRel, "/")[0]; first == ".git" || first == ".ragcode" {
+		return "", fmt.Errorf("%s is protected", p)
+	}
+	if len(protected) > 0 && grepIncluded(slashRel, protected) {
+		return "", fmt.Errorf("%s is protected (edits.protected_paths)", p)
+	}
+
+	// Resolve symlinks of the longest existing prefix, so a link inside the
+	// workspace cannot lead a patch outside of it
+	abs := filepath.Join(root, rel)
+	existing := abs
+	for {
+		if _, err := os.Lstat(existing); err == nil {
+			break
+		}
+		parent := filepath.Dir(existing)
+		if parent == existing {
+			break
+		}
+		existing = parent
+	}
+	resolved, err := filepath.EvalSymlinks(existing)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s: %w", p, err)
+	}
+	if r, err := filepath.Rel(realRoot, resolved); err != nil || r == ".." || strings.HasPrefix(r, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%s resolves outside the workspace", p)
+	}
+	return abs, nil
+}
+
+// writePatchedFile replaces or removes a file. Content is written to a
+// temporary file in the same directory and renamed over the target.
+func writePatchedFile(w patchWrite) error {
+	if w.remove {
+		return os.Remove(w.path)
+	}
+	if err := os.MkdirAll(filepath.Dir(w.path), 0755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(w.path), ".ragcode-patch-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(w.content); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), w.mode); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), w.path)
+}
+
+// ReindexFiles re-indexes the languages of the given files in the background.
+// Indexing is incremental, so only the changed files are analyzed again. It
+// returns the languages started.
+func (m *Manager) ReindexFiles(info *Info, paths []string) []string {
+	seen := make(map[string]bool)
+	var langs []string
+	for _, p := range paths {
+		lang := sourceLanguage(p)
+		if lang == "" || seen[lang] {
+			continue
+		}
+		seen[lang] = true
+		langs = append(langs, lang)
+	}
+	return m.reindexLanguages(info, langs)
+}
+
+// ReindexFile re-indexes the language of one workspace file now, analyzing
+// the file again even when it looks unchanged. Other changed files of the
+// language are indexed by the same run. It returns the language and the
+// absolute path of the file.
+func (m *Manager) ReindexFile(ctx context.Context, info *Info, path string) (string, string, error) {
+	path, err := workspacePath(info, path)
+	if err != nil {
+		return "", path, err
+	}
+	lang := sourceLanguage(path)
+	if lang == "" {
+		return "", path, fmt.Errorf("no code analyzer for %s", filepath.Base(path))
+	}
+	if m.IsIndexing(info.ID + "-" + lang) {
+		return lang, path, fmt.Errorf("%s indexing of workspace '%s' is already running; the file is picked up by the next run", lang, info.Root)
+	}
+
+	// Forget the file so the run analyzes it whatever its modification time
+	unlock := m.workspaceLocks.Lock(info.ID)
+	stateFile := filepath.Join(info.Root, ".ragcode", "state.json")
+	if state, err := LoadState(stateFile); err == nil {
+		if _, ok := state.GetFileState(path); ok {
+			state.RemoveFile(path)
+			if err := state.Save(stateFile); err != nil {
+				log.Printf("⚠️  Failed to save workspace state: %v", err)
+			}
+		}
+	}
+	unlock()
+
+	return lang, path, m.IndexLanguage(ctx, info, lang, info.CollectionNameForLanguage(lang))
+}
+
+// ReindexWorkspace re-indexes every language of a workspace in the
+// background, incrementally. It returns the languages started.
+func (m *Manager) ReindexWorkspace(info *Info) []string {
+	langs := info.Languages
+	if len(langs) == 0 && info.ProjectType != "" && info.ProjectType != "unknown" {
+		langs = []string{info.ProjectType}
+	}
+	return m.reindexLanguages(info, langs)
+}
+
+func (m *Manager) reindexLanguages(info *Info, langs []string) []string {
+	var started []string
+	for _, lang := range langs {
+		if m.IsIndexing(info.ID + "-" + lang) {
+			log.Printf("⏳ %s indexing already running for %s; changes are picked up by the next run", lang, info.Root)
+			continue
+		}
+		if err := m.StartIndexing(context.Background(), info, lang); err == nil {
+			started = append(started, lang)
+		}
+	}
+	return started
+}
@@ -0,0 +1,212 @@
+package workspace
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writePatchFixture(t *testing.T, files map[string]string) string {
+	t.Helper()
+	root := t.TempDir()
+	for name, content := range files {
+		p := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+func readFile(t *testing.T, root, name string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(root, name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+const patchFixture = "package a\n\nfunc A() int {\n\treturn 1\n}\n\nfunc B() int {\n\treturn 2\n}\n"
+
+func TestApplyPatchModifyCreateDelete(t *testing.T) {
+	root := writePatchFixture(t, map[string]string{"a.go": patchFixture, "old.txt": "bye\n"})
+	diff := `diff --git a/a.go b/a.go
+--- a/a.go
++++ b/a.go
+@@ -3,3 +3,3 @@
+ func A() int {
+-	return 1
++	return 10
+ }
+@@ -7,3 +7,3 @@
+ func B() int {
+-	return 2
++	return 20
+ }
+--- /dev/null
++++ b/pkg/new.go
+@@ -0,0 +1,2 @@
++package pkg
++// new
+--- a/old.txt
++++ /dev/null
+@@ -1 +0,0 @@
+-bye
+`
+	dry, err := ApplyPatch(root, diff, PatchOptions{DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dry.Files) != 3 || dry.BackupDir != "" || readFile(t, root, "a.go") != patchFixture {
+		t.Fatalf("dry run must not write: %+v", dry)
+	}
+
+	res, err := ApplyPatch(root, diff, PatchOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := readFile(t, root, "a.go"); got != strings.ReplaceAll(strings.ReplaceAll(patchFixture, "return 1\n", "return 10\n"), "return 2\n", "return 20\n") {
+		t.Errorf("a.go = %q", got)
+	}
+	if got := readFile(t, root, "pkg/new.go"); got != "package pkg\n// new\n" {
+		t.Errorf("new.go = %q", got)
+	}
+	if _, err := os.Stat(filepath.Join(root, "old.txt")); !os.IsNotExist(err) {
+		t.Error("old.txt should be deleted")
+	}
+	if res.Files[0].Action != "modify" || res.Files[0].Added != 2 || res.Files[1].Action != "create" || res.Files[2].Action != "delete" {
+		t.Errorf("files = %+v", res.Files)
+	}
+	if got := readFile(t, res.BackupDir, "a.go"); got != patchFixture {
+		t.Errorf("backup = %q", got)
+	}
+}
+
+func TestApplyPatchShiftedHunk(t *testing.T) {
+	root := writePatchFixture(t, map[string]string{"a.go": "// header\n// more\n" + patchFixture})
+	diff := "--- a/a.go\n+++ b/a.go\n@@ -7,3 +7,3 @@\n func B() int {\n-\treturn 2\n+\treturn 3\n }\n"
+	if _, err := ApplyPatch(root, diff, PatchOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(readFile(t, root, "a.go"), "return 3") {
+		t.Error("hunk not applied at its shifted position")
+	}
+}
+
+func TestApplyPatchIsAtomic(t *testing.T) {
+	root := writePatchFixture(t, map[string]string{"a.go": patchFixture, "b.go": "package a\n"})
+	diff := "--- a/a.go\n+++ b/a.go\n@@ -4 +4 @@\n-\treturn 1\n+\treturn 5\n--- a/b.go\n+++ b/b.go\n@@ -1 +1 @@\n-package nope\n+package b\n"
+	if _, err := ApplyPatch(root, diff, PatchOptions{}); err == nil || !strings.Contains(err.Error(), "does not apply") {
+		t.Fatalf("expected a context error, got %v", err)
+	}
+	if readFile(t, root, "a.go") != patchFixture {
+		t.Error("a.go must be unchanged when another file of the patch fails")
+	}
+}
+
+func TestApplyPatchGuardrails(t *testing.T) {
+	root := writePatchFixture(t, map[string]string{"a.go": patchFixture, "secrets/key.pem": "k\n"})
+	outside := t.TempDir()
+	if err := os.Symlink(outside, filepath.Join(root, "link")); err != nil {
+		t.Fatal(err)
+	}
+	create := func(path string) string {
+		return "--- /dev/null\n+++ b/" + path + "\n@@ -0,0 +1 @@\n+x\n"
+	}
+	tests := []struct {
+		diff, want string
+	}{
+		{create("../evil.go"), "outside the workspace"},
+		{create("link/evil.go"), "resolves outside"},
+		{create(".git/hooks/pre-commit"), "protected"},
+		{"--- a/secrets/key.pem\n+++ b/secrets/key.pem\n@@ -1 +1 @@\n-k\n+x\n", "edits.protected_paths"},
+		{create("a.go"), "already exists"},
+		{create("x.go") + create("y.go"), "limit of 1"},
+	}
+	for _, tt := range tests {
+		_, err := ApplyPatch(root, tt.diff, PatchOptions{MaxFiles: 1, Protected: []string{"secrets/**"}})
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%q: error %v, want %q", tt.diff, err, tt.want)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(outside, "evil.go")); !os.IsNotExist(err) {
+		t.Error("a patch wrote through the symlink")
+	}
+}
+
+func TestRollbackChange(t *testing.T) {
+	root := writePatchFixture(t, map[string]string{"a.go": patchFixture, "old.go": "package a\n"})
+	first, err := ApplyPatch(root, "--- a/a.go\n+++ b/a.go\n@@ -4 +4 @@\n-\treturn 1\n+\treturn 5\n--- a/old.go\n+++ b/new.go\n", PatchOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := ApplyPatch(root, "--- /dev/null\n+++ b/c.go\n@@ -0,0 +1 @@\n+package a\n", PatchOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.ChangeID == second.ChangeID {
+		t.Fatal("changes need distinct IDs")
+	}
+
+	// The newest change is rolled back first
+	change, restored, err := RollbackChange(root, "", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if change.ID != second.ChangeID || len(restored) != 1 {
+		t.Errorf("rolled back %s %v, want %s", change.ID, restored, second.ChangeID)
+	}
+	if _, err := os.Stat(filepath.Join(root, "c.go")); !os.IsNotExist(err) {
+		t.Error("created file must be removed")
+	}
+
+	// A file edited after the patch is a conflict
+	os.WriteFile(filepath.Join(root, "a.go"), []byte("edited\n"), 0644)
+	if _, _, err := RollbackChange(root, "", false); err == nil || !strings.Contains(err.Error(), "a.go") {
+		t.Fatalf("expected a conflict on a.go, got %v", err)
+	}
+	if _, _, err := RollbackChange(root, first.ChangeID, true); err != nil {
+		t.Fatal(err)
+	}
+	if readFile(t, root, "a.go") != patchFixture || readFile(t, root, "old.go") != "package a\n" {
+		t.Error("files not restored")
+	}
+	if _, err := os.Stat(filepath.Join(root, "new.go")); !os.IsNotExist(err) {
+		t.Error("renamed file must be removed")
+	}
+
+	if _, _, err := RollbackChange(root, first.ChangeID, false); err == nil || !strings.Contains(err.Error(), "already rolled back") {
+		t.Errorf("expected already rolled back, got %v", err)
+	}
+	changes, _ := ListChanges(root)
+	if len(changes) != 2 || changes[0].RolledBackAt == nil || changes[1].RolledBackAt == nil {
+		t.Errorf("journal = %+v", changes)
+	}
+}
+
+func TestReindexFileRejects(t *testing.T) {
+	root := writePatchFixture(t, map[string]string{"a.go": patchFixture, "notes.txt": "x\n"})
+	m := NewManager(nil, nil, nil)
+	info := &Info{ID: "ws", Root: root}
+	for path, want := range map[string]string{
+		"../b.go":                         "outside workspace",
+		filepath.Join(root, "..", "x.go"): "outside workspace",
+		"notes.txt":                       "no code analyzer",
+	} {
+		if _, _, err := m.ReindexFile(context.Background(), info, path); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("ReindexFile(%s): error %v, want %q", path, err, want)
+		}
+	}
+
+	m.indexing["ws-go"] = true
+	lang, abs, err := m.ReindexFile(context.Background(), info, "a.go")
+	if lang != "go" || abs != filepath.Join(root, "a.go") || err == nil || !strings.Contains(err.Error(), "already running") {
+		t.Errorf("ReindexFile during indexing = %s, %s, %v", lang, abs, err)
+	}
+}
@@ -0,0 +1,347 @@
+package workspace
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// QueryCache keeps the embeddings and results of frequent search queries for
+// one workspace. Results are tagged with the index generation they were
+// computed against and are ignored once the workspace has been re-indexed.
+type QueryCache struct {
+	mu         sync.Mutex
+	size       int
+	minHits    int
+	entries    map[string]*cachedQuery
+	embeddings map[string]cachedEmbedding
+	clock      uint64 // use counter for least-recently-used ordering
+}
+
+type cachedQuery struct {
+	tool       string
+	params     map[string]interface{}
+	hits       int
+	lastUsed   uint64
+	result     string
+	hasResult  bool
+	generation uint64
+}
+
+type cachedEmbedding struct {
+	vector   []float64
+	lastUsed uint64
+}
+
+// NewQueryCache creates a cache holding at most size queries. Results are
+// stored once a query has been seen minHits times.
+func NewQueryCache(size, minHits int) *QueryCache {
+	if size <= 0 {
+		size = 200
+	}
+	if minHits <= 0 {
+		minHits = 2
+	}
+	return &QueryCache{
+		size:       size,
+		minHits:    minHits,
+		entries:    make(map[string]*cachedQuery),
+		embeddings: make(map[string]cachedEmbedding),
+	}
+}
+
+// QueryKey identifies a tool call by its tool name and parameters.
+func QueryKey(tool string, params map[string]interface{}) string {
+	// encoding/json sorts map keys, so equal parameters give equal keys
+	data, _ := json.Marshal(params)
+	return tool + ":" + string(data)
+}
+
+// Lookup records a use of the query and returns its cached result if one was
+// stored for the given index generation.
+func (c *QueryCache) Lookup(tool string, params map[string]interface{}, generation uint64) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := QueryKey(tool, params)
+	entry, ok := c.entries[key]
+	if !ok {
+		entry = &cachedQuery{tool: tool, params: params}
+		c.entries[key] = entry
+	}
+	entry.hits++
+	entry.lastUsed = c.tickLocked()
+	c.evictLocked()
+	if entry.hasResult && entry.generation == generation {
+		return entry.result, true
+	}
+	return "", false
+}
+
+// Store caches the result of a query for the given index generation once the
+// query is frequent enough. It reports whether the result was stored.
+func (c *QueryCache) Store(tool string, params map[string]interface{}, result string, generation uint64) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[QueryKey(tool, params)]
+	if !ok || entry.hits < c.minHits {
+		return false
+	}
+	entry.result = result
+	entry.hasResult = true
+	entry.generation = generation
+	return true
+}
+
+// Embedding returns the cached embedding of a query text.
+func (c *QueryCache) Embedding(query string) ([]float64, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.embeddings[query]
+	if !ok {
+		return nil, false
+	}
+	e.lastUsed = c.tickLocked()
+	c.embeddings[query] = e
+	return e.vector, true
+}
+
+// SetEmbedding caches the embedding of a query text. Embeddings do not depend
+// on the index and stay valid across re-indexing.
+func (c *QueryCache) SetEmbedding(query string, vector []float64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.embeddings[query] = cachedEmbedding{vector: vector, lastUsed: c.tickLocked()}
+	if len(c.embeddings) > c.size {
+		oldest, oldestUse := "", uint64(0)
+		for q, e := range c.embeddings {
+			if oldest == "" || e.lastUsed < oldestUse {
+				oldest, oldestUse = q, e.lastUsed
+			}
+		}
+		delete(c.embeddings, oldest)
+	}
+}
+
+// CachedQuery identifies a cached tool call.
+type CachedQuery struct {
+	Tool   string
+	Params map[string]interface{}
+}
+
+// Recent returns up to n queries that have a cached result, most recently
+// used first.
+func (c *QueryCache) Recent(n int) []CachedQuery {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entries := make([]*cachedQuery, 0, len(c.entries))
+	for _, e := range c.entries {
+		if e.hasResult {
+			entries = append(entries, e)
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].lastUsed > entries[j].lastUsed
+	})
+	if len(entries) > n {
+		entries = entries[:n]
+	}
+	out := make([]CachedQuery, len(entries))
+	for i, e := range entries {
+		out[i] = CachedQuery{Tool: e.tool, Params: e.params}
+	}
+	return out
+}
+
+func (c *QueryCache) tickLocked() uint64 {
+	c.clock++
+	return c.clock
+}
+
+// evictLocked drops the least recently used queries beyond the cache size.
+func (c *QueryCache) evictLocked() {
+	if len(c.entries) <= c.size {
+		return
+	}
+	keys := make([]string, 0, len(c.entries))
+	for k := range c.entries {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return c.entries[keys[i]].lastUsed < c.entries[keys[j]].lastUsed
+	})
+	for _, k := range keys[:len(keys)-c.size] {
+		delete(c.entries, k)
+	}
+}
+
+// QueryCache returns the query cache of a workspace, or nil when query
+// caching is disabled (queries.cache).
+func (m *Manager) QueryCache(info *Info) *QueryCache {
+	if m.config == nil || !m.config.Queries.Cache {
+		return nil
+	}
+	m.queryMu.Lock()
+	defer m.queryMu.Unlock()
+	if m.queryCaches == nil {
+		m.queryCaches = make(map[string]*QueryCache)
+	}
+	cache, ok := m.queryCaches[info.ID]
+	if !ok {
+		cache = NewQueryCache(m.config.Queries.CacheSize, m.config.Queries.CacheMinHits)
+		m.queryCaches[info.ID] = cache
+	}
+	return cache
+}
+
+// IndexGeneration returns a counter that changes whenever the indexed content
+// of the workspace changes (re-indexing, loading coverage).
+func (m *Manager) IndexGeneration(info *Info) uint64 {
+	m.queryMu.Lock()
+	defer m.queryMu.Unlock()
+	return m.generations[info.ID]
+}
+
+func (m *Manager) bumpIndexGeneration(info *Info) {
+	m.queryMu.Lock()
+	defer m.queryMu.Unlock()
+	if m.generations == nil {
+		m.generations = make(map[string]uint64)
+	}
+	m.generations[info.ID]++
+}
+
+// QueryPrimer re-runs a search tool call without going through the query
+// cache or query log. It returns an error for results that must not be cached.
+type QueryPrimer func(ctx context.Context, params map[string]interface{}) (string, error)
+
+// primeTimeout bounds a whole priming run
+const primeTimeout = 2 * time.Minute
+
+// RegisterQueryPrimer registers how to re-run calls of a search tool when
+// priming the query cache after a re-index.
+func (m *Manager) RegisterQueryPrimer(tool string, fn QueryPrimer) {
+	if m == nil {
+		return
+	}
+	m.queryMu.Lock()
+	defer m.queryMu.Unlock()
+	if m.queryPrimers == nil {
+		m.queryPrimers = make(map[string]QueryPrimer)
+	}
+	m.queryPrimers[tool] = fn
+}
+
+// primeQueryCache re-runs the most recent cached queries of a workspace in
+// the background so they are answered from the cache again right after a
+// re-index (queries.prime_queries). Only one priming run per workspace is
+// active at a time.
+func (m *Manager) primeQueryCache(info *Info) {
+	cache := m.QueryCache(info)
+	if cache == nil || m.config.Queries.PrimeQueries <= 0 {
+		return
+	}
+	queries := cache.Recent(m.config.Queries.PrimeQueries)
+	if len(queries) == 0 {
+		return
+	}
+
+	m.queryMu.Lock()
+	if m.priming == nil {
+		m.priming = make(map[string]bool)
+	}
+	if m.priming[info.ID] {
+		m.queryMu.Unlock()
+		return
+	}
+	m.priming[info.ID] = true
+	primers := make(map[string]QueryPrimer, len(m.queryPrimers))
+	for tool, fn := range m.queryPrimers {
+		primers[tool] = fn
+	}
+	m.queryMu.Unlock()
+
+	m.background(func(ctx context.Context) {
+		defer func() {
+			m.queryMu.Lock()
+			delete(m.priming, info.ID)
+			m.queryMu.Unlock()
+		}()
+		log.Printf("🔥 Priming %d cached queries for workspace '%s'", len(queries), info.Root)
+		m.runQueryPrimers(ctx, info, cache, queries, primers)
+	})
+}
+
+func (m *Manager) runQueryPrimers(ctx context.Context, info *Info, cache *QueryCache, queries []CachedQuery, primers map[string]QueryPrimer) int {
+	ctx, cancel := context.WithTimeout(ctx, primeTimeout)
+	defer cancel()
+
+	primed := 0
+	for _, q := range queries {
+		run, ok := primers[q.Tool]
+		if !ok || ctx.Err() != nil {
+			continue
+		}
+		// Read the generation first; a re-index while running leaves the result stale
+		generation := m.IndexGeneration(info)
+		result, err := run(ctx, q.Params)
+		if err != nil {
+			continue
+		}
+		if cache.Store(q.Tool, q.Params, result, generation) {
+			primed++
+		}
+	}
+	return primed
+}
+
+// QueryLogEntry is a single line of .ragcode/query_log.jsonl.
+type QueryLogEntry struct {
+	Time       time.Time              `json:"time"`
+	Tool       string                 `json:"tool"`
+	Query      string                 `json:"query"`
+	Params     map[string]interface{} `json:"params,omitempty"`
+	DurationMs int64                  `json:"duration_ms"`
+	Cached     bool                   `json:"cached"`
+	TimedOut   bool                   `json:"timed_out,omitempty"`
+	Error      string                 `json:"error,omitempty"`
+}
+
+func queryLogPath(root string) string {
+	return filepath.Join(root, ".ragcode", "query_log.jsonl")
+}
+
+// LogQuery appends a query to the workspace query log when query logging is
+// enabled (queries.log).
+func (m *Manager) LogQuery(info *Info, entry QueryLogEntry) error {
+	if m.config == nil || !m.config.Queries.Log {
+		return nil
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	m.queryMu.Lock()
+	defer m.queryMu.Unlock()
+	path := queryLogPath(info.Root)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.Write(append(data, '\n'))
+	return err
+}
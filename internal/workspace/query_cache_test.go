@@ -0,0 +1,178 @@
+package workspace
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/doITmagic/rag-code-mcp/internal/config"
+	"github.com/doITmagic/rag-code-mcp/internal/storage"
+)
+
+func TestQueryCache(t *testing.T) {
+	c := NewQueryCache(2, 2)
+	params := map[string]interface{}{"query": "charge card", "limit": 5}
+
+	if _, ok := c.Lookup("search_code", params, 0); ok {
+		t.Fatal("unexpected hit on first lookup")
+	}
+	if c.Store("search_code", params, "first", 0) {
+		t.Error("result stored before the query was seen cache_min_hits times")
+	}
+
+	c.Lookup("search_code", params, 0)
+	if !c.Store("search_code", params, "result", 0) {
+		t.Fatal("result of a frequent query was not stored")
+	}
+	if got, ok := c.Lookup("search_code", map[string]interface{}{"limit": 5, "query": "charge card"}, 0); !ok || got != "result" {
+		t.Errorf("Lookup = %q, %v; want cached result", got, ok)
+	}
+	if _, ok := c.Lookup("hybrid_search", params, 0); ok {
+		t.Error("results must be cached per tool")
+	}
+	if _, ok := c.Lookup("search_code", params, 1); ok {
+		t.Error("result from an older index generation returned")
+	}
+
+	// Least recently used queries are evicted beyond the cache size
+	c.Lookup("search_code", map[string]interface{}{"query": "refund"}, 0)
+	if _, ok := c.entries[QueryKey("hybrid_search", params)]; ok {
+		t.Error("least recently used query was not evicted")
+	}
+
+	c.SetEmbedding("charge card", []float64{1, 2})
+	if v, ok := c.Embedding("charge card"); !ok || len(v) != 2 {
+		t.Errorf("Embedding = %v, %v", v, ok)
+	}
+}
+
+func TestQueryCacheAndLogConfig(t *testing.T) {
+	root := t.TempDir()
+	info := &Info{Root: root, ID: "ws"}
+
+	disabled := &Manager{config: config.DefaultConfig()}
+	if disabled.QueryCache(info) != nil {
+		t.Error("query cache should be disabled by default")
+	}
+	if err := disabled.LogQuery(info, QueryLogEntry{Tool: "search_code", Query: "secret"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(queryLogPath(root)); !os.IsNotExist(err) {
+		t.Error("query log written although disabled")
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.Queries.Cache, cfg.Queries.Log = true, true
+	m := &Manager{config: cfg}
+	if c := m.QueryCache(info); c == nil || c != m.QueryCache(info) {
+		t.Error("expected one query cache per workspace")
+	}
+
+	before := m.IndexGeneration(info)
+	m.bumpIndexGeneration(info)
+	if m.IndexGeneration(info) == before {
+		t.Error("index generation did not change")
+	}
+
+	for _, q := range []string{"charge", "refund"} {
+		if err := m.LogQuery(info, QueryLogEntry{Tool: "search_code", Query: q}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	data, err := os.ReadFile(queryLogPath(root))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Split(strings.TrimSpace(string(data)), "\n"); len(lines) != 2 || !strings.Contains(lines[1], `"query":"refund"`) {
+		t.Errorf("query log = %q", data)
+	}
+}
+
+func TestRunQueryPrimers(t *testing.T) {
+	info := &Info{Root: t.TempDir(), ID: "ws"}
+	cfg := config.DefaultConfig()
+	cfg.Queries.Cache = true
+	m := &Manager{config: cfg}
+	cache := m.QueryCache(info)
+
+	for _, q := range []string{"charge", "refund", "invoice"} {
+		params := map[string]interface{}{"query": q}
+		cache.Lookup("search_code", params, 0)
+		cache.Lookup("search_code", params, 0)
+		cache.Store("search_code", params, "old "+q, 0)
+	}
+	cache.Lookup("search_code", map[string]interface{}{"query": "uncached"}, 0)
+
+	recent := cache.Recent(2)
+	if len(recent) != 2 || recent[0].Params["query"] != "invoice" {
+		t.Fatalf("Recent(2) = %+v, want invoice first", recent)
+	}
+
+	m.bumpIndexGeneration(info)
+	m.RegisterQueryPrimer("search_code", func(ctx context.Context, params map[string]interface{}) (string, error) {
+		if params["query"] == "refund" {
+			return "", fmt.Errorf("not cacheable")
+		}
+		return "new " + params["query"].(string), nil
+	})
+	if primed := m.runQueryPrimers(context.Background(), info, cache, cache.Recent(10), m.queryPrimers); primed != 2 {
+		t.Errorf("primed = %d, want 2", primed)
+	}
+
+	generation := m.IndexGeneration(info)
+	if got, ok := cache.Lookup("search_code", map[string]interface{}{"query": "invoice"}, generation); !ok || got != "new invoice" {
+		t.Errorf("Lookup(invoice) = %q, %v; want primed result", got, ok)
+	}
+	if _, ok := cache.Lookup("search_code", map[string]interface{}{"query": "refund"}, generation); ok {
+		t.Error("failed primer must not produce a cached result")
+	}
+}
+
+func TestPrimeQueryCacheAfterIndexing(t *testing.T) {
+	ctx := context.Background()
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "main.go"), []byte("package main\n\nfunc Run() {}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg := config.DefaultConfig()
+	cfg.Storage.VectorDB = config.VectorDBConfig{Provider: "local", Path: t.TempDir()}
+	cfg.Queries.Cache = true
+	cfg.Queries.PrimeQueries = 10
+	cfg.Workspace.TombstoneGrace = 0
+	m := NewManager(nil, &MockLLMProvider{}, cfg)
+	info := &Info{Root: root, ID: "ws", ProjectType: "go", Languages: []string{"go"}}
+	collection := info.CollectionNameForLanguage("go")
+	store, err := storage.Open(cfg.Storage.VectorDB, collection)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.CreateCollection(ctx, collection, 768); err != nil {
+		t.Fatal(err)
+	}
+	store.Close()
+
+	params := map[string]interface{}{"query": "run"}
+	cache := m.QueryCache(info)
+	cache.Lookup("search_code", params, 0)
+	cache.Lookup("search_code", params, 0)
+	cache.Store("search_code", params, "old", 0)
+
+	// Like the search tools, the primer refuses to answer during indexing
+	m.RegisterQueryPrimer("search_code", func(ctx context.Context, params map[string]interface{}) (string, error) {
+		if m.IsIndexing(info.ID + "-go") {
+			return "", fmt.Errorf("being indexed")
+		}
+		return "fresh", nil
+	})
+	if err := m.IndexLanguage(ctx, info, "go", collection); err != nil {
+		t.Fatal(err)
+	}
+	m.jobs.Wait()
+
+	if got, ok := cache.Lookup("search_code", params, m.IndexGeneration(info)); !ok || got != "fresh" {
+		t.Errorf("Lookup = %q, %v; want the result primed after indexing", got, ok)
+	}
+}
@@ -0,0 +1,218 @@
+package workspace
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/doITmagic/rag-code-mcp/internal/codetypes"
+	"github.com/doITmagic/rag-code-mcp/internal/llm"
+	"github.com/doITmagic/rag-code-mcp/internal/ragcode"
+	"github.com/doITmagic/rag-code-mcp/internal/storage"
+)
+
+// ReembedReport describes how Reembed moved the collections of a workspace
+// to the configured embedding model, or would with DryRun
+type ReembedReport struct {
+	Root        string              `json:"root"`
+	Model       string              `json:"model"`
+	Dimension   int                 `json:"dimension"` // of the configured model
+	DryRun      bool                `json:"dry_run,omitempty"`
+	Collections []ReembedCollection `json:"collections"`
+}
+
+// ReembedCollection is a collection of a ReembedReport
+type ReembedCollection struct {
+	Name      string `json:"name"`
+	Language  string `json:"language"`
+	Dimension int    `json:"dimension"` // stored before re-embedding
+	Points    int    `json:"points"`
+	// Reembedded is false when the stored dimension already matched
+	Reembedded bool `json:"reembedded"`
+}
+
+// Reembed re-embeds the collections of a workspace whose vector dimension
+// differs from the configured embedding model's, e.g. after ollama_embed
+// changed from nomic-embed-text to mxbai-embed-large: with force, every
+// collection. The stored points are embedded again from their content into
+// a new collection, which then replaces the old one under the same name, so
+// nothing is analyzed again and searches keep working until the swap.
+// language limits it to one language. Runs in progress make it fail, and
+// indexing waits until it is done.
+func (m *Manager) Reembed(ctx context.Context, info *Info, language string, force, dryRun bool) (*ReembedReport, error) {
+	if m.config == nil {
+		return nil, fmt.Errorf("no vector store configured")
+	}
+	languages := info.Languages
+	if language != "" {
+		lang := codetypes.NormalizeLanguage(language)
+		if !lang.Valid() {
+			return nil, fmt.Errorf("unknown language %q", language)
+		}
+		languages = []string{string(lang)}
+	}
+
+	unlock := m.workspaceLocks.Lock(info.ID)
+	defer unlock()
+	if m.indexingWorkspace(info.ID) {
+		return nil, fmt.Errorf("workspace '%s' is being indexed; try again when indexing is done", info.Root)
+	}
+
+	dim, err := llm.EmbeddingDimension(ctx, m.llm)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get embedding dimension: %w", err)
+	}
+	report := &ReembedReport{Root: info.Root, Model: m.EmbedModel(), Dimension: dim, DryRun: dryRun, Collections: []ReembedCollection{}}
+	for _, lang := range languages {
+		collection := info.CollectionNameForLanguage(lang)
+		client, err := storage.Open(m.config.Storage.VectorDB, collection)
+		if err != nil {
+			return report, fmt.Errorf("failed to create collection client: %w", err)
+		}
+		entry, err := m.reembedCollection(ctx, info, client, collection, dim, force, dryRun)
+		client.Close()
+		if err != nil {
+			return report, fmt.Errorf("failed to re-embed collection '%s': %w", collection, err)
+		}
+		if entry != nil {
+			entry.Language = lang
+			report.Collections = append(report.Collections, *entry)
+		}
+	}
+	return report, nil
+}
+
+// reembedCollection re-embeds one collection into a collection of dimension
+// dim and swaps them. It returns nil when the collection does not exist.
+func (m *Manager) reembedCollection(ctx context.Context, info *Info, client storage.VectorStore, collection string, dim int, force, dryRun bool) (*ReembedCollection, error) {
+	exists, err := client.CollectionExists(ctx, collection)
+	if err != nil || !exists {
+		return nil, err
+	}
+	stored, err := client.CollectionDimension(ctx, collection)
+	if err != nil {
+		return nil, err
+	}
+	count, err := client.GetCollectionPointCount(ctx, collection)
+	if err != nil {
+		return nil, err
+	}
+	entry := &ReembedCollection{Name: collection, Dimension: stored, Points: int(count), Reembedded: force || stored != dim}
+	if !entry.Reembedded || dryRun {
+		return entry, nil
+	}
+
+	// A new name per run: Qdrant serves the old one through an alias
+	target := fmt.Sprintf("%s-v%d", collection, time.Now().Unix())
+	log.Printf("🔁 Re-embedding collection '%s' (%d points, dimension %d → %d) into '%s'", collection, count, stored, dim, target)
+	dest, err := storage.Open(m.config.Storage.VectorDB, target)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create collection client: %w", err)
+	}
+	defer dest.Close()
+	if err := dest.CreateCollection(ctx, target, dim); err != nil {
+		return nil, fmt.Errorf("failed to create collection: %w", err)
+	}
+
+	boiler := m.boilerplate(info)
+	batchSize := m.config.RagCode.EmbedBatchSize
+	if batchSize <= 0 {
+		batchSize = ragcode.DefaultEmbedBatchSize
+	}
+	var batch []storage.VectorPoint
+	points := 0
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		texts := make([]string, len(batch))
+		for i, p := range batch {
+			// The new collection is written in the current payload schema
+			if _, err := storage.MigratePayload(p.Payload); err != nil {
+				log.Printf("⚠️  Point %s of '%s': %v", p.ID, collection, err)
+			}
+			texts[i] = reembedText(p.Payload, boiler)
+		}
+		vectors, err := llm.EmbedBatch(ctx, m.llm, texts)
+		if err != nil {
+			return fmt.Errorf("embed failed (batch of %d): %w", len(batch), err)
+		}
+		for i, p := range batch {
+			if err := dest.Upsert(ctx, p.ID, vectors[i], p.Payload); err != nil {
+				return fmt.Errorf("store failed for %s: %w", p.ID, err)
+			}
+		}
+		points += len(batch)
+		batch = batch[:0]
+		return nil
+	}
+	err = client.ScrollVectors(ctx, batchSize, func(p storage.VectorPoint) error {
+		batch = append(batch, p)
+		if len(batch) < batchSize {
+			return nil
+		}
+		return flush()
+	})
+	if err == nil {
+		err = flush()
+	}
+	if err != nil {
+		if delErr := dest.DeleteCollection(ctx, target); delErr != nil {
+			log.Printf("⚠️  Failed to delete the partial collection '%s': %v", target, delErr)
+		}
+		return nil, err
+	}
+
+	if err := client.SwapCollection(ctx, collection, target); err != nil {
+		return nil, err
+	}
+	m.unloadCollection(info, collection)
+	entry.Points = points
+	log.Printf("✅ Re-embedded %d points of '%s' with %s", points, collection, m.EmbedModel())
+	return entry, nil
+}
+
+// warnDimensionMismatch logs when a collection holds vectors of another
+// dimension than the configured embedding model's: searches fail until it
+// is re-embedded
+func (m *Manager) warnDimensionMismatch(ctx context.Context, client storage.VectorStore, collection string) {
+	if m.llm == nil {
+		return
+	}
+	stored, err := client.CollectionDimension(ctx, collection)
+	if err != nil {
+		return
+	}
+	dim, err := llm.EmbeddingDimension(ctx, m.llm)
+	if err != nil || dim == stored {
+		return
+	}
+	log.Printf("⚠️  Collection '%s' holds %d-dimensional vectors but %s embeds %d dimensions: run `rag-code-mcp reembed` or the reembed_workspace tool",
+		collection, stored, m.EmbedModel(), dim)
+}
+
+// reembedText is the text embedded again for a stored point, the one
+// indexing embeds: for code chunks, rebuilt from the chunk JSON; for
+// documentation, the content with its headings; for glossary chunks, their
+// content
+func reembedText(payload map[string]interface{}, boiler *ragcode.Boilerplate) string {
+	content, _ := payload["content"].(string)
+	switch kind, _ := payload["chunk_type"].(string); kind {
+	case "markdown":
+		headings, _ := payload["headings"].(string)
+		title, _ := payload["title"].(string)
+		return ragcode.MarkdownEmbeddingText(content, ragcode.TitledBreadcrumb(title, headings))
+	case "glossary":
+		return content
+	}
+	var ch codetypes.CodeChunk
+	if err := json.Unmarshal([]byte(content), &ch); err != nil {
+		return content
+	}
+	if text := ragcode.ChunkEmbeddingText(ch, boiler); text != "" {
+		return text
+	}
+	return content
+}
@@ -0,0 +1,107 @@
+package workspace
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"github.com/doITmagic/rag-code-mcp/internal/codetypes"
+	"github.com/doITmagic/rag-code-mcp/internal/config"
+	"github.com/doITmagic/rag-code-mcp/internal/storage"
+)
+
+// textLengthEmbedder embeds texts into 3 dimensions and records them
+type textLengthEmbedder struct {
+	MockLLMProvider
+	texts []string
+}
+
+func (e *textLengthEmbedder) Embed(ctx context.Context, text string) ([]float64, error) {
+	if text != "test" {
+		e.texts = append(e.texts, text)
+	}
+	return []float64{float64(len(text)), 1, 0}, nil
+}
+
+func TestReembed(t *testing.T) {
+	ctx := context.Background()
+	cfg := &config.Config{}
+	cfg.Storage.VectorDB = config.VectorDBConfig{Provider: "local", Path: t.TempDir()}
+	cfg.RagCode.KeepBoilerplate = true
+	embedder := &textLengthEmbedder{}
+	m := &Manager{config: cfg, llm: embedder, indexing: make(map[string]bool)}
+
+	root := t.TempDir()
+	info := &Info{Root: root, ID: "ws1", Languages: []string{"go", "python"}}
+	collection := info.CollectionNameForLanguage("go")
+	store, err := storage.Open(cfg.Storage.VectorDB, collection)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.CreateCollection(ctx, collection, 2); err != nil {
+		t.Fatal(err)
+	}
+	chunk, _ := json.Marshal(codetypes.CodeChunk{Name: "Charge", Language: codetypes.LanguageGo, Signature: "func Charge()", Code: "func Charge() {}"})
+	points := map[string]map[string]interface{}{
+		"1": {"content": string(chunk), "file": filepath.Join(root, "pay.go"), storage.GenerationKey: uint64(4)},
+		"2": {"content": "# Payments", "chunk_type": "markdown"},
+	}
+	for id, payload := range points {
+		if err := store.Upsert(ctx, id, []float64{1, 0}, payload); err != nil {
+			t.Fatal(err)
+		}
+	}
+	store.Close()
+
+	report, err := m.Reembed(ctx, info, "", false, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Dimension != 3 || len(report.Collections) != 1 || !report.Collections[0].Reembedded || report.Collections[0].Dimension != 2 {
+		t.Fatalf("dry run = %+v", report)
+	}
+	if len(embedder.texts) != 0 {
+		t.Fatalf("dry run embedded %v", embedder.texts)
+	}
+
+	report, err = m.Reembed(ctx, info, "", false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c := report.Collections[0]; c.Points != 2 || c.Language != "go" {
+		t.Errorf("collection = %+v", c)
+	}
+	want := map[string]bool{"func Charge()\n\nfunc Charge() {}": true, "# Payments": true}
+	for _, text := range embedder.texts {
+		if !want[text] {
+			t.Errorf("embedded %q", text)
+		}
+	}
+
+	store, err = storage.Open(cfg.Storage.VectorDB, collection)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	if dim, _ := store.CollectionDimension(ctx, collection); dim != 3 {
+		t.Errorf("dimension after reembed = %d, want 3", dim)
+	}
+	got, err := store.GetByID(ctx, "1")
+	if err != nil || got == nil {
+		t.Fatalf("point 1 = %v, %v", got, err)
+	}
+	if got.Payload[storage.GenerationKey] != "4" || got.Payload["content"] != string(chunk) {
+		t.Errorf("payload = %v", got.Payload)
+	}
+
+	// Matching collections are left alone
+	embedder.texts = nil
+	report, err = m.Reembed(ctx, info, "go", false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Collections[0].Reembedded || len(embedder.texts) != 0 {
+		t.Errorf("up to date collection re-embedded: %+v", report.Collections[0])
+	}
+}
@@ -0,0 +1,170 @@
+package workspace
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// DefaultRegistryPath is the collection registry when
+// workspace.registry_path is not set
+const DefaultRegistryPath = "~/.local/share/ragcode/workspaces.json"
+
+// registrySaveInterval is how often the use of a collection is written to
+// the registry at most: queries come far more often than the TTL needs
+const registrySaveInterval = time.Minute
+
+// CollectionUse records the last use of a workspace collection. The
+// registry keeps one per collection across restarts and servers, so
+// cleanup_workspaces knows collections no running server has loaded.
+type CollectionUse struct {
+	Collection  string    `json:"collection"`
+	Root        string    `json:"root"`
+	WorkspaceID string    `json:"workspace_id"`
+	Language    string    `json:"language"`
+	LastUsed    time.Time `json:"last_used"`
+}
+
+// registry is the content of the registry file
+type registry struct {
+	Collections map[string]CollectionUse `json:"collections"`
+}
+
+// registryPath returns the registry file, "" without a configuration
+func (m *Manager) registryPath() (string, error) {
+	if m.config == nil {
+		return "", nil
+	}
+	path := m.config.Workspace.RegistryPath
+	if path == "" {
+		path = DefaultRegistryPath
+	}
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s: %w", path, err)
+	}
+	return filepath.Join(home, strings.TrimPrefix(path, "~")), nil
+}
+
+// recordCollectionUse writes to the registry that a collection was used
+// now, at most once per registrySaveInterval
+func (m *Manager) recordCollectionUse(info *Info, language, collection string) {
+	path, err := m.registryPath()
+	if err != nil || path == "" {
+		return
+	}
+	now := time.Now()
+
+	m.registryMu.Lock()
+	defer m.registryMu.Unlock()
+	if now.Sub(m.registrySaved[collection]) < registrySaveInterval {
+		return
+	}
+	err = updateRegistry(path, func(r *registry) {
+		r.Collections[collection] = CollectionUse{
+			Collection:  collection,
+			Root:        info.Root,
+			WorkspaceID: info.ID,
+			Language:    language,
+			LastUsed:    now,
+		}
+	})
+	if err != nil {
+		log.Printf("⚠️  Failed to record the use of collection '%s': %v", collection, err)
+		return
+	}
+	if m.registrySaved == nil {
+		m.registrySaved = make(map[string]time.Time)
+	}
+	m.registrySaved[collection] = now
+}
+
+// CollectionUses returns the collections of the registry
+func (m *Manager) CollectionUses() ([]CollectionUse, error) {
+	path, err := m.registryPath()
+	if err != nil || path == "" {
+		return nil, err
+	}
+	m.registryMu.Lock()
+	r, err := loadRegistry(path)
+	m.registryMu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	uses := make([]CollectionUse, 0, len(r.Collections))
+	for _, use := range r.Collections {
+		uses = append(uses, use)
+	}
+	return uses, nil
+}
+
+// forgetCollections removes collections from the registry
+func (m *Manager) forgetCollections(collections []string) error {
+	path, err := m.registryPath()
+	if err != nil || path == "" {
+		return err
+	}
+	m.registryMu.Lock()
+	defer m.registryMu.Unlock()
+	for _, c := range collections {
+		delete(m.registrySaved, c)
+	}
+	return updateRegistry(path, func(r *registry) {
+		for _, c := range collections {
+			delete(r.Collections, c)
+		}
+	})
+}
+
+// loadRegistry reads the registry file, empty when it does not exist
+func loadRegistry(path string) (*registry, error) {
+	r := &registry{Collections: make(map[string]CollectionUse)}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return r, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(data, r); err != nil {
+		return nil, fmt.Errorf("corrupt workspace registry %s: %w", path, err)
+	}
+	if r.Collections == nil {
+		r.Collections = make(map[string]CollectionUse)
+	}
+	return r, nil
+}
+
+// updateRegistry loads the registry, applies update and replaces the file
+// atomically. Other servers write it too, so it is read again every time.
+func updateRegistry(path string, update func(*registry)) error {
+	r, err := loadRegistry(path)
+	if err != nil {
+		return err
+	}
+	update(r)
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := fmt.Sprintf("%s.%d.tmp", path, os.Getpid())
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
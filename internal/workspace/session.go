@@ -0,0 +1,512 @@
+package workspace
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// editSessionTTL is how long an edit session may stay idle before it is
+// discarded.
+const editSessionTTL = 2 * time.Hour
+
+// EditSession collects the edits of a multi-step refactor in memory. Each
+// staged edit applies on top of the previous ones; nothing is written until
+// Commit, which writes all files as one journaled change.
+type EditSession struct {
+	ID        string
+	Root      string
+	Client    string // MCP session that began it, "" over stdio
+	CreatedAt time.Time
+
+	mu        sync.Mutex
+	opts      PatchOptions
+	realRoot  string
+	files     map[string]*stagedFile // by slash path relative to Root
+	stages    int
+	touchedAt time.Time
+}
+
+// stagedFile is the state of one file in a session: its content on disk when
+// first staged and its content after the staged edits.
+type stagedFile struct {
+	path       string // absolute
+	origExists bool
+	orig       string
+	origHash   string // "" when the file did not exist
+	mode       os.FileMode
+	exists     bool
+	content    string
+}
+
+// EditSessions holds the open edit sessions of the server.
+type EditSessions struct {
+	mu       sync.Mutex
+	sessions map[string]*EditSession
+}
+
+// NewEditSessions creates an empty session registry.
+func NewEditSessions() *EditSessions {
+	return &EditSessions{sessions: make(map[string]*EditSession)}
+}
+
+// Begin opens a session of client on the workspace at root. opts limit the
+// files the session may change, like for ApplyPatch; DryRun is ignored.
+func (r *EditSessions) Begin(client, root string, opts PatchOptions) (*EditSession, error) {
+	realRoot, err := filepath.EvalSymlinks(root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve workspace root: %w", err)
+	}
+	buf := make([]byte, 4)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	s := &EditSession{
+		ID:        "es-" + hex.EncodeToString(buf),
+		Root:      root,
+		Client:    client,
+		CreatedAt: now,
+		opts:      opts,
+		realRoot:  realRoot,
+		files:     make(map[string]*stagedFile),
+		touchedAt: now,
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.expireLocked(now)
+	r.sessions[s.ID] = s
+	return s, nil
+}
+
+// Get returns the open session with the given id. Sessions of other clients
+// are not found.
+func (r *EditSessions) Get(client, id string) (*EditSession, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.expireLocked(time.Now())
+	s, ok := r.sessions[id]
+	if !ok || s.Client != client {
+		return nil, fmt.Errorf("edit session %s not found: it was committed, discarded or expired", id)
+	}
+	return s, nil
+}
+
+// Close removes a session, after it was committed or to discard it.
+func (r *EditSessions) Close(id string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.sessions, id)
+}
+
+func (r *EditSessions) expireLocked(now time.Time) {
+	for id, s := range r.sessions {
+		s.mu.Lock()
+		idle := now.Sub(s.touchedAt)
+		s.mu.Unlock()
+		if idle > editSessionTTL {
+			delete(r.sessions, id)
+		}
+	}
+}
+
+// file returns the staged state of a path in files, reading it from disk the
+// first time the session touches it.
+func (s *EditSession) file(files map[string]*stagedFile, p string) (string, *stagedFile, error) {
+	abs, err := resolvePatchPath(s.Root, s.realRoot, p, s.opts.Protected)
+	if err != nil {
+		return "", nil, err
+	}
+	rel, _ := filepath.Rel(s.Root, abs)
+	rel = filepath.ToSlash(rel)
+	if f, ok := files[rel]; ok {
+		return rel, f, nil
+	}
+	f := &stagedFile{path: abs, mode: 0644}
+	data, err := os.ReadFile(abs)
+	switch {
+	case err == nil:
+		f.origExists, f.exists = true, true
+		f.orig, f.content = string(data), string(data)
+		f.origHash = fileHash(data)
+		if fi, err := os.Stat(abs); err == nil {
+			f.mode = fi.Mode().Perm()
+		}
+	case !os.IsNotExist(err):
+		return "", nil, fmt.Errorf("cannot read %s: %w", p, err)
+	}
+	files[rel] = f
+	return rel, f, nil
+}
+
+// stage runs fn on a copy of the staged files and keeps the result only when
+// fn succeeds and the session stays within the file limit.
+func (s *EditSession) stage(fn func(files map[string]*stagedFile) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	files := make(map[string]*stagedFile, len(s.files))
+	for rel, f := range s.files {
+		c := *f
+		files[rel] = &c
+	}
+	if err := fn(files); err != nil {
+		return err
+	}
+	maxFiles := s.opts.MaxFiles
+	if maxFiles <= 0 {
+		maxFiles = defaultPatchMaxFiles
+	}
+	if n := len(changedFiles(files)); n > maxFiles {
+		return fmt.Errorf("session would change %d files, more than the limit of %d (edits.max_files)", n, maxFiles)
+	}
+	s.files = files
+	s.stages++
+	s.touchedAt = time.Now()
+	return nil
+}
+
+// StagePatch applies a unified diff to the staged files. Hunks match the
+// content after the edits staged before. A patch that does not apply
+// changes nothing.
+func (s *EditSession) StagePatch(diff string) error {
+	patches, err := ParsePatch(diff)
+	if err != nil {
+		return err
+	}
+	return s.stage(func(files map[string]*stagedFile) error {
+		for _, fp := range patches {
+			var oldFile, newFile *stagedFile
+			if fp.OldPath != "" {
+				_, f, err := s.file(files, fp.OldPath)
+				if err != nil {
+					return err
+				}
+				if !f.exists {
+					return fmt.Errorf("cannot patch %s: the file does not exist", fp.OldPath)
+				}
+				oldFile = f
+			}
+			if fp.NewPath != "" {
+				_, f, err := s.file(files, fp.NewPath)
+				if err != nil {
+					return err
+				}
+				if f != oldFile && f.exists {
+					return fmt.Errorf("cannot create %s: the file already exists", fp.NewPath)
+				}
+				newFile = f
+			}
+
+			content := ""
+			if oldFile != nil {
+				content = oldFile.content
+			}
+			updated, _, _, err := applyHunks(content, fp)
+			if err != nil {
+				return err
+			}
+			if newFile == nil {
+				if updated != "" {
+					return fmt.Errorf("patch deletes %s but leaves lines in it", fp.OldPath)
+				}
+				oldFile.exists, oldFile.content = false, ""
+				continue
+			}
+			if oldFile != nil && oldFile != newFile {
+				newFile.mode = oldFile.mode
+				oldFile.exists, oldFile.content = false, ""
+			}
+			newFile.exists, newFile.content = true, updated
+		}
+		return nil
+	})
+}
+
+// StageFile replaces the staged content of a file, creating it if needed,
+// or deletes it when remove is set.
+func (s *EditSession) StageFile(path, content string, remove bool) error {
+	return s.stage(func(files map[string]*stagedFile) error {
+		_, f, err := s.file(files, path)
+		if err != nil {
+			return err
+		}
+		if remove {
+			if !f.exists {
+				return fmt.Errorf("cannot delete %s: the file does not exist", path)
+			}
+			f.exists, f.content = false, ""
+			return nil
+		}
+		f.exists, f.content = true, content
+		return nil
+	})
+}
+
+// changedFiles returns the paths whose staged state differs from disk,
+// sorted.
+func changedFiles(files map[string]*stagedFile) []string {
+	var rels []string
+	for rel, f := range files {
+		if f.exists != f.origExists || f.content != f.orig {
+			rels = append(rels, rel)
+		}
+	}
+	sort.Strings(rels)
+	return rels
+}
+
+// Preview returns the combined unified diff of the staged edits and the
+// files they change.
+func (s *EditSession) Preview() (string, []PatchedFile) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.touchedAt = time.Now()
+	return s.previewLocked()
+}
+
+func (s *EditSession) previewLocked() (string, []PatchedFile) {
+	var diff strings.Builder
+	var files []PatchedFile
+	for _, rel := range changedFiles(s.files) {
+		f := s.files[rel]
+		d, added, removed := fileDiff(rel, f.origExists, f.orig, f.exists, f.content)
+		action := "modify"
+		switch {
+		case !f.origExists:
+			action = "create"
+		case !f.exists:
+			action = "delete"
+		}
+		files = append(files, PatchedFile{Path: rel, Action: action, Added: added, Removed: removed})
+		diff.WriteString(d)
+	}
+	return diff.String(), files
+}
+
+// Stages returns how many edits were staged.
+func (s *EditSession) Stages() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.stages
+}
+
+// Commit writes the staged files as one change, journaled for
+// RollbackChange. Files changed on disk since they were staged are a
+// conflict and nothing is written.
+func (s *EditSession) Commit() (*PatchResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	diff, files := s.previewLocked()
+	if len(files) == 0 {
+		return nil, fmt.Errorf("edit session %s has no changes to commit", s.ID)
+	}
+
+	var conflicts []string
+	var writes []patchWrite
+	for _, pf := range files {
+		f := s.files[pf.Path]
+		hash, err := currentHash(f.path)
+		if err != nil {
+			return nil, err
+		}
+		if hash != f.origHash {
+			conflicts = append(conflicts, pf.Path)
+			continue
+		}
+		if f.exists {
+			writes = append(writes, patchWrite{path: f.path, content: []byte(f.content), mode: f.mode})
+		} else {
+			writes = append(writes, patchWrite{path: f.path, remove: true})
+		}
+	}
+	if len(conflicts) > 0 {
+		return nil, fmt.Errorf("files changed on disk since they were staged: %v; discard the session and stage again", conflicts)
+	}
+
+	result := &PatchResult{Files: files}
+	if err := writeChange(s.Root, diff, result, writes); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// fileDiff returns the unified diff of one file between two states, with the
+// number of added and removed lines.
+func fileDiff(rel string, oldExists bool, old string, newExists bool, cur string) (string, int, int) {
+	oldLines, newLines := diffSplit(old), diffSplit(cur)
+	var sb strings.Builder
+	switch {
+	case !oldExists:
+		sb.WriteString("--- /dev/null\n")
+	default:
+		sb.WriteString("--- a/" + rel + "\n")
+	}
+	switch {
+	case !newExists:
+		sb.WriteString("+++ /dev/null\n")
+	default:
+		sb.WriteString("+++ b/" + rel + "\n")
+	}
+
+	ops := diffLines(oldLines, newLines)
+	added, removed := 0, 0
+	for _, op := range ops {
+		switch op.kind {
+		case '+':
+			added++
+		case '-':
+			removed++
+		}
+	}
+
+	// Hunks: runs of changes with diffContext lines of context, merged when
+	// their context overlaps
+	const diffContext = 3
+	for i := 0; i < len(ops); {
+		if ops[i].kind == ' ' {
+			i++
+			continue
+		}
+		start := max(0, i-diffContext)
+		end := i
+		for j := i; j < len(ops); j++ {
+			if ops[j].kind != ' ' {
+				end = j + 1
+			} else if j-end >= 2*diffContext {
+				break
+			}
+		}
+		end = min(len(ops), end+diffContext)
+
+		oldStart, newStart := ops[start].oldLine, ops[start].newLine
+		oldCount, newCount := 0, 0
+		for _, op := range ops[start:end] {
+			if op.kind != '+' {
+				oldCount++
+			}
+			if op.kind != '-' {
+				newCount++
+			}
+		}
+		if oldCount == 0 {
+			oldStart--
+		}
+		if newCount == 0 {
+			newStart--
+		}
+		sb.WriteString(fmt.Sprintf("@@ -%d,%d +%d,%d @@\n", oldStart+1, oldCount, newStart+1, newCount))
+		for _, op := range ops[start:end] {
+			sb.WriteByte(op.kind)
+			sb.WriteString(strings.TrimSuffix(op.text, "\n"))
+			sb.WriteString("\n")
+			if !strings.HasSuffix(op.text, "\n") {
+				sb.WriteString("\\ No newline at end of file\n")
+			}
+		}
+		i = end
+	}
+	return sb.String(), added, removed
+}
+
+// diffSplit splits content into lines that keep their "\n"; only the last
+// line may lack it.
+func diffSplit(content string) []string {
+	lines := strings.SplitAfter(content, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// diffOp is one line of a line diff: ' ' kept, '-' removed, '+' added.
+// oldLine and newLine are the 0-based positions before the line.
+type diffOp struct {
+	kind             byte
+	text             string
+	oldLine, newLine int
+}
+
+// maxDiffCells bounds the LCS table of diffLines; larger changed regions are
+// shown as one block replacement.
+const maxDiffCells = 4_000_000
+
+// diffLines computes a line diff: common prefix and suffix are kept, and the
+// region between them is aligned with a longest common subsequence.
+func diffLines(a, b []string) []diffOp {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+	midA, midB := a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]
+
+	var ops []diffOp
+	i, j := 0, 0
+	emit := func(kind byte, text string) {
+		ops = append(ops, diffOp{kind: kind, text: text, oldLine: i, newLine: j})
+		if kind != '+' {
+			i++
+		}
+		if kind != '-' {
+			j++
+		}
+	}
+	for _, l := range a[:prefix] {
+		emit(' ', l)
+	}
+
+	n, m := len(midA), len(midB)
+	if n*m > maxDiffCells {
+		for _, l := range midA {
+			emit('-', l)
+		}
+		for _, l := range midB {
+			emit('+', l)
+		}
+	} else {
+		// lcs[x][y] is the LCS length of midA[x:] and midB[y:]
+		lcs := make([][]int, n+1)
+		for x := range lcs {
+			lcs[x] = make([]int, m+1)
+		}
+		for x := n - 1; x >= 0; x-- {
+			for y := m - 1; y >= 0; y-- {
+				if midA[x] == midB[y] {
+					lcs[x][y] = lcs[x+1][y+1] + 1
+				} else {
+					lcs[x][y] = max(lcs[x+1][y], lcs[x][y+1])
+				}
+			}
+		}
+		x, y := 0, 0
+		for x < n || y < m {
+			switch {
+			case x < n && y < m && midA[x] == midB[y]:
+				emit(' ', midA[x])
+				x++
+				y++
+			case x < n && (y == m || lcs[x+1][y] >= lcs[x][y+1]):
+				emit('-', midA[x])
+				x++
+			default:
+				emit('+', midB[y])
+				y++
+			}
+		}
+	}
+
+	for _, l := range a[len(a)-suffix:] {
+		emit(' ', l)
+	}
+	return ops
+}
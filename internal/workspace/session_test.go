@@ -0,0 +1,117 @@
+package workspace
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestEditSession(t *testing.T) {
+	root := writePatchFixture(t, map[string]string{"a.go": patchFixture, "old.txt": "bye\n"})
+	sessions := NewEditSessions()
+	s, err := sessions.Begin("", root, PatchOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The second patch applies on top of the first
+	if err := s.StagePatch("--- a/a.go\n+++ b/a.go\n@@ -4 +4 @@\n-\treturn 1\n+\treturn 10\n"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.StagePatch("--- a/a.go\n+++ b/a.go\n@@ -3,2 +3,2 @@\n func A() int {\n-\treturn 10\n+\treturn 100\n"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.StageFile("pkg/new.go", "package pkg", false); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.StageFile("old.txt", "", true); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.StagePatch("--- a/missing.go\n+++ b/missing.go\n@@ -1 +1 @@\n-x\n+y\n"); err == nil {
+		t.Fatal("expected a patch of a missing file to fail")
+	}
+	if err := s.StageFile("../outside.go", "x", false); err == nil {
+		t.Fatal("expected a path outside the workspace to be rejected")
+	}
+	if readFile(t, root, "a.go") != patchFixture {
+		t.Fatal("staging wrote to disk")
+	}
+
+	diff, files := s.Preview()
+	want := `--- a/a.go
++++ b/a.go
+@@ -1,7 +1,7 @@
+ package a
+ 
+ func A() int {
+-	return 1
++	return 100
+ }
+ 
+ func B() int {
+--- a/old.txt
++++ /dev/null
+@@ -1,1 +0,0 @@
+-bye
+--- /dev/null
++++ b/pkg/new.go
+@@ -0,0 +1,1 @@
++package pkg
+\ No newline at end of file
+`
+	if diff != want {
+		t.Fatalf("preview:\n%s\nwant\n%s", diff, want)
+	}
+	if len(files) != 3 || files[0].Action != "modify" || files[1].Action != "delete" || files[2].Action != "create" {
+		t.Fatalf("files = %+v", files)
+	}
+	// The preview is itself a patch that applies to the original tree
+	if _, err := ApplyPatch(root, diff, PatchOptions{DryRun: true}); err != nil {
+		t.Fatalf("preview does not apply: %v", err)
+	}
+
+	result, err := s.Commit()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(readFile(t, root, "a.go"), "return 100") || readFile(t, root, "pkg/new.go") != "package pkg" {
+		t.Fatal("commit did not write the staged files")
+	}
+	if _, err := os.Stat(filepath.Join(root, "old.txt")); !os.IsNotExist(err) {
+		t.Fatal("commit did not delete old.txt")
+	}
+	if _, _, err := RollbackChange(root, result.ChangeID, false); err != nil {
+		t.Fatal(err)
+	}
+	if readFile(t, root, "a.go") != patchFixture || readFile(t, root, "old.txt") != "bye\n" {
+		t.Fatal("rollback did not restore the session change")
+	}
+
+	// A file changed on disk after staging is a conflict
+	s2, _ := sessions.Begin("", root, PatchOptions{})
+	if err := s2.StageFile("a.go", "package a\n", false); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "a.go"), []byte("package a // edited\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s2.Commit(); err == nil || !strings.Contains(err.Error(), "changed on disk") {
+		t.Fatalf("conflict: err = %v", err)
+	}
+	if readFile(t, root, "a.go") != "package a // edited\n" {
+		t.Fatal("conflicting commit wrote files")
+	}
+
+	// Sessions are private to the client that began them
+	s3, err := sessions.Begin("client-a", root, PatchOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sessions.Get("client-b", s3.ID); err == nil {
+		t.Fatal("another client could open the session")
+	}
+	if got, err := sessions.Get("client-a", s3.ID); err != nil || got != s3 {
+		t.Fatalf("Get = %v, %v", got, err)
+	}
+}
@@ -0,0 +1,238 @@
+package workspace
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/doITmagic/rag-code-mcp/internal/ragcode"
+)
+
+const (
+	// avgLinesPerChunk and assumedEmbeddingDim drive the index size estimate
+	avgLinesPerChunk    = 30
+	assumedEmbeddingDim = 768
+	// payloadBytesPerChunk is the metadata stored with each vector
+	payloadBytesPerChunk = 1024
+
+	// largeWorkspaceFiles is where a smaller embedding model starts to pay
+	// off in indexing time
+	largeWorkspaceFiles = 5000
+)
+
+// SetupLanguage is a language found in a workspace
+type SetupLanguage struct {
+	Language string `json:"language"`
+	Files    int    `json:"files"`
+	Enable   bool   `json:"enable"`
+	Reason   string `json:"reason,omitempty"`
+}
+
+// SetupEstimate is the expected size and duration of a full index
+type SetupEstimate struct {
+	Files            int     `json:"files"`
+	Lines            int     `json:"lines"`
+	Chunks           int     `json:"chunks"`
+	IndexSizeMB      float64 `json:"index_size_mb"`
+	EstimatedSeconds float64 `json:"estimated_seconds"`
+}
+
+// ModelSuggestion is the recommended embedding model for a workspace
+type ModelSuggestion struct {
+	Current   string `json:"current"`
+	Suggested string `json:"suggested"`
+	Reason    string `json:"reason"`
+}
+
+// SetupRecommendation is what setup_workspace recommends for a workspace
+type SetupRecommendation struct {
+	Root           string          `json:"root"`
+	ProjectType    string          `json:"project_type"`
+	Languages      []SetupLanguage `json:"languages"`
+	Exclude        []string        `json:"exclude,omitempty"`
+	Skipped        []SkippedPath   `json:"skipped,omitempty"`
+	Estimate       SetupEstimate   `json:"estimate"`
+	EmbeddingModel ModelSuggestion `json:"embedding_model"`
+	ConfigFile     string          `json:"config_file"`
+	ConfigExists   bool            `json:"config_exists"`
+	ConfigYAML     string          `json:"config_yaml"`
+}
+
+// RecommendSetup inspects a workspace and recommends the languages to index,
+// paths to exclude (.gitignore patterns that match source files), the
+// embedding model, and estimates the size and duration of indexing.
+func (m *Manager) RecommendSetup(info *Info) (*SetupRecommendation, error) {
+	scan, err := m.scanWorkspace(info)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan workspace '%s': %w", info.Root, err)
+	}
+
+	rec := &SetupRecommendation{
+		Root:        info.Root,
+		ProjectType: info.ProjectType,
+		Skipped:     scan.Skipped,
+		ConfigFile:  filepath.Join(info.Root, ProjectConfigFile),
+	}
+	if _, err := os.Stat(rec.ConfigFile); err == nil {
+		rec.ConfigExists = true
+	}
+
+	// Files ignored by git are usually generated or local: suggest
+	// excluding the patterns that match source files
+	ignore := loadGitignore(info.Root)
+	excluded := make(map[string]bool)
+
+	analyzers := ragcode.NewAnalyzerManager()
+	var langs []string
+	for lang := range scan.LanguageFiles {
+		langs = append(langs, lang)
+	}
+	sort.Strings(langs)
+	for _, lang := range langs {
+		var kept []string
+		for _, path := range scan.LanguageFiles[lang] {
+			rel, err := filepath.Rel(info.Root, path)
+			if err != nil {
+				continue
+			}
+			if p, ok := ignore.matchPath(filepath.ToSlash(rel)); ok {
+				excluded[p.raw] = true
+				continue
+			}
+			kept = append(kept, path)
+		}
+
+		sl := SetupLanguage{Language: lang, Files: len(kept), Enable: true}
+		switch {
+		case analyzers.CodeAnalyzerForProjectType(lang) == nil:
+			sl.Enable, sl.Reason = false, "no analyzer available"
+		case len(kept) == 0:
+			sl.Enable, sl.Reason = false, "all files are ignored by .gitignore"
+		}
+		rec.Languages = append(rec.Languages, sl)
+
+		if sl.Enable {
+			lines, size := countLines(kept)
+			rec.Estimate.Files += len(kept)
+			rec.Estimate.Lines += lines
+			chunks := lines / avgLinesPerChunk
+			if chunks < len(kept) {
+				chunks = len(kept)
+			}
+			rec.Estimate.Chunks += chunks
+			rec.Estimate.IndexSizeMB += float64(chunks*(assumedEmbeddingDim*4+payloadBytesPerChunk)+size) / (1 << 20)
+		}
+	}
+	for p := range excluded {
+		rec.Exclude = append(rec.Exclude, p)
+	}
+	sort.Strings(rec.Exclude)
+	rec.Estimate.EstimatedSeconds = float64(rec.Estimate.Files) * estimatedSecondsPerFile
+	rec.Estimate.IndexSizeMB = float64(int(rec.Estimate.IndexSizeMB*10+0.5)) / 10
+
+	rec.EmbeddingModel = suggestEmbeddingModel(m.EmbedModel(), rec.Estimate.Files)
+	rec.ConfigYAML = rec.starterConfig()
+	return rec, nil
+}
+
+// WriteConfig writes the recommended .ragcode.yaml. An existing file is
+// never overwritten.
+func (r *SetupRecommendation) WriteConfig() error {
+	f, err := os.OpenFile(r.ConfigFile, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if os.IsExist(err) {
+		return fmt.Errorf("%s already exists; edit it instead", r.ConfigFile)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", r.ConfigFile, err)
+	}
+	defer f.Close()
+	if _, err := f.WriteString(r.ConfigYAML); err != nil {
+		return fmt.Errorf("failed to write %s: %w", r.ConfigFile, err)
+	}
+	r.ConfigExists = true
+	return nil
+}
+
+// starterConfig renders the recommended settings as a .ragcode.yaml
+func (r *SetupRecommendation) starterConfig() string {
+	starter := struct {
+		Languages []string `yaml:"languages,omitempty"`
+		Exclude   []string `yaml:"exclude,omitempty"`
+	}{Exclude: r.Exclude}
+	for _, lang := range r.Languages {
+		if lang.Enable {
+			starter.Languages = append(starter.Languages, lang.Language)
+		}
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("# RagCode workspace settings, generated by setup_workspace.\n")
+	buf.WriteString("# languages: languages to index; exclude: paths left out (.gitignore syntax)\n")
+	if len(starter.Languages) > 0 || len(starter.Exclude) > 0 {
+		data, err := yaml.Marshal(starter)
+		if err == nil {
+			buf.Write(data)
+		}
+	}
+	buf.WriteString("# glossary: docs/glossary.yaml\n")
+	buf.WriteString("# templates: .ragcode/templates\n")
+	return buf.String()
+}
+
+// suggestEmbeddingModel picks an Ollama embedding model for the workspace
+// size
+func suggestEmbeddingModel(current string, files int) ModelSuggestion {
+	s := ModelSuggestion{Current: current, Suggested: "nomic-embed-text",
+		Reason: "good retrieval quality for code and documentation at this size"}
+	if files > largeWorkspaceFiles {
+		s.Suggested = "all-minilm"
+		s.Reason = fmt.Sprintf("a small, fast model (384 dimensions) keeps indexing %d files and the index size down", files)
+	}
+	if s.Suggested == current {
+		s.Reason = "the configured model fits this workspace"
+	}
+	return s
+}
+
+// matchPath returns the pattern ignoring a file, directly or through one of
+// its parent directories
+func (g gitignore) matchPath(rel string) (gitignorePattern, bool) {
+	parts := strings.Split(rel, "/")
+	for _, p := range g {
+		single := gitignore{p}
+		for i := 1; i < len(parts); i++ {
+			if single.match(strings.Join(parts[:i], "/"), true) {
+				return p, true
+			}
+		}
+		if single.match(rel, false) {
+			return p, true
+		}
+	}
+	return gitignorePattern{}, false
+}
+
+// countLines returns the total lines and bytes of files
+func countLines(paths []string) (int, int) {
+	lines, size := 0, 0
+	for _, path := range paths {
+		f, err := os.Open(path)
+		if err != nil {
+			continue
+		}
+		scanner := bufio.NewScanner(f)
+		scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+		for scanner.Scan() {
+			lines++
+			size += len(scanner.Bytes()) + 1
+		}
+		f.Close()
+	}
+	return lines, size
+}
@@ -0,0 +1,73 @@
+package workspace
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRecommendSetup(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		".gitignore":  "gen/\n*.log\n",
+		"main.go":     "package main\n\nfunc main() {}\n",
+		"gen/x.go":    "package gen\n",
+		"debug.log":   "noise\n",
+		"README.md":   "# Readme\n",
+		"web/app.foo": "",
+	}
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	rec, err := (&Manager{}).RecommendSetup(&Info{Root: root, ProjectType: "go"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(rec.Exclude, []string{"gen/"}) {
+		t.Errorf("exclude = %v, want [gen/]", rec.Exclude)
+	}
+	if len(rec.Languages) != 1 || rec.Languages[0].Language != "go" || rec.Languages[0].Files != 1 || !rec.Languages[0].Enable {
+		t.Errorf("languages = %+v, want go with 1 file", rec.Languages)
+	}
+	if rec.Estimate.Files != 1 || rec.Estimate.Lines != 3 || rec.Estimate.Chunks != 1 {
+		t.Errorf("estimate = %+v", rec.Estimate)
+	}
+	if rec.EmbeddingModel.Suggested != "nomic-embed-text" || rec.ConfigExists {
+		t.Errorf("recommendation = %+v", rec)
+	}
+	if !strings.Contains(rec.ConfigYAML, "languages:\n    - go\n") || !strings.Contains(rec.ConfigYAML, "- gen/") {
+		t.Errorf("config yaml = %q", rec.ConfigYAML)
+	}
+
+	if err := rec.WriteConfig(); err != nil {
+		t.Fatal(err)
+	}
+	if err := rec.WriteConfig(); err == nil {
+		t.Error("expected second WriteConfig to refuse overwriting")
+	}
+
+	// The written config applies to the next scan
+	cfg, err := LoadProjectConfig(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(cfg.FilterLanguages([]string{"go", "python"}), []string{"go"}) {
+		t.Errorf("filtered languages = %v", cfg.FilterLanguages([]string{"go", "python"}))
+	}
+	scan, err := (&Manager{}).scanWorkspace(&Info{Root: root})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := scan.LanguageFiles["go"]; len(got) != 1 || filepath.Base(got[0]) != "main.go" {
+		t.Errorf("go files = %v, want only main.go", got)
+	}
+}
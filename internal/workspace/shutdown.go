@@ -0,0 +1,111 @@
+package workspace
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+)
+
+// background runs fn in a goroutine that Shutdown waits for. fn receives a
+// context cancelled when shutdown starts. Nothing runs once the manager is
+// shutting down.
+func (m *Manager) background(fn func(ctx context.Context)) {
+	m.jobsMu.Lock()
+	if m.closing {
+		m.jobsMu.Unlock()
+		return
+	}
+	if m.jobsCtx == nil {
+		m.jobsCtx, m.jobsCancel = context.WithCancel(context.Background())
+	}
+	ctx := m.jobsCtx
+	m.jobs.Add(1)
+	m.jobsMu.Unlock()
+
+	go func() {
+		defer m.jobs.Done()
+		fn(ctx)
+	}()
+}
+
+// RunExclusive runs fn as a background job that Shutdown waits for, like
+// indexing. Jobs with the same key run one at a time, e.g. git commands in
+// one checkout. Nothing runs once the manager is shutting down.
+func (m *Manager) RunExclusive(key string, fn func(ctx context.Context)) {
+	m.background(func(ctx context.Context) {
+		unlock := m.exclusiveLocks.Lock(key)
+		defer unlock()
+		if ctx.Err() != nil {
+			return
+		}
+		fn(ctx)
+	})
+}
+
+// Shutdown stops the file watchers, cancels background indexing and waits
+// until every job has checkpointed .ragcode/state.json, then closes the
+// collection clients. It gives up waiting when ctx is done.
+func (m *Manager) Shutdown(ctx context.Context) error {
+	m.jobsMu.Lock()
+	m.closing = true
+	if m.jobsCancel != nil {
+		m.jobsCancel()
+	}
+	m.jobsMu.Unlock()
+
+	m.watchersMu.Lock()
+	for root, watcher := range m.watchers {
+		watcher.Stop()
+		delete(m.watchers, root)
+	}
+	m.watchersMu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		m.jobs.Wait()
+		close(done)
+	}()
+	var err error
+	select {
+	case <-done:
+	case <-ctx.Done():
+		err = fmt.Errorf("background jobs still running at shutdown deadline: %w", ctx.Err())
+	}
+
+	m.memoryMu.Lock()
+	for collection, mem := range m.memories {
+		if closer, ok := mem.(io.Closer); ok {
+			if cerr := closer.Close(); cerr != nil {
+				log.Printf("⚠️  Failed to close collection client '%s': %v", collection, cerr)
+			}
+		}
+		delete(m.memories, collection)
+	}
+	m.memoryMu.Unlock()
+
+	m.docCollections.mu.Lock()
+	for collection, entry := range m.docCollections.entries {
+		if closer, ok := entry.mem.(io.Closer); ok {
+			if cerr := closer.Close(); cerr != nil {
+				log.Printf("⚠️  Failed to close collection client '%s': %v", collection, cerr)
+			}
+		}
+		delete(m.docCollections.entries, collection)
+	}
+	m.docCollections.mu.Unlock()
+	return err
+}
+
+// checkpoint saves the state of an interrupted indexing run. Files not fully
+// indexed are left out so the next run indexes them again.
+func checkpoint(state *WorkspaceState, stateFile string, pending map[string]bool) {
+	for path := range pending {
+		state.RemoveFile(path)
+	}
+	if err := state.Save(stateFile); err != nil {
+		log.Printf("⚠️  Failed to save workspace state: %v", err)
+		return
+	}
+	log.Printf("💾 Indexing interrupted: state saved, %d file(s) left for the next run", len(pending))
+}
@@ -0,0 +1,120 @@
+package workspace
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestShutdownWaitsForBackgroundJobs(t *testing.T) {
+	m := &Manager{}
+	stopped := make(chan struct{})
+	m.background(func(ctx context.Context) {
+		<-ctx.Done()
+		close(stopped)
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := m.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+	select {
+	case <-stopped:
+	default:
+		t.Fatal("Shutdown returned before the job stopped")
+	}
+
+	// No new jobs once shutting down
+	ran := false
+	m.background(func(ctx context.Context) { ran = true })
+	m.jobs.Wait()
+	if ran {
+		t.Error("job started after Shutdown")
+	}
+}
+
+func TestRunExclusive(t *testing.T) {
+	m := &Manager{}
+	release := make(chan struct{})
+	started := make(chan string, 3)
+	for _, key := range []string{"a", "a", "b"} {
+		key := key
+		m.RunExclusive(key, func(ctx context.Context) {
+			started <- key
+			<-release
+		})
+	}
+
+	// one job of a and the job of b start, the second job of a waits
+	got := map[string]int{}
+	for i := 0; i < 2; i++ {
+		got[<-started]++
+	}
+	select {
+	case key := <-started:
+		t.Fatalf("job of %s started while another job of %s was running", key, key)
+	case <-time.After(50 * time.Millisecond):
+	}
+	if got["a"] != 1 || got["b"] != 1 {
+		t.Fatalf("started = %v, want one job of a and one of b", got)
+	}
+	close(release)
+	m.jobs.Wait()
+	if key := <-started; key != "a" {
+		t.Errorf("last job = %s, want the second job of a", key)
+	}
+}
+
+func TestShutdownDeadline(t *testing.T) {
+	m := &Manager{}
+	release := make(chan struct{})
+	defer close(release)
+	m.background(func(ctx context.Context) {
+		<-release
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := m.Shutdown(ctx); err == nil {
+		t.Error("expected an error for a job outliving the deadline")
+	}
+}
+
+func TestCheckpoint(t *testing.T) {
+	dir := t.TempDir()
+	stateFile := filepath.Join(dir, ".ragcode", "state.json")
+	var infos []os.FileInfo
+	for _, name := range []string{"done.go", "pending.go"} {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte("package x\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		fi, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		infos = append(infos, fi)
+	}
+	state := NewWorkspaceState()
+	state.UpdateFile(filepath.Join(dir, "done.go"), infos[0])
+	state.UpdateFile(filepath.Join(dir, "pending.go"), infos[1])
+
+	checkpoint(state, stateFile, map[string]bool{filepath.Join(dir, "pending.go"): true})
+
+	saved, err := LoadState(stateFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := saved.GetFileState(filepath.Join(dir, "done.go")); !ok {
+		t.Error("indexed file missing from the checkpoint")
+	}
+	if _, ok := saved.GetFileState(filepath.Join(dir, "pending.go")); ok {
+		t.Error("pending file recorded as indexed")
+	}
+	if _, err := os.Stat(stateFile + ".tmp"); !os.IsNotExist(err) {
+		t.Error("temporary state file left behind")
+	}
+}
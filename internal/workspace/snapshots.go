@@ -0,0 +1,195 @@
+package workspace
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+
+	"github.com/doITmagic/rag-code-mcp/internal/objstore"
+	"github.com/doITmagic/rag-code-mcp/internal/ragcode"
+)
+
+// PreviousSnapshot is the name of the snapshot holding each language's public
+// symbols as they were before its most recent index run.
+const PreviousSnapshot = "previous"
+
+var snapshotNameRe = regexp.MustCompile(`^[A-Za-z0-9._-]+$`)
+
+// SymbolSnapshot is a saved copy of a workspace's public symbols, per language.
+type SymbolSnapshot struct {
+	Name      string                           `json:"name"`
+	CreatedAt time.Time                        `json:"created_at"`
+	Languages map[string][]ragcode.SymbolEntry `json:"languages"`
+}
+
+// Entries returns the snapshot symbols, optionally restricted to one language
+func (s *SymbolSnapshot) Entries(language string) []ragcode.SymbolEntry {
+	var out []ragcode.SymbolEntry
+	for lang, entries := range s.Languages {
+		if language == "" || lang == language {
+			out = append(out, entries...)
+		}
+	}
+	return out
+}
+
+func snapshotPath(root, name string) string {
+	return filepath.Join(root, ".ragcode", "snapshots", name+".json")
+}
+
+// remoteSnapshotURL returns the object storage URL a pinned snapshot is
+// shared under (workspace.snapshot_url), "" when sharing is off
+func (m *Manager) remoteSnapshotURL(info *Info, name string) string {
+	if m.config == nil || m.config.Workspace.SnapshotURL == "" || name == PreviousSnapshot {
+		return ""
+	}
+	return objstore.Join(m.config.Workspace.SnapshotURL, filepath.Base(info.Root), "snapshots", name+".json")
+}
+
+func validateSnapshotName(name string) error {
+	if !snapshotNameRe.MatchString(name) {
+		return fmt.Errorf("invalid snapshot name %q: use letters, digits, '.', '_' or '-'", name)
+	}
+	return nil
+}
+
+func loadSnapshot(path string) (*SymbolSnapshot, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return decodeSnapshot(data)
+}
+
+func decodeSnapshot(data []byte) (*SymbolSnapshot, error) {
+	var snap SymbolSnapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return nil, err
+	}
+	if snap.Languages == nil {
+		snap.Languages = make(map[string][]ragcode.SymbolEntry)
+	}
+	return &snap, nil
+}
+
+func saveSnapshot(path string, snap *SymbolSnapshot) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := json.Marshal(snap)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// exportedByLanguage groups the exported symbols of a table by language
+func exportedByLanguage(entries []ragcode.SymbolEntry, language string) map[string][]ragcode.SymbolEntry {
+	out := make(map[string][]ragcode.SymbolEntry)
+	for _, e := range entries {
+		if e.Exported && (language == "" || e.Language == language) {
+			out[e.Language] = append(out[e.Language], e)
+		}
+	}
+	return out
+}
+
+// rememberPreviousSymbols stores the public symbols of a language before an
+// index run changes them. Nothing is stored for the first run of a language,
+// so the first diff is not reported as the whole API being added. Must be
+// called with symbolsMu held.
+func (m *Manager) rememberPreviousSymbols(info *Info, language string, table *SymbolTable) error {
+	if !table.HasLanguage(language) {
+		return nil
+	}
+	path := snapshotPath(info.Root, PreviousSnapshot)
+	snap, err := loadSnapshot(path)
+	if err != nil {
+		snap = &SymbolSnapshot{Name: PreviousSnapshot, Languages: make(map[string][]ragcode.SymbolEntry)}
+	}
+	snap.CreatedAt = time.Now()
+	snap.Languages[language] = exportedByLanguage(table.All(), language)[language]
+	return saveSnapshot(path, snap)
+}
+
+// PinSymbolSnapshot saves the current public symbols of the workspace under
+// name so later API diffs can be taken against it. With
+// workspace.snapshot_url the snapshot is also uploaded, for other machines
+// to diff against.
+func (m *Manager) PinSymbolSnapshot(ctx context.Context, info *Info, name string) (*SymbolSnapshot, error) {
+	if err := validateSnapshotName(name); err != nil {
+		return nil, err
+	}
+	if name == PreviousSnapshot {
+		return nil, fmt.Errorf("snapshot name %q is reserved", PreviousSnapshot)
+	}
+
+	table, err := m.Symbols(info)
+	if err != nil {
+		return nil, err
+	}
+	snap := &SymbolSnapshot{
+		Name:      name,
+		CreatedAt: time.Now(),
+		Languages: exportedByLanguage(table.All(), ""),
+	}
+	if err := saveSnapshot(snapshotPath(info.Root, name), snap); err != nil {
+		return nil, err
+	}
+	if url := m.remoteSnapshotURL(info, name); url != "" {
+		data, err := json.Marshal(snap)
+		if err != nil {
+			return nil, err
+		}
+		if err := objstore.Put(ctx, url, data); err != nil {
+			return nil, fmt.Errorf("snapshot saved locally but not shared: %w", err)
+		}
+	}
+	return snap, nil
+}
+
+// SymbolSnapshot loads a pinned snapshot or PreviousSnapshot. It returns
+// (nil, nil) when the snapshot does not exist. With workspace.snapshot_url
+// pinned snapshots are downloaded first and kept as the local copy, which
+// is used when the download fails.
+func (m *Manager) SymbolSnapshot(ctx context.Context, info *Info, name string) (*SymbolSnapshot, error) {
+	if err := validateSnapshotName(name); err != nil {
+		return nil, err
+	}
+	path := snapshotPath(info.Root, name)
+	if url := m.remoteSnapshotURL(info, name); url != "" {
+		snap, err := fetchSnapshot(ctx, url)
+		switch {
+		case err == nil:
+			if err := saveSnapshot(path, snap); err != nil {
+				log.Printf("⚠️  Could not keep a local copy of snapshot %s: %v", name, err)
+			}
+			return snap, nil
+		case !errors.Is(err, objstore.ErrNotFound):
+			log.Printf("⚠️  Shared snapshot %s unavailable, using the local copy: %v", name, err)
+		}
+	}
+	snap, err := loadSnapshot(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	return snap, err
+}
+
+func fetchSnapshot(ctx context.Context, url string) (*SymbolSnapshot, error) {
+	data, err := objstore.Get(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+	snap, err := decodeSnapshot(data)
+	if err != nil {
+		return nil, fmt.Errorf("decode %s: %w", url, err)
+	}
+	return snap, nil
+}
@@ -0,0 +1,107 @@
+package workspace
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/doITmagic/rag-code-mcp/internal/codetypes"
+	"github.com/doITmagic/rag-code-mcp/internal/config"
+)
+
+func TestSymbolSnapshots(t *testing.T) {
+	root := t.TempDir()
+	info := &Info{Root: root, ID: "ws"}
+	m := &Manager{}
+
+	first := []codetypes.CodeChunk{
+		{Type: "function", Name: "Charge", Language: "go", FilePath: root + "/billing.go", Signature: "func Charge(amount int) error"},
+		{Type: "function", Name: "helper", Language: "go", FilePath: root + "/billing.go"},
+	}
+	m.updateSymbols(info, "go", []string{root + "/billing.go"}, nil, first, nil)
+
+	if snap, err := m.SymbolSnapshot(context.Background(), info, PreviousSnapshot); err != nil || snap != nil {
+		t.Fatalf("expected no previous generation after first run, got %+v, %v", snap, err)
+	}
+
+	pinned, err := m.PinSymbolSnapshot(context.Background(), info, "v1.0.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := len(pinned.Entries("")); got != 1 {
+		t.Errorf("pinned %d symbols, want 1 exported", got)
+	}
+	if _, err := m.PinSymbolSnapshot(context.Background(), info, "../escape"); err == nil {
+		t.Error("expected invalid snapshot name to be rejected")
+	}
+
+	second := []codetypes.CodeChunk{
+		{Type: "function", Name: "Charge", Language: "go", FilePath: root + "/billing.go", Signature: "func Charge(ctx context.Context, amount int) error"},
+	}
+	m.updateSymbols(info, "go", []string{root + "/billing.go"}, nil, second, nil)
+
+	prev, err := m.SymbolSnapshot(context.Background(), info, PreviousSnapshot)
+	if err != nil || prev == nil {
+		t.Fatalf("expected previous generation, got %v", err)
+	}
+	entries := prev.Entries("go")
+	if len(entries) != 1 || entries[0].Signature != "func Charge(amount int) error" {
+		t.Errorf("unexpected previous entries: %+v", entries)
+	}
+}
+
+func TestSharedSymbolSnapshots(t *testing.T) {
+	var mu sync.Mutex
+	objects := make(map[string][]byte)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if r.Method == http.MethodPut {
+			objects[r.URL.Path], _ = io.ReadAll(r.Body)
+			return
+		}
+		data, ok := objects[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write(data)
+	}))
+	defer srv.Close()
+	t.Setenv("AWS_ENDPOINT_URL_S3", srv.URL)
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKID")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+
+	cfg := &config.Config{Workspace: config.WorkspaceConfig{SnapshotURL: "s3://team/ragcode"}}
+	ctx := context.Background()
+
+	// CI pins a snapshot in its checkout...
+	ci := &Info{Root: filepath.Join(t.TempDir(), "app"), ID: "ci"}
+	m := &Manager{config: cfg}
+	m.updateSymbols(ci, "go", []string{ci.Root + "/api.go"}, nil, []codetypes.CodeChunk{
+		{Type: "function", Name: "Serve", Language: "go", FilePath: ci.Root + "/api.go"},
+	}, nil)
+	if _, err := m.PinSymbolSnapshot(ctx, ci, "v2.0.0"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := objects["/team/ragcode/app/snapshots/v2.0.0.json"]; !ok {
+		t.Fatalf("snapshot not uploaded: %v", objects)
+	}
+
+	// ...and a developer diffs against it in theirs
+	dev := &Info{Root: filepath.Join(t.TempDir(), "app"), ID: "dev"}
+	snap, err := m.SymbolSnapshot(ctx, dev, "v2.0.0")
+	if err != nil || snap == nil || len(snap.Entries("go")) != 1 {
+		t.Fatalf("shared snapshot = %+v, %v", snap, err)
+	}
+	if _, err := loadSnapshot(snapshotPath(dev.Root, "v2.0.0")); err != nil {
+		t.Errorf("no local copy of the shared snapshot: %v", err)
+	}
+	if snap, err := m.SymbolSnapshot(ctx, dev, "v3.0.0"); err != nil || snap != nil {
+		t.Errorf("missing snapshot = %+v, %v", snap, err)
+	}
+}
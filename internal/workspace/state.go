@@ -2,10 +2,15 @@ package workspace
 
 import (
 	"encoding/json"
+	"fmt"
+	"log"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
 	"time"
+
+	"github.com/doITmagic/rag-code-mcp/internal/codetypes"
 )
 
 // FileState represents the state of a single file
@@ -16,11 +21,38 @@ type FileState struct {
 	// Hash    string    `json:"hash,omitempty"` // Optional: content hash for better accuracy
 }
 
+// ParseFailure records the syntax errors an analyzer reported for a file.
+// The file is not analyzed again until its content changes.
+type ParseFailure struct {
+	Language string                 `json:"language"`
+	Analyzer string                 `json:"analyzer"`
+	Hash     string                 `json:"hash"` // sha256 of the content that failed
+	Errors   []codetypes.ParseError `json:"errors"`
+	FailedAt time.Time              `json:"failed_at"`
+}
+
+// Skipped reports whether the analyzer extracted nothing from the file
+func (f ParseFailure) Skipped() bool {
+	for _, e := range f.Errors {
+		if e.Skipped {
+			return true
+		}
+	}
+	return false
+}
+
 // WorkspaceState tracks the state of files in a workspace
 type WorkspaceState struct {
 	Files       map[string]FileState `json:"files"`
 	LastIndexed time.Time            `json:"last_indexed"`
-	mu          sync.RWMutex
+	// Generations is the last committed index generation per collection
+	Generations map[string]uint64 `json:"generations,omitempty"`
+	// ParseFailures holds the files whose last analysis failed, by path
+	ParseFailures map[string]ParseFailure `json:"parse_failures,omitempty"`
+	// GitHeads is the commit checked out at the last indexing run, per
+	// collection, for since_ref=last_indexed
+	GitHeads map[string]string `json:"git_heads,omitempty"`
+	mu       sync.RWMutex
 }
 
 // NewWorkspaceState creates a new workspace state
@@ -30,13 +62,50 @@ func NewWorkspaceState() *WorkspaceState {
 	}
 }
 
-// LoadState loads workspace state from disk
+// stateBackup is the previous generation of a state file, kept by Save
+func stateBackup(path string) string {
+	return path + ".bak"
+}
+
+// LoadState loads workspace state from disk. A corrupt state file is
+// replaced by its backup; when neither can be read the state starts empty
+// (a full re-index) and the corrupt file is kept as <path>.corrupt.
+// Invalid entries are dropped.
 func LoadState(path string) (*WorkspaceState, error) {
+	state, err := readState(path)
+	if err == nil {
+		return state, nil
+	}
+	if !os.IsNotExist(err) {
+		log.Printf("⚠️  Workspace state %s is unreadable: %v", path, err)
+	}
+
+	backup, bakErr := readState(stateBackup(path))
+	if bakErr == nil {
+		// Restore the backup so the next load finds a valid state
+		if err := backup.write(path); err != nil {
+			log.Printf("⚠️  Failed to restore workspace state from backup: %v", err)
+		} else {
+			log.Printf("🩹 Restored workspace state %s from its backup", path)
+		}
+		return backup, nil
+	}
+	if os.IsNotExist(err) && os.IsNotExist(bakErr) {
+		return NewWorkspaceState(), nil
+	}
+	if !os.IsNotExist(err) {
+		if rerr := os.Rename(path, path+".corrupt"); rerr != nil {
+			return nil, fmt.Errorf("corrupt workspace state %s: %w", path, err)
+		}
+		log.Printf("⚠️  No valid backup of %s, starting a full re-index (kept as %s.corrupt)", path, path)
+	}
+	return NewWorkspaceState(), nil
+}
+
+// readState reads and validates one state file
+func readState(path string) (*WorkspaceState, error) {
 	f, err := os.Open(path)
 	if err != nil {
-		if os.IsNotExist(err) {
-			return NewWorkspaceState(), nil
-		}
 		return nil, err
 	}
 	defer f.Close()
@@ -45,16 +114,47 @@ func LoadState(path string) (*WorkspaceState, error) {
 	if err := json.NewDecoder(f).Decode(&state); err != nil {
 		return nil, err
 	}
-
-	if state.Files == nil {
-		state.Files = make(map[string]FileState)
+	if dropped := state.validate(); dropped > 0 {
+		log.Printf("🩹 Dropped %d invalid entries from workspace state %s", dropped, path)
 	}
-
 	return &state, nil
 }
 
-// SaveState saves workspace state to disk
+// validate removes entries that cannot describe an indexed file and returns
+// how many were removed
+func (s *WorkspaceState) validate() int {
+	if s.Files == nil {
+		s.Files = make(map[string]FileState)
+	}
+	dropped := 0
+	for path, fs := range s.Files {
+		if !filepath.IsAbs(path) || fs.Size < 0 || fs.ModTime.IsZero() {
+			delete(s.Files, path)
+			dropped++
+		}
+	}
+	for path, pf := range s.ParseFailures {
+		if _, ok := s.Files[path]; !ok || pf.Hash == "" {
+			delete(s.ParseFailures, path)
+			dropped++
+		}
+	}
+	return dropped
+}
+
+// Save saves workspace state to disk. The previous state is kept as
+// <path>.bak.
 func (s *WorkspaceState) Save(path string) error {
+	s.mu.Lock()
+	s.LastIndexed = time.Now()
+	s.mu.Unlock()
+	return s.write(path)
+}
+
+// write replaces the state file atomically: a temporary file is written and
+// renamed, so a process killed mid-write never leaves a truncated state
+// behind
+func (s *WorkspaceState) write(path string) error {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
@@ -63,14 +163,35 @@ func (s *WorkspaceState) Save(path string) error {
 		return err
 	}
 
-	f, err := os.Create(path)
+	tmp := path + ".tmp"
+	f, err := os.Create(tmp)
 	if err != nil {
 		return err
 	}
-	defer f.Close()
 
-	s.LastIndexed = time.Now()
-	return json.NewEncoder(f).Encode(s)
+	if err := json.NewEncoder(f).Encode(s); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+
+	// Keep one backup generation. A crash between the renames leaves only
+	// the backup, which LoadState falls back to.
+	if _, err := readState(path); err == nil {
+		if err := os.Rename(path, stateBackup(path)); err != nil {
+			log.Printf("⚠️  Failed to back up workspace state: %v", err)
+		}
+	}
+	return os.Rename(tmp, path)
 }
 
 // UpdateFile updates the state for a file
@@ -89,6 +210,7 @@ func (s *WorkspaceState) RemoveFile(path string) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	delete(s.Files, path)
+	delete(s.ParseFailures, path)
 }
 
 // GetFileState returns the state of a file
@@ -98,3 +220,41 @@ func (s *WorkspaceState) GetFileState(path string) (FileState, bool) {
 	state, ok := s.Files[path]
 	return state, ok
 }
+
+// SetParseFailure records the syntax errors of a file
+func (s *WorkspaceState) SetParseFailure(path string, failure ParseFailure) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.ParseFailures == nil {
+		s.ParseFailures = make(map[string]ParseFailure)
+	}
+	s.ParseFailures[path] = failure
+}
+
+// ClearParseFailure forgets the syntax errors of a file
+func (s *WorkspaceState) ClearParseFailure(path string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.ParseFailures, path)
+}
+
+// GetParseFailure returns the recorded syntax errors of a file
+func (s *WorkspaceState) GetParseFailure(path string) (ParseFailure, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	failure, ok := s.ParseFailures[path]
+	return failure, ok
+}
+
+// LanguageParseFailures returns the parse failures of one language, by path
+func (s *WorkspaceState) LanguageParseFailures(language string) map[string]ParseFailure {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	out := make(map[string]ParseFailure)
+	for path, failure := range s.ParseFailures {
+		if strings.EqualFold(failure.Language, language) {
+			out[path] = failure
+		}
+	}
+	return out
+}
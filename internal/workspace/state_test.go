@@ -0,0 +1,90 @@
+package workspace
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestStateSaveKeepsBackup(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, ".ragcode", "state.json")
+	src := filepath.Join(dir, "a.go")
+	if err := os.WriteFile(src, []byte("package a\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	fi, err := os.Stat(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	state := NewWorkspaceState()
+	state.UpdateFile(src, fi)
+	if err := state.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	state.RemoveFile(src)
+	if err := state.Save(path); err != nil {
+		t.Fatal(err)
+	}
+
+	backup, err := readState(stateBackup(path))
+	if err != nil {
+		t.Fatalf("backup: %v", err)
+	}
+	if _, ok := backup.GetFileState(src); !ok {
+		t.Error("backup should hold the previous generation")
+	}
+	if _, err := os.Stat(path + ".tmp"); !os.IsNotExist(err) {
+		t.Error("temporary state file left behind")
+	}
+
+	// A truncated state falls back to the backup and is repaired
+	if err := os.WriteFile(path, []byte(`{"files": {"/x`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadState(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := loaded.GetFileState(src); !ok {
+		t.Error("expected the state restored from the backup")
+	}
+	if _, err := readState(path); err != nil {
+		t.Errorf("state file not repaired: %v", err)
+	}
+}
+
+func TestLoadStateCorruptWithoutBackup(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	if err := os.WriteFile(path, []byte("not json"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	state, err := LoadState(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(state.Files) != 0 {
+		t.Errorf("files = %v, want an empty state", state.Files)
+	}
+	if _, err := os.Stat(path + ".corrupt"); err != nil {
+		t.Errorf("corrupt state not kept aside: %v", err)
+	}
+}
+
+func TestStateValidate(t *testing.T) {
+	now := time.Now()
+	state := &WorkspaceState{Files: map[string]FileState{
+		"/src/ok.go":     {ModTime: now, Size: 10},
+		"relative.go":    {ModTime: now, Size: 10},
+		"/src/neg.go":    {ModTime: now, Size: -1},
+		"/src/notime.go": {Size: 10},
+	}}
+	if dropped := state.validate(); dropped != 3 {
+		t.Errorf("dropped = %d, want 3", dropped)
+	}
+	if _, ok := state.Files["/src/ok.go"]; !ok || len(state.Files) != 1 {
+		t.Errorf("files = %v", state.Files)
+	}
+}
@@ -0,0 +1,101 @@
+package workspace
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	"github.com/doITmagic/rag-code-mcp/internal/ragcode"
+	"github.com/doITmagic/rag-code-mcp/internal/symbolimport"
+)
+
+// SymbolImportReport describes the files ImportSymbols seeded the symbol
+// table with, or would with DryRun
+type SymbolImportReport struct {
+	Root     string         `json:"root"`
+	Artifact string         `json:"artifact"`
+	Format   string         `json:"format"`
+	DryRun   bool           `json:"dry_run,omitempty"`
+	Files    int            `json:"files"`   // files seeded
+	Symbols  int            `json:"symbols"` // definitions seeded
+	Kept     int            `json:"kept"`    // files the table already held, left as they are
+	Ignored  int            `json:"ignored"` // files indexing does not cover: missing, excluded, other languages
+	Outside  int            `json:"outside"` // definitions of files outside the workspace
+	Language map[string]int `json:"languages"`
+}
+
+// ImportSymbols seeds the symbol table of a workspace with the definitions
+// of a ctags, LSIF or SCIP artifact, so the tools reading the table answer
+// before the workspace is indexed. format "" detects it. Only the source
+// files indexing covers are seeded, and those the table already holds are
+// kept unless replace. Indexing a file replaces its imported entries with
+// the analyzer's, which also record calls; vectors are built as usual, on
+// the first search or by index_workspace.
+func (m *Manager) ImportSymbols(info *Info, artifact string, format symbolimport.Format, replace, dryRun bool) (*SymbolImportReport, error) {
+	result, err := symbolimport.ReadFile(artifact, info.Root, format)
+	if err != nil {
+		return nil, err
+	}
+	scan, err := m.scanWorkspace(info)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan workspace '%s': %w", info.Root, err)
+	}
+	// The language of each file is the collection indexing puts it in
+	indexed := make(map[string]string)
+	for lang, files := range scan.LanguageFiles {
+		for _, file := range files {
+			indexed[filepath.Clean(file)] = lang
+		}
+	}
+
+	m.symbolsMu.Lock()
+	defer m.symbolsMu.Unlock()
+
+	path := symbolsPath(info.Root)
+	table, err := LoadSymbolTable(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load symbol table: %w", err)
+	}
+
+	report := &SymbolImportReport{
+		Root:     info.Root,
+		Artifact: artifact,
+		Format:   string(result.Format),
+		DryRun:   dryRun,
+		Outside:  result.Outside,
+		Language: make(map[string]int),
+	}
+	files := make([]string, 0, len(result.Files))
+	for file := range result.Files {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+	for _, file := range files {
+		lang, ok := indexed[file]
+		if !ok {
+			report.Ignored++
+			continue
+		}
+		if _, held := table.Files[file]; held && !replace {
+			report.Kept++
+			continue
+		}
+		entries := make([]ragcode.SymbolEntry, len(result.Files[file]))
+		for i, e := range result.Files[file] {
+			e.Language = lang
+			entries[i] = e
+		}
+		table.SetFile(file, entries)
+		report.Files++
+		report.Symbols += len(entries)
+		report.Language[lang] += len(entries)
+	}
+
+	if dryRun || report.Files == 0 {
+		return report, nil
+	}
+	if err := table.Save(path); err != nil {
+		return nil, fmt.Errorf("failed to save symbol table: %w", err)
+	}
+	return report, nil
+}
@@ -0,0 +1,93 @@
+package workspace
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/doITmagic/rag-code-mcp/internal/ragcode"
+)
+
+func TestImportSymbols(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"billing/invoice.go": "package billing\n\nfunc Total() int { return 0 }\n",
+		"billing/legacy.go":  "package billing\n\nfunc Old() {}\n",
+		"gen/api.go":         "package gen\n\nfunc Generated() {}\n",
+		ProjectConfigFile:    "exclude:\n  - gen/\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	invoice, legacy := filepath.Join(root, "billing", "invoice.go"), filepath.Join(root, "billing", "legacy.go")
+
+	// legacy.go was indexed already: the analyzer's entries stay
+	table := NewSymbolTable()
+	table.SetFile(legacy, []ragcode.SymbolEntry{{Name: "Old", Kind: "function", Language: "go", FilePath: legacy, StartLine: 3, Calls: []string{"x"}}})
+	if err := table.Save(symbolsPath(root)); err != nil {
+		t.Fatal(err)
+	}
+
+	artifact := filepath.Join(t.TempDir(), "tags.json")
+	tags := `{"_type": "tag", "name": "Total", "path": "billing/invoice.go", "language": "Go", "line": 3, "kind": "func", "scope": "billing", "scopeKind": "package"}
+{"_type": "tag", "name": "Old", "path": "billing/legacy.go", "language": "Go", "line": 3, "kind": "func", "scope": "billing", "scopeKind": "package"}
+{"_type": "tag", "name": "Generated", "path": "gen/api.go", "language": "Go", "line": 3, "kind": "func", "scope": "gen", "scopeKind": "package"}
+{"_type": "tag", "name": "Gone", "path": "billing/deleted.go", "language": "Go", "line": 3, "kind": "func"}
+`
+	if err := os.WriteFile(artifact, []byte(tags), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	m := &Manager{}
+	info := &Info{Root: root, ID: "ws1", Languages: []string{"go"}}
+
+	report, err := m.ImportSymbols(info, artifact, "", false, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Format != "ctags" || report.Files != 1 || report.Symbols != 1 || report.Kept != 1 || report.Ignored != 2 {
+		t.Fatalf("dry run report = %+v", report)
+	}
+	if table, _ := m.Symbols(info); len(table.Files[invoice]) != 0 {
+		t.Fatal("dry run wrote the symbol table")
+	}
+
+	if _, err := m.ImportSymbols(info, artifact, "", false, false); err != nil {
+		t.Fatal(err)
+	}
+	table, err = m.Symbols(info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries := table.Files[invoice]
+	if len(entries) != 1 || entries[0].Name != "Total" || entries[0].Imported != "ctags" || entries[0].Package != "billing" || !entries[0].Exported {
+		t.Fatalf("invoice.go entries = %+v", entries)
+	}
+	if kept := table.Files[legacy]; len(kept) != 1 || len(kept[0].Calls) != 1 {
+		t.Fatalf("legacy.go entries replaced: %+v", kept)
+	}
+	if _, ok := table.Files[filepath.Join(root, "gen", "api.go")]; ok {
+		t.Fatal("excluded file imported")
+	}
+
+	// Indexing a file replaces its imported entries
+	m.updateSymbols(info, "go", []string{invoice}, nil, nil, nil)
+	table, _ = m.Symbols(info)
+	if _, ok := table.Files[invoice]; ok {
+		t.Fatalf("imported entries survived indexing: %+v", table.Files[invoice])
+	}
+
+	// replace overwrites the analyzer's entries
+	report, err = m.ImportSymbols(info, artifact, "ctags", true, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	table, _ = m.Symbols(info)
+	if report.Files != 2 || table.Files[legacy][0].Imported != "ctags" {
+		t.Fatalf("replace: report %+v, legacy.go %+v", report, table.Files[legacy])
+	}
+}
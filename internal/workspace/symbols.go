@@ -0,0 +1,263 @@
+package workspace
+
+import (
+	"encoding/json"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/doITmagic/rag-code-mcp/internal/codetypes"
+	"github.com/doITmagic/rag-code-mcp/internal/ragcode"
+)
+
+// SymbolTable stores the symbols of a workspace, keyed by file. It is kept in
+// sync with the vector index so graph-style queries (callers, dependencies,
+// public API) can be answered without scanning the collection.
+type SymbolTable struct {
+	Files map[string][]ragcode.SymbolEntry `json:"files"`
+	// Imports are the imports of each file, for analyzers that record them
+	Imports map[string]codetypes.FileImports `json:"imports,omitempty"`
+	mu      sync.RWMutex
+}
+
+// NewSymbolTable creates an empty symbol table
+func NewSymbolTable() *SymbolTable {
+	return &SymbolTable{
+		Files:   make(map[string][]ragcode.SymbolEntry),
+		Imports: make(map[string]codetypes.FileImports),
+	}
+}
+
+func symbolsPath(root string) string {
+	return filepath.Join(root, ".ragcode", "symbols.json")
+}
+
+// LoadSymbolTable loads the table from disk, returning an empty table if none
+// has been written yet.
+func LoadSymbolTable(path string) (*SymbolTable, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return NewSymbolTable(), nil
+		}
+		return nil, err
+	}
+
+	table := NewSymbolTable()
+	if err := json.Unmarshal(data, table); err != nil {
+		return nil, err
+	}
+	if table.Files == nil {
+		table.Files = make(map[string][]ragcode.SymbolEntry)
+	}
+	if table.Imports == nil {
+		table.Imports = make(map[string]codetypes.FileImports)
+	}
+	return table, nil
+}
+
+// Save writes the table to disk
+func (t *SymbolTable) Save(path string) error {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := json.Marshal(t)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// SetFile replaces the symbols recorded for a file
+func (t *SymbolTable) SetFile(path string, entries []ragcode.SymbolEntry) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if len(entries) == 0 {
+		delete(t.Files, path)
+		return
+	}
+	t.Files[path] = entries
+}
+
+// SetImports replaces the imports recorded for a file
+func (t *SymbolTable) SetImports(path string, imports codetypes.FileImports) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.Imports[path] = imports
+}
+
+func (t *SymbolTable) dropImports(path string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.Imports, path)
+}
+
+// RemoveFile drops all symbols and imports recorded for a file
+func (t *SymbolTable) RemoveFile(path string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.Files, path)
+	delete(t.Imports, path)
+}
+
+// All returns every symbol in the table in a stable order
+func (t *SymbolTable) All() []ragcode.SymbolEntry {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	files := make([]string, 0, len(t.Files))
+	for f := range t.Files {
+		files = append(files, f)
+	}
+	sort.Strings(files)
+
+	var out []ragcode.SymbolEntry
+	for _, f := range files {
+		out = append(out, t.Files[f]...)
+	}
+	return out
+}
+
+// AllImports returns the imports of every file in a stable order
+func (t *SymbolTable) AllImports() []codetypes.FileImports {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	out := make([]codetypes.FileImports, 0, len(t.Imports))
+	for _, imports := range t.Imports {
+		out = append(out, imports)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].FilePath < out[j].FilePath })
+	return out
+}
+
+// RemoveLanguage drops the symbols and imports of a language
+func (t *SymbolTable) RemoveLanguage(language string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for path, imports := range t.Imports {
+		if string(imports.Language) == language {
+			delete(t.Imports, path)
+		}
+	}
+	for path, entries := range t.Files {
+		kept := entries[:0]
+		for _, e := range entries {
+			if e.Language != language {
+				kept = append(kept, e)
+			}
+		}
+		if len(kept) == 0 {
+			delete(t.Files, path)
+		} else {
+			t.Files[path] = kept
+		}
+	}
+}
+
+// HasLanguage reports whether the table holds any symbol of the language
+func (t *SymbolTable) HasLanguage(language string) bool {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	for _, entries := range t.Files {
+		for _, e := range entries {
+			if e.Language == language {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// hasImports reports whether the table holds the imports of any file of the
+// language
+func (t *SymbolTable) hasImports(language string) bool {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	for _, imports := range t.Imports {
+		if string(imports.Language) == language {
+			return true
+		}
+	}
+	return false
+}
+
+// symbolsNeedBackfill reports whether a language was indexed before the symbol
+// table existed, or before it recorded imports for an analyzer that reports
+// them, in which case unchanged files must be analysed once more.
+func (m *Manager) symbolsNeedBackfill(info *Info, language string, analyzer codetypes.PathAnalyzer) bool {
+	table, err := m.Symbols(info)
+	if err != nil {
+		return false
+	}
+	if _, ok := analyzer.(codetypes.ImportReporter); ok && !table.hasImports(language) {
+		return true
+	}
+	return !table.HasLanguage(language)
+}
+
+// updateSymbols records the symbols and imports of freshly indexed files and
+// drops those of deleted files. Indexed files without symbols are cleared as
+// well. imports is nil for analyzers that do not report them. The language's
+// public symbols before the update are kept as the "previous" snapshot.
+func (m *Manager) updateSymbols(info *Info, language string, indexed, deleted []string, chunks []codetypes.CodeChunk, imports []codetypes.FileImports) {
+	if len(indexed) == 0 && len(deleted) == 0 {
+		return
+	}
+
+	m.symbolsMu.Lock()
+	defer m.symbolsMu.Unlock()
+
+	path := symbolsPath(info.Root)
+	table, err := LoadSymbolTable(path)
+	if err != nil {
+		log.Printf("⚠️  Failed to load symbol table: %v", err)
+		table = NewSymbolTable()
+	}
+
+	// Keep the public surface as it was before this run for diff_api_surface
+	if err := m.rememberPreviousSymbols(info, language, table); err != nil {
+		log.Printf("⚠️  Failed to save previous symbol snapshot: %v", err)
+	}
+
+	byFile := make(map[string][]ragcode.SymbolEntry)
+	for _, entry := range ragcode.SymbolEntriesFromChunks(chunks) {
+		byFile[entry.FilePath] = append(byFile[entry.FilePath], entry)
+	}
+
+	for _, file := range deleted {
+		table.RemoveFile(file)
+	}
+	for _, file := range indexed {
+		table.SetFile(file, byFile[file])
+	}
+	if imports != nil {
+		importsByFile := make(map[string]codetypes.FileImports, len(imports))
+		for _, fi := range imports {
+			importsByFile[fi.FilePath] = fi
+		}
+		for _, file := range indexed {
+			if fi, ok := importsByFile[file]; ok {
+				table.SetImports(file, fi)
+			} else {
+				table.dropImports(file)
+			}
+		}
+	}
+
+	if err := table.Save(path); err != nil {
+		log.Printf("⚠️  Failed to save symbol table: %v", err)
+	}
+}
+
+// Symbols returns the symbol table recorded for a workspace
+func (m *Manager) Symbols(info *Info) (*SymbolTable, error) {
+	m.symbolsMu.Lock()
+	defer m.symbolsMu.Unlock()
+	return LoadSymbolTable(symbolsPath(info.Root))
+}
@@ -0,0 +1,414 @@
+package workspace
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultTemplatesDir is where workspace file templates are looked up when
+// .ragcode.yaml does not set templates.
+const DefaultTemplatesDir = ".ragcode/templates"
+
+// templateManifest is the optional template.yaml of a template directory.
+const templateManifest = "template.yaml"
+
+// TemplateVar is a variable of a file template. A variable without a default
+// is required.
+type TemplateVar struct {
+	Name        string `yaml:"name" json:"name"`
+	Description string `yaml:"description" json:"description,omitempty"`
+	Default     string `yaml:"default" json:"default,omitempty"`
+}
+
+// FileTemplate is a set of files created together. File paths and contents
+// are Go text/template text, rendered with the variables as fields:
+// {{.Name}}, {{.Name | snake}}.
+type FileTemplate struct {
+	Name        string        `json:"name"`
+	Description string        `json:"description,omitempty"`
+	Source      string        `json:"source"` // "builtin" or the template directory
+	Vars        []TemplateVar `json:"vars,omitempty"`
+	Files       []string      `json:"files"`
+
+	contents map[string]string // template path -> template content
+}
+
+// templateFuncs are the helpers available in template paths and contents.
+var templateFuncs = template.FuncMap{
+	"lower":  strings.ToLower,
+	"upper":  strings.ToUpper,
+	"snake":  snakeCase,
+	"camel":  func(s string) string { return lowerFirst(pascalCase(s)) },
+	"pascal": pascalCase,
+}
+
+// splitWords splits an identifier into words at case changes and at '_',
+// '-', '.', '/' and spaces: "HTTPServer_v2" is HTTP, Server, v2.
+func splitWords(s string) []string {
+	var words []string
+	var cur []rune
+	runes := []rune(s)
+	for i, r := range runes {
+		if r == '_' || r == '-' || r == '.' || r == '/' || unicode.IsSpace(r) {
+			if len(cur) > 0 {
+				words = append(words, string(cur))
+				cur = nil
+			}
+			continue
+		}
+		if unicode.IsUpper(r) && len(cur) > 0 {
+			prev := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+				words = append(words, string(cur))
+				cur = nil
+			}
+		}
+		cur = append(cur, r)
+	}
+	if len(cur) > 0 {
+		words = append(words, string(cur))
+	}
+	return words
+}
+
+func snakeCase(s string) string {
+	words := splitWords(s)
+	for i, w := range words {
+		words[i] = strings.ToLower(w)
+	}
+	return strings.Join(words, "_")
+}
+
+func pascalCase(s string) string {
+	var sb strings.Builder
+	for _, w := range splitWords(s) {
+		r := []rune(w)
+		sb.WriteString(strings.ToUpper(string(r[0])))
+		sb.WriteString(string(r[1:]))
+	}
+	return sb.String()
+}
+
+func lowerFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	r := []rune(s)
+	return strings.ToLower(string(r[0])) + string(r[1:])
+}
+
+var builtinTemplates = []*FileTemplate{
+	{
+		Name:        "go-package",
+		Description: "Go package with a doc comment and a test file",
+		Vars: []TemplateVar{
+			{Name: "Name", Description: "package name, also its directory"},
+		},
+		contents: map[string]string{
+			"{{.Name | lower}}/{{.Name | lower}}.go": `// Package {{.Name | lower}} ...
+package {{.Name | lower}}
+`,
+			"{{.Name | lower}}/{{.Name | lower}}_test.go": `package {{.Name | lower}}
+
+import "testing"
+
+func Test{{.Name | pascal}}(t *testing.T) {
+	t.Skip("not implemented")
+}
+`,
+		},
+	},
+	{
+		Name:        "laravel-controller",
+		Description: "Laravel resource controller in app/Http/Controllers",
+		Vars: []TemplateVar{
+			{Name: "Name", Description: "controller class, e.g. InvoiceController"},
+		},
+		contents: map[string]string{
+			"app/Http/Controllers/{{.Name | pascal}}.php": `<?php
+
+namespace App\Http\Controllers;
+
+use Illuminate\Http\Request;
+
+class {{.Name | pascal}} extends Controller
+{
+    public function index()
+    {
+        //
+    }
+
+    public function store(Request $request)
+    {
+        //
+    }
+
+    public function show(string $id)
+    {
+        //
+    }
+
+    public function update(Request $request, string $id)
+    {
+        //
+    }
+
+    public function destroy(string $id)
+    {
+        //
+    }
+}
+`,
+		},
+	},
+	{
+		Name:        "pytest-module",
+		Description: "pytest test module in tests/",
+		Vars: []TemplateVar{
+			{Name: "Name", Description: "unit under test, e.g. billing"},
+		},
+		contents: map[string]string{
+			"tests/test_{{.Name | snake}}.py": `import pytest
+
+
+@pytest.fixture
+def {{.Name | snake}}():
+    ...
+
+
+def test_{{.Name | snake}}({{.Name | snake}}):
+    pytest.skip("not implemented")
+`,
+		},
+	},
+}
+
+func init() {
+	for _, t := range builtinTemplates {
+		t.Source = "builtin"
+		t.Files = sortedKeys(t.contents)
+	}
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// TemplatesDir returns the directory of the workspace file templates.
+func (c *ProjectConfig) TemplatesDir(root string) string {
+	dir := c.Templates
+	if dir == "" {
+		dir = DefaultTemplatesDir
+	}
+	if filepath.IsAbs(dir) {
+		return dir
+	}
+	return filepath.Join(root, dir)
+}
+
+// ListTemplates returns the built-in templates and those of the workspace
+// templates directory, sorted by name. Each subdirectory of the templates
+// directory is a template: every file in it except template.yaml is a file
+// of the template, at the same relative path with a trailing .tmpl removed.
+// template.yaml may give a description and the variables. A workspace
+// template replaces the built-in template of the same name.
+func ListTemplates(root string) ([]*FileTemplate, error) {
+	byName := make(map[string]*FileTemplate)
+	for _, t := range builtinTemplates {
+		byName[t.Name] = t
+	}
+
+	cfg, err := LoadProjectConfig(root)
+	if err != nil {
+		return nil, err
+	}
+	dir := cfg.TemplatesDir(root)
+	entries, err := os.ReadDir(dir)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		t, err := loadTemplateDir(filepath.Join(dir, e.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("template %s: %w", e.Name(), err)
+		}
+		byName[t.Name] = t
+	}
+
+	templates := make([]*FileTemplate, 0, len(byName))
+	for _, t := range byName {
+		templates = append(templates, t)
+	}
+	sort.Slice(templates, func(i, j int) bool { return templates[i].Name < templates[j].Name })
+	return templates, nil
+}
+
+func loadTemplateDir(dir string) (*FileTemplate, error) {
+	t := &FileTemplate{
+		Name:     filepath.Base(dir),
+		Source:   dir,
+		contents: make(map[string]string),
+	}
+	if data, err := os.ReadFile(filepath.Join(dir, templateManifest)); err == nil {
+		var manifest struct {
+			Description string        `yaml:"description"`
+			Vars        []TemplateVar `yaml:"vars"`
+		}
+		if err := yaml.Unmarshal(data, &manifest); err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", templateManifest, err)
+		}
+		t.Description, t.Vars = manifest.Description, manifest.Vars
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	err := filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, _ := filepath.Rel(dir, path)
+		rel = filepath.ToSlash(rel)
+		if rel == templateManifest {
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		t.contents[strings.TrimSuffix(rel, ".tmpl")] = string(data)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(t.contents) == 0 {
+		return nil, fmt.Errorf("no files in %s", dir)
+	}
+	t.Files = sortedKeys(t.contents)
+	return t, nil
+}
+
+// FindTemplate returns the template called name.
+func FindTemplate(root, name string) (*FileTemplate, error) {
+	templates, err := ListTemplates(root)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(templates))
+	for _, t := range templates {
+		if t.Name == name {
+			return t, nil
+		}
+		names = append(names, t.Name)
+	}
+	return nil, fmt.Errorf("template %q not found (available: %s)", name, strings.Join(names, ", "))
+}
+
+// Render returns the files of the template, path to content, with vars
+// applied. Declared variables without a value take their default; a
+// required variable without value, or a variable the template uses but
+// vars does not give, is an error.
+func (t *FileTemplate) Render(vars map[string]string) (map[string]string, error) {
+	data := make(map[string]string, len(vars)+len(t.Vars))
+	for k, v := range vars {
+		data[k] = v
+	}
+	for _, v := range t.Vars {
+		if data[v.Name] != "" {
+			continue
+		}
+		if v.Default == "" {
+			return nil, fmt.Errorf("template %s requires variable %s", t.Name, v.Name)
+		}
+		data[v.Name] = v.Default
+	}
+
+	files := make(map[string]string, len(t.contents))
+	for _, name := range t.Files {
+		path, err := renderTemplateText(name, name, data)
+		if err != nil {
+			return nil, err
+		}
+		path = strings.TrimSpace(path)
+		if path == "" || strings.HasSuffix(path, "/") {
+			return nil, fmt.Errorf("template file %s renders to an empty file name", name)
+		}
+		content, err := renderTemplateText(name, t.contents[name], data)
+		if err != nil {
+			return nil, err
+		}
+		if _, dup := files[path]; dup {
+			return nil, fmt.Errorf("template %s renders two files to %s", t.Name, path)
+		}
+		files[path] = content
+	}
+	return files, nil
+}
+
+func renderTemplateText(name, text string, data map[string]string) (string, error) {
+	tmpl, err := template.New(name).Funcs(templateFuncs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("invalid template %s: %w", name, err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to render %s: %w", name, err)
+	}
+	return buf.String(), nil
+}
+
+// CreateFromTemplate renders a template into dir, relative to the workspace
+// root, and creates its files through ApplyPatch: the same path checks
+// apply, existing files are never overwritten and the change is journaled
+// for RollbackChange.
+func CreateFromTemplate(root string, t *FileTemplate, vars map[string]string, dir string, opts PatchOptions) (*PatchResult, error) {
+	files, err := t.Render(vars)
+	if err != nil {
+		return nil, err
+	}
+	var diff strings.Builder
+	for _, path := range sortedKeys(files) {
+		diff.WriteString(newFileDiff(filepath.ToSlash(filepath.Join(dir, path)), files[path]))
+	}
+	return ApplyPatch(root, diff.String(), opts)
+}
+
+// newFileDiff returns the unified diff creating path with content.
+func newFileDiff(path, content string) string {
+	var sb strings.Builder
+	sb.WriteString("--- /dev/null\n")
+	sb.WriteString("+++ b/" + path + "\n")
+	if content == "" {
+		return sb.String()
+	}
+	lines := strings.Split(content, "\n")
+	noNewline := lines[len(lines)-1] != ""
+	if !noNewline {
+		lines = lines[:len(lines)-1]
+	}
+	sb.WriteString(fmt.Sprintf("@@ -0,0 +1,%d @@\n", len(lines)))
+	for _, l := range lines {
+		sb.WriteString("+" + l + "\n")
+	}
+	if noNewline {
+		sb.WriteString("\\ No newline at end of file\n")
+	}
+	return sb.String()
+}
@@ -0,0 +1,67 @@
+package workspace
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCreateFromTemplate(t *testing.T) {
+	root := writePatchFixture(t, map[string]string{
+		".ragcode/templates/service/template.yaml":             "description: Service with a constructor\nvars:\n  - name: Name\n  - name: Receiver\n    default: s\n",
+		".ragcode/templates/service/{{.Name | snake}}.go.tmpl": "package svc\n\ntype {{.Name}} struct{}\n\nfunc New{{.Name}}() *{{.Name}} { return &{{.Name}}{} }\n\nfunc ({{.Receiver}} *{{.Name}}) Close() {}",
+	})
+
+	templates, err := ListTemplates(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, tmpl := range templates {
+		names = append(names, tmpl.Name)
+	}
+	if got := strings.Join(names, ","); got != "go-package,laravel-controller,pytest-module,service" {
+		t.Fatalf("templates = %s", got)
+	}
+
+	svc, err := FindTemplate(root, "service")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.Render(nil); err == nil || !strings.Contains(err.Error(), "requires variable Name") {
+		t.Fatalf("missing required var: err = %v", err)
+	}
+	result, err := CreateFromTemplate(root, svc, map[string]string{"Name": "OrderBook"}, "internal/svc", PatchOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Files) != 1 || result.Files[0].Path != "internal/svc/order_book.go" || result.Files[0].Action != "create" || result.ChangeID == "" {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+	want := "package svc\n\ntype OrderBook struct{}\n\nfunc NewOrderBook() *OrderBook { return &OrderBook{} }\n\nfunc (s *OrderBook) Close() {}"
+	if got := readFile(t, root, "internal/svc/order_book.go"); got != want {
+		t.Fatalf("rendered file:\n%q\nwant\n%q", got, want)
+	}
+
+	// Existing files are never overwritten, and the sandbox applies
+	if _, err := CreateFromTemplate(root, svc, map[string]string{"Name": "OrderBook"}, "internal/svc", PatchOptions{}); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Fatalf("overwrite: err = %v", err)
+	}
+	if _, err := CreateFromTemplate(root, svc, map[string]string{"Name": "X"}, "../out", PatchOptions{}); err == nil {
+		t.Fatal("expected a path outside the workspace to be rejected")
+	}
+
+	ctrl, err := FindTemplate(root, "laravel-controller")
+	if err != nil {
+		t.Fatal(err)
+	}
+	files, err := ctrl.Render(map[string]string{"Name": "invoice_controller"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if content, ok := files["app/Http/Controllers/InvoiceController.php"]; !ok || !strings.Contains(content, "class InvoiceController extends Controller") {
+		t.Fatalf("laravel-controller rendered %v", files)
+	}
+	if snakeCase("HTTPServer2Go") != "http_server2_go" || pascalCase("order-book") != "OrderBook" {
+		t.Fatalf("case helpers: %s %s", snakeCase("HTTPServer2Go"), pascalCase("order-book"))
+	}
+}
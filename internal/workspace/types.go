@@ -7,7 +7,8 @@ type Info struct {
 	// Root is the absolute path to the workspace root directory
 	Root string `json:"root"`
 
-	// ID is a stable, unique identifier for this workspace (hash of Root)
+	// ID is a stable, unique identifier for this workspace: a hash of its
+	// git remote and path in the repository, or of Root (see Detector)
 	ID string `json:"id"`
 
 	// ProjectType indicates the detected project type (go, node, python, etc.)
@@ -0,0 +1,380 @@
+package workspace
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/doITmagic/rag-code-mcp/internal/llm"
+)
+
+// Outcomes of a tool call in the usage statistics
+const (
+	UsageHit      = "hit"       // the tool returned results
+	UsageNotFound = "not_found" // the tool ran but found nothing
+	UsageError    = "error"     // the tool failed
+	UsagePending  = "pending"   // the workspace was still being indexed
+)
+
+const (
+	usageRetentionDays   = 90
+	usageMaxDailyQueries = 1000
+	usageMaxQueryLength  = 200
+)
+
+// UsageStats are the anonymized tool-call statistics of a workspace,
+// aggregated per day in .ragcode/usage.json. Only counters and normalized
+// query texts are kept: no parameters, paths, results or client identity.
+type UsageStats struct {
+	Days map[string]*UsageDay `json:"days"` // "2006-01-02" -> counters
+}
+
+// UsageDay holds the counters of one day.
+type UsageDay struct {
+	Tools     map[string]*ToolUsage         `json:"tools"`
+	Queries   map[string]*QueryUsage        `json:"queries,omitempty"`
+	Providers map[string]*llm.ProviderUsage `json:"providers,omitempty"` // "provider/kind"
+}
+
+// ToolUsage counts the calls of one tool by outcome.
+type ToolUsage struct {
+	Calls    int   `json:"calls"`
+	Hits     int   `json:"hits"`
+	NotFound int   `json:"not_found"`
+	Errors   int   `json:"errors"`
+	Pending  int   `json:"pending"`
+	TotalMs  int64 `json:"total_ms"`
+
+	// Provider requests made by the calls, with estimated tokens and cost
+	ProviderRequests int     `json:"provider_requests,omitempty"`
+	Tokens           int     `json:"tokens,omitempty"`
+	CostUSD          float64 `json:"cost_usd,omitempty"`
+}
+
+// QueryUsage counts how often a query was asked and came back empty.
+type QueryUsage struct {
+	Count    int `json:"count"`
+	NotFound int `json:"not_found"`
+}
+
+// UsageEvent is one tool call to record.
+type UsageEvent struct {
+	Time     time.Time
+	Tool     string
+	Query    string // search text or symbol name, if any
+	Outcome  string
+	Duration time.Duration
+
+	// Providers are the embedding and chat requests the call made
+	Providers []llm.ProviderUsage
+}
+
+// IndexingUsageTool is the tool name under which the provider requests of
+// indexing runs are recorded
+const IndexingUsageTool = "(indexing)"
+
+func usagePath(root string) string {
+	return filepath.Join(root, ".ragcode", "usage.json")
+}
+
+// LoadUsageStats reads the usage statistics of a workspace, returning empty
+// statistics if none have been recorded yet.
+func LoadUsageStats(root string) (*UsageStats, error) {
+	stats := &UsageStats{Days: make(map[string]*UsageDay)}
+	data, err := os.ReadFile(usagePath(root))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return stats, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(data, stats); err != nil {
+		return nil, err
+	}
+	if stats.Days == nil {
+		stats.Days = make(map[string]*UsageDay)
+	}
+	return stats, nil
+}
+
+func (s *UsageStats) save(root string) error {
+	path := usagePath(root)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// add counts ev and drops days past the retention period.
+func (s *UsageStats) add(ev UsageEvent) {
+	key := ev.Time.Format(time.DateOnly)
+	day := s.Days[key]
+	if day == nil {
+		day = &UsageDay{Tools: make(map[string]*ToolUsage), Queries: make(map[string]*QueryUsage)}
+		s.Days[key] = day
+		cutoff := ev.Time.AddDate(0, 0, -usageRetentionDays).Format(time.DateOnly)
+		for k := range s.Days {
+			if k < cutoff {
+				delete(s.Days, k)
+			}
+		}
+	}
+
+	tool := day.Tools[ev.Tool]
+	if tool == nil {
+		tool = &ToolUsage{}
+		day.Tools[ev.Tool] = tool
+	}
+	tool.Calls++
+	tool.TotalMs += ev.Duration.Milliseconds()
+	switch ev.Outcome {
+	case UsageHit:
+		tool.Hits++
+	case UsageNotFound:
+		tool.NotFound++
+	case UsageError:
+		tool.Errors++
+	case UsagePending:
+		tool.Pending++
+	}
+	for _, u := range ev.Providers {
+		tool.ProviderRequests += u.Requests
+		tool.Tokens += u.Tokens
+		tool.CostUSD += u.CostUSD
+		if day.Providers == nil {
+			day.Providers = make(map[string]*llm.ProviderUsage)
+		}
+		key := u.Provider + "/" + u.Kind
+		sum := day.Providers[key]
+		if sum == nil {
+			sum = &llm.ProviderUsage{Provider: u.Provider, Kind: u.Kind}
+			day.Providers[key] = sum
+		}
+		sum.Requests += u.Requests
+		sum.Tokens += u.Tokens
+		sum.CostUSD += u.CostUSD
+	}
+
+	query := NormalizeUsageQuery(ev.Query)
+	if query == "" || ev.Outcome == UsageError || ev.Outcome == UsagePending {
+		return
+	}
+	if day.Queries == nil {
+		day.Queries = make(map[string]*QueryUsage)
+	}
+	q := day.Queries[query]
+	if q == nil {
+		if len(day.Queries) >= usageMaxDailyQueries {
+			return
+		}
+		q = &QueryUsage{}
+		day.Queries[query] = q
+	}
+	q.Count++
+	if ev.Outcome == UsageNotFound {
+		q.NotFound++
+	}
+}
+
+// NormalizeUsageQuery lower-cases a query and collapses its whitespace so
+// variants of the same question are counted together.
+func NormalizeUsageQuery(query string) string {
+	query = strings.ToLower(strings.Join(strings.Fields(query), " "))
+	if len(query) > usageMaxQueryLength {
+		query = strings.ToValidUTF8(query[:usageMaxQueryLength], "")
+	}
+	return query
+}
+
+// RecordUsage adds a tool call to the workspace usage statistics when usage
+// statistics are enabled (queries.usage).
+func (m *Manager) RecordUsage(info *Info, ev UsageEvent) error {
+	if !m.UsageEnabled() {
+		return nil
+	}
+	if ev.Time.IsZero() {
+		ev.Time = time.Now()
+	}
+
+	m.usageMu.Lock()
+	defer m.usageMu.Unlock()
+	stats, err := LoadUsageStats(info.Root)
+	if err != nil {
+		// A corrupt file is replaced rather than blocking the statistics
+		stats = &UsageStats{Days: make(map[string]*UsageDay)}
+	}
+	stats.add(ev)
+	return stats.save(info.Root)
+}
+
+// UsageReport summarizes the usage statistics of a period.
+type UsageReport struct {
+	From    string         `json:"from"`
+	To      string         `json:"to"`
+	Summary ToolReport     `json:"summary"`
+	Tools   []ToolReport   `json:"tools"`
+	Queries []QueryReport  `json:"top_queries"`
+	Misses  []QueryReport  `json:"top_not_found"`
+	Days    map[string]int `json:"calls_per_day"`
+
+	// Providers are the embedding and chat requests of the period per
+	// provider and kind; Budgets is today's use of the daily budgets of the
+	// running server
+	Providers []llm.ProviderUsage `json:"providers,omitempty"`
+	Budgets   []llm.BudgetStatus  `json:"budgets,omitempty"`
+}
+
+// ToolReport is the call count, outcome rates (0-1) and mean latency of a
+// tool, or of all tools in UsageReport.Summary.
+type ToolReport struct {
+	Tool         string  `json:"tool,omitempty"`
+	Calls        int     `json:"calls"`
+	HitRate      float64 `json:"hit_rate"`
+	NotFoundRate float64 `json:"not_found_rate"`
+	ErrorRate    float64 `json:"error_rate"`
+	AvgLatencyMs int64   `json:"avg_latency_ms"`
+	Tokens       int     `json:"tokens,omitempty"`
+	CostUSD      float64 `json:"cost_usd,omitempty"`
+}
+
+// QueryReport is a query with its counts in the period.
+type QueryReport struct {
+	Query    string `json:"query"`
+	Count    int    `json:"count"`
+	NotFound int    `json:"not_found"`
+}
+
+// Report aggregates the days from..to (inclusive, "2006-01-02") and keeps
+// the top queries and most frequent queries that found nothing.
+func (s *UsageStats) Report(from, to string, top int) *UsageReport {
+	report := &UsageReport{From: from, To: to, Days: make(map[string]int)}
+	tools := make(map[string]*ToolUsage)
+	queries := make(map[string]*QueryUsage)
+	providers := make(map[string]*llm.ProviderUsage)
+	total := &ToolUsage{}
+	for key, day := range s.Days {
+		if key < from || key > to {
+			continue
+		}
+		for name, t := range day.Tools {
+			sum := tools[name]
+			if sum == nil {
+				sum = &ToolUsage{}
+				tools[name] = sum
+			}
+			for _, acc := range []*ToolUsage{sum, total} {
+				acc.Calls += t.Calls
+				acc.Hits += t.Hits
+				acc.NotFound += t.NotFound
+				acc.Errors += t.Errors
+				acc.Pending += t.Pending
+				acc.TotalMs += t.TotalMs
+				acc.ProviderRequests += t.ProviderRequests
+				acc.Tokens += t.Tokens
+				acc.CostUSD += t.CostUSD
+			}
+			report.Days[key] += t.Calls
+		}
+		for key, u := range day.Providers {
+			sum := providers[key]
+			if sum == nil {
+				sum = &llm.ProviderUsage{Provider: u.Provider, Kind: u.Kind}
+				providers[key] = sum
+			}
+			sum.Requests += u.Requests
+			sum.Tokens += u.Tokens
+			sum.CostUSD += u.CostUSD
+		}
+		for text, q := range day.Queries {
+			sum := queries[text]
+			if sum == nil {
+				sum = &QueryUsage{}
+				queries[text] = sum
+			}
+			sum.Count += q.Count
+			sum.NotFound += q.NotFound
+		}
+	}
+
+	report.Summary = toolReport("", total)
+	for name, t := range tools {
+		report.Tools = append(report.Tools, toolReport(name, t))
+	}
+	sort.Slice(report.Tools, func(i, j int) bool {
+		if report.Tools[i].Calls != report.Tools[j].Calls {
+			return report.Tools[i].Calls > report.Tools[j].Calls
+		}
+		return report.Tools[i].Tool < report.Tools[j].Tool
+	})
+
+	for _, u := range providers {
+		report.Providers = append(report.Providers, *u)
+	}
+	sort.Slice(report.Providers, func(i, j int) bool {
+		if report.Providers[i].Provider != report.Providers[j].Provider {
+			return report.Providers[i].Provider < report.Providers[j].Provider
+		}
+		return report.Providers[i].Kind < report.Providers[j].Kind
+	})
+
+	for text, q := range queries {
+		report.Queries = append(report.Queries, QueryReport{Query: text, Count: q.Count, NotFound: q.NotFound})
+	}
+	sort.Slice(report.Queries, func(i, j int) bool {
+		if report.Queries[i].Count != report.Queries[j].Count {
+			return report.Queries[i].Count > report.Queries[j].Count
+		}
+		return report.Queries[i].Query < report.Queries[j].Query
+	})
+	for _, q := range report.Queries {
+		if q.NotFound > 0 {
+			report.Misses = append(report.Misses, q)
+		}
+	}
+	sort.SliceStable(report.Misses, func(i, j int) bool { return report.Misses[i].NotFound > report.Misses[j].NotFound })
+	if len(report.Queries) > top {
+		report.Queries = report.Queries[:top]
+	}
+	if len(report.Misses) > top {
+		report.Misses = report.Misses[:top]
+	}
+	return report
+}
+
+func toolReport(name string, t *ToolUsage) ToolReport {
+	r := ToolReport{Tool: name, Calls: t.Calls, Tokens: t.Tokens, CostUSD: t.CostUSD}
+	if t.Calls > 0 {
+		calls := float64(t.Calls)
+		r.HitRate = float64(t.Hits) / calls
+		r.NotFoundRate = float64(t.NotFound) / calls
+		r.ErrorRate = float64(t.Errors) / calls
+		r.AvgLatencyMs = t.TotalMs / int64(t.Calls)
+	}
+	return r
+}
+
+// BuildUsageReport reports the last days days (including today) of the
+// usage statistics of the workspace at root.
+func BuildUsageReport(root string, days, top int, now time.Time) (*UsageReport, error) {
+	if days <= 0 {
+		days = 7
+	}
+	stats, err := LoadUsageStats(root)
+	if err != nil {
+		return nil, err
+	}
+	from := now.AddDate(0, 0, -(days - 1)).Format(time.DateOnly)
+	return stats.Report(from, now.Format(time.DateOnly), top), nil
+}
+
+// UsageEnabled reports whether tool calls are recorded (queries.usage).
+func (m *Manager) UsageEnabled() bool {
+	return m != nil && m.config != nil && m.config.Queries.Usage
+}
@@ -0,0 +1,123 @@
+package workspace
+
+import (
+	"testing"
+	"time"
+
+	"github.com/doITmagic/rag-code-mcp/internal/config"
+	"github.com/doITmagic/rag-code-mcp/internal/llm"
+)
+
+func TestUsageStats(t *testing.T) {
+	root := t.TempDir()
+	info := &Info{Root: root}
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+
+	disabled := &Manager{config: &config.Config{}}
+	if err := disabled.RecordUsage(info, UsageEvent{Time: now, Tool: "search_code"}); err != nil {
+		t.Fatal(err)
+	}
+	if stats, _ := LoadUsageStats(root); len(stats.Days) != 0 {
+		t.Fatal("usage recorded although queries.usage is off")
+	}
+
+	m := &Manager{config: &config.Config{Queries: config.QueriesConfig{Usage: true}}}
+	events := []UsageEvent{
+		{Time: now, Tool: "search_code", Query: "Retry  Logic", Outcome: UsageHit, Duration: 100 * time.Millisecond},
+		{Time: now, Tool: "search_code", Query: "retry logic", Outcome: UsageHit, Duration: 300 * time.Millisecond},
+		{Time: now, Tool: "search_code", Query: "billing webhook", Outcome: UsageNotFound, Duration: 200 * time.Millisecond},
+		{Time: now.AddDate(0, 0, -1), Tool: "get_function_details", Query: "ParseConfig", Outcome: UsageError},
+		{Time: now.AddDate(0, 0, -1), Tool: "search_code", Query: "anything", Outcome: UsagePending},
+		{Time: now.AddDate(0, 0, -20), Tool: "search_code", Query: "old", Outcome: UsageHit},
+	}
+	for _, ev := range events {
+		if err := m.RecordUsage(info, ev); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	report, err := BuildUsageReport(root, 7, 10, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.From != "2026-03-04" || report.To != "2026-03-10" {
+		t.Errorf("period %s..%s", report.From, report.To)
+	}
+	if report.Summary.Calls != 5 {
+		t.Errorf("summary calls = %d, want 5 (the 20-day-old call is outside the period)", report.Summary.Calls)
+	}
+	if len(report.Tools) != 2 || report.Tools[0].Tool != "search_code" {
+		t.Fatalf("tools = %+v", report.Tools)
+	}
+	search := report.Tools[0]
+	if search.Calls != 4 || search.HitRate != 0.5 || search.NotFoundRate != 0.25 || search.AvgLatencyMs != 150 {
+		t.Errorf("search_code report = %+v", search)
+	}
+	if report.Tools[1].ErrorRate != 1 {
+		t.Errorf("get_function_details report = %+v", report.Tools[1])
+	}
+	// Normalized queries are merged; failed and pending calls record no query
+	if len(report.Queries) != 2 || report.Queries[0] != (QueryReport{Query: "retry logic", Count: 2}) {
+		t.Errorf("top queries = %+v", report.Queries)
+	}
+	if len(report.Misses) != 1 || report.Misses[0].Query != "billing webhook" {
+		t.Errorf("not found = %+v", report.Misses)
+	}
+	if report.Days["2026-03-10"] != 3 || report.Days["2026-03-09"] != 2 {
+		t.Errorf("calls per day = %v", report.Days)
+	}
+
+	// Days past the retention period are dropped when a new day starts
+	if err := m.RecordUsage(info, UsageEvent{Time: now.AddDate(0, 0, 100), Tool: "search_code", Outcome: UsageHit}); err != nil {
+		t.Fatal(err)
+	}
+	stats, err := LoadUsageStats(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stats.Days) != 1 {
+		t.Errorf("days after retention = %d, want 1", len(stats.Days))
+	}
+}
+
+func TestUsageStatsProviders(t *testing.T) {
+	root := t.TempDir()
+	info := &Info{Root: root}
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	m := &Manager{config: &config.Config{Queries: config.QueriesConfig{Usage: true}}}
+
+	events := []UsageEvent{
+		{Time: now, Tool: "search_code", Outcome: UsageHit, Providers: []llm.ProviderUsage{
+			{Provider: "tei", Kind: llm.KindEmbed, Requests: 1, Tokens: 10},
+		}},
+		{Time: now, Tool: IndexingUsageTool, Outcome: UsageHit, Providers: []llm.ProviderUsage{
+			{Provider: "tei", Kind: llm.KindEmbed, Requests: 4, Tokens: 1000},
+			{Provider: "anthropic", Kind: llm.KindChat, Requests: 2, Tokens: 500, CostUSD: 0.0015},
+		}},
+	}
+	for _, ev := range events {
+		if err := m.RecordUsage(info, ev); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	report, err := BuildUsageReport(root, 7, 10, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Summary.Tokens != 1510 || report.Summary.CostUSD != 0.0015 {
+		t.Errorf("summary = %+v", report.Summary)
+	}
+	want := []llm.ProviderUsage{
+		{Provider: "anthropic", Kind: llm.KindChat, Requests: 2, Tokens: 500, CostUSD: 0.0015},
+		{Provider: "tei", Kind: llm.KindEmbed, Requests: 5, Tokens: 1010},
+	}
+	if len(report.Providers) != 2 || report.Providers[0] != want[0] || report.Providers[1] != want[1] {
+		t.Errorf("providers = %+v, want %+v", report.Providers, want)
+	}
+	for _, tr := range report.Tools {
+		if tr.Tool == IndexingUsageTool && tr.Tokens != 1500 {
+			t.Errorf("indexing tokens = %d, want 1500", tr.Tokens)
+		}
+	}
+}
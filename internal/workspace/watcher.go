@@ -127,17 +127,22 @@ func (fw *FileWatcher) triggerDebouncedIndex() {
 		log.Printf("♻️ File changes detected in %s - Triggering reindex...", fw.root)
 
 		// Trigger indexing in background
-		go func() {
+		fw.manager.background(func(ctx context.Context) {
 			// EnsureWorkspaceIndexed handles detection internally
-			if err := fw.manager.EnsureWorkspaceIndexed(context.Background(), fw.root); err != nil {
+			if err := fw.manager.EnsureWorkspaceIndexed(ctx, fw.root); err != nil {
 				log.Printf("[ERROR] Auto-reindexing failed: %v", err)
 			} else {
 				log.Printf("✅ Auto-reindexing complete for %s", fw.root)
 			}
-		}()
+		})
 	})
 }
 
 func (fw *FileWatcher) Stop() {
+	fw.eventsMu.Lock()
+	if fw.timer != nil {
+		fw.timer.Stop()
+	}
+	fw.eventsMu.Unlock()
 	close(fw.stopChan)
 }